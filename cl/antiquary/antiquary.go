@@ -3,6 +3,7 @@ package antiquary
 import (
 	"context"
 	"math"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -13,9 +14,11 @@ import (
 	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/persistence/beacon_indicies"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	state_accessors "github.com/ledgerwatch/erigon/cl/persistence/state"
+	"github.com/ledgerwatch/erigon/cl/persistence/state/historical_states_reader"
 	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
 	"github.com/ledgerwatch/erigon/cl/utils"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
@@ -168,6 +171,9 @@ func (a *Antiquary) Loop() error {
 
 	if a.states {
 		go a.loopStates(a.ctx)
+		if a.sn.BeaconStatesEnabled() {
+			go a.loopStatesSnapshots(a.ctx)
+		}
 	}
 	if a.blobs {
 		go a.loopBlobs(a.ctx)
@@ -307,10 +313,52 @@ func (a *Antiquary) loopBlobs(ctx context.Context) {
 			if err := a.antiquateBlobs(); err != nil {
 				log.Error("[Antiquary]: Failed to antiquate blobs", "err", err)
 			}
+			if err := a.indexBlobsArchive(); err != nil {
+				log.Error("[Antiquary]: Failed to index blobs archive", "err", err)
+			}
 		}
 	}
 }
 
+// indexBlobsArchive - indexes versioned hashes of blob sidecars snapshots which are not produced by this node (for example: downloaded from webseeds)
+func (a *Antiquary) indexBlobsArchive() error {
+	if !a.sn.BlobsArchiveEnabled() {
+		return nil
+	}
+	from, err := a.blobStorage.FrozenVersionedHashIndexProgress(a.ctx)
+	if err != nil {
+		return err
+	}
+	from = utils.Max64(from, a.cfg.DenebForkEpoch*a.cfg.SlotsPerEpoch)
+	to := a.sn.FrozenBlobs()
+	if to <= from {
+		return nil
+	}
+	a.logger.Info("[Antiquary]: Indexing blobs archive", "from", from, "to", to)
+	const batch = 1_000
+	for i := from; i < to; i += batch {
+		batchTo := utils.Min64(i+batch, to)
+		var sidecars []*cltypes.BlobSidecar
+		for slot := i; slot < batchTo; slot++ {
+			slotSidecars, err := a.sn.ReadBlobSidecars(slot)
+			if err != nil {
+				return err
+			}
+			sidecars = append(sidecars, slotSidecars...)
+		}
+		if err := a.blobStorage.WriteFrozenVersionedHashIndex(a.ctx, sidecars, batchTo); err != nil {
+			return err
+		}
+		select {
+		case <-a.ctx.Done():
+			return a.ctx.Err()
+		default:
+		}
+	}
+	a.logger.Info("[Antiquary]: Finished indexing blobs archive", "from", from, "to", to)
+	return nil
+}
+
 func (a *Antiquary) antiquateBlobs() error {
 	roTx, err := a.mainDB.BeginRo(a.ctx)
 	if err != nil {
@@ -368,3 +416,79 @@ func (a *Antiquary) antiquateBlobs() error {
 	}
 	return nil
 }
+
+func (a *Antiquary) loopStatesSnapshots(ctx context.Context) {
+	statesAntiquationTicker := time.NewTicker(10 * time.Minute)
+	defer statesAntiquationTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-statesAntiquationTicker.C:
+			if err := a.antiquateStates(); err != nil {
+				log.Error("[Antiquary]: Failed to antiquate states", "err", err)
+			}
+		}
+	}
+}
+
+// antiquateStates - freezes states (periodic full states and diffs against them) of ranges which are already processed by states antiquary and are in beacon blocks snapshots
+func (a *Antiquary) antiquateStates() error {
+	progress, _, err := a.readHistoricalProcessingProgress(a.ctx)
+	if err != nil {
+		return err
+	}
+	from := a.sn.FrozenStates()
+	to := utils.Min64(progress, a.sn.BlocksAvailable())
+	to = (to / snaptype.Erigon2MergeLimit) * snaptype.Erigon2MergeLimit
+	if to <= from || to-from < snaptype.Erigon2MergeLimit {
+		return nil
+	}
+	if a.snBuildSema != nil {
+		if !a.snBuildSema.TryAcquire(caplinSnapshotBuildSemaWeight) {
+			return nil
+		}
+		defer a.snBuildSema.Release(caplinSnapshotBuildSemaWeight)
+	}
+
+	a.logger.Info("[Antiquary]: Antiquating states", "from", from, "to", to)
+	historicalReader := historical_states_reader.NewHistoricalStatesReader(a.cfg, a.snReader, a.validatorsTable, a.genesisState)
+	readState := func(ctx context.Context, tx kv.Tx, slot uint64) ([]byte, []int, clparams.StateVersion, error) {
+		st, err := historicalReader.ReadHistoricalState(ctx, tx, slot)
+		if err != nil || st == nil {
+			return nil, nil, 0, err
+		}
+		buf, err := st.EncodeSSZ(nil)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		segments, err := st.SegmentsSSZ(buf)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		return buf, segments, st.Version(), nil
+	}
+	if err := freezeblocks.DumpBeaconStates(a.ctx, a.mainDB, readState, from, to, a.sn.Salt, a.dirs, 1, log.LvlDebug, a.logger); err != nil {
+		return err
+	}
+	if err := a.sn.ReopenFolder(); err != nil {
+		return err
+	}
+	a.logger.Info("[Antiquary]: Finished Antiquating states", "from", from, "to", to)
+	if a.downloader == nil {
+		return nil
+	}
+
+	paths := a.sn.SegFilePaths(from, to)
+	downloadItems := make([]*proto_downloader.AddItem, 0, len(paths))
+	for _, path := range paths {
+		if strings.Contains(path, snaptype.BeaconStates.Name()) {
+			downloadItems = append(downloadItems, &proto_downloader.AddItem{Path: path})
+		}
+	}
+	// Notify bittorent to seed the new snapshots
+	if _, err := a.downloader.Add(a.ctx, &proto_downloader.AddRequest{Items: downloadItems}); err != nil {
+		log.Warn("[Antiquary]: Failed to add items to bittorent", "err", err)
+	}
+	return nil
+}
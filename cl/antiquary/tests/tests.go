@@ -91,6 +91,10 @@ func (m *MockBlockReader) ReadHeaderByRoot(ctx context.Context, tx kv.Tx, blockR
 	return block.SignedBeaconBlockHeader(), nil
 }
 
+func (m *MockBlockReader) ReadBeaconStateSSZ(slot uint64) ([]byte, clparams.StateVersion, error) {
+	return nil, 0, nil
+}
+
 func (m *MockBlockReader) FrozenSlots() uint64 {
 	panic("implement me")
 }
@@ -0,0 +1,162 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+const defaultRequestTimeout = 5 * time.Second
+
+var _ BuilderClient = (*builderClient)(nil)
+
+// builderClient - JSON client of a relay (for example mev-boost) speaking builder API
+type builderClient struct {
+	httpClient *http.Client
+	url        *url.URL
+	beaconCfg  *clparams.BeaconChainConfig
+}
+
+func NewBlockBuilderClient(baseUrl string, beaconCfg *clparams.BeaconChainConfig) (BuilderClient, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseUrl, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme of builder url %q", baseUrl)
+	}
+	return &builderClient{
+		httpClient: &http.Client{Timeout: defaultRequestTimeout},
+		url:        u,
+		beaconCfg:  beaconCfg,
+	}, nil
+}
+
+func (b *builderClient) RegisterValidator(ctx context.Context, registrations []*cltypes.SignedValidatorRegistration) error {
+	_, err := b.do(ctx, http.MethodPost, "/eth/v1/builder/validators", registrations)
+	return err
+}
+
+func (b *builderClient) GetHeader(ctx context.Context, slot uint64, parentHash libcommon.Hash, pubKey libcommon.Bytes48) (*SignedBuilderBid, error) {
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash.Hex(), pubKey.Hex())
+	body, err := b.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		// no bid for this slot
+		return nil, nil
+	}
+	var resp versionedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	version, err := clparams.StringToClVersion(resp.Version)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	if version < clparams.BellatrixVersion {
+		return nil, fmt.Errorf("GET %s: unexpected version %s", path, resp.Version)
+	}
+	bid := NewSignedBuilderBid(version)
+	if err := json.Unmarshal(resp.Data, bid); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	return bid, nil
+}
+
+func (b *builderClient) SubmitBlindedBlocks(ctx context.Context, block *cltypes.SignedBlindedBeaconBlock) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, error) {
+	path := "/eth/v1/builder/blinded_blocks"
+	body, err := b.do(ctx, http.MethodPost, path, block, "Eth-Consensus-Version", clparams.ClVersionToString(block.Version()))
+	if err != nil {
+		return nil, nil, err
+	}
+	if body == nil {
+		return nil, nil, fmt.Errorf("POST %s: empty response", path)
+	}
+	var resp versionedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, nil, fmt.Errorf("POST %s: %w", path, err)
+	}
+	version, err := clparams.StringToClVersion(resp.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("POST %s: %w", path, err)
+	}
+	if version != block.Version() {
+		return nil, nil, fmt.Errorf("POST %s: version %s does not match block version", path, resp.Version)
+	}
+	payload := cltypes.NewEth1Block(version, b.beaconCfg)
+	if version < clparams.DenebVersion {
+		if err := json.Unmarshal(resp.Data, payload); err != nil {
+			return nil, nil, fmt.Errorf("POST %s: %w", path, err)
+		}
+		return payload, nil, nil
+	}
+	payloadAndBlobs := executionPayloadAndBlobsBundle{ExecutionPayload: payload}
+	if err := json.Unmarshal(resp.Data, &payloadAndBlobs); err != nil {
+		return nil, nil, fmt.Errorf("POST %s: %w", path, err)
+	}
+	if payloadAndBlobs.BlobsBundle == nil {
+		return nil, nil, fmt.Errorf("POST %s: missing blobs bundle", path)
+	}
+	return payloadAndBlobs.ExecutionPayload, payloadAndBlobs.BlobsBundle, nil
+}
+
+func (b *builderClient) GetStatus(ctx context.Context) error {
+	_, err := b.do(ctx, http.MethodGet, "/eth/v1/builder/status", nil)
+	return err
+}
+
+// do - sends request with JSON body (if not nil) and optional header key/value pairs, returns the response body or nil
+// if the relay responded with no content
+func (b *builderClient) do(ctx context.Context, method, path string, body any, headers ...string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.url.String()+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if len(respBody) == 0 {
+		return nil, nil
+	}
+	return respBody, nil
+}
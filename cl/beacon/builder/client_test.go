@@ -0,0 +1,89 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/holiman/uint256"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderClientGetHeader(t *testing.T) {
+	bid := NewSignedBuilderBid(clparams.DenebVersion)
+	bid.Message.Header.BlockNumber = 100
+	bid.Message.Header.BaseFeePerGas[0] = 7
+	bid.Message.BlobKzgCommitments.Append(&cltypes.KZGCommitment{1})
+	bid.Message.Value = uint256.NewInt(1_000_000_000)
+	bid.Message.PubKey = libcommon.Bytes48{2}
+	bid.Signature = libcommon.Bytes96{3}
+
+	hasBid := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/eth/v1/builder/header/10/"+libcommon.Hash{4}.Hex()+"/"+libcommon.Bytes48{5}.Hex(), r.URL.Path)
+		if !hasBid {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"version": "deneb", "data": bid}))
+	}))
+	defer server.Close()
+
+	client, err := NewBlockBuilderClient(server.URL, &clparams.MainnetBeaconConfig)
+	require.NoError(t, err)
+	got, err := client.GetHeader(context.Background(), 10, libcommon.Hash{4}, libcommon.Bytes48{5})
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	hasBid = true
+	got, err = client.GetHeader(context.Background(), 10, libcommon.Hash{4}, libcommon.Bytes48{5})
+	require.NoError(t, err)
+	require.Equal(t, clparams.DenebVersion, got.Message.Version())
+	require.Equal(t, bid.Signature, got.Signature)
+	require.Equal(t, bid.Message.Value, got.Message.Value)
+	expectedRoot, err := bid.Message.HashSSZ()
+	require.NoError(t, err)
+	gotRoot, err := got.Message.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, expectedRoot, gotRoot)
+}
+
+func TestBuilderClientSubmitBlindedBlocks(t *testing.T) {
+	payload := cltypes.NewEth1Block(clparams.DenebVersion, &clparams.MainnetBeaconConfig)
+	payload.BlockNumber = 100
+	payload.BlockHash = libcommon.Hash{1}
+	bundle := &engine_types.BlobsBundleV1{
+		Commitments: []hexutility.Bytes{make([]byte, 48)},
+		Proofs:      []hexutility.Bytes{make([]byte, 48)},
+		Blobs:       []hexutility.Bytes{make([]byte, cltypes.BYTES_PER_BLOB)},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/eth/v1/builder/blinded_blocks", r.URL.Path)
+		require.Equal(t, "deneb", r.Header.Get("Eth-Consensus-Version"))
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"version": "deneb",
+			"data":    map[string]any{"execution_payload": payload, "blobs_bundle": bundle},
+		}))
+	}))
+	defer server.Close()
+
+	client, err := NewBlockBuilderClient(server.URL, &clparams.MainnetBeaconConfig)
+	require.NoError(t, err)
+	block := cltypes.NewSignedBlindedBeaconBlock(&clparams.MainnetBeaconConfig)
+	block.Block.Body.Version = clparams.DenebVersion
+	block.Block.Body.EncodingSizeSSZ() // initialize empty lists
+
+	gotPayload, gotBundle, err := client.SubmitBlindedBlocks(context.Background(), block)
+	require.NoError(t, err)
+	require.Equal(t, payload.BlockHash, gotPayload.BlockHash)
+	require.Equal(t, payload.BlockNumber, gotPayload.BlockNumber)
+	require.Len(t, gotBundle.Blobs, 1)
+}
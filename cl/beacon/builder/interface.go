@@ -0,0 +1,22 @@
+package builder
+
+import (
+	"context"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+// BuilderClient - the subset of builder API (https://github.com/ethereum/builder-specs) used by block production
+type BuilderClient interface {
+	// RegisterValidator - forwards fee recipient and gas limit preferences of validators to the relay
+	RegisterValidator(ctx context.Context, registrations []*cltypes.SignedValidatorRegistration) error
+	// GetHeader - returns the best bid of the relay for the given slot, or nil if it has none
+	GetHeader(ctx context.Context, slot uint64, parentHash libcommon.Hash, pubKey libcommon.Bytes48) (*SignedBuilderBid, error)
+	// SubmitBlindedBlocks - reveals the payload of the bid by sending the signed blinded block. Blobs bundle is nil
+	// before deneb
+	SubmitBlindedBlocks(ctx context.Context, block *cltypes.SignedBlindedBeaconBlock) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, error)
+	// GetStatus - returns an error if the relay is not available
+	GetStatus(ctx context.Context) error
+}
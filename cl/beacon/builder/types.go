@@ -0,0 +1,109 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/merkle_tree"
+	"github.com/ledgerwatch/erigon/cl/utils"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+// BuilderBid - execution payload header offered by the relay and its value for the proposer
+type BuilderBid struct {
+	Header             *cltypes.Eth1Header
+	BlobKzgCommitments *solid.ListSSZ[*cltypes.KZGCommitment]
+	Value              *uint256.Int
+	PubKey             libcommon.Bytes48
+
+	version clparams.StateVersion
+}
+
+func NewBuilderBid(version clparams.StateVersion) *BuilderBid {
+	return &BuilderBid{
+		Header:             cltypes.NewEth1Header(version),
+		BlobKzgCommitments: solid.NewStaticListSSZ[*cltypes.KZGCommitment](cltypes.MaxBlobsCommittmentsPerBlock, 48),
+		Value:              uint256.NewInt(0),
+		version:            version,
+	}
+}
+
+func (b *BuilderBid) Version() clparams.StateVersion {
+	return b.version
+}
+
+func (b *BuilderBid) MarshalJSON() ([]byte, error) {
+	var commitments *solid.ListSSZ[*cltypes.KZGCommitment]
+	if b.version >= clparams.DenebVersion {
+		commitments = b.BlobKzgCommitments
+	}
+	return json.Marshal(struct {
+		Header             *cltypes.Eth1Header                    `json:"header"`
+		BlobKzgCommitments *solid.ListSSZ[*cltypes.KZGCommitment] `json:"blob_kzg_commitments,omitempty"`
+		Value              string                                 `json:"value"`
+		PubKey             libcommon.Bytes48                      `json:"pubkey"`
+	}{
+		Header:             b.Header,
+		BlobKzgCommitments: commitments,
+		Value:              b.Value.Dec(),
+		PubKey:             b.PubKey,
+	})
+}
+
+func (b *BuilderBid) UnmarshalJSON(buf []byte) error {
+	var tmp struct {
+		Header             *cltypes.Eth1Header                    `json:"header"`
+		BlobKzgCommitments *solid.ListSSZ[*cltypes.KZGCommitment] `json:"blob_kzg_commitments,omitempty"`
+		Value              string                                 `json:"value"`
+		PubKey             libcommon.Bytes48                      `json:"pubkey"`
+	}
+	tmp.Header = cltypes.NewEth1Header(b.version)
+	tmp.BlobKzgCommitments = solid.NewStaticListSSZ[*cltypes.KZGCommitment](cltypes.MaxBlobsCommittmentsPerBlock, 48)
+	if err := json.Unmarshal(buf, &tmp); err != nil {
+		return err
+	}
+	value, err := uint256.FromDecimal(tmp.Value)
+	if err != nil {
+		return fmt.Errorf("invalid bid value: %w", err)
+	}
+	b.Header = tmp.Header
+	b.BlobKzgCommitments = tmp.BlobKzgCommitments
+	b.Value = value
+	b.PubKey = tmp.PubKey
+	return nil
+}
+
+// HashSSZ - root of the bid, signed by the relay. Value is an uint256, so it is merkleized as 32 little endian bytes.
+func (b *BuilderBid) HashSSZ() ([32]byte, error) {
+	value := b.Value.Bytes32()
+	if b.version >= clparams.DenebVersion {
+		return merkle_tree.HashTreeRoot(b.Header, b.BlobKzgCommitments, utils.ReverseOfByteSlice(value[:]), b.PubKey[:])
+	}
+	return merkle_tree.HashTreeRoot(b.Header, utils.ReverseOfByteSlice(value[:]), b.PubKey[:])
+}
+
+type SignedBuilderBid struct {
+	Message   *BuilderBid       `json:"message"`
+	Signature libcommon.Bytes96 `json:"signature"`
+}
+
+func NewSignedBuilderBid(version clparams.StateVersion) *SignedBuilderBid {
+	return &SignedBuilderBid{Message: NewBuilderBid(version)}
+}
+
+// versionedResponse - the envelope of builder API responses depending on the fork
+type versionedResponse struct {
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// executionPayloadAndBlobsBundle - payload revealed by the relay after deneb
+type executionPayloadAndBlobsBundle struct {
+	ExecutionPayload *cltypes.Eth1Block          `json:"execution_payload"`
+	BlobsBundle      *engine_types.BlobsBundleV1 `json:"blobs_bundle"`
+}
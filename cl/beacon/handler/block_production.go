@@ -22,6 +22,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/cl/abstract"
 	"github.com/ledgerwatch/erigon/cl/beacon/beaconhttp"
+	"github.com/ledgerwatch/erigon/cl/beacon/builder"
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
@@ -85,9 +86,43 @@ func (a *ApiHandler) GetEthV1ValidatorAttestationData(
 	return newBeaconResponse(attestationData), nil
 }
 
+// blockProduction is the kind of block returned by a block production endpoint.
+type blockProduction int
+
+const (
+	// produceAnyBlock returns the block of the builder or the local one, whichever pays more (v3)
+	produceAnyBlock blockProduction = iota
+	// produceFullBlock returns the local block along with its payload (v2)
+	produceFullBlock
+	// produceBlindedBlock returns the block of the builder or the local one, blinded either way (v1)
+	produceBlindedBlock
+)
+
+func (a *ApiHandler) GetEthV1ValidatorBlindedBlock(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*beaconhttp.BeaconResponse, error) {
+	return a.produceBlock(w, r, produceBlindedBlock)
+}
+
+func (a *ApiHandler) GetEthV2ValidatorBlock(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*beaconhttp.BeaconResponse, error) {
+	return a.produceBlock(w, r, produceFullBlock)
+}
+
 func (a *ApiHandler) GetEthV3ValidatorBlock(
 	w http.ResponseWriter,
 	r *http.Request,
+) (*beaconhttp.BeaconResponse, error) {
+	return a.produceBlock(w, r, produceAnyBlock)
+}
+
+func (a *ApiHandler) produceBlock(
+	w http.ResponseWriter,
+	r *http.Request,
+	production blockProduction,
 ) (*beaconhttp.BeaconResponse, error) {
 	ctx := r.Context()
 	// parse request data
@@ -107,6 +142,13 @@ func (a *ApiHandler) GetEthV3ValidatorBlock(
 	if !r.URL.Query().Has("graffiti") {
 		graffiti = libcommon.HexToHash(hex.EncodeToString([]byte(defaultGraffitiString)))
 	}
+	builderBoostFactor, err := parseBuilderBoostFactor(r)
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(
+			http.StatusBadRequest,
+			fmt.Errorf("invalid builder_boost_factor: %v", err),
+		)
+	}
 
 	tx, err := a.indiciesDB.BeginRo(ctx)
 	if err != nil {
@@ -163,6 +205,42 @@ func (a *ApiHandler) GetEthV3ValidatorBlock(
 		return nil, err
 	}
 
+	proposerIndex, err := baseState.GetBeaconProposerIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ask the builder relay for a bid while the local payload is being built, the local one is the fallback.
+	var (
+		builderBid *builder.SignedBuilderBid
+		builderWg  sync.WaitGroup
+	)
+	if a.builderClient != nil && production != produceFullBlock && builderBoostFactor > 0 &&
+		a.beaconChainCfg.GetCurrentStateVersion(targetSlot/a.beaconChainCfg.SlotsPerEpoch) >= clparams.BellatrixVersion {
+		tripped, err := a.builderCircuitBreakerTripped(baseState, targetSlot)
+		if err != nil {
+			return nil, err
+		}
+		if tripped {
+			log.Warn("BlockProduction: Too many missed slots, not using builder", "slot", targetSlot)
+		} else {
+			constraints, err := a.computeBuilderBidConstraints(baseState, targetSlot, proposerIndex)
+			if err != nil {
+				return nil, err
+			}
+			builderWg.Add(1)
+			go func() {
+				defer builderWg.Done()
+				bid, err := a.getBuilderBid(ctx, constraints)
+				if err != nil {
+					log.Warn("BlockProduction: Failed to get builder bid", "slot", targetSlot, "err", err)
+					return
+				}
+				builderBid = bid
+			}()
+		}
+	}
+
 	beaconBody, executionValue, err := a.produceBeaconBody(
 		ctx,
 		3,
@@ -172,11 +250,7 @@ func (a *ApiHandler) GetEthV3ValidatorBlock(
 		randaoReveal,
 		graffiti,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	proposerIndex, err := baseState.GetBeaconProposerIndex()
+	builderWg.Wait()
 	if err != nil {
 		return nil, err
 	}
@@ -202,24 +276,74 @@ func (a *ApiHandler) GetEthV3ValidatorBlock(
 	if err := machine.ProcessBlock(transition.DefaultMachine, baseState, &cltypes.SignedBeaconBlock{Block: block}); err != nil {
 		return nil, err
 	}
+	consensusValue := rewardsCollector.Attestations + rewardsCollector.ProposerSlashings + rewardsCollector.AttesterSlashings + rewardsCollector.SyncAggregate
+	if builderBid != nil && isBuilderBidPreferred(builderBid.Message.Value, executionValue, builderBoostFactor) {
+		blindedBlock, err := block.Blinded()
+		if err != nil {
+			return nil, err
+		}
+		blindedBlock.Body.ExecutionPayload = builderBid.Message.Header
+		if blindedBlock.Version() >= clparams.DenebVersion {
+			blindedBlock.Body.BlobKzgCommitments = builderBid.Message.BlobKzgCommitments
+		}
+		// The state was transitioned with the local payload: swap in the body root and the payload header of the bid.
+		bodyRoot, err := blindedBlock.Body.HashSSZ()
+		if err != nil {
+			return nil, err
+		}
+		latestBlockHeader := baseState.LatestBlockHeader()
+		latestBlockHeader.BodyRoot = bodyRoot
+		baseState.SetLatestBlockHeader(&latestBlockHeader)
+		baseState.SetLatestExecutionPayloadHeader(builderBid.Message.Header)
+		blindedBlock.StateRoot, err = baseState.HashSSZ()
+		if err != nil {
+			return nil, err
+		}
+		executionValue = builderBid.Message.Value.Uint64()
+		log.Info("BlockProduction: Using builder payload", "slot", targetSlot, "value", builderBid.Message.Value, "hash", builderBid.Message.Header.BlockHash)
+
+		return a.blockProductionResponse(w, production, blindedBlock, blindedBlock.Version(), true, executionValue, consensusValue), nil
+	}
+
 	block.StateRoot, err = baseState.HashSSZ()
 	if err != nil {
 		return nil, err
 	}
-	consensusValue := rewardsCollector.Attestations + rewardsCollector.ProposerSlashings + rewardsCollector.AttesterSlashings + rewardsCollector.SyncAggregate
-	isSSZBlinded := false
+	// Blocks before bellatrix have no payload to blind
+	if production == produceBlindedBlock && block.Version() >= clparams.BellatrixVersion {
+		blindedBlock, err := block.Blinded()
+		if err != nil {
+			return nil, err
+		}
+		return a.blockProductionResponse(w, production, blindedBlock, blindedBlock.Version(), true, executionValue, consensusValue), nil
+	}
+	return a.blockProductionResponse(w, production, block, block.Version(), false, executionValue, consensusValue), nil
+}
+
+// blockProductionResponse - v3 tells in the response whether the block is blinded and what it is worth, the older
+// versions only tell the fork of the block.
+func (a *ApiHandler) blockProductionResponse(
+	w http.ResponseWriter,
+	production blockProduction,
+	block any,
+	version clparams.StateVersion,
+	blinded bool,
+	executionValue, consensusValue uint64,
+) *beaconhttp.BeaconResponse {
 	a.setupHeaderReponseForBlockProduction(
 		w,
-		block.Version(),
-		isSSZBlinded,
+		version,
+		blinded,
 		executionValue,
 		consensusValue,
 	)
-
+	if production != produceAnyBlock {
+		return newBeaconResponse(block).WithVersion(version)
+	}
 	return newBeaconResponse(block).
-		With("execution_payload_blinded", isSSZBlinded).
+		With("execution_payload_blinded", blinded).
 		With("execution_payload_value", strconv.FormatUint(executionValue, 10)).
-		With("consensus_block_value", strconv.FormatUint(consensusValue, 10)), nil
+		With("consensus_block_value", strconv.FormatUint(consensusValue, 10))
 }
 
 func (a *ApiHandler) produceBeaconBody(
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/holiman/uint256"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon/cl/abstract"
+	"github.com/ledgerwatch/erigon/cl/beacon/builder"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/fork"
+	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
+	"github.com/ledgerwatch/erigon/cl/utils"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const (
+	// builderBidTimeout - how long block production waits for the relay, the local payload is used afterwards
+	builderBidTimeout = time.Second
+	// defaultBuilderBoostFactor - percentage applied to the bid value before comparing it with the local payload value
+	defaultBuilderBoostFactor = 100
+)
+
+// builderBidConstraints - what a bid of the relay must commit to, computed from the state the block is built on
+type builderBidConstraints struct {
+	slot            uint64
+	version         clparams.StateVersion
+	parentHash      libcommon.Hash
+	timestamp       uint64
+	withdrawalsRoot libcommon.Hash
+	proposerPubKey  libcommon.Bytes48
+}
+
+func parseBuilderBoostFactor(r *http.Request) (uint64, error) {
+	str := r.URL.Query().Get("builder_boost_factor")
+	if str == "" {
+		return defaultBuilderBoostFactor, nil
+	}
+	return strconv.ParseUint(str, 10, 64)
+}
+
+// builderCircuitBreakerTripped - the relay is not used if too many slots were missed recently, as it may be the cause
+func (a *ApiHandler) builderCircuitBreakerTripped(s abstract.BeaconState, targetSlot uint64) (bool, error) {
+	window := a.beaconChainCfg.SlotsPerEpoch
+	if targetSlot <= window {
+		return false, nil
+	}
+	var missed, consecutiveMissed uint64
+	trailing := true
+	for i := uint64(1); i <= window; i++ {
+		root, err := s.GetBlockRootAtSlot(targetSlot - i)
+		if err != nil {
+			return false, err
+		}
+		prevRoot, err := s.GetBlockRootAtSlot(targetSlot - i - 1)
+		if err != nil {
+			return false, err
+		}
+		// block roots of empty slots repeat the root of the previous block
+		if root != prevRoot {
+			trailing = false
+			continue
+		}
+		missed++
+		if trailing {
+			consecutiveMissed++
+		}
+	}
+	return consecutiveMissed >= a.beaconChainCfg.MaxBuilderConsecutiveMissedSlots ||
+		missed >= a.beaconChainCfg.MaxBuilderEpochMissedSlots, nil
+}
+
+func (a *ApiHandler) computeBuilderBidConstraints(s *state.CachingBeaconState, targetSlot, proposerIndex uint64) (*builderBidConstraints, error) {
+	proposerPubKey, err := s.ValidatorPublicKey(int(proposerIndex))
+	if err != nil {
+		return nil, err
+	}
+	constraints := &builderBidConstraints{
+		slot:           targetSlot,
+		version:        a.beaconChainCfg.GetCurrentStateVersion(targetSlot / a.beaconChainCfg.SlotsPerEpoch),
+		parentHash:     s.LatestExecutionPayloadHeader().BlockHash,
+		timestamp:      s.GenesisTime() + targetSlot*a.beaconChainCfg.SecondsPerSlot,
+		proposerPubKey: proposerPubKey,
+	}
+	if constraints.version >= clparams.CapellaVersion {
+		withdrawals := solid.NewStaticListSSZ[*cltypes.Withdrawal](int(a.beaconChainCfg.MaxWithdrawalsPerPayload), 44)
+		for _, w := range state.ExpectedWithdrawals(s, targetSlot/a.beaconChainCfg.SlotsPerEpoch) {
+			withdrawals.Append(w)
+		}
+		if constraints.withdrawalsRoot, err = withdrawals.HashSSZ(); err != nil {
+			return nil, err
+		}
+	}
+	return constraints, nil
+}
+
+// getBuilderBid - asks the relay for a bid and validates it. Returns nil if the relay has no bid.
+func (a *ApiHandler) getBuilderBid(ctx context.Context, constraints *builderBidConstraints) (*builder.SignedBuilderBid, error) {
+	ctx, cancel := context.WithTimeout(ctx, builderBidTimeout)
+	defer cancel()
+	bid, err := a.builderClient.GetHeader(ctx, constraints.slot, constraints.parentHash, constraints.proposerPubKey)
+	if err != nil {
+		return nil, err
+	}
+	if bid == nil {
+		return nil, nil
+	}
+	header := bid.Message.Header
+	if bid.Message.Version() != constraints.version {
+		return nil, fmt.Errorf("bid version %d, expected %d", bid.Message.Version(), constraints.version)
+	}
+	if header.ParentHash != constraints.parentHash {
+		return nil, fmt.Errorf("bid parent hash %x, expected %x", header.ParentHash, constraints.parentHash)
+	}
+	if header.Time != constraints.timestamp {
+		return nil, fmt.Errorf("bid timestamp %d, expected %d", header.Time, constraints.timestamp)
+	}
+	if constraints.version >= clparams.CapellaVersion && header.WithdrawalsRoot != constraints.withdrawalsRoot {
+		return nil, fmt.Errorf("bid withdrawals root %x, expected %x", header.WithdrawalsRoot, constraints.withdrawalsRoot)
+	}
+	if constraints.version >= clparams.DenebVersion && uint64(bid.Message.BlobKzgCommitments.Len()) > a.beaconChainCfg.MaxBlobsPerBlock {
+		return nil, fmt.Errorf("bid has %d blob commitments", bid.Message.BlobKzgCommitments.Len())
+	}
+	if bid.Message.Value.IsZero() {
+		return nil, fmt.Errorf("bid has zero value")
+	}
+	// bids are signed over the genesis fork version, regardless of the current fork
+	domain, err := fork.ComputeDomain(a.beaconChainCfg.DomainApplicationBuilder[:], utils.Uint32ToBytes4(uint32(a.beaconChainCfg.GenesisForkVersion)), [32]byte{})
+	if err != nil {
+		return nil, err
+	}
+	signingRoot, err := fork.ComputeSigningRoot(bid.Message, domain)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := bls.Verify(bid.Signature[:], signingRoot[:], bid.Message.PubKey[:])
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid bid signature")
+	}
+	return bid, nil
+}
+
+// isBuilderBidPreferred - whether the bid, scaled by the boost factor percentage, beats the local payload
+func isBuilderBidPreferred(bidValue *uint256.Int, localValue, boostFactor uint64) bool {
+	boosted := new(uint256.Int).Mul(bidValue, uint256.NewInt(boostFactor))
+	boosted.Div(boosted, uint256.NewInt(100))
+	return boosted.Gt(uint256.NewInt(localValue))
+}
+
+func (a *ApiHandler) PostEthV1BlindedBlocks(w http.ResponseWriter, r *http.Request) {
+	a.postBlindedBlocks(w, r, 1)
+}
+
+func (a *ApiHandler) PostEthV2BlindedBlocks(w http.ResponseWriter, r *http.Request) {
+	a.postBlindedBlocks(w, r, 2)
+}
+
+func (a *ApiHandler) postBlindedBlocks(w http.ResponseWriter, r *http.Request, apiVersion int) {
+	ctx := r.Context()
+	version, err := a.parseEthConsensusVersion(r.Header.Get("Eth-Consensus-Version"), apiVersion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if a.builderClient == nil {
+		http.Error(w, "no builder relay configured", http.StatusBadRequest)
+		return
+	}
+	if version < clparams.BellatrixVersion {
+		http.Error(w, "blinded blocks are not supported before bellatrix", http.StatusBadRequest)
+		return
+	}
+	blindedBlock, err := a.parseRequestBlindedBlock(version, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	block, err := a.unblindBlock(ctx, blindedBlock)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := a.broadcastBlock(ctx, block); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *ApiHandler) parseRequestBlindedBlock(
+	version clparams.StateVersion,
+	r *http.Request,
+) (*cltypes.SignedBlindedBeaconBlock, error) {
+	block := cltypes.NewSignedBlindedBeaconBlock(a.beaconChainCfg)
+	block.Block.Body.Version = version
+	// check content type
+	if r.Header.Get("Content-Type") == "application/json" {
+		return block, json.NewDecoder(r.Body).Decode(block)
+	}
+	octect, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := block.DecodeSSZ(octect, int(version)); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// unblindBlock - reveals the payload of a signed blinded block through the relay and keeps its blobs for publishing
+func (a *ApiHandler) unblindBlock(ctx context.Context, blindedBlock *cltypes.SignedBlindedBeaconBlock) (*cltypes.SignedBeaconBlock, error) {
+	payload, blobsBundle, err := a.builderClient.SubmitBlindedBlocks(ctx, blindedBlock)
+	if err != nil {
+		return nil, err
+	}
+	payloadHeader, err := payload.PayloadHeader()
+	if err != nil {
+		return nil, err
+	}
+	payloadHeaderRoot, err := payloadHeader.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	expectedHeaderRoot, err := blindedBlock.Block.Body.ExecutionPayload.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if payloadHeaderRoot != expectedHeaderRoot {
+		return nil, fmt.Errorf("payload revealed by builder does not match the header of the block")
+	}
+	if blindedBlock.Version() >= clparams.DenebVersion {
+		commitments := blindedBlock.Block.Body.BlobKzgCommitments
+		if len(blobsBundle.Commitments) != commitments.Len() ||
+			len(blobsBundle.Proofs) != commitments.Len() ||
+			len(blobsBundle.Blobs) != commitments.Len() {
+			return nil, fmt.Errorf("blobs bundle revealed by builder does not match the commitments of the block")
+		}
+		for i := range blobsBundle.Blobs {
+			if len(blobsBundle.Commitments[i]) != length.Bytes48 || len(blobsBundle.Proofs[i]) != length.Bytes48 ||
+				len(blobsBundle.Blobs[i]) != int(cltypes.BYTES_PER_BLOB) {
+				return nil, fmt.Errorf("invalid blobs bundle revealed by builder")
+			}
+			if libcommon.Bytes48(blobsBundle.Commitments[i]) != libcommon.Bytes48(*commitments.Get(i)) {
+				return nil, fmt.Errorf("blob commitment %d revealed by builder does not match the block", i)
+			}
+			a.blobBundles.Add(libcommon.Bytes48(blobsBundle.Commitments[i]), BlobBundle{
+				Blob:       (*cltypes.Blob)(blobsBundle.Blobs[i]),
+				KzgProof:   libcommon.Bytes48(blobsBundle.Proofs[i]),
+				Commitment: libcommon.Bytes48(blobsBundle.Commitments[i]),
+			})
+		}
+	}
+	log.Info("BlockPublishing: payload revealed by builder", "slot", blindedBlock.Block.Slot, "hash", payload.BlockHash)
+	return blindedBlock.Full(payload.Transactions, payload.Withdrawals), nil
+}
@@ -12,10 +12,12 @@ import (
 	"github.com/ledgerwatch/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/ledgerwatch/erigon/cl/beacon/beaconevents"
 	"github.com/ledgerwatch/erigon/cl/beacon/beaconhttp"
+	"github.com/ledgerwatch/erigon/cl/beacon/builder"
 	"github.com/ledgerwatch/erigon/cl/beacon/synced_data"
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/monitor"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	"github.com/ledgerwatch/erigon/cl/persistence/state/historical_states_reader"
 	"github.com/ledgerwatch/erigon/cl/phase1/core/state/lru"
@@ -77,6 +79,8 @@ type ApiHandler struct {
 	committeeSub        *committee_subscription.CommitteeSubscribeMgmt
 	attestationProducer attestation_producer.AttestationDataProducer
 	aggregatePool       aggregation.AggregationPool
+	builderClient       builder.BuilderClient // nil if no relay is configured
+	validatorMonitor    monitor.ValidatorMonitor
 
 	// services
 	syncCommitteeMessagesService     services.SyncCommitteeMessagesService
@@ -118,6 +122,8 @@ func NewApiHandler(
 	voluntaryExitService services.VoluntaryExitService,
 	blsToExecutionChangeService services.BLSToExecutionChangeService,
 	proposerSlashingService services.ProposerSlashingService,
+	builderClient builder.BuilderClient,
+	validatorMonitor monitor.ValidatorMonitor,
 ) *ApiHandler {
 	blobBundles, err := lru.New[common.Bytes48, BlobBundle]("blobs", maxBlobBundleCacheSize)
 	if err != nil {
@@ -158,6 +164,8 @@ func NewApiHandler(
 		voluntaryExitService:             voluntaryExitService,
 		blsToExecutionChangeService:      blsToExecutionChangeService,
 		proposerSlashingService:          proposerSlashingService,
+		builderClient:                    builderClient,
+		validatorMonitor:                 validatorMonitor,
 	}
 }
 
@@ -176,6 +184,7 @@ func (a *ApiHandler) init() {
 		r.Route("/lighthouse", func(r chi.Router) {
 			r.Get("/validator_inclusion/{epoch}/global", beaconhttp.HandleEndpointFunc(a.GetLighthouseValidatorInclusionGlobal))
 			r.Get("/validator_inclusion/{epoch}/{validator_id}", beaconhttp.HandleEndpointFunc(a.GetLighthouseValidatorInclusion))
+			r.Post("/ui/validator_metrics", beaconhttp.HandleEndpointFunc(a.PostLighthouseUiValidatorMetrics))
 		})
 	}
 	r.Route("/eth", func(r chi.Router) {
@@ -193,6 +202,10 @@ func (a *ApiHandler) init() {
 					r.Get("/peer_count", a.GetEthV1NodePeerCount)
 					r.Get("/peers", a.GetEthV1NodePeersInfos)
 					r.Get("/peers/{peer_id}", a.GetEthV1NodePeerInfos)
+					r.Post("/peers/{peer_id}/ban", a.PostEthV1NodePeerBan)
+					r.Delete("/peers/{peer_id}/ban", a.DeleteEthV1NodePeerBan)
+					r.Post("/peers/{peer_id}/graylist", a.PostEthV1NodePeerGraylist)
+					r.Delete("/peers/{peer_id}/graylist", a.DeleteEthV1NodePeerGraylist)
 					r.Get("/identity", a.GetEthV1NodeIdentity)
 					r.Get("/syncing", a.GetEthV1NodeSyncing)
 				})
@@ -227,6 +240,7 @@ func (a *ApiHandler) init() {
 					})
 					r.Get("/genesis", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconGenesis))
 					r.Get("/blinded_blocks/{block_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BlindedBlock))
+					r.Post("/blinded_blocks", a.PostEthV1BlindedBlocks)
 					r.Route("/pool", func(r chi.Router) {
 						r.Get("/voluntary_exits", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconPoolVoluntaryExits))
 						r.Post("/voluntary_exits", a.PostEthV1BeaconPoolVoluntaryExits)
@@ -256,7 +270,9 @@ func (a *ApiHandler) init() {
 							r.Get("/root", beaconhttp.HandleEndpointFunc(a.getStateRoot))
 							r.Get("/fork", beaconhttp.HandleEndpointFunc(a.getStateFork))
 							r.Get("/validators", a.GetEthV1BeaconStatesValidators)
+							r.Post("/validators", a.PostEthV1BeaconStatesValidators)
 							r.Get("/validator_balances", a.GetEthV1BeaconValidatorsBalances)
+							r.Post("/validator_balances", a.PostEthV1BeaconValidatorsBalances)
 							r.Get("/validators/{validator_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconStatesValidator))
 						})
 					})
@@ -269,7 +285,7 @@ func (a *ApiHandler) init() {
 						r.Get("/proposer/{epoch}", beaconhttp.HandleEndpointFunc(a.getDutiesProposer))
 						r.Post("/sync/{epoch}", beaconhttp.HandleEndpointFunc(a.getSyncDuties))
 					})
-					r.Get("/blinded_blocks/{slot}", http.NotFound)
+					r.Get("/blinded_blocks/{slot}", beaconhttp.HandleEndpointFunc(a.GetEthV1ValidatorBlindedBlock))
 					r.Get("/attestation_data", beaconhttp.HandleEndpointFunc(a.GetEthV1ValidatorAttestationData))
 					r.Get("/aggregate_attestation", beaconhttp.HandleEndpointFunc(a.GetEthV1ValidatorAggregateAttestation))
 					r.Post("/aggregate_and_proofs", a.PostEthV1ValidatorAggregatesAndProof)
@@ -278,6 +294,7 @@ func (a *ApiHandler) init() {
 					r.Get("/sync_committee_contribution", beaconhttp.HandleEndpointFunc(a.GetEthV1ValidatorSyncCommitteeContribution))
 					r.Post("/contribution_and_proofs", a.PostEthV1ValidatorContributionsAndProofs)
 					r.Post("/prepare_beacon_proposer", a.PostEthV1ValidatorPrepareBeaconProposal)
+					r.Post("/register_validator", a.PostEthV1ValidatorRegisterValidator)
 					r.Post("/liveness/{epoch}", beaconhttp.HandleEndpointFunc(a.liveness))
 				})
 			}
@@ -296,11 +313,12 @@ func (a *ApiHandler) init() {
 				r.Route("/beacon", func(r chi.Router) {
 					r.Get("/blocks/{block_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconBlock))
 					r.Post("/blocks", a.PostEthV2BeaconBlocks)
+					r.Post("/blinded_blocks", a.PostEthV2BlindedBlocks)
 				})
 			}
 			if a.routerCfg.Validator {
 				r.Route("/validator", func(r chi.Router) {
-					r.Post("/blocks/{slot}", http.NotFound)
+					r.Get("/blocks/{slot}", beaconhttp.HandleEndpointFunc(a.GetEthV2ValidatorBlock))
 				})
 			}
 		})
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -323,3 +325,65 @@ func (a *ApiHandler) computeLighthouseValidatorInclusion(idx int, prevEpoch, epo
 		IsPreviousEpochHeadAttester:      prevFlags.HasFlag(int(a.beaconChainCfg.TimelyHeadFlagIndex)),
 	}
 }
+
+type LighthouseValidatorMetrics struct {
+	AttestationHits                    uint64  `json:"attestation_hits"`
+	AttestationMisses                  uint64  `json:"attestation_misses"`
+	AttestationHitPercentage           float64 `json:"attestation_hit_percentage"`
+	AttestationHeadHits                uint64  `json:"attestation_head_hits"`
+	AttestationHeadMisses              uint64  `json:"attestation_head_misses"`
+	AttestationHeadHitPercentage       float64 `json:"attestation_head_hit_percentage"`
+	AttestationTargetHits              uint64  `json:"attestation_target_hits"`
+	AttestationTargetMisses            uint64  `json:"attestation_target_misses"`
+	AttestationTargetHitPercentage     float64 `json:"attestation_target_hit_percentage"`
+	LatestAttestationInclusionDistance uint64  `json:"latest_attestation_inclusion_distance"`
+	// Caplin extensions
+	SyncCommitteeHits          uint64  `json:"sync_committee_hits"`
+	SyncCommitteeMisses        uint64  `json:"sync_committee_misses"`
+	SyncCommitteeHitPercentage float64 `json:"sync_committee_hit_percentage"`
+	Proposals                  uint64  `json:"proposals"`
+	MissedProposals            uint64  `json:"missed_proposals"`
+}
+
+func hitPercentage(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) * 100 / float64(hits+misses)
+}
+
+// PostLighthouseUiValidatorMetrics - performance of validators tracked by the validator monitor, untracked validators
+// are left out.
+func (a *ApiHandler) PostLighthouseUiValidatorMetrics(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	req := struct {
+		Indices []uint64 `json:"indices"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	validators := make(map[string]LighthouseValidatorMetrics, len(req.Indices))
+	for _, vid := range req.Indices {
+		p, ok := a.validatorMonitor.ValidatorPerformance(vid)
+		if !ok {
+			continue
+		}
+		validators[strconv.FormatUint(vid, 10)] = LighthouseValidatorMetrics{
+			AttestationHits:                    p.AttestationHits,
+			AttestationMisses:                  p.AttestationMisses,
+			AttestationHitPercentage:           hitPercentage(p.AttestationHits, p.AttestationMisses),
+			AttestationHeadHits:                p.AttestationHeadHits,
+			AttestationHeadMisses:              p.AttestationHeadMisses,
+			AttestationHeadHitPercentage:       hitPercentage(p.AttestationHeadHits, p.AttestationHeadMisses),
+			AttestationTargetHits:              p.AttestationTargetHits,
+			AttestationTargetMisses:            p.AttestationTargetMisses,
+			AttestationTargetHitPercentage:     hitPercentage(p.AttestationTargetHits, p.AttestationTargetMisses),
+			LatestAttestationInclusionDistance: p.LatestAttestationInclusionDistance,
+			SyncCommitteeHits:                  p.SyncCommitteeHits,
+			SyncCommitteeMisses:                p.SyncCommitteeMisses,
+			SyncCommitteeHitPercentage:         hitPercentage(p.SyncCommitteeHits, p.SyncCommitteeMisses),
+			Proposals:                          p.Proposals,
+			MissedProposals:                    p.MissedProposals,
+		}
+	}
+	return newBeaconResponse(map[string]any{"validators": validators}), nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 
 	sentinel "github.com/ledgerwatch/erigon-lib/gointerfaces/sentinelproto"
 	"github.com/ledgerwatch/erigon/cl/beacon/beaconhttp"
+	"google.golang.org/grpc"
 )
 
 /*
@@ -182,3 +184,35 @@ func (a *ApiHandler) GetEthV1NodeSyncing(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// Erigon specific peer administration, banned peers are disconnected and refused, graylisted peers stay connected but
+// their gossip is ignored.
+
+func (a *ApiHandler) PostEthV1NodePeerBan(w http.ResponseWriter, r *http.Request) {
+	a.updatePeer(w, r, a.sentinel.BanPeer)
+}
+
+func (a *ApiHandler) DeleteEthV1NodePeerBan(w http.ResponseWriter, r *http.Request) {
+	a.updatePeer(w, r, a.sentinel.UnbanPeer)
+}
+
+func (a *ApiHandler) PostEthV1NodePeerGraylist(w http.ResponseWriter, r *http.Request) {
+	a.updatePeer(w, r, a.sentinel.PenalizePeer)
+}
+
+func (a *ApiHandler) DeleteEthV1NodePeerGraylist(w http.ResponseWriter, r *http.Request) {
+	a.updatePeer(w, r, a.sentinel.RewardPeer)
+}
+
+func (a *ApiHandler) updatePeer(w http.ResponseWriter, r *http.Request, update func(context.Context, *sentinel.Peer, ...grpc.CallOption) (*sentinel.EmptyMessage, error)) {
+	pid, err := beaconhttp.StringFromRequest(r, "peer_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := update(r.Context(), &sentinel.Peer{Pid: pid}); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
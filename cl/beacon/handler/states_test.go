@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ledgerwatch/erigon/cl/clparams"
@@ -503,3 +504,27 @@ func TestGetRandao(t *testing.T) {
 		})
 	}
 }
+
+func TestPostValidatorBalancesChunked(t *testing.T) {
+	_, blocks, _, _, postState, handler, _, sm, fcu, _ := setupTestingHandler(t, clparams.BellatrixVersion, log.Root())
+
+	var err error
+	fcu.HeadVal, err = blocks[len(blocks)-1].Block.HashSSZ()
+	require.NoError(t, err)
+	fcu.HeadSlotVal = blocks[len(blocks)-1].Block.Slot
+	require.NoError(t, sm.OnHeadState(postState))
+
+	get := httptest.NewRecorder()
+	handler.mux.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/eth/v1/beacon/states/head/validator_balances", nil))
+	require.Equal(t, http.StatusOK, get.Code)
+
+	// The length of a chunked body is unknown, an empty one means all the validators
+	for _, body := range []string{"", "[]"} {
+		req := httptest.NewRequest(http.MethodPost, "/eth/v1/beacon/states/head/validator_balances", struct{ io.Reader }{strings.NewReader(body)})
+		require.Equal(t, int64(-1), req.ContentLength)
+		post := httptest.NewRecorder()
+		handler.mux.ServeHTTP(post, req)
+		require.Equal(t, http.StatusOK, post.Code)
+		require.JSONEq(t, get.Body.String(), post.Body.String())
+	}
+}
@@ -87,6 +87,7 @@ func (a *ApiHandler) PostEthV1ValidatorBeaconCommitteeSubscription(w http.Respon
 		return
 	}
 	for _, sub := range req {
+		a.validatorMonitor.AutoObserveValidator(sub.ValidatorIndex)
 		if err := a.committeeSub.AddAttestationSubscription(context.Background(), sub); err != nil {
 			log.Error("failed to add attestation subscription", "err", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -21,6 +21,7 @@ import (
 	"github.com/ledgerwatch/erigon/cl/clparams/initial_state"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/monitor"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	state_accessors "github.com/ledgerwatch/erigon/cl/persistence/state"
 	"github.com/ledgerwatch/erigon/cl/persistence/state/historical_states_reader"
@@ -148,6 +149,8 @@ func setupTestingHandler(t *testing.T, v clparams.StateVersion, logger log.Logge
 		voluntaryExitService,
 		blsToExecutionChangeService,
 		proposerSlashingService,
+		nil,
+		monitor.NewValidatorMonitor(&bcfg, []uint64{1}, false),
 	) // TODO: add tests
 	h.Init()
 	return
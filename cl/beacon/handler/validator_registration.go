@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
 )
 
 type ValidatorPreparationPayload struct {
@@ -25,3 +26,24 @@ func (a *ApiHandler) PostEthV1ValidatorPrepareBeaconProposal(w http.ResponseWrit
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func (a *ApiHandler) PostEthV1ValidatorRegisterValidator(w http.ResponseWriter, r *http.Request) {
+	req := []*cltypes.SignedValidatorRegistration{}
+	// decode request with json
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if a.builderClient == nil {
+		// no relay configured, registrations are useless
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := a.builderClient.RegisterValidator(r.Context(), req); err != nil {
+		a.logger.Warn("[Caplin] Failed to register validators with builder", "count", len(req), "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	a.logger.Debug("[Caplin] Registered validators with builder", "count", len(req))
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,13 +2,17 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/holiman/uint256"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/beacon/builder"
 	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/require"
 )
@@ -42,3 +46,51 @@ func TestPostEthV1ValidatorPreparation(t *testing.T) {
 	require.Equal(t, libcommon.Address{1}, a1)
 	require.Equal(t, libcommon.Address{2}, a2)
 }
+
+type registrationsBuilderClient struct {
+	builder.BuilderClient
+	registrations []*cltypes.SignedValidatorRegistration
+}
+
+func (b *registrationsBuilderClient) RegisterValidator(ctx context.Context, registrations []*cltypes.SignedValidatorRegistration) error {
+	b.registrations = append(b.registrations, registrations...)
+	return nil
+}
+
+func TestPostEthV1ValidatorRegisterValidator(t *testing.T) {
+	_, _, _, _, _, handler, _, _, _, _ := setupTestingHandler(t, clparams.BellatrixVersion, log.Root())
+	builderClient := &registrationsBuilderClient{}
+	handler.builderClient = builderClient
+	server := httptest.NewServer(handler.mux)
+	defer server.Close()
+	req := []*cltypes.SignedValidatorRegistration{
+		{
+			Message: &cltypes.ValidatorRegistration{
+				FeeRecipient: libcommon.Address{1},
+				GasLimit:     30_000_000,
+				Timestamp:    100,
+				PubKey:       libcommon.Bytes48{2},
+			},
+			Signature: libcommon.Bytes96{3},
+		},
+	}
+
+	reqByte, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/eth/v1/validator/register_validator", "application/json", bytes.NewBuffer(reqByte))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, req, builderClient.registrations)
+}
+
+func TestIsBuilderBidPreferred(t *testing.T) {
+	require.True(t, isBuilderBidPreferred(uint256.NewInt(101), 100, 100))
+	require.False(t, isBuilderBidPreferred(uint256.NewInt(100), 100, 100))
+	// builder has to pay twice as much as local payload
+	require.False(t, isBuilderBidPreferred(uint256.NewInt(150), 100, 50))
+	require.True(t, isBuilderBidPreferred(uint256.NewInt(202), 100, 50))
+	// max boost factor prefers builder
+	require.True(t, isBuilderBidPreferred(uint256.NewInt(1), 1<<40, ^uint64(0)))
+}
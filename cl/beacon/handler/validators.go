@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"slices"
@@ -194,36 +196,56 @@ func checkValidValidatorId(s string) (bool, error) {
 }
 
 func (a *ApiHandler) GetEthV1BeaconStatesValidators(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	tx, err := a.indiciesDB.BeginRo(ctx)
+	queryFilters, err := beaconhttp.StringListFromQueryParams(r, "status")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer tx.Rollback()
 
-	blockId, err := beaconhttp.StateIdFromRequest(r)
+	validatorIds, err := beaconhttp.StringListFromQueryParams(r, "id")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	a.writeValidators(w, r, validatorIds, queryFilters)
+}
 
-	blockRoot, httpStatus, err := a.blockRootFromStateId(ctx, tx, blockId)
+// validatorsRequest is the body of POST /eth/v1/beacon/states/{state_id}/validators, the same filters as query
+// parameters of GET, without the URL length limit on ids
+type validatorsRequest struct {
+	Ids      []string `json:"ids"`
+	Statuses []string `json:"statuses"`
+}
+
+func (a *ApiHandler) PostEthV1BeaconStatesValidators(w http.ResponseWriter, r *http.Request) {
+	var req validatorsRequest
+	// Chunked requests don't tell the length of the body, an empty one is only known once read
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.writeValidators(w, r, req.Ids, req.Statuses)
+}
+
+func (a *ApiHandler) writeValidators(w http.ResponseWriter, r *http.Request, validatorIds []string, queryFilters []string) {
+	ctx := r.Context()
+
+	tx, err := a.indiciesDB.BeginRo(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), httpStatus)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	defer tx.Rollback()
 
-	queryFilters, err := beaconhttp.StringListFromQueryParams(r, "status")
+	blockId, err := beaconhttp.StateIdFromRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	validatorIds, err := beaconhttp.StringListFromQueryParams(r, "id")
+	blockRoot, httpStatus, err := a.blockRootFromStateId(ctx, tx, blockId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), httpStatus)
 		return
 	}
 
@@ -428,6 +450,25 @@ func (a *ApiHandler) GetEthV1BeaconStatesValidator(w http.ResponseWriter, r *htt
 }
 
 func (a *ApiHandler) GetEthV1BeaconValidatorsBalances(w http.ResponseWriter, r *http.Request) {
+	validatorIds, err := beaconhttp.StringListFromQueryParams(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.writeValidatorsBalances(w, r, validatorIds)
+}
+
+// PostEthV1BeaconValidatorsBalances - body is the list of validator ids, empty list or no body means all validators
+func (a *ApiHandler) PostEthV1BeaconValidatorsBalances(w http.ResponseWriter, r *http.Request) {
+	var validatorIds []string
+	if err := json.NewDecoder(r.Body).Decode(&validatorIds); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.writeValidatorsBalances(w, r, validatorIds)
+}
+
+func (a *ApiHandler) writeValidatorsBalances(w http.ResponseWriter, r *http.Request, validatorIds []string) {
 	ctx := r.Context()
 
 	tx, err := a.indiciesDB.BeginRo(ctx)
@@ -449,12 +490,6 @@ func (a *ApiHandler) GetEthV1BeaconValidatorsBalances(w http.ResponseWriter, r *
 		return
 	}
 
-	validatorIds, err := beaconhttp.StringListFromQueryParams(r, "id")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
 	if len(validatorIds) > maxValidatorsLookupFilter {
 		http.Error(w, fmt.Errorf("too many validators requested").Error(), http.StatusBadRequest)
 		return
@@ -493,8 +528,8 @@ func (a *ApiHandler) GetEthV1BeaconValidatorsBalances(w http.ResponseWriter, r *
 			return
 		}
 		if balances == nil {
-
 			http.Error(w, fmt.Errorf("validators not found, node may node be running in archivial node").Error(), http.StatusNotFound)
+			return
 		}
 		responseValidatorsBalances(w, filterIndicies, stateEpoch, balances, true)
 		return
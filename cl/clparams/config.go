@@ -34,6 +34,64 @@ type CaplinConfig struct {
 	BlobBackfilling     bool
 	BlobPruningDisabled bool
 	Archive             bool
+	// Serve light client data over gossip
+	LightClientServer bool
+	// Builder API relay for block production, disabled if empty
+	MevRelayUrl string
+
+	// Checkpoint sync providers (trusted endpoints of the network if empty), the finalized checkpoint must be confirmed
+	// by CheckpointSyncQuorum of them (0 is majority) and match CheckpointSyncRoot if it is set
+	CheckpointSyncUrls   []string
+	CheckpointSyncQuorum uint64
+	CheckpointSyncRoot   libcommon.Hash
+
+	// Embedded validator client, enabled by ValidatorKeystoreDir
+	ValidatorKeystoreDir        string
+	ValidatorPasswordFile       string
+	ValidatorFeeRecipient       libcommon.Address
+	ValidatorDoppelgangerEpochs uint64
+
+	// Gossipsub peer and topic scoring
+	GossipScoring GossipScoringConfig
+
+	// Validator monitor, tracking duties of ValidatorMonitorIndices and of validators of connected validator clients
+	// if ValidatorMonitorAuto is set
+	ValidatorMonitorIndices []uint64
+	ValidatorMonitorAuto    bool
+}
+
+// GossipScoringConfig - thresholds of gossipsub peer scoring. Peers scoring below GossipThreshold get no gossip from us,
+// below PublishThreshold get none of our published messages and below GraylistThreshold are ignored altogether.
+type GossipScoringConfig struct {
+	Disabled                    bool
+	GossipThreshold             float64
+	PublishThreshold            float64
+	GraylistThreshold           float64
+	IPColocationFactorThreshold int
+}
+
+var DefaultGossipScoringConfig = GossipScoringConfig{
+	GossipThreshold:             -4000,
+	PublishThreshold:            -8000,
+	GraylistThreshold:           -16000,
+	IPColocationFactorThreshold: 10,
+}
+
+// Validate - checks that thresholds are not positive and ordered as gossipsub expects them
+func (c GossipScoringConfig) Validate() error {
+	if c.GossipThreshold > 0 {
+		return fmt.Errorf("gossip threshold %v must not be positive", c.GossipThreshold)
+	}
+	if c.PublishThreshold > c.GossipThreshold {
+		return fmt.Errorf("publish threshold %v must not be greater than gossip threshold %v", c.PublishThreshold, c.GossipThreshold)
+	}
+	if c.GraylistThreshold > c.PublishThreshold {
+		return fmt.Errorf("graylist threshold %v must not be greater than publish threshold %v", c.GraylistThreshold, c.PublishThreshold)
+	}
+	if c.IPColocationFactorThreshold < 1 {
+		return fmt.Errorf("ip colocation threshold %d must be at least 1", c.IPColocationFactorThreshold)
+	}
+	return nil
 }
 
 type NetworkType int
@@ -1050,6 +1108,19 @@ func GetCheckpointSyncEndpoint(net NetworkType) string {
 	return checkpoints[n.Int64()]
 }
 
+// GetCheckpointSyncEndpoints returns all trusted checkpoint sync endpoints of the network in random order.
+func GetCheckpointSyncEndpoints(net NetworkType) []string {
+	checkpoints := append([]string{}, CheckpointSyncEndpoints[net]...)
+	for i := len(checkpoints) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			panic(err)
+		}
+		checkpoints[i], checkpoints[n.Int64()] = checkpoints[n.Int64()], checkpoints[i]
+	}
+	return checkpoints
+}
+
 // Check if chain with a specific ID is supported or not
 // 1 is Ethereum Mainnet
 // 5 is Goerli Testnet
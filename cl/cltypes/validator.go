@@ -133,3 +133,65 @@ func (e *SignedVoluntaryExit) HashSSZ() ([32]byte, error) {
 func (e *SignedVoluntaryExit) EncodingSizeSSZ() int {
 	return 96 + e.VoluntaryExit.EncodingSizeSSZ()
 }
+
+// ValidatorRegistration is the builder API registration of a validator's fee recipient and gas limit preferences.
+type ValidatorRegistration struct {
+	FeeRecipient libcommon.Address `json:"fee_recipient"`
+	GasLimit     uint64            `json:"gas_limit,string"`
+	Timestamp    uint64            `json:"timestamp,string"`
+	PubKey       libcommon.Bytes48 `json:"pubkey"`
+}
+
+func (v *ValidatorRegistration) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, v.FeeRecipient[:], v.GasLimit, v.Timestamp, v.PubKey[:])
+}
+
+func (v *ValidatorRegistration) DecodeSSZ(buf []byte, version int) error {
+	return ssz2.UnmarshalSSZ(buf, version, v.FeeRecipient[:], &v.GasLimit, &v.Timestamp, v.PubKey[:])
+}
+
+func (v *ValidatorRegistration) HashSSZ() ([32]byte, error) {
+	return merkle_tree.HashTreeRoot(v.FeeRecipient[:], v.GasLimit, v.Timestamp, v.PubKey[:])
+}
+
+func (*ValidatorRegistration) EncodingSizeSSZ() int {
+	return 84
+}
+
+func (*ValidatorRegistration) Static() bool {
+	return true
+}
+
+func (*ValidatorRegistration) Clone() clonable.Clonable {
+	return &ValidatorRegistration{}
+}
+
+type SignedValidatorRegistration struct {
+	Message   *ValidatorRegistration `json:"message"`
+	Signature libcommon.Bytes96      `json:"signature"`
+}
+
+func (s *SignedValidatorRegistration) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, s.Message, s.Signature[:])
+}
+
+func (s *SignedValidatorRegistration) DecodeSSZ(buf []byte, version int) error {
+	s.Message = new(ValidatorRegistration)
+	return ssz2.UnmarshalSSZ(buf, version, s.Message, s.Signature[:])
+}
+
+func (s *SignedValidatorRegistration) HashSSZ() ([32]byte, error) {
+	return merkle_tree.HashTreeRoot(s.Message, s.Signature[:])
+}
+
+func (*SignedValidatorRegistration) EncodingSizeSSZ() int {
+	return 84 + 96
+}
+
+func (*SignedValidatorRegistration) Static() bool {
+	return true
+}
+
+func (*SignedValidatorRegistration) Clone() clonable.Clonable {
+	return &SignedValidatorRegistration{}
+}
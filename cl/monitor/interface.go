@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
+)
+
+type ValidatorMonitor interface {
+	// ObserveValidator starts tracking the duties of the validator.
+	ObserveValidator(vid uint64)
+	// AutoObserveValidator tracks the validator only if validators of connected validator clients are tracked.
+	AutoObserveValidator(vid uint64)
+	// OnNewBlock accounts the duties fulfilled or missed by the block, state is the post-state of the block.
+	OnNewBlock(state *state.CachingBeaconState, block *cltypes.BeaconBlock) error
+	// ValidatorPerformance returns the performance of the validator since it's tracked, false if it is not tracked.
+	ValidatorPerformance(vid uint64) (ValidatorPerformance, bool)
+}
+
+type ValidatorPerformance struct {
+	AttestationHits                    uint64
+	AttestationMisses                  uint64
+	AttestationHeadHits                uint64
+	AttestationHeadMisses              uint64
+	AttestationTargetHits              uint64
+	AttestationTargetMisses            uint64
+	LatestAttestationInclusionDistance uint64
+	SyncCommitteeHits                  uint64
+	SyncCommitteeMisses                uint64
+	Proposals                          uint64
+	MissedProposals                    uint64
+}
+
+type dummyValidatorMonitor struct{}
+
+// NewDummyValidatorMonitor - monitor tracking nothing, used when validator monitoring is disabled
+func NewDummyValidatorMonitor() ValidatorMonitor {
+	return dummyValidatorMonitor{}
+}
+
+func (dummyValidatorMonitor) ObserveValidator(uint64)     {}
+func (dummyValidatorMonitor) AutoObserveValidator(uint64) {}
+
+func (dummyValidatorMonitor) OnNewBlock(*state.CachingBeaconState, *cltypes.BeaconBlock) error {
+	return nil
+}
+
+func (dummyValidatorMonitor) ValidatorPerformance(uint64) (ValidatorPerformance, bool) {
+	return ValidatorPerformance{}, false
+}
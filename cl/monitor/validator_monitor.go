@@ -0,0 +1,260 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
+	"github.com/ledgerwatch/erigon/cl/utils"
+)
+
+var _ ValidatorMonitor = (*validatorMonitorImpl)(nil)
+
+type validatorData struct {
+	ValidatorPerformance
+	pubKey *libcommon.Bytes48
+	// smallest inclusion distance of the attestations of the validator by target epoch
+	inclusions map[uint64]uint64
+	// previous epoch duty as seen by the latest processed block, it is final once the next epoch starts
+	activePreviousEpoch bool
+	previousEpochFlags  cltypes.ParticipationFlags
+	// participation flags exist from altair onwards
+	hasPreviousEpochFlags bool
+}
+
+// validatorMonitorImpl - follows duties of tracked validators through imported blocks. Attestations of an epoch are
+// judged once the inclusion window of the epoch is over, that is when the first block of the epoch after next shows up.
+type validatorMonitorImpl struct {
+	beaconCfg *clparams.BeaconChainConfig
+	auto      bool
+
+	validators map[uint64]*validatorData
+	// latest processed block
+	lastSlot, lastEpoch uint64
+	initialized         bool
+
+	mu sync.Mutex
+}
+
+// NewValidatorMonitor - tracks validators of indices, plus validators of connected validator clients if auto is set
+func NewValidatorMonitor(beaconCfg *clparams.BeaconChainConfig, indices []uint64, auto bool) ValidatorMonitor {
+	m := &validatorMonitorImpl{
+		beaconCfg:  beaconCfg,
+		auto:       auto,
+		validators: make(map[uint64]*validatorData),
+	}
+	for _, vid := range indices {
+		m.ObserveValidator(vid)
+	}
+	return m
+}
+
+func (m *validatorMonitorImpl) ObserveValidator(vid uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.validators[vid]; ok {
+		return
+	}
+	m.validators[vid] = &validatorData{inclusions: make(map[uint64]uint64)}
+}
+
+func (m *validatorMonitorImpl) AutoObserveValidator(vid uint64) {
+	if m.auto {
+		m.ObserveValidator(vid)
+	}
+}
+
+func (m *validatorMonitorImpl) ValidatorPerformance(vid uint64) (ValidatorPerformance, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.validators[vid]
+	if !ok {
+		return ValidatorPerformance{}, false
+	}
+	return v.ValidatorPerformance, true
+}
+
+func (m *validatorMonitorImpl) OnNewBlock(s *state.CachingBeaconState, block *cltypes.BeaconBlock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.validators) == 0 {
+		return nil
+	}
+	epoch := block.Slot / m.beaconCfg.SlotsPerEpoch
+	if m.initialized && epoch > m.lastEpoch && m.lastEpoch > 0 {
+		m.judgeAttestations(m.lastEpoch - 1)
+	}
+	if err := m.processAttestations(s, block); err != nil {
+		return err
+	}
+	if err := m.processProposals(s, block, epoch); err != nil {
+		return err
+	}
+	if block.Version() >= clparams.AltairVersion {
+		if err := m.processSyncAggregate(s, block); err != nil {
+			return err
+		}
+	}
+	// forks and late blocks do not move the monitor backwards
+	if m.initialized && block.Slot < m.lastSlot {
+		return nil
+	}
+	m.snapshotPreviousEpoch(s, epoch)
+	m.lastSlot, m.lastEpoch, m.initialized = block.Slot, epoch, true
+	return nil
+}
+
+// judgeAttestations - accounts attestation duties of the epoch, assumes the lock is held
+func (m *validatorMonitorImpl) judgeAttestations(epoch uint64) {
+	for vid, v := range m.validators {
+		distance, included := v.inclusions[epoch]
+		for target := range v.inclusions {
+			if target <= epoch {
+				delete(v.inclusions, target)
+			}
+		}
+		if !v.activePreviousEpoch {
+			continue
+		}
+		if included {
+			v.AttestationHits++
+			v.LatestAttestationInclusionDistance = distance
+			incValidatorCounter("validator_attestation_hits", vid)
+			metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_inclusion_distance{validator="%d"}`, vid)).SetUint64(distance)
+		} else {
+			v.AttestationMisses++
+			incValidatorCounter("validator_attestation_misses", vid)
+		}
+		if !v.hasPreviousEpochFlags {
+			continue
+		}
+		if v.previousEpochFlags.HasFlag(int(m.beaconCfg.TimelyTargetFlagIndex)) {
+			v.AttestationTargetHits++
+			incValidatorCounter("validator_attestation_target_hits", vid)
+		} else {
+			v.AttestationTargetMisses++
+			incValidatorCounter("validator_attestation_target_misses", vid)
+		}
+		if v.previousEpochFlags.HasFlag(int(m.beaconCfg.TimelyHeadFlagIndex)) {
+			v.AttestationHeadHits++
+			incValidatorCounter("validator_attestation_head_hits", vid)
+		} else {
+			v.AttestationHeadMisses++
+			incValidatorCounter("validator_attestation_head_misses", vid)
+		}
+	}
+}
+
+// processAttestations - records inclusion distances of attestations of tracked validators, assumes the lock is held
+func (m *validatorMonitorImpl) processAttestations(s *state.CachingBeaconState, block *cltypes.BeaconBlock) error {
+	var err error
+	block.Body.Attestations.Range(func(_ int, att *solid.Attestation, _ int) bool {
+		data := att.AttestantionData()
+		var attesters []uint64
+		if attesters, err = s.GetAttestingIndicies(data, att.AggregationBits(), true); err != nil {
+			return false
+		}
+		for _, vid := range attesters {
+			v, ok := m.validators[vid]
+			if !ok {
+				continue
+			}
+			distance := block.Slot - data.Slot()
+			target := data.Target().Epoch()
+			if prev, ok := v.inclusions[target]; !ok || distance < prev {
+				v.inclusions[target] = distance
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// processProposals - accounts the block proposal and the slots skipped since the previous block of the epoch, assumes
+// the lock is held
+func (m *validatorMonitorImpl) processProposals(s *state.CachingBeaconState, block *cltypes.BeaconBlock, epoch uint64) error {
+	if v, ok := m.validators[block.ProposerIndex]; ok {
+		v.Proposals++
+		incValidatorCounter("validator_proposals", block.ProposerIndex)
+	}
+	// slots before the first processed block are unknown
+	if !m.initialized || block.Slot <= m.lastSlot {
+		return nil
+	}
+	from := epoch * m.beaconCfg.SlotsPerEpoch
+	if m.lastSlot+1 > from {
+		from = m.lastSlot + 1
+	}
+	for slot := from; slot < block.Slot; slot++ {
+		proposer, err := s.GetBeaconProposerIndexForSlot(slot)
+		if err != nil {
+			return err
+		}
+		if v, ok := m.validators[proposer]; ok {
+			v.MissedProposals++
+			incValidatorCounter("validator_missed_proposals", proposer)
+		}
+	}
+	return nil
+}
+
+// processSyncAggregate - accounts sync committee signatures of the block, assumes the lock is held
+func (m *validatorMonitorImpl) processSyncAggregate(s *state.CachingBeaconState, block *cltypes.BeaconBlock) error {
+	members := make(map[libcommon.Bytes48]uint64)
+	for vid, v := range m.validators {
+		if v.pubKey == nil {
+			if vid >= uint64(s.ValidatorLength()) {
+				continue
+			}
+			pubKey, err := s.ValidatorPublicKey(int(vid))
+			if err != nil {
+				return err
+			}
+			v.pubKey = &pubKey
+		}
+		members[*v.pubKey] = vid
+	}
+	bits := block.Body.SyncAggregate.SyncCommiteeBits
+	for i, pubKey := range s.CurrentSyncCommittee().GetCommittee() {
+		vid, ok := members[pubKey]
+		if !ok {
+			continue
+		}
+		if utils.IsBitOn(bits[:], i) {
+			m.validators[vid].SyncCommitteeHits++
+			incValidatorCounter("validator_sync_committee_hits", vid)
+		} else {
+			m.validators[vid].SyncCommitteeMisses++
+			incValidatorCounter("validator_sync_committee_misses", vid)
+		}
+	}
+	return nil
+}
+
+// snapshotPreviousEpoch - saves the previous epoch duties of tracked validators from the state, assumes the lock is held
+func (m *validatorMonitorImpl) snapshotPreviousEpoch(s *state.CachingBeaconState, epoch uint64) {
+	for vid, v := range m.validators {
+		v.activePreviousEpoch, v.hasPreviousEpochFlags = false, false
+		if epoch == 0 || vid >= uint64(s.ValidatorLength()) {
+			continue
+		}
+		validator, err := s.ValidatorForValidatorIndex(int(vid))
+		if err != nil {
+			continue
+		}
+		v.activePreviousEpoch = validator.Active(epoch - 1)
+		if s.Version() >= clparams.AltairVersion {
+			v.previousEpochFlags = s.EpochParticipationForValidatorIndex(false, int(vid))
+			v.hasPreviousEpochFlags = true
+		}
+	}
+}
+
+func incValidatorCounter(name string, vid uint64) {
+	metrics.GetOrCreateCounter(fmt.Sprintf(`%s{validator="%d"}`, name, vid)).Inc()
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cl/antiquary/tests"
+	"github.com/ledgerwatch/erigon/cl/transition"
+)
+
+func TestValidatorMonitor(t *testing.T) {
+	blocks, preState, _ := tests.GetBellatrixRandom()
+	cfg := preState.BeaconConfig()
+	indices := make([]uint64, preState.ValidatorLength())
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+	m := NewValidatorMonitor(cfg, indices, false)
+	m.AutoObserveValidator(uint64(len(indices))) // auto observation is disabled
+
+	s, err := preState.Copy()
+	require.NoError(t, err)
+	epochs := map[uint64]struct{}{}
+	for _, block := range blocks {
+		require.NoError(t, transition.TransitionState(s, block, nil, false))
+		require.NoError(t, m.OnNewBlock(s, block.Block))
+		epochs[block.Block.Slot/cfg.SlotsPerEpoch] = struct{}{}
+	}
+
+	var total ValidatorPerformance
+	for _, vid := range indices {
+		p, ok := m.ValidatorPerformance(vid)
+		require.True(t, ok)
+		total.AttestationHits += p.AttestationHits
+		total.AttestationMisses += p.AttestationMisses
+		total.AttestationTargetHits += p.AttestationTargetHits
+		total.AttestationTargetMisses += p.AttestationTargetMisses
+		total.AttestationHeadHits += p.AttestationHeadHits
+		total.AttestationHeadMisses += p.AttestationHeadMisses
+		total.SyncCommitteeHits += p.SyncCommitteeHits
+		total.SyncCommitteeMisses += p.SyncCommitteeMisses
+		total.Proposals += p.Proposals
+		total.MissedProposals += p.MissedProposals
+	}
+	_, ok := m.ValidatorPerformance(uint64(len(indices)))
+	require.False(t, ok)
+
+	require.Equal(t, uint64(len(blocks)), total.Proposals)
+	require.Equal(t, blocks[len(blocks)-1].Block.Slot-blocks[0].Block.Slot+1-uint64(len(blocks)), total.MissedProposals)
+	require.Equal(t, uint64(len(blocks)*len(s.CurrentSyncCommittee().GetCommittee())), total.SyncCommitteeHits+total.SyncCommitteeMisses)
+	// blocks of the fixture carry empty sync aggregates
+	require.Zero(t, total.SyncCommitteeHits)
+	// every epoch but the last one closes the inclusion window of the epoch before it
+	judged := uint64(len(epochs)-1) * uint64(len(indices))
+	require.Equal(t, judged, total.AttestationHits+total.AttestationMisses)
+	require.Equal(t, judged, total.AttestationTargetHits+total.AttestationTargetMisses)
+	require.Equal(t, judged, total.AttestationHeadHits+total.AttestationHeadMisses)
+	require.NotZero(t, total.AttestationHits)
+}
@@ -0,0 +1,122 @@
+package base_encoding
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ComputeCompressedSegmentsXorDiff - diff of new against old, both are split in segments by boundaries
+// ([0, end of first segment, ..., len]). Every segment of new is xored with the segment of old at the same position,
+// so growth of one segment (for example validators list of a beacon state) does not shift the data of the following
+// ones and unchanged data becomes zeroes, which compress well. The diff carries the boundaries, so applying it
+// does not require knowledge of the segments.
+func ComputeCompressedSegmentsXorDiff(w io.Writer, old, new []byte, oldSegments, newSegments []int) error {
+	if len(oldSegments) == 0 || len(newSegments) == 0 {
+		return fmt.Errorf("no segments")
+	}
+	compressor := compressorPool.Get().(*zstd.Encoder)
+	defer compressorPool.Put(compressor)
+	compressor.Reset(w)
+
+	temp := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(x int) error {
+		_, err := compressor.Write(temp[:binary.PutUvarint(temp, uint64(x))])
+		return err
+	}
+	// Header: number of segments, then for every segment of new: range of old it is xored with and its length
+	if err := writeUvarint(len(newSegments) - 1); err != nil {
+		return err
+	}
+	for i := 0; i < len(newSegments)-1; i++ {
+		oldFrom, oldTo := 0, 0
+		if i < len(oldSegments)-1 {
+			oldFrom, oldTo = oldSegments[i], oldSegments[i+1]
+		}
+		if oldFrom > oldTo || oldTo > len(old) || newSegments[i] > newSegments[i+1] || newSegments[i+1] > len(new) {
+			return fmt.Errorf("bad segment %d", i)
+		}
+		if err := writeUvarint(oldFrom); err != nil {
+			return err
+		}
+		if err := writeUvarint(oldTo); err != nil {
+			return err
+		}
+		if err := writeUvarint(newSegments[i+1] - newSegments[i]); err != nil {
+			return err
+		}
+	}
+
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buffer)
+	for i := 0; i < len(newSegments)-1; i++ {
+		segment := new[newSegments[i]:newSegments[i+1]]
+		var oldSegment []byte
+		if i < len(oldSegments)-1 {
+			oldSegment = old[oldSegments[i]:oldSegments[i+1]]
+		}
+		buffer.Reset()
+		buffer.Write(segment)
+		xored := buffer.Bytes()
+		for j := 0; j < len(xored) && j < len(oldSegment); j++ {
+			xored[j] ^= oldSegment[j]
+		}
+		if _, err := compressor.Write(xored); err != nil {
+			return err
+		}
+	}
+	return compressor.Close()
+}
+
+// ApplyCompressedSegmentsXorDiff - reconstructs new from old and the diff made by ComputeCompressedSegmentsXorDiff
+func ApplyCompressedSegmentsXorDiff(old, out []byte, diff []byte) ([]byte, error) {
+	out = out[:0]
+
+	decompressor, err := zstd.NewReader(bytes.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+	defer decompressor.Close()
+	reader := bufio.NewReader(decompressor)
+
+	segmentsCount, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	type segment struct {
+		oldFrom, oldTo, length uint64
+	}
+	segments := make([]segment, 0, segmentsCount)
+	for i := uint64(0); i < segmentsCount; i++ {
+		var s segment
+		if s.oldFrom, err = binary.ReadUvarint(reader); err != nil {
+			return nil, err
+		}
+		if s.oldTo, err = binary.ReadUvarint(reader); err != nil {
+			return nil, err
+		}
+		if s.length, err = binary.ReadUvarint(reader); err != nil {
+			return nil, err
+		}
+		if s.oldFrom > s.oldTo || s.oldTo > uint64(len(old)) {
+			return nil, fmt.Errorf("segment %d is out of old range", i)
+		}
+		segments = append(segments, s)
+	}
+	for _, s := range segments {
+		start := len(out)
+		out = append(out, make([]byte, s.length)...)
+		if _, err := io.ReadFull(reader, out[start:]); err != nil {
+			return nil, err
+		}
+		xored, oldSegment := out[start:], old[s.oldFrom:s.oldTo]
+		for j := 0; j < len(xored) && j < len(oldSegment); j++ {
+			xored[j] ^= oldSegment[j]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,30 @@
+package base_encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentsXorDiff(t *testing.T) {
+	old := append(bytes.Repeat([]byte{1}, 100), bytes.Repeat([]byte{2}, 50)...)
+	// first segment grows, second one shrinks and changes
+	new := append(bytes.Repeat([]byte{1}, 120), bytes.Repeat([]byte{3}, 30)...)
+	new[7] = 9
+
+	var w bytes.Buffer
+	require.NoError(t, ComputeCompressedSegmentsXorDiff(&w, old, new, []int{0, 100, 150}, []int{0, 120, 150}))
+	out, err := ApplyCompressedSegmentsXorDiff(old, nil, w.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, new, out)
+
+	// new has more segments than old
+	w.Reset()
+	require.NoError(t, ComputeCompressedSegmentsXorDiff(&w, old, new, []int{0, 150}, []int{0, 60, 120, 150}))
+	out, err = ApplyCompressedSegmentsXorDiff(old, out, w.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, new, out)
+
+	require.Error(t, ComputeCompressedSegmentsXorDiff(&w, old, new, []int{0, 200}, []int{0, 150}))
+}
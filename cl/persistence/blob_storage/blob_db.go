@@ -18,7 +18,9 @@ import (
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/persistence/base_encoding"
 	"github.com/ledgerwatch/erigon/cl/sentinel/communication/ssz_snappy"
+	"github.com/ledgerwatch/erigon/cl/utils"
 	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
 	"github.com/spf13/afero"
 )
@@ -32,6 +34,10 @@ type BlobStorage interface {
 	WriteStream(w io.Writer, slot uint64, blockRoot libcommon.Hash, idx uint64) error // Used for P2P networking
 	KzgCommitmentsCount(ctx context.Context, blockRoot libcommon.Hash) (uint32, error)
 	Prune() error
+	// Blobs archive: versioned hashes index of sidecars which are never pruned (sidecars in db and in snapshots)
+	ReadVersionedHashIndex(ctx context.Context, versionedHash libcommon.Hash) (slot, index uint64, found bool, err error)
+	WriteFrozenVersionedHashIndex(ctx context.Context, blobSidecars []*cltypes.BlobSidecar, indexedTo uint64) error
+	FrozenVersionedHashIndexProgress(ctx context.Context) (uint64, error)
 }
 
 type BlobStore struct {
@@ -57,6 +63,7 @@ func blobSidecarFilePath(slot, index uint64, blockRoot libcommon.Hash) (folderpa
 file system layout: <slot/subdivisionSlot>/<blockRoot>_<index>
 indicies:
 - <blockRoot> -> kzg_commitments_length // block
+- <versionedHash> -> slot + index // only if sidecars are not pruned
 */
 
 // WriteBlobSidecars writes the sidecars on the database. it assumes that all blobSidecars are for the same blockRoot and we have all of them.
@@ -93,9 +100,80 @@ func (bs *BlobStore) WriteBlobSidecars(ctx context.Context, blockRoot libcommon.
 	if err := tx.Put(kv.BlockRootToKzgCommitments, blockRoot[:], val); err != nil {
 		return err
 	}
+	if bs.slotsKept == math.MaxUint64 {
+		if err := writeVersionedHashIndex(tx, blobSidecars); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func writeVersionedHashIndex(tx kv.RwTx, blobSidecars []*cltypes.BlobSidecar) error {
+	val := make([]byte, 16)
+	for _, blobSidecar := range blobSidecars {
+		versionedHash, err := utils.KzgCommitmentToVersionedHash(blobSidecar.KzgCommitment)
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(val, blobSidecar.SignedBlockHeader.Header.Slot)
+		binary.BigEndian.PutUint64(val[8:], blobSidecar.Index)
+		if err := tx.Put(kv.BlobVersionedHashToSlot, versionedHash[:], val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadVersionedHashIndex - slot and index of blob sidecar by versioned hash of its kzg commitment. Sidecar may be in db or in snapshots.
+func (bs *BlobStore) ReadVersionedHashIndex(ctx context.Context, versionedHash libcommon.Hash) (slot, index uint64, found bool, err error) {
+	tx, err := bs.db.BeginRo(ctx)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer tx.Rollback()
+	val, err := tx.GetOne(kv.BlobVersionedHashToSlot, versionedHash[:])
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(val) != 16 {
+		return 0, 0, false, nil
+	}
+	return binary.BigEndian.Uint64(val), binary.BigEndian.Uint64(val[8:]), true, nil
+}
+
+// WriteFrozenVersionedHashIndex - indexes sidecars of blob sidecars snapshots (for example: downloaded ones) and saves progress of indexing
+func (bs *BlobStore) WriteFrozenVersionedHashIndex(ctx context.Context, blobSidecars []*cltypes.BlobSidecar, indexedTo uint64) error {
+	tx, err := bs.db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := writeVersionedHashIndex(tx, blobSidecars); err != nil {
+		return err
+	}
+	if err := tx.Put(kv.LastBeaconSnapshot, []byte(kv.BlobsArchiveIndexedKey), base_encoding.Encode64ToBytes4(indexedTo)); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
+// FrozenVersionedHashIndexProgress - blob sidecars snapshots are indexed up to this slot (exclusive)
+func (bs *BlobStore) FrozenVersionedHashIndexProgress(ctx context.Context) (uint64, error) {
+	tx, err := bs.db.BeginRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	val, err := tx.GetOne(kv.LastBeaconSnapshot, []byte(kv.BlobsArchiveIndexedKey))
+	if err != nil {
+		return 0, err
+	}
+	if len(val) != 4 {
+		return 0, nil
+	}
+	return base_encoding.Decode64FromBytes4(val), nil
+}
+
 // ReadBlobSidecars reads the sidecars from the database. it assumes that all blobSidecars are for the same blockRoot and we have all of them.
 func (bs *BlobStore) ReadBlobSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) ([]*cltypes.BlobSidecar, bool, error) {
 	tx, err := bs.db.BeginRo(ctx)
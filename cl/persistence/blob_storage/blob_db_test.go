@@ -2,6 +2,7 @@ package blob_storage
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
@@ -10,6 +11,7 @@ import (
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/utils"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
@@ -50,3 +52,45 @@ func TestBlobDB(t *testing.T) {
 	require.Equal(t, s1.SignedBlockHeader, sidecars[0].SignedBlockHeader)
 	require.Equal(t, s2.SignedBlockHeader, sidecars[1].SignedBlockHeader)
 }
+
+func TestBlobDBVersionedHashIndex(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	s1 := cltypes.NewBlobSidecar(0, &cltypes.Blob{1}, libcommon.Bytes48{2}, libcommon.Bytes48{3}, &cltypes.SignedBeaconBlockHeader{Header: &cltypes.BeaconBlockHeader{Slot: 1}}, solid.NewHashVector(cltypes.CommitmentBranchSize))
+	s2 := cltypes.NewBlobSidecar(1, &cltypes.Blob{3}, libcommon.Bytes48{5}, libcommon.Bytes48{9}, &cltypes.SignedBeaconBlockHeader{Header: &cltypes.BeaconBlockHeader{Slot: 1}}, solid.NewHashVector(cltypes.CommitmentBranchSize))
+	s3 := cltypes.NewBlobSidecar(0, &cltypes.Blob{4}, libcommon.Bytes48{6}, libcommon.Bytes48{7}, &cltypes.SignedBeaconBlockHeader{Header: &cltypes.BeaconBlockHeader{Slot: 7}}, solid.NewHashVector(cltypes.CommitmentBranchSize))
+	h2, err := utils.KzgCommitmentToVersionedHash(s2.KzgCommitment)
+	require.NoError(t, err)
+	h3, err := utils.KzgCommitmentToVersionedHash(s3.KzgCommitment)
+	require.NoError(t, err)
+
+	// pruned sidecars are not indexed
+	bs := NewBlobStore(db, afero.NewMemMapFs(), 12, &clparams.MainnetBeaconConfig, nil)
+	require.NoError(t, bs.WriteBlobSidecars(ctx, libcommon.Hash{1}, []*cltypes.BlobSidecar{s1, s2}))
+	_, _, found, err := bs.ReadVersionedHashIndex(ctx, h2)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	bs = NewBlobStore(db, afero.NewMemMapFs(), math.MaxUint64, &clparams.MainnetBeaconConfig, nil)
+	require.NoError(t, bs.WriteBlobSidecars(ctx, libcommon.Hash{1}, []*cltypes.BlobSidecar{s1, s2}))
+	slot, index, found, err := bs.ReadVersionedHashIndex(ctx, h2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(1), slot)
+	require.Equal(t, uint64(1), index)
+
+	progress, err := bs.FrozenVersionedHashIndexProgress(ctx)
+	require.NoError(t, err)
+	require.Zero(t, progress)
+	require.NoError(t, bs.WriteFrozenVersionedHashIndex(ctx, []*cltypes.BlobSidecar{s3}, 8))
+	progress, err = bs.FrozenVersionedHashIndexProgress(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), progress)
+	slot, index, found, err = bs.ReadVersionedHashIndex(ctx, h3)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(7), slot)
+	require.Equal(t, uint64(0), index)
+}
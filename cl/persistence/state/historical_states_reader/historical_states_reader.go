@@ -53,6 +53,19 @@ func NewHistoricalStatesReader(cfg *clparams.BeaconChainConfig, blockReader free
 	}
 }
 
+// readFrozenState - full state from beacon states snapshots, nil if it's not there
+func (r *HistoricalStatesReader) readFrozenState(slot uint64) (*state.CachingBeaconState, error) {
+	buf, version, err := r.blockReader.ReadBeaconStateSSZ(slot)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	ret := state.New(r.cfg)
+	if err := ret.DecodeSSZ(buf, int(version)); err != nil {
+		return nil, fmt.Errorf("failed to decode frozen state at slot %d: %w", slot, err)
+	}
+	return ret, nil
+}
+
 func (r *HistoricalStatesReader) ReadHistoricalState(ctx context.Context, tx kv.Tx, slot uint64) (*state.CachingBeaconState, error) {
 	ret := state.New(r.cfg)
 	latestProcessedState, err := state_accessors.GetStateProcessingProgress(tx)
@@ -62,7 +75,8 @@ func (r *HistoricalStatesReader) ReadHistoricalState(ctx context.Context, tx kv.
 
 	// If this happens, we need to update our static tables
 	if slot > latestProcessedState || slot > r.validatorTable.Slot() {
-		return nil, nil
+		// history is not processed yet (checkpoint-synced node), but state may be in beacon states snapshots
+		return r.readFrozenState(slot)
 	}
 
 	if slot == r.genesisState.Slot() {
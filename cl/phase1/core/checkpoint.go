@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
@@ -61,6 +63,101 @@ func RetrieveBeaconState(ctx context.Context, beaconConfig *clparams.BeaconChain
 	return beaconState, nil
 }
 
+// checkpointProviderBaseUrl - root of beacon API of a checkpoint sync provider, given by the url of its state endpoint
+func checkpointProviderBaseUrl(uri string) string {
+	if i := strings.Index(uri, "/eth/"); i >= 0 {
+		return uri[:i]
+	}
+	return strings.TrimSuffix(uri, "/")
+}
+
+// RetrieveVerifiedBeaconState - downloads the finalized state from the first provider which serves it and accepts it
+// only if the latest block of the state is confirmed by at least quorum providers, the source included: a provider
+// confirms it by serving the same canonical block at its slot. Quorum 0 means the majority of providers. If
+// trustedBlockRoot is not zero, the latest block of the state must also have this root.
+func RetrieveVerifiedBeaconState(ctx context.Context, beaconConfig *clparams.BeaconChainConfig, uris []string, quorum int, trustedBlockRoot libcommon.Hash) (*state.CachingBeaconState, error) {
+	if len(uris) == 0 {
+		return nil, fmt.Errorf("checkpoint sync failed, no providers")
+	}
+	if quorum == 0 {
+		quorum = len(uris)/2 + 1
+	}
+	if quorum > len(uris) {
+		return nil, fmt.Errorf("checkpoint sync failed, quorum %d is greater than number of providers %d", quorum, len(uris))
+	}
+
+	var (
+		beaconState *state.CachingBeaconState
+		source      string
+		err         error
+	)
+	for _, uri := range uris {
+		if beaconState, err = RetrieveBeaconState(ctx, beaconConfig, uri); err == nil {
+			source = uri
+			break
+		}
+		log.Warn("[Checkpoint Sync] Provider failed", "uri", uri, "err", err)
+	}
+	if beaconState == nil {
+		return nil, fmt.Errorf("checkpoint sync failed on all providers, last error: %w", err)
+	}
+
+	// state root of the latest block header is filled by the next slot processing
+	header := beaconState.LatestBlockHeader()
+	if header.Root == (libcommon.Hash{}) {
+		if header.Root, err = beaconState.HashSSZ(); err != nil {
+			return nil, err
+		}
+	}
+	var blockRoot libcommon.Hash
+	if blockRoot, err = header.HashSSZ(); err != nil {
+		return nil, err
+	}
+	if trustedBlockRoot != (libcommon.Hash{}) && blockRoot != trustedBlockRoot {
+		return nil, fmt.Errorf("checkpoint sync failed, block root %x of state from %s does not match trusted root %x", blockRoot, source, trustedBlockRoot)
+	}
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		confirmedBy = []string{source}
+	)
+	for _, uri := range uris {
+		if uri == source {
+			continue
+		}
+		wg.Add(1)
+		go func(uri string) {
+			defer wg.Done()
+			block, err := RetrieveBlock(ctx, beaconConfig, fmt.Sprintf("%s/eth/v2/beacon/blocks/%d", checkpointProviderBaseUrl(uri), header.Slot), nil)
+			if err != nil {
+				log.Warn("[Checkpoint Sync] Provider could not confirm checkpoint", "uri", uri, "err", err)
+				return
+			}
+			var root libcommon.Hash
+			if root, err = block.Block.HashSSZ(); err != nil {
+				log.Warn("[Checkpoint Sync] Provider could not confirm checkpoint", "uri", uri, "err", err)
+				return
+			}
+			if root != blockRoot {
+				log.Warn("[Checkpoint Sync] Provider disagrees on checkpoint", "uri", uri, "slot", header.Slot, "root", root, "expected", blockRoot)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			confirmedBy = append(confirmedBy, uri)
+		}(uri)
+	}
+	wg.Wait()
+
+	if len(confirmedBy) < quorum {
+		return nil, fmt.Errorf("checkpoint sync failed, block root %x at slot %d is confirmed by %d providers, quorum is %d", blockRoot, header.Slot, len(confirmedBy), quorum)
+	}
+	log.Info("[Checkpoint Sync] Checkpoint verified", "slot", header.Slot, "root", blockRoot, "source", source,
+		"confirmedBy", strings.Join(confirmedBy, ","), "quorum", quorum, "trustedRoot", trustedBlockRoot != (libcommon.Hash{}))
+	return beaconState, nil
+}
+
 func RetrieveBlock(ctx context.Context, beaconConfig *clparams.BeaconChainConfig, uri string, expectedBlockRoot *libcommon.Hash) (*cltypes.SignedBeaconBlock, error) {
 	log.Debug("[Checkpoint Sync] Requesting beacon block", "uri", uri)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
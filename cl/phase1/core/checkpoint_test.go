@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cl/antiquary/tests"
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/stretchr/testify/require"
+)
+
+func checkpointProviderForTest(t *testing.T, stateSSZ []byte, block *cltypes.SignedBeaconBlock) *httptest.Server {
+	blockSSZ, err := block.EncodeSSZ(nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v2/debug/beacon/states/finalized":
+			w.Write(stateSSZ)
+		case fmt.Sprintf("/eth/v2/beacon/blocks/%d", block.Block.Slot):
+			w.Write(blockSSZ)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetrieveVerifiedBeaconState(t *testing.T) {
+	blocks, _, postState := tests.GetPhase0Random()
+	stateSSZ, err := postState.EncodeSSZ(nil)
+	require.NoError(t, err)
+	head := blocks[len(blocks)-1]
+	headRoot, err := head.Block.HashSSZ()
+	require.NoError(t, err)
+	// serves a different block at the slot of head
+	otherBlock := *blocks[0]
+	otherHeader := *blocks[0].Block
+	otherHeader.Slot = head.Block.Slot
+	otherBlock.Block = &otherHeader
+
+	stateUrl := func(server *httptest.Server) string {
+		return server.URL + "/eth/v2/debug/beacon/states/finalized"
+	}
+	good1 := stateUrl(checkpointProviderForTest(t, stateSSZ, head))
+	good2 := stateUrl(checkpointProviderForTest(t, stateSSZ, head))
+	liar := stateUrl(checkpointProviderForTest(t, stateSSZ, &otherBlock))
+	downServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer downServer.Close()
+	down := stateUrl(downServer)
+
+	ctx := context.Background()
+	cfg := &clparams.MainnetBeaconConfig
+	s, err := RetrieveVerifiedBeaconState(ctx, cfg, []string{good1, liar, good2}, 0, libcommon.Hash{})
+	require.NoError(t, err)
+	require.Equal(t, postState.Slot(), s.Slot())
+
+	// source is the first available provider, the others only confirm
+	_, err = RetrieveVerifiedBeaconState(ctx, cfg, []string{down, good1}, 1, headRoot)
+	require.NoError(t, err)
+
+	_, err = RetrieveVerifiedBeaconState(ctx, cfg, []string{good1, liar, down}, 0, libcommon.Hash{})
+	require.Error(t, err)
+	_, err = RetrieveVerifiedBeaconState(ctx, cfg, []string{good1, good2}, 0, libcommon.Hash{1})
+	require.Error(t, err)
+	_, err = RetrieveVerifiedBeaconState(ctx, cfg, []string{good1}, 2, libcommon.Hash{})
+	require.Error(t, err)
+}
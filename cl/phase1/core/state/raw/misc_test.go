@@ -29,3 +29,20 @@ func TestGetters(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, common.Hash(root), common.HexToHash("0x9f1620db18ee06b9cbdf1b7fa9658701063d2bd05d54b09780f6c0a074b4ce5f"))
 }
+
+func TestSegmentsSSZ(t *testing.T) {
+	state := GetTestState()
+	buf, err := state.EncodeSSZ(nil)
+	require.NoError(t, err)
+
+	segments, err := state.SegmentsSSZ(buf)
+	require.NoError(t, err)
+	require.Equal(t, 0, segments[0])
+	require.Equal(t, int(state.baseOffsetSSZ()), segments[1])
+	require.Equal(t, len(buf), segments[len(segments)-1])
+	// fixed part and 9 dynamic fields of deneb state
+	require.Len(t, segments, 11)
+
+	_, err = state.SegmentsSSZ(buf[:100])
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package raw
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	ssz2 "github.com/ledgerwatch/erigon/cl/ssz"
@@ -49,6 +50,36 @@ func (b *BeaconState) EncodeSSZ(buf []byte) ([]byte, error) {
 	return ssz2.MarshalSSZ(buf, b.getSchema()...)
 }
 
+// SegmentsSSZ gives the boundaries of the fixed part and of every dynamic field within buf, the ssz encoding of the
+// state: [0, end of fixed part, end of first dynamic field, ..., len(buf)].
+func (b *BeaconState) SegmentsSSZ(buf []byte) ([]int, error) {
+	segments := []int{0}
+	position := 0
+	for _, element := range b.getSchema() {
+		switch obj := element.(type) {
+		case *uint64:
+			position += 8
+		case []byte:
+			position += len(obj)
+		case ssz2.SizedObjectSSZ:
+			if obj.Static() {
+				position += obj.EncodingSizeSSZ()
+				continue
+			}
+			if position+4 > len(buf) {
+				return nil, fmt.Errorf("[BeaconState] err: %s", ssz.ErrLowBufferSize)
+			}
+			offset := int(binary.LittleEndian.Uint32(buf[position:]))
+			if offset < segments[len(segments)-1] || offset > len(buf) {
+				return nil, fmt.Errorf("[BeaconState] err: %s", ssz.ErrBadOffset)
+			}
+			segments = append(segments, offset)
+			position += 4
+		}
+	}
+	return append(segments, len(buf)), nil
+}
+
 // getSchema gives the schema for the current beacon state version according to ETH 2.0 specs.
 func (b *BeaconState) getSchema() []interface{} {
 	s := []interface{}{&b.genesisTime, b.genesisValidatorsRoot[:], &b.slot, b.fork, b.latestBlockHeader, b.blockRoots, b.stateRoots, b.historicalRoots,
@@ -118,10 +118,12 @@ type forkGraphDisk struct {
 	sszSnappyBuffer bytes.Buffer
 
 	rcfg beacon_router_configuration.RouterConfiguration
+	// light client data is computed for beacon API and for light client server
+	lightClientServer bool
 }
 
 // Initialize fork graph with a new state
-func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, rcfg beacon_router_configuration.RouterConfiguration) ForkGraph {
+func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, rcfg beacon_router_configuration.RouterConfiguration, lightClientServer bool) ForkGraph {
 	farthestExtendingPath := make(map[libcommon.Hash]bool)
 	anchorRoot, err := anchorState.BlockRoot()
 	if err != nil {
@@ -150,6 +152,7 @@ func NewForkGraphDisk(anchorState *state.CachingBeaconState, aferoFs afero.Fs, r
 		validatorSetStorage:     validatorSetStorage,
 		inactivityScoresStorage: inactivityScoresStorage,
 		rcfg:                    rcfg,
+		lightClientServer:       lightClientServer,
 	}
 	f.lowestAvaiableBlock.Store(anchorState.Slot())
 	f.headers.Store(libcommon.Hash(anchorRoot), &anchorHeader)
@@ -197,7 +200,7 @@ func (f *forkGraphDisk) AddChainSegment(signedBlock *cltypes.SignedBeaconBlock,
 	parentBlock, hasParentBlock := f.getBlock(block.ParentRoot)
 
 	// Before processing the state: update the newest lightclient update.
-	if block.Version() >= clparams.AltairVersion && hasParentBlock && fullValidation && hasFinalized && f.rcfg.Beacon {
+	if block.Version() >= clparams.AltairVersion && hasParentBlock && fullValidation && hasFinalized && (f.rcfg.Beacon || f.lightClientServer) {
 		nextSyncCommitteeBranch, err := newState.NextSyncCommitteeBranch()
 		if err != nil {
 			return nil, LogisticError, err
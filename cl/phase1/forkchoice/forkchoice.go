@@ -11,6 +11,7 @@ import (
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/monitor"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
 	state2 "github.com/ledgerwatch/erigon/cl/phase1/core/state"
@@ -117,6 +118,8 @@ type ForkChoiceStore struct {
 	emitters *beaconevents.Emitters
 	synced   atomic.Bool
 
+	validatorMonitor monitor.ValidatorMonitor
+
 	ethClock eth_clock.EthereumClock
 }
 
@@ -140,6 +143,7 @@ func NewForkChoiceStore(
 	emitters *beaconevents.Emitters,
 	syncedDataManager *synced_data.SyncedDataManager,
 	blobStorage blob_storage.BlobStorage,
+	validatorMonitor monitor.ValidatorMonitor,
 ) (*ForkChoiceStore, error) {
 	anchorRoot, err := anchorState.BlockRoot()
 	if err != nil {
@@ -237,6 +241,7 @@ func NewForkChoiceStore(
 		hotSidecars:           make(map[libcommon.Hash][]*cltypes.BlobSidecar),
 		blobStorage:           blobStorage,
 		ethClock:              ethClock,
+		validatorMonitor:      validatorMonitor,
 	}
 	f.justifiedCheckpoint.Store(anchorCheckpoint.Copy())
 	f.finalizedCheckpoint.Store(anchorCheckpoint.Copy())
@@ -46,6 +46,9 @@ type GossipManager struct {
 	voluntaryExitService         services.VoluntaryExitService
 	blsToExecutionChangeService  services.BLSToExecutionChangeService
 	proposerSlashingService      services.ProposerSlashingService
+	// light client server, nil if disabled
+	lightClientFinalityUpdateService   services.LightClientFinalityUpdateService
+	lightClientOptimisticUpdateService services.LightClientOptimisticUpdateService
 }
 
 func NewGossipReceiver(
@@ -64,6 +67,8 @@ func NewGossipReceiver(
 	voluntaryExitService services.VoluntaryExitService,
 	blsToExecutionChangeService services.BLSToExecutionChangeService,
 	proposerSlashingService services.ProposerSlashingService,
+	lightClientFinalityUpdateService services.LightClientFinalityUpdateService,
+	lightClientOptimisticUpdateService services.LightClientOptimisticUpdateService,
 ) *GossipManager {
 	return &GossipManager{
 		sentinel:                     s,
@@ -81,6 +86,9 @@ func NewGossipReceiver(
 		voluntaryExitService:         voluntaryExitService,
 		blsToExecutionChangeService:  blsToExecutionChangeService,
 		proposerSlashingService:      proposerSlashingService,
+
+		lightClientFinalityUpdateService:   lightClientFinalityUpdateService,
+		lightClientOptimisticUpdateService: lightClientOptimisticUpdateService,
 	}
 }
 
@@ -182,6 +190,24 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 			return err
 		}
 		return g.aggregateAndProofService.ProcessMessage(ctx, data.SubnetId, obj)
+	case gossip.TopicNameLightClientFinalityUpdate:
+		if g.lightClientFinalityUpdateService == nil {
+			return services.ErrIgnore
+		}
+		obj := &cltypes.LightClientFinalityUpdate{}
+		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
+			return err
+		}
+		return g.lightClientFinalityUpdateService.ProcessMessage(ctx, data.SubnetId, obj)
+	case gossip.TopicNameLightClientOptimisticUpdate:
+		if g.lightClientOptimisticUpdateService == nil {
+			return services.ErrIgnore
+		}
+		obj := &cltypes.LightClientOptimisticUpdate{}
+		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
+			return err
+		}
+		return g.lightClientOptimisticUpdateService.ProcessMessage(ctx, data.SubnetId, obj)
 	default:
 		switch {
 		case gossip.IsTopicBlobSidecar(data.Name):
@@ -243,6 +269,9 @@ func (g *GossipManager) Start(ctx context.Context) {
 	goWorker(blocksCh, 1)
 	goWorker(blobsCh, 1)
 	goWorker(syncCommitteesCh, 1)
+	if g.lightClientFinalityUpdateService != nil && g.lightClientOptimisticUpdateService != nil {
+		go g.publishLightClientUpdates(ctx)
+	}
 
 Reconnect:
 	for {
@@ -278,3 +307,39 @@ Reconnect:
 		}
 	}
 }
+
+// publishLightClientUpdates - light client server gossips locally computed updates once a third of every slot is over,
+// the same updates received from peers earlier are not published again
+func (g *GossipManager) publishLightClientUpdates(ctx context.Context) {
+	for {
+		slot := g.ethClock.GetCurrentSlot() + 1
+		timer := time.NewTimer(time.Until(g.ethClock.GetSlotTime(slot).Add(time.Duration(g.beaconConfig.SecondsPerSlot) * time.Second / 3)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if update := g.lightClientFinalityUpdateService.LocalFinalityUpdate(); update != nil {
+			if err := g.lightClientFinalityUpdateService.ProcessMessage(ctx, nil, update); err == nil {
+				g.publishLightClientUpdate(ctx, gossip.TopicNameLightClientFinalityUpdate, update)
+			}
+		}
+		if update := g.lightClientOptimisticUpdateService.LocalOptimisticUpdate(); update != nil {
+			if err := g.lightClientOptimisticUpdateService.ProcessMessage(ctx, nil, update); err == nil {
+				g.publishLightClientUpdate(ctx, gossip.TopicNameLightClientOptimisticUpdate, update)
+			}
+		}
+	}
+}
+
+func (g *GossipManager) publishLightClientUpdate(ctx context.Context, topic string, update ssz.Marshaler) {
+	encoded, err := update.EncodeSSZ(nil)
+	if err != nil {
+		log.Warn("[Beacon Gossip] failed to encode light client update", "topic", topic, "err", err)
+		return
+	}
+	if _, err := g.sentinel.PublishGossip(ctx, &sentinel.GossipData{Name: topic, Data: encoded}); err != nil {
+		log.Debug("[Beacon Gossip] failed to publish light client update", "topic", topic, "err", err)
+	}
+}
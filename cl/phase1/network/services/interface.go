@@ -39,3 +39,15 @@ type BLSToExecutionChangeService Service[*cltypes.SignedBLSToExecutionChange]
 
 //go:generate mockgen -typed=true -destination=./mock_services/proposer_slashing_service_mock.go -package=mock_services . ProposerSlashingService
 type ProposerSlashingService Service[*cltypes.ProposerSlashing]
+
+//go:generate mockgen -typed=true -destination=./mock_services/light_client_finality_update_service_mock.go -package=mock_services . LightClientFinalityUpdateService
+type LightClientFinalityUpdateService interface {
+	Service[*cltypes.LightClientFinalityUpdate]
+	LocalFinalityUpdate() *cltypes.LightClientFinalityUpdate
+}
+
+//go:generate mockgen -typed=true -destination=./mock_services/light_client_optimistic_update_service_mock.go -package=mock_services . LightClientOptimisticUpdateService
+type LightClientOptimisticUpdateService interface {
+	Service[*cltypes.LightClientOptimisticUpdate]
+	LocalOptimisticUpdate() *cltypes.LightClientOptimisticUpdate
+}
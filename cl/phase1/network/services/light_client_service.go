@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/phase1/forkchoice"
+	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
+)
+
+// lightClientUpdates - locally computed light client data, shared by finality and optimistic update services
+type lightClientUpdates struct {
+	forkChoice forkchoice.ForkChoiceStorageReader
+	beaconCfg  *clparams.BeaconChainConfig
+	netCfg     *clparams.NetworkConfig
+	ethClock   eth_clock.EthereumClock
+}
+
+// LocalFinalityUpdate - finality update of newest light client update, nil if there is none yet
+func (l *lightClientUpdates) LocalFinalityUpdate() *cltypes.LightClientFinalityUpdate {
+	update := l.forkChoice.NewestLightClientUpdate()
+	if update == nil {
+		return nil
+	}
+	return &cltypes.LightClientFinalityUpdate{
+		AttestedHeader:  update.AttestedHeader,
+		FinalizedHeader: update.FinalizedHeader,
+		FinalityBranch:  update.FinalityBranch,
+		SyncAggregate:   update.SyncAggregate,
+		SignatureSlot:   update.SignatureSlot,
+	}
+}
+
+// LocalOptimisticUpdate - optimistic update of newest light client update, nil if there is none yet
+func (l *lightClientUpdates) LocalOptimisticUpdate() *cltypes.LightClientOptimisticUpdate {
+	update := l.forkChoice.NewestLightClientUpdate()
+	if update == nil {
+		return nil
+	}
+	return &cltypes.LightClientOptimisticUpdate{
+		AttestedHeader: update.AttestedHeader,
+		SyncAggregate:  update.SyncAggregate,
+		SignatureSlot:  update.SignatureSlot,
+	}
+}
+
+// tooEarly - updates are propagated only once a third of signature slot is over
+func (l *lightClientUpdates) tooEarly(signatureSlot uint64) bool {
+	propagationTime := l.ethClock.GetSlotTime(signatureSlot).Add(time.Duration(l.beaconCfg.SecondsPerSlot) * time.Second / 3)
+	return time.Now().Add(l.netCfg.MaximumGossipClockDisparity).Before(propagationTime)
+}
+
+// matchesLocal - received update must be exactly the one computed locally
+func matchesLocal(received, local interface{ HashSSZ() ([32]byte, error) }) (bool, error) {
+	receivedRoot, err := received.HashSSZ()
+	if err != nil {
+		return false, err
+	}
+	localRoot, err := local.HashSSZ()
+	if err != nil {
+		return false, err
+	}
+	return receivedRoot == localRoot, nil
+}
+
+type lightClientFinalityUpdateService struct {
+	*lightClientUpdates
+
+	mu                     sync.Mutex
+	forwardedSlot          uint64
+	forwardedSupermajority bool
+}
+
+func NewLightClientFinalityUpdateService(forkChoice forkchoice.ForkChoiceStorageReader, beaconCfg *clparams.BeaconChainConfig, netCfg *clparams.NetworkConfig, ethClock eth_clock.EthereumClock) *lightClientFinalityUpdateService {
+	return &lightClientFinalityUpdateService{
+		lightClientUpdates: &lightClientUpdates{forkChoice: forkChoice, beaconCfg: beaconCfg, netCfg: netCfg, ethClock: ethClock},
+	}
+}
+
+func (s *lightClientFinalityUpdateService) ProcessMessage(ctx context.Context, subnet *uint64, msg *cltypes.LightClientFinalityUpdate) error {
+	// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/p2p-interface.md#light_client_finality_update
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// [IGNORE] The finalized_header.beacon.slot is greater than that of all previously forwarded finality_updates, or it
+	// matches the highest previously forwarded slot and also has a sync_aggregate indicating supermajority (> 2/3)
+	// sync committee participation while the previously forwarded finality_update for that slot did not indicate
+	// supermajority
+	slot := msg.FinalizedHeader.Beacon.Slot
+	supermajority := msg.SyncAggregate.Sum()*3 > int(s.beaconCfg.SyncCommitteeSize)*2
+	if slot < s.forwardedSlot || (slot == s.forwardedSlot && (s.forwardedSupermajority || !supermajority)) {
+		return ErrIgnore
+	}
+	// [IGNORE] The finality_update is received after the block at signature_slot was given enough time to propagate
+	// through the network
+	if s.tooEarly(msg.SignatureSlot) {
+		return ErrIgnore
+	}
+	// [IGNORE] The received finality_update matches the locally computed one exactly
+	local := s.LocalFinalityUpdate()
+	if local == nil {
+		return ErrIgnore
+	}
+	if match, err := matchesLocal(msg, local); err != nil || !match {
+		return ErrIgnore
+	}
+	s.forwardedSlot, s.forwardedSupermajority = slot, supermajority
+	return nil
+}
+
+type lightClientOptimisticUpdateService struct {
+	*lightClientUpdates
+
+	mu            sync.Mutex
+	forwardedSlot uint64
+}
+
+func NewLightClientOptimisticUpdateService(forkChoice forkchoice.ForkChoiceStorageReader, beaconCfg *clparams.BeaconChainConfig, netCfg *clparams.NetworkConfig, ethClock eth_clock.EthereumClock) *lightClientOptimisticUpdateService {
+	return &lightClientOptimisticUpdateService{
+		lightClientUpdates: &lightClientUpdates{forkChoice: forkChoice, beaconCfg: beaconCfg, netCfg: netCfg, ethClock: ethClock},
+	}
+}
+
+func (s *lightClientOptimisticUpdateService) ProcessMessage(ctx context.Context, subnet *uint64, msg *cltypes.LightClientOptimisticUpdate) error {
+	// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/p2p-interface.md#light_client_optimistic_update
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// [IGNORE] The attested_header.beacon.slot is greater than that of all previously forwarded optimistic_updates
+	slot := msg.AttestedHeader.Beacon.Slot
+	if slot <= s.forwardedSlot {
+		return ErrIgnore
+	}
+	// [IGNORE] The optimistic_update is received after the block at signature_slot was given enough time to propagate
+	// through the network
+	if s.tooEarly(msg.SignatureSlot) {
+		return ErrIgnore
+	}
+	// [IGNORE] The received optimistic_update matches the locally computed one exactly
+	local := s.LocalOptimisticUpdate()
+	if local == nil {
+		return ErrIgnore
+	}
+	if match, err := matchesLocal(msg, local); err != nil || !match {
+		return ErrIgnore
+	}
+	s.forwardedSlot = slot
+	return nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/phase1/forkchoice/mock_services"
+	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupLightClientServiceTest(t *testing.T, ctrl *gomock.Controller) (*mock_services.ForkChoiceStorageMock, *eth_clock.MockEthereumClock) {
+	forkchoiceMock := mock_services.NewForkChoiceStorageMock(t)
+	ethClock := eth_clock.NewMockEthereumClock(ctrl)
+	// signature slot of every update is long over
+	ethClock.EXPECT().GetSlotTime(gomock.Any()).Return(time.Now().Add(-time.Minute)).AnyTimes()
+	return forkchoiceMock, ethClock
+}
+
+func getLightClientUpdateForTest(attestedSlot, finalizedSlot uint64, participation int) *cltypes.LightClientUpdate {
+	update := cltypes.NewLightClientUpdate(clparams.CapellaVersion)
+	update.AttestedHeader.Beacon.Slot = attestedSlot
+	update.FinalizedHeader.Beacon.Slot = finalizedSlot
+	update.SignatureSlot = attestedSlot + 1
+	for i := 0; i < participation/8; i++ {
+		update.SyncAggregate.SyncCommiteeBits[i] = 0xff
+	}
+	return update
+}
+
+func TestLightClientFinalityUpdateService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	forkchoiceMock, ethClock := setupLightClientServiceTest(t, ctrl)
+	s := NewLightClientFinalityUpdateService(forkchoiceMock, &clparams.MainnetBeaconConfig, &clparams.NetworkConfig{}, ethClock)
+
+	// nothing computed locally yet
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(100, 64, 256)
+	msg := s.LocalFinalityUpdate()
+	forkchoiceMock.NewestLCUpdate = nil
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, msg), ErrIgnore)
+
+	// update without supermajority
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(100, 64, 256)
+	require.NoError(t, s.ProcessMessage(context.Background(), nil, s.LocalFinalityUpdate()))
+	// already forwarded
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, s.LocalFinalityUpdate()), ErrIgnore)
+
+	// same finalized slot, now with supermajority
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(101, 64, 512)
+	require.NoError(t, s.ProcessMessage(context.Background(), nil, s.LocalFinalityUpdate()))
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, s.LocalFinalityUpdate()), ErrIgnore)
+
+	// does not match local update
+	msg = s.LocalFinalityUpdate()
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(130, 96, 512)
+	msg.FinalizedHeader.Beacon.Slot = 96
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, msg), ErrIgnore)
+	require.NoError(t, s.ProcessMessage(context.Background(), nil, s.LocalFinalityUpdate()))
+}
+
+func TestLightClientOptimisticUpdateService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	forkchoiceMock, ethClock := setupLightClientServiceTest(t, ctrl)
+	s := NewLightClientOptimisticUpdateService(forkchoiceMock, &clparams.MainnetBeaconConfig, &clparams.NetworkConfig{}, ethClock)
+
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(100, 64, 512)
+	require.NoError(t, s.ProcessMessage(context.Background(), nil, s.LocalOptimisticUpdate()))
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, s.LocalOptimisticUpdate()), ErrIgnore)
+
+	// does not match local update
+	msg := s.LocalOptimisticUpdate()
+	forkchoiceMock.NewestLCUpdate = getLightClientUpdateForTest(101, 64, 512)
+	msg.AttestedHeader.Beacon.Slot = 101
+	require.ErrorIs(t, s.ProcessMessage(context.Background(), nil, msg), ErrIgnore)
+	require.NoError(t, s.ProcessMessage(context.Background(), nil, s.LocalOptimisticUpdate()))
+}
@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ledgerwatch/erigon/cl/phase1/network/services (interfaces: LightClientFinalityUpdateService)
+//
+// Generated by this command:
+//
+//	mockgen -typed=true -destination=./mock_services/light_client_finality_update_service_mock.go -package=mock_services . LightClientFinalityUpdateService
+//
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	cltypes "github.com/ledgerwatch/erigon/cl/cltypes"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLightClientFinalityUpdateService is a mock of LightClientFinalityUpdateService interface.
+type MockLightClientFinalityUpdateService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLightClientFinalityUpdateServiceMockRecorder
+}
+
+// MockLightClientFinalityUpdateServiceMockRecorder is the mock recorder for MockLightClientFinalityUpdateService.
+type MockLightClientFinalityUpdateServiceMockRecorder struct {
+	mock *MockLightClientFinalityUpdateService
+}
+
+// NewMockLightClientFinalityUpdateService creates a new mock instance.
+func NewMockLightClientFinalityUpdateService(ctrl *gomock.Controller) *MockLightClientFinalityUpdateService {
+	mock := &MockLightClientFinalityUpdateService{ctrl: ctrl}
+	mock.recorder = &MockLightClientFinalityUpdateServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLightClientFinalityUpdateService) EXPECT() *MockLightClientFinalityUpdateServiceMockRecorder {
+	return m.recorder
+}
+
+// LocalFinalityUpdate mocks base method.
+func (m *MockLightClientFinalityUpdateService) LocalFinalityUpdate() *cltypes.LightClientFinalityUpdate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalFinalityUpdate")
+	ret0, _ := ret[0].(*cltypes.LightClientFinalityUpdate)
+	return ret0
+}
+
+// LocalFinalityUpdate indicates an expected call of LocalFinalityUpdate.
+func (mr *MockLightClientFinalityUpdateServiceMockRecorder) LocalFinalityUpdate() *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalFinalityUpdate", reflect.TypeOf((*MockLightClientFinalityUpdateService)(nil).LocalFinalityUpdate))
+	return &MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall{Call: call}
+}
+
+// MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall wrap *gomock.Call
+type MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall) Return(arg0 *cltypes.LightClientFinalityUpdate) *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall) Do(f func() *cltypes.LightClientFinalityUpdate) *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall) DoAndReturn(f func() *cltypes.LightClientFinalityUpdate) *MockLightClientFinalityUpdateServiceLocalFinalityUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ProcessMessage mocks base method.
+func (m *MockLightClientFinalityUpdateService) ProcessMessage(arg0 context.Context, arg1 *uint64, arg2 *cltypes.LightClientFinalityUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMessage indicates an expected call of ProcessMessage.
+func (mr *MockLightClientFinalityUpdateServiceMockRecorder) ProcessMessage(arg0, arg1, arg2 any) *MockLightClientFinalityUpdateServiceProcessMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMessage", reflect.TypeOf((*MockLightClientFinalityUpdateService)(nil).ProcessMessage), arg0, arg1, arg2)
+	return &MockLightClientFinalityUpdateServiceProcessMessageCall{Call: call}
+}
+
+// MockLightClientFinalityUpdateServiceProcessMessageCall wrap *gomock.Call
+type MockLightClientFinalityUpdateServiceProcessMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLightClientFinalityUpdateServiceProcessMessageCall) Return(arg0 error) *MockLightClientFinalityUpdateServiceProcessMessageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLightClientFinalityUpdateServiceProcessMessageCall) Do(f func(context.Context, *uint64, *cltypes.LightClientFinalityUpdate) error) *MockLightClientFinalityUpdateServiceProcessMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLightClientFinalityUpdateServiceProcessMessageCall) DoAndReturn(f func(context.Context, *uint64, *cltypes.LightClientFinalityUpdate) error) *MockLightClientFinalityUpdateServiceProcessMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
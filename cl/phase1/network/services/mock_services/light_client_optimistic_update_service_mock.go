@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ledgerwatch/erigon/cl/phase1/network/services (interfaces: LightClientOptimisticUpdateService)
+//
+// Generated by this command:
+//
+//	mockgen -typed=true -destination=./mock_services/light_client_optimistic_update_service_mock.go -package=mock_services . LightClientOptimisticUpdateService
+//
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	cltypes "github.com/ledgerwatch/erigon/cl/cltypes"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLightClientOptimisticUpdateService is a mock of LightClientOptimisticUpdateService interface.
+type MockLightClientOptimisticUpdateService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLightClientOptimisticUpdateServiceMockRecorder
+}
+
+// MockLightClientOptimisticUpdateServiceMockRecorder is the mock recorder for MockLightClientOptimisticUpdateService.
+type MockLightClientOptimisticUpdateServiceMockRecorder struct {
+	mock *MockLightClientOptimisticUpdateService
+}
+
+// NewMockLightClientOptimisticUpdateService creates a new mock instance.
+func NewMockLightClientOptimisticUpdateService(ctrl *gomock.Controller) *MockLightClientOptimisticUpdateService {
+	mock := &MockLightClientOptimisticUpdateService{ctrl: ctrl}
+	mock.recorder = &MockLightClientOptimisticUpdateServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLightClientOptimisticUpdateService) EXPECT() *MockLightClientOptimisticUpdateServiceMockRecorder {
+	return m.recorder
+}
+
+// LocalOptimisticUpdate mocks base method.
+func (m *MockLightClientOptimisticUpdateService) LocalOptimisticUpdate() *cltypes.LightClientOptimisticUpdate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalOptimisticUpdate")
+	ret0, _ := ret[0].(*cltypes.LightClientOptimisticUpdate)
+	return ret0
+}
+
+// LocalOptimisticUpdate indicates an expected call of LocalOptimisticUpdate.
+func (mr *MockLightClientOptimisticUpdateServiceMockRecorder) LocalOptimisticUpdate() *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalOptimisticUpdate", reflect.TypeOf((*MockLightClientOptimisticUpdateService)(nil).LocalOptimisticUpdate))
+	return &MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall{Call: call}
+}
+
+// MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall wrap *gomock.Call
+type MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall) Return(arg0 *cltypes.LightClientOptimisticUpdate) *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall) Do(f func() *cltypes.LightClientOptimisticUpdate) *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall) DoAndReturn(f func() *cltypes.LightClientOptimisticUpdate) *MockLightClientOptimisticUpdateServiceLocalOptimisticUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ProcessMessage mocks base method.
+func (m *MockLightClientOptimisticUpdateService) ProcessMessage(arg0 context.Context, arg1 *uint64, arg2 *cltypes.LightClientOptimisticUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMessage indicates an expected call of ProcessMessage.
+func (mr *MockLightClientOptimisticUpdateServiceMockRecorder) ProcessMessage(arg0, arg1, arg2 any) *MockLightClientOptimisticUpdateServiceProcessMessageCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMessage", reflect.TypeOf((*MockLightClientOptimisticUpdateService)(nil).ProcessMessage), arg0, arg1, arg2)
+	return &MockLightClientOptimisticUpdateServiceProcessMessageCall{Call: call}
+}
+
+// MockLightClientOptimisticUpdateServiceProcessMessageCall wrap *gomock.Call
+type MockLightClientOptimisticUpdateServiceProcessMessageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLightClientOptimisticUpdateServiceProcessMessageCall) Return(arg0 error) *MockLightClientOptimisticUpdateServiceProcessMessageCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLightClientOptimisticUpdateServiceProcessMessageCall) Do(f func(context.Context, *uint64, *cltypes.LightClientOptimisticUpdate) error) *MockLightClientOptimisticUpdateServiceProcessMessageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLightClientOptimisticUpdateServiceProcessMessageCall) DoAndReturn(f func(context.Context, *uint64, *cltypes.LightClientOptimisticUpdate) error) *MockLightClientOptimisticUpdateServiceProcessMessageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
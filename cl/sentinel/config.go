@@ -44,8 +44,11 @@ type SentinelConfig struct {
 	TmpDir         string
 	LocalDiscovery bool
 
-	EnableBlocks   bool
-	ActiveIndicies uint64
+	EnableBlocks      bool
+	EnableLightClient bool
+	ActiveIndicies    uint64
+	// Gossipsub scoring, clparams.DefaultGossipScoringConfig if left empty
+	GossipScoring clparams.GossipScoringConfig
 }
 
 func convertToCryptoPrivkey(privkey *ecdsa.PrivateKey) (crypto.PrivKey, error) {
@@ -3,6 +3,8 @@ package sentinel
 import (
 	"net"
 
+	"github.com/ledgerwatch/erigon/cl/sentinel/peers"
+
 	"github.com/libp2p/go-libp2p/core/control"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -23,10 +25,11 @@ var privateCIDRList = []string{
 
 type Gater struct {
 	filter *multiaddr.Filters
+	peers  *peers.Pool
 }
 
-func NewGater(cfg *SentinelConfig) (g *Gater, err error) {
-	g = &Gater{}
+func NewGater(cfg *SentinelConfig, peers *peers.Pool) (g *Gater, err error) {
+	g = &Gater{peers: peers}
 	g.filter, err = configureFilter(cfg)
 	if err != nil {
 		return nil, err
@@ -37,7 +40,7 @@ func NewGater(cfg *SentinelConfig) (g *Gater, err error) {
 // InterceptPeerDial tests whether we're permitted to Dial the specified peer.
 // This is called by the network.Network implementation when dialling a peer.
 func (g *Gater) InterceptPeerDial(p peer.ID) (allow bool) {
-	return true
+	return !g.peers.BanStatus(p)
 }
 
 // InterceptAddrDial tests whether we're permitted to dial the specified
@@ -63,8 +66,8 @@ func (g *Gater) InterceptAccept(n network.ConnMultiaddrs) (allow bool) {
 // This is called by the upgrader, after it has performed the security
 // handshake, and before it negotiates the muxer, or by the directly by the
 // transport, at the exact same checkpoint.
-func (g *Gater) InterceptSecured(_ network.Direction, _ peer.ID, _ network.ConnMultiaddrs) (allow bool) {
-	return true
+func (g *Gater) InterceptSecured(_ network.Direction, p peer.ID, _ network.ConnMultiaddrs) (allow bool) {
+	return !g.peers.BanStatus(p)
 }
 
 // InterceptUpgraded tests whether a fully capable connection is allowed.
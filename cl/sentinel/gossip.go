@@ -235,8 +235,7 @@ func (s *Sentinel) SubscribeGossip(topic GossipTopic, expiration time.Time, opts
 	if err != nil {
 		return nil, fmt.Errorf("failed to join topic %s, err=%w", path, err)
 	}
-	topicScoreParams := s.topicScoreParams(topic.Name)
-	if topicScoreParams != nil {
+	if topicScoreParams := s.topicScoreParams(topic.Name); topicScoreParams != nil && !s.cfg.GossipScoring.Disabled {
 		sub.topic.SetScoreParams(topicScoreParams)
 	}
 	s.subManager.AddSubscription(path, sub)
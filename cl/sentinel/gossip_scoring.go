@@ -0,0 +1,176 @@
+package sentinel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+var (
+	// peers per band of gossip score, bands are delimited by the scoring thresholds
+	gossipScoresGraylisted    = metrics.GetOrCreateGauge(`gossip_peer_scores{band="graylisted"}`)
+	gossipScoresNoPublish     = metrics.GetOrCreateGauge(`gossip_peer_scores{band="below_publish_threshold"}`)
+	gossipScoresNoGossip      = metrics.GetOrCreateGauge(`gossip_peer_scores{band="below_gossip_threshold"}`)
+	gossipScoresNegative      = metrics.GetOrCreateGauge(`gossip_peer_scores{band="negative"}`)
+	gossipScoresNonNegative   = metrics.GetOrCreateGauge(`gossip_peer_scores{band="non_negative"}`)
+	gossipScoreMin            = metrics.GetOrCreateGauge("gossip_peer_score_min")
+	gossipScoreMax            = metrics.GetOrCreateGauge("gossip_peer_score_max")
+	gossipGraylistedPeerCount = metrics.GetOrCreateGauge("gossip_graylisted_peers")
+)
+
+// inspectPeerScores - exports the distribution of gossip scores, called by gossipsub once per epoch.
+func (s *Sentinel) inspectPeerScores(scores map[peer.ID]*pubsub.PeerScoreSnapshot) {
+	var graylisted, noPublish, noGossip, negative, nonNegative int
+	minScore, maxScore := math.Inf(1), math.Inf(-1)
+	for _, snapshot := range scores {
+		score := snapshot.Score
+		minScore = math.Min(minScore, score)
+		maxScore = math.Max(maxScore, score)
+		switch {
+		case score < s.cfg.GossipScoring.GraylistThreshold:
+			graylisted++
+		case score < s.cfg.GossipScoring.PublishThreshold:
+			noPublish++
+		case score < s.cfg.GossipScoring.GossipThreshold:
+			noGossip++
+		case score < 0:
+			negative++
+		default:
+			nonNegative++
+		}
+	}
+	if len(scores) == 0 {
+		minScore, maxScore = 0, 0
+	}
+	gossipScoresGraylisted.SetInt(graylisted)
+	gossipScoresNoPublish.SetInt(noPublish)
+	gossipScoresNoGossip.SetInt(noGossip)
+	gossipScoresNegative.SetInt(negative)
+	gossipScoresNonNegative.SetInt(nonNegative)
+	gossipScoreMin.Set(minScore)
+	gossipScoreMax.Set(maxScore)
+}
+
+// Graylist - makes gossipsub ignore the peer until it is removed from the graylist, the peer stays connected for
+// req/resp.
+func (s *Sentinel) Graylist(pid peer.ID) error {
+	if s.cfg.GossipScoring.Disabled {
+		return fmt.Errorf("gossip scoring is disabled")
+	}
+	if _, loaded := s.graylist.LoadOrStore(pid, struct{}{}); !loaded {
+		gossipGraylistedPeerCount.Inc()
+	}
+	return nil
+}
+
+// RemoveFromGraylist - lifts the graylisting of the peer, the peer gets its gossip score back.
+func (s *Sentinel) RemoveFromGraylist(pid peer.ID) {
+	if _, loaded := s.graylist.LoadAndDelete(pid); loaded {
+		gossipGraylistedPeerCount.Dec()
+	}
+}
+
+// IsGraylisted - whether the peer was graylisted with Graylist.
+func (s *Sentinel) IsGraylisted(pid peer.ID) bool {
+	_, ok := s.graylist.Load(pid)
+	return ok
+}
+
+// gossipTopicLabel - topic name without fork digest and encoding, e.g. beacon_attestation_3
+func gossipTopicLabel(topic string) string {
+	// /eth2/{fork_digest}/{name}/{encoding}
+	parts := strings.Split(topic, "/")
+	if len(parts) != 5 {
+		return topic
+	}
+	return parts[3]
+}
+
+var _ pubsub.RawTracer = (*meshTracer)(nil)
+
+// meshTracer - tracks the mesh of every joined topic and exports its size, peers below the D_low watermark mean the
+// topic is vulnerable to eclipse or spam.
+type meshTracer struct {
+	mu     sync.Mutex
+	meshes map[string]map[peer.ID]struct{}
+}
+
+func newMeshTracer() *meshTracer {
+	return &meshTracer{meshes: make(map[string]map[peer.ID]struct{})}
+}
+
+func (m *meshTracer) MeshPeers(topic string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.meshes[topic])
+}
+
+// updateGauge - assumes the lock is held
+func (m *meshTracer) updateGauge(topic string) {
+	metrics.GetOrCreateGauge(fmt.Sprintf(`gossip_mesh_peers{topic="%s"}`, gossipTopicLabel(topic))).SetInt(len(m.meshes[topic]))
+}
+
+func (m *meshTracer) Join(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meshes[topic] = make(map[peer.ID]struct{})
+	m.updateGauge(topic)
+}
+
+func (m *meshTracer) Leave(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.meshes, topic)
+	m.updateGauge(topic)
+}
+
+func (m *meshTracer) Graft(p peer.ID, topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mesh, ok := m.meshes[topic]
+	if !ok {
+		return
+	}
+	mesh[p] = struct{}{}
+	m.updateGauge(topic)
+}
+
+func (m *meshTracer) Prune(p peer.ID, topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mesh, ok := m.meshes[topic]
+	if !ok {
+		return
+	}
+	delete(mesh, p)
+	m.updateGauge(topic)
+}
+
+// RemovePeer - gossipsub drops disconnected peers from meshes without pruning them.
+func (m *meshTracer) RemovePeer(p peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for topic, mesh := range m.meshes {
+		if _, ok := mesh[p]; ok {
+			delete(mesh, p)
+			m.updateGauge(topic)
+		}
+	}
+}
+
+func (m *meshTracer) AddPeer(peer.ID, protocol.ID)          {}
+func (m *meshTracer) ValidateMessage(*pubsub.Message)       {}
+func (m *meshTracer) DeliverMessage(*pubsub.Message)        {}
+func (m *meshTracer) RejectMessage(*pubsub.Message, string) {}
+func (m *meshTracer) DuplicateMessage(*pubsub.Message)      {}
+func (m *meshTracer) ThrottlePeer(peer.ID)                  {}
+func (m *meshTracer) RecvRPC(*pubsub.RPC)                   {}
+func (m *meshTracer) SendRPC(*pubsub.RPC, peer.ID)          {}
+func (m *meshTracer) DropRPC(*pubsub.RPC, peer.ID)          {}
+func (m *meshTracer) UndeliverableMessage(*pubsub.Message)  {}
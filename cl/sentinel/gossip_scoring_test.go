@@ -0,0 +1,72 @@
+package sentinel
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+)
+
+func TestGossipGraylist(t *testing.T) {
+	s := &Sentinel{cfg: &SentinelConfig{GossipScoring: clparams.DefaultGossipScoringConfig}}
+	pid := peer.ID("peer")
+
+	require.Zero(t, s.appSpecificScore(pid))
+	require.NoError(t, s.Graylist(pid))
+	require.True(t, s.IsGraylisted(pid))
+	// even a peer with every topic score maxed out must stay graylisted
+	require.Less(t, s.appSpecificScore(pid)+topicScoreCap, s.cfg.GossipScoring.GraylistThreshold)
+
+	s.RemoveFromGraylist(pid)
+	require.False(t, s.IsGraylisted(pid))
+	require.Zero(t, s.appSpecificScore(pid))
+
+	s.cfg.GossipScoring.Disabled = true
+	require.Error(t, s.Graylist(pid))
+}
+
+func TestMeshTracer(t *testing.T) {
+	topic := "/eth2/6a95a1a9/beacon_attestation_3/ssz_snappy"
+	require.Equal(t, "beacon_attestation_3", gossipTopicLabel(topic))
+
+	m := newMeshTracer()
+	// grafts on topics we did not join are not tracked
+	m.Graft("a", topic)
+	require.Zero(t, m.MeshPeers(topic))
+
+	m.Join(topic)
+	m.Graft("a", topic)
+	m.Graft("b", topic)
+	m.Graft("b", topic)
+	require.Equal(t, 2, m.MeshPeers(topic))
+	m.Prune("a", topic)
+	require.Equal(t, 1, m.MeshPeers(topic))
+	m.RemovePeer("b")
+	require.Zero(t, m.MeshPeers(topic))
+
+	m.Graft("a", topic)
+	m.Leave(topic)
+	require.Zero(t, m.MeshPeers(topic))
+}
+
+func TestGossipScoringConfigValidate(t *testing.T) {
+	require.NoError(t, clparams.DefaultGossipScoringConfig.Validate())
+
+	cfg := clparams.DefaultGossipScoringConfig
+	cfg.GossipThreshold = 1
+	require.Error(t, cfg.Validate())
+
+	cfg = clparams.DefaultGossipScoringConfig
+	cfg.PublishThreshold = cfg.GossipThreshold + 1
+	require.Error(t, cfg.Validate())
+
+	cfg = clparams.DefaultGossipScoringConfig
+	cfg.GraylistThreshold = cfg.PublishThreshold + 1
+	require.Error(t, cfg.Validate())
+
+	cfg = clparams.DefaultGossipScoringConfig
+	cfg.IPColocationFactorThreshold = 0
+	require.Error(t, cfg.Validate())
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+// maximum score a peer can earn from all topics together
+const topicScoreCap = 32.72
+
 // determines the decay rate from the provided time period till
 // the decayToZero value. Ex: ( 1 -> 0.01)
 func (s *Sentinel) scoreDecay(totalDurationDecay time.Duration) float64 {
@@ -16,22 +19,35 @@ func (s *Sentinel) scoreDecay(totalDurationDecay time.Duration) float64 {
 }
 
 func (s *Sentinel) pubsubOptions() []pubsub.Option {
+	pubsubQueueSize := 600
+	psOpts := []pubsub.Option{
+		pubsub.WithMessageSignaturePolicy(pubsub.StrictNoSign),
+		pubsub.WithMessageIdFn(s.msgId),
+		pubsub.WithNoAuthor(),
+		pubsub.WithPeerOutboundQueueSize(pubsubQueueSize),
+		pubsub.WithMaxMessageSize(int(s.cfg.NetworkConfig.GossipMaxSizeBellatrix)),
+		pubsub.WithValidateQueueSize(pubsubQueueSize),
+		pubsub.WithGossipSubParams(pubsubGossipParam()),
+		pubsub.WithRawTracer(s.meshTracer),
+	}
+	if s.cfg.GossipScoring.Disabled {
+		return psOpts
+	}
+	scoring := s.cfg.GossipScoring
 	thresholds := &pubsub.PeerScoreThresholds{
-		GossipThreshold:             -4000,
-		PublishThreshold:            -8000,
-		GraylistThreshold:           -16000,
+		GossipThreshold:             scoring.GossipThreshold,
+		PublishThreshold:            scoring.PublishThreshold,
+		GraylistThreshold:           scoring.GraylistThreshold,
 		AcceptPXThreshold:           100,
 		OpportunisticGraftThreshold: 5,
 	}
 	scoreParams := &pubsub.PeerScoreParams{
-		Topics:        make(map[string]*pubsub.TopicScoreParams),
-		TopicScoreCap: 32.72,
-		AppSpecificScore: func(p peer.ID) float64 {
-			return 0
-		},
+		Topics:                      make(map[string]*pubsub.TopicScoreParams),
+		TopicScoreCap:               topicScoreCap,
+		AppSpecificScore:            s.appSpecificScore,
 		AppSpecificWeight:           1,
 		IPColocationFactorWeight:    -35.11,
-		IPColocationFactorThreshold: 10,
+		IPColocationFactorThreshold: scoring.IPColocationFactorThreshold,
 		IPColocationFactorWhitelist: nil,
 		BehaviourPenaltyWeight:      -15.92,
 		BehaviourPenaltyThreshold:   6,
@@ -40,18 +56,18 @@ func (s *Sentinel) pubsubOptions() []pubsub.Option {
 		DecayToZero:                 decayToZero,
 		RetainScore:                 100 * s.oneEpochDuration(), // Retain for 100 epochs
 	}
-	pubsubQueueSize := 600
-	psOpts := []pubsub.Option{
-		pubsub.WithMessageSignaturePolicy(pubsub.StrictNoSign),
-		pubsub.WithMessageIdFn(s.msgId),
-		pubsub.WithNoAuthor(),
-		pubsub.WithPeerOutboundQueueSize(pubsubQueueSize),
-		pubsub.WithMaxMessageSize(int(s.cfg.NetworkConfig.GossipMaxSizeBellatrix)),
-		pubsub.WithValidateQueueSize(pubsubQueueSize),
+	return append(psOpts,
 		pubsub.WithPeerScore(scoreParams, thresholds),
-		pubsub.WithGossipSubParams(pubsubGossipParam()),
+		pubsub.WithPeerScoreInspect(pubsub.ExtendedPeerScoreInspectFn(s.inspectPeerScores), s.oneEpochDuration()),
+	)
+}
+
+// appSpecificScore sinks graylisted peers below the graylist threshold, whatever they earned on topics.
+func (s *Sentinel) appSpecificScore(p peer.ID) float64 {
+	if _, ok := s.graylist.Load(p); ok {
+		return s.cfg.GossipScoring.GraylistThreshold - 2*topicScoreCap
 	}
-	return psOpts
+	return 0
 }
 
 // creates a custom gossipsub parameter set.
@@ -86,11 +86,12 @@ func (p *Pool) SetBanStatus(pid peer.ID, banned bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if banned {
-		p.bannedPeers.Store(pid, struct{}{})
-		p.bannedPeersCount.Add(1)
+		if _, loaded := p.bannedPeers.LoadOrStore(pid, struct{}{}); !loaded {
+			p.bannedPeersCount.Add(1)
+		}
 		delete(p.peerData, pid)
-	} else {
-		p.bannedPeers.Delete(pid)
+	} else if _, loaded := p.bannedPeers.LoadAndDelete(pid); loaded {
+		p.bannedPeersCount.Add(-1)
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	"github.com/ledgerwatch/erigon/cl/phase1/forkchoice"
 	"github.com/ledgerwatch/erigon/cl/sentinel/handlers"
@@ -92,6 +93,9 @@ type Sentinel struct {
 	forkChoiceReader forkchoice.ForkChoiceStorageReader
 	pidToEnr         sync.Map
 	ethClock         eth_clock.EthereumClock
+	// graylisted peers get a score below the graylist threshold, so gossipsub ignores them
+	graylist   sync.Map
+	meshTracer *meshTracer
 
 	metadataLock sync.Mutex
 }
@@ -203,6 +207,14 @@ func New(
 		blobStorage:      blobStorage,
 		ethClock:         ethClock,
 	}
+	if cfg.GossipScoring == (clparams.GossipScoringConfig{}) {
+		cfg.GossipScoring = clparams.DefaultGossipScoringConfig
+	}
+	if !cfg.GossipScoring.Disabled {
+		if err := cfg.GossipScoring.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid gossip scoring config: %w", err)
+		}
+	}
 
 	// Setup discovery
 	enodes := make([]*enode.Node, len(cfg.NetworkConfig.BootNodes))
@@ -237,7 +249,9 @@ func New(
 	}
 	opts = append(opts, libp2p.ResourceManager(rmgr))
 
-	gater, err := NewGater(cfg)
+	s.peers = peers.NewPool()
+
+	gater, err := NewGater(cfg, s.peers)
 	if err != nil {
 		return nil, err
 	}
@@ -250,8 +264,6 @@ func New(
 	}
 	s.host = host
 
-	s.peers = peers.NewPool()
-
 	mux := chi.NewRouter()
 	//	mux := httpreqresp.NewRequestHandler(host)
 	mux.Get("/", httpreqresp.NewRequestHandler(host))
@@ -260,6 +272,7 @@ func New(
 	s.handshaker = handshake.New(ctx, s.ethClock, cfg.BeaconConfig, s.httpApi)
 
 	pubsub.TimeCacheDuration = 550 * gossipSubHeartbeatInterval
+	s.meshTracer = newMeshTracer()
 	s.pubsub, err = pubsub.NewGossipSub(s.ctx, s.host, s.pubsubOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("[Sentinel] failed to subscribe to gossip err=%w", err)
@@ -81,6 +81,37 @@ func (s *SentinelServer) BanPeer(_ context.Context, p *sentinelrpc.Peer) (*senti
 	return &sentinelrpc.EmptyMessage{}, nil
 }
 
+func (s *SentinelServer) UnbanPeer(_ context.Context, p *sentinelrpc.Peer) (*sentinelrpc.EmptyMessage, error) {
+	var pid peer.ID
+	if err := pid.UnmarshalText([]byte(p.Pid)); err != nil {
+		return nil, err
+	}
+	s.sentinel.Peers().SetBanStatus(pid, false)
+	return &sentinelrpc.EmptyMessage{}, nil
+}
+
+// PenalizePeer graylists the peer: gossip from and to it is ignored, but it stays connected.
+func (s *SentinelServer) PenalizePeer(_ context.Context, p *sentinelrpc.Peer) (*sentinelrpc.EmptyMessage, error) {
+	var pid peer.ID
+	if err := pid.UnmarshalText([]byte(p.Pid)); err != nil {
+		return nil, err
+	}
+	if err := s.sentinel.Graylist(pid); err != nil {
+		return nil, err
+	}
+	return &sentinelrpc.EmptyMessage{}, nil
+}
+
+// RewardPeer removes the peer from the graylist.
+func (s *SentinelServer) RewardPeer(_ context.Context, p *sentinelrpc.Peer) (*sentinelrpc.EmptyMessage, error) {
+	var pid peer.ID
+	if err := pid.UnmarshalText([]byte(p.Pid)); err != nil {
+		return nil, err
+	}
+	s.sentinel.RemoveFromGraylist(pid)
+	return &sentinelrpc.EmptyMessage{}, nil
+}
+
 func (s *SentinelServer) PublishGossip(_ context.Context, msg *sentinelrpc.GossipData) (*sentinelrpc.EmptyMessage, error) {
 	manager := s.sentinel.GossipManager()
 	// Snappify payload before sending it to gossip
@@ -83,11 +83,12 @@ func createSentinel(
 		sentinel.ProposerSlashingSsz,
 		sentinel.AttesterSlashingSsz,
 		sentinel.BlsToExecutionChangeSsz,
-		////sentinel.LightClientFinalityUpdateSsz,
-		////sentinel.LightClientOptimisticUpdateSsz,
 		sentinel.SyncCommitteeContributionAndProofSsz,
 		sentinel.BeaconAggregateAndProofSsz,
 	}
+	if cfg.EnableLightClient {
+		gossipTopics = append(gossipTopics, sentinel.LightClientFinalityUpdateSsz, sentinel.LightClientOptimisticUpdateSsz)
+	}
 	gossipTopics = append(
 		gossipTopics,
 		generateSubnetsTopics(
@@ -0,0 +1,197 @@
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/Giulio2002/bls"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/fork"
+	"github.com/ledgerwatch/erigon/cl/merkle_tree"
+	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
+	"github.com/ledgerwatch/erigon/cl/utils"
+)
+
+// Aggregators are selected by their selection proofs: signatures of slot (and sync subcommittee), so the selection is
+// known in advance and is verifiable by everyone. Aggregates are sent two thirds into slot, once attestations and sync
+// committee messages of the slot are collected by beacon node.
+
+type syncCommitteeSubscription struct {
+	ValidatorIndex       uint64   `json:"validator_index,string"`
+	SyncCommitteeIndices []string `json:"sync_committee_indices"`
+	UntilEpoch           uint64   `json:"until_epoch,string"`
+}
+
+// attestationSelectionProof - signature of slot, see get_slot_signature of the spec
+func (v *ValidatorClient) attestationSelectionProof(key *bls.PrivateKey, slot uint64) (libcommon.Bytes96, error) {
+	domain, err := v.domain(v.beaconCfg.DomainSelectionProof, slot/v.beaconCfg.SlotsPerEpoch)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	slotRoot := merkle_tree.Uint64Root(slot)
+	signingRoot := utils.Sha256(slotRoot[:], domain)
+	return libcommon.Bytes96(key.Sign(signingRoot[:]).Bytes()), nil
+}
+
+// syncSelectionProof - signature of slot and subcommittee, see get_sync_committee_selection_proof of the spec
+func (v *ValidatorClient) syncSelectionProof(key *bls.PrivateKey, slot, subcommitteeIndex uint64) (libcommon.Bytes96, error) {
+	domain, err := v.domain(v.beaconCfg.DomainSyncCommitteeSelectionProof, slot/v.beaconCfg.SlotsPerEpoch)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	signingRoot, err := fork.ComputeSigningRoot(&cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: subcommitteeIndex}, domain)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return libcommon.Bytes96(key.Sign(signingRoot[:]).Bytes()), nil
+}
+
+func (v *ValidatorClient) isSyncCommitteeAggregator(selectionProof libcommon.Bytes96) bool {
+	modulo := utils.Max64(1, v.beaconCfg.SyncCommitteeSize/v.beaconCfg.SyncCommitteeSubnetCount/v.beaconCfg.TargetAggregatorsPerSyncSubcommittee)
+	hash := utils.Sha256(selectionProof[:])
+	return binary.LittleEndian.Uint64(hash[:8])%modulo == 0
+}
+
+// selectAttestationAggregators - fills selection proofs of duties, subscriptions of aggregators make beacon node
+// collect attestations of their committees
+func (v *ValidatorClient) selectAttestationAggregators(duties []attesterDuty) error {
+	for i := range duties {
+		duty := &duties[i]
+		selectionProof, err := v.attestationSelectionProof(v.keys[duty.Pubkey], duty.Slot)
+		if err != nil {
+			return err
+		}
+		duty.selectionProof = selectionProof
+		duty.isAggregator = state.IsAggregator(v.beaconCfg, duty.CommitteeLength, duty.CommitteeIndex, selectionProof)
+	}
+	return nil
+}
+
+// subscribeSyncCommittees - beacon node collects sync committee messages of subnets of validators, contributions of
+// them are served to aggregators
+func (v *ValidatorClient) subscribeSyncCommittees(ctx context.Context, epoch uint64, duties []syncDuty) error {
+	subscriptions := make([]syncCommitteeSubscription, 0, len(duties))
+	for _, duty := range duties {
+		subscriptions = append(subscriptions, syncCommitteeSubscription{
+			ValidatorIndex:       duty.ValidatorIndex,
+			SyncCommitteeIndices: duty.ValidatorSyncCommitteeIndices,
+			UntilEpoch:           epoch + 1,
+		})
+	}
+	return v.beacon.post(ctx, "/eth/v1/validator/sync_committee_subscriptions", subscriptions, nil)
+}
+
+// aggregateAttestations - aggregators of committees sign aggregates of attestations with `committeesData` collected
+// by beacon node
+func (v *ValidatorClient) aggregateAttestations(ctx context.Context, slot uint64, duties []attesterDuty, committeesData map[uint64]solid.AttestationData) error {
+	var aggregates []*cltypes.SignedAggregateAndProof
+	for _, duty := range duties {
+		data, ok := committeesData[duty.CommitteeIndex]
+		if !duty.isAggregator || !ok {
+			continue
+		}
+		dataRoot, err := data.HashSSZ()
+		if err != nil {
+			return err
+		}
+		aggregate := &solid.Attestation{}
+		if err := v.beacon.get(ctx, fmt.Sprintf("/eth/v1/validator/aggregate_attestation?attestation_data_root=0x%x&slot=%d", dataRoot, slot), aggregate); err != nil {
+			return err
+		}
+		domain, err := v.domain(v.beaconCfg.DomainAggregateAndProof, slot/v.beaconCfg.SlotsPerEpoch)
+		if err != nil {
+			return err
+		}
+		message := &cltypes.AggregateAndProof{
+			AggregatorIndex: duty.ValidatorIndex,
+			Aggregate:       aggregate,
+			SelectionProof:  duty.selectionProof,
+		}
+		signingRoot, err := fork.ComputeSigningRoot(message, domain)
+		if err != nil {
+			return err
+		}
+		aggregates = append(aggregates, &cltypes.SignedAggregateAndProof{
+			Message:   message,
+			Signature: libcommon.Bytes96(v.keys[duty.Pubkey].Sign(signingRoot[:]).Bytes()),
+		})
+	}
+	if len(aggregates) == 0 {
+		return nil
+	}
+	if err := v.beacon.post(ctx, "/eth/v1/validator/aggregate_and_proofs", aggregates, nil); err != nil {
+		return err
+	}
+	v.logger.Debug("[ValidatorClient] aggregated attestations", "slot", slot, "aggregates", len(aggregates))
+	return nil
+}
+
+// sendSyncContributions - aggregators of sync subcommittees sign contributions of sync committee messages for
+// `blockRoot`
+func (v *ValidatorClient) sendSyncContributions(ctx context.Context, slot uint64, blockRoot libcommon.Hash, duties []syncDuty) error {
+	subcommitteeSize := v.beaconCfg.SyncCommitteeSize / v.beaconCfg.SyncCommitteeSubnetCount
+	domain, err := v.domain(v.beaconCfg.DomainContributionAndProof, slot/v.beaconCfg.SlotsPerEpoch)
+	if err != nil {
+		return err
+	}
+	var contributions []*cltypes.SignedContributionAndProof
+	for _, duty := range duties {
+		key := v.keys[duty.Pubkey]
+		// validator may be in the same subcommittee more than once
+		selected := map[uint64]bool{}
+		for _, index := range duty.ValidatorSyncCommitteeIndices {
+			committeeIndex, err := strconv.ParseUint(index, 10, 64)
+			if err != nil {
+				return err
+			}
+			subcommitteeIndex := committeeIndex / subcommitteeSize
+			if selected[subcommitteeIndex] {
+				continue
+			}
+			selected[subcommitteeIndex] = true
+			selectionProof, err := v.syncSelectionProof(key, slot, subcommitteeIndex)
+			if err != nil {
+				return err
+			}
+			if !v.isSyncCommitteeAggregator(selectionProof) {
+				continue
+			}
+			contribution := &cltypes.Contribution{}
+			if err := v.beacon.get(ctx, fmt.Sprintf("/eth/v1/validator/sync_committee_contribution?slot=%d&subcommittee_index=%d&beacon_block_root=0x%x", slot, subcommitteeIndex, blockRoot), contribution); err != nil {
+				return err
+			}
+			// contribution without participants is rejected by peers
+			if bytes.Equal(contribution.AggregationBits, make([]byte, len(contribution.AggregationBits))) {
+				continue
+			}
+			message := &cltypes.ContributionAndProof{
+				AggregatorIndex: duty.ValidatorIndex,
+				Contribution:    contribution,
+				SelectionProof:  selectionProof,
+			}
+			signingRoot, err := fork.ComputeSigningRoot(message, domain)
+			if err != nil {
+				return err
+			}
+			contributions = append(contributions, &cltypes.SignedContributionAndProof{
+				Message:   message,
+				Signature: libcommon.Bytes96(key.Sign(signingRoot[:]).Bytes()),
+			})
+		}
+	}
+	if len(contributions) == 0 {
+		return nil
+	}
+	if err := v.beacon.post(ctx, "/eth/v1/validator/contribution_and_proofs", contributions, nil); err != nil {
+		return err
+	}
+	v.logger.Debug("[ValidatorClient] sent sync contributions", "slot", slot, "contributions", len(contributions))
+	return nil
+}
@@ -0,0 +1,89 @@
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// handlerTransport - serves requests of validator client by beacon API handler of the same process, without listening
+// on a socket
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	t.handler.ServeHTTP(w, r)
+	return w.Result(), nil
+}
+
+// beaconClient - the subset of standard beacon API used by validator client
+type beaconClient struct {
+	client *http.Client
+}
+
+func newBeaconClient(handler http.Handler) *beaconClient {
+	return &beaconClient{client: &http.Client{Transport: handlerTransport{handler: handler}}}
+}
+
+type beaconResponse struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// do - sends request with JSON body (if not nil), decodes `data` of response into out (if not nil). Returns
+// response headers
+func (c *beaconClient) do(ctx context.Context, method, path string, headers map[string]string, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://caplin"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return resp.Header, nil
+	}
+	var wrapped beaconResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wrapped); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if err := json.Unmarshal(wrapped.Data, out); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	return resp.Header, nil
+}
+
+func (c *beaconClient) get(ctx context.Context, path string, out any) error {
+	_, err := c.do(ctx, http.MethodGet, path, nil, nil, out)
+	return err
+}
+
+func (c *beaconClient) post(ctx context.Context, path string, body, out any) error {
+	_, err := c.do(ctx, http.MethodPost, path, nil, body, out)
+	return err
+}
@@ -0,0 +1,200 @@
+package validator_client
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Giulio2002/bls"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+)
+
+var ErrWrongPassword = errors.New("keystore checksum mismatch, wrong password")
+
+// Keystore is an EIP-2335 keystore, as produced by staking-deposit-cli and other key generators.
+type Keystore struct {
+	Crypto struct {
+		Kdf      keystoreModule `json:"kdf"`
+		Checksum keystoreModule `json:"checksum"`
+		Cipher   keystoreModule `json:"cipher"`
+	} `json:"crypto"`
+	Pubkey  string `json:"pubkey"`
+	Path    string `json:"path"`
+	UUID    string `json:"uuid"`
+	Version int    `json:"version"`
+}
+
+type keystoreModule struct {
+	Function string          `json:"function"`
+	Params   json.RawMessage `json:"params"`
+	Message  string          `json:"message"`
+}
+
+type scryptParams struct {
+	Dklen int    `json:"dklen"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	Dklen int    `json:"dklen"`
+	C     int    `json:"c"`
+	Prf   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+type cipherParams struct {
+	Iv string `json:"iv"`
+}
+
+// Decrypt returns the BLS secret key of the keystore.
+func (k *Keystore) Decrypt(password string) (*bls.PrivateKey, error) {
+	if k.Version != 4 {
+		return nil, fmt.Errorf("unsupported keystore version %d", k.Version)
+	}
+	decryptionKey, err := k.decryptionKey(normalizePassword(password))
+	if err != nil {
+		return nil, err
+	}
+	cipherMessage, err := hex.DecodeString(k.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher message: %w", err)
+	}
+	checksum, err := hex.DecodeString(k.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum message: %w", err)
+	}
+	if k.Crypto.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("unsupported checksum function %s", k.Crypto.Checksum.Function)
+	}
+	expected := sha256.Sum256(append(libcommon.Copy(decryptionKey[16:32]), cipherMessage...))
+	if !bytes.Equal(expected[:], checksum) {
+		return nil, ErrWrongPassword
+	}
+
+	if k.Crypto.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported cipher function %s", k.Crypto.Cipher.Function)
+	}
+	var params cipherParams
+	if err := json.Unmarshal(k.Crypto.Cipher.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid cipher params: %w", err)
+	}
+	iv, err := hex.DecodeString(params.Iv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher iv: %w", err)
+	}
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid cipher iv length %d", len(iv))
+	}
+	secret := make([]byte, len(cipherMessage))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherMessage)
+	key, err := bls.NewPrivateKeyFromBytes(secret)
+	if err != nil {
+		return nil, err
+	}
+	if k.Pubkey != "" {
+		pubkey, err := hex.DecodeString(strings.TrimPrefix(k.Pubkey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pubkey: %w", err)
+		}
+		if !bytes.Equal(bls.CompressPublicKey(key.PublicKey()), pubkey) {
+			return nil, fmt.Errorf("secret key doesn't match pubkey %s", k.Pubkey)
+		}
+	}
+	return key, nil
+}
+
+func (k *Keystore) decryptionKey(password []byte) ([]byte, error) {
+	switch k.Crypto.Kdf.Function {
+	case "scrypt":
+		var params scryptParams
+		if err := json.Unmarshal(k.Crypto.Kdf.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid scrypt params: %w", err)
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrypt salt: %w", err)
+		}
+		if params.Dklen < 32 {
+			return nil, fmt.Errorf("scrypt dklen %d is too short", params.Dklen)
+		}
+		return scrypt.Key(password, salt, params.N, params.R, params.P, params.Dklen)
+	case "pbkdf2":
+		var params pbkdf2Params
+		if err := json.Unmarshal(k.Crypto.Kdf.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 params: %w", err)
+		}
+		if params.Prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %s", params.Prf)
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 salt: %w", err)
+		}
+		if params.Dklen < 32 {
+			return nil, fmt.Errorf("pbkdf2 dklen %d is too short", params.Dklen)
+		}
+		return pbkdf2.Key(password, salt, params.C, params.Dklen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf function %s", k.Crypto.Kdf.Function)
+	}
+}
+
+// normalizePassword - NFKD form without control codes, as required by EIP-2335
+func normalizePassword(password string) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password)))
+}
+
+// LoadKeystores decrypts all keystores (*.json files) of directory with the same password, which is read from
+// passwordFile with trailing newlines trimmed.
+func LoadKeystores(dir string, passwordFile string) ([]*bls.PrivateKey, error) {
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read keystores password: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*bls.PrivateKey, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var keystore Keystore
+		if err := json.Unmarshal(data, &keystore); err != nil {
+			return nil, fmt.Errorf("invalid keystore %s: %w", file, err)
+		}
+		key, err := keystore.Decrypt(strings.TrimRight(string(password), "\r\n"))
+		if err != nil {
+			return nil, fmt.Errorf("can't decrypt keystore %s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
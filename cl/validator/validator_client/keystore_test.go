@@ -0,0 +1,76 @@
+package validator_client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+)
+
+// pbkdf2 test vector of EIP-2335
+const testKeystore = `{
+	"crypto": {
+		"kdf": {
+			"function": "pbkdf2",
+			"params": {
+				"dklen": 32,
+				"c": 262144,
+				"prf": "hmac-sha256",
+				"salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+			},
+			"message": ""
+		},
+		"checksum": {
+			"function": "sha256",
+			"params": {},
+			"message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"
+		},
+		"cipher": {
+			"function": "aes-128-ctr",
+			"params": {
+				"iv": "264daa3f303d7259501c93d997d84fe6"
+			},
+			"message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"
+		}
+	},
+	"description": "This is a test keystore that uses PBKDF2 to secure the secret.",
+	"pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+	"path": "m/12381/60/0/0",
+	"uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+	"version": 4
+}`
+
+const testKeystorePassword = "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑"
+
+func TestKeystoreDecrypt(t *testing.T) {
+	var keystore Keystore
+	require.NoError(t, json.Unmarshal([]byte(testKeystore), &keystore))
+
+	key, err := keystore.Decrypt(testKeystorePassword)
+	require.NoError(t, err)
+	require.Equal(t, libcommon.FromHex("0x000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"), key.Bytes())
+
+	// NFKD normalized password with control codes
+	key, err = keystore.Decrypt("testpassword\x7f🔑")
+	require.NoError(t, err)
+	require.Equal(t, libcommon.FromHex("0x000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"), key.Bytes())
+
+	_, err = keystore.Decrypt("wrong")
+	require.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestLoadKeystores(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore-m_12381_3600_0_0_0.json"), []byte(testKeystore), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deposit_data.txt"), []byte("not a keystore"), 0600))
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte(testKeystorePassword+"\n"), 0600))
+
+	keys, err := LoadKeystores(dir, passwordFile)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
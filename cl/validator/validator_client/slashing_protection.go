@@ -0,0 +1,64 @@
+package validator_client
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+var ErrSlashable = errors.New("slashing protection")
+
+// SlashingProtection - refuses to sign messages which could be slashable together with messages signed before.
+// Only the highest signed block and attestation of every validator are kept, which is the minimal strategy of EIP-3076:
+// blocks must be strictly increasing by slot, attestations by source and target epoch (re-signing of exactly the
+// same message is allowed). Surrounding and surrounded votes are refused by the same rules.
+type SlashingProtection struct {
+	db kv.RwDB
+}
+
+func NewSlashingProtection(db kv.RwDB) *SlashingProtection {
+	return &SlashingProtection{db: db}
+}
+
+// CheckAndRecordBlock - error if block of slot can't be signed, otherwise it's recorded as signed
+func (s *SlashingProtection) CheckAndRecordBlock(ctx context.Context, pubkey libcommon.Bytes48, slot uint64, signingRoot libcommon.Hash) error {
+	return s.db.Update(ctx, func(tx kv.RwTx) error {
+		v, err := tx.GetOne(kv.ValidatorSignedBlocks, pubkey[:])
+		if err != nil {
+			return err
+		}
+		if len(v) == 8+32 {
+			lastSlot := binary.BigEndian.Uint64(v)
+			if slot < lastSlot || (slot == lastSlot && libcommon.BytesToHash(v[8:]) != signingRoot) {
+				return fmt.Errorf("%w: block of slot %d, already signed block of slot %d", ErrSlashable, slot, lastSlot)
+			}
+		}
+		return tx.Put(kv.ValidatorSignedBlocks, pubkey[:], append(binary.BigEndian.AppendUint64(nil, slot), signingRoot[:]...))
+	})
+}
+
+// CheckAndRecordAttestation - error if attestation of source and target epochs can't be signed, otherwise it's
+// recorded as signed
+func (s *SlashingProtection) CheckAndRecordAttestation(ctx context.Context, pubkey libcommon.Bytes48, source, target uint64, signingRoot libcommon.Hash) error {
+	if source > target {
+		return fmt.Errorf("%w: source epoch %d is after target epoch %d", ErrSlashable, source, target)
+	}
+	return s.db.Update(ctx, func(tx kv.RwTx) error {
+		v, err := tx.GetOne(kv.ValidatorSignedAttestations, pubkey[:])
+		if err != nil {
+			return err
+		}
+		if len(v) == 8+8+32 {
+			lastSource, lastTarget := binary.BigEndian.Uint64(v), binary.BigEndian.Uint64(v[8:])
+			if source < lastSource || target < lastTarget || (target == lastTarget && libcommon.BytesToHash(v[16:]) != signingRoot) {
+				return fmt.Errorf("%w: attestation %d=>%d, already signed attestation %d=>%d", ErrSlashable, source, target, lastSource, lastTarget)
+			}
+		}
+		value := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, source), target)
+		return tx.Put(kv.ValidatorSignedAttestations, pubkey[:], append(value, signingRoot[:]...))
+	})
+}
@@ -0,0 +1,45 @@
+package validator_client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+)
+
+func TestSlashingProtectionBlocks(t *testing.T) {
+	ctx := context.Background()
+	s := NewSlashingProtection(memdb.NewTestDB(t))
+	pubkey, other := libcommon.Bytes48{1}, libcommon.Bytes48{2}
+
+	require.NoError(t, s.CheckAndRecordBlock(ctx, pubkey, 10, libcommon.Hash{1}))
+	// the same block can be signed again
+	require.NoError(t, s.CheckAndRecordBlock(ctx, pubkey, 10, libcommon.Hash{1}))
+	// double proposal
+	require.ErrorIs(t, s.CheckAndRecordBlock(ctx, pubkey, 10, libcommon.Hash{2}), ErrSlashable)
+	// lower slot
+	require.ErrorIs(t, s.CheckAndRecordBlock(ctx, pubkey, 9, libcommon.Hash{3}), ErrSlashable)
+	require.NoError(t, s.CheckAndRecordBlock(ctx, pubkey, 11, libcommon.Hash{4}))
+	// other validator is independent
+	require.NoError(t, s.CheckAndRecordBlock(ctx, other, 9, libcommon.Hash{3}))
+}
+
+func TestSlashingProtectionAttestations(t *testing.T) {
+	ctx := context.Background()
+	s := NewSlashingProtection(memdb.NewTestDB(t))
+	pubkey := libcommon.Bytes48{1}
+
+	require.NoError(t, s.CheckAndRecordAttestation(ctx, pubkey, 2, 4, libcommon.Hash{1}))
+	require.NoError(t, s.CheckAndRecordAttestation(ctx, pubkey, 2, 4, libcommon.Hash{1}))
+	// double vote
+	require.ErrorIs(t, s.CheckAndRecordAttestation(ctx, pubkey, 2, 4, libcommon.Hash{2}), ErrSlashable)
+	// surrounding vote
+	require.ErrorIs(t, s.CheckAndRecordAttestation(ctx, pubkey, 1, 5, libcommon.Hash{3}), ErrSlashable)
+	// surrounded vote
+	require.ErrorIs(t, s.CheckAndRecordAttestation(ctx, pubkey, 3, 3, libcommon.Hash{4}), ErrSlashable)
+	require.ErrorIs(t, s.CheckAndRecordAttestation(ctx, pubkey, 5, 4, libcommon.Hash{5}), ErrSlashable)
+	require.NoError(t, s.CheckAndRecordAttestation(ctx, pubkey, 4, 5, libcommon.Hash{6}))
+}
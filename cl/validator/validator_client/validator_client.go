@@ -0,0 +1,517 @@
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/ledgerwatch/log/v3"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/types/ssz"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/fork"
+	"github.com/ledgerwatch/erigon/cl/merkle_tree"
+	"github.com/ledgerwatch/erigon/cl/utils"
+	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
+)
+
+var ErrDoppelganger = errors.New("validator is already live, another validator client is running the same keys")
+
+type Config struct {
+	Keys         []*bls.PrivateKey
+	FeeRecipient libcommon.Address
+	// DoppelgangerEpochs - validators sign nothing for that many epochs after start, if any of them is live meanwhile
+	// validator client stops. 0 disables doppelganger protection
+	DoppelgangerEpochs uint64
+}
+
+// ValidatorClient - validator client embedded into Caplin, it performs duties of loaded keys through standard beacon
+// API served by beacon API handler of the same process: block proposals, attestations, sync committee messages and
+// their aggregation. Every block and attestation is checked by slashing protection before signing.
+type ValidatorClient struct {
+	cfg        Config
+	beaconCfg  *clparams.BeaconChainConfig
+	ethClock   eth_clock.EthereumClock
+	beacon     *beaconClient
+	protection *SlashingProtection
+	logger     log.Logger
+
+	keys     map[libcommon.Bytes48]*bls.PrivateKey
+	indicies map[libcommon.Bytes48]uint64
+	// duties of dutiesEpoch
+	dutiesEpoch    uint64
+	attesterDuties map[uint64][]attesterDuty // slot => duties
+	proposerDuties map[uint64]proposerDuty   // slot => duty
+	syncDuties     []syncDuty
+}
+
+type validatorResponse struct {
+	Index     uint64 `json:"index,string"`
+	Status    string `json:"status"`
+	Validator struct {
+		Pubkey libcommon.Bytes48 `json:"pubkey"`
+	} `json:"validator"`
+}
+
+type attesterDuty struct {
+	Pubkey                  libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex          uint64            `json:"validator_index,string"`
+	CommitteeIndex          uint64            `json:"committee_index,string"`
+	CommitteeLength         uint64            `json:"committee_length,string"`
+	ValidatorCommitteeIndex uint64            `json:"validator_committee_index,string"`
+	CommitteesAtSlot        uint64            `json:"committees_at_slot,string"`
+	Slot                    uint64            `json:"slot,string"`
+
+	selectionProof libcommon.Bytes96
+	isAggregator   bool
+}
+
+type proposerDuty struct {
+	Pubkey         libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex uint64            `json:"validator_index,string"`
+	Slot           uint64            `json:"slot,string"`
+}
+
+type syncDuty struct {
+	Pubkey                        libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex                uint64            `json:"validator_index,string"`
+	ValidatorSyncCommitteeIndices []string          `json:"validator_sync_committee_indices"`
+}
+
+type livenessResponse struct {
+	Index  uint64 `json:"index,string"`
+	IsLive bool   `json:"is_live"`
+}
+
+type feeRecipientPreparation struct {
+	ValidatorIndex uint64            `json:"validator_index,string"`
+	FeeRecipient   libcommon.Address `json:"fee_recipient"`
+}
+
+// NewValidatorClient - beaconApi must serve beacon and validator endpoints
+func NewValidatorClient(cfg Config, beaconCfg *clparams.BeaconChainConfig, ethClock eth_clock.EthereumClock, beaconApi http.Handler, protection *SlashingProtection, logger log.Logger) *ValidatorClient {
+	keys := make(map[libcommon.Bytes48]*bls.PrivateKey, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		keys[libcommon.Bytes48(bls.CompressPublicKey(key.PublicKey()))] = key
+	}
+	return &ValidatorClient{
+		cfg:        cfg,
+		beaconCfg:  beaconCfg,
+		ethClock:   ethClock,
+		beacon:     newBeaconClient(beaconApi),
+		protection: protection,
+		logger:     logger,
+		keys:       keys,
+		indicies:   map[libcommon.Bytes48]uint64{},
+	}
+}
+
+// Run - performs duties until ctx is done, returns ErrDoppelganger if keys are used by another validator client
+func (v *ValidatorClient) Run(ctx context.Context) error {
+	v.logger.Info("[ValidatorClient] starting", "keys", len(v.keys))
+	for {
+		if err := v.resolveIndicies(ctx); err == nil {
+			break
+		} else {
+			v.logger.Debug("[ValidatorClient] waiting for beacon node", "err", err)
+		}
+		if err := v.sleepUntil(ctx, v.ethClock.GetSlotTime(v.ethClock.GetCurrentSlot()+1)); err != nil {
+			return err
+		}
+	}
+	if err := v.checkDoppelganger(ctx); err != nil {
+		return err
+	}
+
+	for {
+		slot := v.ethClock.GetCurrentSlot() + 1
+		if err := v.sleepUntil(ctx, v.ethClock.GetSlotTime(slot)); err != nil {
+			return err
+		}
+		epoch := slot / v.beaconCfg.SlotsPerEpoch
+		if v.attesterDuties == nil || v.dutiesEpoch != epoch {
+			if err := v.updateDuties(ctx, epoch); err != nil {
+				v.logger.Warn("[ValidatorClient] can't update duties", "epoch", epoch, "err", err)
+				continue
+			}
+		}
+		go v.performDuties(ctx, slot, v.proposerDuties[slot], v.attesterDuties[slot], v.syncDuties)
+	}
+}
+
+func (v *ValidatorClient) sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// resolveIndicies - finds indicies of keys among validators of head state, keys which are not deposited yet are
+// resolved on later epochs
+func (v *ValidatorClient) resolveIndicies(ctx context.Context) error {
+	ids := make([]string, 0, len(v.keys))
+	for pubkey := range v.keys {
+		if _, ok := v.indicies[pubkey]; !ok {
+			ids = append(ids, pubkey.Hex())
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var validators []validatorResponse
+	if err := v.beacon.post(ctx, "/eth/v1/beacon/states/head/validators", map[string][]string{"ids": ids}, &validators); err != nil {
+		return err
+	}
+	preparations := make([]feeRecipientPreparation, 0, len(validators))
+	for _, validator := range validators {
+		v.indicies[validator.Validator.Pubkey] = validator.Index
+		preparations = append(preparations, feeRecipientPreparation{ValidatorIndex: validator.Index, FeeRecipient: v.cfg.FeeRecipient})
+		v.logger.Info("[ValidatorClient] validator", "index", validator.Index, "pubkey", validator.Validator.Pubkey, "status", validator.Status)
+	}
+	if len(v.indicies) < len(v.keys) {
+		v.logger.Info("[ValidatorClient] some keys are not deposited yet", "unknown", len(v.keys)-len(v.indicies))
+	}
+	if v.cfg.FeeRecipient != (libcommon.Address{}) && len(preparations) > 0 {
+		return v.beacon.post(ctx, "/eth/v1/validator/prepare_beacon_proposer", preparations, nil)
+	}
+	return nil
+}
+
+func (v *ValidatorClient) indiciesList() []string {
+	indicies := make([]string, 0, len(v.indicies))
+	for _, index := range v.indicies {
+		indicies = append(indicies, strconv.FormatUint(index, 10))
+	}
+	return indicies
+}
+
+// checkDoppelganger - waits cfg.DoppelgangerEpochs epochs without signing anything, validators must not be live
+// during them
+func (v *ValidatorClient) checkDoppelganger(ctx context.Context) error {
+	if v.cfg.DoppelgangerEpochs == 0 || len(v.indicies) == 0 {
+		return nil
+	}
+	start := v.ethClock.GetCurrentEpoch()
+	v.logger.Info("[ValidatorClient] doppelganger protection, duties are delayed", "epochs", v.cfg.DoppelgangerEpochs)
+	for epoch := start; epoch < start+v.cfg.DoppelgangerEpochs; epoch++ {
+		// liveness of epoch is final once the next one is over: attestations are included within an epoch
+		if err := v.sleepUntil(ctx, v.ethClock.GetSlotTime((epoch+2)*v.beaconCfg.SlotsPerEpoch)); err != nil {
+			return err
+		}
+		var liveness []livenessResponse
+		if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/liveness/%d", epoch), v.indiciesList(), &liveness); err != nil {
+			return err
+		}
+		for _, l := range liveness {
+			if l.IsLive {
+				return fmt.Errorf("%w: index %d, epoch %d", ErrDoppelganger, l.Index, epoch)
+			}
+		}
+	}
+	v.logger.Info("[ValidatorClient] doppelganger protection passed")
+	return nil
+}
+
+func (v *ValidatorClient) updateDuties(ctx context.Context, epoch uint64) error {
+	if len(v.indicies) < len(v.keys) {
+		if err := v.resolveIndicies(ctx); err != nil {
+			return err
+		}
+	}
+	indicies := v.indiciesList()
+	if len(indicies) == 0 {
+		return errors.New("no deposited validators")
+	}
+
+	var attesterDuties []attesterDuty
+	if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/attester/%d", epoch), indicies, &attesterDuties); err != nil {
+		return err
+	}
+	var proposerDuties []proposerDuty
+	if err := v.beacon.get(ctx, fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch), &proposerDuties); err != nil {
+		return err
+	}
+	var syncDuties []syncDuty
+	if v.beaconCfg.GetCurrentStateVersion(epoch) >= clparams.AltairVersion {
+		if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/sync/%d", epoch), indicies, &syncDuties); err != nil {
+			return err
+		}
+	}
+
+	if err := v.selectAttestationAggregators(attesterDuties); err != nil {
+		return err
+	}
+
+	v.dutiesEpoch = epoch
+	v.attesterDuties = map[uint64][]attesterDuty{}
+	subscriptions := make([]cltypes.BeaconCommitteeSubscription, 0, len(attesterDuties))
+	for _, duty := range attesterDuties {
+		v.attesterDuties[duty.Slot] = append(v.attesterDuties[duty.Slot], duty)
+		subscriptions = append(subscriptions, cltypes.BeaconCommitteeSubscription{
+			ValidatorIndex:   duty.ValidatorIndex,
+			CommitteeIndex:   duty.CommitteeIndex,
+			CommitteesAtSlot: duty.CommitteesAtSlot,
+			Slot:             duty.Slot,
+			IsAggregator:     duty.isAggregator,
+		})
+	}
+	v.proposerDuties = map[uint64]proposerDuty{}
+	for _, duty := range proposerDuties {
+		if _, ok := v.keys[duty.Pubkey]; ok {
+			v.proposerDuties[duty.Slot] = duty
+		}
+	}
+	v.syncDuties = syncDuties
+	if len(syncDuties) > 0 {
+		if err := v.subscribeSyncCommittees(ctx, epoch, syncDuties); err != nil {
+			v.logger.Warn("[ValidatorClient] can't subscribe to sync committees", "err", err)
+		}
+	}
+	if err := v.registerValidators(ctx); err != nil {
+		v.logger.Warn("[ValidatorClient] can't register validators with builder", "err", err)
+	}
+	v.logger.Debug("[ValidatorClient] duties", "epoch", epoch, "attestations", len(attesterDuties), "proposals", len(v.proposerDuties), "sync", len(syncDuties))
+	if len(subscriptions) > 0 {
+		return v.beacon.post(ctx, "/eth/v1/validator/beacon_committee_subscriptions", subscriptions, nil)
+	}
+	return nil
+}
+
+// performDuties - block is proposed at the start of slot, attestations and sync committee messages are sent a third
+// into slot, their aggregates two thirds into slot
+func (v *ValidatorClient) performDuties(ctx context.Context, slot uint64, proposer proposerDuty, attesters []attesterDuty, sync []syncDuty) {
+	if proposer.Slot == slot {
+		if err := v.propose(ctx, slot, proposer); err != nil {
+			v.logger.Warn("[ValidatorClient] block proposal failed", "slot", slot, "index", proposer.ValidatorIndex, "err", err)
+		}
+	}
+	if len(attesters) == 0 && len(sync) == 0 {
+		return
+	}
+	slotTime, slotDuration := v.ethClock.GetSlotTime(slot), time.Duration(v.beaconCfg.SecondsPerSlot)*time.Second
+	if err := v.sleepUntil(ctx, slotTime.Add(slotDuration/3)); err != nil {
+		return
+	}
+	var (
+		committeesData map[uint64]solid.AttestationData
+		syncBlockRoot  libcommon.Hash
+		err            error
+	)
+	if len(attesters) > 0 {
+		if committeesData, err = v.attest(ctx, slot, attesters); err != nil {
+			v.logger.Warn("[ValidatorClient] attestation failed", "slot", slot, "err", err)
+		}
+	}
+	if len(sync) > 0 {
+		if syncBlockRoot, err = v.sendSyncCommitteeMessages(ctx, slot, sync); err != nil {
+			v.logger.Warn("[ValidatorClient] sync committee messages failed", "slot", slot, "err", err)
+			sync = nil
+		}
+	}
+	if len(committeesData) == 0 && len(sync) == 0 {
+		return
+	}
+	if err := v.sleepUntil(ctx, slotTime.Add(slotDuration*2/3)); err != nil {
+		return
+	}
+	if len(committeesData) > 0 {
+		if err := v.aggregateAttestations(ctx, slot, attesters, committeesData); err != nil {
+			v.logger.Warn("[ValidatorClient] attestation aggregation failed", "slot", slot, "err", err)
+		}
+	}
+	if len(sync) > 0 {
+		if err := v.sendSyncContributions(ctx, slot, syncBlockRoot, sync); err != nil {
+			v.logger.Warn("[ValidatorClient] sync contributions failed", "slot", slot, "err", err)
+		}
+	}
+}
+
+func (v *ValidatorClient) domain(domainType libcommon.Bytes4, epoch uint64) ([]byte, error) {
+	forkVersion := utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(v.beaconCfg.GetCurrentStateVersion(epoch)))
+	return fork.ComputeDomain(domainType[:], forkVersion, v.ethClock.GenesisValidatorsRoot())
+}
+
+func (v *ValidatorClient) propose(ctx context.Context, slot uint64, duty proposerDuty) error {
+	key := v.keys[duty.Pubkey]
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	randaoDomain, err := v.domain(v.beaconCfg.DomainRandao, epoch)
+	if err != nil {
+		return err
+	}
+	epochRoot := merkle_tree.Uint64Root(epoch)
+	randaoRoot := utils.Sha256(epochRoot[:], randaoDomain)
+	randaoReveal := key.Sign(randaoRoot[:]).Bytes()
+
+	var rawBlock json.RawMessage
+	headers, err := v.beacon.do(ctx, http.MethodGet, fmt.Sprintf("/eth/v3/validator/blocks/%d?randao_reveal=0x%x", slot, randaoReveal), nil, nil, &rawBlock)
+	if err != nil {
+		return err
+	}
+	version, err := clparams.StringToClVersion(headers.Get("Eth-Consensus-Version"))
+	if err != nil {
+		return err
+	}
+	// payload of blinded block is built by the builder relay of beacon node, it is revealed once the block is signed
+	blinded, _ := strconv.ParseBool(headers.Get("Eth-Execution-Payload-Blinded"))
+	var (
+		block, blindedBlock      = cltypes.NewBeaconBlock(v.beaconCfg), cltypes.NewBlindedBeaconBlock(v.beaconCfg)
+		blockSlot, blockProposer uint64
+		blockToSign              ssz.HashableSSZ
+	)
+	if blinded {
+		blindedBlock.Body.Version = version
+		if err := json.Unmarshal(rawBlock, blindedBlock); err != nil {
+			return err
+		}
+		blockSlot, blockProposer, blockToSign = blindedBlock.Slot, blindedBlock.ProposerIndex, blindedBlock
+	} else {
+		block.Body.Version = version
+		if err := json.Unmarshal(rawBlock, block); err != nil {
+			return err
+		}
+		blockSlot, blockProposer, blockToSign = block.Slot, block.ProposerIndex, block
+	}
+	if blockSlot != slot || blockProposer != duty.ValidatorIndex {
+		return fmt.Errorf("produced block of slot %d and proposer %d", blockSlot, blockProposer)
+	}
+
+	proposerDomain, err := v.domain(v.beaconCfg.DomainBeaconProposer, epoch)
+	if err != nil {
+		return err
+	}
+	// blinded block has the same root as the full one
+	signingRoot, err := fork.ComputeSigningRoot(blockToSign, proposerDomain)
+	if err != nil {
+		return err
+	}
+	if err := v.protection.CheckAndRecordBlock(ctx, duty.Pubkey, slot, signingRoot); err != nil {
+		return err
+	}
+	signature := libcommon.Bytes96(key.Sign(signingRoot[:]).Bytes())
+	versionHeader := map[string]string{"Eth-Consensus-Version": clparams.ClVersionToString(version)}
+	if blinded {
+		signed := &cltypes.SignedBlindedBeaconBlock{Block: blindedBlock, Signature: signature}
+		if _, err := v.beacon.do(ctx, http.MethodPost, "/eth/v2/beacon/blinded_blocks", versionHeader, signed, nil); err != nil {
+			return err
+		}
+	} else {
+		signed := &cltypes.SignedBeaconBlock{Block: block, Signature: signature}
+		if _, err := v.beacon.do(ctx, http.MethodPost, "/eth/v2/beacon/blocks", versionHeader, signed, nil); err != nil {
+			return err
+		}
+	}
+	v.logger.Info("[ValidatorClient] proposed block", "slot", slot, "index", duty.ValidatorIndex, "blinded", blinded)
+	return nil
+}
+
+// registerValidators - signs fee recipient and gas limit preferences of validators for the builder relay of beacon
+// node. Relays expect registrations to be renewed, so it is done every epoch.
+func (v *ValidatorClient) registerValidators(ctx context.Context) error {
+	if v.cfg.FeeRecipient == (libcommon.Address{}) || len(v.indicies) == 0 {
+		return nil
+	}
+	// registrations are signed over the genesis fork version, regardless of the current fork
+	domain, err := fork.ComputeDomain(v.beaconCfg.DomainApplicationBuilder[:], utils.Uint32ToBytes4(uint32(v.beaconCfg.GenesisForkVersion)), [32]byte{})
+	if err != nil {
+		return err
+	}
+	timestamp := uint64(time.Now().Unix())
+	registrations := make([]*cltypes.SignedValidatorRegistration, 0, len(v.indicies))
+	for pubkey := range v.indicies {
+		registration := &cltypes.ValidatorRegistration{
+			FeeRecipient: v.cfg.FeeRecipient,
+			GasLimit:     v.beaconCfg.DefaultBuilderGasLimit,
+			Timestamp:    timestamp,
+			PubKey:       pubkey,
+		}
+		signingRoot, err := fork.ComputeSigningRoot(registration, domain)
+		if err != nil {
+			return err
+		}
+		registrations = append(registrations, &cltypes.SignedValidatorRegistration{
+			Message:   registration,
+			Signature: libcommon.Bytes96(v.keys[pubkey].Sign(signingRoot[:]).Bytes()),
+		})
+	}
+	return v.beacon.post(ctx, "/eth/v1/validator/register_validator", registrations, nil)
+}
+
+// attest - returns attestation data of committees, aggregators of committees aggregate attestations of that data
+func (v *ValidatorClient) attest(ctx context.Context, slot uint64, duties []attesterDuty) (map[uint64]solid.AttestationData, error) {
+	committeesData := map[uint64]solid.AttestationData{}
+	attestations := make([]*solid.Attestation, 0, len(duties))
+	for _, duty := range duties {
+		data, ok := committeesData[duty.CommitteeIndex]
+		if !ok {
+			data = solid.NewAttestationData()
+			if err := v.beacon.get(ctx, fmt.Sprintf("/eth/v1/validator/attestation_data?slot=%d&committee_index=%d", slot, duty.CommitteeIndex), &data); err != nil {
+				return nil, err
+			}
+			committeesData[duty.CommitteeIndex] = data
+		}
+		domain, err := v.domain(v.beaconCfg.DomainBeaconAttester, data.Target().Epoch())
+		if err != nil {
+			return nil, err
+		}
+		signingRoot, err := fork.ComputeSigningRoot(data, domain)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.protection.CheckAndRecordAttestation(ctx, duty.Pubkey, data.Source().Epoch(), data.Target().Epoch(), signingRoot); err != nil {
+			v.logger.Warn("[ValidatorClient] attestation refused", "slot", slot, "index", duty.ValidatorIndex, "err", err)
+			continue
+		}
+		// bitlist of committee length with the only bit of validator set
+		aggregationBits := make([]byte, duty.CommitteeLength/8+1)
+		aggregationBits[duty.ValidatorCommitteeIndex/8] |= 1 << (duty.ValidatorCommitteeIndex % 8)
+		aggregationBits[duty.CommitteeLength/8] |= 1 << (duty.CommitteeLength % 8)
+		signature := v.keys[duty.Pubkey].Sign(signingRoot[:]).Bytes()
+		attestations = append(attestations, solid.NewAttestionFromParameters(aggregationBits, data, libcommon.Bytes96(signature)))
+	}
+	if len(attestations) == 0 {
+		return committeesData, nil
+	}
+	if err := v.beacon.post(ctx, "/eth/v1/beacon/pool/attestations", attestations, nil); err != nil {
+		return nil, err
+	}
+	v.logger.Debug("[ValidatorClient] attested", "slot", slot, "attestations", len(attestations))
+	return committeesData, nil
+}
+
+// sendSyncCommitteeMessages - returns the signed block root, contributions of the slot are aggregated for it
+func (v *ValidatorClient) sendSyncCommitteeMessages(ctx context.Context, slot uint64, duties []syncDuty) (libcommon.Hash, error) {
+	var head struct {
+		Root libcommon.Hash `json:"root"`
+	}
+	if err := v.beacon.get(ctx, "/eth/v1/beacon/blocks/head/root", &head); err != nil {
+		return libcommon.Hash{}, err
+	}
+	domain, err := v.domain(v.beaconCfg.DomainSyncCommittee, slot/v.beaconCfg.SlotsPerEpoch)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	signingRoot := utils.Sha256(head.Root[:], domain)
+	messages := make([]*cltypes.SyncCommitteeMessage, 0, len(duties))
+	for _, duty := range duties {
+		messages = append(messages, &cltypes.SyncCommitteeMessage{
+			Slot:            slot,
+			BeaconBlockRoot: head.Root,
+			ValidatorIndex:  duty.ValidatorIndex,
+			Signature:       libcommon.Bytes96(v.keys[duty.Pubkey].Sign(signingRoot[:]).Bytes()),
+		})
+	}
+	return head.Root, v.beacon.post(ctx, "/eth/v1/beacon/pool/sync_committees", messages, nil)
+}
@@ -0,0 +1,199 @@
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/types/ssz"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/fork"
+	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
+)
+
+// mockBeaconApi - serves responses of validator endpoints, records posted requests by path
+type mockBeaconApi struct {
+	*http.ServeMux
+	t *testing.T
+
+	mu     sync.Mutex
+	posted map[string][]json.RawMessage
+}
+
+func newMockBeaconApi(t *testing.T) *mockBeaconApi {
+	return &mockBeaconApi{ServeMux: http.NewServeMux(), t: t, posted: map[string][]json.RawMessage{}}
+}
+
+// serve - path is served by `data` of response, posted bodies are recorded
+func (m *mockBeaconApi) serve(path string, data func(r *http.Request) any) {
+	m.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body json.RawMessage
+			require.NoError(m.t, json.NewDecoder(r.Body).Decode(&body))
+			m.mu.Lock()
+			m.posted[r.URL.Path] = append(m.posted[r.URL.Path], body)
+			m.mu.Unlock()
+		}
+		var resp any
+		if data != nil {
+			resp = data(r)
+		}
+		require.NoError(m.t, json.NewEncoder(w).Encode(map[string]any{"data": resp}))
+	})
+}
+
+// requests - posted bodies of path decoded into elements of T
+func requests[T any](t *testing.T, m *mockBeaconApi, path string) []T {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all []T
+	for _, body := range m.posted[path] {
+		var batch []T
+		require.NoError(t, json.Unmarshal(body, &batch))
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func verifySignature(t *testing.T, v *ValidatorClient, obj ssz.HashableSSZ, domainType libcommon.Bytes4, epoch uint64, signature libcommon.Bytes96, pubkey libcommon.Bytes48) {
+	domain, err := v.domain(domainType, epoch)
+	require.NoError(t, err)
+	signingRoot, err := fork.ComputeSigningRoot(obj, domain)
+	require.NoError(t, err)
+	valid, err := bls.Verify(signature[:], signingRoot[:], pubkey[:])
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestValidatorClientDuties(t *testing.T) {
+	ctx := context.Background()
+	beaconCfg := clparams.MainnetBeaconConfig
+	beaconCfg.AltairForkEpoch = 0
+	// every validator is aggregator: committees and subcommittees are smaller than the target number of aggregators
+	beaconCfg.TargetAggregatorsPerSyncSubcommittee = beaconCfg.SyncCommitteeSize
+	const (
+		epoch, slot    = 1, 32
+		committeeIndex = 3
+		validatorIndex = 7
+	)
+
+	key, err := bls.GenerateKey()
+	require.NoError(t, err)
+	pubkey := libcommon.Bytes48(bls.CompressPublicKey(key.PublicKey()))
+
+	ctrl := gomock.NewController(t)
+	ethClock := eth_clock.NewMockEthereumClock(ctrl)
+	// slots are over, duties are performed without waiting
+	ethClock.EXPECT().GetSlotTime(gomock.Any()).Return(time.Now().Add(-time.Hour)).AnyTimes()
+	ethClock.EXPECT().GenesisValidatorsRoot().Return(libcommon.Hash{1}).AnyTimes()
+
+	data := solid.NewAttestionDataFromParameters(slot, committeeIndex, libcommon.Hash{2},
+		solid.NewCheckpointFromParameters(libcommon.Hash{3}, 0), solid.NewCheckpointFromParameters(libcommon.Hash{4}, epoch))
+	dataRoot, err := data.HashSSZ()
+	require.NoError(t, err)
+	headRoot := libcommon.Hash{5}
+	aggregate := solid.NewAttestionFromParameters([]byte{0b10110}, data, libcommon.Bytes96{6})
+
+	api := newMockBeaconApi(t)
+	api.serve("/eth/v1/beacon/states/head/validators", func(r *http.Request) any {
+		resp := validatorResponse{Index: validatorIndex, Status: "active_ongoing"}
+		resp.Validator.Pubkey = pubkey
+		return []validatorResponse{resp}
+	})
+	api.serve("/eth/v1/validator/duties/attester/1", func(r *http.Request) any {
+		return []attesterDuty{{Pubkey: pubkey, ValidatorIndex: validatorIndex, CommitteeIndex: committeeIndex, CommitteeLength: 4, ValidatorCommitteeIndex: 1, CommitteesAtSlot: 4, Slot: slot}}
+	})
+	api.serve("/eth/v1/validator/duties/proposer/1", func(r *http.Request) any { return []proposerDuty{} })
+	api.serve("/eth/v1/validator/duties/sync/1", func(r *http.Request) any {
+		// 5 and 6 are of the first subcommittee, 200 of the second one
+		return []syncDuty{{Pubkey: pubkey, ValidatorIndex: validatorIndex, ValidatorSyncCommitteeIndices: []string{"5", "6", "200"}}}
+	})
+	api.serve("/eth/v1/validator/beacon_committee_subscriptions", nil)
+	api.serve("/eth/v1/validator/sync_committee_subscriptions", nil)
+	api.serve("/eth/v1/validator/attestation_data", func(r *http.Request) any {
+		require.Equal(t, "32", r.URL.Query().Get("slot"))
+		require.Equal(t, "3", r.URL.Query().Get("committee_index"))
+		return data
+	})
+	api.serve("/eth/v1/beacon/pool/attestations", nil)
+	api.serve("/eth/v1/validator/aggregate_attestation", func(r *http.Request) any {
+		require.Equal(t, libcommon.Hash(dataRoot), libcommon.HexToHash(r.URL.Query().Get("attestation_data_root")))
+		return aggregate
+	})
+	api.serve("/eth/v1/validator/aggregate_and_proofs", nil)
+	api.serve("/eth/v1/beacon/blocks/head/root", func(r *http.Request) any {
+		return map[string]any{"root": headRoot}
+	})
+	api.serve("/eth/v1/beacon/pool/sync_committees", nil)
+	api.serve("/eth/v1/validator/sync_committee_contribution", func(r *http.Request) any {
+		require.Equal(t, headRoot, libcommon.HexToHash(r.URL.Query().Get("beacon_block_root")))
+		contribution := &cltypes.Contribution{Slot: slot, BeaconBlockRoot: headRoot, AggregationBits: make([]byte, cltypes.SyncCommitteeAggregationBitsSize)}
+		subcommitteeIndex, err := strconv.ParseUint(r.URL.Query().Get("subcommittee_index"), 10, 64)
+		require.NoError(t, err)
+		contribution.SubcommitteeIndex = subcommitteeIndex
+		if contribution.SubcommitteeIndex == 0 {
+			contribution.AggregationBits[0] = 1
+		}
+		return contribution
+	})
+	api.serve("/eth/v1/validator/contribution_and_proofs", nil)
+
+	v := NewValidatorClient(Config{Keys: []*bls.PrivateKey{key}}, &beaconCfg, ethClock, api, NewSlashingProtection(memdb.NewTestDB(t)), log.New())
+	require.NoError(t, v.resolveIndicies(ctx))
+	require.NoError(t, v.updateDuties(ctx, epoch))
+	require.Len(t, v.attesterDuties[slot], 1)
+
+	subscriptions := requests[cltypes.BeaconCommitteeSubscription](t, api, "/eth/v1/validator/beacon_committee_subscriptions")
+	require.Equal(t, []cltypes.BeaconCommitteeSubscription{{ValidatorIndex: validatorIndex, CommitteeIndex: committeeIndex, CommitteesAtSlot: 4, Slot: slot, IsAggregator: true}}, subscriptions)
+	syncSubscriptions := requests[syncCommitteeSubscription](t, api, "/eth/v1/validator/sync_committee_subscriptions")
+	require.Equal(t, []syncCommitteeSubscription{{ValidatorIndex: validatorIndex, SyncCommitteeIndices: []string{"5", "6", "200"}, UntilEpoch: epoch + 1}}, syncSubscriptions)
+
+	v.performDuties(ctx, slot, v.proposerDuties[slot], v.attesterDuties[slot], v.syncDuties)
+
+	attestations := requests[*solid.Attestation](t, api, "/eth/v1/beacon/pool/attestations")
+	require.Len(t, attestations, 1)
+	// bit of validator and the length bit of committee
+	require.Equal(t, []byte{0b10010}, attestations[0].AggregationBits())
+	verifySignature(t, v, attestations[0].AttestantionData(), beaconCfg.DomainBeaconAttester, epoch, attestations[0].Signature(), pubkey)
+
+	aggregates := requests[*cltypes.SignedAggregateAndProof](t, api, "/eth/v1/validator/aggregate_and_proofs")
+	require.Len(t, aggregates, 1)
+	require.Equal(t, uint64(validatorIndex), aggregates[0].Message.AggregatorIndex)
+	require.Equal(t, aggregate.AggregationBits(), aggregates[0].Message.Aggregate.AggregationBits())
+	verifySignature(t, v, aggregates[0].Message, beaconCfg.DomainAggregateAndProof, epoch, aggregates[0].Signature, pubkey)
+	expectedProof, err := v.attestationSelectionProof(key, slot)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof, aggregates[0].Message.SelectionProof)
+
+	messages := requests[*cltypes.SyncCommitteeMessage](t, api, "/eth/v1/beacon/pool/sync_committees")
+	require.Len(t, messages, 1)
+	require.Equal(t, headRoot, messages[0].BeaconBlockRoot)
+
+	// contribution of the second subcommittee has no participants
+	contributions := requests[*cltypes.SignedContributionAndProof](t, api, "/eth/v1/validator/contribution_and_proofs")
+	require.Len(t, contributions, 1)
+	require.Equal(t, uint64(0), contributions[0].Message.Contribution.SubcommitteeIndex)
+	verifySignature(t, v, contributions[0].Message, beaconCfg.DomainContributionAndProof, epoch, contributions[0].Signature, pubkey)
+	verifySignature(t, v, &cltypes.SyncAggregatorSelectionData{Slot: slot}, beaconCfg.DomainSyncCommitteeSelectionProof, epoch, contributions[0].Message.SelectionProof, pubkey)
+
+	// other attestation data of the same target is a double vote
+	data = solid.NewAttestionDataFromParameters(slot, committeeIndex, libcommon.Hash{7},
+		solid.NewCheckpointFromParameters(libcommon.Hash{3}, 0), solid.NewCheckpointFromParameters(libcommon.Hash{4}, epoch))
+	_, err = v.attest(ctx, slot, v.attesterDuties[slot])
+	require.NoError(t, err)
+	require.Len(t, requests[*solid.Attestation](t, api, "/eth/v1/beacon/pool/attestations"), 1)
+}
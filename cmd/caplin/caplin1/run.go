@@ -2,6 +2,7 @@ package caplin1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -16,12 +17,15 @@ import (
 	"github.com/ledgerwatch/erigon/cl/aggregation"
 	"github.com/ledgerwatch/erigon/cl/antiquary"
 	"github.com/ledgerwatch/erigon/cl/beacon"
+	"github.com/ledgerwatch/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/ledgerwatch/erigon/cl/beacon/beaconevents"
+	"github.com/ledgerwatch/erigon/cl/beacon/builder"
 	"github.com/ledgerwatch/erigon/cl/beacon/handler"
 	"github.com/ledgerwatch/erigon/cl/beacon/synced_data"
 	"github.com/ledgerwatch/erigon/cl/clparams/initial_state"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/cl/cltypes/solid"
+	"github.com/ledgerwatch/erigon/cl/monitor"
 	"github.com/ledgerwatch/erigon/cl/rpc"
 	"github.com/ledgerwatch/erigon/cl/sentinel"
 	"github.com/ledgerwatch/erigon/cl/sentinel/service"
@@ -29,6 +33,7 @@ import (
 	"github.com/ledgerwatch/erigon/cl/validator/attestation_producer"
 	"github.com/ledgerwatch/erigon/cl/validator/committee_subscription"
 	"github.com/ledgerwatch/erigon/cl/validator/sync_contribution_pool"
+	"github.com/ledgerwatch/erigon/cl/validator/validator_client"
 	"github.com/ledgerwatch/erigon/cl/validator/validator_params"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/params"
@@ -108,13 +113,12 @@ func OpenCaplinDatabase(ctx context.Context,
 
 func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngine, config *ethconfig.Config, networkConfig *clparams.NetworkConfig,
 	beaconConfig *clparams.BeaconChainConfig, ethClock eth_clock.EthereumClock, state *state.CachingBeaconState, dirs datadir.Dirs, eth1Getter snapshot_format.ExecutionBlockReaderByNumber,
-	snDownloader proto_downloader.DownloaderClient, backfilling, blobBackfilling bool, states bool, indexDB kv.RwDB, blobStorage blob_storage.BlobStorage, creds credentials.TransportCredentials, snBuildSema *semaphore.Weighted) error {
+	snDownloader proto_downloader.DownloaderClient, backfilling, blobBackfilling bool, states bool, indexDB kv.RwDB, blobStorage blob_storage.BlobStorage, csn *freezeblocks.CaplinSnapshots, creds credentials.TransportCredentials, snBuildSema *semaphore.Weighted) error {
 	ctx, cn := context.WithCancel(ctx)
 	defer cn()
 
 	logger := log.New("app", "caplin")
 
-	csn := freezeblocks.NewCaplinSnapshots(ethconfig.BlocksFreezing{}, beaconConfig, dirs, logger)
 	rcsn := freezeblocks.NewBeaconSnapshotReader(csn, eth1Getter, beaconConfig)
 
 	pool := pool.NewOperationsPool(beaconConfig)
@@ -132,7 +136,11 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 	syncContributionPool := sync_contribution_pool.NewSyncContributionPool(beaconConfig)
 	emitters := beaconevents.NewEmitters()
 	aggregationPool := aggregation.NewAggregationPool(ctx, beaconConfig, networkConfig, ethClock)
-	forkChoice, err := forkchoice.NewForkChoiceStore(ethClock, state, engine, pool, fork_graph.NewForkGraphDisk(state, fcuFs, config.BeaconRouter), emitters, syncedDataManager, blobStorage)
+	validatorMonitor := monitor.NewDummyValidatorMonitor()
+	if len(config.CaplinConfig.ValidatorMonitorIndices) > 0 || config.CaplinConfig.ValidatorMonitorAuto {
+		validatorMonitor = monitor.NewValidatorMonitor(beaconConfig, config.CaplinConfig.ValidatorMonitorIndices, config.CaplinConfig.ValidatorMonitorAuto)
+	}
+	forkChoice, err := forkchoice.NewForkChoiceStore(ethClock, state, engine, pool, fork_graph.NewForkGraphDisk(state, fcuFs, config.BeaconRouter, config.CaplinConfig.LightClientServer), emitters, syncedDataManager, blobStorage, validatorMonitor)
 	if err != nil {
 		logger.Error("Could not create forkchoice", "err", err)
 		return err
@@ -153,14 +161,16 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 	activeIndicies := state.GetActiveValidatorsIndices(state.Slot() / beaconConfig.SlotsPerEpoch)
 
 	sentinel, err := service.StartSentinelService(&sentinel.SentinelConfig{
-		IpAddr:         config.CaplinDiscoveryAddr,
-		Port:           int(config.CaplinDiscoveryPort),
-		TCPPort:        uint(config.CaplinDiscoveryTCPPort),
-		NetworkConfig:  networkConfig,
-		BeaconConfig:   beaconConfig,
-		TmpDir:         dirs.Tmp,
-		EnableBlocks:   true,
-		ActiveIndicies: uint64(len(activeIndicies)),
+		IpAddr:            config.CaplinDiscoveryAddr,
+		Port:              int(config.CaplinDiscoveryPort),
+		TCPPort:           uint(config.CaplinDiscoveryTCPPort),
+		NetworkConfig:     networkConfig,
+		BeaconConfig:      beaconConfig,
+		TmpDir:            dirs.Tmp,
+		EnableBlocks:      true,
+		EnableLightClient: config.CaplinConfig.LightClientServer,
+		ActiveIndicies:    uint64(len(activeIndicies)),
+		GossipScoring:     config.CaplinConfig.GossipScoring,
 	}, rcsn, blobStorage, indexDB, &service.ServerConfig{
 		Network:   "tcp",
 		Addr:      fmt.Sprintf("%s:%d", config.SentinelAddr, config.SentinelPort),
@@ -189,10 +199,19 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 	voluntaryExitService := services.NewVoluntaryExitService(pool, emitters, syncedDataManager, beaconConfig, ethClock)
 	blsToExecutionChangeService := services.NewBLSToExecutionChangeService(pool, emitters, syncedDataManager, beaconConfig)
 	proposerSlashingService := services.NewProposerSlashingService(pool, syncedDataManager, beaconConfig, ethClock)
+	var (
+		lightClientFinalityUpdateService   services.LightClientFinalityUpdateService
+		lightClientOptimisticUpdateService services.LightClientOptimisticUpdateService
+	)
+	if config.CaplinConfig.LightClientServer {
+		lightClientFinalityUpdateService = services.NewLightClientFinalityUpdateService(forkChoice, beaconConfig, networkConfig, ethClock)
+		lightClientOptimisticUpdateService = services.NewLightClientOptimisticUpdateService(forkChoice, beaconConfig, networkConfig, ethClock)
+	}
 	// Create the gossip manager
 	gossipManager := network.NewGossipReceiver(sentinel, forkChoice, beaconConfig, ethClock, emitters, committeeSub,
 		blockService, blobService, syncCommitteeMessagesService, syncContributionService, aggregateAndProofService,
-		attestationService, voluntaryExitService, blsToExecutionChangeService, proposerSlashingService)
+		attestationService, voluntaryExitService, blsToExecutionChangeService, proposerSlashingService,
+		lightClientFinalityUpdateService, lightClientOptimisticUpdateService)
 	{ // start ticking forkChoice
 		go func() {
 			tickInterval := time.NewTicker(2 * time.Millisecond)
@@ -272,8 +291,15 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 
 	statesReader := historical_states_reader.NewHistoricalStatesReader(beaconConfig, rcsn, vTables, genesisState)
 	validatorParameters := validator_params.NewValidatorParams()
-	if config.BeaconRouter.Active {
-		apiHandler := handler.NewApiHandler(
+	var builderClient builder.BuilderClient
+	if config.CaplinConfig.MevRelayUrl != "" {
+		if builderClient, err = builder.NewBlockBuilderClient(config.CaplinConfig.MevRelayUrl, beaconConfig); err != nil {
+			return err
+		}
+		logger.Info("[Caplin] Using builder API relay for block production", "url", config.CaplinConfig.MevRelayUrl)
+	}
+	newApiHandler := func(routerCfg *beacon_router_configuration.RouterConfiguration) *handler.ApiHandler {
+		return handler.NewApiHandler(
 			logger,
 			networkConfig,
 			ethClock,
@@ -286,7 +312,7 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 			statesReader,
 			sentinel,
 			params.GitTag,
-			&config.BeaconRouter,
+			routerCfg,
 			emitters,
 			blobStorage,
 			csn,
@@ -303,12 +329,42 @@ func RunCaplinPhase1(ctx context.Context, engine execution_client.ExecutionEngin
 			voluntaryExitService,
 			blsToExecutionChangeService,
 			proposerSlashingService,
+			builderClient,
+			validatorMonitor,
 		)
+	}
+	if config.BeaconRouter.Active {
+		apiHandler := newApiHandler(&config.BeaconRouter)
 		go beacon.ListenAndServe(&beacon.LayeredBeaconHandler{
 			ArchiveApi: apiHandler,
 		}, config.BeaconRouter)
 		log.Info("Beacon API started", "addr", config.BeaconRouter.Address)
 	}
+	if config.CaplinConfig.ValidatorKeystoreDir != "" {
+		keys, err := validator_client.LoadKeystores(config.CaplinConfig.ValidatorKeystoreDir, config.CaplinConfig.ValidatorPasswordFile)
+		if err != nil {
+			return err
+		}
+		// slashing protection history must survive resyncs, so it's kept apart from beacon indicies
+		slashingProtectionPath := path.Join(dirs.DataDir, "caplin", "validator")
+		if err := os.MkdirAll(slashingProtectionPath, 0700); err != nil {
+			return err
+		}
+		slashingProtectionDB := mdbx.MustOpen(slashingProtectionPath)
+		defer slashingProtectionDB.Close()
+		// validator client has its own handler: validator endpoints are served to it even if they are not exposed
+		validatorApi := newApiHandler(&beacon_router_configuration.RouterConfiguration{Beacon: true, Validator: true})
+		validatorClient := validator_client.NewValidatorClient(validator_client.Config{
+			Keys:               keys,
+			FeeRecipient:       config.CaplinConfig.ValidatorFeeRecipient,
+			DoppelgangerEpochs: config.CaplinConfig.ValidatorDoppelgangerEpochs,
+		}, beaconConfig, ethClock, validatorApi, validator_client.NewSlashingProtection(slashingProtectionDB), logger)
+		go func() {
+			if err := validatorClient.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("[Caplin] validator client stopped", "err", err)
+			}
+		}()
+	}
 
 	stageCfg := stages.ClStagesCfg(beaconRpc, antiq, ethClock, beaconConfig, state, engine, gossipManager, forkChoice, indexDB, csn, rcsn, dirs.Tmp, dbConfig, backfilling, blobBackfilling, syncedDataManager, emitters, blobStorage, attestationProducer)
 	sync := stages.ConsensusClStages(ctx, stageCfg)
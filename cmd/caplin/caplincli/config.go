@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/phase1/core/state"
@@ -21,22 +22,24 @@ import (
 type CaplinCliCfg struct {
 	*sentinelcli.SentinelCliCfg
 
-	CheckpointUri         string        `json:"checkpoint_uri"`
-	Chaindata             string        `json:"chaindata"`
-	ErigonPrivateApi      string        `json:"erigon_private_api"`
-	TransitionChain       bool          `json:"transition_chain"`
-	InitialSync           bool          `json:"initial_sync"`
-	AllowedEndpoints      []string      `json:"endpoints"`
-	BeaconApiReadTimeout  time.Duration `json:"beacon_api_read_timeout"`
-	BeaconApiWriteTimeout time.Duration `json:"beacon_api_write_timeout"`
-	BeaconAddr            string        `json:"beacon_addr"`
-	BeaconProtocol        string        `json:"beacon_protocol"`
-	RecordMode            bool          `json:"record_mode"`
-	RecordDir             string        `json:"record_dir"`
-	DataDir               string        `json:"data_dir"`
-	RunEngineAPI          bool          `json:"run_engine_api"`
-	EngineAPIAddr         string        `json:"engine_api_addr"`
-	EngineAPIPort         int           `json:"engine_api_port"`
+	CheckpointUris        []string       `json:"checkpoint_uris"`
+	CheckpointQuorum      uint64         `json:"checkpoint_quorum"`
+	CheckpointRoot        libcommon.Hash `json:"checkpoint_root"`
+	Chaindata             string         `json:"chaindata"`
+	ErigonPrivateApi      string         `json:"erigon_private_api"`
+	TransitionChain       bool           `json:"transition_chain"`
+	InitialSync           bool           `json:"initial_sync"`
+	AllowedEndpoints      []string       `json:"endpoints"`
+	BeaconApiReadTimeout  time.Duration  `json:"beacon_api_read_timeout"`
+	BeaconApiWriteTimeout time.Duration  `json:"beacon_api_write_timeout"`
+	BeaconAddr            string         `json:"beacon_addr"`
+	BeaconProtocol        string         `json:"beacon_protocol"`
+	RecordMode            bool           `json:"record_mode"`
+	RecordDir             string         `json:"record_dir"`
+	DataDir               string         `json:"data_dir"`
+	RunEngineAPI          bool           `json:"run_engine_api"`
+	EngineAPIAddr         string         `json:"engine_api_addr"`
+	EngineAPIPort         int            `json:"engine_api_port"`
 	JwtSecret             []byte
 
 	AllowedMethods   []string `json:"allowed_methods"`
@@ -100,9 +103,15 @@ func SetupCaplinCli(ctx *cli.Context) (cfg *CaplinCliCfg, err error) {
 	}
 
 	if ctx.String(caplinflags.CheckpointSyncUrlFlag.Name) != "" {
-		cfg.CheckpointUri = ctx.String(caplinflags.CheckpointSyncUrlFlag.Name)
+		cfg.CheckpointUris = libcommon.CliString2Array(ctx.String(caplinflags.CheckpointSyncUrlFlag.Name))
 	} else {
-		cfg.CheckpointUri = clparams.GetCheckpointSyncEndpoint(cfg.NetworkType)
+		cfg.CheckpointUris = clparams.GetCheckpointSyncEndpoints(cfg.NetworkType)
+	}
+	cfg.CheckpointQuorum = ctx.Uint64(caplinflags.CheckpointSyncQuorumFlag.Name)
+	if root := ctx.String(caplinflags.CheckpointSyncRootFlag.Name); root != "" {
+		if err := cfg.CheckpointRoot.UnmarshalText([]byte(root)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", caplinflags.CheckpointSyncRootFlag.Name, err)
+		}
 	}
 
 	cfg.Chaindata = ctx.String(caplinflags.ChaindataFlag.Name)
@@ -14,6 +14,8 @@ var CliFlags = []cli.Flag{
 	&ChaindataFlag,
 	&BeaconDBModeFlag,
 	&CheckpointSyncUrlFlag,
+	&CheckpointSyncQuorumFlag,
+	&CheckpointSyncRootFlag,
 	&TransitionChainFlag,
 	&InitSyncFlag,
 	&RecordModeDir,
@@ -66,7 +68,17 @@ var (
 	}
 	CheckpointSyncUrlFlag = cli.StringFlag{
 		Name:  "checkpoint-sync-url",
-		Usage: "checkpoint sync endpoint",
+		Usage: "comma separated checkpoint sync endpoints, the state is downloaded from the first available one and verified against the others",
+		Value: "",
+	}
+	CheckpointSyncQuorumFlag = cli.Uint64Flag{
+		Name:  "checkpoint-sync-quorum",
+		Usage: "number of checkpoint sync endpoints which must agree on the finalized checkpoint (0 is the majority of endpoints)",
+		Value: 0,
+	}
+	CheckpointSyncRootFlag = cli.StringFlag{
+		Name:  "checkpoint-sync-root",
+		Usage: "trusted root of the latest block of checkpoint sync state",
 		Value: "",
 	}
 	TransitionChainFlag = cli.BoolFlag{
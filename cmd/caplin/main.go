@@ -37,6 +37,7 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/turbo/app"
 	"github.com/ledgerwatch/erigon/turbo/debug"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
 )
 
 func main() {
@@ -87,7 +88,7 @@ func runCaplinNode(cliCtx *cli.Context) error {
 	if cfg.InitialSync {
 		state = cfg.InitalState
 	} else {
-		state, err = core.RetrieveBeaconState(ctx, cfg.BeaconCfg, cfg.CheckpointUri)
+		state, err = core.RetrieveVerifiedBeaconState(ctx, cfg.BeaconCfg, cfg.CheckpointUris, int(cfg.CheckpointQuorum), cfg.CheckpointRoot)
 		if err != nil {
 			return err
 		}
@@ -136,11 +137,13 @@ func runCaplinNode(cliCtx *cli.Context) error {
 		return err
 	}
 
+	csn := freezeblocks.NewCaplinSnapshots(ethconfig.BlocksFreezing{}, cfg.BeaconCfg, cfg.Dirs, log.New("app", "caplin"))
+
 	blockSnapBuildSema := semaphore.NewWeighted(int64(dbg.BuildSnapshotAllowance))
 	return caplin1.RunCaplinPhase1(ctx, executionEngine, &ethconfig.Config{
 		CaplinDiscoveryAddr:    cfg.Addr,
 		CaplinDiscoveryPort:    uint64(cfg.Port),
 		CaplinDiscoveryTCPPort: uint64(cfg.ServerTcpPort),
 		BeaconRouter:           rcfg,
-	}, cfg.NetworkCfg, cfg.BeaconCfg, ethClock, state, cfg.Dirs, nil, nil, false, false, false, indiciesDB, blobStorage, nil, blockSnapBuildSema)
+	}, cfg.NetworkCfg, cfg.BeaconCfg, ethClock, state, cfg.Dirs, nil, nil, false, false, false, indiciesDB, blobStorage, csn, nil, blockSnapBuildSema)
 }
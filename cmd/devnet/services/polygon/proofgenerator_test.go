@@ -150,7 +150,7 @@ func (rg *requestGenerator) GetTransactionReceipt(ctx context.Context, hash libc
 		panic(err)
 	}
 
-	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, reader, tx, 0, historyV3)
+	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, reader, tx, 0, historyV3, nil)
 
 	if err != nil {
 		return nil, err
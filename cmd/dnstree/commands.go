@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/dnsdisc"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+var (
+	nodesFileFlag = cli.StringSliceFlag{
+		Name:  "nodes",
+		Usage: "File with ENRs of the nodes to add, one per line",
+	}
+	rpcFlag = cli.StringSliceFlag{
+		Name:  "rpc",
+		Usage: "JSON-RPC endpoint of a node (with admin namespace) to add, repeat for each node of a fleet",
+	}
+	rpcPeersFlag = cli.BoolFlag{
+		Name:  "rpc.peers",
+		Usage: "Also add the currently connected peers of the --rpc nodes, which report their ENRs",
+	}
+	replaceFlag = cli.BoolFlag{
+		Name:  "replace",
+		Usage: "Replace the nodes of the tree instead of adding to them",
+	}
+	keyFlag = cli.StringFlag{
+		Name:     "key",
+		Usage:    "File with the hex private key the tree is signed with (format of --nodekey)",
+		Required: true,
+	}
+	domainFlag = cli.StringFlag{
+		Name:     "domain",
+		Usage:    "Domain the tree is published under",
+		Required: true,
+	}
+	seqFlag = cli.UintFlag{
+		Name:  "seq",
+		Usage: "Sequence number of the tree, defaults to the previous one plus one",
+	}
+	linkFlag = cli.StringSliceFlag{
+		Name:  "link",
+		Usage: "enrtree:// URL of another tree to link to, replaces the links of the tree",
+	}
+	ttlFlag = cli.UintFlag{
+		Name:  "ttl",
+		Usage: "TTL of the TXT records, in seconds",
+		Value: 3600,
+	}
+	serverFlag = cli.StringFlag{
+		Name:     "server",
+		Usage:    "Primary name server of the zone, host:port",
+		Required: true,
+	}
+	zoneFlag = cli.StringFlag{
+		Name:  "zone",
+		Usage: "Zone to update, defaults to the tree domain",
+	}
+	tsigFlag = cli.StringFlag{
+		Name:  "tsig",
+		Usage: "TSIG key authorizing the update, [algorithm:]name:base64-secret (default algorithm hmac-sha256)",
+	}
+)
+
+var collectCommand = cli.Command{
+	Name:      "collect",
+	Usage:     "Add nodes to the tree from ENR files and from running nodes",
+	ArgsUsage: "<tree-dir>",
+	Flags:     []cli.Flag{&nodesFileFlag, &rpcFlag, &rpcPeersFlag, &replaceFlag},
+	Action:    collect,
+}
+
+var signCommand = cli.Command{
+	Name:      "sign",
+	Usage:     "Build the tree and sign its root, prints the enrtree:// URL to configure clients with",
+	ArgsUsage: "<tree-dir>",
+	Flags:     []cli.Flag{&keyFlag, &domainFlag, &seqFlag, &linkFlag},
+	Action:    sign,
+}
+
+var toTXTCommand = cli.Command{
+	Name:      "to-txt",
+	Usage:     "Write the TXT records of a signed tree as JSON, name to value",
+	ArgsUsage: "<tree-dir> <output-file>",
+	Action:    toTXT,
+}
+
+var toZoneCommand = cli.Command{
+	Name:      "to-zone",
+	Usage:     "Write the TXT records of a signed tree in zone file format",
+	ArgsUsage: "<tree-dir> <output-file>",
+	Flags:     []cli.Flag{&ttlFlag},
+	Action:    toZone,
+}
+
+var nsupdateCommand = cli.Command{
+	Name:      "nsupdate",
+	Usage:     "Publish a signed tree with RFC 2136 dynamic updates, removing records of the previously published tree",
+	ArgsUsage: "<tree-dir>",
+	Flags:     []cli.Flag{&serverFlag, &zoneFlag, &tsigFlag, &ttlFlag},
+	Action:    nsupdate,
+}
+
+func treeDir(ctx *cli.Context) (string, error) {
+	if ctx.NArg() < 1 {
+		return "", fmt.Errorf("need tree directory as argument")
+	}
+	return ctx.Args().Get(0), nil
+}
+
+func collect(ctx *cli.Context) error {
+	dir, err := treeDir(ctx)
+	if err != nil {
+		return err
+	}
+	byID := map[enode.ID]*enode.Node{}
+	add := func(n *enode.Node) {
+		// Keep the latest record of each node
+		if old, ok := byID[n.ID()]; !ok || old.Seq() < n.Seq() {
+			byID[n.ID()] = n
+		}
+	}
+	if !ctx.Bool(replaceFlag.Name) {
+		nodes, err := loadNodes(dir)
+		if err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			add(n)
+		}
+	}
+	for _, file := range ctx.StringSlice(nodesFileFlag.Name) {
+		nodes, err := readNodesFile(file)
+		if err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			add(n)
+		}
+	}
+	for _, url := range ctx.StringSlice(rpcFlag.Name) {
+		nodes, err := nodesFromRPC(ctx.Context, url, ctx.Bool(rpcPeersFlag.Name))
+		if err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+		for _, n := range nodes {
+			add(n)
+		}
+	}
+	nodes := make([]*enode.Node, 0, len(byID))
+	for _, n := range byID {
+		nodes = append(nodes, n)
+	}
+	log.Info("Collected nodes", "count", len(nodes))
+	return writeNodes(dir, nodes)
+}
+
+func readNodesFile(file string) ([]*enode.Node, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var nodes []*enode.Node
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		n, err := parseRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, scanner.Err()
+}
+
+// nodesFromRPC returns the record of the node and optionally the records of its peers. Peers which don't
+// report their ENR are skipped.
+func nodesFromRPC(ctx context.Context, url string, withPeers bool) ([]*enode.Node, error) {
+	client, err := rpc.DialContext(ctx, url, log.Root())
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var info p2p.NodeInfo
+	if err := client.CallContext(ctx, &info, "admin_nodeInfo"); err != nil {
+		return nil, err
+	}
+	self, err := parseRecord(info.ENR)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*enode.Node{self}
+	if !withPeers {
+		return nodes, nil
+	}
+	var peers []*p2p.PeerInfo
+	if err := client.CallContext(ctx, &peers, "admin_peers"); err != nil {
+		return nil, err
+	}
+	for _, peer := range peers {
+		if peer.ENR == "" {
+			log.Debug("Skipping peer without ENR", "enode", peer.Enode)
+			continue
+		}
+		n, err := parseRecord(peer.ENR)
+		if err != nil {
+			log.Debug("Skipping peer", "err", err)
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func sign(ctx *cli.Context) error {
+	dir, err := treeDir(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := crypto.LoadECDSA(ctx.String(keyFlag.Name))
+	if err != nil {
+		return err
+	}
+	info, err := loadInfo(dir)
+	if err != nil {
+		return err
+	}
+	nodes, err := loadNodes(dir)
+	if err != nil {
+		return err
+	}
+	if ctx.IsSet(linkFlag.Name) {
+		info.Links = ctx.StringSlice(linkFlag.Name)
+	}
+	info.Seq++
+	if ctx.IsSet(seqFlag.Name) {
+		info.Seq = ctx.Uint(seqFlag.Name)
+	}
+
+	t, err := dnsdisc.MakeTree(info.Seq, nodes, info.Links)
+	if err != nil {
+		return err
+	}
+	url, err := t.Sign(key, ctx.String(domainFlag.Name))
+	if err != nil {
+		return err
+	}
+	info.URL, info.Signature = url, t.Signature()
+	if err := writeJSON(filepath.Join(dir, infoFile), info); err != nil {
+		return err
+	}
+	fmt.Println(url)
+	return nil
+}
+
+func toTXT(ctx *cli.Context) error {
+	if ctx.NArg() < 2 {
+		return fmt.Errorf("need tree directory and output file as arguments")
+	}
+	t, domain, err := loadSignedTree(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	return writeJSON(ctx.Args().Get(1), t.ToTXT(domain))
+}
+
+func toZone(ctx *cli.Context) error {
+	if ctx.NArg() < 2 {
+		return fmt.Errorf("need tree directory and output file as arguments")
+	}
+	t, domain, err := loadSignedTree(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ctx.Args().Get(1), []byte(zoneFile(t.ToTXT(domain), uint32(ctx.Uint(ttlFlag.Name)))), 0644)
+}
+
+// zoneFile renders TXT records in zone file format, root record first
+func zoneFile(records map[string]string, ttl uint32) string {
+	var sb strings.Builder
+	for _, name := range sortedNames(records) {
+		fmt.Fprintf(&sb, "%s\t%d\tIN\tTXT\t", fqdn(name), ttl)
+		for i, chunk := range txtChunks(records[name]) {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			fmt.Fprintf(&sb, "%q", chunk)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// sortedNames orders the names with the shortest - the root record - first
+func sortedNames(records map[string]string) []string {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// txtChunks splits a TXT value into the strings of at most 255 bytes a TXT record consists of
+func txtChunks(value string) []string {
+	const maxChunk = 255
+	var chunks []string
+	for len(value) > maxChunk {
+		chunks = append(chunks, value[:maxChunk])
+		value = value[maxChunk:]
+	}
+	return append(chunks, value)
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
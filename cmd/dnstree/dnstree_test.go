@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/p2p/dnsdisc"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"github.com/ledgerwatch/erigon/p2p/enr"
+)
+
+type mapResolver map[string]string
+
+func (mr mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if record, ok := mr[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, fmt.Errorf("no such host %s", name)
+}
+
+func testNodes(t *testing.T, n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		record := new(enr.Record)
+		record.SetSeq(uint64(i))
+		require.NoError(t, enode.SignV4(record, key))
+		nodes[i], err = enode.New(enode.ValidSchemes, record)
+		require.NoError(t, err)
+	}
+	return nodes
+}
+
+func run(t *testing.T, args ...string) {
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{&collectCommand, &signCommand, &toTXTCommand, &toZoneCommand, &nsupdateCommand}
+	require.NoError(t, app.Run(append([]string{"dnstree"}, args...)))
+}
+
+func TestPublishTree(t *testing.T) {
+	dir := t.TempDir()
+	treeDir := filepath.Join(dir, "tree")
+	nodes := testNodes(t, 20)
+
+	var list strings.Builder
+	list.WriteString("# fleet\n")
+	for _, n := range nodes {
+		list.WriteString(n.String() + "\n")
+	}
+	nodesFile := filepath.Join(dir, "nodes.txt")
+	require.NoError(t, os.WriteFile(nodesFile, []byte(list.String()), 0644))
+	run(t, "collect", "--nodes", nodesFile, treeDir)
+	// Collecting again doesn't duplicate nodes
+	run(t, "collect", "--nodes", nodesFile, treeDir)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "tree.key")
+	require.NoError(t, crypto.SaveECDSA(keyFile, key))
+	run(t, "sign", "--key", keyFile, "--domain", "nodes.example.org", treeDir)
+	run(t, "sign", "--key", keyFile, "--domain", "nodes.example.org", treeDir)
+	info, err := loadInfo(treeDir)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), info.Seq)
+
+	txtFile := filepath.Join(dir, "records.json")
+	run(t, "to-txt", treeDir, txtFile)
+	records := map[string]string{}
+	require.NoError(t, readJSON(txtFile, &records))
+
+	client := dnsdisc.NewClient(dnsdisc.Config{Resolver: mapResolver(records)})
+	tree, err := client.SyncTree(info.URL)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), tree.Seq())
+	require.ElementsMatch(t, nodeIDs(nodes), nodeIDs(tree.Nodes()))
+
+	zoneFile := filepath.Join(dir, "tree.zone")
+	run(t, "to-zone", "--ttl", "60", treeDir, zoneFile)
+	zone, err := os.ReadFile(zoneFile)
+	require.NoError(t, err)
+	zp := dns.NewZoneParser(strings.NewReader(string(zone)), "", "")
+	parsed := 0
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		txt := rr.(*dns.TXT)
+		require.Equal(t, records[strings.TrimSuffix(txt.Hdr.Name, ".")], strings.Join(txt.Txt, ""))
+		parsed++
+	}
+	require.NoError(t, zp.Err())
+	require.Equal(t, len(records), parsed)
+}
+
+func TestSignedTreeTampering(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, writeNodes(dir, testNodes(t, 3)))
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "tree.key")
+	require.NoError(t, crypto.SaveECDSA(keyFile, key))
+	run(t, "sign", "--key", keyFile, "--domain", "n", dir)
+	_, _, err = loadSignedTree(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, writeNodes(dir, testNodes(t, 3)))
+	_, _, err = loadSignedTree(dir)
+	require.Error(t, err)
+}
+
+func TestParseRecord(t *testing.T) {
+	n := testNodes(t, 1)[0]
+	_, err := parseRecord(n.String())
+	require.NoError(t, err)
+	_, err = parseRecord("enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303")
+	require.ErrorContains(t, err, "no signed record")
+}
+
+func TestPlanUpdates(t *testing.T) {
+	records := map[string]string{"n": "root2", "a.n": "a", "b.n": "b2"}
+	published := map[string]string{"n": "root1", "a.n": "a", "c.n": "c"}
+	msgs := planUpdates("n", records, published, 60)
+	require.Len(t, msgs, 3)
+	require.Equal(t, "b.n.", msgs[0].Ns[0].Header().Name)
+	require.Len(t, msgs[0].Ns, 2) // unchanged a.n is not updated
+	require.Equal(t, "n.", msgs[1].Ns[1].Header().Name)
+	require.Equal(t, "c.n.", msgs[2].Ns[0].Header().Name)
+	require.Equal(t, uint16(dns.ClassANY), msgs[2].Ns[0].Header().Class)
+
+	require.Empty(t, planUpdates("n", records, records, 60))
+}
+
+func TestTXTChunks(t *testing.T) {
+	value := strings.Repeat("x", 600)
+	chunks := txtChunks(value)
+	require.Len(t, chunks, 3)
+	require.Equal(t, value, strings.Join(chunks, ""))
+	require.Equal(t, []string{"short"}, txtChunks("short"))
+}
+
+func nodeIDs(nodes []*enode.Node) []enode.ID {
+	ids := make([]enode.ID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID()
+	}
+	return ids
+}
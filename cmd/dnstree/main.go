@@ -0,0 +1,36 @@
+// dnstree builds, signs and publishes EIP-1459 DNS discovery trees, so that nodes of private networks and L2s
+// can bootstrap discovery from a domain (--discovery.dns enrtree://...) instead of hard-coded enodes.
+//
+// Typical flow:
+//
+//	dnstree collect --rpc http://node1:8545 --rpc http://node2:8545 --rpc.peers ./tree
+//	dnstree sign --key ./tree.key --domain nodes.example.org ./tree
+//	dnstree nsupdate --server ns1.example.org:53 --tsig name:secret ./tree   (or to-zone / to-txt)
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "dnstree"
+	app.Version = params.VersionWithCommit(params.GitCommit)
+	app.Usage = "Build, sign and publish EIP-1459 DNS discovery trees"
+	app.Commands = []*cli.Command{
+		&collectCommand,
+		&signCommand,
+		&toTXTCommand,
+		&toZoneCommand,
+		&nsupdateCommand,
+	}
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/miekg/dns"
+	"github.com/urfave/cli/v2"
+)
+
+// maxUpdateRecords - TXT records per update message, keeps messages well below the 64KB TCP limit
+const maxUpdateRecords = 100
+
+type tsigKey struct {
+	algorithm, name, secret string
+}
+
+func parseTSIG(s string) (*tsigKey, error) {
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+		return &tsigKey{algorithm: dns.HmacSHA256, name: dns.Fqdn(parts[0]), secret: parts[1]}, nil
+	case 3:
+		return &tsigKey{algorithm: dns.Fqdn(parts[0]), name: dns.Fqdn(parts[1]), secret: parts[2]}, nil
+	}
+	return nil, fmt.Errorf("invalid TSIG key %q, expected [algorithm:]name:secret", s)
+}
+
+func nsupdate(ctx *cli.Context) error {
+	dir, err := treeDir(ctx)
+	if err != nil {
+		return err
+	}
+	t, domain, err := loadSignedTree(dir)
+	if err != nil {
+		return err
+	}
+	zone := ctx.String(zoneFlag.Name)
+	if zone == "" {
+		zone = domain
+	}
+	var key *tsigKey
+	if ctx.IsSet(tsigFlag.Name) {
+		if key, err = parseTSIG(ctx.String(tsigFlag.Name)); err != nil {
+			return err
+		}
+	}
+
+	published := map[string]string{}
+	if err := readJSON(filepath.Join(dir, publishedFile), &published); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	records := t.ToTXT(domain)
+	updates := planUpdates(zone, records, published, uint32(ctx.Uint(ttlFlag.Name)))
+
+	client := &dns.Client{Net: "tcp", Timeout: 30 * time.Second}
+	if key != nil {
+		client.TsigSecret = map[string]string{key.name: key.secret}
+	}
+	for _, m := range updates {
+		if key != nil {
+			m.SetTsig(key.name, key.algorithm, 300, time.Now().Unix())
+		}
+		r, _, err := client.Exchange(m, ctx.String(serverFlag.Name))
+		if err != nil {
+			return err
+		}
+		if r.Rcode != dns.RcodeSuccess {
+			return fmt.Errorf("update of zone %s rejected: %s", zone, dns.RcodeToString[r.Rcode])
+		}
+	}
+	log.Info("Published tree", "domain", domain, "seq", t.Seq(), "records", len(records), "updates", len(updates))
+	return writeJSON(filepath.Join(dir, publishedFile), records)
+}
+
+// planUpdates returns the update messages publishing the records: first the new and changed entries, then the
+// root which makes them reachable, and finally the removal of the entries of the previous tree. Clients see
+// either the previous or the new tree at any time.
+func planUpdates(zone string, records, published map[string]string, ttl uint32) []*dns.Msg {
+	var msgs []*dns.Msg
+	var m *dns.Msg
+	count := 0
+	next := func() {
+		if m == nil || count >= maxUpdateRecords {
+			m = new(dns.Msg)
+			m.SetUpdate(dns.Fqdn(zone))
+			msgs = append(msgs, m)
+			count = 0
+		}
+		count++
+	}
+	names := sortedNames(records)
+	root, entries := names[0], names[1:]
+	for _, name := range entries {
+		if published[name] == records[name] {
+			continue
+		}
+		next()
+		m.RemoveRRset([]dns.RR{txtRR(name, "", ttl)})
+		m.Insert([]dns.RR{txtRR(name, records[name], ttl)})
+	}
+	if published[root] != records[root] {
+		m = nil // the root goes in a message of its own, after all the entries are in place
+		next()
+		m.RemoveRRset([]dns.RR{txtRR(root, "", ttl)})
+		m.Insert([]dns.RR{txtRR(root, records[root], ttl)})
+		m = nil
+	}
+	for _, name := range sortedNames(published) {
+		if _, ok := records[name]; ok {
+			continue
+		}
+		next()
+		m.RemoveRRset([]dns.RR{txtRR(name, "", ttl)})
+	}
+	return msgs
+}
+
+func txtRR(name, value string, ttl uint32) *dns.TXT {
+	rr := &dns.TXT{Hdr: dns.RR_Header{Name: fqdn(name), Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttl}}
+	if value != "" {
+		rr.Txt = txtChunks(value)
+	}
+	return rr
+}
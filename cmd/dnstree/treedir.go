@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ledgerwatch/erigon/p2p/dnsdisc"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+)
+
+// A tree directory holds the definition of a DNS discovery tree:
+//
+//	nodes.json         - ENRs of the nodes in the tree
+//	enrtree-info.json  - sequence number, links, and the signature with the tree URL once signed
+//	published.json     - TXT records last published by nsupdate, to remove the stale ones on the next update
+const (
+	nodesFile     = "nodes.json"
+	infoFile      = "enrtree-info.json"
+	publishedFile = "published.json"
+)
+
+type treeInfo struct {
+	URL       string   `json:"url,omitempty"` // enrtree://<public key>@<domain>, set by sign
+	Seq       uint     `json:"seq"`
+	Signature string   `json:"signature,omitempty"`
+	Links     []string `json:"links,omitempty"`
+}
+
+func loadInfo(dir string) (*treeInfo, error) {
+	var info treeInfo
+	if err := readJSON(filepath.Join(dir, infoFile), &info); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func loadNodes(dir string) ([]*enode.Node, error) {
+	var records []string
+	if err := readJSON(filepath.Join(dir, nodesFile), &records); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	nodes := make([]*enode.Node, 0, len(records))
+	for _, record := range records {
+		n, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", nodesFile, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// parseRecord parses an ENR. Tree entries are signed records, which enode URLs can't provide.
+func parseRecord(s string) (*enode.Node, error) {
+	n, err := enode.Parse(enode.ValidSchemes, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node record %q: %w", s, err)
+	}
+	if len(n.Record().Signature()) == 0 {
+		return nil, fmt.Errorf("node %s has no signed record, use its ENR instead of enode URL", n.ID())
+	}
+	return n, nil
+}
+
+func writeNodes(dir string, nodes []*enode.Node) error {
+	records := make([]string, len(nodes))
+	for i, n := range nodes {
+		records[i] = n.String()
+	}
+	sort.Strings(records)
+	return writeJSON(filepath.Join(dir, nodesFile), records)
+}
+
+// loadSignedTree rebuilds the tree of a signed tree directory and verifies its signature
+func loadSignedTree(dir string) (*dnsdisc.Tree, string, error) {
+	info, err := loadInfo(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.URL == "" || info.Signature == "" {
+		return nil, "", fmt.Errorf("tree in %s is not signed, run sign first", dir)
+	}
+	domain, pubkey, err := dnsdisc.ParseURL(info.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	nodes, err := loadNodes(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	t, err := dnsdisc.MakeTree(info.Seq, nodes, info.Links)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := t.SetSignature(pubkey, info.Signature); err != nil {
+		return nil, "", fmt.Errorf("tree in %s was changed after signing, run sign again: %w", dir, err)
+	}
+	return t, domain, nil
+}
+
+func readJSON(file string, v interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+func writeJSON(file string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
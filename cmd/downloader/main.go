@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -11,6 +13,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
@@ -29,6 +32,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
 	"github.com/ledgerwatch/erigon-lib/downloader/downloadergrpc"
 	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
 	"github.com/ledgerwatch/log/v3"
@@ -63,6 +67,13 @@ func main() {
 
 var (
 	webseeds                       string
+	webseedS3                      downloadercfg.S3
+	releaseKeys                    string
+	releaseKeyFile                 string
+	bandwidthSchedule              string
+	withV2Roots                    bool
+	setBandwidthSchedule           string
+	progressAll                    bool
 	datadirCli, chain              string
 	filePath                       string
 	forceRebuild                   bool
@@ -83,6 +94,10 @@ var (
 	disableIPV6                    bool
 	disableIPV4                    bool
 	seedbox                        bool
+	seedOnly                       bool
+
+	tlsCertFile, tlsKeyFile, tlsCACert string
+	tlsAllowedCNs                      string
 )
 
 func init() {
@@ -92,11 +107,16 @@ func init() {
 	withChainFlag(rootCmd)
 
 	rootCmd.Flags().StringVar(&webseeds, utils.WebSeedsFlag.Name, utils.WebSeedsFlag.Value, utils.WebSeedsFlag.Usage)
+	rootCmd.Flags().StringVar(&webseedS3.Endpoint, utils.WebSeedS3EndpointFlag.Name, utils.WebSeedS3EndpointFlag.Value, utils.WebSeedS3EndpointFlag.Usage)
+	rootCmd.Flags().StringVar(&webseedS3.Region, utils.WebSeedS3RegionFlag.Name, utils.WebSeedS3RegionFlag.Value, utils.WebSeedS3RegionFlag.Usage)
+	rootCmd.Flags().BoolVar(&webseedS3.RequesterPays, utils.WebSeedS3RequesterPaysFlag.Name, false, utils.WebSeedS3RequesterPaysFlag.Usage)
+	rootCmd.Flags().StringVar(&releaseKeys, utils.DownloaderReleaseKeysFlag.Name, utils.DownloaderReleaseKeysFlag.Value, utils.DownloaderReleaseKeysFlag.Usage)
 	rootCmd.Flags().StringVar(&natSetting, "nat", utils.NATFlag.Value, utils.NATFlag.Usage)
 	rootCmd.Flags().StringVar(&downloaderApiAddr, "downloader.api.addr", "127.0.0.1:9093", "external downloader api network address, for example: 127.0.0.1:9093 serves remote downloader interface")
 	rootCmd.Flags().StringVar(&downloadRateStr, "torrent.download.rate", utils.TorrentDownloadRateFlag.Value, utils.TorrentDownloadRateFlag.Usage)
 	rootCmd.Flags().StringVar(&uploadRateStr, "torrent.upload.rate", utils.TorrentUploadRateFlag.Value, utils.TorrentUploadRateFlag.Usage)
 	rootCmd.Flags().IntVar(&torrentVerbosity, "torrent.verbosity", utils.TorrentVerbosityFlag.Value, utils.TorrentVerbosityFlag.Usage)
+	rootCmd.Flags().StringVar(&bandwidthSchedule, utils.TorrentBandwidthScheduleFlag.Name, utils.TorrentBandwidthScheduleFlag.Value, utils.TorrentBandwidthScheduleFlag.Usage)
 	rootCmd.Flags().IntVar(&torrentPort, "torrent.port", utils.TorrentPortFlag.Value, utils.TorrentPortFlag.Usage)
 	rootCmd.Flags().IntVar(&torrentMaxPeers, "torrent.maxpeers", utils.TorrentMaxPeersFlag.Value, utils.TorrentMaxPeersFlag.Usage)
 	rootCmd.Flags().IntVar(&torrentConnsPerFile, "torrent.conns.perfile", utils.TorrentConnsPerFileFlag.Value, utils.TorrentConnsPerFileFlag.Usage)
@@ -104,7 +124,12 @@ func init() {
 	rootCmd.Flags().StringVar(&staticPeersStr, utils.TorrentStaticPeersFlag.Name, utils.TorrentStaticPeersFlag.Value, utils.TorrentStaticPeersFlag.Usage)
 	rootCmd.Flags().BoolVar(&disableIPV6, "downloader.disable.ipv6", utils.DisableIPV6.Value, utils.DisableIPV6.Usage)
 	rootCmd.Flags().BoolVar(&disableIPV4, "downloader.disable.ipv4", utils.DisableIPV4.Value, utils.DisableIPV6.Usage)
+	rootCmd.Flags().StringVar(&tlsCertFile, "tls.cert", "", "certificate for gRPC server TLS handshake")
+	rootCmd.Flags().StringVar(&tlsKeyFile, "tls.key", "", "key file for gRPC server TLS handshake")
+	rootCmd.Flags().StringVar(&tlsCACert, "tls.cacert", "", "CA certificate: enables mutual TLS - clients must present certificate signed by it")
+	rootCmd.Flags().StringVar(&tlsAllowedCNs, "tls.allowed.cn", "", "comma-separated Common Names of clients allowed to connect (requires --tls.cacert)")
 	rootCmd.Flags().BoolVar(&seedbox, "seedbox", false, "Turns downloader into independent (doesn't need Erigon) software which discover/download/seed new files - useful for Erigon network, and can work on very cheap hardware. It will: 1) download .torrent from webseed 2) download new files after upgrade 3) we planing add discovery of new files soon")
+	rootCmd.Flags().BoolVar(&seedOnly, "seed-only", false, "Never download: only seed complete files from --datadir. Files copied to datadir later are seeded after rescan. Use --torrent.upload.rate and --torrent.schedule to limit traffic")
 	rootCmd.PersistentFlags().BoolVar(&verify, "verify", false, utils.DownloaderVerifyFlag.Usage)
 	rootCmd.PersistentFlags().StringVar(&_verifyFiles, "verify.files", "", "Limit list of files to verify")
 	rootCmd.PersistentFlags().BoolVar(&verifyFailfast, "verify.failfast", false, "Stop on first found error. Report it and exit")
@@ -129,9 +154,30 @@ func init() {
 	withChainFlag(manifestVerifyCmd)
 	rootCmd.AddCommand(manifestVerifyCmd)
 
+	manifestSignCmd.Flags().StringVar(&filePath, "file", "", "manifest to sign: output of torrent_hashes")
+	manifestSignCmd.Flags().StringVar(&releaseKeyFile, "key", "", "file with hex-encoded ed25519 private key (seed) of release. Created with public key printed if not exists")
+	must(manifestSignCmd.MarkFlagRequired("key"))
+	must(manifestSignCmd.MarkFlagRequired("file"))
+	rootCmd.AddCommand(manifestSignCmd)
+
+	bandwidthCmd.Flags().StringVar(&downloaderApiAddr, "downloader.api.addr", "127.0.0.1:9093", "address of running downloader gRPC API")
+	bandwidthCmd.Flags().StringVar(&setBandwidthSchedule, "set", "", "new schedule (format of --"+utils.TorrentBandwidthScheduleFlag.Name+"), 'none' - remove schedule. Without flag - print current schedule")
+	bandwidthCmd.Flags().StringVar(&tlsCertFile, "tls.cert", "", "client certificate for gRPC TLS")
+	bandwidthCmd.Flags().StringVar(&tlsKeyFile, "tls.key", "", "client key for gRPC TLS")
+	bandwidthCmd.Flags().StringVar(&tlsCACert, "tls.cacert", "", "CA certificate of downloader's gRPC server")
+	rootCmd.AddCommand(bandwidthCmd)
+
+	progressCmd.Flags().StringVar(&downloaderApiAddr, "downloader.api.addr", "127.0.0.1:9093", "address of running downloader gRPC API")
+	progressCmd.Flags().BoolVar(&progressAll, "all", false, "print also completed files")
+	progressCmd.Flags().StringVar(&tlsCertFile, "tls.cert", "", "client certificate for gRPC TLS")
+	progressCmd.Flags().StringVar(&tlsKeyFile, "tls.key", "", "client key for gRPC TLS")
+	progressCmd.Flags().StringVar(&tlsCACert, "tls.cacert", "", "CA certificate of downloader's gRPC server")
+	rootCmd.AddCommand(progressCmd)
+
 	withDataDir(printTorrentHashes)
 	withChainFlag(printTorrentHashes)
 	printTorrentHashes.PersistentFlags().BoolVar(&forceRebuild, "rebuild", false, "Force re-create .torrent files")
+	printTorrentHashes.Flags().BoolVar(&withV2Roots, "v2", false, "Add BitTorrent v2 pieces roots of files (reads all files), format: name = { hash = \"..\", root = \"..\" }")
 	printTorrentHashes.Flags().StringVar(&targetFile, "targetfile", "", "write output to file")
 	if err := printTorrentHashes.MarkFlagFilename("targetfile"); err != nil {
 		panic(err)
@@ -194,6 +240,9 @@ func Downloader(ctx context.Context, logger log.Logger) error {
 	if err := checkChainName(ctx, dirs, chain); err != nil {
 		return err
 	}
+	if seedOnly && seedbox {
+		return fmt.Errorf("--seed-only and --seedbox are mutually exclusive: seedbox downloads files")
+	}
 	torrentLogLevel, _, err := downloadercfg.Int2LogLevel(torrentVerbosity)
 	if err != nil {
 		return err
@@ -221,6 +270,17 @@ func Downloader(ctx context.Context, logger log.Logger) error {
 		return err
 	}
 
+	cfg.WebSeedS3 = downloadercfg.S3FromEnv()
+	cfg.WebSeedS3.Endpoint, cfg.WebSeedS3.RequesterPays = webseedS3.Endpoint, webseedS3.RequesterPays
+	if webseedS3.Region != "" {
+		cfg.WebSeedS3.Region = webseedS3.Region
+	}
+	if cfg.ReleaseKeys, err = downloadercfg.ParseReleaseKeys(releaseKeys); err != nil {
+		return err
+	}
+	cfg.BandwidthSchedule = bandwidthSchedule
+	cfg.SeedOnly = seedOnly
+
 	cfg.ClientConfig.PieceHashersPerTorrent = dbg.EnvInt("DL_HASHERS", 32)
 	cfg.ClientConfig.DisableIPv6 = disableIPV6
 	cfg.ClientConfig.DisableIPv4 = disableIPV4
@@ -268,7 +328,15 @@ func Downloader(ctx context.Context, logger log.Logger) error {
 		}
 	}
 
-	grpcServer, err := StartGrpc(bittorrentServer, downloaderApiAddr, nil /* transportCredentials */, logger)
+	creds, err := grpcutil.ServerTLS(grpcutil.TLSConfig{CACert: tlsCACert, CertFile: tlsCertFile, KeyFile: tlsKeyFile, AllowedCNs: grpcutil.ParseAllowedCNs(tlsAllowedCNs)})
+	if err != nil {
+		return err
+	}
+	var credsPtr *credentials.TransportCredentials
+	if creds != nil {
+		credsPtr = &creds
+	}
+	grpcServer, err := StartGrpc(bittorrentServer, downloaderApiAddr, credsPtr, logger)
 	if err != nil {
 		return err
 	}
@@ -329,6 +397,128 @@ var manifestVerifyCmd = &cobra.Command{
 	},
 }
 
+var manifestSignCmd = &cobra.Command{
+	Use:     "manifest-sign",
+	Example: "go run ./cmd/downloader torrent_hashes --datadir <your_datadir> --chain <chain> > preverified.toml && go run ./cmd/downloader manifest-sign --file preverified.toml --key <key_file>",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := debug.SetupCobra(cmd, "downloader")
+		if err := manifestSign(logger); err != nil {
+			log.Error(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// manifestSign - writes <file>.sig next to manifest. Both files must be uploaded to webseed
+func manifestSign(logger log.Logger) error {
+	key, created, err := downloadercfg.LoadReleaseKey(releaseKeyFile)
+	if err != nil {
+		return err
+	}
+	if created {
+		logger.Info("created release key", "file", releaseKeyFile)
+	}
+
+	manifest, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	// check before signing: nodes will reject manifest which can't be parsed
+	if _, err := downloader.VerifySignedManifest(manifest, downloader.SignManifest(key, manifest), []ed25519.PublicKey{key.Public().(ed25519.PublicKey)}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath+downloader.SignedManifestSigSuffix, downloader.SignManifest(key, manifest), 0644); err != nil { // nolint
+		return err
+	}
+	logger.Info("signed", "file", filePath+downloader.SignedManifestSigSuffix, "release_key", hex.EncodeToString(key.Public().(ed25519.PublicKey)))
+	return nil
+}
+
+var bandwidthCmd = &cobra.Command{
+	Use:     "bandwidth",
+	Short:   "Print or change bandwidth schedule of running downloader",
+	Example: "go run ./cmd/downloader bandwidth --downloader.api.addr 127.0.0.1:9093 --set 'mon-fri 09:00-18:00 download=6mb upload=1mb'",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := grpcutil.ClientTLS(grpcutil.TLSConfig{CACert: tlsCACert, CertFile: tlsCertFile, KeyFile: tlsKeyFile})
+		if err != nil {
+			return err
+		}
+		conn, err := grpcutil.Connect(creds, downloaderApiAddr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		client := proto_downloader.NewDownloaderClient(conn)
+
+		var reply *proto_downloader.BandwidthScheduleReply
+		switch setBandwidthSchedule {
+		case "":
+			reply, err = client.BandwidthSchedule(cmd.Context(), &proto_downloader.BandwidthScheduleRequest{})
+		case "none":
+			reply, err = client.SetBandwidthSchedule(cmd.Context(), &proto_downloader.SetBandwidthScheduleRequest{})
+		default:
+			var s *downloader.BandwidthSchedule
+			if s, err = downloader.ParseBandwidthSchedule(setBandwidthSchedule); err != nil {
+				return err
+			}
+			reply, err = client.SetBandwidthSchedule(cmd.Context(), &proto_downloader.SetBandwidthScheduleRequest{Rules: downloader.BandwidthScheduleToProto(s)})
+		}
+		if err != nil {
+			return err
+		}
+		applied, err := downloader.BandwidthScheduleFromProto(reply.Rules)
+		if err != nil {
+			return err
+		}
+		schedule := applied.String()
+		if schedule == "" {
+			schedule = "<none>: --torrent.download.rate, --torrent.upload.rate are used"
+		}
+		fmt.Println(schedule)
+		return nil
+	},
+}
+
+var progressCmd = &cobra.Command{
+	Use:     "progress",
+	Short:   "Print state of each file of running downloader: queued, downloading, verifying, done",
+	Example: "go run ./cmd/downloader progress --downloader.api.addr 127.0.0.1:9093",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := grpcutil.ClientTLS(grpcutil.TLSConfig{CACert: tlsCACert, CertFile: tlsCertFile, KeyFile: tlsKeyFile})
+		if err != nil {
+			return err
+		}
+		conn, err := grpcutil.Connect(creds, downloaderApiAddr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		reply, err := proto_downloader.NewDownloaderClient(conn).Progress(cmd.Context(), &proto_downloader.ProgressRequest{})
+		if err != nil {
+			return err
+		}
+		report := downloader.ProgressReportFromProto(reply)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tSTATE\tPROGRESS\tPEERS\tWEBSEEDS\tETA")
+		for _, f := range report.Files {
+			if f.State == downloader.FileDone && !progressAll {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s/%s\t%d (%s/s)\t%d (%s/s)\t%s\n", f.Name, f.State,
+				common.ByteCount(f.BytesCompleted), common.ByteCount(f.BytesTotal),
+				f.Peers, common.ByteCount(f.PeersRate), f.Webseeds, common.ByteCount(f.WebseedsRate), f.Eta)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("total: %s/%s, %s/s, eta: %s, completed: %t\n", common.ByteCount(report.BytesCompleted), common.ByteCount(report.BytesTotal),
+			common.ByteCount(report.DownloadRate), report.Eta, report.Completed)
+		return nil
+	},
+}
+
 var torrentCat = &cobra.Command{
 	Use:     "torrent_cat",
 	Example: "go run ./cmd/downloader torrent_cat <path_to_torrent_file>",
@@ -529,7 +719,7 @@ func doPrintTorrentHashes(ctx context.Context, logger log.Logger) error {
 		log.Info("created .torent files", "amount", createdAmount)
 	}
 
-	res := map[string]string{}
+	var res snapcfg.Preverified
 	torrents, err := downloader.AllTorrentSpecs(dirs, tf)
 	if err != nil {
 		return err
@@ -543,9 +733,17 @@ func doPrintTorrentHashes(ctx context.Context, logger log.Logger) error {
 		if strings.Contains(t.DisplayName, "idx") && strings.Contains(t.DisplayName, "commitment") {
 			continue
 		}
-		res[t.DisplayName] = t.InfoHash.String()
+		item := snapcfg.PreverifiedItem{Name: t.DisplayName, Hash: t.InfoHash.String()}
+		if withV2Roots {
+			root, err := downloader.FileRootV2(ctx, filepath.Join(dirs.Snap, t.DisplayName))
+			if err != nil {
+				return err
+			}
+			item.Root = root.String()
+		}
+		res = append(res, item)
 	}
-	serialized, err := toml.Marshal(res)
+	serialized, err := res.MarshalToml()
 	if err != nil {
 		return err
 	}
@@ -559,8 +757,8 @@ func doPrintTorrentHashes(ctx context.Context, logger log.Logger) error {
 	if err != nil {
 		return err
 	}
-	oldLines := map[string]string{}
-	if err := toml.Unmarshal(oldContent, &oldLines); err != nil {
+	oldLines, err := snapcfg.ParsePreverifiedToml(oldContent)
+	if err != nil {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 	if len(oldLines) >= len(res) {
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/cmd/hack/tool/fromdb"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/consensuschain"
+	"github.com/ledgerwatch/erigon/eth/ethconsensusconfig"
+	"github.com/ledgerwatch/erigon/node/nodecfg"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/debug"
+)
+
+var cmdRebuildCliqueSnapshots = &cobra.Command{
+	Use:   "rebuild_clique_snapshots",
+	Short: "Delete stored clique voting snapshots and rebuild them from headers up to --block (default: head). AuRa keeps only epoch transitions, there is nothing to rebuild",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+		db, err := openDB(dbCfg(kv.ChainDB, chaindata), false, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return err
+		}
+		defer db.Close()
+
+		if err = rebuildCliqueSnapshots(ctx, db, block, logger); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Error(err.Error())
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	withDataDir(cmdRebuildCliqueSnapshots)
+	withBlock(cmdRebuildCliqueSnapshots)
+	rootCmd.AddCommand(cmdRebuildCliqueSnapshots)
+}
+
+func rebuildCliqueSnapshots(ctx context.Context, db kv.RwDB, to uint64, logger log.Logger) error {
+	chainConfig := fromdb.ChainConfig(db)
+	if chainConfig.Clique == nil {
+		return errors.New("not a clique chain")
+	}
+	blockReader, _ := blocksIO(db, logger)
+
+	// same location of clique db as the node uses, see utils.setClique
+	snapshotConfig := params.NewSnapshotConfig(10, 1024, 16384, false, "")
+	snapshotConfig.DBPath = filepath.Join(datadirCli, "clique", "db")
+	engine := ethconsensusconfig.CreateConsensusEngine(ctx, &nodecfg.Config{Dirs: datadir.New(datadirCli)}, chainConfig, snapshotConfig,
+		nil, false, nil, true, blockReader, false, logger)
+	defer engine.Close()
+	c, ok := engine.(*clique.Clique)
+	if !ok {
+		return fmt.Errorf("unexpected consensus engine %T", engine)
+	}
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if to == 0 {
+		head := rawdb.ReadCurrentHeader(tx)
+		if head == nil {
+			return errors.New("no headers")
+		}
+		to = head.Number.Uint64()
+	}
+
+	logger.Info("[Clique] Rebuilding snapshots", "to", to)
+	if err = c.RebuildSnapshots(ctx, consensuschain.NewReader(chainConfig, tx, blockReader, logger), to); err != nil {
+		return err
+	}
+	logger.Info("[Clique] Rebuilt snapshots", "to", to)
+	return nil
+}
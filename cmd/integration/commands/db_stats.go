@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/spf13/cobra"
+
+	"github.com/ledgerwatch/erigon/turbo/debug"
+)
+
+var cmdDbStats = &cobra.Command{
+	Use:   "db_stats",
+	Short: "Per-table stats: entries, size, pages, estimated fill factor. Same as admin_dbStats RPC",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		db, err := openDB(dbCfg(kv.ChainDB, chaindata).Readonly(), false, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		s, ok := db.(kv.HasTableStats)
+		if !ok {
+			logger.Error("table stats not supported", "db", fmt.Sprintf("%T", db))
+			return
+		}
+		stats, err := s.TableStats(cmd.Context())
+		if err != nil {
+			logger.Error("collecting table stats", "err", err)
+			return
+		}
+
+		if outputCsvFile != "" {
+			if err := writeDbStatsCsv(outputCsvFile, stats); err != nil {
+				logger.Error("issue writing output to file", "file", outputCsvFile, "err", err)
+				return
+			}
+			logger.Info("wrote table stats to csv output file", "file", outputCsvFile)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "Table\tEntries\tSize\tDepth\tBranch\tLeaf\tOverflow\tFill\t")
+		for _, st := range stats {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%d\t%d\t%.2f\t\n", st.Name, st.Entries, libcommon.ByteCount(st.Size), st.Depth, st.BranchPages, st.LeafPages, st.OverflowPages, st.FillFactor)
+		}
+		if err := w.Flush(); err != nil {
+			logger.Error("print table stats", "err", err)
+		}
+	},
+}
+
+func writeDbStatsCsv(fileName string, stats []kv.TableStat) error {
+	var sb strings.Builder
+	sb.WriteString("Table,Entries,Size,Depth,BranchPages,LeafPages,OverflowPages,FillFactor\n")
+	for _, st := range stats {
+		sb.WriteString(fmt.Sprintf("%s,%d,%d,%d,%d,%d,%d,%.4f\n", st.Name, st.Entries, st.Size, st.Depth, st.BranchPages, st.LeafPages, st.OverflowPages, st.FillFactor))
+	}
+	return os.WriteFile(fileName, []byte(sb.String()), 0644)
+}
+
+func init() {
+	withDataDir(cmdDbStats)
+	withOutputCsvFile(cmdDbStats)
+	rootCmd.AddCommand(cmdDbStats)
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/spf13/cobra"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/membatchwithdb"
+
+	"github.com/ledgerwatch/erigon/migrations"
+)
+
+var dryRun bool
+
+func withDryRun(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "run stage on read-only overlay of db and report what it would write (tables, new files), nothing is committed")
+}
+
+// dryRunTx - with --dry-run stage runs on in-memory overlay over RoTx: `Commit` reports changes of tables and
+// files created in snapshots dir instead of applying them. Stage can't see changes of other transactions it opens.
+type dryRunTx struct {
+	*membatchwithdb.MemoryMutation
+	roTx   kv.Tx
+	dirs   datadir.Dirs
+	files  map[string]int64 // files of snapshots dir before stage run
+	logger log.Logger
+	closed bool
+}
+
+// beginStageRw - RwTx in which stage is run: `--dry-run` overlay or real one
+func beginStageRw(ctx context.Context, db kv.RwDB, logger log.Logger) (kv.RwTx, error) {
+	if !dryRun {
+		return db.BeginRw(ctx)
+	}
+	dirs := datadir.New(datadirCli)
+	files, err := dirFiles(dirs.Snap)
+	if err != nil {
+		return nil, err
+	}
+	roTx, err := db.BeginRo(ctx) //nolint:gocritic
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunTx{
+		MemoryMutation: membatchwithdb.NewMemoryBatch(roTx, dirs.Tmp, logger),
+		roTx:           roTx,
+		dirs:           dirs,
+		files:          files,
+		logger:         logger,
+	}, nil
+}
+
+// commitStageRw - for stages which run in external tx and don't commit it: reports changes of `--dry-run`
+func commitStageRw(tx kv.RwTx) error {
+	if t, ok := tx.(*dryRunTx); ok {
+		return t.Commit()
+	}
+	return nil
+}
+
+func (t *dryRunTx) Commit() error {
+	changes, err := t.Changes()
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		t.logger.Info("[dry-run] Table", "name", c.Table, "put", c.Put, "put_size", libcommon.ByteCount(c.PutSize), "deleted", c.Deleted, "cleared", c.Cleared)
+	}
+
+	files, err := dirFiles(t.dirs.Snap)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if _, ok := t.files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.logger.Info("[dry-run] New file", "name", name, "size", libcommon.ByteCount(uint64(files[name])))
+	}
+	t.logger.Info("[dry-run] Done, nothing is committed", "tables", len(changes), "new_files", len(names))
+	return nil
+}
+
+func (t *dryRunTx) Rollback() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.MemoryMutation.Rollback()
+	t.roTx.Rollback()
+}
+
+// dirFiles - sizes of files of `dir` and its subdirs, by path relative to `dir`
+func dirFiles(dir string) (map[string]int64, error) {
+	files := map[string]int64{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = info.Size()
+		return nil
+	})
+	return files, err
+}
+
+// logPendingMigrations - `--dry-run` doesn't apply migrations, only reports them
+func logPendingMigrations(db kv.RoDB, migrator *migrations.Migrator, logger log.Logger) error {
+	return db.View(context.Background(), func(tx kv.Tx) error {
+		pending, err := migrator.PendingMigrations(tx)
+		if err != nil {
+			return err
+		}
+		for _, m := range pending {
+			logger.Info("[dry-run] Pending migration is not applied", "name", m.Name)
+		}
+		return nil
+	})
+}
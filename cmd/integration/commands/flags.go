@@ -7,6 +7,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
 )
 
 var (
@@ -41,8 +42,9 @@ var (
 	startTxNum     uint64
 	traceFromTx    uint64
 
-	_forceSetHistoryV3    bool
-	workers, reconWorkers uint64
+	_forceSetHistoryV3                         bool
+	workers, reconWorkers, parallelExecWorkers uint64
+	trieWorkers                                int
 )
 
 func must(err error) {
@@ -166,6 +168,11 @@ func withHeimdall(cmd *cobra.Command) {
 func withWorkers(cmd *cobra.Command) {
 	cmd.Flags().Uint64Var(&workers, "exec.workers", uint64(ethconfig.Defaults.Sync.ExecWorkerCount), "")
 	cmd.Flags().Uint64Var(&reconWorkers, "recon.workers", uint64(ethconfig.Defaults.Sync.ReconWorkerCount), "")
+	cmd.Flags().Uint64Var(&parallelExecWorkers, "exec.parallel.workers", uint64(ethconfig.Defaults.Sync.ParallelExecWorkers), "workers of optimistic parallel execution of transactions of a block, 0 - serial execution")
+}
+
+func withTrieWorkers(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&trieWorkers, "trie.workers", min(16, estimate.AlmostAllCPUs()), "full regeneration of trie hashes builds up to 16 sub-tries (by first nibble of account key) in parallel, 1 - single-threaded")
 }
 
 func withStartTx(cmd *cobra.Command) {
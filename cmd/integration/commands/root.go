@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -72,6 +73,9 @@ func dbCfg(label kv.Label, path string) kv2.MdbxOpts {
 }
 
 func openDB(opts kv2.MdbxOpts, applyMigrations bool, logger log.Logger) (kv.RwDB, error) {
+	if dryRun && (reset || resetPruneAt) {
+		return nil, errors.New("--dry-run is not supported with --reset")
+	}
 	db := opts.MustOpen()
 	if applyMigrations {
 		migrator := migrations.NewMigrator(opts.GetLabel())
@@ -79,7 +83,11 @@ func openDB(opts kv2.MdbxOpts, applyMigrations bool, logger log.Logger) (kv.RwDB
 		if err != nil {
 			return nil, err
 		}
-		if has {
+		if has && dryRun {
+			if err := logPendingMigrations(db, migrator, logger); err != nil {
+				return nil, err
+			}
+		} else if has {
 			logger.Info("Re-Opening DB in exclusive mode to apply DB migrations")
 			db.Close()
 			db = opts.Exclusive().MustOpen()
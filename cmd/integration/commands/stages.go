@@ -607,6 +607,7 @@ func init() {
 	withDataDir(cmdStageSenders)
 	withChain(cmdStageSenders)
 	withHeimdall(cmdStageSenders)
+	withDryRun(cmdStageSenders)
 	rootCmd.AddCommand(cmdStageSenders)
 
 	withConfig(cmdStageSnapshots)
@@ -651,6 +652,7 @@ func init() {
 	withChain(cmdStageExec)
 	withHeimdall(cmdStageExec)
 	withWorkers(cmdStageExec)
+	withDryRun(cmdStageExec)
 	rootCmd.AddCommand(cmdStageExec)
 
 	withConfig(cmdStageCustomTrace)
@@ -665,6 +667,7 @@ func init() {
 	withChain(cmdStageCustomTrace)
 	withHeimdall(cmdStageCustomTrace)
 	withWorkers(cmdStageCustomTrace)
+	withDryRun(cmdStageCustomTrace)
 	rootCmd.AddCommand(cmdStageCustomTrace)
 
 	withConfig(cmdStageHashState)
@@ -676,6 +679,7 @@ func init() {
 	withBatchSize(cmdStageHashState)
 	withChain(cmdStageHashState)
 	withHeimdall(cmdStageHashState)
+	withDryRun(cmdStageHashState)
 	rootCmd.AddCommand(cmdStageHashState)
 
 	withConfig(cmdStageTrie)
@@ -687,6 +691,8 @@ func init() {
 	withIntegrityChecks(cmdStageTrie)
 	withChain(cmdStageTrie)
 	withHeimdall(cmdStageTrie)
+	withDryRun(cmdStageTrie)
+	withTrieWorkers(cmdStageTrie)
 	rootCmd.AddCommand(cmdStageTrie)
 
 	withConfig(cmdStagePatriciaTrie)
@@ -698,6 +704,7 @@ func init() {
 	withIntegrityChecks(cmdStagePatriciaTrie)
 	withChain(cmdStagePatriciaTrie)
 	withHeimdall(cmdStagePatriciaTrie)
+	withDryRun(cmdStagePatriciaTrie)
 	rootCmd.AddCommand(cmdStagePatriciaTrie)
 
 	withConfig(cmdStageHistory)
@@ -708,6 +715,7 @@ func init() {
 	withPruneTo(cmdStageHistory)
 	withChain(cmdStageHistory)
 	withHeimdall(cmdStageHistory)
+	withDryRun(cmdStageHistory)
 	rootCmd.AddCommand(cmdStageHistory)
 
 	withConfig(cmdLogIndex)
@@ -719,6 +727,7 @@ func init() {
 	withPruneTo(cmdLogIndex)
 	withChain(cmdLogIndex)
 	withHeimdall(cmdLogIndex)
+	withDryRun(cmdLogIndex)
 	rootCmd.AddCommand(cmdLogIndex)
 
 	withConfig(cmdCallTraces)
@@ -729,6 +738,7 @@ func init() {
 	withPruneTo(cmdCallTraces)
 	withChain(cmdCallTraces)
 	withHeimdall(cmdCallTraces)
+	withDryRun(cmdCallTraces)
 	rootCmd.AddCommand(cmdCallTraces)
 
 	withConfig(cmdStageTxLookup)
@@ -739,6 +749,7 @@ func init() {
 	withPruneTo(cmdStageTxLookup)
 	withChain(cmdStageTxLookup)
 	withHeimdall(cmdStageTxLookup)
+	withDryRun(cmdStageTxLookup)
 	rootCmd.AddCommand(cmdStageTxLookup)
 
 	withConfig(cmdPrintMigrations)
@@ -757,6 +768,7 @@ func init() {
 	withSqueezeCommitmentFiles(cmdRunMigrations)
 	withChain(cmdRunMigrations)
 	withHeimdall(cmdRunMigrations)
+	withDryRun(cmdRunMigrations)
 	rootCmd.AddCommand(cmdRunMigrations)
 
 	withConfig(cmdSetSnap)
@@ -1067,7 +1079,7 @@ func stageSenders(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		return db.Update(ctx, func(tx kv.RwTx) error { return reset2.ResetSenders(ctx, db, tx) })
 	}
 
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1189,6 +1201,7 @@ func stageExec(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	syncCfg := ethconfig.Defaults.Sync
 	syncCfg.ExecWorkerCount = int(workers)
 	syncCfg.ReconWorkerCount = int(reconWorkers)
+	syncCfg.ParallelExecWorkers = int(parallelExecWorkers)
 
 	genesis := core.GenesisBlockByChainName(chain)
 	br, _ := blocksIO(db, logger)
@@ -1213,9 +1226,9 @@ func stageExec(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	}
 
 	var tx kv.RwTx //nil - means lower-level code (each stage) will manage transactions
-	if noCommit {
+	if noCommit || dryRun {
 		var err error
-		tx, err = db.BeginRw(ctx)
+		tx, err = beginStageRw(ctx, db, logger)
 		if err != nil {
 			return err
 		}
@@ -1229,7 +1242,7 @@ func stageExec(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		if err != nil {
 			return err
 		}
-		return nil
+		return commitStageRw(tx)
 	}
 
 	if pruneTo > 0 {
@@ -1241,7 +1254,7 @@ func stageExec(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		if err != nil {
 			return err
 		}
-		return nil
+		return commitStageRw(tx)
 	}
 
 	err := stagedsync.SpawnExecuteBlocksStage(s, sync, txc, block, ctx, cfg, true /* initialCycle */, logger)
@@ -1249,7 +1262,7 @@ func stageExec(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		return err
 	}
 
-	return nil
+	return commitStageRw(tx)
 }
 
 func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error {
@@ -1298,6 +1311,7 @@ func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error
 	syncCfg := ethconfig.Defaults.Sync
 	syncCfg.ExecWorkerCount = int(workers)
 	syncCfg.ReconWorkerCount = int(reconWorkers)
+	syncCfg.ParallelExecWorkers = int(parallelExecWorkers)
 
 	genesis := core.GenesisBlockByChainName(chain)
 	br, _ := blocksIO(db, logger)
@@ -1320,9 +1334,9 @@ func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error
 	}
 
 	var tx kv.RwTx //nil - means lower-level code (each stage) will manage transactions
-	if noCommit {
+	if noCommit || dryRun {
 		var err error
-		tx, err = db.BeginRw(ctx)
+		tx, err = beginStageRw(ctx, db, logger)
 		if err != nil {
 			return err
 		}
@@ -1336,7 +1350,7 @@ func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error
 		if err != nil {
 			return err
 		}
-		return nil
+		return commitStageRw(tx)
 	}
 
 	if pruneTo > 0 {
@@ -1348,7 +1362,7 @@ func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error
 		if err != nil {
 			return err
 		}
-		return nil
+		return commitStageRw(tx)
 	}
 
 	err := stagedsync.SpawnCustomTrace(s, txc, cfg, ctx, true /* initialCycle */, 0, logger)
@@ -1356,7 +1370,7 @@ func stageCustomTrace(db kv.RwDB, ctx context.Context, logger log.Logger) error
 		return err
 	}
 
-	return nil
+	return commitStageRw(tx)
 }
 
 func stageTrie(db kv.RwDB, ctx context.Context, logger log.Logger) error {
@@ -1374,7 +1388,7 @@ func stageTrie(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	if reset {
 		return reset2.Reset(ctx, db, stages.IntermediateHashes)
 	}
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1393,7 +1407,7 @@ func stageTrie(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	logger.Info("StageExec", "progress", execStage.BlockNumber)
 	logger.Info("StageTrie", "progress", s.BlockNumber)
 	br, _ := blocksIO(db, logger)
-	cfg := stagedsync.StageTrieCfg(db, true /* checkRoot */, true /* saveHashesToDb */, false /* badBlockHalt */, dirs.Tmp, br, nil /* hd */, historyV3, agg)
+	cfg := stagedsync.StageTrieCfg(db, true /* checkRoot */, true /* saveHashesToDb */, false /* badBlockHalt */, dirs.Tmp, br, nil /* hd */, historyV3, agg).WithWorkers(trieWorkers)
 	if unwind > 0 {
 		u := sync.NewUnwindState(stages.IntermediateHashes, s.BlockNumber-unwind, s.BlockNumber)
 		if err := stagedsync.UnwindIntermediateHashesStage(u, s, tx, cfg, ctx, logger); err != nil {
@@ -1431,7 +1445,7 @@ func stagePatriciaTrie(db kv.RwDB, ctx context.Context, logger log.Logger) error
 	if reset {
 		return reset2.Reset(ctx, db, stages.Execution)
 	}
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1466,7 +1480,7 @@ func stageHashState(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		return reset2.Reset(ctx, db, stages.HashState)
 	}
 
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1523,7 +1537,7 @@ func stageLogIndex(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	if resetPruneAt {
 		return reset2.ResetPruneAt(ctx, db, stages.LogIndex)
 	}
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1536,12 +1550,13 @@ func stageLogIndex(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		pm.Receipts = prune.Distance(s.BlockNumber - pruneTo)
 		pm.CallTraces = prune.Distance(s.BlockNumber - pruneTo)
 		pm.TxIndex = prune.Distance(s.BlockNumber - pruneTo)
+		pm.LogIndex = prune.Distance(s.BlockNumber - pruneTo)
 	}
 
 	logger.Info("Stage exec", "progress", execAt)
 	logger.Info("Stage", "name", s.ID, "progress", s.BlockNumber)
 
-	cfg := stagedsync.StageLogIndexCfg(db, pm, dirs.Tmp, chainConfig.DepositContract)
+	cfg := stagedsync.StageLogIndexCfg(db, pm, dirs.Tmp, chainConfig.DepositContract, nil)
 	if unwind > 0 {
 		u := sync.NewUnwindState(stages.LogIndex, s.BlockNumber-unwind, s.BlockNumber)
 		err = stagedsync.UnwindLogIndex(u, s, tx, cfg, ctx)
@@ -1580,7 +1595,7 @@ func stageCallTraces(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 		return reset2.Reset(ctx, db, stages.CallTraces)
 	}
 
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1646,7 +1661,7 @@ func stageHistory(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	if reset {
 		return reset2.Reset(ctx, db, stages.AccountHistoryIndex, stages.StorageHistoryIndex)
 	}
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1717,7 +1732,7 @@ func stageTxLookup(db kv.RwDB, ctx context.Context, logger log.Logger) error {
 	if reset {
 		return db.Update(ctx, func(tx kv.RwTx) error { return reset2.ResetTxLookup(tx) })
 	}
-	tx, err := db.BeginRw(ctx)
+	tx, err := beginStageRw(ctx, db, logger)
 	if err != nil {
 		return err
 	}
@@ -1901,6 +1916,7 @@ func newSync(ctx context.Context, db kv.RwDB, miningConfig *params.MiningConfig,
 		chainConfig,
 		genesisBlock,
 		chainConfig.ChainID.Uint64(),
+		0,
 	)
 
 	maxBlockBroadcastPeers := func(header *types.Header) uint { return 0 }
@@ -1976,7 +1992,7 @@ func newSync(ctx context.Context, db kv.RwDB, miningConfig *params.MiningConfig,
 				nil,
 			),
 			stagedsync.StageSendersCfg(db, sentryControlServer.ChainConfig, cfg.Sync, false, dirs.Tmp, cfg.Prune, blockReader, sentryControlServer.Hd, nil),
-			stagedsync.StageMiningExecCfg(db, miner, events, *chainConfig, engine, &vm.Config{}, dirs.Tmp, nil, 0, nil, nil, blockReader),
+			stagedsync.StageMiningExecCfg(db, miner, events, *chainConfig, engine, &vm.Config{}, dirs.Tmp, nil, 0, nil, nil, blockReader, nil),
 			stagedsync.StageMiningFinishCfg(db, *chainConfig, engine, miner, miningCancel, blockReader, builder.NewLatestBlockBuiltStore()),
 		),
 		stagedsync.MiningUnwindOrder,
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"errors"
@@ -11,9 +12,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
@@ -63,6 +66,7 @@ import (
 	"github.com/ledgerwatch/erigon/rpc/rpccfg"
 	"github.com/ledgerwatch/erigon/turbo/debug"
 	"github.com/ledgerwatch/erigon/turbo/logging"
+	"github.com/ledgerwatch/erigon/turbo/reload"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
@@ -79,7 +83,12 @@ var rootCmd = &cobra.Command{
 }
 
 var (
-	stateCacheStr string
+	stateCacheStr     string
+	stateCachePin     []string
+	stateCachePinSize string
+
+	btPageCacheStr string
+	btFanoutStr    string
 )
 
 func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
@@ -101,6 +110,10 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().StringVar(&cfg.TxPoolApiAddr, "txpool.api.addr", "", "txpool api network address, for example: 127.0.0.1:9090 (default: use value of --private.api.addr)")
 
 	rootCmd.PersistentFlags().StringVar(&stateCacheStr, "state.cache", "0MB", "Amount of data to store in StateCache (enabled if no --datadir set). Set 0 to disable StateCache. Defaults to 0MB RAM")
+	rootCmd.PersistentFlags().StringSliceVar(&stateCachePin, utils.StateCachePinFlag.Name, nil, utils.StateCachePinFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&stateCachePinSize, utils.StateCachePinSizeFlag.Name, utils.StateCachePinSizeFlag.Value, utils.StateCachePinSizeFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&btPageCacheStr, "bt.page.cache", "", "Memory limit of cache of domain .bt index pages (for example: 1GB). Useful for historical requests on HDD. Empty - disabled")
+	rootCmd.PersistentFlags().StringVar(&btFanoutStr, "bt.fanout", "", "Per-domain amount of keys on leaf of .bt index, for example: accounts=128,storage=512. Empty - default for all domains")
 	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
 	rootCmd.PersistentFlags().StringVar(&cfg.GRPCListenAddress, "grpc.addr", nodecfg.DefaultGRPCHost, "GRPC server listening interface")
 	rootCmd.PersistentFlags().IntVar(&cfg.GRPCPort, "grpc.port", nodecfg.DefaultGRPCPort, "GRPC server listening port")
@@ -109,6 +122,7 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSCertfile, "tls.cert", "", "certificate for client side TLS handshake for GRPC")
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSKeyFile, "tls.key", "", "key file for client side TLS handshake for GRPC")
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSCACert, "tls.cacert", "", "CA certificate for client side TLS handshake for GRPC")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.TLSAllowedCNs, "tls.allowed.cn", nil, "Common Names expected from private api and txpool servers (requires --tls.cacert)")
 
 	rootCmd.PersistentFlags().StringSliceVar(&cfg.API, "http.api", []string{"eth", "erigon"}, "API's offered over the RPC interface: eth,erigon,web3,net,debug,trace,txpool,db. Supported methods: https://github.com/ledgerwatch/erigon/tree/main/cmd/rpcdaemon")
 
@@ -143,6 +157,17 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().IntVar(&cfg.ReturnDataLimit, utils.RpcReturnDataLimit.Name, utils.RpcReturnDataLimit.Value, utils.RpcReturnDataLimit.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.AllowUnprotectedTxs, utils.AllowUnprotectedTxs.Name, utils.AllowUnprotectedTxs.Value, utils.AllowUnprotectedTxs.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.MaxGetProofRewindBlockCount, utils.RpcMaxGetProofRewindBlockCount.Name, utils.RpcMaxGetProofRewindBlockCount.Value, utils.RpcMaxGetProofRewindBlockCount.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.TraceChainConcurrency, utils.RpcTraceChainConcurrencyFlag.Name, utils.RpcTraceChainConcurrencyFlag.Value, utils.RpcTraceChainConcurrencyFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.ReexecWorkers, utils.RpcReexecWorkersFlag.Name, utils.RpcReexecWorkersFlag.Value, utils.RpcReexecWorkersFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.ReexecQueueSize, utils.RpcReexecQueueFlag.Name, utils.RpcReexecQueueFlag.Value, utils.RpcReexecQueueFlag.Usage)
+	rootCmd.PersistentFlags().BoolVar(&cfg.ReceiptsRegen, utils.RpcReceiptsRegenFlag.Name, false, utils.RpcReceiptsRegenFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.ReceiptsRegenMaxTxs, utils.RpcReceiptsRegenMaxTxsFlag.Name, utils.RpcReceiptsRegenMaxTxsFlag.Value, utils.RpcReceiptsRegenMaxTxsFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.HealthReadyMaxLag, utils.HealthReadyMaxLagFlag.Name, utils.HealthReadyMaxLagFlag.Value, utils.HealthReadyMaxLagFlag.Usage)
+	rootCmd.PersistentFlags().UintVar(&cfg.HealthReadyMinPeers, utils.HealthReadyMinPeersFlag.Name, utils.HealthReadyMinPeersFlag.Value, utils.HealthReadyMinPeersFlag.Usage)
+	rootCmd.PersistentFlags().DurationVar(&cfg.ReceiptsRegenTimeout, utils.RpcReceiptsRegenTimeoutFlag.Name, utils.RpcReceiptsRegenTimeoutFlag.Value, utils.RpcReceiptsRegenTimeoutFlag.Usage)
+	rootCmd.PersistentFlags().DurationVar(&cfg.TracerCPUTime, utils.RpcTracerCPUTimeFlag.Name, utils.RpcTracerCPUTimeFlag.Value, utils.RpcTracerCPUTimeFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.TracerMemory, utils.RpcTracerMemoryFlag.Name, utils.RpcTracerMemoryFlag.Value, utils.RpcTracerMemoryFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.TracerOutputSize, utils.RpcTracerOutputSizeFlag.Name, utils.RpcTracerOutputSizeFlag.Value, utils.RpcTracerOutputSizeFlag.Usage)
 	rootCmd.PersistentFlags().Uint64Var(&cfg.OtsMaxPageSize, utils.OtsSearchMaxCapFlag.Name, utils.OtsSearchMaxCapFlag.Value, utils.OtsSearchMaxCapFlag.Usage)
 	rootCmd.PersistentFlags().DurationVar(&cfg.RPCSlowLogThreshold, utils.RPCSlowFlag.Name, utils.RPCSlowFlag.Value, utils.RPCSlowFlag.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.WebsocketSubscribeLogsChannelSize, utils.WSSubscribeLogsChannelSize.Name, utils.WSSubscribeLogsChannelSize.Value, utils.WSSubscribeLogsChannelSize.Usage)
@@ -165,6 +190,27 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 		if err != nil {
 			return fmt.Errorf("state.cache value of %v is not valid", stateCacheStr)
 		}
+		for _, addr := range stateCachePin {
+			if !libcommon.IsHexAddress(addr) {
+				return fmt.Errorf("state.cache.pin value of %v is not valid address", addr)
+			}
+			cfg.StateCache.PinnedAddresses = append(cfg.StateCache.PinnedAddresses, libcommon.HexToAddress(addr))
+		}
+		if err := cfg.StateCache.PinnedCacheSize.UnmarshalText([]byte(stateCachePinSize)); err != nil {
+			return fmt.Errorf("state.cache.pin.size value of %v is not valid", stateCachePinSize)
+		}
+		if btPageCacheStr != "" {
+			var limit datasize.ByteSize
+			if err := limit.UnmarshalText([]byte(btPageCacheStr)); err != nil {
+				return fmt.Errorf("bt.page.cache value of %v is not valid", btPageCacheStr)
+			}
+			libstate.SetBtreePageCache(limit)
+		}
+		if btFanoutStr != "" {
+			if err := libstate.SetBtreeFanouts(btFanoutStr); err != nil {
+				return fmt.Errorf("bt.fanout value of %v is not valid: %w", btFanoutStr, err)
+			}
+		}
 
 		cfg.WithDatadir = cfg.DataDir != ""
 		if cfg.WithDatadir {
@@ -291,6 +337,7 @@ func EmbeddedServices(ctx context.Context,
 	txPool = direct.NewTxPoolClient(txPoolServer)
 	mining = direct.NewMiningClient(miningServer)
 	ff = rpchelper.New(ctx, eth, txPool, mining, func() {}, logger)
+	ff.SetStateChangesClient(stateDiffClient)
 
 	return
 }
@@ -304,7 +351,7 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 	if !cfg.WithDatadir && cfg.PrivateApiAddr == "" {
 		return nil, nil, nil, nil, nil, nil, nil, ff, nil, fmt.Errorf("either remote db or local db must be specified")
 	}
-	creds, err := grpcutil.TLS(cfg.TLSCACert, cfg.TLSCertfile, cfg.TLSKeyFile)
+	creds, err := grpcutil.ClientTLS(grpcutil.TLSConfig{CACert: cfg.TLSCACert, CertFile: cfg.TLSCertfile, KeyFile: cfg.TLSKeyFile, AllowedCNs: cfg.TLSAllowedCNs})
 	if err != nil {
 		return nil, nil, nil, nil, nil, nil, nil, ff, nil, fmt.Errorf("open tls cert: %w", err)
 	}
@@ -381,6 +428,10 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 		// Erigon does store list of snapshots in db: means RPCDaemon can read this list now, but read by `remoteKvClient.Snapshots` after establish grpc connection
 		allSnapshots.OptimisticReopenWithDB(db)
 		allBorSnapshots.OptimisticalyReopenWithDB(db)
+		// node with --snap.download.profile=minimal has no block files of old blocks: they are out of RPC data window
+		if ranges := allSnapshots.Ranges(); len(ranges) > 0 {
+			allSnapshots.SetSegmentsMin(ranges[0].From())
+		}
 		allSnapshots.LogStat("remote")
 		allBorSnapshots.LogStat("bor:remote")
 
@@ -544,6 +595,7 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 	}()
 
 	ff = rpchelper.New(ctx, eth, txPool, mining, onNewSnapshot, logger)
+	ff.SetStateChangesClient(remoteKvClient)
 	return db, eth, txPool, mining, stateCache, blockReader, engine, ff, agg, err
 }
 
@@ -559,7 +611,7 @@ func StartRpcServerWithJwtAuthentication(ctx context.Context, cfg *httpcfg.HttpC
 	if len(rpcAPI) == 0 {
 		return nil
 	}
-	engineInfo, err := startAuthenticatedRpcServer(cfg, rpcAPI, logger)
+	engineInfo, err := startAuthenticatedRpcServer(ctx, cfg, rpcAPI, logger)
 	if err != nil {
 		return err
 	}
@@ -578,6 +630,7 @@ func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []r
 	srv.SetAllowList(allowListForRPC)
 
 	srv.SetBatchLimit(cfg.BatchLimit)
+	registerReload(srv, true)
 
 	defer srv.Stop()
 
@@ -751,10 +804,32 @@ type engineInfo struct {
 	EngineHttpEndpoint string
 }
 
-func startAuthenticatedRpcServer(cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, logger log.Logger) (*engineInfo, error) {
+// registerReload - limits of srv changed by config reload of erigon (see turbo/reload)
+func registerReload(srv *rpc.Server, batchLimit bool) {
+	if batchLimit {
+		reload.Register(utils.RpcBatchLimit.Name, func(value string) error {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			srv.SetBatchLimit(limit)
+			return nil
+		})
+	}
+	reload.Register(utils.RPCSlowFlag.Name, func(value string) error {
+		threshold, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		srv.SetSlowLogThreshold(threshold)
+		return nil
+	})
+}
+
+func startAuthenticatedRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, logger log.Logger) (*engineInfo, error) {
 	srv := rpc.NewServer(cfg.RpcBatchConcurrency, cfg.TraceRequests, cfg.DebugSingleRequest, cfg.RpcStreamingDisable, logger, cfg.RPCSlowLogThreshold)
 
-	engineListener, engineSrv, engineHttpEndpoint, err := createEngineListener(cfg, rpcAPI, logger)
+	engineListener, engineSrv, engineHttpEndpoint, err := createEngineListener(ctx, cfg, rpcAPI, logger)
 	if err != nil {
 		return nil, fmt.Errorf("could not start RPC api for engine: %w", err)
 	}
@@ -787,22 +862,35 @@ func isWebsocket(r *http.Request) bool {
 
 // ObtainJWTSecret loads the jwt-secret, either from the provided config,
 // or from the default location. If neither of those are present, it generates
-// a new secret and stores to the default location.
+// a new secret and stores to the default location. If there are several
+// secrets (see ObtainJWTSecrets), the first one is returned.
 func ObtainJWTSecret(cfg *httpcfg.HttpCfg, logger log.Logger) ([]byte, error) {
+	jwtSecrets, err := ObtainJWTSecrets(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return jwtSecrets[0], nil
+}
+
+// ObtainJWTSecrets loads the jwt-secrets accepted by the Engine API: the ones
+// of the file (one per line) followed by the ones of the environment variable
+// (comma-separated), if configured. If there are none, it generates a new
+// secret and stores it to the file.
+func ObtainJWTSecrets(cfg *httpcfg.HttpCfg, logger log.Logger) ([][]byte, error) {
 	// try reading from file
 	logger.Info("Reading JWT secret", "path", cfg.JWTSecretPath)
 	// If we run the rpcdaemon and datadir is not specified we just use jwt.hex in current directory.
 	if len(cfg.JWTSecretPath) == 0 {
 		cfg.JWTSecretPath = "jwt.hex"
 	}
-	if data, err := os.ReadFile(cfg.JWTSecretPath); err == nil {
-		jwtSecret := common.FromHex(strings.TrimSpace(string(data)))
-		if len(jwtSecret) == 32 {
-			return jwtSecret, nil
-		}
-		logger.Error("Invalid JWT secret", "path", cfg.JWTSecretPath, "length", len(jwtSecret))
+	jwtSecrets, err := readJWTSecrets(cfg)
+	if err != nil {
+		logger.Error("Invalid JWT secret", "path", cfg.JWTSecretPath, "env", cfg.JWTSecretEnv, "err", err)
 		return nil, errors.New("invalid JWT secret")
 	}
+	if len(jwtSecrets) > 0 {
+		return jwtSecrets, nil
+	}
 	// Need to generate one
 	jwtSecret := make([]byte, 32)
 	rand.Read(jwtSecret)
@@ -811,10 +899,73 @@ func ObtainJWTSecret(cfg *httpcfg.HttpCfg, logger log.Logger) ([]byte, error) {
 		return nil, err
 	}
 	logger.Info("Generated JWT secret", "path", cfg.JWTSecretPath)
-	return jwtSecret, nil
+	return [][]byte{jwtSecret}, nil
+}
+
+func readJWTSecrets(cfg *httpcfg.HttpCfg) ([][]byte, error) {
+	var encoded []string
+	data, err := os.ReadFile(cfg.JWTSecretPath)
+	switch {
+	case err == nil:
+		encoded = append(encoded, strings.Split(string(data), "\n")...)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	if cfg.JWTSecretEnv != "" {
+		encoded = append(encoded, strings.Split(os.Getenv(cfg.JWTSecretEnv), ",")...)
+	}
+
+	var jwtSecrets [][]byte
+	for _, s := range encoded {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		jwtSecret := common.FromHex(s)
+		if len(jwtSecret) != 32 {
+			return nil, fmt.Errorf("secret length %d, expected 32", len(jwtSecret))
+		}
+		jwtSecrets = append(jwtSecrets, jwtSecret)
+	}
+	return jwtSecrets, nil
+}
+
+// reloadJWTSecrets re-reads the jwt-secrets every cfg.JWTSecretReload, so the
+// secrets of consensus clients can be rotated without restart. Invalid or
+// empty ones are ignored: the endpoint keeps accepting the previous secrets.
+func reloadJWTSecrets(ctx context.Context, cfg *httpcfg.HttpCfg, jwtSecrets *rpc.JwtSecrets, logger log.Logger) {
+	ticker := time.NewTicker(cfg.JWTSecretReload)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		secrets, err := readJWTSecrets(cfg)
+		if err != nil || len(secrets) == 0 {
+			logger.Warn("Invalid JWT secrets, keeping previous ones", "path", cfg.JWTSecretPath, "env", cfg.JWTSecretEnv, "err", err)
+			continue
+		}
+		if !equalJWTSecrets(secrets, jwtSecrets.Get()) {
+			jwtSecrets.Set(secrets)
+			logger.Info("Reloaded JWT secrets", "path", cfg.JWTSecretPath, "env", cfg.JWTSecretEnv, "count", len(secrets))
+		}
+	}
+}
+
+func equalJWTSecrets(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
-func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, graphQLHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
+func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, graphQLHandler http.Handler, jwtSecrets *rpc.JwtSecrets) (http.Handler, error) {
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if cfg.GraphQLEnabled && graphql.ProcessGraphQLcheckIfNeeded(graphQLHandler, w, r) {
 			return
@@ -824,12 +975,19 @@ func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Han
 		if health.ProcessHealthcheckIfNeeded(w, r, apiList) {
 			return
 		}
+		if health.ProcessProbesIfNeeded(w, r, apiList, health.ReadinessCfg{
+			MaxStageLag: cfg.HealthReadyMaxLag,
+			MinPeers:    cfg.HealthReadyMinPeers,
+			Chaindata:   cfg.Dirs.Chaindata,
+		}) {
+			return
+		}
 		if cfg.WebsocketEnabled && wsHandler != nil && isWebsocket(r) {
 			wsHandler.ServeHTTP(w, r)
 			return
 		}
 
-		if jwtSecret != nil && !rpc.CheckJwtSecret(w, r, jwtSecret) {
+		if jwtSecrets != nil && !rpc.CheckJwtSecret(w, r, jwtSecrets) {
 			return
 		}
 
@@ -839,27 +997,32 @@ func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Han
 	return handler, nil
 }
 
-func createEngineListener(cfg *httpcfg.HttpCfg, engineApi []rpc.API, logger log.Logger) (*http.Server, *rpc.Server, string, error) {
+func createEngineListener(ctx context.Context, cfg *httpcfg.HttpCfg, engineApi []rpc.API, logger log.Logger) (*http.Server, *rpc.Server, string, error) {
 	engineHttpEndpoint := fmt.Sprintf("tcp://%s:%d", cfg.AuthRpcHTTPListenAddress, cfg.AuthRpcPort)
 
 	engineSrv := rpc.NewServer(cfg.RpcBatchConcurrency, cfg.TraceRequests, cfg.DebugSingleRequest, true, logger, cfg.RPCSlowLogThreshold)
+	registerReload(engineSrv, false)
 
 	if err := node.RegisterApisFromWhitelist(engineApi, nil, engineSrv, true, logger); err != nil {
 		return nil, nil, "", fmt.Errorf("could not start register RPC engine api: %w", err)
 	}
 
-	jwtSecret, err := ObtainJWTSecret(cfg, logger)
+	secrets, err := ObtainJWTSecrets(cfg, logger)
 	if err != nil {
 		return nil, nil, "", err
 	}
+	jwtSecrets := rpc.NewJwtSecrets(secrets)
+	if cfg.JWTSecretReload > 0 {
+		go reloadJWTSecrets(ctx, cfg, jwtSecrets, logger)
+	}
 
-	wsHandler := engineSrv.WebsocketHandler([]string{"*"}, jwtSecret, cfg.WebsocketCompression, logger)
+	wsHandler := engineSrv.WebsocketHandler([]string{"*"}, jwtSecrets, cfg.WebsocketCompression, logger)
 
 	engineHttpHandler := node.NewHTTPHandlerStack(engineSrv, nil /* authCors */, cfg.AuthRpcVirtualHost, cfg.HttpCompression)
 
 	graphQLHandler := graphql.CreateHandler(engineApi)
 
-	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, graphQLHandler, jwtSecret)
+	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, graphQLHandler, jwtSecrets)
 	if err != nil {
 		return nil, nil, "", err
 	}
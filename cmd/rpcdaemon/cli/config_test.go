@@ -2,9 +2,15 @@ package cli
 
 import (
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 )
 
 func TestParseSocketUrl(t *testing.T) {
@@ -19,3 +25,27 @@ func TestParseSocketUrl(t *testing.T) {
 		require.EqualValues(t, "localhost:1234", socketUrl.Host+socketUrl.EscapedPath())
 	})
 }
+
+func TestObtainJWTSecrets(t *testing.T) {
+	logger := log.New()
+	cfg := &httpcfg.HttpCfg{JWTSecretPath: filepath.Join(t.TempDir(), "jwt.hex"), JWTSecretEnv: "TEST_JWT_SECRETS"}
+
+	// generated if there are none
+	secrets, err := ObtainJWTSecrets(cfg, logger)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+
+	oldSecret, newSecret := strings.Repeat("11", 32), strings.Repeat("22", 32)
+	require.NoError(t, os.WriteFile(cfg.JWTSecretPath, []byte("0x"+oldSecret+"\n\n"+newSecret+"\n"), 0600))
+	t.Setenv(cfg.JWTSecretEnv, strings.Repeat("33", 32))
+	secrets, err = ObtainJWTSecrets(cfg, logger)
+	require.NoError(t, err)
+	require.Len(t, secrets, 3)
+	secret, err := ObtainJWTSecret(cfg, logger)
+	require.NoError(t, err)
+	require.Equal(t, secrets[0], secret)
+
+	require.NoError(t, os.WriteFile(cfg.JWTSecretPath, []byte(oldSecret+"\n0x1234\n"), 0600))
+	_, err = ObtainJWTSecrets(cfg, logger)
+	require.Error(t, err)
+}
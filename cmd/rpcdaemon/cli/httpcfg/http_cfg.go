@@ -20,6 +20,7 @@ type HttpCfg struct {
 	TLSCertfile              string
 	TLSCACert                string
 	TLSKeyFile               string
+	TLSAllowedCNs            []string // Common Names expected from private api and txpool servers
 
 	HttpServerEnabled  bool
 	HttpURL            string
@@ -51,7 +52,16 @@ type HttpCfg struct {
 	RpcBatchConcurrency               uint
 	RpcStreamingDisable               bool
 	DBReadConcurrency                 int
-	TraceCompatibility                bool // Bug for bug compatibility for trace_ routines with OpenEthereum
+	TraceCompatibility                bool          // Bug for bug compatibility for trace_ routines with OpenEthereum
+	TraceChainConcurrency             int           // Maximum number of blocks re-executed at the same time by debug_traceChain
+	ReexecWorkers                     int           // Maximum number of requests re-executing transactions at the same time
+	ReexecQueueSize                   int           // Maximum number of requests waiting to re-execute transactions
+	ReceiptsRegen                     bool          // Re-generate pruned receipts by re-executing blocks with state history
+	ReceiptsRegenMaxTxs               int           // Maximum transactions of a block re-executed to re-generate pruned receipts
+	ReceiptsRegenTimeout              time.Duration // Maximum time of re-generation of pruned receipts per request
+	TracerCPUTime                     time.Duration // Maximum time spent running the code of a javascript tracer per transaction
+	TracerMemory                      uint64        // Maximum bytes allocated by the code of a javascript tracer per transaction
+	TracerOutputSize                  uint64        // Maximum size of the result of a javascript tracer per transaction
 	TxPoolApiAddr                     string
 	StateCache                        kvcache.CoherentConfig
 	Snap                              ethconfig.BlocksFreezing
@@ -67,9 +77,11 @@ type HttpCfg struct {
 	SocketServerEnabled bool
 	SocketListenUrl     string
 
-	JWTSecretPath             string // Engine API Authentication
-	TraceRequests             bool   // Print requests to logs at INFO level
-	DebugSingleRequest        bool   // Print single-request-related debugging info to logs at INFO level
+	JWTSecretPath             string        // Engine API Authentication
+	JWTSecretEnv              string        // Name of environment variable with more comma-separated Engine API secrets
+	JWTSecretReload           time.Duration // How often secrets are re-read, 0 - never
+	TraceRequests             bool          // Print requests to logs at INFO level
+	DebugSingleRequest        bool          // Print single-request-related debugging info to logs at INFO level
 	HTTPTimeouts              rpccfg.HTTPTimeouts
 	AuthRpcTimeouts           rpccfg.HTTPTimeouts
 	EvmCallTimeout            time.Duration
@@ -87,4 +99,7 @@ type HttpCfg struct {
 	OtsMaxPageSize uint64
 
 	RPCSlowLogThreshold time.Duration
+
+	HealthReadyMaxLag   uint64 // /readyz fails if executed head is more blocks behind CL head
+	HealthReadyMinPeers uint   // /readyz fails with less peers, 0 - disabled
 }
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+	live          = "live"
+	stageLag      = "stage_lag"
+	dbWritable    = "db_writable"
+)
+
+var (
+	errStageLag = errors.New("stages lag behind head")
+)
+
+// ReadinessCfg - thresholds of /readyz probe
+type ReadinessCfg struct {
+	MaxStageLag uint64 // blocks of executed head behind CL head (Headers stage progress)
+	MinPeers    uint   // 0 - check disabled
+	Chaindata   string // "" - db writability check disabled (rpcdaemon without datadir)
+}
+
+// ProcessProbesIfNeeded - Kubernetes-style probes, /healthz (liveness) only reports that process is serving requests,
+// /readyz (readiness) fails with 503 while node is behind CL head, has not enough peers or can't write to db.
+func ProcessProbesIfNeeded(
+	w http.ResponseWriter,
+	r *http.Request,
+	rpcAPI []rpc.API,
+	cfg ReadinessCfg,
+) bool {
+	switch {
+	case strings.EqualFold(r.URL.Path, livenessPath):
+		if err := writeResponse(w, map[string]string{live: errorStringOrOK(nil)}, http.StatusOK); err != nil {
+			log.Root().Warn("unable to process liveness probe", "err", err)
+		}
+		return true
+	case strings.EqualFold(r.URL.Path, readinessPath):
+		netAPI, ethAPI := parseAPI(rpcAPI)
+		processReadiness(w, r, netAPI, ethAPI, cfg)
+		return true
+	default:
+		return false
+	}
+}
+
+func processReadiness(w http.ResponseWriter, r *http.Request, netAPI NetAPI, ethAPI EthAPI, cfg ReadinessCfg) {
+	var (
+		errStage = checkStageLag(r.Context(), cfg.MaxStageLag, ethAPI)
+		errPeers = errCheckDisabled
+		errDB    = errCheckDisabled
+	)
+	if cfg.MinPeers > 0 {
+		errPeers = checkMinPeers(cfg.MinPeers, netAPI)
+	}
+	if cfg.Chaindata != "" {
+		errDB = checkDBWritable(cfg.Chaindata)
+	}
+
+	statusCode := http.StatusOK
+	errs := make(map[string]string)
+	for name, err := range map[string]error{stageLag: errStage, minPeerCount: errPeers, dbWritable: errDB} {
+		if shouldChangeStatusCode(err) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		errs[name] = errorStringOrOK(err)
+	}
+
+	if err := writeResponse(w, errs, statusCode); err != nil {
+		log.Root().Warn("unable to process readiness probe", "err", err)
+	}
+}
+
+func checkStageLag(ctx context.Context, maxLag uint64, api EthAPI) error {
+	if api == nil {
+		return fmt.Errorf("no connection to the Erigon server or `eth` namespace isn't enabled")
+	}
+	i, err := api.Syncing(ctx)
+	if err != nil {
+		return err
+	}
+	if i == nil || i == false {
+		return nil
+	}
+
+	progress, ok := i.(map[string]interface{})
+	if !ok {
+		return errNotSynced
+	}
+	current, _ := progress["currentBlock"].(hexutil.Uint64)
+	highest, _ := progress["highestBlock"].(hexutil.Uint64)
+	if highest > current && uint64(highest-current) > maxLag {
+		return fmt.Errorf("%w: %d blocks (maximum %d)", errStageLag, highest-current, maxLag)
+	}
+
+	return nil
+}
+
+// checkDBWritable - detects read-only mounts and full disks by writing a small file next to the db
+func checkDBWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write([]byte{0}); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
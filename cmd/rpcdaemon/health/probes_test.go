@@ -0,0 +1,143 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestProcessProbesIfNeeded(t *testing.T) {
+	chaindata := t.TempDir()
+
+	cases := []struct {
+		path                string
+		cfg                 ReadinessCfg
+		netApiResponse      hexutil.Uint
+		ethApiSyncingResult interface{}
+		expectedStatusCode  int
+		expectedBody        map[string]string
+	}{
+		// 0 - liveness doesn't depend on sync state
+		{
+			path:                livenessPath,
+			ethApiSyncingResult: struct{}{},
+			expectedStatusCode:  http.StatusOK,
+			expectedBody:        map[string]string{live: "HEALTHY"},
+		},
+		// 1 - readiness - synced, peers and db checks disabled
+		{
+			path:                readinessPath,
+			cfg:                 ReadinessCfg{MaxStageLag: 32},
+			ethApiSyncingResult: false,
+			expectedStatusCode:  http.StatusOK,
+			expectedBody: map[string]string{
+				stageLag:     "HEALTHY",
+				minPeerCount: "DISABLED",
+				dbWritable:   "DISABLED",
+			},
+		},
+		// 2 - readiness - lag within threshold
+		{
+			path: readinessPath,
+			cfg:  ReadinessCfg{MaxStageLag: 32, MinPeers: 1, Chaindata: chaindata},
+			ethApiSyncingResult: map[string]interface{}{
+				"currentBlock": hexutil.Uint64(100),
+				"highestBlock": hexutil.Uint64(132),
+			},
+			netApiResponse:     hexutil.Uint(3),
+			expectedStatusCode: http.StatusOK,
+			expectedBody: map[string]string{
+				stageLag:     "HEALTHY",
+				minPeerCount: "HEALTHY",
+				dbWritable:   "HEALTHY",
+			},
+		},
+		// 3 - readiness - lag above threshold
+		{
+			path: readinessPath,
+			cfg:  ReadinessCfg{MaxStageLag: 32},
+			ethApiSyncingResult: map[string]interface{}{
+				"currentBlock": hexutil.Uint64(100),
+				"highestBlock": hexutil.Uint64(133),
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				stageLag: "ERROR: stages lag behind head: 33 blocks (maximum 32)",
+			},
+		},
+		// 4 - readiness - not enough peers
+		{
+			path:                readinessPath,
+			cfg:                 ReadinessCfg{MinPeers: 5},
+			ethApiSyncingResult: false,
+			netApiResponse:      hexutil.Uint(3),
+			expectedStatusCode:  http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				stageLag:     "HEALTHY",
+				minPeerCount: "ERROR: not enough peers: 3 (minimum 5)",
+			},
+		},
+		// 5 - readiness - db dir is not writable
+		{
+			path:                readinessPath,
+			cfg:                 ReadinessCfg{Chaindata: filepath.Join(chaindata, "missing")},
+			ethApiSyncingResult: false,
+			expectedStatusCode:  http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				dbWritable: "ERROR:",
+			},
+		},
+	}
+
+	for idx, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, c.path, nil)
+
+		apis := []rpc.API{
+			{Service: &netApiStub{response: c.netApiResponse}},
+			{Service: &ethApiStub{syncingResult: c.ethApiSyncingResult}},
+		}
+
+		if !ProcessProbesIfNeeded(w, r, apis, c.cfg) {
+			t.Fatalf("%v: expected %s to be processed", idx, c.path)
+		}
+
+		result := w.Result()
+		if result.StatusCode != c.expectedStatusCode {
+			t.Errorf("%v: expected status code: %v, but got: %v", idx, c.expectedStatusCode, result.StatusCode)
+		}
+
+		bodyBytes, err := io.ReadAll(result.Body)
+		if err != nil {
+			t.Errorf("%v: reading response body: %s", idx, err)
+		}
+		result.Body.Close()
+
+		var body map[string]string
+		if err = json.Unmarshal(bodyBytes, &body); err != nil {
+			t.Errorf("%v: unmarshalling the response body: %s", idx, err)
+		}
+
+		for k, v := range c.expectedBody {
+			val, found := body[k]
+			if !found {
+				t.Errorf("%v: expected the key: %s to be in the response body but it wasn't there", idx, k)
+			}
+			if !strings.Contains(val, v) {
+				t.Errorf("%v: expected the response body key: %s to contain: %s, but it contained: %s", idx, k, v, val)
+			}
+		}
+	}
+
+	if ProcessProbesIfNeeded(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil), nil, ReadinessCfg{}) {
+		t.Errorf("expected /health to be left for ProcessHealthcheckIfNeeded")
+	}
+}
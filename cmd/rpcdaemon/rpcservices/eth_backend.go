@@ -102,6 +102,9 @@ func (back *RemoteBackend) FrozenFiles() (list []string)          { return back.
 func (back *RemoteBackend) FreezingCfg() ethconfig.BlocksFreezing {
 	return back.blockReader.FreezingCfg()
 }
+func (back *RemoteBackend) ReceiptsFromSnapshots(ctx context.Context, block *types.Block, senders []common.Address) (types.Receipts, error) {
+	return back.blockReader.ReceiptsFromSnapshots(ctx, block, senders)
+}
 func (back *RemoteBackend) EnsureVersionCompatibility() bool {
 	versionReply, err := back.remoteEthBackend.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
 	if err != nil {
@@ -260,6 +263,10 @@ func (back *RemoteBackend) IterateFrozenBodies(_ func(blockNum uint64, baseTxNum
 	panic("not implemented")
 }
 
+func (back *RemoteBackend) IterateFrozenRawBodies(_, _ uint64, _ func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error) error {
+	panic("not implemented")
+}
+
 func (back *RemoteBackend) BodyWithTransactions(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (body *types.Body, err error) {
 	return back.blockReader.BodyWithTransactions(ctx, tx, hash, blockNum)
 }
@@ -380,6 +387,38 @@ func (back *RemoteBackend) AddPeer(ctx context.Context, request *remote.AddPeerR
 	return result, nil
 }
 
+func (back *RemoteBackend) RemovePeer(ctx context.Context, url string) (bool, error) {
+	result, err := back.remoteEthBackend.RemovePeer(ctx, &remote.RemovePeerRequest{Url: url})
+	if err != nil {
+		return false, fmt.Errorf("ETHBACKENDClient.RemovePeer() error: %w", err)
+	}
+	return result.Success, nil
+}
+
+func (back *RemoteBackend) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	result, err := back.remoteEthBackend.AddTrustedPeer(ctx, &remote.AddTrustedPeerRequest{Url: url})
+	if err != nil {
+		return false, fmt.Errorf("ETHBACKENDClient.AddTrustedPeer() error: %w", err)
+	}
+	return result.Success, nil
+}
+
+func (back *RemoteBackend) RemoveTrustedPeer(ctx context.Context, url string) (bool, error) {
+	result, err := back.remoteEthBackend.RemoveTrustedPeer(ctx, &remote.RemoveTrustedPeerRequest{Url: url})
+	if err != nil {
+		return false, fmt.Errorf("ETHBACKENDClient.RemoveTrustedPeer() error: %w", err)
+	}
+	return result.Success, nil
+}
+
+func (back *RemoteBackend) ReloadPeerFiles(ctx context.Context) (bool, error) {
+	result, err := back.remoteEthBackend.ReloadPeerFiles(ctx, &remote.ReloadPeerFilesRequest{})
+	if err != nil {
+		return false, fmt.Errorf("ETHBACKENDClient.ReloadPeerFiles() error: %w", err)
+	}
+	return result.Success, nil
+}
+
 func (back *RemoteBackend) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
 	rpcPeers, err := back.remoteEthBackend.Peers(ctx, &emptypb.Empty{})
 	if err != nil {
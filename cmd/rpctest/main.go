@@ -435,6 +435,21 @@ func main() {
 	}
 	with(replayCmd, withErigonUrl, withRecord)
 
+	var engineURL, jwtSecretPath string
+	var stopOnMismatch bool
+	var replayEngineCmd = &cobra.Command{
+		Use:   "replayEngine",
+		Short: "Replays engine API calls recorded by erigon --engine.record",
+		Long:  `Sends recorded engine_newPayload and engine_forkchoiceUpdated calls to the node in recorded order and compares statuses of responses with recorded ones. Node must start from the state it had when recording started`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rpctest.ReplayEngine(engineURL, jwtSecretPath, recordFile, stopOnMismatch, logger)
+		},
+	}
+	replayEngineCmd.Flags().StringVar(&engineURL, "engineUrl", "http://localhost:8551", "Erigon engine API url")
+	replayEngineCmd.Flags().StringVar(&jwtSecretPath, "jwtSecret", "jwt.hex", "Path to engine API JWT secret")
+	replayEngineCmd.Flags().BoolVar(&stopOnMismatch, "stopOnMismatch", false, "Stop at first call with different result")
+	with(replayEngineCmd, withRecord)
+
 	var tmpDataDir, tmpDataDirOrig string
 	var notRegenerateGethData bool
 	var compareAccountRange = &cobra.Command{
@@ -487,6 +502,7 @@ func main() {
 		benchEthGetBalanceCmd,
 		benchOtsGetBlockTransactions,
 		replayCmd,
+		replayEngineCmd,
 	)
 	if err := rootCmd.ExecuteContext(rootContext()); err != nil {
 		fmt.Println(err)
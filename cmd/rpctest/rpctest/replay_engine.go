@@ -0,0 +1,110 @@
+package rpctest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/cl/phase1/execution_client/rpc_helper"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/engineapi"
+)
+
+// engineStatus - status of engine_newPayload or engine_forkchoiceUpdated response
+type engineStatus struct {
+	Status        string `json:"status"`
+	PayloadStatus *struct {
+		Status string `json:"status"`
+	} `json:"payloadStatus"`
+}
+
+func parseEngineStatus(response json.RawMessage) string {
+	var status engineStatus
+	if err := json.Unmarshal(response, &status); err != nil {
+		return ""
+	}
+	if status.PayloadStatus != nil {
+		return status.PayloadStatus.Status
+	}
+	return status.Status
+}
+
+// ReplayEngine sends engine API calls recorded by `--engine.record` to the node in recorded order, one by one,
+// and compares statuses of responses with recorded ones
+func ReplayEngine(engineURL, jwtSecretPath, recordFile string, stopOnMismatch bool, logger log.Logger) error {
+	data, err := os.ReadFile(jwtSecretPath)
+	if err != nil {
+		return err
+	}
+	// any of the secrets accepted by the node will do
+	jwtSecret := common.FromHex(strings.TrimSpace(strings.Split(string(data), "\n")[0]))
+	if len(jwtSecret) != 32 {
+		return fmt.Errorf("invalid JWT secret in %s", jwtSecretPath)
+	}
+	client, err := rpc.DialHTTPWithClient(engineURL, &http.Client{Transport: rpc_helper.NewJWTRoundTripper(jwtSecret), Timeout: 10 * time.Minute}, logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	f, err := os.Open(recordFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	var buf [64 * 1024 * 1024]byte // 64 Mb line buffer
+	s.Buffer(buf[:], len(buf))
+
+	var calls, mismatches int
+	for s.Scan() {
+		var call engineapi.RecordedCall
+		if err := json.Unmarshal(s.Bytes(), &call); err != nil {
+			return fmt.Errorf("call %d: %w", calls+1, err)
+		}
+		calls++
+		params := make([]any, len(call.Params))
+		for i := range call.Params {
+			params[i] = call.Params[i]
+		}
+
+		var response json.RawMessage
+		callErr := client.CallContext(context.Background(), &response, call.Method, params...)
+
+		expected, got := call.Error, ""
+		if expected == "" {
+			expected = parseEngineStatus(call.Response)
+		}
+		if callErr != nil {
+			got = callErr.Error()
+		} else {
+			got = parseEngineStatus(response)
+		}
+		if expected == got {
+			logger.Info("Replayed", "call", calls, "method", call.Method, "recorded", call.Time, "result", got)
+			continue
+		}
+		mismatches++
+		logger.Warn("Different result", "call", calls, "method", call.Method, "recorded", call.Time, "expected", expected, "got", got, "params", string(s.Bytes()))
+		if stopOnMismatch {
+			return fmt.Errorf("call %d %s: expected %s, got %s", calls, call.Method, expected, got)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	logger.Info("Replay finished", "calls", calls, "mismatches", mismatches)
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d calls had different results", mismatches, calls)
+	}
+	return nil
+}
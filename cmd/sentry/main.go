@@ -6,6 +6,7 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/spf13/cobra"
 
 	"github.com/ledgerwatch/erigon/cmd/utils"
@@ -26,6 +27,8 @@ var (
 	port         int      // Listening port
 	staticPeers  []string // static peers
 	trustedPeers []string // trusted peers
+	staticFile   string   // static peers, reloaded on change
+	trustedFile  string   // trusted peers, reloaded on change
 	discoveryDNS []string
 	nodiscover   bool // disable sentry's discovery mechanism
 	protocol     uint
@@ -35,6 +38,11 @@ var (
 	maxPendPeers int
 	healthCheck  bool
 	metrics      bool
+
+	tlsCertFile   string
+	tlsKeyFile    string
+	tlsCACert     string
+	tlsAllowedCNs string
 )
 
 func init() {
@@ -46,6 +54,8 @@ func init() {
 	rootCmd.Flags().IntVar(&port, utils.ListenPortFlag.Name, utils.ListenPortFlag.Value, utils.ListenPortFlag.Usage)
 	rootCmd.Flags().StringSliceVar(&staticPeers, utils.StaticPeersFlag.Name, []string{}, utils.StaticPeersFlag.Usage)
 	rootCmd.Flags().StringSliceVar(&trustedPeers, utils.TrustedPeersFlag.Name, []string{}, utils.TrustedPeersFlag.Usage)
+	rootCmd.Flags().StringVar(&staticFile, utils.StaticPeersFileFlag.Name, "", utils.StaticPeersFileFlag.Usage)
+	rootCmd.Flags().StringVar(&trustedFile, utils.TrustedPeersFileFlag.Name, "", utils.TrustedPeersFileFlag.Usage)
 	rootCmd.Flags().StringSliceVar(&discoveryDNS, utils.DNSDiscoveryFlag.Name, []string{}, utils.DNSDiscoveryFlag.Usage)
 	rootCmd.Flags().BoolVar(&nodiscover, utils.NoDiscoverFlag.Name, false, utils.NoDiscoverFlag.Usage)
 	rootCmd.Flags().UintVar(&protocol, utils.P2pProtocolVersionFlag.Name, utils.P2pProtocolVersionFlag.Value.Value()[0], utils.P2pProtocolVersionFlag.Usage)
@@ -55,6 +65,10 @@ func init() {
 	rootCmd.Flags().IntVar(&maxPendPeers, utils.MaxPendingPeersFlag.Name, utils.MaxPendingPeersFlag.Value, utils.MaxPendingPeersFlag.Usage)
 	rootCmd.Flags().BoolVar(&healthCheck, utils.HealthCheckFlag.Name, false, utils.HealthCheckFlag.Usage)
 	rootCmd.Flags().BoolVar(&metrics, utils.MetricsEnabledFlag.Name, false, utils.MetricsEnabledFlag.Usage)
+	rootCmd.Flags().StringVar(&tlsCertFile, "tls.cert", "", "certificate for gRPC server TLS handshake")
+	rootCmd.Flags().StringVar(&tlsKeyFile, "tls.key", "", "key file for gRPC server TLS handshake")
+	rootCmd.Flags().StringVar(&tlsCACert, "tls.cacert", "", "CA certificate: enables mutual TLS - clients must present certificate signed by it")
+	rootCmd.Flags().StringVar(&tlsAllowedCNs, "tls.allowed.cn", "", "comma-separated Common Names of clients allowed to connect (requires --tls.cacert)")
 
 	if err := rootCmd.MarkFlagDirname(utils.DataDirFlag.Name); err != nil {
 		panic(err)
@@ -92,9 +106,15 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		p2pConfig.StaticNodesFile, p2pConfig.TrustedNodesFile = staticFile, trustedFile
+
+		creds, err := grpcutil.ServerTLS(grpcutil.TLSConfig{CACert: tlsCACert, CertFile: tlsCertFile, KeyFile: tlsKeyFile, AllowedCNs: grpcutil.ParseAllowedCNs(tlsAllowedCNs)})
+		if err != nil {
+			return err
+		}
 
 		logger := debug.SetupCobra(cmd, "sentry")
-		return sentry.Sentry(cmd.Context(), dirs, sentryAddr, discoveryDNS, p2pConfig, protocol, healthCheck, logger)
+		return sentry.Sentry(cmd.Context(), dirs, sentryAddr, discoveryDNS, p2pConfig, protocol, creds, healthCheck, logger)
 	},
 }
 
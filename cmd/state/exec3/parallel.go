@@ -0,0 +1,257 @@
+package exec3
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/evmtypes"
+)
+
+var (
+	parallelExecApplied = metrics.GetOrCreateCounter(`exec_parallel_applied`)
+	parallelExecRepeats = metrics.GetOrCreateCounter(`exec_parallel_repeats`)
+
+	// parallelExecMinTxs - blocks with less txs are executed serially: speculation doesn't pay off
+	parallelExecMinTxs = dbg.EnvInt("EXEC_PARALLEL_MIN_TXS", 16)
+)
+
+// ParallelExecutor - optimistic (Block-STM style) execution of block's txs:
+//   - after block initialisation all txs of block are executed by parallel workers on state of block beginning.
+//     Each worker records read set and updates of tx, nothing is applied.
+//   - then, in order of txs (`Apply`): if all values read by tx are still latest - tx's updates are applied,
+//     otherwise tx did conflict with previous txs and must be re-executed serially on latest state.
+//
+// Fees are added to coinbase by IntraBlockState without reading its balance (see BalanceIncreaseSet):
+// so fees don't create conflicts.
+type ParallelExecutor struct {
+	ctx     context.Context
+	chainDb kv.RoDB
+	rs      *state.StateV3
+	workers []*parallelWorker
+
+	blockHash libcommon.Hash
+	results   []*parallelTxResult
+}
+
+type parallelTxResult struct {
+	txTask  *state.TxTask
+	updates *state.StateWriterRecorderV3
+	ok      bool // false - tx must be re-executed serially: speculation failed or not supported
+}
+
+func NewParallelExecutor(ctx context.Context, workerCount int, chainDb kv.RoDB, rs *state.StateV3, chainConfig *chain.Config, engine consensus.Engine) *ParallelExecutor {
+	pe := &ParallelExecutor{ctx: ctx, chainDb: chainDb, rs: rs, workers: make([]*parallelWorker, workerCount)}
+	for i := range pe.workers {
+		pe.workers[i] = newParallelWorker(rs, chainConfig, engine)
+	}
+	return pe
+}
+
+func (pe *ParallelExecutor) ResetState(rs *state.StateV3) {
+	if pe == nil {
+		return
+	}
+	pe.rs = rs
+	pe.blockHash, pe.results = libcommon.Hash{}, pe.results[:0]
+	for _, worker := range pe.workers {
+		worker.resetState(rs)
+	}
+}
+
+// Execute - speculatively executes all txs of block. `first` - task of block's first tx (after block initialisation was applied),
+// tasks of other txs are derived from it. Blocks with few txs are not speculated
+func (pe *ParallelExecutor) Execute(first *state.TxTask, signer *types.Signer) error {
+	pe.blockHash, pe.results = libcommon.Hash{}, pe.results[:0]
+	if len(first.Txs) < parallelExecMinTxs || first.HistoryExecution {
+		return nil
+	}
+
+	for txIndex, txn := range first.Txs {
+		txTask := *first
+		txTask.TxIndex = txIndex
+		txTask.TxNum = first.TxNum + uint64(txIndex)
+		txTask.Tx = txn
+		txTask.BlockReceipts = nil
+		msg, err := txn.AsMessage(*signer, first.Header.BaseFee, first.Rules)
+		if err != nil {
+			return err
+		}
+		txTask.TxAsMessage = msg
+		if sender, ok := txn.GetSender(); ok {
+			txTask.Sender = &sender
+		} else {
+			sender, err := signer.Sender(txn)
+			if err != nil {
+				return err
+			}
+			txTask.Sender = &sender
+		}
+		pe.results = append(pe.results, &parallelTxResult{txTask: &txTask, updates: state.NewStateWriterRecorderV3()})
+	}
+
+	// MDBX doesn't allow RoTx in thread which has RwTx: workers do run in own goroutines.
+	// Shared domains are not modified until all workers are done.
+	var next atomic.Int64
+	g, ctx := errgroup.WithContext(pe.ctx)
+	for _, worker := range pe.workers {
+		worker := worker
+		g.Go(func() error {
+			return pe.chainDb.View(ctx, func(tx kv.Tx) error {
+				worker.stateReader.SetTx(tx)
+				defer worker.stateReader.SetTx(nil)
+				for i := int(next.Add(1)) - 1; i < len(pe.results); i = int(next.Add(1)) - 1 {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					worker.run(pe.results[i])
+				}
+				return nil
+			})
+		})
+	}
+	if err := g.Wait(); err != nil {
+		pe.results = pe.results[:0]
+		return err
+	}
+	pe.blockHash = first.BlockHash
+	return nil
+}
+
+// Apply - applies results of speculative execution of `txTask` to state of `applyWorker`, if values read by tx were not changed
+// by previous txs. Returns false if tx must be executed by `applyWorker` (no results or conflict)
+func (pe *ParallelExecutor) Apply(applyWorker *Worker, txTask *state.TxTask) (bool, error) {
+	if pe == nil || txTask.BlockHash != pe.blockHash || txTask.TxIndex < 0 || txTask.TxIndex >= len(pe.results) {
+		return false, nil
+	}
+	res := pe.results[txTask.TxIndex]
+	pe.results[txTask.TxIndex] = nil
+	if res == nil || !res.ok || res.txTask.TxNum != txTask.TxNum {
+		parallelExecRepeats.Inc()
+		return false, nil
+	}
+	valid, err := pe.rs.Domains().LatestReadsValid(res.txTask.ReadLists)
+	if err != nil {
+		return false, err
+	}
+	if !valid {
+		parallelExecRepeats.Inc()
+		return false, nil
+	}
+
+	applyWorker.stateReader.SetTxNum(txTask.TxNum)
+	applyWorker.stateWriter.SetTxNum(applyWorker.ctx, txTask.TxNum)
+	if err := res.updates.Replay(applyWorker.stateWriter); err != nil {
+		return false, err
+	}
+	txTask.Error = nil
+	txTask.Failed = res.txTask.Failed
+	txTask.UsedGas = res.txTask.UsedGas
+	txTask.Logs = res.txTask.Logs
+	txTask.TraceFroms = res.txTask.TraceFroms
+	txTask.TraceTos = res.txTask.TraceTos
+	txTask.BalanceIncreaseSet = res.txTask.BalanceIncreaseSet
+	txTask.ReadLists = res.txTask.ReadLists
+	parallelExecApplied.Inc()
+	return true, nil
+}
+
+// parallelWorker - executes txs speculatively: like Worker, but reads from own RoTx and only records updates
+type parallelWorker struct {
+	chainConfig *chain.Config
+	engine      consensus.Engine
+	stateReader *state.StateReaderParallelV3
+	ibs         *state.IntraBlockState
+
+	callTracer  *CallTracer
+	taskGasPool *core.GasPool
+	evm         *vm.EVM
+	vmCfg       vm.Config
+
+	blockHashUsed bool
+}
+
+func newParallelWorker(rs *state.StateV3, chainConfig *chain.Config, engine consensus.Engine) *parallelWorker {
+	w := &parallelWorker{
+		chainConfig: chainConfig,
+		engine:      engine,
+		evm:         vm.NewEVM(evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, chainConfig, vm.Config{}),
+		callTracer:  NewCallTracer(),
+		taskGasPool: new(core.GasPool),
+	}
+	w.vmCfg = vm.Config{Debug: true, Tracer: w.callTracer}
+	w.resetState(rs)
+	return w
+}
+
+func (w *parallelWorker) resetState(rs *state.StateV3) {
+	w.stateReader = state.NewStateReaderParallelV3(rs.Domains())
+	w.ibs = state.New(w.stateReader)
+}
+
+func (w *parallelWorker) run(res *parallelTxResult) {
+	txTask := res.txTask
+	msg := txTask.TxAsMessage
+	// service txs of AuRa are detected by system call to contract: not speculated
+	if msg.FeeCap().IsZero() && w.engine != nil {
+		return
+	}
+	defer func() {
+		// state read from different sources may be inconsistent: any failure means only that tx will be re-executed
+		if rec := recover(); rec != nil {
+			res.ok = false
+			txTask.Error = fmt.Errorf("speculative execution panic: %v", rec)
+		}
+	}()
+
+	w.stateReader.ResetReadSet()
+	w.ibs.Reset()
+	w.blockHashUsed = false
+	w.taskGasPool.Reset(txTask.Tx.GetGas(), w.chainConfig.GetMaxBlobGasPerBlock())
+	w.callTracer.Reset()
+	w.vmCfg.SkipAnalysis = txTask.SkipAnalysis
+	txHash := txTask.Tx.Hash()
+	w.ibs.SetTxContext(txHash, txTask.BlockHash, txTask.TxIndex)
+
+	// canonical hashes may be not committed yet: BLOCKHASH is not visible in read set, such txs are re-executed
+	blockContext := txTask.EvmBlockContext
+	blockContext.GetHash = func(n uint64) libcommon.Hash {
+		w.blockHashUsed = true
+		return libcommon.Hash{}
+	}
+	w.evm.ResetBetweenBlocks(blockContext, core.NewEVMTxContext(msg), w.ibs, w.vmCfg, txTask.Rules)
+
+	applyRes, err := core.ApplyMessage(w.evm, msg, w.taskGasPool, true /* refunds */, false /* gasBailout */)
+	if err != nil || w.blockHashUsed {
+		return
+	}
+	txTask.Failed = applyRes.Failed()
+	txTask.UsedGas = applyRes.UsedGas
+	w.ibs.SoftFinalise()
+	txTask.Logs = w.ibs.GetLogs(txHash)
+	txTask.TraceFroms = w.callTracer.Froms()
+	txTask.TraceTos = w.callTracer.Tos()
+	txTask.BalanceIncreaseSet = w.ibs.BalanceIncreaseSet()
+	if err := w.ibs.MakeWriteSet(txTask.Rules, res.updates); err != nil {
+		return
+	}
+	if w.ibs.Error() != nil {
+		w.ibs = state.New(w.stateReader) // Reset doesn't clear error
+		return
+	}
+	txTask.ReadLists = w.stateReader.ReadSet()
+	res.ok = true
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common/paths"
@@ -43,9 +44,10 @@ var (
 	txpoolApiAddr  string
 	datadirCli     string // Path to td working dir
 
-	TLSCertfile string
-	TLSCACert   string
-	TLSKeyFile  string
+	TLSCertfile   string
+	TLSCACert     string
+	TLSKeyFile    string
+	TLSAllowedCNs string
 
 	pendingPoolLimit int
 	baseFeePoolLimit int
@@ -55,11 +57,25 @@ var (
 	accountSlots       uint64
 	blobSlots          uint64
 	totalBlobPoolLimit uint64
+	totalBlobPoolBytes string
+	minTip             uint64
+	maxNonceGap        uint64
 	priceBump          uint64
 	blobPriceBump      uint64
 
 	noTxGossip bool
 
+	noJournal       bool
+	journalLifetime time.Duration
+	rejournalEvery  time.Duration
+
+	scoring       string
+	privateBlocks uint64
+
+	propagationFraction float64
+	propagationPeerRate uint64
+	propagationDeny     []string
+
 	commitEvery time.Duration
 )
 
@@ -75,6 +91,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&TLSCertfile, "tls.cert", "", "certificate for client side TLS handshake")
 	rootCmd.PersistentFlags().StringVar(&TLSKeyFile, "tls.key", "", "key file for client side TLS handshake")
 	rootCmd.PersistentFlags().StringVar(&TLSCACert, "tls.cacert", "", "CA certificate for client side TLS handshake")
+	rootCmd.PersistentFlags().StringVar(&TLSAllowedCNs, "tls.allowed.cn", "", "comma-separated Common Names of clients allowed to connect to txpool api (requires --tls.cacert)")
 
 	rootCmd.PersistentFlags().IntVar(&pendingPoolLimit, "txpool.globalslots", txpoolcfg.DefaultConfig.PendingSubPoolLimit, "Maximum number of executable transaction slots for all accounts")
 	rootCmd.PersistentFlags().IntVar(&baseFeePoolLimit, "txpool.globalbasefeeslots", txpoolcfg.DefaultConfig.BaseFeeSubPoolLimit, "Maximum number of non-executable transactions where only not enough baseFee")
@@ -83,10 +100,21 @@ func init() {
 	rootCmd.PersistentFlags().Uint64Var(&accountSlots, "txpool.accountslots", txpoolcfg.DefaultConfig.AccountSlots, "Minimum number of executable transaction slots guaranteed per account")
 	rootCmd.PersistentFlags().Uint64Var(&blobSlots, "txpool.blobslots", txpoolcfg.DefaultConfig.BlobSlots, "Max allowed total number of blobs (within type-3 txs) per account")
 	rootCmd.PersistentFlags().Uint64Var(&totalBlobPoolLimit, "txpool.totalblobpoollimit", txpoolcfg.DefaultConfig.TotalBlobPoolLimit, "Total limit of number of all blobs in txs within the txpool")
+	rootCmd.PersistentFlags().StringVar(&totalBlobPoolBytes, utils.TxPoolTotalBlobPoolBytesFlag.Name, utils.TxPoolTotalBlobPoolBytesFlag.Value, utils.TxPoolTotalBlobPoolBytesFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&minTip, utils.TxPoolMinTipFlag.Name, utils.TxPoolMinTipFlag.Value, utils.TxPoolMinTipFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&maxNonceGap, utils.TxPoolMaxNonceGapFlag.Name, utils.TxPoolMaxNonceGapFlag.Value, utils.TxPoolMaxNonceGapFlag.Usage)
 	rootCmd.PersistentFlags().Uint64Var(&priceBump, "txpool.pricebump", txpoolcfg.DefaultConfig.PriceBump, "Price bump percentage to replace an already existing transaction")
 	rootCmd.PersistentFlags().Uint64Var(&blobPriceBump, "txpool.blobpricebump", txpoolcfg.DefaultConfig.BlobPriceBump, "Price bump percentage to replace an existing blob (type-3) transaction")
 	rootCmd.PersistentFlags().DurationVar(&commitEvery, utils.TxPoolCommitEveryFlag.Name, utils.TxPoolCommitEveryFlag.Value, utils.TxPoolCommitEveryFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&noTxGossip, utils.TxPoolGossipDisableFlag.Name, utils.TxPoolGossipDisableFlag.Value, utils.TxPoolGossipDisableFlag.Usage)
+	rootCmd.PersistentFlags().BoolVar(&noJournal, utils.TxPoolNoJournalFlag.Name, false, utils.TxPoolNoJournalFlag.Usage)
+	rootCmd.PersistentFlags().DurationVar(&journalLifetime, utils.TxPoolJournalLifetimeFlag.Name, utils.TxPoolJournalLifetimeFlag.Value, utils.TxPoolJournalLifetimeFlag.Usage)
+	rootCmd.PersistentFlags().DurationVar(&rejournalEvery, utils.TxPoolRejournalFlag.Name, utils.TxPoolRejournalFlag.Value, utils.TxPoolRejournalFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&scoring, utils.TxPoolScoringFlag.Name, utils.TxPoolScoringFlag.Value, utils.TxPoolScoringFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&privateBlocks, utils.TxPoolPrivateBlocksFlag.Name, utils.TxPoolPrivateBlocksFlag.Value, utils.TxPoolPrivateBlocksFlag.Usage)
+	rootCmd.PersistentFlags().Float64Var(&propagationFraction, utils.TxPoolPropagationFractionFlag.Name, utils.TxPoolPropagationFractionFlag.Value, utils.TxPoolPropagationFractionFlag.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&propagationPeerRate, utils.TxPoolPropagationPeerRateFlag.Name, utils.TxPoolPropagationPeerRateFlag.Value, utils.TxPoolPropagationPeerRateFlag.Usage)
+	rootCmd.PersistentFlags().StringSliceVar(&propagationDeny, utils.TxPoolPropagationDenyFlag.Name, []string{}, utils.TxPoolPropagationDenyFlag.Usage)
 	rootCmd.Flags().StringSliceVar(&traceSenders, utils.TxPoolTraceSendersFlag.Name, []string{}, utils.TxPoolTraceSendersFlag.Usage)
 }
 
@@ -108,7 +136,8 @@ var rootCmd = &cobra.Command{
 }
 
 func doTxpool(ctx context.Context, logger log.Logger) error {
-	creds, err := grpcutil.TLS(TLSCACert, TLSCertfile, TLSKeyFile)
+	tlsCfg := grpcutil.TLSConfig{CACert: TLSCACert, CertFile: TLSCertfile, KeyFile: TLSKeyFile}
+	creds, err := grpcutil.ClientTLS(tlsCfg)
 	if err != nil {
 		return fmt.Errorf("could not connect to remoteKv: %w", err)
 	}
@@ -127,10 +156,6 @@ func doTxpool(ctx context.Context, logger log.Logger) error {
 
 	sentryClients := make([]direct.SentryClient, len(sentryAddr))
 	for i := range sentryAddr {
-		creds, err := grpcutil.TLS(TLSCACert, TLSCertfile, TLSKeyFile)
-		if err != nil {
-			return fmt.Errorf("could not connect to sentry: %w", err)
-		}
 		sentryConn, err := grpcutil.Connect(creds, sentryAddr[i])
 		if err != nil {
 			return fmt.Errorf("could not connect to sentry: %w", err)
@@ -152,9 +177,24 @@ func doTxpool(ctx context.Context, logger log.Logger) error {
 	cfg.AccountSlots = accountSlots
 	cfg.BlobSlots = blobSlots
 	cfg.TotalBlobPoolLimit = totalBlobPoolLimit
+	if err := cfg.TotalBlobPoolBytes.UnmarshalText([]byte(totalBlobPoolBytes)); err != nil {
+		return fmt.Errorf("invalid --%s: %w", utils.TxPoolTotalBlobPoolBytesFlag.Name, err)
+	}
+	cfg.MinTipCap = minTip
+	cfg.MaxNonceGap = maxNonceGap
 	cfg.PriceBump = priceBump
 	cfg.BlobPriceBump = blobPriceBump
 	cfg.NoGossip = noTxGossip
+	cfg.NoJournal = noJournal
+	cfg.JournalLifetime = journalLifetime
+	cfg.RejournalEvery = rejournalEvery
+	cfg.Scoring = scoring
+	cfg.PrivateTxBlocks = privateBlocks
+	cfg.Propagation = txpoolcfg.Propagation{
+		BroadcastFraction: propagationFraction,
+		PeerTxsPerSecond:  propagationPeerRate,
+		DenyPeers:         propagationDeny,
+	}
 
 	cacheConfig := kvcache.DefaultCoherentConfig
 	cacheConfig.MetricsLabel = "txpool"
@@ -178,7 +218,16 @@ func doTxpool(ctx context.Context, logger log.Logger) error {
 
 	miningGrpcServer := privateapi.NewMiningServer(ctx, &rpcdaemontest.IsMiningMock{}, nil, logger)
 
-	grpcServer, err := txpool.StartGrpc(txpoolGrpcServer, miningGrpcServer, txpoolApiAddr, nil, logger)
+	tlsCfg.AllowedCNs = grpcutil.ParseAllowedCNs(TLSAllowedCNs)
+	serverCreds, err := grpcutil.ServerTLS(tlsCfg)
+	if err != nil {
+		return err
+	}
+	var serverCredsPtr *credentials.TransportCredentials
+	if serverCreds != nil {
+		serverCredsPtr = &serverCreds
+	}
+	grpcServer, err := txpool.StartGrpc(txpoolGrpcServer, miningGrpcServer, txpoolApiAddr, serverCredsPtr, logger)
 	if err != nil {
 		return err
 	}
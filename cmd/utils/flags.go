@@ -21,6 +21,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"net/url"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -41,7 +42,9 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/metrics"
 	libkzg "github.com/ledgerwatch/erigon-lib/crypto/kzg"
 	"github.com/ledgerwatch/erigon-lib/direct"
+	"github.com/ledgerwatch/erigon-lib/downloader"
 	downloadercfg2 "github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/tiering"
 	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 
 	"github.com/ledgerwatch/erigon/cl/clparams"
@@ -136,10 +139,31 @@ var (
 		Name:  "ethash.dagslockmmap",
 		Usage: "Lock memory maps for recent ethash mining DAGs",
 	}
+	EthashLightFlag = cli.BoolFlag{
+		Name:  "ethash.light",
+		Usage: "Verify PoW work submitted by remote sealers (eth_submitWork) with ethash caches instead of multi-GB DAGs. Blocks are verified with caches regardless of this flag",
+	}
 	ExternalConsensusFlag = cli.BoolFlag{
 		Name:  "externalcl",
 		Usage: "Enables the external consensus layer",
 	}
+	EngineAsyncValidationFlag = cli.BoolFlag{
+		Name:  "engine.asyncvalidation",
+		Usage: "engine_newPayload answers SYNCING if payload isn't validated in --engine.asyncvalidation.wait and validates it in background: result is returned by next engine_newPayload of the block, engine_forkchoiceUpdated waits for it",
+	}
+	EngineAsyncValidationWaitFlag = cli.DurationFlag{
+		Name:  "engine.asyncvalidation.wait",
+		Usage: "How long engine_newPayload waits for validation with --engine.asyncvalidation",
+		Value: 500 * time.Millisecond,
+	}
+	EngineWitnessFlag = cli.BoolFlag{
+		Name:  "engine.witness",
+		Usage: "Generate execution witnesses (accessed state proven against parent state root, bytecodes, ancestor headers) of payloads which become head and serve them by debug_executionWitness on engine API endpoint, for stateless verifiers and provers",
+	}
+	EngineRecordFlag = cli.StringFlag{
+		Name:  "engine.record",
+		Usage: "Append engine_newPayload and engine_forkchoiceUpdated calls with responses to the file, one JSON per line, to reproduce issues without the original CL: see `rpctest replayEngine`",
+	}
 	// Transaction pool settings
 	TxPoolDisableFlag = cli.BoolFlag{
 		Name:  "txpool.disable",
@@ -164,6 +188,16 @@ var (
 		Usage: "Minimum gas price (fee cap) limit to enforce for acceptance into the pool",
 		Value: ethconfig.Defaults.DeprecatedTxPool.PriceLimit,
 	}
+	TxPoolMinTipFlag = cli.Uint64Flag{
+		Name:  "txpool.mintip",
+		Usage: "Minimum tip (priority fee per gas) to enforce for acceptance of non-local transactions into the pool",
+		Value: txpoolcfg.DefaultConfig.MinTipCap,
+	}
+	TxPoolMaxNonceGapFlag = cli.Uint64Flag{
+		Name:  "txpool.maxnoncegap",
+		Usage: "Maximum distance between nonce of non-local transaction and current nonce of its sender. 0 - no limit",
+		Value: txpoolcfg.DefaultConfig.MaxNonceGap,
+	}
 	TxPoolPriceBumpFlag = cli.Uint64Flag{
 		Name:  "txpool.pricebump",
 		Usage: "Price bump percentage to replace an already existing transaction",
@@ -189,6 +223,11 @@ var (
 		Usage: "Total limit of number of all blobs in txs within the txpool",
 		Value: txpoolcfg.DefaultConfig.TotalBlobPoolLimit,
 	}
+	TxPoolTotalBlobPoolBytesFlag = cli.StringFlag{
+		Name:  "txpool.totalblobpoolbytes",
+		Usage: "Total limit of size of all blob txs (with blobs) within the txpool. When reached - new blob txs evict blob txs with lower blob fee cap. 0 - no limit",
+		Value: txpoolcfg.DefaultConfig.TotalBlobPoolBytes.String(),
+	}
 	TxPoolGlobalSlotsFlag = cli.Uint64Flag{
 		Name:  "txpool.globalslots",
 		Usage: "Maximum number of executable transaction slots for all accounts",
@@ -214,6 +253,55 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.DeprecatedTxPool.Lifetime,
 	}
+	TxPoolNoJournalFlag = cli.BoolFlag{
+		Name:  "txpool.nojournal",
+		Usage: "Disables journal of local transactions. Journaled transactions are replayed to the pool after restart and re-announced until they are mined or expired",
+	}
+	TxPoolJournalLifetimeFlag = cli.DurationFlag{
+		Name:  "txpool.journal.lifetime",
+		Usage: "Maximum amount of time local transaction is kept in journal",
+		Value: txpoolcfg.DefaultConfig.JournalLifetime,
+	}
+	TxPoolRejournalFlag = cli.DurationFlag{
+		Name:  "txpool.rejournal",
+		Usage: "How often journaled local transactions are re-added to the pool and re-announced to peers",
+		Value: txpoolcfg.DefaultConfig.RejournalEvery,
+	}
+	TxPoolScoringFlag = cli.StringFlag{
+		Name:  "txpool.scoring",
+		Usage: "Order of transactions in mined blocks: empty - by effective tip, 'fifo' - by arrival to the pool, name of scorer linked at build time, or 'grpc://<host>:<port>' - external scorer",
+		Value: txpoolcfg.DefaultConfig.Scoring,
+	}
+	TxPoolPrivateBlocksFlag = cli.Uint64Flag{
+		Name:  "txpool.private.blocks",
+		Usage: "Number of blocks after which private transaction (eth_sendPrivateRawTransaction) is dropped if not mined, unless sender set its max block number",
+		Value: txpoolcfg.DefaultConfig.PrivateTxBlocks,
+	}
+	TxPoolPropagationFractionFlag = cli.Float64Flag{
+		Name:  "txpool.propagation.broadcastfraction",
+		Usage: "Fraction of peers (0..1) receiving full transaction bodies, the rest get hash announcements (default: sqrt of peers count)",
+		Value: txpoolcfg.DefaultConfig.Propagation.BroadcastFraction,
+	}
+	TxPoolPropagationPeerRateFlag = cli.Uint64Flag{
+		Name:  "txpool.propagation.peerrate",
+		Usage: "Maximum number of transactions per second propagated to a single peer (0 - unlimited)",
+		Value: txpoolcfg.DefaultConfig.Propagation.PeerTxsPerSecond,
+	}
+	TxPoolPropagationDenyFlag = cli.StringFlag{
+		Name:  "txpool.propagation.deny",
+		Usage: "Comma separated list of peers (enode URLs or hex public keys) which never receive propagated transactions",
+		Value: "",
+	}
+	TxPoolUserOpsEntryPointsFlag = cli.StringFlag{
+		Name:  "txpool.aa.entrypoints",
+		Usage: "Comma separated list of ERC-4337 EntryPoint (v0.6) addresses, enables pool of user operations and its gRPC service for bundlers",
+		Value: "",
+	}
+	TxPoolUserOpsMaxFlag = cli.IntFlag{
+		Name:  "txpool.aa.maxops",
+		Usage: "Maximum number of user operations in pool",
+		Value: ethconfig.Defaults.UserOps.MaxOps,
+	}
 	TxPoolTraceSendersFlag = cli.StringFlag{
 		Name:  "txpool.trace.senders",
 		Usage: "Comma separated list of addresses, whose transactions will traced in transaction pool with debug printing",
@@ -270,6 +358,36 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerTxOrderingFlag = cli.StringFlag{
+		Name:  "miner.txordering",
+		Usage: "Order of txpool transactions in produced blocks: 'pool' (as yielded by txpool) or 'tip' (by effective priority fee)",
+		Value: params.MiningTxOrderingPool,
+	}
+	MinerMaxBlobsFlag = cli.Uint64Flag{
+		Name:  "miner.maxblobs",
+		Usage: "Limit of blobs in produced blocks (0 - chain's limit)",
+	}
+	BuilderURLsFlag = cli.StringFlag{
+		Name:  "builder.urls",
+		Usage: "Comma separated JSON-RPC URLs of external builders: engine_getPayload returns their payload if it's more valuable than locally built one",
+	}
+	BuilderMinBidFlag = flags.BigFlag{
+		Name:  "builder.minbid",
+		Usage: "Minimal value (wei) of external builder payload to be taken instead of locally built one",
+	}
+	BuilderDenyListFlag = cli.StringFlag{
+		Name:  "builder.denylist",
+		Usage: "Comma separated addresses: external builder payloads with transactions from or to them are rejected",
+	}
+	BuilderTimeoutFlag = cli.DurationFlag{
+		Name:  "builder.timeout",
+		Usage: "How long engine_getPayload waits for external builders",
+		Value: ethconfig.Defaults.Miner.BuilderTimeout,
+	}
+	MinerLocalTxBoostFlag = cli.Uint64Flag{
+		Name:  "miner.localboost",
+		Usage: "Percent added to effective priority fee of local transactions when ordering and packing them into produced blocks",
+	}
 	VMEnableDebugFlag = cli.BoolFlag{
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
@@ -345,9 +463,19 @@ var (
 
 	JWTSecretPath = cli.StringFlag{
 		Name:  "authrpc.jwtsecret",
-		Usage: "Path to the token that ensures safe connection between CL and EL",
+		Usage: "Path to the token that ensures safe connection between CL and EL. File may contain several tokens, one per line, any of them is accepted",
+		Value: "",
+	}
+	JWTSecretEnv = cli.StringFlag{
+		Name:  "authrpc.jwtsecret.env",
+		Usage: "Name of environment variable with more comma-separated tokens accepted by Engine API, in addition to --authrpc.jwtsecret ones",
 		Value: "",
 	}
+	JWTSecretReload = cli.DurationFlag{
+		Name:  "authrpc.jwtsecret.reload",
+		Usage: "How often tokens of --authrpc.jwtsecret and --authrpc.jwtsecret.env are re-read, so CL can rotate them without restart of EL: add new token, switch CL to it, remove old token (0 - never)",
+		Value: 10 * time.Second,
+	}
 
 	HttpCompressionFlag = cli.BoolFlag{
 		Name:  "http.compression",
@@ -423,6 +551,60 @@ var (
 		Name:  "trace.compat",
 		Usage: "Bug for bug compatibility with OE for trace_ routines",
 	}
+	RpcTraceChainConcurrencyFlag = cli.IntFlag{
+		Name:  "rpc.tracechain.concurrency",
+		Usage: "Maximum number of blocks re-executed at the same time by all debug_traceChain subscriptions",
+		Value: 2,
+	}
+	RpcReexecWorkersFlag = cli.IntFlag{
+		Name:  "rpc.reexec.workers",
+		Usage: "Maximum number of RPC requests re-executing transactions of historical blocks at the same time (traces, receipts, logs)",
+		Value: cmp.Max(1, runtime.GOMAXPROCS(-1)/2),
+	}
+	RpcReexecQueueFlag = cli.IntFlag{
+		Name:  "rpc.reexec.queue",
+		Usage: "Maximum number of RPC requests waiting to re-execute transactions, further requests are rejected",
+		Value: 1024,
+	}
+	HealthReadyMaxLagFlag = cli.Uint64Flag{
+		Name:  "healthcheck.ready.maxlag",
+		Usage: "Readiness probe (/readyz) fails if executed blocks are more than this number behind CL head",
+		Value: 32,
+	}
+	HealthReadyMinPeersFlag = cli.UintFlag{
+		Name:  "healthcheck.ready.minpeers",
+		Usage: "Readiness probe (/readyz) fails if node has less peers (0 = check disabled)",
+		Value: 0,
+	}
+	RpcReceiptsRegenFlag = cli.BoolFlag{
+		Name:  "rpc.receipts.regenerate",
+		Usage: "Re-generate pruned receipts (--prune=r, --prune.receipts) by re-executing the block if its state history is available, instead of returning null",
+	}
+	RpcReceiptsRegenMaxTxsFlag = cli.IntFlag{
+		Name:  "rpc.receipts.regenerate.maxtxs",
+		Usage: "Maximum number of transactions of a block re-executed per request to re-generate pruned receipts (0 = unlimited)",
+		Value: 5000,
+	}
+	RpcReceiptsRegenTimeoutFlag = cli.DurationFlag{
+		Name:  "rpc.receipts.regenerate.timeout",
+		Usage: "Maximum time of re-generation of pruned receipts per request (0 = unlimited)",
+		Value: 10 * time.Second,
+	}
+	RpcTracerCPUTimeFlag = cli.DurationFlag{
+		Name:  "rpc.tracer.cputime",
+		Usage: "Maximum time spent running the code of a javascript tracer per traced transaction (0 = unlimited)",
+		Value: time.Minute,
+	}
+	RpcTracerMemoryFlag = cli.Uint64Flag{
+		Name:  "rpc.tracer.memory",
+		Usage: "Maximum bytes allocated while running the code of a javascript tracer per traced transaction (0 = unlimited)",
+		Value: 4 * 1024 * 1024 * 1024,
+	}
+	RpcTracerOutputSizeFlag = cli.Uint64Flag{
+		Name:  "rpc.tracer.outputsize",
+		Usage: "Maximum size in bytes of the result of a javascript tracer per traced transaction (0 = unlimited)",
+		Value: 128 * 1024 * 1024,
+	}
 
 	TxpoolApiAddrFlag = cli.StringFlag{
 		Name:  "txpool.api.addr",
@@ -519,6 +701,16 @@ var (
 		Value: "0MB",
 		Usage: "Amount of data to store in StateCache (enabled if no --datadir set). Set 0 to disable StateCache. Defaults to 0MB",
 	}
+	StateCachePinFlag = cli.StringFlag{
+		Name:  "state.cache.pin",
+		Usage: "Comma separated list of addresses which state (account and storage) must never be evicted from StateCache. For example: hot DEX contracts",
+		Value: "",
+	}
+	StateCachePinSizeFlag = cli.StringFlag{
+		Name:  "state.cache.pin.size",
+		Usage: "Amount of pinned state (see --state.cache.pin) to keep in StateCache, on top of --state.cache. Pinned state over it is evicted as usual",
+		Value: "256MB",
+	}
 
 	// Network Settings
 	MaxPeersFlag = cli.IntFlag{
@@ -546,10 +738,35 @@ var (
 		Usage: "Allowed ports to pick for different eth p2p protocol versions as follows <porta>,<portb>,..,<porti>",
 		Value: cli.NewUintSlice(uint(ListenPortFlag.Value), 30304, 30305, 30306, 30307),
 	}
+	P2pSnapServeFlag = cli.BoolFlag{
+		Name:  "p2p.snap.serve",
+		Usage: "Serve the snap/1 protocol from the latest state, so that peers can snap sync from this node (embedded sentry only)",
+	}
+	P2pWitServeFlag = cli.BoolFlag{
+		Name:  "p2p.wit.serve",
+		Usage: "Serve the execution witnesses of recent blocks over the wit/0 protocol to stateless clients (embedded sentry only)",
+	}
+	P2pWitFetchFlag = cli.BoolFlag{
+		Name:  "p2p.wit.fetch",
+		Usage: "Run the wit/0 protocol to fetch block execution witnesses from peers (embedded sentry only)",
+	}
 	SentryAddrFlag = cli.StringFlag{
 		Name:  "sentry.api.addr",
 		Usage: "Comma separated sentry addresses '<host>:<port>,<host>:<port>'",
 	}
+	SentryPolicyFlag = cli.StringFlag{
+		Name:  "sentry.policy",
+		Usage: "Routing of header, body and tx requests across sentries: random, peers (most peers first), protocol (newest eth protocol first), tag (--sentry.preferred.tag first)",
+		Value: string(direct.SentryPolicyRandom),
+	}
+	SentryTagsFlag = cli.StringFlag{
+		Name:  "sentry.tags",
+		Usage: "Comma separated tags of --sentry.api.addr sentries, in the same order, e.g. their regions 'eu,us'",
+	}
+	SentryPreferredTagFlag = cli.StringFlag{
+		Name:  "sentry.preferred.tag",
+		Usage: "Tag of the sentries to route requests to first, with --sentry.policy=tag",
+	}
 	SentryLogPeerInfoFlag = cli.BoolFlag{
 		Name:  "sentry.log-peer-info",
 		Usage: "Log detailed peer info when a peer connects or disconnects. Enable to integrate with observer.",
@@ -573,6 +790,14 @@ var (
 		Usage: "Comma separated enode URLs which are always allowed to connect, even above the peer limit",
 		Value: "",
 	}
+	StaticPeersFileFlag = cli.StringFlag{
+		Name:  "staticpeers.file",
+		Usage: "JSON file with a list of enode URLs to connect to, in addition to --staticpeers. Reloaded on change, without restart",
+	}
+	TrustedPeersFileFlag = cli.StringFlag{
+		Name:  "trustedpeers.file",
+		Usage: "JSON file with a list of enode URLs which are always allowed to connect, in addition to --trustedpeers. Reloaded on change, without restart",
+	}
 	NodeKeyFileFlag = cli.StringFlag{
 		Name:  "nodekey",
 		Usage: "P2P node key file",
@@ -686,6 +911,39 @@ var (
 		Name:  ethconfig.FlagSnapStop,
 		Usage: "Workaround to stop producing new snapshots, if you meet some snapshots-related critical bug. It will stop move historical data from DB to new immutable snapshots. DB will grow and may slightly slow-down - and removing this flag in future will not fix this effect (db size will not greatly reduce).",
 	}
+	SnapDownloadProfileFlag = cli.StringFlag{
+		Name:  ethconfig.FlagSnapDownloadProfile,
+		Usage: "Which snapshot files to download: archive - all files, full - without historical state (history/idx files), minimal - as full and only block files of recent blocks (older headers/bodies/transactions are not available)",
+		Value: snapcfg.ArchiveProfile.Name,
+	}
+	SnapReceiptsFlag = cli.BoolFlag{
+		Name:  ethconfig.FlagSnapReceipts,
+		Usage: "Freeze receipts of retired blocks into snapshots (and download them if available): eth_getTransactionReceipt/eth_getBlockReceipts of ancient blocks don't re-execute blocks. Requires receipts in db (no --prune.r)",
+	}
+	SnapColdDirFlag = cli.StringFlag{
+		Name:  ethconfig.FlagSnapColdDir,
+		Usage: "Cold storage tier: dir of object storage mounted by rclone/s3fs/gcsfuse. Frozen files (not accessors) are moved there by age and reads frequency (--snap.cold.*) and replaced by symlinks, moved back when read frequently. Pin files to local disk by `erigon snapshots pin`",
+	}
+	SnapColdAgeFlag = cli.DurationFlag{
+		Name:  "snap.cold.age",
+		Usage: "Frozen files younger than it stay on local disk",
+		Value: tiering.DefaultPolicy.MinAge,
+	}
+	SnapColdReadsFlag = cli.Uint64Flag{
+		Name:  "snap.cold.reads",
+		Usage: "Frozen files read at least that many times during --snap.cold.window stay on local disk (files in cold tier are moved back). 0 - by age only",
+		Value: tiering.DefaultPolicy.PromoteReads,
+	}
+	SnapColdWindowFlag = cli.DurationFlag{
+		Name:  "snap.cold.window",
+		Usage: "Window of reads frequency of frozen files",
+		Value: tiering.DefaultPolicy.Window,
+	}
+	DiskIOProfileFlag = cli.DurationFlag{
+		Name:  "diskio.profile",
+		Usage: "Attribute page faults (disk reads) to subsystems (domains, snapshots, mdbx, torrent) by mapped files, every given interval: 30s, 1m. Exposed as disk_io_* metrics and logs. Linux only, 0 - disabled",
+		Value: 0,
+	}
 	TorrentVerbosityFlag = cli.IntFlag{
 		Name:  "torrent.verbosity",
 		Value: 2,
@@ -701,6 +959,11 @@ var (
 		Value: "4mb",
 		Usage: "Bytes per second, example: 32mb",
 	}
+	TorrentBandwidthScheduleFlag = cli.StringFlag{
+		Name:  "torrent.schedule",
+		Value: "",
+		Usage: "Time-of-day limits, overriding --torrent.download.rate/--torrent.upload.rate. Rules separated by ';', first matching wins. Example: 'mon-fri 09:00-18:00 download=6mb upload=1mb; 00:00-07:00 download=inf upload=inf'",
+	}
 	TorrentDownloadSlotsFlag = cli.IntFlag{
 		Name:  "torrent.download.slots",
 		Value: 6,
@@ -755,6 +1018,11 @@ var (
 		Usage: "Runtime limit of chaindata db size. You can change value of this flag at any time.",
 		Value: (12 * datasize.TB).String(),
 	}
+	DbReadTxWatchdogFlag = cli.DurationFlag{
+		Name:  "db.read.tx.watchdog",
+		Usage: "Report read transactions of chaindata living longer than this (logs, metrics, admin_longReadTransactions). Resumable iterators re-open such transactions at safe point, eth_getLogs fails with advice to narrow block range. 0 - disabled",
+		Value: 0,
+	}
 
 	HealthCheckFlag = cli.BoolFlag{
 		Name:  "healthcheck",
@@ -766,10 +1034,29 @@ var (
 		Usage: "Comma-separated URL's, holding metadata about network-support infrastructure (like S3 buckets with snapshots, bootnodes, etc...)",
 		Value: "",
 	}
+	WebSeedS3EndpointFlag = cli.StringFlag{
+		Name:  "webseed.s3.endpoint",
+		Usage: "Endpoint of S3-compatible storage for s3:// webseeds (for example: https://storage.googleapis.com or MinIO url). Empty - AWS S3. Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN env variables",
+		Value: "",
+	}
+	WebSeedS3RegionFlag = cli.StringFlag{
+		Name:  "webseed.s3.region",
+		Usage: "Region of s3:// webseeds buckets. Empty - AWS_REGION env variable or us-east-1",
+		Value: "",
+	}
+	WebSeedS3RequesterPaysFlag = cli.BoolFlag{
+		Name:  "webseed.s3.requester.pays",
+		Usage: "Traffic of s3:// webseeds buckets is paid by this node (requester-pays buckets)",
+	}
+	DownloaderReleaseKeysFlag = cli.StringFlag{
+		Name:  "downloader.release.keys",
+		Usage: "Comma-separated hex-encoded ed25519 public keys of releases. If set: webseeds are used only if they serve preverified.toml signed by one of keys (preverified.toml.sig), hashes from it are trusted in addition to preverified list of binary",
+		Value: "",
+	}
 
 	HeimdallURLFlag = cli.StringFlag{
 		Name:  "bor.heimdall",
-		Usage: "URL of Heimdall service",
+		Usage: "URL of Heimdall service. Several comma-separated URLs may be given: requests fail over to the next one when the current one is unavailable",
 		Value: "http://localhost:1317",
 	}
 
@@ -977,6 +1264,96 @@ var (
 		Usage: "enables archival node in caplin",
 		Value: false,
 	}
+	CaplinStatesSnapshotsFlag = cli.BoolFlag{
+		Name:  "caplin.states-snapshots",
+		Usage: "download snapshots of beacon states (if published for chain) and produce them with --caplin.archive: historical states are served from them without replay from genesis",
+		Value: false,
+	}
+	CaplinBlobsArchiveFlag = cli.BoolFlag{
+		Name:  "caplin.blobs-archive",
+		Usage: "keep blob sidecars after retention window (implies --caplin.backfilling.blob and --caplin.backfilling.blob.no-pruning): download blob sidecars snapshots from webseeds, serve them by engine_getBlobsV1 and eth_getBlobSidecars",
+		Value: false,
+	}
+	CaplinValidatorKeystoreDirFlag = cli.StringFlag{
+		Name:  "caplin.validator.keystores",
+		Usage: "enables validator client embedded into caplin: directory of EIP-2335 keystores (*.json) of validators",
+		Value: "",
+	}
+	CaplinValidatorPasswordFileFlag = cli.StringFlag{
+		Name:  "caplin.validator.password-file",
+		Usage: "file with password of keystores from --caplin.validator.keystores",
+		Value: "",
+	}
+	CaplinValidatorFeeRecipientFlag = cli.StringFlag{
+		Name:  "caplin.validator.fee-recipient",
+		Usage: "address receiving priority fees of blocks proposed by validators of --caplin.validator.keystores",
+		Value: "",
+	}
+	CaplinValidatorDoppelgangerEpochsFlag = cli.Uint64Flag{
+		Name:  "caplin.validator.doppelganger-epochs",
+		Usage: "validators stay silent for that many epochs after start and stop if they are seen live meanwhile (0 disables doppelganger protection)",
+		Value: 2,
+	}
+	CaplinLightClientServerFlag = cli.BoolFlag{
+		Name:  "caplin.light-client-server",
+		Usage: "compute light client updates and serve them over gossip to light clients",
+		Value: false,
+	}
+	CaplinCheckpointSyncUrlFlag = cli.StringFlag{
+		Name:  "caplin.checkpoint-sync.url",
+		Usage: "comma separated urls of finalized beacon state used by checkpoint sync (trusted endpoints of the network by default), the state is downloaded from the first available one and verified against the others",
+		Value: "",
+	}
+	CaplinCheckpointSyncQuorumFlag = cli.Uint64Flag{
+		Name:  "caplin.checkpoint-sync.quorum",
+		Usage: "number of checkpoint sync providers which must agree on the finalized checkpoint (0 is the majority of providers)",
+		Value: 0,
+	}
+	CaplinCheckpointSyncRootFlag = cli.StringFlag{
+		Name:  "caplin.checkpoint-sync.root",
+		Usage: "trusted root of the latest block of checkpoint sync state, checkpoint sync fails if it does not match",
+		Value: "",
+	}
+	CaplinMevRelayUrlFlag = cli.StringFlag{
+		Name:  "caplin.mev-relay-url",
+		Usage: "url of builder API relay (for example mev-boost) used for block production, local execution payload is used if the relay fails or misses too many slots",
+		Value: "",
+	}
+	CaplinGossipScoringFlag = cli.BoolFlag{
+		Name:  "caplin.gossip-scoring",
+		Usage: "score gossip peers and topics, peers spamming invalid messages or failing to deliver lose their mesh slots and get ignored",
+		Value: true,
+	}
+	CaplinGossipThresholdFlag = cli.Float64Flag{
+		Name:  "caplin.gossip-scoring.gossip-threshold",
+		Usage: "score below which peers are not gossiped to",
+		Value: clparams.DefaultGossipScoringConfig.GossipThreshold,
+	}
+	CaplinGossipPublishThresholdFlag = cli.Float64Flag{
+		Name:  "caplin.gossip-scoring.publish-threshold",
+		Usage: "score below which own messages are not published to peers, must not be greater than the gossip threshold",
+		Value: clparams.DefaultGossipScoringConfig.PublishThreshold,
+	}
+	CaplinGossipGraylistThresholdFlag = cli.Float64Flag{
+		Name:  "caplin.gossip-scoring.graylist-threshold",
+		Usage: "score below which all messages of peers are ignored, must not be greater than the publish threshold",
+		Value: clparams.DefaultGossipScoringConfig.GraylistThreshold,
+	}
+	CaplinGossipIPColocationThresholdFlag = cli.IntFlag{
+		Name:  "caplin.gossip-scoring.ip-colocation-threshold",
+		Usage: "number of peers sharing an ip address above which they are penalized",
+		Value: clparams.DefaultGossipScoringConfig.IPColocationFactorThreshold,
+	}
+	CaplinValidatorMonitorIndicesFlag = cli.StringFlag{
+		Name:  "caplin.validator-monitor.indices",
+		Usage: "comma separated indices of validators whose attestations, proposals and sync committee duties are tracked and exposed as metrics",
+		Value: "",
+	}
+	CaplinValidatorMonitorAutoFlag = cli.BoolFlag{
+		Name:  "caplin.validator-monitor.auto",
+		Usage: "track duties of validators of validator clients connected to the beacon API (always on for validators of --caplin.validator.keystores)",
+		Value: false,
+	}
 	BeaconApiAllowCredentialsFlag = cli.BoolFlag{
 		Name:  "beacon.api.cors.allow-credentials",
 		Usage: "set the cors' allow credentials",
@@ -1112,6 +1489,15 @@ func setTrustedPeers(ctx *cli.Context, cfg *p2p.Config) {
 	cfg.TrustedNodes = append(cfg.TrustedNodes, trustedNodes...)
 }
 
+func setPeersFiles(ctx *cli.Context, cfg *p2p.Config) {
+	if ctx.IsSet(StaticPeersFileFlag.Name) {
+		cfg.StaticNodesFile = ctx.String(StaticPeersFileFlag.Name)
+	}
+	if ctx.IsSet(TrustedPeersFileFlag.Name) {
+		cfg.TrustedNodesFile = ctx.String(TrustedPeersFileFlag.Name)
+	}
+}
+
 func ParseNodesFromURLs(urls []string) ([]*enode.Node, error) {
 	nodes := make([]*enode.Node, 0, len(urls))
 	for _, url := range urls {
@@ -1152,6 +1538,8 @@ func NewP2PConfig(
 		enodeDBPath = filepath.Join(dirs.Nodes, "eth67")
 	case direct.ETH68:
 		enodeDBPath = filepath.Join(dirs.Nodes, "eth68")
+	case direct.ETH69:
+		enodeDBPath = filepath.Join(dirs.Nodes, "eth69")
 	default:
 		return nil, fmt.Errorf("unknown protocol: %v", protocol)
 	}
@@ -1209,6 +1597,24 @@ func nodeKey(datadir string) (*ecdsa.PrivateKey, error) {
 	return config.LoadOrGenerateAndSave(keyfile)
 }
 
+func setSentryPolicy(ctx *cli.Context, cfg *p2p.Config) {
+	policy, err := direct.ParseSentryPolicy(ctx.String(SentryPolicyFlag.Name))
+	if err != nil {
+		Fatalf("Option %s: %v", SentryPolicyFlag.Name, err)
+	}
+	cfg.SentryPolicy = string(policy)
+	if ctx.IsSet(SentryTagsFlag.Name) {
+		cfg.SentryTags = libcommon.CliString2Array(ctx.String(SentryTagsFlag.Name))
+		if len(cfg.SentryTags) != len(cfg.SentryAddr) {
+			Fatalf("Option %s: expected %d tags, one per %s, got %d", SentryTagsFlag.Name, len(cfg.SentryAddr), SentryAddrFlag.Name, len(cfg.SentryTags))
+		}
+	}
+	cfg.SentryPreferredTag = ctx.String(SentryPreferredTagFlag.Name)
+	if policy == direct.SentryPolicyTag && (cfg.SentryPreferredTag == "" || len(cfg.SentryTags) == 0) {
+		Fatalf("Option %s=%s requires %s and %s", SentryPolicyFlag.Name, policy, SentryTagsFlag.Name, SentryPreferredTagFlag.Name)
+	}
+}
+
 // setListenAddress creates a TCP listening address string from set command
 // line flags.
 func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
@@ -1221,6 +1627,7 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(SentryAddrFlag.Name) {
 		cfg.SentryAddr = libcommon.CliString2Array(ctx.String(SentryAddrFlag.Name))
 	}
+	setSentryPolicy(ctx, cfg)
 	// TODO cli lib doesn't store defaults for UintSlice properly so we have to get value directly
 	cfg.AllowedPorts = P2pProtocolAllowedPorts.Value.Value()
 	if ctx.IsSet(P2pProtocolAllowedPorts.Name) {
@@ -1309,6 +1716,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config, nodeName, datadir string, l
 	setBootstrapNodesV5(ctx, cfg)
 	setStaticPeers(ctx, cfg)
 	setTrustedPeers(ctx, cfg)
+	setPeersFiles(ctx, cfg)
 
 	if ctx.IsSet(MaxPeersFlag.Name) {
 		cfg.MaxPeers = ctx.Int(MaxPeersFlag.Name)
@@ -1382,6 +1790,7 @@ func setDataDir(ctx *cli.Context, cfg *nodecfg.Config) {
 	if szLimit%256 != 0 || szLimit < 256 {
 		panic(fmt.Errorf("invalid --db.size.limit: %s=%d, see: %s", ctx.String(DbSizeLimitFlag.Name), szLimit, DbSizeLimitFlag.Usage))
 	}
+	cfg.MdbxReadTxWatchdog = ctx.Duration(DbReadTxWatchdogFlag.Name)
 }
 
 func setDataDirCobra(f *pflag.FlagSet, cfg *nodecfg.Config) {
@@ -1446,6 +1855,12 @@ func setTxPool(ctx *cli.Context, fullCfg *ethconfig.Config) {
 	if ctx.IsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.Uint64(TxPoolPriceLimitFlag.Name)
 	}
+	if ctx.IsSet(TxPoolMinTipFlag.Name) {
+		fullCfg.TxPool.MinTipCap = ctx.Uint64(TxPoolMinTipFlag.Name)
+	}
+	if ctx.IsSet(TxPoolMaxNonceGapFlag.Name) {
+		fullCfg.TxPool.MaxNonceGap = ctx.Uint64(TxPoolMaxNonceGapFlag.Name)
+	}
 	if ctx.IsSet(TxPoolPriceBumpFlag.Name) {
 		cfg.PriceBump = ctx.Uint64(TxPoolPriceBumpFlag.Name)
 	}
@@ -1461,6 +1876,11 @@ func setTxPool(ctx *cli.Context, fullCfg *ethconfig.Config) {
 	if ctx.IsSet(TxPoolTotalBlobPoolLimit.Name) {
 		fullCfg.TxPool.TotalBlobPoolLimit = ctx.Uint64(TxPoolTotalBlobPoolLimit.Name)
 	}
+	if ctx.IsSet(TxPoolTotalBlobPoolBytesFlag.Name) {
+		if err := fullCfg.TxPool.TotalBlobPoolBytes.UnmarshalText([]byte(ctx.String(TxPoolTotalBlobPoolBytesFlag.Name))); err != nil {
+			Fatalf("Invalid --%s: %v", TxPoolTotalBlobPoolBytesFlag.Name, err)
+		}
+	}
 	if ctx.IsSet(TxPoolGlobalSlotsFlag.Name) {
 		cfg.GlobalSlots = ctx.Uint64(TxPoolGlobalSlotsFlag.Name)
 	}
@@ -1476,6 +1896,41 @@ func setTxPool(ctx *cli.Context, fullCfg *ethconfig.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolNoJournalFlag.Name) {
+		fullCfg.TxPool.NoJournal = ctx.Bool(TxPoolNoJournalFlag.Name)
+	}
+	if ctx.IsSet(TxPoolJournalLifetimeFlag.Name) {
+		fullCfg.TxPool.JournalLifetime = ctx.Duration(TxPoolJournalLifetimeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolRejournalFlag.Name) {
+		fullCfg.TxPool.RejournalEvery = ctx.Duration(TxPoolRejournalFlag.Name)
+	}
+	if ctx.IsSet(TxPoolScoringFlag.Name) {
+		fullCfg.TxPool.Scoring = ctx.String(TxPoolScoringFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPrivateBlocksFlag.Name) {
+		fullCfg.TxPool.PrivateTxBlocks = ctx.Uint64(TxPoolPrivateBlocksFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPropagationFractionFlag.Name) {
+		fullCfg.TxPool.Propagation.BroadcastFraction = ctx.Float64(TxPoolPropagationFractionFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPropagationPeerRateFlag.Name) {
+		fullCfg.TxPool.Propagation.PeerTxsPerSecond = ctx.Uint64(TxPoolPropagationPeerRateFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPropagationDenyFlag.Name) {
+		fullCfg.TxPool.Propagation.DenyPeers = libcommon.CliString2Array(ctx.String(TxPoolPropagationDenyFlag.Name))
+	}
+	if ctx.IsSet(TxPoolUserOpsEntryPointsFlag.Name) {
+		for _, entryPoint := range libcommon.CliString2Array(ctx.String(TxPoolUserOpsEntryPointsFlag.Name)) {
+			if !libcommon.IsHexAddress(entryPoint) {
+				Fatalf("Invalid entry point in --%s: %s", TxPoolUserOpsEntryPointsFlag.Name, entryPoint)
+			}
+			fullCfg.UserOps.EntryPoints = append(fullCfg.UserOps.EntryPoints, libcommon.HexToAddress(entryPoint))
+		}
+	}
+	if ctx.IsSet(TxPoolUserOpsMaxFlag.Name) {
+		fullCfg.UserOps.MaxOps = ctx.Int(TxPoolUserOpsMaxFlag.Name)
+	}
 	if ctx.IsSet(TxPoolTraceSendersFlag.Name) {
 		// Parse the command separated flag
 		senderHexes := libcommon.CliString2Array(ctx.String(TxPoolTraceSendersFlag.Name))
@@ -1503,6 +1958,9 @@ func setEthash(ctx *cli.Context, datadir string, cfg *ethconfig.Config) {
 	if ctx.IsSet(EthashCachesLockMmapFlag.Name) {
 		cfg.Ethash.CachesLockMmap = ctx.Bool(EthashCachesLockMmapFlag.Name)
 	}
+	if ctx.Bool(EthashLightFlag.Name) {
+		cfg.Ethash.PowMode = ethashcfg.ModeLight
+	}
 	if ctx.IsSet(FakePoWFlag.Name) {
 		cfg.Ethash.PowMode = ethashcfg.ModeFake
 	}
@@ -1607,6 +2065,30 @@ func setMiner(ctx *cli.Context, cfg *params.MiningConfig) {
 	if ctx.IsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.Bool(MinerNoVerfiyFlag.Name)
 	}
+	switch ordering := ctx.String(MinerTxOrderingFlag.Name); ordering {
+	case params.MiningTxOrderingPool, params.MiningTxOrderingTip:
+		cfg.TxOrdering = ordering
+	default:
+		Fatalf("Invalid --%s value %q, expected %q or %q", MinerTxOrderingFlag.Name, ordering, params.MiningTxOrderingPool, params.MiningTxOrderingTip)
+	}
+	cfg.MaxBlobsPerBlock = ctx.Uint64(MinerMaxBlobsFlag.Name)
+	cfg.LocalTxBoost = ctx.Uint64(MinerLocalTxBoostFlag.Name)
+
+	if ctx.IsSet(BuilderURLsFlag.Name) {
+		cfg.Builders = libcommon.CliString2Array(ctx.String(BuilderURLsFlag.Name))
+	}
+	if ctx.IsSet(BuilderMinBidFlag.Name) {
+		cfg.BuilderMinBid = flags.GlobalBig(ctx, BuilderMinBidFlag.Name)
+	}
+	if ctx.IsSet(BuilderDenyListFlag.Name) {
+		for _, addr := range libcommon.CliString2Array(ctx.String(BuilderDenyListFlag.Name)) {
+			if !libcommon.IsHexAddress(addr) {
+				Fatalf("Invalid address %q in --%s", addr, BuilderDenyListFlag.Name)
+			}
+			cfg.BuilderDenyList = append(cfg.BuilderDenyList, libcommon.HexToAddress(addr))
+		}
+	}
+	cfg.BuilderTimeout = ctx.Duration(BuilderTimeoutFlag.Name)
 }
 
 func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1651,11 +2133,67 @@ func setBeaconAPI(ctx *cli.Context, cfg *ethconfig.Config) error {
 
 func setCaplin(ctx *cli.Context, cfg *ethconfig.Config) {
 	// Caplin's block's backfilling is enabled if any of the following flags are set
-	cfg.CaplinConfig.Backfilling = ctx.Bool(CaplinBackfillingFlag.Name) || ctx.Bool(CaplinArchiveFlag.Name) || ctx.Bool(CaplinBlobBackfillingFlag.Name)
+	cfg.CaplinConfig.Backfilling = ctx.Bool(CaplinBackfillingFlag.Name) || ctx.Bool(CaplinArchiveFlag.Name) || ctx.Bool(CaplinBlobBackfillingFlag.Name) || ctx.Bool(CaplinBlobsArchiveFlag.Name)
 	// More granularity here.
-	cfg.CaplinConfig.BlobBackfilling = ctx.Bool(CaplinBlobBackfillingFlag.Name)
-	cfg.CaplinConfig.BlobPruningDisabled = ctx.Bool(CaplinDisableBlobPruningFlag.Name)
+	cfg.CaplinConfig.BlobBackfilling = ctx.Bool(CaplinBlobBackfillingFlag.Name) || ctx.Bool(CaplinBlobsArchiveFlag.Name)
+	cfg.CaplinConfig.BlobPruningDisabled = ctx.Bool(CaplinDisableBlobPruningFlag.Name) || ctx.Bool(CaplinBlobsArchiveFlag.Name)
 	cfg.CaplinConfig.Archive = ctx.Bool(CaplinArchiveFlag.Name)
+	cfg.CaplinConfig.LightClientServer = ctx.Bool(CaplinLightClientServerFlag.Name)
+	if urls := ctx.String(CaplinCheckpointSyncUrlFlag.Name); urls != "" {
+		cfg.CaplinConfig.CheckpointSyncUrls = libcommon.CliString2Array(urls)
+	}
+	cfg.CaplinConfig.CheckpointSyncQuorum = ctx.Uint64(CaplinCheckpointSyncQuorumFlag.Name)
+	if quorum, providers := cfg.CaplinConfig.CheckpointSyncQuorum, len(cfg.CaplinConfig.CheckpointSyncUrls); providers > 0 && quorum > uint64(providers) {
+		Fatalf("Option %s: quorum %d is greater than number of providers %d", CaplinCheckpointSyncQuorumFlag.Name, quorum, providers)
+	}
+	if root := ctx.String(CaplinCheckpointSyncRootFlag.Name); root != "" {
+		if err := cfg.CaplinConfig.CheckpointSyncRoot.UnmarshalText([]byte(root)); err != nil {
+			Fatalf("Option %s: invalid root %s: %v", CaplinCheckpointSyncRootFlag.Name, root, err)
+		}
+	}
+	if relayUrl := ctx.String(CaplinMevRelayUrlFlag.Name); relayUrl != "" {
+		if u, err := url.Parse(relayUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			Fatalf("Option %s: invalid url %s", CaplinMevRelayUrlFlag.Name, relayUrl)
+		}
+		cfg.CaplinConfig.MevRelayUrl = relayUrl
+	}
+	cfg.CaplinConfig.GossipScoring = clparams.GossipScoringConfig{
+		Disabled:                    !ctx.Bool(CaplinGossipScoringFlag.Name),
+		GossipThreshold:             ctx.Float64(CaplinGossipThresholdFlag.Name),
+		PublishThreshold:            ctx.Float64(CaplinGossipPublishThresholdFlag.Name),
+		GraylistThreshold:           ctx.Float64(CaplinGossipGraylistThresholdFlag.Name),
+		IPColocationFactorThreshold: ctx.Int(CaplinGossipIPColocationThresholdFlag.Name),
+	}
+	if err := cfg.CaplinConfig.GossipScoring.Validate(); err != nil && !cfg.CaplinConfig.GossipScoring.Disabled {
+		Fatalf("Option %s: %v", CaplinGossipScoringFlag.Name, err)
+	}
+
+	if indices := ctx.String(CaplinValidatorMonitorIndicesFlag.Name); indices != "" {
+		for _, index := range libcommon.CliString2Array(indices) {
+			vid, err := strconv.ParseUint(index, 10, 64)
+			if err != nil {
+				Fatalf("Option %s: invalid validator index %s", CaplinValidatorMonitorIndicesFlag.Name, index)
+			}
+			cfg.CaplinConfig.ValidatorMonitorIndices = append(cfg.CaplinConfig.ValidatorMonitorIndices, vid)
+		}
+	}
+	// validators of the embedded validator client are tracked through their committee subscriptions
+	cfg.CaplinConfig.ValidatorMonitorAuto = ctx.Bool(CaplinValidatorMonitorAutoFlag.Name) || ctx.String(CaplinValidatorKeystoreDirFlag.Name) != ""
+
+	if keystores := ctx.String(CaplinValidatorKeystoreDirFlag.Name); keystores != "" {
+		if !ctx.IsSet(CaplinValidatorPasswordFileFlag.Name) {
+			Fatalf("Option %s is required by %s", CaplinValidatorPasswordFileFlag.Name, CaplinValidatorKeystoreDirFlag.Name)
+		}
+		cfg.CaplinConfig.ValidatorKeystoreDir = keystores
+		cfg.CaplinConfig.ValidatorPasswordFile = ctx.String(CaplinValidatorPasswordFileFlag.Name)
+		if feeRecipient := ctx.String(CaplinValidatorFeeRecipientFlag.Name); feeRecipient != "" {
+			if !libcommon.IsHexAddress(feeRecipient) {
+				Fatalf("Option %s: invalid address %s", CaplinValidatorFeeRecipientFlag.Name, feeRecipient)
+			}
+			cfg.CaplinConfig.ValidatorFeeRecipient = libcommon.HexToAddress(feeRecipient)
+		}
+		cfg.CaplinConfig.ValidatorDoppelgangerEpochs = ctx.Uint64(CaplinValidatorDoppelgangerEpochsFlag.Name)
+	}
 }
 
 func setSilkworm(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1738,6 +2276,19 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	cfg.Snapshot.NoDownloader = ctx.Bool(NoDownloaderFlag.Name)
 	cfg.Snapshot.Verify = ctx.Bool(DownloaderVerifyFlag.Name)
 	cfg.Snapshot.DownloaderAddr = strings.TrimSpace(ctx.String(DownloaderAddrFlag.Name))
+	if _, err := snapcfg.ParseDownloadProfile(ctx.String(SnapDownloadProfileFlag.Name)); err != nil {
+		Fatalf("Option %s: %v", SnapDownloadProfileFlag.Name, err)
+	}
+	cfg.Snapshot.DownloadProfile = ctx.String(SnapDownloadProfileFlag.Name)
+	cfg.Snapshot.BeaconStates = ctx.Bool(CaplinStatesSnapshotsFlag.Name)
+	cfg.Snapshot.Receipts = ctx.Bool(SnapReceiptsFlag.Name)
+	cfg.Snapshot.BlobsArchive = ctx.Bool(CaplinBlobsArchiveFlag.Name)
+	cfg.Snapshot.ColdDir = ctx.String(SnapColdDirFlag.Name)
+	cfg.Snapshot.ColdPolicy = tiering.Policy{
+		MinAge:       ctx.Duration(SnapColdAgeFlag.Name),
+		PromoteReads: ctx.Uint64(SnapColdReadsFlag.Name),
+		Window:       ctx.Duration(SnapColdWindowFlag.Name),
+	}
 	if cfg.Snapshot.DownloaderAddr == "" {
 		downloadRateStr := ctx.String(TorrentDownloadRateFlag.Name)
 		uploadRateStr := ctx.String(TorrentUploadRateFlag.Name)
@@ -1762,6 +2313,19 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 		if err != nil {
 			panic(err)
 		}
+		cfg.Downloader.WebSeedS3 = downloadercfg2.S3FromEnv()
+		cfg.Downloader.WebSeedS3.Endpoint = ctx.String(WebSeedS3EndpointFlag.Name)
+		if ctx.IsSet(WebSeedS3RegionFlag.Name) {
+			cfg.Downloader.WebSeedS3.Region = ctx.String(WebSeedS3RegionFlag.Name)
+		}
+		cfg.Downloader.WebSeedS3.RequesterPays = ctx.Bool(WebSeedS3RequesterPaysFlag.Name)
+		cfg.Downloader.BandwidthSchedule = ctx.String(TorrentBandwidthScheduleFlag.Name)
+		if _, err := downloader.ParseBandwidthSchedule(cfg.Downloader.BandwidthSchedule); err != nil {
+			Fatalf("Option %s: %v", TorrentBandwidthScheduleFlag.Name, err)
+		}
+		if cfg.Downloader.ReleaseKeys, err = downloadercfg2.ParseReleaseKeys(ctx.String(DownloaderReleaseKeysFlag.Name)); err != nil {
+			Fatalf("Option %s: %v", DownloaderReleaseKeysFlag.Name, err)
+		}
 		downloadernat.DoNat(nodeConfig.P2P.NAT, cfg.Downloader.ClientConfig, logger)
 	}
 
@@ -1851,6 +2415,10 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	if clparams.EmbeddedSupported(cfg.NetworkID) {
 		cfg.InternalCL = !ctx.Bool(ExternalConsensusFlag.Name)
 	}
+	cfg.AsyncValidation = ctx.Bool(EngineAsyncValidationFlag.Name)
+	cfg.AsyncValidationWait = ctx.Duration(EngineAsyncValidationWaitFlag.Name)
+	cfg.EngineWitness = ctx.Bool(EngineWitnessFlag.Name)
+	cfg.EngineRecordFile = ctx.String(EngineRecordFlag.Name)
 
 	if ctx.IsSet(TrustedSetupFile.Name) {
 		libkzg.SetTrustedSetupFilePath(ctx.String(TrustedSetupFile.Name))
@@ -1859,6 +2427,10 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	if ctx.IsSet(TxPoolGossipDisableFlag.Name) {
 		cfg.DisableTxPoolGossip = ctx.Bool(TxPoolGossipDisableFlag.Name)
 	}
+	cfg.SnapServe = ctx.Bool(P2pSnapServeFlag.Name)
+	cfg.WitServe = ctx.Bool(P2pWitServeFlag.Name)
+	cfg.WitFetch = ctx.Bool(P2pWitFetchFlag.Name)
+	cfg.DiskIOProfile = ctx.Duration(DiskIOProfileFlag.Name)
 }
 
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
@@ -1903,6 +2475,8 @@ func CobraFlags(cmd *cobra.Command, urfaveCliFlagsLists ...[]cli.Flag) {
 				flags.String(f.Name, f.Value, f.Usage)
 			case *cli.BoolFlag:
 				flags.Bool(f.Name, false, f.Usage)
+			case *cli.Float64Flag:
+				flags.Float64(f.Name, f.Value, f.Usage)
 			default:
 				panic(fmt.Errorf("unexpected type: %T", flag))
 			}
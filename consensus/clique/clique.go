@@ -28,6 +28,7 @@ import (
 	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goccy/go-json"
@@ -58,6 +59,10 @@ const (
 	ExtraSeal            = crypto.SignatureLength // Fixed number of extra-data suffix bytes reserved for signer seal
 	warmupCacheSnapshots = 20
 
+	// snapshotsCompactionInterval - stored snapshots older than params.FullImmutabilityThreshold are compacted
+	// once per this number of blocks, see compactSnapshots
+	snapshotsCompactionInterval = 10_000
+
 	wiggleTime = 500 * time.Millisecond // Random delay (per signer) to allow concurrent signers
 )
 
@@ -187,6 +192,8 @@ type Clique struct {
 
 	proposals map[libcommon.Address]bool // Current list of proposals we are pushing
 
+	compactedAt atomic.Uint64 // Block number of last compaction of stored snapshots
+
 	signer libcommon.Address // Ethereum address of the signing key
 	signFn SignerFn          // Signer function to authorize hashes with
 	lock   sync.RWMutex      // Protects the signer and proposals fields
@@ -643,3 +650,57 @@ func (c *Clique) snapshots(latest uint64, total int) ([]*Snapshot, error) {
 
 	return res, nil
 }
+
+// maybeCompactSnapshots runs compaction of stored snapshots if there were snapshotsCompactionInterval blocks since
+// the previous one. Compaction of a node restarted with a big backlog takes one pass.
+func (c *Clique) maybeCompactSnapshots(number uint64) {
+	compactedAt := c.compactedAt.Load()
+	if number < compactedAt+snapshotsCompactionInterval || number < params.FullImmutabilityThreshold {
+		return
+	}
+	if !c.compactedAt.CompareAndSwap(compactedAt, number) {
+		return
+	}
+	before := number - params.FullImmutabilityThreshold
+	deleted, err := compactSnapshots(c.DB, c.config.Epoch, before)
+	if err != nil {
+		c.logger.Warn("[Clique] Compaction of stored snapshots failed", "before", before, "err", err)
+		return
+	}
+	c.logger.Debug("[Clique] Compacted stored snapshots", "before", before, "deleted", deleted)
+}
+
+// RebuildSnapshots deletes all stored snapshots and rebuilds them from headers of canonical chain up to `to`,
+// compacting them on the way
+func (c *Clique) RebuildSnapshots(ctx context.Context, chain consensus.ChainHeaderReader, to uint64) error {
+	if err := c.DB.Update(ctx, func(tx kv.RwTx) error {
+		if err := tx.ClearBucket(kv.CliqueSeparate); err != nil {
+			return err
+		}
+		return tx.ClearBucket(kv.CliqueLastSnapshot)
+	}); err != nil {
+		return err
+	}
+	c.recents.Purge()
+	c.compactedAt.Store(0)
+
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for number := uint64(0); number <= to; number += c.snapshotConfig.CheckpointInterval {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-logEvery.C:
+			c.logger.Info("[Clique] Rebuilding snapshots", "block", number, "to", to)
+		default:
+		}
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return fmt.Errorf("header %d not found", number)
+		}
+		if _, err := c.Snapshot(chain, number, header.Hash(), nil); err != nil {
+			return fmt.Errorf("snapshot at %d: %w", number, err)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package clique
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"sort"
@@ -140,15 +141,57 @@ func lastSnapshot(db kv.RwDB, logger log.Logger) (uint64, error) {
 	return lastNum, nil
 }
 
-// store inserts the snapshot into the database.
+// store inserts the snapshot into the database and advances the last snapshot marker, used to warm up caches
+// on restart.
 func (s *Snapshot) store(db kv.RwDB) error {
 	blob, err := json.Marshal(s)
 	if err != nil {
 		return err
 	}
 	return db.Update(context.Background(), func(tx kv.RwTx) error {
-		return tx.Put(kv.CliqueSeparate, SnapshotFullKey(s.Number, s.Hash), blob)
+		if err := tx.Put(kv.CliqueSeparate, SnapshotFullKey(s.Number, s.Hash), blob); err != nil {
+			return err
+		}
+		lastEnc, err := tx.GetOne(kv.CliqueLastSnapshot, LastSnapshotKey())
+		if err != nil {
+			return err
+		}
+		if len(lastEnc) == NumberLength && binary.BigEndian.Uint64(lastEnc) > s.Number {
+			return nil
+		}
+		return tx.Put(kv.CliqueLastSnapshot, LastSnapshotKey(), EncodeBlockNumber(s.Number))
+	})
+}
+
+// compactSnapshots deletes stored snapshots below `before`, except ones at epoch boundaries. Deleted snapshots
+// are reconstructed lazily from headers on request, starting from the closest kept one, so at most `epoch`
+// headers are applied.
+func compactSnapshots(db kv.RwDB, epoch uint64, before uint64) (deleted int, err error) {
+	err = db.Update(context.Background(), func(tx kv.RwTx) error {
+		c, err := tx.RwCursor(kv.CliqueSeparate)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		for k, _, err := c.First(); k != nil; k, _, err = c.Next() {
+			if err != nil {
+				return err
+			}
+			number := binary.BigEndian.Uint64(k[:NumberLength])
+			if number >= before {
+				break
+			}
+			if number%epoch == 0 {
+				continue
+			}
+			if err = c.DeleteCurrent(); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
 	})
+	return deleted, err
 }
 
 // validVote returns whether it makes sense to cast the specified vote in the
@@ -0,0 +1,45 @@
+package clique
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+)
+
+func TestCompactSnapshots(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	config := &chain.CliqueConfig{Epoch: 30}
+	for number := uint64(0); number <= 100; number += 10 {
+		require.NoError(t, newSnapshot(config, number, libcommon.Hash{byte(number)}, nil).store(db))
+	}
+	// marker isn't moved back by older snapshots
+	require.NoError(t, newSnapshot(config, 50, libcommon.Hash{1}, nil).store(db))
+	last, err := lastSnapshot(db, log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), last)
+
+	deleted, err := compactSnapshots(db, config.Epoch, 70)
+	require.NoError(t, err)
+	require.Equal(t, 5, deleted)
+
+	var numbers []uint64
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		return tx.ForEach(kv.CliqueSeparate, nil, func(k, _ []byte) error {
+			numbers = append(numbers, binary.BigEndian.Uint64(k))
+			return nil
+		})
+	}))
+	require.Equal(t, []uint64{0, 30, 60, 70, 80, 90, 100}, numbers)
+
+	snap, err := loadSnapshot(config, db, 30, libcommon.Hash{30})
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), snap.Number)
+}
@@ -226,6 +226,7 @@ func (c *Clique) Snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			return nil, err
 		}
 		c.logger.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+		c.maybeCompactSnapshots(snap.Number)
 	}
 	return snap, err
 }
@@ -189,6 +189,15 @@ type EngineWriter interface {
 	APIs(chain ChainHeaderReader) []rpc.API
 }
 
+// SealPreverifier is implemented by engines which can verify seal of a header without
+// chain context. Implementations must be thread-safe and remember the outcome, so that
+// following VerifyHeader of same header doesn't repeat the expensive part. Headers download
+// uses it to verify seals of queued headers in parallel, while VerifyHeader is still called
+// in order of insertion.
+type SealPreverifier interface {
+	PreverifySeal(header *types.Header) error
+}
+
 // PoW is a consensus engine based on proof-of-work.
 type PoW interface {
 	Engine
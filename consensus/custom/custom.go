@@ -0,0 +1,363 @@
+// Package custom lets private networks plug their own consensus rules into
+// Erigon without modifying it: rules implement the Rules interface, register
+// a constructor under a name with Register, and chains select them with
+// `"consensus": "<name>"` in their chain config. Engine derives a complete
+// consensus.Engine from the rules.
+package custom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+const allowedFutureBlockTimeSeconds = int64(15) // Max seconds from current time allowed for blocks, before they're considered future blocks
+
+var (
+	// ErrFinalizedReorg is returned for a header which would reorg a block the
+	// rules consider final.
+	ErrFinalizedReorg = errors.New("header reorgs finalized block")
+
+	// ErrUnclesNotAllowed is returned for a block with uncles.
+	ErrUnclesNotAllowed = errors.New("uncles not allowed")
+
+	// ErrNotAuthorized is returned by Seal when no signer is authorized.
+	ErrNotAuthorized = errors.New("no signer authorized")
+
+	errOlderBlockTime = errors.New("timestamp older than parent")
+)
+
+// SignFn hashes and signs the data to be signed by a backing account.
+type SignFn func(signer libcommon.Address, mimeType string, message []byte) ([]byte, error)
+
+// Rules are the consensus rules of a custom chain. Everything else is common
+// to custom chains: headers are verified like the ones of ethash chains
+// (timestamps, gas limit, EIP-1559 fields, numbering), except extra data
+// which is left to the rules, and uncles are not allowed. Rules are called
+// concurrently and must be thread-safe.
+type Rules interface {
+	// Author returns the address of the account which sealed the header.
+	Author(header *types.Header) (libcommon.Address, error)
+
+	// SealHash returns the hash of the header signed by its author.
+	SealHash(header *types.Header) libcommon.Hash
+
+	// VerifySeal checks that the header is sealed by an account allowed to
+	// seal it on top of its parent.
+	VerifySeal(chain consensus.ChainHeaderReader, header, parent *types.Header) error
+
+	// Seal seals the header of a locally built block with the signer.
+	Seal(chain consensus.ChainHeaderReader, header *types.Header, signer libcommon.Address, sign SignFn) error
+
+	// Difficulty returns the difficulty of a block with the given time on top
+	// of its parent.
+	Difficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int
+
+	// Finalized returns the number of the latest block which can't be reorged
+	// while the given header is the head, 0 if the rules have no finality.
+	Finalized(chain consensus.ChainHeaderReader, head *types.Header) uint64
+
+	// BlockStart and BlockEnd make the system calls of the block, before and
+	// after its transactions: contract calls like validator set updates whose
+	// state changes are part of the state transition of the block. An error
+	// of BlockEnd invalidates the block, an error of BlockStart is only logged
+	// since the engine can't reject blocks there.
+	BlockStart(chain consensus.ChainHeaderReader, header *types.Header, syscall consensus.SystemCall) error
+	BlockEnd(chain consensus.ChainHeaderReader, header *types.Header, syscall consensus.SystemCall) error
+
+	// Rewards returns the balances credited at the end of the block.
+	Rewards(config *chain.Config, header *types.Header) ([]consensus.Reward, error)
+}
+
+// APIProvider is implemented by rules serving RPC APIs.
+type APIProvider interface {
+	APIs(chain consensus.ChainHeaderReader) []rpc.API
+}
+
+// Config is what the rules of a chain are created from.
+type Config struct {
+	ChainConfig *chain.Config
+	Params      json.RawMessage // `consensusParams` of the chain config, as is
+	DB          kv.RwDB         // consensus database of the rules, for snapshots of validator sets and alike
+	Logger      log.Logger
+}
+
+// Factory creates the rules of a chain.
+type Factory func(cfg Config) (Rules, error)
+
+var (
+	factoriesLock sync.RWMutex
+	factories     = map[chain.ConsensusName]Factory{}
+)
+
+// Register makes rules available to chains with the given consensus name. It
+// is meant to be called from init functions and panics if the name is taken.
+func Register(name chain.ConsensusName, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	switch name {
+	case "", chain.AuRaConsensus, chain.EtHashConsensus, chain.CliqueConsensus, chain.BorConsensus:
+		panic(fmt.Sprintf("consensus name %q is reserved", name))
+	}
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("consensus %q is already registered", name))
+	}
+	factories[name] = factory
+}
+
+// Lookup returns the factory registered under the name.
+func Lookup(name chain.ConsensusName) (Factory, bool) {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+// Engine is the consensus.Engine of a custom chain, built around its rules.
+type Engine struct {
+	name   chain.ConsensusName
+	rules  Rules
+	logger log.Logger
+
+	lock   sync.RWMutex
+	signer libcommon.Address
+	signFn SignFn
+}
+
+var _ consensus.Engine = (*Engine)(nil)
+
+func New(name chain.ConsensusName, rules Rules, logger log.Logger) *Engine {
+	return &Engine{name: name, rules: rules, logger: logger}
+}
+
+// Rules returns the rules the engine is built around.
+func (e *Engine) Rules() Rules {
+	return e.rules
+}
+
+// Authorize injects the signer used to seal blocks.
+func (e *Engine) Authorize(signer libcommon.Address, signFn SignFn) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.signer, e.signFn = signer, signFn
+}
+
+func (e *Engine) Type() chain.ConsensusName {
+	return e.name
+}
+
+func (e *Engine) Author(header *types.Header) (libcommon.Address, error) {
+	return e.rules.Author(header)
+}
+
+func (e *Engine) IsServiceTransaction(sender libcommon.Address, syscall consensus.SystemCall) bool {
+	return false
+}
+
+func (e *Engine) CalculateRewards(config *chain.Config, header *types.Header, uncles []*types.Header, syscall consensus.SystemCall) ([]consensus.Reward, error) {
+	return e.rules.Rewards(config, header)
+}
+
+func (e *Engine) Close() error {
+	if closer, ok := e.rules.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (e *Engine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	// Short circuit if the header is known, or its parent not
+	number := header.Number.Uint64()
+	if chain.GetHeader(header.Hash(), number) != nil {
+		return nil
+	}
+	if number == 0 {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	if err := verifyHeaderBasics(chain, header, parent); err != nil {
+		return err
+	}
+	if expected := e.rules.Difficulty(chain, header.Time, parent); expected.Cmp(header.Difficulty) != 0 {
+		return fmt.Errorf("invalid difficulty: have %v, want %v", header.Difficulty, expected)
+	}
+	if current := chain.CurrentHeader(); current != nil {
+		if finalized := e.rules.Finalized(chain, current); finalized > 0 && number <= finalized {
+			return fmt.Errorf("%w: header %d, finalized %d", ErrFinalizedReorg, number, finalized)
+		}
+	}
+	if seal {
+		return e.rules.VerifySeal(chain, header, parent)
+	}
+	return nil
+}
+
+// verifyHeaderBasics - checks of ethash.VerifyHeaderBasics except extra data size: custom rules usually keep
+// signatures or validator sets there
+func verifyHeaderBasics(chain consensus.ChainHeaderReader, header, parent *types.Header) error {
+	if header.Time > uint64(time.Now().Unix()+allowedFutureBlockTimeSeconds) {
+		return consensus.ErrFutureBlock
+	}
+	if header.Time <= parent.Time {
+		return errOlderBlockTime
+	}
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number.Uint64()) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, expected 'nil'", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header, false /*skipGasLimit*/); err != nil {
+		return err
+	}
+	if err := misc.VerifyAbsenceOfCancunHeaderFields(header); err != nil {
+		return err
+	}
+	if header.Number.Uint64() != parent.Number.Uint64()+1 {
+		return consensus.ErrInvalidNumber
+	}
+	if header.WithdrawalsHash != nil {
+		return consensus.ErrUnexpectedWithdrawals
+	}
+	if header.UncleHash != types.EmptyUncleHash {
+		return ErrUnclesNotAllowed
+	}
+	return nil
+}
+
+func (e *Engine) VerifyUncles(chain consensus.ChainReader, header *types.Header, uncles []*types.Header) error {
+	if len(uncles) > 0 {
+		return ErrUnclesNotAllowed
+	}
+	return nil
+}
+
+func (e *Engine) Prepare(chain consensus.ChainHeaderReader, header *types.Header, state *state.IntraBlockState) error {
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Difficulty = e.rules.Difficulty(chain, header.Time, parent)
+	return nil
+}
+
+func (e *Engine) Initialize(config *chain.Config, chain consensus.ChainHeaderReader, header *types.Header,
+	state *state.IntraBlockState, syscall consensus.SysCallCustom, logger log.Logger) {
+	call := func(contract libcommon.Address, data []byte) ([]byte, error) {
+		return syscall(contract, data, state, header, false /* constCall */)
+	}
+	if err := e.rules.BlockStart(chain, header, call); err != nil {
+		logger.Error("[custom] block start system calls failed", "consensus", e.name, "block", header.Number, "err", err)
+	}
+}
+
+func (e *Engine) Finalize(config *chain.Config, header *types.Header, state *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, withdrawals []*types.Withdrawal,
+	chain consensus.ChainReader, syscall consensus.SystemCall, logger log.Logger,
+) (types.Transactions, types.Receipts, error) {
+	if err := e.rules.BlockEnd(chain, header, syscall); err != nil {
+		return nil, nil, fmt.Errorf("block end system calls: %w", err)
+	}
+	rewards, err := e.rules.Rewards(config, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range rewards {
+		state.AddBalance(rewards[i].Beneficiary, &rewards[i].Amount)
+	}
+	return txs, receipts, nil
+}
+
+func (e *Engine) FinalizeAndAssemble(config *chain.Config, header *types.Header, state *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, withdrawals []*types.Withdrawal,
+	chain consensus.ChainReader, syscall consensus.SystemCall, call consensus.Call, logger log.Logger,
+) (*types.Block, types.Transactions, types.Receipts, error) {
+	outTxs, outReceipts, err := e.Finalize(config, header, state, txs, uncles, receipts, withdrawals, chain, syscall, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return types.NewBlock(header, outTxs, nil, outReceipts, withdrawals), outTxs, outReceipts, nil
+}
+
+// Seal seals the block with the authorized signer and sends it once its time
+// has come.
+func (e *Engine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	e.lock.RLock()
+	signer, signFn := e.signer, e.signFn
+	e.lock.RUnlock()
+	if signFn == nil {
+		return ErrNotAuthorized
+	}
+
+	header := block.Header()
+	if err := e.rules.Seal(chain, header, signer, signFn); err != nil {
+		return err
+	}
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	go func() {
+		defer debug.LogPanic()
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			e.logger.Warn("[custom] sealing result is not read by miner", "consensus", e.name, "sealhash", e.rules.SealHash(header))
+		}
+	}()
+	return nil
+}
+
+func (e *Engine) SealHash(header *types.Header) libcommon.Hash {
+	return e.rules.SealHash(header)
+}
+
+func (e *Engine) CalcDifficulty(chain consensus.ChainHeaderReader, time, parentTime uint64, parentDifficulty *big.Int, parentNumber uint64,
+	parentHash, parentUncleHash libcommon.Hash, parentAuRaStep uint64) *big.Int {
+	parent := chain.GetHeader(parentHash, parentNumber)
+	if parent == nil {
+		return nil
+	}
+	return e.rules.Difficulty(chain, time, parent)
+}
+
+func (e *Engine) GenerateSeal(chain consensus.ChainHeaderReader, currnt, parent *types.Header, call consensus.Call) []byte {
+	return nil
+}
+
+func (e *Engine) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	if provider, ok := e.rules.(APIProvider); ok {
+		return provider.APIs(chain)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package custom
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// soloRules - single authority signing blocks into Extra, blocks are final after 2 confirmations
+type soloRules struct {
+	authority libcommon.Address
+}
+
+func (r *soloRules) Author(header *types.Header) (libcommon.Address, error) {
+	pub, err := crypto.SigToPub(r.SealHash(header).Bytes(), header.Extra)
+	if err != nil {
+		return libcommon.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func (r *soloRules) SealHash(header *types.Header) libcommon.Hash {
+	h := types.CopyHeader(header)
+	h.Extra = nil
+	return h.Hash()
+}
+
+func (r *soloRules) VerifySeal(_ consensus.ChainHeaderReader, header, _ *types.Header) error {
+	author, err := r.Author(header)
+	if err != nil {
+		return err
+	}
+	if author != r.authority {
+		return errors.New("not the authority")
+	}
+	return nil
+}
+
+func (r *soloRules) Seal(_ consensus.ChainHeaderReader, header *types.Header, signer libcommon.Address, sign SignFn) error {
+	sig, err := sign(signer, "", r.SealHash(header).Bytes())
+	if err != nil {
+		return err
+	}
+	header.Extra = sig
+	return nil
+}
+
+func (r *soloRules) Difficulty(consensus.ChainHeaderReader, uint64, *types.Header) *big.Int {
+	return big.NewInt(1)
+}
+
+func (r *soloRules) Finalized(_ consensus.ChainHeaderReader, head *types.Header) uint64 {
+	if number := head.Number.Uint64(); number > 2 {
+		return number - 2
+	}
+	return 0
+}
+
+func (r *soloRules) BlockStart(consensus.ChainHeaderReader, *types.Header, consensus.SystemCall) error {
+	return nil
+}
+
+func (r *soloRules) BlockEnd(consensus.ChainHeaderReader, *types.Header, consensus.SystemCall) error {
+	return nil
+}
+
+func (r *soloRules) Rewards(*chain.Config, *types.Header) ([]consensus.Reward, error) {
+	return nil, nil
+}
+
+type testChain struct {
+	consensus.ChainHeaderReader
+	headers []*types.Header
+}
+
+func (c *testChain) Config() *chain.Config { return &chain.Config{ChainID: big.NewInt(1337)} }
+
+func (c *testChain) CurrentHeader() *types.Header { return c.headers[len(c.headers)-1] }
+
+func (c *testChain) GetHeader(hash libcommon.Hash, number uint64) *types.Header {
+	if number < uint64(len(c.headers)) && c.headers[number].Hash() == hash {
+		return c.headers[number]
+	}
+	return nil
+}
+
+func signFn(key *ecdsa.PrivateKey) SignFn {
+	return func(_ libcommon.Address, _ string, message []byte) ([]byte, error) {
+		return crypto.Sign(message, key)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	factory := func(Config) (Rules, error) { return &soloRules{}, nil }
+	Register("solo", factory)
+	_, ok := Lookup("solo")
+	require.True(t, ok)
+	_, ok = Lookup("other")
+	require.False(t, ok)
+
+	require.Panics(t, func() { Register("solo", factory) })
+	require.Panics(t, func() { Register(chain.CliqueConsensus, factory) })
+}
+
+func TestEngine(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	authority := crypto.PubkeyToAddress(key.PublicKey)
+	engine := New("solo", &soloRules{authority: authority}, log.New())
+
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), GasLimit: params.GenesisGasLimit, Time: uint64(time.Now().Unix()) - 100}
+	c := &testChain{headers: []*types.Header{genesis}}
+	newHeader := func(parent *types.Header) *types.Header {
+		return &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).Add(parent.Number, big.NewInt(1)), GasLimit: parent.GasLimit, Time: parent.Time + 1, UncleHash: types.EmptyUncleHash}
+	}
+	seal := func(header *types.Header) *types.Header {
+		require.NoError(t, engine.Prepare(c, header, nil))
+		results := make(chan *types.Block, 1)
+		require.NoError(t, engine.Seal(c, types.NewBlockWithHeader(header), results, nil))
+		return (<-results).Header()
+	}
+
+	header := newHeader(genesis)
+	require.ErrorIs(t, engine.Seal(c, types.NewBlockWithHeader(header), nil, nil), ErrNotAuthorized)
+
+	// sealed by the authority
+	engine.Authorize(authority, signFn(key))
+	header = seal(header)
+	require.NoError(t, engine.VerifyHeader(c, header, true))
+	author, err := engine.Author(header)
+	require.NoError(t, err)
+	require.Equal(t, authority, author)
+
+	// sealed by someone else
+	engine.Authorize(authority, signFn(otherKey))
+	require.Error(t, engine.VerifyHeader(c, seal(newHeader(genesis)), true))
+
+	// blocks below finalized one can't be replaced
+	engine.Authorize(authority, signFn(key))
+	for i := 0; i < 4; i++ {
+		c.headers = append(c.headers, seal(newHeader(c.CurrentHeader())))
+	}
+	fork := func(parent *types.Header) *types.Header {
+		header := newHeader(parent)
+		header.Time++ // differs from canonical one
+		return seal(header)
+	}
+	require.ErrorIs(t, engine.VerifyHeader(c, fork(c.headers[1]), true), ErrFinalizedReorg)
+	require.NoError(t, engine.VerifyHeader(c, fork(c.headers[2]), true))
+
+	require.ErrorIs(t, engine.VerifyUncles(nil, header, []*types.Header{genesis}), ErrUnclesNotAllowed)
+}
@@ -474,9 +474,32 @@ func calcDifficultyFrontier(time, parentTime uint64, parentDifficulty *big.Int,
 // VerifySeal implements consensus.Engine, checking whether the given block satisfies
 // the PoW difficulty requirements.
 func (ethash *Ethash) VerifySeal(_ consensus.ChainHeaderReader, header *types.Header) error {
+	if ethash.shared != nil {
+		return ethash.shared.VerifySeal(nil, header)
+	}
+	if ethash.verifiedSeals != nil {
+		if _, ok := ethash.verifiedSeals.Get(header.Hash()); ok {
+			return nil
+		}
+	}
 	return ethash.verifySeal(header, false)
 }
 
+// PreverifySeal implements consensus.SealPreverifier: light verification of PoW doesn't need chain context,
+// headers with valid seal are remembered and not verified again by VerifyHeader
+func (ethash *Ethash) PreverifySeal(header *types.Header) error {
+	if ethash.shared != nil {
+		return ethash.shared.PreverifySeal(header)
+	}
+	if err := ethash.verifySeal(header, false); err != nil {
+		return err
+	}
+	if ethash.verifiedSeals != nil {
+		ethash.verifiedSeals.Add(header.Hash(), struct{}{})
+	}
+	return nil
+}
+
 // Exported for fuzzing
 var FrontierDifficultyCalulator = calcDifficultyFrontier
 var HomesteadDifficultyCalulator = calcDifficultyHomestead
@@ -32,7 +32,9 @@ import (
 	"unsafe"
 
 	"github.com/edsrzf/mmap-go"
+	lru2 "github.com/hashicorp/golang-lru/v2"
 	"github.com/hashicorp/golang-lru/v2/simplelru"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon/consensus/ethash/ethashcfg"
 
 	"github.com/ledgerwatch/erigon/common/debug"
@@ -44,6 +46,9 @@ import (
 
 const doNotStoreCachesOnDisk = ""
 
+// verifiedSealsLimit - amount of headers with preverified seal to remember, covers few batches of headers download
+const verifiedSealsLimit = 16 * 1024
+
 var ErrInvalidDumpMagic = errors.New("invalid dump magic")
 
 var (
@@ -408,6 +413,8 @@ type Ethash struct {
 	caches   *lru // In memory caches to avoid regenerating too often
 	datasets *lru // In memory datasets to avoid regenerating too often
 
+	verifiedSeals *lru2.Cache[libcommon.Hash, struct{}] // Hashes of headers with valid PoW, see PreverifySeal
+
 	// Mining related fields
 	rand     *rand.Rand     // Properly seeded random source for nonces
 	hashrate *hashRateMeter // Meter tracking the average hashrate
@@ -431,7 +438,9 @@ func New(config ethashcfg.Config, notify []string, noverify bool) *Ethash {
 		config.Log.Warn("One ethash cache must always be in memory", "requested", config.CachesInMem)
 		config.CachesInMem = 1
 	}
-	if config.DatasetDir != "" && config.DatasetsOnDisk > 0 {
+	if config.PowMode == ethashcfg.ModeLight {
+		config.Log.Info("Ethash in light mode: work of remote sealers is verified with caches only", "caches", config.CachesInMem)
+	} else if config.DatasetDir != "" && config.DatasetsOnDisk > 0 {
 		config.Log.Info("Disk storage enabled for ethash DAGs", "dir", config.DatasetDir, "count", config.DatasetsOnDisk)
 	}
 	ethash := &Ethash{
@@ -440,6 +449,7 @@ func New(config ethashcfg.Config, notify []string, noverify bool) *Ethash {
 		datasets: newlru("dataset", config.DatasetsInMem, newDataset),
 		hashrate: newHashRateMeter(),
 	}
+	ethash.verifiedSeals, _ = lru2.New[libcommon.Hash, struct{}](verifiedSealsLimit)
 	if config.PowMode == ethashcfg.ModeShared {
 		ethash.shared = GetSharedEthash()
 	}
@@ -531,8 +541,8 @@ func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
 // Note the returned hashrate includes local hashrate, but also includes the total
 // hashrate of all remote miner.
 func (ethash *Ethash) Hashrate() float64 {
-	// Short circuit if we are run the ethash in normal/test mode.
-	if (ethash.config.PowMode != ethashcfg.ModeNormal && ethash.config.PowMode != ethashcfg.ModeTest) || ethash.remote == nil {
+	// Short circuit if we are run the ethash in normal/test/light mode.
+	if (ethash.config.PowMode != ethashcfg.ModeNormal && ethash.config.PowMode != ethashcfg.ModeTest && ethash.config.PowMode != ethashcfg.ModeLight) || ethash.remote == nil {
 		return ethash.hashrate.Rate()
 	}
 	var res = make(chan uint64, 1)
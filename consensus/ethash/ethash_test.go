@@ -25,6 +25,7 @@ import (
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 
+	"github.com/ledgerwatch/erigon/consensus/ethash/ethashcfg"
 	"github.com/ledgerwatch/erigon/core/types"
 )
 
@@ -108,3 +109,73 @@ func TestClosedRemoteSealer(t *testing.T) {
 		t.Error("expect to return false when submit hashrate to a stopped ethash")
 	}
 }
+
+func TestPreverifySeal(t *testing.T) {
+	ethash := NewTester(nil, true)
+	defer ethash.Close()
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Nonce: types.EncodeNonce(7)}
+	if err := ethash.PreverifySeal(header); err != errInvalidMixDigest {
+		t.Fatalf("expect invalid mix digest, got %v", err)
+	}
+	if ethash.verifiedSeals.Contains(header.Hash()) {
+		t.Fatal("invalid seal must not be remembered")
+	}
+
+	cache := ethash.cache(1)
+	digest, _ := hashimotoLight(32*1024, cache.cache, ethash.SealHash(header).Bytes(), header.Nonce.Uint64())
+	header.MixDigest = libcommon.BytesToHash(digest)
+	if err := ethash.PreverifySeal(header); err != nil {
+		t.Fatal(err)
+	}
+	if !ethash.verifiedSeals.Contains(header.Hash()) {
+		t.Fatal("valid seal must be remembered")
+	}
+
+	// remembered seals are not verified again
+	invalid := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Nonce: types.EncodeNonce(8)}
+	ethash.verifiedSeals.Add(invalid.Hash(), struct{}{})
+	if err := ethash.VerifySeal(nil, invalid); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLightModeNeverGeneratesDataset(t *testing.T) {
+	ethash := New(ethashcfg.Config{PowMode: ethashcfg.ModeLight, CachesInMem: 1}, nil, false)
+	defer ethash.Close()
+
+	// mining path: work submitted by remote sealer is verified with cache
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	results := make(chan *types.Block, 1)
+	if err := ethash.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatal(err)
+	}
+	api := &API{ethash}
+	work, err := api.GetWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealhash := ethash.SealHash(header)
+	if work[0] != sealhash.Hex() {
+		t.Fatal("expect to return a mining work has same hash")
+	}
+	nonce := types.EncodeNonce(7)
+	digest, _ := hashimotoLight(datasetSize(1), ethash.cache(1).cache, sealhash.Bytes(), nonce.Uint64())
+	if !api.SubmitWork(nonce, sealhash, libcommon.BytesToHash(digest)) {
+		t.Fatal("expect valid solution to be accepted")
+	}
+	if block := <-results; block.Nonce() != nonce {
+		t.Fatal("expect sealed block with submitted nonce")
+	}
+	if ethash.datasets.cache.Len() != 0 {
+		t.Fatal("dataset must not be generated in light mode")
+	}
+
+	// remote sealers hashrate is reported as in normal mode
+	if !api.SubmitHashRate(hexutil.Uint64(100), libcommon.HexToHash("a")) {
+		t.Fatal("remote miner submit hashrate failed")
+	}
+	if tot := ethash.Hashrate(); tot != 100 {
+		t.Fatalf("expect remote hashrate to be counted, got %f", tot)
+	}
+}
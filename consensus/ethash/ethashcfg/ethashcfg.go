@@ -31,4 +31,9 @@ const (
 
 	ModeFake
 	ModeFullFake
+
+	// ModeLight - sealing switch: work submitted by remote sealers is verified with caches instead of DAG (slower,
+	// but no multi-GB DAG for a pool frontend). Header verification uses caches in every mode, and local mining
+	// still generates DAGs
+	ModeLight
 )
@@ -112,6 +112,11 @@ func (f *FakeEthash) VerifySeal(_ consensus.ChainHeaderReader, header *types.Hea
 	return nil
 }
 
+// PreverifySeal - fake seals are cheap to verify, nothing to do ahead
+func (f *FakeEthash) PreverifySeal(header *types.Header) error {
+	return nil
+}
+
 // If we're running a fake PoW, simply return a 0 nonce immediately
 func (f *FakeEthash) Seal(_ consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
 	header := block.Header()
@@ -35,6 +35,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/ethash/ethashcfg"
 	"github.com/ledgerwatch/erigon/core/types"
 )
 
@@ -302,7 +303,9 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest libcommon.Ha
 
 	start := time.Now()
 	if !s.noverify {
-		if err := s.ethash.verifySeal(header, true); err != nil {
+		// light mode: submitted work is verified with cache, as during sync
+		fulldag := s.ethash.config.PowMode != ethashcfg.ModeLight
+		if err := s.ethash.verifySeal(header, fulldag); err != nil {
 			s.ethash.config.Log.Warn("Invalid proof-of-work submitted", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)), "err", err)
 			return false
 		}
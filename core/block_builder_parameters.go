@@ -16,4 +16,5 @@ type BlockBuilderParameters struct {
 	SuggestedFeeRecipient libcommon.Address
 	Withdrawals           []*types.Withdrawal // added in Shapella (EIP-4895)
 	ParentBeaconBlockRoot *libcommon.Hash     // added in Dencun (EIP-4788)
+	InclusionList         types.Transactions  // included right after bundles if still valid, before txpool transactions
 }
@@ -345,13 +345,18 @@ func FinalizeBlockExecution(
 
 func InitializeBlockExecution(engine consensus.Engine, chain consensus.ChainHeaderReader, header *types.Header,
 	cc *chain.Config, ibs *state.IntraBlockState, logger log.Logger,
+) error {
+	return InitializeBlockExecutionWithWriter(engine, chain, header, cc, ibs, state.NewNoopWriter(), logger)
+}
+
+// InitializeBlockExecutionWithWriter - InitializeBlockExecution sending the state writes of the initialization to stateWriter
+func InitializeBlockExecutionWithWriter(engine consensus.Engine, chain consensus.ChainHeaderReader, header *types.Header,
+	cc *chain.Config, ibs *state.IntraBlockState, stateWriter state.StateWriter, logger log.Logger,
 ) error {
 	engine.Initialize(cc, chain, header, ibs, func(contract libcommon.Address, data []byte, ibState *state.IntraBlockState, header *types.Header, constCall bool) ([]byte, error) {
 		return SysCallContract(contract, data, cc, ibState, header, engine, constCall)
 	}, logger)
-	noop := state.NewNoopWriter()
-	ibs.FinalizeTx(cc.Rules(header.Number.Uint64(), header.Time), noop)
-	return nil
+	return ibs.FinalizeTx(cc.Rules(header.Number.Uint64(), header.Time), stateWriter)
 }
 
 func BlockPostValidation(gasUsed, blobGasUsed uint64, h *types.Header) error {
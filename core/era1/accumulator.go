@@ -0,0 +1,84 @@
+package era1
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+)
+
+const length32 = 32
+
+// ComputeAccumulator - SSZ hash_tree_root of List[HeaderRecord, MaxEraSize], where HeaderRecord = {block_hash: Bytes32, total_difficulty: uint256}.
+// Same root as in "historical_hashes_accumulator" of portal network: allows to verify era1 file by trusted list of roots.
+func ComputeAccumulator(hashes []libcommon.Hash, tds []*big.Int) (libcommon.Hash, error) {
+	if len(hashes) != len(tds) {
+		return libcommon.Hash{}, fmt.Errorf("era1: hashes and tds length mismatch: %d != %d", len(hashes), len(tds))
+	}
+	if len(hashes) > MaxEraSize {
+		return libcommon.Hash{}, fmt.Errorf("era1: too many header records: %d", len(hashes))
+	}
+	layer := make([][length32]byte, len(hashes))
+	for i := range hashes {
+		td, err := tdToBytes(tds[i])
+		if err != nil {
+			return libcommon.Hash{}, err
+		}
+		layer[i] = hashPair(hashes[i], td)
+	}
+
+	// merkleize with limit MaxEraSize: missing leaves are zero-hashes of corresponding depth
+	var zero [length32]byte
+	for size := MaxEraSize; size > 1; size /= 2 {
+		next := make([][length32]byte, (len(layer)+1)/2)
+		for i := range next {
+			right := zero
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+			next[i] = hashPair(layer[2*i], right)
+		}
+		layer = next
+		zero = hashPair(zero, zero)
+	}
+	root := zero
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+
+	// mix_in_length
+	var length [length32]byte
+	length[0], length[1] = byte(len(hashes)), byte(len(hashes)>>8)
+	return hashPair(root, length), nil
+}
+
+func hashPair(a, b [length32]byte) [length32]byte {
+	var buf [2 * length32]byte
+	copy(buf[:], a[:])
+	copy(buf[length32:], b[:])
+	return sha256.Sum256(buf[:])
+}
+
+// tdToBytes - uint256, little-endian
+func tdToBytes(td *big.Int) (res [length32]byte, err error) {
+	if td == nil || td.Sign() < 0 || td.BitLen() > 256 {
+		return res, fmt.Errorf("era1: invalid total difficulty: %v", td)
+	}
+	td.FillBytes(res[:])
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, nil
+}
+
+func tdFromBytes(b []byte) (*big.Int, error) {
+	if len(b) != length32 {
+		return nil, fmt.Errorf("era1: invalid total difficulty size: %d", len(b))
+	}
+	be := make([]byte, length32)
+	for i := range b {
+		be[length32-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be), nil
+}
@@ -0,0 +1,85 @@
+package era1
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// e2store - container format of era/era1 files: sequence of entries `type(2 bytes) | length(4 bytes) | reserved(2 bytes) | value`.
+// All integers are little-endian. Spec: https://github.com/status-im/nimbus-eth2/blob/stable/docs/e2store.md
+const e2HeaderSize = 8
+
+type Entry struct {
+	Type  uint16
+	Value []byte
+}
+
+type e2Writer struct {
+	w   io.Writer
+	hdr [e2HeaderSize]byte
+}
+
+func newE2Writer(w io.Writer) *e2Writer { return &e2Writer{w: w} }
+
+// Write - writes entry, returns amount of written bytes (header included)
+func (w *e2Writer) Write(typ uint16, value []byte) (int, error) {
+	binary.LittleEndian.PutUint16(w.hdr[:2], typ)
+	binary.LittleEndian.PutUint32(w.hdr[2:6], uint32(len(value)))
+	binary.LittleEndian.PutUint16(w.hdr[6:], 0)
+	n, err := w.w.Write(w.hdr[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(value)
+	return n + m, err
+}
+
+type e2Reader struct {
+	r io.ReaderAt
+}
+
+func newE2Reader(r io.ReaderAt) *e2Reader { return &e2Reader{r: r} }
+
+// ReadMetadataAt - type and length of value of entry at offset `off`
+func (r *e2Reader) ReadMetadataAt(off int64) (typ uint16, length uint32, err error) {
+	var hdr [e2HeaderSize]byte
+	if _, err = r.r.ReadAt(hdr[:], off); err != nil {
+		return 0, 0, err
+	}
+	if reserved := binary.LittleEndian.Uint16(hdr[6:]); reserved != 0 {
+		return 0, 0, fmt.Errorf("e2store: reserved bytes are not zero at offset %d", off)
+	}
+	return binary.LittleEndian.Uint16(hdr[:2]), binary.LittleEndian.Uint32(hdr[2:6]), nil
+}
+
+// ReadAt - entry at offset `off` and its size (header included): offset of next entry is `off+size`
+func (r *e2Reader) ReadAt(off int64) (e *Entry, size int64, err error) {
+	typ, length, err := r.ReadMetadataAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	e = &Entry{Type: typ, Value: make([]byte, length)}
+	if length > 0 {
+		if _, err = r.r.ReadAt(e.Value, off+e2HeaderSize); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, 0, fmt.Errorf("e2store: entry at offset %d: %w", off, err)
+		}
+	}
+	return e, e2HeaderSize + int64(length), nil
+}
+
+// ReadTypeAt - same as ReadAt, but fails if entry has other type
+func (r *e2Reader) ReadTypeAt(typ uint16, off int64) (*Entry, int64, error) {
+	e, size, err := r.ReadAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if e.Type != typ {
+		return nil, 0, fmt.Errorf("e2store: unexpected entry type at offset %d: 0x%04x, expected 0x%04x", off, e.Type, typ)
+	}
+	return e, size, nil
+}
@@ -0,0 +1,360 @@
+// Package era1 - reading and writing of era1 files: archives of pre-merge history (headers, bodies, receipts, total difficulty)
+// used by geth, nimbus and other clients. Spec: https://github.com/eth-clients/e2store-format-specs/blob/main/formats/era1.md
+//
+//	era1 := Version | block-tuple* | other-entries* | Accumulator | BlockIndex
+//	block-tuple := CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty
+package era1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalDifficulty    uint16 = 0x06
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+
+	// MaxEraSize - blocks in one era1 file (epoch). Only last pre-merge file may have less blocks.
+	MaxEraSize = 8192
+
+	Ext = ".era1"
+)
+
+// Builder - writes blocks of one epoch into era1 file. Blocks must be added in order, then Finalize must be called.
+type Builder struct {
+	w       *e2Writer
+	start   uint64
+	written uint64
+	offsets []uint64
+	hashes  []libcommon.Hash
+	tds     []*big.Int
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+func NewBuilder(w io.Writer) *Builder {
+	buf := bytes.NewBuffer(nil)
+	return &Builder{w: newE2Writer(w), buf: buf, snappy: snappy.NewBufferedWriter(buf)}
+}
+
+// Add - td is total difficulty including the block
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	header, err := rlp.EncodeToBytes(block.HeaderNoCopy())
+	if err != nil {
+		return err
+	}
+	body, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	if receipts == nil {
+		receipts = types.Receipts{}
+	}
+	receiptsRLP, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(header, body, receiptsRLP, block.NumberU64(), block.Hash(), td)
+}
+
+func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash libcommon.Hash, td *big.Int) error {
+	if len(b.offsets) == 0 {
+		b.start = number
+		n, err := b.w.Write(TypeVersion, nil)
+		if err != nil {
+			return err
+		}
+		b.written += uint64(n)
+	}
+	if expected := b.start + uint64(len(b.offsets)); number != expected {
+		return fmt.Errorf("era1: non-sequential block %d, expected %d", number, expected)
+	}
+	if len(b.offsets) >= MaxEraSize {
+		return fmt.Errorf("era1: too many blocks in one file, max %d", MaxEraSize)
+	}
+	tdBytes, err := tdToBytes(td)
+	if err != nil {
+		return err
+	}
+
+	b.offsets = append(b.offsets, b.written)
+	b.hashes = append(b.hashes, hash)
+	b.tds = append(b.tds, new(big.Int).Set(td))
+
+	for _, e := range []struct {
+		typ  uint16
+		data []byte
+	}{{TypeCompressedHeader, header}, {TypeCompressedBody, body}, {TypeCompressedReceipts, receipts}} {
+		if err := b.writeCompressed(e.typ, e.data); err != nil {
+			return err
+		}
+	}
+	n, err := b.w.Write(TypeTotalDifficulty, tdBytes[:])
+	if err != nil {
+		return err
+	}
+	b.written += uint64(n)
+	return nil
+}
+
+func (b *Builder) writeCompressed(typ uint16, data []byte) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if _, err := b.snappy.Write(data); err != nil {
+		return err
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return err
+	}
+	n, err := b.w.Write(typ, b.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	b.written += uint64(n)
+	return nil
+}
+
+// Finalize - writes accumulator and block index. Returns accumulator root: it's part of file name.
+func (b *Builder) Finalize() (libcommon.Hash, error) {
+	if len(b.offsets) == 0 {
+		return libcommon.Hash{}, fmt.Errorf("era1: no blocks added")
+	}
+	root, err := ComputeAccumulator(b.hashes, b.tds)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	n, err := b.w.Write(TypeAccumulator, root[:])
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	b.written += uint64(n)
+
+	// offsets are relative to beginning of BlockIndex entry
+	index := make([]byte, 16+8*len(b.offsets))
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(int64(offset)-int64(b.written)))
+	}
+	binary.LittleEndian.PutUint64(index[8+8*len(b.offsets):], uint64(len(b.offsets)))
+	if _, err := b.w.Write(TypeBlockIndex, index); err != nil {
+		return libcommon.Hash{}, err
+	}
+	return root, nil
+}
+
+// Era - read-only era1 file
+type Era struct {
+	f       *os.File
+	r       *e2Reader
+	start   uint64
+	offsets []int64
+}
+
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	e, err := open(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return e, nil
+}
+
+func open(f *os.File) (*Era, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	length := st.Size()
+	if length < e2HeaderSize+24 {
+		return nil, fmt.Errorf("era1: file too short: %d bytes", length)
+	}
+	e := &Era{f: f, r: newE2Reader(f)}
+	if _, _, err := e.r.ReadTypeAt(TypeVersion, 0); err != nil {
+		return nil, err
+	}
+
+	var num [8]byte
+	if _, err := f.ReadAt(num[:], length-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(num[:])
+	if count == 0 || count > MaxEraSize {
+		return nil, fmt.Errorf("era1: invalid blocks count in index: %d", count)
+	}
+	indexOffset := length - int64(e2HeaderSize+16+8*count)
+	index, _, err := e.r.ReadTypeAt(TypeBlockIndex, indexOffset)
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Value) != int(16+8*count) {
+		return nil, fmt.Errorf("era1: invalid block index size: %d", len(index.Value))
+	}
+	e.start = binary.LittleEndian.Uint64(index.Value)
+	e.offsets = make([]int64, count)
+	for i := range e.offsets {
+		e.offsets[i] = indexOffset + int64(binary.LittleEndian.Uint64(index.Value[8+i*8:]))
+		if e.offsets[i] <= 0 || e.offsets[i] >= indexOffset {
+			return nil, fmt.Errorf("era1: invalid offset of block %d: %d", e.start+uint64(i), e.offsets[i])
+		}
+	}
+	return e, nil
+}
+
+func (e *Era) Close() error { return e.f.Close() }
+
+// Start - first block number in file
+func (e *Era) Start() uint64 { return e.start }
+
+// Count - amount of blocks in file
+func (e *Era) Count() uint64 { return uint64(len(e.offsets)) }
+
+// GetRawByNumber - uncompressed rlp of header, body and receipts. td is total difficulty including the block.
+func (e *Era) GetRawByNumber(num uint64) (header, body, receipts []byte, td *big.Int, err error) {
+	if num < e.start || num >= e.start+e.Count() {
+		return nil, nil, nil, nil, fmt.Errorf("era1: block %d out of range [%d, %d)", num, e.start, e.start+e.Count())
+	}
+	off := e.offsets[num-e.start]
+	res := make([][]byte, 3)
+	for i, typ := range []uint16{TypeCompressedHeader, TypeCompressedBody, TypeCompressedReceipts} {
+		entry, size, err := e.r.ReadTypeAt(typ, off)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		off += size
+		if res[i], err = io.ReadAll(snappy.NewReader(bytes.NewReader(entry.Value))); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("era1: block %d, entry 0x%04x: %w", num, typ, err)
+		}
+	}
+	entry, _, err := e.r.ReadTypeAt(TypeTotalDifficulty, off)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if td, err = tdFromBytes(entry.Value); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return res[0], res[1], res[2], td, nil
+}
+
+// GetBlockByNumber - decoded block, receipts and total difficulty
+func (e *Era) GetBlockByNumber(num uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	headerRLP, bodyRLP, receiptsRLP, td, err := e.GetRawByNumber(num)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var header types.Header
+	if err := rlp.DecodeBytes(headerRLP, &header); err != nil {
+		return nil, nil, nil, fmt.Errorf("era1: header of block %d: %w", num, err)
+	}
+	var body types.Body
+	if err := rlp.DecodeBytes(bodyRLP, &body); err != nil {
+		return nil, nil, nil, fmt.Errorf("era1: body of block %d: %w", num, err)
+	}
+	var receipts types.Receipts
+	if err := rlp.DecodeBytes(receiptsRLP, &receipts); err != nil {
+		return nil, nil, nil, fmt.Errorf("era1: receipts of block %d: %w", num, err)
+	}
+	block := types.NewBlockFromStorage(header.Hash(), &header, body.Transactions, body.Uncles, body.Withdrawals)
+	return block, receipts, td, nil
+}
+
+// Accumulator - root stored in file. It's not re-computed: see ComputeAccumulator.
+func (e *Era) Accumulator() (libcommon.Hash, error) {
+	off := e.offsets[len(e.offsets)-1]
+	for _, typ := range []uint16{TypeCompressedHeader, TypeCompressedBody, TypeCompressedReceipts, TypeTotalDifficulty} {
+		_, size, err := e.r.ReadTypeAt(typ, off)
+		if err != nil {
+			return libcommon.Hash{}, err
+		}
+		off += size
+	}
+	entry, _, err := e.r.ReadTypeAt(TypeAccumulator, off)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	if len(entry.Value) != length32 {
+		return libcommon.Hash{}, fmt.Errorf("era1: invalid accumulator size: %d", len(entry.Value))
+	}
+	return libcommon.BytesToHash(entry.Value), nil
+}
+
+// Filename - `<network>-<epoch>-<short root>.era1`, for example: `mainnet-00000-5ec1ffb8.era1`
+func Filename(network string, epoch uint64, root libcommon.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x%s", network, epoch, root[:4], Ext)
+}
+
+// ParseFilename - returns network and epoch
+func ParseFilename(name string) (network string, epoch uint64, ok bool) {
+	name = filepath.Base(name)
+	if filepath.Ext(name) != Ext {
+		return "", 0, false
+	}
+	parts := strings.Split(strings.TrimSuffix(name, Ext), "-")
+	if len(parts) < 3 {
+		return "", 0, false
+	}
+	epoch, err := strconv.ParseUint(parts[len(parts)-2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.Join(parts[:len(parts)-2], "-"), epoch, true
+}
+
+// ReadDir - era1 files of given network in dir, sorted by epoch. Epochs must be consecutive.
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		name  string
+		epoch uint64
+	}
+	var files []file
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		net, epoch, ok := ParseFilename(entry.Name())
+		if !ok || net != network {
+			continue
+		}
+		files = append(files, file{name: filepath.Join(dir, entry.Name()), epoch: epoch})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].epoch < files[j].epoch })
+	res := make([]string, 0, len(files))
+	for i, f := range files {
+		if i > 0 && f.epoch != files[i-1].epoch+1 {
+			if f.epoch == files[i-1].epoch {
+				return nil, fmt.Errorf("era1: duplicated epoch %d in %s", f.epoch, dir)
+			}
+			return nil, fmt.Errorf("era1: missing epochs %d-%d in %s", files[i-1].epoch+1, f.epoch-1, dir)
+		}
+		res = append(res, f.name)
+	}
+	return res, nil
+}
@@ -0,0 +1,135 @@
+package era1
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/types"
+)
+
+func testBlocks(from, count uint64) ([]*types.Block, []types.Receipts, []*big.Int) {
+	var blocks []*types.Block
+	var receipts []types.Receipts
+	var tds []*big.Int
+	td := big.NewInt(int64(from) * 1000)
+	parent := libcommon.Hash{}
+	for i := from; i < from+count; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(1000), ParentHash: parent, GasLimit: 8_000_000}
+		var txs []types.Transaction
+		var rs types.Receipts
+		if i%2 == 0 {
+			to := libcommon.Address{1}
+			txs = append(txs,
+				types.NewTransaction(i, to, uint256.NewInt(1), 21000, uint256.NewInt(1), nil),
+				types.NewEIP1559Transaction(*uint256.NewInt(1), i, to, uint256.NewInt(2), 21000, uint256.NewInt(2), uint256.NewInt(1), uint256.NewInt(2), []byte{1, 2}))
+			r1 := types.NewReceipt(false, 21000)
+			r1.Logs = []*types.Log{{Address: to, Topics: []libcommon.Hash{{2}}, Data: []byte{3}}}
+			r1.Bloom = types.CreateBloom(types.Receipts{r1})
+			r2 := types.NewReceipt(true, 42000)
+			r2.Type = types.DynamicFeeTxType
+			r2.Logs = []*types.Log{}
+			rs = types.Receipts{r1, r2}
+		}
+		block := types.NewBlock(header, txs, nil, rs, nil)
+		td = new(big.Int).Add(td, header.Difficulty)
+		blocks, receipts, tds = append(blocks, block), append(receipts, rs), append(tds, td)
+		parent = block.Hash()
+	}
+	return blocks, receipts, tds
+}
+
+func writeEra(t *testing.T, dir string, epoch uint64, blocks []*types.Block, receipts []types.Receipts, tds []*big.Int) string {
+	t.Helper()
+	tmp := filepath.Join(dir, "tmp")
+	f, err := os.Create(tmp)
+	require.NoError(t, err)
+	b := NewBuilder(f)
+	for i, block := range blocks {
+		require.NoError(t, b.Add(block, receipts[i], tds[i]))
+	}
+	root, err := b.Finalize()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	path := filepath.Join(dir, Filename("testnet", epoch, root))
+	require.NoError(t, os.Rename(tmp, path))
+	return path
+}
+
+func TestEraRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	blocks, receipts, tds := testBlocks(MaxEraSize, 10)
+	path := writeEra(t, dir, 1, blocks, receipts, tds)
+
+	e, err := Open(path)
+	require.NoError(t, err)
+	defer e.Close()
+	require.Equal(t, uint64(MaxEraSize), e.Start())
+	require.Equal(t, uint64(10), e.Count())
+
+	hashes := make([]libcommon.Hash, len(blocks))
+	for i, expected := range blocks {
+		block, rs, td, err := e.GetBlockByNumber(expected.NumberU64())
+		require.NoError(t, err)
+		require.Equal(t, expected.Hash(), block.Hash())
+		require.Equal(t, expected.TxHash(), types.DeriveSha(block.Transactions()))
+		require.Equal(t, expected.ReceiptHash(), types.DeriveSha(rs))
+		require.Equal(t, tds[i], td)
+		hashes[i] = block.Hash()
+	}
+	_, _, _, err = e.GetBlockByNumber(MaxEraSize + 10)
+	require.Error(t, err)
+
+	root, err := e.Accumulator()
+	require.NoError(t, err)
+	expected, err := ComputeAccumulator(hashes, tds)
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+	require.Equal(t, Filename("testnet", 1, root), filepath.Base(path))
+}
+
+func TestAccumulator(t *testing.T) {
+	hashes := []libcommon.Hash{{1}, {2}}
+	tds := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	root, err := ComputeAccumulator(hashes, tds)
+	require.NoError(t, err)
+
+	// any change of record or amount of records changes root
+	other, err := ComputeAccumulator(hashes, []*big.Int{big.NewInt(1), big.NewInt(3)})
+	require.NoError(t, err)
+	require.NotEqual(t, root, other)
+	other, err = ComputeAccumulator(hashes[:1], tds[:1])
+	require.NoError(t, err)
+	require.NotEqual(t, root, other)
+
+	_, err = ComputeAccumulator(hashes, tds[:1])
+	require.Error(t, err)
+	_, err = ComputeAccumulator(hashes, []*big.Int{big.NewInt(1), big.NewInt(-1)})
+	require.Error(t, err)
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"testnet-00001-bbbbbbbb.era1", "testnet-00000-aaaaaaaa.era1", "mainnet-00000-cccccccc.era1", "testnet-00002-dddddddd.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	files, err := ReadDir(dir, "testnet")
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "testnet-00000-aaaaaaaa.era1"), filepath.Join(dir, "testnet-00001-bbbbbbbb.era1")}, files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "testnet-00003-eeeeeeee.era1"), nil, 0644))
+	_, err = ReadDir(dir, "testnet")
+	require.ErrorContains(t, err, "missing epochs 2-2")
+
+	network, epoch, ok := ParseFilename("bor-mainnet-00012-01020304.era1")
+	require.True(t, ok)
+	require.Equal(t, "bor-mainnet", network)
+	require.Equal(t, uint64(12), epoch)
+}
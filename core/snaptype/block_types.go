@@ -26,37 +26,44 @@ import (
 )
 
 func init() {
-	ethereumTypes := append(BlockSnapshotTypes, snaptype.CaplinSnapshotTypes...)
+	ethereumTypes := append(append(BlockSnapshotTypes, ReceiptSnapshotTypes...), snaptype.CaplinSnapshotTypes...)
 
 	snapcfg.RegisterKnownTypes(networkname.MainnetChainName, ethereumTypes)
 	snapcfg.RegisterKnownTypes(networkname.SepoliaChainName, ethereumTypes)
 	snapcfg.RegisterKnownTypes(networkname.GoerliChainName, ethereumTypes)
 	snapcfg.RegisterKnownTypes(networkname.GnosisChainName, ethereumTypes)
 	snapcfg.RegisterKnownTypes(networkname.ChiadoChainName, ethereumTypes)
+
+	// transactions.idx: first txn id is taken from bodies.seg
+	snaptype.RegisterIndexDeps(Enums.Transactions, Enums.Bodies)
 }
 
 var Enums = struct {
 	snaptype.Enums
 	Headers,
 	Bodies,
-	Transactions snaptype.Enum
+	Transactions,
+	Receipts snaptype.Enum
 }{
 	Enums:        snaptype.Enums{},
 	Headers:      snaptype.MinCoreEnum,
 	Bodies:       snaptype.MinCoreEnum + 1,
 	Transactions: snaptype.MinCoreEnum + 2,
+	Receipts:     snaptype.MinCoreExtEnum,
 }
 
 var Indexes = struct {
 	HeaderHash,
 	BodyHash,
 	TxnHash,
-	TxnHash2BlockNum snaptype.Index
+	TxnHash2BlockNum,
+	ReceiptsBlockNum snaptype.Index
 }{
 	HeaderHash:       snaptype.Index{Name: "headers"},
 	BodyHash:         snaptype.Index{Name: "bodies"},
 	TxnHash:          snaptype.Index{Name: "transactions"},
 	TxnHash2BlockNum: snaptype.Index{Name: "transactions-to-block", Offset: 1},
+	ReceiptsBlockNum: snaptype.Index{Name: "receipts"},
 }
 
 var (
@@ -294,7 +301,40 @@ var (
 			}),
 	)
 
+	// Receipts - optional, see ethconfig.BlocksFreezing.Receipts
+	Receipts = snaptype.RegisterType(
+		Enums.Receipts,
+		"receipts",
+		snaptype.Versions{
+			Current:      1,
+			MinSupported: 1,
+		},
+		nil,
+		[]snaptype.Index{Indexes.ReceiptsBlockNum},
+		snaptype.IndexBuilderFunc(
+			func(ctx context.Context, info snaptype.FileInfo, salt uint32, _ *chain.Config, tmpDir string, p *background.Progress, lvl log.Lvl, logger log.Logger) (err error) {
+				num := make([]byte, 8)
+
+				if err := snaptype.BuildIndex(ctx, info, salt, info.From, tmpDir, log.LvlDebug, p, func(idx *recsplit.RecSplit, i, offset uint64, _ []byte) error {
+					if p != nil {
+						p.Processed.Add(1)
+					}
+					n := binary.PutUvarint(num, i)
+					if err := idx.AddKey(num[:n], offset); err != nil {
+						return err
+					}
+					return nil
+				}, logger); err != nil {
+					return fmt.Errorf("can't index %s: %w", info.Name(), err)
+				}
+				return nil
+			}),
+	)
+
 	BlockSnapshotTypes = []snaptype.Type{Headers, Bodies, Transactions}
+
+	// ReceiptSnapshotTypes - not part of BlockSnapshotTypes: blocks are available without them
+	ReceiptSnapshotTypes = []snaptype.Type{Receipts}
 )
 
 func txsAmountBasedOnBodiesSnapshots(bodiesSegment *seg.Decompressor, len uint64) (firstTxID uint64, expectedCount int, err error) {
@@ -20,6 +20,10 @@ func TestEnumeration(t *testing.T) {
 		t.Fatal("enum mismatch", snaptype.Transactions, snaptype.Transactions.Enum(), snaptype.Enums.Transactions)
 	}
 
+	if snaptype.Receipts.Enum() != snaptype.Enums.Receipts {
+		t.Fatal("enum mismatch", snaptype.Receipts, snaptype.Receipts.Enum(), snaptype.Enums.Receipts)
+	}
+
 }
 
 func TestNames(t *testing.T) {
@@ -35,4 +39,8 @@ func TestNames(t *testing.T) {
 	if snaptype.Transactions.Name() != snaptype.Enums.Transactions.String() {
 		t.Fatal("name mismatch", snaptype.Transactions, snaptype.Transactions.Name(), snaptype.Enums.Transactions.String())
 	}
+
+	if snaptype.Receipts.Name() != snaptype.Enums.Receipts.String() {
+		t.Fatal("name mismatch", snaptype.Receipts, snaptype.Receipts.Name(), snaptype.Enums.Receipts.String())
+	}
 }
@@ -290,47 +290,50 @@ func (rs *StateV3) Unwind(ctx context.Context, tx kv.RwTx, blockUnwindTo, txUnwi
 		}
 		return nil
 	}
-	stateChanges := etl.NewCollector("", "", etl.NewOldestEntryBuffer(etl.BufferOptimalSize), rs.logger)
-	defer stateChanges.Close()
-	stateChanges.SortAndFlushInBackground(true)
+	// history is walked only to notify subscribers. Domains are rolled back to txUnwindTo in bulk: unwind cost doesn't depend on amount of blocks
+	if accumulator != nil {
+		stateChanges := etl.NewCollector("", "", etl.NewOldestEntryBuffer(etl.BufferOptimalSize), rs.logger)
+		defer stateChanges.Close()
+		stateChanges.SortAndFlushInBackground(true)
 
-	ttx := tx.(kv.TemporalTx)
+		ttx := tx.(kv.TemporalTx)
 
-	{
-		iter, err := ttx.HistoryRange(kv.AccountsHistory, int(txUnwindTo), -1, order.Asc, -1)
-		if err != nil {
-			return err
-		}
-		defer iter.Close()
-		for iter.HasNext() {
-			k, v, err := iter.Next()
+		{
+			iter, err := ttx.HistoryRange(kv.AccountsHistory, int(txUnwindTo), -1, order.Asc, -1)
 			if err != nil {
 				return err
 			}
-			if err := stateChanges.Collect(k, v); err != nil {
-				return err
+			defer iter.Close()
+			for iter.HasNext() {
+				k, v, err := iter.Next()
+				if err != nil {
+					return err
+				}
+				if err := stateChanges.Collect(k, v); err != nil {
+					return err
+				}
 			}
 		}
-	}
-	{
-		iter, err := ttx.HistoryRange(kv.StorageHistory, int(txUnwindTo), -1, order.Asc, -1)
-		if err != nil {
-			return err
-		}
-		defer iter.Close()
-		for iter.HasNext() {
-			k, v, err := iter.Next()
+		{
+			iter, err := ttx.HistoryRange(kv.StorageHistory, int(txUnwindTo), -1, order.Asc, -1)
 			if err != nil {
 				return err
 			}
-			if err := stateChanges.Collect(k, v); err != nil {
-				return err
+			defer iter.Close()
+			for iter.HasNext() {
+				k, v, err := iter.Next()
+				if err != nil {
+					return err
+				}
+				if err := stateChanges.Collect(k, v); err != nil {
+					return err
+				}
 			}
 		}
-	}
 
-	if err := stateChanges.Load(tx, "", handle, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
-		return err
+		if err := stateChanges.Load(tx, "", handle, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
+			return err
+		}
 	}
 	if err := rs.domains.Unwind(ctx, tx, blockUnwindTo, txUnwindTo); err != nil {
 		return err
@@ -350,7 +353,7 @@ func (rs *StateV3) SizeEstimate() (r uint64) {
 	return r
 }
 
-func (rs *StateV3) ReadsValid(readLists map[string]*libstate.KvList) bool {
+func (rs *StateV3) ReadsValid(readLists map[string]*libstate.KvList) (bool, error) {
 	return rs.domains.ReadsValid(readLists)
 }
 
@@ -667,6 +670,130 @@ func (r *StateReaderV3) ReadAccountIncarnation(address common.Address) (uint64,
 	return 0, nil
 }
 
+// StateReaderParallelV3 - used by speculative parallel workers: reads not-flushed updates of shared domains and then own RoTx.
+// Values may be stale (RoTx doesn't see not-committed updates): results of execution can be used only after check of
+// read set by `SharedDomains.LatestReadsValid`.
+type StateReaderParallelV3 struct {
+	sd        *libstate.SharedDomains
+	tx        kv.Tx
+	composite []byte
+
+	readLists map[string]*libstate.KvList
+}
+
+func NewStateReaderParallelV3(sd *libstate.SharedDomains) *StateReaderParallelV3 {
+	return &StateReaderParallelV3{
+		sd:        sd,
+		readLists: newReadList(),
+		composite: make([]byte, 20+32),
+	}
+}
+
+func (r *StateReaderParallelV3) SetTx(tx kv.Tx)                       { r.tx = tx }
+func (r *StateReaderParallelV3) ReadSet() map[string]*libstate.KvList { return r.readLists }
+func (r *StateReaderParallelV3) ResetReadSet()                        { r.readLists = newReadList() }
+
+func (r *StateReaderParallelV3) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	enc, err := r.sd.DomainGetWithTx(kv.AccountsDomain, address[:], r.tx)
+	if err != nil {
+		return nil, err
+	}
+	r.readLists[kv.AccountsDomain.String()].Push(string(address[:]), enc)
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	var acc accounts.Account
+	if err := accounts.DeserialiseV3(&acc, enc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+func (r *StateReaderParallelV3) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.composite = append(append(r.composite[:0], address[:]...), key.Bytes()...)
+	enc, err := r.sd.DomainGetWithTx(kv.StorageDomain, r.composite, r.tx)
+	if err != nil {
+		return nil, err
+	}
+	r.readLists[kv.StorageDomain.String()].Push(string(r.composite), enc)
+	return enc, nil
+}
+
+func (r *StateReaderParallelV3) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	enc, err := r.sd.DomainGetWithTx(kv.CodeDomain, address[:], r.tx)
+	if err != nil {
+		return nil, err
+	}
+	r.readLists[kv.CodeDomain.String()].Push(string(address[:]), enc)
+	return enc, nil
+}
+
+func (r *StateReaderParallelV3) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	enc, err := r.sd.DomainGetWithTx(kv.CodeDomain, address[:], r.tx)
+	if err != nil {
+		return 0, err
+	}
+	var sizebuf [8]byte
+	binary.BigEndian.PutUint64(sizebuf[:], uint64(len(enc)))
+	r.readLists[libstate.CodeSizeTableFake].Push(string(address[:]), sizebuf[:])
+	return len(enc), nil
+}
+
+func (r *StateReaderParallelV3) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return 0, nil
+}
+
+// StateWriterRecorderV3 - used by speculative parallel workers: records updates without applying them.
+// If tx has no conflicts - updates are replayed on StateWriterV3 in same order as they were done.
+type StateWriterRecorderV3 struct {
+	updates []func(w StateWriter) error
+}
+
+func NewStateWriterRecorderV3() *StateWriterRecorderV3 { return &StateWriterRecorderV3{} }
+
+func (w *StateWriterRecorderV3) Reset() { w.updates = nil }
+
+// Replay - applies recorded updates to `to`
+func (w *StateWriterRecorderV3) Replay(to StateWriter) error {
+	for _, update := range w.updates {
+		if err := update(to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *StateWriterRecorderV3) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	var o, a accounts.Account
+	o.Copy(original)
+	a.Copy(account)
+	w.updates = append(w.updates, func(to StateWriter) error { return to.UpdateAccountData(address, &o, &a) })
+	return nil
+}
+
+func (w *StateWriterRecorderV3) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	w.updates = append(w.updates, func(to StateWriter) error { return to.UpdateAccountCode(address, incarnation, codeHash, code) })
+	return nil
+}
+
+func (w *StateWriterRecorderV3) DeleteAccount(address common.Address, original *accounts.Account) error {
+	var o accounts.Account
+	o.Copy(original)
+	w.updates = append(w.updates, func(to StateWriter) error { return to.DeleteAccount(address, &o) })
+	return nil
+}
+
+func (w *StateWriterRecorderV3) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	k, o, v := *key, *original, *value
+	w.updates = append(w.updates, func(to StateWriter) error { return to.WriteAccountStorage(address, incarnation, &k, &o, &v) })
+	return nil
+}
+
+func (w *StateWriterRecorderV3) CreateContract(address common.Address) error {
+	w.updates = append(w.updates, func(to StateWriter) error { return to.CreateContract(address) })
+	return nil
+}
+
 var writeListPool = sync.Pool{
 	New: func() any {
 		return map[string]*libstate.KvList{
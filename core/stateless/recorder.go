@@ -0,0 +1,128 @@
+package stateless
+
+import (
+	"bytes"
+	"sort"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+)
+
+// Recorder is a state.StateReader which records the state read through it,
+// that is the state a witness of the execution has to prove.
+type Recorder struct {
+	reader       state.StateReader
+	incarnations map[libcommon.Address]uint64
+	storage      map[libcommon.Address]map[libcommon.Hash]struct{}
+	codes        map[libcommon.Hash][]byte
+}
+
+func NewRecorder(reader state.StateReader) *Recorder {
+	return &Recorder{
+		reader:       reader,
+		incarnations: make(map[libcommon.Address]uint64),
+		storage:      make(map[libcommon.Address]map[libcommon.Hash]struct{}),
+		codes:        make(map[libcommon.Hash][]byte),
+	}
+}
+
+func (r *Recorder) touch(address libcommon.Address, account *accounts.Account) {
+	if _, ok := r.incarnations[address]; ok {
+		return
+	}
+	var incarnation uint64
+	if account != nil {
+		incarnation = account.Incarnation
+	}
+	r.incarnations[address] = incarnation
+}
+
+func (r *Recorder) ReadAccountData(address libcommon.Address) (*accounts.Account, error) {
+	account, err := r.reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	r.touch(address, account)
+	return account, nil
+}
+
+func (r *Recorder) ReadAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash) ([]byte, error) {
+	slots, ok := r.storage[address]
+	if !ok {
+		slots = make(map[libcommon.Hash]struct{})
+		r.storage[address] = slots
+	}
+	slots[*key] = struct{}{}
+	return r.reader.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *Recorder) ReadAccountCode(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash) ([]byte, error) {
+	code, err := r.reader.ReadAccountCode(address, incarnation, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) > 0 {
+		r.codes[codeHash] = code
+	}
+	return code, nil
+}
+
+// ReadAccountCodeSize records the whole code, since a stateless client can
+// only tell its size from the code itself.
+func (r *Recorder) ReadAccountCodeSize(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash) (int, error) {
+	code, err := r.ReadAccountCode(address, incarnation, codeHash)
+	if err != nil {
+		return 0, err
+	}
+	return len(code), nil
+}
+
+func (r *Recorder) ReadAccountIncarnation(address libcommon.Address) (uint64, error) {
+	return r.reader.ReadAccountIncarnation(address)
+}
+
+// Accounts returns the addresses of the accounts read, in ascending order.
+func (r *Recorder) Accounts() []libcommon.Address {
+	addresses := make([]libcommon.Address, 0, len(r.incarnations)+len(r.storage))
+	for address := range r.incarnations {
+		addresses = append(addresses, address)
+	}
+	for address := range r.storage {
+		if _, ok := r.incarnations[address]; !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool { return bytes.Compare(addresses[i][:], addresses[j][:]) < 0 })
+	return addresses
+}
+
+// Incarnation returns the incarnation the account had when it was first read.
+func (r *Recorder) Incarnation(address libcommon.Address) uint64 {
+	return r.incarnations[address]
+}
+
+// Storage returns the storage keys of the account read, in ascending order.
+func (r *Recorder) Storage(address libcommon.Address) []libcommon.Hash {
+	keys := make([]libcommon.Hash, 0, len(r.storage[address]))
+	for key := range r.storage[address] {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	return keys
+}
+
+// Codes returns the bytecodes read, ordered by their hashes.
+func (r *Recorder) Codes() [][]byte {
+	hashes := make([]libcommon.Hash, 0, len(r.codes))
+	for hash := range r.codes {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	codes := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = r.codes[hash]
+	}
+	return codes
+}
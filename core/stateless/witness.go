@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateless contains the execution witness of a block: everything a
+// stateless client needs, besides the block itself, to execute it and to
+// verify the resulting state root.
+package stateless
+
+import (
+	"errors"
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// Witness encompasses the state required to apply a set of transactions and
+// derive a post state/receipt root.
+type Witness struct {
+	Headers []*types.Header // Past headers in reverse order (0=parent, 1=parent's-parent, etc). First *must* be set.
+	Codes   [][]byte        // Set of bytecodes ran or accessed
+	State   [][]byte        // Set of MPT state trie nodes (account and storage together)
+}
+
+// Root returns the pre-state root, the one of the parent block.
+func (w *Witness) Root() libcommon.Hash {
+	return w.Headers[0].Root
+}
+
+// Validate performs the checks which do not need the block to be executed: the
+// headers have to form a chain ending with the parent of the block, and the
+// state has to contain the pre-state root node.
+func (w *Witness) Validate(block *types.Header) error {
+	if len(w.Headers) == 0 {
+		return errors.New("no headers in witness")
+	}
+	if hash := w.Headers[0].Hash(); hash != block.ParentHash {
+		return fmt.Errorf("witness parent %x does not match block parent %x", hash, block.ParentHash)
+	}
+	for i := 1; i < len(w.Headers); i++ {
+		if hash := w.Headers[i].Hash(); hash != w.Headers[i-1].ParentHash {
+			return fmt.Errorf("witness header %d (%x) is not the parent of header %d", i, hash, i-1)
+		}
+	}
+	root := w.Root()
+	if root == types.EmptyRootHash {
+		return nil
+	}
+	for _, node := range w.State {
+		if crypto.Keccak256Hash(node) == root {
+			return nil
+		}
+	}
+	return fmt.Errorf("witness does not contain the pre-state root node %x", root)
+}
@@ -0,0 +1,93 @@
+package stateless
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+func TestWitnessValidate(t *testing.T) {
+	rootNode := []byte{0xc2, 0x80, 0x80}
+	grandparent := &types.Header{Number: big.NewInt(1), Root: types.EmptyRootHash}
+	parent := &types.Header{Number: big.NewInt(2), ParentHash: grandparent.Hash(), Root: crypto.Keccak256Hash(rootNode)}
+	block := &types.Header{Number: big.NewInt(3), ParentHash: parent.Hash()}
+
+	w := &Witness{Headers: []*types.Header{parent, grandparent}, State: [][]byte{rootNode}}
+	require.NoError(t, w.Validate(block))
+	require.Equal(t, parent.Root, w.Root())
+
+	require.Error(t, (&Witness{}).Validate(block))
+	require.Error(t, (&Witness{Headers: []*types.Header{grandparent}}).Validate(block))
+	require.Error(t, (&Witness{Headers: []*types.Header{parent, parent}, State: [][]byte{rootNode}}).Validate(block))
+	require.Error(t, (&Witness{Headers: []*types.Header{parent}}).Validate(block))
+
+	empty := &types.Header{Number: big.NewInt(3), ParentHash: grandparent.Hash()}
+	require.NoError(t, (&Witness{Headers: []*types.Header{grandparent}}).Validate(empty))
+}
+
+type testReader struct {
+	accounts map[libcommon.Address]*accounts.Account
+	code     []byte
+}
+
+func (r *testReader) ReadAccountData(address libcommon.Address) (*accounts.Account, error) {
+	return r.accounts[address], nil
+}
+
+func (r *testReader) ReadAccountStorage(libcommon.Address, uint64, *libcommon.Hash) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (r *testReader) ReadAccountCode(libcommon.Address, uint64, libcommon.Hash) ([]byte, error) {
+	return r.code, nil
+}
+
+func (r *testReader) ReadAccountCodeSize(libcommon.Address, uint64, libcommon.Hash) (int, error) {
+	return len(r.code), nil
+}
+
+func (r *testReader) ReadAccountIncarnation(libcommon.Address) (uint64, error) {
+	return 0, nil
+}
+
+func TestRecorder(t *testing.T) {
+	contract, eoa, missing := libcommon.Address{3}, libcommon.Address{2}, libcommon.Address{1}
+	reader := &testReader{
+		accounts: map[libcommon.Address]*accounts.Account{
+			contract: {Incarnation: 2, Balance: *uint256.NewInt(1)},
+			eoa:      {Nonce: 1},
+		},
+		code: []byte{0x60, 0x00},
+	}
+	r := NewRecorder(reader)
+
+	_, err := r.ReadAccountData(contract)
+	require.NoError(t, err)
+	_, err = r.ReadAccountData(eoa)
+	require.NoError(t, err)
+	_, err = r.ReadAccountData(missing)
+	require.NoError(t, err)
+	keys := []libcommon.Hash{{2}, {1}, {2}}
+	for i := range keys {
+		_, err = r.ReadAccountStorage(contract, 2, &keys[i])
+		require.NoError(t, err)
+	}
+	size, err := r.ReadAccountCodeSize(contract, 2, crypto.Keccak256Hash(reader.code))
+	require.NoError(t, err)
+	require.Equal(t, len(reader.code), size)
+
+	require.Equal(t, []libcommon.Address{missing, eoa, contract}, r.Accounts())
+	require.Equal(t, uint64(2), r.Incarnation(contract))
+	require.Equal(t, uint64(0), r.Incarnation(missing))
+	require.Equal(t, []libcommon.Hash{{1}, {2}}, r.Storage(contract))
+	require.Empty(t, r.Storage(eoa))
+	require.Equal(t, [][]byte{reader.code}, r.Codes())
+}
@@ -0,0 +1,82 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	diaglib "github.com/ledgerwatch/erigon-lib/diagnostics"
+)
+
+const (
+	liveDefaultInterval = time.Second
+	liveMinInterval     = 100 * time.Millisecond
+)
+
+// SetupLiveAccess - /live streams diaglib.LiveMessage as newline delimited json until the client disconnects,
+// or for `duration` if given. Samples are taken every `interval` (default 1s).
+func SetupLiveAccess(metricsMux *http.ServeMux, diag *diaglib.DiagnosticClient) {
+	if metricsMux == nil {
+		return
+	}
+
+	metricsMux.HandleFunc("/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		writeLive(w, r, diag)
+	})
+}
+
+func writeLive(w http.ResponseWriter, r *http.Request, diag *diaglib.DiagnosticClient) {
+	interval, err := parseLiveDuration(r.URL.Query().Get("interval"), liveDefaultInterval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if interval < liveMinInterval {
+		interval = liveMinInterval
+	}
+
+	duration, err := parseLiveDuration(r.URL.Query().Get("duration"), 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	diag.StreamLive(ctx, interval, func(msg diaglib.LiveMessage) error { //nolint:errcheck
+		if err := encoder.Encode(msg); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+func parseLiveDuration(s string, defaultValue time.Duration) (time.Duration, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	return d, nil
+}
@@ -18,6 +18,18 @@ func SetupStagesAccess(metricsMux *http.ServeMux, diag *diaglib.DiagnosticClient
 		writeStages(w, diag)
 	})
 
+	metricsMux.HandleFunc("/stages-progress", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+		writeStagesProgress(w, diag)
+	})
+
+	metricsMux.HandleFunc("/stages-throttle", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+		writeStagesThrottle(w, diag)
+	})
+
 	metricsMux.HandleFunc("/snapshot-files-list", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/json")
@@ -62,3 +74,11 @@ func writeFilesList(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
 func writeHardwareInfo(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
 	json.NewEncoder(w).Encode(diag.HardwareInfo())
 }
+
+func writeStagesProgress(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
+	json.NewEncoder(w).Encode(diag.StagesProgress())
+}
+
+func writeStagesThrottle(w http.ResponseWriter, diag *diaglib.DiagnosticClient) {
+	json.NewEncoder(w).Encode(diag.StagesThrottle())
+}
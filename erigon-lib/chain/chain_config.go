@@ -89,6 +89,9 @@ type Config struct {
 
 	Bor     BorConfig       `json:"-"`
 	BorJSON json.RawMessage `json:"bor,omitempty"`
+
+	// (Optional) parameters of custom consensus registered under Consensus name, passed to it as is
+	ConsensusParams json.RawMessage `json:"consensusParams,omitempty"`
 }
 
 type BorConfig interface {
@@ -137,6 +140,8 @@ func (c *Config) getEngine() string {
 		return c.Bor.String()
 	case c.Aura != nil:
 		return c.Aura.String()
+	case c.Consensus != "":
+		return string(c.Consensus)
 	default:
 		return "unknown"
 	}
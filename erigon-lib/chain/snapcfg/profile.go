@@ -0,0 +1,182 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package snapcfg
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+)
+
+// DownloadProfile - which files of preverified list node downloads. Default (archive) - everything.
+type DownloadProfile struct {
+	Name string
+	// History - download `history/*.v` and `idx/*.ef` files: historical state (eth_getBalance at old block, traces, ...).
+	// Without them node has only latest state (`domain/*.kv`).
+	History bool
+	// RecentBlocks - 0: all block files. Otherwise: only block files (headers, bodies, transactions, bor events/spans)
+	// which cover last RecentBlocks blocks. Older blocks are not available - block snapshots start from ProfileMinBlock.
+	RecentBlocks uint64
+}
+
+var (
+	ArchiveProfile = DownloadProfile{Name: "archive", History: true}
+	FullProfile    = DownloadProfile{Name: "full"}
+	MinimalProfile = DownloadProfile{Name: "minimal", RecentBlocks: 100_000}
+
+	DownloadProfiles = []DownloadProfile{ArchiveProfile, FullProfile, MinimalProfile}
+)
+
+// ParseDownloadProfile - empty name is ArchiveProfile
+func ParseDownloadProfile(name string) (DownloadProfile, error) {
+	if name == "" {
+		return ArchiveProfile, nil
+	}
+	var names []string
+	for _, p := range DownloadProfiles {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return DownloadProfile{}, fmt.Errorf("unknown download profile %q, available: %s", name, strings.Join(names, ", "))
+}
+
+func (p DownloadProfile) String() string { return p.Name }
+
+func isHistoryFile(name string) bool {
+	return strings.HasPrefix(name, "history") || strings.HasPrefix(name, "idx")
+}
+
+type profileBlockFile struct {
+	typeName string
+	from, to uint64
+}
+
+// parseProfileBlockFile - `v1-000000-000500-bodies.seg`. Types are registered outside of erigon-lib - parse by name.
+// Caplin files are numbered by slots, not blocks: they are controlled by caplin flags, not by profile
+func parseProfileBlockFile(name string) (res profileBlockFile, ok bool) {
+	if filepath.Ext(name) != ".seg" {
+		return res, false
+	}
+	parts := strings.Split(strings.TrimSuffix(name, ".seg"), "-")
+	if len(parts) != 4 {
+		return res, false
+	}
+	for _, t := range snaptype.CaplinSnapshotTypes {
+		if parts[3] == t.Name() {
+			return res, false
+		}
+	}
+	from, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return res, false
+	}
+	to, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return res, false
+	}
+	return profileBlockFile{typeName: parts[3], from: from * 1_000, to: to * 1_000}, true
+}
+
+// ProfileMinBlock - first block available in block snapshots downloaded with given profile. 0 - all blocks.
+// It's `From` of some file which exists for all block types: block snapshots must have no gaps starting from this block.
+func (p Preverified) ProfileMinBlock(profile DownloadProfile) uint64 {
+	if profile.RecentBlocks == 0 {
+		return 0
+	}
+	froms := map[string]map[uint64]struct{}{}
+	var maxTo uint64
+	for _, item := range p {
+		f, ok := parseProfileBlockFile(item.Name)
+		if !ok {
+			continue
+		}
+		if froms[f.typeName] == nil {
+			froms[f.typeName] = map[uint64]struct{}{}
+		}
+		froms[f.typeName][f.from] = struct{}{}
+		if f.to > maxTo {
+			maxTo = f.to
+		}
+	}
+	if maxTo <= profile.RecentBlocks {
+		return 0
+	}
+	minBlock := maxTo - profile.RecentBlocks
+
+	// candidate must exist in all types - enough to check `from`-s of any one type
+	var anyType map[uint64]struct{}
+	for _, typeFroms := range froms {
+		anyType = typeFroms
+		break
+	}
+	var res uint64
+Candidates:
+	for from := range anyType {
+		if from > minBlock || from <= res {
+			continue
+		}
+		for _, other := range froms {
+			if _, ok := other[from]; !ok {
+				continue Candidates
+			}
+		}
+		res = from
+	}
+	return res
+}
+
+// Profiled - files to download with given profile
+func (p Preverified) Profiled(profile DownloadProfile) Preverified {
+	minBlock := p.ProfileMinBlock(profile)
+	var res Preverified
+	for _, item := range p {
+		if !profile.History && isHistoryFile(item.Name) {
+			continue
+		}
+		if f, ok := parseProfileBlockFile(item.Name); ok && f.from < minBlock {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+// expirableTypes - block files which are not downloaded for blocks before history expiry block (EIP-4444). Headers and
+// bodies files are kept: they are small and number transactions of all blocks
+var expirableTypes = []string{"transactions", "receipts"}
+
+// WithoutExpired - files to download with history expiry: transactions and receipts files of blocks before expiryBlock
+// are skipped. 0 - no expiry
+func (p Preverified) WithoutExpired(expiryBlock uint64) Preverified {
+	if expiryBlock == 0 {
+		return p
+	}
+	var res Preverified
+	for _, item := range p {
+		if f, ok := parseProfileBlockFile(item.Name); ok && f.to <= expiryBlock && slices.Contains(expirableTypes, f.typeName) {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+package snapcfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadProfiles(t *testing.T) {
+	p := Preverified{
+		{Name: "domain/v1-accounts.0-1024.kv"},
+		{Name: "history/v1-accounts.0-1024.v"},
+		{Name: "idx/v1-accounts.0-1024.ef"},
+		{Name: "v1-000000-000500-beaconblocks.seg"},
+		{Name: "v1-000000-000500-bodies.seg"},
+		{Name: "v1-000000-000500-headers.seg"},
+		{Name: "v1-000000-000500-transactions.seg"},
+		{Name: "v1-000500-000600-bodies.seg"},
+		{Name: "v1-000500-000600-headers.seg"},
+		{Name: "v1-000500-000600-transactions.seg"},
+		{Name: "v1-000600-000610-bodies.seg"},
+		{Name: "v1-000600-000610-headers.seg"},
+		{Name: "v1-000600-000610-transactions.seg"},
+	}
+	names := func(p Preverified) (res []string) {
+		for _, item := range p {
+			res = append(res, item.Name)
+		}
+		return res
+	}
+
+	_, err := ParseDownloadProfile("unknown")
+	require.Error(t, err)
+	archive, err := ParseDownloadProfile("")
+	require.NoError(t, err)
+	require.Equal(t, ArchiveProfile, archive)
+	require.Equal(t, p, p.Profiled(archive))
+	require.Zero(t, p.ProfileMinBlock(archive))
+
+	full := p.Profiled(FullProfile)
+	require.Equal(t, len(p)-2, len(full))
+	require.NotContains(t, names(full), "history/v1-accounts.0-1024.v")
+	require.NotContains(t, names(full), "idx/v1-accounts.0-1024.ef")
+	require.Contains(t, names(full), "domain/v1-accounts.0-1024.kv")
+
+	// last 100K blocks are in [500K, 610K) - all block types start from 500K, caplin files are not affected
+	require.Equal(t, uint64(500_000), p.ProfileMinBlock(MinimalProfile))
+	require.Equal(t, []string{
+		"domain/v1-accounts.0-1024.kv",
+		"v1-000000-000500-beaconblocks.seg",
+		"v1-000500-000600-bodies.seg",
+		"v1-000500-000600-headers.seg",
+		"v1-000500-000600-transactions.seg",
+		"v1-000600-000610-bodies.seg",
+		"v1-000600-000610-headers.seg",
+		"v1-000600-000610-transactions.seg",
+	}, names(p.Profiled(MinimalProfile)))
+
+	// cut can happen only where all types have file boundary
+	p = append(p[:7:7], Preverified{
+		{Name: "v1-000500-000610-bodies.seg"},
+		{Name: "v1-000500-000600-headers.seg"},
+		{Name: "v1-000500-000610-transactions.seg"},
+		{Name: "v1-000600-000610-headers.seg"},
+	}...)
+	require.Equal(t, uint64(500_000), p.ProfileMinBlock(MinimalProfile))
+	require.Equal(t, uint64(0), p.ProfileMinBlock(DownloadProfile{RecentBlocks: 10_000_000}))
+}
+
+func TestWithoutExpired(t *testing.T) {
+	p := Preverified{
+		{Name: "v1-000000-000500-bodies.seg"},
+		{Name: "v1-000000-000500-headers.seg"},
+		{Name: "v1-000000-000500-receipts.seg"},
+		{Name: "v1-000000-000500-transactions.seg"},
+		{Name: "v1-000500-001000-bodies.seg"},
+		{Name: "v1-000500-001000-headers.seg"},
+		{Name: "v1-000500-001000-receipts.seg"},
+		{Name: "v1-000500-001000-transactions.seg"},
+	}
+	require.Equal(t, p, p.WithoutExpired(0))
+	// file which covers expiry block is kept
+	require.Equal(t, append(p[:2:2], p[4:]...), p.WithoutExpired(600_000))
+	require.Equal(t, append(p[:2:2], p[4:6]...), p.WithoutExpired(1_000_000))
+}
@@ -3,6 +3,7 @@ package snapcfg
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -31,7 +32,10 @@ var (
 
 type PreverifiedItem struct {
 	Name string
-	Hash string
+	Hash string // v1 info hash
+	// Root - BitTorrent v2 (BEP-52) pieces root of file, hex. Optional: allows per-piece verification of file
+	// and de-duplication of same content under different names (for example: new version of file with same data)
+	Root string
 }
 type Preverified []PreverifiedItem
 
@@ -245,41 +249,74 @@ func (p Preverified) MaxBlock(version snaptype.Version) (uint64, error) {
 }
 
 func (p Preverified) MarshalJSON() ([]byte, error) {
-	out := map[string]string{}
-
-	for _, i := range p {
-		out[i.Name] = i.Hash
-	}
-
-	return json.Marshal(out)
+	return json.Marshal(p.values())
 }
 
 func (p *Preverified) UnmarshalJSON(data []byte) error {
-	var outMap map[string]string
+	var outMap map[string]any
 
 	if err := json.Unmarshal(data, &outMap); err != nil {
 		return err
 	}
 
-	*p = doSort(outMap)
-	return nil
+	var err error
+	*p, err = fromValues(outMap)
+	return err
 }
 
-func fromToml(in []byte) (out Preverified) {
-	var outMap map[string]string
+// ParsePreverifiedToml - values are `"<info hash>"` or `{ hash = "<info hash>", root = "<v2 pieces root>" }`
+func ParsePreverifiedToml(in []byte) (Preverified, error) {
+	var outMap map[string]any
 	if err := toml.Unmarshal(in, &outMap); err != nil {
+		return nil, err
+	}
+	return fromValues(outMap)
+}
+
+// MarshalToml - same format as ParsePreverifiedToml. Items without Root are written as plain strings (readable by old versions)
+func (p Preverified) MarshalToml() ([]byte, error) {
+	return toml.Marshal(p.values())
+}
+
+func (p Preverified) values() map[string]any {
+	out := make(map[string]any, len(p))
+	for _, i := range p {
+		if i.Root == "" {
+			out[i.Name] = i.Hash
+		} else {
+			out[i.Name] = map[string]string{"hash": i.Hash, "root": i.Root}
+		}
+	}
+	return out
+}
+
+func fromToml(in []byte) (out Preverified) {
+	out, err := ParsePreverifiedToml(in)
+	if err != nil {
 		panic(err)
 	}
-	return doSort(outMap)
+	return out
 }
 
-func doSort(in map[string]string) Preverified {
+func fromValues(in map[string]any) (Preverified, error) {
 	out := make(Preverified, 0, len(in))
 	for k, v := range in {
-		out = append(out, PreverifiedItem{k, v})
+		switch v := v.(type) {
+		case string:
+			out = append(out, PreverifiedItem{Name: k, Hash: v})
+		case map[string]any:
+			hash, _ := v["hash"].(string)
+			root, _ := v["root"].(string)
+			if hash == "" {
+				return nil, fmt.Errorf("preverified %s: hash is empty", k)
+			}
+			out = append(out, PreverifiedItem{Name: k, Hash: hash, Root: root})
+		default:
+			return nil, fmt.Errorf("preverified %s: unexpected value %v", k, v)
+		}
 	}
 	slices.SortFunc(out, func(i, j PreverifiedItem) int { return strings.Compare(i.Name, j.Name) })
-	return out
+	return out, nil
 }
 
 func newCfg(networkName string, preverified Preverified) *Cfg {
@@ -0,0 +1,34 @@
+package snapcfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreverifiedV2Roots(t *testing.T) {
+	in := []byte(`"v1-000000-000500-bodies.seg" = "aa"
+"v2-000000-000500-bodies.seg" = { hash = "bb", root = "cc" }
+`)
+	p, err := ParsePreverifiedToml(in)
+	require.NoError(t, err)
+	require.Equal(t, Preverified{
+		{Name: "v1-000000-000500-bodies.seg", Hash: "aa"},
+		{Name: "v2-000000-000500-bodies.seg", Hash: "bb", Root: "cc"},
+	}, p)
+
+	out, err := p.MarshalToml()
+	require.NoError(t, err)
+	reparsed, err := ParsePreverifiedToml(out)
+	require.NoError(t, err)
+	require.Equal(t, p, reparsed)
+
+	j, err := p.MarshalJSON()
+	require.NoError(t, err)
+	var fromJson Preverified
+	require.NoError(t, fromJson.UnmarshalJSON(j))
+	require.Equal(t, p, fromJson)
+
+	_, err = ParsePreverifiedToml([]byte(`"a" = { root = "cc" }`))
+	require.Error(t, err)
+}
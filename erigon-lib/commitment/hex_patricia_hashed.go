@@ -83,6 +83,11 @@ type HexPatriciaHashed struct {
 	auxBuffer     *bytes.Buffer // auxiliary buffer used during branch updates encoding
 	branchMerger  *BranchMerger
 	branchEncoder *BranchEncoder
+
+	// Set while proving (see ProveHashedKeys): trie is only read, encodings of hashed nodes are collected by hashes
+	proofNodes map[common.Hash][]byte
+	nodeBuf    bytes.Buffer // encoding of leaf or extension node being hashed
+	branchBuf  bytes.Buffer // encoding of branch node being hashed
 }
 
 func NewHexPatriciaHashed(accountKeyLen int, ctx PatriciaContext) *HexPatriciaHashed {
@@ -484,8 +489,7 @@ func (hph *HexPatriciaHashed) completeLeafHash(buf, keyPrefix []byte, kp, kl, co
 		hph.auxBuffer.Reset()
 		writer = hph.auxBuffer
 	} else {
-		hph.keccak.Reset()
-		writer = hph.keccak
+		writer = hph.hashWriter()
 	}
 	if _, err := writer.Write(lenPrefix[:pt]); err != nil {
 		return nil, err
@@ -517,6 +521,7 @@ func (hph *HexPatriciaHashed) completeLeafHash(buf, keyPrefix []byte, kp, kl, co
 		if _, err := hph.keccak.Read(hashBuf[1:]); err != nil {
 			return nil, err
 		}
+		hph.collectNode(hashBuf[1:])
 		buf = append(buf, hashBuf[:]...)
 	}
 	return buf, nil
@@ -580,6 +585,23 @@ func (hph *HexPatriciaHashed) accountLeafHashWithKey(buf, key []byte, val rlp.Rl
 	return hph.completeLeafHash(buf, keyPrefix[:], kp, kl, compactLen, key, compact0, ni, val, true)
 }
 
+// hashWriter - resets keccak to hash leaf or extension node, while proving the node encoding is also collected
+func (hph *HexPatriciaHashed) hashWriter() io.Writer {
+	hph.keccak.Reset()
+	if hph.proofNodes == nil {
+		return hph.keccak
+	}
+	hph.nodeBuf.Reset()
+	return io.MultiWriter(hph.keccak, &hph.nodeBuf)
+}
+
+// collectNode - while proving, keeps encoding of node written to hashWriter by its hash
+func (hph *HexPatriciaHashed) collectNode(hash []byte) {
+	if hph.proofNodes != nil {
+		hph.proofNodes[common.BytesToHash(hash)] = common.Copy(hph.nodeBuf.Bytes())
+	}
+}
+
 func (hph *HexPatriciaHashed) extensionHash(key []byte, hash []byte) ([length.Hash]byte, error) {
 	var hashBuf [length.Hash]byte
 
@@ -615,36 +637,37 @@ func (hph *HexPatriciaHashed) extensionHash(key []byte, hash []byte) ([length.Ha
 	totalLen := kp + kl + 33
 	var lenPrefix [4]byte
 	pt := rlp.GenerateStructLen(lenPrefix[:], totalLen)
-	hph.keccak.Reset()
-	if _, err := hph.keccak.Write(lenPrefix[:pt]); err != nil {
+	writer := hph.hashWriter()
+	if _, err := writer.Write(lenPrefix[:pt]); err != nil {
 		return hashBuf, err
 	}
-	if _, err := hph.keccak.Write(keyPrefix[:kp]); err != nil {
+	if _, err := writer.Write(keyPrefix[:kp]); err != nil {
 		return hashBuf, err
 	}
 	var b [1]byte
 	b[0] = compact0
-	if _, err := hph.keccak.Write(b[:]); err != nil {
+	if _, err := writer.Write(b[:]); err != nil {
 		return hashBuf, err
 	}
 	for i := 1; i < compactLen; i++ {
 		b[0] = key[ni]*16 + key[ni+1]
-		if _, err := hph.keccak.Write(b[:]); err != nil {
+		if _, err := writer.Write(b[:]); err != nil {
 			return hashBuf, err
 		}
 		ni += 2
 	}
 	b[0] = 0x80 + length.Hash
-	if _, err := hph.keccak.Write(b[:]); err != nil {
+	if _, err := writer.Write(b[:]); err != nil {
 		return hashBuf, err
 	}
-	if _, err := hph.keccak.Write(hash); err != nil {
+	if _, err := writer.Write(hash); err != nil {
 		return hashBuf, err
 	}
 	// Replace previous hash with the new one
 	if _, err := hph.keccak.Read(hashBuf[:]); err != nil {
 		return hashBuf, err
 	}
+	hph.collectNode(hashBuf[:])
 	return hashBuf, nil
 }
 
@@ -1121,15 +1144,20 @@ func (hph *HexPatriciaHashed) fold() (err error) {
 		}
 
 		hph.keccak2.Reset()
+		var branchWriter io.Writer = hph.keccak2
+		if hph.proofNodes != nil {
+			hph.branchBuf.Reset()
+			branchWriter = io.MultiWriter(hph.keccak2, &hph.branchBuf)
+		}
 		pt := rlp.GenerateStructLen(hph.hashAuxBuffer[:], totalBranchLen)
-		if _, err := hph.keccak2.Write(hph.hashAuxBuffer[:pt]); err != nil {
+		if _, err := branchWriter.Write(hph.hashAuxBuffer[:pt]); err != nil {
 			return err
 		}
 
 		b := [...]byte{0x80}
 		cellGetter := func(nibble int, skip bool) (*Cell, error) {
 			if skip {
-				if _, err := hph.keccak2.Write(b[:]); err != nil {
+				if _, err := branchWriter.Write(b[:]); err != nil {
 					return nil, fmt.Errorf("failed to write empty nibble to hash: %w", err)
 				}
 				if hph.trace {
@@ -1145,7 +1173,7 @@ func (hph *HexPatriciaHashed) fold() (err error) {
 			if hph.trace {
 				fmt.Printf("%x: computeCellHash(%d,%x,depth=%d)=[%x]\n", nibble, row, nibble, depth, cellHash)
 			}
-			if _, err := hph.keccak2.Write(cellHash); err != nil {
+			if _, err := branchWriter.Write(cellHash); err != nil {
 				return nil, err
 			}
 
@@ -1160,7 +1188,7 @@ func (hph *HexPatriciaHashed) fold() (err error) {
 			return fmt.Errorf("failed to encode branch update: %w", err)
 		}
 		for i := lastNibble; i < 17; i++ {
-			if _, err := hph.keccak2.Write(b[:]); err != nil {
+			if _, err := branchWriter.Write(b[:]); err != nil {
 				return err
 			}
 			if hph.trace {
@@ -1181,6 +1209,9 @@ func (hph *HexPatriciaHashed) fold() (err error) {
 		if _, err := hph.keccak2.Read(upCell.h[:]); err != nil {
 			return err
 		}
+		if hph.proofNodes != nil {
+			hph.proofNodes[common.Hash(upCell.h)] = common.Copy(hph.branchBuf.Bytes())
+		}
 		if hph.trace {
 			fmt.Printf("} [%x]\n", upCell.h[:])
 		}
@@ -1280,6 +1311,10 @@ func (hph *HexPatriciaHashed) collectBranchUpdate(
 	if err != nil {
 		return 0, err
 	}
+	if hph.proofNodes != nil {
+		// nothing is modified while proving, cells are only read to hash the branch
+		return ln, nil
+	}
 	prev, prevStep, err := hph.ctx.GetBranch(prefix) // prefix already compacted by fold
 	if err != nil {
 		return 0, err
@@ -0,0 +1,237 @@
+package commitment
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+)
+
+// Merkle proofs and iteration over the leaves of the committed trie, both are read-only: branches are read from
+// PatriciaContext and never put back.
+
+// ProveHashedKeys walks the trie down to the given keys without modifying it and returns the root hash along with
+// the encodings of the hashed nodes met on the way, by their hashes. Keys are nibbles of hashed keys: 64 for accounts,
+// 128 (hashed address followed by hashed location) for storage. Keys don't have to be present in the trie, then
+// collected nodes prove their absence. Besides the nodes on the paths of the keys, leaves of their siblings and
+// storage roots of the accounts on the paths are collected, so the proof of a key is extracted by following its path
+// from the root.
+func (hph *HexPatriciaHashed) ProveHashedKeys(ctx context.Context, hashedKeys [][]byte) (rootHash []byte, nodes map[common.Hash][]byte, err error) {
+	if hph.activeRows > 0 {
+		return nil, nil, fmt.Errorf("trie has active rows, could not prove before fold")
+	}
+	hph.proofNodes = make(map[common.Hash][]byte)
+	defer func() { hph.proofNodes = nil }()
+
+	keys := make([][]byte, len(hashedKeys))
+	copy(keys, hashedKeys)
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for _, hashedKey := range keys {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+		for hph.needFolding(hashedKey) {
+			if err := hph.fold(); err != nil {
+				return nil, nil, fmt.Errorf("fold: %w", err)
+			}
+		}
+		for unfolding := hph.needUnfolding(hashedKey); unfolding > 0; unfolding = hph.needUnfolding(hashedKey) {
+			if err := hph.unfold(hashedKey, unfolding); err != nil {
+				return nil, nil, fmt.Errorf("unfold: %w", err)
+			}
+		}
+	}
+	for hph.activeRows > 0 {
+		if err := hph.fold(); err != nil {
+			return nil, nil, fmt.Errorf("final fold: %w", err)
+		}
+	}
+	// root extension or leaf is hashed only here
+	if rootHash, err = hph.RootHash(); err != nil {
+		return nil, nil, fmt.Errorf("root hash evaluation failed: %w", err)
+	}
+	return rootHash, hph.proofNodes, nil
+}
+
+// LeafVisitor receives the leaves of the trie in order of their hashed keys (bytes). Account cells come with Nonce,
+// Balance, CodeHash and storage root of the account, storage cells - with the value in Storage. Returning false stops
+// the walk
+type LeafVisitor func(hashedKey, plainKey []byte, cell *Cell, storageRoot []byte) (bool, error)
+
+// WalkLeaves visits the leaves of accounts trie with hashed keys starting from `from`, or, if hashed key of `account`
+// is given, the leaves of its storage trie starting from hashed location `from`
+func (hph *HexPatriciaHashed) WalkLeaves(account, from []byte, visit LeafVisitor) error {
+	if account == nil {
+		w := &leafWalker{hph: hph, from: bytesToNibbles(from), visit: visit}
+		_, err := w.walkCell(&hph.root, nil)
+		return err
+	}
+
+	// storage trie hangs on the account leaf
+	var accountCell *Cell
+	accountKey := bytesToNibbles(account)
+	aw := &leafWalker{hph: hph, from: accountKey, visit: func(hashedKey, _ []byte, cell *Cell, _ []byte) (bool, error) {
+		if bytes.Equal(hashedKey, account) {
+			accountCell = new(Cell)
+			*accountCell = *cell
+		}
+		return false, nil
+	}}
+	if _, err := aw.walkCell(&hph.root, nil); err != nil {
+		return err
+	}
+	if accountCell == nil {
+		return nil
+	}
+	w := &leafWalker{hph: hph, from: append(accountKey, bytesToNibbles(from)...), storage: true, visit: visit}
+	switch {
+	case accountCell.spl > 0:
+		// single storage slot is kept in the account cell
+		_, err := w.visitStorage(accountCell)
+		return err
+	case accountCell.hl > 0:
+		_, err := w.walkBranch(append(accountKey, accountCell.extension[:accountCell.extLen]...))
+		return err
+	}
+	return nil
+}
+
+type leafWalker struct {
+	hph     *HexPatriciaHashed
+	from    []byte // nibbles, storage keys start with hashed account key
+	storage bool   // walking the storage trie, otherwise accounts trie
+	visit   LeafVisitor
+}
+
+// walkCell - visits leaves under the cell located at `path` (nibbles, including the nibble of the cell)
+func (w *leafWalker) walkCell(cell *Cell, path []byte) (stop bool, err error) {
+	switch {
+	case !w.storage && cell.apl > 0:
+		return w.visitAccount(cell)
+	case w.storage && cell.spl > 0:
+		return w.visitStorage(cell)
+	case cell.hl > 0:
+		return w.walkBranch(append(path[:len(path):len(path)], cell.extension[:cell.extLen]...))
+	}
+	return false, nil
+}
+
+// walkBranch - visits cells of the branch located at `prefix`, skipping the ones before `from`
+func (w *leafWalker) walkBranch(prefix []byte) (stop bool, err error) {
+	if bytes.Compare(prefix, w.from[:minInt(len(prefix), len(w.from))]) < 0 {
+		return false, nil
+	}
+	key := hexToCompact(prefix)
+	if len(key) == 0 {
+		key = temporalReplacementForEmpty
+	}
+	branchData, _, err := w.hph.ctx.GetBranch(key)
+	if err != nil {
+		return false, err
+	}
+	if len(branchData) < 4 {
+		return false, fmt.Errorf("branch %x not found", key)
+	}
+	branchData = branchData[2:] // skip touch map
+	bitmap := binary.BigEndian.Uint16(branchData[0:])
+	pos := 2
+	var cells [16]Cell
+	for bitset := bitmap; bitset != 0; {
+		bit := bitset & -bitset
+		nibble := bits.TrailingZeros16(bit)
+		fieldBits := branchData[pos]
+		pos++
+		if pos, err = cells[nibble].fillFromFields(branchData, pos, PartFlags(fieldBits)); err != nil {
+			return false, fmt.Errorf("prefix [%x], branchData[%x]: %w", prefix, branchData, err)
+		}
+		bitset ^= bit
+	}
+	for bitset := bitmap; bitset != 0; {
+		bit := bitset & -bitset
+		nibble := bits.TrailingZeros16(bit)
+		if stop, err = w.walkCell(&cells[nibble], append(prefix[:len(prefix):len(prefix)], byte(nibble))); stop || err != nil {
+			return stop, err
+		}
+		bitset ^= bit
+	}
+	return false, nil
+}
+
+func (w *leafWalker) visitAccount(cell *Cell) (stop bool, err error) {
+	hashedKey := w.hph.hashAndNibblizeKey(cell.apk[:cell.apl])
+	if bytes.Compare(hashedKey, w.from) < 0 {
+		return false, nil
+	}
+	if err := w.hph.ctx.GetAccount(cell.apk[:cell.apl], cell); err != nil {
+		return false, fmt.Errorf("GetAccount for key %x failed: %w", cell.apk[:cell.apl], err)
+	}
+	if cell.spl > 0 {
+		if err := w.hph.ctx.GetStorage(cell.spk[:cell.spl], cell); err != nil {
+			return false, fmt.Errorf("GetStorage for key %x failed: %w", cell.spk[:cell.spl], err)
+		}
+	}
+	storageRoot, err := w.hph.accountStorageRoot(cell)
+	if err != nil {
+		return false, err
+	}
+	ok, err := w.visit(nibblesToBytes(hashedKey), cell.apk[:cell.apl], cell, storageRoot[:])
+	return !ok, err
+}
+
+func (w *leafWalker) visitStorage(cell *Cell) (stop bool, err error) {
+	hashedKey := w.hph.hashAndNibblizeKey(cell.spk[:cell.spl])
+	if bytes.Compare(hashedKey, w.from) < 0 {
+		return false, nil
+	}
+	if err := w.hph.ctx.GetStorage(cell.spk[:cell.spl], cell); err != nil {
+		return false, fmt.Errorf("GetStorage for key %x failed: %w", cell.spk[:cell.spl], err)
+	}
+	ok, err := w.visit(nibblesToBytes(hashedKey[64:]), cell.spk[:cell.spl], cell, nil)
+	return !ok, err
+}
+
+// accountStorageRoot - storage root of the account cell, the same as computeCellHash uses for hashing of the account
+func (hph *HexPatriciaHashed) accountStorageRoot(cell *Cell) ([length.Hash]byte, error) {
+	switch {
+	case cell.spl > 0:
+		// single storage slot is storage trie of one leaf
+		if err := hashKey(hph.keccak, cell.spk[hph.accountKeyLen:cell.spl], cell.downHashedKey[:], 0); err != nil {
+			return [length.Hash]byte{}, err
+		}
+		cell.downHashedKey[64] = 16 // Add terminator
+		aux, err := hph.leafHashWithKeyVal(make([]byte, 0, 33), cell.downHashedKey[:65], cell.Storage[:cell.StorageLen], true)
+		if err != nil {
+			return [length.Hash]byte{}, err
+		}
+		return *(*[length.Hash]byte)(aux[1:]), nil
+	case cell.extLen > 0 && cell.hl > 0:
+		return hph.extensionHash(cell.extension[:cell.extLen], cell.h[:cell.hl])
+	case cell.hl > 0:
+		return cell.h, nil
+	}
+	return *(*[length.Hash]byte)(EmptyRootHash), nil
+}
+
+func bytesToNibbles(b []byte) []byte {
+	nibbles := make([]byte, len(b)*2)
+	for i, c := range b {
+		nibbles[i*2] = c >> 4
+		nibbles[i*2+1] = c & 0xf
+	}
+	return nibbles
+}
+
+func nibblesToBytes(nibbles []byte) []byte {
+	b := make([]byte, len(nibbles)/2)
+	for i := range b {
+		b[i] = nibbles[i*2]<<4 | nibbles[i*2+1]
+	}
+	return b
+}
@@ -0,0 +1,152 @@
+package commitment
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+)
+
+func keccakTest(data []byte) []byte {
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write(data)
+	return keccak.Sum(nil)
+}
+
+// proofTestTrie - accounts, first of them with many storage slots, second one with single slot
+func proofTestTrie(t *testing.T) (*MockState, *HexPatriciaHashed, []string, []byte) {
+	t.Helper()
+	ms := NewMockState(t)
+	builder := NewUpdateBuilder()
+	var addrs []string
+	for i := 0; i < 40; i++ {
+		addr := hex.EncodeToString(keccakTest([]byte{byte(i)})[:length.Addr])
+		addrs = append(addrs, addr)
+		builder.Balance(addr, uint64(i+1)).Nonce(addr, uint64(i))
+	}
+	for i := 0; i < 20; i++ {
+		builder.Storage(addrs[0], hex.EncodeToString(keccakTest([]byte{0, byte(i)})), hex.EncodeToString([]byte{byte(i + 1)}))
+	}
+	builder.Storage(addrs[1], hex.EncodeToString(keccakTest([]byte{1})), "01")
+	plainKeys, updates := builder.Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+
+	hph := NewHexPatriciaHashed(length.Addr, ms)
+	rootHash, err := hph.ProcessKeys(context.Background(), plainKeys, "")
+	require.NoError(t, err)
+
+	// trie restored from its state, the way it's done with commitment state of domains
+	restored := NewHexPatriciaHashed(length.Addr, ms)
+	require.NoError(t, restored.SetState(mustEncodeState(t, hph)))
+	return ms, restored, addrs, rootHash
+}
+
+func mustEncodeState(t *testing.T, hph *HexPatriciaHashed) []byte {
+	t.Helper()
+	state, err := hph.EncodeCurrentState(nil)
+	require.NoError(t, err)
+	return state
+}
+
+func Test_HexPatriciaHashed_ProveHashedKeys(t *testing.T) {
+	ctx := context.Background()
+	ms, hph, addrs, rootHash := proofTestTrie(t)
+
+	contract := hph.hashAndNibblizeKey(decodeHex(addrs[0]))
+	keys := [][]byte{
+		hph.hashAndNibblizeKey(decodeHex(addrs[5])),
+		hph.hashAndNibblizeKey(decodeHex(addrs[1])),
+		bytesToNibbles(bytes.Repeat([]byte{0xff}, length.Hash)),                                   // absent account
+		append(common.Copy(contract), bytesToNibbles(keccakTest(keccakTest([]byte{0, 3})))...),    // present slot
+		append(common.Copy(contract), bytesToNibbles(bytes.Repeat([]byte{0x11}, length.Hash))...), // absent slot
+	}
+	proofRoot, nodes, err := hph.ProveHashedKeys(ctx, keys)
+	require.NoError(t, err)
+	require.Equal(t, rootHash, proofRoot)
+	for hash, node := range nodes {
+		require.Equal(t, hash[:], keccakTest(node))
+	}
+	require.Contains(t, nodes, common.BytesToHash(rootHash))
+
+	// storage roots of the proven accounts are among the collected nodes
+	for _, addr := range addrs[:2] {
+		err = hph.WalkLeaves(nil, keccakTest(decodeHex(addr)), func(hashedKey, plainKey []byte, cell *Cell, storageRoot []byte) (bool, error) {
+			require.Equal(t, decodeHex(addr), plainKey)
+			require.NotEqual(t, EmptyRootHash, storageRoot)
+			require.Contains(t, nodes, common.BytesToHash(storageRoot))
+			return false, nil
+		})
+		require.NoError(t, err)
+	}
+
+	// trie isn't modified by proving: updates are applied the same way as to the trie which wasn't proven
+	otherMs, other, _, _ := proofTestTrie(t)
+	plainKeys, updates := NewUpdateBuilder().Balance(addrs[7], 1000).Storage(addrs[0], "01", "01").Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+	require.NoError(t, otherMs.applyPlainUpdates(plainKeys, updates))
+	updatedRoot, err := hph.ProcessKeys(ctx, plainKeys, "")
+	require.NoError(t, err)
+	expectedRoot, err := other.ProcessKeys(ctx, plainKeys, "")
+	require.NoError(t, err)
+	require.NotEqual(t, rootHash, updatedRoot)
+	require.Equal(t, expectedRoot, updatedRoot)
+}
+
+func Test_HexPatriciaHashed_WalkLeaves(t *testing.T) {
+	_, hph, addrs, _ := proofTestTrie(t)
+
+	var expected [][]byte
+	for _, addr := range addrs {
+		expected = append(expected, keccakTest(decodeHex(addr)))
+	}
+	sort.Slice(expected, func(i, j int) bool { return bytes.Compare(expected[i], expected[j]) < 0 })
+
+	walk := func(account, from []byte, limit int) (hashedKeys [][]byte, cells []Cell) {
+		err := hph.WalkLeaves(account, from, func(hashedKey, plainKey []byte, cell *Cell, storageRoot []byte) (bool, error) {
+			hashedKeys = append(hashedKeys, hashedKey)
+			cells = append(cells, *cell)
+			return len(hashedKeys) < limit, nil
+		})
+		require.NoError(t, err)
+		return hashedKeys, cells
+	}
+
+	hashedKeys, cells := walk(nil, nil, len(addrs)+1)
+	require.Equal(t, expected, hashedKeys)
+	for i, cell := range cells {
+		require.Equal(t, keccakTest(cell.apk[:cell.apl]), hashedKeys[i])
+		require.Equal(t, cell.Nonce+1, cell.Balance.Uint64())
+	}
+
+	// from the middle, with limit
+	hashedKeys, _ = walk(nil, expected[10], 5)
+	require.Equal(t, expected[10:15], hashedKeys)
+	from := common.Copy(expected[10])
+	from[length.Hash-1]++
+	hashedKeys, _ = walk(nil, from, 1)
+	require.Equal(t, expected[11:12], hashedKeys)
+
+	// storage
+	hashedKeys, cells = walk(keccakTest(decodeHex(addrs[0])), nil, 100)
+	require.Len(t, hashedKeys, 20)
+	require.True(t, sort.SliceIsSorted(hashedKeys, func(i, j int) bool { return bytes.Compare(hashedKeys[i], hashedKeys[j]) < 0 }))
+	for i, cell := range cells {
+		require.Equal(t, keccakTest(cell.spk[length.Addr:cell.spl]), hashedKeys[i])
+	}
+	tail, _ := walk(keccakTest(decodeHex(addrs[0])), hashedKeys[15], 100)
+	require.Equal(t, hashedKeys[15:], tail)
+
+	hashedKeys, _ = walk(keccakTest(decodeHex(addrs[1])), nil, 100)
+	require.Equal(t, [][]byte{keccakTest(keccakTest([]byte{1}))}, hashedKeys)
+	hashedKeys, _ = walk(keccakTest(decodeHex(addrs[2])), nil, 100)
+	require.Empty(t, hashedKeys)
+	hashedKeys, _ = walk(bytes.Repeat([]byte{0xff}, length.Hash), nil, 100)
+	require.Empty(t, hashedKeys)
+}
@@ -45,6 +45,9 @@ var (
 	discardHistory    = EnvBool("DISCARD_HISTORY", false)
 	discardCommitment = EnvBool("DISCARD_COMMITMENT", false)
 
+	// warm state of next block (senders, recipients, access lists) by background readers, while current block executes
+	ExecPrefetchState = EnvBool("EXEC_PREFETCH_STATE", false)
+
 	// force skipping of any non-Erigon2 .torrent files
 	DownloaderOnlyBlocks = EnvBool("DOWNLOADER_ONLY_BLOCKS", false)
 
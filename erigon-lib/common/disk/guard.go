@@ -0,0 +1,147 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	psdisk "github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// FreeSpaceLevel - how close volumes of datadir are to be full
+type FreeSpaceLevel int
+
+const (
+	FreeSpaceOk       FreeSpaceLevel = iota
+	FreeSpaceLow                     // snapshot retirement/merges and new torrents are paused
+	FreeSpaceCritical                // sync loop is halted before next commit
+)
+
+var ErrNoSpace = errors.New("not enough free disk space")
+
+type GuardCfg struct {
+	Dirs  []string          // datadir, tmp, ...: volumes of them are checked
+	Pause datasize.ByteSize // below: FreeSpaceLow, 0 - disabled
+	Halt  datasize.ByteSize // below: FreeSpaceCritical, 0 - disabled
+}
+
+func (cfg GuardCfg) Enabled() bool { return cfg.Pause > 0 || cfg.Halt > 0 }
+
+const guardInterval = 10 * time.Second
+
+// freeSpace - bytes available to the process on volume of dir, variable for tests
+var freeSpace = func(dir string) (uint64, error) {
+	usage, err := psdisk.Usage(dir)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Free, nil
+}
+
+var guard struct {
+	sync.RWMutex
+	level     FreeSpaceLevel
+	free      uint64
+	dir       string
+	threshold datasize.ByteSize
+}
+
+// CheckFreeSpace - ErrNoSpace if free space is at level or below, nil if guard is not running
+func CheckFreeSpace(level FreeSpaceLevel) error {
+	guard.RLock()
+	defer guard.RUnlock()
+	if level == FreeSpaceOk || guard.level < level {
+		return nil
+	}
+	return fmt.Errorf("%w: %s free on volume of %s (threshold %s)", ErrNoSpace, common.ByteCount(guard.free), guard.dir, guard.threshold)
+}
+
+// WaitFreeSpace - blocks until free space is above level
+func WaitFreeSpace(ctx context.Context, level FreeSpaceLevel) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for CheckFreeSpace(level) != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// GuardFreeSpace - monitors free space of volumes of cfg.Dirs, to stop growing datadir before disk is full:
+// at FreeSpaceLow snapshot retirement/merges and new torrents are paused, at FreeSpaceCritical sync loop is halted
+// (mdbx doesn't survive disk full in the middle of commit). Everything resumes when space is freed.
+func GuardFreeSpace(ctx context.Context, cfg GuardCfg, logger log.Logger) {
+	if !cfg.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(guardInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := checkVolumes(cfg, logger); err != nil {
+			logger.Warn("[disk] free space check failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkVolumes(cfg GuardCfg, logger log.Logger) error {
+	var minFree uint64
+	var minDir string
+	for _, dir := range cfg.Dirs {
+		if dir == "" {
+			continue
+		}
+		free, err := freeSpace(dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dir, err)
+		}
+		if minDir == "" || free < minFree {
+			minFree, minDir = free, dir
+		}
+	}
+	if minDir == "" {
+		return nil
+	}
+
+	level, threshold := FreeSpaceOk, cfg.Pause
+	switch {
+	case cfg.Halt > 0 && minFree < cfg.Halt.Bytes():
+		level, threshold = FreeSpaceCritical, cfg.Halt
+	case cfg.Pause > 0 && minFree < cfg.Pause.Bytes():
+		level = FreeSpaceLow
+	}
+
+	guard.Lock()
+	prev := guard.level
+	guard.level, guard.free, guard.dir, guard.threshold = level, minFree, minDir, threshold
+	guard.Unlock()
+
+	if level == prev {
+		return nil
+	}
+	switch level {
+	case FreeSpaceCritical:
+		logger.Error("[disk] free space is critical, sync is halted until space is freed", "free", common.ByteCount(minFree), "dir", minDir, "threshold", cfg.Halt)
+	case FreeSpaceLow:
+		logger.Warn("[disk] free space is low, snapshot retirement/merges and new torrents are paused", "free", common.ByteCount(minFree), "dir", minDir, "threshold", cfg.Pause)
+	default:
+		logger.Info("[disk] free space recovered", "free", common.ByteCount(minFree), "dir", minDir)
+	}
+	return nil
+}
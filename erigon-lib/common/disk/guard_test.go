@@ -0,0 +1,44 @@
+package disk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardFreeSpace(t *testing.T) {
+	free := map[string]uint64{"/datadir": 100 * uint64(datasize.GB), "/tmp": 100 * uint64(datasize.GB)}
+	defer func(f func(string) (uint64, error)) { freeSpace = f }(freeSpace)
+	freeSpace = func(dir string) (uint64, error) { return free[dir], nil }
+
+	cfg := GuardCfg{Dirs: []string{"/datadir", "/tmp"}, Pause: 10 * datasize.GB, Halt: datasize.GB}
+	logger := log.New()
+
+	require.NoError(t, checkVolumes(cfg, logger))
+	require.NoError(t, CheckFreeSpace(FreeSpaceLow))
+	require.NoError(t, CheckFreeSpace(FreeSpaceCritical))
+
+	free["/tmp"] = 5 * uint64(datasize.GB)
+	require.NoError(t, checkVolumes(cfg, logger))
+	require.ErrorIs(t, CheckFreeSpace(FreeSpaceLow), ErrNoSpace)
+	require.ErrorContains(t, CheckFreeSpace(FreeSpaceLow), "/tmp")
+	require.NoError(t, CheckFreeSpace(FreeSpaceCritical))
+
+	free["/datadir"] = 512 * uint64(datasize.MB)
+	require.NoError(t, checkVolumes(cfg, logger))
+	require.ErrorIs(t, CheckFreeSpace(FreeSpaceLow), ErrNoSpace)
+	require.ErrorIs(t, CheckFreeSpace(FreeSpaceCritical), ErrNoSpace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, WaitFreeSpace(ctx, FreeSpaceCritical), context.DeadlineExceeded)
+
+	free["/datadir"], free["/tmp"] = 20*uint64(datasize.GB), 20*uint64(datasize.GB)
+	require.NoError(t, checkVolumes(cfg, logger))
+	require.NoError(t, WaitFreeSpace(context.Background(), FreeSpaceCritical))
+	require.NoError(t, CheckFreeSpace(FreeSpaceLow))
+}
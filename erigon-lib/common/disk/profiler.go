@@ -0,0 +1,274 @@
+package disk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+// Subsystem - owner of files, IO of the process is attributed to
+type Subsystem string
+
+const (
+	Domains   Subsystem = "domains"
+	Snapshots Subsystem = "snapshots"
+	MDBX      Subsystem = "mdbx"
+	Torrent   Subsystem = "torrent"
+	Other     Subsystem = "other"
+)
+
+type tag struct {
+	prefix    string
+	writable  bool // applies only to writable mappings
+	subsystem Subsystem
+}
+
+var (
+	tagsLock sync.RWMutex
+	tags     []tag
+)
+
+// Tag - attributes IO of files under path (file or dir) to subsystem. Subsystems tag files when open them,
+// the most specific path wins.
+func Tag(path string, subsystem Subsystem) { addTag(path, false, subsystem) }
+
+// TagWritable - same as Tag, but only for writable mappings, for subsystems sharing files with others.
+// For example torrent mmaps snapshot files for write, while snapshots and domains mmap them read-only.
+func TagWritable(path string, subsystem Subsystem) { addTag(path, true, subsystem) }
+
+func addTag(path string, writable bool, subsystem Subsystem) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil { // kernel reports resolved paths of mappings
+		path = resolved
+	}
+
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	for i := range tags {
+		if tags[i].prefix == path && tags[i].writable == writable {
+			tags[i].subsystem = subsystem
+			return
+		}
+	}
+	tags = append(tags, tag{prefix: path, writable: writable, subsystem: subsystem})
+}
+
+// Classify - subsystem of file mapped by the process
+func Classify(path string, writable bool) Subsystem {
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+
+	subsystem, best := Other, -1
+	for _, t := range tags {
+		if t.writable && !writable {
+			continue
+		}
+		if path != t.prefix && !strings.HasPrefix(path, t.prefix+string(filepath.Separator)) {
+			continue
+		}
+		score := 2 * len(t.prefix)
+		if t.writable {
+			score++
+		}
+		if score > best {
+			subsystem, best = t.subsystem, score
+		}
+	}
+	return subsystem
+}
+
+// mapping - file-backed memory mapping of the process, from /proc/self/smaps
+type mapping struct {
+	start    uint64
+	inode    uint64
+	path     string
+	writable bool
+	rss      uint64 // bytes
+}
+
+// parseSmaps - reads file-backed mappings, anonymous mappings are skipped
+func parseSmaps(r io.Reader) ([]mapping, error) {
+	var res []mapping
+	var cur *mapping
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// header: address perms offset dev inode [path]
+		if addr, _, ok := strings.Cut(fields[0], "-"); ok && len(fields) >= 5 && !strings.HasSuffix(fields[0], ":") {
+			cur = nil
+			inode, err := strconv.ParseUint(fields[4], 10, 64)
+			if err != nil || inode == 0 || len(fields) < 6 {
+				continue
+			}
+			start, err := strconv.ParseUint(addr, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse mapping address %q: %w", fields[0], err)
+			}
+			res = append(res, mapping{
+				start:    start,
+				inode:    inode,
+				path:     strings.Join(fields[5:], " "),
+				writable: len(fields[1]) > 1 && fields[1][1] == 'w',
+			})
+			cur = &res[len(res)-1]
+			continue
+		}
+
+		if cur != nil && fields[0] == "Rss:" && len(fields) >= 2 {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse Rss of %s: %w", cur.path, err)
+			}
+			cur.rss = kb * 1024
+		}
+	}
+	return res, scanner.Err()
+}
+
+// IOStats - IO attributed to subsystem. Reads are pages faulted-in from files, observed as growth of resident
+// set of mappings between samples, so pages evicted and faulted again between samples are not counted.
+type IOStats struct {
+	ReadBytes uint64 // since start of profiler
+	ReadPages uint64 // since start of profiler, upper bound of read IOPS (kernel readahead merges them)
+	Resident  uint64 // bytes of subsystem files in page cache and mapped by the process
+}
+
+type mappingKey struct {
+	start uint64
+	inode uint64
+}
+
+type profiler struct {
+	pageSize uint64
+	rss      map[mappingKey]uint64
+	stats    map[Subsystem]*IOStats
+	started  bool
+}
+
+func newProfiler() *profiler {
+	return &profiler{pageSize: uint64(os.Getpagesize()), rss: map[mappingKey]uint64{}, stats: map[Subsystem]*IOStats{}}
+}
+
+// step - attributes growth of mappings since previous step, first step only takes baseline
+func (p *profiler) step(mappings []mapping) {
+	rss := make(map[mappingKey]uint64, len(mappings))
+	for _, s := range p.stats {
+		s.Resident = 0
+	}
+
+	for _, m := range mappings {
+		subsystem := Classify(m.path, m.writable)
+		s, ok := p.stats[subsystem]
+		if !ok {
+			s = &IOStats{}
+			p.stats[subsystem] = s
+		}
+		s.Resident += m.rss
+
+		key := mappingKey{m.start, m.inode}
+		rss[key] = m.rss
+		if prev := p.rss[key]; p.started && m.rss > prev {
+			s.ReadBytes += m.rss - prev
+			s.ReadPages += (m.rss - prev) / p.pageSize
+		}
+	}
+
+	p.rss, p.started = rss, true
+}
+
+var profilerStats struct {
+	sync.Mutex
+	stats map[Subsystem]IOStats
+}
+
+// Stats - IO by subsystem, empty if profiler is not running
+func Stats() map[Subsystem]IOStats {
+	profilerStats.Lock()
+	defer profilerStats.Unlock()
+	res := make(map[Subsystem]IOStats, len(profilerStats.stats))
+	for s, st := range profilerStats.stats {
+		res[s] = st
+	}
+	return res
+}
+
+// Profile - attributes page faults of the process to subsystems by tags of mapped files, every interval.
+// Reading smaps walks page tables of all mappings, so it's optional and not for short intervals.
+func Profile(ctx context.Context, interval time.Duration, logger log.Logger) {
+	if !profilerSupported {
+		logger.Warn("[disk] io profiler is not supported on this platform")
+		return
+	}
+
+	p := newProfiler()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := map[Subsystem]IOStats{}
+	for {
+		mappings, err := readMappings()
+		if err != nil {
+			logger.Warn("[disk] io profiler failed to read mappings", "err", err)
+		} else {
+			p.step(mappings)
+			prev = p.report(prev, interval, logger)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *profiler) report(prev map[Subsystem]IOStats, interval time.Duration, logger log.Logger) map[Subsystem]IOStats {
+	subsystems := make([]Subsystem, 0, len(p.stats))
+	for s := range p.stats {
+		subsystems = append(subsystems, s)
+	}
+	sort.Slice(subsystems, func(i, j int) bool { return subsystems[i] < subsystems[j] })
+
+	cur := make(map[Subsystem]IOStats, len(p.stats))
+	var logItems []interface{}
+	for _, s := range subsystems {
+		st := *p.stats[s]
+		cur[s] = st
+		readBytes, readPages := st.ReadBytes-prev[s].ReadBytes, st.ReadPages-prev[s].ReadPages
+
+		metrics.GetOrCreateCounter(fmt.Sprintf(`disk_io_read_bytes_total{subsystem="%s"}`, s)).AddUint64(readBytes)
+		metrics.GetOrCreateCounter(fmt.Sprintf(`disk_io_read_pages_total{subsystem="%s"}`, s)).AddUint64(readPages)
+		metrics.GetOrCreateGauge(fmt.Sprintf(`disk_io_resident_bytes{subsystem="%s"}`, s)).SetUint64(st.Resident)
+
+		logItems = append(logItems, string(s), fmt.Sprintf("%s/s, %d pages/s, resident=%s",
+			common.ByteCount(uint64(float64(readBytes)/interval.Seconds())), uint64(float64(readPages)/interval.Seconds()), common.ByteCount(st.Resident)))
+	}
+
+	profilerStats.Lock()
+	profilerStats.stats = cur
+	profilerStats.Unlock()
+
+	logger.Info("[disk] io by subsystem", logItems...)
+	return cur
+}
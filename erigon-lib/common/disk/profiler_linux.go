@@ -0,0 +1,16 @@
+//go:build linux
+
+package disk
+
+import "os"
+
+const profilerSupported = true
+
+func readMappings() ([]mapping, error) {
+	f, err := os.Open("/proc/self/smaps")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseSmaps(f)
+}
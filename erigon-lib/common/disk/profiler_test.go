@@ -0,0 +1,60 @@
+package disk
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSmaps = `55d0c0a00000-55d0c0a21000 r--p 00000000 103:02 1835050                   /usr/bin/erigon
+Size:                132 kB
+Rss:                 128 kB
+7f0000000000-7f0000100000 r--s 00000000 103:02 100                       /data/snapshots/v1-000000-000500-headers.seg
+Size:               1024 kB
+Rss:                  64 kB
+7f0000200000-7f0000300000 rw-s 00000000 103:02 100                       /data/snapshots/v1-000000-000500-headers.seg
+Rss:                  16 kB
+7f0000400000-7f0000500000 r--s 00000000 103:02 200                       /data/snapshots/domain/v1-accounts.0-32.kv
+Rss:                  32 kB
+7f0000600000-7f0000700000 r--s 00000000 103:02 300                       /data/chaindata/mdbx.dat
+Rss:                 256 kB
+7f0000800000-7f0000900000 rw-p 00000000 00:00 0
+Rss:                 512 kB
+`
+
+func TestProfiler(t *testing.T) {
+	Tag("/data/chaindata", MDBX)
+	Tag("/data/snapshots", Snapshots)
+	Tag(filepath.Join("/data/snapshots", "domain"), Domains)
+	TagWritable("/data/snapshots", Torrent)
+
+	mappings, err := parseSmaps(strings.NewReader(testSmaps))
+	require.NoError(t, err)
+	require.Len(t, mappings, 5) // anonymous mapping is skipped
+	require.Equal(t, uint64(0x7f0000200000), mappings[2].start)
+	require.True(t, mappings[2].writable)
+	require.Equal(t, uint64(16*1024), mappings[2].rss)
+
+	require.Equal(t, Other, Classify(mappings[0].path, mappings[0].writable))
+	require.Equal(t, Snapshots, Classify(mappings[1].path, mappings[1].writable))
+	require.Equal(t, Torrent, Classify(mappings[2].path, mappings[2].writable))
+	require.Equal(t, Domains, Classify(mappings[3].path, mappings[3].writable))
+	require.Equal(t, MDBX, Classify(mappings[4].path, mappings[4].writable))
+	require.Equal(t, Other, Classify("/data/chaindata2/mdbx.dat", false))
+
+	p := newProfiler()
+	p.step(mappings)
+	require.Equal(t, uint64(0), p.stats[Snapshots].ReadBytes) // first step is baseline
+	require.Equal(t, uint64(64*1024), p.stats[Snapshots].Resident)
+
+	mappings[1].rss += 40 * 1024
+	mappings[3].rss -= 16 * 1024 // evicted
+	p.step(mappings)
+	require.Equal(t, uint64(40*1024), p.stats[Snapshots].ReadBytes)
+	require.Equal(t, uint64(40*1024)/p.pageSize, p.stats[Snapshots].ReadPages)
+	require.Equal(t, uint64(104*1024), p.stats[Snapshots].Resident)
+	require.Equal(t, uint64(0), p.stats[Domains].ReadBytes)
+	require.Equal(t, uint64(16*1024), p.stats[Domains].Resident)
+}
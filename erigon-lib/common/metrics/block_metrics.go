@@ -3,6 +3,7 @@ package metrics
 import (
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/log/v3"
 
 	"github.com/ledgerwatch/erigon-lib/metrics"
@@ -62,3 +63,26 @@ func UpdateBlockProducerProductionDelay(parentTime uint64, blockNumber uint64, l
 		log.Info("[producer-delay] Production", "blockNumber", blockNumber, "delay", time.Since(t))
 	}
 }
+
+var (
+	BlockProducerPayloadValue        = metrics.NewGauge(`block_producer_payload_value_gwei`)
+	BlockProducerGasFill             = metrics.NewGauge(`block_producer_fill_percent{type="gas"}`)
+	BlockProducerBlobFill            = metrics.NewGauge(`block_producer_fill_percent{type="blob"}`)
+	BlockProducerInclusionListMissed = metrics.NewCounter(`block_producer_inclusion_list_missed`)
+)
+
+// UpdateBlockProducerPayload - value (tips to fee recipient, in wei) and gas/blob gas fill rate of produced block
+func UpdateBlockProducerPayload(value *uint256.Int, blockNumber, gasUsed, gasLimit, blobGasUsed, maxBlobGas uint64, log log.Logger) {
+	gwei := new(uint256.Int).Div(value, uint256.NewInt(1_000_000_000))
+	BlockProducerPayloadValue.SetUint64(gwei.Uint64())
+	if gasLimit > 0 {
+		BlockProducerGasFill.SetUint64(gasUsed * 100 / gasLimit)
+	}
+	if maxBlobGas > 0 {
+		BlockProducerBlobFill.SetUint64(blobGasUsed * 100 / maxBlobGas)
+	}
+
+	if DelayLoggingEnabled {
+		log.Info("[producer-payload] Production", "blockNumber", blockNumber, "valueGwei", gwei.Uint64(), "gasUsed", gasUsed, "gasLimit", gasLimit, "blobGasUsed", blobGasUsed)
+	}
+}
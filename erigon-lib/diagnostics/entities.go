@@ -74,6 +74,7 @@ type SnapshotDownloadStatistics struct {
 	DownloadFinished     bool                                 `json:"downloadFinished"`
 	SegmentsDownloading  map[string]SegmentDownloadStatistics `json:"segmentsDownloading"`
 	TorrentMetadataReady int32                                `json:"torrentMetadataReady"`
+	Eta                  float64                              `json:"eta"` // seconds, 0 - unknown
 }
 
 type SegmentDownloadStatistics struct {
@@ -82,6 +83,8 @@ type SegmentDownloadStatistics struct {
 	DownloadedBytes uint64        `json:"downloadedBytes"`
 	Webseeds        []SegmentPeer `json:"webseeds"`
 	Peers           []SegmentPeer `json:"peers"`
+	State           string        `json:"state"` // queued, downloading, verifying, done
+	Eta             float64       `json:"eta"`   // seconds, 0 - unknown
 }
 
 type SegmentPeer struct {
@@ -114,8 +117,29 @@ type CurrentSyncStage struct {
 }
 
 type SyncStages struct {
-	StagesList   []string `json:"stagesList"`
-	CurrentStage uint     `json:"currentStage"`
+	StagesList   []string                 `json:"stagesList"`
+	CurrentStage uint                     `json:"currentStage"`
+	Progress     map[string]StageProgress `json:"progress"` // by stage
+	Throttle     map[string]StageThrottle `json:"throttle"` // by stage
+}
+
+// StageProgress - structured progress of stage, reported by each stage in same format
+type StageProgress struct {
+	Stage     string  `json:"stage"`
+	Unit      string  `json:"unit"` // what is processed: blocks, txs, ...
+	Processed uint64  `json:"processed"`
+	Total     uint64  `json:"total"` // 0 - unknown
+	Rate      float64 `json:"rate"`  // units per second
+	Eta       float64 `json:"eta"`   // seconds, 0 - unknown
+}
+
+// StageThrottle - limits of resources of stage and how much stage was slowed down by them
+type StageThrottle struct {
+	Stage     string  `json:"stage"`
+	Workers   int     `json:"workers"`   // limit of goroutine pools, 0 - no limit
+	IOLimit   uint64  `json:"ioLimit"`   // bytes per second, 0 - no limit
+	IOWaited  float64 `json:"ioWaited"`  // seconds spent waiting for IO limit
+	UpdatedAt int64   `json:"updatedAt"` // unix seconds
 }
 
 type BlockExecutionStatistics struct {
@@ -241,6 +265,23 @@ type MemoryStats struct {
 	StageIndex  int       `json:"stageIndex"`
 }
 
+// MdbxStats - page operations, page cache and GC state of chaindata, reported on each metrics collection
+type MdbxStats struct {
+	Size         uint64    `json:"size"`
+	PgopsNewly   uint64    `json:"pgopsNewly"`
+	PgopsCow     uint64    `json:"pgopsCow"`
+	PgopsClone   uint64    `json:"pgopsClone"`
+	PgopsSplit   uint64    `json:"pgopsSplit"`
+	PgopsMerge   uint64    `json:"pgopsMerge"`
+	PgopsSpill   uint64    `json:"pgopsSpill"`
+	PgopsUnspill uint64    `json:"pgopsUnspill"`
+	PgopsWops    uint64    `json:"pgopsWops"`
+	TxDirty      uint64    `json:"txDirty"`
+	TxLimit      uint64    `json:"txLimit"`
+	GcPages      uint64    `json:"gcPages"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
 type NetworkSpeedTestResult struct {
 	Latency       time.Duration `json:"latency"`
 	DownloadSpeed float64       `json:"downloadSpeed"`
@@ -251,6 +292,10 @@ func (ti MemoryStats) Type() Type {
 	return TypeOf(ti)
 }
 
+func (ti MdbxStats) Type() Type {
+	return TypeOf(ti)
+}
+
 func (ti BodiesProcessingUpdate) Type() Type {
 	return TypeOf(ti)
 }
@@ -303,6 +348,14 @@ func (ti CurrentSyncStage) Type() Type {
 	return TypeOf(ti)
 }
 
+func (ti StageThrottle) Type() Type {
+	return TypeOf(ti)
+}
+
+func (ti StageProgress) Type() Type {
+	return TypeOf(ti)
+}
+
 func (ti PeerStatisticMsgUpdate) Type() Type {
 	return TypeOf(ti)
 }
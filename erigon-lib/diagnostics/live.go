@@ -0,0 +1,162 @@
+package diagnostics
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// LiveMessageType - kind of payload carried by LiveMessage, lets the UI dispatch without guessing the shape
+type LiveMessageType string
+
+const (
+	LiveMemory     LiveMessageType = "memory"
+	LiveGoroutines LiveMessageType = "goroutines"
+	LiveMdbx       LiveMessageType = "mdbx"
+	LiveStages     LiveMessageType = "stages"
+	LiveDownloader LiveMessageType = "downloader"
+)
+
+// LiveMessage - one typed sample of node health, streamed to the support UI
+type LiveMessage struct {
+	Type      LiveMessageType `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      interface{}     `json:"data"`
+}
+
+type LiveMemoryStats struct {
+	Alloc        uint64 `json:"alloc"`
+	Sys          uint64 `json:"sys"`
+	HeapInuse    uint64 `json:"heapInuse"`
+	HeapObjects  uint64 `json:"heapObjects"`
+	StackInuse   uint64 `json:"stackInuse"`
+	NumGC        uint32 `json:"numGC"`
+	PauseTotalNs uint64 `json:"pauseTotalNs"`
+}
+
+type LiveGoroutinesStats struct {
+	Count int `json:"count"`
+}
+
+type LiveStagesStats struct {
+	Current  string          `json:"current"`
+	Progress []StageProgress `json:"progress"` // sorted by stage
+}
+
+// LiveDownloaderStats - SnapshotDownloadStatistics without per-segment details, which are too heavy for each tick
+type LiveDownloaderStats struct {
+	Downloaded           uint64  `json:"downloaded"`
+	Total                uint64  `json:"total"`
+	DownloadRate         uint64  `json:"downloadRate"`
+	UploadRate           uint64  `json:"uploadRate"`
+	Peers                int32   `json:"peers"`
+	Files                int32   `json:"files"`
+	Segments             int     `json:"segments"`
+	TorrentMetadataReady int32   `json:"torrentMetadataReady"`
+	DownloadFinished     bool    `json:"downloadFinished"`
+	Eta                  float64 `json:"eta"`
+}
+
+func (d *DiagnosticClient) setupMdbxDiagnostics(rootCtx context.Context) {
+	d.runMdbxStatsListener(rootCtx)
+}
+
+func (d *DiagnosticClient) runMdbxStatsListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[MdbxStats](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(MdbxStats{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.mdbxStatsMutex.Lock()
+				d.mdbxStats = info
+				d.mdbxStatsMutex.Unlock()
+			}
+		}
+	}()
+}
+
+// LiveMessages - current sample of all live message types, mdbx is omitted until the first metrics collection
+func (d *DiagnosticClient) LiveMessages() []LiveMessage {
+	now := time.Now()
+
+	var m runtime.MemStats
+	dbg.ReadMemStats(&m)
+
+	msgs := []LiveMessage{
+		{Type: LiveMemory, Timestamp: now, Data: LiveMemoryStats{
+			Alloc:        m.Alloc,
+			Sys:          m.Sys,
+			HeapInuse:    m.HeapInuse,
+			HeapObjects:  m.HeapObjects,
+			StackInuse:   m.StackInuse,
+			NumGC:        m.NumGC,
+			PauseTotalNs: m.PauseTotalNs,
+		}},
+		{Type: LiveGoroutines, Timestamp: now, Data: LiveGoroutinesStats{Count: runtime.NumGoroutine()}},
+	}
+
+	d.mdbxStatsMutex.Lock()
+	mdbxStats := d.mdbxStats
+	d.mdbxStatsMutex.Unlock()
+	if !mdbxStats.Timestamp.IsZero() {
+		msgs = append(msgs, LiveMessage{Type: LiveMdbx, Timestamp: now, Data: mdbxStats})
+	}
+
+	d.mu.Lock()
+	stages := LiveStagesStats{Progress: make([]StageProgress, 0, len(d.syncStats.SyncStages.Progress))}
+	if current := int(d.syncStats.SyncStages.CurrentStage); current < len(d.syncStats.SyncStages.StagesList) {
+		stages.Current = d.syncStats.SyncStages.StagesList[current]
+	}
+	for _, p := range d.syncStats.SyncStages.Progress {
+		stages.Progress = append(stages.Progress, p)
+	}
+	download := d.syncStats.SnapshotDownload
+	d.mu.Unlock()
+
+	sort.Slice(stages.Progress, func(i, j int) bool { return stages.Progress[i].Stage < stages.Progress[j].Stage })
+
+	return append(msgs,
+		LiveMessage{Type: LiveStages, Timestamp: now, Data: stages},
+		LiveMessage{Type: LiveDownloader, Timestamp: now, Data: LiveDownloaderStats{
+			Downloaded:           download.Downloaded,
+			Total:                download.Total,
+			DownloadRate:         download.DownloadRate,
+			UploadRate:           download.UploadRate,
+			Peers:                download.Peers,
+			Files:                download.Files,
+			Segments:             len(download.SegmentsDownloading),
+			TorrentMetadataReady: download.TorrentMetadataReady,
+			DownloadFinished:     download.DownloadFinished,
+			Eta:                  download.Eta,
+		}},
+	)
+}
+
+// StreamLive - sends LiveMessages to send every interval until ctx is done or send fails
+func (d *DiagnosticClient) StreamLive(ctx context.Context, interval time.Duration, send func(LiveMessage) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, msg := range d.LiveMessages() {
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
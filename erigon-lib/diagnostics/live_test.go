@@ -0,0 +1,53 @@
+package diagnostics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/diagnostics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveMessages(t *testing.T) {
+	d := diagnostics.NewDiagnosticClient(nil, "")
+
+	msgs := d.LiveMessages()
+
+	var types []diagnostics.LiveMessageType
+	for _, msg := range msgs {
+		types = append(types, msg.Type)
+	}
+	// mdbx stats are not reported until first metrics collection
+	require.Equal(t, []diagnostics.LiveMessageType{
+		diagnostics.LiveMemory,
+		diagnostics.LiveGoroutines,
+		diagnostics.LiveStages,
+		diagnostics.LiveDownloader,
+	}, types)
+
+	require.NotZero(t, msgs[0].Data.(diagnostics.LiveMemoryStats).Sys)
+	require.Positive(t, msgs[1].Data.(diagnostics.LiveGoroutinesStats).Count)
+}
+
+func TestStreamLive(t *testing.T) {
+	d := diagnostics.NewDiagnosticClient(nil, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	var count int
+	err := d.StreamLive(ctx, 100*time.Millisecond, func(msg diagnostics.LiveMessage) error {
+		count++
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// first sample is sent immediately, then one per tick
+	require.GreaterOrEqual(t, count, 3*4)
+
+	stop := context.Canceled
+	err = d.StreamLive(context.Background(), time.Hour, func(msg diagnostics.LiveMessage) error {
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+}
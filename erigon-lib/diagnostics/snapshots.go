@@ -174,7 +174,10 @@ func (d *DiagnosticClient) runSnapshotFilesListListener(rootCtx context.Context)
 }
 
 func (d *DiagnosticClient) SyncStatistics() SyncStatistics {
-	return d.syncStats
+	stats := d.syncStats
+	stats.SyncStages.Progress = d.StagesProgress()
+	stats.SyncStages.Throttle = d.StagesThrottle()
+	return stats
 }
 
 func (d *DiagnosticClient) SnapshotFilesList() SnapshoFilesList {
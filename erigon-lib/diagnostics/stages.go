@@ -9,6 +9,8 @@ import (
 func (d *DiagnosticClient) setupStagesDiagnostics(rootCtx context.Context) {
 	d.runCurrentSyncStageListener(rootCtx)
 	d.runSyncStagesListListener(rootCtx)
+	d.runStageProgressListener(rootCtx)
+	d.runStageThrottleListener(rootCtx)
 }
 
 func (d *DiagnosticClient) runSyncStagesListListener(rootCtx context.Context) {
@@ -45,6 +47,7 @@ func (d *DiagnosticClient) runCurrentSyncStageListener(rootCtx context.Context)
 				d.mu.Lock()
 				d.syncStats.SyncStages.CurrentStage = info.Stage
 				if int(d.syncStats.SyncStages.CurrentStage) >= len(d.syncStats.SyncStages.StagesList) {
+					d.mu.Unlock()
 					return
 				}
 				d.mu.Unlock()
@@ -52,3 +55,69 @@ func (d *DiagnosticClient) runCurrentSyncStageListener(rootCtx context.Context)
 		}
 	}()
 }
+
+func (d *DiagnosticClient) runStageProgressListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[StageProgress](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(StageProgress{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.mu.Lock()
+				if d.syncStats.SyncStages.Progress == nil {
+					d.syncStats.SyncStages.Progress = map[string]StageProgress{}
+				}
+				d.syncStats.SyncStages.Progress[info.Stage] = info
+				d.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// StagesProgress - last reported progress of each stage
+func (d *DiagnosticClient) StagesProgress() map[string]StageProgress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	progress := make(map[string]StageProgress, len(d.syncStats.SyncStages.Progress))
+	for stage, p := range d.syncStats.SyncStages.Progress {
+		progress[stage] = p
+	}
+	return progress
+}
+
+func (d *DiagnosticClient) runStageThrottleListener(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[StageThrottle](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(StageThrottle{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.mu.Lock()
+				if d.syncStats.SyncStages.Throttle == nil {
+					d.syncStats.SyncStages.Throttle = map[string]StageThrottle{}
+				}
+				d.syncStats.SyncStages.Throttle[info.Stage] = info
+				d.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// StagesThrottle - last reported throttle state of each throttled stage
+func (d *DiagnosticClient) StagesThrottle() map[string]StageThrottle {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	throttle := make(map[string]StageThrottle, len(d.syncStats.SyncStages.Throttle))
+	for stage, t := range d.syncStats.SyncStages.Throttle {
+		throttle[stage] = t
+	}
+	return throttle
+}
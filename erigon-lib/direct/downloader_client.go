@@ -48,3 +48,12 @@ func (c *DownloaderClient) Verify(ctx context.Context, in *proto_downloader.Veri
 func (c *DownloaderClient) Stats(ctx context.Context, in *proto_downloader.StatsRequest, opts ...grpc.CallOption) (*proto_downloader.StatsReply, error) {
 	return c.server.Stats(ctx, in)
 }
+func (c *DownloaderClient) BandwidthSchedule(ctx context.Context, in *proto_downloader.BandwidthScheduleRequest, opts ...grpc.CallOption) (*proto_downloader.BandwidthScheduleReply, error) {
+	return c.server.BandwidthSchedule(ctx, in)
+}
+func (c *DownloaderClient) SetBandwidthSchedule(ctx context.Context, in *proto_downloader.SetBandwidthScheduleRequest, opts ...grpc.CallOption) (*proto_downloader.BandwidthScheduleReply, error) {
+	return c.server.SetBandwidthSchedule(ctx, in)
+}
+func (c *DownloaderClient) Progress(ctx context.Context, in *proto_downloader.ProgressRequest, opts ...grpc.CallOption) (*proto_downloader.ProgressReply, error) {
+	return c.server.Progress(ctx, in)
+}
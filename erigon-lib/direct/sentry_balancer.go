@@ -0,0 +1,251 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package direct
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+)
+
+// SentryPolicy - order in which sentries are tried for requests which any of them can serve
+type SentryPolicy string
+
+const (
+	SentryPolicyRandom   SentryPolicy = "random"   // random order
+	SentryPolicyPeers    SentryPolicy = "peers"    // sentries with more peers first
+	SentryPolicyProtocol SentryPolicy = "protocol" // sentries of newer eth protocol first
+	SentryPolicyTag      SentryPolicy = "tag"      // sentries with SentryBalancerCfg.PreferredTag first, e.g. of the same region
+)
+
+func ParseSentryPolicy(s string) (SentryPolicy, error) {
+	switch p := SentryPolicy(s); p {
+	case SentryPolicyRandom, SentryPolicyPeers, SentryPolicyProtocol, SentryPolicyTag:
+		return p, nil
+	case "":
+		return SentryPolicyRandom, nil
+	}
+	return "", fmt.Errorf("unknown sentry policy %q, expected one of: random, peers, protocol, tag", s)
+}
+
+type SentryBalancerCfg struct {
+	Policy       SentryPolicy
+	Tags         []string // tag of each sentry, in order of sentries
+	PreferredTag string
+	MaxFailures  int           // consecutive failures after which sentry is degraded
+	Backoff      time.Duration // degraded sentry is tried only after healthy ones for this long
+	PeersRefresh time.Duration // how often peer counts are refreshed for SentryPolicyPeers
+}
+
+var DefaultSentryBalancerCfg = SentryBalancerCfg{
+	Policy:       SentryPolicyRandom,
+	MaxFailures:  3,
+	Backoff:      30 * time.Second,
+	PeersRefresh: 10 * time.Second,
+}
+
+type sentryState struct {
+	peers         uint64
+	failures      int
+	degradedUntil time.Time
+}
+
+// SentryBalancer - routes requests which any sentry can serve (header and body requests, tx broadcasts) across
+// sentries: only ready sentries whose protocol supports the message are used, in order of the policy, and sentries
+// failing repeatedly are degraded - tried only after the healthy ones until the backoff passes.
+type SentryBalancer struct {
+	cfg      SentryBalancerCfg
+	sentries []SentryClient
+	lock     sync.Mutex
+	states   []sentryState
+}
+
+func NewSentryBalancer(sentries []SentryClient, cfg SentryBalancerCfg) *SentryBalancer {
+	if cfg.Policy == "" {
+		cfg.Policy = DefaultSentryBalancerCfg.Policy
+	}
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = DefaultSentryBalancerCfg.MaxFailures
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultSentryBalancerCfg.Backoff
+	}
+	if cfg.PeersRefresh <= 0 {
+		cfg.PeersRefresh = DefaultSentryBalancerCfg.PeersRefresh
+	}
+	return &SentryBalancer{cfg: cfg, sentries: sentries, states: make([]sentryState, len(sentries))}
+}
+
+func (b *SentryBalancer) tag(i int) string {
+	if i < len(b.cfg.Tags) {
+		return b.cfg.Tags[i]
+	}
+	return ""
+}
+
+// Order returns indices of the sentries to try for message with given id, in order of preference
+func (b *SentryBalancer) Order(id sentry.MessageId) []int {
+	now := time.Now()
+	var healthy, degraded []int
+	for i, s := range b.sentries {
+		if !s.Ready() || !supports(s.Protocol(), id) {
+			continue
+		}
+		if b.Degraded(i, now) {
+			degraded = append(degraded, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	b.sort(healthy)
+	b.sort(degraded)
+	return append(healthy, degraded...)
+}
+
+// Healthy returns indices of the sentries to broadcast message with given id to: as Order, but without the degraded
+// sentries unless all of them are degraded
+func (b *SentryBalancer) Healthy(id sentry.MessageId) []int {
+	order := b.Order(id)
+	now := time.Now()
+	for k, i := range order {
+		if b.Degraded(i, now) {
+			if k == 0 {
+				return order
+			}
+			return order[:k]
+		}
+	}
+	return order
+}
+
+// supports - whether sentry of given protocol can send message with given id, unknown ids are allowed
+func supports(protocol uint, id sentry.MessageId) bool {
+	if _, ok := ProtoIds[protocol][id]; ok {
+		return true
+	}
+	for _, ids := range ProtoIds {
+		if _, ok := ids[id]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sort orders indices by policy, randomly among equal ones
+func (b *SentryBalancer) sort(indices []int) {
+	rand.Shuffle(len(indices), func(i, j int) { indices[i], indices[j] = indices[j], indices[i] }) // nolint: gosec
+	var less func(i, j int) bool
+	switch b.cfg.Policy {
+	case SentryPolicyPeers:
+		b.lock.Lock()
+		peers := make([]uint64, len(indices))
+		for k, i := range indices {
+			peers[k] = b.states[i].peers
+		}
+		b.lock.Unlock()
+		sort.Sort(byPeers{indices: indices, peers: peers})
+		return
+	case SentryPolicyProtocol:
+		less = func(i, j int) bool { return b.sentries[indices[i]].Protocol() > b.sentries[indices[j]].Protocol() }
+	case SentryPolicyTag:
+		less = func(i, j int) bool {
+			return b.tag(indices[i]) == b.cfg.PreferredTag && b.tag(indices[j]) != b.cfg.PreferredTag
+		}
+	default:
+		return
+	}
+	sort.SliceStable(indices, less)
+}
+
+type byPeers struct {
+	indices []int
+	peers   []uint64
+}
+
+func (s byPeers) Len() int           { return len(s.indices) }
+func (s byPeers) Less(i, j int) bool { return s.peers[i] > s.peers[j] }
+func (s byPeers) Swap(i, j int) {
+	s.indices[i], s.indices[j] = s.indices[j], s.indices[i]
+	s.peers[i], s.peers[j] = s.peers[j], s.peers[i]
+}
+
+func (b *SentryBalancer) Degraded(i int, now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return now.Before(b.states[i].degradedUntil)
+}
+
+// Success - request to sentry i succeeded, it's healthy again
+func (b *SentryBalancer) Success(i int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.states[i].failures = 0
+	b.states[i].degradedUntil = time.Time{}
+}
+
+// Failure - request to sentry i failed. Returns true if the sentry became degraded.
+func (b *SentryBalancer) Failure(i int) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s := &b.states[i]
+	s.failures++
+	if s.failures < b.cfg.MaxFailures {
+		return false
+	}
+	wasDegraded := time.Now().Before(s.degradedUntil)
+	s.degradedUntil = time.Now().Add(b.cfg.Backoff)
+	return !wasDegraded
+}
+
+// RefreshPeerCounts updates peer counts of sentries, used by SentryPolicyPeers
+func (b *SentryBalancer) RefreshPeerCounts(ctx context.Context) {
+	for i, s := range b.sentries {
+		if !s.Ready() {
+			continue
+		}
+		reply, err := s.PeerCount(ctx, &sentry.PeerCountRequest{})
+		if err != nil {
+			b.Failure(i)
+			continue
+		}
+		b.lock.Lock()
+		b.states[i].peers = reply.Count
+		b.lock.Unlock()
+	}
+}
+
+// Run refreshes peer counts until ctx is done, if the policy needs them
+func (b *SentryBalancer) Run(ctx context.Context) {
+	if b.cfg.Policy != SentryPolicyPeers {
+		return
+	}
+	ticker := time.NewTicker(b.cfg.PeersRefresh)
+	defer ticker.Stop()
+	for {
+		b.RefreshPeerCounts(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package direct
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+)
+
+func testSentries(t *testing.T, protocols []uint, ready []bool, peers []uint64) []SentryClient {
+	ctrl := gomock.NewController(t)
+	sentries := make([]SentryClient, len(protocols))
+	for i := range protocols {
+		s := NewMockSentryClient(ctrl)
+		s.EXPECT().Ready().Return(ready[i]).AnyTimes()
+		s.EXPECT().Protocol().Return(protocols[i]).AnyTimes()
+		s.EXPECT().PeerCount(gomock.Any(), gomock.Any()).Return(&sentry.PeerCountReply{Count: peers[i]}, nil).AnyTimes()
+		sentries[i] = s
+	}
+	return sentries
+}
+
+func TestSentryBalancerPolicies(t *testing.T) {
+	sentries := testSentries(t, []uint{ETH66, ETH68, ETH67, ETH68}, []bool{true, true, true, false}, []uint64{5, 1, 10, 50})
+
+	b := NewSentryBalancer(sentries, SentryBalancerCfg{Policy: SentryPolicyPeers})
+	b.RefreshPeerCounts(context.Background())
+	require.Equal(t, []int{2, 0, 1}, b.Order(sentry.MessageId_GET_BLOCK_HEADERS_66))
+
+	b = NewSentryBalancer(sentries, SentryBalancerCfg{Policy: SentryPolicyProtocol})
+	require.Equal(t, []int{1, 2, 0}, b.Order(sentry.MessageId_GET_BLOCK_HEADERS_66))
+
+	b = NewSentryBalancer(sentries, SentryBalancerCfg{Policy: SentryPolicyTag, Tags: []string{"us", "eu", "us", "eu"}, PreferredTag: "eu"})
+	require.Equal(t, 1, b.Order(sentry.MessageId_GET_BLOCK_HEADERS_66)[0])
+
+	// capability: only eth/68 sentries announce with types and sizes
+	b = NewSentryBalancer(sentries, DefaultSentryBalancerCfg)
+	require.Equal(t, []int{1}, b.Order(sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68))
+	require.ElementsMatch(t, []int{0, 2}, b.Order(sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66))
+
+	_, err := ParseSentryPolicy("nearest")
+	require.Error(t, err)
+}
+
+func TestSentryBalancerFailover(t *testing.T) {
+	sentries := testSentries(t, []uint{ETH68, ETH68}, []bool{true, true}, []uint64{10, 1})
+	b := NewSentryBalancer(sentries, SentryBalancerCfg{Policy: SentryPolicyPeers, MaxFailures: 2})
+	b.RefreshPeerCounts(context.Background())
+	id := sentry.MessageId_GET_BLOCK_BODIES_66
+	require.Equal(t, []int{0, 1}, b.Order(id))
+
+	require.False(t, b.Failure(0))
+	require.Equal(t, []int{0, 1}, b.Order(id))
+	require.True(t, b.Failure(0))
+	require.False(t, b.Failure(0)) // already degraded
+	require.Equal(t, []int{1, 0}, b.Order(id))
+	require.Equal(t, []int{1}, b.Healthy(id))
+
+	// all degraded - still used
+	b.Failure(1)
+	b.Failure(1)
+	require.Len(t, b.Healthy(id), 2)
+
+	b.Success(0)
+	require.Equal(t, []int{0, 1}, b.Order(id))
+}
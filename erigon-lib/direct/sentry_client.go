@@ -35,6 +35,7 @@ const (
 	ETH66 = 66
 	ETH67 = 67
 	ETH68 = 68
+	ETH69 = 69
 )
 
 var ProtoIds = map[uint]map[sentry.MessageId]struct{}{
@@ -98,6 +99,20 @@ var ProtoIds = map[uint]map[sentry.MessageId]struct{}{
 		sentry.MessageId_GET_POOLED_TRANSACTIONS_66:       struct{}{},
 		sentry.MessageId_POOLED_TRANSACTIONS_66:           struct{}{},
 	},
+	ETH69: { // same as eth/68: sentry translates eth/69 receipts and also serves eth/68 peers
+		sentry.MessageId_GET_BLOCK_HEADERS_66:             struct{}{},
+		sentry.MessageId_BLOCK_HEADERS_66:                 struct{}{},
+		sentry.MessageId_GET_BLOCK_BODIES_66:              struct{}{},
+		sentry.MessageId_BLOCK_BODIES_66:                  struct{}{},
+		sentry.MessageId_GET_RECEIPTS_66:                  struct{}{},
+		sentry.MessageId_RECEIPTS_66:                      struct{}{},
+		sentry.MessageId_NEW_BLOCK_HASHES_66:              struct{}{},
+		sentry.MessageId_NEW_BLOCK_66:                     struct{}{},
+		sentry.MessageId_TRANSACTIONS_66:                  struct{}{},
+		sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68: struct{}{},
+		sentry.MessageId_GET_POOLED_TRANSACTIONS_66:       struct{}{},
+		sentry.MessageId_POOLED_TRANSACTIONS_66:           struct{}{},
+	},
 }
 
 //go:generate mockgen -typed=true -destination=./sentry_client_mock.go -package=direct . SentryClient
@@ -159,6 +174,8 @@ func (c *SentryClientRemote) HandShake(ctx context.Context, in *emptypb.Empty, o
 		c.protocol = ETH67
 	case sentry.Protocol_ETH68:
 		c.protocol = ETH68
+	case sentry.Protocol_ETH69:
+		c.protocol = ETH69
 	default:
 		return nil, fmt.Errorf("unexpected protocol: %d", reply.Protocol)
 	}
@@ -325,6 +342,22 @@ func (c *SentryClientDirect) AddPeer(ctx context.Context, in *sentry.AddPeerRequ
 	return c.server.AddPeer(ctx, in)
 }
 
+func (c *SentryClientDirect) RemovePeer(ctx context.Context, in *sentry.RemovePeerRequest, opts ...grpc.CallOption) (*sentry.RemovePeerReply, error) {
+	return c.server.RemovePeer(ctx, in)
+}
+
+func (c *SentryClientDirect) AddTrustedPeer(ctx context.Context, in *sentry.AddTrustedPeerRequest, opts ...grpc.CallOption) (*sentry.AddTrustedPeerReply, error) {
+	return c.server.AddTrustedPeer(ctx, in)
+}
+
+func (c *SentryClientDirect) RemoveTrustedPeer(ctx context.Context, in *sentry.RemoveTrustedPeerRequest, opts ...grpc.CallOption) (*sentry.RemoveTrustedPeerReply, error) {
+	return c.server.RemoveTrustedPeer(ctx, in)
+}
+
+func (c *SentryClientDirect) ReloadPeerFiles(ctx context.Context, in *sentry.ReloadPeerFilesRequest, opts ...grpc.CallOption) (*sentry.ReloadPeerFilesReply, error) {
+	return c.server.ReloadPeerFiles(ctx, in)
+}
+
 type peersReply struct {
 	r   *sentry.PeerEvent
 	err error
@@ -383,6 +416,22 @@ func (c *SentryClientDirect) NodeInfo(ctx context.Context, in *emptypb.Empty, op
 	return c.server.NodeInfo(ctx, in)
 }
 
+func (c *SentryClientDirect) PeerScores(ctx context.Context, in *sentry.PeerScoresRequest, opts ...grpc.CallOption) (*sentry.PeerScoresReply, error) {
+	return c.server.PeerScores(ctx, in)
+}
+
+func (c *SentryClientDirect) PinPeer(ctx context.Context, in *sentry.PinPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	return c.server.PinPeer(ctx, in)
+}
+
+func (c *SentryClientDirect) BanPeer(ctx context.Context, in *sentry.BanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	return c.server.BanPeer(ctx, in)
+}
+
+func (c *SentryClientDirect) PeerStats(ctx context.Context, in *sentry.PeerStatsRequest, opts ...grpc.CallOption) (*sentry.PeerStatsReply, error) {
+	return c.server.PeerStats(ctx, in)
+}
+
 func filterIds(in []sentry.MessageId, protocol uint) (filtered []sentry.MessageId) {
 	for _, id := range in {
 		if _, ok := ProtoIds[protocol][id]; ok {
@@ -119,3 +119,83 @@ func (s *TxPoolClient) Status(ctx context.Context, in *txpool_proto.StatusReques
 func (s *TxPoolClient) Nonce(ctx context.Context, in *txpool_proto.NonceRequest, opts ...grpc.CallOption) (*txpool_proto.NonceReply, error) {
 	return s.server.Nonce(ctx, in)
 }
+
+func (s *TxPoolClient) ListJournal(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*txpool_proto.TransactionsReply, error) {
+	return s.server.ListJournal(ctx, in)
+}
+
+func (s *TxPoolClient) EvictJournal(ctx context.Context, in *txpool_proto.TxHashes, opts ...grpc.CallOption) (*txpool_proto.TxHashes, error) {
+	return s.server.EvictJournal(ctx, in)
+}
+
+func (s *TxPoolClient) GetPolicy(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*txpool_proto.PolicyReply, error) {
+	return s.server.GetPolicy(ctx, in)
+}
+
+func (s *TxPoolClient) SetPolicy(ctx context.Context, in *txpool_proto.SetPolicyRequest, opts ...grpc.CallOption) (*txpool_proto.PolicyReply, error) {
+	return s.server.SetPolicy(ctx, in)
+}
+
+func (s *TxPoolClient) AddPrivate(ctx context.Context, in *txpool_proto.AddPrivateRequest, opts ...grpc.CallOption) (*txpool_proto.AddReply, error) {
+	return s.server.AddPrivate(ctx, in)
+}
+
+func (s *TxPoolClient) AddConditional(ctx context.Context, in *txpool_proto.AddConditionalRequest, opts ...grpc.CallOption) (*txpool_proto.AddReply, error) {
+	return s.server.AddConditional(ctx, in)
+}
+
+func (s *TxPoolClient) Analytics(ctx context.Context, in *txpool_proto.AnalyticsRequest, opts ...grpc.CallOption) (*txpool_proto.AnalyticsReply, error) {
+	return s.server.Analytics(ctx, in)
+}
+
+// -- start SubscribeOutcomes
+
+func (s *TxPoolClient) SubscribeOutcomes(ctx context.Context, in *txpool_proto.OutcomesRequest, opts ...grpc.CallOption) (txpool_proto.Txpool_SubscribeOutcomesClient, error) {
+	ch := make(chan *outcomeReply, 16384)
+	streamServer := &TxPoolOutcomesS{ch: ch, ctx: ctx}
+	go func() {
+		defer close(ch)
+		streamServer.Err(s.server.SubscribeOutcomes(in, streamServer))
+	}()
+	return &TxPoolOutcomesC{ch: ch, ctx: ctx}, nil
+}
+
+type outcomeReply struct {
+	r   *txpool_proto.TxOutcome
+	err error
+}
+
+type TxPoolOutcomesS struct {
+	ch  chan *outcomeReply
+	ctx context.Context
+	grpc.ServerStream
+}
+
+func (s *TxPoolOutcomesS) Send(m *txpool_proto.TxOutcome) error {
+	s.ch <- &outcomeReply{r: m}
+	return nil
+}
+func (s *TxPoolOutcomesS) Context() context.Context { return s.ctx }
+func (s *TxPoolOutcomesS) Err(err error) {
+	if err == nil {
+		return
+	}
+	s.ch <- &outcomeReply{err: err}
+}
+
+type TxPoolOutcomesC struct {
+	ch  chan *outcomeReply
+	ctx context.Context
+	grpc.ClientStream
+}
+
+func (c *TxPoolOutcomesC) Recv() (*txpool_proto.TxOutcome, error) {
+	m, ok := <-c.ch
+	if !ok || m == nil {
+		return nil, io.EOF
+	}
+	return m.r, m.err
+}
+func (c *TxPoolOutcomesC) Context() context.Context { return c.ctx }
+
+// -- end SubscribeOutcomes
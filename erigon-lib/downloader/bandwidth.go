@@ -0,0 +1,357 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"golang.org/x/time/rate"
+)
+
+// BandwidthSchedule - time-of-day limits of torrent client, separate for downloading (fetching) and uploading (seeding).
+// Format: rules separated by `;`, first matching rule wins (for each direction separately):
+//
+//	[days ]HH:MM-HH:MM [download=<rate>] [upload=<rate>]
+//
+// days: `mon-fri`, `sat,sun`, ... (default: every day). `22:00-06:00` - till 06:00 of next day, `00:00-24:00` - whole day.
+// rate: bytes per second (`50mb`, `512kb`) or `inf`. Outside of rules: --torrent.download.rate, --torrent.upload.rate.
+// Example: `mon-fri 09:00-18:00 download=6mb upload=1mb; 00:00-07:00 download=inf upload=inf`
+type BandwidthSchedule struct {
+	Rules []BandwidthRule
+}
+
+type BandwidthRule struct {
+	Days     uint8         // bit per time.Weekday
+	From, To time.Duration // since midnight
+	Download rate.Limit    // 0 - not limited by this rule
+	Upload   rate.Limit    // 0 - not limited by this rule
+}
+
+const allDays = 1<<7 - 1
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func ParseBandwidthSchedule(s string) (*BandwidthSchedule, error) {
+	res := &BandwidthSchedule{}
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		r, err := parseBandwidthRule(strings.Fields(part))
+		if err != nil {
+			return nil, fmt.Errorf("bandwidth schedule rule %q: %w", strings.TrimSpace(part), err)
+		}
+		res.Rules = append(res.Rules, r)
+	}
+	return res, nil
+}
+
+func parseBandwidthRule(fields []string) (r BandwidthRule, err error) {
+	if len(fields) > 0 && !strings.Contains(fields[0], ":") {
+		if r.Days, err = parseWeekdays(fields[0]); err != nil {
+			return r, err
+		}
+		fields = fields[1:]
+	} else {
+		r.Days = allDays
+	}
+	if len(fields) == 0 {
+		return r, fmt.Errorf("expected time range HH:MM-HH:MM")
+	}
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return r, fmt.Errorf("expected time range HH:MM-HH:MM, got %q", fields[0])
+	}
+	if r.From, err = parseTimeOfDay(from); err != nil {
+		return r, err
+	}
+	if r.To, err = parseTimeOfDay(to); err != nil {
+		return r, err
+	}
+	if r.From == 24*time.Hour {
+		return r, fmt.Errorf("time range can't start at 24:00")
+	}
+	for _, f := range fields[1:] {
+		name, val, ok := strings.Cut(f, "=")
+		if !ok {
+			return r, fmt.Errorf("expected download=<rate> or upload=<rate>, got %q", f)
+		}
+		limit, err := parseRate(val)
+		if err != nil {
+			return r, err
+		}
+		switch name {
+		case "download":
+			r.Download = limit
+		case "upload":
+			r.Upload = limit
+		default:
+			return r, fmt.Errorf("unknown rate class %q, expected download or upload", name)
+		}
+	}
+	if r.Download == 0 && r.Upload == 0 {
+		return r, fmt.Errorf("rule has no download or upload rate")
+	}
+	return r, nil
+}
+
+func parseWeekdays(s string) (uint8, error) {
+	day := func(name string) (int, error) {
+		for i, d := range weekdays {
+			if d == strings.ToLower(name) {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown day %q", name)
+	}
+	var res uint8
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := day(from)
+		if err != nil {
+			return 0, err
+		}
+		last := first
+		if isRange {
+			if last, err = day(to); err != nil {
+				return 0, err
+			}
+		}
+		for i := first; ; i = (i + 1) % 7 { // `fri-mon` is allowed
+			res |= 1 << i
+			if i == last {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func parseRate(s string) (rate.Limit, error) {
+	if s == "inf" {
+		return rate.Inf, nil
+	}
+	var size datasize.ByteSize
+	if err := size.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %w", s, err)
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("invalid rate %q: must be > 0", s)
+	}
+	return rate.Limit(size.Bytes()), nil
+}
+
+func formatRate(l rate.Limit) string {
+	if l == rate.Inf {
+		return "inf"
+	}
+	return datasize.ByteSize(l).String()
+}
+
+func (r BandwidthRule) match(now time.Time) bool {
+	tod := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+	day := func(d time.Weekday) bool { return r.Days&(1<<d) != 0 }
+	switch {
+	case r.From == r.To:
+		return day(now.Weekday())
+	case r.From < r.To:
+		return tod >= r.From && tod < r.To && day(now.Weekday())
+	case tod >= r.From:
+		return day(now.Weekday())
+	case tod < r.To: // range started yesterday
+		return day((now.Weekday() + 6) % 7)
+	default:
+		return false
+	}
+}
+
+func (r BandwidthRule) String() string {
+	var b strings.Builder
+	if r.Days != allDays {
+		var days []string
+		for i, d := range weekdays {
+			if r.Days&(1<<i) != 0 {
+				days = append(days, d)
+			}
+		}
+		b.WriteString(strings.Join(days, ",") + " ")
+	}
+	fmt.Fprintf(&b, "%02d:%02d-%02d:%02d", int(r.From.Hours()), int(r.From.Minutes())%60, int(r.To.Hours()), int(r.To.Minutes())%60)
+	if r.Download != 0 {
+		b.WriteString(" download=" + formatRate(r.Download))
+	}
+	if r.Upload != 0 {
+		b.WriteString(" upload=" + formatRate(r.Upload))
+	}
+	return b.String()
+}
+
+func (s *BandwidthSchedule) String() string {
+	if s == nil {
+		return ""
+	}
+	rules := make([]string, len(s.Rules))
+	for i, r := range s.Rules {
+		rules[i] = r.String()
+	}
+	return strings.Join(rules, "; ")
+}
+
+// Limits - limits at `now`. download, upload - used if no rule matches
+func (s *BandwidthSchedule) Limits(now time.Time, download, upload rate.Limit) (rate.Limit, rate.Limit) {
+	if s == nil {
+		return download, upload
+	}
+	var downloadSet, uploadSet bool
+	for _, r := range s.Rules {
+		if !r.match(now) {
+			continue
+		}
+		if r.Download != 0 && !downloadSet {
+			download, downloadSet = r.Download, true
+		}
+		if r.Upload != 0 && !uploadSet {
+			upload, uploadSet = r.Upload, true
+		}
+	}
+	return download, upload
+}
+
+const bandwidthCheckInterval = time.Minute
+
+// bandwidth - limits of torrent client configured by flags, changed by schedule at runtime
+type bandwidth struct {
+	lock             sync.Mutex
+	schedule         *BandwidthSchedule
+	download, upload rate.Limit // configured: used outside of schedule rules
+	activeDownload   rate.Limit
+	activeUpload     rate.Limit
+}
+
+func (d *Downloader) initBandwidth(schedule *BandwidthSchedule) {
+	b := &d.bandwidth
+	b.download, b.upload = rate.Inf, rate.Inf
+	if l := d.cfg.ClientConfig.DownloadRateLimiter; l != nil {
+		b.download = l.Limit()
+	}
+	if l := d.cfg.ClientConfig.UploadRateLimiter; l != nil {
+		b.upload = l.Limit()
+	}
+	b.activeDownload, b.activeUpload = b.download, b.upload
+	b.schedule = schedule
+	if len(schedule.Rules) == 0 {
+		return
+	}
+	d.applyBandwidth(time.Now())
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.bandwidthLoop()
+	}()
+}
+
+func (d *Downloader) bandwidthLoop() {
+	ticker := time.NewTicker(bandwidthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.applyBandwidth(now)
+		}
+	}
+}
+
+// BandwidthSchedule - current schedule, empty if not set
+func (d *Downloader) BandwidthSchedule() *BandwidthSchedule {
+	d.bandwidth.lock.Lock()
+	defer d.bandwidth.lock.Unlock()
+	return d.bandwidth.schedule
+}
+
+// SetBandwidthSchedule - replaces schedule at runtime, new limits are applied immediately
+func (d *Downloader) SetBandwidthSchedule(schedule *BandwidthSchedule) {
+	d.bandwidth.lock.Lock()
+	hadRules := len(d.bandwidth.schedule.Rules) > 0
+	d.bandwidth.schedule = schedule
+	d.bandwidth.lock.Unlock()
+
+	d.applyBandwidth(time.Now())
+	if !hadRules && len(schedule.Rules) > 0 {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.bandwidthLoop()
+		}()
+	}
+	d.logger.Info("[snapshots] bandwidth schedule changed", "schedule", schedule.String())
+}
+
+// SetBandwidthLimits - replaces configured limits at runtime, used outside of schedule rules. nil - keep current
+func (d *Downloader) SetBandwidthLimits(download, upload *rate.Limit) {
+	d.bandwidth.lock.Lock()
+	if download != nil {
+		d.bandwidth.download = *download
+	}
+	if upload != nil {
+		d.bandwidth.upload = *upload
+	}
+	d.bandwidth.lock.Unlock()
+
+	d.applyBandwidth(time.Now())
+}
+
+func (d *Downloader) applyBandwidth(now time.Time) {
+	b := &d.bandwidth
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	download, upload := b.schedule.Limits(now, b.download, b.upload)
+	if download == b.activeDownload && upload == b.activeUpload {
+		return
+	}
+	b.activeDownload, b.activeUpload = download, upload
+
+	if l := d.cfg.ClientConfig.UploadRateLimiter; l != nil {
+		l.SetLimit(upload)
+	}
+	if l := d.cfg.ClientConfig.DownloadRateLimiter; l != nil {
+		d.lock.Lock()
+		if d.downloadLimit != nil {
+			*d.downloadLimit = download
+		}
+		if download == rate.Inf || download <= d.webDownloadShare {
+			l.SetLimit(download)
+		} else {
+			l.SetLimit(download - d.webDownloadShare)
+		}
+		d.lock.Unlock()
+	}
+	d.logger.Info("[snapshots] bandwidth", "download", formatRate(download), "upload", formatRate(upload))
+}
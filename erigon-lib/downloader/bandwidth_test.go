@@ -0,0 +1,116 @@
+package downloader
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
+)
+
+func TestBandwidthSchedule(t *testing.T) {
+	s, err := ParseBandwidthSchedule("mon-fri 09:00-18:00 download=6mb upload=1mb; sat,sun 22:00-06:00 download=inf; 00:00-24:00 upload=512kb")
+	require.NoError(t, err)
+	require.Equal(t, "mon,tue,wed,thu,fri 09:00-18:00 download=6MB upload=1MB; sun,sat 22:00-06:00 download=inf; 00:00-24:00 upload=512KB", s.String())
+	reparsed, err := ParseBandwidthSchedule(s.String())
+	require.NoError(t, err)
+	require.Equal(t, s, reparsed)
+
+	const defDownload, defUpload = rate.Limit(100), rate.Limit(200)
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 30, 0, 0, time.UTC) } // 2024-01-01 is monday
+	check := func(now time.Time, download, upload rate.Limit) {
+		t.Helper()
+		d, u := s.Limits(now, defDownload, defUpload)
+		require.Equal(t, download, d, now.String())
+		require.Equal(t, upload, u, now.String())
+	}
+	check(at(1, 10), 6<<20, 1<<20) // monday, business hours
+	check(at(1, 20), defDownload, 512<<10)
+	check(at(6, 23), rate.Inf, 512<<10) // saturday night
+	check(at(7, 3), rate.Inf, 512<<10)  // started on saturday
+	check(at(8, 3), rate.Inf, 512<<10)  // started on sunday
+	check(at(9, 3), defDownload, 512<<10)
+
+	var empty *BandwidthSchedule
+	d, u := empty.Limits(at(1, 10), defDownload, defUpload)
+	require.Equal(t, defDownload, d)
+	require.Equal(t, defUpload, u)
+
+	for _, bad := range []string{"09:00-18:00", "mon 09:00 download=1mb", "xyz 09:00-18:00 download=1mb", "09:00-25:00 download=1mb", "09:00-18:00 download=0", "09:00-18:00 fetch=1mb"} {
+		_, err := ParseBandwidthSchedule(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestBandwidthGrpc(t *testing.T) {
+	cfg := &downloadercfg.Cfg{ClientConfig: torrent.NewDefaultClientConfig()}
+	cfg.ClientConfig.DownloadRateLimiter = rate.NewLimiter(100, downloadercfg.DefaultNetworkChunkSize)
+	cfg.ClientConfig.UploadRateLimiter = rate.NewLimiter(200, downloadercfg.DefaultNetworkChunkSize)
+	downloadLimit := cfg.ClientConfig.DownloadRateLimiter.Limit()
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Downloader{cfg: cfg, lock: &sync.RWMutex{}, ctx: ctx, stopMainLoop: cancel, logger: log.New(), downloadLimit: &downloadLimit}
+	d.initBandwidth(&BandwidthSchedule{})
+	defer func() {
+		d.stopMainLoop()
+		d.wg.Wait()
+	}()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto_downloader.RegisterDownloaderServer(server, &GrpcServer{d: d})
+	go server.Serve(lis) //nolint:errcheck
+	defer server.Stop()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := proto_downloader.NewDownloaderClient(conn)
+	set := func(schedule string) (string, error) {
+		s, err := ParseBandwidthSchedule(schedule)
+		require.NoError(t, err)
+		reply, err := client.SetBandwidthSchedule(ctx, &proto_downloader.SetBandwidthScheduleRequest{Rules: BandwidthScheduleToProto(s)})
+		if err != nil {
+			return "", err
+		}
+		applied, err := BandwidthScheduleFromProto(reply.Rules)
+		require.NoError(t, err)
+		return applied.String(), nil
+	}
+
+	reply, err := client.BandwidthSchedule(ctx, &proto_downloader.BandwidthScheduleRequest{})
+	require.NoError(t, err)
+	require.Empty(t, reply.Rules)
+
+	_, err = client.SetBandwidthSchedule(ctx, &proto_downloader.SetBandwidthScheduleRequest{Rules: []*proto_downloader.BandwidthRule{{From: 24 * 60 * 60, Download: 1}}})
+	require.Error(t, err)
+	_, err = client.SetBandwidthSchedule(ctx, &proto_downloader.SetBandwidthScheduleRequest{Rules: []*proto_downloader.BandwidthRule{{}}})
+	require.Error(t, err)
+
+	schedule, err := set("mon-fri 09:00-18:00 download=6mb; 00:00-24:00 download=1kb upload=inf")
+	require.NoError(t, err)
+	require.Equal(t, "mon,tue,wed,thu,fri 09:00-18:00 download=6MB; 00:00-24:00 download=1KB upload=inf", schedule)
+
+	schedule, err = set("00:00-24:00 download=1kb upload=inf")
+	require.NoError(t, err)
+	require.Equal(t, "00:00-24:00 download=1KB upload=inf", schedule)
+	require.Equal(t, rate.Limit(1024), cfg.ClientConfig.DownloadRateLimiter.Limit())
+	require.Equal(t, rate.Limit(1024), downloadLimit)
+	require.Equal(t, rate.Inf, cfg.ClientConfig.UploadRateLimiter.Limit())
+
+	// back to configured limits
+	_, err = set("")
+	require.NoError(t, err)
+	require.Equal(t, rate.Limit(100), cfg.ClientConfig.DownloadRateLimiter.Limit())
+	require.Equal(t, rate.Limit(200), cfg.ClientConfig.UploadRateLimiter.Limit())
+}
@@ -57,6 +57,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/diagnostics"
 	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
 	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
@@ -94,6 +95,9 @@ type Downloader struct {
 	webDownloadInfo map[string]webDownloadInfo
 	downloading     map[string]struct{}
 	downloadLimit   *rate.Limit
+	// webDownloadShare - part of downloadLimit moved from torrent client to web downloads
+	webDownloadShare rate.Limit
+	bandwidth        bandwidth
 }
 
 type webDownloadInfo struct {
@@ -133,11 +137,13 @@ type AggStats struct {
 
 	lastTorrentStatus time.Time
 	downloadProgress  map[string]downloadProgress
+	files             map[string]FileProgress // see Downloader.Progress
 }
 
 type requestHandler struct {
 	http.Transport
 	downloader *Downloader
+	s3         *s3Signer
 }
 
 var cloudflareHeaders = http.Header{
@@ -189,6 +195,10 @@ func (r *requestHandler) RoundTrip(req *http.Request) (resp *http.Response, err
 	}()
 
 	insertCloudflareHeaders(req)
+	if r.s3.signs(req) {
+		req = req.Clone(req.Context())
+		r.s3.sign(req, time.Now())
+	}
 
 	resp, err = r.Transport.RoundTrip(req)
 
@@ -259,7 +269,16 @@ func (r *requestHandler) RoundTrip(req *http.Request) (resp *http.Response, err
 }
 
 func New(ctx context.Context, cfg *downloadercfg.Cfg, logger log.Logger, verbosity log.Lvl, discover bool) (*Downloader, error) {
+	webseedUrls, s3, err := resolveS3WebSeeds(cfg.WebSeedUrls, cfg.WebSeedS3)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := ParseBandwidthSchedule(cfg.BandwidthSchedule)
+	if err != nil {
+		return nil, err
+	}
 	requestHandler := &requestHandler{
+		s3: s3,
 		Transport: http.Transport{
 			Proxy:       cfg.ClientConfig.HTTPProxy,
 			DialContext: cfg.ClientConfig.HTTPDialContext,
@@ -308,7 +327,7 @@ func New(ctx context.Context, cfg *downloadercfg.Cfg, logger log.Logger, verbosi
 		torrentClient:       torrentClient,
 		lock:                mutex,
 		stats:               stats,
-		webseeds:            NewWebSeeds(cfg.WebSeedUrls, verbosity, logger),
+		webseeds:            NewWebSeeds(webseedUrls, verbosity, logger),
 		logger:              logger,
 		verbosity:           verbosity,
 		torrentFS:           &AtomicTorrentFS{dir: cfg.Dirs.Snap},
@@ -319,6 +338,8 @@ func New(ctx context.Context, cfg *downloadercfg.Cfg, logger log.Logger, verbosi
 		webseedsDiscover:    discover,
 	}
 	d.webseeds.SetTorrent(d.torrentFS, snapLock.Downloads, cfg.DownloadTorrentFilesFromWebseed)
+	d.webseeds.s3 = s3
+	d.webseeds.releaseKeys = cfg.ReleaseKeys
 
 	requestHandler.downloader = d
 
@@ -328,6 +349,7 @@ func New(ctx context.Context, cfg *downloadercfg.Cfg, logger log.Logger, verbosi
 	}
 
 	d.ctx, d.stopMainLoop = context.WithCancel(ctx)
+	d.initBandwidth(schedule)
 
 	if cfg.AddTorrentsFromDisk {
 		var downloadMismatches []string
@@ -781,6 +803,13 @@ func fileHashBytes(ctx context.Context, fileInfo snaptype.FileInfo, stats *AggSt
 }
 
 func (d *Downloader) MainLoopInBackground(silent bool) {
+	if d.cfg.SeedOnly {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.seedRescanLoop()
+		}()
+	}
 	d.wg.Add(1)
 	go func() {
 		defer d.wg.Done()
@@ -980,7 +1009,7 @@ func (d *Downloader) mainLoop(silent bool) error {
 					continue
 				}
 
-				if downloading {
+				if downloading || d.cfg.SeedOnly {
 					continue
 				}
 
@@ -1448,14 +1477,14 @@ func (d *Downloader) getWebDownloadInfo(t *torrent.Torrent) (webDownloadInfo, []
 	return infos[0], seedHashMismatches, nil
 }
 
-func getWebpeerTorrentInfo(ctx context.Context, downloadUrl *url.URL) (*metainfo.MetaInfo, error) {
+func getWebpeerTorrentInfo(ctx context.Context, s3 *s3Signer, downloadUrl *url.URL) (*metainfo.MetaInfo, error) {
 	torrentRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadUrl.String()+".torrent", nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	torrentResponse, err := http.DefaultClient.Do(torrentRequest)
+	torrentResponse, err := s3.do(torrentRequest)
 
 	if err != nil {
 		return nil, err
@@ -1530,7 +1559,7 @@ func (d *Downloader) webDownload(peerUrls []*url.URL, t *torrent.Torrent, i *web
 		return nil, fmt.Errorf("webdownload client not enabled")
 	}
 
-	peerUrl, err := selectDownloadPeer(d.ctx, peerUrls, t)
+	peerUrl, err := selectDownloadPeer(d.ctx, d.webseeds.s3, peerUrls, t)
 
 	if err != nil {
 		return nil, err
@@ -1602,28 +1631,38 @@ func (d *Downloader) webDownload(peerUrls []*url.URL, t *torrent.Torrent, i *web
 		}
 
 		if d.downloadLimit != nil {
-			limit := float64(*d.downloadLimit) / float64(d.cfg.DownloadSlots)
+			var limit rate.Limit
 
 			func() {
 				d.lock.Lock()
 				defer d.lock.Unlock()
 
+				// limit may be changed by bandwidth schedule
+				limit = *d.downloadLimit / rate.Limit(d.cfg.DownloadSlots)
+				if limit == rate.Inf {
+					return
+				}
 				torrentLimit := d.cfg.ClientConfig.DownloadRateLimiter.Limit()
 				rcloneLimit := d.webDownloadClient.GetBwLimit()
 
-				d.cfg.ClientConfig.DownloadRateLimiter.SetLimit(torrentLimit - rate.Limit(limit))
-				d.webDownloadClient.SetBwLimit(d.ctx, rcloneLimit+rate.Limit(limit))
+				d.cfg.ClientConfig.DownloadRateLimiter.SetLimit(torrentLimit - limit)
+				d.webDownloadClient.SetBwLimit(d.ctx, rcloneLimit+limit)
+				d.webDownloadShare += limit
 			}()
 
 			defer func() {
+				if limit == rate.Inf {
+					return
+				}
 				d.lock.Lock()
 				defer d.lock.Unlock()
 
 				torrentLimit := d.cfg.ClientConfig.DownloadRateLimiter.Limit()
 				rcloneLimit := d.webDownloadClient.GetBwLimit()
 
-				d.cfg.ClientConfig.DownloadRateLimiter.SetLimit(torrentLimit + rate.Limit(limit))
-				d.webDownloadClient.SetBwLimit(d.ctx, rcloneLimit-rate.Limit(limit))
+				d.cfg.ClientConfig.DownloadRateLimiter.SetLimit(torrentLimit + limit)
+				d.webDownloadClient.SetBwLimit(d.ctx, rcloneLimit-limit)
+				d.webDownloadShare -= limit
 			}()
 		}
 
@@ -1661,14 +1700,14 @@ func (d *Downloader) webDownload(peerUrls []*url.URL, t *torrent.Torrent, i *web
 	return session, nil
 }
 
-func selectDownloadPeer(ctx context.Context, peerUrls []*url.URL, t *torrent.Torrent) (string, error) {
+func selectDownloadPeer(ctx context.Context, s3 *s3Signer, peerUrls []*url.URL, t *torrent.Torrent) (string, error) {
 	switch len(peerUrls) {
 	case 0:
 		return "", fmt.Errorf("no download peers")
 
 	case 1:
 		downloadUrl := peerUrls[0].JoinPath(t.Name())
-		peerInfo, err := getWebpeerTorrentInfo(ctx, downloadUrl)
+		peerInfo, err := getWebpeerTorrentInfo(ctx, s3, downloadUrl)
 
 		if err == nil && bytes.Equal(peerInfo.HashInfoBytes().Bytes(), t.InfoHash().Bytes()) {
 			return peerUrls[0].String(), nil
@@ -1678,7 +1717,7 @@ func selectDownloadPeer(ctx context.Context, peerUrls []*url.URL, t *torrent.Tor
 		peerIndex := rand.Intn(len(peerUrls))
 		peerUrl := peerUrls[peerIndex]
 		downloadUrl := peerUrl.JoinPath(t.Name())
-		peerInfo, err := getWebpeerTorrentInfo(ctx, downloadUrl)
+		peerInfo, err := getWebpeerTorrentInfo(ctx, s3, downloadUrl)
 
 		if err == nil && bytes.Equal(peerInfo.HashInfoBytes().Bytes(), t.InfoHash().Bytes()) {
 			return peerUrl.String(), nil
@@ -1688,7 +1727,7 @@ func selectDownloadPeer(ctx context.Context, peerUrls []*url.URL, t *torrent.Tor
 			if i == peerIndex {
 				continue
 			}
-			peerInfo, err := getWebpeerTorrentInfo(ctx, downloadUrl)
+			peerInfo, err := getWebpeerTorrentInfo(ctx, s3, downloadUrl)
 
 			if err == nil && bytes.Equal(peerInfo.HashInfoBytes().Bytes(), t.InfoHash().Bytes()) {
 				return peerUrl.String(), nil
@@ -1849,6 +1888,7 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 
 	var zeroProgress []string
 	var noMetadata []string
+	files := make(map[string]FileProgress, len(torrents))
 
 	isDiagEnabled := diagnostics.TypeOf(diagnostics.SnapshoFilesList{}).Enabled()
 	if isDiagEnabled {
@@ -1875,6 +1915,7 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 		default: // if some torrents have no metadata, we are for-sure uncomplete
 			stats.Completed = false
 			noMetadata = append(noMetadata, t.Name())
+			files[t.Name()] = FileProgress{Name: t.Name(), State: FileQueued}
 			continue
 		}
 
@@ -1948,12 +1989,25 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 			d.logger.Log(d.verbosity, "[snapshots] bittorrent peers", rates...)
 		}
 
+		fp := FileProgress{Name: torrentName, BytesTotal: uint64(tLen), BytesCompleted: uint64(bytesCompleted), Peers: len(peersOfThisFile), Webseeds: len(weebseedPeersOfThisFile)}
+		for _, p := range peers {
+			fp.PeersRate += p.DownloadRate
+		}
+		for _, p := range webseeds {
+			fp.WebseedsRate += p.DownloadRate
+		}
+		fp.State = torrentFileState(t, torrentComplete, bytesCompleted, fp.Peers+fp.Webseeds)
+		fp.calcEta()
+		files[torrentName] = fp
+
 		diagnostics.Send(diagnostics.SegmentDownloadStatistics{
 			Name:            torrentName,
 			TotalBytes:      uint64(tLen),
 			DownloadedBytes: uint64(bytesCompleted),
 			Webseeds:        webseeds,
 			Peers:           peers,
+			State:           string(fp.State),
+			Eta:             fp.Eta.Seconds(),
 		})
 
 		stats.Completed = stats.Completed && torrentComplete
@@ -2013,16 +2067,31 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 					d.logger.Log(d.verbosity, "[snapshots] web peers", webseedRates...)
 				}
 
+				fp := FileProgress{Name: transferName, State: FileDownloading, BytesTotal: tLen, BytesCompleted: bytesCompleted, Webseeds: len(seeds)}
+				for _, p := range seeds {
+					fp.WebseedsRate += p.DownloadRate
+				}
+				fp.calcEta()
+				files[transferName] = fp
+
 				diagnostics.Send(diagnostics.SegmentDownloadStatistics{
 					Name:            transferName,
 					TotalBytes:      tLen,
 					DownloadedBytes: bytesCompleted,
 					Webseeds:        seeds,
+					State:           string(fp.State),
+					Eta:             fp.Eta.Seconds(),
 				})
 			}
 		}
 	}
 
+	for file := range downloading { // web downloads which are not started yet
+		if _, ok := files[file]; !ok {
+			files[file] = FileProgress{Name: file, State: FileQueued}
+		}
+	}
+
 	if len(downloading) > 0 {
 		if d.webDownloadClient != nil {
 			webTransfers += int32(len(downloading))
@@ -2129,6 +2198,8 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 
 	stats.PeersUnique = int32(len(peers))
 	stats.FilesTotal = int32(len(torrents)) + webTransfers
+	stats.files = files
+	updateSeedMetrics(&stats)
 
 	d.stats = stats
 }
@@ -2368,6 +2439,9 @@ func (d *Downloader) AddMagnetLink(ctx context.Context, infoHash metainfo.Hash,
 	if d.alreadyHaveThisName(name) || !IsSnapNameAllowed(name) {
 		return nil
 	}
+	if err := disk.CheckFreeSpace(disk.FreeSpaceLow); err != nil {
+		return fmt.Errorf("new download %s: %w", name, err)
+	}
 	isProhibited, err := d.torrentFS.NewDownloadsAreProhibited(name)
 	if err != nil {
 		return err
@@ -2377,6 +2451,11 @@ func (d *Downloader) AddMagnetLink(ctx context.Context, infoHash metainfo.Hash,
 		return nil
 	}
 
+	linked, err := d.linkSameContent(name)
+	if err != nil {
+		d.logger.Debug("[snapshots] link file with same content", "name", name, "err", err)
+	}
+
 	mi := &metainfo.MetaInfo{AnnounceList: Trackers}
 	magnet := mi.Magnet(&infoHash, &metainfo.Info{Name: name})
 	spec, err := torrent.TorrentSpecFromMagnetUri(magnet.String())
@@ -2426,6 +2505,9 @@ func (d *Downloader) AddMagnetLink(ctx context.Context, infoHash metainfo.Hash,
 			d.logger.Warn("[snapshots] create torrent file", "err", err)
 			return
 		}
+		if linked { // pieces of linked file are not in piece completion db yet
+			t.VerifyData()
+		}
 
 		urls, ok := d.webseeds.ByFileName(t.Name())
 		if ok {
@@ -2519,7 +2601,7 @@ func (d *Downloader) addTorrentFilesFromDisk(quiet bool) error {
 			}
 		}
 
-		if whitelisted, ok := d.webseeds.torrentsWhitelist.Get(ts.DisplayName); ok {
+		if whitelisted, ok := d.webseeds.whitelist().Get(ts.DisplayName); ok {
 			if ts.InfoHash.HexString() != whitelisted.Hash {
 				continue
 			}
@@ -2617,6 +2699,7 @@ func openClient(ctx context.Context, dbDir, snapDir string, cfg *torrent.ClientC
 	// - MMAP files are pre-allocated - which is not cool, but: 1. we can live with it 2. maybe can just resize MMAP in future
 	// See also: https://github.com/ledgerwatch/erigon/pull/10074
 	m = storage.NewMMapWithCompletion(snapDir, c)
+	disk.TagWritable(snapDir, disk.Torrent) // same files are mapped read-only by snapshots and domains
 	//m = storage.NewFileOpts(storage.NewFileClientOpts{
 	//	ClientBaseDir:   snapDir,
 	//	PieceCompletion: c,
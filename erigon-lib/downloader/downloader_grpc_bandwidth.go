@@ -0,0 +1,109 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
+)
+
+func (s *GrpcServer) BandwidthSchedule(ctx context.Context, _ *proto_downloader.BandwidthScheduleRequest) (*proto_downloader.BandwidthScheduleReply, error) {
+	return &proto_downloader.BandwidthScheduleReply{Rules: BandwidthScheduleToProto(s.d.BandwidthSchedule())}, nil
+}
+
+func (s *GrpcServer) SetBandwidthSchedule(ctx context.Context, req *proto_downloader.SetBandwidthScheduleRequest) (*proto_downloader.BandwidthScheduleReply, error) {
+	schedule, err := BandwidthScheduleFromProto(req.Rules)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.d.SetBandwidthSchedule(schedule)
+	return &proto_downloader.BandwidthScheduleReply{Rules: BandwidthScheduleToProto(schedule)}, nil
+}
+
+func BandwidthScheduleToProto(s *BandwidthSchedule) []*proto_downloader.BandwidthRule {
+	if s == nil {
+		return nil
+	}
+	rules := make([]*proto_downloader.BandwidthRule, len(s.Rules))
+	for i, r := range s.Rules {
+		days := uint32(r.Days)
+		if r.Days == allDays {
+			days = 0
+		}
+		rules[i] = &proto_downloader.BandwidthRule{
+			Days:     days,
+			From:     uint32(r.From / time.Second),
+			To:       uint32(r.To / time.Second),
+			Download: limitToProto(r.Download),
+			Upload:   limitToProto(r.Upload),
+		}
+	}
+	return rules
+}
+
+// BandwidthScheduleFromProto - validates rules same way as ParseBandwidthSchedule
+func BandwidthScheduleFromProto(rules []*proto_downloader.BandwidthRule) (*BandwidthSchedule, error) {
+	res := &BandwidthSchedule{}
+	for i, r := range rules {
+		if r.Days > allDays {
+			return nil, fmt.Errorf("bandwidth schedule rule %d: bad days %b", i, r.Days)
+		}
+		if r.From >= 24*60*60 || r.To > 24*60*60 {
+			return nil, fmt.Errorf("bandwidth schedule rule %d: time of day out of range", i)
+		}
+		if math.IsNaN(r.Download) || r.Download < 0 || math.IsNaN(r.Upload) || r.Upload < 0 {
+			return nil, fmt.Errorf("bandwidth schedule rule %d: bad rate", i)
+		}
+		if r.Download == 0 && r.Upload == 0 {
+			return nil, fmt.Errorf("bandwidth schedule rule %d: rule has no download or upload rate", i)
+		}
+		days := uint8(r.Days)
+		if days == 0 {
+			days = allDays
+		}
+		res.Rules = append(res.Rules, BandwidthRule{
+			Days:     days,
+			From:     time.Duration(r.From) * time.Second,
+			To:       time.Duration(r.To) * time.Second,
+			Download: limitFromProto(r.Download),
+			Upload:   limitFromProto(r.Upload),
+		})
+	}
+	return res, nil
+}
+
+func limitToProto(l rate.Limit) float64 {
+	if l == rate.Inf {
+		return math.Inf(1)
+	}
+	return float64(l)
+}
+
+func limitFromProto(v float64) rate.Limit {
+	if math.IsInf(v, 1) {
+		return rate.Inf
+	}
+	return rate.Limit(v)
+}
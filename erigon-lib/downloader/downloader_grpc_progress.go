@@ -0,0 +1,97 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"time"
+
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
+)
+
+// Progress - per-file state for dashboards: `Stats` has only totals
+func (s *GrpcServer) Progress(ctx context.Context, _ *proto_downloader.ProgressRequest) (*proto_downloader.ProgressReply, error) {
+	return ProgressReportToProto(s.d.Progress()), nil
+}
+
+var fileStateToProto = map[FileState]proto_downloader.FileProgress_State{
+	FileQueued:      proto_downloader.FileProgress_QUEUED,
+	FileDownloading: proto_downloader.FileProgress_DOWNLOADING,
+	FileVerifying:   proto_downloader.FileProgress_VERIFYING,
+	FileDone:        proto_downloader.FileProgress_DONE,
+}
+
+var fileStateFromProto = map[proto_downloader.FileProgress_State]FileState{
+	proto_downloader.FileProgress_QUEUED:      FileQueued,
+	proto_downloader.FileProgress_DOWNLOADING: FileDownloading,
+	proto_downloader.FileProgress_VERIFYING:   FileVerifying,
+	proto_downloader.FileProgress_DONE:        FileDone,
+}
+
+func ProgressReportToProto(r ProgressReport) *proto_downloader.ProgressReply {
+	reply := &proto_downloader.ProgressReply{
+		Files:          make([]*proto_downloader.FileProgress, len(r.Files)),
+		BytesTotal:     r.BytesTotal,
+		BytesCompleted: r.BytesCompleted,
+		DownloadRate:   r.DownloadRate,
+		Eta:            uint64(r.Eta / time.Second),
+		Completed:      r.Completed,
+	}
+	for i, f := range r.Files {
+		reply.Files[i] = &proto_downloader.FileProgress{
+			Name:           f.Name,
+			State:          fileStateToProto[f.State],
+			BytesTotal:     f.BytesTotal,
+			BytesCompleted: f.BytesCompleted,
+			Peers:          uint32(f.Peers),
+			Webseeds:       uint32(f.Webseeds),
+			PeersRate:      f.PeersRate,
+			WebseedsRate:   f.WebseedsRate,
+			Eta:            uint64(f.Eta / time.Second),
+		}
+	}
+	return reply
+}
+
+func ProgressReportFromProto(reply *proto_downloader.ProgressReply) ProgressReport {
+	r := ProgressReport{
+		Files:          make([]FileProgress, len(reply.Files)),
+		BytesTotal:     reply.BytesTotal,
+		BytesCompleted: reply.BytesCompleted,
+		DownloadRate:   reply.DownloadRate,
+		Eta:            time.Duration(reply.Eta) * time.Second,
+		Completed:      reply.Completed,
+	}
+	for i, f := range reply.Files {
+		state, ok := fileStateFromProto[f.State]
+		if !ok {
+			state = FileState(f.State.String())
+		}
+		r.Files[i] = FileProgress{
+			Name:           f.Name,
+			State:          state,
+			BytesTotal:     f.BytesTotal,
+			BytesCompleted: f.BytesCompleted,
+			Peers:          int(f.Peers),
+			Webseeds:       int(f.Webseeds),
+			PeersRate:      f.PeersRate,
+			WebseedsRate:   f.WebseedsRate,
+			Eta:            time.Duration(f.Eta) * time.Second,
+		}
+	}
+	return r
+}
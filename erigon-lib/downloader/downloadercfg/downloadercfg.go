@@ -17,6 +17,7 @@
 package downloadercfg
 
 import (
+	"crypto/ed25519"
 	"net"
 	"net/url"
 	"os"
@@ -51,12 +52,21 @@ type Cfg struct {
 
 	WebSeedUrls                     []*url.URL
 	WebSeedFiles                    []string
+	WebSeedS3                       S3 // for s3:// and gs:// webseeds
 	SnapshotConfig                  *snapcfg.Cfg
 	DownloadTorrentFilesFromWebseed bool
 	AddTorrentsFromDisk             bool
 	SnapshotLock                    bool
 	ChainName                       string
 
+	// ReleaseKeys - if set: http webseeds are used only if they serve manifest of hashes signed by one of keys,
+	// hashes from signed manifest are trusted in addition to preverified list of binary
+	ReleaseKeys []ed25519.PublicKey
+	// BandwidthSchedule - time-of-day limits of download/upload rate, see downloader.BandwidthSchedule. Can be changed by gRPC API
+	BandwidthSchedule string
+	// SeedOnly - never download: only seed complete files which exist on disk. New files copied to datadir are picked up periodically
+	SeedOnly bool
+
 	Dirs datadir.Dirs
 }
 
@@ -96,6 +106,14 @@ func Default() *torrent.ClientConfig {
 	return torrentConfig
 }
 
+// RateLimit - limit of --torrent.download.rate/--torrent.upload.rate, above 512mb is unlimited
+func RateLimit(r datasize.ByteSize) rate.Limit {
+	if r > 512*datasize.MB {
+		return rate.Inf
+	}
+	return rate.Limit(r.Bytes())
+}
+
 func New(dirs datadir.Dirs, version string, verbosity lg.Level, downloadRate, uploadRate datasize.ByteSize, port, connsPerFile, downloadSlots int, staticPeers, webseeds []string, chainName string, lockSnapshots bool) (*Cfg, error) {
 	torrentConfig := Default()
 	//torrentConfig.PieceHashersPerTorrent = runtime.NumCPU()
@@ -110,17 +128,8 @@ func New(dirs datadir.Dirs, version string, verbosity lg.Level, downloadRate, up
 	// check if ipv6 is enabled
 	torrentConfig.DisableIPv6 = !getIpv6Enabled()
 
-	if uploadRate > 512*datasize.MB {
-		torrentConfig.UploadRateLimiter = rate.NewLimiter(rate.Inf, DefaultNetworkChunkSize) // default: unlimited
-	} else {
-		torrentConfig.UploadRateLimiter = rate.NewLimiter(rate.Limit(uploadRate.Bytes()), DefaultNetworkChunkSize) // default: unlimited
-	}
-
-	if downloadRate > 512*datasize.MB {
-		torrentConfig.DownloadRateLimiter = rate.NewLimiter(rate.Inf, DefaultNetworkChunkSize) // default: unlimited
-	} else {
-		torrentConfig.DownloadRateLimiter = rate.NewLimiter(rate.Limit(downloadRate.Bytes()), DefaultNetworkChunkSize) // default: unlimited
-	}
+	torrentConfig.UploadRateLimiter = rate.NewLimiter(RateLimit(uploadRate), DefaultNetworkChunkSize)
+	torrentConfig.DownloadRateLimiter = rate.NewLimiter(RateLimit(downloadRate), DefaultNetworkChunkSize)
 
 	// debug
 	//torrentConfig.Debug = true
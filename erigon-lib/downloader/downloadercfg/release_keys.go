@@ -0,0 +1,69 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloadercfg
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseReleaseKeys - comma-separated hex-encoded ed25519 public keys. Empty string - no keys.
+func ParseReleaseKeys(s string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, err := hex.DecodeString(strings.TrimPrefix(part, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("release key %q: %w", part, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("release key %q: expected %d bytes, got %d", part, ed25519.PublicKeySize, len(key))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// LoadReleaseKey - reads hex-encoded ed25519 seed from file. If file doesn't exist: creates new key (created=true).
+func LoadReleaseKey(fPath string) (key ed25519.PrivateKey, created bool, err error) {
+	seed, err := os.ReadFile(fPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if _, key, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			return nil, false, err
+		}
+		if err := os.WriteFile(fPath, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+			return nil, false, err
+		}
+		return key, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	seed, err = hex.DecodeString(strings.TrimSpace(string(seed)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, false, fmt.Errorf("release key file %s: expected hex-encoded %d bytes seed", fPath, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), false, nil
+}
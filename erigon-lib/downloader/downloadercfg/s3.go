@@ -0,0 +1,97 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloadercfg
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	S3Scheme  = "s3"
+	GCSScheme = "gs"
+
+	defaultS3Region   = "us-east-1"
+	defaultGCSEnpoint = "https://storage.googleapis.com"
+)
+
+// S3 - access to webseeds in S3-compatible buckets (AWS S3, GCS via HMAC keys, R2, MinIO, ...): `--webseed=s3://bucket/prefix`.
+// Allows to mirror snapshots in own (private) bucket: mirror is used before public webseeds, BitTorrent peers stay as fallback.
+// Bucket must have same layout as public webseeds: data files, .torrent files and manifest.txt
+type S3 struct {
+	Endpoint      string // empty - AWS: https://<bucket>.s3.<region>.amazonaws.com. Otherwise path-style: <endpoint>/<bucket>/<prefix>
+	Region        string
+	AccessKey     string
+	SecretKey     string
+	SessionToken  string
+	RequesterPays bool // bucket owner doesn't pay for traffic: `x-amz-request-payer: requester`
+}
+
+// S3FromEnv - credentials are not accepted by cli flags (visible in `ps` output), only by standard AWS env variables
+func S3FromEnv() S3 {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return S3{
+		Region:       region,
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// HasCredentials - anonymous access to public buckets is allowed (requests are not signed)
+func (s S3) HasCredentials() bool { return s.AccessKey != "" && s.SecretKey != "" }
+
+func IsS3Url(u *url.URL) bool { return u.Scheme == S3Scheme || u.Scheme == GCSScheme }
+
+// HttpUrl - converts s3://bucket/prefix or gs://bucket/prefix to https url of bucket's endpoint
+func (s S3) HttpUrl(u *url.URL) (*url.URL, error) {
+	bucket, prefix := u.Host, strings.Trim(u.Path, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("webseed %s: bucket name is empty", u)
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" && u.Scheme == GCSScheme {
+		endpoint = defaultGCSEnpoint
+	}
+
+	var res string
+	if endpoint == "" {
+		res = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, s.RegionOrDefault(), prefix)
+	} else {
+		res = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + prefix
+	}
+	httpUrl, err := url.ParseRequestURI(strings.TrimSuffix(res, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("webseed %s: %w", u, err)
+	}
+	return httpUrl, nil
+}
+
+func (s S3) RegionOrDefault() string {
+	if s.Region == "" {
+		if strings.Contains(s.Endpoint, "storage.googleapis.com") {
+			return "auto"
+		}
+		return defaultS3Region
+	}
+	return s.Region
+}
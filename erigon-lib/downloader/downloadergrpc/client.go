@@ -29,11 +29,13 @@ import (
 	prototypes "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 )
 
-func NewClient(ctx context.Context, downloaderAddr string) (proto_downloader.DownloaderClient, error) {
+// NewClient - creds=nil means plaintext connection
+func NewClient(ctx context.Context, downloaderAddr string, creds credentials.TransportCredentials) (proto_downloader.DownloaderClient, error) {
 	// creating grpc client connection
 	var dialOpts []grpc.DialOption
 
@@ -46,7 +48,10 @@ func NewClient(ctx context.Context, downloaderAddr string) (proto_downloader.Dow
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{}),
 	}
 
-	dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
 	conn, err := grpc.DialContext(ctx, downloaderAddr, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
@@ -0,0 +1,149 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+)
+
+// Signed manifest - published by release process next to webseed's manifest.txt:
+//   - SignedManifestFileName: toml `<file name> = "<torrent info hash>"` (same format as preverified lists of erigon-snapshot, see snapcfg.ParsePreverifiedToml)
+//   - SignedManifestFileName + SignedManifestSigSuffix: hex-encoded ed25519 signature of manifest file bytes
+//
+// Info hash covers piece hashes of file: content downloaded from webseed or torrent peers is verified against it.
+const (
+	SignedManifestFileName  = "preverified.toml"
+	SignedManifestSigSuffix = ".sig"
+)
+
+var ErrManifestSignature = errors.New("manifest signature is not valid for any of release keys")
+
+// SignManifest - returns content of signature file
+func SignManifest(key ed25519.PrivateKey, manifest []byte) []byte {
+	return []byte(hex.EncodeToString(ed25519.Sign(key, manifest)) + "\n")
+}
+
+// VerifySignedManifest - checks signature by any of release keys and parses manifest
+func VerifySignedManifest(manifest, sig []byte, keys []ed25519.PublicKey) (snapcfg.Preverified, error) {
+	signature, err := hex.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return nil, fmt.Errorf("manifest signature: %w", err)
+	}
+	if !slices.ContainsFunc(keys, func(key ed25519.PublicKey) bool { return ed25519.Verify(key, manifest, signature) }) {
+		return nil, ErrManifestSignature
+	}
+	res, err := snapcfg.ParsePreverifiedToml(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("signed manifest: %w", err)
+	}
+	return res, nil
+}
+
+// retrieveSignedManifest - fetches signed manifest of webseed and verifies it
+func (d *WebSeeds) retrieveSignedManifest(ctx context.Context, webSeedProviderUrl *url.URL) (snapcfg.Preverified, error) {
+	manifest, err := d.fetch(ctx, webSeedProviderUrl.JoinPath(SignedManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := d.fetch(ctx, webSeedProviderUrl.JoinPath(SignedManifestFileName+SignedManifestSigSuffix))
+	if err != nil {
+		return nil, err
+	}
+	return VerifySignedManifest(manifest, sig, d.releaseKeys)
+}
+
+// signedManifestMaxSize - preverified lists of big chains are ~1Mb
+const signedManifestMaxSize = 64 << 20
+
+func (d *WebSeeds) fetch(ctx context.Context, u *url.URL) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	insertCloudflareHeaders(request)
+	resp, err := d.s3.do(request)
+	if err != nil {
+		return nil, fmt.Errorf("webseed.http: make request: %w, url=%s", err, u.String())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webseed.http: status=%d, url=%s", resp.StatusCode, u.String())
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, signedManifestMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("webseed.http: read: %w, url=%s", err, u.String())
+	}
+	return b, nil
+}
+
+// trustSignedManifest - marks webseed as trusted and adds hashes of signed manifest to whitelist.
+// Hashes of binary's preverified list have priority: conflicting entries are ignored.
+// Returns names of files which webseed is allowed to serve.
+func (d *WebSeeds) trustSignedManifest(webseed *url.URL, signed snapcfg.Preverified) map[string]struct{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	allowed := make(map[string]struct{}, len(signed))
+	var added []snapcfg.PreverifiedItem
+	for _, item := range signed {
+		if known, ok := d.torrentsWhitelist.Get(item.Name); ok {
+			if known.Hash != item.Hash {
+				d.logger.Warn("[snapshots.webseed] signed manifest conflicts with preverified list, file ignored", "webseed", webseed.String(), "name", item.Name)
+				continue
+			}
+		} else {
+			added = append(added, item)
+		}
+		allowed[item.Name] = struct{}{}
+		allowed[item.Name+".torrent"] = struct{}{}
+	}
+	if len(added) > 0 {
+		d.torrentsWhitelist = snapcfg.Merge(slices.Clone(d.torrentsWhitelist), added)
+	}
+	if d.trusted == nil {
+		d.trusted = map[*url.URL]struct{}{}
+	}
+	d.trusted[webseed] = struct{}{}
+	return allowed
+}
+
+// seedTrusted - without release keys all webseeds are trusted
+func (d *WebSeeds) seedTrusted(webseed *url.URL) bool {
+	if len(d.releaseKeys) == 0 {
+		return true
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, ok := d.trusted[webseed]
+	return ok
+}
+
+func (d *WebSeeds) whitelist() snapcfg.Preverified {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.torrentsWhitelist
+}
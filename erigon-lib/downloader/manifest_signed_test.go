@@ -0,0 +1,77 @@
+package downloader
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+)
+
+func TestSignedManifest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	manifest := []byte("\"v1-000000-000500-bodies.seg\" = \"aa\"\n\"v1-000000-000500-headers.seg\" = \"bb\"\n")
+	sig := SignManifest(priv, manifest)
+
+	signed, err := VerifySignedManifest(manifest, sig, []ed25519.PublicKey{otherPub, pub})
+	require.NoError(t, err)
+	require.Equal(t, snapcfg.Preverified{
+		{Name: "v1-000000-000500-bodies.seg", Hash: "aa"},
+		{Name: "v1-000000-000500-headers.seg", Hash: "bb"},
+	}, signed)
+
+	_, err = VerifySignedManifest(manifest, sig, []ed25519.PublicKey{otherPub})
+	require.ErrorIs(t, err, ErrManifestSignature)
+	tampered := append([]byte{}, manifest...)
+	tampered[len(tampered)-3] = 'c'
+	_, err = VerifySignedManifest(tampered, sig, []ed25519.PublicKey{pub})
+	require.ErrorIs(t, err, ErrManifestSignature)
+
+	// webseed without valid signature is not used, signed hashes extend whitelist but don't override preverified
+	files := map[string][]byte{
+		"/good/manifest.txt":                       []byte("v1-000000-000500-bodies.seg\nv1-000000-000500-bodies.seg.torrent\nv1-000000-000500-txs.seg\n"),
+		"/good/" + SignedManifestFileName:          manifest,
+		"/good/" + SignedManifestFileName + ".sig": sig,
+		"/bad/manifest.txt":                        []byte("v1-000000-000500-bodies.seg\n"),
+		"/bad/" + SignedManifestFileName:           manifest,
+		"/bad/" + SignedManifestFileName + ".sig":  SignManifest(priv, tampered),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+	good, err := url.Parse(srv.URL + "/good")
+	require.NoError(t, err)
+	bad, err := url.Parse(srv.URL + "/bad")
+	require.NoError(t, err)
+
+	ws := NewWebSeeds([]*url.URL{good, bad}, log.LvlDebug, log.New())
+	ws.releaseKeys = []ed25519.PublicKey{pub}
+	ws.SetTorrent(&AtomicTorrentFS{dir: t.TempDir()}, snapcfg.Preverified{{Name: "v1-000000-000500-headers.seg", Hash: "cc"}}, false)
+	lists := ws.constructListsOfFiles(context.Background(), ws.seeds, nil)
+	require.Len(t, lists, 1)
+	require.Len(t, lists[0], 2)
+	require.Contains(t, lists[0], "v1-000000-000500-bodies.seg")
+	require.Contains(t, lists[0], "v1-000000-000500-bodies.seg.torrent")
+	require.True(t, ws.seedTrusted(good))
+	require.False(t, ws.seedTrusted(bad))
+	require.Equal(t, snapcfg.Preverified{
+		{Name: "v1-000000-000500-bodies.seg", Hash: "aa"},
+		{Name: "v1-000000-000500-headers.seg", Hash: "cc"},
+	}, ws.whitelist())
+}
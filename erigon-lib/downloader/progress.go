@@ -0,0 +1,104 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"sort"
+	"time"
+
+	"github.com/anacrolix/torrent"
+)
+
+// FileState - stage of file in downloader
+type FileState string
+
+const (
+	FileQueued      FileState = "queued" // no metadata yet, or nothing downloaded and no sources
+	FileDownloading FileState = "downloading"
+	FileVerifying   FileState = "verifying" // pieces are hashed: after download or by `--verify`
+	FileDone        FileState = "done"
+)
+
+// FileProgress - what downloader does with one file. Rates are bytes per second.
+type FileProgress struct {
+	Name           string        `json:"name"`
+	State          FileState     `json:"state"`
+	BytesTotal     uint64        `json:"bytesTotal"`
+	BytesCompleted uint64        `json:"bytesCompleted"`
+	Peers          int           `json:"peers"`
+	Webseeds       int           `json:"webseeds"`
+	PeersRate      uint64        `json:"peersRate"`    // from BitTorrent peers
+	WebseedsRate   uint64        `json:"webseedsRate"` // from webseeds: torrent webseeds and web downloads
+	Eta            time.Duration `json:"eta"`          // 0 - unknown (no sources) or done
+}
+
+func (p *FileProgress) calcEta() {
+	rate := p.PeersRate + p.WebseedsRate
+	if rate == 0 || p.BytesCompleted >= p.BytesTotal {
+		p.Eta = 0
+		return
+	}
+	p.Eta = time.Duration((p.BytesTotal-p.BytesCompleted)/rate) * time.Second
+}
+
+// ProgressReport - files ordered by name, and totals of downloader's AggStats
+type ProgressReport struct {
+	Files          []FileProgress `json:"files"`
+	BytesTotal     uint64         `json:"bytesTotal"`
+	BytesCompleted uint64         `json:"bytesCompleted"`
+	DownloadRate   uint64         `json:"downloadRate"`
+	Eta            time.Duration  `json:"eta"`
+	Completed      bool           `json:"completed"`
+}
+
+// Progress - state of each file, as of last ReCalcStats
+func (d *Downloader) Progress() ProgressReport {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	stats := d.stats
+	r := ProgressReport{
+		Files:          make([]FileProgress, 0, len(stats.files)),
+		BytesTotal:     stats.BytesTotal,
+		BytesCompleted: stats.BytesCompleted,
+		DownloadRate:   stats.DownloadRate,
+		Completed:      stats.Completed,
+	}
+	for _, f := range stats.files {
+		r.Files = append(r.Files, f)
+	}
+	sort.Slice(r.Files, func(i, j int) bool { return r.Files[i].Name < r.Files[j].Name })
+	if !r.Completed && r.DownloadRate > 0 && r.BytesTotal > r.BytesCompleted {
+		r.Eta = time.Duration((r.BytesTotal-r.BytesCompleted)/r.DownloadRate) * time.Second
+	}
+	return r
+}
+
+// torrentFileState - must be called only for torrents with metadata
+func torrentFileState(t *torrent.Torrent, complete bool, bytesCompleted int64, sources int) FileState {
+	if complete {
+		return FileDone
+	}
+	for _, run := range t.PieceStateRuns() {
+		if run.Checking || run.Hashing || run.QueuedForHash {
+			return FileVerifying
+		}
+	}
+	if bytesCompleted == 0 && sources == 0 {
+		return FileQueued
+	}
+	return FileDownloading
+}
@@ -0,0 +1,53 @@
+package downloader
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	proto_downloader "github.com/ledgerwatch/erigon-lib/gointerfaces/downloaderproto"
+)
+
+func TestProgress(t *testing.T) {
+	fp := FileProgress{Name: "a", BytesTotal: 1000, BytesCompleted: 400, PeersRate: 50, WebseedsRate: 10}
+	fp.calcEta()
+	require.Equal(t, 10*time.Second, fp.Eta)
+	fp.PeersRate, fp.WebseedsRate = 0, 0
+	fp.calcEta()
+	require.Equal(t, time.Duration(0), fp.Eta)
+
+	d := &Downloader{lock: &sync.RWMutex{}}
+	d.stats = AggStats{BytesTotal: 3000, BytesCompleted: 1000, DownloadRate: 100, files: map[string]FileProgress{
+		"b": {Name: "b", State: FileQueued},
+		"a": {Name: "a", State: FileDownloading, BytesTotal: 2000, BytesCompleted: 1000, WebseedsRate: 100, Eta: 10 * time.Second},
+		"c": {Name: "c", State: FileDone, BytesTotal: 1000, BytesCompleted: 1000},
+	}}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto_downloader.RegisterDownloaderServer(server, &GrpcServer{d: d})
+	go server.Serve(lis) //nolint:errcheck
+	defer server.Stop()
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	reply, err := proto_downloader.NewDownloaderClient(conn).Progress(ctx, &proto_downloader.ProgressRequest{})
+	require.NoError(t, err)
+	report := ProgressReportFromProto(reply)
+	require.Equal(t, 20*time.Second, report.Eta)
+	require.Equal(t, 3, len(report.Files))
+	require.Equal(t, []string{"a", "b", "c"}, []string{report.Files[0].Name, report.Files[1].Name, report.Files[2].Name})
+	require.Equal(t, FileDownloading, report.Files[0].State)
+	require.Equal(t, uint64(100), report.Files[0].WebseedsRate)
+	require.Equal(t, FileQueued, report.Files[1].State)
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	dir2 "github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+)
+
+const ManifestFileName = "manifest.txt"
+
+// ReleaseCfg - how `erigon snapshots create` publishes frozen files of a chain (including private/consortium chains)
+type ReleaseCfg struct {
+	Chain string
+	// All - publish all frozen files. By default only files of merge-limit size are seedable (see snapcfg.Seedable):
+	// private chains are short and may have no such files yet.
+	All     bool
+	V2Roots bool               // add BitTorrent v2 pieces roots to preverified.toml
+	Key     ed25519.PrivateKey // nil - preverified.toml is not signed
+}
+
+// ReleaseFiles - data files of release, relative to dirs.Snap
+func ReleaseFiles(dirs datadir.Dirs, cfg ReleaseCfg) ([]string, error) {
+	if !cfg.All {
+		return SeedableFiles(dirs, cfg.Chain)
+	}
+	var res []string
+	l, err := dir2.ListFiles(dirs.Snap, snaptype.SeedableV2Extensions()...)
+	if err != nil {
+		return nil, err
+	}
+	for _, fPath := range l {
+		if _, name := filepath.Split(fPath); snaptype.IsCorrectFileName(name) {
+			res = append(res, name)
+		}
+	}
+	for _, subDir := range []string{"idx", "history", "domain"} {
+		l, err := dir2.ListFiles(filepath.Join(dirs.Snap, subDir), snaptype.SeedableV3Extensions()...)
+		if err != nil {
+			return nil, err
+		}
+		for _, fPath := range l {
+			if _, name := filepath.Split(fPath); snaptype.IsStateFile(name) {
+				res = append(res, filepath.Join(subDir, name))
+			}
+		}
+	}
+	return res, nil
+}
+
+// Release - result of CreateRelease. Paths are relative to dirs.Snap
+type Release struct {
+	Preverified snapcfg.Preverified
+	Files       []string // data and .torrent files
+	Meta        []string // manifests: must be uploaded after Files, in given order - nodes must not see files which are not uploaded yet
+}
+
+// CreateRelease - creates .torrent files, manifest.txt, preverified.toml (and it's signature) in dirs.Snap
+func CreateRelease(ctx context.Context, dirs datadir.Dirs, cfg ReleaseCfg) (*Release, error) {
+	files, err := ReleaseFiles(dirs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// commitment history is not released
+	files = filterOut(files, func(name string) bool {
+		return strings.Contains(name, "commitment") && (strings.HasPrefix(name, "history") || strings.HasPrefix(name, "idx"))
+	})
+	sort.Strings(files)
+
+	tf := NewAtomicTorrentFS(dirs.Snap)
+	res := make(snapcfg.Preverified, len(files))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(-1))
+	for i, name := range files {
+		i, name := i, name
+		g.Go(func() error {
+			if _, err := BuildTorrentIfNeed(gctx, name, dirs.Snap, tf); err != nil {
+				return err
+			}
+			spec, err := tf.LoadByName(name)
+			if err != nil {
+				return err
+			}
+			res[i] = snapcfg.PreverifiedItem{Name: name, Hash: spec.InfoHash.String()}
+			if cfg.V2Roots {
+				root, err := FileRootV2(gctx, filepath.Join(dirs.Snap, name))
+				if err != nil {
+					return err
+				}
+				res[i].Root = root.String()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	release := &Release{Preverified: res}
+	for _, name := range files {
+		release.Files = append(release.Files, name, name+".torrent")
+	}
+
+	preverified, err := res.MarshalToml()
+	if err != nil {
+		return nil, err
+	}
+	type metaFile struct {
+		name    string
+		content []byte
+	}
+	meta := []metaFile{{SignedManifestFileName, preverified}}
+	if cfg.Key != nil {
+		sig := SignManifest(cfg.Key, preverified)
+		if _, err := VerifySignedManifest(preverified, sig, []ed25519.PublicKey{cfg.Key.Public().(ed25519.PublicKey)}); err != nil {
+			return nil, err
+		}
+		meta = append(meta, metaFile{SignedManifestFileName + SignedManifestSigSuffix, sig})
+	}
+	meta = append(meta, metaFile{ManifestFileName, []byte(strings.Join(release.Files, "\n") + "\n")})
+	for _, m := range meta {
+		if err := os.WriteFile(filepath.Join(dirs.Snap, m.name), m.content, 0644); err != nil { // nolint
+			return nil, err
+		}
+		release.Meta = append(release.Meta, m.name)
+	}
+	return release, nil
+}
+
+func filterOut(files []string, skip func(name string) bool) []string {
+	res := files[:0]
+	for _, name := range files {
+		if !skip(name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package downloader
+
+import (
+	"context"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+)
+
+func TestCreateRelease(t *testing.T) {
+	dirs := datadir.New(t.TempDir())
+	for _, name := range []string{
+		"v1-000000-000001-headers.seg",
+		"v1-000000-000001-bodies.seg",
+		"domain/v1-accounts.0-1.kv",
+		"history/v1-commitment.0-1.v",  // not released
+		"v1-000000-000001-headers.idx", // indices are built by nodes
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dirs.Snap, name), []byte(name), 0644))
+	}
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// private chain: files are smaller than merge limit - not seedable by default
+	files, err := ReleaseFiles(dirs, ReleaseCfg{Chain: "private"})
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	release, err := CreateRelease(context.Background(), dirs, ReleaseCfg{Chain: "private", All: true, V2Roots: true, Key: key})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(release.Preverified))
+	for _, item := range release.Preverified {
+		require.NotEmpty(t, item.Hash)
+		require.NotEmpty(t, item.Root)
+		require.False(t, strings.Contains(item.Name, "commitment"))
+	}
+	require.Equal(t, []string{
+		"domain/v1-accounts.0-1.kv", "domain/v1-accounts.0-1.kv.torrent",
+		"v1-000000-000001-bodies.seg", "v1-000000-000001-bodies.seg.torrent",
+		"v1-000000-000001-headers.seg", "v1-000000-000001-headers.seg.torrent",
+	}, release.Files)
+	require.Equal(t, []string{SignedManifestFileName, SignedManifestFileName + SignedManifestSigSuffix, ManifestFileName}, release.Meta)
+	for _, name := range append(release.Files, release.Meta...) {
+		require.FileExists(t, filepath.Join(dirs.Snap, name))
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(dirs.Snap, SignedManifestFileName))
+	require.NoError(t, err)
+	sig, err := os.ReadFile(filepath.Join(dirs.Snap, SignedManifestFileName+SignedManifestSigSuffix))
+	require.NoError(t, err)
+	signed, err := VerifySignedManifest(manifest, sig, []ed25519.PublicKey{key.Public().(ed25519.PublicKey)})
+	require.NoError(t, err)
+	require.Equal(t, release.Preverified, signed)
+}
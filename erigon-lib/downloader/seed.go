@@ -0,0 +1,76 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var (
+	mxSeedFiles      = metrics.GetOrCreateGauge("downloader_seed_files")
+	mxSeedBytes      = metrics.GetOrCreateGauge("downloader_seed_bytes")
+	mxSeedPeers      = metrics.GetOrCreateGauge("downloader_seed_peers")
+	mxSeedUploadRate = metrics.GetOrCreateGauge("downloader_seed_upload_rate")
+	mxSeedUploaded   = metrics.GetOrCreateGauge("downloader_seed_uploaded_bytes")
+)
+
+// seedRescanInterval - how often seed-only downloader looks for new files in datadir (copied by rsync, produced by other node, ...)
+var seedRescanInterval = dbg.EnvDuration("DL_SEED_RESCAN", time.Minute)
+
+// updateSeedMetrics - seeded are files which are complete on disk: only they can be served to peers
+func updateSeedMetrics(stats *AggStats) {
+	var files, size uint64
+	for _, f := range stats.files {
+		if f.State == FileDone {
+			files++
+			size += f.BytesTotal
+		}
+	}
+	mxSeedFiles.SetUint64(files)
+	mxSeedBytes.SetUint64(size)
+	mxSeedPeers.SetInt(int(stats.PeersUnique))
+	mxSeedUploadRate.SetUint64(stats.UploadRate)
+	mxSeedUploaded.SetUint64(stats.BytesUpload)
+}
+
+// seedRescanLoop - Cfg.SeedOnly: create .torrent files for new files and start seeding them
+func (d *Downloader) seedRescanLoop() {
+	ticker := time.NewTicker(seedRescanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.rescanSeedable(); err != nil && !errors.Is(err, context.Canceled) {
+				d.logger.Warn("[snapshots] seed rescan", "err", err)
+			}
+		}
+	}
+}
+
+func (d *Downloader) rescanSeedable() error {
+	if err := d.BuildTorrentFilesIfNeed(d.ctx, d.cfg.ChainName, nil); err != nil {
+		return err
+	}
+	return d.addTorrentFilesFromDisk(true)
+}
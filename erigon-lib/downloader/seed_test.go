@@ -0,0 +1,46 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lg "github.com/anacrolix/log"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+)
+
+func TestSeedOnlyRescan(t *testing.T) {
+	require := require.New(t)
+	dirs := datadir.New(t.TempDir())
+	cfg, err := downloadercfg.New(dirs, "", lg.Info, 0, 0, 0, 0, 0, nil, nil, "testnet", false)
+	require.NoError(err)
+	cfg.SeedOnly = true
+	d, err := New(context.Background(), cfg, log.New(), log.LvlInfo, false)
+	require.NoError(err)
+	defer d.Close()
+	require.Empty(d.torrentClient.Torrents())
+
+	// file copied to datadir after start
+	name := "domain/v1-accounts.0-64.kv"
+	require.NoError(os.WriteFile(filepath.Join(dirs.Snap, name), []byte("some data"), 0644))
+	require.NoError(d.rescanSeedable())
+
+	torrents := d.torrentClient.Torrents()
+	require.Len(torrents, 1)
+	require.Equal(name, torrents[0].Name())
+	require.True(d.torrentFS.Exists(name))
+
+	stats := AggStats{PeersUnique: 2, UploadRate: 10, files: map[string]FileProgress{
+		"a": {State: FileDone, BytesTotal: 100},
+		"b": {State: FileQueued, BytesTotal: 50},
+	}}
+	updateSeedMetrics(&stats)
+	require.Equal(float64(1), mxSeedFiles.GetValue())
+	require.Equal(float64(100), mxSeedBytes.GetValue())
+	require.Equal(float64(2), mxSeedPeers.GetValue())
+}
@@ -19,8 +19,19 @@ var (
 		},
 		indexes: []Index{CaplinIndexes.BlobSidecarSlot},
 	}
+	// BeaconStates - full beacon states (ssz) of every freezeblocks.SlotsPerStateSnapshot slot and diffs against them for other
+	// slots: allows to read historical state without replay from genesis
+	BeaconStates = snapType{
+		enum: CaplinEnums.BeaconStates,
+		name: "beaconstates",
+		versions: Versions{
+			Current:      1,
+			MinSupported: 1,
+		},
+		indexes: []Index{CaplinIndexes.BeaconStateSlot},
+	}
 
-	CaplinSnapshotTypes = []Type{BeaconBlocks, BlobSidecars}
+	CaplinSnapshotTypes = []Type{BeaconBlocks, BlobSidecars, BeaconStates}
 )
 
 func IsCaplinType(t Enum) bool {
@@ -15,6 +15,10 @@ func TestEnumeration(t *testing.T) {
 	if snaptype.BeaconBlocks.Enum() != snaptype.CaplinEnums.BeaconBlocks {
 		t.Fatal("enum mismatch", snaptype.BeaconBlocks, snaptype.BeaconBlocks.Enum(), snaptype.CaplinEnums.BeaconBlocks)
 	}
+
+	if snaptype.BeaconStates.Enum() != snaptype.CaplinEnums.BeaconStates {
+		t.Fatal("enum mismatch", snaptype.BeaconStates, snaptype.BeaconStates.Enum(), snaptype.CaplinEnums.BeaconStates)
+	}
 }
 
 func TestNames(t *testing.T) {
@@ -27,4 +31,8 @@ func TestNames(t *testing.T) {
 		t.Fatal("name mismatch", snaptype.BlobSidecars, snaptype.BlobSidecars.Name(), snaptype.CaplinEnums.BlobSidecars.String())
 	}
 
+	if snaptype.BeaconStates.Name() != snaptype.CaplinEnums.BeaconStates.String() {
+		t.Fatal("name mismatch", snaptype.BeaconStates, snaptype.BeaconStates.Name(), snaptype.CaplinEnums.BeaconStates.String())
+	}
+
 }
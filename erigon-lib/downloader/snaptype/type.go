@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -119,10 +120,12 @@ type Index struct {
 
 var CaplinIndexes = struct {
 	BeaconBlockSlot,
-	BlobSidecarSlot Index
+	BlobSidecarSlot,
+	BeaconStateSlot Index
 }{
 	BeaconBlockSlot: Index{Name: "beaconblocks"},
 	BlobSidecarSlot: Index{Name: "blocksidecars"},
+	BeaconStateSlot: Index{Name: "beaconstates"},
 }
 
 func (i Index) HasFile(info FileInfo, logger log.Logger) bool {
@@ -302,14 +305,19 @@ const MinCoreEnum = 1
 const MinBorEnum = 4
 const MinCaplinEnum = 8
 
+// MinCoreExtEnum - optional types of block snapshots: produced only if enabled, may start not from genesis
+const MinCoreExtEnum = 16
+
 var CaplinEnums = struct {
 	Enums
 	BeaconBlocks,
-	BlobSidecars Enum
+	BlobSidecars,
+	BeaconStates Enum
 }{
 	Enums:        Enums{},
 	BeaconBlocks: MinCaplinEnum,
 	BlobSidecars: MinCaplinEnum + 1,
+	BeaconStates: MinCaplinEnum + 2,
 }
 
 func (ft Enum) String() string {
@@ -318,6 +326,8 @@ func (ft Enum) String() string {
 		return "beaconblocks"
 	case CaplinEnums.BlobSidecars:
 		return "blobsidecars"
+	case CaplinEnums.BeaconStates:
+		return "beaconstates"
 	default:
 		if t, ok := registeredTypes[ft]; ok {
 			return t.Name()
@@ -333,6 +343,8 @@ func (ft Enum) Type() Type {
 		return BeaconBlocks
 	case CaplinEnums.BlobSidecars:
 		return BlobSidecars
+	case CaplinEnums.BeaconStates:
+		return BeaconStates
 	default:
 		return registeredTypes[ft]
 	}
@@ -355,6 +367,30 @@ func (e Enum) BuildIndexes(ctx context.Context, info FileInfo, chainConfig *chai
 	return e.Type().BuildIndexes(ctx, info, chainConfig, tmpDir, p, lvl, logger)
 }
 
+// indexDeps - indices of type are built from segments of other types of same range (transactions.idx needs bodies.seg).
+// Written once during program initialization
+var indexDeps = map[Enum][]Enum{}
+
+// RegisterIndexDeps - indices of `t` must be rebuilt if segments of `deps` (of same range) are changed
+func RegisterIndexDeps(t Enum, deps ...Enum) {
+	indexDeps[t] = append(indexDeps[t], deps...)
+}
+
+// IndexDependents - types which indices must be rebuilt if segment of type `t` changed: `t` itself and types which depend on it
+func IndexDependents(t Enum) []Enum {
+	res := []Enum{t}
+	for dependent, deps := range indexDeps {
+		for _, dep := range deps {
+			if dep == t {
+				res = append(res, dependent)
+				break
+			}
+		}
+	}
+	sort.Slice(res[1:], func(i, j int) bool { return res[1+i] < res[1+j] })
+	return res
+}
+
 func ParseEnum(s string) (Enum, bool) {
 	s = strings.ToLower(s)
 	switch s {
@@ -362,6 +398,8 @@ func ParseEnum(s string) (Enum, bool) {
 		return CaplinEnums.BeaconBlocks, true
 	case "blobsidecars":
 		return CaplinEnums.BlobSidecars, true
+	case "beaconstates":
+		return CaplinEnums.BeaconStates, true
 	default:
 		if t, ok := namedTypes[s]; ok {
 			return t.Enum(), true
@@ -0,0 +1,255 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+// BitTorrent v2 (BEP-52): file is identified by `pieces root` - root of SHA-256 merkle tree of 16Kb blocks.
+// Unlike v1 info hash it doesn't depend on file name and piece size, and any piece can be verified by
+// root + `piece layer` (hashes of pieces subtrees). Roots are published in preverified lists (snapcfg.PreverifiedItem.Root).
+//
+// Hybrid v1+v2 .torrent files are not produced yet: torrent library doesn't support BEP-52 - v1 info hashes
+// stay identity of files in torrent network.
+
+const V2BlockSize = 16 * 1024
+
+var ErrV2RootMismatch = errors.New("v2 pieces root mismatch")
+
+var mxV2Dedup = metrics.GetOrCreateCounter("downloader_v2_dedup_total")
+
+type V2Hash [sha256.Size]byte
+
+func (h V2Hash) String() string { return hex.EncodeToString(h[:]) }
+
+func ParseV2Hash(s string) (h V2Hash, err error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, err
+	}
+	if len(b) != len(h) {
+		return h, fmt.Errorf("v2 hash: expected %d bytes, got %d", len(h), len(b))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func hashPair(l, r V2Hash) V2Hash {
+	var b [2 * sha256.Size]byte
+	copy(b[:], l[:])
+	copy(b[sha256.Size:], r[:])
+	return sha256.Sum256(b[:])
+}
+
+// merkleRoot - `layer` is padded by `pad` (hash of empty subtree of same height) to power of 2. Modifies `layer`
+func merkleRoot(layer []V2Hash, pad V2Hash) V2Hash {
+	if len(layer) == 0 {
+		return V2Hash{}
+	}
+	for len(layer) > 1 {
+		if len(layer)%2 != 0 {
+			layer = append(layer, pad)
+		}
+		for i := 0; i < len(layer)/2; i++ {
+			layer[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = layer[:len(layer)/2]
+		pad = hashPair(pad, pad)
+	}
+	return layer[0]
+}
+
+// padHash - root of subtree of `blocks` zero leaves
+func padHash(blocks int) V2Hash {
+	var h V2Hash
+	for ; blocks > 1; blocks /= 2 {
+		h = hashPair(h, h)
+	}
+	return h
+}
+
+// readLeaves - hashes of up to `limit` blocks. eof - nothing left to read
+func readLeaves(r io.Reader, buf []byte, limit int, leaves []V2Hash) (_ []V2Hash, eof bool, err error) {
+	for len(leaves) < limit {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			leaves = append(leaves, sha256.Sum256(buf[:n]))
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return leaves, true, nil
+		}
+		if err != nil {
+			return leaves, false, err
+		}
+	}
+	return leaves, false, nil
+}
+
+// pieceHash - root of piece subtree: leaves of last piece are padded by zero hashes to piece size
+func pieceHash(leaves []V2Hash, blocksPerPiece int) V2Hash {
+	for len(leaves) < blocksPerPiece {
+		leaves = append(leaves, V2Hash{})
+	}
+	return merkleRoot(leaves, V2Hash{})
+}
+
+func v2BlocksPerPiece(pieceLength int64) (int, error) {
+	if pieceLength < V2BlockSize || pieceLength&(pieceLength-1) != 0 {
+		return 0, fmt.Errorf("v2 piece length must be power of 2 and >= %d, got %d", V2BlockSize, pieceLength)
+	}
+	return int(pieceLength / V2BlockSize), nil
+}
+
+// PieceLayerV2 - pieces root of content and piece layer (only for content bigger than one piece, as in BEP-52).
+// pieceLength - power of 2, >= V2BlockSize. Empty content has no root (zero hash).
+func PieceLayerV2(ctx context.Context, r io.Reader, pieceLength int64) (root V2Hash, pieceLayer []V2Hash, err error) {
+	blocksPerPiece, err := v2BlocksPerPiece(pieceLength)
+	if err != nil {
+		return root, nil, err
+	}
+	buf := make([]byte, V2BlockSize)
+	leaves := make([]V2Hash, 0, blocksPerPiece)
+	for {
+		select {
+		case <-ctx.Done():
+			return root, nil, ctx.Err()
+		default:
+		}
+		var eof bool
+		leaves, eof, err = readLeaves(r, buf, blocksPerPiece, leaves[:0])
+		if err != nil {
+			return root, nil, err
+		}
+		if eof && len(pieceLayer) == 0 { // content fits in one piece: tree is not padded to piece size
+			return merkleRoot(leaves, V2Hash{}), nil, nil
+		}
+		if len(leaves) > 0 {
+			pieceLayer = append(pieceLayer, pieceHash(leaves, blocksPerPiece))
+		}
+		if eof {
+			break
+		}
+	}
+	root = merkleRoot(append([]V2Hash{}, pieceLayer...), padHash(blocksPerPiece))
+	if len(pieceLayer) == 1 {
+		return root, nil, nil
+	}
+	return root, pieceLayer, nil
+}
+
+// FileRootV2 - pieces root of file
+func FileRootV2(ctx context.Context, path string) (V2Hash, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return V2Hash{}, err
+	}
+	defer f.Close()
+	root, _, err := PieceLayerV2(ctx, f, downloadercfg.DefaultPieceSize)
+	return root, err
+}
+
+// VerifyPiecesV2 - checks piece layer by pieces root (from preverified list), then content by piece layer.
+// Returns indices of corrupted pieces: only them need to be re-downloaded.
+func VerifyPiecesV2(ctx context.Context, r io.ReaderAt, size, pieceLength int64, pieceLayer []V2Hash, root V2Hash) (bad []int, err error) {
+	blocksPerPiece, err := v2BlocksPerPiece(pieceLength)
+	if err != nil {
+		return nil, err
+	}
+	if size <= pieceLength { // no piece layer: root covers whole content
+		computed, _, err := PieceLayerV2(ctx, io.NewSectionReader(r, 0, size), pieceLength)
+		if err != nil {
+			return nil, err
+		}
+		if computed != root {
+			return []int{0}, nil
+		}
+		return nil, nil
+	}
+	if want := int((size + pieceLength - 1) / pieceLength); len(pieceLayer) != want {
+		return nil, fmt.Errorf("%w: piece layer has %d pieces, expected %d", ErrV2RootMismatch, len(pieceLayer), want)
+	}
+	if merkleRoot(append([]V2Hash{}, pieceLayer...), padHash(blocksPerPiece)) != root {
+		return nil, fmt.Errorf("%w: piece layer doesn't match root", ErrV2RootMismatch)
+	}
+	buf := make([]byte, V2BlockSize)
+	leaves := make([]V2Hash, 0, blocksPerPiece)
+	for i, want := range pieceLayer {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		off := int64(i) * pieceLength
+		leaves, _, err = readLeaves(io.NewSectionReader(r, off, min(pieceLength, size-off)), buf, blocksPerPiece, leaves[:0])
+		if err != nil {
+			return nil, err
+		}
+		if pieceHash(leaves, blocksPerPiece) != want {
+			bad = append(bad, i)
+		}
+	}
+	return bad, nil
+}
+
+// linkSameContent - v2 pieces root doesn't depend on file name: if preverified file with same root already downloaded
+// (for example: previous version of file with same data) - hard-link it instead of downloading.
+// Torrent client verifies pieces of linked file as usual. Returns true if file was linked.
+func (d *Downloader) linkSameContent(name string) (bool, error) {
+	whitelist := d.webseeds.whitelist()
+	item, ok := whitelist.Get(name)
+	if !ok || item.Root == "" {
+		return false, nil
+	}
+	target := filepath.Join(d.SnapDir(), name)
+	if dir.FileExist(target) {
+		return false, nil
+	}
+	for _, other := range whitelist {
+		if other.Root != item.Root || other.Name == name {
+			continue
+		}
+		if info, err := d.torrentInfo(other.Name); err != nil || info.Completed == nil {
+			continue
+		}
+		src := filepath.Join(d.SnapDir(), other.Name)
+		if !dir.FileExist(src) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return false, err
+		}
+		if err := os.Link(src, target); err != nil {
+			return false, err
+		}
+		mxV2Dedup.Inc()
+		d.logger.Info("[snapshots] same content already downloaded, linked", "name", name, "from", other.Name)
+		return true, nil
+	}
+	return false, nil
+}
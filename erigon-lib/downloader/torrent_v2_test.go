@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPieceLayerV2(t *testing.T) {
+	ctx := context.Background()
+	data := make([]byte, 4*V2BlockSize+100) // 5 blocks, last one is short
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	var h [5]V2Hash
+	for i := range h {
+		h[i] = sha256.Sum256(data[i*V2BlockSize : min((i+1)*V2BlockSize, len(data))])
+	}
+	var zero V2Hash
+
+	// fits in one piece: tree of 8 leaves, not padded to piece size
+	root, layer, err := PieceLayerV2(ctx, bytes.NewReader(data), 16*V2BlockSize)
+	require.NoError(t, err)
+	require.Nil(t, layer)
+	expect := hashPair(hashPair(hashPair(h[0], h[1]), hashPair(h[2], h[3])), hashPair(hashPair(h[4], zero), hashPair(zero, zero)))
+	require.Equal(t, expect, root)
+
+	// 2 blocks per piece: same root, piece layer of 3 pieces
+	root, layer, err = PieceLayerV2(ctx, bytes.NewReader(data), 2*V2BlockSize)
+	require.NoError(t, err)
+	require.Equal(t, expect, root)
+	require.Equal(t, []V2Hash{hashPair(h[0], h[1]), hashPair(h[2], h[3]), hashPair(h[4], zero)}, layer)
+
+	// single block: root is hash of block
+	root, layer, err = PieceLayerV2(ctx, bytes.NewReader(data[:10]), 2*V2BlockSize)
+	require.NoError(t, err)
+	require.Nil(t, layer)
+	require.Equal(t, V2Hash(sha256.Sum256(data[:10])), root)
+
+	_, _, err = PieceLayerV2(ctx, bytes.NewReader(data), 3*V2BlockSize)
+	require.Error(t, err)
+
+	// per-piece verification
+	_, layer, err = PieceLayerV2(ctx, bytes.NewReader(data), 2*V2BlockSize)
+	require.NoError(t, err)
+	bad, err := VerifyPiecesV2(ctx, bytes.NewReader(data), int64(len(data)), 2*V2BlockSize, layer, expect)
+	require.NoError(t, err)
+	require.Empty(t, bad)
+
+	corrupted := bytes.Clone(data)
+	corrupted[2*V2BlockSize+5]++
+	bad, err = VerifyPiecesV2(ctx, bytes.NewReader(corrupted), int64(len(data)), 2*V2BlockSize, layer, expect)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, bad)
+
+	layer[0][0]++ // piece layer from untrusted source
+	_, err = VerifyPiecesV2(ctx, bytes.NewReader(data), int64(len(data)), 2*V2BlockSize, layer, expect)
+	require.ErrorIs(t, err, ErrV2RootMismatch)
+
+	bad, err = VerifyPiecesV2(ctx, bytes.NewReader(corrupted), int64(len(data)), 16*V2BlockSize, nil, expect)
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, bad)
+
+	// root doesn't depend on name and piece size
+	path := filepath.Join(t.TempDir(), "v1-000000-000500-bodies.seg")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	fileRoot, err := FileRootV2(ctx, path)
+	require.NoError(t, err)
+	require.Equal(t, expect, fileRoot)
+	parsed, err := ParseV2Hash(fileRoot.String())
+	require.NoError(t, err)
+	require.Equal(t, fileRoot, parsed)
+}
@@ -3,6 +3,7 @@ package downloader
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"io"
@@ -41,6 +42,10 @@ type WebSeeds struct {
 	verbosity log.Lvl
 
 	torrentFiles *AtomicTorrentFS
+	s3           *s3Signer // for s3:// and gs:// webseeds
+
+	releaseKeys []ed25519.PublicKey   // if set: only webseeds with signed manifest are used
+	trusted     map[*url.URL]struct{} // webseeds which served valid signed manifest
 }
 
 func NewWebSeeds(seeds []*url.URL, verbosity log.Lvl, logger log.Logger) *WebSeeds {
@@ -55,12 +60,15 @@ func (d *WebSeeds) getWebDownloadInfo(ctx context.Context, t *torrent.Torrent) (
 	torrentHash := t.InfoHash().Bytes()
 
 	for _, webseed := range d.seeds {
+		if !d.seedTrusted(webseed) {
+			continue
+		}
 		downloadUrl := webseed.JoinPath(t.Name())
 
 		if headRequest, err := http.NewRequestWithContext(ctx, http.MethodHead, downloadUrl.String(), nil); err == nil {
 			insertCloudflareHeaders(headRequest)
 
-			headResponse, err := http.DefaultClient.Do(headRequest)
+			headResponse, err := d.s3.do(headRequest)
 			if err != nil {
 				continue
 			}
@@ -71,7 +79,7 @@ func (d *WebSeeds) getWebDownloadInfo(ctx context.Context, t *torrent.Torrent) (
 					"webseed", webseed.String(), "name", t.Name(), "status", headResponse.Status)
 				continue
 			}
-			if meta, err := getWebpeerTorrentInfo(ctx, downloadUrl); err == nil {
+			if meta, err := getWebpeerTorrentInfo(ctx, d.s3, downloadUrl); err == nil {
 				if bytes.Equal(torrentHash, meta.HashInfoBytes().Bytes()) {
 					md5tag := headResponse.Header.Get("Etag")
 					if md5tag != "" {
@@ -328,11 +336,27 @@ func (d *WebSeeds) constructListsOfFiles(ctx context.Context, httpProviders []*u
 			return listsOfFiles
 		default:
 		}
+		var allowed map[string]struct{}
+		if len(d.releaseKeys) > 0 {
+			signed, err := d.retrieveSignedManifest(ctx, webSeedProviderURL)
+			if err != nil {
+				d.logger.Warn("[snapshots.webseed] webseed is not trusted: no valid signed manifest", "err", err, "url", webSeedProviderURL.String())
+				continue
+			}
+			allowed = d.trustSignedManifest(webSeedProviderURL, signed)
+		}
 		manifestResponse, err := d.retrieveManifest(ctx, webSeedProviderURL)
 		if err != nil { // don't fail on error
 			d.logger.Debug("[snapshots.webseed] get from HTTP provider", "err", err, "url", webSeedProviderURL.String())
 			continue
 		}
+		if allowed != nil {
+			for name := range manifestResponse {
+				if _, ok := allowed[name]; !ok {
+					delete(manifestResponse, name)
+				}
+			}
+		}
 		// check if we need to prohibit new downloads for some files
 		for name := range manifestResponse {
 			prohibited, err := d.torrentFiles.NewDownloadsAreProhibited(name)
@@ -371,7 +395,7 @@ func (d *WebSeeds) makeTorrentUrls(listsOfFiles []snaptype.WebSeedsFromProvider)
 			if !strings.HasSuffix(name, ".torrent") {
 				continue
 			}
-			if !nameWhitelisted(name, d.torrentsWhitelist) {
+			if !nameWhitelisted(name, d.whitelist()) {
 				continue
 			}
 			uri, err := url.ParseRequestURI(wUrl)
@@ -437,7 +461,7 @@ func (d *WebSeeds) retrieveFileEtag(ctx context.Context, file *url.URL) (string,
 	}
 
 	request = request.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(request)
+	resp, err := d.s3.do(request)
 	if err != nil {
 		return "", fmt.Errorf("webseed.http: %w, url=%s", err, file.String())
 	}
@@ -474,7 +498,7 @@ func (d *WebSeeds) retrieveManifest(ctx context.Context, webSeedProviderUrl *url
 	insertCloudflareHeaders(request)
 
 	request = request.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(request)
+	resp, err := d.s3.do(request)
 	if err != nil {
 		return nil, fmt.Errorf("webseed.http: make request: %w, url=%s", err, u.String())
 	}
@@ -628,7 +652,7 @@ func (d *WebSeeds) callTorrentHttpProvider(ctx context.Context, url *url.URL, fi
 	insertCloudflareHeaders(request)
 
 	request = request.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(request)
+	resp, err := d.s3.do(request)
 	if err != nil {
 		return nil, fmt.Errorf("webseed.downloadTorrentFile: url=%s, %w", url.String(), err)
 	}
@@ -641,7 +665,7 @@ func (d *WebSeeds) callTorrentHttpProvider(ctx context.Context, url *url.URL, fi
 	if err != nil {
 		return nil, fmt.Errorf("webseed.downloadTorrentFile: host=%s, url=%s, %w", url.Hostname(), url.EscapedPath(), err)
 	}
-	if err = validateTorrentBytes(fileName, res, d.torrentsWhitelist); err != nil {
+	if err = validateTorrentBytes(fileName, res, d.whitelist()); err != nil {
 		return nil, fmt.Errorf("webseed.downloadTorrentFile: host=%s, url=%s, %w", url.Hostname(), url.EscapedPath(), err)
 	}
 	return res, nil
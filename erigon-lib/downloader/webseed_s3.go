@@ -0,0 +1,159 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package downloader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+)
+
+const (
+	s3SignAlgorithm   = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+)
+
+// s3Signer - signs (AWS Signature V4) requests to hosts of s3:// and gs:// webseeds.
+// Requests to other webseeds are sent as-is. nil-signer - nothing to sign.
+type s3Signer struct {
+	cfg   downloadercfg.S3
+	hosts map[string]struct{}
+}
+
+// resolveS3WebSeeds - converts s3:// and gs:// webseeds to https urls. They go first: own mirror is preferred over public webseeds.
+func resolveS3WebSeeds(seeds []*url.URL, cfg downloadercfg.S3) ([]*url.URL, *s3Signer, error) {
+	var mirrors, public []*url.URL
+	var signer *s3Signer
+	for _, seed := range seeds {
+		if !downloadercfg.IsS3Url(seed) {
+			public = append(public, seed)
+			continue
+		}
+		httpUrl, err := cfg.HttpUrl(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		mirrors = append(mirrors, httpUrl)
+		if !cfg.HasCredentials() {
+			if cfg.RequesterPays {
+				return nil, nil, fmt.Errorf("webseed %s: requester-pays bucket requires credentials", seed)
+			}
+			continue
+		}
+		if signer == nil {
+			signer = &s3Signer{cfg: cfg, hosts: map[string]struct{}{}}
+		}
+		signer.hosts[httpUrl.Host] = struct{}{}
+	}
+	return append(mirrors, public...), signer, nil
+}
+
+func (s *s3Signer) signs(req *http.Request) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.hosts[req.URL.Host]
+	return ok
+}
+
+// do - http.DefaultClient.Do with signing if needed
+func (s *s3Signer) do(req *http.Request) (*http.Response, error) {
+	if s.signs(req) {
+		req = req.Clone(req.Context())
+		s.sign(req, time.Now())
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// sign - adds `Authorization` header. Body is not signed (UNSIGNED-PAYLOAD) - only GET/HEAD requests are sent to webseeds
+func (s *s3Signer) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format(s3TimeFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	}
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	if s.cfg.RequesterPays {
+		req.Header.Set("X-Amz-Request-Payer", "requester")
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + s.cfg.RegionOrDefault() + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3SignAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSha256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSha256(key, s.cfg.RegionOrDefault())
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SignAlgorithm, s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
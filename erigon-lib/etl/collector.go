@@ -28,6 +28,7 @@ import (
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
@@ -55,6 +56,8 @@ type Collector struct {
 	//   - if disk is over-loaded - app may have much background threads which waiting for flush - and each thread whill hold own `buf` (can't free RAM until flush is done)
 	//   - enable it only when writing to `etl` is a bottleneck and unlikely to have many parallel collectors (to not overload CPU/Disk)
 	sortAndFlushInBackground bool
+	compression              Compression
+	workers                  int // sort of buffer and merge of files on Load
 }
 
 // NewCollectorFromFiles creates collector from existing files (left over from previous unsuccessful loading)
@@ -75,14 +78,15 @@ func NewCollectorFromFiles(logPrefix, tmpdir string, logger log.Logger) (*Collec
 		if err != nil {
 			return nil, fmt.Errorf("collector from files - reading file info %s: %w", dirEntry.Name(), err)
 		}
-		var dataProvider fileDataProvider
+		dataProvider := &fileDataProvider{compression: compressionByFileName(fileInfo.Name()), wg: &errgroup.Group{}}
 		dataProvider.file, err = os.Open(filepath.Join(tmpdir, fileInfo.Name()))
 		if err != nil {
 			return nil, fmt.Errorf("collector from files - opening file %s: %w", fileInfo.Name(), err)
 		}
-		dataProviders[i] = &dataProvider
+		dataProvider.tmp = tmpQuota.forceReserve(uint64(fileInfo.Size()))
+		dataProviders[i] = dataProvider
 	}
-	return &Collector{dataProviders: dataProviders, allFlushed: true, autoClean: false, logPrefix: logPrefix}, nil
+	return &Collector{dataProviders: dataProviders, allFlushed: true, autoClean: false, logPrefix: logPrefix, workers: Workers}, nil
 }
 
 // NewCriticalCollector does not clean up temporary files if loading has failed
@@ -93,11 +97,17 @@ func NewCriticalCollector(logPrefix, tmpdir string, sortableBuffer Buffer, logge
 }
 
 func NewCollector(logPrefix, tmpdir string, sortableBuffer Buffer, logger log.Logger) *Collector {
-	return &Collector{autoClean: true, bufType: getTypeByBuffer(sortableBuffer), buf: sortableBuffer, logPrefix: logPrefix, tmpdir: tmpdir, logLvl: log.LvlInfo, logger: logger}
+	return &Collector{autoClean: true, bufType: getTypeByBuffer(sortableBuffer), buf: sortableBuffer, logPrefix: logPrefix, tmpdir: tmpdir, logLvl: log.LvlInfo, logger: logger, workers: Workers}
 }
 
 func (c *Collector) SortAndFlushInBackground(v bool) { c.sortAndFlushInBackground = v }
 
+// Workers - amount of goroutines to sort buffer and to merge spill files on Load. Default: etl.Workers
+func (c *Collector) Workers(n int) { c.workers = n }
+
+// SpillCompression - compress files flushed to tmpdir. Reduces tmp disk pressure for well-compressible data (trie branches, commitment) - costs CPU.
+func (c *Collector) SpillCompression(v Compression) { c.compression = v }
+
 func (c *Collector) extractNextFunc(originalK, k []byte, v []byte) error {
 	c.buf.Put(k, v)
 	if !c.buf.CheckFlushSize() {
@@ -119,25 +129,28 @@ func (c *Collector) flushBuffer(canStoreInRam bool) error {
 
 	var provider dataProvider
 	if canStoreInRam && len(c.dataProviders) == 0 {
-		c.buf.Sort()
+		sortBuffer(c.buf, c.workers)
 		provider = KeepInRAM(c.buf)
 		c.allFlushed = true
 	} else {
 		doFsync := !c.autoClean /* is critical collector */
-		var err error
+		reserved, err := tmpQuota.reserve(c.logPrefix, uint64(bufferSize(c.buf)), c.tmpBytes(), c.logger)
+		if err != nil {
+			return err
+		}
 
 		if c.sortAndFlushInBackground {
 			fullBuf := c.buf // can't `.Reset()` because this `buf` will move to another goroutine
 			prevLen, prevSize := fullBuf.Len(), fullBuf.SizeLimit()
 			c.buf = getBufferByType(c.bufType, datasize.ByteSize(c.buf.SizeLimit()), c.buf)
 
-			provider, err = FlushToDiskAsync(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.logLvl)
+			provider, err = FlushToDiskAsync(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.compression, c.workers, reserved, c.logLvl)
 			if err != nil {
 				return err
 			}
 			c.buf.Prealloc(prevLen/8, prevSize/8)
 		} else {
-			provider, err = FlushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.logLvl)
+			provider, err = FlushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.compression, c.workers, reserved, c.logLvl)
 			if err != nil {
 				return err
 			}
@@ -150,6 +163,24 @@ func (c *Collector) flushBuffer(canStoreInRam bool) error {
 	return nil
 }
 
+// bufferSize - estimation of spill file size: encoding adds only varint lengths
+func bufferSize(b Buffer) int {
+	if s, ok := b.(interface{ Size() int }); ok {
+		return s.Size()
+	}
+	return b.SizeLimit()
+}
+
+// tmpBytes - tmpdir space held by this collector's files
+func (c *Collector) tmpBytes() (res uint64) {
+	for _, p := range c.dataProviders {
+		if fp, ok := p.(*fileDataProvider); ok {
+			res += fp.tmpBytes()
+		}
+	}
+	return res
+}
+
 // Flush - an optional method (usually user don't need to call it) - forcing sort+flush current buffer.
 // it does trigger background sort and flush, reducing RAM-holding, etc...
 // it's useful when working with many collectors: to trigger background sort for all of them
@@ -254,7 +285,7 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 	simpleLoad := func(k, v []byte) error {
 		return loadFunc(k, v, currentTable, loadNextFunc)
 	}
-	if err := mergeSortFiles(c.logPrefix, c.dataProviders, simpleLoad, args, c.buf); err != nil {
+	if err := mergeSortFiles(c.logPrefix, c.dataProviders, simpleLoad, args, c.buf, c.workers); err != nil {
 		return fmt.Errorf("loadIntoTable %s: %w", toBucket, err)
 	}
 	//logger.Trace(fmt.Sprintf("[%s] ETL Load done", c.logPrefix), "bucket", bucket, "records", i)
@@ -283,71 +314,54 @@ func (c *Collector) Close() {
 // for the next item, which is then added back to the heap.
 // The subsequent iterations pop the heap again and load up the provider associated with it to get the next element after processing LoadFunc.
 // this continues until all providers have reached their EOF.
-func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleLoadFunc, args TransformArgs, buf Buffer) (err error) {
+func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleLoadFunc, args TransformArgs, buf Buffer, workers int) (err error) {
 	for _, provider := range providers {
 		if err := provider.Wait(); err != nil {
 			return err
 		}
 	}
 
-	h := &Heap{}
-	heapInit(h)
-	for i, provider := range providers {
-		if key, value, err := provider.Next(nil, nil); err == nil {
-			heapPush(h, &HeapElem{key, value, i})
-		} else /* we must have at least one entry per file */ {
-			eee := fmt.Errorf("%s: error reading first readers: n=%d current=%d provider=%s err=%w",
-				logPrefix, len(providers), i, provider, err)
-			panic(eee)
-		}
-	}
-
 	var prevK, prevV []byte
-
-	// Main loading loop
-	for h.Len() > 0 {
-		if err := common.Stopped(args.Quit); err != nil {
-			return err
-		}
-
-		element := heapPop(h)
-		provider := providers[element.TimeIdx]
-
+	emit := func(k, v []byte) error {
 		// SortableOldestAppearedBuffer must guarantee that only 1 oldest value of key will appear
 		// but because size of buffer is limited - each flushed file does guarantee "oldest appeared"
 		// property, but files may overlap. files are sorted, just skip repeated keys here
 		if args.BufferType == SortableOldestAppearedBuffer {
-			if !bytes.Equal(prevK, element.Key) {
-				if err = loadFunc(element.Key, element.Value); err != nil {
+			if !bytes.Equal(prevK, k) {
+				if err := loadFunc(k, v); err != nil {
 					return err
 				}
 				// Need to copy k because the underlying space will be re-used for the next key
-				prevK = common.Copy(element.Key)
+				prevK = common.Copy(k)
 			}
 		} else if args.BufferType == SortableAppendBuffer {
-			if !bytes.Equal(prevK, element.Key) {
+			if !bytes.Equal(prevK, k) {
 				if prevK != nil {
-					if err = loadFunc(prevK, prevV); err != nil {
+					if err := loadFunc(prevK, prevV); err != nil {
 						return err
 					}
 				}
 				// Need to copy k because the underlying space will be re-used for the next key
-				prevK = common.Copy(element.Key)
-				prevV = common.Copy(element.Value)
+				prevK = common.Copy(k)
+				prevV = common.Copy(v)
 			} else {
-				prevV = append(prevV, element.Value...)
+				prevV = append(prevV, v...)
 			}
 		} else {
-			if err = loadFunc(element.Key, element.Value); err != nil {
+			if err := loadFunc(k, v); err != nil {
 				return err
 			}
 		}
+		return nil
+	}
 
-		if element.Key, element.Value, err = provider.Next(element.Key[:0], element.Value[:0]); err == nil {
-			heapPush(h, element)
-		} else if !errors.Is(err, io.EOF) {
-			return fmt.Errorf("%s: error while reading next element from disk: %w", logPrefix, err)
-		}
+	if workers > 1 && len(providers) >= parallelMergeMinFiles {
+		err = mergeProvidersParallel(logPrefix, providers, workers, args.Quit, emit)
+	} else {
+		err = mergeProviders(logPrefix, providers, args.Quit, emit)
+	}
+	if err != nil {
+		return err
 	}
 
 	if args.BufferType == SortableAppendBuffer {
@@ -361,6 +375,40 @@ func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleL
 	return nil
 }
 
+func mergeProviders(logPrefix string, providers []dataProvider, quit <-chan struct{}, emit func(k, v []byte) error) (err error) {
+	h := &Heap{}
+	heapInit(h)
+	for i, provider := range providers {
+		if key, value, err := provider.Next(nil, nil); err == nil {
+			heapPush(h, &HeapElem{key, value, i})
+		} else /* we must have at least one entry per file */ {
+			eee := fmt.Errorf("%s: error reading first readers: n=%d current=%d provider=%s err=%w",
+				logPrefix, len(providers), i, provider, err)
+			panic(eee)
+		}
+	}
+
+	// Main loading loop
+	for h.Len() > 0 {
+		if err := common.Stopped(quit); err != nil {
+			return err
+		}
+
+		element := heapPop(h)
+		provider := providers[element.TimeIdx]
+		if err = emit(element.Key, element.Value); err != nil {
+			return err
+		}
+
+		if element.Key, element.Value, err = provider.Next(element.Key[:0], element.Value[:0]); err == nil {
+			heapPush(h, element)
+		} else if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: error while reading next element from disk: %w", logPrefix, err)
+		}
+	}
+	return nil
+}
+
 func makeCurrentKeyStr(k []byte) string {
 	var currentKeyStr string
 	if k == nil {
@@ -0,0 +1,118 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package etl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression - of spill files (sorted buffers flushed to tmpdir).
+// Worth it for well-compressible data (trie branches, commitment) when tmp disk is a bottleneck - costs CPU.
+type Compression uint8
+
+const (
+	CompressNone Compression = iota
+	CompressS2               // fast, lz4-class ratio
+	CompressZstd             // better ratio, slower
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressNone:
+		return "none"
+	case CompressS2:
+		return "s2"
+	case CompressZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// fileExt - compression is visible in spill file name: NewCollectorFromFiles must know how to read leftovers
+func (c Compression) fileExt() string {
+	switch c {
+	case CompressS2:
+		return ".s2"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return CompressNone, nil
+	case "s2":
+		return CompressS2, nil
+	case "zstd":
+		return CompressZstd, nil
+	default:
+		return CompressNone, fmt.Errorf("unknown etl compression: %s", s)
+	}
+}
+
+func compressionByFileName(name string) Compression {
+	switch {
+	case strings.HasSuffix(name, CompressS2.fileExt()):
+		return CompressS2
+	case strings.HasSuffix(name, CompressZstd.fileExt()):
+		return CompressZstd
+	default:
+		return CompressNone
+	}
+}
+
+// newSpillWriter - returned closer must be called after last write (it flushes compressor), it doesn't close `w`
+func newSpillWriter(w io.Writer, c Compression) (io.Writer, func() error, error) {
+	switch c {
+	case CompressS2:
+		enc := s2.NewWriter(w, s2.WriterConcurrency(1))
+		return enc, enc.Close, nil
+	case CompressZstd:
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, nil, err
+		}
+		return enc, enc.Close, nil
+	default:
+		return w, func() error { return nil }, nil
+	}
+}
+
+// newSpillReader - returned closer releases decompressor resources, it doesn't close `r`
+func newSpillReader(r io.Reader, c Compression) (io.Reader, func(), error) {
+	switch c {
+	case CompressS2:
+		return s2.NewReader(r), func() {}, nil
+	case CompressZstd:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			return nil, nil, err
+		}
+		return dec, dec.Close, nil
+	default:
+		return r, func() {}, nil
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/ledgerwatch/log/v3"
 	"golang.org/x/sync/errgroup"
@@ -35,24 +36,31 @@ type dataProvider interface {
 }
 
 type fileDataProvider struct {
-	file       *os.File
-	reader     io.Reader
-	byteReader io.ByteReader // Different interface to the same object as reader
-	wg         *errgroup.Group
+	file        *os.File
+	compression Compression
+	reader      io.Reader
+	byteReader  io.ByteReader // Different interface to the same object as reader
+	closeReader func()
+	wg          *errgroup.Group
+	tmpMu       sync.Mutex
+	tmp         tmpReservation // held in tmpQuota
 }
 
 // FlushToDiskAsync - `doFsync` is true only for 'critical' collectors (which should not loose).
-func FlushToDiskAsync(logPrefix string, b Buffer, tmpdir string, doFsync bool, lvl log.Lvl) (dataProvider, error) {
+// `reserved` - space already reserved in tmpQuota for this buffer, provider takes ownership of it.
+func FlushToDiskAsync(logPrefix string, b Buffer, tmpdir string, doFsync bool, compression Compression, workers int, reserved tmpReservation, lvl log.Lvl) (dataProvider, error) {
 	if b.Len() == 0 {
+		tmpQuota.release(reserved)
 		return nil, nil
 	}
 
-	provider := &fileDataProvider{reader: nil, wg: &errgroup.Group{}}
+	provider := &fileDataProvider{reader: nil, compression: compression, wg: &errgroup.Group{}, tmp: reserved}
 	provider.wg.Go(func() (err error) {
-		provider.file, err = sortAndFlush(b, tmpdir, doFsync)
+		provider.file, err = sortAndFlush(b, tmpdir, doFsync, compression, workers)
 		if err != nil {
 			return err
 		}
+		provider.adjustTmpBytes()
 		_, fName := filepath.Split(provider.file.Name())
 		log.Log(lvl, fmt.Sprintf("[%s] Flushed buffer file", logPrefix), "name", fName)
 		return nil
@@ -62,24 +70,28 @@ func FlushToDiskAsync(logPrefix string, b Buffer, tmpdir string, doFsync bool, l
 }
 
 // FlushToDisk - `doFsync` is true only for 'critical' collectors (which should not loose).
-func FlushToDisk(logPrefix string, b Buffer, tmpdir string, doFsync bool, lvl log.Lvl) (dataProvider, error) {
+// `reserved` - space already reserved in tmpQuota for this buffer, provider takes ownership of it.
+func FlushToDisk(logPrefix string, b Buffer, tmpdir string, doFsync bool, compression Compression, workers int, reserved tmpReservation, lvl log.Lvl) (dataProvider, error) {
 	if b.Len() == 0 {
+		tmpQuota.release(reserved)
 		return nil, nil
 	}
 
 	var err error
-	provider := &fileDataProvider{reader: nil, wg: &errgroup.Group{}}
-	provider.file, err = sortAndFlush(b, tmpdir, doFsync)
+	provider := &fileDataProvider{reader: nil, compression: compression, wg: &errgroup.Group{}, tmp: reserved}
+	provider.file, err = sortAndFlush(b, tmpdir, doFsync, compression, workers)
 	if err != nil {
+		tmpQuota.release(reserved)
 		return nil, err
 	}
+	provider.adjustTmpBytes()
 	_, fName := filepath.Split(provider.file.Name())
 	log.Log(lvl, fmt.Sprintf("[%s] Flushed buffer file", logPrefix), "name", fName)
 	return provider, nil
 }
 
-func sortAndFlush(b Buffer, tmpdir string, doFsync bool) (*os.File, error) {
-	b.Sort()
+func sortAndFlush(b Buffer, tmpdir string, doFsync bool, compression Compression, workers int) (*os.File, error) {
+	sortBuffer(b, workers)
 
 	// if we are going to create files in the system temp dir, we don't need any
 	// subfolders.
@@ -89,7 +101,7 @@ func sortAndFlush(b Buffer, tmpdir string, doFsync bool) (*os.File, error) {
 		}
 	}
 
-	bufferFile, err := os.CreateTemp(tmpdir, "erigon-sortable-buf-")
+	bufferFile, err := os.CreateTemp(tmpdir, "erigon-sortable-buf-*"+compression.fileExt())
 	if err != nil {
 		return nil, err
 	}
@@ -98,12 +110,20 @@ func sortAndFlush(b Buffer, tmpdir string, doFsync bool) (*os.File, error) {
 		defer bufferFile.Sync() //nolint:errcheck
 	}
 
-	w := bufio.NewWriterSize(bufferFile, BufIOSize)
-	defer w.Flush() //nolint:errcheck
-
+	cw, closeCompressor, err := newSpillWriter(bufferFile, compression)
+	if err != nil {
+		return bufferFile, err
+	}
+	w := bufio.NewWriterSize(cw, BufIOSize)
 	if err = b.Write(w); err != nil {
 		return bufferFile, fmt.Errorf("error writing entries to disk: %w", err)
 	}
+	if err = w.Flush(); err != nil {
+		return bufferFile, fmt.Errorf("error writing entries to disk: %w", err)
+	}
+	if err = closeCompressor(); err != nil {
+		return bufferFile, fmt.Errorf("error writing entries to disk: %w", err)
+	}
 	return bufferFile, nil
 }
 
@@ -113,26 +133,57 @@ func (p *fileDataProvider) Next(keyBuf, valBuf []byte) ([]byte, []byte, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		r := bufio.NewReaderSize(p.file, BufIOSize)
+		cr, closeReader, err := newSpillReader(p.file, p.compression)
+		if err != nil {
+			return nil, nil, err
+		}
+		r := bufio.NewReaderSize(cr, BufIOSize)
 		p.reader = r
 		p.byteReader = r
+		p.closeReader = closeReader
 
 	}
 	return readElementFromDisk(p.reader, p.byteReader, keyBuf, valBuf)
 }
 
+// adjustTmpBytes - replace reservation by real file size (compressed files are smaller than buffer)
+func (p *fileDataProvider) adjustTmpBytes() {
+	st, err := p.file.Stat()
+	if err != nil {
+		return
+	}
+	p.tmpMu.Lock()
+	defer p.tmpMu.Unlock()
+	p.tmp = tmpQuota.adjust(p.tmp, uint64(st.Size()))
+}
+
+// tmpBytes - tmpdir space held by this provider
+func (p *fileDataProvider) tmpBytes() uint64 {
+	p.tmpMu.Lock()
+	defer p.tmpMu.Unlock()
+	return p.tmp.size
+}
+
 func (p *fileDataProvider) Wait() error { return p.wg.Wait() }
 func (p *fileDataProvider) Dispose() {
+	p.Wait()           // async flush may still own file and quota
 	if p.file != nil { //invariant: safe to call multiple time
-		p.Wait()
+		if p.closeReader != nil {
+			p.closeReader()
+			p.closeReader = nil
+		}
 		_ = p.file.Close()
 		go func(fPath string) { _ = os.Remove(fPath) }(p.file.Name())
 		p.file = nil
 	}
+	p.tmpMu.Lock()
+	tmpQuota.release(p.tmp)
+	p.tmp = tmpReservation{}
+	p.tmpMu.Unlock()
 }
 
 func (p *fileDataProvider) String() string {
-	return fmt.Sprintf("%T(file: %s)", p, p.file.Name())
+	return fmt.Sprintf("%T(file: %s, compression: %s)", p, p.file.Name(), p.compression)
 }
 
 func readElementFromDisk(r io.Reader, br io.ByteReader, keyBuf, valBuf []byte) ([]byte, []byte, error) {
@@ -26,6 +26,9 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/c2h5oh/datasize"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 
@@ -208,6 +211,45 @@ func TestFileDataProviders(t *testing.T) {
 	}
 }
 
+func TestCompressedFileDataProviders(t *testing.T) {
+	logger := log.New()
+	for _, compression := range []Compression{CompressNone, CompressS2, CompressZstd} {
+		compression := compression
+		t.Run(compression.String(), func(t *testing.T) {
+			_, tx := memdb.NewTestTx(t)
+			collector := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(4*1024), logger)
+			defer collector.Close()
+			collector.SpillCompression(compression)
+			for i := 999; i >= 0; i-- {
+				require.NoError(t, collector.Collect([]byte(fmt.Sprintf("key-%05d", i)), []byte(fmt.Sprintf("val-%099d", i))))
+			}
+			require.NoError(t, collector.Flush())
+			require.Greater(t, len(collector.dataProviders), 1)
+			var spillSize int64
+			for _, p := range collector.dataProviders {
+				fp := p.(*fileDataProvider)
+				require.NoError(t, fp.Wait())
+				require.Equal(t, compression, compressionByFileName(fp.file.Name()))
+				st, err := os.Stat(fp.file.Name())
+				require.NoError(t, err)
+				spillSize += st.Size()
+			}
+			if compression != CompressNone {
+				require.Less(t, spillSize, int64(1000*len(fmt.Sprintf("key-%05dval-%099d", 0, 0)))/2)
+			}
+
+			i := 0
+			require.NoError(t, collector.Load(tx, "", func(k, v []byte, _ CurrentTableReader, _ LoadNextFunc) error {
+				require.Equal(t, fmt.Sprintf("key-%05d", i), string(k))
+				require.Equal(t, fmt.Sprintf("val-%099d", i), string(v))
+				i++
+				return nil
+			}, TransformArgs{}))
+			require.Equal(t, 1000, i)
+		})
+	}
+}
+
 func TestRAMDataProviders(t *testing.T) {
 	logger := log.New()
 	// test invariant when we go through memory (1 buffer)
@@ -631,3 +673,124 @@ func TestSortable(t *testing.T) {
 	require.Equal([][]byte{{1}, {2}, {3}, {4}, {5}, {6}, {7}, {1}, {20}, nil}, vals)
 
 }
+
+func TestParallelSortAndMerge(t *testing.T) {
+	logger := log.New()
+	collect := func(workers int, buf Buffer) (keys, vals []string) {
+		collector := NewCollector(t.Name(), t.TempDir(), buf, logger)
+		defer collector.Close()
+		collector.Workers(workers)
+		for i := 0; i < 200_000; i++ {
+			k := []byte(fmt.Sprintf("key-%05d", (i*7919)%50_000)) // each key appears 4 times
+			require.NoError(t, collector.Collect(k, []byte(fmt.Sprintf("%d", i))))
+		}
+		_, tx := memdb.NewTestTx(t)
+		require.NoError(t, collector.Load(tx, "", func(k, v []byte, _ CurrentTableReader, _ LoadNextFunc) error {
+			keys, vals = append(keys, string(k)), append(vals, string(v))
+			return nil
+		}, TransformArgs{}))
+		return keys, vals
+	}
+
+	for _, bufSize := range []datasize.ByteSize{256 * datasize.KB, 64 * datasize.MB} { // many files, single in-RAM buffer
+		seqKeys, seqVals := collect(1, NewSortableBuffer(bufSize))
+		require.Len(t, seqKeys, 200_000)
+		require.True(t, sort.StringsAreSorted(seqKeys))
+		parKeys, parVals := collect(8, NewSortableBuffer(bufSize))
+		require.Equal(t, seqKeys, parKeys)
+		require.Equal(t, seqVals, parVals) // stable: duplicates keep order of Collect
+
+		seqKeys, seqVals = collect(1, NewOldestEntryBuffer(bufSize))
+		require.Len(t, seqKeys, 50_000)
+		parKeys, parVals = collect(8, NewOldestEntryBuffer(bufSize))
+		require.Equal(t, seqKeys, parKeys)
+		require.Equal(t, seqVals, parVals)
+	}
+}
+
+func TestTmpDirQuota(t *testing.T) {
+	logger := log.New()
+	SetTmpDirQuota(64 * datasize.KB)
+	defer SetTmpDirQuota(0)
+	collect := func(c *Collector, n int) error {
+		for i := 0; i < n; i++ {
+			if err := c.Collect([]byte(fmt.Sprintf("key-%05d", i)), []byte(fmt.Sprintf("val-%04d", i%10_000))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// collector alone filled quota: waiting is useless - clear error
+	a := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(16*datasize.KB), logger)
+	err := collect(a, 100_000)
+	require.ErrorIs(t, err, ErrTmpQuotaExceeded)
+	require.LessOrEqual(t, TmpDirUsage(), 64*datasize.KB)
+	a.Close()
+	require.Zero(t, TmpDirUsage())
+
+	// quota is held by other collector: producer is blocked until space is free
+	a = NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(16*datasize.KB), logger)
+	defer a.Close()
+	require.NoError(t, collect(a, 2_500))
+	require.Greater(t, TmpDirUsage(), 40*datasize.KB)
+
+	b := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(32*datasize.KB), logger)
+	defer b.Close()
+	done := make(chan error, 1)
+	go func() { done <- collect(b, 1_100) }()
+	select {
+	case err := <-done:
+		t.Fatalf("expected producer to be blocked, got: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	a.Close()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("producer was not resumed after space was freed")
+	}
+	b.Close()
+	require.Zero(t, TmpDirUsage())
+}
+
+func TestTmpDirQuotaCollectorsOfOneGoroutine(t *testing.T) {
+	logger := log.New()
+	SetTmpDirQuota(64 * datasize.KB)
+	defer SetTmpDirQuota(0)
+
+	// like accTrieCollector/stTrieCollector: both are filled by one goroutine and loaded after - waiting for `acc` to free
+	// space would deadlock
+	acc := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(16*datasize.KB), logger)
+	defer acc.Close()
+	st := NewCollector(t.Name(), t.TempDir(), NewSortableBuffer(16*datasize.KB), logger)
+	defer st.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 100_000; i++ {
+			k, v := []byte(fmt.Sprintf("key-%05d", i)), []byte(fmt.Sprintf("val-%04d", i%10_000))
+			if err := acc.Collect(k, v); err != nil {
+				done <- err
+				return
+			}
+			if err := st.Collect(k, v); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrTmpQuotaExceeded)
+	case <-time.After(10 * time.Second):
+		t.Fatal("collectors of one goroutine are waiting for each other")
+	}
+	require.LessOrEqual(t, TmpDirUsage(), 64*datasize.KB)
+	acc.Close()
+	st.Close()
+	require.Zero(t, TmpDirUsage())
+	require.Empty(t, tmpQuota.owned)
+}
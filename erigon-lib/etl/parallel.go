@@ -0,0 +1,311 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package etl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+)
+
+// Workers - default amount of goroutines used by Collector to sort buffer and to merge spill files on Load.
+// Can be changed per-collector by Collector.Workers
+var Workers = dbg.EnvInt("ETL_WORKERS", defaultWorkers())
+
+func defaultWorkers() int {
+	// many collectors may work in parallel - don't take all cores
+	if n := runtime.NumCPU() / 4; n > 1 {
+		return n
+	}
+	return 1
+}
+
+const (
+	parallelSortMinChunk   = 16 * 1024 // entries. smaller buffers are sorted by 1 goroutine
+	parallelMergeMinFiles  = 4         // less files - merge by 1 goroutine
+	parallelMergeBatchSize = 1024      // elements sent from file-group merger to final merger at once
+)
+
+// sortBuffer - uses `workers` goroutines if buffer supports it and big enough
+func sortBuffer(b Buffer, workers int) {
+	if ps, ok := b.(interface{ sortParallel(workers int) }); ok && workers > 1 {
+		ps.sortParallel(workers)
+		return
+	}
+	b.Sort()
+}
+
+// parallelSortStable - returns stable-sorted permutation of [0, n). Chunks are sorted concurrently,
+// then merged pairwise (also concurrently). Returns nil if n too small for parallel sort - caller must use sort.Stable.
+func parallelSortStable(n int, less func(i, j int) bool, workers int) []int {
+	if workers > n/parallelSortMinChunk {
+		workers = n / parallelSortMinChunk
+	}
+	if workers <= 1 {
+		return nil
+	}
+	perm, tmp := make([]int, n), make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	bounds := make([]int, workers+1)
+	for i := range bounds {
+		bounds[i] = i * n / workers
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		chunk := perm[bounds[i]:bounds[i+1]]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sort.SliceStable(chunk, func(a, b int) bool { return less(chunk[a], chunk[b]) })
+		}()
+	}
+	wg.Wait()
+
+	for len(bounds) > 2 {
+		nextBounds := make([]int, 0, len(bounds)/2+1)
+		for i := 0; i+1 < len(bounds); i += 2 {
+			nextBounds = append(nextBounds, bounds[i])
+			if i+2 >= len(bounds) { // odd amount of chunks - last one just moved
+				copy(tmp[bounds[i]:bounds[i+1]], perm[bounds[i]:bounds[i+1]])
+				continue
+			}
+			from, mid, to := bounds[i], bounds[i+1], bounds[i+2]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				mergeStable(perm[from:mid], perm[mid:to], tmp[from:to], less)
+			}()
+		}
+		nextBounds = append(nextBounds, bounds[len(bounds)-1])
+		wg.Wait()
+		bounds = nextBounds
+		perm, tmp = tmp, perm
+	}
+	return perm
+}
+
+// mergeStable - on equal elements `a` goes first
+func mergeStable(a, b, dst []int, less func(i, j int) bool) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if less(b[j], a[i]) {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
+
+func (b *sortableBuffer) sortParallel(workers int) {
+	if sort.IsSorted(b) {
+		return
+	}
+	perm := parallelSortStable(b.Len(), b.Less, workers)
+	if perm == nil {
+		sort.Stable(b)
+		return
+	}
+	offsets, lens := make([]int, len(b.offsets)), make([]int, len(b.lens))
+	for to, from := range perm {
+		offsets[to*2], offsets[to*2+1] = b.offsets[from*2], b.offsets[from*2+1]
+		lens[to*2], lens[to*2+1] = b.lens[from*2], b.lens[from*2+1]
+	}
+	b.offsets, b.lens = offsets, lens
+}
+
+func bytesLess(a, b []byte) bool { return bytes.Compare(a, b) < 0 }
+
+func sortEntriesParallel(entries []sortableBufferEntry, workers int) []sortableBufferEntry {
+	perm := parallelSortStable(len(entries), func(i, j int) bool { return bytesLess(entries[i].key, entries[j].key) }, workers)
+	if perm == nil {
+		sort.SliceStable(entries, func(i, j int) bool { return bytesLess(entries[i].key, entries[j].key) })
+		return entries
+	}
+	sorted := make([]sortableBufferEntry, len(entries))
+	for to, from := range perm {
+		sorted[to] = entries[from]
+	}
+	return sorted
+}
+
+func (b *appendSortableBuffer) sortParallel(workers int) {
+	for key, val := range b.entries {
+		b.sortedBuf = append(b.sortedBuf, sortableBufferEntry{key: []byte(key), value: val})
+	}
+	b.sortedBuf = sortEntriesParallel(b.sortedBuf, workers)
+}
+
+func (b *oldestEntrySortableBuffer) sortParallel(workers int) {
+	for k, v := range b.entries {
+		b.sortedBuf = append(b.sortedBuf, sortableBufferEntry{key: []byte(k), value: v})
+	}
+	b.sortedBuf = sortEntriesParallel(b.sortedBuf, workers)
+}
+
+// mergeGroup - sorted stream of elements of several providers, merged by separated goroutine
+type mergeGroup struct {
+	ch    chan []HeapElem
+	batch []HeapElem
+	pos   int
+}
+
+func (g *mergeGroup) next() (*HeapElem, bool) {
+	if g.pos >= len(g.batch) {
+		batch, ok := <-g.ch
+		if !ok {
+			return nil, false
+		}
+		g.batch, g.pos = batch, 0
+	}
+	e := &g.batch[g.pos]
+	g.pos++
+	return e, true
+}
+
+// mergeProvidersParallel - providers are split into `workers` groups, each group merged by own goroutine (reading/decompression/heap),
+// then group streams are merged in caller's goroutine. Order is same as in sequential merge: by key, then by provider index.
+func mergeProvidersParallel(logPrefix string, providers []dataProvider, workers int, quit <-chan struct{}, emit func(k, v []byte) error) error {
+	if workers > len(providers)/2 {
+		workers = len(providers) / 2
+	}
+	g, ctx := errgroup.WithContext(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	groups := make([]*mergeGroup, workers)
+	groupOf := make([]int, len(providers))
+	for gi := range groups {
+		groups[gi] = &mergeGroup{ch: make(chan []HeapElem, 2)}
+		var idxs []int
+		for i := gi; i < len(providers); i += workers {
+			idxs = append(idxs, i)
+			groupOf[i] = gi
+		}
+		ch := groups[gi].ch
+		g.Go(func() error {
+			defer close(ch)
+			return mergeProvidersGroup(ctx, logPrefix, providers, idxs, ch)
+		})
+	}
+
+	consume := func() error {
+		h := &Heap{}
+		heapInit(h)
+		for _, group := range groups {
+			if e, ok := group.next(); ok {
+				heapPush(h, e)
+			}
+		}
+		for h.Len() > 0 {
+			if err := common.Stopped(quit); err != nil {
+				return err
+			}
+			e := heapPop(h)
+			if err := emit(e.Key, e.Value); err != nil {
+				return err
+			}
+			if next, ok := groups[groupOf[e.TimeIdx]].next(); ok {
+				heapPush(h, next)
+			}
+		}
+		return nil
+	}
+	err := consume()
+	cancel() // stop group mergers if consumer failed
+	for _, group := range groups {
+		for range group.ch { // unblock senders
+		}
+	}
+	if gErr := g.Wait(); gErr != nil && !errors.Is(gErr, context.Canceled) {
+		return gErr
+	}
+	return err
+}
+
+func mergeProvidersGroup(ctx context.Context, logPrefix string, providers []dataProvider, idxs []int, out chan<- []HeapElem) error {
+	h := &Heap{}
+	heapInit(h)
+	for _, i := range idxs {
+		key, value, err := providers[i].Next(nil, nil)
+		if err != nil { // we must have at least one entry per file
+			return fmt.Errorf("%s: error reading first readers: n=%d current=%d provider=%s err=%w", logPrefix, len(providers), i, providers[i], err)
+		}
+		heapPush(h, &HeapElem{key, value, i})
+	}
+
+	batch := make([]HeapElem, 0, parallelMergeBatchSize)
+	arena := make([]byte, 0, 64*1024)
+	send := func() error {
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		batch, arena = make([]HeapElem, 0, parallelMergeBatchSize), make([]byte, 0, 64*1024)
+		return nil
+	}
+	for h.Len() > 0 {
+		element := heapPop(h)
+		// copy: provider will re-use element's buffers
+		from := len(arena)
+		arena = append(arena, element.Key...)
+		arena = append(arena, element.Value...)
+		kEnd := from + len(element.Key)
+		var k, v []byte
+		if element.Key != nil {
+			k = arena[from:kEnd:kEnd]
+		}
+		if element.Value != nil {
+			v = arena[kEnd:len(arena):len(arena)]
+		}
+		batch = append(batch, HeapElem{Key: k, Value: v, TimeIdx: element.TimeIdx})
+		if len(batch) == parallelMergeBatchSize {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if element.Key, element.Value, err = providers[element.TimeIdx].Next(element.Key[:0], element.Value[:0]); err == nil {
+			heapPush(h, element)
+		} else if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: error while reading next element from disk: %w", logPrefix, err)
+		}
+	}
+	if len(batch) > 0 {
+		return send()
+	}
+	return nil
+}
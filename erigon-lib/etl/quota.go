@@ -0,0 +1,200 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package etl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var ErrTmpQuotaExceeded = errors.New("etl tmpdir quota exceeded")
+
+var (
+	tmpUsedBytes     = metrics.GetOrCreateGauge("etl_tmp_used_bytes")
+	tmpQuotaWaits    = metrics.GetOrCreateCounter("etl_tmp_quota_waits_total")
+	tmpQuotaExceeded = metrics.GetOrCreateCounter("etl_tmp_quota_exceeded_total")
+)
+
+// tmpQuota - limits total size of spill files of all collectors of process. 0 - unlimited.
+//
+// Collector reserves space before flushing buffer to tmpdir and frees it when files are removed (Load/Close).
+// If quota is reached - collector waits (and blocks it's producer) until other collectors free space:
+// it's better than filling the disk and crashing in the middle of stage.
+// If nobody except this collector, or the goroutine which feeds it, holds space - waiting is useless (for example
+// accTrieCollector and stTrieCollector are filled by one goroutine and loaded only after it's done): ErrTmpQuotaExceeded returned.
+var tmpQuota = newTmpDirQuota(uint64(dbg.EnvDataSize("ETL_TMP_QUOTA", 0)))
+
+// SetTmpDirQuota - limit of total size of etl spill files in tmpdir. 0 - unlimited.
+func SetTmpDirQuota(limit datasize.ByteSize) { tmpQuota.setLimit(uint64(limit)) }
+
+// TmpDirUsage - total size of etl spill files (or space reserved for files which are being written)
+func TmpDirUsage() datasize.ByteSize {
+	tmpQuota.lock.Lock()
+	defer tmpQuota.lock.Unlock()
+	return datasize.ByteSize(tmpQuota.used)
+}
+
+const tmpQuotaLogInterval = 30 * time.Second
+
+type tmpDirQuota struct {
+	lock     sync.Mutex
+	limit    uint64
+	used     uint64
+	owned    map[uint64]uint64 // goroutine id -> bytes reserved by it
+	released chan struct{}     // closed and replaced on each release - wakes up waiters
+}
+
+// tmpReservation - space held in tmpQuota and goroutine which reserved it
+type tmpReservation struct {
+	owner uint64
+	size  uint64
+}
+
+func newTmpDirQuota(limit uint64) *tmpDirQuota {
+	return &tmpDirQuota{limit: limit, owned: map[uint64]uint64{}, released: make(chan struct{})}
+}
+
+// goroutineID - id of current goroutine. Used only to detect self-deadlock: goroutine waits for space which only it can free
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+func (q *tmpDirQuota) setLimit(limit uint64) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.limit = limit
+	q.notify()
+}
+
+// notify - must be called under lock
+func (q *tmpDirQuota) notify() {
+	close(q.released)
+	q.released = make(chan struct{})
+}
+
+// reserve - blocks until `size` bytes fit into quota. `own` - bytes already held by calling collector. If quota is filled
+// only by them or by other reservations of current goroutine - nobody will release space and error is returned
+func (q *tmpDirQuota) reserve(logPrefix string, size, own uint64, logger log.Logger) (tmpReservation, error) {
+	if logger == nil {
+		logger = log.Root()
+	}
+	r := tmpReservation{owner: goroutineID(), size: size}
+	var waitStart time.Time
+	var logEvery *time.Ticker
+	for {
+		q.lock.Lock()
+		if q.limit == 0 || q.used+size <= q.limit {
+			q.used += size
+			q.owned[r.owner] += size
+			tmpUsedBytes.SetUint64(q.used)
+			q.lock.Unlock()
+			if logEvery != nil {
+				logEvery.Stop()
+				logger.Info(fmt.Sprintf("[%s] ETL tmpdir quota: resumed", logPrefix), "waited", time.Since(waitStart))
+			}
+			return r, nil
+		}
+		limit, used, mine, released := q.limit, q.used, max(own, q.owned[r.owner]), q.released
+		q.lock.Unlock()
+
+		if used <= mine {
+			if logEvery != nil {
+				logEvery.Stop()
+			}
+			tmpQuotaExceeded.Inc()
+			return tmpReservation{}, fmt.Errorf("[%s] %w: limit=%s, used_by_collector=%s, used_by_goroutine=%s, need=%s", logPrefix, ErrTmpQuotaExceeded,
+				datasize.ByteSize(limit).HR(), datasize.ByteSize(own).HR(), datasize.ByteSize(q.ownedBy(r.owner)).HR(), datasize.ByteSize(size).HR())
+		}
+		if logEvery == nil {
+			waitStart, logEvery = time.Now(), time.NewTicker(tmpQuotaLogInterval)
+			tmpQuotaWaits.Inc()
+			logger.Warn(fmt.Sprintf("[%s] ETL tmpdir quota reached, waiting for other collectors to free space", logPrefix),
+				"limit", datasize.ByteSize(limit).HR(), "used", datasize.ByteSize(used).HR(), "need", datasize.ByteSize(size).HR())
+		}
+		select {
+		case <-released:
+		case <-logEvery.C:
+			logger.Warn(fmt.Sprintf("[%s] ETL tmpdir quota reached, still waiting", logPrefix),
+				"waited", time.Since(waitStart), "limit", datasize.ByteSize(limit).HR(), "used", datasize.ByteSize(used).HR())
+		}
+	}
+}
+
+func (q *tmpDirQuota) ownedBy(owner uint64) uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.owned[owner]
+}
+
+func (q *tmpDirQuota) release(r tmpReservation) {
+	if r.size == 0 {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.used -= min(r.size, q.used)
+	if owned := q.owned[r.owner]; owned > r.size {
+		q.owned[r.owner] = owned - r.size
+	} else {
+		delete(q.owned, r.owner)
+	}
+	tmpUsedBytes.SetUint64(q.used)
+	q.notify()
+}
+
+// adjust - replaces reservation by real size of written file. Files may be bigger than reservation (encoding overhead) -
+// it's accounted without waiting: file already on disk.
+func (q *tmpDirQuota) adjust(r tmpReservation, actual uint64) tmpReservation {
+	if actual <= r.size {
+		q.release(tmpReservation{owner: r.owner, size: r.size - actual})
+		return tmpReservation{owner: r.owner, size: actual}
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.used += actual - r.size
+	q.owned[r.owner] += actual - r.size
+	tmpUsedBytes.SetUint64(q.used)
+	return tmpReservation{owner: r.owner, size: actual}
+}
+
+// forceReserve - account space of already existing files (leftovers of previous run)
+func (q *tmpDirQuota) forceReserve(size uint64) tmpReservation {
+	r := tmpReservation{owner: goroutineID(), size: size}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.used += size
+	q.owned[r.owner] += size
+	tmpUsedBytes.SetUint64(q.used)
+	return r
+}
@@ -22,6 +22,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type FileProgress_State int32
+
+const (
+	FileProgress_QUEUED      FileProgress_State = 0 // no metadata yet, or nothing downloaded and no sources
+	FileProgress_DOWNLOADING FileProgress_State = 1
+	FileProgress_VERIFYING   FileProgress_State = 2 // pieces are hashed: after download or by `--verify`
+	FileProgress_DONE        FileProgress_State = 3
+)
+
+// Enum value maps for FileProgress_State.
+var (
+	FileProgress_State_name = map[int32]string{
+		0: "QUEUED",
+		1: "DOWNLOADING",
+		2: "VERIFYING",
+		3: "DONE",
+	}
+	FileProgress_State_value = map[string]int32{
+		"QUEUED":      0,
+		"DOWNLOADING": 1,
+		"VERIFYING":   2,
+		"DONE":        3,
+	}
+)
+
+func (x FileProgress_State) Enum() *FileProgress_State {
+	p := new(FileProgress_State)
+	*p = x
+	return p
+}
+
+func (x FileProgress_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileProgress_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_downloader_downloader_proto_enumTypes[0].Descriptor()
+}
+
+func (FileProgress_State) Type() protoreflect.EnumType {
+	return &file_downloader_downloader_proto_enumTypes[0]
+}
+
+func (x FileProgress_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileProgress_State.Descriptor instead.
+func (FileProgress_State) EnumDescriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{12, 0}
+}
+
 // DownloadItem:
 // - if Erigon created new snapshot and want seed it
 // - if Erigon wnat download files - it fills only "torrent_hash" field
@@ -422,6 +474,454 @@ func (x *StatsReply) GetDownloadRate() uint64 {
 	return 0
 }
 
+// BandwidthRule: first matching rule wins (for each direction separately)
+type BandwidthRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Days     uint32  `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`          // bit per weekday: sunday - 1<<0, ..., saturday - 1<<6. 0 - every day
+	From     uint32  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`          // seconds since midnight
+	To       uint32  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`              // seconds since midnight, to < from - till next day, to == from - whole day
+	Download float64 `protobuf:"fixed64,4,opt,name=download,proto3" json:"download,omitempty"` // bytes/sec, 0 - not limited by this rule, +Inf - unlimited
+	Upload   float64 `protobuf:"fixed64,5,opt,name=upload,proto3" json:"upload,omitempty"`     // bytes/sec, 0 - not limited by this rule, +Inf - unlimited
+}
+
+func (x *BandwidthRule) Reset() {
+	*x = BandwidthRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BandwidthRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BandwidthRule) ProtoMessage() {}
+
+func (x *BandwidthRule) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandwidthRule.ProtoReflect.Descriptor instead.
+func (*BandwidthRule) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BandwidthRule) GetDays() uint32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *BandwidthRule) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *BandwidthRule) GetTo() uint32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *BandwidthRule) GetDownload() float64 {
+	if x != nil {
+		return x.Download
+	}
+	return 0
+}
+
+func (x *BandwidthRule) GetUpload() float64 {
+	if x != nil {
+		return x.Upload
+	}
+	return 0
+}
+
+type BandwidthScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *BandwidthScheduleRequest) Reset() {
+	*x = BandwidthScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BandwidthScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BandwidthScheduleRequest) ProtoMessage() {}
+
+func (x *BandwidthScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandwidthScheduleRequest.ProtoReflect.Descriptor instead.
+func (*BandwidthScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{8}
+}
+
+type SetBandwidthScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rules []*BandwidthRule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *SetBandwidthScheduleRequest) Reset() {
+	*x = SetBandwidthScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetBandwidthScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBandwidthScheduleRequest) ProtoMessage() {}
+
+func (x *SetBandwidthScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBandwidthScheduleRequest.ProtoReflect.Descriptor instead.
+func (*SetBandwidthScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetBandwidthScheduleRequest) GetRules() []*BandwidthRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type BandwidthScheduleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rules []*BandwidthRule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *BandwidthScheduleReply) Reset() {
+	*x = BandwidthScheduleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BandwidthScheduleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BandwidthScheduleReply) ProtoMessage() {}
+
+func (x *BandwidthScheduleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandwidthScheduleReply.ProtoReflect.Descriptor instead.
+func (*BandwidthScheduleReply) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BandwidthScheduleReply) GetRules() []*BandwidthRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ProgressRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ProgressRequest) Reset() {
+	*x = ProgressRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressRequest) ProtoMessage() {}
+
+func (x *ProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressRequest.ProtoReflect.Descriptor instead.
+func (*ProgressRequest) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{11}
+}
+
+type FileProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name           string             `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	State          FileProgress_State `protobuf:"varint,2,opt,name=state,proto3,enum=downloader.FileProgress_State" json:"state,omitempty"`
+	BytesTotal     uint64             `protobuf:"varint,3,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"`
+	BytesCompleted uint64             `protobuf:"varint,4,opt,name=bytes_completed,json=bytesCompleted,proto3" json:"bytes_completed,omitempty"`
+	Peers          uint32             `protobuf:"varint,5,opt,name=peers,proto3" json:"peers,omitempty"`
+	Webseeds       uint32             `protobuf:"varint,6,opt,name=webseeds,proto3" json:"webseeds,omitempty"`
+	PeersRate      uint64             `protobuf:"varint,7,opt,name=peers_rate,json=peersRate,proto3" json:"peers_rate,omitempty"`          // bytes/sec, from BitTorrent peers
+	WebseedsRate   uint64             `protobuf:"varint,8,opt,name=webseeds_rate,json=webseedsRate,proto3" json:"webseeds_rate,omitempty"` // bytes/sec, from webseeds: torrent webseeds and web downloads
+	Eta            uint64             `protobuf:"varint,9,opt,name=eta,proto3" json:"eta,omitempty"`                                       // seconds, 0 - unknown (no sources) or done
+}
+
+func (x *FileProgress) Reset() {
+	*x = FileProgress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileProgress) ProtoMessage() {}
+
+func (x *FileProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileProgress.ProtoReflect.Descriptor instead.
+func (*FileProgress) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FileProgress) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileProgress) GetState() FileProgress_State {
+	if x != nil {
+		return x.State
+	}
+	return FileProgress_QUEUED
+}
+
+func (x *FileProgress) GetBytesTotal() uint64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+func (x *FileProgress) GetBytesCompleted() uint64 {
+	if x != nil {
+		return x.BytesCompleted
+	}
+	return 0
+}
+
+func (x *FileProgress) GetPeers() uint32 {
+	if x != nil {
+		return x.Peers
+	}
+	return 0
+}
+
+func (x *FileProgress) GetWebseeds() uint32 {
+	if x != nil {
+		return x.Webseeds
+	}
+	return 0
+}
+
+func (x *FileProgress) GetPeersRate() uint64 {
+	if x != nil {
+		return x.PeersRate
+	}
+	return 0
+}
+
+func (x *FileProgress) GetWebseedsRate() uint64 {
+	if x != nil {
+		return x.WebseedsRate
+	}
+	return 0
+}
+
+func (x *FileProgress) GetEta() uint64 {
+	if x != nil {
+		return x.Eta
+	}
+	return 0
+}
+
+type ProgressReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files          []*FileProgress `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"` // ordered by name
+	BytesTotal     uint64          `protobuf:"varint,2,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"`
+	BytesCompleted uint64          `protobuf:"varint,3,opt,name=bytes_completed,json=bytesCompleted,proto3" json:"bytes_completed,omitempty"`
+	DownloadRate   uint64          `protobuf:"varint,4,opt,name=download_rate,json=downloadRate,proto3" json:"download_rate,omitempty"` // bytes/sec
+	Eta            uint64          `protobuf:"varint,5,opt,name=eta,proto3" json:"eta,omitempty"`                                       // seconds, 0 - unknown or completed
+	Completed      bool            `protobuf:"varint,6,opt,name=completed,proto3" json:"completed,omitempty"`
+}
+
+func (x *ProgressReply) Reset() {
+	*x = ProgressReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_downloader_downloader_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProgressReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressReply) ProtoMessage() {}
+
+func (x *ProgressReply) ProtoReflect() protoreflect.Message {
+	mi := &file_downloader_downloader_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressReply.ProtoReflect.Descriptor instead.
+func (*ProgressReply) Descriptor() ([]byte, []int) {
+	return file_downloader_downloader_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ProgressReply) GetFiles() []*FileProgress {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ProgressReply) GetBytesTotal() uint64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetBytesCompleted() uint64 {
+	if x != nil {
+		return x.BytesCompleted
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetDownloadRate() uint64 {
+	if x != nil {
+		return x.DownloadRate
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetEta() uint64 {
+	if x != nil {
+		return x.Eta
+	}
+	return 0
+}
+
+func (x *ProgressReply) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
 var File_downloader_downloader_proto protoreflect.FileDescriptor
 
 var file_downloader_downloader_proto_rawDesc = []byte{
@@ -469,32 +969,106 @@ var file_downloader_downloader_proto_rawDesc = []byte{
 	0x64, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x75, 0x70,
 	0x6c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x6f, 0x77, 0x6e,
 	0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x0c, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x74, 0x65, 0x32, 0xdb, 0x02,
-	0x0a, 0x0a, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x12, 0x59, 0x0a, 0x14,
-	0x50, 0x72, 0x6f, 0x68, 0x69, 0x62, 0x69, 0x74, 0x4e, 0x65, 0x77, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
-	0x6f, 0x61, 0x64, 0x73, 0x12, 0x27, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65,
-	0x72, 0x2e, 0x50, 0x72, 0x6f, 0x68, 0x69, 0x62, 0x69, 0x74, 0x4e, 0x65, 0x77, 0x44, 0x6f, 0x77,
-	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x16,
-	0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x41, 0x64, 0x64, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00,
-	0x12, 0x3d, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x19, 0x2e, 0x64, 0x6f, 0x77,
-	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12,
-	0x3d, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x19, 0x2e, 0x64, 0x6f, 0x77, 0x6e,
-	0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3b,
-	0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
-	0x61, 0x64, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x53,
-	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x1e, 0x5a, 0x1c, 0x2e,
-	0x2f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x3b, 0x64, 0x6f, 0x77, 0x6e,
-	0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x0c, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x74, 0x65, 0x22, 0x7b, 0x0a,
+	0x0d, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x64, 0x61,
+	0x79, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
+	0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
+	0x61, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x1a, 0x0a, 0x18, 0x42, 0x61,
+	0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4e, 0x0a, 0x1b, 0x53, 0x65, 0x74, 0x42, 0x61, 0x6e,
+	0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65,
+	0x72, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x52, 0x75, 0x6c, 0x65, 0x52,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x49, 0x0a, 0x16, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69,
+	0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x2f, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x6e,
+	0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65,
+	0x73, 0x22, 0x11, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0xe9, 0x02, 0x0a, 0x0c, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x72, 0x6f,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x34, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c,
+	0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x62, 0x79, 0x74, 0x65, 0x73, 0x54, 0x6f, 0x74, 0x61, 0x6c,
+	0x12, 0x27, 0x0a, 0x0f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65,
+	0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x77, 0x65, 0x62, 0x73, 0x65, 0x65, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x08, 0x77, 0x65, 0x62, 0x73, 0x65, 0x65, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70,
+	0x65, 0x65, 0x72, 0x73, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x70, 0x65, 0x65, 0x72, 0x73, 0x52, 0x61, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x77, 0x65,
+	0x62, 0x73, 0x65, 0x65, 0x64, 0x73, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x77, 0x65, 0x62, 0x73, 0x65, 0x65, 0x64, 0x73, 0x52, 0x61, 0x74, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x74,
+	0x61, 0x22, 0x3d, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x51, 0x55,
+	0x45, 0x55, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f,
+	0x41, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x56, 0x45, 0x52, 0x49, 0x46,
+	0x59, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x03,
+	0x22, 0xde, 0x01, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x46,
+	0x69, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x05, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x62, 0x79, 0x74, 0x65, 0x73, 0x54, 0x6f,
+	0x74, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x0d,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x74,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03,
+	0x65, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x32, 0xe9, 0x04, 0x0a, 0x0a, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72,
+	0x12, 0x59, 0x0a, 0x14, 0x50, 0x72, 0x6f, 0x68, 0x69, 0x62, 0x69, 0x74, 0x4e, 0x65, 0x77, 0x44,
+	0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x12, 0x27, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c,
+	0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x68, 0x69, 0x62, 0x69, 0x74, 0x4e, 0x65,
+	0x77, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x03, 0x41,
+	0x64, 0x64, 0x12, 0x16, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e,
+	0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x19,
+	0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x19, 0x2e,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x22, 0x00, 0x12, 0x3b, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x64, 0x6f,
+	0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x65, 0x72, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x5f, 0x0a, 0x11, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65,
+	0x64, 0x75, 0x6c, 0x65, 0x12, 0x24, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65,
+	0x72, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x64, 0x6f, 0x77,
+	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x65, 0x0a, 0x14, 0x53, 0x65, 0x74, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68,
+	0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x27, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c,
+	0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x22, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x42,
+	0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x1b, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72,
+	0x2e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x19, 0x2e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x1e, 0x5a,
+	0x1c, 0x2e, 0x2f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x3b, 0x64, 0x6f,
+	0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x72, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -509,36 +1083,55 @@ func file_downloader_downloader_proto_rawDescGZIP() []byte {
 	return file_downloader_downloader_proto_rawDescData
 }
 
-var file_downloader_downloader_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_downloader_downloader_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_downloader_downloader_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_downloader_downloader_proto_goTypes = []interface{}{
-	(*AddItem)(nil),                     // 0: downloader.AddItem
-	(*AddRequest)(nil),                  // 1: downloader.AddRequest
-	(*DeleteRequest)(nil),               // 2: downloader.DeleteRequest
-	(*VerifyRequest)(nil),               // 3: downloader.VerifyRequest
-	(*StatsRequest)(nil),                // 4: downloader.StatsRequest
-	(*ProhibitNewDownloadsRequest)(nil), // 5: downloader.ProhibitNewDownloadsRequest
-	(*StatsReply)(nil),                  // 6: downloader.StatsReply
-	(*typesproto.H160)(nil),             // 7: types.H160
-	(*emptypb.Empty)(nil),               // 8: google.protobuf.Empty
+	(FileProgress_State)(0),             // 0: downloader.FileProgress.State
+	(*AddItem)(nil),                     // 1: downloader.AddItem
+	(*AddRequest)(nil),                  // 2: downloader.AddRequest
+	(*DeleteRequest)(nil),               // 3: downloader.DeleteRequest
+	(*VerifyRequest)(nil),               // 4: downloader.VerifyRequest
+	(*StatsRequest)(nil),                // 5: downloader.StatsRequest
+	(*ProhibitNewDownloadsRequest)(nil), // 6: downloader.ProhibitNewDownloadsRequest
+	(*StatsReply)(nil),                  // 7: downloader.StatsReply
+	(*BandwidthRule)(nil),               // 8: downloader.BandwidthRule
+	(*BandwidthScheduleRequest)(nil),    // 9: downloader.BandwidthScheduleRequest
+	(*SetBandwidthScheduleRequest)(nil), // 10: downloader.SetBandwidthScheduleRequest
+	(*BandwidthScheduleReply)(nil),      // 11: downloader.BandwidthScheduleReply
+	(*ProgressRequest)(nil),             // 12: downloader.ProgressRequest
+	(*FileProgress)(nil),                // 13: downloader.FileProgress
+	(*ProgressReply)(nil),               // 14: downloader.ProgressReply
+	(*typesproto.H160)(nil),             // 15: types.H160
+	(*emptypb.Empty)(nil),               // 16: google.protobuf.Empty
 }
 var file_downloader_downloader_proto_depIdxs = []int32{
-	7, // 0: downloader.AddItem.torrent_hash:type_name -> types.H160
-	0, // 1: downloader.AddRequest.items:type_name -> downloader.AddItem
-	5, // 2: downloader.Downloader.ProhibitNewDownloads:input_type -> downloader.ProhibitNewDownloadsRequest
-	1, // 3: downloader.Downloader.Add:input_type -> downloader.AddRequest
-	2, // 4: downloader.Downloader.Delete:input_type -> downloader.DeleteRequest
-	3, // 5: downloader.Downloader.Verify:input_type -> downloader.VerifyRequest
-	4, // 6: downloader.Downloader.Stats:input_type -> downloader.StatsRequest
-	8, // 7: downloader.Downloader.ProhibitNewDownloads:output_type -> google.protobuf.Empty
-	8, // 8: downloader.Downloader.Add:output_type -> google.protobuf.Empty
-	8, // 9: downloader.Downloader.Delete:output_type -> google.protobuf.Empty
-	8, // 10: downloader.Downloader.Verify:output_type -> google.protobuf.Empty
-	6, // 11: downloader.Downloader.Stats:output_type -> downloader.StatsReply
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	15, // 0: downloader.AddItem.torrent_hash:type_name -> types.H160
+	1,  // 1: downloader.AddRequest.items:type_name -> downloader.AddItem
+	8,  // 2: downloader.SetBandwidthScheduleRequest.rules:type_name -> downloader.BandwidthRule
+	8,  // 3: downloader.BandwidthScheduleReply.rules:type_name -> downloader.BandwidthRule
+	0,  // 4: downloader.FileProgress.state:type_name -> downloader.FileProgress.State
+	13, // 5: downloader.ProgressReply.files:type_name -> downloader.FileProgress
+	6,  // 6: downloader.Downloader.ProhibitNewDownloads:input_type -> downloader.ProhibitNewDownloadsRequest
+	2,  // 7: downloader.Downloader.Add:input_type -> downloader.AddRequest
+	3,  // 8: downloader.Downloader.Delete:input_type -> downloader.DeleteRequest
+	4,  // 9: downloader.Downloader.Verify:input_type -> downloader.VerifyRequest
+	5,  // 10: downloader.Downloader.Stats:input_type -> downloader.StatsRequest
+	9,  // 11: downloader.Downloader.BandwidthSchedule:input_type -> downloader.BandwidthScheduleRequest
+	10, // 12: downloader.Downloader.SetBandwidthSchedule:input_type -> downloader.SetBandwidthScheduleRequest
+	12, // 13: downloader.Downloader.Progress:input_type -> downloader.ProgressRequest
+	16, // 14: downloader.Downloader.ProhibitNewDownloads:output_type -> google.protobuf.Empty
+	16, // 15: downloader.Downloader.Add:output_type -> google.protobuf.Empty
+	16, // 16: downloader.Downloader.Delete:output_type -> google.protobuf.Empty
+	16, // 17: downloader.Downloader.Verify:output_type -> google.protobuf.Empty
+	7,  // 18: downloader.Downloader.Stats:output_type -> downloader.StatsReply
+	11, // 19: downloader.Downloader.BandwidthSchedule:output_type -> downloader.BandwidthScheduleReply
+	11, // 20: downloader.Downloader.SetBandwidthSchedule:output_type -> downloader.BandwidthScheduleReply
+	14, // 21: downloader.Downloader.Progress:output_type -> downloader.ProgressReply
+	14, // [14:22] is the sub-list for method output_type
+	6,  // [6:14] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_downloader_downloader_proto_init() }
@@ -631,19 +1224,104 @@ func file_downloader_downloader_proto_init() {
 				return nil
 			}
 		}
+		file_downloader_downloader_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BandwidthRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BandwidthScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetBandwidthScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BandwidthScheduleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProgressRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_downloader_downloader_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProgressReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_downloader_downloader_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_downloader_downloader_proto_goTypes,
 		DependencyIndexes: file_downloader_downloader_proto_depIdxs,
+		EnumInfos:         file_downloader_downloader_proto_enumTypes,
 		MessageInfos:      file_downloader_downloader_proto_msgTypes,
 	}.Build()
 	File_downloader_downloader_proto = out.File
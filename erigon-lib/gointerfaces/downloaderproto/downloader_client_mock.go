@@ -85,6 +85,50 @@ func (c *MockDownloaderClientAddCall) DoAndReturn(f func(context.Context, *AddRe
 	return c
 }
 
+// BandwidthSchedule mocks base method.
+func (m *MockDownloaderClient) BandwidthSchedule(arg0 context.Context, arg1 *BandwidthScheduleRequest, arg2 ...grpc.CallOption) (*BandwidthScheduleReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BandwidthSchedule", varargs...)
+	ret0, _ := ret[0].(*BandwidthScheduleReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BandwidthSchedule indicates an expected call of BandwidthSchedule.
+func (mr *MockDownloaderClientMockRecorder) BandwidthSchedule(arg0, arg1 any, arg2 ...any) *MockDownloaderClientBandwidthScheduleCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthSchedule", reflect.TypeOf((*MockDownloaderClient)(nil).BandwidthSchedule), varargs...)
+	return &MockDownloaderClientBandwidthScheduleCall{Call: call}
+}
+
+// MockDownloaderClientBandwidthScheduleCall wrap *gomock.Call
+type MockDownloaderClientBandwidthScheduleCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockDownloaderClientBandwidthScheduleCall) Return(arg0 *BandwidthScheduleReply, arg1 error) *MockDownloaderClientBandwidthScheduleCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockDownloaderClientBandwidthScheduleCall) Do(f func(context.Context, *BandwidthScheduleRequest, ...grpc.CallOption) (*BandwidthScheduleReply, error)) *MockDownloaderClientBandwidthScheduleCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockDownloaderClientBandwidthScheduleCall) DoAndReturn(f func(context.Context, *BandwidthScheduleRequest, ...grpc.CallOption) (*BandwidthScheduleReply, error)) *MockDownloaderClientBandwidthScheduleCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Delete mocks base method.
 func (m *MockDownloaderClient) Delete(arg0 context.Context, arg1 *DeleteRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -129,6 +173,50 @@ func (c *MockDownloaderClientDeleteCall) DoAndReturn(f func(context.Context, *De
 	return c
 }
 
+// Progress mocks base method.
+func (m *MockDownloaderClient) Progress(arg0 context.Context, arg1 *ProgressRequest, arg2 ...grpc.CallOption) (*ProgressReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Progress", varargs...)
+	ret0, _ := ret[0].(*ProgressReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Progress indicates an expected call of Progress.
+func (mr *MockDownloaderClientMockRecorder) Progress(arg0, arg1 any, arg2 ...any) *MockDownloaderClientProgressCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Progress", reflect.TypeOf((*MockDownloaderClient)(nil).Progress), varargs...)
+	return &MockDownloaderClientProgressCall{Call: call}
+}
+
+// MockDownloaderClientProgressCall wrap *gomock.Call
+type MockDownloaderClientProgressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockDownloaderClientProgressCall) Return(arg0 *ProgressReply, arg1 error) *MockDownloaderClientProgressCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockDownloaderClientProgressCall) Do(f func(context.Context, *ProgressRequest, ...grpc.CallOption) (*ProgressReply, error)) *MockDownloaderClientProgressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockDownloaderClientProgressCall) DoAndReturn(f func(context.Context, *ProgressRequest, ...grpc.CallOption) (*ProgressReply, error)) *MockDownloaderClientProgressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ProhibitNewDownloads mocks base method.
 func (m *MockDownloaderClient) ProhibitNewDownloads(arg0 context.Context, arg1 *ProhibitNewDownloadsRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -173,6 +261,50 @@ func (c *MockDownloaderClientProhibitNewDownloadsCall) DoAndReturn(f func(contex
 	return c
 }
 
+// SetBandwidthSchedule mocks base method.
+func (m *MockDownloaderClient) SetBandwidthSchedule(arg0 context.Context, arg1 *SetBandwidthScheduleRequest, arg2 ...grpc.CallOption) (*BandwidthScheduleReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetBandwidthSchedule", varargs...)
+	ret0, _ := ret[0].(*BandwidthScheduleReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetBandwidthSchedule indicates an expected call of SetBandwidthSchedule.
+func (mr *MockDownloaderClientMockRecorder) SetBandwidthSchedule(arg0, arg1 any, arg2 ...any) *MockDownloaderClientSetBandwidthScheduleCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBandwidthSchedule", reflect.TypeOf((*MockDownloaderClient)(nil).SetBandwidthSchedule), varargs...)
+	return &MockDownloaderClientSetBandwidthScheduleCall{Call: call}
+}
+
+// MockDownloaderClientSetBandwidthScheduleCall wrap *gomock.Call
+type MockDownloaderClientSetBandwidthScheduleCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockDownloaderClientSetBandwidthScheduleCall) Return(arg0 *BandwidthScheduleReply, arg1 error) *MockDownloaderClientSetBandwidthScheduleCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockDownloaderClientSetBandwidthScheduleCall) Do(f func(context.Context, *SetBandwidthScheduleRequest, ...grpc.CallOption) (*BandwidthScheduleReply, error)) *MockDownloaderClientSetBandwidthScheduleCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockDownloaderClientSetBandwidthScheduleCall) DoAndReturn(f func(context.Context, *SetBandwidthScheduleRequest, ...grpc.CallOption) (*BandwidthScheduleReply, error)) *MockDownloaderClientSetBandwidthScheduleCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Stats mocks base method.
 func (m *MockDownloaderClient) Stats(arg0 context.Context, arg1 *StatsRequest, arg2 ...grpc.CallOption) (*StatsReply, error) {
 	m.ctrl.T.Helper()
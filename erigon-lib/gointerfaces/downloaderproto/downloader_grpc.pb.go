@@ -25,6 +25,9 @@ const (
 	Downloader_Delete_FullMethodName               = "/downloader.Downloader/Delete"
 	Downloader_Verify_FullMethodName               = "/downloader.Downloader/Verify"
 	Downloader_Stats_FullMethodName                = "/downloader.Downloader/Stats"
+	Downloader_BandwidthSchedule_FullMethodName    = "/downloader.Downloader/BandwidthSchedule"
+	Downloader_SetBandwidthSchedule_FullMethodName = "/downloader.Downloader/SetBandwidthSchedule"
+	Downloader_Progress_FullMethodName             = "/downloader.Downloader/Progress"
 )
 
 // DownloaderClient is the client API for Downloader service.
@@ -42,6 +45,12 @@ type DownloaderClient interface {
 	// If some part of file is bad - such part will be re-downloaded (without returning error)
 	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error)
+	// Runtime control of `--torrent.bandwidth.schedule`. Outside of rules: --torrent.download.rate, --torrent.upload.rate
+	BandwidthSchedule(ctx context.Context, in *BandwidthScheduleRequest, opts ...grpc.CallOption) (*BandwidthScheduleReply, error)
+	// Replaces whole schedule, empty list of rules - back to configured rates. Returns applied schedule
+	SetBandwidthSchedule(ctx context.Context, in *SetBandwidthScheduleRequest, opts ...grpc.CallOption) (*BandwidthScheduleReply, error)
+	// State of each file, as of last stats re-calculation. Stats has only totals
+	Progress(ctx context.Context, in *ProgressRequest, opts ...grpc.CallOption) (*ProgressReply, error)
 }
 
 type downloaderClient struct {
@@ -97,6 +106,33 @@ func (c *downloaderClient) Stats(ctx context.Context, in *StatsRequest, opts ...
 	return out, nil
 }
 
+func (c *downloaderClient) BandwidthSchedule(ctx context.Context, in *BandwidthScheduleRequest, opts ...grpc.CallOption) (*BandwidthScheduleReply, error) {
+	out := new(BandwidthScheduleReply)
+	err := c.cc.Invoke(ctx, Downloader_BandwidthSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *downloaderClient) SetBandwidthSchedule(ctx context.Context, in *SetBandwidthScheduleRequest, opts ...grpc.CallOption) (*BandwidthScheduleReply, error) {
+	out := new(BandwidthScheduleReply)
+	err := c.cc.Invoke(ctx, Downloader_SetBandwidthSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *downloaderClient) Progress(ctx context.Context, in *ProgressRequest, opts ...grpc.CallOption) (*ProgressReply, error) {
+	out := new(ProgressReply)
+	err := c.cc.Invoke(ctx, Downloader_Progress_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DownloaderServer is the server API for Downloader service.
 // All implementations must embed UnimplementedDownloaderServer
 // for forward compatibility
@@ -112,6 +148,12 @@ type DownloaderServer interface {
 	// If some part of file is bad - such part will be re-downloaded (without returning error)
 	Verify(context.Context, *VerifyRequest) (*emptypb.Empty, error)
 	Stats(context.Context, *StatsRequest) (*StatsReply, error)
+	// Runtime control of `--torrent.bandwidth.schedule`. Outside of rules: --torrent.download.rate, --torrent.upload.rate
+	BandwidthSchedule(context.Context, *BandwidthScheduleRequest) (*BandwidthScheduleReply, error)
+	// Replaces whole schedule, empty list of rules - back to configured rates. Returns applied schedule
+	SetBandwidthSchedule(context.Context, *SetBandwidthScheduleRequest) (*BandwidthScheduleReply, error)
+	// State of each file, as of last stats re-calculation. Stats has only totals
+	Progress(context.Context, *ProgressRequest) (*ProgressReply, error)
 	mustEmbedUnimplementedDownloaderServer()
 }
 
@@ -134,6 +176,15 @@ func (UnimplementedDownloaderServer) Verify(context.Context, *VerifyRequest) (*e
 func (UnimplementedDownloaderServer) Stats(context.Context, *StatsRequest) (*StatsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedDownloaderServer) BandwidthSchedule(context.Context, *BandwidthScheduleRequest) (*BandwidthScheduleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BandwidthSchedule not implemented")
+}
+func (UnimplementedDownloaderServer) SetBandwidthSchedule(context.Context, *SetBandwidthScheduleRequest) (*BandwidthScheduleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBandwidthSchedule not implemented")
+}
+func (UnimplementedDownloaderServer) Progress(context.Context, *ProgressRequest) (*ProgressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Progress not implemented")
+}
 func (UnimplementedDownloaderServer) mustEmbedUnimplementedDownloaderServer() {}
 
 // UnsafeDownloaderServer may be embedded to opt out of forward compatibility for this service.
@@ -237,6 +288,60 @@ func _Downloader_Stats_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Downloader_BandwidthSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BandwidthScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloaderServer).BandwidthSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Downloader_BandwidthSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloaderServer).BandwidthSchedule(ctx, req.(*BandwidthScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Downloader_SetBandwidthSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBandwidthScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloaderServer).SetBandwidthSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Downloader_SetBandwidthSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloaderServer).SetBandwidthSchedule(ctx, req.(*SetBandwidthScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Downloader_Progress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloaderServer).Progress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Downloader_Progress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloaderServer).Progress(ctx, req.(*ProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Downloader_ServiceDesc is the grpc.ServiceDesc for Downloader service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -264,6 +369,18 @@ var Downloader_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stats",
 			Handler:    _Downloader_Stats_Handler,
 		},
+		{
+			MethodName: "BandwidthSchedule",
+			Handler:    _Downloader_BandwidthSchedule_Handler,
+		},
+		{
+			MethodName: "SetBandwidthSchedule",
+			Handler:    _Downloader_SetBandwidthSchedule_Handler,
+		},
+		{
+			MethodName: "Progress",
+			Handler:    _Downloader_Progress_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "downloader/downloader.proto",
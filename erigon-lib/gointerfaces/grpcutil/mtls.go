@@ -0,0 +1,201 @@
+package grpcutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+const DefaultTLSReloadInterval = time.Minute
+
+// TLSConfig - mutual TLS between Erigon components (sentry, downloader, txpool, remote kv):
+// both sides present certificate signed by CACert and verify each other.
+//
+// Certificate rotation: files are re-read when changed on disk (checked not more often than ReloadInterval) -
+// new connections use new certificate/CA, existing connections are not affected.
+// Common Name is used as identity of component instead of host name - so split deployments don't need DNS-matching certs.
+type TLSConfig struct {
+	CACert   string
+	CertFile string
+	KeyFile  string
+
+	AllowedCNs     []string      // empty - any certificate signed by CACert is accepted
+	ReloadInterval time.Duration // 0 - DefaultTLSReloadInterval
+}
+
+func (cfg TLSConfig) Enabled() bool {
+	return cfg.CertFile != "" || cfg.KeyFile != "" || cfg.CACert != ""
+}
+func (cfg TLSConfig) Mutual() bool { return cfg.CACert != "" }
+
+// ParseAllowedCNs - comma-separated list of Common Names
+func ParseAllowedCNs(s string) (res []string) {
+	for _, cn := range strings.Split(s, ",") {
+		if cn = strings.TrimSpace(cn); cn != "" {
+			res = append(res, cn)
+		}
+	}
+	return res
+}
+
+// ServerTLS - credentials for gRPC server. nil if TLS not configured. Without CACert - one-way TLS (as before).
+func ServerTLS(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	if !cfg.Mutual() {
+		return credentials.NewServerTLSFromFile(cfg.CertFile, cfg.KeyFile)
+	}
+	store, err := newCertStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAnyClientCert, // chain is verified by verifyPeer: CA pool may be rotated
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := store.get()
+			return cert, nil
+		},
+		VerifyPeerCertificate: store.verifyPeer(x509.ExtKeyUsageClientAuth),
+	}), nil
+}
+
+// ClientTLS - credentials for gRPC client. nil if TLS not configured. Without CACert - legacy behavior of TLS func.
+func ClientTLS(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	if !cfg.Mutual() {
+		return TLS(cfg.CACert, cfg.CertFile, cfg.KeyFile)
+	}
+	store, err := newCertStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		//nolint:gosec
+		InsecureSkipVerify: true, // host name is not checked: chain and Common Name are verified by verifyPeer
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := store.get()
+			return cert, nil
+		},
+		VerifyPeerCertificate: store.verifyPeer(x509.ExtKeyUsageServerAuth),
+	}), nil
+}
+
+// certStore - holds current certificate and CA pool, re-reads them from disk on change
+type certStore struct {
+	cfg     TLSConfig
+	allowed map[string]struct{}
+
+	lock      sync.Mutex
+	cert      *tls.Certificate
+	caPool    *x509.CertPool
+	modTime   time.Time // latest mod time of cfg files
+	lastCheck time.Time
+}
+
+func newCertStore(cfg TLSConfig) (*certStore, error) {
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = DefaultTLSReloadInterval
+	}
+	s := &certStore{cfg: cfg}
+	if len(cfg.AllowedCNs) > 0 {
+		s.allowed = make(map[string]struct{}, len(cfg.AllowedCNs))
+		for _, cn := range cfg.AllowedCNs {
+			s.allowed[cn] = struct{}{}
+		}
+	}
+	modTime, err := s.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.load(modTime); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *certStore) filesModTime() (latest time.Time, err error) {
+	for _, f := range []string{s.cfg.CACert, s.cfg.CertFile, s.cfg.KeyFile} {
+		st, err := os.Stat(f)
+		if err != nil {
+			return latest, err
+		}
+		if st.ModTime().After(latest) {
+			latest = st.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (s *certStore) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load peer cert/key error:%w", err)
+	}
+	caCert, err := os.ReadFile(s.cfg.CACert)
+	if err != nil {
+		return fmt.Errorf("read ca cert file error:%w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caCert) {
+		return fmt.Errorf("no certificates found in ca cert file: %s", s.cfg.CACert)
+	}
+	s.cert, s.caPool, s.modTime = &cert, caPool, modTime
+	return nil
+}
+
+// get - returns current cert and CA pool. On reload error old ones are used: files may be in the middle of rotation.
+func (s *certStore) get() (*tls.Certificate, *x509.CertPool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if time.Since(s.lastCheck) < s.cfg.ReloadInterval {
+		return s.cert, s.caPool
+	}
+	s.lastCheck = time.Now()
+	if modTime, err := s.filesModTime(); err == nil && modTime.After(s.modTime) {
+		_ = s.load(modTime)
+	}
+	return s.cert, s.caPool
+}
+
+func (s *certStore) verifyPeer(usage x509.ExtKeyUsage) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("peer didn't provide certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("parse peer certificate: %w", err)
+			}
+			certs[i] = cert
+		}
+		_, caPool := s.get()
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: caPool, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			return fmt.Errorf("verify peer certificate: %w", err)
+		}
+		if s.allowed == nil {
+			return nil
+		}
+		if _, ok := s.allowed[certs[0].Subject.CommonName]; !ok {
+			return fmt.Errorf("peer certificate common name %q is not allowed", certs[0].Subject.CommonName)
+		}
+		return nil
+	}
+}
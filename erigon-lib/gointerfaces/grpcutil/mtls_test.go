@@ -0,0 +1,162 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	writePem(t, filepath.Join(dir, "ca.crt"), "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue - writes <cn>.crt and <cn>.key to dir
+func (ca *testCA) issue(t *testing.T, dir, cn string) TLSConfig {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	writePem(t, filepath.Join(dir, cn+".crt"), "CERTIFICATE", der)
+	writePem(t, filepath.Join(dir, cn+".key"), "EC PRIVATE KEY", keyDer)
+	return TLSConfig{CACert: filepath.Join(dir, "ca.crt"), CertFile: filepath.Join(dir, cn+".crt"), KeyFile: filepath.Join(dir, cn+".key")}
+}
+
+func writePem(t *testing.T, fileName, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(fileName, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+}
+
+func startHealthServer(t *testing.T, creds credentials.TransportCredentials) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(creds))
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func healthCheck(addr string, creds credentials.TransportCredentials) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCfg := ca.issue(t, dir, "erigon")
+	serverCfg.AllowedCNs = []string{"rpcdaemon"}
+	serverCreds, err := ServerTLS(serverCfg)
+	require.NoError(t, err)
+	addr := startHealthServer(t, serverCreds)
+
+	clientCfg := ca.issue(t, dir, "rpcdaemon")
+	clientCfg.AllowedCNs = []string{"erigon"}
+	clientCreds, err := ClientTLS(clientCfg)
+	require.NoError(t, err)
+	require.NoError(t, healthCheck(addr, clientCreds))
+
+	// CN not in server's allow-list
+	otherCreds, err := ClientTLS(ca.issue(t, dir, "txpool"))
+	require.NoError(t, err)
+	require.Error(t, healthCheck(addr, otherCreds))
+
+	// client doesn't trust server's CN
+	clientCfg.AllowedCNs = []string{"sentry"}
+	strictCreds, err := ClientTLS(clientCfg)
+	require.NoError(t, err)
+	require.Error(t, healthCheck(addr, strictCreds))
+
+	// certificate signed by other CA
+	otherDir := t.TempDir()
+	otherCA := newTestCA(t, otherDir)
+	strangerCfg := otherCA.issue(t, otherDir, "rpcdaemon")
+	strangerCfg.CACert = filepath.Join(dir, "ca.crt")
+	strangerCreds, err := ClientTLS(strangerCfg)
+	require.NoError(t, err)
+	require.Error(t, healthCheck(addr, strangerCreds))
+}
+
+func TestMutualTLSRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCfg := ca.issue(t, dir, "erigon")
+	serverCfg.ReloadInterval = time.Nanosecond
+	serverCreds, err := ServerTLS(serverCfg)
+	require.NoError(t, err)
+	addr := startHealthServer(t, serverCreds)
+
+	clientCreds, err := ClientTLS(ca.issue(t, dir, "rpcdaemon"))
+	require.NoError(t, err)
+	require.NoError(t, healthCheck(addr, clientCreds))
+
+	// rotate CA and all certificates: old client must be rejected, new one accepted
+	newCA := newTestCA(t, dir)
+	newCA.issue(t, dir, "erigon")
+	future := time.Now().Add(time.Minute)
+	for _, f := range []string{serverCfg.CACert, serverCfg.CertFile, serverCfg.KeyFile} {
+		require.NoError(t, os.Chtimes(f, future, future))
+	}
+	require.Error(t, healthCheck(addr, clientCreds))
+
+	newClientCreds, err := ClientTLS(newCA.issue(t, dir, "rpcdaemon"))
+	require.NoError(t, err)
+	require.NoError(t, healthCheck(addr, newClientCreds))
+}
@@ -21,21 +21,25 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ETHBACKEND_Etherbase_FullMethodName       = "/remote.ETHBACKEND/Etherbase"
-	ETHBACKEND_NetVersion_FullMethodName      = "/remote.ETHBACKEND/NetVersion"
-	ETHBACKEND_NetPeerCount_FullMethodName    = "/remote.ETHBACKEND/NetPeerCount"
-	ETHBACKEND_Version_FullMethodName         = "/remote.ETHBACKEND/Version"
-	ETHBACKEND_ProtocolVersion_FullMethodName = "/remote.ETHBACKEND/ProtocolVersion"
-	ETHBACKEND_ClientVersion_FullMethodName   = "/remote.ETHBACKEND/ClientVersion"
-	ETHBACKEND_Subscribe_FullMethodName       = "/remote.ETHBACKEND/Subscribe"
-	ETHBACKEND_SubscribeLogs_FullMethodName   = "/remote.ETHBACKEND/SubscribeLogs"
-	ETHBACKEND_Block_FullMethodName           = "/remote.ETHBACKEND/Block"
-	ETHBACKEND_TxnLookup_FullMethodName       = "/remote.ETHBACKEND/TxnLookup"
-	ETHBACKEND_NodeInfo_FullMethodName        = "/remote.ETHBACKEND/NodeInfo"
-	ETHBACKEND_Peers_FullMethodName           = "/remote.ETHBACKEND/Peers"
-	ETHBACKEND_AddPeer_FullMethodName         = "/remote.ETHBACKEND/AddPeer"
-	ETHBACKEND_PendingBlock_FullMethodName    = "/remote.ETHBACKEND/PendingBlock"
-	ETHBACKEND_BorEvent_FullMethodName        = "/remote.ETHBACKEND/BorEvent"
+	ETHBACKEND_Etherbase_FullMethodName         = "/remote.ETHBACKEND/Etherbase"
+	ETHBACKEND_NetVersion_FullMethodName        = "/remote.ETHBACKEND/NetVersion"
+	ETHBACKEND_NetPeerCount_FullMethodName      = "/remote.ETHBACKEND/NetPeerCount"
+	ETHBACKEND_Version_FullMethodName           = "/remote.ETHBACKEND/Version"
+	ETHBACKEND_ProtocolVersion_FullMethodName   = "/remote.ETHBACKEND/ProtocolVersion"
+	ETHBACKEND_ClientVersion_FullMethodName     = "/remote.ETHBACKEND/ClientVersion"
+	ETHBACKEND_Subscribe_FullMethodName         = "/remote.ETHBACKEND/Subscribe"
+	ETHBACKEND_SubscribeLogs_FullMethodName     = "/remote.ETHBACKEND/SubscribeLogs"
+	ETHBACKEND_Block_FullMethodName             = "/remote.ETHBACKEND/Block"
+	ETHBACKEND_TxnLookup_FullMethodName         = "/remote.ETHBACKEND/TxnLookup"
+	ETHBACKEND_NodeInfo_FullMethodName          = "/remote.ETHBACKEND/NodeInfo"
+	ETHBACKEND_Peers_FullMethodName             = "/remote.ETHBACKEND/Peers"
+	ETHBACKEND_AddPeer_FullMethodName           = "/remote.ETHBACKEND/AddPeer"
+	ETHBACKEND_RemovePeer_FullMethodName        = "/remote.ETHBACKEND/RemovePeer"
+	ETHBACKEND_AddTrustedPeer_FullMethodName    = "/remote.ETHBACKEND/AddTrustedPeer"
+	ETHBACKEND_RemoveTrustedPeer_FullMethodName = "/remote.ETHBACKEND/RemoveTrustedPeer"
+	ETHBACKEND_ReloadPeerFiles_FullMethodName   = "/remote.ETHBACKEND/ReloadPeerFiles"
+	ETHBACKEND_PendingBlock_FullMethodName      = "/remote.ETHBACKEND/PendingBlock"
+	ETHBACKEND_BorEvent_FullMethodName          = "/remote.ETHBACKEND/BorEvent"
 )
 
 // ETHBACKENDClient is the client API for ETHBACKEND service.
@@ -66,6 +70,11 @@ type ETHBACKENDClient interface {
 	// Peers collects and returns peers information from all running sentry instances.
 	Peers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PeersReply, error)
 	AddPeer(ctx context.Context, in *AddPeerRequest, opts ...grpc.CallOption) (*AddPeerReply, error)
+	RemovePeer(ctx context.Context, in *RemovePeerRequest, opts ...grpc.CallOption) (*RemovePeerReply, error)
+	AddTrustedPeer(ctx context.Context, in *AddTrustedPeerRequest, opts ...grpc.CallOption) (*AddTrustedPeerReply, error)
+	RemoveTrustedPeer(ctx context.Context, in *RemoveTrustedPeerRequest, opts ...grpc.CallOption) (*RemoveTrustedPeerReply, error)
+	// ReloadPeerFiles - re-reads static and trusted peers files, see --staticpeers.file
+	ReloadPeerFiles(ctx context.Context, in *ReloadPeerFilesRequest, opts ...grpc.CallOption) (*ReloadPeerFilesReply, error)
 	// PendingBlock returns latest built block.
 	PendingBlock(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PendingBlockReply, error)
 	BorEvent(ctx context.Context, in *BorEventRequest, opts ...grpc.CallOption) (*BorEventReply, error)
@@ -241,6 +250,42 @@ func (c *eTHBACKENDClient) AddPeer(ctx context.Context, in *AddPeerRequest, opts
 	return out, nil
 }
 
+func (c *eTHBACKENDClient) RemovePeer(ctx context.Context, in *RemovePeerRequest, opts ...grpc.CallOption) (*RemovePeerReply, error) {
+	out := new(RemovePeerReply)
+	err := c.cc.Invoke(ctx, ETHBACKEND_RemovePeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) AddTrustedPeer(ctx context.Context, in *AddTrustedPeerRequest, opts ...grpc.CallOption) (*AddTrustedPeerReply, error) {
+	out := new(AddTrustedPeerReply)
+	err := c.cc.Invoke(ctx, ETHBACKEND_AddTrustedPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) RemoveTrustedPeer(ctx context.Context, in *RemoveTrustedPeerRequest, opts ...grpc.CallOption) (*RemoveTrustedPeerReply, error) {
+	out := new(RemoveTrustedPeerReply)
+	err := c.cc.Invoke(ctx, ETHBACKEND_RemoveTrustedPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) ReloadPeerFiles(ctx context.Context, in *ReloadPeerFilesRequest, opts ...grpc.CallOption) (*ReloadPeerFilesReply, error) {
+	out := new(ReloadPeerFilesReply)
+	err := c.cc.Invoke(ctx, ETHBACKEND_ReloadPeerFiles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eTHBACKENDClient) PendingBlock(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PendingBlockReply, error) {
 	out := new(PendingBlockReply)
 	err := c.cc.Invoke(ctx, ETHBACKEND_PendingBlock_FullMethodName, in, out, opts...)
@@ -287,6 +332,11 @@ type ETHBACKENDServer interface {
 	// Peers collects and returns peers information from all running sentry instances.
 	Peers(context.Context, *emptypb.Empty) (*PeersReply, error)
 	AddPeer(context.Context, *AddPeerRequest) (*AddPeerReply, error)
+	RemovePeer(context.Context, *RemovePeerRequest) (*RemovePeerReply, error)
+	AddTrustedPeer(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error)
+	RemoveTrustedPeer(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error)
+	// ReloadPeerFiles - re-reads static and trusted peers files, see --staticpeers.file
+	ReloadPeerFiles(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error)
 	// PendingBlock returns latest built block.
 	PendingBlock(context.Context, *emptypb.Empty) (*PendingBlockReply, error)
 	BorEvent(context.Context, *BorEventRequest) (*BorEventReply, error)
@@ -336,6 +386,18 @@ func (UnimplementedETHBACKENDServer) Peers(context.Context, *emptypb.Empty) (*Pe
 func (UnimplementedETHBACKENDServer) AddPeer(context.Context, *AddPeerRequest) (*AddPeerReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddPeer not implemented")
 }
+func (UnimplementedETHBACKENDServer) RemovePeer(context.Context, *RemovePeerRequest) (*RemovePeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePeer not implemented")
+}
+func (UnimplementedETHBACKENDServer) AddTrustedPeer(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTrustedPeer not implemented")
+}
+func (UnimplementedETHBACKENDServer) RemoveTrustedPeer(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTrustedPeer not implemented")
+}
+func (UnimplementedETHBACKENDServer) ReloadPeerFiles(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadPeerFiles not implemented")
+}
 func (UnimplementedETHBACKENDServer) PendingBlock(context.Context, *emptypb.Empty) (*PendingBlockReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PendingBlock not implemented")
 }
@@ -600,6 +662,78 @@ func _ETHBACKEND_AddPeer_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ETHBACKEND_RemovePeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemovePeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).RemovePeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETHBACKEND_RemovePeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).RemovePeer(ctx, req.(*RemovePeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_AddTrustedPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTrustedPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).AddTrustedPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETHBACKEND_AddTrustedPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).AddTrustedPeer(ctx, req.(*AddTrustedPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_RemoveTrustedPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTrustedPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).RemoveTrustedPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETHBACKEND_RemoveTrustedPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).RemoveTrustedPeer(ctx, req.(*RemoveTrustedPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_ReloadPeerFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadPeerFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).ReloadPeerFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETHBACKEND_ReloadPeerFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).ReloadPeerFiles(ctx, req.(*ReloadPeerFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ETHBACKEND_PendingBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -687,6 +821,22 @@ var ETHBACKEND_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddPeer",
 			Handler:    _ETHBACKEND_AddPeer_Handler,
 		},
+		{
+			MethodName: "RemovePeer",
+			Handler:    _ETHBACKEND_RemovePeer_Handler,
+		},
+		{
+			MethodName: "AddTrustedPeer",
+			Handler:    _ETHBACKEND_AddTrustedPeer_Handler,
+		},
+		{
+			MethodName: "RemoveTrustedPeer",
+			Handler:    _ETHBACKEND_RemoveTrustedPeer_Handler,
+		},
+		{
+			MethodName: "ReloadPeerFiles",
+			Handler:    _ETHBACKEND_ReloadPeerFiles_Handler,
+		},
 		{
 			MethodName: "PendingBlock",
 			Handler:    _ETHBACKEND_PendingBlock_Handler,
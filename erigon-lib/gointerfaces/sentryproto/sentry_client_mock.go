@@ -86,6 +86,94 @@ func (c *MockSentryClientAddPeerCall) DoAndReturn(f func(context.Context, *AddPe
 	return c
 }
 
+// AddTrustedPeer mocks base method.
+func (m *MockSentryClient) AddTrustedPeer(arg0 context.Context, arg1 *AddTrustedPeerRequest, arg2 ...grpc.CallOption) (*AddTrustedPeerReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddTrustedPeer", varargs...)
+	ret0, _ := ret[0].(*AddTrustedPeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTrustedPeer indicates an expected call of AddTrustedPeer.
+func (mr *MockSentryClientMockRecorder) AddTrustedPeer(arg0, arg1 any, arg2 ...any) *MockSentryClientAddTrustedPeerCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTrustedPeer", reflect.TypeOf((*MockSentryClient)(nil).AddTrustedPeer), varargs...)
+	return &MockSentryClientAddTrustedPeerCall{Call: call}
+}
+
+// MockSentryClientAddTrustedPeerCall wrap *gomock.Call
+type MockSentryClientAddTrustedPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientAddTrustedPeerCall) Return(arg0 *AddTrustedPeerReply, arg1 error) *MockSentryClientAddTrustedPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientAddTrustedPeerCall) Do(f func(context.Context, *AddTrustedPeerRequest, ...grpc.CallOption) (*AddTrustedPeerReply, error)) *MockSentryClientAddTrustedPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientAddTrustedPeerCall) DoAndReturn(f func(context.Context, *AddTrustedPeerRequest, ...grpc.CallOption) (*AddTrustedPeerReply, error)) *MockSentryClientAddTrustedPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// BanPeer mocks base method.
+func (m *MockSentryClient) BanPeer(arg0 context.Context, arg1 *BanPeerRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BanPeer", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanPeer indicates an expected call of BanPeer.
+func (mr *MockSentryClientMockRecorder) BanPeer(arg0, arg1 any, arg2 ...any) *MockSentryClientBanPeerCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanPeer", reflect.TypeOf((*MockSentryClient)(nil).BanPeer), varargs...)
+	return &MockSentryClientBanPeerCall{Call: call}
+}
+
+// MockSentryClientBanPeerCall wrap *gomock.Call
+type MockSentryClientBanPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientBanPeerCall) Return(arg0 *emptypb.Empty, arg1 error) *MockSentryClientBanPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientBanPeerCall) Do(f func(context.Context, *BanPeerRequest, ...grpc.CallOption) (*emptypb.Empty, error)) *MockSentryClientBanPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientBanPeerCall) DoAndReturn(f func(context.Context, *BanPeerRequest, ...grpc.CallOption) (*emptypb.Empty, error)) *MockSentryClientBanPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HandShake mocks base method.
 func (m *MockSentryClient) HandShake(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*HandShakeReply, error) {
 	m.ctrl.T.Helper()
@@ -394,6 +482,94 @@ func (c *MockSentryClientPeerMinBlockCall) DoAndReturn(f func(context.Context, *
 	return c
 }
 
+// PeerScores mocks base method.
+func (m *MockSentryClient) PeerScores(arg0 context.Context, arg1 *PeerScoresRequest, arg2 ...grpc.CallOption) (*PeerScoresReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PeerScores", varargs...)
+	ret0, _ := ret[0].(*PeerScoresReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeerScores indicates an expected call of PeerScores.
+func (mr *MockSentryClientMockRecorder) PeerScores(arg0, arg1 any, arg2 ...any) *MockSentryClientPeerScoresCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerScores", reflect.TypeOf((*MockSentryClient)(nil).PeerScores), varargs...)
+	return &MockSentryClientPeerScoresCall{Call: call}
+}
+
+// MockSentryClientPeerScoresCall wrap *gomock.Call
+type MockSentryClientPeerScoresCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientPeerScoresCall) Return(arg0 *PeerScoresReply, arg1 error) *MockSentryClientPeerScoresCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientPeerScoresCall) Do(f func(context.Context, *PeerScoresRequest, ...grpc.CallOption) (*PeerScoresReply, error)) *MockSentryClientPeerScoresCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientPeerScoresCall) DoAndReturn(f func(context.Context, *PeerScoresRequest, ...grpc.CallOption) (*PeerScoresReply, error)) *MockSentryClientPeerScoresCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerStats mocks base method.
+func (m *MockSentryClient) PeerStats(arg0 context.Context, arg1 *PeerStatsRequest, arg2 ...grpc.CallOption) (*PeerStatsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PeerStats", varargs...)
+	ret0, _ := ret[0].(*PeerStatsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeerStats indicates an expected call of PeerStats.
+func (mr *MockSentryClientMockRecorder) PeerStats(arg0, arg1 any, arg2 ...any) *MockSentryClientPeerStatsCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerStats", reflect.TypeOf((*MockSentryClient)(nil).PeerStats), varargs...)
+	return &MockSentryClientPeerStatsCall{Call: call}
+}
+
+// MockSentryClientPeerStatsCall wrap *gomock.Call
+type MockSentryClientPeerStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientPeerStatsCall) Return(arg0 *PeerStatsReply, arg1 error) *MockSentryClientPeerStatsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientPeerStatsCall) Do(f func(context.Context, *PeerStatsRequest, ...grpc.CallOption) (*PeerStatsReply, error)) *MockSentryClientPeerStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientPeerStatsCall) DoAndReturn(f func(context.Context, *PeerStatsRequest, ...grpc.CallOption) (*PeerStatsReply, error)) *MockSentryClientPeerStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Peers mocks base method.
 func (m *MockSentryClient) Peers(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*PeersReply, error) {
 	m.ctrl.T.Helper()
@@ -482,6 +658,182 @@ func (c *MockSentryClientPenalizePeerCall) DoAndReturn(f func(context.Context, *
 	return c
 }
 
+// PinPeer mocks base method.
+func (m *MockSentryClient) PinPeer(arg0 context.Context, arg1 *PinPeerRequest, arg2 ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PinPeer", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PinPeer indicates an expected call of PinPeer.
+func (mr *MockSentryClientMockRecorder) PinPeer(arg0, arg1 any, arg2 ...any) *MockSentryClientPinPeerCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinPeer", reflect.TypeOf((*MockSentryClient)(nil).PinPeer), varargs...)
+	return &MockSentryClientPinPeerCall{Call: call}
+}
+
+// MockSentryClientPinPeerCall wrap *gomock.Call
+type MockSentryClientPinPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientPinPeerCall) Return(arg0 *emptypb.Empty, arg1 error) *MockSentryClientPinPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientPinPeerCall) Do(f func(context.Context, *PinPeerRequest, ...grpc.CallOption) (*emptypb.Empty, error)) *MockSentryClientPinPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientPinPeerCall) DoAndReturn(f func(context.Context, *PinPeerRequest, ...grpc.CallOption) (*emptypb.Empty, error)) *MockSentryClientPinPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReloadPeerFiles mocks base method.
+func (m *MockSentryClient) ReloadPeerFiles(arg0 context.Context, arg1 *ReloadPeerFilesRequest, arg2 ...grpc.CallOption) (*ReloadPeerFilesReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadPeerFiles", varargs...)
+	ret0, _ := ret[0].(*ReloadPeerFilesReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadPeerFiles indicates an expected call of ReloadPeerFiles.
+func (mr *MockSentryClientMockRecorder) ReloadPeerFiles(arg0, arg1 any, arg2 ...any) *MockSentryClientReloadPeerFilesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadPeerFiles", reflect.TypeOf((*MockSentryClient)(nil).ReloadPeerFiles), varargs...)
+	return &MockSentryClientReloadPeerFilesCall{Call: call}
+}
+
+// MockSentryClientReloadPeerFilesCall wrap *gomock.Call
+type MockSentryClientReloadPeerFilesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientReloadPeerFilesCall) Return(arg0 *ReloadPeerFilesReply, arg1 error) *MockSentryClientReloadPeerFilesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientReloadPeerFilesCall) Do(f func(context.Context, *ReloadPeerFilesRequest, ...grpc.CallOption) (*ReloadPeerFilesReply, error)) *MockSentryClientReloadPeerFilesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientReloadPeerFilesCall) DoAndReturn(f func(context.Context, *ReloadPeerFilesRequest, ...grpc.CallOption) (*ReloadPeerFilesReply, error)) *MockSentryClientReloadPeerFilesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemovePeer mocks base method.
+func (m *MockSentryClient) RemovePeer(arg0 context.Context, arg1 *RemovePeerRequest, arg2 ...grpc.CallOption) (*RemovePeerReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemovePeer", varargs...)
+	ret0, _ := ret[0].(*RemovePeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePeer indicates an expected call of RemovePeer.
+func (mr *MockSentryClientMockRecorder) RemovePeer(arg0, arg1 any, arg2 ...any) *MockSentryClientRemovePeerCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePeer", reflect.TypeOf((*MockSentryClient)(nil).RemovePeer), varargs...)
+	return &MockSentryClientRemovePeerCall{Call: call}
+}
+
+// MockSentryClientRemovePeerCall wrap *gomock.Call
+type MockSentryClientRemovePeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientRemovePeerCall) Return(arg0 *RemovePeerReply, arg1 error) *MockSentryClientRemovePeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientRemovePeerCall) Do(f func(context.Context, *RemovePeerRequest, ...grpc.CallOption) (*RemovePeerReply, error)) *MockSentryClientRemovePeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientRemovePeerCall) DoAndReturn(f func(context.Context, *RemovePeerRequest, ...grpc.CallOption) (*RemovePeerReply, error)) *MockSentryClientRemovePeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemoveTrustedPeer mocks base method.
+func (m *MockSentryClient) RemoveTrustedPeer(arg0 context.Context, arg1 *RemoveTrustedPeerRequest, arg2 ...grpc.CallOption) (*RemoveTrustedPeerReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveTrustedPeer", varargs...)
+	ret0, _ := ret[0].(*RemoveTrustedPeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTrustedPeer indicates an expected call of RemoveTrustedPeer.
+func (mr *MockSentryClientMockRecorder) RemoveTrustedPeer(arg0, arg1 any, arg2 ...any) *MockSentryClientRemoveTrustedPeerCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTrustedPeer", reflect.TypeOf((*MockSentryClient)(nil).RemoveTrustedPeer), varargs...)
+	return &MockSentryClientRemoveTrustedPeerCall{Call: call}
+}
+
+// MockSentryClientRemoveTrustedPeerCall wrap *gomock.Call
+type MockSentryClientRemoveTrustedPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryClientRemoveTrustedPeerCall) Return(arg0 *RemoveTrustedPeerReply, arg1 error) *MockSentryClientRemoveTrustedPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryClientRemoveTrustedPeerCall) Do(f func(context.Context, *RemoveTrustedPeerRequest, ...grpc.CallOption) (*RemoveTrustedPeerReply, error)) *MockSentryClientRemoveTrustedPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryClientRemoveTrustedPeerCall) DoAndReturn(f func(context.Context, *RemoveTrustedPeerRequest, ...grpc.CallOption) (*RemoveTrustedPeerReply, error)) *MockSentryClientRemoveTrustedPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SendMessageById mocks base method.
 func (m *MockSentryClient) SendMessageById(arg0 context.Context, arg1 *SendMessageByIdRequest, arg2 ...grpc.CallOption) (*SentPeers, error) {
 	m.ctrl.T.Helper()
@@ -35,7 +35,15 @@ const (
 	Sentry_PeerById_FullMethodName                 = "/sentry.Sentry/PeerById"
 	Sentry_PeerEvents_FullMethodName               = "/sentry.Sentry/PeerEvents"
 	Sentry_AddPeer_FullMethodName                  = "/sentry.Sentry/AddPeer"
+	Sentry_RemovePeer_FullMethodName               = "/sentry.Sentry/RemovePeer"
+	Sentry_AddTrustedPeer_FullMethodName           = "/sentry.Sentry/AddTrustedPeer"
+	Sentry_RemoveTrustedPeer_FullMethodName        = "/sentry.Sentry/RemoveTrustedPeer"
+	Sentry_ReloadPeerFiles_FullMethodName          = "/sentry.Sentry/ReloadPeerFiles"
 	Sentry_NodeInfo_FullMethodName                 = "/sentry.Sentry/NodeInfo"
+	Sentry_PeerScores_FullMethodName               = "/sentry.Sentry/PeerScores"
+	Sentry_PinPeer_FullMethodName                  = "/sentry.Sentry/PinPeer"
+	Sentry_BanPeer_FullMethodName                  = "/sentry.Sentry/BanPeer"
+	Sentry_PeerStats_FullMethodName                = "/sentry.Sentry/PeerStats"
 )
 
 // SentryClient is the client API for Sentry service.
@@ -63,8 +71,20 @@ type SentryClient interface {
 	// Subscribe to notifications about connected or lost peers.
 	PeerEvents(ctx context.Context, in *PeerEventsRequest, opts ...grpc.CallOption) (Sentry_PeerEventsClient, error)
 	AddPeer(ctx context.Context, in *AddPeerRequest, opts ...grpc.CallOption) (*AddPeerReply, error)
+	RemovePeer(ctx context.Context, in *RemovePeerRequest, opts ...grpc.CallOption) (*RemovePeerReply, error)
+	AddTrustedPeer(ctx context.Context, in *AddTrustedPeerRequest, opts ...grpc.CallOption) (*AddTrustedPeerReply, error)
+	RemoveTrustedPeer(ctx context.Context, in *RemoveTrustedPeerRequest, opts ...grpc.CallOption) (*RemoveTrustedPeerReply, error)
+	// ReloadPeerFiles - re-reads static and trusted peers files, see --staticpeers.file
+	ReloadPeerFiles(ctx context.Context, in *ReloadPeerFilesRequest, opts ...grpc.CallOption) (*ReloadPeerFilesReply, error)
 	// NodeInfo returns a collection of metadata known about the host.
 	NodeInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*typesproto.NodeInfoReply, error)
+	// Scores of all known peers, and manual pinning/banning of peers. Banned peer is also disconnected.
+	PeerScores(ctx context.Context, in *PeerScoresRequest, opts ...grpc.CallOption) (*PeerScoresReply, error)
+	PinPeer(ctx context.Context, in *PinPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	BanPeer(ctx context.Context, in *BanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Traffic and responsiveness of connected peers. Peers which only consume bandwidth (high total_out,
+	// low success_rate) can be dropped with PenalizePeer or banned with BanPeer.
+	PeerStats(ctx context.Context, in *PeerStatsRequest, opts ...grpc.CallOption) (*PeerStatsReply, error)
 }
 
 type sentryClient struct {
@@ -247,6 +267,42 @@ func (c *sentryClient) AddPeer(ctx context.Context, in *AddPeerRequest, opts ...
 	return out, nil
 }
 
+func (c *sentryClient) RemovePeer(ctx context.Context, in *RemovePeerRequest, opts ...grpc.CallOption) (*RemovePeerReply, error) {
+	out := new(RemovePeerReply)
+	err := c.cc.Invoke(ctx, Sentry_RemovePeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) AddTrustedPeer(ctx context.Context, in *AddTrustedPeerRequest, opts ...grpc.CallOption) (*AddTrustedPeerReply, error) {
+	out := new(AddTrustedPeerReply)
+	err := c.cc.Invoke(ctx, Sentry_AddTrustedPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) RemoveTrustedPeer(ctx context.Context, in *RemoveTrustedPeerRequest, opts ...grpc.CallOption) (*RemoveTrustedPeerReply, error) {
+	out := new(RemoveTrustedPeerReply)
+	err := c.cc.Invoke(ctx, Sentry_RemoveTrustedPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) ReloadPeerFiles(ctx context.Context, in *ReloadPeerFilesRequest, opts ...grpc.CallOption) (*ReloadPeerFilesReply, error) {
+	out := new(ReloadPeerFilesReply)
+	err := c.cc.Invoke(ctx, Sentry_ReloadPeerFiles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sentryClient) NodeInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*typesproto.NodeInfoReply, error) {
 	out := new(typesproto.NodeInfoReply)
 	err := c.cc.Invoke(ctx, Sentry_NodeInfo_FullMethodName, in, out, opts...)
@@ -256,6 +312,42 @@ func (c *sentryClient) NodeInfo(ctx context.Context, in *emptypb.Empty, opts ...
 	return out, nil
 }
 
+func (c *sentryClient) PeerScores(ctx context.Context, in *PeerScoresRequest, opts ...grpc.CallOption) (*PeerScoresReply, error) {
+	out := new(PeerScoresReply)
+	err := c.cc.Invoke(ctx, Sentry_PeerScores_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) PinPeer(ctx context.Context, in *PinPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sentry_PinPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) BanPeer(ctx context.Context, in *BanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sentry_BanPeer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryClient) PeerStats(ctx context.Context, in *PeerStatsRequest, opts ...grpc.CallOption) (*PeerStatsReply, error) {
+	out := new(PeerStatsReply)
+	err := c.cc.Invoke(ctx, Sentry_PeerStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SentryServer is the server API for Sentry service.
 // All implementations must embed UnimplementedSentryServer
 // for forward compatibility
@@ -281,8 +373,20 @@ type SentryServer interface {
 	// Subscribe to notifications about connected or lost peers.
 	PeerEvents(*PeerEventsRequest, Sentry_PeerEventsServer) error
 	AddPeer(context.Context, *AddPeerRequest) (*AddPeerReply, error)
+	RemovePeer(context.Context, *RemovePeerRequest) (*RemovePeerReply, error)
+	AddTrustedPeer(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error)
+	RemoveTrustedPeer(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error)
+	// ReloadPeerFiles - re-reads static and trusted peers files, see --staticpeers.file
+	ReloadPeerFiles(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error)
 	// NodeInfo returns a collection of metadata known about the host.
 	NodeInfo(context.Context, *emptypb.Empty) (*typesproto.NodeInfoReply, error)
+	// Scores of all known peers, and manual pinning/banning of peers. Banned peer is also disconnected.
+	PeerScores(context.Context, *PeerScoresRequest) (*PeerScoresReply, error)
+	PinPeer(context.Context, *PinPeerRequest) (*emptypb.Empty, error)
+	BanPeer(context.Context, *BanPeerRequest) (*emptypb.Empty, error)
+	// Traffic and responsiveness of connected peers. Peers which only consume bandwidth (high total_out,
+	// low success_rate) can be dropped with PenalizePeer or banned with BanPeer.
+	PeerStats(context.Context, *PeerStatsRequest) (*PeerStatsReply, error)
 	mustEmbedUnimplementedSentryServer()
 }
 
@@ -332,9 +436,33 @@ func (UnimplementedSentryServer) PeerEvents(*PeerEventsRequest, Sentry_PeerEvent
 func (UnimplementedSentryServer) AddPeer(context.Context, *AddPeerRequest) (*AddPeerReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddPeer not implemented")
 }
+func (UnimplementedSentryServer) RemovePeer(context.Context, *RemovePeerRequest) (*RemovePeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePeer not implemented")
+}
+func (UnimplementedSentryServer) AddTrustedPeer(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTrustedPeer not implemented")
+}
+func (UnimplementedSentryServer) RemoveTrustedPeer(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTrustedPeer not implemented")
+}
+func (UnimplementedSentryServer) ReloadPeerFiles(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadPeerFiles not implemented")
+}
 func (UnimplementedSentryServer) NodeInfo(context.Context, *emptypb.Empty) (*typesproto.NodeInfoReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NodeInfo not implemented")
 }
+func (UnimplementedSentryServer) PeerScores(context.Context, *PeerScoresRequest) (*PeerScoresReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PeerScores not implemented")
+}
+func (UnimplementedSentryServer) PinPeer(context.Context, *PinPeerRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinPeer not implemented")
+}
+func (UnimplementedSentryServer) BanPeer(context.Context, *BanPeerRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanPeer not implemented")
+}
+func (UnimplementedSentryServer) PeerStats(context.Context, *PeerStatsRequest) (*PeerStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PeerStats not implemented")
+}
 func (UnimplementedSentryServer) mustEmbedUnimplementedSentryServer() {}
 
 // UnsafeSentryServer may be embedded to opt out of forward compatibility for this service.
@@ -606,6 +734,78 @@ func _Sentry_AddPeer_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sentry_RemovePeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemovePeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).RemovePeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_RemovePeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).RemovePeer(ctx, req.(*RemovePeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_AddTrustedPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTrustedPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).AddTrustedPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_AddTrustedPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).AddTrustedPeer(ctx, req.(*AddTrustedPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_RemoveTrustedPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTrustedPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).RemoveTrustedPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_RemoveTrustedPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).RemoveTrustedPeer(ctx, req.(*RemoveTrustedPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_ReloadPeerFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadPeerFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).ReloadPeerFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_ReloadPeerFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).ReloadPeerFiles(ctx, req.(*ReloadPeerFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Sentry_NodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -624,6 +824,78 @@ func _Sentry_NodeInfo_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sentry_PeerScores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerScoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).PeerScores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_PeerScores_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).PeerScores(ctx, req.(*PeerScoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_PinPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).PinPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_PinPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).PinPeer(ctx, req.(*PinPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_BanPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).BanPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_BanPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).BanPeer(ctx, req.(*BanPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentry_PeerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryServer).PeerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentry_PeerStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryServer).PeerStats(ctx, req.(*PeerStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Sentry_ServiceDesc is the grpc.ServiceDesc for Sentry service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -679,10 +951,42 @@ var Sentry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddPeer",
 			Handler:    _Sentry_AddPeer_Handler,
 		},
+		{
+			MethodName: "RemovePeer",
+			Handler:    _Sentry_RemovePeer_Handler,
+		},
+		{
+			MethodName: "AddTrustedPeer",
+			Handler:    _Sentry_AddTrustedPeer_Handler,
+		},
+		{
+			MethodName: "RemoveTrustedPeer",
+			Handler:    _Sentry_RemoveTrustedPeer_Handler,
+		},
+		{
+			MethodName: "ReloadPeerFiles",
+			Handler:    _Sentry_ReloadPeerFiles_Handler,
+		},
 		{
 			MethodName: "NodeInfo",
 			Handler:    _Sentry_NodeInfo_Handler,
 		},
+		{
+			MethodName: "PeerScores",
+			Handler:    _Sentry_PeerScores_Handler,
+		},
+		{
+			MethodName: "PinPeer",
+			Handler:    _Sentry_PinPeer_Handler,
+		},
+		{
+			MethodName: "BanPeer",
+			Handler:    _Sentry_BanPeer_Handler,
+		},
+		{
+			MethodName: "PeerStats",
+			Handler:    _Sentry_PeerStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
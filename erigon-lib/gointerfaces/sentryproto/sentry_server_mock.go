@@ -80,6 +80,84 @@ func (c *MockSentryServerAddPeerCall) DoAndReturn(f func(context.Context, *AddPe
 	return c
 }
 
+// AddTrustedPeer mocks base method.
+func (m *MockSentryServer) AddTrustedPeer(arg0 context.Context, arg1 *AddTrustedPeerRequest) (*AddTrustedPeerReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTrustedPeer", arg0, arg1)
+	ret0, _ := ret[0].(*AddTrustedPeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTrustedPeer indicates an expected call of AddTrustedPeer.
+func (mr *MockSentryServerMockRecorder) AddTrustedPeer(arg0, arg1 any) *MockSentryServerAddTrustedPeerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTrustedPeer", reflect.TypeOf((*MockSentryServer)(nil).AddTrustedPeer), arg0, arg1)
+	return &MockSentryServerAddTrustedPeerCall{Call: call}
+}
+
+// MockSentryServerAddTrustedPeerCall wrap *gomock.Call
+type MockSentryServerAddTrustedPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerAddTrustedPeerCall) Return(arg0 *AddTrustedPeerReply, arg1 error) *MockSentryServerAddTrustedPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerAddTrustedPeerCall) Do(f func(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error)) *MockSentryServerAddTrustedPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerAddTrustedPeerCall) DoAndReturn(f func(context.Context, *AddTrustedPeerRequest) (*AddTrustedPeerReply, error)) *MockSentryServerAddTrustedPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// BanPeer mocks base method.
+func (m *MockSentryServer) BanPeer(arg0 context.Context, arg1 *BanPeerRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanPeer", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanPeer indicates an expected call of BanPeer.
+func (mr *MockSentryServerMockRecorder) BanPeer(arg0, arg1 any) *MockSentryServerBanPeerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanPeer", reflect.TypeOf((*MockSentryServer)(nil).BanPeer), arg0, arg1)
+	return &MockSentryServerBanPeerCall{Call: call}
+}
+
+// MockSentryServerBanPeerCall wrap *gomock.Call
+type MockSentryServerBanPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerBanPeerCall) Return(arg0 *emptypb.Empty, arg1 error) *MockSentryServerBanPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerBanPeerCall) Do(f func(context.Context, *BanPeerRequest) (*emptypb.Empty, error)) *MockSentryServerBanPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerBanPeerCall) DoAndReturn(f func(context.Context, *BanPeerRequest) (*emptypb.Empty, error)) *MockSentryServerBanPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HandShake mocks base method.
 func (m *MockSentryServer) HandShake(arg0 context.Context, arg1 *emptypb.Empty) (*HandShakeReply, error) {
 	m.ctrl.T.Helper()
@@ -351,6 +429,84 @@ func (c *MockSentryServerPeerMinBlockCall) DoAndReturn(f func(context.Context, *
 	return c
 }
 
+// PeerScores mocks base method.
+func (m *MockSentryServer) PeerScores(arg0 context.Context, arg1 *PeerScoresRequest) (*PeerScoresReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerScores", arg0, arg1)
+	ret0, _ := ret[0].(*PeerScoresReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeerScores indicates an expected call of PeerScores.
+func (mr *MockSentryServerMockRecorder) PeerScores(arg0, arg1 any) *MockSentryServerPeerScoresCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerScores", reflect.TypeOf((*MockSentryServer)(nil).PeerScores), arg0, arg1)
+	return &MockSentryServerPeerScoresCall{Call: call}
+}
+
+// MockSentryServerPeerScoresCall wrap *gomock.Call
+type MockSentryServerPeerScoresCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerPeerScoresCall) Return(arg0 *PeerScoresReply, arg1 error) *MockSentryServerPeerScoresCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerPeerScoresCall) Do(f func(context.Context, *PeerScoresRequest) (*PeerScoresReply, error)) *MockSentryServerPeerScoresCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerPeerScoresCall) DoAndReturn(f func(context.Context, *PeerScoresRequest) (*PeerScoresReply, error)) *MockSentryServerPeerScoresCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerStats mocks base method.
+func (m *MockSentryServer) PeerStats(arg0 context.Context, arg1 *PeerStatsRequest) (*PeerStatsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerStats", arg0, arg1)
+	ret0, _ := ret[0].(*PeerStatsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeerStats indicates an expected call of PeerStats.
+func (mr *MockSentryServerMockRecorder) PeerStats(arg0, arg1 any) *MockSentryServerPeerStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerStats", reflect.TypeOf((*MockSentryServer)(nil).PeerStats), arg0, arg1)
+	return &MockSentryServerPeerStatsCall{Call: call}
+}
+
+// MockSentryServerPeerStatsCall wrap *gomock.Call
+type MockSentryServerPeerStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerPeerStatsCall) Return(arg0 *PeerStatsReply, arg1 error) *MockSentryServerPeerStatsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerPeerStatsCall) Do(f func(context.Context, *PeerStatsRequest) (*PeerStatsReply, error)) *MockSentryServerPeerStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerPeerStatsCall) DoAndReturn(f func(context.Context, *PeerStatsRequest) (*PeerStatsReply, error)) *MockSentryServerPeerStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Peers mocks base method.
 func (m *MockSentryServer) Peers(arg0 context.Context, arg1 *emptypb.Empty) (*PeersReply, error) {
 	m.ctrl.T.Helper()
@@ -429,6 +585,162 @@ func (c *MockSentryServerPenalizePeerCall) DoAndReturn(f func(context.Context, *
 	return c
 }
 
+// PinPeer mocks base method.
+func (m *MockSentryServer) PinPeer(arg0 context.Context, arg1 *PinPeerRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinPeer", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PinPeer indicates an expected call of PinPeer.
+func (mr *MockSentryServerMockRecorder) PinPeer(arg0, arg1 any) *MockSentryServerPinPeerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinPeer", reflect.TypeOf((*MockSentryServer)(nil).PinPeer), arg0, arg1)
+	return &MockSentryServerPinPeerCall{Call: call}
+}
+
+// MockSentryServerPinPeerCall wrap *gomock.Call
+type MockSentryServerPinPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerPinPeerCall) Return(arg0 *emptypb.Empty, arg1 error) *MockSentryServerPinPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerPinPeerCall) Do(f func(context.Context, *PinPeerRequest) (*emptypb.Empty, error)) *MockSentryServerPinPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerPinPeerCall) DoAndReturn(f func(context.Context, *PinPeerRequest) (*emptypb.Empty, error)) *MockSentryServerPinPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReloadPeerFiles mocks base method.
+func (m *MockSentryServer) ReloadPeerFiles(arg0 context.Context, arg1 *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadPeerFiles", arg0, arg1)
+	ret0, _ := ret[0].(*ReloadPeerFilesReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadPeerFiles indicates an expected call of ReloadPeerFiles.
+func (mr *MockSentryServerMockRecorder) ReloadPeerFiles(arg0, arg1 any) *MockSentryServerReloadPeerFilesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadPeerFiles", reflect.TypeOf((*MockSentryServer)(nil).ReloadPeerFiles), arg0, arg1)
+	return &MockSentryServerReloadPeerFilesCall{Call: call}
+}
+
+// MockSentryServerReloadPeerFilesCall wrap *gomock.Call
+type MockSentryServerReloadPeerFilesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerReloadPeerFilesCall) Return(arg0 *ReloadPeerFilesReply, arg1 error) *MockSentryServerReloadPeerFilesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerReloadPeerFilesCall) Do(f func(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error)) *MockSentryServerReloadPeerFilesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerReloadPeerFilesCall) DoAndReturn(f func(context.Context, *ReloadPeerFilesRequest) (*ReloadPeerFilesReply, error)) *MockSentryServerReloadPeerFilesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemovePeer mocks base method.
+func (m *MockSentryServer) RemovePeer(arg0 context.Context, arg1 *RemovePeerRequest) (*RemovePeerReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePeer", arg0, arg1)
+	ret0, _ := ret[0].(*RemovePeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePeer indicates an expected call of RemovePeer.
+func (mr *MockSentryServerMockRecorder) RemovePeer(arg0, arg1 any) *MockSentryServerRemovePeerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePeer", reflect.TypeOf((*MockSentryServer)(nil).RemovePeer), arg0, arg1)
+	return &MockSentryServerRemovePeerCall{Call: call}
+}
+
+// MockSentryServerRemovePeerCall wrap *gomock.Call
+type MockSentryServerRemovePeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerRemovePeerCall) Return(arg0 *RemovePeerReply, arg1 error) *MockSentryServerRemovePeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerRemovePeerCall) Do(f func(context.Context, *RemovePeerRequest) (*RemovePeerReply, error)) *MockSentryServerRemovePeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerRemovePeerCall) DoAndReturn(f func(context.Context, *RemovePeerRequest) (*RemovePeerReply, error)) *MockSentryServerRemovePeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RemoveTrustedPeer mocks base method.
+func (m *MockSentryServer) RemoveTrustedPeer(arg0 context.Context, arg1 *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTrustedPeer", arg0, arg1)
+	ret0, _ := ret[0].(*RemoveTrustedPeerReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTrustedPeer indicates an expected call of RemoveTrustedPeer.
+func (mr *MockSentryServerMockRecorder) RemoveTrustedPeer(arg0, arg1 any) *MockSentryServerRemoveTrustedPeerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTrustedPeer", reflect.TypeOf((*MockSentryServer)(nil).RemoveTrustedPeer), arg0, arg1)
+	return &MockSentryServerRemoveTrustedPeerCall{Call: call}
+}
+
+// MockSentryServerRemoveTrustedPeerCall wrap *gomock.Call
+type MockSentryServerRemoveTrustedPeerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentryServerRemoveTrustedPeerCall) Return(arg0 *RemoveTrustedPeerReply, arg1 error) *MockSentryServerRemoveTrustedPeerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentryServerRemoveTrustedPeerCall) Do(f func(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error)) *MockSentryServerRemoveTrustedPeerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentryServerRemoveTrustedPeerCall) DoAndReturn(f func(context.Context, *RemoveTrustedPeerRequest) (*RemoveTrustedPeerReply, error)) *MockSentryServerRemoveTrustedPeerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SendMessageById mocks base method.
 func (m *MockSentryServer) SendMessageById(arg0 context.Context, arg1 *SendMessageByIdRequest) (*SentPeers, error) {
 	m.ctrl.T.Helper()
@@ -129,6 +129,110 @@ func (AllReply_TxnType) EnumDescriptor() ([]byte, []int) {
 	return file_txpool_txpool_proto_rawDescGZIP(), []int{8, 0}
 }
 
+type TxOutcome_Status int32
+
+const (
+	TxOutcome_ACCEPTED TxOutcome_Status = 0 // added to pool
+	TxOutcome_REJECTED TxOutcome_Status = 1 // not added to pool, see reason
+	TxOutcome_REPLACED TxOutcome_Status = 2 // removed from pool by transaction with same sender and nonce, see replaced_by
+	TxOutcome_DROPPED  TxOutcome_Status = 3 // removed from pool without inclusion: evicted, expired, invalidated by new block
+	TxOutcome_MINED    TxOutcome_Status = 4 // removed from pool because it's included in block
+)
+
+// Enum value maps for TxOutcome_Status.
+var (
+	TxOutcome_Status_name = map[int32]string{
+		0: "ACCEPTED",
+		1: "REJECTED",
+		2: "REPLACED",
+		3: "DROPPED",
+		4: "MINED",
+	}
+	TxOutcome_Status_value = map[string]int32{
+		"ACCEPTED": 0,
+		"REJECTED": 1,
+		"REPLACED": 2,
+		"DROPPED":  3,
+		"MINED":    4,
+	}
+)
+
+func (x TxOutcome_Status) Enum() *TxOutcome_Status {
+	p := new(TxOutcome_Status)
+	*p = x
+	return p
+}
+
+func (x TxOutcome_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TxOutcome_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_txpool_txpool_proto_enumTypes[2].Descriptor()
+}
+
+func (TxOutcome_Status) Type() protoreflect.EnumType {
+	return &file_txpool_txpool_proto_enumTypes[2]
+}
+
+func (x TxOutcome_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TxOutcome_Status.Descriptor instead.
+func (TxOutcome_Status) EnumDescriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{19, 0}
+}
+
+type ReputationEntry_Status int32
+
+const (
+	ReputationEntry_OK        ReputationEntry_Status = 0
+	ReputationEntry_THROTTLED ReputationEntry_Status = 1
+	ReputationEntry_BANNED    ReputationEntry_Status = 2
+)
+
+// Enum value maps for ReputationEntry_Status.
+var (
+	ReputationEntry_Status_name = map[int32]string{
+		0: "OK",
+		1: "THROTTLED",
+		2: "BANNED",
+	}
+	ReputationEntry_Status_value = map[string]int32{
+		"OK":        0,
+		"THROTTLED": 1,
+		"BANNED":    2,
+	}
+)
+
+func (x ReputationEntry_Status) Enum() *ReputationEntry_Status {
+	p := new(ReputationEntry_Status)
+	*p = x
+	return p
+}
+
+func (x ReputationEntry_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReputationEntry_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_txpool_txpool_proto_enumTypes[3].Descriptor()
+}
+
+func (ReputationEntry_Status) Type() protoreflect.EnumType {
+	return &file_txpool_txpool_proto_enumTypes[3]
+}
+
+func (x ReputationEntry_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReputationEntry_Status.Descriptor instead.
+func (ReputationEntry_Status) EnumDescriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{33, 0}
+}
+
 type TxHashes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -792,18 +896,17 @@ func (x *NonceReply) GetNonce() uint64 {
 	return 0
 }
 
-type AllReply_Tx struct {
+type AddPrivateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	TxnType AllReply_TxnType `protobuf:"varint,1,opt,name=txn_type,json=txnType,proto3,enum=txpool.AllReply_TxnType" json:"txn_type,omitempty"`
-	Sender  *typesproto.H160 `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
-	RlpTx   []byte           `protobuf:"bytes,3,opt,name=rlp_tx,json=rlpTx,proto3" json:"rlp_tx,omitempty"`
+	RlpTxs         [][]byte `protobuf:"bytes,1,rep,name=rlp_txs,json=rlpTxs,proto3" json:"rlp_txs,omitempty"`
+	MaxBlockNumber uint64   `protobuf:"varint,2,opt,name=max_block_number,json=maxBlockNumber,proto3" json:"max_block_number,omitempty"` // transactions are dropped if not mined until this block, 0 - `--txpool.private.blocks` after last seen block
 }
 
-func (x *AllReply_Tx) Reset() {
-	*x = AllReply_Tx{}
+func (x *AddPrivateRequest) Reset() {
+	*x = AddPrivateRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_txpool_txpool_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -811,13 +914,13 @@ func (x *AllReply_Tx) Reset() {
 	}
 }
 
-func (x *AllReply_Tx) String() string {
+func (x *AddPrivateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AllReply_Tx) ProtoMessage() {}
+func (*AddPrivateRequest) ProtoMessage() {}
 
-func (x *AllReply_Tx) ProtoReflect() protoreflect.Message {
+func (x *AddPrivateRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_txpool_txpool_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -829,59 +932,106 @@ func (x *AllReply_Tx) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AllReply_Tx.ProtoReflect.Descriptor instead.
-func (*AllReply_Tx) Descriptor() ([]byte, []int) {
-	return file_txpool_txpool_proto_rawDescGZIP(), []int{8, 0}
+// Deprecated: Use AddPrivateRequest.ProtoReflect.Descriptor instead.
+func (*AddPrivateRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *AllReply_Tx) GetTxnType() AllReply_TxnType {
+func (x *AddPrivateRequest) GetRlpTxs() [][]byte {
 	if x != nil {
-		return x.TxnType
+		return x.RlpTxs
 	}
-	return AllReply_PENDING
+	return nil
 }
 
-func (x *AllReply_Tx) GetSender() *typesproto.H160 {
+func (x *AddPrivateRequest) GetMaxBlockNumber() uint64 {
 	if x != nil {
-		return x.Sender
+		return x.MaxBlockNumber
+	}
+	return 0
+}
+
+type StorageSlot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   *typesproto.H256 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value *typesproto.H256 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *StorageSlot) Reset() {
+	*x = StorageSlot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageSlot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageSlot) ProtoMessage() {}
+
+func (x *StorageSlot) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageSlot.ProtoReflect.Descriptor instead.
+func (*StorageSlot) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StorageSlot) GetKey() *typesproto.H256 {
+	if x != nil {
+		return x.Key
 	}
 	return nil
 }
 
-func (x *AllReply_Tx) GetRlpTx() []byte {
+func (x *StorageSlot) GetValue() *typesproto.H256 {
 	if x != nil {
-		return x.RlpTx
+		return x.Value
 	}
 	return nil
 }
 
-type PendingReply_Tx struct {
+type KnownAccount struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sender  *typesproto.H160 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
-	RlpTx   []byte           `protobuf:"bytes,2,opt,name=rlp_tx,json=rlpTx,proto3" json:"rlp_tx,omitempty"`
-	IsLocal bool             `protobuf:"varint,3,opt,name=is_local,json=isLocal,proto3" json:"is_local,omitempty"`
+	Address *typesproto.H160 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Slots   []*StorageSlot   `protobuf:"bytes,2,rep,name=slots,proto3" json:"slots,omitempty"` // expected values of storage slots
 }
 
-func (x *PendingReply_Tx) Reset() {
-	*x = PendingReply_Tx{}
+func (x *KnownAccount) Reset() {
+	*x = KnownAccount{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_txpool_txpool_proto_msgTypes[15]
+		mi := &file_txpool_txpool_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PendingReply_Tx) String() string {
+func (x *KnownAccount) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PendingReply_Tx) ProtoMessage() {}
+func (*KnownAccount) ProtoMessage() {}
 
-func (x *PendingReply_Tx) ProtoReflect() protoreflect.Message {
-	mi := &file_txpool_txpool_proto_msgTypes[15]
+func (x *KnownAccount) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -892,232 +1042,2204 @@ func (x *PendingReply_Tx) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PendingReply_Tx.ProtoReflect.Descriptor instead.
-func (*PendingReply_Tx) Descriptor() ([]byte, []int) {
-	return file_txpool_txpool_proto_rawDescGZIP(), []int{9, 0}
+// Deprecated: Use KnownAccount.ProtoReflect.Descriptor instead.
+func (*KnownAccount) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *PendingReply_Tx) GetSender() *typesproto.H160 {
+func (x *KnownAccount) GetAddress() *typesproto.H160 {
 	if x != nil {
-		return x.Sender
+		return x.Address
 	}
 	return nil
 }
 
-func (x *PendingReply_Tx) GetRlpTx() []byte {
+func (x *KnownAccount) GetSlots() []*StorageSlot {
 	if x != nil {
-		return x.RlpTx
+		return x.Slots
 	}
 	return nil
 }
 
-func (x *PendingReply_Tx) GetIsLocal() bool {
-	if x != nil {
-		return x.IsLocal
+// transactions are kept in pool only while conditions are met
+type AddConditionalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RlpTxs         [][]byte        `protobuf:"bytes,1,rep,name=rlp_txs,json=rlpTxs,proto3" json:"rlp_txs,omitempty"`
+	KnownAccounts  []*KnownAccount `protobuf:"bytes,2,rep,name=known_accounts,json=knownAccounts,proto3" json:"known_accounts,omitempty"`
+	BlockNumberMin *uint64         `protobuf:"varint,3,opt,name=block_number_min,json=blockNumberMin,proto3,oneof" json:"block_number_min,omitempty"`
+	BlockNumberMax *uint64         `protobuf:"varint,4,opt,name=block_number_max,json=blockNumberMax,proto3,oneof" json:"block_number_max,omitempty"`
+	TimestampMin   *uint64         `protobuf:"varint,5,opt,name=timestamp_min,json=timestampMin,proto3,oneof" json:"timestamp_min,omitempty"`
+	TimestampMax   *uint64         `protobuf:"varint,6,opt,name=timestamp_max,json=timestampMax,proto3,oneof" json:"timestamp_max,omitempty"`
+}
+
+func (x *AddConditionalRequest) Reset() {
+	*x = AddConditionalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return false
 }
 
-var File_txpool_txpool_proto protoreflect.FileDescriptor
+func (x *AddConditionalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-var file_txpool_txpool_proto_rawDesc = []byte{
-	0x0a, 0x13, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x1a, 0x1b, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65,
-	0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2f, 0x0a,
-	0x08, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x06, 0x68, 0x61, 0x73,
-	0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x25,
-	0x0a, 0x0a, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07,
-	0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72,
-	0x6c, 0x70, 0x54, 0x78, 0x73, 0x22, 0x54, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x12, 0x30, 0x0a, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x49, 0x6d, 0x70,
-	0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72,
-	0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x3a, 0x0a, 0x13, 0x54,
-	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x23, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52,
-	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x2c, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x17, 0x0a, 0x07,
-	0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72,
-	0x6c, 0x70, 0x54, 0x78, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x25, 0x0a, 0x0a, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x70, 0x6c, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x70, 0x6c, 0x54, 0x78, 0x73, 0x22, 0x0c, 0x0a, 0x0a,
-	0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xda, 0x01, 0x0a, 0x08, 0x41,
-	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x25, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6c,
-	0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x52, 0x03, 0x74, 0x78, 0x73, 0x1a, 0x75,
-	0x0a, 0x02, 0x54, 0x78, 0x12, 0x33, 0x0a, 0x08, 0x74, 0x78, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
-	0x41, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65,
-	0x52, 0x07, 0x74, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e,
-	0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x15,
-	0x0a, 0x06, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
-	0x72, 0x6c, 0x70, 0x54, 0x78, 0x22, 0x30, 0x0a, 0x07, 0x54, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0a, 0x0a,
-	0x06, 0x51, 0x55, 0x45, 0x55, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x42, 0x41, 0x53,
-	0x45, 0x5f, 0x46, 0x45, 0x45, 0x10, 0x02, 0x22, 0x96, 0x01, 0x0a, 0x0c, 0x50, 0x65, 0x6e, 0x64,
-	0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x29, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x50,
-	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x52, 0x03,
-	0x74, 0x78, 0x73, 0x1a, 0x5b, 0x0a, 0x02, 0x54, 0x78, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e,
-	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x15,
-	0x0a, 0x06, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
-	0x72, 0x6c, 0x70, 0x54, 0x78, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x6c, 0x6f, 0x63, 0x61,
-	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
-	0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x22, 0x7b, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
-	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
-	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x5f,
-	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x71, 0x75, 0x65,
-	0x75, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x62, 0x61, 0x73, 0x65,
-	0x5f, 0x66, 0x65, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x46, 0x65, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x35,
-	0x0a, 0x0c, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25,
-	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x07, 0x61, 0x64,
-	0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e,
-	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x2a,
-	0x6c, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
-	0x0b, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e,
-	0x41, 0x4c, 0x52, 0x45, 0x41, 0x44, 0x59, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x01,
-	0x12, 0x0f, 0x0a, 0x0b, 0x46, 0x45, 0x45, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x4c, 0x4f, 0x57, 0x10,
-	0x02, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54, 0x41, 0x4c, 0x45, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07,
-	0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x49, 0x4e, 0x54,
-	0x45, 0x52, 0x4e, 0x41, 0x4c, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x05, 0x32, 0xec, 0x03,
-	0x0a, 0x06, 0x54, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x12, 0x36, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x74, 0x79,
-	0x70, 0x65, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x31, 0x0a, 0x0b, 0x46, 0x69, 0x6e, 0x64, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x12,
-	0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x65,
-	0x73, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73,
-	0x68, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x12, 0x2e, 0x74, 0x78, 0x70,
-	0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10,
-	0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x46, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x12, 0x1b, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
-	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x03, 0x41, 0x6c, 0x6c, 0x12,
-	0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6c, 0x6c,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x37, 0x0a, 0x07, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
-	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f,
-	0x6c, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x33,
-	0x0a, 0x05, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x12, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c,
-	0x2e, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e,
-	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x30, 0x01, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x15, 0x2e,
-	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x4e, 0x6f, 0x6e,
-	0x63, 0x65, 0x12, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4e, 0x6f, 0x6e, 0x63,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f,
-	0x6c, 0x2e, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x42, 0x16, 0x5a, 0x14,
-	0x2e, 0x2f, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x3b, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (*AddConditionalRequest) ProtoMessage() {}
+
+func (x *AddConditionalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var (
-	file_txpool_txpool_proto_rawDescOnce sync.Once
-	file_txpool_txpool_proto_rawDescData = file_txpool_txpool_proto_rawDesc
-)
+// Deprecated: Use AddConditionalRequest.ProtoReflect.Descriptor instead.
+func (*AddConditionalRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{17}
+}
 
-func file_txpool_txpool_proto_rawDescGZIP() []byte {
-	file_txpool_txpool_proto_rawDescOnce.Do(func() {
-		file_txpool_txpool_proto_rawDescData = protoimpl.X.CompressGZIP(file_txpool_txpool_proto_rawDescData)
-	})
-	return file_txpool_txpool_proto_rawDescData
+func (x *AddConditionalRequest) GetRlpTxs() [][]byte {
+	if x != nil {
+		return x.RlpTxs
+	}
+	return nil
 }
 
-var file_txpool_txpool_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_txpool_txpool_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
-var file_txpool_txpool_proto_goTypes = []interface{}{
-	(ImportResult)(0),               // 0: txpool.ImportResult
-	(AllReply_TxnType)(0),           // 1: txpool.AllReply.TxnType
-	(*TxHashes)(nil),                // 2: txpool.TxHashes
-	(*AddRequest)(nil),              // 3: txpool.AddRequest
-	(*AddReply)(nil),                // 4: txpool.AddReply
-	(*TransactionsRequest)(nil),     // 5: txpool.TransactionsRequest
-	(*TransactionsReply)(nil),       // 6: txpool.TransactionsReply
-	(*OnAddRequest)(nil),            // 7: txpool.OnAddRequest
-	(*OnAddReply)(nil),              // 8: txpool.OnAddReply
-	(*AllRequest)(nil),              // 9: txpool.AllRequest
-	(*AllReply)(nil),                // 10: txpool.AllReply
-	(*PendingReply)(nil),            // 11: txpool.PendingReply
-	(*StatusRequest)(nil),           // 12: txpool.StatusRequest
-	(*StatusReply)(nil),             // 13: txpool.StatusReply
-	(*NonceRequest)(nil),            // 14: txpool.NonceRequest
-	(*NonceReply)(nil),              // 15: txpool.NonceReply
-	(*AllReply_Tx)(nil),             // 16: txpool.AllReply.Tx
-	(*PendingReply_Tx)(nil),         // 17: txpool.PendingReply.Tx
-	(*typesproto.H256)(nil),         // 18: types.H256
-	(*typesproto.H160)(nil),         // 19: types.H160
-	(*emptypb.Empty)(nil),           // 20: google.protobuf.Empty
-	(*typesproto.VersionReply)(nil), // 21: types.VersionReply
+func (x *AddConditionalRequest) GetKnownAccounts() []*KnownAccount {
+	if x != nil {
+		return x.KnownAccounts
+	}
+	return nil
 }
-var file_txpool_txpool_proto_depIdxs = []int32{
-	18, // 0: txpool.TxHashes.hashes:type_name -> types.H256
-	0,  // 1: txpool.AddReply.imported:type_name -> txpool.ImportResult
-	18, // 2: txpool.TransactionsRequest.hashes:type_name -> types.H256
-	16, // 3: txpool.AllReply.txs:type_name -> txpool.AllReply.Tx
-	17, // 4: txpool.PendingReply.txs:type_name -> txpool.PendingReply.Tx
-	19, // 5: txpool.NonceRequest.address:type_name -> types.H160
-	1,  // 6: txpool.AllReply.Tx.txn_type:type_name -> txpool.AllReply.TxnType
-	19, // 7: txpool.AllReply.Tx.sender:type_name -> types.H160
-	19, // 8: txpool.PendingReply.Tx.sender:type_name -> types.H160
-	20, // 9: txpool.Txpool.Version:input_type -> google.protobuf.Empty
-	2,  // 10: txpool.Txpool.FindUnknown:input_type -> txpool.TxHashes
-	3,  // 11: txpool.Txpool.Add:input_type -> txpool.AddRequest
-	5,  // 12: txpool.Txpool.Transactions:input_type -> txpool.TransactionsRequest
-	9,  // 13: txpool.Txpool.All:input_type -> txpool.AllRequest
-	20, // 14: txpool.Txpool.Pending:input_type -> google.protobuf.Empty
-	7,  // 15: txpool.Txpool.OnAdd:input_type -> txpool.OnAddRequest
-	12, // 16: txpool.Txpool.Status:input_type -> txpool.StatusRequest
-	14, // 17: txpool.Txpool.Nonce:input_type -> txpool.NonceRequest
-	21, // 18: txpool.Txpool.Version:output_type -> types.VersionReply
-	2,  // 19: txpool.Txpool.FindUnknown:output_type -> txpool.TxHashes
-	4,  // 20: txpool.Txpool.Add:output_type -> txpool.AddReply
-	6,  // 21: txpool.Txpool.Transactions:output_type -> txpool.TransactionsReply
-	10, // 22: txpool.Txpool.All:output_type -> txpool.AllReply
-	11, // 23: txpool.Txpool.Pending:output_type -> txpool.PendingReply
-	8,  // 24: txpool.Txpool.OnAdd:output_type -> txpool.OnAddReply
-	13, // 25: txpool.Txpool.Status:output_type -> txpool.StatusReply
-	15, // 26: txpool.Txpool.Nonce:output_type -> txpool.NonceReply
-	18, // [18:27] is the sub-list for method output_type
-	9,  // [9:18] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+
+func (x *AddConditionalRequest) GetBlockNumberMin() uint64 {
+	if x != nil && x.BlockNumberMin != nil {
+		return *x.BlockNumberMin
+	}
+	return 0
 }
 
-func init() { file_txpool_txpool_proto_init() }
-func file_txpool_txpool_proto_init() {
-	if File_txpool_txpool_proto != nil {
-		return
+func (x *AddConditionalRequest) GetBlockNumberMax() uint64 {
+	if x != nil && x.BlockNumberMax != nil {
+		return *x.BlockNumberMax
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_txpool_txpool_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TxHashes); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
+	return 0
+}
+
+func (x *AddConditionalRequest) GetTimestampMin() uint64 {
+	if x != nil && x.TimestampMin != nil {
+		return *x.TimestampMin
+	}
+	return 0
+}
+
+func (x *AddConditionalRequest) GetTimestampMax() uint64 {
+	if x != nil && x.TimestampMax != nil {
+		return *x.TimestampMax
+	}
+	return 0
+}
+
+type OutcomesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hashes []*typesproto.H256 `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"` // empty - outcomes of all transactions
+}
+
+func (x *OutcomesRequest) Reset() {
+	*x = OutcomesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutcomesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutcomesRequest) ProtoMessage() {}
+
+func (x *OutcomesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutcomesRequest.ProtoReflect.Descriptor instead.
+func (*OutcomesRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *OutcomesRequest) GetHashes() []*typesproto.H256 {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// what happened to transaction in pool
+type TxOutcome struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash       *typesproto.H256 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Sender     *typesproto.H160 `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Nonce      uint64           `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Status     TxOutcome_Status `protobuf:"varint,4,opt,name=status,proto3,enum=txpool.TxOutcome_Status" json:"status,omitempty"`
+	ReasonCode uint32           `protobuf:"varint,5,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"` // code of discard reason of rejected and dropped transactions
+	Reason     string           `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReplacedBy *typesproto.H256 `protobuf:"bytes,7,opt,name=replaced_by,json=replacedBy,proto3" json:"replaced_by,omitempty"`
+}
+
+func (x *TxOutcome) Reset() {
+	*x = TxOutcome{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TxOutcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxOutcome) ProtoMessage() {}
+
+func (x *TxOutcome) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxOutcome.ProtoReflect.Descriptor instead.
+func (*TxOutcome) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TxOutcome) GetHash() *typesproto.H256 {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *TxOutcome) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *TxOutcome) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *TxOutcome) GetStatus() TxOutcome_Status {
+	if x != nil {
+		return x.Status
+	}
+	return TxOutcome_ACCEPTED
+}
+
+func (x *TxOutcome) GetReasonCode() uint32 {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return 0
+}
+
+func (x *TxOutcome) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TxOutcome) GetReplacedBy() *typesproto.H256 {
+	if x != nil {
+		return x.ReplacedBy
+	}
+	return nil
+}
+
+type AnalyticsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender *typesproto.H160 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"` // not set - analytics of whole pool
+}
+
+func (x *AnalyticsRequest) Reset() {
+	*x = AnalyticsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsRequest) ProtoMessage() {}
+
+func (x *AnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*AnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AnalyticsRequest) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+// diagnostics of pool content
+type AnalyticsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Block        uint64                           `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`                    // last seen block, age of transactions is counted from it
+	BaseFee      uint64                           `protobuf:"varint,2,opt,name=base_fee,json=baseFee,proto3" json:"base_fee,omitempty"` // pending base fee
+	Txs          uint64                           `protobuf:"varint,3,opt,name=txs,proto3" json:"txs,omitempty"`
+	BelowBaseFee uint64                           `protobuf:"varint,4,opt,name=below_base_fee,json=belowBaseFee,proto3" json:"below_base_fee,omitempty"` // transactions with fee cap below pending base fee
+	Age          []*AnalyticsReply_AgeBucket      `protobuf:"bytes,5,rep,name=age,proto3" json:"age,omitempty"`
+	NonceGaps    []*AnalyticsReply_SenderNonceGap `protobuf:"bytes,6,rep,name=nonce_gaps,json=nonceGaps,proto3" json:"nonce_gaps,omitempty"`
+}
+
+func (x *AnalyticsReply) Reset() {
+	*x = AnalyticsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyticsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsReply) ProtoMessage() {}
+
+func (x *AnalyticsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsReply.ProtoReflect.Descriptor instead.
+func (*AnalyticsReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AnalyticsReply) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *AnalyticsReply) GetBaseFee() uint64 {
+	if x != nil {
+		return x.BaseFee
+	}
+	return 0
+}
+
+func (x *AnalyticsReply) GetTxs() uint64 {
+	if x != nil {
+		return x.Txs
+	}
+	return 0
+}
+
+func (x *AnalyticsReply) GetBelowBaseFee() uint64 {
+	if x != nil {
+		return x.BelowBaseFee
+	}
+	return 0
+}
+
+func (x *AnalyticsReply) GetAge() []*AnalyticsReply_AgeBucket {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+func (x *AnalyticsReply) GetNonceGaps() []*AnalyticsReply_SenderNonceGap {
+	if x != nil {
+		return x.NonceGaps
+	}
+	return nil
+}
+
+// replacement and pricing rules of pool, which can be changed at runtime
+type PolicyReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PriceBump     uint64 `protobuf:"varint,1,opt,name=price_bump,json=priceBump,proto3" json:"price_bump,omitempty"`               // price bump percentage to replace an already existing transaction
+	BlobPriceBump uint64 `protobuf:"varint,2,opt,name=blob_price_bump,json=blobPriceBump,proto3" json:"blob_price_bump,omitempty"` // price bump percentage to replace an existing blob (type-3) transaction
+	MinFeeCap     uint64 `protobuf:"varint,3,opt,name=min_fee_cap,json=minFeeCap,proto3" json:"min_fee_cap,omitempty"`
+	MinTipCap     uint64 `protobuf:"varint,4,opt,name=min_tip_cap,json=minTipCap,proto3" json:"min_tip_cap,omitempty"`
+	AccountSlots  uint64 `protobuf:"varint,5,opt,name=account_slots,json=accountSlots,proto3" json:"account_slots,omitempty"`
+	BlobSlots     uint64 `protobuf:"varint,6,opt,name=blob_slots,json=blobSlots,proto3" json:"blob_slots,omitempty"`
+	MaxNonceGap   uint64 `protobuf:"varint,7,opt,name=max_nonce_gap,json=maxNonceGap,proto3" json:"max_nonce_gap,omitempty"`
+}
+
+func (x *PolicyReply) Reset() {
+	*x = PolicyReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyReply) ProtoMessage() {}
+
+func (x *PolicyReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyReply.ProtoReflect.Descriptor instead.
+func (*PolicyReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PolicyReply) GetPriceBump() uint64 {
+	if x != nil {
+		return x.PriceBump
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetBlobPriceBump() uint64 {
+	if x != nil {
+		return x.BlobPriceBump
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetMinFeeCap() uint64 {
+	if x != nil {
+		return x.MinFeeCap
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetMinTipCap() uint64 {
+	if x != nil {
+		return x.MinTipCap
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetAccountSlots() uint64 {
+	if x != nil {
+		return x.AccountSlots
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetBlobSlots() uint64 {
+	if x != nil {
+		return x.BlobSlots
+	}
+	return 0
+}
+
+func (x *PolicyReply) GetMaxNonceGap() uint64 {
+	if x != nil {
+		return x.MaxNonceGap
+	}
+	return 0
+}
+
+// omitted fields keep current values
+type SetPolicyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PriceBump     *uint64 `protobuf:"varint,1,opt,name=price_bump,json=priceBump,proto3,oneof" json:"price_bump,omitempty"`
+	BlobPriceBump *uint64 `protobuf:"varint,2,opt,name=blob_price_bump,json=blobPriceBump,proto3,oneof" json:"blob_price_bump,omitempty"`
+	MinFeeCap     *uint64 `protobuf:"varint,3,opt,name=min_fee_cap,json=minFeeCap,proto3,oneof" json:"min_fee_cap,omitempty"`
+	MinTipCap     *uint64 `protobuf:"varint,4,opt,name=min_tip_cap,json=minTipCap,proto3,oneof" json:"min_tip_cap,omitempty"`
+	AccountSlots  *uint64 `protobuf:"varint,5,opt,name=account_slots,json=accountSlots,proto3,oneof" json:"account_slots,omitempty"`
+	BlobSlots     *uint64 `protobuf:"varint,6,opt,name=blob_slots,json=blobSlots,proto3,oneof" json:"blob_slots,omitempty"`
+	MaxNonceGap   *uint64 `protobuf:"varint,7,opt,name=max_nonce_gap,json=maxNonceGap,proto3,oneof" json:"max_nonce_gap,omitempty"`
+}
+
+func (x *SetPolicyRequest) Reset() {
+	*x = SetPolicyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPolicyRequest) ProtoMessage() {}
+
+func (x *SetPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPolicyRequest.ProtoReflect.Descriptor instead.
+func (*SetPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetPolicyRequest) GetPriceBump() uint64 {
+	if x != nil && x.PriceBump != nil {
+		return *x.PriceBump
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetBlobPriceBump() uint64 {
+	if x != nil && x.BlobPriceBump != nil {
+		return *x.BlobPriceBump
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetMinFeeCap() uint64 {
+	if x != nil && x.MinFeeCap != nil {
+		return *x.MinFeeCap
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetMinTipCap() uint64 {
+	if x != nil && x.MinTipCap != nil {
+		return *x.MinTipCap
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetAccountSlots() uint64 {
+	if x != nil && x.AccountSlots != nil {
+		return *x.AccountSlots
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetBlobSlots() uint64 {
+	if x != nil && x.BlobSlots != nil {
+		return *x.BlobSlots
+	}
+	return 0
+}
+
+func (x *SetPolicyRequest) GetMaxNonceGap() uint64 {
+	if x != nil && x.MaxNonceGap != nil {
+		return *x.MaxNonceGap
+	}
+	return 0
+}
+
+// transaction to order, see Scorer service
+type ScoredTx struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash         *typesproto.H256 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Sender       *typesproto.H160 `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Nonce        uint64           `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Type         uint32           `protobuf:"varint,4,opt,name=type,proto3" json:"type,omitempty"`
+	Gas          uint64           `protobuf:"varint,5,opt,name=gas,proto3" json:"gas,omitempty"`
+	Size         uint32           `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Tip          *typesproto.H256 `protobuf:"bytes,7,opt,name=tip,proto3" json:"tip,omitempty"`
+	FeeCap       *typesproto.H256 `protobuf:"bytes,8,opt,name=fee_cap,json=feeCap,proto3" json:"fee_cap,omitempty"`
+	BlobFeeCap   *typesproto.H256 `protobuf:"bytes,9,opt,name=blob_fee_cap,json=blobFeeCap,proto3" json:"blob_fee_cap,omitempty"`
+	EffectiveTip *typesproto.H256 `protobuf:"bytes,10,opt,name=effective_tip,json=effectiveTip,proto3" json:"effective_tip,omitempty"` // tip which transaction pays on top of pending base fee
+	Local        bool             `protobuf:"varint,11,opt,name=local,proto3" json:"local,omitempty"`
+	Arrival      uint64           `protobuf:"varint,12,opt,name=arrival,proto3" json:"arrival,omitempty"` // sequence number of arrival to pool, grows monotonically (within process lifetime)
+}
+
+func (x *ScoredTx) Reset() {
+	*x = ScoredTx{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoredTx) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredTx) ProtoMessage() {}
+
+func (x *ScoredTx) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredTx.ProtoReflect.Descriptor instead.
+func (*ScoredTx) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ScoredTx) GetHash() *typesproto.H256 {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *ScoredTx) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *ScoredTx) GetGas() uint64 {
+	if x != nil {
+		return x.Gas
+	}
+	return 0
+}
+
+func (x *ScoredTx) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ScoredTx) GetTip() *typesproto.H256 {
+	if x != nil {
+		return x.Tip
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetFeeCap() *typesproto.H256 {
+	if x != nil {
+		return x.FeeCap
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetBlobFeeCap() *typesproto.H256 {
+	if x != nil {
+		return x.BlobFeeCap
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetEffectiveTip() *typesproto.H256 {
+	if x != nil {
+		return x.EffectiveTip
+	}
+	return nil
+}
+
+func (x *ScoredTx) GetLocal() bool {
+	if x != nil {
+		return x.Local
+	}
+	return false
+}
+
+func (x *ScoredTx) GetArrival() uint64 {
+	if x != nil {
+		return x.Arrival
+	}
+	return 0
+}
+
+type ScoreRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Txs []*ScoredTx `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+}
+
+func (x *ScoreRequest) Reset() {
+	*x = ScoreRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreRequest) ProtoMessage() {}
+
+func (x *ScoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreRequest.ProtoReflect.Descriptor instead.
+func (*ScoreRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ScoreRequest) GetTxs() []*ScoredTx {
+	if x != nil {
+		return x.Txs
+	}
+	return nil
+}
+
+type ScoreReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scores []uint64 `protobuf:"varint,1,rep,packed,name=scores,proto3" json:"scores,omitempty"` // same length and order as txs of request
+}
+
+func (x *ScoreReply) Reset() {
+	*x = ScoreReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreReply) ProtoMessage() {}
+
+func (x *ScoreReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreReply.ProtoReflect.Descriptor instead.
+func (*ScoreReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ScoreReply) GetScores() []uint64 {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
+// ERC-4337 user operation
+type UserOperation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender               *typesproto.H160 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Nonce                *typesproto.H256 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	InitCode             []byte           `protobuf:"bytes,3,opt,name=init_code,json=initCode,proto3" json:"init_code,omitempty"`
+	CallData             []byte           `protobuf:"bytes,4,opt,name=call_data,json=callData,proto3" json:"call_data,omitempty"`
+	CallGasLimit         *typesproto.H256 `protobuf:"bytes,5,opt,name=call_gas_limit,json=callGasLimit,proto3" json:"call_gas_limit,omitempty"`
+	VerificationGasLimit *typesproto.H256 `protobuf:"bytes,6,opt,name=verification_gas_limit,json=verificationGasLimit,proto3" json:"verification_gas_limit,omitempty"`
+	PreVerificationGas   *typesproto.H256 `protobuf:"bytes,7,opt,name=pre_verification_gas,json=preVerificationGas,proto3" json:"pre_verification_gas,omitempty"`
+	MaxFeePerGas         *typesproto.H256 `protobuf:"bytes,8,opt,name=max_fee_per_gas,json=maxFeePerGas,proto3" json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *typesproto.H256 `protobuf:"bytes,9,opt,name=max_priority_fee_per_gas,json=maxPriorityFeePerGas,proto3" json:"max_priority_fee_per_gas,omitempty"`
+	PaymasterAndData     []byte           `protobuf:"bytes,10,opt,name=paymaster_and_data,json=paymasterAndData,proto3" json:"paymaster_and_data,omitempty"`
+	Signature            []byte           `protobuf:"bytes,11,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *UserOperation) Reset() {
+	*x = UserOperation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserOperation) ProtoMessage() {}
+
+func (x *UserOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserOperation.ProtoReflect.Descriptor instead.
+func (*UserOperation) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UserOperation) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *UserOperation) GetNonce() *typesproto.H256 {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *UserOperation) GetInitCode() []byte {
+	if x != nil {
+		return x.InitCode
+	}
+	return nil
+}
+
+func (x *UserOperation) GetCallData() []byte {
+	if x != nil {
+		return x.CallData
+	}
+	return nil
+}
+
+func (x *UserOperation) GetCallGasLimit() *typesproto.H256 {
+	if x != nil {
+		return x.CallGasLimit
+	}
+	return nil
+}
+
+func (x *UserOperation) GetVerificationGasLimit() *typesproto.H256 {
+	if x != nil {
+		return x.VerificationGasLimit
+	}
+	return nil
+}
+
+func (x *UserOperation) GetPreVerificationGas() *typesproto.H256 {
+	if x != nil {
+		return x.PreVerificationGas
+	}
+	return nil
+}
+
+func (x *UserOperation) GetMaxFeePerGas() *typesproto.H256 {
+	if x != nil {
+		return x.MaxFeePerGas
+	}
+	return nil
+}
+
+func (x *UserOperation) GetMaxPriorityFeePerGas() *typesproto.H256 {
+	if x != nil {
+		return x.MaxPriorityFeePerGas
+	}
+	return nil
+}
+
+func (x *UserOperation) GetPaymasterAndData() []byte {
+	if x != nil {
+		return x.PaymasterAndData
+	}
+	return nil
+}
+
+func (x *UserOperation) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// user operation accepted to pool
+type PoolUserOp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash       *typesproto.H256 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	EntryPoint *typesproto.H160 `protobuf:"bytes,2,opt,name=entry_point,json=entryPoint,proto3" json:"entry_point,omitempty"`
+	UserOp     *UserOperation   `protobuf:"bytes,3,opt,name=user_op,json=userOp,proto3" json:"user_op,omitempty"`
+	ValidUntil uint64           `protobuf:"varint,4,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"` // 0 - no limit
+	Prefund    *typesproto.H256 `protobuf:"bytes,5,opt,name=prefund,proto3" json:"prefund,omitempty"`
+}
+
+func (x *PoolUserOp) Reset() {
+	*x = PoolUserOp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PoolUserOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolUserOp) ProtoMessage() {}
+
+func (x *PoolUserOp) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolUserOp.ProtoReflect.Descriptor instead.
+func (*PoolUserOp) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PoolUserOp) GetHash() *typesproto.H256 {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *PoolUserOp) GetEntryPoint() *typesproto.H160 {
+	if x != nil {
+		return x.EntryPoint
+	}
+	return nil
+}
+
+func (x *PoolUserOp) GetUserOp() *UserOperation {
+	if x != nil {
+		return x.UserOp
+	}
+	return nil
+}
+
+func (x *PoolUserOp) GetValidUntil() uint64 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+func (x *PoolUserOp) GetPrefund() *typesproto.H256 {
+	if x != nil {
+		return x.Prefund
+	}
+	return nil
+}
+
+type AddUserOpRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EntryPoint *typesproto.H160 `protobuf:"bytes,1,opt,name=entry_point,json=entryPoint,proto3" json:"entry_point,omitempty"`
+	UserOp     *UserOperation   `protobuf:"bytes,2,opt,name=user_op,json=userOp,proto3" json:"user_op,omitempty"`
+}
+
+func (x *AddUserOpRequest) Reset() {
+	*x = AddUserOpRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddUserOpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserOpRequest) ProtoMessage() {}
+
+func (x *AddUserOpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserOpRequest.ProtoReflect.Descriptor instead.
+func (*AddUserOpRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AddUserOpRequest) GetEntryPoint() *typesproto.H160 {
+	if x != nil {
+		return x.EntryPoint
+	}
+	return nil
+}
+
+func (x *AddUserOpRequest) GetUserOp() *UserOperation {
+	if x != nil {
+		return x.UserOp
+	}
+	return nil
+}
+
+type AddUserOpReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash      *typesproto.H256 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	ErrorCode int32            `protobuf:"varint,2,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // 0 - user operation is accepted, otherwise ERC-4337 rejection code (-325xx) or -32602 (invalid fields)
+	Error     string           `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *AddUserOpReply) Reset() {
+	*x = AddUserOpReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddUserOpReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserOpReply) ProtoMessage() {}
+
+func (x *AddUserOpReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserOpReply.ProtoReflect.Descriptor instead.
+func (*AddUserOpReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AddUserOpReply) GetHash() *typesproto.H256 {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *AddUserOpReply) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *AddUserOpReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PendingUserOpsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EntryPoint *typesproto.H160 `protobuf:"bytes,1,opt,name=entry_point,json=entryPoint,proto3" json:"entry_point,omitempty"`
+}
+
+func (x *PendingUserOpsRequest) Reset() {
+	*x = PendingUserOpsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingUserOpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingUserOpsRequest) ProtoMessage() {}
+
+func (x *PendingUserOpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingUserOpsRequest.ProtoReflect.Descriptor instead.
+func (*PendingUserOpsRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PendingUserOpsRequest) GetEntryPoint() *typesproto.H160 {
+	if x != nil {
+		return x.EntryPoint
+	}
+	return nil
+}
+
+type PendingUserOpsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ops []*PoolUserOp `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"` // best first
+}
+
+func (x *PendingUserOpsReply) Reset() {
+	*x = PendingUserOpsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingUserOpsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingUserOpsReply) ProtoMessage() {}
+
+func (x *PendingUserOpsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingUserOpsReply.ProtoReflect.Descriptor instead.
+func (*PendingUserOpsReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PendingUserOpsReply) GetOps() []*PoolUserOp {
+	if x != nil {
+		return x.Ops
+	}
+	return nil
+}
+
+type ReputationEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address     *typesproto.H160       `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	OpsSeen     uint64                 `protobuf:"varint,2,opt,name=ops_seen,json=opsSeen,proto3" json:"ops_seen,omitempty"`
+	OpsIncluded uint64                 `protobuf:"varint,3,opt,name=ops_included,json=opsIncluded,proto3" json:"ops_included,omitempty"`
+	Status      ReputationEntry_Status `protobuf:"varint,4,opt,name=status,proto3,enum=txpool.ReputationEntry_Status" json:"status,omitempty"`
+}
+
+func (x *ReputationEntry) Reset() {
+	*x = ReputationEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReputationEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReputationEntry) ProtoMessage() {}
+
+func (x *ReputationEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReputationEntry.ProtoReflect.Descriptor instead.
+func (*ReputationEntry) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ReputationEntry) GetAddress() *typesproto.H160 {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *ReputationEntry) GetOpsSeen() uint64 {
+	if x != nil {
+		return x.OpsSeen
+	}
+	return 0
+}
+
+func (x *ReputationEntry) GetOpsIncluded() uint64 {
+	if x != nil {
+		return x.OpsIncluded
+	}
+	return 0
+}
+
+func (x *ReputationEntry) GetStatus() ReputationEntry_Status {
+	if x != nil {
+		return x.Status
+	}
+	return ReputationEntry_OK
+}
+
+type ReputationReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*ReputationEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ReputationReply) Reset() {
+	*x = ReputationReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReputationReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReputationReply) ProtoMessage() {}
+
+func (x *ReputationReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReputationReply.ProtoReflect.Descriptor instead.
+func (*ReputationReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ReputationReply) GetEntries() []*ReputationEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type AllReply_Tx struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TxnType AllReply_TxnType `protobuf:"varint,1,opt,name=txn_type,json=txnType,proto3,enum=txpool.AllReply_TxnType" json:"txn_type,omitempty"`
+	Sender  *typesproto.H160 `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	RlpTx   []byte           `protobuf:"bytes,3,opt,name=rlp_tx,json=rlpTx,proto3" json:"rlp_tx,omitempty"`
+}
+
+func (x *AllReply_Tx) Reset() {
+	*x = AllReply_Tx{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllReply_Tx) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllReply_Tx) ProtoMessage() {}
+
+func (x *AllReply_Tx) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllReply_Tx.ProtoReflect.Descriptor instead.
+func (*AllReply_Tx) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *AllReply_Tx) GetTxnType() AllReply_TxnType {
+	if x != nil {
+		return x.TxnType
+	}
+	return AllReply_PENDING
+}
+
+func (x *AllReply_Tx) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *AllReply_Tx) GetRlpTx() []byte {
+	if x != nil {
+		return x.RlpTx
+	}
+	return nil
+}
+
+type PendingReply_Tx struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender  *typesproto.H160 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	RlpTx   []byte           `protobuf:"bytes,2,opt,name=rlp_tx,json=rlpTx,proto3" json:"rlp_tx,omitempty"`
+	IsLocal bool             `protobuf:"varint,3,opt,name=is_local,json=isLocal,proto3" json:"is_local,omitempty"`
+}
+
+func (x *PendingReply_Tx) Reset() {
+	*x = PendingReply_Tx{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingReply_Tx) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingReply_Tx) ProtoMessage() {}
+
+func (x *PendingReply_Tx) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingReply_Tx.ProtoReflect.Descriptor instead.
+func (*PendingReply_Tx) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *PendingReply_Tx) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *PendingReply_Tx) GetRlpTx() []byte {
+	if x != nil {
+		return x.RlpTx
+	}
+	return nil
+}
+
+func (x *PendingReply_Tx) GetIsLocal() bool {
+	if x != nil {
+		return x.IsLocal
+	}
+	return false
+}
+
+type AnalyticsReply_AgeBucket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blocks string `protobuf:"bytes,1,opt,name=blocks,proto3" json:"blocks,omitempty"` // range of age in blocks, like "2-10"
+	Txs    uint64 `protobuf:"varint,2,opt,name=txs,proto3" json:"txs,omitempty"`
+}
+
+func (x *AnalyticsReply_AgeBucket) Reset() {
+	*x = AnalyticsReply_AgeBucket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyticsReply_AgeBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsReply_AgeBucket) ProtoMessage() {}
+
+func (x *AnalyticsReply_AgeBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsReply_AgeBucket.ProtoReflect.Descriptor instead.
+func (*AnalyticsReply_AgeBucket) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{21, 0}
+}
+
+func (x *AnalyticsReply_AgeBucket) GetBlocks() string {
+	if x != nil {
+		return x.Blocks
+	}
+	return ""
+}
+
+func (x *AnalyticsReply_AgeBucket) GetTxs() uint64 {
+	if x != nil {
+		return x.Txs
+	}
+	return 0
+}
+
+// transactions of sender with nonce above first_gap can't be included until transaction with nonce first_gap arrives
+type AnalyticsReply_SenderNonceGap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sender     *typesproto.H160 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	StateNonce uint64           `protobuf:"varint,2,opt,name=state_nonce,json=stateNonce,proto3" json:"state_nonce,omitempty"`
+	FirstGap   uint64           `protobuf:"varint,3,opt,name=first_gap,json=firstGap,proto3" json:"first_gap,omitempty"` // lowest missing nonce
+	GappedTxs  uint64           `protobuf:"varint,4,opt,name=gapped_txs,json=gappedTxs,proto3" json:"gapped_txs,omitempty"`
+}
+
+func (x *AnalyticsReply_SenderNonceGap) Reset() {
+	*x = AnalyticsReply_SenderNonceGap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_txpool_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyticsReply_SenderNonceGap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsReply_SenderNonceGap) ProtoMessage() {}
+
+func (x *AnalyticsReply_SenderNonceGap) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsReply_SenderNonceGap.ProtoReflect.Descriptor instead.
+func (*AnalyticsReply_SenderNonceGap) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{21, 1}
+}
+
+func (x *AnalyticsReply_SenderNonceGap) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *AnalyticsReply_SenderNonceGap) GetStateNonce() uint64 {
+	if x != nil {
+		return x.StateNonce
+	}
+	return 0
+}
+
+func (x *AnalyticsReply_SenderNonceGap) GetFirstGap() uint64 {
+	if x != nil {
+		return x.FirstGap
+	}
+	return 0
+}
+
+func (x *AnalyticsReply_SenderNonceGap) GetGappedTxs() uint64 {
+	if x != nil {
+		return x.GappedTxs
+	}
+	return 0
+}
+
+var File_txpool_txpool_proto protoreflect.FileDescriptor
+
+var file_txpool_txpool_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x1a, 0x1b, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65,
+	0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2f, 0x0a,
+	0x08, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x06, 0x68, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x25,
+	0x0a, 0x0a, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72,
+	0x6c, 0x70, 0x54, 0x78, 0x73, 0x22, 0x54, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x30, 0x0a, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x49, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x08, 0x69, 0x6d, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x3a, 0x0a, 0x13, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x23, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52,
+	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x2c, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x17, 0x0a, 0x07,
+	0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72,
+	0x6c, 0x70, 0x54, 0x78, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x25, 0x0a, 0x0a, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x70, 0x6c, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x70, 0x6c, 0x54, 0x78, 0x73, 0x22, 0x0c, 0x0a, 0x0a,
+	0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xda, 0x01, 0x0a, 0x08, 0x41,
+	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x25, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6c,
+	0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x52, 0x03, 0x74, 0x78, 0x73, 0x1a, 0x75,
+	0x0a, 0x02, 0x54, 0x78, 0x12, 0x33, 0x0a, 0x08, 0x74, 0x78, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x41, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x07, 0x74, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x15,
+	0x0a, 0x06, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x72, 0x6c, 0x70, 0x54, 0x78, 0x22, 0x30, 0x0a, 0x07, 0x54, 0x78, 0x6e, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0a, 0x0a,
+	0x06, 0x51, 0x55, 0x45, 0x55, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x42, 0x41, 0x53,
+	0x45, 0x5f, 0x46, 0x45, 0x45, 0x10, 0x02, 0x22, 0x96, 0x01, 0x0a, 0x0c, 0x50, 0x65, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x29, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x50,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x54, 0x78, 0x52, 0x03,
+	0x74, 0x78, 0x73, 0x1a, 0x5b, 0x0a, 0x02, 0x54, 0x78, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x15,
+	0x0a, 0x06, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x72, 0x6c, 0x70, 0x54, 0x78, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
+	0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x7b, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x71, 0x75, 0x65,
+	0x75, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x62, 0x61, 0x73, 0x65,
+	0x5f, 0x66, 0x65, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x46, 0x65, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x35,
+	0x0a, 0x0c, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25,
+	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x22,
+	0x56, 0x0a, 0x11, 0x41, 0x64, 0x64, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x6c, 0x70, 0x54, 0x78, 0x73, 0x12, 0x28, 0x0a,
+	0x10, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x4f, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x53, 0x6c, 0x6f, 0x74, 0x12, 0x1d, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35,
+	0x36, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x60, 0x0a, 0x0c, 0x4b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x29, 0x0a, 0x05, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x53,
+	0x6c, 0x6f, 0x74, 0x52, 0x05, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x22, 0xed, 0x02, 0x0a, 0x15, 0x41,
+	0x64, 0x64, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6c, 0x70, 0x5f, 0x74, 0x78, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x6c, 0x70, 0x54, 0x78, 0x73, 0x12, 0x3b, 0x0a,
+	0x0e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4b,
+	0x6e, 0x6f, 0x77, 0x6e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x0d, 0x6b, 0x6e, 0x6f,
+	0x77, 0x6e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x10, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x0e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x4d, 0x69, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x10, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x48, 0x01, 0x52, 0x0e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x4d, 0x61, 0x78, 0x88, 0x01, 0x01, 0x12, 0x28, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x48,
+	0x02, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4d, 0x69, 0x6e, 0x88,
+	0x01, 0x01, 0x12, 0x28, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f,
+	0x6d, 0x61, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x48, 0x03, 0x52, 0x0c, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4d, 0x61, 0x78, 0x88, 0x01, 0x01, 0x42, 0x13, 0x0a, 0x11,
+	0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6d, 0x69,
+	0x6e, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x5f, 0x6d, 0x61, 0x78, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x69, 0x6e, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x61, 0x78, 0x22, 0x36, 0x0a, 0x0f, 0x4f, 0x75,
+	0x74, 0x63, 0x6f, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a,
+	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x22, 0xcc, 0x02, 0x0a, 0x09, 0x54, 0x78, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65,
+	0x12, 0x1f, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06,
+	0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x74,
+	0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74,
+	0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x64, 0x42, 0x79, 0x22, 0x4a, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x0c, 0x0a, 0x08, 0x41, 0x43, 0x43, 0x45, 0x50, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a,
+	0x08, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x52,
+	0x45, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x52, 0x4f,
+	0x50, 0x50, 0x45, 0x44, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x4d, 0x49, 0x4e, 0x45, 0x44, 0x10,
+	0x04, 0x22, 0x37, 0x0a, 0x10, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31,
+	0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x22, 0xbf, 0x03, 0x0a, 0x0e, 0x41,
+	0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x66, 0x65, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x62, 0x61, 0x73, 0x65, 0x46, 0x65, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x74, 0x78, 0x73,
+	0x12, 0x24, 0x0a, 0x0e, 0x62, 0x65, 0x6c, 0x6f, 0x77, 0x5f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x66,
+	0x65, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x62, 0x65, 0x6c, 0x6f, 0x77, 0x42,
+	0x61, 0x73, 0x65, 0x46, 0x65, 0x65, 0x12, 0x32, 0x0a, 0x03, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x41, 0x67, 0x65, 0x42,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x03, 0x61, 0x67, 0x65, 0x12, 0x44, 0x0a, 0x0a, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x5f, 0x67, 0x61, 0x70, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25,
+	0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4e, 0x6f, 0x6e,
+	0x63, 0x65, 0x47, 0x61, 0x70, 0x52, 0x09, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x47, 0x61, 0x70, 0x73,
+	0x1a, 0x35, 0x0a, 0x09, 0x41, 0x67, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x03, 0x74, 0x78, 0x73, 0x1a, 0x92, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x47, 0x61, 0x70, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x67, 0x61, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x66, 0x69, 0x72, 0x73, 0x74, 0x47, 0x61, 0x70, 0x12, 0x1d, 0x0a,
+	0x0a, 0x67, 0x61, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x74, 0x78, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x67, 0x61, 0x70, 0x70, 0x65, 0x64, 0x54, 0x78, 0x73, 0x22, 0xfc, 0x01, 0x0a,
+	0x0b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x62, 0x75, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x70, 0x72, 0x69, 0x63, 0x65, 0x42, 0x75, 0x6d, 0x70, 0x12, 0x26, 0x0a, 0x0f, 0x62,
+	0x6c, 0x6f, 0x62, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x62, 0x75, 0x6d, 0x70, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x62, 0x6c, 0x6f, 0x62, 0x50, 0x72, 0x69, 0x63, 0x65, 0x42,
+	0x75, 0x6d, 0x70, 0x12, 0x1e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x63,
+	0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x46, 0x65, 0x65,
+	0x43, 0x61, 0x70, 0x12, 0x1e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x69, 0x70, 0x5f, 0x63,
+	0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x54, 0x69, 0x70,
+	0x43, 0x61, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x73,
+	0x6c, 0x6f, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62,
+	0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x62, 0x6c,
+	0x6f, 0x62, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x5f, 0x67, 0x61, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b,
+	0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x47, 0x61, 0x70, 0x22, 0x9a, 0x03, 0x0a, 0x10,
+	0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x22, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x62, 0x75, 0x6d, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x09, 0x70, 0x72, 0x69, 0x63, 0x65, 0x42, 0x75, 0x6d,
+	0x70, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0f, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x5f, 0x62, 0x75, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x01, 0x52,
+	0x0d, 0x62, 0x6c, 0x6f, 0x62, 0x50, 0x72, 0x69, 0x63, 0x65, 0x42, 0x75, 0x6d, 0x70, 0x88, 0x01,
+	0x01, 0x12, 0x23, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x63, 0x61, 0x70,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x48, 0x02, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x46, 0x65, 0x65,
+	0x43, 0x61, 0x70, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x69,
+	0x70, 0x5f, 0x63, 0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x48, 0x03, 0x52, 0x09, 0x6d,
+	0x69, 0x6e, 0x54, 0x69, 0x70, 0x43, 0x61, 0x70, 0x88, 0x01, 0x01, 0x12, 0x28, 0x0a, 0x0d, 0x61,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x04, 0x48, 0x04, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x53, 0x6c, 0x6f,
+	0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x73, 0x6c,
+	0x6f, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x48, 0x05, 0x52, 0x09, 0x62, 0x6c, 0x6f,
+	0x62, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0d, 0x6d, 0x61, 0x78,
+	0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x5f, 0x67, 0x61, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04,
+	0x48, 0x06, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x47, 0x61, 0x70, 0x88,
+	0x01, 0x01, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x62, 0x75, 0x6d,
+	0x70, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x5f, 0x62, 0x75, 0x6d, 0x70, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x66, 0x65,
+	0x65, 0x5f, 0x63, 0x61, 0x70, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x69,
+	0x70, 0x5f, 0x63, 0x61, 0x70, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x62, 0x6c, 0x6f, 0x62,
+	0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x5f, 0x67, 0x61, 0x70, 0x22, 0xf6, 0x02, 0x0a, 0x08, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x64, 0x54, 0x78, 0x12, 0x1f, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36,
+	0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x23, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48,
+	0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x67, 0x61, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x03, 0x67, 0x61, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x03, 0x74,
+	0x69, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x03, 0x74, 0x69, 0x70, 0x12, 0x24, 0x0a, 0x07, 0x66, 0x65,
+	0x65, 0x5f, 0x63, 0x61, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x06, 0x66, 0x65, 0x65, 0x43, 0x61, 0x70,
+	0x12, 0x2d, 0x0a, 0x0c, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x63, 0x61, 0x70,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48,
+	0x32, 0x35, 0x36, 0x52, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x46, 0x65, 0x65, 0x43, 0x61, 0x70, 0x12,
+	0x30, 0x0a, 0x0d, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x74, 0x69, 0x70,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48,
+	0x32, 0x35, 0x36, 0x52, 0x0c, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x54, 0x69,
+	0x70, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x05, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x72, 0x72, 0x69, 0x76,
+	0x61, 0x6c, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x72, 0x72, 0x69, 0x76, 0x61,
+	0x6c, 0x22, 0x32, 0x0a, 0x0c, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x22, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x64, 0x54, 0x78,
+	0x52, 0x03, 0x74, 0x78, 0x73, 0x22, 0x24, 0x0a, 0x0a, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x04, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x22, 0x8b, 0x04, 0x0a, 0x0d,
+	0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a,
+	0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x12, 0x21, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x05,
+	0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x69, 0x74, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x69, 0x6e, 0x69, 0x74, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x61, 0x6c, 0x6c, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x63, 0x61, 0x6c, 0x6c, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x31, 0x0a, 0x0e, 0x63, 0x61, 0x6c, 0x6c, 0x5f, 0x67, 0x61, 0x73, 0x5f, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e,
+	0x48, 0x32, 0x35, 0x36, 0x52, 0x0c, 0x63, 0x61, 0x6c, 0x6c, 0x47, 0x61, 0x73, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x12, 0x41, 0x0a, 0x16, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x67, 0x61, 0x73, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52,
+	0x14, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x47, 0x61, 0x73,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x3d, 0x0a, 0x14, 0x70, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x67, 0x61, 0x73, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36,
+	0x52, 0x12, 0x70, 0x72, 0x65, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x47, 0x61, 0x73, 0x12, 0x32, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x66, 0x65, 0x65, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x67, 0x61, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x46,
+	0x65, 0x65, 0x50, 0x65, 0x72, 0x47, 0x61, 0x73, 0x12, 0x43, 0x0a, 0x18, 0x6d, 0x61, 0x78, 0x5f,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x70, 0x65, 0x72,
+	0x5f, 0x67, 0x61, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x14, 0x6d, 0x61, 0x78, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x46, 0x65, 0x65, 0x50, 0x65, 0x72, 0x47, 0x61, 0x73, 0x12, 0x2c, 0x0a,
+	0x12, 0x70, 0x61, 0x79, 0x6d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x5f, 0x61, 0x6e, 0x64, 0x5f, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x70, 0x61, 0x79, 0x6d, 0x61,
+	0x73, 0x74, 0x65, 0x72, 0x41, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0xd3, 0x01, 0x0a, 0x0a, 0x50, 0x6f,
+	0x6f, 0x6c, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x12, 0x1f, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48,
+	0x32, 0x35, 0x36, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x2c, 0x0a, 0x0b, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x0a, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f,
+	0x6f, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x06, 0x75, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x66,
+	0x75, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x07, 0x70, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x22,
+	0x70, 0x0a, 0x10, 0x41, 0x64, 0x64, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x2c, 0x0a, 0x0b, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x0a, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x2e, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x4f,
+	0x70, 0x22, 0x66, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x04,
+	0x68, 0x61, 0x73, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x45, 0x0a, 0x15, 0x50, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2c, 0x0a, 0x0b, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e,
+	0x48, 0x31, 0x36, 0x30, 0x52, 0x0a, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x69, 0x6e, 0x74,
+	0x22, 0x3b, 0x0a, 0x13, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x55, 0x73, 0x65, 0x72, 0x4f,
+	0x70, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x24, 0x0a, 0x03, 0x6f, 0x70, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x50, 0x6f,
+	0x6f, 0x6c, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x52, 0x03, 0x6f, 0x70, 0x73, 0x22, 0xdb, 0x01,
+	0x0a, 0x0f, 0x52, 0x65, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x70, 0x73, 0x5f,
+	0x73, 0x65, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6f, 0x70, 0x73, 0x53,
+	0x65, 0x65, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x70, 0x73, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6f, 0x70, 0x73, 0x49, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x12, 0x36, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x52, 0x65, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x2b,
+	0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x06, 0x0a, 0x02, 0x4f, 0x4b, 0x10, 0x00,
+	0x12, 0x0d, 0x0a, 0x09, 0x54, 0x48, 0x52, 0x4f, 0x54, 0x54, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12,
+	0x0a, 0x0a, 0x06, 0x42, 0x41, 0x4e, 0x4e, 0x45, 0x44, 0x10, 0x02, 0x22, 0x44, 0x0a, 0x0f, 0x52,
+	0x65, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x31,
+	0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x52, 0x65, 0x70, 0x75, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x2a, 0x6c, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x10, 0x00, 0x12, 0x12,
+	0x0a, 0x0e, 0x41, 0x4c, 0x52, 0x45, 0x41, 0x44, 0x59, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53,
+	0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x46, 0x45, 0x45, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x4c, 0x4f,
+	0x57, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54, 0x41, 0x4c, 0x45, 0x10, 0x03, 0x12, 0x0b,
+	0x0a, 0x07, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x49,
+	0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x05, 0x32,
+	0xd8, 0x07, 0x0a, 0x06, 0x54, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x12, 0x36, 0x0a, 0x07, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x31, 0x0a, 0x0b, 0x46, 0x69, 0x6e, 0x64, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x12, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x12, 0x2e, 0x74,
+	0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x46, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x1b, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x19, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x03, 0x41, 0x6c,
+	0x6c, 0x12, 0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6c, 0x6c, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41,
+	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x37, 0x0a, 0x07, 0x50, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x74, 0x78, 0x70,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x33, 0x0a, 0x05, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x12, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f,
+	0x6f, 0x6c, 0x2e, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x30, 0x01, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x15, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x4e,
+	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4e, 0x6f,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x74, 0x78, 0x70,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x40,
+	0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x19, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x32, 0x0a, 0x0c, 0x45, 0x76, 0x69, 0x63, 0x74, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c,
+	0x12, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61,
+	0x73, 0x68, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f,
+	0x6f, 0x6c, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x3a,
+	0x0a, 0x09, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x18, 0x2e, 0x74, 0x78,
+	0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x39, 0x0a, 0x0a, 0x41, 0x64,
+	0x64, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x19, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x41, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x6e, 0x64,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x12, 0x1d, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x41, 0x64, 0x64, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x41, 0x64, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x41, 0x0a, 0x11, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x73, 0x12, 0x17, 0x2e,
+	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x54, 0x78, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x30, 0x01, 0x12, 0x3d, 0x0a, 0x09, 0x41,
+	0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x12, 0x18, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x32, 0x3b, 0x0a, 0x06, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x05, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x14, 0x2e,
+	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x32, 0xad, 0x02, 0x0a, 0x07, 0x55, 0x73, 0x65, 0x72,
+	0x4f, 0x70, 0x73, 0x12, 0x37, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x18, 0x2e, 0x74, 0x78, 0x70,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64, 0x64, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x41, 0x64,
+	0x64, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x45, 0x0a, 0x07,
+	0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x55, 0x73, 0x65, 0x72, 0x4f, 0x70, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x10, 0x2e,
+	0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x1a,
+	0x10, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x12, 0x3d, 0x0a, 0x0a, 0x52, 0x65, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x17, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x52, 0x65, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x35, 0x0a, 0x05, 0x4f, 0x6e, 0x41, 0x64, 0x64, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x1a, 0x12, 0x2e, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2e, 0x50, 0x6f, 0x6f, 0x6c, 0x55,
+	0x73, 0x65, 0x72, 0x4f, 0x70, 0x30, 0x01, 0x42, 0x16, 0x5a, 0x14, 0x2e, 0x2f, 0x74, 0x78, 0x70,
+	0x6f, 0x6f, 0x6c, 0x3b, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_txpool_txpool_proto_rawDescOnce sync.Once
+	file_txpool_txpool_proto_rawDescData = file_txpool_txpool_proto_rawDesc
+)
+
+func file_txpool_txpool_proto_rawDescGZIP() []byte {
+	file_txpool_txpool_proto_rawDescOnce.Do(func() {
+		file_txpool_txpool_proto_rawDescData = protoimpl.X.CompressGZIP(file_txpool_txpool_proto_rawDescData)
+	})
+	return file_txpool_txpool_proto_rawDescData
+}
+
+var file_txpool_txpool_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_txpool_txpool_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
+var file_txpool_txpool_proto_goTypes = []interface{}{
+	(ImportResult)(0),                     // 0: txpool.ImportResult
+	(AllReply_TxnType)(0),                 // 1: txpool.AllReply.TxnType
+	(TxOutcome_Status)(0),                 // 2: txpool.TxOutcome.Status
+	(ReputationEntry_Status)(0),           // 3: txpool.ReputationEntry.Status
+	(*TxHashes)(nil),                      // 4: txpool.TxHashes
+	(*AddRequest)(nil),                    // 5: txpool.AddRequest
+	(*AddReply)(nil),                      // 6: txpool.AddReply
+	(*TransactionsRequest)(nil),           // 7: txpool.TransactionsRequest
+	(*TransactionsReply)(nil),             // 8: txpool.TransactionsReply
+	(*OnAddRequest)(nil),                  // 9: txpool.OnAddRequest
+	(*OnAddReply)(nil),                    // 10: txpool.OnAddReply
+	(*AllRequest)(nil),                    // 11: txpool.AllRequest
+	(*AllReply)(nil),                      // 12: txpool.AllReply
+	(*PendingReply)(nil),                  // 13: txpool.PendingReply
+	(*StatusRequest)(nil),                 // 14: txpool.StatusRequest
+	(*StatusReply)(nil),                   // 15: txpool.StatusReply
+	(*NonceRequest)(nil),                  // 16: txpool.NonceRequest
+	(*NonceReply)(nil),                    // 17: txpool.NonceReply
+	(*AddPrivateRequest)(nil),             // 18: txpool.AddPrivateRequest
+	(*StorageSlot)(nil),                   // 19: txpool.StorageSlot
+	(*KnownAccount)(nil),                  // 20: txpool.KnownAccount
+	(*AddConditionalRequest)(nil),         // 21: txpool.AddConditionalRequest
+	(*OutcomesRequest)(nil),               // 22: txpool.OutcomesRequest
+	(*TxOutcome)(nil),                     // 23: txpool.TxOutcome
+	(*AnalyticsRequest)(nil),              // 24: txpool.AnalyticsRequest
+	(*AnalyticsReply)(nil),                // 25: txpool.AnalyticsReply
+	(*PolicyReply)(nil),                   // 26: txpool.PolicyReply
+	(*SetPolicyRequest)(nil),              // 27: txpool.SetPolicyRequest
+	(*ScoredTx)(nil),                      // 28: txpool.ScoredTx
+	(*ScoreRequest)(nil),                  // 29: txpool.ScoreRequest
+	(*ScoreReply)(nil),                    // 30: txpool.ScoreReply
+	(*UserOperation)(nil),                 // 31: txpool.UserOperation
+	(*PoolUserOp)(nil),                    // 32: txpool.PoolUserOp
+	(*AddUserOpRequest)(nil),              // 33: txpool.AddUserOpRequest
+	(*AddUserOpReply)(nil),                // 34: txpool.AddUserOpReply
+	(*PendingUserOpsRequest)(nil),         // 35: txpool.PendingUserOpsRequest
+	(*PendingUserOpsReply)(nil),           // 36: txpool.PendingUserOpsReply
+	(*ReputationEntry)(nil),               // 37: txpool.ReputationEntry
+	(*ReputationReply)(nil),               // 38: txpool.ReputationReply
+	(*AllReply_Tx)(nil),                   // 39: txpool.AllReply.Tx
+	(*PendingReply_Tx)(nil),               // 40: txpool.PendingReply.Tx
+	(*AnalyticsReply_AgeBucket)(nil),      // 41: txpool.AnalyticsReply.AgeBucket
+	(*AnalyticsReply_SenderNonceGap)(nil), // 42: txpool.AnalyticsReply.SenderNonceGap
+	(*typesproto.H256)(nil),               // 43: types.H256
+	(*typesproto.H160)(nil),               // 44: types.H160
+	(*emptypb.Empty)(nil),                 // 45: google.protobuf.Empty
+	(*typesproto.VersionReply)(nil),       // 46: types.VersionReply
+}
+var file_txpool_txpool_proto_depIdxs = []int32{
+	43, // 0: txpool.TxHashes.hashes:type_name -> types.H256
+	0,  // 1: txpool.AddReply.imported:type_name -> txpool.ImportResult
+	43, // 2: txpool.TransactionsRequest.hashes:type_name -> types.H256
+	39, // 3: txpool.AllReply.txs:type_name -> txpool.AllReply.Tx
+	40, // 4: txpool.PendingReply.txs:type_name -> txpool.PendingReply.Tx
+	44, // 5: txpool.NonceRequest.address:type_name -> types.H160
+	43, // 6: txpool.StorageSlot.key:type_name -> types.H256
+	43, // 7: txpool.StorageSlot.value:type_name -> types.H256
+	44, // 8: txpool.KnownAccount.address:type_name -> types.H160
+	19, // 9: txpool.KnownAccount.slots:type_name -> txpool.StorageSlot
+	20, // 10: txpool.AddConditionalRequest.known_accounts:type_name -> txpool.KnownAccount
+	43, // 11: txpool.OutcomesRequest.hashes:type_name -> types.H256
+	43, // 12: txpool.TxOutcome.hash:type_name -> types.H256
+	44, // 13: txpool.TxOutcome.sender:type_name -> types.H160
+	2,  // 14: txpool.TxOutcome.status:type_name -> txpool.TxOutcome.Status
+	43, // 15: txpool.TxOutcome.replaced_by:type_name -> types.H256
+	44, // 16: txpool.AnalyticsRequest.sender:type_name -> types.H160
+	41, // 17: txpool.AnalyticsReply.age:type_name -> txpool.AnalyticsReply.AgeBucket
+	42, // 18: txpool.AnalyticsReply.nonce_gaps:type_name -> txpool.AnalyticsReply.SenderNonceGap
+	43, // 19: txpool.ScoredTx.hash:type_name -> types.H256
+	44, // 20: txpool.ScoredTx.sender:type_name -> types.H160
+	43, // 21: txpool.ScoredTx.tip:type_name -> types.H256
+	43, // 22: txpool.ScoredTx.fee_cap:type_name -> types.H256
+	43, // 23: txpool.ScoredTx.blob_fee_cap:type_name -> types.H256
+	43, // 24: txpool.ScoredTx.effective_tip:type_name -> types.H256
+	28, // 25: txpool.ScoreRequest.txs:type_name -> txpool.ScoredTx
+	44, // 26: txpool.UserOperation.sender:type_name -> types.H160
+	43, // 27: txpool.UserOperation.nonce:type_name -> types.H256
+	43, // 28: txpool.UserOperation.call_gas_limit:type_name -> types.H256
+	43, // 29: txpool.UserOperation.verification_gas_limit:type_name -> types.H256
+	43, // 30: txpool.UserOperation.pre_verification_gas:type_name -> types.H256
+	43, // 31: txpool.UserOperation.max_fee_per_gas:type_name -> types.H256
+	43, // 32: txpool.UserOperation.max_priority_fee_per_gas:type_name -> types.H256
+	43, // 33: txpool.PoolUserOp.hash:type_name -> types.H256
+	44, // 34: txpool.PoolUserOp.entry_point:type_name -> types.H160
+	31, // 35: txpool.PoolUserOp.user_op:type_name -> txpool.UserOperation
+	43, // 36: txpool.PoolUserOp.prefund:type_name -> types.H256
+	44, // 37: txpool.AddUserOpRequest.entry_point:type_name -> types.H160
+	31, // 38: txpool.AddUserOpRequest.user_op:type_name -> txpool.UserOperation
+	43, // 39: txpool.AddUserOpReply.hash:type_name -> types.H256
+	44, // 40: txpool.PendingUserOpsRequest.entry_point:type_name -> types.H160
+	32, // 41: txpool.PendingUserOpsReply.ops:type_name -> txpool.PoolUserOp
+	44, // 42: txpool.ReputationEntry.address:type_name -> types.H160
+	3,  // 43: txpool.ReputationEntry.status:type_name -> txpool.ReputationEntry.Status
+	37, // 44: txpool.ReputationReply.entries:type_name -> txpool.ReputationEntry
+	1,  // 45: txpool.AllReply.Tx.txn_type:type_name -> txpool.AllReply.TxnType
+	44, // 46: txpool.AllReply.Tx.sender:type_name -> types.H160
+	44, // 47: txpool.PendingReply.Tx.sender:type_name -> types.H160
+	44, // 48: txpool.AnalyticsReply.SenderNonceGap.sender:type_name -> types.H160
+	45, // 49: txpool.Txpool.Version:input_type -> google.protobuf.Empty
+	4,  // 50: txpool.Txpool.FindUnknown:input_type -> txpool.TxHashes
+	5,  // 51: txpool.Txpool.Add:input_type -> txpool.AddRequest
+	7,  // 52: txpool.Txpool.Transactions:input_type -> txpool.TransactionsRequest
+	11, // 53: txpool.Txpool.All:input_type -> txpool.AllRequest
+	45, // 54: txpool.Txpool.Pending:input_type -> google.protobuf.Empty
+	9,  // 55: txpool.Txpool.OnAdd:input_type -> txpool.OnAddRequest
+	14, // 56: txpool.Txpool.Status:input_type -> txpool.StatusRequest
+	16, // 57: txpool.Txpool.Nonce:input_type -> txpool.NonceRequest
+	45, // 58: txpool.Txpool.ListJournal:input_type -> google.protobuf.Empty
+	4,  // 59: txpool.Txpool.EvictJournal:input_type -> txpool.TxHashes
+	45, // 60: txpool.Txpool.GetPolicy:input_type -> google.protobuf.Empty
+	27, // 61: txpool.Txpool.SetPolicy:input_type -> txpool.SetPolicyRequest
+	18, // 62: txpool.Txpool.AddPrivate:input_type -> txpool.AddPrivateRequest
+	21, // 63: txpool.Txpool.AddConditional:input_type -> txpool.AddConditionalRequest
+	22, // 64: txpool.Txpool.SubscribeOutcomes:input_type -> txpool.OutcomesRequest
+	24, // 65: txpool.Txpool.Analytics:input_type -> txpool.AnalyticsRequest
+	29, // 66: txpool.Scorer.Score:input_type -> txpool.ScoreRequest
+	33, // 67: txpool.UserOps.Add:input_type -> txpool.AddUserOpRequest
+	35, // 68: txpool.UserOps.Pending:input_type -> txpool.PendingUserOpsRequest
+	4,  // 69: txpool.UserOps.Remove:input_type -> txpool.TxHashes
+	45, // 70: txpool.UserOps.Reputation:input_type -> google.protobuf.Empty
+	45, // 71: txpool.UserOps.OnAdd:input_type -> google.protobuf.Empty
+	46, // 72: txpool.Txpool.Version:output_type -> types.VersionReply
+	4,  // 73: txpool.Txpool.FindUnknown:output_type -> txpool.TxHashes
+	6,  // 74: txpool.Txpool.Add:output_type -> txpool.AddReply
+	8,  // 75: txpool.Txpool.Transactions:output_type -> txpool.TransactionsReply
+	12, // 76: txpool.Txpool.All:output_type -> txpool.AllReply
+	13, // 77: txpool.Txpool.Pending:output_type -> txpool.PendingReply
+	10, // 78: txpool.Txpool.OnAdd:output_type -> txpool.OnAddReply
+	15, // 79: txpool.Txpool.Status:output_type -> txpool.StatusReply
+	17, // 80: txpool.Txpool.Nonce:output_type -> txpool.NonceReply
+	8,  // 81: txpool.Txpool.ListJournal:output_type -> txpool.TransactionsReply
+	4,  // 82: txpool.Txpool.EvictJournal:output_type -> txpool.TxHashes
+	26, // 83: txpool.Txpool.GetPolicy:output_type -> txpool.PolicyReply
+	26, // 84: txpool.Txpool.SetPolicy:output_type -> txpool.PolicyReply
+	6,  // 85: txpool.Txpool.AddPrivate:output_type -> txpool.AddReply
+	6,  // 86: txpool.Txpool.AddConditional:output_type -> txpool.AddReply
+	23, // 87: txpool.Txpool.SubscribeOutcomes:output_type -> txpool.TxOutcome
+	25, // 88: txpool.Txpool.Analytics:output_type -> txpool.AnalyticsReply
+	30, // 89: txpool.Scorer.Score:output_type -> txpool.ScoreReply
+	34, // 90: txpool.UserOps.Add:output_type -> txpool.AddUserOpReply
+	36, // 91: txpool.UserOps.Pending:output_type -> txpool.PendingUserOpsReply
+	4,  // 92: txpool.UserOps.Remove:output_type -> txpool.TxHashes
+	38, // 93: txpool.UserOps.Reputation:output_type -> txpool.ReputationReply
+	32, // 94: txpool.UserOps.OnAdd:output_type -> txpool.PoolUserOp
+	72, // [72:95] is the sub-list for method output_type
+	49, // [49:72] is the sub-list for method input_type
+	49, // [49:49] is the sub-list for extension type_name
+	49, // [49:49] is the sub-list for extension extendee
+	0,  // [0:49] is the sub-list for field type_name
+}
+
+func init() { file_txpool_txpool_proto_init() }
+func file_txpool_txpool_proto_init() {
+	if File_txpool_txpool_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_txpool_txpool_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TxHashes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
 			}
 		}
 		file_txpool_txpool_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
@@ -1277,7 +3399,7 @@ func file_txpool_txpool_proto_init() {
 			}
 		}
 		file_txpool_txpool_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AllReply_Tx); i {
+			switch v := v.(*AddPrivateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1289,6 +3411,258 @@ func file_txpool_txpool_proto_init() {
 			}
 		}
 		file_txpool_txpool_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageSlot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KnownAccount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddConditionalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutcomesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TxOutcome); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyticsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyticsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPolicyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoredTx); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserOperation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PoolUserOp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddUserOpRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddUserOpReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PendingUserOpsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PendingUserOpsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReputationEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReputationReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllReply_Tx); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PendingReply_Tx); i {
 			case 0:
 				return &v.state
@@ -1300,16 +3674,42 @@ func file_txpool_txpool_proto_init() {
 				return nil
 			}
 		}
+		file_txpool_txpool_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyticsReply_AgeBucket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_txpool_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyticsReply_SenderNonceGap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_txpool_txpool_proto_msgTypes[17].OneofWrappers = []interface{}{}
+	file_txpool_txpool_proto_msgTypes[23].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_txpool_txpool_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   16,
+			NumEnums:      4,
+			NumMessages:   39,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   3,
 		},
 		GoTypes:           file_txpool_txpool_proto_goTypes,
 		DependencyIndexes: file_txpool_txpool_proto_depIdxs,
@@ -21,15 +21,23 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Txpool_Version_FullMethodName      = "/txpool.Txpool/Version"
-	Txpool_FindUnknown_FullMethodName  = "/txpool.Txpool/FindUnknown"
-	Txpool_Add_FullMethodName          = "/txpool.Txpool/Add"
-	Txpool_Transactions_FullMethodName = "/txpool.Txpool/Transactions"
-	Txpool_All_FullMethodName          = "/txpool.Txpool/All"
-	Txpool_Pending_FullMethodName      = "/txpool.Txpool/Pending"
-	Txpool_OnAdd_FullMethodName        = "/txpool.Txpool/OnAdd"
-	Txpool_Status_FullMethodName       = "/txpool.Txpool/Status"
-	Txpool_Nonce_FullMethodName        = "/txpool.Txpool/Nonce"
+	Txpool_Version_FullMethodName           = "/txpool.Txpool/Version"
+	Txpool_FindUnknown_FullMethodName       = "/txpool.Txpool/FindUnknown"
+	Txpool_Add_FullMethodName               = "/txpool.Txpool/Add"
+	Txpool_Transactions_FullMethodName      = "/txpool.Txpool/Transactions"
+	Txpool_All_FullMethodName               = "/txpool.Txpool/All"
+	Txpool_Pending_FullMethodName           = "/txpool.Txpool/Pending"
+	Txpool_OnAdd_FullMethodName             = "/txpool.Txpool/OnAdd"
+	Txpool_Status_FullMethodName            = "/txpool.Txpool/Status"
+	Txpool_Nonce_FullMethodName             = "/txpool.Txpool/Nonce"
+	Txpool_ListJournal_FullMethodName       = "/txpool.Txpool/ListJournal"
+	Txpool_EvictJournal_FullMethodName      = "/txpool.Txpool/EvictJournal"
+	Txpool_GetPolicy_FullMethodName         = "/txpool.Txpool/GetPolicy"
+	Txpool_SetPolicy_FullMethodName         = "/txpool.Txpool/SetPolicy"
+	Txpool_AddPrivate_FullMethodName        = "/txpool.Txpool/AddPrivate"
+	Txpool_AddConditional_FullMethodName    = "/txpool.Txpool/AddConditional"
+	Txpool_SubscribeOutcomes_FullMethodName = "/txpool.Txpool/SubscribeOutcomes"
+	Txpool_Analytics_FullMethodName         = "/txpool.Txpool/Analytics"
 )
 
 // TxpoolClient is the client API for Txpool service.
@@ -55,6 +63,22 @@ type TxpoolClient interface {
 	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error)
 	// returns nonce for given account
 	Nonce(ctx context.Context, in *NonceRequest, opts ...grpc.CallOption) (*NonceReply, error)
+	// returns journaled local transactions, oldest first
+	ListJournal(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TransactionsReply, error)
+	// removes transactions from journal (not from pool), returns hashes of removed ones
+	EvictJournal(ctx context.Context, in *TxHashes, opts ...grpc.CallOption) (*TxHashes, error)
+	// returns current policy of pool
+	GetPolicy(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PolicyReply, error)
+	// changes policy of pool without restart, returns applied policy
+	SetPolicy(ctx context.Context, in *SetPolicyRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	// like Add, but transactions are never gossiped to peers: they are only included into blocks produced by this node
+	AddPrivate(ctx context.Context, in *AddPrivateRequest, opts ...grpc.CallOption) (*AddReply, error)
+	// like AddPrivate, but transactions are dropped as soon as new block invalidates their conditions
+	AddConditional(ctx context.Context, in *AddConditionalRequest, opts ...grpc.CallOption) (*AddReply, error)
+	// subscribe to outcomes of transactions: accepted, rejected, replaced, dropped, mined. Slow subscribers may miss outcomes
+	SubscribeOutcomes(ctx context.Context, in *OutcomesRequest, opts ...grpc.CallOption) (Txpool_SubscribeOutcomesClient, error)
+	// returns age distribution of transactions, transactions below base fee and senders with nonce gaps
+	Analytics(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*AnalyticsReply, error)
 }
 
 type txpoolClient struct {
@@ -169,6 +193,101 @@ func (c *txpoolClient) Nonce(ctx context.Context, in *NonceRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *txpoolClient) ListJournal(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TransactionsReply, error) {
+	out := new(TransactionsReply)
+	err := c.cc.Invoke(ctx, Txpool_ListJournal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) EvictJournal(ctx context.Context, in *TxHashes, opts ...grpc.CallOption) (*TxHashes, error) {
+	out := new(TxHashes)
+	err := c.cc.Invoke(ctx, Txpool_EvictJournal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) GetPolicy(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, Txpool_GetPolicy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) SetPolicy(ctx context.Context, in *SetPolicyRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, Txpool_SetPolicy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) AddPrivate(ctx context.Context, in *AddPrivateRequest, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := c.cc.Invoke(ctx, Txpool_AddPrivate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) AddConditional(ctx context.Context, in *AddConditionalRequest, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := c.cc.Invoke(ctx, Txpool_AddConditional_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txpoolClient) SubscribeOutcomes(ctx context.Context, in *OutcomesRequest, opts ...grpc.CallOption) (Txpool_SubscribeOutcomesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Txpool_ServiceDesc.Streams[1], Txpool_SubscribeOutcomes_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &txpoolSubscribeOutcomesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Txpool_SubscribeOutcomesClient interface {
+	Recv() (*TxOutcome, error)
+	grpc.ClientStream
+}
+
+type txpoolSubscribeOutcomesClient struct {
+	grpc.ClientStream
+}
+
+func (x *txpoolSubscribeOutcomesClient) Recv() (*TxOutcome, error) {
+	m := new(TxOutcome)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *txpoolClient) Analytics(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*AnalyticsReply, error) {
+	out := new(AnalyticsReply)
+	err := c.cc.Invoke(ctx, Txpool_Analytics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TxpoolServer is the server API for Txpool service.
 // All implementations must embed UnimplementedTxpoolServer
 // for forward compatibility
@@ -192,6 +311,22 @@ type TxpoolServer interface {
 	Status(context.Context, *StatusRequest) (*StatusReply, error)
 	// returns nonce for given account
 	Nonce(context.Context, *NonceRequest) (*NonceReply, error)
+	// returns journaled local transactions, oldest first
+	ListJournal(context.Context, *emptypb.Empty) (*TransactionsReply, error)
+	// removes transactions from journal (not from pool), returns hashes of removed ones
+	EvictJournal(context.Context, *TxHashes) (*TxHashes, error)
+	// returns current policy of pool
+	GetPolicy(context.Context, *emptypb.Empty) (*PolicyReply, error)
+	// changes policy of pool without restart, returns applied policy
+	SetPolicy(context.Context, *SetPolicyRequest) (*PolicyReply, error)
+	// like Add, but transactions are never gossiped to peers: they are only included into blocks produced by this node
+	AddPrivate(context.Context, *AddPrivateRequest) (*AddReply, error)
+	// like AddPrivate, but transactions are dropped as soon as new block invalidates their conditions
+	AddConditional(context.Context, *AddConditionalRequest) (*AddReply, error)
+	// subscribe to outcomes of transactions: accepted, rejected, replaced, dropped, mined. Slow subscribers may miss outcomes
+	SubscribeOutcomes(*OutcomesRequest, Txpool_SubscribeOutcomesServer) error
+	// returns age distribution of transactions, transactions below base fee and senders with nonce gaps
+	Analytics(context.Context, *AnalyticsRequest) (*AnalyticsReply, error)
 	mustEmbedUnimplementedTxpoolServer()
 }
 
@@ -226,6 +361,30 @@ func (UnimplementedTxpoolServer) Status(context.Context, *StatusRequest) (*Statu
 func (UnimplementedTxpoolServer) Nonce(context.Context, *NonceRequest) (*NonceReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Nonce not implemented")
 }
+func (UnimplementedTxpoolServer) ListJournal(context.Context, *emptypb.Empty) (*TransactionsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJournal not implemented")
+}
+func (UnimplementedTxpoolServer) EvictJournal(context.Context, *TxHashes) (*TxHashes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvictJournal not implemented")
+}
+func (UnimplementedTxpoolServer) GetPolicy(context.Context, *emptypb.Empty) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicy not implemented")
+}
+func (UnimplementedTxpoolServer) SetPolicy(context.Context, *SetPolicyRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPolicy not implemented")
+}
+func (UnimplementedTxpoolServer) AddPrivate(context.Context, *AddPrivateRequest) (*AddReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPrivate not implemented")
+}
+func (UnimplementedTxpoolServer) AddConditional(context.Context, *AddConditionalRequest) (*AddReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddConditional not implemented")
+}
+func (UnimplementedTxpoolServer) SubscribeOutcomes(*OutcomesRequest, Txpool_SubscribeOutcomesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeOutcomes not implemented")
+}
+func (UnimplementedTxpoolServer) Analytics(context.Context, *AnalyticsRequest) (*AnalyticsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analytics not implemented")
+}
 func (UnimplementedTxpoolServer) mustEmbedUnimplementedTxpoolServer() {}
 
 // UnsafeTxpoolServer may be embedded to opt out of forward compatibility for this service.
@@ -404,6 +563,153 @@ func _Txpool_Nonce_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Txpool_ListJournal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).ListJournal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_ListJournal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).ListJournal(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_EvictJournal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).EvictJournal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_EvictJournal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).EvictJournal(ctx, req.(*TxHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_GetPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).GetPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_GetPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).GetPolicy(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_SetPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).SetPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_SetPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).SetPolicy(ctx, req.(*SetPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_AddPrivate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPrivateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).AddPrivate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_AddPrivate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).AddPrivate(ctx, req.(*AddPrivateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_AddConditional_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddConditionalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).AddConditional(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_AddConditional_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).AddConditional(ctx, req.(*AddConditionalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Txpool_SubscribeOutcomes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OutcomesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TxpoolServer).SubscribeOutcomes(m, &txpoolSubscribeOutcomesServer{stream})
+}
+
+type Txpool_SubscribeOutcomesServer interface {
+	Send(*TxOutcome) error
+	grpc.ServerStream
+}
+
+type txpoolSubscribeOutcomesServer struct {
+	grpc.ServerStream
+}
+
+func (x *txpoolSubscribeOutcomesServer) Send(m *TxOutcome) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Txpool_Analytics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).Analytics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_Analytics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).Analytics(ctx, req.(*AnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Txpool_ServiceDesc is the grpc.ServiceDesc for Txpool service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -443,6 +749,34 @@ var Txpool_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Nonce",
 			Handler:    _Txpool_Nonce_Handler,
 		},
+		{
+			MethodName: "ListJournal",
+			Handler:    _Txpool_ListJournal_Handler,
+		},
+		{
+			MethodName: "EvictJournal",
+			Handler:    _Txpool_EvictJournal_Handler,
+		},
+		{
+			MethodName: "GetPolicy",
+			Handler:    _Txpool_GetPolicy_Handler,
+		},
+		{
+			MethodName: "SetPolicy",
+			Handler:    _Txpool_SetPolicy_Handler,
+		},
+		{
+			MethodName: "AddPrivate",
+			Handler:    _Txpool_AddPrivate_Handler,
+		},
+		{
+			MethodName: "AddConditional",
+			Handler:    _Txpool_AddConditional_Handler,
+		},
+		{
+			MethodName: "Analytics",
+			Handler:    _Txpool_Analytics_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -450,6 +784,379 @@ var Txpool_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Txpool_OnAdd_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeOutcomes",
+			Handler:       _Txpool_SubscribeOutcomes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "txpool/txpool.proto",
+}
+
+const (
+	Scorer_Score_FullMethodName = "/txpool.Scorer/Score"
+)
+
+// ScorerClient is the client API for Scorer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ScorerClient interface {
+	// higher score - earlier transaction is included
+	Score(ctx context.Context, in *ScoreRequest, opts ...grpc.CallOption) (*ScoreReply, error)
+}
+
+type scorerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScorerClient(cc grpc.ClientConnInterface) ScorerClient {
+	return &scorerClient{cc}
+}
+
+func (c *scorerClient) Score(ctx context.Context, in *ScoreRequest, opts ...grpc.CallOption) (*ScoreReply, error) {
+	out := new(ScoreReply)
+	err := c.cc.Invoke(ctx, Scorer_Score_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScorerServer is the server API for Scorer service.
+// All implementations must embed UnimplementedScorerServer
+// for forward compatibility
+type ScorerServer interface {
+	// higher score - earlier transaction is included
+	Score(context.Context, *ScoreRequest) (*ScoreReply, error)
+	mustEmbedUnimplementedScorerServer()
+}
+
+// UnimplementedScorerServer must be embedded to have forward compatible implementations.
+type UnimplementedScorerServer struct {
+}
+
+func (UnimplementedScorerServer) Score(context.Context, *ScoreRequest) (*ScoreReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Score not implemented")
+}
+func (UnimplementedScorerServer) mustEmbedUnimplementedScorerServer() {}
+
+// UnsafeScorerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScorerServer will
+// result in compilation errors.
+type UnsafeScorerServer interface {
+	mustEmbedUnimplementedScorerServer()
+}
+
+func RegisterScorerServer(s grpc.ServiceRegistrar, srv ScorerServer) {
+	s.RegisterService(&Scorer_ServiceDesc, srv)
+}
+
+func _Scorer_Score_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScorerServer).Score(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scorer_Score_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScorerServer).Score(ctx, req.(*ScoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Scorer_ServiceDesc is the grpc.ServiceDesc for Scorer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Scorer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "txpool.Scorer",
+	HandlerType: (*ScorerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Score",
+			Handler:    _Scorer_Score_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "txpool/txpool.proto",
+}
+
+const (
+	UserOps_Add_FullMethodName        = "/txpool.UserOps/Add"
+	UserOps_Pending_FullMethodName    = "/txpool.UserOps/Pending"
+	UserOps_Remove_FullMethodName     = "/txpool.UserOps/Remove"
+	UserOps_Reputation_FullMethodName = "/txpool.UserOps/Reputation"
+	UserOps_OnAdd_FullMethodName      = "/txpool.UserOps/OnAdd"
+)
+
+// UserOpsClient is the client API for UserOps service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UserOpsClient interface {
+	// adds user operation to pool, returns its hash or reason of rejection
+	Add(ctx context.Context, in *AddUserOpRequest, opts ...grpc.CallOption) (*AddUserOpReply, error)
+	// returns user operations of entry point, best first
+	Pending(ctx context.Context, in *PendingUserOpsRequest, opts ...grpc.CallOption) (*PendingUserOpsReply, error)
+	// removes user operations from pool (e.g. included by bundler), returns hashes of removed ones
+	Remove(ctx context.Context, in *TxHashes, opts ...grpc.CallOption) (*TxHashes, error)
+	// returns ERC-7562 reputation of entities
+	Reputation(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReputationReply, error)
+	// subscribe to user operations added to pool
+	OnAdd(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (UserOps_OnAddClient, error)
+}
+
+type userOpsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUserOpsClient(cc grpc.ClientConnInterface) UserOpsClient {
+	return &userOpsClient{cc}
+}
+
+func (c *userOpsClient) Add(ctx context.Context, in *AddUserOpRequest, opts ...grpc.CallOption) (*AddUserOpReply, error) {
+	out := new(AddUserOpReply)
+	err := c.cc.Invoke(ctx, UserOps_Add_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userOpsClient) Pending(ctx context.Context, in *PendingUserOpsRequest, opts ...grpc.CallOption) (*PendingUserOpsReply, error) {
+	out := new(PendingUserOpsReply)
+	err := c.cc.Invoke(ctx, UserOps_Pending_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userOpsClient) Remove(ctx context.Context, in *TxHashes, opts ...grpc.CallOption) (*TxHashes, error) {
+	out := new(TxHashes)
+	err := c.cc.Invoke(ctx, UserOps_Remove_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userOpsClient) Reputation(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReputationReply, error) {
+	out := new(ReputationReply)
+	err := c.cc.Invoke(ctx, UserOps_Reputation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userOpsClient) OnAdd(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (UserOps_OnAddClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserOps_ServiceDesc.Streams[0], UserOps_OnAdd_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userOpsOnAddClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserOps_OnAddClient interface {
+	Recv() (*PoolUserOp, error)
+	grpc.ClientStream
+}
+
+type userOpsOnAddClient struct {
+	grpc.ClientStream
+}
+
+func (x *userOpsOnAddClient) Recv() (*PoolUserOp, error) {
+	m := new(PoolUserOp)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UserOpsServer is the server API for UserOps service.
+// All implementations must embed UnimplementedUserOpsServer
+// for forward compatibility
+type UserOpsServer interface {
+	// adds user operation to pool, returns its hash or reason of rejection
+	Add(context.Context, *AddUserOpRequest) (*AddUserOpReply, error)
+	// returns user operations of entry point, best first
+	Pending(context.Context, *PendingUserOpsRequest) (*PendingUserOpsReply, error)
+	// removes user operations from pool (e.g. included by bundler), returns hashes of removed ones
+	Remove(context.Context, *TxHashes) (*TxHashes, error)
+	// returns ERC-7562 reputation of entities
+	Reputation(context.Context, *emptypb.Empty) (*ReputationReply, error)
+	// subscribe to user operations added to pool
+	OnAdd(*emptypb.Empty, UserOps_OnAddServer) error
+	mustEmbedUnimplementedUserOpsServer()
+}
+
+// UnimplementedUserOpsServer must be embedded to have forward compatible implementations.
+type UnimplementedUserOpsServer struct {
+}
+
+func (UnimplementedUserOpsServer) Add(context.Context, *AddUserOpRequest) (*AddUserOpReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedUserOpsServer) Pending(context.Context, *PendingUserOpsRequest) (*PendingUserOpsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pending not implemented")
+}
+func (UnimplementedUserOpsServer) Remove(context.Context, *TxHashes) (*TxHashes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Remove not implemented")
+}
+func (UnimplementedUserOpsServer) Reputation(context.Context, *emptypb.Empty) (*ReputationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reputation not implemented")
+}
+func (UnimplementedUserOpsServer) OnAdd(*emptypb.Empty, UserOps_OnAddServer) error {
+	return status.Errorf(codes.Unimplemented, "method OnAdd not implemented")
+}
+func (UnimplementedUserOpsServer) mustEmbedUnimplementedUserOpsServer() {}
+
+// UnsafeUserOpsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserOpsServer will
+// result in compilation errors.
+type UnsafeUserOpsServer interface {
+	mustEmbedUnimplementedUserOpsServer()
+}
+
+func RegisterUserOpsServer(s grpc.ServiceRegistrar, srv UserOpsServer) {
+	s.RegisterService(&UserOps_ServiceDesc, srv)
+}
+
+func _UserOps_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddUserOpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserOpsServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserOps_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserOpsServer).Add(ctx, req.(*AddUserOpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserOps_Pending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PendingUserOpsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserOpsServer).Pending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserOps_Pending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserOpsServer).Pending(ctx, req.(*PendingUserOpsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserOps_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserOpsServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserOps_Remove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserOpsServer).Remove(ctx, req.(*TxHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserOps_Reputation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserOpsServer).Reputation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserOps_Reputation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserOpsServer).Reputation(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserOps_OnAdd_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserOpsServer).OnAdd(m, &userOpsOnAddServer{stream})
+}
+
+type UserOps_OnAddServer interface {
+	Send(*PoolUserOp) error
+	grpc.ServerStream
+}
+
+type userOpsOnAddServer struct {
+	grpc.ServerStream
+}
+
+func (x *userOpsOnAddServer) Send(m *PoolUserOp) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// UserOps_ServiceDesc is the grpc.ServiceDesc for UserOps service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UserOps_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "txpool.UserOps",
+	HandlerType: (*UserOpsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Add",
+			Handler:    _UserOps_Add_Handler,
+		},
+		{
+			MethodName: "Pending",
+			Handler:    _UserOps_Pending_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _UserOps_Remove_Handler,
+		},
+		{
+			MethodName: "Reputation",
+			Handler:    _UserOps_Reputation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "OnAdd",
+			Handler:       _UserOps_OnAdd_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "txpool/txpool.proto",
 }
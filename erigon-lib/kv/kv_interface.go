@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/ledgerwatch/erigon-lib/kv/iter"
@@ -143,6 +144,9 @@ var (
 	GcOverflowMetric = metrics.GetOrCreateGauge(`db_gc_overflow`) //nolint
 	GcPagesMetric    = metrics.GetOrCreateGauge(`db_gc_pages`)    //nolint
 
+	DbLongRoTxs  = metrics.GetOrCreateGauge(`db_long_ro_txs`)          //nolint
+	DbLongRoTxMs = metrics.GetOrCreateGauge(`db_longest_ro_tx_ms`)     //nolint
+	DbRoTxRenews = metrics.GetOrCreateCounter(`db_ro_tx_renews_total`) //nolint
 )
 
 type DBVerbosityLvl int8
@@ -585,3 +589,78 @@ type CanWarmupDB interface {
 	WarmupDB(force bool) error
 	LockDBInRam() error
 }
+
+// LongReadTx - read transaction which lives longer than watchdog threshold
+type LongReadTx struct {
+	ID       uint64        `json:"id"`
+	Owner    string        `json:"owner"` // RPC method or subsystem name, see WithTxOwner
+	Duration time.Duration `json:"duration"`
+	Renews   uint64        `json:"renews"` // how many times tx was re-opened at safe point
+}
+
+// HasLongReadTxs - db which tracks long-living read transactions
+type HasLongReadTxs interface {
+	LongReadTxs() []LongReadTx
+}
+
+// CanRenew - read transaction which can be re-opened to release old MDBX snapshot (and let GC reclaim free-list pages).
+// Must be called only at "safe point": when caller doesn't hold any open cursor/stream or values read by this tx.
+type CanRenew interface {
+	RenewIfLong() (renewed bool, err error)
+	IsLong() bool // lives longer than watchdog threshold
+}
+
+// TableStat - per-table storage stats
+type TableStat struct {
+	Name          string  `json:"name"`
+	Entries       uint64  `json:"entries"`
+	Size          uint64  `json:"size"` // bytes: (branch + leaf + overflow pages) * page size
+	Depth         uint64  `json:"depth"`
+	BranchPages   uint64  `json:"branchPages"`
+	LeafPages     uint64  `json:"leafPages"`
+	OverflowPages uint64  `json:"overflowPages"`
+	FillFactor    float64 `json:"fillFactor"` // estimated: ratio of payload bytes in leaf pages, 0..1. Based on sample of first entries.
+}
+
+// HasTableStats - db which can report per-table stats. Stats are cached and refreshed incrementally:
+// expensive parts (fill factor) are re-calculated only for tables modified since previous call.
+type HasTableStats interface {
+	TableStats(ctx context.Context) ([]TableStat, error)
+}
+
+// RenewAtSafePoint - for resumable iterators (which can restart from last seen key):
+// re-open `tx` if it lives longer than watchdog threshold. No-op for transactions which can't renew.
+func RenewAtSafePoint(tx Tx) (bool, error) {
+	if r, ok := tx.(CanRenew); ok {
+		return r.RenewIfLong()
+	}
+	return false, nil
+}
+
+// ErrLongReadTx - see CheckLongRead
+var ErrLongReadTx = errors.New("read transaction lives longer than watchdog threshold")
+
+// CheckLongRead - for queries which must see one consistent snapshot and can't re-open `tx` in the middle
+// (for example: one RPC response): returns ErrLongReadTx if `tx` lives longer than watchdog threshold.
+func CheckLongRead(tx Tx) error {
+	if r, ok := tx.(CanRenew); ok && r.IsLong() {
+		return ErrLongReadTx
+	}
+	return nil
+}
+
+type txOwnerKey struct{}
+
+// WithTxOwner - tag ctx by name of RPC method or subsystem. Transactions opened with this ctx
+// will be reported under this name by long-read transactions watchdog.
+func WithTxOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, txOwnerKey{}, owner)
+}
+
+func TxOwner(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	owner, _ := ctx.Value(txOwnerKey{}).(string)
+	return owner
+}
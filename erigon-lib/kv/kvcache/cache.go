@@ -32,6 +32,7 @@ import (
 	"golang.org/x/crypto/sha3"
 
 	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -55,6 +56,14 @@ type Cache interface {
 	Len() int
 	ValidateCurrentRoot(ctx context.Context, tx kv.Tx) (*CacheValidationResult, error)
 }
+
+// HasPins - cache which can keep state of hot addresses from eviction
+type HasPins interface {
+	Pin(addrs ...common.Address)
+	Unpin(addrs ...common.Address)
+	Pinned() []common.Address
+}
+
 type CacheView interface {
 	StateV3() bool
 	Get(k []byte) ([]byte, error)
@@ -100,20 +109,38 @@ type CacheView interface {
 // Rules of filling cache.stateEvict:
 //   - changes in Canonical View SHOULD reflect in stateEvict
 //   - changes in Non-Canonical View SHOULD NOT reflect in stateEvict
+//
+// Rules of pinning:
+//   - state keys of pinned addresses (account and its storage) not added to stateEvict - so they survive eviction
+//   - pinned keys have own PinnedCacheSize limit instead of CacheSize, keys over it go to stateEvict as usual
+//   - pinned keys of latestStateView are the ones out of stateEvict: pinnedSize/pinnedLen accounted only for it
+//   - pinned keys still reflected in per-view size accounting
 type Coherent struct {
 	hasher               hash.Hash
 	codeEvictLen         metrics.Gauge
 	codeKeys             metrics.Gauge
 	keys                 metrics.Gauge
 	evict                metrics.Gauge
+	size                 metrics.Gauge
+	codeSize             metrics.Gauge
+	viewSize             metrics.Gauge
+	pinnedAddrs          metrics.Gauge
+	pinnedKeys           metrics.Gauge
+	pinnedBytes          metrics.Gauge
 	latestStateView      *CoherentRoot
 	codeMiss             metrics.Counter
 	timeout              metrics.Counter
 	hits                 metrics.Counter
 	codeHits             metrics.Counter
+	evicted              metrics.Counter
+	codeEvicted          metrics.Counter
+	pinnedOverflow       metrics.Counter
 	roots                map[uint64]*CoherentRoot
 	stateEvict           *ThreadSafeEvictionList
 	codeEvict            *ThreadSafeEvictionList
+	pinned               map[common.Address]struct{}
+	pinnedSize           int
+	pinnedLen            int
 	miss                 metrics.Counter
 	cfg                  CoherentConfig
 	latestStateVersionID uint64
@@ -132,6 +159,8 @@ type CoherentRoot struct {
 	// keys added to `Non-Canonical` views SHOULD NOT be added to stateEvict
 	// cache.latestStateView is always `Canonical`
 	isCanonical bool
+
+	size int // bytes of keys/values in `cache` and `codeCache` of this view
 }
 
 // CoherentView - dumb object, which proxy all requests to Coherent object.
@@ -152,6 +181,7 @@ func (c *CoherentView) GetCode(k []byte) ([]byte, error) {
 
 var _ Cache = (*Coherent)(nil)         // compile-time interface check
 var _ CacheView = (*CoherentView)(nil) // compile-time interface check
+var _ HasPins = (*Coherent)(nil)       // compile-time interface check
 
 const (
 	DEGREE    = 32
@@ -167,6 +197,8 @@ type CoherentConfig struct {
 	NewBlockWait    time.Duration // how long wait
 	KeepViews       uint64        // keep in memory up to this amount of views, evict older
 	StateV3         bool
+	PinnedAddresses []common.Address  // state of this addresses (account and storage) never evicted
+	PinnedCacheSize datasize.ByteSize // limit of pinned state, keys over it are evicted as usual
 }
 
 var DefaultCoherentConfig = CoherentConfig{
@@ -174,6 +206,7 @@ var DefaultCoherentConfig = CoherentConfig{
 	NewBlockWait:    5 * time.Millisecond,
 	CacheSize:       2 * datasize.GB,
 	CodeCacheSize:   2 * datasize.GB,
+	PinnedCacheSize: 256 * datasize.MB,
 	MetricsLabel:    "default",
 	WithStorage:     true,
 	WaitForNewBlock: true,
@@ -185,22 +218,130 @@ func New(cfg CoherentConfig) *Coherent {
 		panic("empty config passed")
 	}
 
-	return &Coherent{
-		roots:        map[uint64]*CoherentRoot{},
-		stateEvict:   &ThreadSafeEvictionList{l: NewList()},
-		codeEvict:    &ThreadSafeEvictionList{l: NewList()},
-		hasher:       sha3.NewLegacyKeccak256(),
-		cfg:          cfg,
-		miss:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
-		hits:         metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
-		timeout:      metrics.GetOrCreateCounter(fmt.Sprintf(`cache_timeout_total{name="%s"}`, cfg.MetricsLabel)),
-		keys:         metrics.GetOrCreateGauge(fmt.Sprintf(`cache_keys_total{name="%s"}`, cfg.MetricsLabel)),
-		evict:        metrics.GetOrCreateGauge(fmt.Sprintf(`cache_list_total{name="%s"}`, cfg.MetricsLabel)),
-		codeMiss:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
-		codeHits:     metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
-		codeKeys:     metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_keys_total{name="%s"}`, cfg.MetricsLabel)),
-		codeEvictLen: metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_list_total{name="%s"}`, cfg.MetricsLabel)),
+	c := &Coherent{
+		roots:          map[uint64]*CoherentRoot{},
+		stateEvict:     &ThreadSafeEvictionList{l: NewList()},
+		codeEvict:      &ThreadSafeEvictionList{l: NewList()},
+		pinned:         map[common.Address]struct{}{},
+		hasher:         sha3.NewLegacyKeccak256(),
+		cfg:            cfg,
+		miss:           metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
+		hits:           metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
+		evicted:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_total{result="evict",name="%s"}`, cfg.MetricsLabel)),
+		timeout:        metrics.GetOrCreateCounter(fmt.Sprintf(`cache_timeout_total{name="%s"}`, cfg.MetricsLabel)),
+		keys:           metrics.GetOrCreateGauge(fmt.Sprintf(`cache_keys_total{name="%s"}`, cfg.MetricsLabel)),
+		evict:          metrics.GetOrCreateGauge(fmt.Sprintf(`cache_list_total{name="%s"}`, cfg.MetricsLabel)),
+		size:           metrics.GetOrCreateGauge(fmt.Sprintf(`cache_list_bytes{name="%s"}`, cfg.MetricsLabel)),
+		viewSize:       metrics.GetOrCreateGauge(fmt.Sprintf(`cache_view_bytes{name="%s"}`, cfg.MetricsLabel)),
+		pinnedAddrs:    metrics.GetOrCreateGauge(fmt.Sprintf(`cache_pinned_addresses{name="%s"}`, cfg.MetricsLabel)),
+		pinnedKeys:     metrics.GetOrCreateGauge(fmt.Sprintf(`cache_pinned_keys_total{name="%s"}`, cfg.MetricsLabel)),
+		pinnedBytes:    metrics.GetOrCreateGauge(fmt.Sprintf(`cache_pinned_bytes{name="%s"}`, cfg.MetricsLabel)),
+		pinnedOverflow: metrics.GetOrCreateCounter(fmt.Sprintf(`cache_pinned_total{result="overflow",name="%s"}`, cfg.MetricsLabel)),
+		codeMiss:       metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="miss",name="%s"}`, cfg.MetricsLabel)),
+		codeHits:       metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="hit",name="%s"}`, cfg.MetricsLabel)),
+		codeEvicted:    metrics.GetOrCreateCounter(fmt.Sprintf(`cache_code_total{result="evict",name="%s"}`, cfg.MetricsLabel)),
+		codeKeys:       metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_keys_total{name="%s"}`, cfg.MetricsLabel)),
+		codeEvictLen:   metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_list_total{name="%s"}`, cfg.MetricsLabel)),
+		codeSize:       metrics.GetOrCreateGauge(fmt.Sprintf(`cache_code_list_bytes{name="%s"}`, cfg.MetricsLabel)),
+	}
+	c.Pin(cfg.PinnedAddresses...)
+	return c
+}
+
+// Pin - state of given addresses (account and storage) will survive eviction. Useful for hot contracts (big DEX, etc...)
+func (c *Coherent) Pin(addrs ...common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, addr := range addrs {
+		c.pinned[addr] = struct{}{}
+		if c.latestStateView == nil {
+			continue
+		}
+		c.walkAddr(c.latestStateView, addr, func(e *Element) {
+			if e.list != nil && c.holdPinned(e) {
+				c.stateEvict.Remove(e)
+			}
+		})
+	}
+	c.updatePinnedMetrics()
+}
+
+// Unpin - return state of given addresses under usual eviction policy
+func (c *Coherent) Unpin(addrs ...common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, addr := range addrs {
+		if _, ok := c.pinned[addr]; !ok {
+			continue
+		}
+		delete(c.pinned, addr)
+		if c.latestStateView == nil {
+			continue
+		}
+		c.walkAddr(c.latestStateView, addr, func(e *Element) {
+			if e.list == nil {
+				c.releasePinned(e)
+				c.stateEvict.PushFront(e)
+			}
+		})
+		c.evictOverLimit(c.latestStateView)
+	}
+	c.updatePinnedMetrics()
+}
+
+func (c *Coherent) Pinned() []common.Address {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	res := make([]common.Address, 0, len(c.pinned))
+	for addr := range c.pinned {
+		res = append(res, addr)
+	}
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i][:], res[j][:]) < 0 })
+	return res
+}
+
+func (c *Coherent) isPinned(k []byte) bool {
+	if len(c.pinned) == 0 || len(k) < length.Addr {
+		return false
+	}
+	_, ok := c.pinned[common.BytesToAddress(k[:length.Addr])]
+	return ok
+}
+
+// holdPinned - keeps key of pinned address out of stateEvict, if it fits PinnedCacheSize
+func (c *Coherent) holdPinned(e *Element) bool {
+	if !c.isPinned(e.K) {
+		return false
 	}
+	if c.pinnedSize+e.Size() > int(c.cfg.PinnedCacheSize.Bytes()) {
+		c.pinnedOverflow.Inc()
+		return false
+	}
+	c.pinnedSize += e.Size()
+	c.pinnedLen++
+	return true
+}
+
+func (c *Coherent) releasePinned(e *Element) {
+	c.pinnedSize -= e.Size()
+	c.pinnedLen--
+}
+
+func (c *Coherent) updatePinnedMetrics() {
+	c.pinnedAddrs.SetInt(len(c.pinned))
+	c.pinnedKeys.SetInt(c.pinnedLen)
+	c.pinnedBytes.SetInt(c.pinnedSize)
+}
+
+// walkAddr - visit account and storage keys of `addr` in state cache of view `r`
+func (c *Coherent) walkAddr(r *CoherentRoot, addr common.Address, f func(e *Element)) {
+	r.cache.Ascend(&Element{K: addr[:]}, func(e *Element) bool {
+		if !bytes.HasPrefix(e.K, addr[:]) {
+			return false
+		}
+		f(e)
+		return true
+	})
 }
 
 // selectOrCreateRoot - used for usual getting root
@@ -239,9 +380,11 @@ func (c *Coherent) advanceRoot(stateVersionID uint64) (r *CoherentRoot) {
 		//log.Info("advance: clone", "from", viewID-1, "to", viewID)
 		r.cache = prevView.cache.Copy()
 		r.codeCache = prevView.codeCache.Copy()
+		r.size = prevView.size
 	} else {
 		c.stateEvict.Init()
 		c.codeEvict.Init()
+		c.pinnedSize, c.pinnedLen = 0, 0
 		if r.cache == nil {
 			//log.Info("advance: new", "to", viewID)
 			r.cache = btree2.NewBTreeG[*Element](Less)
@@ -249,6 +392,9 @@ func (c *Coherent) advanceRoot(stateVersionID uint64) (r *CoherentRoot) {
 		} else {
 			r.cache.Walk(func(items []*Element) bool {
 				for _, i := range items {
+					if c.holdPinned(i) {
+						continue
+					}
 					c.stateEvict.PushFront(i)
 				}
 				return true
@@ -271,6 +417,10 @@ func (c *Coherent) advanceRoot(stateVersionID uint64) (r *CoherentRoot) {
 	c.codeKeys.SetInt(c.latestStateView.codeCache.Len())
 	c.evict.SetInt(c.stateEvict.Len())
 	c.codeEvictLen.SetInt(c.codeEvict.Len())
+	c.size.SetInt(c.stateEvict.Size())
+	c.codeSize.SetInt(c.codeEvict.Size())
+	c.viewSize.SetInt(r.size)
+	c.updatePinnedMetrics()
 	return r
 }
 
@@ -457,38 +607,61 @@ func (c *Coherent) removeOldest(r *CoherentRoot) {
 	e := c.stateEvict.Oldest()
 	if e != nil {
 		c.stateEvict.Remove(e)
-		r.cache.Delete(e)
+		if _, ok := r.cache.Delete(e); ok {
+			r.size -= e.Size()
+		}
+		c.evicted.Inc()
 	}
 }
 func (c *Coherent) removeOldestCode(r *CoherentRoot) {
 	e := c.codeEvict.Oldest()
 	if e != nil {
 		c.codeEvict.Remove(e)
-		r.codeCache.Delete(e)
+		if _, ok := r.codeCache.Delete(e); ok {
+			r.size -= e.Size()
+		}
+		c.codeEvicted.Inc()
+	}
+}
+
+// evictOverLimit - clear down cache until size below the configured limit. pinned keys are not in evict list: they have own limit.
+func (c *Coherent) evictOverLimit(r *CoherentRoot) {
+	for c.stateEvict.Size() > int(c.cfg.CacheSize.Bytes()) && c.stateEvict.Len() > 0 {
+		c.removeOldest(r)
 	}
 }
 func (c *Coherent) add(k, v []byte, r *CoherentRoot, id uint64) *Element {
 	it := &Element{K: k, V: v}
 	replaced, _ := r.cache.Set(it)
+	if replaced != nil {
+		r.size -= replaced.Size()
+	}
+	r.size += it.Size()
 	if c.latestStateVersionID != id {
 		//fmt.Printf("add to non-last viewID: %d<%d\n", c.latestViewID, id)
 		return it
 	}
 	if replaced != nil {
-		c.stateEvict.Remove(replaced)
+		if replaced.list == nil && c.isPinned(k) {
+			c.releasePinned(replaced)
+		} else {
+			c.stateEvict.Remove(replaced)
+		}
 	}
-	c.stateEvict.PushFront(it)
-
-	// clear down cache until size below the configured limit
-	for c.stateEvict.Size() > int(c.cfg.CacheSize.Bytes()) {
-		c.removeOldest(r)
+	if c.holdPinned(it) {
+		return it
 	}
-
+	c.stateEvict.PushFront(it)
+	c.evictOverLimit(r)
 	return it
 }
 func (c *Coherent) addCode(k, v []byte, r *CoherentRoot, id uint64) *Element {
 	it := &Element{K: k, V: v}
 	replaced, _ := r.codeCache.Set(it)
+	if replaced != nil {
+		r.size -= replaced.Size()
+	}
+	r.size += it.Size()
 	if c.latestStateVersionID != id {
 		//fmt.Printf("add to non-last viewID: %d<%d\n", c.latestViewID, id)
 		return it
@@ -624,12 +797,14 @@ func (c *Coherent) clearCaches(r *CoherentRoot) {
 	defer c.lock.Unlock()
 	r.cache.Clear()
 	r.codeCache.Clear()
+	r.size = 0
 }
 
 type Stat struct {
 	BlockNum  uint64
 	BlockHash [32]byte
 	Lenght    int
+	Size      int // bytes of keys/values in state and code caches of this view
 }
 
 func DebugStats(cache Cache) []Stat {
@@ -643,6 +818,7 @@ func DebugStats(cache Cache) []Stat {
 		res = append(res, Stat{
 			BlockNum: root,
 			Lenght:   r.cache.Len(),
+			Size:     r.size,
 		})
 	}
 	casted.lock.Unlock()
@@ -377,3 +377,81 @@ func TestCode(t *testing.T) {
 		return nil
 	})
 }
+
+func TestPinning(t *testing.T) {
+	require := require.New(t)
+	cfg := DefaultCoherentConfig
+	cfg.CacheSize = 21 * 2
+	cfg.NewBlockWait = 0
+	hot, cold := common.Address{1}, common.Address{2}
+	cfg.PinnedAddresses = []common.Address{hot}
+	c := New(cfg)
+	r := c.advanceRoot(1)
+
+	hotStorage := append(append(hot[:0:0], hot[:]...), make([]byte, 8+32)...)
+	c.add(hot[:], []byte{1}, r, 1)
+	c.add(hotStorage, []byte{1}, r, 1)
+	require.Equal(0, c.stateEvict.Len())
+	require.Equal(21+61, r.size)
+
+	for i := byte(0); i < 10; i++ {
+		k := common.Address{2, i}
+		c.add(k[:], []byte{1}, r, 1)
+	}
+	require.Equal(2, c.stateEvict.Len())
+	_, ok := r.cache.Get(&Element{K: hot[:]})
+	require.True(ok)
+	_, ok = r.cache.Get(&Element{K: hotStorage})
+	require.True(ok)
+	require.Equal(21*2+21+61, r.size)
+
+	c.Unpin(hot) // unpinned keys are bigger than limit
+	require.Equal(0, c.stateEvict.Len())
+	_, ok = r.cache.Get(&Element{K: hot[:]})
+	require.False(ok)
+	require.Empty(c.Pinned())
+
+	c.Pin(cold)
+	require.Equal([]common.Address{cold}, c.Pinned())
+	stats := DebugStats(c)
+	require.Equal(r.size, stats[0].Size)
+}
+
+func TestPinnedCacheSize(t *testing.T) {
+	require := require.New(t)
+	cfg := DefaultCoherentConfig
+	cfg.CacheSize = 1000
+	cfg.PinnedCacheSize = 21 + 61
+	cfg.NewBlockWait = 0
+	hot := common.Address{1}
+	cfg.PinnedAddresses = []common.Address{hot}
+	c := New(cfg)
+	r := c.advanceRoot(1)
+
+	// pinned keys over own limit are evicted as usual
+	for i := byte(0); i < 3; i++ {
+		k := append(append(hot[:0:0], hot[:]...), make([]byte, 8+32)...)
+		k[len(k)-1] = i
+		c.add(k, []byte{1}, r, 1)
+	}
+	require.Equal(61, c.pinnedSize)
+	require.Equal(1, c.pinnedLen)
+	require.Equal(2, c.stateEvict.Len())
+	c.add(hot[:], []byte{1}, r, 1)
+	require.Equal(61+21, c.pinnedSize)
+	require.Equal(2, c.stateEvict.Len())
+
+	// replaced pinned key doesn't leak its size
+	c.add(hot[:], []byte{2}, r, 1)
+	require.Equal(61+21, c.pinnedSize)
+	require.Equal(2, c.pinnedLen)
+
+	c.Unpin(hot)
+	require.Zero(c.pinnedSize)
+	require.Zero(c.pinnedLen)
+	require.Equal(4, c.stateEvict.Len())
+
+	c.Pin(hot)
+	require.Equal(21+61, c.pinnedSize)
+	require.Equal(2, c.stateEvict.Len())
+}
@@ -41,6 +41,8 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
+	"github.com/ledgerwatch/erigon-lib/diagnostics"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/iter"
 	"github.com/ledgerwatch/erigon-lib/kv/order"
@@ -73,6 +75,7 @@ type MdbxOpts struct {
 	verbosity       kv.DBVerbosityLvl
 	label           kv.Label // marker to distinct db instances - one process may open many databases. for example to collect metrics of only 1 database
 	inMem           bool
+	roTxWatchdog    time.Duration // read transactions living longer than this are reported and can be renewed at safe point. 0 - disabled
 }
 
 const DefaultMapSize = 2 * datasize.TB
@@ -202,6 +205,13 @@ func (opts MdbxOpts) WriteMergeThreshold(v uint64) MdbxOpts {
 	return opts
 }
 
+// RoTxWatchdog - report read transactions living longer than `threshold` (logs, metrics, kv.HasLongReadTxs)
+// and allow them to re-open at safe point (kv.RenewAtSafePoint). 0 - disabled
+func (opts MdbxOpts) RoTxWatchdog(threshold time.Duration) MdbxOpts {
+	opts.roTxWatchdog = threshold
+	return opts
+}
+
 func (opts MdbxOpts) WithTableCfg(f TableCfgFunc) MdbxOpts {
 	opts.bucketsCfg = f
 	return opts
@@ -358,6 +368,7 @@ func (opts MdbxOpts) Open(ctx context.Context) (kv.RwDB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%w, label: %s, trace: %s", err, opts.label.String(), stack2.Trace().String())
 	}
+	disk.Tag(opts.path, disk.MDBX)
 
 	// mdbx will not change pageSize if db already exists. means need read real value after env.open()
 	in, err := env.Info(nil)
@@ -407,6 +418,7 @@ func (opts MdbxOpts) Open(ctx context.Context) (kv.RwDB, error) {
 		txsAllDoneOnCloseCond: sync.NewCond(txsCountMutex),
 
 		leakDetector: dbg.NewLeakDetector("db."+opts.label.String(), dbg.SlowTx()),
+		roTxWatchdog: newRoTxWatchdog(opts.label, opts.roTxWatchdog, opts.log),
 
 		MaxBatchSize:  DefaultMaxBatchSize,
 		MaxBatchDelay: DefaultMaxBatchDelay,
@@ -458,6 +470,7 @@ func (opts MdbxOpts) Open(ctx context.Context) (kv.RwDB, error) {
 	}
 	db.path = opts.path
 	addToPathDbMap(opts.path, db)
+	go db.roTxWatchdog.loop()
 	if dbg.MdbxLockInRam() && opts.label == kv.ChainDB {
 		log.Info("[dbg] locking db in mem", "lable", opts.label)
 		if err := db.View(ctx, func(tx kv.Tx) error { return tx.(*MdbxTx).LockDBInRam() }); err != nil {
@@ -490,6 +503,8 @@ type MdbxKV struct {
 	txsAllDoneOnCloseCond *sync.Cond
 
 	leakDetector *dbg.LeakDetector
+	roTxWatchdog *roTxWatchdog
+	tableStats   tableStatsCache
 
 	// MaxBatchSize is the maximum size of a batch. Default value is
 	// copied from DefaultMaxBatchSize in Open.
@@ -730,6 +745,7 @@ func (db *MdbxKV) Close() {
 		return
 	}
 	db.waitTxsAllDoneOnClose()
+	db.roTxWatchdog.close()
 
 	db.env.Close()
 	db.env = nil
@@ -775,12 +791,15 @@ func (db *MdbxKV) BeginRo(ctx context.Context) (txn kv.Tx, err error) {
 		return nil, fmt.Errorf("%w, label: %s, trace: %s", err, db.opts.label.String(), stack2.Trace().String())
 	}
 
+	watchdogID, watchdogItem := db.roTxWatchdog.add(kv.TxOwner(ctx))
 	return &MdbxTx{
-		ctx:      ctx,
-		db:       db,
-		tx:       tx,
-		readOnly: true,
-		id:       db.leakDetector.Add(),
+		ctx:          ctx,
+		db:           db,
+		tx:           tx,
+		readOnly:     true,
+		id:           db.leakDetector.Add(),
+		watchdogID:   watchdogID,
+		watchdogItem: watchdogItem,
 	}, nil
 }
 
@@ -831,6 +850,9 @@ type MdbxTx struct {
 
 	streams  map[int]kv.Closer
 	streamID int
+
+	watchdogID   uint64 // set only for read transactions if watchdog enabled
+	watchdogItem *roTxWatchdogItem
 }
 
 type MdbxCursor struct {
@@ -902,9 +924,26 @@ func (tx *MdbxTx) CollectMetrics() {
 	if err != nil {
 		return
 	}
+	gcPages := (gc.LeafPages + gc.OverflowPages) * tx.db.opts.pageSize / 8
 	kv.GcLeafMetric.SetUint64(gc.LeafPages)
 	kv.GcOverflowMetric.SetUint64(gc.OverflowPages)
-	kv.GcPagesMetric.SetUint64((gc.LeafPages + gc.OverflowPages) * tx.db.opts.pageSize / 8)
+	kv.GcPagesMetric.SetUint64(gcPages)
+
+	diagnostics.Send(diagnostics.MdbxStats{
+		Size:         info.Geo.Current,
+		PgopsNewly:   info.PageOps.Newly,
+		PgopsCow:     info.PageOps.Cow,
+		PgopsClone:   info.PageOps.Clone,
+		PgopsSplit:   info.PageOps.Split,
+		PgopsMerge:   info.PageOps.Merge,
+		PgopsSpill:   info.PageOps.Spill,
+		PgopsUnspill: info.PageOps.Unspill,
+		PgopsWops:    info.PageOps.Wops,
+		TxDirty:      txInfo.SpaceDirty,
+		TxLimit:      tx.db.txSize,
+		GcPages:      gcPages,
+		Timestamp:    time.Now(),
+	})
 }
 
 // ListBuckets - all buckets stored as keys of un-named bucket
@@ -1066,6 +1105,7 @@ func (tx *MdbxTx) Commit() error {
 		tx.db.trackTxEnd()
 		if tx.readOnly {
 			tx.db.roTxsLimiter.Release(1)
+			tx.db.roTxWatchdog.del(tx.watchdogID)
 		} else {
 			runtime.UnlockOSThread()
 		}
@@ -1117,6 +1157,7 @@ func (tx *MdbxTx) Rollback() {
 		tx.db.trackTxEnd()
 		if tx.readOnly {
 			tx.db.roTxsLimiter.Release(1)
+			tx.db.roTxWatchdog.del(tx.watchdogID)
 		} else {
 			runtime.UnlockOSThread()
 		}
@@ -1127,6 +1168,32 @@ func (tx *MdbxTx) Rollback() {
 	tx.tx.Abort()
 }
 
+// LongReadTxs - read transactions living longer than watchdog threshold, sorted by duration desc
+func (db *MdbxKV) LongReadTxs() []kv.LongReadTx { return db.roTxWatchdog.longList() }
+
+// RenewIfLong - implements kv.CanRenew: if read transaction lives longer than watchdog threshold - re-open it on latest snapshot.
+// Returns false if watchdog disabled, tx is young or user still has open cursors/streams (not a safe point).
+func (tx *MdbxTx) RenewIfLong() (renewed bool, err error) {
+	if !tx.IsLong() {
+		return false, nil
+	}
+	if len(tx.streams) > 0 || len(tx.cursors) > len(tx.statelessCursors) {
+		return false, nil
+	}
+	tx.closeCursors()
+	tx.tx.Reset()
+	if err = tx.tx.Renew(); err != nil {
+		return false, fmt.Errorf("label: %s, renew: %w", tx.db.opts.label, err)
+	}
+	tx.db.roTxWatchdog.renewed(tx.watchdogItem)
+	return true, nil
+}
+
+// IsLong - implements kv.CanRenew: read transaction lives longer than watchdog threshold. False if watchdog disabled.
+func (tx *MdbxTx) IsLong() bool {
+	return tx.readOnly && tx.tx != nil && tx.db.roTxWatchdog.isLong(tx.watchdogItem)
+}
+
 func (tx *MdbxTx) SpaceDirty() (uint64, uint64, error) {
 	txInfo, err := tx.tx.Info(true)
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1091,3 +1092,92 @@ func TestDB_BatchTime(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestRoTxWatchdog(t *testing.T) {
+	logger := log.New()
+	table := "Table"
+	db := NewMDBX(logger).InMem(t.TempDir()).WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.TableCfg{table: kv.TableCfgItem{}}
+	}).MapSize(128 * datasize.MB).RoTxWatchdog(time.Millisecond).MustOpen()
+	t.Cleanup(db.Close)
+	ctx := context.Background()
+
+	tx, err := db.BeginRo(kv.WithTxOwner(ctx, "eth_getLogs"))
+	require.NoError(t, err)
+	defer tx.Rollback()
+	time.Sleep(5 * time.Millisecond)
+
+	list := db.(kv.HasLongReadTxs).LongReadTxs()
+	require.Len(t, list, 1)
+	require.Equal(t, "eth_getLogs", list[0].Owner)
+
+	require.NoError(t, db.Update(ctx, func(rwTx kv.RwTx) error { return rwTx.Put(table, []byte("k"), []byte("v")) }))
+	v, err := tx.GetOne(table, []byte("k"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	require.ErrorIs(t, kv.CheckLongRead(tx), kv.ErrLongReadTx)
+
+	// open cursor - not a safe point
+	c, err := tx.Cursor(table)
+	require.NoError(t, err)
+	renewed, err := kv.RenewAtSafePoint(tx)
+	require.NoError(t, err)
+	require.False(t, renewed)
+	c.Close()
+
+	renewed, err = kv.RenewAtSafePoint(tx)
+	require.NoError(t, err)
+	require.True(t, renewed)
+	v, err = tx.GetOne(table, []byte("k"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v"), v)
+	require.NoError(t, kv.CheckLongRead(tx))
+	time.Sleep(5 * time.Millisecond)
+	require.Equal(t, uint64(1), db.(kv.HasLongReadTxs).LongReadTxs()[0].Renews)
+
+	tx.Rollback()
+	require.Empty(t, db.(kv.HasLongReadTxs).LongReadTxs())
+}
+
+func TestTableStats(t *testing.T) {
+	logger := log.New()
+	table, empty := "Table", "Empty"
+	db := NewMDBX(logger).InMem(t.TempDir()).WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		return kv.TableCfg{table: kv.TableCfgItem{}, empty: kv.TableCfgItem{}}
+	}).MapSize(128 * datasize.MB).MustOpen()
+	t.Cleanup(db.Close)
+	ctx := context.Background()
+
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		for i := 0; i < 1000; i++ {
+			if err := tx.Put(table, []byte(fmt.Sprintf("key%05d", i)), make([]byte, 32)); err != nil {
+				return err
+			}
+		}
+		return tx.Put(table, []byte("big"), make([]byte, 64*1024))
+	}))
+
+	stats, err := db.(kv.HasTableStats).TableStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, table, stats[0].Name) // sorted by size
+	require.Equal(t, uint64(1001), stats[0].Entries)
+	require.Positive(t, stats[0].OverflowPages)
+	require.Positive(t, stats[0].FillFactor)
+	require.LessOrEqual(t, stats[0].FillFactor, 1.0)
+	require.Equal(t, empty, stats[1].Name)
+	require.Zero(t, stats[1].Entries)
+
+	// not modified - same result
+	again, err := db.(kv.HasTableStats).TableStats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, stats, again)
+
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error { return tx.Put(empty, []byte("k"), []byte("v")) }))
+	again, err = db.(kv.HasTableStats).TableStats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, stats[0], again[0])
+	require.Equal(t, uint64(1), again[1].Entries)
+	require.Positive(t, again[1].FillFactor)
+}
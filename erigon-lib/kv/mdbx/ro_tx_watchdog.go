@@ -0,0 +1,160 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mdbx
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// roTxWatchdog - tracks living read transactions. Long read transactions hold old MDBX snapshot:
+// free-list can't be reclaimed and writers have to grow file (or stall).
+// Watchdog doesn't abort anything - it only exposes offenders (logs, metrics, LongReadTxs) and allows
+// resumable iterators to re-open their tx at safe point (see kv.RenewAtSafePoint).
+type roTxWatchdog struct {
+	threshold time.Duration
+	label     kv.Label
+	logger    log.Logger
+
+	autoIncrement atomic.Uint64
+	list          map[uint64]*roTxWatchdogItem
+	lock          sync.Mutex
+
+	quit chan struct{}
+}
+
+type roTxWatchdogItem struct {
+	owner   string
+	started atomic.Int64 // unix nano, updated on renew
+	renews  atomic.Uint64
+}
+
+func newRoTxWatchdog(label kv.Label, threshold time.Duration, logger log.Logger) *roTxWatchdog {
+	if threshold <= 0 {
+		return nil
+	}
+	return &roTxWatchdog{label: label, threshold: threshold, logger: logger, list: map[uint64]*roTxWatchdogItem{}, quit: make(chan struct{})}
+}
+
+func (w *roTxWatchdog) add(owner string) (uint64, *roTxWatchdogItem) {
+	if w == nil {
+		return 0, nil
+	}
+	item := &roTxWatchdogItem{owner: owner}
+	item.started.Store(time.Now().UnixNano())
+	id := w.autoIncrement.Add(1)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.list[id] = item
+	return id, item
+}
+
+func (w *roTxWatchdog) del(id uint64) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.list, id)
+}
+
+func (w *roTxWatchdog) isLong(item *roTxWatchdogItem) bool {
+	if w == nil || item == nil {
+		return false
+	}
+	return time.Since(time.Unix(0, item.started.Load())) > w.threshold
+}
+
+func (w *roTxWatchdog) renewed(item *roTxWatchdogItem) {
+	item.started.Store(time.Now().UnixNano())
+	item.renews.Add(1)
+	if w.label == kv.ChainDB {
+		kv.DbRoTxRenews.Inc()
+	}
+}
+
+// longList - sorted by duration desc
+func (w *roTxWatchdog) longList() (res []kv.LongReadTx) {
+	if w == nil {
+		return nil
+	}
+	now := time.Now()
+	w.lock.Lock()
+	for id, item := range w.list {
+		living := now.Sub(time.Unix(0, item.started.Load()))
+		if living <= w.threshold {
+			continue
+		}
+		res = append(res, kv.LongReadTx{ID: id, Owner: item.owner, Duration: living, Renews: item.renews.Load()})
+	}
+	w.lock.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Duration > res[j].Duration })
+	return res
+}
+
+func (w *roTxWatchdog) close() {
+	if w == nil {
+		return
+	}
+	close(w.quit)
+}
+
+func (w *roTxWatchdog) loop() {
+	if w == nil {
+		return
+	}
+	logEvery := time.NewTicker(w.threshold)
+	defer logEvery.Stop()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-logEvery.C:
+		}
+		list := w.longList()
+		if w.label == kv.ChainDB {
+			kv.DbLongRoTxs.SetInt(len(list))
+			if len(list) > 0 {
+				kv.DbLongRoTxMs.SetInt(int(list[0].Duration.Milliseconds()))
+			} else {
+				kv.DbLongRoTxMs.SetInt(0)
+			}
+		}
+		if len(list) == 0 {
+			continue
+		}
+		if len(list) > 10 { // protect logs from too many output
+			list = list[:10]
+		}
+		args := make([]interface{}, 0, 2+len(list)*2)
+		args = append(args, "label", w.label.String())
+		for _, tx := range list {
+			owner := tx.Owner
+			if owner == "" {
+				owner = "unknown"
+			}
+			args = append(args, owner, tx.Duration.Truncate(time.Second).String())
+		}
+		w.logger.Warn("[db] long read transactions", args...)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mdbx
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// fillFactorSampleSize - amount of first entries of table used to estimate avg node size
+const fillFactorSampleSize = 1024
+
+// mdbx node header: 2*uint16 sizes + flags + extra + data size (see mdbx "node" struct)
+const mdbxNodeHeaderSize = 8
+
+// tableStatsCache - stats of each table, keyed by table name. Fill factor is re-calculated only if table's
+// entries/pages counters changed - so periodic calls on big db are cheap.
+type tableStatsCache struct {
+	lock sync.Mutex
+	list map[string]kv.TableStat
+}
+
+// TableStats - implements kv.HasTableStats
+func (db *MdbxKV) TableStats(ctx context.Context) (res []kv.TableStat, err error) {
+	db.tableStats.lock.Lock()
+	defer db.tableStats.lock.Unlock()
+	if db.tableStats.list == nil {
+		db.tableStats.list = map[string]kv.TableStat{}
+	}
+
+	if err = db.View(ctx, func(tx kv.Tx) error {
+		mtx := tx.(*MdbxTx)
+		for name, cfg := range db.buckets {
+			if cfg.IsDeprecated || cfg.DBI == NonExistingDBI {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			st, err := mtx.tableStat(name, db.tableStats.list[name])
+			if err != nil {
+				return err
+			}
+			db.tableStats.list[name] = st
+			res = append(res, st)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Size > res[j].Size })
+	return res, nil
+}
+
+// tableStat - `prev` is result of previous call, re-used if table was not modified since
+func (tx *MdbxTx) tableStat(name string, prev kv.TableStat) (kv.TableStat, error) {
+	st, err := tx.BucketStat(name)
+	if err != nil {
+		return prev, err
+	}
+	if prev.Name == name && prev.Entries == st.Entries && prev.LeafPages == st.LeafPages &&
+		prev.BranchPages == st.BranchPages && prev.OverflowPages == st.OverflowPages {
+		return prev, nil
+	}
+	pageSize := tx.db.opts.pageSize
+	res := kv.TableStat{
+		Name:          name,
+		Entries:       st.Entries,
+		Size:          (st.LeafPages + st.BranchPages + st.OverflowPages) * pageSize,
+		Depth:         uint64(st.Depth),
+		BranchPages:   st.BranchPages,
+		LeafPages:     st.LeafPages,
+		OverflowPages: st.OverflowPages,
+	}
+	if st.Entries == 0 || st.LeafPages == 0 {
+		return res, nil
+	}
+	avgNodeSize, err := tx.sampleNodeSize(name, pageSize)
+	if err != nil {
+		return res, err
+	}
+	res.FillFactor = avgNodeSize * float64(st.Entries) / float64(st.LeafPages*pageSize)
+	if res.FillFactor > 1 {
+		res.FillFactor = 1
+	}
+	return res, nil
+}
+
+// sampleNodeSize - avg size of leaf-page node of first entries. Big values are stored in overflow pages -
+// leaf page has only key and pointer to them.
+func (tx *MdbxTx) sampleNodeSize(name string, pageSize uint64) (float64, error) {
+	c, err := tx.Cursor(name)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	var total, n uint64
+	for k, v, err := c.First(); k != nil && n < fillFactorSampleSize; k, v, err = c.Next() {
+		if err != nil {
+			return 0, err
+		}
+		nodeSize := uint64(mdbxNodeHeaderSize + len(k) + len(v))
+		if nodeSize > pageSize/2 {
+			nodeSize = uint64(mdbxNodeHeaderSize + len(k) + 8) // value moved to overflow pages
+		}
+		total += nodeSize
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return float64(total) / float64(n), nil
+}
@@ -1,6 +1,11 @@
 package membatchwithdb
 
-import "github.com/ledgerwatch/erigon-lib/kv"
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
 
 type entry struct {
 	k []byte
@@ -56,3 +61,75 @@ func (m *MemoryDiff) Flush(tx kv.RwTx) error {
 	}
 	return nil
 }
+
+// TableChanges - summary of changes of table in batch, for reporting without applying them
+type TableChanges struct {
+	Table   string
+	Cleared bool
+	Put     int    // amount of written entries
+	PutSize uint64 // size of keys and values of written entries
+	Deleted int    // amount of deleted keys and key-value pairs of dupsort tables
+}
+
+// Changes - summary of changes of batch per table, sorted by table name
+func (m *MemoryMutation) Changes() ([]TableChanges, error) {
+	changes := map[string]*TableChanges{}
+	get := func(table string) *TableChanges {
+		c, ok := changes[table]
+		if !ok {
+			c = &TableChanges{Table: table}
+			changes[table] = c
+		}
+		return c
+	}
+	for table := range m.clearedTables {
+		get(table).Cleared = true
+	}
+	for table, keys := range m.deletedEntries {
+		get(table).Deleted += len(keys)
+	}
+	for table, keys := range m.deletedDups {
+		for _, values := range keys {
+			get(table).Deleted += len(values)
+		}
+	}
+
+	tables, err := m.memTx.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if err := func() error {
+			c, err := m.memTx.Cursor(table)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			for k, v, err := c.First(); k != nil; k, v, err = c.Next() {
+				if err != nil {
+					return err
+				}
+				if table == kv.Sequence { // all sequences are copied to batch: report only changed
+					if prev, err := m.db.GetOne(table, k); err != nil {
+						return err
+					} else if bytes.Equal(prev, v) {
+						continue
+					}
+				}
+				tc := get(table)
+				tc.Put++
+				tc.PutSize += uint64(len(k) + len(v))
+			}
+			return nil
+		}(); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]TableChanges, 0, len(changes))
+	for _, c := range changes {
+		res = append(res, *c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Table < res[j].Table })
+	return res, nil
+}
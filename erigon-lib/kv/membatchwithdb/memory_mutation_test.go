@@ -670,3 +670,30 @@ func TestAutoConversionSeekBothRange(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, v)
 }
+
+func TestChanges(t *testing.T) {
+	_, rwTx := memdb.NewTestTx(t)
+	initializeDbNonDupSort(rwTx)
+	_, err := rwTx.IncrementSequence(kv.EthTx, 10)
+	require.NoError(t, err)
+
+	batch := NewMemoryBatch(rwTx, "", log.Root())
+	defer batch.Close()
+	require.NoError(t, batch.Put(kv.HashedAccounts, []byte("BAAA"), []byte("value4")))
+	require.NoError(t, batch.Delete(kv.HashedAccounts, []byte("CAAA")))
+	require.NoError(t, batch.Put(kv.Headers, []byte("h"), []byte("header")))
+
+	changes, err := batch.Changes()
+	require.NoError(t, err)
+	require.Equal(t, []TableChanges{
+		{Table: kv.HashedAccounts, Put: 1, PutSize: 10, Deleted: 1},
+		{Table: kv.Headers, Put: 1, PutSize: 7},
+	}, changes)
+
+	_, err = batch.IncrementSequence(kv.EthTx, 5)
+	require.NoError(t, err)
+	changes, err = batch.Changes()
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, TableChanges{Table: kv.Sequence, Put: 1, PutSize: uint64(len(kv.EthTx) + 8)}, changes[2])
+}
@@ -19,6 +19,7 @@ package remotedbserver
 import (
 	"context"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -437,9 +438,59 @@ func bytesCopy(b []byte) []byte {
 	return copiedBytes
 }
 
-func (s *KvServer) StateChanges(_ *remote.StateChangeRequest, server remote.KV_StateChangesServer) error {
-	ch, remove := s.stateChangeStreams.Sub()
+// StateChangesReplayLimit - how many recent batches (usually 1 batch = 1 block) kept in memory for replay
+const StateChangesReplayLimit = 128
+
+// StateChanges - if req.FromBlock is set, stream starts by replay of recent batches (see StateChangesReplayLimit)
+// which have changes of blocks >= it, then continues with live batches.
+func (s *KvServer) StateChanges(req *remote.StateChangeRequest, server remote.KV_StateChangesServer) error {
+	if req.FromBlock == nil {
+		ch, remove := s.stateChangeStreams.Sub()
+		defer remove()
+		return s.sendStateChanges(ch, server)
+	}
+
+	head, headKnown := s.executionProgress(server.Context())
+	ch, history, remove, err := s.stateChangeStreams.SubFrom(*req.FromBlock, head, headKnown)
+	if err != nil {
+		return err
+	}
 	defer remove()
+	if len(history) == 0 {
+		// replay accepted, but nothing to replay yet: empty batch lets client know that `FromBlock` is served
+		history = append(history, &remote.StateChangeBatch{})
+	}
+	for _, reply := range history {
+		if err := server.Send(reply); err != nil {
+			return err
+		}
+	}
+	return s.sendStateChanges(ch, server)
+}
+
+// executionProgress - last executed block. Replay of blocks <= it is impossible when no batches are in memory (after restart)
+func (s *KvServer) executionProgress(ctx context.Context) (head uint64, ok bool) {
+	if s.kv == nil {
+		return 0, false
+	}
+	if err := s.kv.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.SyncStageProgress, []byte("Execution"))
+		if err != nil {
+			return err
+		}
+		if len(v) < 8 {
+			return nil
+		}
+		head, ok = binary.BigEndian.Uint64(v), true
+		return nil
+	}); err != nil {
+		s.logger.Debug("[kv_server] can't read execution progress", "err", err)
+		return 0, false
+	}
+	return head, ok
+}
+
+func (s *KvServer) sendStateChanges(ch chan *remote.StateChangeBatch, server remote.KV_StateChangesServer) error {
 	for {
 		select {
 		case reply := <-ch:
@@ -482,18 +533,58 @@ func (s *KvServer) Snapshots(_ context.Context, _ *remote.SnapshotsRequest) (rep
 }
 
 type StateChangePubSub struct {
-	chans map[uint]chan *remote.StateChangeBatch
-	id    uint
-	mu    sync.RWMutex
+	chans   map[uint]chan *remote.StateChangeBatch
+	id      uint
+	history []*remote.StateChangeBatch // ring of recent batches for replay, oldest first
+	mu      sync.RWMutex
 }
 
 func newStateChangeStreams() *StateChangePubSub {
 	return &StateChangePubSub{}
 }
 
+// SubFrom - subscribe and return buffered batches which have changes of blocks >= fromBlock.
+// Done under same lock as Pub - so no gaps and no duplicates between `history` and `ch`.
+// `head` - last executed block, used only when there are no buffered batches: blocks <= head can't be replayed then.
+func (s *StateChangePubSub) SubFrom(fromBlock, head uint64, headKnown bool) (ch chan *remote.StateChangeBatch, history []*remote.StateChangeBatch, remove func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) > 0 && fromBlock < batchFirstBlock(s.history[0]) {
+		return nil, nil, nil, fmt.Errorf("state changes of block %d are out of replay window, oldest available: %d", fromBlock, batchFirstBlock(s.history[0]))
+	}
+	if len(s.history) == 0 && (!headKnown || fromBlock <= head) {
+		return nil, nil, nil, fmt.Errorf("state changes of block %d are not available for replay: no recent state changes in memory", fromBlock)
+	}
+	for i, batch := range s.history {
+		if batchLastBlock(batch) >= fromBlock {
+			history = append(history, s.history[i:]...)
+			break
+		}
+	}
+	ch, remove = s.sub()
+	return ch, history, remove, nil
+}
+
+func batchFirstBlock(batch *remote.StateChangeBatch) uint64 {
+	if len(batch.ChangeBatch) == 0 {
+		return 0
+	}
+	return batch.ChangeBatch[0].BlockHeight
+}
+func batchLastBlock(batch *remote.StateChangeBatch) uint64 {
+	if len(batch.ChangeBatch) == 0 {
+		return 0
+	}
+	return batch.ChangeBatch[len(batch.ChangeBatch)-1].BlockHeight
+}
+
 func (s *StateChangePubSub) Sub() (ch chan *remote.StateChangeBatch, remove func()) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.sub()
+}
+
+func (s *StateChangePubSub) sub() (ch chan *remote.StateChangeBatch, remove func()) {
 	if s.chans == nil {
 		s.chans = make(map[uint]chan *remote.StateChangeBatch)
 	}
@@ -505,8 +596,13 @@ func (s *StateChangePubSub) Sub() (ch chan *remote.StateChangeBatch, remove func
 }
 
 func (s *StateChangePubSub) Pub(reply *remote.StateChangeBatch) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) >= StateChangesReplayLimit {
+		s.history[0] = nil // avoid memory leaks
+		s.history = s.history[1:]
+	}
+	s.history = append(s.history, reply)
 	for _, ch := range s.chans {
 		common.PrioritizedSend(ch, reply)
 	}
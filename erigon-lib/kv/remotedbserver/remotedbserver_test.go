@@ -26,6 +26,7 @@ import (
 	"go.uber.org/mock/gomock"
 	"golang.org/x/sync/errgroup"
 
+	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 )
@@ -139,3 +140,37 @@ func TestKVServerSnapshotsReturnsEmptyIfNoBlockSnapshots(t *testing.T) {
 	require.Empty(t, reply.BlocksFiles)
 	require.Empty(t, reply.HistoryFiles)
 }
+
+func TestStateChangesReplay(t *testing.T) {
+	ctx := context.Background()
+	s := NewKvServer(ctx, nil, nil, nil, nil, log.New())
+	batch := func(block uint64) *remote.StateChangeBatch {
+		return &remote.StateChangeBatch{StateVersionId: block, ChangeBatch: []*remote.StateChange{{BlockHeight: block}}}
+	}
+
+	// nothing in memory (e.g. after restart): only blocks after head can be served
+	_, _, _, err := s.stateChangeStreams.SubFrom(1, 0, false)
+	require.Error(t, err)
+	_, _, _, err = s.stateChangeStreams.SubFrom(5, 5, true)
+	require.Error(t, err)
+	_, history, remove, err := s.stateChangeStreams.SubFrom(6, 5, true)
+	require.NoError(t, err)
+	require.Empty(t, history)
+	remove()
+
+	for i := uint64(1); i <= StateChangesReplayLimit+2; i++ {
+		s.SendStateChanges(ctx, batch(i))
+	}
+
+	_, _, _, err = s.stateChangeStreams.SubFrom(2, 0, false)
+	require.Error(t, err)
+
+	ch, history, remove, err := s.stateChangeStreams.SubFrom(StateChangesReplayLimit, 0, false)
+	require.NoError(t, err)
+	defer remove()
+	require.Len(t, history, 3)
+	require.Equal(t, uint64(StateChangesReplayLimit), history[0].StateVersionId)
+
+	s.SendStateChanges(ctx, batch(StateChangesReplayLimit+3))
+	require.Equal(t, uint64(StateChangesReplayLimit+3), (<-ch).StateVersionId)
+}
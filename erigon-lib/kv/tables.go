@@ -466,6 +466,10 @@ const (
 
 	BlockRootToKzgCommitments = "BlockRootToKzgCommitments"
 	KzgCommitmentToBlob       = "KzgCommitmentToBlob"
+	// [Versioned Hash] => [Slot + Blob Index], written only if blob sidecars are not pruned (blobs archive)
+	BlobVersionedHashToSlot = "BlobVersionedHashToSlot"
+	// BlobsArchiveIndexedKey - blob sidecars snapshots are indexed by BlobVersionedHashToSlot up to this slot (exclusive)
+	BlobsArchiveIndexedKey = "BlobsArchiveIndexedKey"
 
 	// [Block Root] => [Parent Root]
 	BlockRootToParentRoot = "BlockRootToParentRoot"
@@ -510,6 +514,12 @@ const (
 	Proposers        = "BlockProposers"   // epoch => proposers indicies
 
 	StatesProcessingProgress = "StatesProcessingProgress"
+
+	// Caplin validator client slashing protection
+	// [Public Key] => [Slot + Signing Root] of highest signed block
+	ValidatorSignedBlocks = "ValidatorSignedBlocks"
+	// [Public Key] => [Source Epoch + Target Epoch + Signing Root] of highest signed attestation
+	ValidatorSignedAttestations = "ValidatorSignedAttestations"
 )
 
 // Keys
@@ -528,6 +538,10 @@ var (
 	PruneTxIndexType    = []byte("pruneTxIndexType")
 	PruneCallTraces     = []byte("pruneCallTraces")
 	PruneCallTracesType = []byte("pruneCallTracesType")
+	PruneBlocks         = []byte("pruneBlocks")
+	PruneBlocksType     = []byte("pruneBlocksType")
+	PruneLogIndex       = []byte("pruneLogIndex")
+	PruneLogIndexType   = []byte("pruneLogIndexType")
 
 	DBSchemaVersionKey = []byte("dbVersion")
 
@@ -544,6 +558,9 @@ var (
 	LightClientOptimisticUpdate = []byte("LightClientOptimisticUpdate")
 
 	StatesProcessingKey = []byte("StatesProcessing")
+
+	// SnapshotSendersVerified - prefix of keys: transactions segment file name -> senders embedded in segment were verified
+	SnapshotSendersVerified = []byte("SnapshotSendersVerified.")
 )
 
 // ChaindataTables - list of all buckets. App will panic if some bucket is not in this list.
@@ -685,6 +702,7 @@ var ChaindataTables = []string{
 	// Blob Storage
 	BlockRootToKzgCommitments,
 	KzgCommitmentToBlob,
+	BlobVersionedHashToSlot,
 	// State Reconstitution
 	ValidatorPublicKeys,
 	InvertedValidatorPublicKeys,
@@ -713,6 +731,9 @@ var ChaindataTables = []string{
 	ActiveValidatorIndicies,
 	EffectiveBalancesDump,
 	BalancesDump,
+	// Validator client
+	ValidatorSignedBlocks,
+	ValidatorSignedAttestations,
 }
 
 const (
@@ -60,6 +60,20 @@ func New(db kv.RwDB, agg *state.Aggregator) (*DB, error) {
 func (db *DB) Agg() *state.Aggregator { return db.agg }
 func (db *DB) InternalDB() kv.RwDB    { return db.RwDB }
 
+func (db *DB) LongReadTxs() []kv.LongReadTx {
+	if w, ok := db.RwDB.(kv.HasLongReadTxs); ok {
+		return w.LongReadTxs()
+	}
+	return nil
+}
+
+func (db *DB) TableStats(ctx context.Context) ([]kv.TableStat, error) {
+	if s, ok := db.RwDB.(kv.HasTableStats); ok {
+		return s.TableStats(ctx)
+	}
+	return nil, fmt.Errorf("table stats not supported by %T", db.RwDB)
+}
+
 func (db *DB) BeginTemporalRo(ctx context.Context) (kv.TemporalTx, error) {
 	kvTx, err := db.RwDB.BeginRo(ctx) //nolint:gocritic
 	if err != nil {
@@ -154,6 +168,19 @@ func (tx *Tx) ForceReopenAggCtx() {
 	tx.aggCtx = tx.Agg().BeginFilesRo()
 }
 
+// RenewIfLong - also re-opens files view: data may be pruned from DB after it moved to newer files
+func (tx *Tx) RenewIfLong() (bool, error) {
+	if len(tx.resourcesToClose) > 0 { // iterators may still be used by caller - not a safe point
+		return false, nil
+	}
+	renewed, err := tx.MdbxTx.RenewIfLong()
+	if err != nil || !renewed {
+		return renewed, err
+	}
+	tx.ForceReopenAggCtx()
+	return true, nil
+}
+
 func (tx *Tx) WarmupDB(force bool) error { return tx.MdbxTx.WarmupDB(force) }
 func (tx *Tx) LockDBInRam() error        { return tx.MdbxTx.LockDBInRam() }
 func (tx *Tx) AggCtx() interface{}       { return tx.aggCtx }
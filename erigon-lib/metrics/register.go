@@ -171,3 +171,24 @@ func GetOrCreateHistogram(name string) Histogram {
 
 	return &histogram{h}
 }
+
+// GetOrCreateHistogramWithBuckets returns registered histogram with the given name
+// or creates new histogram with the given upper bounds of buckets.
+//
+// Buckets of registered histogram are not changed.
+func GetOrCreateHistogramWithBuckets(name string, buckets []float64) Histogram {
+	h, err := defaultSet.GetOrCreateHistogramWithBuckets(name, buckets)
+	if err != nil {
+		panic(fmt.Errorf("could not get or create new histogram: %w", err))
+	}
+
+	return &histogram{h}
+}
+
+// UnregisterMetric removes metric with the given name, for metrics of short-lived objects such as peers.
+//
+// True is returned if the metric has been removed.
+// False is returned if the given metric is missing.
+func UnregisterMetric(name string) bool {
+	return defaultSet.UnregisterMetric(name)
+}
@@ -79,7 +79,7 @@ func (s *Set) Collect(ch chan<- prometheus.Metric) {
 //
 // The returned histogram is safe to use from concurrent goroutines.
 func (s *Set) NewHistogram(name string, help ...string) (prometheus.Histogram, error) {
-	h, err := newHistogram(name, help...)
+	h, err := newHistogram(name, nil, help...)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +88,8 @@ func (s *Set) NewHistogram(name string, help ...string) (prometheus.Histogram, e
 	return h, nil
 }
 
-func newHistogram(name string, help ...string) (prometheus.Histogram, error) {
+// newHistogram - nil buckets are prometheus.DefBuckets
+func newHistogram(name string, buckets []float64, help ...string) (prometheus.Histogram, error) {
 	name, labels, err := parseMetric(name)
 	if err != nil {
 		return nil, err
@@ -97,6 +98,7 @@ func newHistogram(name string, help ...string) (prometheus.Histogram, error) {
 	return prometheus.NewHistogram(prometheus.HistogramOpts{
 		Name:        name,
 		ConstLabels: labels,
+		Buckets:     buckets,
 		Help:        strings.Join(help, " "),
 	}), nil
 }
@@ -115,11 +117,17 @@ func newHistogram(name string, help ...string) (prometheus.Histogram, error) {
 //
 // Performance tip: prefer NewHistogram instead of GetOrCreateHistogram.
 func (s *Set) GetOrCreateHistogram(name string, help ...string) (prometheus.Histogram, error) {
+	return s.GetOrCreateHistogramWithBuckets(name, nil, help...)
+}
+
+// GetOrCreateHistogramWithBuckets - same as GetOrCreateHistogram, but with given upper bounds of buckets,
+// for values not fitting prometheus.DefBuckets (sizes, long durations). Buckets are set by first call.
+func (s *Set) GetOrCreateHistogramWithBuckets(name string, buckets []float64, help ...string) (prometheus.Histogram, error) {
 	s.mu.Lock()
 	nm := s.m[name]
 	s.mu.Unlock()
 	if nm == nil {
-		metric, err := newHistogram(name, help...)
+		metric, err := newHistogram(name, buckets, help...)
 		if err != nil {
 			return nil, fmt.Errorf("invalid metric name %q: %w", name, err)
 		}
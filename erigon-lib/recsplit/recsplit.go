@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -82,12 +83,12 @@ type RecSplit struct {
 	gr                GolombRice // Helper object to encode the tree of hash function salts using Golomb-Rice code.
 	bucketPosAcc      []uint64   // Accumulator for position of every bucket in the encoding of the hash function
 	startSeed         []uint64
-	count             []uint16
 	currentBucket     []uint64 // 64-bit fingerprints of keys in the current bucket accumulated before the recsplit is performed for that bucket
 	currentBucketOffs []uint64 // Index offsets for the current bucket
-	offsetBuffer      []uint64
-	buffer            []uint64
-	golombRice        []uint32
+	splitter          *bucketSplitter
+	splitRes          bucketResult
+	parallel          *parallelSplit // nil if workers <= 1
+	workers           int
 	bucketSizeAcc     []uint64 // Bucket size accumulator
 	// Helper object to encode the sequence of cumulative number of keys in the buckets
 	// and the sequence of cumulative bit offsets of buckets in the Golomb-Rice code.
@@ -136,6 +137,7 @@ type RecSplitArgs struct {
 	EtlBufLimit datasize.ByteSize
 	Salt        *uint32 // Hash seed (salt) for the hash function used for allocating the initial buckets - need to be generated randomly
 	LeafSize    uint16
+	Workers     int // goroutines to split buckets and encode them. 0 - recsplit.Workers
 }
 
 // NewRecSplit creates a new RecSplit instance with given number of keys and given bucket size
@@ -206,7 +208,11 @@ func NewRecSplit(args RecSplitArgs, logger log.Logger) (*RecSplit, error) {
 		rs.secondaryAggrBound = rs.primaryAggrBound * uint16(math.Ceil(0.21*float64(rs.leafSize)+9./10.))
 	}
 	rs.startSeed = args.StartSeed
-	rs.count = make([]uint16, rs.secondaryAggrBound)
+	rs.splitter = rs.newBucketSplitter()
+	rs.workers = args.Workers
+	if rs.workers <= 0 {
+		rs.workers = Workers
+	}
 	return rs, nil
 }
 
@@ -230,6 +236,7 @@ func (rs *RecSplit) LogLvl(lvl log.Lvl) { rs.lvl = lvl }
 
 func (rs *RecSplit) SetTrace(trace bool) {
 	rs.trace = trace
+	rs.splitter.trace = trace
 }
 
 // remap converts the number x which is assumed to be uniformly distributed over the range [0..2^64) to the number that is uniformly
@@ -324,19 +331,19 @@ func computeGolombRice(m uint16, table []uint32, leafSize, primaryAggrBound, sec
 // golombParam returns the optimal Golomb parameter to use for encoding
 // salt for the part of the hash function separating m elements. It is based on
 // calculations with assumptions that we draw hash functions at random
-func (rs *RecSplit) golombParam(m uint16) int {
-	for s := uint16(len(rs.golombRice)); m >= s; s++ {
-		rs.golombRice = append(rs.golombRice, 0)
+func (bs *bucketSplitter) golombParam(m uint16) int {
+	for s := uint16(len(bs.golombRice)); m >= s; s++ {
+		bs.golombRice = append(bs.golombRice, 0)
 		// For the case where bucket is larger than planned
 		if s == 0 {
-			rs.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
-		} else if s <= rs.leafSize {
-			rs.golombRice[s] = (bijMemo[s] << 27) | (uint32(1) << 16) | bijMemo[s]
+			bs.golombRice[0] = (bijMemo[0] << 27) | bijMemo[0]
+		} else if s <= bs.leafSize {
+			bs.golombRice[s] = (bijMemo[s] << 27) | (uint32(1) << 16) | bijMemo[s]
 		} else {
-			computeGolombRice(s, rs.golombRice, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
+			computeGolombRice(s, bs.golombRice, bs.leafSize, bs.primaryAggrBound, bs.secondaryAggrBound)
 		}
 	}
-	return int(rs.golombRice[m] >> 27)
+	return int(bs.golombRice[m] >> 27)
 }
 
 // Add key to the RecSplit. There can be many more keys than what fits in RAM, and RecSplit
@@ -402,60 +409,119 @@ func (rs *RecSplit) recsplitCurrentBucket() error {
 		rs.bucketSizeAcc = append(rs.bucketSizeAcc, rs.bucketSizeAcc[len(rs.bucketSizeAcc)-1])
 	}
 	rs.bucketSizeAcc[int(rs.currentBucketIdx)+1] += uint64(len(rs.currentBucket))
-	// Sets of size 0 and 1 are not further processed, just write them to index
-	if len(rs.currentBucket) > 1 {
-		for i, key := range rs.currentBucket[1:] {
-			if key == rs.currentBucket[i] {
-				rs.collision = true
-				return fmt.Errorf("%w: %x", ErrCollision, key)
-			}
-		}
-		bitPos := rs.gr.bitCount
-		if rs.buffer == nil {
-			rs.buffer = make([]uint64, len(rs.currentBucket))
-			rs.offsetBuffer = make([]uint64, len(rs.currentBucketOffs))
-		} else {
-			for len(rs.buffer) < len(rs.currentBucket) {
-				rs.buffer = append(rs.buffer, 0)
-				rs.offsetBuffer = append(rs.offsetBuffer, 0)
-			}
+	defer func() {
+		// clear for the next buckey
+		rs.currentBucket = rs.currentBucket[:0]
+		rs.currentBucketOffs = rs.currentBucketOffs[:0]
+	}()
+	if rs.parallel != nil {
+		return rs.addParallel(rs.currentBucketIdx, rs.currentBucket, rs.currentBucketOffs)
+	}
+	rs.splitRes.reset()
+	err := rs.splitter.splitBucket(rs.currentBucket, rs.currentBucketOffs, &rs.splitRes)
+	return rs.applyBucket(rs.currentBucketIdx, len(rs.currentBucket), &rs.splitRes, err)
+}
+
+// applyBucket - writes result of bucket split to index. Buckets must be applied in order of bucket index.
+func (rs *RecSplit) applyBucket(bucketIdx uint64, bucketLen int, res *bucketResult, splitErr error) error {
+	if splitErr != nil {
+		if errors.Is(splitErr, ErrCollision) {
+			rs.collision = true
 		}
-		unary, err := rs.recsplit(0 /* level */, rs.currentBucket, rs.currentBucketOffs, nil /* unary */)
-		if err != nil {
+		return splitErr
+	}
+	bitPos := rs.gr.bitCount
+	for _, f := range res.fixed {
+		rs.gr.appendFixed(f.v, f.log2golomb)
+	}
+	if len(res.unary) > 0 {
+		rs.gr.appendUnaryAll(res.unary)
+	}
+	if rs.trace && bucketLen > 1 {
+		fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", bucketIdx, bucketLen, rs.gr.bitCount-bitPos)
+	}
+	for _, offset := range res.offsets {
+		binary.BigEndian.PutUint64(rs.numBuf[:], offset)
+		if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
 			return err
 		}
-		rs.gr.appendUnaryAll(unary)
-		if rs.trace {
-			fmt.Printf("recsplitBucket(%d, %d, bitsize = %d)\n", rs.currentBucketIdx, len(rs.currentBucket), rs.gr.bitCount-bitPos)
-		}
-	} else {
-		for _, offset := range rs.currentBucketOffs {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return err
-			}
-		}
 	}
 	// Extend rs.bucketPosAcc to accomodate current bucket index + 1
-	for len(rs.bucketPosAcc) <= int(rs.currentBucketIdx)+1 {
+	for len(rs.bucketPosAcc) <= int(bucketIdx)+1 {
 		rs.bucketPosAcc = append(rs.bucketPosAcc, rs.bucketPosAcc[len(rs.bucketPosAcc)-1])
 	}
-	rs.bucketPosAcc[int(rs.currentBucketIdx)+1] = uint64(rs.gr.Bits())
-	// clear for the next buckey
-	rs.currentBucket = rs.currentBucket[:0]
-	rs.currentBucketOffs = rs.currentBucketOffs[:0]
+	rs.bucketPosAcc[int(bucketIdx)+1] = uint64(rs.gr.Bits())
+	return nil
+}
+
+// bucketSplitter - finds hash function salts for one bucket. Doesn't touch RecSplit state: each worker has own splitter.
+type bucketSplitter struct {
+	startSeed          []uint64
+	golombRice         []uint32
+	buffer             []uint64
+	offsetBuffer       []uint64
+	count              []uint16
+	leafSize           uint16
+	primaryAggrBound   uint16
+	secondaryAggrBound uint16
+	trace              bool
+}
+
+func (rs *RecSplit) newBucketSplitter() *bucketSplitter {
+	return &bucketSplitter{
+		startSeed:          rs.startSeed,
+		count:              make([]uint16, rs.secondaryAggrBound),
+		leafSize:           rs.leafSize,
+		primaryAggrBound:   rs.primaryAggrBound,
+		secondaryAggrBound: rs.secondaryAggrBound,
+		trace:              rs.trace,
+	}
+}
+
+type fixedCode struct {
+	v          uint64
+	log2golomb int
+}
+
+// bucketResult - encoding of one bucket: golomb-rice codes in order of appending, and index offsets in order of writing
+type bucketResult struct {
+	fixed   []fixedCode
+	unary   []uint64
+	offsets []uint64
+}
+
+func (r *bucketResult) reset() {
+	r.fixed, r.unary, r.offsets = r.fixed[:0], r.unary[:0], r.offsets[:0]
+}
+
+func (bs *bucketSplitter) splitBucket(bucket, offsets []uint64, res *bucketResult) error {
+	// Sets of size 0 and 1 are not further processed, just write them to index
+	if len(bucket) <= 1 {
+		res.offsets = append(res.offsets, offsets...)
+		return nil
+	}
+	for i, key := range bucket[1:] {
+		if key == bucket[i] {
+			return fmt.Errorf("%w: %x", ErrCollision, key)
+		}
+	}
+	for len(bs.buffer) < len(bucket) {
+		bs.buffer = append(bs.buffer, 0)
+		bs.offsetBuffer = append(bs.offsetBuffer, 0)
+	}
+	res.unary = bs.recsplit(0 /* level */, bucket, offsets, res.unary, res)
 	return nil
 }
 
 // recsplit applies recSplit algorithm to the given bucket
-func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary []uint64) ([]uint64, error) {
-	if rs.trace {
+func (bs *bucketSplitter) recsplit(level int, bucket []uint64, offsets []uint64, unary []uint64, res *bucketResult) []uint64 {
+	if bs.trace {
 		fmt.Printf("recsplit(%d, %d, %x)\n", level, len(bucket), bucket)
 	}
 	// Pick initial salt for this level of recursive split
-	salt := rs.startSeed[level]
+	salt := bs.startSeed[level]
 	m := uint16(len(bucket))
-	if m <= rs.leafSize {
+	if m <= bs.leafSize {
 		// No need to build aggregation levels - just find bijection
 		var mask uint32
 		for {
@@ -476,24 +542,19 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m)
-			rs.offsetBuffer[j] = offsets[i]
-		}
-		for _, offset := range rs.offsetBuffer[:m] {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offset)
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
-			}
+			bs.offsetBuffer[j] = offsets[i]
 		}
-		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
-		if rs.trace {
-			fmt.Printf("encode bij %d with log2golomn %d at p = %d\n", salt, log2golomb, rs.gr.bitCount)
+		res.offsets = append(res.offsets, bs.offsetBuffer[:m]...)
+		salt -= bs.startSeed[level]
+		log2golomb := bs.golombParam(m)
+		if bs.trace {
+			fmt.Printf("encode bij %d with log2golomn %d\n", salt, log2golomb)
 		}
-		rs.gr.appendFixed(salt, log2golomb)
+		res.fixed = append(res.fixed, fixedCode{salt, log2golomb})
 		unary = append(unary, salt>>log2golomb)
 	} else {
-		fanout, unit := splitParams(m, rs.leafSize, rs.primaryAggrBound, rs.secondaryAggrBound)
-		count := rs.count
+		fanout, unit := splitParams(m, bs.leafSize, bs.primaryAggrBound, bs.secondaryAggrBound)
+		count := bs.count
 		for {
 			for i := uint16(0); i < fanout-1; i++ {
 				count[i] = 0
@@ -516,38 +577,30 @@ func (rs *RecSplit) recsplit(level int, bucket []uint64, offsets []uint64, unary
 		}
 		for i := uint16(0); i < m; i++ {
 			j := remap16(remix(bucket[i]+salt), m) / unit
-			rs.buffer[count[j]] = bucket[i]
-			rs.offsetBuffer[count[j]] = offsets[i]
+			bs.buffer[count[j]] = bucket[i]
+			bs.offsetBuffer[count[j]] = offsets[i]
 			count[j]++
 		}
-		copy(bucket, rs.buffer)
-		copy(offsets, rs.offsetBuffer)
-		salt -= rs.startSeed[level]
-		log2golomb := rs.golombParam(m)
-		if rs.trace {
-			fmt.Printf("encode fanout %d: %d with log2golomn %d at p = %d\n", fanout, salt, log2golomb, rs.gr.bitCount)
+		copy(bucket, bs.buffer)
+		copy(offsets, bs.offsetBuffer)
+		salt -= bs.startSeed[level]
+		log2golomb := bs.golombParam(m)
+		if bs.trace {
+			fmt.Printf("encode fanout %d: %d with log2golomn %d\n", fanout, salt, log2golomb)
 		}
-		rs.gr.appendFixed(salt, log2golomb)
+		res.fixed = append(res.fixed, fixedCode{salt, log2golomb})
 		unary = append(unary, salt>>log2golomb)
-		var err error
 		var i uint16
 		for i = 0; i < m-unit; i += unit {
-			if unary, err = rs.recsplit(level+1, bucket[i:i+unit], offsets[i:i+unit], unary); err != nil {
-				return nil, err
-			}
+			unary = bs.recsplit(level+1, bucket[i:i+unit], offsets[i:i+unit], unary, res)
 		}
 		if m-i > 1 {
-			if unary, err = rs.recsplit(level+1, bucket[i:], offsets[i:], unary); err != nil {
-				return nil, err
-			}
+			unary = bs.recsplit(level+1, bucket[i:], offsets[i:], unary, res)
 		} else if m-i == 1 {
-			binary.BigEndian.PutUint64(rs.numBuf[:], offsets[i])
-			if _, err := rs.indexW.Write(rs.numBuf[8-rs.bytesPerRec:]); err != nil {
-				return nil, err
-			}
+			res.offsets = append(res.offsets, offsets[i])
 		}
 	}
-	return unary, nil
+	return unary
 }
 
 // loadFuncBucket is required to satisfy the type etl.LoadFunc type, to use with collector.Load
@@ -611,7 +664,11 @@ func (rs *RecSplit) Build(ctx context.Context) error {
 	rs.currentBucketIdx = math.MaxUint64 // To make sure 0 bucket is detected
 	defer rs.bucketCollector.Close()
 	if rs.lvl < log.LvlTrace {
-		log.Log(rs.lvl, "[index] calculating", "file", rs.indexFileName)
+		log.Log(rs.lvl, "[index] calculating", "file", rs.indexFileName, "workers", rs.workers)
+	}
+	if rs.workers > 1 {
+		rs.parallel = rs.newParallelSplit(ctx)
+		defer rs.closeParallel() //nolint:errcheck
 	}
 	if err := rs.bucketCollector.Load(nil, "", rs.loadFuncBucket, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
 		return err
@@ -621,6 +678,9 @@ func (rs *RecSplit) Build(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := rs.closeParallel(); err != nil {
+		return err
+	}
 
 	if assert.Enable {
 		rs.indexW.Flush()
@@ -695,7 +755,7 @@ func (rs *RecSplit) Build(ctx context.Context) error {
 		return err
 	}
 	// Write out the size of golomb rice params
-	binary.BigEndian.PutUint16(rs.numBuf[:], uint16(len(rs.golombRice)))
+	binary.BigEndian.PutUint16(rs.numBuf[:], uint16(len(rs.splitter.golombRice)))
 	if _, err := rs.indexW.Write(rs.numBuf[:4]); err != nil {
 		return fmt.Errorf("writing golomb rice param size: %w", err)
 	}
@@ -0,0 +1,138 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package recsplit
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+)
+
+// Workers - default amount of goroutines used by RecSplit.Build to split buckets and golomb-rice encode them.
+// Files are also indexed in parallel by callers - so default is 1. Can be changed per-index by RecSplitArgs.Workers
+var Workers = dbg.EnvInt("RECSPLIT_WORKERS", 1)
+
+// bucketJob - bucket sent to worker. Result is applied to index by single goroutine in order of bucket index
+type bucketJob struct {
+	idx       uint64
+	keys      []uint64
+	offsets   []uint64
+	bucketLen int
+	res       bucketResult
+	err       error
+	done      chan struct{}
+}
+
+// parallelSplit - buckets are independent: salts search and encoding are done by workers,
+// but golomb-rice stream and index offsets must be written in order of buckets - it's done by `applier` goroutine.
+type parallelSplit struct {
+	ctx       context.Context
+	g         *errgroup.Group
+	jobs      chan *bucketJob // to workers
+	ordered   chan *bucketJob // to applier, in order of buckets
+	pool      sync.Pool
+	splitters []*bucketSplitter
+}
+
+func (rs *RecSplit) newParallelSplit(ctx context.Context) *parallelSplit {
+	g, ctx := errgroup.WithContext(ctx)
+	p := &parallelSplit{
+		ctx:     ctx,
+		g:       g,
+		jobs:    make(chan *bucketJob, rs.workers*4),
+		ordered: make(chan *bucketJob, rs.workers*64),
+		pool:    sync.Pool{New: func() any { return &bucketJob{} }},
+	}
+	for i := 0; i < rs.workers; i++ {
+		bs := rs.newBucketSplitter()
+		p.splitters = append(p.splitters, bs)
+		g.Go(func() error {
+			for j := range p.jobs {
+				j.err = bs.splitBucket(j.keys, j.offsets, &j.res)
+				close(j.done)
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		for j := range p.ordered {
+			select {
+			case <-j.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if err := rs.applyBucket(j.idx, j.bucketLen, &j.res, j.err); err != nil {
+				return err
+			}
+			p.pool.Put(j)
+		}
+		return nil
+	})
+	return p
+}
+
+// addParallel - copies bucket and sends it to workers. Blocks if workers/applier are behind.
+func (rs *RecSplit) addParallel(idx uint64, keys, offsets []uint64) error {
+	p := rs.parallel
+	j := p.pool.Get().(*bucketJob)
+	j.idx, j.bucketLen, j.err, j.done = idx, len(keys), nil, make(chan struct{})
+	j.keys = append(j.keys[:0], keys...)
+	j.offsets = append(j.offsets[:0], offsets...)
+	j.res.reset()
+	select {
+	case p.jobs <- j:
+	case <-p.ctx.Done():
+		return rs.failParallel()
+	}
+	select {
+	case p.ordered <- j:
+	case <-p.ctx.Done():
+		return rs.failParallel()
+	}
+	return nil
+}
+
+// failParallel - stops workers and returns error which cancelled them
+func (rs *RecSplit) failParallel() error {
+	ctx := rs.parallel.ctx
+	if err := rs.closeParallel(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// closeParallel - waits until all buckets are applied. Safe for repeated call
+func (rs *RecSplit) closeParallel() error {
+	p := rs.parallel
+	if p == nil {
+		return nil
+	}
+	close(p.jobs)
+	close(p.ordered)
+	err := p.g.Wait()
+	// golomb-rice params table is written to index: must cover largest bucket seen by any worker
+	for _, bs := range p.splitters {
+		if len(bs.golombRice) > len(rs.splitter.golombRice) {
+			rs.splitter.golombRice = bs.golombRice
+		}
+	}
+	rs.parallel = nil
+	return err
+}
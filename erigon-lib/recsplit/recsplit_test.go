@@ -19,11 +19,13 @@ package recsplit
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRecSplit2(t *testing.T) {
@@ -184,3 +186,66 @@ func TestTwoLayerIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestParallelBuild(t *testing.T) {
+	logger := log.New()
+	tmpDir := t.TempDir()
+	salt := uint32(1)
+	const keys = 100_000
+	build := func(workers int) []byte {
+		indexFile := filepath.Join(tmpDir, fmt.Sprintf("index-%d", workers))
+		rs, err := NewRecSplit(RecSplitArgs{
+			KeyCount:   keys,
+			BucketSize: 2000,
+			Salt:       &salt,
+			TmpDir:     tmpDir,
+			IndexFile:  indexFile,
+			LeafSize:   8,
+			Enums:      true,
+			Workers:    workers,
+		}, logger)
+		require.NoError(t, err)
+		defer rs.Close()
+		for i := 0; i < keys; i++ {
+			require.NoError(t, rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i*17)))
+		}
+		require.NoError(t, rs.Build(context.Background()))
+
+		idx := MustOpen(indexFile)
+		defer idx.Close()
+		reader := NewIndexReader(idx)
+		for i := 0; i < keys; i++ {
+			enum, ok := reader.Lookup([]byte(fmt.Sprintf("key %d", i)))
+			require.True(t, ok)
+			require.Equal(t, uint64(i*17), idx.OrdinalLookup(enum))
+		}
+		data, err := os.ReadFile(indexFile)
+		require.NoError(t, err)
+		return data
+	}
+	// same salt - must produce byte-identical index
+	require.Equal(t, build(1), build(4))
+}
+
+func TestParallelBuildDuplicate(t *testing.T) {
+	logger := log.New()
+	tmpDir := t.TempDir()
+	salt := uint32(1)
+	rs, err := NewRecSplit(RecSplitArgs{
+		KeyCount:   10_001,
+		BucketSize: 10,
+		Salt:       &salt,
+		TmpDir:     tmpDir,
+		IndexFile:  filepath.Join(tmpDir, "index"),
+		LeafSize:   8,
+		Workers:    4,
+	}, logger)
+	require.NoError(t, err)
+	defer rs.Close()
+	for i := 0; i < 10_000; i++ {
+		require.NoError(t, rs.AddKey([]byte(fmt.Sprintf("key %d", i)), uint64(i)))
+	}
+	require.NoError(t, rs.AddKey([]byte("key 5000"), 0))
+	require.ErrorIs(t, rs.Build(context.Background()), ErrCollision)
+	require.True(t, rs.Collision())
+}
@@ -68,6 +68,8 @@ type Compressor struct {
 	superstringCount uint64
 	superstringLen   int
 	workers          int
+	cfg              Cfg
+	dict             *DictionaryBuilder // dictionary used by last Compress call
 	Ratio            CompressionRatio
 	lvl              log.Lvl
 	trace            bool
@@ -75,7 +77,57 @@ type Compressor struct {
 	noFsync          bool // fsync is enabled by default, but tests can manually disable
 }
 
+// Cfg - tuning of compressor. Dictionary of patterns is trained on sample of words:
+// trade-off between compression ratio and build time/RAM.
+type Cfg struct {
+	MinPatternScore uint64 // minimum score (per superstring) required to consider including pattern into the dictionary
+	MinPatternLen   int
+	MaxPatternLen   int
+	SamplingFactor  uint64 // only 1 of SamplingFactor superstrings is used for dictionary training
+	MaxDictPatterns int    // dictionary size. Large values increase RAM of dictionary reduction and decompression
+	Workers         int
+
+	// Dictionary - pre-trained dictionary (for example, of previous file of same snapshot type). If set - training is skipped:
+	// files are built faster, ratio depends on how similar data is. See Compressor.Dictionary, ReadDictionary
+	Dictionary *DictionaryBuilder
+}
+
+var DefaultCfg = Cfg{
+	MinPatternScore: MinPatternScore,
+	MinPatternLen:   minPatternLen,
+	MaxPatternLen:   maxPatternLen,
+	SamplingFactor:  samplingFactor,
+	MaxDictPatterns: maxDictPatterns,
+	Workers:         1,
+}
+
+func (cfg Cfg) validate() error {
+	if cfg.MinPatternLen < 1 || cfg.MaxPatternLen < cfg.MinPatternLen {
+		return fmt.Errorf("invalid pattern len: min=%d, max=%d", cfg.MinPatternLen, cfg.MaxPatternLen)
+	}
+	if cfg.SamplingFactor < 1 {
+		return fmt.Errorf("invalid sampling factor: %d", cfg.SamplingFactor)
+	}
+	if cfg.MaxDictPatterns < 1 {
+		return fmt.Errorf("invalid dictionary size: %d", cfg.MaxDictPatterns)
+	}
+	if cfg.Workers < 1 {
+		return fmt.Errorf("invalid workers: %d", cfg.Workers)
+	}
+	return nil
+}
+
 func NewCompressor(ctx context.Context, logPrefix, outputFile, tmpDir string, minPatternScore uint64, workers int, lvl log.Lvl, logger log.Logger) (*Compressor, error) {
+	cfg := DefaultCfg
+	cfg.MinPatternScore, cfg.Workers = minPatternScore, workers
+	return NewCompressorWithCfg(ctx, logPrefix, outputFile, tmpDir, cfg, lvl, logger)
+}
+
+func NewCompressorWithCfg(ctx context.Context, logPrefix, outputFile, tmpDir string, cfg Cfg, lvl log.Lvl, logger log.Logger) (*Compressor, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	workers := cfg.Workers
 	dir2.MustExist(tmpDir)
 	dir, fileName := filepath.Split(outputFile)
 
@@ -92,14 +144,17 @@ func NewCompressor(ctx context.Context, logPrefix, outputFile, tmpDir string, mi
 	// Collector for dictionary superstrings (sorted by their score)
 	superstrings := make(chan []byte, workers*2)
 	wg := &sync.WaitGroup{}
-	wg.Add(workers)
-	suffixCollectors := make([]*etl.Collector, workers)
-	for i := 0; i < workers; i++ {
-		collector := etl.NewCollector(logPrefix+"_dict", tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize/2), logger) //nolint:gocritic
-		collector.LogLvl(lvl)
-
-		suffixCollectors[i] = collector
-		go extractPatternsInSuperstrings(ctx, superstrings, collector, minPatternScore, wg, logger)
+	var suffixCollectors []*etl.Collector
+	if cfg.Dictionary == nil { // pre-trained dictionary doesn't need patterns extraction
+		wg.Add(workers)
+		suffixCollectors = make([]*etl.Collector, workers)
+		for i := 0; i < workers; i++ {
+			collector := etl.NewCollector(logPrefix+"_dict", tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize/2), logger) //nolint:gocritic
+			collector.LogLvl(lvl)
+
+			suffixCollectors[i] = collector
+			go extractPatternsInSuperstrings(ctx, superstrings, collector, cfg, wg, logger)
+		}
 	}
 
 	return &Compressor{
@@ -109,6 +164,7 @@ func NewCompressor(ctx context.Context, logPrefix, outputFile, tmpDir string, mi
 		tmpDir:           tmpDir,
 		logPrefix:        logPrefix,
 		workers:          workers,
+		cfg:              cfg,
 		ctx:              ctx,
 		superstrings:     superstrings,
 		suffixCollectors: suffixCollectors,
@@ -129,6 +185,9 @@ func (c *Compressor) Close() {
 func (c *Compressor) SetTrace(trace bool) { c.trace = trace }
 func (c *Compressor) Workers() int        { return c.workers }
 
+// Dictionary - patterns dictionary used by Compress. Can be passed to Cfg.Dictionary of next compressor or persisted by PersistDictionary
+func (c *Compressor) Dictionary() *DictionaryBuilder { return c.dict }
+
 func (c *Compressor) Count() int { return int(c.wordsCount) }
 
 func (c *Compressor) AddWord(word []byte) error {
@@ -139,9 +198,12 @@ func (c *Compressor) AddWord(word []byte) error {
 	}
 
 	c.wordsCount++
+	if c.cfg.Dictionary != nil {
+		return c.uncompressedFile.Append(word)
+	}
 	l := 2*len(word) + 2
 	if c.superstringLen+l > superstringLimit {
-		if c.superstringCount%samplingFactor == 0 {
+		if c.superstringCount%c.cfg.SamplingFactor == 0 {
 			c.superstrings <- c.superstring
 		}
 		c.superstringCount++
@@ -150,7 +212,7 @@ func (c *Compressor) AddWord(word []byte) error {
 	}
 	c.superstringLen += l
 
-	if c.superstringCount%samplingFactor == 0 {
+	if c.superstringCount%c.cfg.SamplingFactor == 0 {
 		for _, a := range word {
 			c.superstring = append(c.superstring, 1, a)
 		}
@@ -188,11 +250,17 @@ func (c *Compressor) Compress() error {
 		c.logger.Log(c.lvl, fmt.Sprintf("[%s] BuildDict start", c.logPrefix), "workers", c.workers)
 	}
 	t := time.Now()
-	db, err := DictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.lvl, c.logger)
-	if err != nil {
-
-		return err
+	var db *DictionaryBuilder
+	if c.cfg.Dictionary != nil {
+		db = c.cfg.Dictionary.clone()
+	} else {
+		var err error
+		db, err = DictionaryBuilderFromCollectors(c.ctx, compressLogPrefix, c.tmpDir, c.suffixCollectors, c.cfg.MaxDictPatterns, c.lvl, c.logger)
+		if err != nil {
+			return err
+		}
 	}
+	c.dict = db.clone() // compression consumes db
 	if c.trace {
 		_, fileName := filepath.Split(c.outputFile)
 		if err := PersistDictionary(filepath.Join(c.tmpDir, fileName)+".dictionary.txt", db); err != nil {
@@ -364,6 +432,11 @@ func (db *DictionaryBuilder) ForEach(f func(score uint64, word []byte)) {
 	}
 }
 
+// clone - shallow: patterns are not modified by compression
+func (db *DictionaryBuilder) clone() *DictionaryBuilder {
+	return &DictionaryBuilder{items: slices.Clone(db.items), limit: db.limit}
+}
+
 func (db *DictionaryBuilder) Close() {
 	db.items = nil
 	db.lastWord = nil
@@ -269,3 +269,58 @@ func TestCompressDictCmp(t *testing.T) {
 		t.Errorf("result file hash changed, %d", cs)
 	}
 }
+
+func TestCompressPretrainedDictionary(t *testing.T) {
+	logger := log.New()
+	tmpDir := t.TempDir()
+	compress := func(fileName string, cfg Cfg, from int) *Compressor {
+		c, err := NewCompressorWithCfg(context.Background(), t.Name(), filepath.Join(tmpDir, fileName), tmpDir, cfg, log.LvlDebug, logger)
+		require.NoError(t, err)
+		for i := from; i < from+1000; i++ {
+			require.NoError(t, c.AddWord([]byte(fmt.Sprintf("%d longlongword %d", i, i))))
+		}
+		require.NoError(t, c.Compress())
+		return c
+	}
+	check := func(fileName string, from int) {
+		d, err := NewDecompressor(filepath.Join(tmpDir, fileName))
+		require.NoError(t, err)
+		defer d.Close()
+		g := d.MakeGetter()
+		i := from
+		for g.HasNext() {
+			word, _ := g.Next(nil)
+			require.Equal(t, fmt.Sprintf("%d longlongword %d", i, i), string(word))
+			i++
+		}
+		require.Equal(t, from+1000, i)
+	}
+
+	cfg := DefaultCfg
+	cfg.MinPatternScore, cfg.SamplingFactor, cfg.MaxPatternLen, cfg.MaxDictPatterns = 1, 1, 16, 1024
+	c := compress("a.seg", cfg, 0)
+	defer c.Close()
+	check("a.seg", 0)
+	require.NotZero(t, c.Dictionary().Len())
+	for _, p := range c.Dictionary().items {
+		require.LessOrEqual(t, len(p.word), cfg.MaxPatternLen)
+	}
+
+	// dictionary survives persist/read and can be used for other files
+	dictFile := filepath.Join(tmpDir, "dict.txt")
+	require.NoError(t, PersistDictionary(dictFile, c.Dictionary()))
+	dict, err := ReadDictionary(dictFile)
+	require.NoError(t, err)
+	require.Equal(t, c.Dictionary().Len(), dict.Len())
+
+	cfg.Dictionary = dict
+	c2 := compress("b.seg", cfg, 1000)
+	defer c2.Close()
+	check("b.seg", 1000)
+	require.Equal(t, dict.Len(), c2.Dictionary().Len())
+	require.Equal(t, dict.Len(), cfg.Dictionary.Len()) // not consumed by compression
+
+	cfg.MaxPatternLen = cfg.MinPatternLen - 1
+	_, err = NewCompressorWithCfg(context.Background(), t.Name(), filepath.Join(tmpDir, "c.seg"), tmpDir, cfg, log.LvlDebug, logger)
+	require.Error(t, err)
+}
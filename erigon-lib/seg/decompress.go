@@ -135,7 +135,8 @@ type Decompressor struct {
 
 	filePath, FileName1 string
 
-	readAheadRefcnt atomic.Int32 // ref-counter: allow enable/disable read-ahead from goroutines. only when refcnt=0 - disable read-ahead once
+	readAheadRefcnt atomic.Int32   // ref-counter: allow enable/disable read-ahead from goroutines. only when refcnt=0 - disable read-ahead once
+	reads           *atomic.Uint64 // getters made for the file, see Reads
 }
 
 const (
@@ -182,6 +183,7 @@ func NewDecompressor(compressedFilePath string) (*Decompressor, error) {
 	d := &Decompressor{
 		filePath:  compressedFilePath,
 		FileName1: fName,
+		reads:     readCounter(fName),
 	}
 
 	defer func() {
@@ -644,6 +646,9 @@ func (d *Decompressor) EmptyWordsCount() int { return int(d.emptyWordsCount) }
 // Getter is not thread-safe, but there can be multiple getters used simultaneously and concurrently
 // for the same decompressor
 func (d *Decompressor) MakeGetter() *Getter {
+	if d.reads != nil {
+		d.reads.Add(1)
+	}
 	return &Getter{
 		posDict:     d.posDict,
 		data:        d.data[d.wordsStart:],
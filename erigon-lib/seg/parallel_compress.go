@@ -21,12 +21,14 @@ import (
 	"container/heap"
 	"context"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -304,7 +306,7 @@ func compressWithPatternCandidates(ctx context.Context, trace bool, logPrefix, s
 		return fmt.Errorf("create intermediate file: %w", err)
 	}
 	defer intermediateFile.Close()
-	intermediateW := bufio.NewWriterSize(intermediateFile, 8*etl.BufIOSize)
+	intermediateW := bufio.NewWriterSize(newLimitedWriter(ctx, intermediateFile), 8*etl.BufIOSize)
 
 	var inCount, outCount, emptyWordsCount uint64 // Counters words sent to compression and returned for compression
 	var numBuf [binary.MaxVarintLen64]byte
@@ -463,7 +465,11 @@ func compressWithPatternCandidates(ctx context.Context, trace bool, logPrefix, s
 	}
 	//fmt.Printf("posMap = %v\n", posMap)
 	var patternList PatternList
-	distribution := make([]int, maxPatternLen+1)
+	var maxLen int
+	for _, p := range code2pattern {
+		maxLen = max(maxLen, len(p.word))
+	}
+	distribution := make([]int, maxLen+1)
 	for _, p := range code2pattern {
 		if p.uses > 0 {
 			patternList = append(patternList, p)
@@ -535,7 +541,7 @@ func compressWithPatternCandidates(ctx context.Context, trace bool, logPrefix, s
 	if lvl < log.LvlTrace {
 		logger.Log(lvl, fmt.Sprintf("[%s] Effective dictionary", logPrefix), logCtx...)
 	}
-	cw := bufio.NewWriterSize(cf, 2*etl.BufIOSize)
+	cw := bufio.NewWriterSize(newLimitedWriter(ctx, cf), 2*etl.BufIOSize)
 	// 1-st, output amount of words - just a useful metadata
 	binary.BigEndian.PutUint64(numBuf[:], inCount) // Dictionary size
 	if _, err = cw.Write(numBuf[:8]); err != nil {
@@ -745,8 +751,9 @@ func compressWithPatternCandidates(ctx context.Context, trace bool, logPrefix, s
 // into the collector, using lock to mutual exclusion. At the end (when the input channel is closed),
 // it notifies the waitgroup before exiting, so that the caller known when all work is done
 // No error channels for now
-func extractPatternsInSuperstrings(ctx context.Context, superstringCh chan []byte, dictCollector *etl.Collector, minPatternScore uint64, completion *sync.WaitGroup, logger log.Logger) {
+func extractPatternsInSuperstrings(ctx context.Context, superstringCh chan []byte, dictCollector *etl.Collector, cfg Cfg, completion *sync.WaitGroup, logger log.Logger) {
 	defer completion.Done()
+	minPatternScore, minPatternLen, maxPatternLen := cfg.MinPatternScore, cfg.MinPatternLen, cfg.MaxPatternLen
 	dictVal := make([]byte, 8)
 	dictKey := make([]byte, maxPatternLen)
 	var lcp, sa, inv []int32
@@ -916,7 +923,7 @@ func extractPatternsInSuperstrings(ctx context.Context, superstringCh chan []byt
 	}
 }
 
-func DictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir string, collectors []*etl.Collector, lvl log.Lvl, logger log.Logger) (*DictionaryBuilder, error) {
+func DictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir string, collectors []*etl.Collector, maxDictPatterns int, lvl log.Lvl, logger log.Logger) (*DictionaryBuilder, error) {
 	dictCollector := etl.NewCollector(logPrefix+"_collectDict", tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize), logger)
 	defer dictCollector.Close()
 	dictCollector.LogLvl(lvl)
@@ -931,7 +938,7 @@ func DictionaryBuilderFromCollectors(ctx context.Context, logPrefix, tmpDir stri
 	if err := dictAggregator.finish(); err != nil {
 		return nil, err
 	}
-	db := &DictionaryBuilder{limit: maxDictPatterns} // Only collect 1m words with highest scores
+	db := &DictionaryBuilder{limit: maxDictPatterns} // Only collect words with highest scores
 	if err := dictCollector.Load(nil, "", db.loadFunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
 		return nil, err
 	}
@@ -957,6 +964,40 @@ func PersistDictionary(fileName string, db *DictionaryBuilder) error {
 	return df.Close()
 }
 
+// ReadDictionary - reads dictionary persisted by PersistDictionary. Can be used as Cfg.Dictionary: to re-use pre-trained dictionary for many files
+func ReadDictionary(fileName string) (*DictionaryBuilder, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	db := &DictionaryBuilder{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		scoreStr, wordStr, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("ReadDictionary: %s: unexpected line: %q", fileName, line)
+		}
+		score, err := strconv.ParseUint(scoreStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ReadDictionary: %s: %w", fileName, err)
+		}
+		word, err := hex.DecodeString(wordStr)
+		if err != nil {
+			return nil, fmt.Errorf("ReadDictionary: %s: %w", fileName, err)
+		}
+		db.items = append(db.items, &Pattern{word: word, score: score})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	db.limit = len(db.items)
+	db.Sort()
+	return db, nil
+}
+
 func ReadSimpleFile(fileName string, walker func(v []byte) error) error {
 	// Read keys from the file and generate superstring (with extra byte 0x1 prepended to each character, and with 0x0 0x0 pair inserted between keys and values)
 	// We only consider values with length > 2, because smaller values are not compressible without going into bits
@@ -0,0 +1,42 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package seg
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// readCounters - getters made per file name by all decompressors of the file (re-opened too): access frequency of
+// frozen files, used by cold storage tiering
+var readCounters sync.Map // file name -> *atomic.Uint64
+
+func readCounter(fileName string) *atomic.Uint64 {
+	if c, ok := readCounters.Load(fileName); ok {
+		return c.(*atomic.Uint64)
+	}
+	c, _ := readCounters.LoadOrStore(fileName, new(atomic.Uint64))
+	return c.(*atomic.Uint64)
+}
+
+// Reads - amount of getters made for the file since process start
+func Reads(fileName string) uint64 {
+	if c, ok := readCounters.Load(fileName); ok {
+		return c.(*atomic.Uint64).Load()
+	}
+	return 0
+}
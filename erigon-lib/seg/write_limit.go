@@ -0,0 +1,87 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package seg
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"golang.org/x/time/rate"
+)
+
+const maxWriteBurst = 1024 * 1024
+
+var (
+	writeLimiter atomic.Pointer[rate.Limiter] // nil - no limit
+	writeWaited  atomic.Int64                 // nanoseconds
+)
+
+// SetWriteLimit - limits bandwidth of writing compressed files (and their intermediate files) by all compressors of
+// process: retire and merge of files don't saturate disk of syncing node. 0 - no limit
+func SetWriteLimit(bytesPerSecond datasize.ByteSize) {
+	if bytesPerSecond == 0 {
+		writeLimiter.Store(nil)
+		return
+	}
+	writeLimiter.Store(rate.NewLimiter(rate.Limit(bytesPerSecond), int(min(bytesPerSecond, maxWriteBurst))))
+}
+
+// WriteLimit - current limit of SetWriteLimit, 0 - no limit
+func WriteLimit() datasize.ByteSize {
+	l := writeLimiter.Load()
+	if l == nil {
+		return 0
+	}
+	return datasize.ByteSize(l.Limit())
+}
+
+// WriteThrottled - total time compressors waited for write limit
+func WriteThrottled() time.Duration { return time.Duration(writeWaited.Load()) }
+
+type limitedWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func newLimitedWriter(ctx context.Context, w io.Writer) io.Writer {
+	return &limitedWriter{ctx: ctx, w: w}
+}
+
+func (w *limitedWriter) Write(p []byte) (n int, err error) {
+	l := writeLimiter.Load()
+	if l == nil {
+		return w.w.Write(p)
+	}
+	for len(p) > 0 {
+		chunk := min(len(p), l.Burst())
+		start := time.Now()
+		if err = l.WaitN(w.ctx, chunk); err != nil {
+			return n, err
+		}
+		writeWaited.Add(int64(time.Since(start)))
+		written, err := w.w.Write(p[:chunk])
+		n += written
+		if err != nil {
+			return n, err
+		}
+		p = p[chunk:]
+	}
+	return n, nil
+}
@@ -0,0 +1,54 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package seg
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteLimit(t *testing.T) {
+	defer SetWriteLimit(0)
+
+	var buf bytes.Buffer
+	w := newLimitedWriter(context.Background(), &buf)
+
+	require.Zero(t, WriteLimit())
+	_, err := w.Write(make([]byte, 1024))
+	require.NoError(t, err)
+
+	SetWriteLimit(64 * datasize.KB)
+	require.Equal(t, 64*datasize.KB, WriteLimit())
+	waited := WriteThrottled()
+	start := time.Now()
+	n, err := w.Write(make([]byte, 96*1024)) // burst of 64kb, then 32kb at 64kb/s
+	require.NoError(t, err)
+	require.Equal(t, 96*1024, n)
+	require.Equal(t, 97*1024, buf.Len())
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	require.Greater(t, WriteThrottled(), waited)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = newLimitedWriter(ctx, &buf).Write(make([]byte, 1024))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/bitmapdb"
 	"github.com/ledgerwatch/erigon-lib/kv/iter"
@@ -116,6 +117,10 @@ func NewAggregator(ctx context.Context, dirs datadir.Dirs, aggregationStep uint6
 		return nil, err
 	}
 
+	for _, dir := range []string{dirs.SnapDomain, dirs.SnapHistory, dirs.SnapIdx, dirs.SnapAccessors} {
+		disk.Tag(dir, disk.Domains)
+	}
+
 	ctx, ctxCancel := context.WithCancel(ctx)
 	a := &Aggregator{
 		ctx:                    ctx,
@@ -706,6 +711,10 @@ func (a *Aggregator) mergeLoopStep(ctx context.Context) (somethingDone bool, err
 
 func (a *Aggregator) MergeLoop(ctx context.Context) error {
 	for {
+		if err := disk.CheckFreeSpace(disk.FreeSpaceLow); err != nil {
+			a.logger.Debug("[snapshots] merge paused", "err", err)
+			return nil
+		}
 		somethingMerged, err := a.mergeLoopStep(ctx)
 		if err != nil {
 			return err
@@ -1503,6 +1512,12 @@ func (a *Aggregator) BuildFilesInBackground(txNum uint64) chan struct{} {
 		return fin
 	}
 
+	if err := disk.CheckFreeSpace(disk.FreeSpaceLow); err != nil {
+		a.logger.Debug("[snapshots] build files paused", "err", err)
+		close(fin)
+		return fin
+	}
+
 	if ok := a.buildingFiles.CompareAndSwap(false, true); !ok {
 		close(fin)
 		return fin
@@ -27,7 +27,7 @@ type dataLookupFunc func(di uint64, g ArchiveGetter) ([]byte, []byte, error)
 type keyCmpFunc func(k []byte, di uint64, g ArchiveGetter) (int, []byte, error)
 
 func NewBpsTree(kv ArchiveGetter, offt *eliasfano32.EliasFano, M uint64, dataLookup dataLookupFunc, keyCmp keyCmpFunc) *BpsTree {
-	bt := &BpsTree{M: M, offt: offt, dataLookupFunc: dataLookup, keyCmpFunc: keyCmp}
+	bt := &BpsTree{M: M, offt: offt, dataLookupFunc: dataLookup, keyCmpFunc: keyCmp, cacheID: btTreeID.Add(1)}
 	if err := bt.WarmUp(kv); err != nil {
 		panic(err)
 	}
@@ -40,6 +40,8 @@ type BpsTree struct {
 	M     uint64
 	trace bool
 
+	cacheID uint64 // key of pages in btPageCache
+
 	dataLookupFunc dataLookupFunc
 	keyCmpFunc     keyCmpFunc
 }
@@ -216,12 +218,16 @@ func (b *BpsTree) Seek(g ArchiveGetter, key []byte) (skey []byte, di uint64, fou
 		fmt.Printf("pivot %d n %x [%d %d]\n", n.di, n.prefix, dl, dr)
 	}
 	l, r = dl, dr
+	p, err := b.page(g, dl, dr)
+	if err != nil {
+		return nil, 0, false, err
+	}
 
 	var m uint64
 	var cmp int
 	for l < r {
 		m = (l + r) >> 1
-		cmp, skey, err = b.keyCmpFunc(key, m, g)
+		cmp, skey, err = b.cmp(p, key, m, g)
 		if err != nil {
 			return nil, 0, false, err
 		}
@@ -244,7 +250,7 @@ func (b *BpsTree) Seek(g ArchiveGetter, key []byte) (skey []byte, di uint64, fou
 		//return &BpsTreeIterator{t: b, i: l}, nil
 	}
 
-	cmp, skey, err = b.keyCmpFunc(key, m, g)
+	cmp, skey, err = b.cmp(p, key, m, g)
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -278,10 +284,14 @@ func (b *BpsTree) Get(g ArchiveGetter, key []byte) ([]byte, bool, uint64, error)
 		fmt.Printf("pivot %d n %x [%d %d]\n", n.di, n.prefix, dl, dr)
 	}
 	l, r = dl, dr
+	p, err := b.page(g, dl, dr)
+	if err != nil {
+		return nil, false, 0, err
+	}
 	var m uint64
 	for l < r {
 		m = (l + r) >> 1
-		cmp, k, err := b.keyCmpFunc(key, m, g)
+		cmp, k, err := b.cmp(p, key, m, g)
 		if err != nil {
 			return nil, false, 0, err
 		}
@@ -299,7 +309,7 @@ func (b *BpsTree) Get(g ArchiveGetter, key []byte) ([]byte, bool, uint64, error)
 		}
 	}
 
-	cmp, k, err := b.keyCmpFunc(key, l, g)
+	cmp, k, err := b.cmp(p, key, l, g)
 	if err != nil || cmp != 0 {
 		return nil, false, 0, err
 	}
@@ -0,0 +1,250 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var (
+	mxBtPageCacheHit   = metrics.GetOrCreateCounter(`bt_page_cache_total{result="hit"}`)
+	mxBtPageCacheMiss  = metrics.GetOrCreateCounter(`bt_page_cache_total{result="miss"}`)
+	mxBtPageCacheBytes = metrics.GetOrCreateGauge(`bt_page_cache_bytes`)
+)
+
+// btPages - keys of BpsTree leaf pages (range of keys between 2 nodes of `mx`), shared by all .bt files of process.
+// Without cache every lookup does log2(M) random reads of .kv file - on HDD it's log2(M) seeks.
+// With cache: page is read once by 1 sequential read, then binary search happens in memory. 0 - disabled.
+var btPages = newBtPageCache(uint64(dbg.EnvDataSize("BT_PAGE_CACHE", 0)))
+
+// SetBtreePageCache - limit of memory used by cache of .bt index pages. 0 - disabled.
+func SetBtreePageCache(limit datasize.ByteSize) { btPages.setLimit(uint64(limit)) }
+
+// btreeFanouts - per-domain override of DefaultBtreeM. Bigger M - less memory for `mx` but bigger pages (more reads without cache).
+// M is not stored in .bt file - can be changed between restarts.
+var btreeFanouts = mustParseBtreeFanouts(dbg.EnvString("BT_FANOUT", ""))
+
+// SetBtreeFanouts - format: "accounts=128,storage=512". Applied to files opened after the call.
+func SetBtreeFanouts(s string) error {
+	fanouts, err := ParseBtreeFanouts(s)
+	if err != nil {
+		return err
+	}
+	btreeFanouts = fanouts
+	return nil
+}
+
+func ParseBtreeFanouts(s string) (map[string]uint64, error) {
+	res := map[string]uint64{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("btree fanout: expected <domain>=<M>, got %q", part)
+		}
+		m, err := strconv.ParseUint(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("btree fanout %q: %w", part, err)
+		}
+		if m < 4 {
+			return nil, fmt.Errorf("btree fanout %q: M must be >= 4", part)
+		}
+		res[strings.TrimSpace(name)] = m
+	}
+	return res, nil
+}
+
+func mustParseBtreeFanouts(s string) map[string]uint64 {
+	res, err := ParseBtreeFanouts(s)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func btreeFanout(filenameBase string) uint64 {
+	if m, ok := btreeFanouts[filenameBase]; ok {
+		return m
+	}
+	return DefaultBtreeM
+}
+
+// btPageMaxKeys - in M units. `bs` may return wide range for small files - read such ranges as before, without caching
+const btPageMaxKeys = 4
+
+var btTreeID atomic.Uint64
+
+type btPageKey struct {
+	tree uint64 // BpsTree.cacheID
+	from uint64 // di of first key of page
+}
+
+type btPage struct {
+	from uint64
+	keys [][]byte
+	size uint64
+}
+
+func (p *btPage) has(di uint64) bool {
+	return p != nil && di >= p.from && di < p.from+uint64(len(p.keys))
+}
+
+// btPageSizeOverhead - approximate memory of page's bookkeeping: slice headers, lru element, map entry
+const btPageSizeOverhead = 128
+
+func newBtPage(from uint64, keys [][]byte) *btPage {
+	p := &btPage{from: from, keys: keys, size: btPageSizeOverhead}
+	for _, k := range keys {
+		p.size += uint64(len(k)) + 24
+	}
+	return p
+}
+
+type btPageCache struct {
+	lock  sync.Mutex
+	limit uint64
+	used  uint64
+	lru   *simplelru.LRU[btPageKey, *btPage]
+}
+
+func newBtPageCache(limit uint64) *btPageCache {
+	c := &btPageCache{limit: limit}
+	// size is limited by bytes, not by amount of pages
+	c.lru, _ = simplelru.NewLRU[btPageKey, *btPage](math.MaxInt, func(_ btPageKey, p *btPage) {
+		c.used -= p.size
+	})
+	return c
+}
+
+func (c *btPageCache) enabled() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.limit > 0
+}
+
+func (c *btPageCache) setLimit(limit uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.limit = limit
+	c.evict()
+}
+
+// evict - must be called under lock
+func (c *btPageCache) evict() {
+	for c.used > c.limit {
+		if _, _, ok := c.lru.RemoveOldest(); !ok {
+			break
+		}
+	}
+	mxBtPageCacheBytes.SetUint64(c.used)
+}
+
+func (c *btPageCache) get(k btPageKey) (*btPage, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	p, ok := c.lru.Get(k)
+	if ok {
+		mxBtPageCacheHit.Inc()
+	} else {
+		mxBtPageCacheMiss.Inc()
+	}
+	return p, ok
+}
+
+func (c *btPageCache) put(k btPageKey, p *btPage) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if p.size > c.limit {
+		return
+	}
+	c.lru.Add(k, p) // replaced page is passed to evict-callback
+	c.used += p.size
+	c.evict()
+}
+
+// removeTree - drop pages of closed file. Ids are never re-used - it's only to free memory early
+func (c *btPageCache) removeTree(tree uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, k := range c.lru.Keys() {
+		if k.tree == tree {
+			c.lru.Remove(k)
+		}
+	}
+	mxBtPageCacheBytes.SetUint64(c.used)
+}
+
+// page - returns page which has keys [dl, dr] (dr may be out of file). nil - if cache disabled or range too wide.
+func (b *BpsTree) page(g ArchiveGetter, dl, dr uint64) (*btPage, error) {
+	if dr <= dl || dr-dl > btPageMaxKeys*b.M || !btPages.enabled() {
+		return nil, nil
+	}
+	if cnt := b.offt.Count(); dr >= cnt {
+		dr = cnt - 1
+	}
+	k := btPageKey{tree: b.cacheID, from: dl}
+	if p, ok := btPages.get(k); ok && p.has(dr) {
+		return p, nil
+	}
+
+	keys := make([][]byte, 0, dr-dl+1)
+	g.Reset(b.offt.Get(dl))
+	for di := dl; di <= dr; di++ {
+		if !g.HasNext() {
+			return nil, fmt.Errorf("key at %d/%d not found, file: %s", di, b.offt.Count(), g.FileName())
+		}
+		key, _ := g.Next(nil)
+		keys = append(keys, key)
+		if di < dr {
+			g.Skip()
+		}
+	}
+	p := newBtPage(dl, keys)
+	btPages.put(k, p)
+	return p, nil
+}
+
+// cmp - same as keyCmpFunc, but uses page if it has `di`
+func (b *BpsTree) cmp(p *btPage, key []byte, di uint64, g ArchiveGetter) (int, []byte, error) {
+	if p.has(di) {
+		k := p.keys[di-p.from]
+		return bytes.Compare(k, key), k, nil
+	}
+	return b.keyCmpFunc(key, di, g)
+}
+
+func (b *BpsTree) Close() {
+	if b == nil {
+		return
+	}
+	btPages.removeTree(b.cacheID)
+}
@@ -945,6 +945,7 @@ func (b *BtIndex) Close() {
 	if b == nil {
 		return
 	}
+	b.bplus.Close()
 	if b.m != nil {
 		if err := b.m.Unmap(); err != nil {
 			log.Log(dbg.FileCloseLogLevel, "unmap", "err", err, "file", b.FileName(), "stack", dbg.Stack())
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/c2h5oh/datasize"
 	bloomfilter "github.com/holiman/bloomfilter/v2"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/require"
@@ -358,3 +359,77 @@ func (b *mockIndexReader) keyCmp(k []byte, di uint64, g ArchiveGetter) (int, []b
 	return bytes.Compare(res, k), res, nil
 	//return b.getter.Match(k), result, nil
 }
+
+func TestBtreeIndex_PageCache(t *testing.T) {
+	tmp := t.TempDir()
+	logger := log.New()
+	keyCount, M := 10_000, 64
+
+	compressFlags := CompressKeys | CompressVals
+	dataPath := generateKV(t, tmp, 52, 48, keyCount, logger, compressFlags)
+	keys, err := pivotKeysFromKV(dataPath)
+	require.NoError(t, err)
+
+	indexPath := path.Join(tmp, filepath.Base(dataPath)+".bti")
+	buildBtreeIndex(t, dataPath, indexPath, compressFlags, 1, logger, true)
+
+	kv, bt, err := OpenBtreeIndexAndDataFile(indexPath, dataPath, uint64(M), compressFlags, false)
+	require.NoError(t, err)
+	defer kv.Close()
+	getter := NewArchiveGetter(kv.MakeGetter(), compressFlags)
+
+	// absent keys: between existing ones and after the last one
+	lookups := make([][]byte, 0, len(keys)*2+1)
+	for _, k := range keys {
+		alt := append(common.Copy(k), 0x01)
+		lookups = append(lookups, k, alt)
+	}
+	lookups = append(lookups, common.FromHex("0xffffffffffffff"))
+
+	type result struct {
+		k, v  []byte
+		found bool
+		seekK []byte
+	}
+	lookupAll := func() []result {
+		res := make([]result, len(lookups))
+		for i, x := range lookups {
+			res[i].k, res[i].v, res[i].found, err = bt.Get(x, getter)
+			require.NoError(t, err)
+			c, err := bt.Seek(getter, x)
+			require.NoError(t, err)
+			if c != nil {
+				res[i].seekK = c.Key()
+			}
+		}
+		return res
+	}
+	noCache := lookupAll()
+
+	SetBtreePageCache(1 * datasize.MB)
+	defer SetBtreePageCache(0)
+	hits := mxBtPageCacheHit.GetValueUint64()
+	require.Equal(t, noCache, lookupAll())
+	require.Equal(t, noCache, lookupAll())
+	require.Greater(t, mxBtPageCacheHit.GetValueUint64(), hits)
+	require.NotZero(t, btPages.used)
+
+	// small limit: pages are evicted, results are the same
+	SetBtreePageCache(4 * datasize.KB)
+	require.LessOrEqual(t, btPages.used, uint64(4*datasize.KB))
+	require.Equal(t, noCache, lookupAll())
+
+	bt.Close()
+	require.Zero(t, btPages.used)
+}
+
+func TestParseBtreeFanouts(t *testing.T) {
+	fanouts, err := ParseBtreeFanouts("accounts=128, storage=512,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"accounts": 128, "storage": 512}, fanouts)
+
+	_, err = ParseBtreeFanouts("accounts")
+	require.Error(t, err)
+	_, err = ParseBtreeFanouts("accounts=2")
+	require.Error(t, err)
+}
@@ -97,6 +97,7 @@ type Domain struct {
 	stats       DomainStats
 	compression FileCompression
 	indexList   idxList
+	btreeM      uint64 // fanout of .bt index: DefaultBtreeM or per-domain override
 }
 
 type domainCfg struct {
@@ -115,6 +116,7 @@ func NewDomain(cfg domainCfg, aggregationStep uint64, filenameBase, keysTable, v
 		keysTable:   keysTable,
 		valsTable:   valsTable,
 		compression: cfg.compress,
+		btreeM:      btreeFanout(filenameBase),
 		dirtyFiles:  btree2.NewBTreeGOptions[*filesItem](filesItemLess, btree2.Options{Degree: 128, NoLocks: false}),
 		stats:       DomainStats{FilesQueries: &atomic.Uint64{}, TotalQueries: &atomic.Uint64{}},
 
@@ -361,7 +363,7 @@ func (d *Domain) openFiles() (err error) {
 			if item.bindex == nil {
 				fPath := d.kvBtFilePath(fromStep, toStep)
 				if dir.FileExist(fPath) {
-					if item.bindex, err = OpenBtreeIndexWithDecompressor(fPath, DefaultBtreeM, item.decompressor, d.compression); err != nil {
+					if item.bindex, err = OpenBtreeIndexWithDecompressor(fPath, d.btreeM, item.decompressor, d.compression); err != nil {
 						_, fName := filepath.Split(fPath)
 						d.logger.Warn("[agg] Domain.openFiles", "err", err, "f", fName)
 						// don't interrupt on error. other files may be good
@@ -970,7 +972,7 @@ func (d *Domain) buildFiles(ctx context.Context, step uint64, collation Collatio
 
 	{
 		btPath := d.kvBtFilePath(step, step+1)
-		bt, err = CreateBtreeIndexWithDecompressor(btPath, DefaultBtreeM, valuesDecomp, d.compression, *d.salt, ps, d.dirs.Tmp, d.logger, d.noFsync)
+		bt, err = CreateBtreeIndexWithDecompressor(btPath, d.btreeM, valuesDecomp, d.compression, *d.salt, ps, d.dirs.Tmp, d.logger, d.noFsync)
 		if err != nil {
 			return StaticFiles{}, fmt.Errorf("build %s .bt idx: %w", d.filenameBase, err)
 		}
@@ -1193,7 +1195,9 @@ func (dt *DomainRoTx) Unwind(ctx context.Context, rwTx kv.RwTx, step, txNumUnwin
 		return fmt.Errorf("historyRange %s: %w", dt.ht.h.filenameBase, err)
 	}
 
-	seen := make(map[string]struct{})
+	// only keys changed after txNumUnwindTo are visited: no full scan of domain keys table,
+	// unwind cost depends on amount of changes, not on size of state
+	var changed [][]byte
 	restored := dt.NewWriter()
 
 	for histRng.HasNext() && txNumUnwindTo > 0 {
@@ -1223,13 +1227,9 @@ func (dt *DomainRoTx) Unwind(ctx context.Context, rwTx kv.RwTx, step, txNumUnwin
 			Close()
 		}
 		ic.(closable).Close()
-		seen[string(k)] = struct{}{}
+		changed = append(changed, common.Copy(k))
 	}
 
-	keysCursor, err := dt.keysCursor(rwTx)
-	if err != nil {
-		return err
-	}
 	keysCursorForDeletes, err := rwTx.RwCursorDupSort(d.keysTable)
 	if err != nil {
 		return fmt.Errorf("create %s domain delete cursor: %w", d.filenameBase, err)
@@ -1243,37 +1243,22 @@ func (dt *DomainRoTx) Unwind(ctx context.Context, rwTx kv.RwTx, step, txNumUnwin
 	}
 	defer valsC.Close()
 
-	stepBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(stepBytes, ^step)
-	var k, v []byte
-
-	for k, v, err = keysCursor.First(); k != nil; k, v, err = keysCursor.Next() {
-		if err != nil {
-			return fmt.Errorf("iterate over %s domain keys: %w", d.filenameBase, err)
-		}
-		if !bytes.Equal(v, stepBytes) {
-			continue
-		}
-		if _, replaced := seen[string(k)]; !replaced && txNumUnwindTo > 0 {
-			continue
-		}
-
-		kk, _, err := valsC.SeekExact(common.Append(k, stepBytes))
+	if txNumUnwindTo == 0 {
+		keysCursor, err := dt.keysCursor(rwTx)
 		if err != nil {
 			return err
 		}
-		if kk != nil {
-			//fmt.Printf("[domain][%s] rm large value %x v %x\n", d.filenameBase, kk, vv)
-			if err = valsC.DeleteCurrent(); err != nil {
-				return err
+		for k, _, err := keysCursor.First(); k != nil; k, _, err = keysCursor.NextNoDup() {
+			if err != nil {
+				return fmt.Errorf("iterate over %s domain keys: %w", d.filenameBase, err)
 			}
+			changed = append(changed, common.Copy(k))
 		}
+	}
 
-		// This DeleteCurrent needs to the last in the loop iteration, because it invalidates k and v
-		if _, _, err = keysCursorForDeletes.SeekBothExact(k, v); err != nil {
-			return err
-		}
-		if err = keysCursorForDeletes.DeleteCurrent(); err != nil {
+	// values of all steps starting from unwind step are removed (unwind may cross steps boundary), restored values are written below
+	for _, k := range changed {
+		if err := dt.unwindKey(k, step, keysCursorForDeletes, valsC); err != nil {
 			return err
 		}
 	}
@@ -1286,6 +1271,32 @@ func (dt *DomainRoTx) Unwind(ctx context.Context, rwTx kv.RwTx, step, txNumUnwin
 	return restored.Flush(ctx, rwTx)
 }
 
+// unwindKey - removes all versions of `k` which belong to `step` or newer steps
+func (dt *DomainRoTx) unwindKey(k []byte, step uint64, keysC kv.RwCursorDupSort, valsC kv.RwCursor) error {
+	for {
+		_, invStep, err := keysC.SeekExact(k)
+		if err != nil {
+			return err
+		}
+		// dups are sorted by inverted step: newest step goes first
+		if invStep == nil || ^binary.BigEndian.Uint64(invStep) < step {
+			return nil
+		}
+		kk, _, err := valsC.SeekExact(common.Append(k, invStep))
+		if err != nil {
+			return err
+		}
+		if kk != nil {
+			if err = valsC.DeleteCurrent(); err != nil {
+				return err
+			}
+		}
+		if err = keysC.DeleteCurrent(); err != nil {
+			return err
+		}
+	}
+}
+
 func (d *Domain) isEmpty(tx kv.Tx) (bool, error) {
 	k, err := kv.FirstKey(tx, d.keysTable)
 	if err != nil {
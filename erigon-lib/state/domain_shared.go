@@ -14,15 +14,20 @@ import (
 	"unsafe"
 
 	btree2 "github.com/tidwall/btree"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/sha3"
 
 	"github.com/ledgerwatch/erigon-lib/commitment"
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/assert"
+	"github.com/ledgerwatch/erigon-lib/common/cryptozerocopy"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/order"
 	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 	"github.com/ledgerwatch/erigon-lib/types"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -74,6 +79,8 @@ type SharedDomains struct {
 	logTopicsWriter  *invertedIndexBufferedWriter
 	tracesFromWriter *invertedIndexBufferedWriter
 	tracesToWriter   *invertedIndexBufferedWriter
+
+	reads, writes tracing.Timer // domain reads (not from RAM) and writes, attributes of block span
 }
 
 type HasAggCtx interface {
@@ -382,7 +389,7 @@ func (sd *SharedDomains) replaceShortenedKeysInBranch(prefix []byte, branch comm
 
 const CodeSizeTableFake = "CodeSize"
 
-func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) bool {
+func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) (bool, error) {
 	//sd.muMaps.RLock()
 	//defer sd.muMaps.RUnlock()
 
@@ -393,7 +400,7 @@ func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) bool {
 			for i, key := range list.Keys {
 				if val, ok := m[key]; ok {
 					if !bytes.Equal(list.Vals[i], val) {
-						return false
+						return false, nil
 					}
 				}
 			}
@@ -402,7 +409,7 @@ func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) bool {
 			for i, key := range list.Keys {
 				if val, ok := m[key]; ok {
 					if !bytes.Equal(list.Vals[i], val) {
-						return false
+						return false, nil
 					}
 				}
 			}
@@ -411,7 +418,7 @@ func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) bool {
 			for i, key := range list.Keys {
 				if val, ok := m.Get(key); ok {
 					if !bytes.Equal(list.Vals[i], val) {
-						return false
+						return false, nil
 					}
 				}
 			}
@@ -420,16 +427,68 @@ func (sd *SharedDomains) ReadsValid(readLists map[string]*KvList) bool {
 			for i, key := range list.Keys {
 				if val, ok := m[key]; ok {
 					if binary.BigEndian.Uint64(list.Vals[i]) != uint64(len(val)) {
-						return false
+						return false, nil
 					}
 				}
 			}
 		default:
-			panic(table)
+			return false, fmt.Errorf("unknown table of read list: %s", table)
+		}
+	}
+
+	return true, nil
+}
+
+// LatestReadsValid - checks that all values of `readLists` are equal to latest values. Unlike ReadsValid,
+// checks also values which are not updated in memory: for reads done not by `sd` itself (for example, from other RoTx)
+func (sd *SharedDomains) LatestReadsValid(readLists map[string]*KvList) (bool, error) {
+	for table, list := range readLists {
+		var domain kv.Domain
+		switch table {
+		case kv.AccountsDomain.String():
+			domain = kv.AccountsDomain
+		case kv.CodeDomain.String(), CodeSizeTableFake:
+			domain = kv.CodeDomain
+		case kv.StorageDomain.String():
+			domain = kv.StorageDomain
+		default:
+			return false, fmt.Errorf("unknown table of read list: %s", table)
+		}
+		for i, key := range list.Keys {
+			val, _, err := sd.DomainGet(domain, []byte(key), nil)
+			if err != nil {
+				return false, err
+			}
+			if table == CodeSizeTableFake {
+				if binary.BigEndian.Uint64(list.Vals[i]) != uint64(len(val)) {
+					return false, nil
+				}
+				continue
+			}
+			if !bytes.Equal(list.Vals[i], val) {
+				return false, nil
+			}
 		}
 	}
+	return true, nil
+}
 
-	return true
+// DomainGetWithTx - same as DomainGet, but values which are not in memory are read from given temporal `roTx`.
+// Doesn't modify `sd`: safe for concurrent use (each goroutine with own `roTx`) while `sd` is not updated.
+// Values flushed by `sd` to not-committed tx are not visible: result may be stale
+func (sd *SharedDomains) DomainGetWithTx(domain kv.Domain, k []byte, roTx kv.Tx) (v []byte, err error) {
+	if v, ok := sd.get(domain, k); ok {
+		return v, nil
+	}
+	casted, ok := roTx.(HasAggCtx)
+	if !ok {
+		return nil, fmt.Errorf("type %T need AggCtx method", roTx)
+	}
+	v, _, _, err = casted.AggCtx().(*AggregatorRoTx).GetLatest(domain, k, nil, roTx)
+	if err != nil {
+		return nil, fmt.Errorf("%s %x read error: %w", domain, k, err)
+	}
+	return v, nil
 }
 
 func (sd *SharedDomains) updateAccountData(addr []byte, account, prevAccount []byte, prevStep uint64) error {
@@ -546,9 +605,58 @@ func (sd *SharedDomains) SetTrace(b bool) {
 }
 
 func (sd *SharedDomains) ComputeCommitment(ctx context.Context, saveStateAfter bool, blockNum uint64, logPrefix string) (rootHash []byte, err error) {
+	ctx, span := tracing.Start(ctx, "commitment", attribute.Int64("block", int64(blockNum)), attribute.Int64("keys", int64(sd.sdCtx.updates.Size())))
+	defer func() { tracing.End(span, err) }()
 	return sd.sdCtx.ComputeCommitment(ctx, saveStateAfter, blockNum, logPrefix)
 }
 
+// ProveHashedKeys - nodes of commitment trie on the paths of hashed keys (nibbles), see HexPatriciaHashed.ProveHashedKeys.
+// Proves the last computed commitment, so domains must not have pending updates
+func (sd *SharedDomains) ProveHashedKeys(ctx context.Context, hashedKeys [][]byte) (rootHash []byte, nodes map[common.Hash][]byte, err error) {
+	hph, err := sd.sdCtx.hexPatriciaHashed()
+	if err != nil {
+		return nil, nil, err
+	}
+	return hph.ProveHashedKeys(ctx, hashedKeys)
+}
+
+// WalkCommitmentLeaves - visits accounts or storage of account in order of hashed keys, see HexPatriciaHashed.WalkLeaves
+func (sd *SharedDomains) WalkCommitmentLeaves(account, from []byte, visit commitment.LeafVisitor) error {
+	hph, err := sd.sdCtx.hexPatriciaHashed()
+	if err != nil {
+		return err
+	}
+	return hph.WalkLeaves(account, from, visit)
+}
+
+// CommitmentAsOf restores the commitment trie as it was at txNum: the one of the last block committed before txNum. The
+// trie reads the domains as of txNum and can only be proven or walked, not updated. Branches have no history in files,
+// so txNum has to be within the range the domains can be unwound to.
+func (sd *SharedDomains) CommitmentAsOf(txNum uint64) (hph *commitment.HexPatriciaHashed, blockNum uint64, err error) {
+	ctx := &commitmentAsOfContext{sd: sd, txNum: txNum, keccak: sha3.NewLegacyKeccak256().(cryptozerocopy.KeccakState)}
+	state, _, err := ctx.GetBranch(keyCommitmentState)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(state) == 0 {
+		return nil, 0, fmt.Errorf("no commitment state as of txNum %d", txNum)
+	}
+	cs := new(commitmentState)
+	if err := cs.Decode(state); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode commitment state as of txNum %d: %w", txNum, err)
+	}
+	hph = commitment.NewHexPatriciaHashed(length.Addr, ctx)
+	if err := hph.SetState(cs.trieState); err != nil {
+		return nil, 0, fmt.Errorf("failed restore state : %w", err)
+	}
+	return hph, cs.blockNum, nil
+}
+
+// TraceAttributes - count and duration of domain reads and writes since previous call
+func (sd *SharedDomains) TraceAttributes() []attribute.KeyValue {
+	return append(sd.reads.Attributes("domain.reads"), sd.writes.Attributes("domain.writes")...)
+}
+
 // IterateStoragePrefix iterates over key-value pairs of the storage domain that start with given prefix
 // Such iteration is not intended to be used in public API, therefore it uses read-write transaction
 // inside the domain. Another version of this for public API use needs to be created, that uses
@@ -723,13 +831,16 @@ func (sd *SharedDomains) Close() {
 	}
 }
 
-func (sd *SharedDomains) Flush(ctx context.Context, tx kv.RwTx) error {
+func (sd *SharedDomains) Flush(ctx context.Context, tx kv.RwTx) (err error) {
 	if sd.noFlush > 0 {
 		sd.noFlush--
 	}
 
 	if sd.noFlush == 0 {
 		defer mxFlushTook.ObserveDuration(time.Now())
+		var span trace.Span
+		ctx, span = tracing.Start(ctx, "domains flush", attribute.Int64("block", int64(sd.BlockNum())))
+		defer func() { tracing.End(span, err) }()
 		fh, err := sd.ComputeCommitment(ctx, true, sd.BlockNum(), "flush-commitment")
 		if err != nil {
 			return err
@@ -789,6 +900,7 @@ func (sd *SharedDomains) DomainGet(domain kv.Domain, k, k2 []byte) (v []byte, st
 	if v, ok := sd.get(domain, k); ok {
 		return v, 0, nil
 	}
+	defer sd.reads.Observe(sd.reads.Start())
 	v, step, _, err = sd.aggCtx.GetLatest(domain, k, nil, sd.roTx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("storage %x read error: %w", k, err)
@@ -805,6 +917,7 @@ func (sd *SharedDomains) DomainPut(domain kv.Domain, k1, k2 []byte, val, prevVal
 	if val == nil {
 		return fmt.Errorf("DomainPut: %s, trying to put nil value. not allowed", domain)
 	}
+	defer sd.writes.Observe(sd.writes.Start())
 	if prevVal == nil {
 		var err error
 		prevVal, prevStep, err = sd.DomainGet(domain, k1, k2)
@@ -928,6 +1041,88 @@ type cachedBranch struct {
 }
 
 // Cache should ResetBranchCache after each commitment computation
+// commitmentAsOfContext - read-only PatriciaContext of the domains as of txNum: values are read from the history, or
+// are the latest ones if they didn't change since txNum
+type commitmentAsOfContext struct {
+	sd     *SharedDomains
+	txNum  uint64
+	keccak cryptozerocopy.KeccakState
+}
+
+func (c *commitmentAsOfContext) GetBranch(pref []byte) ([]byte, uint64, error) {
+	v, ok, err := c.sd.aggCtx.d[kv.CommitmentDomain].ht.GetNoStateWithRecent(pref, c.txNum, c.sd.roTx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetBranch failed: %w", err)
+	}
+	if ok {
+		// empty value marks the branch created after txNum
+		return v, 0, nil
+	}
+	v, step, err := c.sd.LatestCommitment(pref)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetBranch failed: %w", err)
+	}
+	return v, step, nil
+}
+
+func (c *commitmentAsOfContext) GetAccount(plainKey []byte, cell *commitment.Cell) error {
+	encAccount, err := c.sd.aggCtx.d[kv.AccountsDomain].GetAsOf(plainKey, c.txNum, c.sd.roTx)
+	if err != nil {
+		return fmt.Errorf("GetAccount failed: %w", err)
+	}
+	cell.Nonce = 0
+	cell.Balance.Clear()
+	if len(encAccount) > 0 {
+		nonce, balance, _ := types.DecodeAccountBytesV3(encAccount)
+		cell.Nonce = nonce
+		cell.Balance.Set(balance)
+	}
+
+	code, err := c.sd.aggCtx.d[kv.CodeDomain].GetAsOf(plainKey, c.txNum, c.sd.roTx)
+	if err != nil {
+		return fmt.Errorf("GetAccount: failed to read code: %w", err)
+	}
+	if len(code) > 0 {
+		c.keccak.Reset()
+		c.keccak.Write(code)
+		c.keccak.Read(cell.CodeHash[:])
+	} else {
+		cell.CodeHash = commitment.EmptyCodeHashArray
+	}
+	cell.Delete = len(encAccount) == 0 && len(code) == 0
+	return nil
+}
+
+func (c *commitmentAsOfContext) GetStorage(plainKey []byte, cell *commitment.Cell) error {
+	enc, err := c.sd.aggCtx.d[kv.StorageDomain].GetAsOf(plainKey, c.txNum, c.sd.roTx)
+	if err != nil {
+		return err
+	}
+	cell.StorageLen = len(enc)
+	copy(cell.Storage[:], enc)
+	cell.Delete = cell.StorageLen == 0
+	return nil
+}
+
+func (c *commitmentAsOfContext) TempDir() string {
+	return c.sd.aggCtx.a.dirs.Tmp
+}
+
+func (c *commitmentAsOfContext) PutBranch(prefix []byte, data []byte, prevData []byte, prevStep uint64) error {
+	return fmt.Errorf("commitment as of txNum %d is read-only, could not put branch %x", c.txNum, prefix)
+}
+
+func (sdc *SharedDomainsCommitmentContext) hexPatriciaHashed() (*commitment.HexPatriciaHashed, error) {
+	if sdc.updates.Size() > 0 {
+		return nil, fmt.Errorf("commitment has %d pending updates", sdc.updates.Size())
+	}
+	hph, ok := sdc.patriciaTrie.(*commitment.HexPatriciaHashed)
+	if !ok {
+		return nil, fmt.Errorf("unsupported patricia trie type: %T", sdc.patriciaTrie)
+	}
+	return hph, nil
+}
+
 func (sdc *SharedDomainsCommitmentContext) ResetBranchCache() {
 	sdc.branchCache = make(map[string]cachedBranch)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/types"
@@ -489,3 +490,86 @@ func TestSharedDomain_StorageIter(t *testing.T) {
 	domains.Close()
 	ac.Close()
 }
+
+func TestSharedDomain_LatestReadsValid(t *testing.T) {
+	stepSize := uint64(100)
+	db, agg := testDbAndAggregatorv3(t, stepSize)
+
+	ctx := context.Background()
+	rwTx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer rwTx.Rollback()
+
+	ac := agg.BeginFilesRo()
+	defer ac.Close()
+
+	domains, err := NewSharedDomains(WrapTxWithCtx(rwTx, ac), log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+
+	k1, k2 := make([]byte, length.Addr), make([]byte, length.Addr)
+	k1[0], k2[0] = 1, 2
+	v1 := types.EncodeAccountBytesV3(1, uint256.NewInt(10), nil, 0)
+	v2 := types.EncodeAccountBytesV3(2, uint256.NewInt(20), nil, 0)
+	v3 := types.EncodeAccountBytesV3(3, uint256.NewInt(30), nil, 0)
+
+	domains.SetTxNum(1)
+	require.NoError(t, domains.DomainPut(kv.AccountsDomain, k1, nil, v1, nil, 0))
+	require.NoError(t, domains.Flush(ctx, rwTx))
+	domains.Close()
+	require.NoError(t, rwTx.Commit())
+
+	rwTx, err = db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer rwTx.Rollback()
+	ac = agg.BeginFilesRo()
+	defer ac.Close()
+	domains, err = NewSharedDomains(WrapTxWithCtx(rwTx, ac), log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+	domains.SetTxNum(2)
+	require.NoError(t, domains.DomainPut(kv.AccountsDomain, k2, nil, v2, nil, 0))
+
+	// k1 - from other RoTx, k2 - from memory. RoTx can't overlap with RwTx in same thread: read in other goroutine
+	var got1, got2 []byte
+	g := errgroup.Group{}
+	g.Go(func() error {
+		return db.View(ctx, func(roTx kv.Tx) (err error) {
+			roAc := agg.BeginFilesRo()
+			defer roAc.Close()
+			if got1, err = domains.DomainGetWithTx(kv.AccountsDomain, k1, WrapTxWithCtx(roTx, roAc)); err != nil {
+				return err
+			}
+			got2, err = domains.DomainGetWithTx(kv.AccountsDomain, k2, WrapTxWithCtx(roTx, roAc))
+			return err
+		})
+	})
+	require.NoError(t, g.Wait())
+	require.Equal(t, v1, got1)
+	require.Equal(t, v2, got2)
+
+	reads := map[string]*KvList{kv.AccountsDomain.String(): {}, kv.StorageDomain.String(): {}}
+	reads[kv.AccountsDomain.String()].Push(string(k1), got1)
+	reads[kv.AccountsDomain.String()].Push(string(k2), got2)
+	ok, err := domains.LatestReadsValid(reads)
+	require.NoError(t, err)
+	require.True(t, ok)
+	valid, err := domains.ReadsValid(reads)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// k1 updated after it was read
+	domains.SetTxNum(3)
+	require.NoError(t, domains.DomainPut(kv.AccountsDomain, k1, nil, v3, v1, 0))
+	require.NoError(t, domains.Flush(ctx, rwTx))
+	ok, err = domains.LatestReadsValid(reads)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// unknown table is an error, not a crash
+	reads = map[string]*KvList{"unknown": {}}
+	_, err = domains.LatestReadsValid(reads)
+	require.Error(t, err)
+	_, err = domains.ReadsValid(reads)
+	require.Error(t, err)
+}
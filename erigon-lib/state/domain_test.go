@@ -2206,6 +2206,52 @@ func TestDomain_Unwind(t *testing.T) {
 	return
 }
 
+func TestDomain_UnwindAcrossSteps(t *testing.T) {
+	db, d := testDbAndDomain(t, log.New())
+	defer d.Close()
+	defer db.Close()
+	ctx := context.Background()
+
+	d.aggregationStep = 16
+	maxTx := d.aggregationStep*3 + 5
+	keys := [][]byte{[]byte("key1"), []byte("key2"), []byte("key3")}
+
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	dc := d.BeginFilesRo()
+	defer dc.Close()
+	writer := dc.NewWriter()
+	defer writer.close()
+	prev := make([][]byte, len(keys))
+	for i := uint64(0); i < maxTx; i++ {
+		writer.SetTxNum(i)
+		k := i % uint64(len(keys))
+		v := []byte(fmt.Sprintf("value%d.%d", k, i))
+		require.NoError(t, writer.PutWithPrev(keys[k], nil, v, prev[k], 0))
+		prev[k] = v
+	}
+	require.NoError(t, writer.Flush(ctx, tx))
+
+	// all steps are in db: unwind must drop versions of all steps after unwind point
+	unwindTo := d.aggregationStep + 4
+	require.NoError(t, dc.Unwind(ctx, tx, unwindTo/d.aggregationStep, unwindTo))
+	dc.Close()
+
+	dc = d.BeginFilesRo()
+	defer dc.Close()
+	for k := range keys {
+		lastTx := unwindTo - 1
+		for lastTx%uint64(len(keys)) != uint64(k) {
+			lastTx--
+		}
+		v, _, found, err := dc.GetLatest(keys[k], nil, tx)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, fmt.Sprintf("value%d.%d", k, lastTx), string(v))
+	}
+}
+
 func compareIterators(t *testing.T, et, ut iter.KV) {
 	t.Helper()
 
@@ -145,9 +145,18 @@ func filesFromDir(dir string) ([]string, error) {
 	}
 	filtered := make([]string, 0, len(allFiles))
 	for _, f := range allFiles {
-		if f.IsDir() || !f.Type().IsRegular() {
+		if f.IsDir() {
 			continue
 		}
+		if !f.Type().IsRegular() {
+			// files moved to cold storage tier are symlinks to it
+			if f.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			if target, err := os.Stat(filepath.Join(dir, f.Name())); err != nil || !target.Mode().IsRegular() {
+				continue
+			}
+		}
 		filtered = append(filtered, f.Name())
 	}
 	return filtered, nil
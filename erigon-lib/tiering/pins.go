@@ -0,0 +1,88 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tiering
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+)
+
+// PinsFileName - names of files pinned to local tier (`erigon snapshots pin`), in snapshots dir. Re-read by every step:
+// pins made while node is running are applied by it
+const PinsFileName = "tiering-pinned.json"
+
+func ReadPins(dirs datadir.Dirs) (map[string]bool, error) {
+	data, err := os.ReadFile(filepath.Join(dirs.Snap, PinsFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, err
+	}
+	pins := make(map[string]bool, len(names))
+	for _, name := range names {
+		pins[name] = true
+	}
+	return pins, nil
+}
+
+func writePins(dirs datadir.Dirs, pins map[string]bool) error {
+	names := make([]string, 0, len(pins))
+	for name := range pins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	return dir.WriteFileWithFsync(filepath.Join(dirs.Snap, PinsFileName), data, 0644)
+}
+
+// Pin - files (by name) stay in local tier regardless of policy, cold ones are moved back by next step
+func Pin(dirs datadir.Dirs, names ...string) error {
+	pins, err := ReadPins(dirs)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		pins[name] = true
+	}
+	return writePins(dirs, pins)
+}
+
+func Unpin(dirs datadir.Dirs, names ...string) error {
+	pins, err := ReadPins(dirs)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		delete(pins, name)
+	}
+	return writePins(dirs, pins)
+}
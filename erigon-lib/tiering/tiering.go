@@ -0,0 +1,387 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package tiering - moves frozen files between local disk and cold tier: directory on object storage mounted by
+// rclone/s3fs/gcsfuse/etc. File moved to cold tier is replaced by symlink to it: readers open it transparently,
+// already opened (mmap-ed) files stay valid. Small accessor files (.idx, .efi, .vi, .kvi, .bt) always stay local.
+package tiering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/seg"
+)
+
+type Tier string
+
+const (
+	Local Tier = "local"
+	Cold  Tier = "cold"
+)
+
+var (
+	tierReads = map[Tier]metrics.Counter{
+		Local: metrics.GetOrCreateCounter(`snapshots_tier_reads{tier="local"}`),
+		Cold:  metrics.GetOrCreateCounter(`snapshots_tier_reads{tier="cold"}`),
+	}
+	tierFiles = map[Tier]metrics.Gauge{
+		Local: metrics.GetOrCreateGauge(`snapshots_tier_files{tier="local"}`),
+		Cold:  metrics.GetOrCreateGauge(`snapshots_tier_files{tier="cold"}`),
+	}
+	tierBytes = map[Tier]metrics.Gauge{
+		Local: metrics.GetOrCreateGauge(`snapshots_tier_bytes{tier="local"}`),
+		Cold:  metrics.GetOrCreateGauge(`snapshots_tier_bytes{tier="cold"}`),
+	}
+	tierMoves = map[Tier]metrics.Counter{
+		Local: metrics.GetOrCreateCounter(`snapshots_tier_moves{to="local"}`),
+		Cold:  metrics.GetOrCreateCounter(`snapshots_tier_moves{to="cold"}`),
+	}
+)
+
+// Policy - which frozen files stay in local tier, others are moved to cold tier
+type Policy struct {
+	MinAge       time.Duration // files younger than it stay local
+	PromoteReads uint64        // files read at least that many times during Window stay local (cold ones are moved back). 0 - by age only
+	Window       time.Duration
+}
+
+var DefaultPolicy = Policy{MinAge: 30 * 24 * time.Hour, PromoteReads: 1_000, Window: 24 * time.Hour}
+
+const DefaultInterval = 10 * time.Minute
+
+// File - frozen data file
+type File struct {
+	Path    string // in local dirs: regular file (local tier) or symlink to cold tier
+	Rel     string // relative to datadir: same in cold tier
+	Tier    Tier
+	Size    int64
+	ModTime time.Time
+	Pinned  bool
+}
+
+func (f File) Name() string { return filepath.Base(f.Path) }
+
+const tmpSuffix = ".tiering.tmp"
+
+// dataFiles - extensions of files which can be moved to cold tier, by dir
+func dataFiles(dirs datadir.Dirs) map[string]string {
+	return map[string]string{dirs.Snap: ".seg", dirs.SnapHistory: ".v", dirs.SnapIdx: ".ef", dirs.SnapDomain: ".kv"}
+}
+
+type sample struct {
+	at    time.Time
+	reads uint64
+}
+
+type Tiering struct {
+	dirs    datadir.Dirs
+	coldDir string
+	policy  Policy
+	samples map[string][]sample // by file name: reads counter at steps, during policy window
+	logger  log.Logger
+}
+
+func New(dirs datadir.Dirs, coldDir string, policy Policy, logger log.Logger) (*Tiering, error) {
+	coldDir, err := filepath.Abs(coldDir)
+	if err != nil {
+		return nil, err
+	}
+	if isInside(coldDir, dirs.DataDir) || isInside(dirs.DataDir, coldDir) {
+		return nil, fmt.Errorf("cold tier dir %s must be outside of datadir %s", coldDir, dirs.DataDir)
+	}
+	// cold tier mirrors layout of datadir, files are mapped by their resolved path
+	for dir, subsystem := range map[string]disk.Subsystem{dirs.Snap: disk.Snapshots, dirs.SnapDomain: disk.Domains,
+		dirs.SnapHistory: disk.Domains, dirs.SnapIdx: disk.Domains, dirs.SnapAccessors: disk.Domains} {
+		if rel, err := filepath.Rel(dirs.DataDir, dir); err == nil {
+			disk.Tag(filepath.Join(coldDir, rel), subsystem)
+		}
+	}
+	return &Tiering{dirs: dirs, coldDir: coldDir, policy: policy, samples: map[string][]sample{}, logger: logger}, nil
+}
+
+func isInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// Files - frozen data files in local dirs and their tiers
+func Files(dirs datadir.Dirs) ([]File, error) {
+	pins, err := ReadPins(dirs)
+	if err != nil {
+		return nil, err
+	}
+	var res []File
+	for dir, ext := range dataFiles(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ext {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			info, err := os.Stat(path) // follows symlink to cold tier
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) { // removed by merge or cold tier is not mounted
+					continue
+				}
+				return nil, err
+			}
+			rel, err := filepath.Rel(dirs.DataDir, path)
+			if err != nil {
+				return nil, err
+			}
+			f := File{Path: path, Rel: rel, Tier: Local, Size: info.Size(), ModTime: info.ModTime(), Pinned: pins[e.Name()]}
+			if e.Type()&fs.ModeSymlink != 0 {
+				f.Tier = Cold
+			}
+			res = append(res, f)
+		}
+	}
+	return res, nil
+}
+
+// Reads - reads of the file during policy window, observed by steps
+func (t *Tiering) Reads(name string) uint64 {
+	s := t.samples[name]
+	if len(s) == 0 {
+		return 0
+	}
+	return s[len(s)-1].reads - s[0].reads
+}
+
+// observe - records reads counter of the file, returns reads since previous step
+func (t *Tiering) observe(name string, now time.Time) (delta uint64) {
+	reads := seg.Reads(name)
+	s := t.samples[name]
+	if len(s) > 0 {
+		delta = reads - s[len(s)-1].reads
+	}
+	s = append(s, sample{at: now, reads: reads})
+	// keep one sample at or before window start: reads during window are counted from it
+	for len(s) > 1 && !s[1].at.After(now.Add(-t.policy.Window)) {
+		s = s[1:]
+	}
+	t.samples[name] = s
+	return delta
+}
+
+// Target - tier where the file must be by policy
+func (t *Tiering) Target(f File, now time.Time) Tier {
+	if f.Pinned || now.Sub(f.ModTime) < t.policy.MinAge {
+		return Local
+	}
+	if t.policy.PromoteReads > 0 && t.Reads(f.Name()) >= t.policy.PromoteReads {
+		return Local
+	}
+	return Cold
+}
+
+// Step - observes reads of files, moves files to their target tiers, removes cold copies of files which were removed
+// locally (by merge)
+func (t *Tiering) Step(ctx context.Context, now time.Time) (demoted, promoted int, err error) {
+	files, err := Files(t.dirs)
+	if err != nil {
+		return 0, 0, err
+	}
+	counts, sizes := map[Tier]int{}, map[Tier]int64{}
+	seen := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		seen[f.Name()] = struct{}{}
+		tierReads[f.Tier].AddUint64(t.observe(f.Name(), now))
+		switch target := t.Target(f, now); {
+		case target == Cold && f.Tier == Local:
+			if err := t.demote(f); err != nil {
+				return demoted, promoted, fmt.Errorf("move %s to cold tier: %w", f.Rel, err)
+			}
+			demoted++
+			f.Tier = Cold
+		case target == Local && f.Tier == Cold:
+			if err := t.promote(f); err != nil {
+				return demoted, promoted, fmt.Errorf("move %s to local tier: %w", f.Rel, err)
+			}
+			promoted++
+			f.Tier = Local
+		}
+		counts[f.Tier]++
+		sizes[f.Tier] += f.Size
+		if err := ctx.Err(); err != nil {
+			return demoted, promoted, err
+		}
+	}
+	for name := range t.samples {
+		if _, ok := seen[name]; !ok {
+			delete(t.samples, name)
+		}
+	}
+	for _, tier := range []Tier{Local, Cold} {
+		tierFiles[tier].SetInt(counts[tier])
+		tierBytes[tier].SetInt(int(sizes[tier]))
+	}
+	tierMoves[Cold].AddInt(demoted)
+	tierMoves[Local].AddInt(promoted)
+	return demoted, promoted, t.removeOrphans()
+}
+
+// Promote - moves files (by name) from cold tier to local disk, regardless of policy
+func (t *Tiering) Promote(names ...string) (promoted int, err error) {
+	files, err := Files(t.dirs)
+	if err != nil {
+		return 0, err
+	}
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[name] = true
+	}
+	for _, f := range files {
+		if f.Tier != Cold || !want[f.Name()] {
+			continue
+		}
+		if err := t.promote(f); err != nil {
+			return promoted, fmt.Errorf("move %s to local tier: %w", f.Rel, err)
+		}
+		promoted++
+	}
+	tierMoves[Local].AddInt(promoted)
+	return promoted, nil
+}
+
+// Run - background promotion/demotion of files
+func (t *Tiering) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		demoted, promoted, err := t.Step(ctx, time.Now())
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.logger.Warn("[tiering] step", "err", err)
+		} else if demoted > 0 || promoted > 0 {
+			t.logger.Info("[tiering] moved files", "to_cold", demoted, "to_local", promoted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// demote - copies file to cold tier (if there is no copy yet) and replaces it by symlink
+func (t *Tiering) demote(f File) error {
+	cold := filepath.Join(t.coldDir, f.Rel)
+	if info, err := os.Stat(cold); err != nil || info.Size() != f.Size {
+		if err := copyFile(f.Path, cold); err != nil {
+			return err
+		}
+	}
+	tmp := f.Path + tmpSuffix
+	_ = os.Remove(tmp)
+	if err := os.Symlink(cold, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
+
+// promote - replaces symlink by copy of file. Cold copy is kept: demotion of the file again doesn't upload it
+func (t *Tiering) promote(f File) error {
+	return copyFile(filepath.Join(t.coldDir, f.Rel), f.Path)
+}
+
+// removeOrphans - cold copies of files which are not present locally: merged into bigger files or removed
+func (t *Tiering) removeOrphans() error {
+	for dir := range dataFiles(t.dirs) {
+		rel, err := filepath.Rel(t.dirs.DataDir, dir)
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(filepath.Join(t.coldDir, rel))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasSuffix(e.Name(), tmpSuffix) {
+				continue
+			}
+			if _, err := os.Lstat(filepath.Join(dir, e.Name())); !errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(t.coldDir, rel, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyFile - to temporary file, then renames it: `to` is replaced atomically (symlink itself, not its target).
+// Modification time is kept: age of file is age of its data
+func copyFile(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	r, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmp := to + tmpSuffix
+	w, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Sync(); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	info, err := r.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, to)
+}
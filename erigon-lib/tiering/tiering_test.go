@@ -0,0 +1,122 @@
+/*
+   Copyright 2024 Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tiering
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/seg"
+)
+
+func writeFile(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	c, err := seg.NewCompressor(context.Background(), t.Name(), path, t.TempDir(), 1, 1, log.LvlDebug, log.New())
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.AddWord([]byte(filepath.Base(path))))
+	require.NoError(t, c.Compress())
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func tierOf(t *testing.T, path string) Tier {
+	t.Helper()
+	info, err := os.Lstat(path)
+	require.NoError(t, err)
+	if info.Mode()&os.ModeSymlink != 0 {
+		return Cold
+	}
+	return Local
+}
+
+func TestTiering(t *testing.T) {
+	ctx := context.Background()
+	dirs := datadir.New(t.TempDir())
+	coldDir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+
+	oldSeg := filepath.Join(dirs.Snap, "v1-000000-000500-bodies.seg")
+	newSeg := filepath.Join(dirs.Snap, "v1-000500-001000-bodies.seg")
+	oldHist := filepath.Join(dirs.SnapHistory, "v1-accounts.0-64.v")
+	oldIdx := filepath.Join(dirs.Snap, "v1-000000-000500-bodies.idx")
+	writeFile(t, oldSeg, old)
+	writeFile(t, newSeg, now)
+	writeFile(t, oldHist, old)
+	writeFile(t, oldIdx, old)
+
+	_, err := New(dirs, filepath.Join(dirs.DataDir, "cold"), DefaultPolicy, log.New())
+	require.Error(t, err)
+	tiering, err := New(dirs, coldDir, Policy{MinAge: 30 * 24 * time.Hour, PromoteReads: 3, Window: time.Hour}, log.New())
+	require.NoError(t, err)
+
+	// old data files are moved to cold tier, readable through symlinks. Accessor files stay local
+	demoted, promoted, err := tiering.Step(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, demoted)
+	require.Equal(t, 0, promoted)
+	require.Equal(t, Cold, tierOf(t, oldSeg))
+	require.Equal(t, Cold, tierOf(t, oldHist))
+	require.Equal(t, Local, tierOf(t, newSeg))
+	require.Equal(t, Local, tierOf(t, oldIdx))
+	require.FileExists(t, filepath.Join(coldDir, "snapshots", "history", "v1-accounts.0-64.v"))
+
+	d, err := seg.NewDecompressor(oldSeg)
+	require.NoError(t, err)
+	defer d.Close()
+	w, _ := d.MakeGetter().Next(nil)
+	require.Equal(t, "v1-000000-000500-bodies.seg", string(w))
+
+	// frequently read files are moved back
+	for i := 0; i < 3; i++ {
+		d.MakeGetter()
+	}
+	demoted, promoted, err = tiering.Step(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 0, demoted)
+	require.Equal(t, 1, promoted)
+	require.Equal(t, Local, tierOf(t, oldSeg))
+	require.FileExists(t, filepath.Join(coldDir, "snapshots", "v1-000000-000500-bodies.seg"))
+
+	// reads are out of window, pinned files stay local
+	require.NoError(t, Pin(dirs, "v1-000000-000500-bodies.seg", "v1-accounts.0-64.v"))
+	demoted, promoted, err = tiering.Step(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, demoted)
+	require.Equal(t, 1, promoted)
+	require.Equal(t, Local, tierOf(t, oldSeg))
+	require.Equal(t, Local, tierOf(t, oldHist))
+
+	require.NoError(t, Unpin(dirs, "v1-000000-000500-bodies.seg", "v1-accounts.0-64.v"))
+	demoted, _, err = tiering.Step(ctx, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, demoted)
+
+	// cold copies of files removed locally (merged) are removed
+	require.NoError(t, os.Remove(oldHist))
+	_, _, err = tiering.Step(ctx, now.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(coldDir, "snapshots", "history", "v1-accounts.0-64.v"))
+	require.FileExists(t, filepath.Join(coldDir, "snapshots", "v1-000000-000500-bodies.seg"))
+}
@@ -0,0 +1,113 @@
+// Package tracing - OpenTelemetry spans of stage loop, domains, commitment and RPC handlers.
+// Spans are no-op until Setup is called (--tracing.otlp.endpoint)
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ledgerwatch/erigon"
+
+var enabled atomic.Bool
+
+// Enabled - spans are exported. Checked before measuring operations too frequent for spans (see Timer)
+func Enabled() bool { return enabled.Load() }
+
+// Setup - exports spans to OTLP/HTTP collector at endpoint (host:port). Root spans (sync cycles, RPC requests)
+// are sampled with sampleRatio, their children follow. Returned func flushes exported spans
+func Setup(endpoint string, insecure bool, sampleRatio float64, logger log.Logger) (shutdown func(context.Context) error, err error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("erigon"))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+	logger.Info("Enabling tracing export to OTLP collector", "endpoint", endpoint, "sampling", sampleRatio)
+	return provider.Shutdown, nil
+}
+
+// Start - span named `name` child of span of ctx (if any). Caller must End it
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// WithSpanOf - ctx with span of spanCtx: spans of code which gets ctx from elsewhere (stages capture node ctx)
+// become children of it
+func WithSpanOf(ctx, spanCtx context.Context) context.Context {
+	if spanCtx == nil {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(spanCtx))
+}
+
+// End - ends span, marks it failed if err != nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract - ctx with remote span of W3C `traceparent` header: RPC request spans continue caller's trace
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if !Enabled() {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Timer - count and total duration of operations too frequent for a span each (domain reads/writes),
+// added to span of enclosing operation as attributes
+type Timer struct {
+	count atomic.Uint64
+	took  atomic.Int64
+}
+
+// Start - zero time if tracing is disabled: Observe ignores it
+func (t *Timer) Start() time.Time {
+	if !Enabled() {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (t *Timer) Observe(start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	t.count.Add(1)
+	t.took.Add(int64(time.Since(start)))
+}
+
+// Attributes - `<name>.count`, `<name>.ms` since previous call
+func (t *Timer) Attributes(name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64(name+".count", int64(t.count.Swap(0))),
+		attribute.Int64(name+".ms", time.Duration(t.took.Swap(0)).Milliseconds()),
+	}
+}
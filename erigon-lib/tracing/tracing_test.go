@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+	defer enabled.Store(false)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), header)
+
+	stageCtx, stage := Start(ctx, "stage Execution")
+	// stage code uses ctx captured elsewhere
+	_, block := Start(WithSpanOf(context.Background(), stageCtx), "exec block", attribute.Int64("block", 1))
+	var reads Timer
+	reads.Observe(reads.Start())
+	reads.Observe(reads.Start())
+	block.SetAttributes(reads.Attributes("domain.reads")...)
+	End(block, errors.New("bad block"))
+	End(stage, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "exec block", spans[0].Name())
+	require.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].SpanContext().TraceID().String())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Contains(t, spans[0].Attributes(), attribute.Int64("domain.reads.count", 2))
+	require.Equal(t, attribute.Int64("domain.reads.count", 0), reads.Attributes("domain.reads")[0])
+
+	enabled.Store(false)
+	require.True(t, reads.Start().IsZero())
+	reads.Observe(time.Time{})
+	require.Equal(t, attribute.Int64("domain.reads.count", 0), reads.Attributes("domain.reads")[0])
+}
@@ -0,0 +1,155 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+)
+
+// GrpcServer - `txpool.UserOps` service, registered with txpool services
+type GrpcServer struct {
+	txpool_proto.UnimplementedUserOpsServer
+	pool *Pool
+}
+
+var _ txpool_proto.UserOpsServer = &GrpcServer{}
+
+func NewGrpcServer(pool *Pool) *GrpcServer { return &GrpcServer{pool: pool} }
+
+func (s *GrpcServer) Add(ctx context.Context, in *txpool_proto.AddUserOpRequest) (*txpool_proto.AddUserOpReply, error) {
+	if in.UserOp == nil {
+		return &txpool_proto.AddUserOpReply{ErrorCode: CodeInvalidFields, Error: "userOp is missing"}, nil
+	}
+	hash, err := s.pool.Add(ctx, gointerfaces.ConvertH160toAddress(in.EntryPoint), UserOpFromProto(in.UserOp))
+	if err != nil {
+		var rejected *RejectError
+		if errors.As(err, &rejected) {
+			return &txpool_proto.AddUserOpReply{ErrorCode: int32(rejected.Code), Error: rejected.Message}, nil
+		}
+		return nil, err
+	}
+	return &txpool_proto.AddUserOpReply{Hash: gointerfaces.ConvertHashToH256(hash)}, nil
+}
+
+func (s *GrpcServer) Pending(ctx context.Context, in *txpool_proto.PendingUserOpsRequest) (*txpool_proto.PendingUserOpsReply, error) {
+	ops := s.pool.Pending(gointerfaces.ConvertH160toAddress(in.EntryPoint))
+	reply := &txpool_proto.PendingUserOpsReply{Ops: make([]*txpool_proto.PoolUserOp, len(ops))}
+	for i, op := range ops {
+		reply.Ops[i] = poolOpToProto(op)
+	}
+	return reply, nil
+}
+
+func (s *GrpcServer) Remove(ctx context.Context, in *txpool_proto.TxHashes) (*txpool_proto.TxHashes, error) {
+	hashes := make([]common.Hash, len(in.Hashes))
+	for i := range in.Hashes {
+		hashes[i] = gointerfaces.ConvertH256ToHash(in.Hashes[i])
+	}
+	removed := s.pool.Remove(hashes)
+	reply := &txpool_proto.TxHashes{Hashes: make([]*types2.H256, len(removed))}
+	for i := range removed {
+		reply.Hashes[i] = gointerfaces.ConvertHashToH256(removed[i])
+	}
+	return reply, nil
+}
+
+func (s *GrpcServer) Reputation(ctx context.Context, _ *emptypb.Empty) (*txpool_proto.ReputationReply, error) {
+	entries := s.pool.Reputation()
+	reply := &txpool_proto.ReputationReply{Entries: make([]*txpool_proto.ReputationEntry, len(entries))}
+	for i, e := range entries {
+		reply.Entries[i] = &txpool_proto.ReputationEntry{
+			Address:     gointerfaces.ConvertAddressToH160(e.Address),
+			OpsSeen:     e.OpsSeen,
+			OpsIncluded: e.OpsIncluded,
+			Status:      txpool_proto.ReputationEntry_Status(e.Status),
+		}
+	}
+	return reply, nil
+}
+
+func (s *GrpcServer) OnAdd(_ *emptypb.Empty, stream txpool_proto.UserOps_OnAddServer) error {
+	ch, unsubscribe := s.pool.Subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case op := <-ch:
+			if err := stream.Send(poolOpToProto(op)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RejectErrorOf - RejectError of reply of UserOpsClient.Add, nil if user operation is accepted
+func RejectErrorOf(reply *txpool_proto.AddUserOpReply) *RejectError {
+	if reply.ErrorCode == 0 {
+		return nil
+	}
+	return &RejectError{Code: int(reply.ErrorCode), Message: reply.Error}
+}
+
+func poolOpToProto(op *PoolOp) *txpool_proto.PoolUserOp {
+	return &txpool_proto.PoolUserOp{
+		Hash:       gointerfaces.ConvertHashToH256(op.Hash),
+		EntryPoint: gointerfaces.ConvertAddressToH160(op.EntryPoint),
+		UserOp:     UserOpToProto(op.UserOp),
+		ValidUntil: op.ValidUntil,
+		Prefund:    gointerfaces.ConvertUint256IntToH256(&op.Prefund),
+	}
+}
+
+func UserOpToProto(op *UserOperation) *txpool_proto.UserOperation {
+	return &txpool_proto.UserOperation{
+		Sender:               gointerfaces.ConvertAddressToH160(op.Sender),
+		Nonce:                gointerfaces.ConvertUint256IntToH256(&op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         gointerfaces.ConvertUint256IntToH256(&op.CallGasLimit),
+		VerificationGasLimit: gointerfaces.ConvertUint256IntToH256(&op.VerificationGasLimit),
+		PreVerificationGas:   gointerfaces.ConvertUint256IntToH256(&op.PreVerificationGas),
+		MaxFeePerGas:         gointerfaces.ConvertUint256IntToH256(&op.MaxFeePerGas),
+		MaxPriorityFeePerGas: gointerfaces.ConvertUint256IntToH256(&op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	}
+}
+
+func UserOpFromProto(op *txpool_proto.UserOperation) *UserOperation {
+	return &UserOperation{
+		Sender:               gointerfaces.ConvertH160toAddress(op.Sender),
+		Nonce:                *gointerfaces.ConvertH256ToUint256Int(op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         *gointerfaces.ConvertH256ToUint256Int(op.CallGasLimit),
+		VerificationGasLimit: *gointerfaces.ConvertH256ToUint256Int(op.VerificationGasLimit),
+		PreVerificationGas:   *gointerfaces.ConvertH256ToUint256Int(op.PreVerificationGas),
+		MaxFeePerGas:         *gointerfaces.ConvertH256ToUint256Int(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: *gointerfaces.ConvertH256ToUint256Int(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	}
+}
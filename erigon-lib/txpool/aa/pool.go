@@ -0,0 +1,392 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var (
+	userOpsGauge         = metrics.GetOrCreateGauge(`txpool_aa_userops`)
+	userOpsAddedCounter  = metrics.GetOrCreateCounter(`txpool_aa_userops_added`)
+	userOpsRejectCounter = metrics.GetOrCreateCounter(`txpool_aa_userops_rejected`)
+	userOpsMinedCounter  = metrics.GetOrCreateCounter(`txpool_aa_userops_included`)
+)
+
+var ErrPoolFull = errors.New("user operations pool is full")
+
+type Config struct {
+	EntryPoints        []common.Address // empty - pool is disabled
+	MaxOps             int
+	MinStake           uint256.Int // stake (in EntryPoint) of entity which makes it "staked"
+	MinUnstakeDelaySec uint64
+	MinPriorityFee     uint64
+	PriceBump          uint64 // Price bump percentage to replace user operation with same sender and nonce
+	MaxVerificationGas uint64
+}
+
+var DefaultConfig = Config{
+	MaxOps:             4096,
+	MinStake:           *uint256.NewInt(common.Ether),
+	MinUnstakeDelaySec: 86400,
+	PriceBump:          10,
+	MaxVerificationGas: 5_000_000,
+}
+
+// PoolOp - valid user operation in pool
+type PoolOp struct {
+	Hash       common.Hash    `json:"userOpHash"`
+	EntryPoint common.Address `json:"entryPoint"`
+	UserOp     *UserOperation `json:"userOperation"`
+	ValidUntil uint64         `json:"validUntil"` // 0 - no limit
+	Prefund    uint256.Int    `json:"prefund"`
+
+	senderStaked bool
+}
+
+type senderNonce struct {
+	entryPoint common.Address
+	sender     common.Address
+	nonce      uint256.Int
+}
+
+// Pool - mempool of user operations. Unlike txpool - it doesn't track chain state itself: OnNewHead must be called
+// on every new block to remove included and expired user operations.
+type Pool struct {
+	cfg        Config
+	chainID    uint256.Int
+	simulator  Simulator
+	reputation *Reputation
+
+	lock      sync.Mutex
+	ops       map[common.Hash]*PoolOp
+	bySender  map[senderNonce]*PoolOp
+	lastDecay time.Time
+	subs      map[int]chan *PoolOp
+	lastSubID int
+
+	logger log.Logger
+}
+
+func New(cfg Config, chainID uint256.Int, simulator Simulator, logger log.Logger) *Pool {
+	return &Pool{
+		cfg:        cfg,
+		chainID:    chainID,
+		simulator:  simulator,
+		reputation: NewReputation(),
+		ops:        map[common.Hash]*PoolOp{},
+		bySender:   map[senderNonce]*PoolOp{},
+		lastDecay:  time.Now(),
+		subs:       map[int]chan *PoolOp{},
+		logger:     logger,
+	}
+}
+
+func (p *Pool) EntryPoints() []common.Address { return p.cfg.EntryPoints }
+
+func (p *Pool) Reputation() []ReputationEntry { return p.reputation.Dump() }
+
+// Add - validates user operation and adds it to pool. Returns *RejectError if user operation is invalid.
+func (p *Pool) Add(ctx context.Context, entryPoint common.Address, op *UserOperation) (common.Hash, error) {
+	hash, err := p.add(ctx, entryPoint, op)
+	if err != nil {
+		userOpsRejectCounter.Inc()
+		p.logger.Debug("[txpool.aa] UserOp rejected", "sender", op.Sender, "nonce", &op.Nonce, "err", err)
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+func (p *Pool) add(ctx context.Context, entryPoint common.Address, op *UserOperation) (common.Hash, error) {
+	if !p.supported(entryPoint) {
+		return common.Hash{}, reject(CodeInvalidFields, fmt.Sprintf("unsupported entry point %x", entryPoint))
+	}
+	if err := p.checkFields(op); err != nil {
+		return common.Hash{}, err
+	}
+	for _, entity := range []common.Address{op.Factory(), op.Paymaster()} {
+		if entity != (common.Address{}) && p.reputation.Status(entity) == ReputationBanned {
+			return common.Hash{}, reject(CodeBannedOrThrottled, fmt.Sprintf("entity %x is banned", entity))
+		}
+	}
+
+	res, err := p.simulator.SimulateValidation(ctx, entryPoint, op)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := p.checkValidation(op, res, time.Now()); err != nil {
+		return common.Hash{}, err
+	}
+
+	hash := op.Hash(entryPoint, &p.chainID)
+	poolOp := &PoolOp{Hash: hash, EntryPoint: entryPoint, UserOp: op, ValidUntil: res.ReturnInfo.ValidUntil,
+		Prefund: res.ReturnInfo.Prefund, senderStaked: p.isStaked(&res.Sender)}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.ops[hash]; ok {
+		return hash, nil
+	}
+	key := senderNonce{entryPoint: entryPoint, sender: op.Sender, nonce: op.Nonce}
+	if replaced, ok := p.bySender[key]; ok {
+		if !p.bumped(replaced.UserOp, op) {
+			return common.Hash{}, reject(CodeInvalidFields, fmt.Sprintf("replacement UserOp must have %d%% higher gas fees", p.cfg.PriceBump))
+		}
+		p.removeLocked(replaced)
+	} else if err := p.checkLimitsLocked(poolOp, res); err != nil {
+		return common.Hash{}, err
+	}
+
+	p.ops[hash] = poolOp
+	p.bySender[key] = poolOp
+	userOpsGauge.SetInt(len(p.ops))
+	userOpsAddedCounter.Inc()
+	for _, entity := range []common.Address{op.Factory(), op.Paymaster()} {
+		if entity != (common.Address{}) {
+			p.reputation.Seen(entity)
+		}
+	}
+	for _, ch := range p.subs {
+		common.PrioritizedSend(ch, poolOp)
+	}
+	return hash, nil
+}
+
+func (p *Pool) supported(entryPoint common.Address) bool {
+	for _, addr := range p.cfg.EntryPoints {
+		if addr == entryPoint {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) checkFields(op *UserOperation) *RejectError {
+	if len(op.InitCode) != 0 && len(op.InitCode) < 20 {
+		return reject(CodeInvalidFields, "initCode must start with factory address")
+	}
+	if len(op.PaymasterAndData) != 0 && len(op.PaymasterAndData) < 20 {
+		return reject(CodeInvalidFields, "paymasterAndData must start with paymaster address")
+	}
+	if op.VerificationGasLimit.GtUint64(p.cfg.MaxVerificationGas) {
+		return reject(CodeInvalidFields, fmt.Sprintf("verificationGasLimit is higher than %d", p.cfg.MaxVerificationGas))
+	}
+	if op.MaxPriorityFeePerGas.LtUint64(p.cfg.MinPriorityFee) {
+		return reject(CodeInvalidFields, fmt.Sprintf("maxPriorityFeePerGas is lower than %d", p.cfg.MinPriorityFee))
+	}
+	if op.MaxFeePerGas.Lt(&op.MaxPriorityFeePerGas) {
+		return reject(CodeInvalidFields, "maxFeePerGas is lower than maxPriorityFeePerGas")
+	}
+	return nil
+}
+
+func (p *Pool) bumped(old, op *UserOperation) bool {
+	bump := uint256.NewInt(100 + p.cfg.PriceBump)
+	var threshold uint256.Int
+	threshold.Mul(&old.MaxFeePerGas, bump).Div(&threshold, uint256.NewInt(100))
+	if op.MaxFeePerGas.Lt(&threshold) {
+		return false
+	}
+	threshold.Mul(&old.MaxPriorityFeePerGas, bump).Div(&threshold, uint256.NewInt(100))
+	return !op.MaxPriorityFeePerGas.Lt(&threshold)
+}
+
+// checkLimitsLocked - limits of ops per unstaked sender, per unstaked or throttled entity, and of whole pool
+func (p *Pool) checkLimitsLocked(poolOp *PoolOp, res *ValidationResult) error {
+	if len(p.ops) >= p.cfg.MaxOps {
+		return ErrPoolFull
+	}
+	op := poolOp.UserOp
+	if !poolOp.senderStaked && p.countLocked(func(o *UserOperation) bool { return o.Sender == op.Sender }) >= sameSenderMempoolCount {
+		return reject(CodeStakeTooLow, fmt.Sprintf("unstaked sender %x has too many UserOps in pool", op.Sender))
+	}
+	entities := []struct {
+		addr  common.Address
+		stake *StakeInfo
+		of    func(*UserOperation) common.Address
+	}{
+		{op.Factory(), &res.Factory, (*UserOperation).Factory},
+		{op.Paymaster(), &res.Paymaster, (*UserOperation).Paymaster},
+	}
+	for _, e := range entities {
+		if e.addr == (common.Address{}) {
+			continue
+		}
+		count := p.countLocked(func(o *UserOperation) bool { return e.of(o) == e.addr })
+		if p.reputation.Status(e.addr) == ReputationThrottled && count >= throttledEntityMempoolCount {
+			return reject(CodeBannedOrThrottled, fmt.Sprintf("entity %x is throttled", e.addr))
+		}
+		if !p.isStaked(e.stake) && count >= unstakedEntityMempoolCount {
+			return reject(CodeStakeTooLow, fmt.Sprintf("unstaked entity %x has too many UserOps in pool", e.addr))
+		}
+	}
+	return nil
+}
+
+func (p *Pool) countLocked(f func(op *UserOperation) bool) int {
+	count := 0
+	for _, poolOp := range p.ops {
+		if f(poolOp.UserOp) {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *Pool) removeLocked(poolOp *PoolOp) {
+	delete(p.ops, poolOp.Hash)
+	delete(p.bySender, senderNonce{entryPoint: poolOp.EntryPoint, sender: poolOp.UserOp.Sender, nonce: poolOp.UserOp.Nonce})
+	userOpsGauge.SetInt(len(p.ops))
+}
+
+// Remove - removes user operations from pool (for example - bundler found that they fail in bundle).
+// Returns hashes of removed ones.
+func (p *Pool) Remove(hashes []common.Hash) []common.Hash {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var removed []common.Hash
+	for _, hash := range hashes {
+		if poolOp, ok := p.ops[hash]; ok {
+			p.removeLocked(poolOp)
+			removed = append(removed, hash)
+		}
+	}
+	return removed
+}
+
+type nonceKey struct {
+	entryPoint common.Address
+	sender     common.Address
+	key        uint256.Int
+}
+
+// OnNewHead - removes user operations which were included (EntryPoint's nonce of sender is above theirs) and expired
+// ones, updates reputation of entities of included ones
+func (p *Pool) OnNewHead(ctx context.Context) error {
+	p.lock.Lock()
+	keys := map[nonceKey]struct{}{}
+	for _, poolOp := range p.ops {
+		key, _ := poolOp.UserOp.NonceKey()
+		keys[nonceKey{entryPoint: poolOp.EntryPoint, sender: poolOp.UserOp.Sender, key: key}] = struct{}{}
+	}
+	p.lock.Unlock()
+
+	nonces := make(map[nonceKey]*uint256.Int, len(keys))
+	for k := range keys {
+		nonce, err := p.simulator.Nonce(ctx, k.entryPoint, k.sender, &k.key)
+		if err != nil {
+			return fmt.Errorf("getNonce of %x: %w", k.sender, err)
+		}
+		nonces[k] = nonce
+	}
+
+	now := uint64(time.Now().Unix())
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	included, expired := 0, 0
+	for _, poolOp := range p.ops {
+		key, _ := poolOp.UserOp.NonceKey()
+		nonce, ok := nonces[nonceKey{entryPoint: poolOp.EntryPoint, sender: poolOp.UserOp.Sender, key: key}]
+		switch {
+		case ok && poolOp.UserOp.Nonce.Lt(nonce):
+			p.removeLocked(poolOp)
+			for _, entity := range []common.Address{poolOp.UserOp.Factory(), poolOp.UserOp.Paymaster()} {
+				if entity != (common.Address{}) {
+					p.reputation.Included(entity)
+				}
+			}
+			included++
+		case poolOp.ValidUntil != 0 && poolOp.ValidUntil < now:
+			p.removeLocked(poolOp)
+			expired++
+		}
+	}
+	userOpsMinedCounter.AddInt(included)
+	if time.Since(p.lastDecay) >= time.Hour {
+		p.reputation.Decay()
+		p.lastDecay = time.Now()
+	}
+	if included > 0 || expired > 0 {
+		p.logger.Debug("[txpool.aa] UserOps removed", "included", included, "expired", expired, "left", len(p.ops))
+	}
+	return nil
+}
+
+// Pending - user operations of entry point, best first: by priority fee, keeping nonce order of each sender
+func (p *Pool) Pending(entryPoint common.Address) []*PoolOp {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var res []*PoolOp
+	for _, poolOp := range p.ops {
+		if poolOp.EntryPoint == entryPoint {
+			res = append(res, poolOp)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if c := res[i].UserOp.MaxPriorityFeePerGas.Cmp(&res[j].UserOp.MaxPriorityFeePerGas); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(res[i].Hash[:], res[j].Hash[:]) < 0
+	})
+
+	// ops of sender may be out of nonce order: put them to positions of sender's ops by nonce
+	bySender := map[common.Address][]int{}
+	for i, poolOp := range res {
+		bySender[poolOp.UserOp.Sender] = append(bySender[poolOp.UserOp.Sender], i)
+	}
+	for _, positions := range bySender {
+		if len(positions) < 2 {
+			continue
+		}
+		ops := make([]*PoolOp, len(positions))
+		for k, i := range positions {
+			ops[k] = res[i]
+		}
+		sort.Slice(ops, func(a, b int) bool { return ops[a].UserOp.Nonce.Lt(&ops[b].UserOp.Nonce) })
+		for k, i := range positions {
+			res[i] = ops[k]
+		}
+	}
+	return res
+}
+
+// Subscribe - feed of user operations added to pool. Slow subscribers may miss user operations.
+func (p *Pool) Subscribe() (<-chan *PoolOp, func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ch := make(chan *PoolOp, 1024)
+	p.lastSubID++
+	id := p.lastSubID
+	p.subs[id] = ch
+	return ch, func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		delete(p.subs, id)
+	}
+}
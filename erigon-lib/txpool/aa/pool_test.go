@@ -0,0 +1,298 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+)
+
+type testSimulator struct {
+	result func(op *UserOperation) *ValidationResult
+	nonces map[common.Address]uint64
+}
+
+func (s *testSimulator) SimulateValidation(_ context.Context, _ common.Address, op *UserOperation) (*ValidationResult, error) {
+	if s.result != nil {
+		return s.result(op), nil
+	}
+	return &ValidationResult{Traces: map[Entity]*EntityTrace{}}, nil
+}
+
+func (s *testSimulator) Nonce(_ context.Context, _, sender common.Address, _ *uint256.Int) (*uint256.Int, error) {
+	return uint256.NewInt(s.nonces[sender]), nil
+}
+
+var testEntryPoint = common.HexToAddress("0x5ff137d4b0fdcd49dca30c7cf57e578a026d2789")
+
+func newTestPool(sim *testSimulator) *Pool {
+	cfg := DefaultConfig
+	cfg.EntryPoints = []common.Address{testEntryPoint}
+	return New(cfg, *uint256.NewInt(1), sim, log.New())
+}
+
+func testOp(sender byte, nonce, tip uint64) *UserOperation {
+	op := &UserOperation{Sender: common.Address{sender}}
+	op.Nonce.SetUint64(nonce)
+	op.VerificationGasLimit.SetUint64(100_000)
+	op.MaxPriorityFeePerGas.SetUint64(tip)
+	op.MaxFeePerGas.SetUint64(tip * 2)
+	return op
+}
+
+func rejectCode(t *testing.T, err error) int {
+	t.Helper()
+	var rejected *RejectError
+	require.True(t, errors.As(err, &rejected), "%v", err)
+	return rejected.Code
+}
+
+func TestUserOpHash(t *testing.T) {
+	hash := (&UserOperation{}).Hash(testEntryPoint, uint256.NewInt(1))
+	require.Equal(t, (&UserOperation{}).Hash(testEntryPoint, uint256.NewInt(1)), hash)
+	require.NotEqual(t, hash, (&UserOperation{}).Hash(testEntryPoint, uint256.NewInt(5)))
+	require.NotEqual(t, hash, testOp(1, 0, 0).Hash(testEntryPoint, uint256.NewInt(1)))
+}
+
+func TestAddAndReplace(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPool(&testSimulator{})
+
+	_, err := p.Add(ctx, common.Address{1}, testOp(1, 0, 10))
+	require.Equal(t, CodeInvalidFields, rejectCode(t, err))
+
+	hash, err := p.Add(ctx, testEntryPoint, testOp(1, 0, 10))
+	require.NoError(t, err)
+	require.Len(t, p.Pending(testEntryPoint), 1)
+
+	// same sender and nonce: replacement needs price bump
+	_, err = p.Add(ctx, testEntryPoint, testOp(1, 0, 10))
+	require.NoError(t, err) // same hash - already known
+	notBumped := testOp(1, 0, 10)
+	notBumped.CallData = []byte{1}
+	_, err = p.Add(ctx, testEntryPoint, notBumped)
+	require.Equal(t, CodeInvalidFields, rejectCode(t, err))
+	replacement, err := p.Add(ctx, testEntryPoint, testOp(1, 0, 11))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, replacement)
+	pending := p.Pending(testEntryPoint)
+	require.Len(t, pending, 1)
+	require.Equal(t, replacement, pending[0].Hash)
+
+	// unstaked sender is limited
+	for nonce := uint64(1); nonce < sameSenderMempoolCount; nonce++ {
+		_, err = p.Add(ctx, testEntryPoint, testOp(1, nonce, 10))
+		require.NoError(t, err)
+	}
+	_, err = p.Add(ctx, testEntryPoint, testOp(1, sameSenderMempoolCount, 10))
+	require.Equal(t, CodeStakeTooLow, rejectCode(t, err))
+
+	require.Equal(t, []common.Hash{replacement}, p.Remove([]common.Hash{replacement, {1}}))
+	require.Len(t, p.Pending(testEntryPoint), sameSenderMempoolCount-1)
+}
+
+func TestPending(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPool(&testSimulator{})
+	for _, op := range []*UserOperation{testOp(1, 0, 5), testOp(1, 1, 50), testOp(2, 0, 20)} {
+		_, err := p.Add(ctx, testEntryPoint, op)
+		require.NoError(t, err)
+	}
+	pending := p.Pending(testEntryPoint)
+	require.Len(t, pending, 3)
+	// sender 1 has best tip, but its nonce 0 must go first
+	require.Equal(t, common.Address{1}, pending[0].UserOp.Sender)
+	require.Equal(t, uint64(0), pending[0].UserOp.Nonce.Uint64())
+	require.Equal(t, common.Address{2}, pending[1].UserOp.Sender)
+	require.Equal(t, uint64(1), pending[2].UserOp.Nonce.Uint64())
+	require.Empty(t, p.Pending(common.Address{1}))
+}
+
+func TestOnNewHead(t *testing.T) {
+	ctx := context.Background()
+	paymaster := common.Address{0xaa}
+	now := uint64(time.Now().Unix())
+	sim := &testSimulator{nonces: map[common.Address]uint64{}}
+	sim.result = func(op *UserOperation) *ValidationResult {
+		res := &ValidationResult{Traces: map[Entity]*EntityTrace{}}
+		if op.Sender == (common.Address{2}) {
+			res.ReturnInfo.ValidUntil = now + 60
+		}
+		return res
+	}
+	p := newTestPool(sim)
+	ch, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	op := testOp(1, 0, 10)
+	op.PaymasterAndData = paymaster[:]
+	_, err := p.Add(ctx, testEntryPoint, op)
+	require.NoError(t, err)
+	_, err = p.Add(ctx, testEntryPoint, testOp(1, 1, 10))
+	require.NoError(t, err)
+	_, err = p.Add(ctx, testEntryPoint, testOp(2, 0, 10))
+	require.NoError(t, err)
+	require.Equal(t, op, (<-ch).UserOp)
+	require.Equal(t, []ReputationEntry{{Address: paymaster, OpsSeen: 1}}, p.Reputation())
+
+	sim.nonces[common.Address{1}] = 1
+	require.NoError(t, p.OnNewHead(ctx))
+	require.Len(t, p.Pending(testEntryPoint), 2)
+	require.Equal(t, []ReputationEntry{{Address: paymaster, OpsSeen: 1, OpsIncluded: 1}}, p.Reputation())
+
+	p.lock.Lock()
+	for _, poolOp := range p.ops {
+		if poolOp.ValidUntil != 0 {
+			poolOp.ValidUntil = now - 1
+		}
+	}
+	p.lock.Unlock()
+	require.NoError(t, p.OnNewHead(ctx))
+	pending := p.Pending(testEntryPoint)
+	require.Len(t, pending, 1)
+	require.Equal(t, common.Address{1}, pending[0].UserOp.Sender)
+}
+
+func TestReputation(t *testing.T) {
+	ctx := context.Background()
+	paymaster := common.Address{0xaa}
+	r := NewReputation()
+	for i := 0; i < 10*(throttlingSlack+1); i++ {
+		r.Seen(paymaster)
+	}
+	require.Equal(t, ReputationThrottled, r.Status(paymaster))
+	r.Included(paymaster)
+	require.Equal(t, ReputationOK, r.Status(paymaster))
+	for i := 0; i < 10*banSlack; i++ {
+		r.Seen(paymaster)
+	}
+	require.Equal(t, ReputationBanned, r.Status(paymaster))
+
+	p := newTestPool(&testSimulator{})
+	p.reputation = r
+	op := testOp(1, 0, 10)
+	op.PaymasterAndData = paymaster[:]
+	_, err := p.Add(ctx, testEntryPoint, op)
+	require.Equal(t, CodeBannedOrThrottled, rejectCode(t, err))
+
+	r.Decay()
+	require.Equal(t, uint64(10*(throttlingSlack+1+banSlack)-10*(throttlingSlack+1+banSlack)/24), r.Dump()[0].OpsSeen)
+}
+
+func TestValidationRules(t *testing.T) {
+	p := newTestPool(&testSimulator{})
+	now := time.Now()
+	factory, paymaster, token := common.Address{0xf}, common.Address{0xaa}, common.Address{0x70}
+	op := testOp(1, 0, 10)
+	op.InitCode = factory[:]
+	op.PaymasterAndData = paymaster[:]
+
+	staked := StakeInfo{Stake: DefaultConfig.MinStake, UnstakeDelaySec: DefaultConfig.MinUnstakeDelaySec}
+	result := func(entity Entity, trace *EntityTrace, stakedEntity bool) *ValidationResult {
+		res := &ValidationResult{
+			Sender:    StakeInfo{Addr: op.Sender},
+			Factory:   StakeInfo{Addr: factory},
+			Paymaster: StakeInfo{Addr: paymaster},
+			Traces:    map[Entity]*EntityTrace{entity: trace},
+		}
+		if stakedEntity {
+			res.Paymaster.Stake, res.Paymaster.UnstakeDelaySec = staked.Stake, staked.UnstakeDelaySec
+		}
+		return res
+	}
+	slot := func(s common.Hash) map[common.Hash]struct{} { return map[common.Hash]struct{}{s: {}} }
+
+	require.Nil(t, p.checkValidation(op, result(EntitySender, &EntityTrace{Opcodes: map[string]int{"SLOAD": 1}}, false), now))
+	err := p.checkValidation(op, result(EntitySender, &EntityTrace{Opcodes: map[string]int{"TIMESTAMP": 1}}, false), now)
+	require.Equal(t, CodeBannedOpcode, err.Code)
+	err = p.checkValidation(op, result(EntityPaymaster, &EntityTrace{Opcodes: map[string]int{"BALANCE": 1}}, false), now)
+	require.Equal(t, CodeBannedOpcode, err.Code)
+	require.Nil(t, p.checkValidation(op, result(EntityPaymaster, &EntityTrace{Opcodes: map[string]int{"BALANCE": 1}}, true), now))
+	err = p.checkValidation(op, result(EntitySender, &EntityTrace{Opcodes: map[string]int{"CREATE2": 1}}, false), now)
+	require.Equal(t, CodeBannedOpcode, err.Code)
+	require.Nil(t, p.checkValidation(op, result(EntityFactory, &EntityTrace{Opcodes: map[string]int{"CREATE2": 1}}, false), now))
+	err = p.checkValidation(op, result(EntityPaymaster, &EntityTrace{OOG: true}, false), now)
+	require.Equal(t, CodeRejectedByPaymaster, err.Code)
+
+	// sender may be un-deployed while factory deploys it, other contracts - not
+	require.Nil(t, p.checkValidation(op, result(EntitySender, &EntityTrace{ContractSize: map[common.Address]int{op.Sender: 0}}, false), now))
+	err = p.checkValidation(op, result(EntitySender, &EntityTrace{ContractSize: map[common.Address]int{token: 0}}, false), now)
+	require.Equal(t, CodeBannedOpcode, err.Code)
+
+	// token.balanceOf(sender): slot keccak(sender || 0) is associated with sender, but sender is being deployed by
+	// unstaked factory
+	preimage := append(common.BytesToHash(op.Sender[:]).Bytes(), make([]byte, 32)...)
+	associated := common.BytesToHash(keccak(preimage))
+	trace := &EntityTrace{Keccak: [][]byte{preimage}, Storage: map[common.Address]StorageAccess{token: {Reads: slot(associated)}}}
+	err = p.checkValidation(op, result(EntityPaymaster, trace, false), now)
+	require.Equal(t, CodeBannedOpcode, err.Code)
+	deployed := testOp(1, 0, 10)
+	deployed.PaymasterAndData = paymaster[:]
+	require.Nil(t, p.checkValidation(deployed, result(EntityPaymaster, trace, false), now))
+
+	// paymaster's own storage - only if staked; unassociated storage - only reads of staked entity
+	trace = &EntityTrace{Storage: map[common.Address]StorageAccess{paymaster: {Writes: slot(common.Hash{1})}}}
+	require.Equal(t, CodeBannedOpcode, p.checkValidation(deployed, result(EntityPaymaster, trace, false), now).Code)
+	require.Nil(t, p.checkValidation(deployed, result(EntityPaymaster, trace, true), now))
+	trace = &EntityTrace{Storage: map[common.Address]StorageAccess{token: {Reads: slot(common.Hash{1})}}}
+	require.Nil(t, p.checkValidation(deployed, result(EntityPaymaster, trace, true), now))
+	trace = &EntityTrace{Storage: map[common.Address]StorageAccess{token: {Writes: slot(common.Hash{1})}}}
+	require.Equal(t, CodeBannedOpcode, p.checkValidation(deployed, result(EntityPaymaster, trace, true), now).Code)
+
+	res := result(EntitySender, &EntityTrace{}, false)
+	res.ReturnInfo.ValidUntil = uint64(now.Add(validUntilMargin / 2).Unix())
+	require.Equal(t, CodeShortDeadline, p.checkValidation(op, res, now).Code)
+	res.ReturnInfo.ValidUntil = 0
+	res.ReturnInfo.SigFailed = true
+	require.Equal(t, CodeSignatureFailure, p.checkValidation(op, res, now).Code)
+}
+
+func TestGrpcServer(t *testing.T) {
+	ctx := context.Background()
+	s := NewGrpcServer(newTestPool(&testSimulator{}))
+	entryPoint := gointerfaces.ConvertAddressToH160(testEntryPoint)
+
+	op := testOp(1, 0, 10)
+	op.CallData = []byte{1, 2}
+	reply, err := s.Add(ctx, &txpool_proto.AddUserOpRequest{EntryPoint: entryPoint, UserOp: UserOpToProto(op)})
+	require.NoError(t, err)
+	require.Nil(t, RejectErrorOf(reply))
+
+	reply, err = s.Add(ctx, &txpool_proto.AddUserOpRequest{EntryPoint: gointerfaces.ConvertAddressToH160(common.Address{1}), UserOp: UserOpToProto(op)})
+	require.NoError(t, err)
+	require.Equal(t, CodeInvalidFields, RejectErrorOf(reply).Code)
+
+	pending, err := s.Pending(ctx, &txpool_proto.PendingUserOpsRequest{EntryPoint: entryPoint})
+	require.NoError(t, err)
+	require.Len(t, pending.Ops, 1)
+	require.Equal(t, op, UserOpFromProto(pending.Ops[0].UserOp))
+
+	removed, err := s.Remove(ctx, &txpool_proto.TxHashes{Hashes: []*types2.H256{pending.Ops[0].Hash}})
+	require.NoError(t, err)
+	require.Len(t, removed.Hashes, 1)
+}
@@ -0,0 +1,145 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// ERC-7562 reputation parameters of bundler
+const (
+	minInclusionRateDenominator = 10
+	throttlingSlack             = 10
+	banSlack                    = 50
+	throttledEntityMempoolCount = 4  // max ops in pool of throttled entity
+	unstakedEntityMempoolCount  = 10 // max ops in pool of unstaked factory or paymaster
+	sameSenderMempoolCount      = 4  // max ops in pool of unstaked sender
+)
+
+type ReputationStatus byte
+
+const (
+	ReputationOK ReputationStatus = iota
+	ReputationThrottled
+	ReputationBanned
+)
+
+func (s ReputationStatus) String() string {
+	switch s {
+	case ReputationOK:
+		return "ok"
+	case ReputationThrottled:
+		return "throttled"
+	default:
+		return "banned"
+	}
+}
+
+func (s ReputationStatus) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+type ReputationEntry struct {
+	Address     common.Address   `json:"address"`
+	OpsSeen     uint64           `json:"opsSeen"`
+	OpsIncluded uint64           `json:"opsIncluded"`
+	Status      ReputationStatus `json:"status"`
+}
+
+// Reputation - ERC-7562 reputation of entities (factories, paymasters, aggregators): entity which has much more
+// user operations seen (validated) than included into blocks - is throttled and then banned.
+// Counters decay: `Decay` is expected to be called hourly.
+type Reputation struct {
+	lock    sync.Mutex
+	entries map[common.Address]*ReputationEntry
+}
+
+func NewReputation() *Reputation {
+	return &Reputation{entries: map[common.Address]*ReputationEntry{}}
+}
+
+func (r *Reputation) entry(addr common.Address) *ReputationEntry {
+	e, ok := r.entries[addr]
+	if !ok {
+		e = &ReputationEntry{Address: addr}
+		r.entries[addr] = e
+	}
+	return e
+}
+
+func (r *Reputation) Seen(addr common.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entry(addr).OpsSeen++
+}
+
+func (r *Reputation) Included(addr common.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entry(addr).OpsIncluded++
+}
+
+func (r *Reputation) Status(addr common.Address) ReputationStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	e, ok := r.entries[addr]
+	if !ok {
+		return ReputationOK
+	}
+	return e.status()
+}
+
+func (e *ReputationEntry) status() ReputationStatus {
+	maxSeen := e.OpsSeen / minInclusionRateDenominator
+	switch {
+	case maxSeen <= e.OpsIncluded+throttlingSlack:
+		return ReputationOK
+	case maxSeen <= e.OpsIncluded+banSlack:
+		return ReputationThrottled
+	default:
+		return ReputationBanned
+	}
+}
+
+// Decay - hourly decay of counters, forgotten entities are removed
+func (r *Reputation) Decay() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for addr, e := range r.entries {
+		e.OpsSeen -= e.OpsSeen / 24
+		e.OpsIncluded -= e.OpsIncluded / 24
+		if e.OpsSeen == 0 && e.OpsIncluded == 0 {
+			delete(r.entries, addr)
+		}
+	}
+}
+
+// Dump - all known entities, sorted by address
+func (r *Reputation) Dump() []ReputationEntry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]ReputationEntry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entry := *e
+		entry.Status = e.status()
+		res = append(res, entry)
+	}
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i].Address[:], res[j].Address[:]) < 0 })
+	return res
+}
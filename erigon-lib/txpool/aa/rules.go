@@ -0,0 +1,173 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// bannedOpcodes - [OP-011] opcodes which may give different results in simulation and in bundle.
+// [OP-080] BALANCE and SELFBALANCE are allowed for staked entities.
+var bannedOpcodes = map[string]bool{
+	"GASPRICE": true, "GASLIMIT": true, "DIFFICULTY": true, "PREVRANDAO": true, "TIMESTAMP": true, "BASEFEE": true,
+	"BLOCKHASH": true, "NUMBER": true, "ORIGIN": true, "COINBASE": true, "SELFDESTRUCT": true, "INVALID": true,
+	"BLOBHASH": true, "BLOBBASEFEE": true,
+	"GAS":    true, // [OP-012] is reported by tracer only if not followed by *CALL
+	"CREATE": true, // [OP-031] only CREATE2 of factory is allowed
+}
+
+var stakedOnlyOpcodes = map[string]bool{"BALANCE": true, "SELFBALANCE": true}
+
+// maxAssociatedSlotOffset - slot `keccak(A || x) + n`, n <= maxAssociatedSlotOffset is associated with address A
+const maxAssociatedSlotOffset = 128
+
+// validUntilMargin - user operation must stay valid at least this time after it is added to pool
+const validUntilMargin = 30 * time.Second
+
+func (p *Pool) isStaked(info *StakeInfo) bool {
+	return !info.Stake.Lt(&p.cfg.MinStake) && info.UnstakeDelaySec >= p.cfg.MinUnstakeDelaySec
+}
+
+// checkValidation - ERC-7562 validation rules (opcodes, storage access, stake) on result of simulation
+func (p *Pool) checkValidation(op *UserOperation, res *ValidationResult, now time.Time) *RejectError {
+	info := &res.ReturnInfo
+	if info.SigFailed {
+		return reject(CodeSignatureFailure, "invalid UserOp signature or paymaster signature")
+	}
+	if info.ValidAfter > uint64(now.Unix()) {
+		return reject(CodeShortDeadline, fmt.Sprintf("UserOp is not valid until %d", info.ValidAfter))
+	}
+	if info.ValidUntil != 0 && info.ValidUntil < uint64(now.Add(validUntilMargin).Unix()) {
+		return reject(CodeShortDeadline, fmt.Sprintf("UserOp expires too soon: %d", info.ValidUntil))
+	}
+	if res.Aggregator != nil {
+		return reject(CodeUnsupportedAggregate, fmt.Sprintf("unsupported aggregator %x", res.Aggregator.Addr))
+	}
+
+	factory, paymaster := op.Factory(), op.Paymaster()
+	entities := map[Entity]*StakeInfo{EntitySender: &res.Sender, EntityFactory: &res.Factory, EntityPaymaster: &res.Paymaster}
+	entityAddrs := map[common.Address]bool{op.Sender: true}
+	if factory != (common.Address{}) {
+		entityAddrs[factory] = true
+	}
+	if paymaster != (common.Address{}) {
+		entityAddrs[paymaster] = true
+	}
+
+	for entity, trace := range res.Traces {
+		stake, ok := entities[entity]
+		if !ok {
+			continue
+		}
+		staked := p.isStaked(stake)
+		code := CodeBannedOpcode
+		if entity == EntityPaymaster {
+			code = CodeRejectedByPaymaster
+		}
+		if trace.OOG {
+			return reject(code, fmt.Sprintf("%s ran out of gas during validation", entity))
+		}
+		for opcode, count := range trace.Opcodes {
+			if bannedOpcodes[opcode] || (stakedOnlyOpcodes[opcode] && !staked) {
+				return reject(CodeBannedOpcode, fmt.Sprintf("%s uses banned opcode: %s", entity, opcode))
+			}
+			if opcode == "CREATE2" && (entity != EntityFactory || count > 1) {
+				return reject(CodeBannedOpcode, fmt.Sprintf("%s uses banned opcode: CREATE2", entity))
+			}
+		}
+		for addr, size := range trace.ContractSize {
+			// [OP-041] sender may have no code only while it's deployed by factory
+			if size == 0 && !(addr == op.Sender && factory != (common.Address{})) {
+				return reject(CodeBannedOpcode, fmt.Sprintf("%s accesses un-deployed contract %x", entity, addr))
+			}
+		}
+		if err := checkStorage(entity, stake.Addr, staked, p.isStaked(&res.Factory), op, trace, entityAddrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStorage - [STO-*] rules of storage access
+func checkStorage(entity Entity, entityAddr common.Address, staked, factoryStaked bool, op *UserOperation,
+	trace *EntityTrace, entityAddrs map[common.Address]bool) *RejectError {
+	senderAssociated := associatedSlots(op.Sender, trace.Keccak)
+	entityAssociated := associatedSlots(entityAddr, trace.Keccak)
+	for contract, access := range trace.Storage {
+		for _, slots := range []map[common.Hash]struct{}{access.Reads, access.Writes} {
+			for slot := range slots {
+				var allowed bool
+				switch {
+				case contract == op.Sender: // [STO-010] account's own storage
+					allowed = true
+				case isAssociated(slot, senderAssociated): // [STO-021] while sender is deployed - [STO-022]
+					allowed = len(op.InitCode) == 0 || factoryStaked
+				case contract == entityAddr: // [STO-031]
+					allowed = staked
+				case entityAddrs[contract]:
+					allowed = false
+				case isAssociated(slot, entityAssociated): // [STO-032]
+					allowed = staked
+				default: // [STO-033]
+					allowed = staked && isReadOnly(access, slot)
+				}
+				if !allowed {
+					return reject(CodeBannedOpcode, fmt.Sprintf("%s has forbidden access to storage of %x, slot %x", entity, contract, slot))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isReadOnly(access StorageAccess, slot common.Hash) bool {
+	_, written := access.Writes[slot]
+	return !written
+}
+
+// associatedSlots - bases of slots associated with address: the address itself and `keccak(A || x)` for
+// all KECCAK256 preimages which start with (padded) address
+func associatedSlots(addr common.Address, preimages [][]byte) []uint256.Int {
+	var padded [32]byte
+	copy(padded[12:], addr[:])
+	res := []uint256.Int{*new(uint256.Int).SetBytes(padded[:])}
+	for _, preimage := range preimages {
+		if len(preimage) >= 32 && common.Hash(padded) == common.BytesToHash(preimage[:32]) {
+			res = append(res, *new(uint256.Int).SetBytes(keccak(preimage)))
+		}
+	}
+	return res
+}
+
+func isAssociated(slot common.Hash, bases []uint256.Int) bool {
+	s := new(uint256.Int).SetBytes(slot[:])
+	var offset uint256.Int
+	for i := range bases {
+		if s.Lt(&bases[i]) {
+			continue
+		}
+		if offset.Sub(s, &bases[i]).LtUint64(maxAssociatedSlotOffset + 1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package aa
+
+import (
+	"context"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// Simulator - access to chain state, implemented by node (pool itself doesn't execute EVM)
+type Simulator interface {
+	// SimulateValidation - `EntryPoint.simulateValidation(op)` on top of latest block, with tracing of validation
+	// code of each entity. Returns *RejectError if EntryPoint reverted with `FailedOp`.
+	SimulateValidation(ctx context.Context, entryPoint common.Address, op *UserOperation) (*ValidationResult, error)
+	// Nonce - `EntryPoint.getNonce(sender, key)` at latest block
+	Nonce(ctx context.Context, entryPoint, sender common.Address, key *uint256.Int) (*uint256.Int, error)
+}
+
+// Entity - participant of user operation validation
+type Entity byte
+
+const (
+	EntityFactory Entity = iota
+	EntitySender
+	EntityPaymaster
+	EntityAggregator
+)
+
+func (e Entity) String() string {
+	switch e {
+	case EntityFactory:
+		return "factory"
+	case EntitySender:
+		return "account"
+	case EntityPaymaster:
+		return "paymaster"
+	case EntityAggregator:
+		return "aggregator"
+	default:
+		return "unknown"
+	}
+}
+
+type StakeInfo struct {
+	Addr            common.Address `json:"addr"`
+	Stake           uint256.Int    `json:"stake"`
+	UnstakeDelaySec uint64         `json:"unstakeDelaySec"`
+}
+
+// ReturnInfo - `ValidationResult.returnInfo` of EntryPoint v0.6
+type ReturnInfo struct {
+	PreOpGas         uint64      `json:"preOpGas"`
+	Prefund          uint256.Int `json:"prefund"`
+	SigFailed        bool        `json:"sigFailed"`
+	ValidAfter       uint64      `json:"validAfter"`
+	ValidUntil       uint64      `json:"validUntil"` // 0 - no limit
+	PaymasterContext []byte      `json:"paymasterContext"`
+}
+
+// EntityTrace - what validation code of entity did (in its own frame and all nested calls)
+type EntityTrace struct {
+	Opcodes      map[string]int                   // executed opcodes (GAS - only if not followed by *CALL)
+	Storage      map[common.Address]StorageAccess // storage slots accessed by SLOAD/SSTORE, by contract
+	ContractSize map[common.Address]int           // code size of called addresses
+	Keccak       [][]byte                         // KECCAK256 preimages - to find slots associated with addresses
+	OOG          bool                             // some frame ran out of gas
+}
+
+type StorageAccess struct {
+	Reads  map[common.Hash]struct{}
+	Writes map[common.Hash]struct{}
+}
+
+// ValidationResult - result of `EntryPoint.simulateValidation` (`ValidationResult` or `ValidationResultWithAggregation`)
+type ValidationResult struct {
+	ReturnInfo ReturnInfo
+	Sender     StakeInfo
+	Factory    StakeInfo
+	Paymaster  StakeInfo
+	Aggregator *StakeInfo // nil - no aggregator
+	Traces     map[Entity]*EntityTrace
+}
+
+// Error codes of ERC-4337 bundler RPC
+const (
+	CodeRejectedByAccount    = -32500 // rejected by EntryPoint simulation or by account validation
+	CodeRejectedByPaymaster  = -32501
+	CodeBannedOpcode         = -32502 // opcode or storage access rules violation
+	CodeShortDeadline        = -32503 // validUntil is too close or validAfter in future
+	CodeBannedOrThrottled    = -32504 // reputation of entity
+	CodeStakeTooLow          = -32505
+	CodeUnsupportedAggregate = -32506
+	CodeSignatureFailure     = -32507
+	CodeInvalidFields        = -32602 // invalid fields of user operation, or of request
+)
+
+// RejectError - user operation was not accepted to pool
+type RejectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RejectError) Error() string { return e.Message }
+
+func reject(code int, msg string) *RejectError { return &RejectError{Code: code, Message: msg} }
@@ -0,0 +1,114 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package aa - alternative mempool of ERC-4337 user operations (EntryPoint v0.6): validation of user operations by
+// simulation against ERC-7562 rules, reputation of staked entities (factories, paymasters, aggregators)
+// and gRPC feed of valid user operations for bundlers.
+package aa
+
+import (
+	"github.com/holiman/uint256"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+)
+
+// UserOperation - `UserOperation` struct of EntryPoint v0.6
+type UserOperation struct {
+	Sender               common.Address   `json:"sender"`
+	Nonce                uint256.Int      `json:"nonce"`
+	InitCode             hexutility.Bytes `json:"initCode"`
+	CallData             hexutility.Bytes `json:"callData"`
+	CallGasLimit         uint256.Int      `json:"callGasLimit"`
+	VerificationGasLimit uint256.Int      `json:"verificationGasLimit"`
+	PreVerificationGas   uint256.Int      `json:"preVerificationGas"`
+	MaxFeePerGas         uint256.Int      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas uint256.Int      `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutility.Bytes `json:"paymasterAndData"`
+	Signature            hexutility.Bytes `json:"signature"`
+}
+
+// Factory - deployer of sender, zero address if sender is already deployed
+func (op *UserOperation) Factory() common.Address {
+	return addressPrefix(op.InitCode)
+}
+
+// Paymaster - zero address if sender pays for itself
+func (op *UserOperation) Paymaster() common.Address {
+	return addressPrefix(op.PaymasterAndData)
+}
+
+func addressPrefix(data []byte) common.Address {
+	if len(data) < length.Addr {
+		return common.Address{}
+	}
+	return common.BytesToAddress(data[:length.Addr])
+}
+
+// NonceKey - 2d nonce of EntryPoint: key (high 192 bits) and sequence number (low 64 bits) within key
+func (op *UserOperation) NonceKey() (key uint256.Int, seq uint64) {
+	key.Rsh(&op.Nonce, 64)
+	return key, op.Nonce.Uint64()
+}
+
+// Hash - `EntryPoint.getUserOpHash`: keccak256(abi.encode(keccak256(pack(op)), entryPoint, chainId))
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *uint256.Int) common.Hash {
+	packed := make([]byte, 0, 10*32)
+	packed = appendWord(packed, op.Sender[:])
+	packed = appendUint(packed, &op.Nonce)
+	packed = appendWord(packed, keccak(op.InitCode))
+	packed = appendWord(packed, keccak(op.CallData))
+	packed = appendUint(packed, &op.CallGasLimit)
+	packed = appendUint(packed, &op.VerificationGasLimit)
+	packed = appendUint(packed, &op.PreVerificationGas)
+	packed = appendUint(packed, &op.MaxFeePerGas)
+	packed = appendUint(packed, &op.MaxPriorityFeePerGas)
+	packed = appendWord(packed, keccak(op.PaymasterAndData))
+
+	encoded := make([]byte, 0, 3*32)
+	encoded = appendWord(encoded, keccak(packed))
+	encoded = appendWord(encoded, entryPoint[:])
+	encoded = appendUint(encoded, chainID)
+	return common.BytesToHash(keccak(encoded))
+}
+
+// GasPrice - price which user operation pays with given base fee
+func (op *UserOperation) GasPrice(baseFee *uint256.Int) *uint256.Int {
+	price := new(uint256.Int).Add(baseFee, &op.MaxPriorityFeePerGas)
+	if price.Gt(&op.MaxFeePerGas) {
+		price.Set(&op.MaxFeePerGas)
+	}
+	return price
+}
+
+func keccak(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func appendWord(buf []byte, word []byte) []byte {
+	var padded [32]byte
+	copy(padded[32-len(word):], word)
+	return append(buf, padded[:]...)
+}
+
+func appendUint(buf []byte, v *uint256.Int) []byte {
+	word := v.Bytes32()
+	return append(buf, word[:]...)
+}
@@ -0,0 +1,219 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/google/btree"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var nonceGappedSendersGauge = metrics.GetOrCreateGauge(`txpool_nonce_gapped_senders`)
+
+// analyticsAgeBuckets - upper bounds (in blocks) of buckets of Analytics.Age, last bucket is unbounded
+var analyticsAgeBuckets = []uint64{1, 10, 100, 1000}
+
+// MaxAnalyticsSenders - limit of senders in Analytics.NonceGaps, senders with most gapped txs go first
+const MaxAnalyticsSenders = 100
+
+// Analytics - why txs are stuck in pool: how long they wait, how many can't pay pending base fee
+// and which senders have nonce gaps
+type Analytics struct {
+	Block        uint64           `json:"block"`   // last seen block, age of txs is counted from it
+	BaseFee      uint64           `json:"baseFee"` // pending base fee
+	Txs          int              `json:"txs"`
+	BelowBaseFee int              `json:"belowBaseFee"` // txs with feeCap below pending base fee
+	Age          []AgeBucket      `json:"age"`
+	NonceGaps    []SenderNonceGap `json:"nonceGaps"`
+}
+
+type AgeBucket struct {
+	Blocks string `json:"blocks"` // range of age in blocks, like "2-10"
+	Txs    int    `json:"txs"`
+}
+
+// SenderNonceGap - txs of sender with nonce above FirstGap can't be included until txn with nonce FirstGap arrives
+type SenderNonceGap struct {
+	Sender     common.Address `json:"sender"`
+	StateNonce uint64         `json:"stateNonce"`
+	FirstGap   uint64         `json:"firstGap"` // lowest missing nonce
+	GappedTxs  int            `json:"gappedTxs"`
+}
+
+type feeCapCount struct {
+	feeCap uint64
+	count  int
+}
+
+// poolAnalytics - aggregates of pool content, maintained incrementally on add and discard of every txn and on
+// change of sender's state, so Analytics doesn't iterate over all txs
+type poolAnalytics struct {
+	byBlock map[uint64]int             // block number of arrival => count of txs
+	feeCaps *btree.BTreeG[feeCapCount] // feeCap => count of txs
+	nonces  map[uint64]uint64          // senderID => state nonce, seen by last onSenderStateChange
+	gaps    map[uint64]*SenderNonceGap // senderID => nonce gap, only senders with gapped txs
+}
+
+func newPoolAnalytics() *poolAnalytics {
+	return &poolAnalytics{
+		byBlock: map[uint64]int{},
+		feeCaps: btree.NewG[feeCapCount](32, func(a, b feeCapCount) bool { return a.feeCap < b.feeCap }),
+		nonces:  map[uint64]uint64{},
+		gaps:    map[uint64]*SenderNonceGap{},
+	}
+}
+
+func feeCapOf(mt *metaTx) uint64 {
+	if !mt.Tx.FeeCap.IsUint64() {
+		return ^uint64(0)
+	}
+	return mt.Tx.FeeCap.Uint64()
+}
+
+func (a *poolAnalytics) added(mt *metaTx) {
+	a.byBlock[mt.timestamp]++
+	item, _ := a.feeCaps.Get(feeCapCount{feeCap: feeCapOf(mt)})
+	item.feeCap = feeCapOf(mt)
+	item.count++
+	a.feeCaps.ReplaceOrInsert(item)
+}
+
+func (a *poolAnalytics) removed(mt *metaTx) {
+	if a.byBlock[mt.timestamp] > 1 {
+		a.byBlock[mt.timestamp]--
+	} else {
+		delete(a.byBlock, mt.timestamp)
+	}
+	item, ok := a.feeCaps.Get(feeCapCount{feeCap: feeCapOf(mt)})
+	if !ok {
+		return
+	}
+	if item.count > 1 {
+		item.count--
+		a.feeCaps.ReplaceOrInsert(item)
+	} else {
+		a.feeCaps.Delete(item)
+	}
+}
+
+// updateNonceGapLocked - re-calculates nonce gap of sender, must be called on every change of sender's txs or state
+func (p *TxPool) updateNonceGapLocked(senderID uint64) {
+	nonce, ok := p.analytics.nonces[senderID]
+	if !ok { // state of sender is not known yet, onSenderStateChange will follow
+		return
+	}
+	firstGap, gapped := nonce, 0
+	p.all.ascend(senderID, func(mt *metaTx) bool {
+		switch {
+		case gapped == 0 && mt.Tx.Nonce == firstGap:
+			firstGap++
+		case mt.Tx.Nonce > firstGap:
+			gapped++
+		}
+		return true
+	})
+	if gapped > 0 {
+		p.analytics.gaps[senderID] = &SenderNonceGap{StateNonce: nonce, FirstGap: firstGap, GappedTxs: gapped}
+	} else {
+		delete(p.analytics.gaps, senderID)
+	}
+	if p.all.count(senderID) == 0 {
+		delete(p.analytics.nonces, senderID)
+	}
+	nonceGappedSendersGauge.SetInt(len(p.analytics.gaps))
+}
+
+func ageBucket(block, arrival uint64) int {
+	var age uint64
+	if block > arrival {
+		age = block - arrival
+	}
+	for i, bound := range analyticsAgeBuckets {
+		if age <= bound {
+			return i
+		}
+	}
+	return len(analyticsAgeBuckets)
+}
+
+// Analytics - age distribution of txs, count of txs below pending base fee and senders with nonce gaps.
+// Non-nil sender limits analytics to txs of given sender.
+func (p *TxPool) Analytics(sender *common.Address) *Analytics {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	res := &Analytics{
+		Block:     p.lastSeenBlock.Load(),
+		BaseFee:   p.pendingBaseFee.Load(),
+		Age:       make([]AgeBucket, len(analyticsAgeBuckets)+1),
+		NonceGaps: []SenderNonceGap{},
+	}
+	from := uint64(0)
+	for i, bound := range analyticsAgeBuckets {
+		res.Age[i].Blocks = fmt.Sprintf("%d-%d", from, bound)
+		from = bound + 1
+	}
+	res.Age[len(analyticsAgeBuckets)].Blocks = fmt.Sprintf(">%d", from-1)
+
+	if sender != nil {
+		senderID, ok := p.senders.getID(*sender)
+		if !ok {
+			return res
+		}
+		p.all.ascend(senderID, func(mt *metaTx) bool {
+			res.Txs++
+			res.Age[ageBucket(res.Block, mt.timestamp)].Txs++
+			if feeCapOf(mt) < res.BaseFee {
+				res.BelowBaseFee++
+			}
+			return true
+		})
+		if gap, ok := p.analytics.gaps[senderID]; ok {
+			res.NonceGaps = append(res.NonceGaps, *gap)
+			res.NonceGaps[0].Sender = *sender
+		}
+		return res
+	}
+
+	res.Txs = p.all.tree.Len()
+	for block, count := range p.analytics.byBlock {
+		res.Age[ageBucket(res.Block, block)].Txs += count
+	}
+	p.analytics.feeCaps.AscendLessThan(feeCapCount{feeCap: res.BaseFee}, func(item feeCapCount) bool {
+		res.BelowBaseFee += item.count
+		return true
+	})
+	for senderID, gap := range p.analytics.gaps {
+		g := *gap
+		g.Sender = p.senders.senderID2Addr[senderID]
+		res.NonceGaps = append(res.NonceGaps, g)
+	}
+	sort.Slice(res.NonceGaps, func(i, j int) bool {
+		if res.NonceGaps[i].GappedTxs != res.NonceGaps[j].GappedTxs {
+			return res.NonceGaps[i].GappedTxs > res.NonceGaps[j].GappedTxs
+		}
+		return bytes.Compare(res.NonceGaps[i].Sender[:], res.NonceGaps[j].Sender[:]) < 0
+	})
+	if len(res.NonceGaps) > MaxAnalyticsSenders {
+		res.NonceGaps = res.NonceGaps[:MaxAnalyticsSenders]
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+)
+
+var (
+	blobPoolTxsGauge     = metrics.GetOrCreateGauge(`txpool_blob_txs`)
+	blobPoolBlobsGauge   = metrics.GetOrCreateGauge(`txpool_blob_blobs`)
+	blobPoolBytesGauge   = metrics.GetOrCreateGauge(`txpool_blob_bytes`)
+	blobPoolEvictedCount = metrics.GetOrCreateCounter(`txpool_blob_evicted`)
+)
+
+// blobPool - blob (type-3) txs of pool. They live in same sub-pools as other txs (pending/baseFee/queued) - to be yielded
+// in same order, but have own limits: total amount of blobs and total size (blobs dominate memory of pool).
+// When limits are reached - new blob tx evicts blob txs with lower blob fee cap, or is rejected.
+type blobPool struct {
+	txs   map[*metaTx]struct{}
+	blobs uint64
+	bytes uint64
+}
+
+func newBlobPool() *blobPool { return &blobPool{txs: map[*metaTx]struct{}{}} }
+
+func (b *blobPool) add(mt *metaTx) {
+	if _, ok := b.txs[mt]; ok {
+		return
+	}
+	b.txs[mt] = struct{}{}
+	b.blobs += uint64(len(mt.Tx.BlobHashes))
+	b.bytes += uint64(mt.Tx.Size)
+}
+
+func (b *blobPool) remove(mt *metaTx) {
+	if _, ok := b.txs[mt]; !ok {
+		return
+	}
+	delete(b.txs, mt)
+	b.blobs -= uint64(len(mt.Tx.BlobHashes))
+	b.bytes -= uint64(mt.Tx.Size)
+}
+
+func (b *blobPool) fits(blobs, bytes uint64, cfg txpoolcfg.Config) bool {
+	if blobs > cfg.TotalBlobPoolLimit {
+		return false
+	}
+	return cfg.TotalBlobPoolBytes == 0 || bytes <= uint64(cfg.TotalBlobPoolBytes)
+}
+
+// evictionsFor - txs to evict to give room to `mt`, nil and false if `mt` doesn't pay enough.
+// Only non-local txs with highest nonce of their sender are evictable: eviction doesn't make nonce gaps.
+// Cheapest by blob fee cap are evicted first
+func (b *blobPool) evictionsFor(mt *metaTx, all *BySenderAndNonce, cfg txpoolcfg.Config) ([]*metaTx, bool) {
+	blobs, bytes := b.blobs+uint64(len(mt.Tx.BlobHashes)), b.bytes+uint64(mt.Tx.Size)
+	var evict []*metaTx
+	evicted := map[*metaTx]struct{}{}
+	for !b.fits(blobs, bytes, cfg) {
+		var worst *metaTx
+		for candidate := range b.txs {
+			if _, ok := evicted[candidate]; ok || candidate.subPool&IsLocal != 0 || candidate.Tx.SenderID == mt.Tx.SenderID {
+				continue
+			}
+			if next := all.get(candidate.Tx.SenderID, candidate.Tx.Nonce+1); next != nil {
+				if _, ok := evicted[next]; !ok {
+					continue
+				}
+			}
+			if worst == nil || candidate.Tx.BlobFeeCap.Lt(&worst.Tx.BlobFeeCap) {
+				worst = candidate
+			}
+		}
+		if worst == nil || !worst.Tx.BlobFeeCap.Lt(&mt.Tx.BlobFeeCap) {
+			return nil, false
+		}
+		evict = append(evict, worst)
+		evicted[worst] = struct{}{}
+		blobs, bytes = blobs-uint64(len(worst.Tx.BlobHashes)), bytes-uint64(worst.Tx.Size)
+	}
+	return evict, true
+}
+
+func (b *blobPool) updateMetrics() {
+	blobPoolTxsGauge.SetInt(len(b.txs))
+	blobPoolBlobsGauge.SetUint64(b.blobs)
+	blobPoolBytesGauge.SetUint64(b.bytes)
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"testing"
+
+	"github.com/google/btree"
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+func TestBlobPoolEviction(t *testing.T) {
+	all := &BySenderAndNonce{
+		tree:              btree.NewG[*metaTx](32, SortByNonceLess),
+		search:            &metaTx{Tx: &types.TxSlot{}},
+		senderIDTxnCount:  map[uint64]int{},
+		senderIDBlobCount: map[uint64]uint64{},
+	}
+	blobs := newBlobPool()
+	newBlobTx := func(sender, nonce, blobFeeCap uint64, isLocal bool) *metaTx {
+		mt := newMetaTx(&types.TxSlot{
+			Type:       types.BlobTxType,
+			SenderID:   sender,
+			Nonce:      nonce,
+			BlobFeeCap: *uint256.NewInt(blobFeeCap),
+			BlobHashes: make([]common.Hash, 2),
+			Size:       1000,
+		}, isLocal, 0)
+		all.replaceOrInsert(mt, log.New())
+		return mt
+	}
+	cfg := txpoolcfg.DefaultConfig
+	cfg.TotalBlobPoolLimit, cfg.TotalBlobPoolBytes = 6, 0
+
+	cheapHead := newBlobTx(1, 0, 10, false)
+	cheapTail := newBlobTx(1, 1, 20, false)
+	local := newBlobTx(2, 0, 5, true)
+	for _, mt := range []*metaTx{cheapHead, cheapTail, local} {
+		blobs.add(mt)
+	}
+	require.Equal(t, uint64(6), blobs.blobs)
+	require.Equal(t, uint64(3000), blobs.bytes)
+
+	// same blob fee cap as cheapest evictable: rejected
+	_, ok := blobs.evictionsFor(newBlobTx(3, 0, 20, false), all, cfg)
+	require.False(t, ok)
+
+	// only tail of sender 1 is evictable, local tx isn't
+	evict, ok := blobs.evictionsFor(newBlobTx(3, 1, 21, false), all, cfg)
+	require.True(t, ok)
+	require.Equal(t, []*metaTx{cheapTail}, evict)
+
+	// room for 4 blobs: whole sender 1 is evicted from tail
+	cfg.TotalBlobPoolLimit = 4
+	evict, ok = blobs.evictionsFor(newBlobTx(4, 0, 30, false), all, cfg)
+	require.True(t, ok)
+	require.Equal(t, []*metaTx{cheapTail, cheapHead}, evict)
+
+	// bytes limit
+	cfg.TotalBlobPoolLimit, cfg.TotalBlobPoolBytes = 100, 3500
+	evict, ok = blobs.evictionsFor(newBlobTx(5, 0, 30, false), all, cfg)
+	require.True(t, ok)
+	require.Equal(t, []*metaTx{cheapTail}, evict)
+
+	// txs of same sender don't evict each other
+	_, ok = blobs.evictionsFor(newBlobTx(1, 2, 100, false), all, cfg)
+	require.False(t, ok)
+
+	blobs.remove(cheapTail)
+	blobs.remove(cheapTail)
+	require.Equal(t, uint64(4), blobs.blobs)
+	require.Equal(t, uint64(2000), blobs.bytes)
+}
@@ -0,0 +1,188 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+var (
+	conditionalTxsGauge          = metrics.GetOrCreateGauge(`txpool_conditional_txs`)
+	conditionalTxsDroppedCounter = metrics.GetOrCreateCounter(`txpool_conditional_txs_dropped`)
+)
+
+// MaxConditionSlots - limit of storage slots in KnownAccounts of one conditional txn, they are re-checked on every block
+const MaxConditionSlots = 1000
+
+// Conditions - preconditions of conditional txn (options of `eth_sendRawTransactionConditional`).
+// Txn is accepted only if conditions are met at pending block, and is dropped as soon as new block invalidates them.
+type Conditions struct {
+	KnownAccounts  map[common.Address]map[common.Hash]common.Hash `json:"knownAccounts,omitempty"` // expected values of storage slots
+	BlockNumberMin *uint64                                        `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *uint64                                        `json:"blockNumberMax,omitempty"`
+	TimestampMin   *uint64                                        `json:"timestampMin,omitempty"`
+	TimestampMax   *uint64                                        `json:"timestampMax,omitempty"`
+}
+
+func (c *Conditions) Validate() error {
+	slots := 0
+	for _, storage := range c.KnownAccounts {
+		slots += len(storage)
+	}
+	if slots > MaxConditionSlots {
+		return fmt.Errorf("too many knownAccounts slots: %d, max %d", slots, MaxConditionSlots)
+	}
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && *c.BlockNumberMin > *c.BlockNumberMax {
+		return errors.New("blockNumberMin is greater than blockNumberMax")
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return errors.New("timestampMin is greater than timestampMax")
+	}
+	return nil
+}
+
+// check - conditions are met at block `pendingBlock` built at `now` on top of state of `cacheView`.
+// Timestamp of pending block is not known to txpool - wall clock is used instead.
+func (c *Conditions) check(cacheView kvcache.CacheView, pendingBlock uint64, now time.Time) error {
+	if c.BlockNumberMin != nil && pendingBlock < *c.BlockNumberMin {
+		return fmt.Errorf("block number %d is less than blockNumberMin %d", pendingBlock, *c.BlockNumberMin)
+	}
+	if c.BlockNumberMax != nil && pendingBlock > *c.BlockNumberMax {
+		return fmt.Errorf("block number %d is greater than blockNumberMax %d", pendingBlock, *c.BlockNumberMax)
+	}
+	timestamp := uint64(now.Unix())
+	if c.TimestampMin != nil && timestamp < *c.TimestampMin {
+		return fmt.Errorf("timestamp %d is less than timestampMin %d", timestamp, *c.TimestampMin)
+	}
+	if c.TimestampMax != nil && timestamp > *c.TimestampMax {
+		return fmt.Errorf("timestamp %d is greater than timestampMax %d", timestamp, *c.TimestampMax)
+	}
+	for addr, storage := range c.KnownAccounts {
+		for slot, expected := range storage {
+			value, err := storageAt(cacheView, addr, slot)
+			if err != nil {
+				return err
+			}
+			if value != expected {
+				return fmt.Errorf("storage slot %x of %x is %x, expected %x", slot, addr, value, expected)
+			}
+		}
+	}
+	return nil
+}
+
+func storageAt(cacheView kvcache.CacheView, addr common.Address, slot common.Hash) (common.Hash, error) {
+	var key []byte
+	if cacheView.StateV3() {
+		key = append(common.Copy(addr[:]), slot[:]...)
+	} else {
+		encoded, err := cacheView.Get(addr[:])
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if len(encoded) == 0 {
+			return common.Hash{}, nil
+		}
+		incarnation, err := types.DecodeIncarnation(encoded)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		key = make([]byte, 0, len(addr)+8+len(slot))
+		key = append(key, addr[:]...)
+		key = binary.BigEndian.AppendUint64(key, incarnation)
+		key = append(key, slot[:]...)
+	}
+	v, err := cacheView.Get(key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(v), nil
+}
+
+// AddConditionalTxs - adds local txs with preconditions. Conditional txs are not gossiped (peers don't know their
+// conditions) and not persisted, like private txs, and are discarded as soon as new block invalidates conditions.
+func (p *TxPool) AddConditionalTxs(ctx context.Context, newTxs types.TxSlots, tx kv.Tx, conditions *Conditions) ([]txpoolcfg.DiscardReason, error) {
+	if err := conditions.Validate(); err != nil {
+		return nil, err
+	}
+	coreDb, cache := p.coreDBWithCache()
+	coreTx, err := coreDb.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer coreTx.Rollback()
+	cacheView, err := cache.View(ctx, coreTx)
+	if err != nil {
+		return nil, err
+	}
+	lastSeenBlock := p.lastSeenBlock.Load()
+	if err := conditions.check(cacheView, lastSeenBlock+1, time.Now()); err != nil {
+		return nil, fmt.Errorf("%s: %w", txpoolcfg.ConditionFailed, err)
+	}
+
+	maxBlockNumber := lastSeenBlock + p.cfg.PrivateTxBlocks
+	if conditions.BlockNumberMax != nil && *conditions.BlockNumberMax < maxBlockNumber {
+		maxBlockNumber = *conditions.BlockNumberMax
+	}
+	return p.addLocalTxs(ctx, newTxs, maxBlockNumber, conditions)
+}
+
+func (p *TxPool) setConditionsLocked(idHash []byte, conditions *Conditions) {
+	p.conditional[string(idHash)] = conditions
+	conditionalTxsGauge.SetInt(len(p.conditional))
+}
+
+func (p *TxPool) unsetConditionsLocked(hashStr string) {
+	if _, ok := p.conditional[hashStr]; !ok {
+		return
+	}
+	delete(p.conditional, hashStr)
+	conditionalTxsGauge.SetInt(len(p.conditional))
+}
+
+// dropConditionalLocked - discards conditional txs whose conditions are not met anymore on top of new block
+func (p *TxPool) dropConditionalLocked(cacheView kvcache.CacheView, block uint64) {
+	now := time.Now()
+	for hashStr, conditions := range p.conditional {
+		mt, ok := p.byHash[hashStr]
+		if !ok {
+			p.unsetConditionsLocked(hashStr)
+			continue
+		}
+		err := conditions.check(cacheView, block+1, now)
+		if err == nil {
+			continue
+		}
+		if mt.Tx.Traced {
+			p.logger.Info(fmt.Sprintf("TX TRACING: conditional txn dropped idHash=%x, senderId=%d: %s", mt.Tx.IDHash, mt.Tx.SenderID, err))
+		}
+		p.removeFromSubPool(mt, "conditions-failed")
+		p.discardLocked(mt, txpoolcfg.ConditionFailed)
+		conditionalTxsDroppedCounter.Inc()
+	}
+}
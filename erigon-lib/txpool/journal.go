@@ -0,0 +1,301 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+const localJournalFileName = "locals.journal"
+
+var journaledTxsGauge = metrics.GetOrCreateGauge(`txpool_journal_txs`)
+
+// localJournal - append-only file of local txs. Pool's db stores txs only every `CommitEvery` and doesn't store
+// txs which were evicted, journal stores local txs at the moment they were added: they are replayed to pool
+// on restart and periodically re-added (re-announced) until they are mined or `lifetime` expired.
+//
+// Record: added_unix_seconds(8) + tx_hash(32) + sender(20) + rlp_len(4) + rlp.
+// Removed txs stay in file until `rotate` - which rewrites file with alive txs only.
+type localJournal struct {
+	path     string
+	lifetime time.Duration
+	txs      map[common.Hash]*journalTx
+	f        *os.File
+	dirty    bool // file has records of removed txs
+}
+
+type journalTx struct {
+	hash   common.Hash
+	sender common.Address
+	rlp    []byte
+	added  time.Time
+}
+
+const journalRecordHeader = 8 + 32 + 20 + 4
+
+func openLocalJournal(dbDir string, lifetime time.Duration) (*localJournal, error) {
+	j := &localJournal{path: filepath.Join(dbDir, localJournalFileName), lifetime: lifetime, txs: map[common.Hash]*journalTx{}}
+	if err := j.load(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := j.rotate(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// load - reads journal file, tolerates truncated last record (node was killed while writing it)
+func (j *localJournal) load(now time.Time) error {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for len(data) > 0 {
+		if len(data) < journalRecordHeader {
+			j.dirty = true
+			break
+		}
+		rlpLen := int(binary.BigEndian.Uint32(data[8+32+20:]))
+		if len(data) < journalRecordHeader+rlpLen {
+			j.dirty = true
+			break
+		}
+		txn := &journalTx{added: time.Unix(int64(binary.BigEndian.Uint64(data)), 0)}
+		copy(txn.hash[:], data[8:])
+		copy(txn.sender[:], data[8+32:])
+		txn.rlp = common.Copy(data[journalRecordHeader : journalRecordHeader+rlpLen])
+		data = data[journalRecordHeader+rlpLen:]
+		if _, ok := j.txs[txn.hash]; ok || j.expired(txn, now) {
+			j.dirty = true
+			continue
+		}
+		j.txs[txn.hash] = txn
+	}
+	journaledTxsGauge.SetInt(len(j.txs))
+	return nil
+}
+
+func (j *localJournal) expired(txn *journalTx, now time.Time) bool {
+	return j.lifetime > 0 && now.Sub(txn.added) > j.lifetime
+}
+
+func (j *localJournal) insert(hash common.Hash, sender common.Address, rlp []byte, now time.Time) error {
+	if j == nil {
+		return nil
+	}
+	if _, ok := j.txs[hash]; ok {
+		return nil
+	}
+	txn := &journalTx{hash: hash, sender: sender, rlp: common.Copy(rlp), added: now}
+	j.txs[hash] = txn
+	journaledTxsGauge.SetInt(len(j.txs))
+	if j.f == nil {
+		return nil
+	}
+	_, err := j.f.Write(encodeJournalTx(nil, txn))
+	return err
+}
+
+func (j *localJournal) remove(hash common.Hash) bool {
+	if j == nil {
+		return false
+	}
+	if _, ok := j.txs[hash]; !ok {
+		return false
+	}
+	delete(j.txs, hash)
+	j.dirty = true
+	journaledTxsGauge.SetInt(len(j.txs))
+	return true
+}
+
+func (j *localJournal) expire(now time.Time) {
+	if j == nil {
+		return
+	}
+	for hash, txn := range j.txs {
+		if j.expired(txn, now) {
+			j.remove(hash)
+		}
+	}
+}
+
+// list - alive txs, oldest first
+func (j *localJournal) list() []*journalTx {
+	if j == nil {
+		return nil
+	}
+	res := make([]*journalTx, 0, len(j.txs))
+	for _, txn := range j.txs {
+		res = append(res, txn)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if !res[a].added.Equal(res[b].added) {
+			return res[a].added.Before(res[b].added)
+		}
+		return bytes.Compare(res[a].hash[:], res[b].hash[:]) < 0
+	})
+	return res
+}
+
+// rotate - rewrites file (if it has records of removed txs) and opens it for appending
+func (j *localJournal) rotate() error {
+	if j == nil {
+		return nil
+	}
+	if j.dirty {
+		if j.f != nil {
+			if err := j.f.Close(); err != nil {
+				return err
+			}
+			j.f = nil
+		}
+		var buf []byte
+		for _, txn := range j.list() {
+			buf = encodeJournalTx(buf, txn)
+		}
+		if err := dir.WriteFileWithFsync(j.path+".tmp", buf, 0644); err != nil {
+			return fmt.Errorf("rewrite journal: %w", err)
+		}
+		if err := os.Rename(j.path+".tmp", j.path); err != nil {
+			return fmt.Errorf("rewrite journal: %w", err)
+		}
+		j.dirty = false
+	}
+	if j.f != nil {
+		return nil
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	j.f = f
+	return nil
+}
+
+// close - persists removals and closes file
+func (j *localJournal) close() error {
+	if j == nil || j.f == nil {
+		return nil
+	}
+	if err := j.rotate(); err != nil {
+		return err
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+func encodeJournalTx(buf []byte, txn *journalTx) []byte {
+	var header [journalRecordHeader]byte
+	binary.BigEndian.PutUint64(header[:], uint64(txn.added.Unix()))
+	copy(header[8:], txn.hash[:])
+	copy(header[8+32:], txn.sender[:])
+	binary.BigEndian.PutUint32(header[8+32+20:], uint32(len(txn.rlp)))
+	buf = append(buf, header[:]...)
+	return append(buf, txn.rlp...)
+}
+
+// rejournal - re-adds journaled txs to pool: missing ones (evicted, or lost by reorg) are added again and pending ones
+// are re-announced to peers. Mined txs are removed from journal.
+func (p *TxPool) rejournal(ctx context.Context) error {
+	p.lock.Lock()
+	p.journal.expire(time.Now())
+	journaled := p.journal.list()
+	p.lock.Unlock()
+
+	var slots types.TxSlots
+	var broken []common.Hash
+	parseCtx := types.NewTxParseContext(p.chainID)
+	parseCtx.WithSender(false)
+	for _, jtx := range journaled {
+		j := len(slots.Txs)
+		slots.Resize(uint(j + 1))
+		slots.Txs[j] = &types.TxSlot{}
+		slots.IsLocal[j] = true
+		if _, err := parseCtx.ParseTransaction(jtx.rlp, 0, slots.Txs[j], nil, false /* hasEnvelope */, true /*wrappedWithBlobs*/, nil); err != nil {
+			slots.Resize(uint(j))
+			broken = append(broken, jtx.hash)
+			continue
+		}
+		copy(slots.Senders.At(j), jtx.sender[:])
+	}
+
+	var reasons []txpoolcfg.DiscardReason
+	if len(slots.Txs) > 0 {
+		var err error
+		if reasons, err = p.AddLocalTxs(ctx, slots, nil); err != nil {
+			return err
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, hash := range broken {
+		p.journal.remove(hash)
+	}
+	for i, reason := range reasons {
+		if reason == txpoolcfg.NonceTooLow {
+			p.journal.remove(slots.Txs[i].IDHash)
+		}
+	}
+	return p.journal.rotate()
+}
+
+// Journaled - rlp of journaled local txs, oldest first
+func (p *TxPool) Journaled() [][]byte {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	journaled := p.journal.list()
+	res := make([][]byte, len(journaled))
+	for i, txn := range journaled {
+		res[i] = common.Copy(txn.rlp)
+	}
+	return res
+}
+
+// EvictJournaled - removes txs from journal: they are not replayed and re-announced anymore, but stay in pool.
+// Returns hashes of removed txs.
+func (p *TxPool) EvictJournaled(hashes []common.Hash) ([]common.Hash, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var evicted []common.Hash
+	for _, hash := range hashes {
+		if p.journal.remove(hash) {
+			evicted = append(evicted, hash)
+		}
+	}
+	return evicted, p.journal.rotate()
+}
@@ -0,0 +1,74 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+func TestLocalJournal(t *testing.T) {
+	dir := t.TempDir()
+	j, err := openLocalJournal(dir, time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now()
+	hashes := []common.Hash{{1}, {2}, {3}}
+	require.NoError(t, j.insert(hashes[0], common.Address{1}, []byte{0xc1, 0x01}, now.Add(-2*time.Hour)))
+	require.NoError(t, j.insert(hashes[1], common.Address{2}, []byte{0xc1, 0x02}, now.Add(-time.Minute)))
+	require.NoError(t, j.insert(hashes[2], common.Address{3}, []byte{0xc1, 0x03}, now))
+	require.NoError(t, j.insert(hashes[2], common.Address{3}, []byte{0xc1, 0x03}, now)) // duplicate
+	require.True(t, j.remove(hashes[2]))
+	require.False(t, j.remove(hashes[2]))
+	require.NoError(t, j.close())
+
+	// killed while writing last record
+	f, err := os.OpenFile(filepath.Join(dir, localJournalFileName), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write(encodeJournalTx(nil, &journalTx{hash: common.Hash{4}, rlp: []byte{0xc1, 0x04}, added: now})[:journalRecordHeader+1])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// first one is expired
+	j, err = openLocalJournal(dir, time.Hour)
+	require.NoError(t, err)
+	list := j.list()
+	require.Len(t, list, 1)
+	require.Equal(t, hashes[1], list[0].hash)
+	require.Equal(t, common.Address{2}, list[0].sender)
+	require.Equal(t, []byte{0xc1, 0x02}, list[0].rlp)
+
+	j.expire(now.Add(2 * time.Hour))
+	require.Empty(t, j.list())
+	require.NoError(t, j.rotate())
+	require.NoError(t, j.close())
+
+	data, err := os.ReadFile(filepath.Join(dir, localJournalFileName))
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	var disabled *localJournal
+	require.NoError(t, disabled.insert(hashes[0], common.Address{1}, nil, now))
+	require.False(t, disabled.remove(hashes[0]))
+	require.Empty(t, disabled.list())
+}
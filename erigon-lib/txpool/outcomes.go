@@ -0,0 +1,99 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+var outcomeSubscribersGauge = metrics.GetOrCreateGauge(`txpool_outcome_subscribers`)
+
+type TxStatus string
+
+const (
+	TxAccepted TxStatus = "accepted" // txn added to pool
+	TxRejected TxStatus = "rejected" // txn not added to pool, see Reason
+	TxReplaced TxStatus = "replaced" // txn removed from pool by txn with same sender and nonce, see ReplacedBy
+	TxDropped  TxStatus = "dropped"  // txn removed from pool without inclusion: evicted, expired, invalidated by new block
+	TxMined    TxStatus = "mined"    // txn removed from pool because it's included in block
+)
+
+// TxOutcome - what happened to txn in pool, stream of outcomes is available by SubscribeOutcomes
+type TxOutcome struct {
+	Hash       common.Hash             `json:"hash"`
+	Sender     common.Address          `json:"sender"`
+	Nonce      uint64                  `json:"nonce"`
+	Status     TxStatus                `json:"status"`
+	ReasonCode txpoolcfg.DiscardReason `json:"reasonCode,omitempty"`
+	Reason     string                  `json:"reason,omitempty"`
+	ReplacedBy *common.Hash            `json:"replacedBy,omitempty"`
+}
+
+// SubscribeOutcomes - feed of outcomes of txs (accepted, rejected, replaced, dropped, mined).
+// Slow subscribers may miss outcomes.
+func (p *TxPool) SubscribeOutcomes() (<-chan *TxOutcome, func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ch := make(chan *TxOutcome, 4096)
+	p.lastOutcomeSubID++
+	id := p.lastOutcomeSubID
+	p.outcomeSubs[id] = ch
+	outcomeSubscribersGauge.SetInt(len(p.outcomeSubs))
+	return ch, func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		delete(p.outcomeSubs, id)
+		outcomeSubscribersGauge.SetInt(len(p.outcomeSubs))
+	}
+}
+
+func (p *TxPool) publishOutcomeLocked(txn *types.TxSlot, sender common.Address, status TxStatus, reason txpoolcfg.DiscardReason, replacedBy *common.Hash) {
+	if len(p.outcomeSubs) == 0 {
+		return
+	}
+	outcome := &TxOutcome{
+		Hash:       common.Hash(txn.IDHash),
+		Sender:     sender,
+		Nonce:      txn.Nonce,
+		Status:     status,
+		ReplacedBy: replacedBy,
+	}
+	if status != TxAccepted && status != TxMined {
+		outcome.ReasonCode = reason
+		outcome.Reason = reason.String()
+	}
+	for _, ch := range p.outcomeSubs {
+		common.PrioritizedSend(ch, outcome)
+	}
+}
+
+// publishDiscardLocked - outcome of txn removed from pool, replacement is published by addLocked (it knows new txn)
+func (p *TxPool) publishDiscardLocked(mt *metaTx, reason txpoolcfg.DiscardReason) {
+	if len(p.outcomeSubs) == 0 {
+		return
+	}
+	switch reason {
+	case txpoolcfg.ReplacedByHigherTip:
+	case txpoolcfg.Mined:
+		p.publishOutcomeLocked(mt.Tx, p.senders.senderID2Addr[mt.Tx.SenderID], TxMined, reason, nil)
+	default:
+		p.publishOutcomeLocked(mt.Tx, p.senders.senderID2Addr[mt.Tx.SenderID], TxDropped, reason, nil)
+	}
+}
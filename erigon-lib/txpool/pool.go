@@ -132,6 +132,7 @@ type metaTx struct {
 	subPool                   SubPoolMarker
 	currentSubPool            SubPoolType
 	minedBlockNum             uint64
+	arrival                   uint64 // sequence number of arrival to pool, see ScoredTx.Arrival
 }
 
 func newMetaTx(slot *types.TxSlot, isLocal bool, timestamp uint64) *metaTx {
@@ -221,7 +222,15 @@ type TxPool struct {
 	pendingBaseFee          atomic.Uint64
 	pendingBlobFee          atomic.Uint64 // For gas accounting for blobs, which has its own dimension
 	blockGasLimit           atomic.Uint64
-	totalBlobsInPool        atomic.Uint64
+	blobs                   *blobPool // accounting and limits of blob txs
+	journal                 *localJournal
+	scorer                  Scorer                  // custom order of best txs, nil - default order
+	private                 map[string]uint64       // tx_hash => max block number of private txn, see AddPrivateTxs
+	conditional             map[string]*Conditions  // tx_hash => conditions of conditional txn, see AddConditionalTxs
+	outcomeSubs             map[int]chan *TxOutcome // subscribers of SubscribeOutcomes
+	lastOutcomeSubID        int
+	analytics               *poolAnalytics // aggregates of pool content for Analytics
+	arrivals                uint64
 	shanghaiTime            *uint64
 	isPostShanghai          atomic.Bool
 	agraBlock               *uint64
@@ -261,6 +270,11 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		tracedSenders[common.BytesToAddress([]byte(sender))] = struct{}{}
 	}
 
+	scorer, err := NewScorer(cfg.Scoring)
+	if err != nil {
+		return nil, err
+	}
+
 	lock := &sync.Mutex{}
 
 	res := &TxPool{
@@ -284,8 +298,14 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		unprocessedRemoteByHash: map[string]int{},
 		minedBlobTxsByBlock:     map[uint64][]*metaTx{},
 		minedBlobTxsByHash:      map[string]*metaTx{},
+		blobs:                   newBlobPool(),
+		private:                 map[string]uint64{},
+		conditional:             map[string]*Conditions{},
+		outcomeSubs:             map[int]chan *TxOutcome{},
+		analytics:               newPoolAnalytics(),
 		maxBlobsPerBlock:        maxBlobsPerBlock,
 		feeCalculator:           feeCalculator,
+		scorer:                  scorer,
 		logger:                  logger,
 	}
 
@@ -319,6 +339,19 @@ func (p *TxPool) Start(ctx context.Context, db kv.RwDB) error {
 		return nil
 	}
 
+	if !p.cfg.NoJournal && p.cfg.DBDir != "" && p.journal == nil {
+		journal, err := openLocalJournal(p.cfg.DBDir, p.cfg.JournalLifetime)
+		if err != nil {
+			return fmt.Errorf("opening journal of local txs: %w", err)
+		}
+		p.lock.Lock()
+		p.journal = journal
+		p.lock.Unlock()
+	}
+	if err := migratePoolFormat(ctx, db, p.logger); err != nil {
+		return fmt.Errorf("migrating pool DB: %w", err)
+	}
+
 	return db.View(ctx, func(tx kv.Tx) error {
 		coreDb, _ := p.coreDBWithCache()
 		coreTx, err := coreDb.BeginRo(ctx)
@@ -476,6 +509,8 @@ func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChang
 	if err = p.removeMined(p.all, minedTxs.Txs); err != nil {
 		return err
 	}
+	p.expirePrivateLocked(block)
+	p.dropConditionalLocked(cacheView, block)
 
 	var announcements types.Announcements
 
@@ -575,7 +610,11 @@ func (p *TxPool) getRlpLocked(tx kv.Tx, hash []byte) (rlpTxn []byte, sender comm
 	if v == nil {
 		return nil, common.Address{}, false, nil
 	}
-	return v[20:], *(*[20]byte)(v[:20]), txn != nil && txn.subPool&IsLocal > 0, nil
+	rlpTxn, sender, err = decodePoolTx(v)
+	if err != nil {
+		return nil, common.Address{}, false, err
+	}
+	return rlpTxn, sender, txn != nil && txn.subPool&IsLocal > 0, nil
 }
 func (p *TxPool) GetRlp(tx kv.Tx, hash []byte) ([]byte, error) {
 	p.lock.Lock()
@@ -590,6 +629,9 @@ func (p *TxPool) AppendLocalAnnouncements(types []byte, sizes []uint32, hashes [
 		if txn.subPool&IsLocal == 0 {
 			continue
 		}
+		if _, ok := p.private[hash]; ok {
+			continue
+		}
 		types = append(types, txn.Tx.Type)
 		sizes = append(sizes, txn.Tx.Size)
 		hashes = append(hashes, hash...)
@@ -687,7 +729,10 @@ func (p *TxPool) getCachedBlobTxnLocked(tx kv.Tx, hash []byte) (*metaTx, error)
 	if err != nil {
 		return nil, err
 	}
-	txRlp := common.Copy(v[20:])
+	txRlp, _, err := decodePoolTx(v)
+	if err != nil {
+		return nil, err
+	}
 	parseCtx := types.NewTxParseContext(p.chainID)
 	parseCtx.WithSender(false)
 	txSlot := &types.TxSlot{}
@@ -713,26 +758,29 @@ func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableG
 		p.lastSeenCond.Wait()
 	}
 
-	best := p.pending.best
+	best := p.pending.best.ms
+	if p.scorer != nil {
+		best = p.scored(best)
+	}
 
 	isShanghai := p.isShanghai() || p.isAgra()
 
-	txs.Resize(uint(cmp.Min(int(n), len(best.ms))))
+	txs.Resize(uint(cmp.Min(int(n), len(best))))
 	var toRemove []*metaTx
 	count := 0
 	i := 0
 
 	defer func() {
-		p.logger.Debug("[txpool] Processing best request", "last", onTopOf, "txRequested", n, "txAvailable", len(best.ms), "txProcessed", i, "txReturned", count)
+		p.logger.Debug("[txpool] Processing best request", "last", onTopOf, "txRequested", n, "txAvailable", len(best), "txProcessed", i, "txReturned", count)
 	}()
 
-	for ; count < int(n) && i < len(best.ms); i++ {
+	for ; count < int(n) && i < len(best); i++ {
 		// if we wouldn't have enough gas for a standard transaction then quit out early
 		if availableGas < fixedgas.TxGas {
 			break
 		}
 
-		mt := best.ms[i]
+		mt := best[i]
 
 		if yielded.Contains(mt.Tx.IDHash) {
 			continue
@@ -880,12 +928,6 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 			}
 			return txpoolcfg.Spammer
 		}
-		if p.totalBlobsInPool.Load() >= p.cfg.TotalBlobPoolLimit {
-			if txn.Traced {
-				p.logger.Info(fmt.Sprintf("TX TRACING: validateTx total blobs limit reached in pool limit=%x current blobs=%d", p.cfg.TotalBlobPoolLimit, p.totalBlobsInPool.Load()))
-			}
-			return txpoolcfg.BlobPoolOverflow
-		}
 	}
 
 	// Drop non-local transactions under our own minimal accepted gas price or tip
@@ -895,6 +937,12 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 		}
 		return txpoolcfg.UnderPriced
 	}
+	if !isLocal && txn.Tip.LtUint64(p.cfg.MinTipCap) {
+		if txn.Traced {
+			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx underpriced idHash=%x local=%t, tip=%d, cfg.MinTipCap=%d", txn.IDHash, isLocal, txn.Tip, p.cfg.MinTipCap))
+		}
+		return txpoolcfg.UnderPriced
+	}
 	gas, reason := txpoolcfg.CalcIntrinsicGas(uint64(txn.DataLen), uint64(txn.DataNonZeroLen), nil, txn.Creation, true, true, isShanghai)
 	if txn.Traced {
 		p.logger.Info(fmt.Sprintf("TX TRACING: validateTx intrinsic gas idHash=%x gas=%d", txn.IDHash, gas))
@@ -926,6 +974,12 @@ func (p *TxPool) validateTx(txn *types.TxSlot, isLocal bool, stateCache kvcache.
 		}
 		return txpoolcfg.NonceTooLow
 	}
+	if !isLocal && p.cfg.MaxNonceGap > 0 && txn.Nonce-senderNonce > p.cfg.MaxNonceGap {
+		if txn.Traced {
+			p.logger.Info(fmt.Sprintf("TX TRACING: validateTx nonce too high idHash=%x nonce in state=%d, txn.nonce=%d, cfg.MaxNonceGap=%d", txn.IDHash, senderNonce, txn.Nonce, p.cfg.MaxNonceGap))
+		}
+		return txpoolcfg.NonceTooHigh
+	}
 	// Transactor should have enough funds to cover the costs
 	total := requiredBalance(txn)
 	if senderBalance.Cmp(total) < 0 {
@@ -1109,6 +1163,7 @@ func (p *TxPool) validateTxs(txs *types.TxSlots, stateCache kvcache.CacheView) (
 			p.punishSpammer(txn.SenderID)
 		}
 		reasons[i] = reason
+		p.publishOutcomeLocked(txn, txs.Senders.AddressAt(i), TxRejected, reason, nil)
 	}
 
 	goodTxs.Resize(uint(goodCount))
@@ -1169,6 +1224,11 @@ func fillDiscardReasons(reasons []txpoolcfg.DiscardReason, newTxs types.TxSlots,
 }
 
 func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots, tx kv.Tx) ([]txpoolcfg.DiscardReason, error) {
+	return p.addLocalTxs(ctx, newTransactions, 0, nil)
+}
+
+// addLocalTxs - adds private txs if `privateMaxBlock` > 0, and conditional (also private) txs if `conditions` != nil
+func (p *TxPool) addLocalTxs(ctx context.Context, newTransactions types.TxSlots, privateMaxBlock uint64, conditions *Conditions) ([]txpoolcfg.DiscardReason, error) {
 	coreDb, cache := p.coreDBWithCache()
 	coreTx, err := coreDb.BeginRo(ctx)
 	if err != nil {
@@ -1214,7 +1274,17 @@ func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots,
 			if txn.Traced {
 				p.logger.Info(fmt.Sprintf("TX TRACING: AddLocalTxs promotes idHash=%x, senderId=%d", txn.IDHash, txn.SenderID))
 			}
+			if conditions != nil {
+				p.setConditionsLocked(txn.IDHash[:], conditions)
+			}
+			if privateMaxBlock > 0 {
+				p.setPrivateLocked(txn.IDHash[:], privateMaxBlock)
+				continue
+			}
 			p.promoted.Append(txn.Type, txn.Size, txn.IDHash[:])
+			if err := p.journal.insert(txn.IDHash, newTxs.Senders.AddressAt(i), txn.Rlp, time.Now()); err != nil {
+				p.logger.Warn("[txpool] journal local tx", "err", err)
+			}
 		}
 	}
 	if p.promoted.Len() > 0 {
@@ -1265,9 +1335,11 @@ func (p *TxPool) addTxs(blockNum uint64, cacheView kvcache.CacheView, senders *s
 		mt := newMetaTx(txn, newTxs.IsLocal[i], blockNum)
 		if reason := p.addLocked(mt, &announcements); reason != txpoolcfg.NotSet {
 			discardReasons[i] = reason
+			p.publishOutcomeLocked(txn, newTxs.Senders.AddressAt(i), TxRejected, reason, nil)
 			continue
 		}
 		discardReasons[i] = txpoolcfg.NotSet // unnecessary
+		p.publishOutcomeLocked(txn, newTxs.Senders.AddressAt(i), TxAccepted, txpoolcfg.Success, nil)
 		if txn.Traced {
 			logger.Info(fmt.Sprintf("TX TRACING: schedule sendersWithChangedState idHash=%x senderId=%d", txn.IDHash, mt.Tx.SenderID))
 		}
@@ -1368,7 +1440,8 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) txpoo
 	// Insert to pending pool, if pool doesn't have txn with same Nonce and bigger Tip
 	found := p.all.get(mt.Tx.SenderID, mt.Tx.Nonce)
 	if found != nil {
-		if found.Tx.Type == types.BlobTxType && mt.Tx.Type != types.BlobTxType {
+		// blob and non-blob txs don't replace each other: they have different limits and propagation
+		if (found.Tx.Type == types.BlobTxType) != (mt.Tx.Type == types.BlobTxType) {
 			return txpoolcfg.BlobTxReplace
 		}
 		priceBump := p.cfg.PriceBump
@@ -1408,17 +1481,9 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) txpoo
 			return txpoolcfg.NotReplaced
 		}
 
-		switch found.currentSubPool {
-		case PendingSubPool:
-			p.pending.Remove(found, "add", p.logger)
-		case BaseFeeSubPool:
-			p.baseFee.Remove(found, "add", p.logger)
-		case QueuedSubPool:
-			p.queued.Remove(found, "add", p.logger)
-		default:
-			//already removed
-		}
-
+		p.removeFromSubPool(found, "add")
+		replacedBy := common.Hash(mt.Tx.IDHash)
+		p.publishOutcomeLocked(found.Tx, p.senders.senderID2Addr[found.Tx.SenderID], TxReplaced, txpoolcfg.ReplacedByHigherTip, &replacedBy)
 		p.discardLocked(found, txpoolcfg.ReplacedByHigherTip)
 	}
 
@@ -1426,15 +1491,32 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) txpoo
 	if mt.Tx.Type == types.BlobTxType && mt.Tx.BlobFeeCap.LtUint64(p.pendingBlobFee.Load()) {
 		return txpoolcfg.FeeTooLow
 	}
+	if mt.Tx.Type == types.BlobTxType {
+		evict, ok := p.blobs.evictionsFor(mt, p.all, p.cfg)
+		if !ok {
+			if mt.Tx.Traced {
+				p.logger.Info(fmt.Sprintf("TX TRACING: addLocked blob pool is full idHash=%x blobs=%d, bytes=%d", mt.Tx.IDHash, p.blobs.blobs, p.blobs.bytes))
+			}
+			return txpoolcfg.BlobPoolOverflow
+		}
+		for _, worst := range evict {
+			p.removeFromSubPool(worst, "evict-blob")
+			p.discardLocked(worst, txpoolcfg.BlobPoolOverflow)
+			blobPoolEvictedCount.Inc()
+		}
+	}
 
 	hashStr := string(mt.Tx.IDHash[:])
 	p.byHash[hashStr] = mt
+	p.arrivals++
+	mt.arrival = p.arrivals
 
 	if replaced := p.all.replaceOrInsert(mt, p.logger); replaced != nil {
 		if assert.Enable {
 			panic("must never happen")
 		}
 	}
+	p.analytics.added(mt)
 
 	if mt.subPool&IsLocal != 0 {
 		p.isLocalLRU.Add(hashStr, struct{}{})
@@ -1442,8 +1524,7 @@ func (p *TxPool) addLocked(mt *metaTx, announcements *types.Announcements) txpoo
 	// All transactions are first added to the queued pool and then immediately promoted from there if required
 	p.queued.Add(mt, "addLocked", p.logger)
 	if mt.Tx.Type == types.BlobTxType {
-		t := p.totalBlobsInPool.Load()
-		p.totalBlobsInPool.Store(t + (uint64(len(mt.Tx.BlobHashes))))
+		p.blobs.add(mt)
 	}
 
 	// Remove from mined cache as we are now "resurrecting" it to a sub-pool
@@ -1457,11 +1538,33 @@ func (p *TxPool) discardLocked(mt *metaTx, reason txpoolcfg.DiscardReason) {
 	hashStr := string(mt.Tx.IDHash[:])
 	delete(p.byHash, hashStr)
 	p.deletedTxs = append(p.deletedTxs, mt)
-	p.all.delete(mt, reason, p.logger)
+	if p.all.delete(mt, reason, p.logger) {
+		p.analytics.removed(mt)
+	}
+	p.updateNonceGapLocked(mt.Tx.SenderID)
 	p.discardReasonsLRU.Add(hashStr, reason)
+	p.unsetPrivateLocked(hashStr)
+	p.unsetConditionsLocked(hashStr)
+	p.publishDiscardLocked(mt, reason)
 	if mt.Tx.Type == types.BlobTxType {
-		t := p.totalBlobsInPool.Load()
-		p.totalBlobsInPool.Store(t - uint64(len(mt.Tx.BlobHashes)))
+		p.blobs.remove(mt)
+	}
+	switch reason {
+	case txpoolcfg.Mined, txpoolcfg.NonceTooLow, txpoolcfg.ReplacedByHigherTip:
+		p.journal.remove(mt.Tx.IDHash)
+	}
+}
+
+func (p *TxPool) removeFromSubPool(mt *metaTx, reason string) {
+	switch mt.currentSubPool {
+	case PendingSubPool:
+		p.pending.Remove(mt, reason, p.logger)
+	case BaseFeeSubPool:
+		p.baseFee.Remove(mt, reason, p.logger)
+	case QueuedSubPool:
+		p.queued.Remove(mt, reason, p.logger)
+	default:
+		//already removed
 	}
 }
 
@@ -1508,6 +1611,27 @@ func (p *TxPool) deleteMinedBlobTxn(hash string) {
 	delete(p.minedBlobTxsByHash, hash)
 }
 
+// Policy - current replacement and pricing policy of pool
+func (p *TxPool) Policy() txpoolcfg.Policy {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.cfg.Policy()
+}
+
+// SetPolicy - changes replacement and pricing policy of pool at runtime. New policy is applied to new txs only.
+func (p *TxPool) SetPolicy(policy txpoolcfg.Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.cfg.SetPolicy(policy)
+	p.logger.Info("[txpool] Policy updated", "priceBump", policy.PriceBump, "blobPriceBump", policy.BlobPriceBump,
+		"minFeeCap", policy.MinFeeCap, "minTipCap", policy.MinTipCap, "accountSlots", policy.AccountSlots,
+		"blobSlots", policy.BlobSlots, "maxNonceGap", policy.MaxNonceGap)
+	return nil
+}
+
 func (p *TxPool) NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -1598,6 +1722,7 @@ func (p *TxPool) onSenderStateChange(senderID uint64, senderNonce uint64, sender
 	minFeeCap := uint256.NewInt(0).SetAllOne()
 	minTip := uint64(math.MaxUint64)
 	var toDel []*metaTx // can't delete items while iterate them
+	p.analytics.nonces[senderID] = senderNonce
 
 	p.all.ascend(senderID, func(mt *metaTx) bool {
 		deleteAndContinueReasonLog := ""
@@ -1693,6 +1818,7 @@ func (p *TxPool) onSenderStateChange(senderID uint64, senderNonce uint64, sender
 	for _, mt := range toDel {
 		p.discardLocked(mt, txpoolcfg.NonceTooLow)
 	}
+	p.updateNonceGapLocked(senderID)
 
 	logger.Trace("[txpool] onSenderStateChange", "sender", senderID, "count", p.all.count(senderID), "pending", p.pending.Len(), "baseFee", p.baseFee.Len(), "queued", p.queued.Len())
 }
@@ -1783,11 +1909,31 @@ func MainLoop(ctx context.Context, db kv.RwDB, p *TxPool, newTxs chan types.Anno
 		return
 	}
 
+	var rejournal <-chan time.Time
+	if p.journal != nil && p.cfg.RejournalEvery > 0 {
+		rejournalEvery := time.NewTicker(p.cfg.RejournalEvery)
+		defer rejournalEvery.Stop()
+		rejournal = rejournalEvery.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			_, _ = p.flush(ctx, db)
+			// ctx is already canceled, but whole pool must be persisted to be reloaded after restart
+			t := time.Now()
+			if written, err := p.flush(context.Background(), db); err != nil {
+				p.logger.Warn("[txpool] flush on shutdown", "err", err)
+			} else {
+				p.logger.Info("[txpool] Persisted", "written_kb", written/1024, "in", time.Since(t))
+			}
+			p.lock.Lock()
+			_ = p.journal.close()
+			p.lock.Unlock()
 			return
+		case <-rejournal:
+			if err := p.rejournal(ctx); err != nil {
+				p.logger.Warn("[txpool] rejournal local txs", "err", err)
+			}
 		case <-logEvery.C:
 			p.logStats()
 		case <-processRemoteTxsEvery.C:
@@ -1851,11 +1997,15 @@ func MainLoop(ctx context.Context, db kv.RwDB, p *TxPool, newTxs chan types.Anno
 				var remoteTxHashes types.Hashes
 				var remoteTxRlps [][]byte
 				var broadcastHashes types.Hashes
+				var localPropagateRlps, remotePropagateRlps [][]byte // aligned with hashes, nil - only announce
 				slotsRlp := make([][]byte, 0, announcements.Len())
 
 				if err := db.View(ctx, func(tx kv.Tx) error {
 					for i := 0; i < announcements.Len(); i++ {
 						t, size, hash := announcements.At(i)
+						if p.IsPrivate(hash) {
+							continue
+						}
 						slotRlp, err := p.GetRlp(tx, hash)
 						if err != nil {
 							return err
@@ -1880,6 +2030,9 @@ func MainLoop(ctx context.Context, db kv.RwDB, p *TxPool, newTxs chan types.Anno
 							if t != types.BlobTxType {
 								localTxRlps = append(localTxRlps, slotRlp)
 								broadcastHashes = append(broadcastHashes, hash...)
+								localPropagateRlps = append(localPropagateRlps, slotRlp)
+							} else {
+								localPropagateRlps = append(localPropagateRlps, nil)
 							}
 						} else {
 							remoteTxTypes = append(remoteTxTypes, t)
@@ -1889,6 +2042,9 @@ func MainLoop(ctx context.Context, db kv.RwDB, p *TxPool, newTxs chan types.Anno
 							// "Nodes MUST NOT automatically broadcast blob transactions to their peers" - EIP-4844
 							if t != types.BlobTxType && len(slotRlp) < txMaxBroadcastSize {
 								remoteTxRlps = append(remoteTxRlps, slotRlp)
+								remotePropagateRlps = append(remotePropagateRlps, slotRlp)
+							} else {
+								remotePropagateRlps = append(remotePropagateRlps, nil)
 							}
 						}
 					}
@@ -1901,6 +2057,12 @@ func MainLoop(ctx context.Context, db kv.RwDB, p *TxPool, newTxs chan types.Anno
 					newSlotsStreams.Broadcast(&txpoolproto.OnAddReply{RplTxs: slotsRlp}, p.logger)
 				}
 
+				if send.PeerAware() { // local txs go first: they have priority under per-peer rate limits
+					send.Propagate(append(localTxTypes, remoteTxTypes...), append(localTxSizes, remoteTxSizes...),
+						append(localTxHashes, remoteTxHashes...), append(localPropagateRlps, remotePropagateRlps...))
+					return
+				}
+
 				// broadcast local transactions
 				const localTxsBroadcastMaxPeers uint64 = 10
 				txSentTo := send.BroadcastPooledTxs(localTxRlps, localTxsBroadcastMaxPeers)
@@ -2016,22 +2178,21 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 		if metaTx.Tx.Rlp == nil {
 			continue
 		}
-		v = common.EnsureEnoughSize(v, 20+len(metaTx.Tx.Rlp))
-
+		if _, ok := p.private[txHash]; ok { // private txs are kept in memory only
+			continue
+		}
 		addr, ok := p.senders.senderID2Addr[metaTx.Tx.SenderID]
 		if !ok {
 			p.logger.Warn("[txpool] flush: sender address not found by ID", "senderID", metaTx.Tx.SenderID)
 			continue
 		}
 
-		copy(v[:20], addr.Bytes())
-		copy(v[20:], metaTx.Tx.Rlp)
-
 		has, err := tx.Has(kv.PoolTransaction, []byte(txHash))
 		if err != nil {
 			return err
 		}
 		if !has {
+			v = encodePoolTx(v, addr, metaTx.Tx.Rlp)
 			if err := tx.Put(kv.PoolTransaction, []byte(txHash), v); err != nil {
 				return err
 			}
@@ -2050,6 +2211,11 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 	if err := PutLastSeenBlock(tx, p.lastSeenBlock.Load(), encID); err != nil {
 		return err
 	}
+	// Start migrates older pools before anything is flushed
+	binary.BigEndian.PutUint64(encID, poolFormatVersion)
+	if err := tx.Put(kv.PoolInfo, PoolFormatVersionKey, encID); err != nil {
+		return err
+	}
 
 	// clean - in-memory data structure as later as possible - because if during this Tx will happen error,
 	// DB will stay consistent but some in-memory structures may be already cleaned, and retry will not work
@@ -2059,6 +2225,7 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 }
 
 func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
+	t := time.Now()
 	if p.lastSeenBlock.Load() == 0 {
 		lastSeenBlock, err := LastSeenBlock(tx)
 		if err != nil {
@@ -2105,7 +2272,7 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 	parseCtx := types.NewTxParseContext(p.chainID)
 	parseCtx.WithSender(false)
 
-	i := 0
+	i, invalid := 0, 0 // txs which were valid before restart, but not on top of current head
 	it, err = tx.Range(kv.PoolTransaction, nil, nil)
 	if err != nil {
 		return err
@@ -2115,7 +2282,11 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		if err != nil {
 			return err
 		}
-		addr, txRlp := *(*[20]byte)(v[:20]), v[20:]
+		txRlp, addr, err := decodePoolTx(v)
+		if err != nil {
+			p.logger.Warn("[txpool] fromDB: decode tx", "hash", k, "err", err)
+			continue
+		}
 		txn := &types.TxSlot{}
 
 		// TODO(eip-4844) ensure wrappedWithBlobs when transactions are saved to the DB
@@ -2128,12 +2299,12 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		txn.Rlp = nil // means that we don't need store it in db anymore
 
 		txn.SenderID, txn.Traced = p.senders.getOrCreateID(addr, p.logger)
-		binary.BigEndian.Uint64(v) // TODO - unnecessary line, remove
 
 		isLocalTx := p.isLocalLRU.Contains(string(k))
 
 		if reason := p.validateTx(txn, isLocalTx, cacheView); reason != txpoolcfg.NotSet && reason != txpoolcfg.Success {
-			return nil // TODO: Clarify - if one of the txs has the wrong reason, no pooled txs!
+			invalid++
+			continue
 		}
 		txs.Resize(uint(i + 1))
 		txs.Txs[i] = txn
@@ -2142,6 +2313,37 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		i++
 	}
 
+	// local txs which were not flushed to db before shutdown, or were evicted from pool
+	for _, journaled := range p.journal.list() {
+		if has, err := tx.Has(kv.PoolTransaction, journaled.hash[:]); err != nil {
+			return err
+		} else if has {
+			continue
+		}
+		txn := &types.TxSlot{}
+		if _, err = parseCtx.ParseTransaction(journaled.rlp, 0, txn, nil, false /* hasEnvelope */, true /*wrappedWithBlobs*/, nil); err != nil {
+			p.logger.Warn("[txpool] fromDB: parse journaled tx", "hash", journaled.hash, "err", err)
+			p.journal.remove(journaled.hash)
+			continue
+		}
+		txn.SenderID, txn.Traced = p.senders.getOrCreateID(journaled.sender, p.logger)
+		if reason := p.validateTx(txn, true, cacheView); reason != txpoolcfg.NotSet && reason != txpoolcfg.Success {
+			if reason == txpoolcfg.NonceTooLow {
+				p.journal.remove(journaled.hash)
+			}
+			continue
+		}
+		p.isLocalLRU.Add(string(journaled.hash[:]), struct{}{})
+		txs.Resize(uint(i + 1))
+		txs.Txs[i] = txn
+		txs.IsLocal[i] = true
+		copy(txs.Senders.At(i), journaled.sender[:])
+		i++
+	}
+	if err := p.journal.rotate(); err != nil {
+		return err
+	}
+
 	var pendingBaseFee, pendingBlobFee, minBlobGasPrice, blockGasLimit uint64
 
 	if p.feeCalculator != nil {
@@ -2192,6 +2394,9 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 	p.pendingBaseFee.Store(pendingBaseFee)
 	p.pendingBlobFee.Store(pendingBlobFee)
 	p.blockGasLimit.Store(blockGasLimit)
+	if i > 0 || invalid > 0 {
+		p.logger.Info("[txpool] Loaded from db", "txs", i, "invalid", invalid, "block", p.lastSeenBlock.Load(), "in", time.Since(t))
+	}
 	return nil
 }
 
@@ -2294,11 +2499,15 @@ func (p *TxPool) logStats() {
 	if cacheKeys > 0 {
 		ctx = append(ctx, "cache_keys", cacheKeys)
 	}
+	if len(p.blobs.txs) > 0 {
+		ctx = append(ctx, "blob_txs", len(p.blobs.txs), "blobs", p.blobs.blobs, "blob_bytes", common.ByteCount(p.blobs.bytes))
+	}
 	ctx = append(ctx, "alloc", common.ByteCount(m.Alloc), "sys", common.ByteCount(m.Sys))
 	p.logger.Info("[txpool] stat", ctx...)
 	pendingSubCounter.SetInt(p.pending.Len())
 	basefeeSubCounter.SetInt(p.baseFee.Len())
 	queuedSubCounter.SetInt(p.queued.Len())
+	p.blobs.updateMetrics()
 }
 
 // Deprecated need switch to streaming-like
@@ -2318,7 +2527,10 @@ func (p *TxPool) deprecatedForEach(_ context.Context, f func(rlp []byte, sender
 				p.logger.Warn("[txpool] foreach: tx not found in db")
 				return true
 			}
-			slotRlp = v[20:]
+			if slotRlp, _, err = decodePoolTx(v); err != nil {
+				p.logger.Warn("[txpool] foreach: decode tx from db", "err", err)
+				return true
+			}
 		}
 		if sender, found := p.senders.senderID2Addr[slot.SenderID]; found {
 			f(slotRlp, sender, mt.currentSubPool)
@@ -2540,8 +2752,9 @@ func (b *BySenderAndNonce) has(mt *metaTx) bool {
 	return b.tree.Has(mt)
 }
 
-func (b *BySenderAndNonce) delete(mt *metaTx, reason txpoolcfg.DiscardReason, logger log.Logger) {
-	if _, ok := b.tree.Delete(mt); ok {
+func (b *BySenderAndNonce) delete(mt *metaTx, reason txpoolcfg.DiscardReason, logger log.Logger) bool {
+	_, ok := b.tree.Delete(mt)
+	if ok {
 		if mt.Tx.Traced {
 			logger.Info("TX TRACING: Deleted tx by nonce", "idHash", fmt.Sprintf("%x", mt.Tx.IDHash), "sender", mt.Tx.SenderID, "nonce", mt.Tx.Nonce, "reason", reason)
 		}
@@ -2564,6 +2777,7 @@ func (b *BySenderAndNonce) delete(mt *metaTx, reason txpoolcfg.DiscardReason, lo
 			}
 		}
 	}
+	return ok
 }
 
 func (b *BySenderAndNonce) replaceOrInsert(mt *metaTx, logger log.Logger) *metaTx {
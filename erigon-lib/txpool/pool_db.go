@@ -0,0 +1,115 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// PoolFormatVersionKey - encoding of the kv.PoolTransaction values. Pools written before it was versioned don't have it.
+var PoolFormatVersionKey = []byte("format_version")
+
+const (
+	// poolFormatPlain - sender address followed by the rlp of the tx
+	poolFormatPlain uint64 = 0
+	// poolFormatCompact - sender address followed by the s2 block of the rlp: calldata is mostly zeros and repeated words
+	poolFormatCompact uint64 = 1
+
+	poolFormatVersion = poolFormatCompact
+)
+
+func encodePoolTx(dst []byte, sender common.Address, rlp []byte) []byte {
+	dst = common.EnsureEnoughSize(dst, 20+s2.MaxEncodedLen(len(rlp)))
+	copy(dst, sender[:])
+	return dst[:20+len(s2.Encode(dst[20:], rlp))]
+}
+
+func decodePoolTx(v []byte) (rlp []byte, sender common.Address, err error) {
+	if len(v) < 20 {
+		return nil, sender, fmt.Errorf("pool tx of %d bytes is shorter than sender address", len(v))
+	}
+	copy(sender[:], v[:20])
+	if rlp, err = s2.Decode(nil, v[20:]); err != nil {
+		return nil, sender, fmt.Errorf("decoding pool tx: %w", err)
+	}
+	return rlp, sender, nil
+}
+
+// PoolFormat - of the txs persisted in the pool db
+func PoolFormat(tx kv.Getter) (uint64, error) {
+	v, err := tx.GetOne(kv.PoolInfo, PoolFormatVersionKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return poolFormatPlain, nil
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// migratePoolFormat - re-encodes the txs persisted by an older version, in one RwTx: the pool db is small.
+func migratePoolFormat(ctx context.Context, db kv.RwDB, logger log.Logger) error {
+	return db.Update(ctx, func(tx kv.RwTx) error {
+		format, err := PoolFormat(tx)
+		if err != nil {
+			return err
+		}
+		if format == poolFormatVersion {
+			return nil
+		}
+		if format > poolFormatVersion {
+			return fmt.Errorf("pool db format %d is newer than supported %d", format, poolFormatVersion)
+		}
+
+		var keys, values [][]byte
+		var before, after int
+		if err := tx.ForEach(kv.PoolTransaction, nil, func(k, v []byte) error {
+			if len(v) < 20 {
+				return fmt.Errorf("pool tx %x of %d bytes is shorter than sender address", k, len(v))
+			}
+			keys = append(keys, common.Copy(k))
+			values = append(values, encodePoolTx(nil, *(*[20]byte)(v[:20]), v[20:]))
+			before, after = before+len(v), after+len(values[len(values)-1])
+			return nil
+		}); err != nil {
+			return err
+		}
+		for i := range keys {
+			if err := tx.Put(kv.PoolTransaction, keys[i], values[i]); err != nil {
+				return err
+			}
+		}
+
+		encVersion := make([]byte, 8)
+		binary.BigEndian.PutUint64(encVersion, poolFormatVersion)
+		if err := tx.Put(kv.PoolInfo, PoolFormatVersionKey, encVersion); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			logger.Info("[txpool] migrated persisted txs", "from", format, "to", poolFormatVersion, "txs", len(keys), "bytes", before, "compacted", after)
+		}
+		return nil
+	})
+}
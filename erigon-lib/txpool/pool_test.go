@@ -19,10 +19,12 @@ package txpool
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/big"
 	"testing"
+	"time"
 
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	"github.com/holiman/uint256"
@@ -37,8 +39,11 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
 	"github.com/ledgerwatch/erigon-lib/common/u256"
 	"github.com/ledgerwatch/erigon-lib/crypto/kzg"
+	"github.com/ledgerwatch/erigon-lib/direct"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
@@ -1209,3 +1214,330 @@ func TestGasLimitChanged(t *testing.T) {
 
 	assert.Zero(mtx.subPool&NotTooMuchGas, "Should now have block space (again) for the tx")
 }
+
+func TestPolicy(t *testing.T) {
+	logger := log.New()
+	ch := make(chan types.Announcements, 100)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	cache := &kvcache.DummyCache{}
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, cache, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	ctx := context.Background()
+	tx, err := coreDB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	sndr := sender{nonce: 5, balance: *uint256.NewInt(math.MaxUint64)}
+	sndrBytes := make([]byte, types.EncodeSenderLengthForStorage(sndr.nonce, sndr.balance))
+	types.EncodeSender(sndr.nonce, sndr.balance, sndrBytes)
+	require.NoError(t, tx.Put(kv.PlainState, make([]byte, 20), sndrBytes))
+
+	txns := types.TxSlots{Txs: []*types.TxSlot{{}}, Senders: make(types.Addresses, 20)}
+	require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+	newTxn := func(nonce, tip uint64) *types.TxSlot {
+		return &types.TxSlot{SenderID: txns.Txs[0].SenderID, Nonce: nonce, Tip: *uint256.NewInt(tip), FeeCap: *uint256.NewInt(100), Gas: 100000}
+	}
+	view, err := cache.View(ctx, tx)
+	require.NoError(t, err)
+
+	require.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(100, 1), false, view))
+
+	policy := pool.Policy()
+	require.Equal(t, txpoolcfg.DefaultConfig.PriceBump, policy.PriceBump)
+	policy.MinTipCap, policy.MaxNonceGap, policy.PriceBump = 2, 10, 25
+	require.NoError(t, pool.SetPolicy(policy))
+	require.Equal(t, policy, pool.Policy())
+	require.Equal(t, uint64(25), pool.cfg.PriceBump)
+
+	require.Equal(t, txpoolcfg.UnderPriced, pool.validateTx(newTxn(5, 1), false, view))
+	require.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(5, 1), true, view))
+	require.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(15, 2), false, view))
+	require.Equal(t, txpoolcfg.NonceTooHigh, pool.validateTx(newTxn(16, 2), false, view))
+	require.Equal(t, txpoolcfg.Success, pool.validateTx(newTxn(16, 2), true, view))
+
+	policy.AccountSlots = 0
+	require.Error(t, pool.SetPolicy(policy))
+	require.Equal(t, uint64(10), pool.Policy().MaxNonceGap)
+}
+
+func TestPrivateTxs(t *testing.T) {
+	logger := log.New()
+	ch := make(chan types.Announcements, 100)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	pool.lastSeenBlock.Store(10)
+	_, err = pool.AddPrivateTxs(context.Background(), types.TxSlots{}, nil, 10)
+	require.Error(t, err)
+
+	txns := types.TxSlots{Txs: []*types.TxSlot{{}}, Senders: make(types.Addresses, 20)}
+	require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+	newTxn := func(nonce uint64) *metaTx {
+		txn := &types.TxSlot{SenderID: txns.Txs[0].SenderID, Nonce: nonce, Tip: *uint256.NewInt(1), FeeCap: *uint256.NewInt(1), Gas: 100000}
+		txn.IDHash[0] = byte(nonce)
+		return newMetaTx(txn, true, 10)
+	}
+	public, private := newTxn(1), newTxn(2)
+	var announcements types.Announcements
+	require.Equal(t, txpoolcfg.NotSet, pool.addLocked(public, &announcements))
+	require.Equal(t, txpoolcfg.NotSet, pool.addLocked(private, &announcements))
+	pool.setPrivateLocked(private.Tx.IDHash[:], 12)
+	pool.setPrivateLocked([]byte{0xff}, 11) // already discarded
+
+	require.False(t, pool.IsPrivate(public.Tx.IDHash[:]))
+	require.True(t, pool.IsPrivate(private.Tx.IDHash[:]))
+	_, _, hashes := pool.AppendAllAnnouncements(nil, nil, nil)
+	require.Equal(t, types.Hashes(public.Tx.IDHash[:]), types.Hashes(hashes))
+
+	pool.expirePrivateLocked(12)
+	require.Len(t, pool.private, 1)
+	require.Contains(t, pool.byHash, string(private.Tx.IDHash[:]))
+
+	pool.expirePrivateLocked(13)
+	require.Empty(t, pool.private)
+	require.NotContains(t, pool.byHash, string(private.Tx.IDHash[:]))
+	require.Contains(t, pool.byHash, string(public.Tx.IDHash[:]))
+	reason, ok := pool.discardReasonsLRU.Get(string(private.Tx.IDHash[:]))
+	require.True(t, ok)
+	require.Equal(t, txpoolcfg.PrivateTxExpired, reason)
+}
+
+type testCacheView struct {
+	stateV3 bool
+	state   map[string][]byte
+}
+
+func (v *testCacheView) StateV3() bool                    { return v.stateV3 }
+func (v *testCacheView) Get(k []byte) ([]byte, error)     { return v.state[string(k)], nil }
+func (v *testCacheView) GetCode(k []byte) ([]byte, error) { return nil, nil }
+
+func TestConditionalTxs(t *testing.T) {
+	logger := log.New()
+	ch := make(chan types.Announcements, 100)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	pool.lastSeenBlock.Store(10)
+
+	contract, slot := common.Address{1}, common.Hash{2}
+	maxBlock, maxTimestamp := uint64(12), uint64(time.Now().Add(time.Hour).Unix())
+	conditions := &Conditions{
+		KnownAccounts:  map[common.Address]map[common.Hash]common.Hash{contract: {slot: {31: 5}}},
+		BlockNumberMax: &maxBlock,
+		TimestampMax:   &maxTimestamp,
+	}
+	require.NoError(t, conditions.Validate())
+	minBlock := maxBlock + 1
+	require.Error(t, (&Conditions{BlockNumberMin: &minBlock, BlockNumberMax: &maxBlock}).Validate())
+
+	// E2: storage key includes incarnation of contract
+	v2 := &testCacheView{state: map[string][]byte{
+		string(contract[:]): {4, 1, 1}, // incarnation 1
+		string(append(append(common.Copy(contract[:]), 0, 0, 0, 0, 0, 0, 0, 1), slot[:]...)): {5},
+	}}
+	require.NoError(t, conditions.check(v2, 11, time.Now()))
+	require.Error(t, conditions.check(v2, 13, time.Now()))
+	require.Error(t, conditions.check(v2, 11, time.Now().Add(2*time.Hour)))
+	v3 := &testCacheView{stateV3: true, state: map[string][]byte{string(append(common.Copy(contract[:]), slot[:]...)): {5}}}
+	require.NoError(t, conditions.check(v3, 11, time.Now()))
+
+	txns := types.TxSlots{Txs: []*types.TxSlot{{}}, Senders: make(types.Addresses, 20)}
+	require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+	txn := &types.TxSlot{SenderID: txns.Txs[0].SenderID, Nonce: 1, Tip: *uint256.NewInt(1), FeeCap: *uint256.NewInt(1), Gas: 100000}
+	txn.IDHash[0] = 1
+	conditional := newMetaTx(txn, true, 10)
+	var announcements types.Announcements
+	require.Equal(t, txpoolcfg.NotSet, pool.addLocked(conditional, &announcements))
+	pool.setConditionsLocked(conditional.Tx.IDHash[:], conditions)
+	pool.setPrivateLocked(conditional.Tx.IDHash[:], maxBlock)
+
+	pool.dropConditionalLocked(v3, 10)
+	require.Contains(t, pool.byHash, string(conditional.Tx.IDHash[:]))
+
+	// new block changes the slot
+	v3.state[string(append(common.Copy(contract[:]), slot[:]...))] = []byte{6}
+	pool.dropConditionalLocked(v3, 11)
+	require.Empty(t, pool.conditional)
+	require.Empty(t, pool.private)
+	require.NotContains(t, pool.byHash, string(conditional.Tx.IDHash[:]))
+	reason, ok := pool.discardReasonsLRU.Get(string(conditional.Tx.IDHash[:]))
+	require.True(t, ok)
+	require.Equal(t, txpoolcfg.ConditionFailed, reason)
+}
+
+func TestTxOutcomes(t *testing.T) {
+	logger := log.New()
+	ch := make(chan types.Announcements, 100)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	outcomes, unsubscribe := pool.SubscribeOutcomes()
+	defer unsubscribe()
+
+	sender := common.Address{7}
+	newTx := func(id byte, tip uint64) types.TxSlots {
+		txn := &types.TxSlot{Nonce: 0, Tip: *uint256.NewInt(tip), FeeCap: *uint256.NewInt(tip), Gas: 100000}
+		txn.IDHash[0] = id
+		txns := types.TxSlots{Txs: []*types.TxSlot{txn}, IsLocal: []bool{true}, Senders: common.Copy(sender[:])}
+		require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+		return txns
+	}
+	txns1, txns2, txns3 := newTx(1, 10), newTx(2, 20), newTx(3, 5)
+	hash1, hash2 := common.Hash(txns1.Txs[0].IDHash), common.Hash(txns2.Txs[0].IDHash)
+
+	// in-process client of grpc server, subscribed to outcomes of first txn only
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	filter := &txpool_proto.OutcomesRequest{Hashes: []*types2.H256{gointerfaces.ConvertHashToH256(hash1)}}
+	stream, err := direct.NewTxPoolClient(NewGrpcServer(ctx, pool, coreDB, *u256.N1, logger)).SubscribeOutcomes(ctx, filter)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+		return len(pool.outcomeSubs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cacheView := &testCacheView{state: map[string][]byte{}}
+	for _, txns := range []types.TxSlots{txns1, txns2, txns3} {
+		_, _, err = pool.addTxs(0, cacheView, pool.senders, txns, 1, 1, math.MaxUint64, true, logger)
+		require.NoError(t, err)
+	}
+	pool.removeFromSubPool(pool.byHash[string(hash2[:])], "mined")
+	pool.discardLocked(pool.byHash[string(hash2[:])], txpoolcfg.Mined)
+
+	expected := []TxOutcome{
+		{Hash: hash1, Sender: sender, Status: TxAccepted},
+		{Hash: hash1, Sender: sender, Status: TxReplaced, ReasonCode: txpoolcfg.ReplacedByHigherTip, Reason: txpoolcfg.ReplacedByHigherTip.String(), ReplacedBy: &hash2},
+		{Hash: hash2, Sender: sender, Status: TxAccepted},
+		{Hash: common.Hash(txns3.Txs[0].IDHash), Sender: sender, Status: TxRejected, ReasonCode: txpoolcfg.NotReplaced, Reason: txpoolcfg.NotReplaced.String()},
+		{Hash: hash2, Sender: sender, Status: TxMined},
+	}
+	for _, want := range expected {
+		require.Equal(t, want, *<-outcomes)
+	}
+
+	for _, want := range expected[:2] {
+		reply, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, want, *TxOutcomeFromProto(reply))
+	}
+}
+
+func TestAnalytics(t *testing.T) {
+	logger := log.New()
+	ch := make(chan types.Announcements, 100)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	pool.lastSeenBlock.Store(100)
+	pool.pendingBaseFee.Store(10)
+
+	gapped, cheap := common.Address{1}, common.Address{2}
+	cacheView := &testCacheView{state: map[string][]byte{}}
+	add := func(sender common.Address, nonce, feeCap, block uint64) {
+		txn := &types.TxSlot{Nonce: nonce, Tip: *uint256.NewInt(1), FeeCap: *uint256.NewInt(feeCap), Gas: 100000}
+		txn.IDHash[0], txn.IDHash[1], txn.IDHash[2] = sender[0], byte(nonce), byte(feeCap)
+		txns := types.TxSlots{Txs: []*types.TxSlot{txn}, IsLocal: []bool{false}, Senders: common.Copy(sender[:])}
+		require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+		_, reasons, err := pool.addTxs(block, cacheView, pool.senders, txns, 10, 1, math.MaxUint64, true, logger)
+		require.NoError(t, err)
+		require.Equal(t, txpoolcfg.NotSet, reasons[0])
+	}
+	for _, nonce := range []uint64{0, 1, 3, 4} {
+		add(gapped, nonce, 20, 100)
+	}
+	add(cheap, 0, 5, 50)
+	add(cheap, 1, 5, 5)
+
+	res := pool.Analytics(nil)
+	require.Equal(t, 6, res.Txs)
+	require.Equal(t, 2, res.BelowBaseFee)
+	require.Equal(t, []AgeBucket{{"0-1", 4}, {"2-10", 0}, {"11-100", 2}, {"101-1000", 0}, {">1000", 0}}, res.Age)
+	require.Equal(t, []SenderNonceGap{{Sender: gapped, StateNonce: 0, FirstGap: 2, GappedTxs: 2}}, res.NonceGaps)
+
+	// discard of gapped txn and arrival of missing nonce update gaps
+	mt := pool.all.get(pool.senders.senderIDs[gapped], 4)
+	pool.removeFromSubPool(mt, "test")
+	pool.discardLocked(mt, txpoolcfg.Spammer)
+	require.Equal(t, []SenderNonceGap{{Sender: gapped, StateNonce: 0, FirstGap: 2, GappedTxs: 1}}, pool.Analytics(nil).NonceGaps)
+	add(gapped, 2, 20, 100)
+	res = pool.Analytics(nil)
+	require.Equal(t, 6, res.Txs)
+	require.Empty(t, res.NonceGaps)
+
+	res = pool.Analytics(&cheap)
+	require.Equal(t, 2, res.Txs)
+	require.Equal(t, 2, res.BelowBaseFee)
+	require.Equal(t, []AgeBucket{{"0-1", 0}, {"2-10", 0}, {"11-100", 2}, {"101-1000", 0}, {">1000", 0}}, res.Age)
+	require.Empty(t, res.NonceGaps)
+	require.Zero(t, pool.Analytics(&common.Address{3}).Txs)
+}
+
+func TestPersistOnShutdown(t *testing.T) {
+	logger := log.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan types.Announcements, 100)
+	db := memdb.NewTestPoolDB(t)
+	_, coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+
+	pool, err := New(ch, coreDB, txpoolcfg.DefaultConfig, &kvcache.DummyCache{}, *u256.N1, nil, nil, nil, fixedgas.DefaultMaxBlobsPerBlock, nil, logger)
+	require.NoError(t, err)
+	require.NoError(t, pool.Start(ctx, db))
+
+	txns := types.TxSlots{Txs: []*types.TxSlot{{}}, Senders: make(types.Addresses, 20)}
+	require.NoError(t, pool.senders.registerNewSenders(&txns, logger))
+	txn := &types.TxSlot{SenderID: txns.Txs[0].SenderID, Tip: *uint256.NewInt(1), FeeCap: *uint256.NewInt(1), Gas: 100000, Rlp: []byte{1}}
+	txn.IDHash[0] = 1
+	var announcements types.Announcements
+	require.Equal(t, txpoolcfg.NotSet, pool.addLocked(newMetaTx(txn, false, 0), &announcements))
+
+	// txn arrived after last periodic flush: it's persisted by MainLoop on shutdown, when ctx is already canceled
+	cancel()
+	MainLoop(ctx, db, pool, nil, nil, nil, func() {})
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		has, err := tx.Has(kv.PoolTransaction, txn.IDHash[:])
+		require.True(t, has)
+		return err
+	}))
+}
+
+func TestMigratePoolFormat(t *testing.T) {
+	logger := log.New()
+	ctx := context.Background()
+	db := memdb.NewTestPoolDB(t)
+
+	// plain format: sender address followed by the rlp
+	sender, txRlp := common.Address{1}, bytes.Repeat([]byte{0}, 1000)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(kv.PoolTransaction, []byte{1}, append(sender.Bytes(), txRlp...))
+	}))
+	require.NoError(t, migratePoolFormat(ctx, db, logger))
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+		format, err := PoolFormat(tx)
+		require.NoError(t, err)
+		require.Equal(t, poolFormatVersion, format)
+		v, err := tx.GetOne(kv.PoolTransaction, []byte{1})
+		require.NoError(t, err)
+		require.Less(t, len(v), 20+len(txRlp))
+		decodedRlp, decodedSender, err := decodePoolTx(v)
+		require.NoError(t, err)
+		require.Equal(t, sender, decodedSender)
+		require.Equal(t, txRlp, decodedRlp)
+		return nil
+	}))
+	// already migrated
+	require.NoError(t, migratePoolFormat(ctx, db, logger))
+
+	encVersion := make([]byte, 8)
+	binary.BigEndian.PutUint64(encVersion, poolFormatVersion+1)
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(kv.PoolInfo, PoolFormatVersionKey, encVersion)
+	}))
+	require.Error(t, migratePoolFormat(ctx, db, logger))
+}
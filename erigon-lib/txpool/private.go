@@ -0,0 +1,85 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+var (
+	privateTxsGauge          = metrics.GetOrCreateGauge(`txpool_private_txs`)
+	privateTxsExpiredCounter = metrics.GetOrCreateCounter(`txpool_private_txs_expired`)
+)
+
+// AddPrivateTxs - adds local txs which are available for local block building, but never gossiped to peers:
+// they are not announced, not sent to new peers and not streamed to subscribers of new txs.
+// Private txs are not journaled and not persisted to db (lost on restart), and are discarded if not mined
+// until `maxBlockNumber` (0 - `PrivateTxBlocks` after last seen block).
+func (p *TxPool) AddPrivateTxs(ctx context.Context, newTxs types.TxSlots, tx kv.Tx, maxBlockNumber uint64) ([]txpoolcfg.DiscardReason, error) {
+	lastSeenBlock := p.lastSeenBlock.Load()
+	if maxBlockNumber == 0 {
+		maxBlockNumber = lastSeenBlock + p.cfg.PrivateTxBlocks
+	}
+	if maxBlockNumber <= lastSeenBlock {
+		return nil, fmt.Errorf("max block number %d of private txs is not after last seen block %d", maxBlockNumber, lastSeenBlock)
+	}
+	return p.addLocalTxs(ctx, newTxs, maxBlockNumber, nil)
+}
+
+// IsPrivate - txn was added by AddPrivateTxs and must not be gossiped
+func (p *TxPool) IsPrivate(idHash []byte) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	_, ok := p.private[string(idHash)]
+	return ok
+}
+
+func (p *TxPool) setPrivateLocked(idHash []byte, maxBlockNumber uint64) {
+	p.private[string(idHash)] = maxBlockNumber
+	privateTxsGauge.SetInt(len(p.private))
+}
+
+func (p *TxPool) unsetPrivateLocked(hashStr string) {
+	if _, ok := p.private[hashStr]; !ok {
+		return
+	}
+	delete(p.private, hashStr)
+	privateTxsGauge.SetInt(len(p.private))
+}
+
+// expirePrivateLocked - discards private txs which were not mined until their max block number
+func (p *TxPool) expirePrivateLocked(block uint64) {
+	for hashStr, maxBlockNumber := range p.private {
+		if maxBlockNumber >= block {
+			continue
+		}
+		mt, ok := p.byHash[hashStr]
+		if !ok {
+			p.unsetPrivateLocked(hashStr)
+			continue
+		}
+		p.removeFromSubPool(mt, "private-expired")
+		p.discardLocked(mt, txpoolcfg.PrivateTxExpired)
+		privateTxsExpiredCounter.Inc()
+	}
+}
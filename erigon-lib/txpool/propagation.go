@@ -0,0 +1,141 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+)
+
+var (
+	propagatedBodiesCounter        = metrics.GetOrCreateCounter(`txpool_propagated_bodies`)
+	propagatedAnnouncementsCounter = metrics.GetOrCreateCounter(`txpool_propagated_announcements`)
+	propagationRateLimitedCounter  = metrics.GetOrCreateCounter(`txpool_propagation_rate_limited`)
+)
+
+// maxPropagationLimiters - rate limiters of idle peers are forgotten when there are more limiters than this
+const maxPropagationLimiters = 1024
+
+// propagationPolicy - peer-aware propagation of new txs, see txpoolcfg.Propagation
+type propagationPolicy struct {
+	cfg  txpoolcfg.Propagation
+	deny map[[64]byte]struct{}
+
+	lock     sync.Mutex
+	limiters map[[64]byte]*rate.Limiter // public key of peer => limit of txs sent to it
+}
+
+func newPropagationPolicy(cfg txpoolcfg.Propagation) (*propagationPolicy, error) {
+	if cfg.BroadcastFraction < 0 || cfg.BroadcastFraction > 1 {
+		return nil, fmt.Errorf("broadcast fraction must be between 0 and 1, got %v", cfg.BroadcastFraction)
+	}
+	p := &propagationPolicy{cfg: cfg, deny: map[[64]byte]struct{}{}, limiters: map[[64]byte]*rate.Limiter{}}
+	for _, peer := range cfg.DenyPeers {
+		pubkey, err := parsePeerPubkey(peer)
+		if err != nil {
+			return nil, fmt.Errorf("propagation deny peer %q: %w", peer, err)
+		}
+		p.deny[pubkey] = struct{}{}
+	}
+	return p, nil
+}
+
+// parsePeerPubkey - public key of peer from enode URL (enode://<hex public key>@host:port) or hex public key
+func parsePeerPubkey(s string) (pubkey [64]byte, err error) {
+	s = strings.TrimPrefix(s, "enode://")
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		s = s[:i]
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return pubkey, err
+	}
+	if len(b) != len(pubkey) {
+		return pubkey, fmt.Errorf("public key must be %d bytes, got %d", len(pubkey), len(b))
+	}
+	copy(pubkey[:], b)
+	return pubkey, nil
+}
+
+func (p *propagationPolicy) denied(peer [64]byte) bool {
+	_, ok := p.deny[peer]
+	return ok
+}
+
+// split - chooses peers which receive bodies of new txs (`BroadcastFraction` of peers, or square root of number
+// of peers by default) and peers which receive announcements. Denied peers receive nothing.
+func (p *propagationPolicy) split(peers []*typesproto.PeerInfo) (bodies, announces [][64]byte) {
+	allowed := make([][64]byte, 0, len(peers))
+	for _, peer := range peers {
+		pubkey, err := parsePeerPubkey(peer.Enode)
+		if err != nil {
+			continue
+		}
+		if p.denied(pubkey) {
+			continue
+		}
+		allowed = append(allowed, pubkey)
+	}
+	rand.Shuffle(len(allowed), func(i, j int) { allowed[i], allowed[j] = allowed[j], allowed[i] })
+
+	n := int(math.Sqrt(float64(len(allowed))))
+	if p.cfg.BroadcastFraction > 0 {
+		n = int(math.Ceil(p.cfg.BroadcastFraction * float64(len(allowed))))
+	}
+	return allowed[:n], allowed[n:]
+}
+
+// allow - how many of `n` txs can be sent to peer now, without exceeding `PeerTxsPerSecond`
+func (p *propagationPolicy) allow(peer [64]byte, n int) int {
+	if p.cfg.PeerTxsPerSecond == 0 || n == 0 {
+		return n
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now()
+	limiter, ok := p.limiters[peer]
+	if !ok {
+		if len(p.limiters) >= maxPropagationLimiters {
+			for pubkey, l := range p.limiters {
+				if l.TokensAt(now) >= float64(l.Burst()) {
+					delete(p.limiters, pubkey)
+				}
+			}
+		}
+		limiter = rate.NewLimiter(rate.Limit(p.cfg.PeerTxsPerSecond), int(p.cfg.PeerTxsPerSecond))
+		p.limiters[peer] = limiter
+	}
+	allowed := min(n, int(limiter.TokensAt(now)))
+	if allowed > 0 {
+		limiter.AllowN(now, allowed)
+	}
+	if allowed < n {
+		propagationRateLimitedCounter.AddInt(n - allowed)
+	}
+	return allowed
+}
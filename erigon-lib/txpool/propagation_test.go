@@ -0,0 +1,103 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+)
+
+func testPeerPubkey(i byte) (pubkey [64]byte) {
+	pubkey[0] = i
+	pubkey[63] = i
+	return pubkey
+}
+
+func testPeers(n int) []*typesproto.PeerInfo {
+	peers := make([]*typesproto.PeerInfo, n)
+	for i := range peers {
+		pubkey := testPeerPubkey(byte(i + 1))
+		peers[i] = &typesproto.PeerInfo{Enode: "enode://" + hex.EncodeToString(pubkey[:]) + "@127.0.0.1:30303"}
+	}
+	return peers
+}
+
+func TestPropagationPolicy(t *testing.T) {
+	t.Run("parse peer", func(t *testing.T) {
+		pubkey := testPeerPubkey(7)
+		for _, s := range []string{
+			hex.EncodeToString(pubkey[:]),
+			"0x" + hex.EncodeToString(pubkey[:]),
+			"enode://" + hex.EncodeToString(pubkey[:]) + "@10.0.0.1:30303?discport=0",
+		} {
+			parsed, err := parsePeerPubkey(s)
+			require.NoError(t, err)
+			require.Equal(t, pubkey, parsed)
+		}
+		_, err := parsePeerPubkey("enode://abcd@10.0.0.1:30303")
+		require.Error(t, err)
+		_, err = parsePeerPubkey(strings.Repeat("zz", 64))
+		require.Error(t, err)
+
+		_, err = newPropagationPolicy(txpoolcfg.Propagation{BroadcastFraction: 1.5})
+		require.Error(t, err)
+		_, err = newPropagationPolicy(txpoolcfg.Propagation{DenyPeers: []string{"nonsense"}})
+		require.Error(t, err)
+	})
+	t.Run("split", func(t *testing.T) {
+		denied := testPeerPubkey(1)
+		p, err := newPropagationPolicy(txpoolcfg.Propagation{DenyPeers: []string{hex.EncodeToString(denied[:])}})
+		require.NoError(t, err)
+		bodies, announces := p.split(testPeers(17))
+		require.Len(t, bodies, 4) // sqrt of 16 allowed peers
+		require.Len(t, announces, 12)
+		for _, peer := range append(bodies, announces...) {
+			require.NotEqual(t, denied, peer)
+		}
+
+		p, err = newPropagationPolicy(txpoolcfg.Propagation{BroadcastFraction: 0.5})
+		require.NoError(t, err)
+		bodies, announces = p.split(testPeers(9))
+		require.Len(t, bodies, 5)
+		require.Len(t, announces, 4)
+
+		p, err = newPropagationPolicy(txpoolcfg.Propagation{BroadcastFraction: 1})
+		require.NoError(t, err)
+		bodies, announces = p.split(testPeers(3))
+		require.Len(t, bodies, 3)
+		require.Empty(t, announces)
+	})
+	t.Run("rate limit", func(t *testing.T) {
+		p, err := newPropagationPolicy(txpoolcfg.Propagation{PeerTxsPerSecond: 10})
+		require.NoError(t, err)
+		peer, other := testPeerPubkey(1), testPeerPubkey(2)
+		require.Equal(t, 6, p.allow(peer, 6))
+		require.Equal(t, 4, p.allow(peer, 6))
+		require.Equal(t, 0, p.allow(peer, 6))
+		require.Equal(t, 10, p.allow(other, 20))
+
+		unlimited, err := newPropagationPolicy(txpoolcfg.Propagation{})
+		require.NoError(t, err)
+		require.Equal(t, 1000, unlimited.allow(peer, 1000))
+	})
+}
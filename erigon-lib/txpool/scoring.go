@@ -0,0 +1,176 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+// ScoredTx - executable (pending) txn, as it's seen by Scorer
+type ScoredTx struct {
+	Hash         common.Hash    `json:"hash"`
+	Sender       common.Address `json:"sender"`
+	Nonce        uint64         `json:"nonce"`
+	Type         byte           `json:"type"`
+	Gas          uint64         `json:"gas"`
+	Size         uint32         `json:"size"`
+	Tip          uint256.Int    `json:"tip"`
+	FeeCap       uint256.Int    `json:"feeCap"`
+	BlobFeeCap   uint256.Int    `json:"blobFeeCap"`
+	EffectiveTip uint256.Int    `json:"effectiveTip"` // tip which txn pays on top of pending base fee
+	Local        bool           `json:"local"`
+	Arrival      uint64         `json:"arrival"` // sequence number of arrival to pool, grows monotonically (within process lifetime)
+}
+
+// Scorer - custom order of yielding executable txs by YieldBest/PeekBest (and as result - order of txs in mined blocks).
+// Higher score - earlier txn is yielded, txs with equal scores keep default order (by effective tip).
+// Txs of same sender are always yielded in nonce order: effective score of txn is min of scores of sender's txs
+// with lower nonces.
+// Called under lock of pool - must be fast.
+type Scorer interface {
+	Score(txs []*ScoredTx) []uint64
+}
+
+var (
+	scorersLock sync.RWMutex
+	scorers     = map[string]func() Scorer{
+		"fifo": func() Scorer { return fifoScorer{} },
+	}
+)
+
+// RegisterScorer - makes Scorer available by name for `--txpool.scoring` flag. Intended to be called from init()
+// of package linked to binary at build time.
+func RegisterScorer(name string, newScorer func() Scorer) {
+	scorersLock.Lock()
+	defer scorersLock.Unlock()
+	if _, ok := scorers[name]; ok {
+		panic(fmt.Sprintf("txpool scorer %q is already registered", name))
+	}
+	scorers[name] = newScorer
+}
+
+const grpcScorerPrefix = "grpc://"
+
+// NewScorer - by value of `txpoolcfg.Config.Scoring`: "" - default order (nil Scorer), name of registered Scorer,
+// or "grpc://<host>:<port>" - external Scorer (see ScorerServer)
+func NewScorer(scoring string) (Scorer, error) {
+	if scoring == "" {
+		return nil, nil
+	}
+	if addr, ok := strings.CutPrefix(scoring, grpcScorerPrefix); ok {
+		return newGrpcScorer(addr)
+	}
+	scorersLock.RLock()
+	defer scorersLock.RUnlock()
+	newScorer, ok := scorers[scoring]
+	if !ok {
+		return nil, fmt.Errorf("unknown txpool scoring: %q", scoring)
+	}
+	return newScorer(), nil
+}
+
+// fifoScorer - first-come-first-served: txs are yielded in order of arrival to pool
+type fifoScorer struct{}
+
+func (fifoScorer) Score(txs []*ScoredTx) []uint64 {
+	scores := make([]uint64, len(txs))
+	for i, txn := range txs {
+		scores[i] = math.MaxUint64 - txn.Arrival
+	}
+	return scores
+}
+
+func (p *TxPool) scoredTx(mt *metaTx, pendingBaseFee *uint256.Int) *ScoredTx {
+	txn := &ScoredTx{
+		Hash:       mt.Tx.IDHash,
+		Sender:     p.senders.senderID2Addr[mt.Tx.SenderID],
+		Nonce:      mt.Tx.Nonce,
+		Type:       mt.Tx.Type,
+		Gas:        mt.Tx.Gas,
+		Size:       mt.Tx.Size,
+		Tip:        mt.Tx.Tip,
+		FeeCap:     mt.Tx.FeeCap,
+		BlobFeeCap: mt.Tx.BlobFeeCap,
+		Local:      mt.subPool&IsLocal != 0,
+		Arrival:    mt.arrival,
+	}
+	if txn.FeeCap.Gt(pendingBaseFee) {
+		txn.EffectiveTip.Sub(&txn.FeeCap, pendingBaseFee)
+		if txn.EffectiveTip.Gt(&txn.Tip) {
+			txn.EffectiveTip = txn.Tip
+		}
+	}
+	return txn
+}
+
+// scored - executable txs (in default order) re-ordered by scores of p.scorer
+func (p *TxPool) scored(ms []*metaTx) []*metaTx {
+	if len(ms) == 0 {
+		return ms
+	}
+	pendingBaseFee := uint256.NewInt(p.pendingBaseFee.Load())
+	txs := make([]*ScoredTx, len(ms))
+	for i, mt := range ms {
+		txs[i] = p.scoredTx(mt, pendingBaseFee)
+	}
+	scores := p.scorer.Score(txs)
+	if len(scores) != len(ms) {
+		p.logger.Warn("[txpool] Scorer failed, default order is used", "txs", len(ms), "scores", len(scores))
+		return ms
+	}
+
+	bySender := map[uint64][]int{}
+	for i, mt := range ms {
+		bySender[mt.Tx.SenderID] = append(bySender[mt.Tx.SenderID], i)
+	}
+	for _, idx := range bySender {
+		sort.Slice(idx, func(a, b int) bool { return ms[idx[a]].Tx.Nonce < ms[idx[b]].Tx.Nonce })
+		for k := 1; k < len(idx); k++ {
+			scores[idx[k]] = min(scores[idx[k]], scores[idx[k-1]])
+		}
+	}
+
+	order := make([]int, len(ms))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	res := make([]*metaTx, len(ms))
+	for i, j := range order {
+		res[i] = ms[j]
+	}
+
+	// txs of sender with equal scores may be in any order: put them to positions of sender's txs by nonce
+	positions := map[uint64][]int{}
+	for i, mt := range res {
+		positions[mt.Tx.SenderID] = append(positions[mt.Tx.SenderID], i)
+	}
+	for senderID, idx := range bySender {
+		for k, i := range positions[senderID] {
+			res[i] = ms[idx[k]]
+		}
+	}
+	return res
+}
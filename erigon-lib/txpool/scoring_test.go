@@ -0,0 +1,77 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+type brokenScorer struct{}
+
+func (brokenScorer) Score(txs []*ScoredTx) []uint64 { return nil }
+
+func TestScoring(t *testing.T) {
+	_, err := NewScorer("unknown")
+	require.Error(t, err)
+	scorer, err := NewScorer("")
+	require.NoError(t, err)
+	require.Nil(t, scorer)
+	scorer, err = NewScorer("fifo")
+	require.NoError(t, err)
+
+	p := &TxPool{senders: newSendersCache(nil), scorer: scorer, logger: log.New()}
+	newMt := func(senderID, nonce, arrival uint64) *metaTx {
+		return &metaTx{Tx: &types.TxSlot{SenderID: senderID, Nonce: nonce}, arrival: arrival}
+	}
+	// default order (by tip): sender 2 nonce 7 arrived before its nonce 6 (e.g. nonce 6 was replaced)
+	ms := []*metaTx{newMt(1, 1, 5), newMt(2, 7, 2), newMt(3, 1, 4), newMt(2, 6, 3), newMt(1, 2, 1)}
+
+	got := p.scored(ms)
+	type senderNonce struct{ sender, nonce uint64 }
+	order := make([]senderNonce, len(got))
+	for i, mt := range got {
+		order[i] = senderNonce{mt.Tx.SenderID, mt.Tx.Nonce}
+	}
+	require.Equal(t, []senderNonce{{2, 6}, {2, 7}, {3, 1}, {1, 1}, {1, 2}}, order)
+
+	p.scorer = brokenScorer{}
+	require.Equal(t, ms, p.scored(ms))
+	require.Empty(t, p.scored(nil))
+}
+
+func TestScorerServer(t *testing.T) {
+	srv := NewScorerServer(fifoScorer{})
+	newTx := func(arrival uint64) *txpool_proto.ScoredTx {
+		zero := gointerfaces.ConvertUint256IntToH256(new(uint256.Int))
+		return &txpool_proto.ScoredTx{Hash: zero, Sender: gointerfaces.ConvertAddressToH160(common.Address{}),
+			Tip: zero, FeeCap: zero, BlobFeeCap: zero, EffectiveTip: zero, Arrival: arrival}
+	}
+	out, err := srv.Score(context.Background(), &txpool_proto.ScoreRequest{Txs: []*txpool_proto.ScoredTx{newTx(1), newTx(2)}})
+	require.NoError(t, err)
+	require.Len(t, out.Scores, 2)
+	require.Greater(t, out.Scores[0], out.Scores[1])
+}
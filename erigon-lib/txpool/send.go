@@ -22,11 +22,14 @@ import (
 	"sync"
 
 	"github.com/ledgerwatch/erigon-lib/direct"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
 	"github.com/ledgerwatch/erigon-lib/rlp"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 	types2 "github.com/ledgerwatch/erigon-lib/types"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type SentryClient interface {
@@ -40,7 +43,9 @@ type Send struct {
 	ctx           context.Context
 	pool          Pool
 	wg            *sync.WaitGroup
-	sentryClients []direct.SentryClient // sentry clients that will be used for accessing the network
+	sentryClients []direct.SentryClient  // sentry clients that will be used for accessing the network
+	propagation   *propagationPolicy     // nil - txs are sent to random peers chosen by sentry
+	balancer      *direct.SentryBalancer // nil - txs are broadcast by all ready sentries
 	logger        log.Logger
 }
 
@@ -57,6 +62,52 @@ func (f *Send) SetWaitGroup(wg *sync.WaitGroup) {
 	f.wg = wg
 }
 
+// SetPropagation - enables peer-aware propagation of txs (see Propagate), if cfg is not zero
+func (f *Send) SetPropagation(cfg txpoolcfg.Propagation) error {
+	if !cfg.PeerAware() {
+		f.propagation = nil
+		return nil
+	}
+	propagation, err := newPropagationPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	f.propagation = propagation
+	return nil
+}
+
+// PeerAware - new txs must be sent by Propagate instead of BroadcastPooledTxs and AnnouncePooledTxs
+// SetBalancer - skip degraded sentries when broadcasting txs, see direct.SentryBalancer
+func (f *Send) SetBalancer(b *direct.SentryBalancer) { f.balancer = b }
+
+// broadcastSentries returns indices of the sentries to broadcast message with given id by
+func (f *Send) broadcastSentries(id sentry.MessageId) []int {
+	if f.balancer != nil {
+		return f.balancer.Healthy(id)
+	}
+	indices := make([]int, 0, len(f.sentryClients))
+	for i, sentryClient := range f.sentryClients {
+		if sentryClient.Ready() {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// sent records result of sending to sentry i for the balancer
+func (f *Send) sent(i int, err error) {
+	if f.balancer == nil {
+		return
+	}
+	if err != nil {
+		f.balancer.Failure(i)
+		return
+	}
+	f.balancer.Success(i)
+}
+
+func (f *Send) PeerAware() bool { return f.propagation != nil }
+
 const (
 	// This is the target size for the packs of transactions or announcements. A
 	// pack can get larger than this if a single transactions exceeds this size.
@@ -84,10 +135,8 @@ func (f *Send) BroadcastPooledTxs(rlps [][]byte, maxPeers uint64) (txSentTo []in
 		if i == l-1 || size >= p2pTxPacketLimit {
 			txsData := types2.EncodeTransactions(rlps[prev:i+1], nil)
 			var txs66 *sentry.SendMessageToRandomPeersRequest
-			for _, sentryClient := range f.sentryClients {
-				if !sentryClient.Ready() {
-					continue
-				}
+			for _, k := range f.broadcastSentries(sentry.MessageId_TRANSACTIONS_66) {
+				sentryClient := f.sentryClients[k]
 				if txs66 == nil {
 					txs66 = &sentry.SendMessageToRandomPeersRequest{
 						Data: &sentry.OutboundMessageData{
@@ -98,6 +147,7 @@ func (f *Send) BroadcastPooledTxs(rlps [][]byte, maxPeers uint64) (txSentTo []in
 					}
 				}
 				peers, err := sentryClient.SendMessageToRandomPeers(f.ctx, txs66)
+				f.sent(k, err)
 				if err != nil {
 					f.logger.Debug("[txpool.send] BroadcastPooledTxs", "err", err)
 				}
@@ -142,10 +192,8 @@ func (f *Send) AnnouncePooledTxs(types []byte, sizes []uint32, hashes types2.Has
 		if s := rlp.EncodeAnnouncements(types[prevJ:j], sizes[prevJ:j], hashes[32*prevJ:32*j], jData); s != jSize {
 			panic(fmt.Sprintf("Serialised announcements encoding len mismatch, expected %d, got %d", jSize, s))
 		}
-		for _, sentryClient := range f.sentryClients {
-			if !sentryClient.Ready() {
-				continue
-			}
+		for _, n := range f.broadcastSentries(sentry.MessageId_TRANSACTIONS_66) { // message id depends on protocol
+			sentryClient := f.sentryClients[n]
 			switch sentryClient.Protocol() {
 			case direct.ETH66, direct.ETH67:
 				if i > prevI {
@@ -157,6 +205,7 @@ func (f *Send) AnnouncePooledTxs(types []byte, sizes []uint32, hashes types2.Has
 						MaxPeers: maxPeers,
 					}
 					peers, err := sentryClient.SendMessageToRandomPeers(f.ctx, req)
+					f.sent(n, err)
 					if err != nil {
 						f.logger.Debug("[txpool.send] AnnouncePooledTxs", "err", err)
 					}
@@ -166,7 +215,7 @@ func (f *Send) AnnouncePooledTxs(types []byte, sizes []uint32, hashes types2.Has
 						}
 					}
 				}
-			case direct.ETH68:
+			case direct.ETH68, direct.ETH69:
 
 				if j > prevJ {
 					req := &sentry.SendMessageToRandomPeersRequest{
@@ -177,6 +226,7 @@ func (f *Send) AnnouncePooledTxs(types []byte, sizes []uint32, hashes types2.Has
 						MaxPeers: maxPeers,
 					}
 					peers, err := sentryClient.SendMessageToRandomPeers(f.ctx, req)
+					f.sent(n, err)
 					if err != nil {
 						f.logger.Debug("[txpool.send] AnnouncePooledTxs68", "err", err)
 					}
@@ -201,6 +251,15 @@ func (f *Send) PropagatePooledTxsToPeersList(peers []types2.PeerID, types []byte
 	if len(types) == 0 {
 		return
 	}
+	if f.propagation != nil {
+		allowed := make([]types2.PeerID, 0, len(peers))
+		for _, peer := range peers {
+			if !f.propagation.denied(gointerfaces.ConvertH512ToHash(peer)) {
+				allowed = append(allowed, peer)
+			}
+		}
+		peers = allowed
+	}
 
 	prevI := 0
 	prevJ := 0
@@ -245,7 +304,7 @@ func (f *Send) PropagatePooledTxsToPeersList(peers []types2.PeerID, types []byte
 							f.logger.Debug("[txpool.send] PropagatePooledTxsToPeersList", "err", err)
 						}
 					}
-				case direct.ETH68:
+				case direct.ETH68, direct.ETH69:
 
 					if j > prevJ {
 						req := &sentry.SendMessageByIdRequest{
@@ -267,3 +326,114 @@ func (f *Send) PropagatePooledTxsToPeersList(peers []types2.PeerID, types []byte
 		prevJ = j
 	}
 }
+
+// Propagate - peer-aware alternative of BroadcastPooledTxs and AnnouncePooledTxs: bodies of txs are sent to part of
+// peers chosen by propagation policy, announcements - to other peers, denied peers receive nothing, and txs over
+// per-peer rate limit are not sent at all (first txs have priority). `rlps[i]` is nil if txn must be only announced.
+func (f *Send) Propagate(txTypes []byte, sizes []uint32, hashes types2.Hashes, rlps [][]byte) {
+	if len(txTypes) == 0 || f.propagation == nil {
+		return
+	}
+	for _, sentryClient := range f.sentryClients {
+		if !sentryClient.Ready() {
+			continue
+		}
+		reply, err := sentryClient.Peers(f.ctx, &emptypb.Empty{})
+		if err != nil {
+			f.logger.Debug("[txpool.send] Propagate", "err", err)
+			continue
+		}
+		bodies, announces := f.propagation.split(reply.Peers)
+		for _, peer := range bodies {
+			n := f.propagation.allow(peer, len(txTypes))
+			var bodyRlps [][]byte
+			var announced []int
+			for i := 0; i < n; i++ {
+				// "Nodes MUST NOT automatically broadcast blob transactions to their peers" - EIP-4844
+				if rlps[i] != nil && txTypes[i] != types2.BlobTxType {
+					bodyRlps = append(bodyRlps, rlps[i])
+				} else {
+					announced = append(announced, i)
+				}
+			}
+			f.sendBodies(sentryClient, peer, bodyRlps)
+			f.sendAnnouncements(sentryClient, peer, txTypes, sizes, hashes, announced)
+		}
+		for _, peer := range announces {
+			n := f.propagation.allow(peer, len(txTypes))
+			announced := make([]int, n)
+			for i := range announced {
+				announced[i] = i
+			}
+			f.sendAnnouncements(sentryClient, peer, txTypes, sizes, hashes, announced)
+		}
+	}
+}
+
+func (f *Send) sendBodies(sentryClient direct.SentryClient, peer [64]byte, rlps [][]byte) {
+	var prev, size int
+	for i := range rlps {
+		size += len(rlps[i])
+		if i < len(rlps)-1 && size < p2pTxPacketLimit {
+			continue
+		}
+		req := &sentry.SendMessageByIdRequest{
+			PeerId: gointerfaces.ConvertHashToH512(peer),
+			Data: &sentry.OutboundMessageData{
+				Id:   sentry.MessageId_TRANSACTIONS_66,
+				Data: types2.EncodeTransactions(rlps[prev:i+1], nil),
+			},
+		}
+		if _, err := sentryClient.SendMessageById(f.ctx, req, &grpc.EmptyCallOption{}); err != nil {
+			f.logger.Debug("[txpool.send] Propagate bodies", "err", err)
+		} else {
+			propagatedBodiesCounter.AddInt(i + 1 - prev)
+		}
+		prev, size = i+1, 0
+	}
+}
+
+// sendAnnouncements - announces txs with given indices to peer
+func (f *Send) sendAnnouncements(sentryClient direct.SentryClient, peer [64]byte, txTypes []byte, sizes []uint32, hashes types2.Hashes, indices []int) {
+	if len(indices) == 0 {
+		return
+	}
+	var annTypes []byte
+	var annSizes []uint32
+	var annHashes types2.Hashes
+	for _, i := range indices {
+		annTypes = append(annTypes, txTypes[i])
+		annSizes = append(annSizes, sizes[i])
+		annHashes = append(annHashes, hashes.At(i)...)
+	}
+	for prev := 0; prev < len(annTypes); {
+		j := prev
+		for j < len(annTypes) && rlp.AnnouncementsLen(annTypes[prev:j+1], annSizes[prev:j+1], annHashes[32*prev:32*j+32]) < p2pTxPacketLimit {
+			j++
+		}
+		if j == prev { // single announcement exceeds packet limit, must never happen
+			j++
+		}
+		var data []byte
+		id := sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68
+		switch sentryClient.Protocol() {
+		case direct.ETH66, direct.ETH67:
+			id = sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66
+			data = make([]byte, rlp.HashesLen(annHashes[32*prev:32*j]))
+			rlp.EncodeHashes(annHashes[32*prev:32*j], data)
+		default:
+			data = make([]byte, rlp.AnnouncementsLen(annTypes[prev:j], annSizes[prev:j], annHashes[32*prev:32*j]))
+			rlp.EncodeAnnouncements(annTypes[prev:j], annSizes[prev:j], annHashes[32*prev:32*j], data)
+		}
+		req := &sentry.SendMessageByIdRequest{
+			PeerId: gointerfaces.ConvertHashToH512(peer),
+			Data:   &sentry.OutboundMessageData{Id: id, Data: data},
+		}
+		if _, err := sentryClient.SendMessageById(f.ctx, req, &grpc.EmptyCallOption{}); err != nil {
+			f.logger.Debug("[txpool.send] Propagate announcements", "err", err)
+		} else {
+			propagatedAnnouncementsCounter.AddInt(j - prev)
+		}
+		prev = j
+	}
+}
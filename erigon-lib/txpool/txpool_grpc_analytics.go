@@ -0,0 +1,70 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+)
+
+func (s *GrpcServer) Analytics(ctx context.Context, in *txpool_proto.AnalyticsRequest) (*txpool_proto.AnalyticsReply, error) {
+	var sender *common.Address
+	if in.Sender != nil {
+		addr := common.Address(gointerfaces.ConvertH160toAddress(in.Sender))
+		sender = &addr
+	}
+	a := s.txPool.Analytics(sender)
+	reply := &txpool_proto.AnalyticsReply{
+		Block:        a.Block,
+		BaseFee:      a.BaseFee,
+		Txs:          uint64(a.Txs),
+		BelowBaseFee: uint64(a.BelowBaseFee),
+		Age:          make([]*txpool_proto.AnalyticsReply_AgeBucket, len(a.Age)),
+		NonceGaps:    make([]*txpool_proto.AnalyticsReply_SenderNonceGap, len(a.NonceGaps)),
+	}
+	for i, bucket := range a.Age {
+		reply.Age[i] = &txpool_proto.AnalyticsReply_AgeBucket{Blocks: bucket.Blocks, Txs: uint64(bucket.Txs)}
+	}
+	for i, gap := range a.NonceGaps {
+		reply.NonceGaps[i] = &txpool_proto.AnalyticsReply_SenderNonceGap{Sender: gointerfaces.ConvertAddressToH160(gap.Sender),
+			StateNonce: gap.StateNonce, FirstGap: gap.FirstGap, GappedTxs: uint64(gap.GappedTxs)}
+	}
+	return reply, nil
+}
+
+// AnalyticsFromReply - Analytics from reply of `txpool.Txpool/Analytics`
+func AnalyticsFromReply(reply *txpool_proto.AnalyticsReply) *Analytics {
+	a := &Analytics{
+		Block:        reply.Block,
+		BaseFee:      reply.BaseFee,
+		Txs:          int(reply.Txs),
+		BelowBaseFee: int(reply.BelowBaseFee),
+		Age:          make([]AgeBucket, len(reply.Age)),
+		NonceGaps:    make([]SenderNonceGap, len(reply.NonceGaps)),
+	}
+	for i, bucket := range reply.Age {
+		a.Age[i] = AgeBucket{Blocks: bucket.Blocks, Txs: int(bucket.Txs)}
+	}
+	for i, gap := range reply.NonceGaps {
+		a.NonceGaps[i] = SenderNonceGap{Sender: gointerfaces.ConvertH160toAddress(gap.Sender), StateNonce: gap.StateNonce,
+			FirstGap: gap.FirstGap, GappedTxs: int(gap.GappedTxs)}
+	}
+	return a
+}
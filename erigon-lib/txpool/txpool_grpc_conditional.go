@@ -0,0 +1,78 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+)
+
+func (s *GrpcServer) AddConditional(ctx context.Context, in *txpool_proto.AddConditionalRequest) (*txpool_proto.AddReply, error) {
+	conditions := &Conditions{
+		BlockNumberMin: in.BlockNumberMin,
+		BlockNumberMax: in.BlockNumberMax,
+		TimestampMin:   in.TimestampMin,
+		TimestampMax:   in.TimestampMax,
+	}
+	if len(in.KnownAccounts) > 0 {
+		conditions.KnownAccounts = make(map[common.Address]map[common.Hash]common.Hash, len(in.KnownAccounts))
+		for _, account := range in.KnownAccounts {
+			storage := make(map[common.Hash]common.Hash, len(account.Slots))
+			for _, slot := range account.Slots {
+				storage[gointerfaces.ConvertH256ToHash(slot.Key)] = gointerfaces.ConvertH256ToHash(slot.Value)
+			}
+			conditions.KnownAccounts[gointerfaces.ConvertH160toAddress(account.Address)] = storage
+		}
+	}
+	if err := conditions.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.add(ctx, in.RlpTxs, func(ctx context.Context, newTxs types.TxSlots, tx kv.Tx) ([]txpoolcfg.DiscardReason, error) {
+		return s.txPool.AddConditionalTxs(ctx, newTxs, tx, conditions)
+	})
+}
+
+// AddConditionalRequest - request of `txpool.Txpool/AddConditional`
+func AddConditionalRequest(rlpTxs [][]byte, conditions *Conditions) *txpool_proto.AddConditionalRequest {
+	req := &txpool_proto.AddConditionalRequest{
+		RlpTxs:         rlpTxs,
+		KnownAccounts:  make([]*txpool_proto.KnownAccount, 0, len(conditions.KnownAccounts)),
+		BlockNumberMin: conditions.BlockNumberMin,
+		BlockNumberMax: conditions.BlockNumberMax,
+		TimestampMin:   conditions.TimestampMin,
+		TimestampMax:   conditions.TimestampMax,
+	}
+	for addr, storage := range conditions.KnownAccounts {
+		account := &txpool_proto.KnownAccount{Address: gointerfaces.ConvertAddressToH160(addr),
+			Slots: make([]*txpool_proto.StorageSlot, 0, len(storage))}
+		for key, value := range storage {
+			account.Slots = append(account.Slots, &txpool_proto.StorageSlot{Key: gointerfaces.ConvertHashToH256(key),
+				Value: gointerfaces.ConvertHashToH256(value)})
+		}
+		req.KnownAccounts = append(req.KnownAccounts, account)
+	}
+	return req
+}
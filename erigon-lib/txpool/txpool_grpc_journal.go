@@ -0,0 +1,48 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+)
+
+func (s *GrpcServer) ListJournal(ctx context.Context, _ *emptypb.Empty) (*txpool_proto.TransactionsReply, error) {
+	return &txpool_proto.TransactionsReply{RlpTxs: s.txPool.Journaled()}, nil
+}
+
+func (s *GrpcServer) EvictJournal(ctx context.Context, in *txpool_proto.TxHashes) (*txpool_proto.TxHashes, error) {
+	hashes := make([]common.Hash, len(in.Hashes))
+	for i := range in.Hashes {
+		hashes[i] = gointerfaces.ConvertH256ToHash(in.Hashes[i])
+	}
+	evicted, err := s.txPool.EvictJournaled(hashes)
+	if err != nil {
+		return nil, err
+	}
+	reply := &txpool_proto.TxHashes{Hashes: make([]*types2.H256, len(evicted))}
+	for i := range evicted {
+		reply.Hashes[i] = gointerfaces.ConvertHashToH256(evicted[i])
+	}
+	return reply, nil
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+)
+
+var txStatusToProto = map[TxStatus]txpool_proto.TxOutcome_Status{
+	TxAccepted: txpool_proto.TxOutcome_ACCEPTED,
+	TxRejected: txpool_proto.TxOutcome_REJECTED,
+	TxReplaced: txpool_proto.TxOutcome_REPLACED,
+	TxDropped:  txpool_proto.TxOutcome_DROPPED,
+	TxMined:    txpool_proto.TxOutcome_MINED,
+}
+
+var txStatusFromProto = map[txpool_proto.TxOutcome_Status]TxStatus{
+	txpool_proto.TxOutcome_ACCEPTED: TxAccepted,
+	txpool_proto.TxOutcome_REJECTED: TxRejected,
+	txpool_proto.TxOutcome_REPLACED: TxReplaced,
+	txpool_proto.TxOutcome_DROPPED:  TxDropped,
+	txpool_proto.TxOutcome_MINED:    TxMined,
+}
+
+func (s *GrpcServer) SubscribeOutcomes(in *txpool_proto.OutcomesRequest, stream txpool_proto.Txpool_SubscribeOutcomesServer) error {
+	var filter map[common.Hash]struct{}
+	if len(in.Hashes) > 0 {
+		filter = make(map[common.Hash]struct{}, len(in.Hashes))
+		for _, hash := range in.Hashes {
+			filter[gointerfaces.ConvertH256ToHash(hash)] = struct{}{}
+		}
+	}
+	ch, unsubscribe := s.txPool.SubscribeOutcomes()
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case outcome := <-ch:
+			if filter != nil {
+				if _, ok := filter[outcome.Hash]; !ok {
+					continue
+				}
+			}
+			reply := &txpool_proto.TxOutcome{
+				Hash:       gointerfaces.ConvertHashToH256(outcome.Hash),
+				Sender:     gointerfaces.ConvertAddressToH160(outcome.Sender),
+				Nonce:      outcome.Nonce,
+				Status:     txStatusToProto[outcome.Status],
+				ReasonCode: uint32(outcome.ReasonCode),
+				Reason:     outcome.Reason,
+			}
+			if outcome.ReplacedBy != nil {
+				reply.ReplacedBy = gointerfaces.ConvertHashToH256(*outcome.ReplacedBy)
+			}
+			if err := stream.Send(reply); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// TxOutcomeFromProto - TxOutcome from item of `txpool.Txpool/SubscribeOutcomes` stream
+func TxOutcomeFromProto(in *txpool_proto.TxOutcome) *TxOutcome {
+	outcome := &TxOutcome{
+		Hash:       gointerfaces.ConvertH256ToHash(in.Hash),
+		Sender:     gointerfaces.ConvertH160toAddress(in.Sender),
+		Nonce:      in.Nonce,
+		Status:     txStatusFromProto[in.Status],
+		ReasonCode: txpoolcfg.DiscardReason(in.ReasonCode),
+		Reason:     in.Reason,
+	}
+	if in.ReplacedBy != nil {
+		replacedBy := common.Hash(gointerfaces.ConvertH256ToHash(in.ReplacedBy))
+		outcome.ReplacedBy = &replacedBy
+	}
+	return outcome
+}
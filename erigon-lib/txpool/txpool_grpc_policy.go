@@ -0,0 +1,77 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+)
+
+func (s *GrpcServer) GetPolicy(ctx context.Context, _ *emptypb.Empty) (*txpool_proto.PolicyReply, error) {
+	return policyReply(s.txPool.Policy()), nil
+}
+
+func (s *GrpcServer) SetPolicy(ctx context.Context, req *txpool_proto.SetPolicyRequest) (*txpool_proto.PolicyReply, error) {
+	policy := s.txPool.Policy()
+	set := func(dst *uint64, v *uint64) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&policy.PriceBump, req.PriceBump)
+	set(&policy.BlobPriceBump, req.BlobPriceBump)
+	set(&policy.MinFeeCap, req.MinFeeCap)
+	set(&policy.MinTipCap, req.MinTipCap)
+	set(&policy.AccountSlots, req.AccountSlots)
+	set(&policy.BlobSlots, req.BlobSlots)
+	set(&policy.MaxNonceGap, req.MaxNonceGap)
+	if err := s.txPool.SetPolicy(policy); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return policyReply(s.txPool.Policy()), nil
+}
+
+func policyReply(p txpoolcfg.Policy) *txpool_proto.PolicyReply {
+	return &txpool_proto.PolicyReply{
+		PriceBump:     p.PriceBump,
+		BlobPriceBump: p.BlobPriceBump,
+		MinFeeCap:     p.MinFeeCap,
+		MinTipCap:     p.MinTipCap,
+		AccountSlots:  p.AccountSlots,
+		BlobSlots:     p.BlobSlots,
+		MaxNonceGap:   p.MaxNonceGap,
+	}
+}
+
+// PolicyFromReply - txpoolcfg.Policy from reply of GetPolicy/SetPolicy
+func PolicyFromReply(r *txpool_proto.PolicyReply) *txpoolcfg.Policy {
+	return &txpoolcfg.Policy{
+		PriceBump:     r.PriceBump,
+		BlobPriceBump: r.BlobPriceBump,
+		MinFeeCap:     r.MinFeeCap,
+		MinTipCap:     r.MinTipCap,
+		AccountSlots:  r.AccountSlots,
+		BlobSlots:     r.BlobSlots,
+		MaxNonceGap:   r.MaxNonceGap,
+	}
+}
@@ -0,0 +1,102 @@
+/*
+   Copyright 2024 The Erigon contributors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package txpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
+	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+)
+
+// grpcScorerTimeout - Score is called under lock of pool: if external scorer is slow or unavailable,
+// default order is used
+const grpcScorerTimeout = 500 * time.Millisecond
+
+type scorerServer struct {
+	txpool_proto.UnimplementedScorerServer
+	scorer Scorer
+}
+
+// NewScorerServer - serves Scorer over gRPC (`txpool.Scorer` service), see txpool_proto.RegisterScorerServer
+func NewScorerServer(scorer Scorer) txpool_proto.ScorerServer {
+	return &scorerServer{scorer: scorer}
+}
+
+func (s *scorerServer) Score(ctx context.Context, in *txpool_proto.ScoreRequest) (*txpool_proto.ScoreReply, error) {
+	txs := make([]*ScoredTx, len(in.Txs))
+	for i, txn := range in.Txs {
+		txs[i] = &ScoredTx{
+			Hash:    gointerfaces.ConvertH256ToHash(txn.Hash),
+			Sender:  gointerfaces.ConvertH160toAddress(txn.Sender),
+			Nonce:   txn.Nonce,
+			Type:    byte(txn.Type),
+			Gas:     txn.Gas,
+			Size:    txn.Size,
+			Local:   txn.Local,
+			Arrival: txn.Arrival,
+		}
+		txs[i].Tip.Set(gointerfaces.ConvertH256ToUint256Int(txn.Tip))
+		txs[i].FeeCap.Set(gointerfaces.ConvertH256ToUint256Int(txn.FeeCap))
+		txs[i].BlobFeeCap.Set(gointerfaces.ConvertH256ToUint256Int(txn.BlobFeeCap))
+		txs[i].EffectiveTip.Set(gointerfaces.ConvertH256ToUint256Int(txn.EffectiveTip))
+	}
+	return &txpool_proto.ScoreReply{Scores: s.scorer.Score(txs)}, nil
+}
+
+// grpcScorer - client of Scorer service. Returns no scores if service failed - then pool uses default order.
+type grpcScorer struct {
+	client txpool_proto.ScorerClient
+}
+
+func newGrpcScorer(addr string) (*grpcScorer, error) {
+	cc, err := grpcutil.Connect(nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to txpool scorer %s: %w", addr, err)
+	}
+	return &grpcScorer{client: txpool_proto.NewScorerClient(cc)}, nil
+}
+
+func (s *grpcScorer) Score(txs []*ScoredTx) []uint64 {
+	req := &txpool_proto.ScoreRequest{Txs: make([]*txpool_proto.ScoredTx, len(txs))}
+	for i, txn := range txs {
+		req.Txs[i] = &txpool_proto.ScoredTx{
+			Hash:         gointerfaces.ConvertHashToH256(txn.Hash),
+			Sender:       gointerfaces.ConvertAddressToH160(txn.Sender),
+			Nonce:        txn.Nonce,
+			Type:         uint32(txn.Type),
+			Gas:          txn.Gas,
+			Size:         txn.Size,
+			Tip:          gointerfaces.ConvertUint256IntToH256(&txn.Tip),
+			FeeCap:       gointerfaces.ConvertUint256IntToH256(&txn.FeeCap),
+			BlobFeeCap:   gointerfaces.ConvertUint256IntToH256(&txn.BlobFeeCap),
+			EffectiveTip: gointerfaces.ConvertUint256IntToH256(&txn.EffectiveTip),
+			Local:        txn.Local,
+			Arrival:      txn.Arrival,
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), grpcScorerTimeout)
+	defer cancel()
+	reply, err := s.client.Score(ctx, req)
+	if err != nil {
+		return nil
+	}
+	return reply.Scores
+}
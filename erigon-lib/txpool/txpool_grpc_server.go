@@ -59,6 +59,14 @@ type txPool interface {
 	CountContent() (int, int, int)
 	IdHashKnown(tx kv.Tx, hash []byte) (bool, error)
 	NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool)
+	Journaled() [][]byte
+	EvictJournaled(hashes []common.Hash) ([]common.Hash, error)
+	Policy() txpoolcfg.Policy
+	SetPolicy(policy txpoolcfg.Policy) error
+	AddPrivateTxs(ctx context.Context, newTxs types.TxSlots, tx kv.Tx, maxBlockNumber uint64) ([]txpoolcfg.DiscardReason, error)
+	AddConditionalTxs(ctx context.Context, newTxs types.TxSlots, tx kv.Tx, conditions *Conditions) ([]txpoolcfg.DiscardReason, error)
+	SubscribeOutcomes() (<-chan *TxOutcome, func())
+	Analytics(sender *common.Address) *Analytics
 }
 
 var _ txpool_proto.TxpoolServer = (*GrpcServer)(nil)   // compile-time interface check
@@ -97,6 +105,30 @@ func (*GrpcDisabled) Status(ctx context.Context, request *txpool_proto.StatusReq
 func (*GrpcDisabled) Nonce(ctx context.Context, request *txpool_proto.NonceRequest) (*txpool_proto.NonceReply, error) {
 	return nil, ErrPoolDisabled
 }
+func (*GrpcDisabled) ListJournal(ctx context.Context, empty *emptypb.Empty) (*txpool_proto.TransactionsReply, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) EvictJournal(ctx context.Context, hashes *txpool_proto.TxHashes) (*txpool_proto.TxHashes, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) GetPolicy(ctx context.Context, empty *emptypb.Empty) (*txpool_proto.PolicyReply, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) SetPolicy(ctx context.Context, request *txpool_proto.SetPolicyRequest) (*txpool_proto.PolicyReply, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) AddPrivate(ctx context.Context, request *txpool_proto.AddPrivateRequest) (*txpool_proto.AddReply, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) AddConditional(ctx context.Context, request *txpool_proto.AddConditionalRequest) (*txpool_proto.AddReply, error) {
+	return nil, ErrPoolDisabled
+}
+func (*GrpcDisabled) SubscribeOutcomes(request *txpool_proto.OutcomesRequest, server txpool_proto.Txpool_SubscribeOutcomesServer) error {
+	return ErrPoolDisabled
+}
+func (*GrpcDisabled) Analytics(ctx context.Context, request *txpool_proto.AnalyticsRequest) (*txpool_proto.AnalyticsReply, error) {
+	return nil, ErrPoolDisabled
+}
 
 type GrpcServer struct {
 	txpool_proto.UnimplementedTxpoolServer
@@ -104,6 +136,7 @@ type GrpcServer struct {
 	txPool          txPool
 	db              kv.RoDB
 	NewSlotsStreams *NewSlotsStreams
+	UserOps         txpool_proto.UserOpsServer // optional ERC-4337 user operations pool, registered with txpool services
 
 	chainID uint256.Int
 	logger  log.Logger
@@ -175,6 +208,10 @@ func (s *GrpcServer) FindUnknown(ctx context.Context, in *txpool_proto.TxHashes)
 }
 
 func (s *GrpcServer) Add(ctx context.Context, in *txpool_proto.AddRequest) (*txpool_proto.AddReply, error) {
+	return s.add(ctx, in.RlpTxs, s.txPool.AddLocalTxs)
+}
+
+func (s *GrpcServer) add(ctx context.Context, rlpTxs [][]byte, addTxs func(ctx context.Context, newTxs types.TxSlots, tx kv.Tx) ([]txpoolcfg.DiscardReason, error)) (*txpool_proto.AddReply, error) {
 	tx, err := s.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -185,14 +222,14 @@ func (s *GrpcServer) Add(ctx context.Context, in *txpool_proto.AddRequest) (*txp
 	parseCtx := types.NewTxParseContext(s.chainID).ChainIDRequired()
 	parseCtx.ValidateRLP(s.txPool.ValidateSerializedTxn)
 
-	reply := &txpool_proto.AddReply{Imported: make([]txpool_proto.ImportResult, len(in.RlpTxs)), Errors: make([]string, len(in.RlpTxs))}
+	reply := &txpool_proto.AddReply{Imported: make([]txpool_proto.ImportResult, len(rlpTxs)), Errors: make([]string, len(rlpTxs))}
 
-	for i := 0; i < len(in.RlpTxs); i++ {
+	for i := 0; i < len(rlpTxs); i++ {
 		j := len(slots.Txs) // some incoming txs may be rejected, so - need second index
 		slots.Resize(uint(j + 1))
 		slots.Txs[j] = &types.TxSlot{}
 		slots.IsLocal[j] = true
-		if _, err := parseCtx.ParseTransaction(in.RlpTxs[i], 0, slots.Txs[j], slots.Senders.At(j), false /* hasEnvelope */, true /* wrappedWithBlobs */, func(hash []byte) error {
+		if _, err := parseCtx.ParseTransaction(rlpTxs[i], 0, slots.Txs[j], slots.Senders.At(j), false /* hasEnvelope */, true /* wrappedWithBlobs */, func(hash []byte) error {
 			if known, _ := s.txPool.IdHashKnown(tx, hash); known {
 				return types.ErrAlreadyKnown
 			}
@@ -212,7 +249,7 @@ func (s *GrpcServer) Add(ctx context.Context, in *txpool_proto.AddRequest) (*txp
 		}
 	}
 
-	discardReasons, err := s.txPool.AddLocalTxs(ctx, slots, tx)
+	discardReasons, err := addTxs(ctx, slots, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +276,7 @@ func mapDiscardReasonToProto(reason txpoolcfg.DiscardReason) txpool_proto.Import
 		return txpool_proto.ImportResult_ALREADY_EXISTS
 	case txpoolcfg.UnderPriced, txpoolcfg.ReplaceUnderpriced, txpoolcfg.FeeTooLow:
 		return txpool_proto.ImportResult_FEE_TOO_LOW
-	case txpoolcfg.InvalidSender, txpoolcfg.NegativeValue, txpoolcfg.OversizedData, txpoolcfg.InitCodeTooLarge, txpoolcfg.RLPTooLong, txpoolcfg.CreateBlobTxn, txpoolcfg.NoBlobs, txpoolcfg.TooManyBlobs, txpoolcfg.TypeNotActivated, txpoolcfg.UnequalBlobTxExt, txpoolcfg.BlobHashCheckFail, txpoolcfg.UnmatchedBlobTxExt:
+	case txpoolcfg.InvalidSender, txpoolcfg.NegativeValue, txpoolcfg.OversizedData, txpoolcfg.InitCodeTooLarge, txpoolcfg.RLPTooLong, txpoolcfg.CreateBlobTxn, txpoolcfg.NoBlobs, txpoolcfg.TooManyBlobs, txpoolcfg.TypeNotActivated, txpoolcfg.UnequalBlobTxExt, txpoolcfg.BlobHashCheckFail, txpoolcfg.UnmatchedBlobTxExt, txpoolcfg.NonceTooHigh:
 		// TODO(eip-4844) TypeNotActivated may be transient (e.g. a blob transaction is submitted 1 sec prior to Cancun activation)
 		return txpool_proto.ImportResult_INVALID
 	default:
@@ -348,6 +385,14 @@ func (s *NewSlotsStreams) remove(id uint) {
 	delete(s.chans, id)
 }
 
+// RegisterTxpoolServer - registers `txpool.Txpool` service and `txpool.UserOps` service if ERC-4337 pool is enabled
+func RegisterTxpoolServer(s grpc.ServiceRegistrar, txPoolServer txpool_proto.TxpoolServer) {
+	txpool_proto.RegisterTxpoolServer(s, txPoolServer)
+	if grpcServer, ok := txPoolServer.(*GrpcServer); ok && grpcServer.UserOps != nil {
+		txpool_proto.RegisterUserOpsServer(s, grpcServer.UserOps)
+	}
+}
+
 func StartGrpc(txPoolServer txpool_proto.TxpoolServer, miningServer txpool_proto.MiningServer, addr string, creds *credentials.TransportCredentials, logger log.Logger) (*grpc.Server, error) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -388,7 +433,7 @@ func StartGrpc(txPoolServer txpool_proto.TxpoolServer, miningServer txpool_proto
 	grpcServer := grpc.NewServer(opts...)
 	reflection.Register(grpcServer) // Register reflection service on gRPC server.
 	if txPoolServer != nil {
-		txpool_proto.RegisterTxpoolServer(grpcServer, txPoolServer)
+		RegisterTxpoolServer(grpcServer, txPoolServer)
 	}
 	if miningServer != nil {
 		txpool_proto.RegisterMiningServer(grpcServer, miningServer)
@@ -39,12 +39,16 @@ type Config struct {
 	BaseFeeSubPoolLimit int
 	QueuedSubPoolLimit  int
 	MinFeeCap           uint64
+	MinTipCap           uint64 // Minimum tip (priority fee per gas) of non-local txs
 	AccountSlots        uint64 // Number of executable transaction slots guaranteed per account
 	BlobSlots           uint64 // Total number of blobs (not txs) allowed per account
 	TotalBlobPoolLimit  uint64 // Total number of blobs (not txs) allowed within the txpool
 	PriceBump           uint64 // Price bump percentage to replace an already existing transaction
 	BlobPriceBump       uint64 //Price bump percentage to replace an existing 4844 blob tx (type-3)
 
+	TotalBlobPoolBytes datasize.ByteSize // Total size of blob txs (with blobs) allowed within the txpool, 0 - no limit
+	MaxNonceGap        uint64            // Max distance between nonce of non-local txn and nonce of its sender, 0 - no limit
+
 	// regular batch tasks processing
 	SyncToNewPeersEvery   time.Duration
 	ProcessRemoteTxsEvery time.Duration
@@ -57,6 +61,34 @@ type Config struct {
 	MdbxGrowthStep  datasize.ByteSize
 
 	NoGossip bool // this mode doesn't broadcast any txs, and if receive remote-txn - skip it
+
+	// journal of local txs: replayed on restart and re-announced every `RejournalEvery` until mined or expired
+	NoJournal       bool
+	JournalLifetime time.Duration
+	RejournalEvery  time.Duration
+
+	// order of best txs (and txs in mined blocks): "" - by effective tip, name of registered scorer (like "fifo"),
+	// or "grpc://<host>:<port>" - external scorer, see txpool.Scorer
+	Scoring string
+
+	// private txs (not gossiped, not persisted) are discarded if not mined during `PrivateTxBlocks` blocks,
+	// unless sender set other max block number
+	PrivateTxBlocks uint64
+
+	Propagation Propagation
+}
+
+// Propagation - rules of propagation of new txs to peers. Zero value - bodies and announcements are sent to
+// random peers chosen by sentry, see txpool.Send
+type Propagation struct {
+	BroadcastFraction float64  // fraction of peers which receive bodies of new txs, other peers receive announcements
+	PeerTxsPerSecond  uint64   // max txs (bodies and announcements) sent to one peer per second, 0 - no limit
+	DenyPeers         []string // enode URLs or hex public keys of peers which never receive txs from this node
+}
+
+// PeerAware - txs are propagated to peers chosen by txpool, not by sentry
+func (p Propagation) PeerAware() bool {
+	return p.BroadcastFraction > 0 || p.PeerTxsPerSecond > 0 || len(p.DenyPeers) > 0
 }
 
 var DefaultConfig = Config{
@@ -75,8 +107,52 @@ var DefaultConfig = Config{
 	TotalBlobPoolLimit: 480, // Default for a total of 10 different accounts hitting the above limit
 	PriceBump:          10,  // Price bump percentage to replace an already existing transaction
 	BlobPriceBump:      100,
+	TotalBlobPoolBytes: 128 * datasize.MB, // ~2x of TotalBlobPoolLimit blobs
 
 	NoGossip: false,
+
+	JournalLifetime: 3 * time.Hour,
+	RejournalEvery:  time.Minute,
+
+	PrivateTxBlocks: 25,
+}
+
+// Policy - part of Config which can be changed at runtime, without restart of txpool: replacement and pricing rules
+// and per-sender limits. Applied to new txs only: txs which are already in pool are not re-validated.
+type Policy struct {
+	PriceBump     uint64 `json:"priceBump"`     // Price bump percentage to replace an already existing transaction
+	BlobPriceBump uint64 `json:"blobPriceBump"` // Price bump percentage to replace an existing blob (type-3) transaction
+	MinFeeCap     uint64 `json:"minFeeCap"`
+	MinTipCap     uint64 `json:"minTipCap"`
+	AccountSlots  uint64 `json:"accountSlots"`
+	BlobSlots     uint64 `json:"blobSlots"`
+	MaxNonceGap   uint64 `json:"maxNonceGap"`
+}
+
+func (p Policy) Validate() error {
+	if p.AccountSlots == 0 {
+		return fmt.Errorf("accountSlots must be positive")
+	}
+	return nil
+}
+
+func (c Config) Policy() Policy {
+	return Policy{
+		PriceBump:     c.PriceBump,
+		BlobPriceBump: c.BlobPriceBump,
+		MinFeeCap:     c.MinFeeCap,
+		MinTipCap:     c.MinTipCap,
+		AccountSlots:  c.AccountSlots,
+		BlobSlots:     c.BlobSlots,
+		MaxNonceGap:   c.MaxNonceGap,
+	}
+}
+
+func (c *Config) SetPolicy(p Policy) {
+	c.PriceBump, c.BlobPriceBump = p.PriceBump, p.BlobPriceBump
+	c.MinFeeCap, c.MinTipCap = p.MinFeeCap, p.MinTipCap
+	c.AccountSlots, c.BlobSlots = p.AccountSlots, p.BlobSlots
+	c.MaxNonceGap = p.MaxNonceGap
 }
 
 type DiscardReason uint8
@@ -114,6 +190,9 @@ const (
 	UnmatchedBlobTxExt  DiscardReason = 29 // KZGcommitments must match the corresponding blobs and proofs
 	BlobTxReplace       DiscardReason = 30 // Cannot replace type-3 blob txn with another type of txn
 	BlobPoolOverflow    DiscardReason = 31 // The total number of blobs (through blob txs) in the pool has reached its limit
+	NonceTooHigh        DiscardReason = 32 // Nonce of non-local txn is too far ahead of sender's nonce (see Config.MaxNonceGap)
+	PrivateTxExpired    DiscardReason = 33 // Private txn was not mined until its max block number
+	ConditionFailed     DiscardReason = 34 // Conditions of conditional txn (knownAccounts, block and timestamp range) are not met
 
 )
 
@@ -177,6 +256,12 @@ func (r DiscardReason) String() string {
 		return "can't replace blob-txn with a non-blob-txn"
 	case BlobPoolOverflow:
 		return "blobs limit in txpool is full"
+	case NonceTooHigh:
+		return "nonce too high"
+	case PrivateTxExpired:
+		return "private txn expired"
+	case ConditionFailed:
+		return "txn conditions not met"
 	default:
 		panic(fmt.Sprintf("discard reason: %d", r))
 	}
@@ -951,6 +951,37 @@ func DecodeSender(enc []byte) (nonce uint64, balance uint256.Int, err error) {
 	return
 }
 
+// DecodeIncarnation - incarnation of contract from account encoded for storage (same encoding as in DecodeSender)
+func DecodeIncarnation(enc []byte) (incarnation uint64, err error) {
+	if len(enc) == 0 {
+		return 0, nil
+	}
+	var fieldSet = enc[0]
+	var pos = 1
+	for _, field := range []byte{1, 2} { // skip nonce and balance
+		if fieldSet&field == 0 {
+			continue
+		}
+		if len(enc) <= pos {
+			return 0, fmt.Errorf("malformed CBOR for Account: %x", enc)
+		}
+		pos += int(enc[pos]) + 1
+	}
+	if fieldSet&4 > 0 {
+		if len(enc) <= pos {
+			return 0, fmt.Errorf("malformed CBOR for Account: %x", enc)
+		}
+		decodeLength := int(enc[pos])
+		if len(enc) < pos+decodeLength+1 {
+			return 0, fmt.Errorf(
+				"malformed CBOR for Account.Incarnation: %x, Length %d",
+				enc[pos+1:], decodeLength)
+		}
+		incarnation = bytesToUint64(enc[pos+1 : pos+decodeLength+1])
+	}
+	return incarnation, nil
+}
+
 func bytesToUint64(buf []byte) (x uint64) {
 	for i, b := range buf {
 		x = x<<8 + uint64(b)
@@ -38,12 +38,14 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/common/mem"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/erigontech/mdbx-go/mdbx"
 	lru "github.com/hashicorp/golang-lru/arc/v2"
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/log/v3"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -72,8 +74,11 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv/kvcfg"
 	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
 	"github.com/ledgerwatch/erigon-lib/kv/temporal"
+	"github.com/ledgerwatch/erigon-lib/seg"
 	libstate "github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/erigon-lib/tiering"
 	"github.com/ledgerwatch/erigon-lib/txpool"
+	"github.com/ledgerwatch/erigon-lib/txpool/aa"
 	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 	"github.com/ledgerwatch/erigon-lib/txpool/txpooluitl"
 	libtypes "github.com/ledgerwatch/erigon-lib/types"
@@ -86,15 +91,18 @@ import (
 	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
 	"github.com/ledgerwatch/erigon/cmd/caplin/caplin1"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
+	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/consensus/custom"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
 	"github.com/ledgerwatch/erigon/consensus/merge"
 	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/rawdb/blockio"
+	"github.com/ledgerwatch/erigon/core/stateless"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
@@ -103,8 +111,11 @@ import (
 	"github.com/ledgerwatch/erigon/eth/ethconsensusconfig"
 	"github.com/ledgerwatch/erigon/eth/ethutils"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	snapprotocol "github.com/ledgerwatch/erigon/eth/protocols/snap"
+	witprotocol "github.com/ledgerwatch/erigon/eth/protocols/wit"
 	"github.com/ledgerwatch/erigon/eth/stagedsync"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/eth/userops"
 	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/ethdb/prune"
 	"github.com/ledgerwatch/erigon/ethstats"
@@ -121,12 +132,14 @@ import (
 	polygonsync "github.com/ledgerwatch/erigon/polygon/sync"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/builder"
+	erigoncli "github.com/ledgerwatch/erigon/turbo/cli"
 	"github.com/ledgerwatch/erigon/turbo/engineapi"
 	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_block_downloader"
 	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_helpers"
 	"github.com/ledgerwatch/erigon/turbo/execution/eth1"
 	"github.com/ledgerwatch/erigon/turbo/execution/eth1/eth1_chain_reader.go"
 	"github.com/ledgerwatch/erigon/turbo/jsonrpc"
+	"github.com/ledgerwatch/erigon/turbo/reload"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/turbo/shards"
 	"github.com/ledgerwatch/erigon/turbo/silkworm"
@@ -165,6 +178,7 @@ type Ethereum struct {
 
 	ethBackendRPC      *privateapi.EthBackendServer
 	engineBackendRPC   *engineapi.EngineServer
+	blobsReader        jsonrpc.BlobsReader // nil - if embedded Caplin is not enabled
 	miningRPC          txpoolproto.MiningServer
 	stateChangesClient txpool.StateChangesClient
 
@@ -176,6 +190,7 @@ type Ethereum struct {
 	sentryCancel   context.CancelFunc
 	sentriesClient *sentry_multi_client.MultiClient
 	sentryServers  []*sentry.GrpcServer
+	witHandler     *witprotocol.Handler
 
 	stagedSync         *stagedsync.Sync
 	pipelineStagedSync *stagedsync.Sync
@@ -190,6 +205,7 @@ type Ethereum struct {
 
 	waitForStageLoopStop chan struct{}
 	waitForMiningStop    chan struct{}
+	waitForTxPoolStop    chan struct{} // txpool persists itself on stop, its db must be closed after that
 
 	txPoolDB                kv.RwDB
 	txPool                  *txpool.TxPool
@@ -197,6 +213,7 @@ type Ethereum struct {
 	txPoolFetch             *txpool.Fetch
 	txPoolSend              *txpool.Send
 	txPoolGrpcServer        txpoolproto.TxpoolServer
+	userOpsPool             *aa.Pool
 	notifyMiningAboutNewTxs chan struct{}
 	forkValidator           *engine_helpers.ForkValidator
 	downloader              *downloader.Downloader
@@ -261,12 +278,16 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if err != nil {
 			return err
 		}
+		config.Snapshot.ExpiryBlock = config.Prune.ExpiryBlock()
 
 		config.HistoryV3, err = kvcfg.HistoryV3.WriteOnce(tx, config.HistoryV3)
 		return err
 	}); err != nil {
 		return nil, err
 	}
+	for _, d := range config.Prune.Degraded() {
+		logger.Warn("[prune] RPC methods can't serve pruned data of old blocks", "data", d.Data, "retention", d.Retention, "methods", strings.Join(d.Methods, ", "))
+	}
 
 	ctx, ctxCancel := context.WithCancel(context.Background())
 
@@ -355,7 +376,11 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		chainKv = backend.chainDB //nolint
 	}
 
-	if err := backend.setUpSnapDownloader(ctx, config.Downloader); err != nil {
+	downloaderCreds, err := grpcutil.ClientTLS(stack.Config().GrpcTLS(stack.Config().DownloaderTLSAllowedCNs))
+	if err != nil {
+		return nil, fmt.Errorf("downloader tls: %w", err)
+	}
+	if err := backend.setUpSnapDownloader(ctx, config.Downloader, downloaderCreds); err != nil {
 		return nil, err
 	}
 
@@ -379,8 +404,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	p2pConfig := stack.Config().P2P
 	var sentries []direct.SentryClient
 	if len(p2pConfig.SentryAddr) > 0 {
+		sentryCreds, err := grpcutil.ClientTLS(stack.Config().GrpcTLS(stack.Config().SentryTLSAllowedCNs))
+		if err != nil {
+			return nil, fmt.Errorf("sentry tls: %w", err)
+		}
 		for _, addr := range p2pConfig.SentryAddr {
-			sentryClient, err := sentry_multi_client.GrpcClient(backend.sentryCtx, addr)
+			sentryClient, err := sentry_multi_client.GrpcClient(backend.sentryCtx, addr, sentryCreds)
 			if err != nil {
 				return nil, err
 			}
@@ -414,7 +443,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		silkwormSentryService := silkworm.NewSentryService(backend.silkworm, settings)
 		backend.silkwormSentryService = &silkwormSentryService
 
-		sentryClient, err := sentry_multi_client.GrpcClient(backend.sentryCtx, apiAddr)
+		sentryClient, err := sentry_multi_client.GrpcClient(backend.sentryCtx, apiAddr, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -443,6 +472,17 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			return nil, err
 		}
 
+		if config.WitServe || config.WitFetch {
+			var generate witprotocol.GenerateFunc
+			if config.WitServe {
+				generate = func(ctx context.Context, tx kv.Tx, hash libcommon.Hash) (*stateless.Witness, error) {
+					// The consensus engine is created after the sentries
+					return witprotocol.NewGenerator(backend.chainConfig, backend.engine, blockReader, config.Dirs, config.HistoryV3, logger).Generate(ctx, tx, hash)
+				}
+			}
+			backend.witHandler = witprotocol.NewHandler(backend.sentryCtx, backend.chainDB, generate, logger)
+		}
+
 		var pi int // points to next port to be picked from refCfg.AllowedPorts
 		for _, protocol := range p2pConfig.ProtocolVersion {
 			cfg := p2pConfig
@@ -475,6 +515,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 
 			cfg.ListenAddr = fmt.Sprintf("%s:%d", listenHost, listenPort)
 			server := sentry.NewGrpcServer(backend.sentryCtx, discovery, readNodeInfo, &cfg, protocol, logger)
+			if config.SnapServe {
+				server.Protocols = append(server.Protocols, snapprotocol.MakeProtocol(backend.sentryCtx, backend.chainDB, config.HistoryV3, logger))
+			}
+			if backend.witHandler != nil {
+				server.Protocols = append(server.Protocols, backend.witHandler.Protocol())
+			}
 			backend.sentryServers = append(backend.sentryServers, server)
 			sentries = append(sentries, direct.NewSentryClientDirect(protocol, server))
 		}
@@ -510,6 +556,14 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	// setup periodic logging and prometheus updates
 	go mem.LogMemStats(ctx, logger)
 	go disk.UpdateDiskStats(ctx, logger)
+	if config.DiskIOProfile > 0 {
+		go disk.Profile(ctx, config.DiskIOProfile, logger)
+	}
+	go disk.GuardFreeSpace(ctx, disk.GuardCfg{
+		Dirs:  []string{config.Dirs.DataDir, config.Dirs.Tmp},
+		Pause: config.DiskFreePause,
+		Halt:  config.DiskFreeHalt,
+	}, logger)
 
 	var currentBlock *types.Block
 	if err := backend.chainDB.View(context.Background(), func(tx kv.Tx) error {
@@ -583,6 +637,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		chainConfig,
 		genesis,
 		backend.config.NetworkID,
+		config.Prune.ExpiryBlock(),
 	)
 
 	// limit "new block" broadcasts to at most 10 random peers at time
@@ -623,6 +678,14 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	if err != nil {
 		return nil, err
 	}
+	sentryPolicy, err := direct.ParseSentryPolicy(p2pConfig.SentryPolicy)
+	if err != nil {
+		return nil, err
+	}
+	sentryBalancerCfg := direct.DefaultSentryBalancerCfg
+	sentryBalancerCfg.Policy, sentryBalancerCfg.Tags, sentryBalancerCfg.PreferredTag = sentryPolicy, p2pConfig.SentryTags, p2pConfig.SentryPreferredTag
+	sentryBalancer := direct.NewSentryBalancer(sentries, sentryBalancerCfg)
+	backend.sentriesClient.SetSentryBalancer(sentryBalancer)
 
 	config.TxPool.NoGossip = config.DisableTxPoolGossip
 	var miningRPC txpoolproto.MiningServer
@@ -642,6 +705,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if err != nil {
 			return nil, err
 		}
+		backend.txPoolSend.SetBalancer(sentryBalancer)
+		if len(config.UserOps.EntryPoints) > 0 {
+			simulator := userops.NewSimulator(chainKv, blockReader, backend.engine, chainConfig, config.HistoryV3)
+			backend.userOpsPool = aa.New(config.UserOps, *uint256.MustFromBig(chainConfig.ChainID), simulator, logger)
+			backend.txPoolGrpcServer.(*txpool.GrpcServer).UserOps = aa.NewGrpcServer(backend.userOpsPool)
+		}
 	}
 
 	backend.notifyMiningAboutNewTxs = make(chan struct{}, 1)
@@ -689,7 +758,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				stages2.SilkwormForExecutionStage(backend.silkworm, config),
 			),
 			stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd, loopBreakCheck),
-			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil, 0, backend.txPool, backend.txPoolDB, blockReader),
+			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil, 0, backend.txPool, backend.txPoolDB, blockReader, nil),
 			stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miner, backend.miningSealingQuit, backend.blockReader, latestBlockBuiltStore),
 		), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder,
 		logger)
@@ -729,7 +798,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 					stages2.SilkwormForExecutionStage(backend.silkworm, config),
 				),
 				stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd, loopBreakCheck),
-				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt, param.PayloadId, backend.txPool, backend.txPoolDB, blockReader),
+				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt, param.PayloadId, backend.txPool, backend.txPoolDB, blockReader, nil),
 				stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miningStatePos, backend.miningSealingQuit, backend.blockReader, latestBlockBuiltStore)), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder, logger)
 		// We start the mining step
 		if err := stages2.MiningStep(ctx, backend.chainDB, proposingSync, tmpdir, logger); err != nil {
@@ -746,17 +815,16 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	blockSnapBuildSema := semaphore.NewWeighted(int64(dbg.BuildSnapshotAllowance))
 
 	agg.SetSnapshotBuildSema(blockSnapBuildSema)
+	seg.SetWriteLimit(config.Sync.StageThrottle[string(stages.Snapshots)].IO) // retire and merge of files
 	blockRetire := freezeblocks.NewBlockRetire(1, dirs, blockReader, blockWriter, backend.chainDB, backend.chainConfig, backend.notifications.Events, blockSnapBuildSema, logger)
 
 	miningRPC = privateapi.NewMiningServer(ctx, backend, ethashApi, logger)
 
 	var creds credentials.TransportCredentials
 	if stack.Config().PrivateApiAddr != "" {
-		if stack.Config().TLSConnection {
-			creds, err = grpcutil.TLS(stack.Config().TLSCACert, stack.Config().TLSCertFile, stack.Config().TLSKeyFile)
-			if err != nil {
-				return nil, err
-			}
+		creds, err = grpcutil.ServerTLS(stack.Config().GrpcTLS(stack.Config().TLSAllowedCNs))
+		if err != nil {
+			return nil, err
 		}
 		backend.privateAPI, err = privateapi.StartGrpc(
 			kvRPC,
@@ -788,14 +856,25 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if casted, ok := backend.txPoolGrpcServer.(*txpool.GrpcServer); ok {
 			newTxsBroadcaster = casted.NewSlotsStreams
 		}
-		go txpool.MainLoop(backend.sentryCtx,
-			backend.txPoolDB, backend.txPool, backend.newTxs, backend.txPoolSend, newTxsBroadcaster,
-			func() {
-				select {
-				case backend.notifyMiningAboutNewTxs <- struct{}{}:
-				default:
-				}
-			})
+		backend.waitForTxPoolStop = make(chan struct{})
+		go func() {
+			defer close(backend.waitForTxPoolStop)
+			txpool.MainLoop(backend.sentryCtx,
+				backend.txPoolDB, backend.txPool, backend.newTxs, backend.txPoolSend, newTxsBroadcaster,
+				func() {
+					select {
+					case backend.notifyMiningAboutNewTxs <- struct{}{}:
+					default:
+					}
+				})
+		}()
+		if backend.userOpsPool != nil {
+			headCh, unsubscribe := backend.notifications.Events.AddHeaderSubscription()
+			go func() {
+				defer unsubscribe()
+				userops.HeadLoop(backend.sentryCtx, backend.userOpsPool, headCh, logger)
+			}()
+		}
 	}
 
 	go func() {
@@ -897,6 +976,10 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	backend.pipelineStagedSync = stagedsync.New(config.Sync, pipelineStages, stagedsync.PipelineUnwindOrder, stagedsync.PipelinePruneOrder, logger)
 	backend.eth1ExecutionServer = eth1.NewEthereumExecutionModule(blockReader, backend.chainDB, backend.pipelineStagedSync, backend.forkValidator, chainConfig, assembleBlockPOS, hook, backend.notifications.Accumulator, backend.notifications.StateChangesConsumer, logger, backend.engine, config.HistoryV3, config.Sync, ctx)
 	executionRpc := direct.NewExecutionClientDirect(backend.eth1ExecutionServer)
+	externalBuilders, err := engineapi.NewExternalBuilders(&config.Miner, chainConfig, logger)
+	if err != nil {
+		return nil, err
+	}
 	engineBackendRPC := engineapi.NewEngineServer(
 		logger,
 		chainConfig,
@@ -906,9 +989,22 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			logger, backend.sentriesClient.Hd, executionRpc,
 			backend.sentriesClient.Bd, backend.sentriesClient.BroadcastNewBlock, backend.sentriesClient.SendBodyRequest, blockReader,
 			backend.chainDB, chainConfig, tmpdir, config.Sync),
+		externalBuilders,
 		config.InternalCL,
 		false,
 		config.Miner.EnabledPOS)
+	if config.AsyncValidation {
+		engineBackendRPC.EnableAsyncValidation(ctx, config.AsyncValidationWait)
+	}
+	if config.EngineWitness {
+		// On HistoryV3 databases the state is proven from the commitment trie
+		engineBackendRPC.EnableWitnesses(witprotocol.NewGenerator(chainConfig, backend.engine, blockReader, config.Dirs, config.HistoryV3, logger).Generate)
+	}
+	if config.EngineRecordFile != "" {
+		if err := engineBackendRPC.EnableRecording(config.EngineRecordFile); err != nil {
+			return nil, err
+		}
+	}
 	backend.engineBackendRPC = engineBackendRPC
 
 	var executionEngine executionclient.ExecutionEngine
@@ -936,8 +1032,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if err != nil {
 			return nil, err
 		}
-		state, err := clcore.RetrieveBeaconState(ctx, beaconCfg,
-			clparams.GetCheckpointSyncEndpoint(clparams.NetworkType(config.NetworkID)))
+		checkpointSyncUrls := config.CaplinConfig.CheckpointSyncUrls
+		if len(checkpointSyncUrls) == 0 {
+			checkpointSyncUrls = clparams.GetCheckpointSyncEndpoints(clparams.NetworkType(config.NetworkID))
+		}
+		state, err := clcore.RetrieveVerifiedBeaconState(ctx, beaconCfg, checkpointSyncUrls,
+			int(config.CaplinConfig.CheckpointSyncQuorum), config.CaplinConfig.CheckpointSyncRoot)
 		if err != nil {
 			return nil, err
 		}
@@ -952,10 +1052,12 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if err != nil {
 			return nil, err
 		}
+		csn := freezeblocks.NewCaplinSnapshots(ethconfig.BlocksFreezing{BeaconStates: config.Snapshot.BeaconStates, BlobsArchive: config.Snapshot.BlobsArchive}, beaconCfg, dirs, logger)
+		backend.blobsReader = freezeblocks.NewBlobsArchiveReader(indiciesDB, blobStorage, csn, ethClock)
 
 		go func() {
 			eth1Getter := getters.NewExecutionSnapshotReader(ctx, beaconCfg, blockReader, backend.chainDB)
-			if err := caplin1.RunCaplinPhase1(ctx, executionEngine, config, networkCfg, beaconCfg, ethClock, state, dirs, eth1Getter, backend.downloaderClient, config.CaplinConfig.Backfilling, config.CaplinConfig.BlobBackfilling, config.CaplinConfig.Archive, indiciesDB, blobStorage, creds, blockSnapBuildSema); err != nil {
+			if err := caplin1.RunCaplinPhase1(ctx, executionEngine, config, networkCfg, beaconCfg, ethClock, state, dirs, eth1Getter, backend.downloaderClient, config.CaplinConfig.Backfilling, config.CaplinConfig.BlobBackfilling, config.CaplinConfig.Archive, indiciesDB, blobStorage, csn, creds, blockSnapBuildSema); err != nil {
 				logger.Error("could not start caplin", "err", err)
 			}
 			ctxCancel()
@@ -1041,7 +1143,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 		}
 	}
 
-	s.apiList = jsonrpc.APIList(chainKv, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, s.agg, &httpRpcCfg, s.engine, s.logger)
+	s.apiList = jsonrpc.APIList(chainKv, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, s.agg, &httpRpcCfg, s.engine, s.blobsReader, s.logger)
 
 	if config.SilkwormRpcDaemon && httpRpcCfg.Enabled {
 		interface_log_settings := silkworm.RpcInterfaceLogSettings{
@@ -1075,7 +1177,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 	}
 
 	if chainConfig.Bor == nil {
-		go s.engineBackendRPC.Start(ctx, &httpRpcCfg, s.chainDB, s.blockReader, ff, stateCache, s.agg, s.engine, ethRpcClient, txPoolRpcClient, miningRpcClient)
+		go s.engineBackendRPC.Start(ctx, &httpRpcCfg, s.chainDB, s.blockReader, ff, stateCache, s.agg, s.engine, ethRpcClient, txPoolRpcClient, miningRpcClient, s.blobsReader)
 	}
 
 	// Register the backend on the node
@@ -1222,6 +1324,25 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 		})
 	}
 
+	var customEngine *custom.Engine
+	if c, ok := s.engine.(*custom.Engine); ok {
+		customEngine = c
+	} else if cl, ok := s.engine.(*merge.Merge); ok {
+		if c, ok := cl.InnerEngine().(*custom.Engine); ok {
+			customEngine = c
+		}
+	}
+	if customEngine != nil {
+		if miner.MiningConfig.SigKey == nil {
+			s.logger.Error("Etherbase account unavailable locally", "err", err)
+			return fmt.Errorf("signer missing: %w", err)
+		}
+
+		customEngine.Authorize(eb, func(_ libcommon.Address, mimeType string, message []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(message), miner.MiningConfig.SigKey)
+		})
+	}
+
 	streamCtx, streamCancel := context.WithCancel(ctx)
 	stream, err := stateDiffClient.StateChanges(streamCtx, &remote.StateChangeRequest{WithStorage: false, WithTransactions: true}, grpc.WaitForReady(true))
 
@@ -1387,14 +1508,14 @@ func (s *Ethereum) NodesInfo(limit int) (*remote.NodesInfoReply, error) {
 }
 
 // sets up blockReader and client downloader
-func (s *Ethereum) setUpSnapDownloader(ctx context.Context, downloaderCfg *downloadercfg.Cfg) error {
+func (s *Ethereum) setUpSnapDownloader(ctx context.Context, downloaderCfg *downloadercfg.Cfg, creds credentials.TransportCredentials) error {
 	var err error
 	if s.config.Snapshot.NoDownloader {
 		return nil
 	}
 	if s.config.Snapshot.DownloaderAddr != "" {
 		// connect to external Downloader
-		s.downloaderClient, err = downloadergrpc.NewClient(ctx, s.config.Snapshot.DownloaderAddr)
+		s.downloaderClient, err = downloadergrpc.NewClient(ctx, s.config.Snapshot.DownloaderAddr, creds)
 	} else {
 		// start embedded Downloader
 		if uploadFs := s.config.Sync.UploadLocation; len(uploadFs) > 0 {
@@ -1407,6 +1528,7 @@ func (s *Ethereum) setUpSnapDownloader(ctx context.Context, downloaderCfg *downl
 			return err
 		}
 		s.downloader.MainLoopInBackground(true)
+		registerBandwidthReload(s.downloader)
 		bittorrentServer, err := downloader.NewGrpcServer(s.downloader)
 		if err != nil {
 			return fmt.Errorf("new server: %w", err)
@@ -1432,6 +1554,33 @@ func (s *Ethereum) setUpSnapDownloader(ctx context.Context, downloaderCfg *downl
 	return err
 }
 
+// registerBandwidthReload - torrent rates of embedded Downloader changed by config reload (see turbo/reload)
+func registerBandwidthReload(d *downloader.Downloader) {
+	parse := func(value string) (rate.Limit, error) {
+		var r datasize.ByteSize
+		if err := r.UnmarshalText([]byte(value)); err != nil {
+			return 0, err
+		}
+		return downloadercfg.RateLimit(r), nil
+	}
+	reload.Register(utils.TorrentDownloadRateFlag.Name, func(value string) error {
+		limit, err := parse(value)
+		if err != nil {
+			return err
+		}
+		d.SetBandwidthLimits(&limit, nil)
+		return nil
+	})
+	reload.Register(utils.TorrentUploadRateFlag.Name, func(value string) error {
+		limit, err := parse(value)
+		if err != nil {
+			return err
+		}
+		d.SetBandwidthLimits(nil, &limit)
+		return nil
+	})
+}
+
 func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConfig *ethconfig.Config, histV3 bool, isBor bool, logger log.Logger) (services.FullBlockReader, *blockio.BlockWriter, *freezeblocks.RoSnapshots, *freezeblocks.BorRoSnapshots, *libstate.Aggregator, error) {
 	var minFrozenBlock uint64
 
@@ -1440,6 +1589,12 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 			minFrozenBlock = maxSeedable - frozenLimit
 		}
 	}
+	// block files older than profile's min block are not downloaded: snapshots must start from it (no gaps)
+	if profile, err := snapcfg.ParseDownloadProfile(snConfig.Snapshot.DownloadProfile); err == nil {
+		if profileMin := snapcfg.KnownCfg(snConfig.Genesis.Config.ChainName).Preverified.ProfileMinBlock(profile); profileMin > minFrozenBlock {
+			minFrozenBlock = profileMin
+		}
+	}
 
 	allSnapshots := freezeblocks.NewRoSnapshots(snConfig.Snapshot, dirs.Snap, minFrozenBlock, logger)
 
@@ -1476,29 +1631,114 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 	return blockReader, blockWriter, allSnapshots, allBorSnapshots, agg, nil
 }
 
+// FetchWitness fetches the execution witness of a block from the peers
+// running the wit/0 protocol. The witness is not validated.
+func (s *Ethereum) FetchWitness(ctx context.Context, hash libcommon.Hash) (*stateless.Witness, error) {
+	if s.witHandler == nil {
+		return nil, errors.New("witness fetching is not enabled, see --p2p.wit.fetch")
+	}
+	return s.witHandler.FetchWitness(ctx, hash)
+}
+
+// sentriesDo calls f on all connected sentries. It succeeds if f succeeds on any of them, failures of the others
+// are logged, so a single unavailable sentry doesn't break peer management of the rest.
+func (s *Ethereum) sentriesDo(method string, f func(sc direct.SentryClient) error) error {
+	var errs []error
+	succeeded := 0
+	for _, sc := range s.sentriesClient.Sentries() {
+		if !sc.Ready() {
+			continue
+		}
+		if err := f(sc); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		succeeded++
+	}
+	if succeeded == 0 {
+		if len(errs) == 0 {
+			return fmt.Errorf("ethereum backend MultiClient.%s error: no sentries are connected", method)
+		}
+		return fmt.Errorf("ethereum backend MultiClient.%s error: %w", method, errors.Join(errs...))
+	}
+	if len(errs) > 0 {
+		s.logger.Warn("[p2p] peer management failed on some sentries", "method", method, "failed", len(errs), "succeeded", succeeded, "err", errors.Join(errs...))
+	}
+	return nil
+}
+
 func (s *Ethereum) Peers(ctx context.Context) (*remote.PeersReply, error) {
 	var reply remote.PeersReply
-	for _, sentryClient := range s.sentriesClient.Sentries() {
-		peers, err := sentryClient.Peers(ctx, &emptypb.Empty{})
+	err := s.sentriesDo("Peers", func(sc direct.SentryClient) error {
+		peers, err := sc.Peers(ctx, &emptypb.Empty{})
 		if err != nil {
-			return nil, fmt.Errorf("ethereum backend MultiClient.Peers error: %w", err)
+			return err
 		}
 		reply.Peers = append(reply.Peers, peers.Peers...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return &reply, nil
 }
 
 func (s *Ethereum) AddPeer(ctx context.Context, req *remote.AddPeerRequest) (*remote.AddPeerReply, error) {
-	for _, sentryClient := range s.sentriesClient.Sentries() {
-		_, err := sentryClient.AddPeer(ctx, &protosentry.AddPeerRequest{Url: req.Url})
-		if err != nil {
-			return nil, fmt.Errorf("ethereum backend MultiClient.AddPeers error: %w", err)
-		}
+	err := s.sentriesDo("AddPeer", func(sc direct.SentryClient) error {
+		_, err := sc.AddPeer(ctx, &protosentry.AddPeerRequest{Url: req.Url})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &remote.AddPeerReply{Success: true}, nil
 }
 
+func (s *Ethereum) RemovePeer(ctx context.Context, req *remote.RemovePeerRequest) (*remote.RemovePeerReply, error) {
+	err := s.sentriesDo("RemovePeer", func(sc direct.SentryClient) error {
+		_, err := sc.RemovePeer(ctx, &protosentry.RemovePeerRequest{Url: req.Url})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &remote.RemovePeerReply{Success: true}, nil
+}
+
+func (s *Ethereum) AddTrustedPeer(ctx context.Context, req *remote.AddTrustedPeerRequest) (*remote.AddTrustedPeerReply, error) {
+	err := s.sentriesDo("AddTrustedPeer", func(sc direct.SentryClient) error {
+		_, err := sc.AddTrustedPeer(ctx, &protosentry.AddTrustedPeerRequest{Url: req.Url})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &remote.AddTrustedPeerReply{Success: true}, nil
+}
+
+func (s *Ethereum) RemoveTrustedPeer(ctx context.Context, req *remote.RemoveTrustedPeerRequest) (*remote.RemoveTrustedPeerReply, error) {
+	err := s.sentriesDo("RemoveTrustedPeer", func(sc direct.SentryClient) error {
+		_, err := sc.RemoveTrustedPeer(ctx, &protosentry.RemoveTrustedPeerRequest{Url: req.Url})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &remote.RemoveTrustedPeerReply{Success: true}, nil
+}
+
+// ReloadPeerFiles makes all sentries reload static and trusted peers files, see --staticpeers.file
+func (s *Ethereum) ReloadPeerFiles(ctx context.Context, _ *remote.ReloadPeerFilesRequest) (*remote.ReloadPeerFilesReply, error) {
+	err := s.sentriesDo("ReloadPeerFiles", func(sc direct.SentryClient) error {
+		_, err := sc.ReloadPeerFiles(ctx, &protosentry.ReloadPeerFilesRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &remote.ReloadPeerFilesReply{Success: true}, nil
+}
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -1525,6 +1765,7 @@ func (s *Ethereum) Start() error {
 		return currentTD
 	}
 
+	pruneSync := s.pipelineStagedSync // stages are pruned by pipeline which executes blocks: execution module or sync loop
 	if params.IsChainPoS(s.chainConfig, currentTDProvider) {
 		s.waitForStageLoopStop = nil // TODO: Ethereum.Stop should wait for execution_server shutdown
 		go s.eth1ExecutionServer.Start(s.sentryCtx)
@@ -1545,6 +1786,26 @@ func (s *Ethereum) Start() error {
 		}()
 	} else {
 		go stages2.StageLoop(s.sentryCtx, s.chainDB, s.stagedSync, s.sentriesClient.Hd, s.waitForStageLoopStop, s.config.Sync.LoopThrottle, s.logger, s.blockReader, hook)
+		pruneSync = s.stagedSync
+	}
+
+	if pruner := pruneSync.BackgroundPruner(s.chainDB); pruner != nil {
+		reload.Register(erigoncli.SyncPruneIntervalFlag.Name, func(value string) error {
+			interval, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			return pruner.SetInterval(interval)
+		})
+		go pruner.Run(s.sentryCtx)
+	}
+
+	if s.config.Snapshot.ColdDir != "" {
+		coldTier, err := tiering.New(s.config.Dirs, s.config.Snapshot.ColdDir, s.config.Snapshot.ColdPolicy, s.logger)
+		if err != nil {
+			return err
+		}
+		go coldTier.Run(s.sentryCtx, tiering.DefaultInterval)
 	}
 
 	if s.chainConfig.Bor != nil {
@@ -1588,6 +1849,9 @@ func (s *Ethereum) Stop() error {
 		case <-shutdownDone:
 		}
 	}
+	if s.engineBackendRPC != nil {
+		s.engineBackendRPC.Stop()
+	}
 	libcommon.SafeClose(s.sentriesClient.Hd.QuitPoWMining)
 	_ = s.engine.Close()
 	if s.waitForStageLoopStop != nil {
@@ -1599,6 +1863,9 @@ func (s *Ethereum) Stop() error {
 	for _, sentryServer := range s.sentryServers {
 		sentryServer.Close()
 	}
+	if s.waitForTxPoolStop != nil {
+		<-s.waitForTxPoolStop
+	}
 	if s.txPoolDB != nil {
 		s.txPoolDB.Close()
 	}
@@ -18,11 +18,13 @@
 package ethconfig
 
 import (
+	"fmt"
 	"math/big"
 	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +34,8 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
+	"github.com/ledgerwatch/erigon-lib/tiering"
+	"github.com/ledgerwatch/erigon-lib/txpool/aa"
 	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 	"github.com/ledgerwatch/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/ledgerwatch/erigon/cl/clparams"
@@ -76,9 +80,11 @@ var Defaults = Config{
 		ExecWorkerCount:            estimate.ReconstituteState.WorkersHalf(), //only half of CPU, other half will spend for snapshots build/merge/prune
 		ReconWorkerCount:           estimate.ReconstituteState.Workers(),
 		BodyCacheLimit:             256 * 1024 * 1024,
+		BodiesResponseLimit:        64 * 1024 * 1024,
 		BodyDownloadTimeoutSeconds: 2,
 		//LoopBlockLimit:             100_000,
-		PruneLimit: 100,
+		PruneLimit:   100,
+		SendersTrust: SendersTrustSnapshots,
 	},
 	Ethash: ethashcfg.Config{
 		CachesInMem:      2,
@@ -93,9 +99,12 @@ var Defaults = Config{
 		GasLimit: 30_000_000,
 		GasPrice: big.NewInt(params.GWei),
 		Recommit: 3 * time.Second,
+
+		BuilderTimeout: time.Second,
 	},
 	DeprecatedTxPool: DeprecatedDefaultTxPoolConfig,
 	TxPool:           txpoolcfg.DefaultConfig,
+	UserOps:          aa.DefaultConfig,
 	RPCGasCap:        50000000,
 	GPO:              FullNodeGPO,
 	RPCTxFeeCap:      1, // 1 ether
@@ -141,6 +150,23 @@ type BlocksFreezing struct {
 	NoDownloader   bool // possible to use snapshots without calling Downloader
 	Verify         bool // verify snapshots on startup
 	DownloaderAddr string
+	// DownloadProfile - name of snapcfg.DownloadProfile: which files of preverified list to download. Empty - all files
+	DownloadProfile string
+	// BeaconStates - download and produce snapshots of beacon states. They are big: off by default
+	BeaconStates bool
+	// Receipts - produce and download snapshots of receipts: receipts of ancient blocks are served without re-execution.
+	// Produced from receipts in db: blocks pruned by --prune.r are skipped
+	Receipts bool
+	// BlobsArchive - keep blob sidecars after ~18 days retention window: in blob sidecars snapshots, downloaded from webseeds
+	// and produced by Caplin. Served by engine_getBlobsV1 and eth_getBlobSidecars
+	BlobsArchive bool
+	// ExpiryBlock - EIP-4444 history expiry (--prune=e): transactions and receipts files of blocks before it are
+	// not downloaded and not required to open snapshots, used if present. Set from prune mode, 0 - off
+	ExpiryBlock uint64
+	// ColdDir - cold storage tier: object storage mounted by rclone/s3fs/gcsfuse. Frozen files are moved there by
+	// ColdPolicy (replaced by symlinks), files pinned by `erigon snapshots pin` stay local. Empty - off
+	ColdDir    string
+	ColdPolicy tiering.Policy
 }
 
 func (s BlocksFreezing) String() string {
@@ -154,12 +180,22 @@ func (s BlocksFreezing) String() string {
 	if !s.Produce {
 		out = append(out, "--"+FlagSnapStop+"=true")
 	}
+	if s.DownloadProfile != "" {
+		out = append(out, "--"+FlagSnapDownloadProfile+"="+s.DownloadProfile)
+	}
+	if s.Receipts {
+		out = append(out, "--"+FlagSnapReceipts+"=true")
+	}
 	return strings.Join(out, " ")
 }
 
 var (
 	FlagSnapKeepBlocks = "snap.keepblocks"
 	FlagSnapStop       = "snap.stop"
+
+	FlagSnapDownloadProfile = "snap.download.profile"
+	FlagSnapReceipts        = "snap.receipts"
+	FlagSnapColdDir         = "snap.cold.dir"
 )
 
 func NewSnapCfg(enabled, keepBlocks, produce bool) BlocksFreezing {
@@ -184,6 +220,9 @@ type Config struct {
 	Prune     prune.Mode
 	BatchSize datasize.ByteSize // Batch size for execution stage
 
+	// Logs of these addresses are indexed and retained forever, regardless of Prune.LogIndex (--prune.logs.keep)
+	PruneKeepLogsOf []common.Address
+
 	ImportMode bool
 
 	BadBlockHash common.Hash // hash of the block marked as bad
@@ -214,6 +253,7 @@ type Config struct {
 	// Transaction pool options
 	DeprecatedTxPool DeprecatedTxPoolConfig
 	TxPool           txpoolcfg.Config
+	UserOps          aa.Config // ERC-4337 user operations pool, disabled if no entry points
 
 	// Gas Price Oracle options
 	GPO gaspricecfg.Config
@@ -249,6 +289,14 @@ type Config struct {
 	SentinelAddr           string
 	SentinelPort           uint64
 
+	// engine_newPayload answers SYNCING if validation takes longer than AsyncValidationWait and validates in background
+	AsyncValidation     bool
+	AsyncValidationWait time.Duration
+	// EngineWitness enables generation of execution witnesses of new heads, served by debug_executionWitness of engine API
+	EngineWitness bool
+	// EngineRecordFile - file engine_newPayload and engine_forkchoiceUpdated calls are appended to, empty - no recording
+	EngineRecordFile string
+
 	OverridePragueTime *big.Int `toml:",omitempty"`
 
 	// Embedded Silkworm support
@@ -266,6 +314,21 @@ type Config struct {
 	SilkwormRpcJsonCompatibility bool
 
 	DisableTxPoolGossip bool
+
+	// SnapServe enables serving the snap/1 protocol from the latest state
+	SnapServe bool
+
+	// WitServe enables serving block execution witnesses over the wit/0 protocol
+	WitServe bool
+	// WitFetch enables the wit/0 protocol to fetch block execution witnesses from peers
+	WitFetch bool
+
+	// DiskIOProfile - interval of attribution of page faults to subsystems (domains, snapshots, mdbx, torrent), 0 - disabled
+	DiskIOProfile time.Duration
+	// DiskFreePause/DiskFreeHalt - free space of datadir volumes below which snapshot building and new torrents are paused,
+	// and sync loop is halted
+	DiskFreePause datasize.ByteSize
+	DiskFreeHalt  datasize.ByteSize
 }
 
 type Sync struct {
@@ -279,15 +342,105 @@ type Sync struct {
 	ExecWorkerCount  int
 	ReconWorkerCount int
 
+	// ParallelExecWorkers - amount of workers for optimistic parallel execution of txs of a block. 0 - serial execution
+	ParallelExecWorkers int
+
+	// ExecCheckpointBlocks, ExecCheckpointInterval - Execution stage commits progress at least every given amount of blocks
+	// or time, even if batch is not full: crash during long catch-up loses at most one checkpoint interval. 0 - disabled
+	ExecCheckpointBlocks   uint64
+	ExecCheckpointInterval time.Duration
+
+	// SendersTrust - which snapshots are trusted to have correct senders embedded: Senders stage doesn't recover senders of frozen blocks
+	SendersTrust SendersTrust
+
 	BodyCacheLimit             datasize.ByteSize
-	BodyDownloadTimeoutSeconds int // TODO: change to duration
-	PruneLimit                 int //the maximum records to delete from the DB during pruning
+	BodiesResponseLimit        datasize.ByteSize // engine_getPayloadBodiesByRange response is cut once it reaches it (not before 32 bodies), 0 - no limit
+	BodyDownloadTimeoutSeconds int               // TODO: change to duration
+	PruneLimit                 int               //the maximum records to delete from the DB during pruning
 	BreakAfterStage            string
 	LoopBlockLimit             uint
 
+	// PruneInterval - if > 0: stages are pruned by background pruner with given interval between rounds, instead of
+	// pruning at end of each sync loop iteration
+	PruneInterval time.Duration
+
+	// StageThrottle - limits of resources of stages, by stage id. Stage without limits isn't in map
+	StageThrottle map[string]StageThrottle
+
 	UploadLocation   string
 	UploadFrom       rpc.BlockNumber
 	FrozenBlockLimit uint64
 }
 
+// SendersTrust - senders of frozen blocks are never recovered by Senders stage: they are embedded into transactions snapshots.
+// Mode defines which snapshots are trusted without verification.
+type SendersTrust string
+
+const (
+	SendersTrustSnapshots SendersTrust = "snapshots" // all snapshots
+	SendersTrustManifest  SendersTrust = "manifest"  // only snapshots listed in chain's manifest (preverified.toml). Senders of other snapshots are verified once
+)
+
+func SendersTrustFromString(s string) (SendersTrust, error) {
+	switch t := SendersTrust(s); t {
+	case SendersTrustSnapshots, SendersTrustManifest:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown senders trust mode: %s, expected one of: %s, %s", s, SendersTrustSnapshots, SendersTrustManifest)
+	}
+}
+
+// StageThrottle - resources which stage may use: syncing node stays responsive for RPC.
+// Workers limits goroutine pools of stage (Senders, Execution, Snapshots). IO limits bandwidth of writing snapshot files
+// (Snapshots: blocks retire and merge of files). 0 - no limit
+type StageThrottle struct {
+	Workers int
+	IO      datasize.ByteSize // per second
+}
+
+// ParseStageThrottle - from comma-separated list of `<stage>:cpu=<workers>` and `<stage>:io=<size>`,
+// for example: `Execution:cpu=8,Snapshots:io=100mb`
+func ParseStageThrottle(s string) (map[string]StageThrottle, error) {
+	res := map[string]StageThrottle{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		stage, limit, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("stage throttle %q: expected <stage>:cpu=<workers> or <stage>:io=<size>", entry)
+		}
+		kind, value, ok := strings.Cut(limit, "=")
+		if !ok {
+			return nil, fmt.Errorf("stage throttle %q: expected <stage>:cpu=<workers> or <stage>:io=<size>", entry)
+		}
+		t := res[stage]
+		switch kind {
+		case "cpu":
+			workers, err := strconv.Atoi(value)
+			if err != nil || workers < 0 {
+				return nil, fmt.Errorf("stage throttle %q: invalid amount of workers", entry)
+			}
+			t.Workers = workers
+		case "io":
+			if err := t.IO.UnmarshalText([]byte(value)); err != nil {
+				return nil, fmt.Errorf("stage throttle %q: %w", entry, err)
+			}
+		default:
+			return nil, fmt.Errorf("stage throttle %q: unknown resource %q, expected cpu or io", entry, kind)
+		}
+		res[stage] = t
+	}
+	return res, nil
+}
+
+// LimitWorkers - `workers` capped by throttle
+func (t StageThrottle) LimitWorkers(workers int) int {
+	if t.Workers > 0 && t.Workers < workers {
+		return t.Workers
+	}
+	return workers
+}
+
 func UseSnapshotsByChainName(chain string) bool { return true }
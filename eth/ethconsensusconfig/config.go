@@ -14,6 +14,7 @@ import (
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/aura"
 	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/consensus/custom"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
 	"github.com/ledgerwatch/erigon/consensus/ethash/ethashcfg"
 	"github.com/ledgerwatch/erigon/consensus/merge"
@@ -31,6 +32,19 @@ func CreateConsensusEngine(ctx context.Context, nodeConfig *nodecfg.Config, chai
 ) consensus.Engine {
 	var eng consensus.Engine
 
+	if factory, ok := custom.Lookup(chainConfig.Consensus); ok {
+		db, err := node.OpenDatabase(ctx, nodeConfig, kv.ConsensusDB, string(chainConfig.Consensus), readonly, logger)
+		if err != nil {
+			panic(err)
+		}
+		rules, err := factory(custom.Config{ChainConfig: chainConfig, Params: chainConfig.ConsensusParams, DB: db, Logger: logger})
+		if err != nil {
+			panic(err)
+		}
+		eng = custom.New(chainConfig.Consensus, rules, logger)
+		config = nil // skip built-in engines below
+	}
+
 	switch consensusCfg := config.(type) {
 	case *ethashcfg.Config:
 		switch consensusCfg.PowMode {
@@ -51,6 +65,7 @@ func CreateConsensusEngine(ctx context.Context, nodeConfig *nodecfg.Config, chai
 				DatasetsInMem:    consensusCfg.DatasetsInMem,
 				DatasetsOnDisk:   consensusCfg.DatasetsOnDisk,
 				DatasetsLockMmap: consensusCfg.DatasetsLockMmap,
+				PowMode:          consensusCfg.PowMode,
 			}, notify, noVerify)
 		}
 	case *params.ConsensusSnapshotConfig:
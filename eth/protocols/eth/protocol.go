@@ -35,6 +35,7 @@ var ProtocolToString = map[uint]string{
 	direct.ETH66: "eth66",
 	direct.ETH67: "eth67",
 	direct.ETH68: "eth68",
+	direct.ETH69: "eth69",
 }
 
 // ProtocolName is the official short name of the `eth` protocol used during
@@ -64,8 +65,19 @@ const (
 	NewPooledTransactionHashesMsg = 0x08
 	GetPooledTransactionsMsg      = 0x09
 	PooledTransactionsMsg         = 0x0a
+
+	// Protocol messages introduced in eth/69
+	BlockRangeUpdateMsg = 0x11
 )
 
+// ProtocolLengths is the number of implemented messages of each protocol version
+var ProtocolLengths = map[uint]uint64{
+	direct.ETH66: 17,
+	direct.ETH67: 17,
+	direct.ETH68: 17,
+	direct.ETH69: 18,
+}
+
 var ToProto = map[uint]map[uint64]proto_sentry.MessageId{
 	direct.ETH66: {
 		GetBlockHeadersMsg:            proto_sentry.MessageId_GET_BLOCK_HEADERS_66,
@@ -111,6 +123,20 @@ var ToProto = map[uint]map[uint64]proto_sentry.MessageId{
 		GetPooledTransactionsMsg:      proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66,
 		PooledTransactionsMsg:         proto_sentry.MessageId_POOLED_TRANSACTIONS_66,
 	},
+	direct.ETH69: {
+		GetBlockHeadersMsg:            proto_sentry.MessageId_GET_BLOCK_HEADERS_66,
+		BlockHeadersMsg:               proto_sentry.MessageId_BLOCK_HEADERS_66,
+		GetBlockBodiesMsg:             proto_sentry.MessageId_GET_BLOCK_BODIES_66,
+		BlockBodiesMsg:                proto_sentry.MessageId_BLOCK_BODIES_66,
+		GetReceiptsMsg:                proto_sentry.MessageId_GET_RECEIPTS_66,
+		ReceiptsMsg:                   proto_sentry.MessageId_RECEIPTS_66,
+		NewBlockHashesMsg:             proto_sentry.MessageId_NEW_BLOCK_HASHES_66,
+		NewBlockMsg:                   proto_sentry.MessageId_NEW_BLOCK_66,
+		TransactionsMsg:               proto_sentry.MessageId_TRANSACTIONS_66,
+		NewPooledTransactionHashesMsg: proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68,
+		GetPooledTransactionsMsg:      proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66,
+		PooledTransactionsMsg:         proto_sentry.MessageId_POOLED_TRANSACTIONS_66,
+	},
 }
 
 var FromProto = map[uint]map[proto_sentry.MessageId]uint64{
@@ -158,6 +184,20 @@ var FromProto = map[uint]map[proto_sentry.MessageId]uint64{
 		proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66:       GetPooledTransactionsMsg,
 		proto_sentry.MessageId_POOLED_TRANSACTIONS_66:           PooledTransactionsMsg,
 	},
+	direct.ETH69: {
+		proto_sentry.MessageId_GET_BLOCK_HEADERS_66:             GetBlockHeadersMsg,
+		proto_sentry.MessageId_BLOCK_HEADERS_66:                 BlockHeadersMsg,
+		proto_sentry.MessageId_GET_BLOCK_BODIES_66:              GetBlockBodiesMsg,
+		proto_sentry.MessageId_BLOCK_BODIES_66:                  BlockBodiesMsg,
+		proto_sentry.MessageId_GET_RECEIPTS_66:                  GetReceiptsMsg,
+		proto_sentry.MessageId_RECEIPTS_66:                      ReceiptsMsg,
+		proto_sentry.MessageId_NEW_BLOCK_HASHES_66:              NewBlockHashesMsg,
+		proto_sentry.MessageId_NEW_BLOCK_66:                     NewBlockMsg,
+		proto_sentry.MessageId_TRANSACTIONS_66:                  TransactionsMsg,
+		proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68: NewPooledTransactionHashesMsg,
+		proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66:       GetPooledTransactionsMsg,
+		proto_sentry.MessageId_POOLED_TRANSACTIONS_66:           PooledTransactionsMsg,
+	},
 }
 
 // Packet represents a p2p message in the `eth` protocol.
@@ -176,6 +216,25 @@ type StatusPacket struct {
 	ForkID          forkid.ID
 }
 
+// StatusPacket69 is the network packet for the status message for eth/69 and later.
+// Total difficulty is removed, and the peer announces the range of blocks it serves.
+type StatusPacket69 struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	Genesis         libcommon.Hash
+	ForkID          forkid.ID
+	EarliestBlock   uint64
+	LatestBlock     uint64
+	LatestBlockHash libcommon.Hash
+}
+
+// BlockRangeUpdatePacket is sent by eth/69 peers when the range of blocks they serve changes.
+type BlockRangeUpdatePacket struct {
+	EarliestBlock   uint64
+	LatestBlock     uint64
+	LatestBlockHash libcommon.Hash
+}
+
 // NewBlockHashesPacket is the network packet for the block announcements.
 type NewBlockHashesPacket []struct {
 	Hash   libcommon.Hash // Hash of one particular block being announced
@@ -397,9 +456,81 @@ type ReceiptsRLPPacket66 struct {
 	ReceiptsRLPPacket
 }
 
+// Receipt69 is the eth/69 network encoding of a receipt: the tx type is a list
+// element instead of an envelope, and the bloom (derivable from logs) is dropped.
+type Receipt69 struct {
+	Type              uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*types.Log
+}
+
+// ReceiptsPacket69 is the network packet for block receipts distribution over eth/69.
+type ReceiptsPacket69 struct {
+	RequestId uint64
+	Receipts  [][]*Receipt69
+}
+
+// ReceiptsPacket66To69 converts receipts response of eth/66..68 encoding (which node produces) into eth/69 one
+func ReceiptsPacket66To69(data []byte) ([]byte, error) {
+	var packet ReceiptsPacket66
+	if err := rlp.DecodeBytes(data, &packet); err != nil {
+		return nil, fmt.Errorf("decode eth/66 receipts: %w", err)
+	}
+	out := ReceiptsPacket69{RequestId: packet.RequestId, Receipts: make([][]*Receipt69, len(packet.ReceiptsPacket))}
+	for i, receipts := range packet.ReceiptsPacket {
+		out.Receipts[i] = make([]*Receipt69, len(receipts))
+		for j, r := range receipts {
+			status := r.PostState
+			if len(status) == 0 {
+				status = []byte{}
+				if r.Status == types.ReceiptStatusSuccessful {
+					status = []byte{0x01}
+				}
+			}
+			out.Receipts[i][j] = &Receipt69{Type: r.Type, PostStateOrStatus: status, CumulativeGasUsed: r.CumulativeGasUsed, Logs: r.Logs}
+		}
+	}
+	return rlp.EncodeToBytes(&out)
+}
+
+// ReceiptsPacket69To66 converts eth/69 receipts response into eth/66..68 encoding (which node consumes), restoring blooms
+func ReceiptsPacket69To66(data []byte) ([]byte, error) {
+	var packet ReceiptsPacket69
+	if err := rlp.DecodeBytes(data, &packet); err != nil {
+		return nil, fmt.Errorf("decode eth/69 receipts: %w", err)
+	}
+	out := ReceiptsPacket66{RequestId: packet.RequestId, ReceiptsPacket: make(ReceiptsPacket, len(packet.Receipts))}
+	for i, receipts := range packet.Receipts {
+		out.ReceiptsPacket[i] = make([]*types.Receipt, len(receipts))
+		for j, r := range receipts {
+			receipt := &types.Receipt{Type: r.Type, CumulativeGasUsed: r.CumulativeGasUsed, Logs: r.Logs}
+			switch {
+			case len(r.PostStateOrStatus) == 0:
+				receipt.Status = types.ReceiptStatusFailed
+			case len(r.PostStateOrStatus) == 1 && r.PostStateOrStatus[0] == 0x01:
+				receipt.Status = types.ReceiptStatusSuccessful
+			case len(r.PostStateOrStatus) == len(libcommon.Hash{}):
+				receipt.PostState = r.PostStateOrStatus
+			default:
+				return nil, fmt.Errorf("invalid receipt status %x", r.PostStateOrStatus)
+			}
+			receipt.Bloom = types.BytesToBloom(types.LogsBloom(r.Logs))
+			out.ReceiptsPacket[i][j] = receipt
+		}
+	}
+	return rlp.EncodeToBytes(&out)
+}
+
 func (*StatusPacket) Name() string { return "Status" }
 func (*StatusPacket) Kind() byte   { return StatusMsg }
 
+func (*StatusPacket69) Name() string { return "Status" }
+func (*StatusPacket69) Kind() byte   { return StatusMsg }
+
+func (*BlockRangeUpdatePacket) Name() string { return "BlockRangeUpdate" }
+func (*BlockRangeUpdatePacket) Kind() byte   { return BlockRangeUpdateMsg }
+
 func (*NewBlockHashesPacket) Name() string { return "NewBlockHashes" }
 func (*NewBlockHashesPacket) Kind() byte   { return NewBlockHashesMsg }
 
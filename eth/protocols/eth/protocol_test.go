@@ -229,3 +229,48 @@ func TestEth66Messages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that receipts survive translation to eth/69 encoding and back, with blooms restored.
+func TestReceipts69Translation(t *testing.T) {
+	logs := []*types.Log{{Address: libcommon.HexToAddress("0x11"), Topics: []libcommon.Hash{libcommon.HexToHash("0x22")}, Data: []byte{0x33}}}
+	receipts := ReceiptsPacket{
+		{
+			{Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: logs},
+			{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusFailed, CumulativeGasUsed: 42000, Logs: []*types.Log{}},
+		},
+		{},
+		{
+			{Type: types.AccessListTxType, PostState: libcommon.HexToHash("0x44").Bytes(), CumulativeGasUsed: 1, Logs: []*types.Log{}},
+		},
+	}
+	for _, block := range receipts {
+		for _, r := range block {
+			r.Bloom = types.BytesToBloom(types.LogsBloom(r.Logs))
+		}
+	}
+	data, err := rlp.EncodeToBytes(&ReceiptsPacket66{RequestId: 7, ReceiptsPacket: receipts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data69, err := ReceiptsPacket66To69(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data69) >= len(data) {
+		t.Fatalf("eth/69 receipts must be smaller: %d >= %d", len(data69), len(data))
+	}
+	var packet69 ReceiptsPacket69
+	if err := rlp.DecodeBytes(data69, &packet69); err != nil {
+		t.Fatal(err)
+	}
+	if packet69.RequestId != 7 || len(packet69.Receipts) != 3 || packet69.Receipts[0][1].Type != types.DynamicFeeTxType {
+		t.Fatalf("unexpected eth/69 packet: %+v", packet69)
+	}
+	back, err := ReceiptsPacket69To66(data69)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back, data) {
+		t.Fatalf("receipts mismatch after translation:\nhave %x\nwant %x", back, data)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/p2p"
+)
+
+const (
+	// softResponseLimit is the target maximum size of replies to data retrievals.
+	softResponseLimit = 2 * 1024 * 1024
+
+	// maxCodeLookups is the maximum number of bytecodes to serve. This number is
+	// there to limit the number of disk lookups.
+	maxCodeLookups = 1024
+
+	// maxTrieNodeLookups is the maximum number of state trie nodes to serve. This
+	// number is there to limit the number of disk lookups.
+	maxTrieNodeLookups = 1024
+
+	// codeIndexBatch is the number of accounts indexed per read transaction
+	// when building the index of the contract codes.
+	codeIndexBatch = 100_000
+)
+
+// MakeProtocol constructs the `snap` protocol which serves the state of the
+// given database to the peers. The protocol only answers requests: Erigon
+// does not snap sync itself, so it never issues any.
+func MakeProtocol(ctx context.Context, db kv.RoDB, historyV3 bool, logger log.Logger) p2p.Protocol {
+	srv := NewServer(db, historyV3, logger)
+	if historyV3 {
+		go func() {
+			if err := srv.IndexCodes(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("[snap] indexing contract codes failed", "err", err)
+			}
+		}()
+	}
+	return p2p.Protocol{
+		Name:    ProtocolName,
+		Version: Version1,
+		Length:  ProtocolLength,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) *p2p.PeerError {
+			if err := srv.Handle(ctx, rw); err != nil {
+				logger.Trace("[snap] peer disconnected", "peer", peer.ID(), "err", err)
+				return p2p.NewPeerError(p2p.PeerErrorMessageReceive, p2p.DiscProtocolError, err, "snap.Handle failed")
+			}
+			return nil
+		},
+	}
+}
+
+// Server answers the requests of all the peers. HistoryV3 databases don't
+// maintain the hashed state, their requests are answered from the domains
+// and the commitment trie instead.
+type Server struct {
+	db        kv.RoDB
+	historyV3 bool
+	logger    log.Logger
+
+	// Contract codes are stored by address in the domains, so the addresses
+	// of the accounts are indexed by their code hashes
+	codesLock sync.RWMutex
+	codes     map[libcommon.Hash]libcommon.Address
+
+	// The root of the last computed commitment, along with the executed block
+	// it was computed at
+	rootLock  sync.Mutex
+	rootBlock uint64
+	root      libcommon.Hash
+}
+
+func NewServer(db kv.RoDB, historyV3 bool, logger log.Logger) *Server {
+	return &Server{db: db, historyV3: historyV3, logger: logger, codes: make(map[libcommon.Hash]libcommon.Address)}
+}
+
+// Handle serves the requests of a peer until reading from the connection
+// fails, the context is cancelled or the peer misbehaves.
+func (s *Server) Handle(ctx context.Context, rw p2p.MsgReadWriter) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		err = s.handleMessage(ctx, rw, msg)
+		msg.Discard()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handleMessage(ctx context.Context, rw p2p.MsgReadWriter, msg p2p.Msg) error {
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	switch msg.Code {
+	case GetAccountRangeMsg:
+		var req GetAccountRangePacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		var res *AccountRangePacket
+		if err := s.db.View(ctx, func(tx kv.Tx) (err error) {
+			if s.historyV3 {
+				res, err = s.answerGetAccountRangeQueryV3(ctx, tx, &req)
+			} else {
+				res, err = AnswerGetAccountRangeQuery(tx, &req)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		return p2p.Send(rw, AccountRangeMsg, res)
+	case GetStorageRangesMsg:
+		var req GetStorageRangesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		var res *StorageRangesPacket
+		if err := s.db.View(ctx, func(tx kv.Tx) (err error) {
+			if s.historyV3 {
+				res, err = s.answerGetStorageRangesQueryV3(ctx, tx, &req)
+			} else {
+				res, err = AnswerGetStorageRangesQuery(tx, &req)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		return p2p.Send(rw, StorageRangesMsg, res)
+	case GetByteCodesMsg:
+		var req GetByteCodesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		var res *ByteCodesPacket
+		if err := s.db.View(ctx, func(tx kv.Tx) (err error) {
+			if s.historyV3 {
+				res, err = s.answerGetByteCodesQueryV3(tx, &req)
+			} else {
+				res, err = AnswerGetByteCodesQuery(tx, &req)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		return p2p.Send(rw, ByteCodesMsg, res)
+	case GetTrieNodesMsg:
+		var req GetTrieNodesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		var res *TrieNodesPacket
+		if err := s.db.View(ctx, func(tx kv.Tx) (err error) {
+			if s.historyV3 {
+				res, err = s.answerGetTrieNodesQueryV3(ctx, tx, &req)
+			} else {
+				res, err = AnswerGetTrieNodesQuery(tx, &req)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		return p2p.Send(rw, TrieNodesMsg, res)
+	default:
+		// Responses are unsolicited as no requests are ever sent
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
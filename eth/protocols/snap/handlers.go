@@ -0,0 +1,338 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
+
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// The requests are served from the hashed state (HashedAccounts, HashedStorage
+// and the intermediate hashes), which is keyed the same way as the trie the
+// peers are syncing. Every answer recalculates the state root with the
+// FlatDBTrieLoader, collecting the proof nodes on the way, and is left empty
+// when the requested root is not the current one: historical state is not
+// served, the same way geth only serves the roots of its snapshot layers.
+
+func responseLimit(bytes uint64) uint64 {
+	if bytes > softResponseLimit {
+		return softResponseLimit
+	}
+	return bytes
+}
+
+// AnswerGetAccountRangeQuery returns the consecutive accounts starting at the
+// requested origin, along with the proof of the range boundaries.
+func AnswerGetAccountRangeQuery(tx kv.Tx, req *GetAccountRangePacket) (*AccountRangePacket, error) {
+	res := &AccountRangePacket{ID: req.ID}
+	hardLimit := responseLimit(req.Bytes)
+
+	c, err := tx.Cursor(kv.HashedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var (
+		hashes []libcommon.Hash
+		accs   []accounts.Account
+		size   uint64
+	)
+	for k, v, err := c.Seek(req.Origin[:]); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return nil, err
+		}
+		var acc accounts.Account
+		if err := acc.DecodeForStorage(v); err != nil {
+			return nil, fmt.Errorf("decode account %x: %w", k, err)
+		}
+		hashes = append(hashes, libcommon.BytesToHash(k))
+		accs = append(accs, acc)
+		// Storage root is not known yet, account for its full length
+		size += length.Hash + uint64(acc.EncodingLengthForHashing())
+		if bytes.Compare(k, req.Limit[:]) >= 0 || size > hardLimit {
+			break
+		}
+	}
+
+	rl := trie.NewRetainList(0)
+	pr := trie.NewRangeProofRetainer(rl)
+	proofKeys := [][]byte{pr.AddKey(req.Origin[:])}
+	hexKeys := make([][]byte, len(hashes))
+	for i := range hashes {
+		hexKeys[i] = pr.AddKey(hashes[i][:])
+	}
+	if len(hexKeys) > 0 {
+		proofKeys = append(proofKeys, hexKeys[len(hexKeys)-1])
+	}
+	if ok, err := calcRoot(tx, rl, pr, req.Root); err != nil || !ok {
+		return res, err
+	}
+
+	res.Accounts = make([]*AccountData, len(hashes))
+	for i := range hashes {
+		storageRoot := trie.EmptyRoot
+		if root, ok := pr.StorageRoot(hexKeys[i]); ok {
+			storageRoot = root
+		}
+		body, err := SlimAccountRLP(&accs[i], storageRoot)
+		if err != nil {
+			return nil, err
+		}
+		res.Accounts[i] = &AccountData{Hash: hashes[i], Body: body}
+	}
+	res.Proof = pr.Proof(0, proofKeys...)
+	return res, nil
+}
+
+// AnswerGetStorageRangesQuery returns the storage slots of the requested
+// accounts. The origin applies to the first account and the limit to the last
+// one; if the slots of an account could not be returned in full (or started
+// at a non-zero origin), the response ends with that account and carries the
+// proof of its range boundaries.
+func AnswerGetStorageRangesQuery(tx kv.Tx, req *GetStorageRangesPacket) (*StorageRangesPacket, error) {
+	res := &StorageRangesPacket{ID: req.ID}
+	hardLimit := responseLimit(req.Bytes)
+
+	c, err := tx.CursorDupSort(kv.HashedStorage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var (
+		size       uint64
+		proofKeys  [][]byte
+		reqOrigin  = req.Origin
+		rl         = trie.NewRetainList(0)
+		pr         = trie.NewRangeProofRetainer(rl)
+		maxHashKey = bytes.Repeat([]byte{0xff}, length.Hash)
+	)
+	for i, account := range req.Accounts {
+		if size >= hardLimit {
+			break
+		}
+		var origin libcommon.Hash
+		if len(reqOrigin) > 0 {
+			origin, reqOrigin = libcommon.BytesToHash(reqOrigin), nil
+		}
+		limit := maxHashKey
+		if i == len(req.Accounts)-1 && len(req.Limit) > 0 {
+			limit = libcommon.BytesToHash(req.Limit).Bytes()
+		}
+
+		enc, err := tx.GetOne(kv.HashedAccounts, account[:])
+		if err != nil {
+			return nil, err
+		}
+		var acc accounts.Account
+		if len(enc) > 0 {
+			if err := acc.DecodeForStorage(enc); err != nil {
+				return nil, fmt.Errorf("decode account %x: %w", account, err)
+			}
+		}
+		prefix := dbutils.GenerateStoragePrefix(account[:], acc.Incarnation)
+
+		var (
+			storage []*StorageData
+			abort   bool
+		)
+		if len(enc) > 0 {
+			for v, err := c.SeekBothRange(prefix, origin[:]); v != nil; _, v, err = c.NextDup() {
+				if err != nil {
+					return nil, err
+				}
+				if size >= hardLimit {
+					abort = true
+					break
+				}
+				body, err := rlp.EncodeToBytes(v[length.Hash:])
+				if err != nil {
+					return nil, err
+				}
+				storage = append(storage, &StorageData{Hash: libcommon.BytesToHash(v[:length.Hash]), Body: body})
+				size += uint64(length.Hash + len(body))
+				if bytes.Compare(v[:length.Hash], limit) >= 0 {
+					break
+				}
+			}
+		}
+		if len(storage) > 0 {
+			res.Slots = append(res.Slots, storage)
+		}
+		// A partial range of slots has to be proven against the storage root
+		if origin != (libcommon.Hash{}) || (abort && len(storage) > 0) {
+			proofKeys = append(proofKeys, pr.AddKey(append(libcommon.Copy(prefix), origin[:]...)))
+			if len(storage) > 0 {
+				proofKeys = append(proofKeys, pr.AddKey(append(libcommon.Copy(prefix), storage[len(storage)-1].Hash[:]...)))
+			}
+			break
+		}
+	}
+	if ok, err := calcRoot(tx, rl, pr, req.Root); err != nil || !ok {
+		return &StorageRangesPacket{ID: req.ID}, err
+	}
+	if len(proofKeys) > 0 {
+		res.Proof = pr.Proof(trie.StorageTrieDepth, proofKeys...)
+	}
+	return res, nil
+}
+
+// AnswerGetByteCodesQuery returns the contract codes of the requested hashes,
+// skipping the unknown ones.
+func AnswerGetByteCodesQuery(tx kv.Tx, req *GetByteCodesPacket) (*ByteCodesPacket, error) {
+	res := &ByteCodesPacket{ID: req.ID}
+	hardLimit := responseLimit(req.Bytes)
+	hashes := req.Hashes
+	if len(hashes) > maxCodeLookups {
+		hashes = hashes[:maxCodeLookups]
+	}
+
+	var size uint64
+	for _, hash := range hashes {
+		if hash == trie.EmptyCodeHash {
+			// Peers should not request the empty code, but if they do, at
+			// least sent them back a correct response without db lookups
+			res.Codes = append(res.Codes, []byte{})
+			continue
+		}
+		code, err := tx.GetOne(kv.Code, hash[:])
+		if err != nil {
+			return nil, err
+		}
+		if len(code) == 0 {
+			continue
+		}
+		res.Codes = append(res.Codes, libcommon.CopyBytes(code))
+		size += uint64(len(code))
+		if size > hardLimit {
+			break
+		}
+	}
+	return res, nil
+}
+
+// AnswerGetTrieNodesQuery returns the trie nodes located at the requested
+// paths, stopping at the first node which is not available.
+func AnswerGetTrieNodesQuery(tx kv.Tx, req *GetTrieNodesPacket) (*TrieNodesPacket, error) {
+	res := &TrieNodesPacket{ID: req.ID}
+	hardLimit := responseLimit(req.Bytes)
+
+	rl := trie.NewRetainList(0)
+	pr := trie.NewRangeProofRetainer(rl)
+	var paths [][]byte
+	for _, pathset := range req.Paths {
+		if len(paths) >= maxTrieNodeLookups {
+			break
+		}
+		switch len(pathset) {
+		case 0:
+			// Ensure we penalize invalid requests
+			return nil, fmt.Errorf("%w: zero-item pathset requested", errBadRequest)
+		case 1:
+			// If we're only retrieving an account trie node, add it directly
+			paths = append(paths, compactToHex(pathset[0]))
+		default:
+			// Storage trie nodes are located under the account hash and incarnation
+			if len(pathset[0]) != length.Hash {
+				return nil, fmt.Errorf("%w: invalid account hash %x", errBadRequest, pathset[0])
+			}
+			enc, err := tx.GetOne(kv.HashedAccounts, pathset[0])
+			if err != nil {
+				return nil, err
+			}
+			var acc accounts.Account
+			if len(enc) > 0 {
+				if err := acc.DecodeForStorage(enc); err != nil {
+					return nil, fmt.Errorf("decode account %x: %w", pathset[0], err)
+				}
+			}
+			base := keyToHex(dbutils.GenerateStoragePrefix(pathset[0], acc.Incarnation))
+			for _, path := range pathset[1:] {
+				paths = append(paths, append(libcommon.Copy(base), compactToHex(path)...))
+			}
+		}
+	}
+	if len(paths) > maxTrieNodeLookups {
+		paths = paths[:maxTrieNodeLookups]
+	}
+	for _, path := range paths {
+		pr.AddHex(path)
+	}
+	if ok, err := calcRoot(tx, rl, pr, req.Root); err != nil || !ok {
+		return res, err
+	}
+
+	var size uint64
+	for _, path := range paths {
+		node := pr.Node(path)
+		if node == nil {
+			break
+		}
+		res.Nodes = append(res.Nodes, node)
+		size += uint64(len(node))
+		if size > hardLimit {
+			break
+		}
+	}
+	return res, nil
+}
+
+// calcRoot calculates the state root, collecting the nodes requested from the
+// retainer, and reports whether it matches the expected one.
+func calcRoot(tx kv.Tx, rl *trie.RetainList, pr *trie.RangeProofRetainer, expected libcommon.Hash) (bool, error) {
+	loader := trie.NewFlatDBTrieLoader("snap", rl, nil, nil, false)
+	loader.SetRangeProofRetainer(pr)
+	root, err := loader.CalcTrieRoot(tx, nil)
+	if err != nil {
+		return false, err
+	}
+	return root == expected, nil
+}
+
+// keyToHex converts a key into nibbles, without the terminator.
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2] = b / 16
+		hex[i*2+1] = b % 16
+	}
+	return hex
+}
+
+// compactToHex converts a trie path in the compact encoding used on the wire
+// into nibbles, without the terminator.
+func compactToHex(compact []byte) []byte {
+	if len(compact) == 0 {
+		return nil
+	}
+	keybytes := trie.CompactToKeybytes(compact)
+	hex := keybytes.ToHex()
+	if len(hex) > 0 && hex[len(hex)-1] == 16 {
+		hex = hex[:len(hex)-1]
+	}
+	return hex
+}
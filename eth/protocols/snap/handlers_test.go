@@ -0,0 +1,192 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+const (
+	testAccounts = 64
+	testSlots    = 16
+)
+
+var testCode = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+
+// newTestState seeds the hashed state with accounts, every fourth of them
+// being a contract with storage, and returns the sorted account hashes along
+// with the state root.
+func newTestState(t *testing.T) (kv.RwDB, []libcommon.Hash, libcommon.Hash) {
+	t.Helper()
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	codeHash := crypto.Keccak256Hash(testCode)
+	require.NoError(t, tx.Put(kv.Code, codeHash[:], testCode))
+	hashes := make([]libcommon.Hash, testAccounts)
+	for i := range hashes {
+		hashes[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		acc := accounts.Account{Nonce: uint64(i), Balance: *uint256.NewInt(uint64(i) * 1000), Initialised: true, CodeHash: trie.EmptyCodeHash}
+		if i%4 == 0 {
+			acc.Incarnation = 1
+			acc.CodeHash = codeHash
+			for j := 0; j < testSlots; j++ {
+				slot := crypto.Keccak256Hash([]byte{byte(i), byte(j)})
+				require.NoError(t, tx.Put(kv.HashedStorage, dbutils.GenerateCompositeStorageKey(hashes[i], 1, slot), []byte{byte(j + 1)}))
+			}
+		}
+		enc := make([]byte, acc.EncodingLengthForStorage())
+		acc.EncodeForStorage(enc)
+		require.NoError(t, tx.Put(kv.HashedAccounts, hashes[i][:], enc))
+	}
+	root, err := trie.CalcRoot("test", tx)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	return db, hashes, root
+}
+
+func maxHash() libcommon.Hash {
+	return libcommon.BytesToHash(bytes.Repeat([]byte{0xff}, 32))
+}
+
+func TestAnswerGetAccountRangeQuery(t *testing.T) {
+	db, hashes, root := newTestState(t)
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	t.Run("full range", func(t *testing.T) {
+		res, err := AnswerGetAccountRangeQuery(tx, &GetAccountRangePacket{ID: 1, Root: root, Limit: maxHash(), Bytes: softResponseLimit})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), res.ID)
+		require.Len(t, res.Accounts, testAccounts)
+		for i, acc := range res.Accounts {
+			require.Equal(t, hashes[i], acc.Hash)
+			var slim slimAccount
+			require.NoError(t, rlp.DecodeBytes(acc.Body, &slim))
+		}
+		require.NotEmpty(t, res.Proof)
+		require.Equal(t, root, crypto.Keccak256Hash(res.Proof[0]))
+	})
+	t.Run("byte limit", func(t *testing.T) {
+		res, err := AnswerGetAccountRangeQuery(tx, &GetAccountRangePacket{Root: root, Origin: hashes[10], Limit: maxHash(), Bytes: 500})
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Accounts)
+		require.Less(t, len(res.Accounts), testAccounts-10)
+		require.Equal(t, hashes[10], res.Accounts[0].Hash)
+	})
+	t.Run("limit", func(t *testing.T) {
+		res, err := AnswerGetAccountRangeQuery(tx, &GetAccountRangePacket{Root: root, Origin: hashes[10], Limit: hashes[20], Bytes: softResponseLimit})
+		require.NoError(t, err)
+		require.Len(t, res.Accounts, 11)
+	})
+	t.Run("unknown root", func(t *testing.T) {
+		res, err := AnswerGetAccountRangeQuery(tx, &GetAccountRangePacket{Root: libcommon.Hash{1}, Limit: maxHash(), Bytes: softResponseLimit})
+		require.NoError(t, err)
+		require.Empty(t, res.Accounts)
+		require.Empty(t, res.Proof)
+	})
+}
+
+func TestAnswerGetStorageRangesQuery(t *testing.T) {
+	db, _, root := newTestState(t)
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	contracts := []libcommon.Hash{crypto.Keccak256Hash([]byte{0}), crypto.Keccak256Hash([]byte{4})}
+
+	t.Run("whole storages", func(t *testing.T) {
+		res, err := AnswerGetStorageRangesQuery(tx, &GetStorageRangesPacket{ID: 2, Root: root, Accounts: contracts, Bytes: softResponseLimit})
+		require.NoError(t, err)
+		require.Len(t, res.Slots, 2)
+		for _, slots := range res.Slots {
+			require.Len(t, slots, testSlots)
+			require.True(t, sort.SliceIsSorted(slots, func(i, j int) bool { return bytes.Compare(slots[i].Hash[:], slots[j].Hash[:]) < 0 }))
+		}
+		require.Empty(t, res.Proof)
+	})
+	t.Run("partial storage", func(t *testing.T) {
+		res, err := AnswerGetStorageRangesQuery(tx, &GetStorageRangesPacket{Root: root, Accounts: contracts, Bytes: 100})
+		require.NoError(t, err)
+		require.Len(t, res.Slots, 1)
+		require.Less(t, len(res.Slots[0]), testSlots)
+		require.NotEmpty(t, res.Proof)
+	})
+	t.Run("unknown root", func(t *testing.T) {
+		res, err := AnswerGetStorageRangesQuery(tx, &GetStorageRangesPacket{Root: libcommon.Hash{1}, Accounts: contracts, Bytes: softResponseLimit})
+		require.NoError(t, err)
+		require.Empty(t, res.Slots)
+	})
+}
+
+func TestAnswerGetByteCodesQuery(t *testing.T) {
+	db, _, _ := newTestState(t)
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	res, err := AnswerGetByteCodesQuery(tx, &GetByteCodesPacket{Hashes: []libcommon.Hash{crypto.Keccak256Hash(testCode), {1}, trie.EmptyCodeHash}, Bytes: softResponseLimit})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{testCode, {}}, res.Codes)
+}
+
+func TestHandleTrieNodes(t *testing.T) {
+	db, _, root := newTestState(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, remote := p2p.MsgPipe()
+	defer local.Close()
+	go NewServer(db, false, log.New()).Handle(ctx, remote) //nolint:errcheck
+
+	contract := crypto.Keccak256Hash([]byte{0})
+	req := &GetTrieNodesPacket{
+		ID:   3,
+		Root: root,
+		Paths: []TrieNodePathSet{
+			{{0x00}},
+			{contract[:], {0x00}},
+		},
+		Bytes: softResponseLimit,
+	}
+	require.NoError(t, p2p.Send(local, GetTrieNodesMsg, req))
+	msg, err := local.ReadMsg()
+	require.NoError(t, err)
+	require.Equal(t, uint64(TrieNodesMsg), msg.Code)
+	var res TrieNodesPacket
+	require.NoError(t, msg.Decode(&res))
+	require.Equal(t, uint64(3), res.ID)
+	require.Len(t, res.Nodes, 2)
+	require.Equal(t, root, crypto.Keccak256Hash(res.Nodes[0]))
+
+	res2, err := AnswerGetAccountRangeQuery(mustBeginRo(t, db), &GetAccountRangePacket{Root: root, Origin: contract, Limit: contract, Bytes: softResponseLimit})
+	require.NoError(t, err)
+	var slim slimAccount
+	require.NoError(t, rlp.DecodeBytes(res2.Accounts[0].Body, &slim))
+	require.Equal(t, slim.Root, crypto.Keccak256(res.Nodes[1]))
+}
+
+func mustBeginRo(t *testing.T, db kv.RoDB) kv.Tx {
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+	return tx
+}
@@ -0,0 +1,412 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ledgerwatch/erigon-lib/commitment"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/order"
+	libstate "github.com/ledgerwatch/erigon-lib/state"
+
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// HistoryV3 databases don't keep the hashed state, the requests are served
+// from the domains instead. Accounts and slots are iterated over in the order
+// of their hashes by walking the leaves of the commitment trie, which is keyed
+// the same way as the trie the peers are syncing, and the proofs are collected
+// from its branches. Only the root of the last computed commitment is served.
+
+func (s *Server) answerGetAccountRangeQueryV3(ctx context.Context, tx kv.Tx, req *GetAccountRangePacket) (*AccountRangePacket, error) {
+	res := &AccountRangePacket{ID: req.ID}
+	domains, ok, err := s.openDomains(ctx, tx, req.Root)
+	if err != nil || !ok {
+		return res, err
+	}
+	defer domains.Close()
+	hardLimit := responseLimit(req.Bytes)
+
+	var size uint64
+	if err := domains.WalkCommitmentLeaves(nil, req.Origin[:], func(hashedKey, plainKey []byte, cell *commitment.Cell, storageRoot []byte) (bool, error) {
+		acc := accounts.Account{Nonce: cell.Nonce, Balance: cell.Balance, CodeHash: cell.CodeHash, Initialised: true}
+		body, err := SlimAccountRLP(&acc, libcommon.BytesToHash(storageRoot))
+		if err != nil {
+			return false, err
+		}
+		if acc.CodeHash != trie.EmptyCodeHash {
+			s.addCode(acc.CodeHash, libcommon.BytesToAddress(plainKey))
+		}
+		res.Accounts = append(res.Accounts, &AccountData{Hash: libcommon.BytesToHash(hashedKey), Body: body})
+		size += length.Hash + uint64(len(body))
+		return bytes.Compare(hashedKey, req.Limit[:]) < 0 && size <= hardLimit, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	proofKeys := [][]byte{req.Origin[:]}
+	if len(res.Accounts) > 0 {
+		proofKeys = append(proofKeys, res.Accounts[len(res.Accounts)-1].Hash[:])
+	}
+	if res.Proof, err = proveKeys(ctx, domains, req.Root, nil, proofKeys); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *Server) answerGetStorageRangesQueryV3(ctx context.Context, tx kv.Tx, req *GetStorageRangesPacket) (*StorageRangesPacket, error) {
+	res := &StorageRangesPacket{ID: req.ID}
+	domains, ok, err := s.openDomains(ctx, tx, req.Root)
+	if err != nil || !ok {
+		return res, err
+	}
+	defer domains.Close()
+	hardLimit := responseLimit(req.Bytes)
+
+	var (
+		size       uint64
+		reqOrigin  = req.Origin
+		maxHashKey = bytes.Repeat([]byte{0xff}, length.Hash)
+	)
+	for i, account := range req.Accounts {
+		if size >= hardLimit {
+			break
+		}
+		var origin libcommon.Hash
+		if len(reqOrigin) > 0 {
+			origin, reqOrigin = libcommon.BytesToHash(reqOrigin), nil
+		}
+		limit := maxHashKey
+		if i == len(req.Accounts)-1 && len(req.Limit) > 0 {
+			limit = libcommon.BytesToHash(req.Limit).Bytes()
+		}
+
+		var (
+			storage []*StorageData
+			abort   bool
+		)
+		if err := domains.WalkCommitmentLeaves(account[:], origin[:], func(hashedKey, _ []byte, cell *commitment.Cell, _ []byte) (bool, error) {
+			if size >= hardLimit {
+				abort = true
+				return false, nil
+			}
+			body, err := rlp.EncodeToBytes(cell.Storage[:cell.StorageLen])
+			if err != nil {
+				return false, err
+			}
+			storage = append(storage, &StorageData{Hash: libcommon.BytesToHash(hashedKey), Body: body})
+			size += uint64(length.Hash + len(body))
+			return bytes.Compare(hashedKey, limit) < 0, nil
+		}); err != nil {
+			return nil, err
+		}
+		if len(storage) > 0 {
+			res.Slots = append(res.Slots, storage)
+		}
+		// A partial range of slots has to be proven against the storage root
+		if origin != (libcommon.Hash{}) || (abort && len(storage) > 0) {
+			proofKeys := [][]byte{origin[:]}
+			if len(storage) > 0 {
+				proofKeys = append(proofKeys, storage[len(storage)-1].Hash[:])
+			}
+			if res.Proof, err = proveKeys(ctx, domains, req.Root, account[:], proofKeys); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return res, nil
+}
+
+func (s *Server) answerGetByteCodesQueryV3(tx kv.Tx, req *GetByteCodesPacket) (*ByteCodesPacket, error) {
+	ttx, ok := tx.(kv.TemporalTx)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a temporal transaction", tx)
+	}
+	res := &ByteCodesPacket{ID: req.ID}
+	hardLimit := responseLimit(req.Bytes)
+	hashes := req.Hashes
+	if len(hashes) > maxCodeLookups {
+		hashes = hashes[:maxCodeLookups]
+	}
+
+	var size uint64
+	for _, hash := range hashes {
+		if hash == trie.EmptyCodeHash {
+			res.Codes = append(res.Codes, []byte{})
+			continue
+		}
+		addr, ok := s.codeAddress(hash)
+		if !ok {
+			continue
+		}
+		code, _, err := ttx.DomainGet(kv.CodeDomain, addr[:], nil)
+		if err != nil {
+			return nil, err
+		}
+		// The contract may have been recreated since
+		if len(code) == 0 || crypto.Keccak256Hash(code) != hash {
+			continue
+		}
+		res.Codes = append(res.Codes, libcommon.CopyBytes(code))
+		size += uint64(len(code))
+		if size > hardLimit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (s *Server) answerGetTrieNodesQueryV3(ctx context.Context, tx kv.Tx, req *GetTrieNodesPacket) (*TrieNodesPacket, error) {
+	res := &TrieNodesPacket{ID: req.ID}
+	for _, pathset := range req.Paths {
+		if len(pathset) == 0 {
+			// Ensure we penalize invalid requests
+			return nil, fmt.Errorf("%w: zero-item pathset requested", errBadRequest)
+		}
+		if len(pathset) > 1 && len(pathset[0]) != length.Hash {
+			return nil, fmt.Errorf("%w: invalid account hash %x", errBadRequest, pathset[0])
+		}
+	}
+	domains, ok, err := s.openDomains(ctx, tx, req.Root)
+	if err != nil || !ok {
+		return res, err
+	}
+	defer domains.Close()
+	hardLimit := responseLimit(req.Bytes)
+
+	var (
+		size    uint64
+		lookups int
+	)
+	for _, pathset := range req.Paths {
+		var (
+			account []byte
+			paths   = pathset
+		)
+		if len(pathset) > 1 {
+			account, paths = pathset[0], pathset[1:]
+		}
+		if lookups+len(paths) > maxTrieNodeLookups {
+			paths = paths[:maxTrieNodeLookups-lookups]
+		}
+		lookups += len(paths)
+
+		nodes, err := trieNodes(ctx, domains, req.Root, account, paths)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			res.Nodes = append(res.Nodes, node)
+			size += uint64(len(node))
+			if size > hardLimit {
+				return res, nil
+			}
+		}
+		if len(nodes) < len(paths) || lookups >= maxTrieNodeLookups {
+			break
+		}
+	}
+	return res, nil
+}
+
+// openDomains opens the domains of the transaction, provided their commitment
+// is of the requested root. The root is computed once per executed block, the
+// requests for other roots are refused without opening the domains.
+func (s *Server) openDomains(ctx context.Context, tx kv.Tx, root libcommon.Hash) (*libstate.SharedDomains, bool, error) {
+	executed, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return nil, false, err
+	}
+	s.rootLock.Lock()
+	cachedRoot, cached := s.root, s.rootBlock == executed && s.root != (libcommon.Hash{})
+	s.rootLock.Unlock()
+	if cached && cachedRoot != root {
+		return nil, false, nil
+	}
+
+	domains, err := libstate.NewSharedDomains(tx, s.logger)
+	if err != nil {
+		return nil, false, err
+	}
+	if !cached {
+		rootHash, err := domains.ComputeCommitment(ctx, false, domains.BlockNum(), "snap")
+		if err != nil {
+			domains.Close()
+			return nil, false, err
+		}
+		cachedRoot = libcommon.BytesToHash(rootHash)
+		s.rootLock.Lock()
+		s.rootBlock, s.root = executed, cachedRoot
+		s.rootLock.Unlock()
+	}
+	if cachedRoot != root {
+		domains.Close()
+		return nil, false, nil
+	}
+	return domains, true, nil
+}
+
+// IndexCodes indexes the addresses of all the contracts by their code hashes,
+// so that the codes of the accounts not served yet can be looked up. The
+// accounts are read in batches, each in its own transaction.
+func (s *Server) IndexCodes(ctx context.Context) error {
+	var from []byte
+	for {
+		var next []byte
+		if err := s.db.View(ctx, func(tx kv.Tx) error {
+			ttx, ok := tx.(kv.TemporalTx)
+			if !ok {
+				return fmt.Errorf("%T is not a temporal transaction", tx)
+			}
+			it, err := ttx.DomainRange(kv.AccountsDomain, from, nil, math.MaxUint64, order.Asc, codeIndexBatch+1)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			for n := 0; it.HasNext(); n++ {
+				k, v, err := it.Next()
+				if err != nil {
+					return err
+				}
+				if n == codeIndexBatch {
+					next = libcommon.Copy(k)
+					break
+				}
+				if len(v) == 0 {
+					continue
+				}
+				var acc accounts.Account
+				if err := accounts.DeserialiseV3(&acc, v); err != nil {
+					return err
+				}
+				if acc.CodeHash != trie.EmptyCodeHash && acc.CodeHash != (libcommon.Hash{}) {
+					s.addCode(acc.CodeHash, libcommon.BytesToAddress(k))
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		from = next
+	}
+}
+
+func (s *Server) addCode(hash libcommon.Hash, addr libcommon.Address) {
+	s.codesLock.Lock()
+	defer s.codesLock.Unlock()
+	s.codes[hash] = addr
+}
+
+func (s *Server) codeAddress(hash libcommon.Hash) (libcommon.Address, bool) {
+	s.codesLock.RLock()
+	defer s.codesLock.RUnlock()
+	addr, ok := s.codes[hash]
+	return addr, ok
+}
+
+// proveKeys returns the nodes on the paths to the given account hashes, or to
+// the slot hashes if the account is given, ordered from the root downwards
+// and without duplicates.
+func proveKeys(ctx context.Context, domains *libstate.SharedDomains, root libcommon.Hash, account []byte, keys [][]byte) ([][]byte, error) {
+	hexKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		hexKeys[i] = keyToHex(append(libcommon.Copy(account), key...))
+	}
+	_, nodes, err := domains.ProveHashedKeys(ctx, hexKeys)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		var ok bool
+		if root, ok, err = storageRoot(domains, account); err != nil || !ok {
+			return nil, err
+		}
+	}
+
+	var (
+		proof [][]byte
+		seen  = make(map[libcommon.Hash]struct{})
+	)
+	for _, hexKey := range hexKeys {
+		path, _, err := trie.ProofPath(root, nodes, hexKey[2*len(account):])
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range path {
+			hash := crypto.Keccak256Hash(node)
+			if _, ok := seen[hash]; !ok {
+				seen[hash] = struct{}{}
+				proof = append(proof, node)
+			}
+		}
+	}
+	return proof, nil
+}
+
+// trieNodes returns the nodes located at the given compact encoded paths of
+// the account trie, or of the storage trie if the account is given, stopping
+// at the first node which is not available.
+func trieNodes(ctx context.Context, domains *libstate.SharedDomains, root libcommon.Hash, account []byte, paths [][]byte) ([][]byte, error) {
+	// Nodes on a path are collected by proving any key under it
+	keyLen := 2 * length.Hash
+	if account != nil {
+		keyLen *= 2
+	}
+	hexPaths := make([][]byte, len(paths))
+	hexKeys := make([][]byte, len(paths))
+	for i, path := range paths {
+		hexPaths[i] = compactToHex(path)
+		hexKeys[i] = append(keyToHex(account), hexPaths[i]...)
+		if len(hexKeys[i]) > keyLen {
+			return nil, fmt.Errorf("%w: invalid trie node path %x", errBadRequest, path)
+		}
+		hexKeys[i] = append(hexKeys[i], make([]byte, keyLen-len(hexKeys[i]))...)
+	}
+	_, proofNodes, err := domains.ProveHashedKeys(ctx, hexKeys)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		var ok bool
+		if root, ok, err = storageRoot(domains, account); err != nil || !ok {
+			return nil, err
+		}
+	}
+
+	var nodes [][]byte
+	for _, hexPath := range hexPaths {
+		_, node, err := trie.ProofPath(root, proofNodes, hexPath)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			break
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// storageRoot returns the storage root of the account with the given hash,
+// reporting whether the account exists.
+func storageRoot(domains *libstate.SharedDomains, account []byte) (root libcommon.Hash, found bool, err error) {
+	err = domains.WalkCommitmentLeaves(nil, account, func(hashedKey, _ []byte, _ *commitment.Cell, storageRoot []byte) (bool, error) {
+		if bytes.Equal(hashedKey, account) {
+			root, found = libcommon.BytesToHash(storageRoot), true
+		}
+		return false, nil
+	})
+	return root, found, err
+}
@@ -0,0 +1,222 @@
+package snap_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/protocols/snap"
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// The contract stores the call value at the slot of the block number
+	contractAddr = libcommon.HexToAddress("0xc0de")
+	contractCode = []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE), byte(vm.STOP)}
+)
+
+// newHistoryV3Chain builds a chain transferring to new accounts and writing
+// the storage of a contract, on a HistoryV3 database. The blocks are inserted
+// one by one, so the commitment is computed for each of them.
+func newHistoryV3Chain(t *testing.T) (*mock.MockSentry, libcommon.Hash) {
+	t.Helper()
+	storage := make(map[libcommon.Hash]libcommon.Hash)
+	for i := 0; i < 20; i++ {
+		storage[libcommon.BigToHash(big.NewInt(int64(100+i)))] = libcommon.BigToHash(big.NewInt(int64(i + 1)))
+	}
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			testAddr:     {Balance: big.NewInt(params.Ether)},
+			contractAddr: {Balance: big.NewInt(1), Code: contractCode, Storage: storage},
+		},
+	}, testKey, false)
+	require.True(t, m.HistoryV3)
+
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 4, func(i int, b *core.BlockGen) {
+		for j := 0; j < 3; j++ {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), libcommon.Address{byte(i + 1), byte(j + 1)}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+			require.NoError(t, err)
+			b.AddTx(tx)
+		}
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), contractAddr, uint256.NewInt(uint64(i+1)), 50000, nil, nil), *signer, testKey)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	require.NoError(t, err)
+	for i := 0; i < chain.Length(); i++ {
+		require.NoError(t, m.InsertChain(chain.Slice(i, i+1)))
+	}
+
+	var root libcommon.Hash
+	require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		head := rawdb.ReadCurrentHeader(tx)
+		require.Equal(t, uint64(4), head.Number.Uint64())
+		root = head.Root
+		return nil
+	}))
+	return m, root
+}
+
+func request[T any](t *testing.T, rw p2p.MsgReadWriter, code uint64, req interface{}, resCode uint64) *T {
+	t.Helper()
+	require.NoError(t, p2p.Send(rw, code, req))
+	msg, err := rw.ReadMsg()
+	require.NoError(t, err)
+	require.Equal(t, resCode, msg.Code)
+	res := new(T)
+	require.NoError(t, msg.Decode(res))
+	return res
+}
+
+// proofNodes maps the nodes of a proof by their hashes.
+func proofNodes(proof [][]byte) map[libcommon.Hash][]byte {
+	nodes := make(map[libcommon.Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+	return nodes
+}
+
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2], hex[i*2+1] = b/16, b%16
+	}
+	return hex
+}
+
+func TestServeHistoryV3(t *testing.T) {
+	m, root := newHistoryV3Chain(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, remote := p2p.MsgPipe()
+	defer local.Close()
+	go snap.NewServer(m.DB, true, m.Log).Handle(ctx, remote) //nolint:errcheck
+
+	maxHash := libcommon.BytesToHash(bytes.Repeat([]byte{0xff}, 32))
+	contractHash := crypto.Keccak256Hash(contractAddr[:])
+
+	// The whole state rebuilds the state root
+	accs := request[snap.AccountRangePacket](t, local, snap.GetAccountRangeMsg, &snap.GetAccountRangePacket{ID: 1, Root: root, Limit: maxHash, Bytes: 1 << 20}, snap.AccountRangeMsg)
+	require.Equal(t, uint64(1), accs.ID)
+	require.Len(t, accs.Accounts, 1+4*3+2) // contract, transfer recipients, sender and coinbase
+	require.Equal(t, root, crypto.Keccak256Hash(accs.Proof[0]))
+
+	var (
+		accountTrie = trie.New(trie.EmptyRoot)
+		contract    *accounts.Account
+	)
+	for i, data := range accs.Accounts {
+		if i > 0 {
+			require.Equal(t, -1, bytes.Compare(accs.Accounts[i-1].Hash[:], data.Hash[:]))
+		}
+		var slim struct {
+			Nonce    uint64
+			Balance  *uint256.Int
+			Root     []byte
+			CodeHash []byte
+		}
+		require.NoError(t, rlp.DecodeBytes(data.Body, &slim))
+		acc := accounts.NewAccount()
+		acc.Nonce, acc.Balance = slim.Nonce, *slim.Balance
+		if len(slim.Root) > 0 {
+			acc.Root = libcommon.BytesToHash(slim.Root)
+		}
+		if len(slim.CodeHash) > 0 {
+			acc.CodeHash = libcommon.BytesToHash(slim.CodeHash)
+		}
+		accountTrie.UpdateAccount(data.Hash[:], &acc)
+		if data.Hash == contractHash {
+			contract = &acc
+		}
+	}
+	require.Equal(t, root, accountTrie.Hash())
+	require.NotNil(t, contract)
+
+	// A range in the middle is proven at its boundaries
+	origin := accs.Accounts[3].Hash
+	origin[31]--
+	part := request[snap.AccountRangePacket](t, local, snap.GetAccountRangeMsg, &snap.GetAccountRangePacket{Root: root, Origin: origin, Limit: accs.Accounts[6].Hash, Bytes: 1 << 20}, snap.AccountRangeMsg)
+	require.Equal(t, accs.Accounts[3:7], part.Accounts)
+	for _, key := range []libcommon.Hash{origin, part.Accounts[3].Hash} {
+		path, _, err := trie.ProofPath(root, proofNodes(part.Proof), keyToHex(key[:]))
+		require.NoError(t, err)
+		require.Subset(t, part.Proof, path)
+	}
+
+	// Storage of the contract rebuilds its storage root
+	slots := request[snap.StorageRangesPacket](t, local, snap.GetStorageRangesMsg, &snap.GetStorageRangesPacket{Root: root, Accounts: []libcommon.Hash{contractHash, {}}, Bytes: 1 << 20}, snap.StorageRangesMsg)
+	require.Len(t, slots.Slots, 1)
+	require.Len(t, slots.Slots[0], 20+4)
+	require.Empty(t, slots.Proof)
+	storageTrie := trie.New(trie.EmptyRoot)
+	for _, slot := range slots.Slots[0] {
+		storageTrie.Update(slot.Hash[:], slot.Body)
+	}
+	require.Equal(t, contract.Root, storageTrie.Hash())
+
+	partSlots := request[snap.StorageRangesPacket](t, local, snap.GetStorageRangesMsg, &snap.GetStorageRangesPacket{Root: root, Accounts: []libcommon.Hash{contractHash}, Origin: slots.Slots[0][5].Hash[:], Bytes: 1 << 20}, snap.StorageRangesMsg)
+	require.Equal(t, slots.Slots[0][5:], partSlots.Slots[0])
+	require.Equal(t, contract.Root, crypto.Keccak256Hash(partSlots.Proof[0]))
+
+	// Code of the served contract
+	codes := request[snap.ByteCodesPacket](t, local, snap.GetByteCodesMsg, &snap.GetByteCodesPacket{Hashes: []libcommon.Hash{contract.CodeHash, {1}}, Bytes: 1 << 20}, snap.ByteCodesMsg)
+	require.Equal(t, [][]byte{contractCode}, codes.Codes)
+
+	// Roots of the account and the storage tries
+	nodes := request[snap.TrieNodesPacket](t, local, snap.GetTrieNodesMsg, &snap.GetTrieNodesPacket{Root: root, Paths: []snap.TrieNodePathSet{{{}}, {contractHash[:], {}}}, Bytes: 1 << 20}, snap.TrieNodesMsg)
+	require.Len(t, nodes.Nodes, 2)
+	require.Equal(t, root, crypto.Keccak256Hash(nodes.Nodes[0]))
+	require.Equal(t, contract.Root, crypto.Keccak256Hash(nodes.Nodes[1]))
+
+	// Older roots are not served
+	var parentRoot libcommon.Hash
+	require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		parentRoot = rawdb.ReadHeaderByNumber(tx, 3).Root
+		return nil
+	}))
+	stale := request[snap.AccountRangePacket](t, local, snap.GetAccountRangeMsg, &snap.GetAccountRangePacket{Root: parentRoot, Limit: maxHash, Bytes: 1 << 20}, snap.AccountRangeMsg)
+	require.Empty(t, stale.Accounts)
+	require.Empty(t, stale.Proof)
+}
+
+func TestServeHistoryV3IndexedCodes(t *testing.T) {
+	m, _ := newHistoryV3Chain(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, remote := p2p.MsgPipe()
+	defer local.Close()
+	srv := snap.NewServer(m.DB, true, m.Log)
+	go srv.Handle(ctx, remote) //nolint:errcheck
+
+	// No account is served before, the code is found by the index
+	codeHash := crypto.Keccak256Hash(contractCode)
+	codes := request[snap.ByteCodesPacket](t, local, snap.GetByteCodesMsg, &snap.GetByteCodesPacket{Hashes: []libcommon.Hash{codeHash}, Bytes: 1 << 20}, snap.ByteCodesMsg)
+	require.Empty(t, codes.Codes)
+
+	require.NoError(t, srv.IndexCodes(ctx))
+	codes = request[snap.ByteCodesPacket](t, local, snap.GetByteCodesMsg, &snap.GetByteCodesPacket{Hashes: []libcommon.Hash{codeHash}, Bytes: 1 << 20}, snap.ByteCodesMsg)
+	require.Equal(t, [][]byte{contractCode}, codes.Codes)
+}
@@ -0,0 +1,315 @@
+package wit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/commitment"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
+	"github.com/ledgerwatch/erigon-lib/kv/membatchwithdb"
+	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
+	libstate "github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/consensuschain"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// MaxWitnessRewind is how far behind the latest state a block may be for its
+// witness to be generated, bounding the unwind of the state needed to prove
+// its pre-state.
+const MaxWitnessRewind = 128
+
+// Generator produces the witnesses of recent blocks by re-executing them on
+// top of the historical state, recording the state accessed and proving it
+// against the state of the parent block: the commitment trie read from the
+// history of the domains on HistoryV3 databases, or the hashed state and the
+// intermediate hashes unwound in memory otherwise.
+type Generator struct {
+	chainConfig *chain.Config
+	engine      consensus.Engine
+	blockReader services.FullBlockReader
+	dirs        datadir.Dirs
+	historyV3   bool
+	logger      log.Logger
+}
+
+func NewGenerator(chainConfig *chain.Config, engine consensus.Engine, blockReader services.FullBlockReader, dirs datadir.Dirs, historyV3 bool, logger log.Logger) *Generator {
+	return &Generator{
+		chainConfig: chainConfig,
+		engine:      engine,
+		blockReader: blockReader,
+		dirs:        dirs,
+		historyV3:   historyV3,
+		logger:      logger,
+	}
+}
+
+// Generate returns the witness of the canonical block with the given hash, or
+// nil if the block is not known or not canonical.
+func (g *Generator) Generate(ctx context.Context, tx kv.Tx, hash libcommon.Hash) (*stateless.Witness, error) {
+	number := rawdb.ReadHeaderNumber(tx, hash)
+	if number == nil || *number == 0 {
+		return nil, nil
+	}
+	// The latest state only follows the canonical chain
+	canonical, err := g.blockReader.CanonicalHash(ctx, tx, *number)
+	if err != nil {
+		return nil, err
+	}
+	if canonical != hash {
+		return nil, nil
+	}
+	block, _, err := g.blockReader.BlockWithSenders(ctx, tx, hash, *number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	parent, err := g.blockReader.Header(ctx, tx, block.ParentHash(), *number-1)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, nil
+	}
+
+	// The state the witness is proven against is the latest one, unwound
+	latestStage := stages.IntermediateHashes
+	if g.historyV3 {
+		latestStage = stages.Execution
+	}
+	latest, err := stages.GetStageProgress(tx, latestStage)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Number.Uint64() > latest {
+		return nil, fmt.Errorf("block %d is not executed yet, state is at %d", block.NumberU64(), latest)
+	}
+	if latest-parent.Number.Uint64() > MaxWitnessRewind {
+		return nil, fmt.Errorf("block %d is too old, it must be within %d blocks of the state at %d", block.NumberU64(), MaxWitnessRewind, latest)
+	}
+
+	// Re-execute the block, recording the state it reads and the headers
+	// BLOCKHASH looks up
+	historyReader, err := rpchelper.CreateHistoryStateReader(tx, block.NumberU64(), 0, g.historyV3, g.chainConfig.ChainName)
+	if err != nil {
+		return nil, err
+	}
+	recorder := stateless.NewRecorder(historyReader)
+	// GetHashFn walks back from the parent one header at a time, so the
+	// headers looked up form a chain
+	headers := []*types.Header{parent}
+	seen := map[libcommon.Hash]struct{}{parent.Hash(): {}}
+	getHeader := func(hash libcommon.Hash, number uint64) *types.Header {
+		h, err := g.blockReader.Header(ctx, tx, hash, number)
+		if err != nil {
+			g.logger.Warn("[wit] header lookup failed", "number", number, "err", err)
+			return nil
+		}
+		if _, ok := seen[hash]; h != nil && !ok {
+			seen[hash] = struct{}{}
+			headers = append(headers, h)
+		}
+		return h
+	}
+	vmConfig := vm.Config{}
+	chainReader := consensuschain.NewReader(g.chainConfig, tx, g.blockReader, g.logger)
+	if _, err = core.ExecuteBlockEphemerally(g.chainConfig, &vmConfig, core.GetHashFn(block.Header(), getHeader), g.engine, block, recorder, state.NewNoopWriter(), chainReader, nil, g.logger); err != nil {
+		return nil, fmt.Errorf("executing block %d: %w", block.NumberU64(), err)
+	}
+
+	// Prove the recorded state against the parent root
+	var proof [][]byte
+	if g.historyV3 {
+		proof, err = g.proveCommitment(ctx, tx, parent, recorder)
+	} else {
+		proof, err = g.proveHashedState(ctx, tx, parent, latest, recorder)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Number.Uint64() > headers[j].Number.Uint64() })
+	return &stateless.Witness{
+		Headers: headers,
+		Codes:   recorder.Codes(),
+		State:   proof,
+	}, nil
+}
+
+// proveHashedState proves the recorded state against the hashed state and the
+// intermediate hashes, unwound to the parent block in memory.
+func (g *Generator) proveHashedState(ctx context.Context, tx kv.Tx, parent *types.Header, latest uint64, recorder *stateless.Recorder) ([][]byte, error) {
+	rl := trie.NewRetainList(0)
+	pr := trie.NewRangeProofRetainer(rl)
+	var accountKeys, storageKeys [][]byte
+	for _, address := range recorder.Accounts() {
+		addrHash := crypto.Keccak256Hash(address[:])
+		accountKeys = append(accountKeys, pr.AddKey(addrHash[:]))
+		incarnation := recorder.Incarnation(address)
+		for _, key := range recorder.Storage(address) {
+			keyHash := crypto.Keccak256Hash(key[:])
+			storageKeys = append(storageKeys, pr.AddKey(dbutils.GenerateCompositeStorageKey(addrHash, incarnation, keyHash)))
+		}
+	}
+
+	var (
+		loader *trie.FlatDBTrieLoader
+		err    error
+	)
+	if parentNumber := parent.Number.Uint64(); parentNumber < latest {
+		batch := membatchwithdb.NewMemoryBatch(tx, g.dirs.Tmp, g.logger)
+		defer batch.Rollback()
+
+		unwindState := &stagedsync.UnwindState{UnwindPoint: parentNumber}
+		stageState := &stagedsync.StageState{BlockNumber: latest}
+		if err := stagedsync.UnwindHashStateStage(unwindState, stageState, batch, stagedsync.StageHashStateCfg(nil, g.dirs, false), ctx, g.logger); err != nil {
+			return nil, err
+		}
+		interHashStageCfg := stagedsync.StageTrieCfg(nil, false, false, false, g.dirs.Tmp, g.blockReader, nil, false, nil)
+		loader, err = stagedsync.UnwindIntermediateHashesForTrieLoader("wit", rl, unwindState, stageState, batch, interHashStageCfg, nil, nil, ctx.Done(), g.logger)
+		if err != nil {
+			return nil, err
+		}
+		tx = batch
+	} else {
+		loader = trie.NewFlatDBTrieLoader("wit", rl, nil, nil, false)
+	}
+	loader.SetRangeProofRetainer(pr)
+	root, err := loader.CalcTrieRoot(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if root != parent.Root {
+		return nil, fmt.Errorf("mismatch in expected state root computed %x vs %x", root, parent.Root)
+	}
+	return append(pr.Proof(0, accountKeys...), pr.Proof(trie.StorageTrieDepth, storageKeys...)...), nil
+}
+
+// proveCommitment proves the recorded state against the commitment trie of the
+// parent block, read from the history of the domains.
+func (g *Generator) proveCommitment(ctx context.Context, tx kv.Tx, parent *types.Header, recorder *stateless.Recorder) ([][]byte, error) {
+	parentNumber := parent.Number.Uint64()
+	// Branches of the commitment trie have no history in files
+	unwindToLimit, err := tx.(libstate.HasAggCtx).AggCtx().(*libstate.AggregatorRoTx).CanUnwindDomainsToBlockNum(tx)
+	if err != nil {
+		return nil, err
+	}
+	if parentNumber < unwindToLimit {
+		return nil, fmt.Errorf("commitment of block %d is not available, the domains can be unwound to block %d", parentNumber, unwindToLimit)
+	}
+	domains, err := libstate.NewSharedDomains(tx, g.logger)
+	if err != nil {
+		return nil, err
+	}
+	defer domains.Close()
+	// The state of the parent is the one as of the first txNum of the block
+	txNum, err := rawdbv3.TxNums.Min(tx, parentNumber+1)
+	if err != nil {
+		return nil, err
+	}
+	hph, blockNum, err := domains.CommitmentAsOf(txNum)
+	if err != nil {
+		return nil, err
+	}
+	if blockNum != parentNumber {
+		return nil, fmt.Errorf("commitment of block %d is not stored, the previous one is of block %d", parentNumber, blockNum)
+	}
+
+	var hexKeys [][]byte
+	for _, address := range recorder.Accounts() {
+		addrHash := crypto.Keccak256Hash(address[:])
+		hexKeys = append(hexKeys, keyToHex(addrHash[:]))
+		for _, key := range recorder.Storage(address) {
+			keyHash := crypto.Keccak256Hash(key[:])
+			hexKeys = append(hexKeys, keyToHex(append(addrHash[:], keyHash[:]...)))
+		}
+	}
+	root, nodes, err := hph.ProveHashedKeys(ctx, hexKeys)
+	if err != nil {
+		return nil, err
+	}
+	if libcommon.BytesToHash(root) != parent.Root {
+		return nil, fmt.Errorf("mismatch in expected state root computed %x vs %x", root, parent.Root)
+	}
+
+	// Nodes collected on the way to the keys include their siblings, only the
+	// paths of the keys are kept
+	var (
+		proof     [][]byte
+		seenNodes = make(map[libcommon.Hash]struct{})
+	)
+	addPath := func(root libcommon.Hash, hexKey []byte) error {
+		path, _, err := trie.ProofPath(root, nodes, hexKey)
+		if err != nil {
+			return err
+		}
+		for _, node := range path {
+			hash := crypto.Keccak256Hash(node)
+			if _, ok := seenNodes[hash]; !ok {
+				seenNodes[hash] = struct{}{}
+				proof = append(proof, node)
+			}
+		}
+		return nil
+	}
+	for _, address := range recorder.Accounts() {
+		addrHash := crypto.Keccak256Hash(address[:])
+		if err := addPath(parent.Root, keyToHex(addrHash[:])); err != nil {
+			return nil, err
+		}
+		slots := recorder.Storage(address)
+		if len(slots) == 0 {
+			continue
+		}
+		var storageRoot libcommon.Hash
+		if err := hph.WalkLeaves(nil, addrHash[:], func(hashedKey, _ []byte, _ *commitment.Cell, root []byte) (bool, error) {
+			if bytes.Equal(hashedKey, addrHash[:]) {
+				storageRoot = libcommon.BytesToHash(root)
+			}
+			return false, nil
+		}); err != nil {
+			return nil, err
+		}
+		if storageRoot == (libcommon.Hash{}) {
+			continue // the account does not exist
+		}
+		for _, key := range slots {
+			keyHash := crypto.Keccak256Hash(key[:])
+			if err := addPath(storageRoot, keyToHex(keyHash[:])); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return proof, nil
+}
+
+// keyToHex converts a key into nibbles, without the terminator.
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2] = b / 16
+		hex[i*2+1] = b % 16
+	}
+	return hex
+}
@@ -0,0 +1,126 @@
+package wit_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/protocols/wit"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// The contract stores the call value at the slot of the block number
+	contractAddr = libcommon.HexToAddress("0xc0de")
+	contractCode = []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE), byte(vm.STOP)}
+)
+
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2], hex[i*2+1] = b/16, b%16
+	}
+	return hex
+}
+
+// proveAccount checks the witness contains the path to the account and
+// returns its storage root.
+func proveAccount(t *testing.T, nodes map[libcommon.Hash][]byte, root libcommon.Hash, address libcommon.Address) libcommon.Hash {
+	t.Helper()
+	path, _, err := trie.ProofPath(root, nodes, keyToHex(crypto.Keccak256(address[:])))
+	require.NoError(t, err)
+	var leaf [][]byte
+	require.NoError(t, rlp.DecodeBytes(path[len(path)-1], &leaf))
+	require.Len(t, leaf, 2)
+	var acc struct {
+		Nonce    uint64
+		Balance  *big.Int
+		Root     libcommon.Hash
+		CodeHash libcommon.Hash
+	}
+	require.NoError(t, rlp.DecodeBytes(leaf[1], &acc))
+	return acc.Root
+}
+
+func TestGenerateHistoryV3(t *testing.T) {
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			testAddr:     {Balance: big.NewInt(params.Ether)},
+			contractAddr: {Balance: big.NewInt(1), Code: contractCode, Storage: map[libcommon.Hash]libcommon.Hash{{1}: {1}}},
+		},
+	}, testKey, false)
+	require.True(t, m.HistoryV3)
+
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 4, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), libcommon.Address{byte(i + 1)}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+		require.NoError(t, err)
+		b.AddTx(tx)
+		tx, err = types.SignTx(types.NewTransaction(b.TxNonce(testAddr), contractAddr, uint256.NewInt(uint64(i+1)), 50000, nil, nil), *signer, testKey)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	require.NoError(t, err)
+	// Commitment is computed for every block
+	for i := 0; i < chain.Length(); i++ {
+		require.NoError(t, m.InsertChain(chain.Slice(i, i+1)))
+	}
+
+	ctx := context.Background()
+	generator := wit.NewGenerator(m.ChainConfig, m.Engine, m.BlockReader, m.Dirs, true, m.Log)
+	generate := func(hash libcommon.Hash) (witness *stateless.Witness) {
+		require.NoError(t, m.DB.View(ctx, func(tx kv.Tx) (err error) {
+			witness, err = generator.Generate(ctx, tx, hash)
+			return err
+		}))
+		return witness
+	}
+
+	// The head block needs no unwind, the older ones are proven against the
+	// state unwound in memory
+	for _, block := range chain.Blocks {
+		witness := generate(block.Hash())
+		require.NotNil(t, witness)
+		require.NoError(t, witness.Validate(block.Header()))
+		require.Contains(t, witness.Codes, contractCode)
+
+		nodes := make(map[libcommon.Hash][]byte)
+		for _, node := range witness.State {
+			nodes[crypto.Keccak256Hash(node)] = node
+		}
+		root := witness.Root()
+		proveAccount(t, nodes, root, testAddr)
+		storageRoot := proveAccount(t, nodes, root, contractAddr)
+		require.NotEqual(t, trie.EmptyRoot, storageRoot)
+		slot := libcommon.BigToHash(block.Number())
+		_, _, err := trie.ProofPath(storageRoot, nodes, keyToHex(crypto.Keccak256(slot[:])))
+		require.NoError(t, err)
+	}
+	// Generating the witnesses leaves the database intact
+	require.NoError(t, m.DB.View(ctx, func(tx kv.Tx) error {
+		require.Equal(t, chain.TopBlock.Hash(), rawdb.ReadHeadBlockHash(tx))
+		return nil
+	}))
+	require.NotNil(t, generate(chain.TopBlock.Hash()))
+
+	require.Nil(t, generate(libcommon.Hash{1}))
+}
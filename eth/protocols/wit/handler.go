@@ -0,0 +1,236 @@
+package wit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+const (
+	// softResponseLimit is the target maximum size of replies to data retrievals.
+	softResponseLimit = 8 * 1024 * 1024
+
+	// maxWitnessesServe is the maximum number of witnesses to serve in a
+	// response. Each one requires the block to be re-executed.
+	maxWitnessesServe = 8
+
+	// requestTimeout is how long a peer has to answer a witness request.
+	requestTimeout = 10 * time.Second
+)
+
+var ErrNoWitness = errors.New("no peer provided the witness")
+
+// GenerateFunc produces the witness of a block, or nil if the block is not
+// known.
+type GenerateFunc func(ctx context.Context, tx kv.Tx, hash libcommon.Hash) (*stateless.Witness, error)
+
+// Handler runs the `wit` protocol. It serves the witnesses produced by its
+// generator to the peers (unless it has none, in which case the requests are
+// answered with unavailable witnesses), and fetches witnesses from them.
+type Handler struct {
+	ctx      context.Context
+	db       kv.RoDB
+	generate GenerateFunc
+	logger   log.Logger
+
+	peersLock sync.RWMutex
+	peers     map[enode.ID]*peer
+	requestID atomic.Uint64
+}
+
+type peer struct {
+	rw      p2p.MsgReadWriter
+	lock    sync.Mutex
+	pending map[uint64]chan []*stateless.Witness
+}
+
+func NewHandler(ctx context.Context, db kv.RoDB, generate GenerateFunc, logger log.Logger) *Handler {
+	return &Handler{
+		ctx:      ctx,
+		db:       db,
+		generate: generate,
+		logger:   logger,
+		peers:    make(map[enode.ID]*peer),
+	}
+}
+
+// Protocol returns the `wit` protocol to be registered on the p2p server.
+func (h *Handler) Protocol() p2p.Protocol {
+	return p2p.Protocol{
+		Name:    ProtocolName,
+		Version: Version0,
+		Length:  ProtocolLength,
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) *p2p.PeerError {
+			if err := h.Handle(p.ID(), rw); err != nil {
+				h.logger.Trace("[wit] peer disconnected", "peer", p.ID(), "err", err)
+				return p2p.NewPeerError(p2p.PeerErrorMessageReceive, p2p.DiscProtocolError, err, "wit.Handle failed")
+			}
+			return nil
+		},
+	}
+}
+
+// Handle runs the protocol with a peer until reading from the connection
+// fails, the context is cancelled or the peer misbehaves.
+func (h *Handler) Handle(id enode.ID, rw p2p.MsgReadWriter) error {
+	p := &peer{rw: rw, pending: make(map[uint64]chan []*stateless.Witness)}
+	h.peersLock.Lock()
+	h.peers[id] = p
+	h.peersLock.Unlock()
+	defer func() {
+		h.peersLock.Lock()
+		delete(h.peers, id)
+		h.peersLock.Unlock()
+	}()
+
+	for {
+		if h.ctx.Err() != nil {
+			return h.ctx.Err()
+		}
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		err = h.handleMessage(p, msg)
+		msg.Discard()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (h *Handler) handleMessage(p *peer, msg p2p.Msg) error {
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	switch msg.Code {
+	case GetBlockWitnessMsg:
+		var req GetBlockWitnessPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		res, err := h.answerGetBlockWitness(&req)
+		if err != nil {
+			return err
+		}
+		return p2p.Send(p.rw, BlockWitnessMsg, res)
+	case BlockWitnessMsg:
+		var res BlockWitnessPacket
+		if err := msg.Decode(&res); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		p.lock.Lock()
+		ch, ok := p.pending[res.RequestId]
+		delete(p.pending, res.RequestId)
+		p.lock.Unlock()
+		if !ok {
+			return fmt.Errorf("%w: unsolicited witnesses %d", errDecode, res.RequestId)
+		}
+		ch <- res.Witnesses
+		return nil
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+func (h *Handler) answerGetBlockWitness(req *GetBlockWitnessPacket) (*BlockWitnessPacket, error) {
+	res := &BlockWitnessPacket{RequestId: req.RequestId}
+	hashes := req.Hashes
+	if len(hashes) > maxWitnessesServe {
+		hashes = hashes[:maxWitnessesServe]
+	}
+	var size int
+	for _, hash := range hashes {
+		witness := &stateless.Witness{}
+		if h.generate != nil {
+			if err := h.db.View(h.ctx, func(tx kv.Tx) error {
+				generated, err := h.generate(h.ctx, tx, hash)
+				if err != nil {
+					// Too old or not executed yet, either way it is not available
+					h.logger.Debug("[wit] witness generation failed", "hash", hash, "err", err)
+					return nil
+				}
+				if generated != nil {
+					witness = generated
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		enc, err := rlp.EncodeToBytes(witness)
+		if err != nil {
+			return nil, err
+		}
+		res.Witnesses = append(res.Witnesses, witness)
+		if size += len(enc); size > softResponseLimit {
+			break
+		}
+	}
+	return res, nil
+}
+
+// FetchWitness requests the witness of a block from the connected peers, one
+// at a time, and returns the first available one. The witness is not
+// validated, which is up to the caller holding the block.
+func (h *Handler) FetchWitness(ctx context.Context, hash libcommon.Hash) (*stateless.Witness, error) {
+	h.peersLock.RLock()
+	peers := make([]*peer, 0, len(h.peers))
+	for _, p := range h.peers {
+		peers = append(peers, p)
+	}
+	h.peersLock.RUnlock()
+
+	for _, p := range peers {
+		witnesses, err := h.request(ctx, p, []libcommon.Hash{hash})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if len(witnesses) > 0 && witnesses[0] != nil && len(witnesses[0].Headers) > 0 {
+			return witnesses[0], nil
+		}
+	}
+	return nil, ErrNoWitness
+}
+
+func (h *Handler) request(ctx context.Context, p *peer, hashes []libcommon.Hash) ([]*stateless.Witness, error) {
+	id := h.requestID.Add(1)
+	ch := make(chan []*stateless.Witness, 1)
+	p.lock.Lock()
+	p.pending[id] = ch
+	p.lock.Unlock()
+	defer func() {
+		p.lock.Lock()
+		delete(p.pending, id)
+		p.lock.Unlock()
+	}()
+
+	if err := p2p.Send(p.rw, GetBlockWitnessMsg, &GetBlockWitnessPacket{RequestId: id, Hashes: hashes}); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+	select {
+	case witnesses := <-ch:
+		return witnesses, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("witness request %d timed out", id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,73 @@
+package wit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+)
+
+func TestFetchWitness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := memdb.NewTestDB(t)
+	logger := log.New()
+
+	known := libcommon.Hash{1}
+	witness := &stateless.Witness{
+		Headers: []*types.Header{{Number: big.NewInt(1), Root: types.EmptyRootHash}},
+		Codes:   [][]byte{{0x60, 0x00}},
+		State:   [][]byte{{0xc0}},
+	}
+	server := NewHandler(ctx, db, func(ctx context.Context, tx kv.Tx, hash libcommon.Hash) (*stateless.Witness, error) {
+		if hash == known {
+			return witness, nil
+		}
+		return nil, nil
+	}, logger)
+	client := NewHandler(ctx, db, nil, logger)
+
+	serverRw, clientRw := p2p.MsgPipe()
+	defer serverRw.Close()
+	go server.Handle(enode.ID{2}, serverRw) //nolint:errcheck
+	go client.Handle(enode.ID{1}, clientRw) //nolint:errcheck
+	require.Eventually(t, func() bool {
+		client.peersLock.RLock()
+		defer client.peersLock.RUnlock()
+		return len(client.peers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	got, err := client.FetchWitness(ctx, known)
+	require.NoError(t, err)
+	require.Equal(t, witness.Headers[0].Hash(), got.Headers[0].Hash())
+	require.Equal(t, witness.Codes, got.Codes)
+	require.Equal(t, witness.State, got.State)
+
+	_, err = client.FetchWitness(ctx, libcommon.Hash{2})
+	require.ErrorIs(t, err, ErrNoWitness)
+}
+
+func TestUnsolicitedWitness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := NewHandler(ctx, memdb.NewTestDB(t), nil, log.New())
+
+	local, remote := p2p.MsgPipe()
+	defer local.Close()
+	errc := make(chan error, 1)
+	go func() { errc <- h.Handle(enode.ID{1}, remote) }()
+	require.NoError(t, p2p.Send(local, BlockWitnessMsg, &BlockWitnessPacket{RequestId: 7}))
+	require.ErrorIs(t, <-errc, errDecode)
+}
@@ -0,0 +1,53 @@
+package wit
+
+import (
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/stateless"
+)
+
+// ProtocolName is the official short name of the `wit` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "wit"
+
+// Version0 is the only version of the `wit` protocol.
+const Version0 = 0
+
+// ProtocolLength is the number of implemented messages of the `wit` protocol.
+const ProtocolLength = 2
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 16 * 1024 * 1024
+
+const (
+	GetBlockWitnessMsg = 0x00
+	BlockWitnessMsg    = 0x01
+)
+
+var (
+	errMsgTooLarge    = fmt.Errorf("message too long")
+	errDecode         = fmt.Errorf("invalid message")
+	errInvalidMsgCode = fmt.Errorf("invalid message code")
+)
+
+// GetBlockWitnessPacket requests the execution witnesses of blocks.
+type GetBlockWitnessPacket struct {
+	RequestId uint64
+	Hashes    []libcommon.Hash
+}
+
+// BlockWitnessPacket is the response to GetBlockWitnessPacket. It has a
+// witness for each requested hash, in the same order, but may be shorter
+// than the request; a witness without headers stands for an unavailable one.
+type BlockWitnessPacket struct {
+	RequestId uint64
+	Witnesses []*stateless.Witness
+}
+
+func (*GetBlockWitnessPacket) Name() string { return "GetBlockWitness" }
+func (*GetBlockWitnessPacket) Kind() byte   { return GetBlockWitnessMsg }
+
+func (*BlockWitnessPacket) Name() string { return "BlockWitness" }
+func (*BlockWitnessPacket) Kind() byte   { return BlockWitnessMsg }
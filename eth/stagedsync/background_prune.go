@@ -0,0 +1,102 @@
+package stagedsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var (
+	backgroundPruneRounds = metrics.GetOrCreateCounter(`sync_prune_background_rounds`)
+	backgroundPruneErrors = metrics.GetOrCreateCounter(`sync_prune_background_errors`)
+	backgroundPruneTook   = metrics.GetOrCreateSummary(`sync_prune_background_seconds`)
+)
+
+// BackgroundPruner - prunes stages by own schedule, independently of sync loop (see ethconfig.Sync.PruneInterval).
+// Stages prune only data below progress which they committed to db (watermark), so the only coordination with sync
+// loop is serialisation of RwTx by db. Round is limited by same limits as pruning in sync loop (PruneLimit, prune timeouts of stages)
+// and rounds are separated by interval: pruning doesn't hold write lock of db long enough to delay new blocks.
+type BackgroundPruner struct {
+	sync     *Sync // own instance: doesn't share current stage and timings with sync loop
+	db       kv.RwDB
+	interval atomic.Int64 // time.Duration, changed by config reload
+	logger   log.Logger
+}
+
+// BackgroundPruner - nil if pruning is done by sync loop
+func (s *Sync) BackgroundPruner(db kv.RwDB) *BackgroundPruner {
+	if s.cfg.PruneInterval <= 0 {
+		return nil
+	}
+	p := &BackgroundPruner{
+		sync: &Sync{
+			cfg:           s.cfg,
+			stages:        s.stages,
+			unwindOrder:   s.unwindOrder,
+			pruningOrder:  s.pruningOrder,
+			logPrefixes:   s.logPrefixes,
+			logger:        s.logger,
+			stagesIdsList: s.stagesIdsList,
+			background:    true,
+		},
+		db:     db,
+		logger: s.logger,
+	}
+	p.interval.Store(int64(s.cfg.PruneInterval))
+	return p
+}
+
+// SetInterval - changes interval between rounds, starting from next round. Pruning can't be moved back to sync loop
+// at runtime, so interval must stay > 0
+func (p *BackgroundPruner) SetInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("background pruning can't be disabled without restart")
+	}
+	p.interval.Store(int64(interval))
+	p.logger.Info("[prune] Background pruning interval changed", "interval", interval)
+	return nil
+}
+
+// Run - prunes until `ctx` is cancelled. Errors of round are logged: next round will retry
+func (p *BackgroundPruner) Run(ctx context.Context) {
+	p.logger.Info("[prune] Background pruning started", "interval", time.Duration(p.interval.Load()))
+	timer := time.NewTimer(time.Duration(p.interval.Load()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := p.Round(ctx); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, libcommon.ErrStopped) {
+				return
+			}
+			backgroundPruneErrors.Inc()
+			p.logger.Warn("[prune] Background pruning failed", "err", err)
+		}
+		timer.Reset(time.Duration(p.interval.Load()))
+	}
+}
+
+// Round - prunes all stages in pruning order in one RwTx
+func (p *BackgroundPruner) Round(ctx context.Context) error {
+	start := time.Now()
+	if err := p.db.Update(ctx, func(tx kv.RwTx) error { return p.sync.RunPrune(p.db, tx, false) }); err != nil {
+		return err
+	}
+	backgroundPruneRounds.Inc()
+	backgroundPruneTook.ObserveDuration(start)
+	if logCtx := p.sync.PrintTimings(); len(logCtx) > 0 {
+		p.logger.Info("[prune] Background pruning round", append(logCtx, "in", time.Since(start))...)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package stagedsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/wrap"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+)
+
+func TestBackgroundPruner(t *testing.T) {
+	var pruned []uint64
+	s := []*Stage{
+		{
+			ID: stages.Headers,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, txc wrap.TxContainer, logger log.Logger) error {
+				return s.Update(txc.Tx, s.BlockNumber+100)
+			},
+			Prune: func(firstCycle bool, p *PruneState, tx kv.RwTx, logger log.Logger) error {
+				pruned = append(pruned, p.ForwardProgress)
+				return p.Done(tx)
+			},
+		},
+	}
+	cfg := ethconfig.Defaults.Sync
+	require.Nil(t, New(cfg, s, nil, []stages.SyncStage{stages.Headers}, log.New()).BackgroundPruner(nil))
+
+	cfg.PruneInterval = time.Minute
+	sync := New(cfg, s, nil, []stages.SyncStage{stages.Headers}, log.New())
+	db := memdb.NewTestDB(t)
+	require.NoError(t, db.Update(context.Background(), func(tx kv.RwTx) error {
+		if _, err := sync.Run(db, wrap.TxContainer{Tx: tx}, false); err != nil {
+			return err
+		}
+		// sync loop doesn't prune: it's done by background pruner
+		return sync.RunPrune(db, tx, false)
+	}))
+	require.Empty(t, pruned)
+
+	pruner := sync.BackgroundPruner(db)
+	require.NotNil(t, pruner)
+	require.NoError(t, pruner.Round(context.Background()))
+	require.Equal(t, []uint64{100}, pruned)
+
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		progress, err := stages.GetStagePruneProgress(tx, stages.Headers)
+		require.NoError(t, err)
+		require.Equal(t, uint64(100), progress)
+		return nil
+	}))
+}
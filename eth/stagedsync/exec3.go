@@ -18,6 +18,8 @@ import (
 	"github.com/ledgerwatch/erigon-lib/config3"
 	"github.com/ledgerwatch/erigon/consensus/aura"
 	"github.com/ledgerwatch/log/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ledgerwatch/erigon-lib/chain"
@@ -26,6 +28,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcfg"
@@ -33,6 +36,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
 	"github.com/ledgerwatch/erigon-lib/metrics"
 	state2 "github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 	"github.com/ledgerwatch/erigon-lib/wrap"
 	"github.com/ledgerwatch/erigon/cmd/state/exec3"
 	"github.com/ledgerwatch/erigon/common/math"
@@ -43,6 +47,7 @@ import (
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/turbo/services"
@@ -52,8 +57,9 @@ var execStepsInDB = metrics.NewGauge(`exec_steps_in_db`) //nolint
 var execRepeats = metrics.NewCounter(`exec_repeats`)     //nolint
 var execTriggers = metrics.NewCounter(`exec_triggers`)   //nolint
 
-func NewProgress(prevOutputBlockNum, commitThreshold uint64, workersCount int, logPrefix string, logger log.Logger) *Progress {
-	return &Progress{prevTime: time.Now(), prevOutputBlockNum: prevOutputBlockNum, commitThreshold: commitThreshold, workersCount: workersCount, logPrefix: logPrefix, logger: logger}
+func NewProgress(prevOutputBlockNum, maxBlockNum, commitThreshold uint64, workersCount int, logPrefix string, logger log.Logger) *Progress {
+	return &Progress{prevTime: time.Now(), prevOutputBlockNum: prevOutputBlockNum, commitThreshold: commitThreshold, workersCount: workersCount, logPrefix: logPrefix, logger: logger,
+		blocks: NewStageProgress(logPrefix, "block", prevOutputBlockNum, maxBlockNum+1)}
 }
 
 type Progress struct {
@@ -66,6 +72,7 @@ type Progress struct {
 	workersCount int
 	logPrefix    string
 	logger       log.Logger
+	blocks       *StageProgress
 }
 
 func (p *Progress) Log(rs *state.StateV3, in *state.QueueWithRetry, rws *state.ResultsQueue, doneCount, inputBlockNum, outputBlockNum, outTxNum, repeatCount uint64, idxStepsAmountInDB float64) {
@@ -80,9 +87,8 @@ func (p *Progress) Log(rs *state.StateV3, in *state.QueueWithRetry, rws *state.R
 	//if doneCount > p.prevCount {
 	//	repeatRatio = 100.0 * float64(repeatCount-p.prevRepeatCount) / float64(doneCount-p.prevCount)
 	//}
-	p.logger.Info(fmt.Sprintf("[%s] Transaction replay", p.logPrefix),
+	p.blocks.Log(p.logger, outputBlockNum,
 		//"workers", workerCount,
-		"blk", outputBlockNum,
 		"tx/s", fmt.Sprintf("%.1f", speedTx),
 		//"pipe", fmt.Sprintf("(%d+%d)->%d/%d->%d/%d", in.NewTasksLen(), in.RetriesLen(), rws.ResultChLen(), rws.ResultChCap(), rws.Len(), rws.Limit()),
 		//"repeatRatio", fmt.Sprintf("%.2f%%", repeatRatio),
@@ -99,6 +105,35 @@ func (p *Progress) Log(rs *state.StateV3, in *state.QueueWithRetry, rws *state.R
 	p.prevRepeatCount = repeatCount
 }
 
+// execCheckpoint - intermediate commits of large execution batch: domains are flushed and stage progress is committed
+// every `blocks` blocks or `interval` since previous commit (see ethconfig.Sync.ExecCheckpointBlocks), so restart after crash
+// re-executes at most one checkpoint interval. Commits by size of batch are done as before.
+type execCheckpoint struct {
+	blocks   uint64
+	interval time.Duration
+
+	lastBlock uint64
+	lastTime  time.Time
+}
+
+// newExecCheckpoint - `enabled=false` if stage can't commit: external tx or in-memory execution
+func newExecCheckpoint(cfg ethconfig.Sync, blockNum uint64, enabled bool) *execCheckpoint {
+	c := &execCheckpoint{lastBlock: blockNum, lastTime: time.Now()}
+	if enabled {
+		c.blocks, c.interval = cfg.ExecCheckpointBlocks, cfg.ExecCheckpointInterval
+	}
+	return c
+}
+
+// due - checkpoint must be committed after block `blockNum`
+func (c *execCheckpoint) due(blockNum uint64) bool {
+	return (c.blocks > 0 && blockNum >= c.lastBlock+c.blocks) || (c.interval > 0 && time.Since(c.lastTime) >= c.interval)
+}
+
+func (c *execCheckpoint) done(blockNum uint64) {
+	c.lastBlock, c.lastTime = blockNum, time.Now()
+}
+
 /*
 ExecV3 - parallel execution. Has many layers of abstractions - each layer does accumulate
 state changes (updates) and can "atomically commit all changes to underlying layer of abstraction"
@@ -156,13 +191,14 @@ func ExecV3(ctx context.Context,
 	chainConfig, genesis := cfg.chainConfig, cfg.genesis
 	blocksFreezeCfg := cfg.blockReader.FreezingCfg()
 
+	throttle := cfg.syncCfg.StageThrottle[string(stages.Execution)]
 	if initialCycle {
 		if _, ok := engine.(*aura.AuRa); ok { //gnosis collate eating too much RAM, will add ETL later
 			agg.SetCollateAndBuildWorkers(1)
 		} else {
-			agg.SetCollateAndBuildWorkers(min(2, estimate.StateV3Collate.Workers()))
+			agg.SetCollateAndBuildWorkers(throttle.LimitWorkers(min(2, estimate.StateV3Collate.Workers())))
 		}
-		agg.SetCompressWorkers(estimate.CompressSnapshot.Workers())
+		agg.SetCompressWorkers(throttle.LimitWorkers(estimate.CompressSnapshot.Workers()))
 		defer agg.DiscardHistory(kv.CommitmentDomain).EnableHistory(kv.CommitmentDomain)
 	} else {
 		agg.SetCompressWorkers(1)
@@ -325,8 +361,14 @@ func ExecV3(ctx context.Context,
 	defer stopWorkers()
 	applyWorker.DiscardReadList()
 
+	var parallelExec *exec3.ParallelExecutor
+	if parallelWorkers := throttle.LimitWorkers(cfg.syncCfg.ParallelExecWorkers); !parallel && parallelWorkers > 1 {
+		parallelExec = exec3.NewParallelExecutor(ctx, parallelWorkers, chainDb, rs, chainConfig, engine)
+	}
+
 	commitThreshold := batchSize.Bytes()
-	progress := NewProgress(blockNum, commitThreshold, workerCount, execStage.LogPrefix(), logger)
+	progress := NewProgress(blockNum, maxBlockNum, commitThreshold, workerCount, execStage.LogPrefix(), logger)
+	checkpoint := newExecCheckpoint(cfg.syncCfg, blockNum, !useExternalTx && !inMemExec)
 	logEvery := time.NewTicker(20 * time.Second)
 	defer logEvery.Stop()
 	pruneEvery := time.NewTicker(2 * time.Second)
@@ -416,7 +458,7 @@ func ExecV3(ctx context.Context,
 						logger.Info(fmt.Sprintf("[%s] Background files build", execStage.LogPrefix()), "progress", agg.BackgroundProgress())
 					}
 				case <-pruneEvery.C:
-					if rs.SizeEstimate() < commitThreshold {
+					if rs.SizeEstimate() < commitThreshold && !checkpoint.due(outputBlockNum.GetValueUint64()) {
 						if doms.BlockNum() != outputBlockNum.GetValueUint64() {
 							panic(fmt.Errorf("%d != %d", doms.BlockNum(), outputBlockNum.GetValueUint64()))
 						}
@@ -529,6 +571,7 @@ func ExecV3(ctx context.Context,
 					applyLoopWg.Add(1)
 					go applyLoop(applyCtx, rwLoopErrCh)
 
+					checkpoint.done(outputBlockNum.GetValueUint64())
 					logger.Info("Committed", "time", time.Since(commitStart), "drain", t0, "drain_and_lock", t1, "rs.flush", t2, "agg.flush", t3, "tx.commit", t4)
 				}
 			}
@@ -600,15 +643,27 @@ func ExecV3(ctx context.Context,
 		// can't use OS-level ReadAhead - because Data >> RAM
 		// it also warmsup state a bit - by touching senders/coninbase accounts and code
 		var clean func()
-		readAhead, clean = blocksReadAhead(ctx, &cfg, 4, engine, true)
+		readAhead, clean = blocksReadAhead(ctx, &cfg, throttle.LimitWorkers(4), engine, true)
 		defer clean()
 	}
 
 	//fmt.Printf("exec blocks: %d -> %d\n", blockNum, maxBlockNum)
 
 	var b *types.Block
+	var blockSpan trace.Span
+	endBlockSpan := func() {
+		if blockSpan != nil {
+			blockSpan.SetAttributes(doms.TraceAttributes()...)
+			blockSpan.End()
+			blockSpan = nil
+		}
+	}
+	defer endBlockSpan()
 Loop:
 	for ; blockNum <= maxBlockNum; blockNum++ {
+		endBlockSpan()
+		var blockCtx context.Context
+		blockCtx, blockSpan = tracing.Start(ctx, "exec block", attribute.Int64("block", int64(blockNum)))
 		//time.Sleep(50 * time.Microsecond)
 		if !parallel {
 			select {
@@ -629,6 +684,7 @@ Loop:
 		}
 		txs := b.Transactions()
 		header := b.HeaderNoCopy()
+		blockSpan.SetAttributes(attribute.Int("txs", len(txs)))
 		skipAnalysis := core.SkipAnalysis(chainConfig, blockNum)
 		signer := *types.MakeSigner(chainConfig, blockNum, header.Time)
 
@@ -753,7 +809,16 @@ Loop:
 				if txTask.Error != nil {
 					break Loop
 				}
-				applyWorker.RunTxTaskNoLock(txTask)
+				if parallelExec != nil && txTask.TxIndex == 0 && offsetFromBlockBeginning == 0 {
+					if err := parallelExec.Execute(txTask, &signer); err != nil {
+						return err
+					}
+				}
+				if applied, err := parallelExec.Apply(applyWorker, txTask); err != nil {
+					return err
+				} else if !applied {
+					applyWorker.RunTxTaskNoLock(txTask)
+				}
 				if err := func() error {
 					if errors.Is(txTask.Error, context.Canceled) {
 						return err
@@ -850,13 +915,19 @@ Loop:
 
 			outputBlockNum.SetUint64(blockNum)
 
+			commit := checkpoint.due(blockNum)
 			select {
 			case <-logEvery.C:
 				stepsInDB := rawdbhelpers.IdxStepsCountV3(applyTx)
 				progress.Log(rs, in, rws, count, inputBlockNum.Load(), outputBlockNum.GetValueUint64(), outputTxNum.Load(), execRepeats.GetValueUint64(), stepsInDB)
 				// If we skip post evaluation, then we should compute root hash ASAP for fail-fast
-				if !skipPostEvaluation && (rs.SizeEstimate() < commitThreshold || inMemExec) {
-					break
+				commit = commit || skipPostEvaluation || (rs.SizeEstimate() >= commitThreshold && !inMemExec)
+			default:
+			}
+			if commit {
+				// don't start commit which may not fit into disk: mdbx doesn't survive disk full in the middle of it
+				if err := disk.CheckFreeSpace(disk.FreeSpaceCritical); err != nil {
+					return err
 				}
 				var (
 					commitStart = time.Now()
@@ -865,7 +936,7 @@ Loop:
 					t1, t2, t3 time.Duration
 				)
 
-				if ok, err := flushAndCheckCommitmentV3(ctx, b.HeaderNoCopy(), applyTx, doms, cfg, execStage, stageProgress, parallel, logger, u, inMemExec); err != nil {
+				if ok, err := flushAndCheckCommitmentV3(blockCtx, b.HeaderNoCopy(), applyTx, doms, cfg, execStage, stageProgress, parallel, logger, u, inMemExec); err != nil {
 					return err
 				} else if !ok {
 					break Loop
@@ -925,16 +996,17 @@ Loop:
 
 					applyWorker.ResetTx(applyTx)
 					applyWorker.ResetState(rs)
+					parallelExec.ResetState(rs)
 
 					return nil
 				}(); err != nil {
 					return err
 				}
+				checkpoint.done(blockNum)
 				logger.Info("Committed", "time", time.Since(commitStart),
 					"block", doms.BlockNum(), "txNum", doms.TxNum(),
 					"step", fmt.Sprintf("%.1f", float64(doms.TxNum())/float64(agg.StepSize())),
 					"flush+commitment", t1, "tx.commit", t2, "prune", t3)
-			default:
 			}
 		}
 
@@ -1186,7 +1258,13 @@ func processResultQueue(ctx context.Context, in *state.QueueWithRetry, rws *stat
 	outputTxNum = outputTxNumIn
 	for rwsIt.HasNext(outputTxNum) {
 		txTask := rwsIt.PopNext()
-		if txTask.Error != nil || !rs.ReadsValid(txTask.ReadLists) {
+		valid := txTask.Error == nil
+		if valid {
+			if valid, err = rs.ReadsValid(txTask.ReadLists); err != nil {
+				return outputTxNum, conflicts, triggers, processedBlockNum, false, err
+			}
+		}
+		if !valid {
 			conflicts++
 
 			if i > 0 && canRetry {
@@ -0,0 +1,167 @@
+package stagedsync
+
+import (
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// MiningTxSelector - policy of transaction selection of block production stage (see SpawnMiningExecStage):
+// which bundles go on top of block, how much blob gas block may use and in which order transactions yielded
+// by txpool are packed and executed. Default one is built from params.MiningConfig, custom one can be passed to StageMiningExecCfg
+type MiningTxSelector interface {
+	// Bundles - groups of transactions executed on top of block, before txpool transactions.
+	// Group is included all-or-nothing: if one of its transactions fails - whole group is reverted
+	Bundles(header *types.Header) ([]types.Transactions, error)
+	// MaxBlobGas - blob gas which block may use, not more than `chainMax`
+	MaxBlobGas(header *types.Header, chainMax uint64) uint64
+	// PackBlobs - drops blob transactions of batch of txpool transactions which don't fit into `availableBlobGas`
+	// together with the ones chosen. `locals` - hashes of transactions submitted to this node
+	PackBlobs(header *types.Header, txs []types.Transaction, locals mapset.Set[libcommon.Hash], availableBlobGas uint64) []types.Transaction
+	// Order - execution order of batch of txpool transactions. Transactions of same sender must stay in nonce order
+	Order(header *types.Header, txs []types.Transaction, locals mapset.Set[libcommon.Hash]) []types.Transaction
+}
+
+type miningTxPolicy struct {
+	ordering   string
+	maxBlobs   uint64
+	localBoost uint64
+}
+
+// NewMiningTxSelector - selector by `--miner.txordering`, `--miner.maxblobs` and `--miner.localboost`, without bundles
+func NewMiningTxSelector(cfg *params.MiningConfig) MiningTxSelector {
+	if cfg == nil {
+		return miningTxPolicy{}
+	}
+	return miningTxPolicy{ordering: cfg.TxOrdering, maxBlobs: cfg.MaxBlobsPerBlock, localBoost: cfg.LocalTxBoost}
+}
+
+func (p miningTxPolicy) Bundles(*types.Header) ([]types.Transactions, error) { return nil, nil }
+
+func (p miningTxPolicy) MaxBlobGas(_ *types.Header, chainMax uint64) uint64 {
+	if p.maxBlobs == 0 {
+		return chainMax
+	}
+	return min(chainMax, p.maxBlobs*fixedgas.BlobGasPerBlob)
+}
+
+func (p miningTxPolicy) PackBlobs(header *types.Header, txs []types.Transaction, locals mapset.Set[libcommon.Hash], availableBlobGas uint64) []types.Transaction {
+	return packBlobs(txs, p.tipFn(header, locals), availableBlobGas/fixedgas.BlobGasPerBlob)
+}
+
+func (p miningTxPolicy) Order(header *types.Header, txs []types.Transaction, locals mapset.Set[libcommon.Hash]) []types.Transaction {
+	if p.ordering != params.MiningTxOrderingTip || len(txs) < 2 {
+		return txs
+	}
+	return orderByTip(txs, p.tipFn(header, locals))
+}
+
+// tipFn - effective tip at header's base fee, raised by `localBoost` percent for local transactions
+func (p miningTxPolicy) tipFn(header *types.Header, locals mapset.Set[libcommon.Hash]) func(types.Transaction) *uint256.Int {
+	var baseFee *uint256.Int
+	if header.BaseFee != nil {
+		baseFee, _ = uint256.FromBig(header.BaseFee)
+	}
+	return func(txn types.Transaction) *uint256.Int {
+		tip := txn.GetEffectiveGasTip(baseFee)
+		if p.localBoost == 0 || locals == nil || !locals.Contains(txn.Hash()) {
+			return tip
+		}
+		boost := new(uint256.Int).Mul(tip, uint256.NewInt(p.localBoost))
+		return boost.Add(tip, boost.Div(boost, uint256.NewInt(100)))
+	}
+}
+
+// packBlobs - keeps the set of blob transactions paying most in tips (tip * gas) whose blobs fit into `maxBlobs`
+// (0/1 knapsack by blob count, which is small), instead of first ones which fit. Later transactions of sender
+// whose blob transaction is dropped are dropped too: they would fail on nonce. Non-blob transactions are kept
+func packBlobs(txs []types.Transaction, tip func(types.Transaction) *uint256.Int, maxBlobs uint64) []types.Transaction {
+	var blobTxs []int
+	var blobs uint64
+	for i, txn := range txs {
+		if n := uint64(len(txn.GetBlobHashes())); n > 0 {
+			blobTxs = append(blobTxs, i)
+			blobs += n
+		}
+	}
+	if blobs <= maxBlobs {
+		return txs
+	}
+
+	// best[c] - max value of chosen transactions using at most c blobs, taken[k][c] - whether k-th blob tx is in it
+	best := make([]uint256.Int, maxBlobs+1)
+	taken := make([][]bool, len(blobTxs))
+	for k, i := range blobTxs {
+		taken[k] = make([]bool, maxBlobs+1)
+		n := uint64(len(txs[i].GetBlobHashes()))
+		if n > maxBlobs {
+			continue
+		}
+		value := new(uint256.Int).Mul(tip(txs[i]), uint256.NewInt(txs[i].GetGas()))
+		for c := maxBlobs; c >= n; c-- {
+			if with := new(uint256.Int).Add(&best[c-n], value); with.Gt(&best[c]) {
+				best[c] = *with
+				taken[k][c] = true
+			}
+		}
+	}
+	chosen := make(map[int]struct{}, len(blobTxs))
+	for k, c := len(blobTxs)-1, maxBlobs; k >= 0; k-- {
+		if taken[k][c] {
+			chosen[blobTxs[k]] = struct{}{}
+			c -= uint64(len(txs[blobTxs[k]].GetBlobHashes()))
+		}
+	}
+
+	packed := make([]types.Transaction, 0, len(txs))
+	dropped := map[libcommon.Address]struct{}{}
+	for i, txn := range txs {
+		sender, _ := txn.GetSender()
+		if _, ok := dropped[sender]; ok {
+			continue
+		}
+		if _, ok := chosen[i]; !ok && len(txn.GetBlobHashes()) > 0 {
+			dropped[sender] = struct{}{}
+			continue
+		}
+		packed = append(packed, txn)
+	}
+	return packed
+}
+
+// orderByTip - picks transaction with highest effective tip among first not-picked transactions of each sender:
+// order of transactions of same sender is kept. Ties keep order of `txs`
+func orderByTip(txs []types.Transaction, tip func(types.Transaction) *uint256.Int) []types.Transaction {
+	var senders []libcommon.Address
+	bySender := map[libcommon.Address][]types.Transaction{}
+	for _, txn := range txs {
+		sender, _ := txn.GetSender()
+		if _, ok := bySender[sender]; !ok {
+			senders = append(senders, sender)
+		}
+		bySender[sender] = append(bySender[sender], txn)
+	}
+
+	ordered := make([]types.Transaction, 0, len(txs))
+	for len(ordered) < len(txs) {
+		var best *libcommon.Address
+		var bestTip *uint256.Int
+		for i := range senders {
+			queue := bySender[senders[i]]
+			if len(queue) == 0 {
+				continue
+			}
+			if queueTip := tip(queue[0]); bestTip == nil || queueTip.Gt(bestTip) {
+				best, bestTip = &senders[i], queueTip
+			}
+		}
+		ordered = append(ordered, bySender[*best][0])
+		bySender[*best] = bySender[*best][1:]
+	}
+	return ordered
+}
@@ -0,0 +1,90 @@
+package stagedsync
+
+import (
+	"math/big"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func TestMiningTxSelector(t *testing.T) {
+	alice, bob := libcommon.Address{1}, libcommon.Address{2}
+	newTx := func(sender libcommon.Address, nonce, tip uint64) types.Transaction {
+		txn := types.NewEIP1559Transaction(*uint256.NewInt(1), nonce, libcommon.Address{}, uint256.NewInt(0), 21_000, nil, uint256.NewInt(tip), uint256.NewInt(100+tip), nil)
+		txn.SetSender(sender)
+		return txn
+	}
+	header := &types.Header{BaseFee: big.NewInt(100)}
+	txs := []types.Transaction{newTx(alice, 0, 1), newTx(alice, 1, 10), newTx(bob, 0, 5), newTx(bob, 1, 3)}
+
+	pool := NewMiningTxSelector(&params.MiningConfig{TxOrdering: params.MiningTxOrderingPool})
+	require.Equal(t, txs, pool.Order(header, txs, nil))
+	require.Equal(t, uint64(6*fixedgas.BlobGasPerBlob), pool.MaxBlobGas(header, 6*fixedgas.BlobGasPerBlob))
+	bundles, err := pool.Bundles(header)
+	require.NoError(t, err)
+	require.Empty(t, bundles)
+
+	tip := NewMiningTxSelector(&params.MiningConfig{TxOrdering: params.MiningTxOrderingTip, MaxBlobsPerBlock: 2})
+	// alice's nonce 1 pays most, but can't go before her nonce 0
+	require.Equal(t, []types.Transaction{txs[2], txs[3], txs[0], txs[1]}, tip.Order(header, txs, nil))
+	require.Equal(t, uint64(2*fixedgas.BlobGasPerBlob), tip.MaxBlobGas(header, 6*fixedgas.BlobGasPerBlob))
+	require.Equal(t, uint64(fixedgas.BlobGasPerBlob), tip.MaxBlobGas(header, fixedgas.BlobGasPerBlob))
+}
+
+func TestMiningTxSelectorLocalBoost(t *testing.T) {
+	alice, bob := libcommon.Address{1}, libcommon.Address{2}
+	aliceTx := types.NewEIP1559Transaction(*uint256.NewInt(1), 0, libcommon.Address{}, uint256.NewInt(0), 21_000, nil, uint256.NewInt(10), uint256.NewInt(110), nil)
+	aliceTx.SetSender(alice)
+	bobTx := types.NewEIP1559Transaction(*uint256.NewInt(1), 0, libcommon.Address{}, uint256.NewInt(0), 21_000, nil, uint256.NewInt(12), uint256.NewInt(112), nil)
+	bobTx.SetSender(bob)
+	header := &types.Header{BaseFee: big.NewInt(100)}
+	txs := []types.Transaction{aliceTx, bobTx}
+	locals := mapset.NewThreadUnsafeSet[libcommon.Hash](aliceTx.Hash())
+
+	noBoost := NewMiningTxSelector(&params.MiningConfig{TxOrdering: params.MiningTxOrderingTip})
+	require.Equal(t, []types.Transaction{bobTx, aliceTx}, noBoost.Order(header, txs, locals))
+	// 10 * 1.5 > 12
+	boost := NewMiningTxSelector(&params.MiningConfig{TxOrdering: params.MiningTxOrderingTip, LocalTxBoost: 50})
+	require.Equal(t, []types.Transaction{bobTx, aliceTx}, boost.Order(header, txs, nil))
+	require.Equal(t, []types.Transaction{aliceTx, bobTx}, boost.Order(header, txs, locals))
+	require.Equal(t, []types.Transaction{bobTx, aliceTx}, boost.Order(header, []types.Transaction{bobTx, aliceTx}, mapset.NewThreadUnsafeSet[libcommon.Hash]()))
+}
+
+func TestMiningTxSelectorPackBlobs(t *testing.T) {
+	newBlobTx := func(sender libcommon.Address, nonce, tip uint64, blobs int) types.Transaction {
+		txn := &types.BlobTx{
+			DynamicFeeTransaction: *types.NewEIP1559Transaction(*uint256.NewInt(1), nonce, libcommon.Address{}, uint256.NewInt(0), 21_000, nil, uint256.NewInt(tip), uint256.NewInt(100+tip), nil),
+			MaxFeePerBlobGas:      uint256.NewInt(1),
+			BlobVersionedHashes:   make([]libcommon.Hash, blobs),
+		}
+		txn.SetSender(sender)
+		return txn
+	}
+	alice, bob, carol := libcommon.Address{1}, libcommon.Address{2}, libcommon.Address{3}
+	header := &types.Header{BaseFee: big.NewInt(100)}
+	selector := NewMiningTxSelector(&params.MiningConfig{})
+
+	big4 := newBlobTx(alice, 0, 10, 4)       // first come, but 2+2 pay more
+	aliceNext := newBlobTx(alice, 1, 100, 0) // can't go without alice's blob tx
+	small1 := newBlobTx(bob, 0, 8, 2)
+	small2 := newBlobTx(carol, 0, 7, 2)
+	txs := []types.Transaction{big4, aliceNext, small1, small2}
+
+	require.Equal(t, []types.Transaction{small1, small2}, selector.PackBlobs(header, txs, nil, 4*fixedgas.BlobGasPerBlob))
+	require.Equal(t, txs, selector.PackBlobs(header, txs, nil, 8*fixedgas.BlobGasPerBlob))
+	require.Equal(t, []types.Transaction{small1}, selector.PackBlobs(header, txs, nil, 3*fixedgas.BlobGasPerBlob))
+	require.Equal(t, []types.Transaction{aliceNext}, selector.PackBlobs(header, []types.Transaction{small1, aliceNext}, nil, fixedgas.BlobGasPerBlob))
+
+	// boosted local transaction wins over the pair
+	boost := NewMiningTxSelector(&params.MiningConfig{LocalTxBoost: 100})
+	locals := mapset.NewThreadUnsafeSet[libcommon.Hash](big4.Hash())
+	require.Equal(t, []types.Transaction{big4, aliceNext}, boost.PackBlobs(header, txs, locals, 4*fixedgas.BlobGasPerBlob))
+}
@@ -1,10 +1,13 @@
 package stagedsync
 
 import (
+	"context"
+
 	"github.com/ledgerwatch/log/v3"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 	"github.com/ledgerwatch/erigon-lib/wrap"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 )
@@ -49,6 +52,11 @@ type StageState struct {
 
 func (s *StageState) LogPrefix() string { return s.state.LogPrefix() }
 
+// TraceContext - ctx with span of running stage: stages capture node ctx, spans of their internals use it
+func (s *StageState) TraceContext(ctx context.Context) context.Context {
+	return tracing.WithSpanOf(ctx, s.state.stageCtx)
+}
+
 // Update updates the stage state (current block number) in the database. Can be called multiple times during stage execution.
 func (s *StageState) Update(db kv.Putter, newBlockNum uint64) error {
 	return stages.SaveStageProgress(db, s.ID, newBlockNum)
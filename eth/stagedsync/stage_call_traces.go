@@ -106,7 +106,7 @@ func promoteCallTraces(logPrefix string, tx kv.RwTx, startBlock, endBlock uint64
 	defer traceCursor.Close()
 
 	var k, v []byte
-	prev := startBlock
+	progress := NewStageProgress(logPrefix, "block", startBlock, endBlock+1)
 	for k, v, err = traceCursor.Seek(hexutility.EncodeTs(startBlock)); k != nil; k, v, err = traceCursor.Next() {
 		if err != nil {
 			return err
@@ -140,13 +140,7 @@ func promoteCallTraces(logPrefix string, tx kv.RwTx, startBlock, endBlock uint64
 		case <-logEvery.C:
 			var m runtime.MemStats
 			dbg.ReadMemStats(&m)
-			speed := float64(blockNum-prev) / float64(logInterval/time.Second)
-			prev = blockNum
-
-			logger.Info(fmt.Sprintf("[%s] Progress", logPrefix), "number", blockNum,
-				"blk/second", speed,
-				"alloc", libcommon.ByteCount(m.Alloc),
-				"sys", libcommon.ByteCount(m.Sys))
+			progress.Log(logger, blockNum, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
 		case <-checkFlushEvery.C:
 			if needFlush64(froms, bufLimit) {
 				if err := flushBitmaps64(collectorFrom, froms); err != nil {
@@ -310,7 +304,7 @@ func DoUnwindCallTraces(logPrefix string, db kv.RwTx, from, to uint64, ctx conte
 	defer traceCursor.Close()
 
 	var k, v []byte
-	prev := to + 1
+	progress := NewStageProgress(logPrefix, "block", to+1, from)
 	for k, v, err = traceCursor.Seek(hexutility.EncodeTs(to + 1)); k != nil; k, v, err = traceCursor.Next() {
 		if err != nil {
 			return err
@@ -337,13 +331,7 @@ func DoUnwindCallTraces(logPrefix string, db kv.RwTx, from, to uint64, ctx conte
 		case <-logEvery.C:
 			var m runtime.MemStats
 			dbg.ReadMemStats(&m)
-			speed := float64(blockNum-prev) / float64(logInterval/time.Second)
-			prev = blockNum
-
-			logger.Info(fmt.Sprintf("[%s] Progress", logPrefix), "number", blockNum,
-				"blk/second", speed,
-				"alloc", libcommon.ByteCount(m.Alloc),
-				"sys", libcommon.ByteCount(m.Sys))
+			progress.Log(logger, blockNum, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
 		case <-ctx.Done():
 			return libcommon.ErrStopped
 		default:
@@ -11,7 +11,6 @@ import (
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon-lib/config3"
-	"github.com/ledgerwatch/erigon-lib/kv/temporal"
 	"github.com/ledgerwatch/log/v3"
 	"golang.org/x/sync/errgroup"
 
@@ -265,7 +264,7 @@ func newStateReaderWriter(
 // ================ Erigon3 ================
 
 func ExecBlockV3(s *StageState, u Unwinder, txc wrap.TxContainer, toBlock uint64, ctx context.Context, cfg ExecuteBlockCfg, initialCycle bool, logger log.Logger) (err error) {
-	workersCount := cfg.syncCfg.ExecWorkerCount
+	workersCount := cfg.syncCfg.StageThrottle[string(stages.Execution)].LimitWorkers(cfg.syncCfg.ExecWorkerCount)
 	if !initialCycle {
 		workersCount = 1
 	}
@@ -305,7 +304,7 @@ func ExecBlockV3(s *StageState, u Unwinder, txc wrap.TxContainer, toBlock uint64
 	}
 
 	parallel := txc.Tx == nil
-	if err := ExecV3(ctx, s, u, workersCount, cfg, txc, parallel, to, logger, initialCycle); err != nil {
+	if err := ExecV3(s.TraceContext(ctx), s, u, workersCount, cfg, txc, parallel, to, logger, initialCycle); err != nil {
 		return fmt.Errorf("ExecV3: %w", err)
 	}
 	return nil
@@ -699,6 +698,11 @@ func blocksReadAhead(ctx context.Context, cfg *ExecuteBlockCfg, workers int, eng
 				if err := blocksReadAheadFunc(gCtx, tx, cfg, bn+readAheadBlocks, engine, histV3); err != nil {
 					return err
 				}
+				if histV3 && dbg.ExecPrefetchState {
+					if err := prefetchStateV3(gCtx, tx, cfg, bn+1); err != nil {
+						return err
+					}
+				}
 			}
 		})
 	}
@@ -750,6 +754,58 @@ func blocksReadAheadFunc(ctx context.Context, tx kv.Tx, cfg *ExecuteBlockCfg, bl
 	return nil
 }
 
+// prefetchStateV3 - reads state which block is likely to touch: accounts and code of senders, recipients and coinbase,
+// storage slots of access lists. Values are not used: it moves random reads of files/db out of execution's critical path.
+func prefetchStateV3(ctx context.Context, tx kv.Tx, cfg *ExecuteBlockCfg, blockNum uint64) error {
+	ttx, ok := tx.(kv.TemporalTx)
+	if !ok {
+		return nil
+	}
+	hash, err := cfg.blockReader.CanonicalHash(ctx, tx, blockNum)
+	if err != nil {
+		return err
+	}
+	block, senders, err := cfg.blockReader.BlockWithSenders(ctx, tx, hash, blockNum)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return nil
+	}
+
+	var acc accounts.Account
+	touchAccount := func(addr common.Address) {
+		enc, _, err := ttx.DomainGet(kv.AccountsDomain, addr[:], nil)
+		if err != nil || len(enc) == 0 {
+			return
+		}
+		if err := accounts.DeserialiseV3(&acc, enc); err != nil || acc.IsEmptyCodeHash() {
+			return
+		}
+		_, _, _ = ttx.DomainGet(kv.CodeDomain, addr[:], nil)
+	}
+
+	touchAccount(block.Coinbase())
+	for i, txn := range block.Transactions() {
+		if err := common.Stopped(ctx.Done()); err != nil {
+			return nil
+		}
+		if i < len(senders) {
+			touchAccount(senders[i])
+		}
+		if to := txn.GetTo(); to != nil {
+			touchAccount(*to)
+		}
+		for _, tuple := range txn.GetAccessList() {
+			touchAccount(tuple.Address)
+			for _, key := range tuple.StorageKeys {
+				_, _, _ = ttx.DomainGet(kv.StorageDomain, tuple.Address[:], key[:])
+			}
+		}
+	}
+	return nil
+}
+
 func logProgress(logPrefix string, prevBlock uint64, prevTime time.Time, currentBlock uint64, prevTx, currentTx uint64, gas uint64,
 	gasState float64, batch kv.PendingMutations, logger log.Logger, from uint64, to uint64, startTime time.Time) (uint64, uint64, time.Time) {
 	currentTime := time.Now()
@@ -994,7 +1050,7 @@ func PruneExecutionStage(s *PruneState, tx kv.RwTx, cfg ExecuteBlockCfg, ctx con
 		if initialCycle {
 			pruneTimeout = 12 * time.Hour
 		}
-		if _, err = tx.(*temporal.Tx).AggCtx().(*libstate.AggregatorRoTx).PruneSmallBatches(ctx, pruneTimeout, tx); err != nil { // prune part of retired data, before commit
+		if _, err = tx.(libstate.HasAggCtx).AggCtx().(*libstate.AggregatorRoTx).PruneSmallBatches(ctx, pruneTimeout, tx); err != nil { // prune part of retired data, before commit
 			return err
 		}
 	} else {
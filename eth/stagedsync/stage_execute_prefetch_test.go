@@ -0,0 +1,77 @@
+package stagedsync_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	types2 "github.com/ledgerwatch/erigon-lib/types"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+)
+
+// prefetch of next block's state only reads: execution results must be same with and without it
+func TestExecPrefetchState(t *testing.T) {
+	defer func(v bool) { dbg.ExecPrefetchState = v }(dbg.ExecPrefetchState)
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		counter = libcommon.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		gspec   = &types.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				address: {Balance: big.NewInt(1_000_000_000_000)},
+				// slot 0 += 1
+				counter: {Code: []byte{0x60, 0x01, 0x60, 0x00, 0x54, 0x01, 0x60, 0x00, 0x55}, Balance: big.NewInt(0)},
+			},
+		}
+	)
+
+	run := func(prefetch bool) (root libcommon.Hash, slot []byte) {
+		dbg.ExecPrefetchState = prefetch
+		m := mock.MockWithGenesis(t, gspec, key, false)
+		require.True(t, m.HistoryV3)
+		signer := types.LatestSigner(gspec.Config)
+		chainID, _ := uint256.FromBig(gspec.Config.ChainID)
+		chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 20, func(i int, b *core.BlockGen) {
+			b.SetCoinbase(libcommon.Address{1})
+			tx, err := types.SignNewTx(key, *signer, &types.AccessListTx{
+				ChainID: chainID,
+				LegacyTx: types.LegacyTx{
+					CommonTx: types.CommonTx{Nonce: b.TxNonce(address), To: &counter, Gas: 100_000},
+					GasPrice: uint256.NewInt(1),
+				},
+				AccessList: types2.AccessList{{Address: counter, StorageKeys: []libcommon.Hash{{}}}},
+			})
+			require.NoError(t, err)
+			b.AddTx(tx)
+		})
+		require.NoError(t, err)
+		require.NoError(t, m.InsertChain(chain)) // checks state root of each block
+
+		tx, err := m.DB.BeginRo(m.Ctx)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		header := rawdb.ReadCurrentHeader(tx)
+		slot, _, err = tx.(kv.TemporalTx).DomainGet(kv.StorageDomain, counter[:], make([]byte, 32))
+		require.NoError(t, err)
+		return header.Root, slot
+	}
+
+	root, slot := run(false)
+	prefetchRoot, prefetchSlot := run(true)
+	require.Equal(t, root, prefetchRoot)
+	require.Equal(t, slot, prefetchSlot)
+	require.Equal(t, []byte{20}, slot)
+}
@@ -3,6 +3,7 @@ package stagedsync
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ledgerwatch/erigon-lib/config3"
 	"github.com/ledgerwatch/log/v3"
@@ -13,6 +14,7 @@ import (
 	libstate "github.com/ledgerwatch/erigon-lib/state"
 
 	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/params"
 )
 
@@ -64,3 +66,20 @@ func newAgg(t *testing.T, logger log.Logger) *libstate.Aggregator {
 	require.NoError(t, err)
 	return agg
 }
+
+func TestExecCheckpoint(t *testing.T) {
+	cfg := ethconfig.Sync{ExecCheckpointBlocks: 100}
+	c := newExecCheckpoint(cfg, 1000, true)
+	require.False(t, c.due(1099))
+	require.True(t, c.due(1100))
+	c.done(1100)
+	require.False(t, c.due(1150))
+
+	// external tx: stage doesn't commit
+	require.False(t, newExecCheckpoint(cfg, 1000, false).due(5000))
+
+	c = newExecCheckpoint(ethconfig.Sync{ExecCheckpointInterval: time.Minute}, 1000, true)
+	require.False(t, c.due(5000))
+	c.lastTime = time.Now().Add(-2 * time.Minute)
+	require.True(t, c.due(1001))
+}
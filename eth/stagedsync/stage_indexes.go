@@ -141,6 +141,7 @@ func SpawnStorageHistoryIndex(s *StageState, tx kv.RwTx, cfg HistoryCfg, ctx con
 func promoteHistory(logPrefix string, tx kv.RwTx, changesetBucket string, start, stop uint64, cfg HistoryCfg, quit <-chan struct{}, logger log.Logger) error {
 	logEvery := time.NewTicker(30 * time.Second)
 	defer logEvery.Stop()
+	progress := NewStageProgress(logPrefix, "block", start, stop)
 
 	updates := map[string]*roaring64.Bitmap{}
 	checkFlushEvery := time.NewTicker(cfg.flushEvery)
@@ -161,7 +162,7 @@ func promoteHistory(logPrefix string, tx kv.RwTx, changesetBucket string, start,
 		case <-logEvery.C:
 			var m runtime.MemStats
 			dbg.ReadMemStats(&m)
-			log.Info(fmt.Sprintf("[%s] Progress", logPrefix), "number", blockN, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
+			progress.Log(logger, blockN, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
 		case <-checkFlushEvery.C:
 			if needFlush64(updates, cfg.bufLimit) {
 				if err := flushBitmaps64(collectorUpdates, updates); err != nil {
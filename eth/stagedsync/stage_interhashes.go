@@ -42,6 +42,8 @@ type TrieCfg struct {
 
 	historyV3 bool
 	agg       *state.Aggregator
+
+	workers int // full regeneration in parallel, see WithWorkers
 }
 
 func StageTrieCfg(db kv.RwDB, checkRoot, saveNewHashesToDB, badBlockHalt bool, tmpDir string, blockReader services.FullBlockReader, hd *headerdownload.HeaderDownload, historyV3 bool, agg *state.Aggregator) TrieCfg {
@@ -59,6 +61,13 @@ func StageTrieCfg(db kv.RwDB, checkRoot, saveNewHashesToDB, badBlockHalt bool, t
 	}
 }
 
+// WithWorkers - full regeneration of trie hashes builds sub-tries in parallel, in own read-only transactions.
+// Only for callers which have state committed to db (integration tool): workers don't see changes of stage's tx.
+func (cfg TrieCfg) WithWorkers(workers int) TrieCfg {
+	cfg.workers = workers
+	return cfg
+}
+
 var ErrInvalidStateRootHash = fmt.Errorf("invalid state root hash")
 
 func SpawnIntermediateHashesStage(s *StageState, u Unwinder, tx kv.RwTx, cfg TrieCfg, ctx context.Context, logger log.Logger) (libcommon.Hash, error) {
@@ -164,13 +173,19 @@ func RegenerateIntermediateHashes(logPrefix string, db kv.RwTx, cfg TrieCfg, exp
 	defer clean()
 	clean2 := kv.ReadAhead(ctx, cfg.db, &atomic.Bool{}, kv.HashedStorage, nil, math.MaxUint32)
 	defer clean2()
+	if cfg.workers > 1 {
+		return regenerateIntermediateHashesParallel(logPrefix, db, cfg, expectedRootHash, ctx, logger)
+	}
 
+	// full regeneration spills whole trie to tmpdir - branches compress well
 	accTrieCollector := etl.NewCollector(logPrefix, cfg.tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize), logger)
 	defer accTrieCollector.Close()
+	accTrieCollector.SpillCompression(etl.CompressS2)
 	accTrieCollectorFunc := accountTrieCollector(accTrieCollector)
 
 	stTrieCollector := etl.NewCollector(logPrefix, cfg.tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize), logger)
 	defer stTrieCollector.Close()
+	stTrieCollector.SpillCompression(etl.CompressS2)
 	stTrieCollectorFunc := storageTrieCollector(stTrieCollector)
 
 	loader := trie.NewFlatDBTrieLoader(logPrefix, trie.NewRetainList(0), accTrieCollectorFunc, stTrieCollectorFunc, false)
@@ -0,0 +1,191 @@
+package stagedsync
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/etl"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// trieParts - full regeneration is split by first nibble of hashed account key: records of branch nodes depend only on
+// sub-trie below them, so all records (except root - it's not stored) of 16 sub-tries can be built independently
+const trieParts = 16
+
+// triePartSize - size of one part in space of first 8 bytes of hashed account key
+const triePartSize = 1 << 60
+
+type subTrie struct {
+	accTrieCollector, stTrieCollector *etl.Collector
+}
+
+func (p *subTrie) load(tx kv.RwTx, quit <-chan struct{}) error {
+	if err := p.accTrieCollector.Load(tx, kv.TrieOfAccounts, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit}); err != nil {
+		return err
+	}
+	return p.stTrieCollector.Load(tx, kv.TrieOfStorage, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit})
+}
+
+func (p *subTrie) close() {
+	p.accTrieCollector.Close()
+	p.stTrieCollector.Close()
+}
+
+// regenerateIntermediateHashesParallel - workers build sub-tries, each in own read-only transaction and with own ETL
+// collectors. Finished sub-tries are loaded by caller's goroutine (owner of tx) while other workers are still running -
+// it bounds RAM: at most `workers` sub-tries are building and 1 is loading. Root is calculated at the end from loaded
+// records - it reads only few accounts near the root.
+//
+// State (HashedAccounts, HashedStorage) must be committed: workers don't see changes of tx.
+func regenerateIntermediateHashesParallel(logPrefix string, tx kv.RwTx, cfg TrieCfg, expectedRootHash libcommon.Hash, ctx context.Context, logger log.Logger) (libcommon.Hash, error) {
+	workers := min(cfg.workers, trieParts)
+	bufSize := etl.BufferOptimalSize / datasize.ByteSize(workers+1)
+	logger.Info(fmt.Sprintf("[%s] Regeneration of trie hashes in parallel", logPrefix), "workers", workers, "buffer", bufSize.HR())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	progress := &trieProgress{started: time.Now()}
+	done := make(chan *subTrie)
+	var workersErr error
+	go func() {
+		defer close(done)
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(workers)
+		for i := 0; i < trieParts; i++ {
+			nibble := byte(i)
+			g.Go(func() error {
+				part, err := buildSubTrie(gctx, logPrefix, cfg, nibble, bufSize, progress, logger)
+				if err != nil {
+					return err
+				}
+				select {
+				case done <- part:
+					return nil
+				case <-gctx.Done():
+					part.close()
+					return gctx.Err()
+				}
+			})
+		}
+		workersErr = g.Wait()
+	}()
+
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	var loadErr error
+Loop:
+	for {
+		select {
+		case part, ok := <-done:
+			if !ok {
+				break Loop
+			}
+			if loadErr == nil {
+				if loadErr = part.load(tx, ctx.Done()); loadErr != nil {
+					cancel()
+				}
+			}
+			part.close()
+		case <-logEvery.C:
+			progress.log(logPrefix, logger)
+		}
+	}
+	if loadErr != nil {
+		return trie.EmptyRoot, loadErr
+	}
+	if workersErr != nil {
+		return trie.EmptyRoot, workersErr
+	}
+
+	hash, err := trie.NewFlatDBTrieLoader(logPrefix, trie.NewRetainList(0), nil, nil, false).CalcTrieRoot(tx, ctx.Done())
+	if err != nil {
+		return trie.EmptyRoot, err
+	}
+	if cfg.checkRoot && hash != expectedRootHash {
+		// same as sequential regeneration: don't leave records of wrong trie
+		_ = tx.ClearBucket(kv.TrieOfAccounts)
+		_ = tx.ClearBucket(kv.TrieOfStorage)
+		return hash, nil
+	}
+	logger.Info(fmt.Sprintf("[%s] Trie root", logPrefix), "hash", hash.Hex(), "took", time.Since(progress.started).Round(time.Second))
+	return hash, nil
+}
+
+func buildSubTrie(ctx context.Context, logPrefix string, cfg TrieCfg, nibble byte, bufSize datasize.ByteSize, progress *trieProgress, logger log.Logger) (*subTrie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tx, err := cfg.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	part := &subTrie{
+		accTrieCollector: etl.NewCollector(logPrefix, cfg.tmpDir, etl.NewSortableBuffer(bufSize), logger),
+		stTrieCollector:  etl.NewCollector(logPrefix, cfg.tmpDir, etl.NewSortableBuffer(bufSize), logger),
+	}
+	part.accTrieCollector.SpillCompression(etl.CompressS2)
+	part.stTrieCollector.SpillCompression(etl.CompressS2)
+
+	loader := trie.NewFlatDBTrieLoader(logPrefix, trie.NewRetainList(0), accountTrieCollector(part.accTrieCollector), storageTrieCollector(part.stTrieCollector), false)
+	if err := loader.CalcSubTrie(tx, nibble, progress.visit, ctx.Done()); err != nil {
+		part.close()
+		return nil, err
+	}
+	progress.done(nibble)
+	return part, nil
+}
+
+// trieProgress - position of each worker in its part of key space. Hashed keys are uniformly distributed - so position
+// is good estimate of done work (except accounts with huge storage).
+type trieProgress struct {
+	started time.Time
+	pos     [trieParts]atomic.Uint64 // offset of current account within part, triePartSize - part is done
+}
+
+func (p *trieProgress) visit(k []byte) {
+	p.pos[k[0]>>4].Store(binary.BigEndian.Uint64(k) & (triePartSize - 1))
+}
+
+func (p *trieProgress) done(nibble byte) { p.pos[nibble].Store(triePartSize) }
+
+// ratio - share of done work [0, 1] and amount of done parts
+func (p *trieProgress) ratio() (ratio float64, doneParts int) {
+	for i := range p.pos {
+		pos := p.pos[i].Load()
+		if pos == triePartSize {
+			doneParts++
+		}
+		ratio += float64(pos) / triePartSize
+	}
+	return ratio / trieParts, doneParts
+}
+
+func (p *trieProgress) log(logPrefix string, logger log.Logger) {
+	ratio, doneParts := p.ratio()
+	eta := "unknown"
+	if ratio > 0 {
+		elapsed := time.Since(p.started)
+		eta = time.Duration(float64(elapsed) * (1 - ratio) / ratio).Round(time.Second).String()
+	}
+	var m runtime.MemStats
+	dbg.ReadMemStats(&m)
+	logger.Info(fmt.Sprintf("[%s] Regenerating trie hashes", logPrefix),
+		"progress", fmt.Sprintf("%.2f%%", ratio*100),
+		"parts", fmt.Sprintf("%d/%d", doneParts, trieParts),
+		"eta", eta,
+		"alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
+}
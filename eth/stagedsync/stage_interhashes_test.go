@@ -3,6 +3,7 @@ package stagedsync_test
 import (
 	"context"
 	"encoding/binary"
+	"math/rand"
 	"testing"
 
 	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
@@ -409,3 +410,60 @@ func TestHiveTrieRoot(t *testing.T) {
 
 	assert.Equal(t, regeneratedRoot, incrementalRoot)
 }
+
+func TestRegenerateIntermediateHashesParallel(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	ctx := context.Background()
+	logger := log.New()
+
+	rnd := rand.New(rand.NewSource(42))
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for i := 0; i < 3_000; i++ {
+		var hash libcommon.Hash
+		rnd.Read(hash[:])
+		if i%16 == 0 {
+			hash[0] = 0x70 // dense part: deeper branches under same nibble
+		}
+		incarnation := uint64(0)
+		if i%10 == 0 {
+			incarnation = 1
+			for j := 0; j < 1+rnd.Intn(50); j++ {
+				var loc libcommon.Hash
+				rnd.Read(loc[:])
+				require.NoError(t, tx.Put(kv.HashedStorage, dbutils.GenerateCompositeStorageKey(hash, incarnation, loc), []byte{byte(j + 1)}))
+			}
+		}
+		require.NoError(t, addTestAccount(tx, hash, uint64(i+1)*params.GWei, incarnation))
+	}
+	require.NoError(t, tx.Commit())
+
+	readTable := func(tx kv.Tx, table string) map[string]string {
+		res := map[string]string{}
+		require.NoError(t, tx.ForEach(table, nil, func(k, v []byte) error {
+			res[string(k)] = string(v)
+			return nil
+		}))
+		return res
+	}
+	cfg := stagedsync.StageTrieCfg(db, false, true, false, t.TempDir(), nil, nil, false, nil)
+	regenerate := func(cfg stagedsync.TrieCfg) (libcommon.Hash, map[string]string, map[string]string) {
+		tx, err := db.BeginRw(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		root, err := stagedsync.RegenerateIntermediateHashes("IH", tx, cfg, libcommon.Hash{}, ctx, logger)
+		require.NoError(t, err)
+		return root, readTable(tx, kv.TrieOfAccounts), readTable(tx, kv.TrieOfStorage)
+	}
+
+	expectRoot, expectAccTrie, expectStTrie := regenerate(cfg)
+	require.NotEmpty(t, expectAccTrie)
+	require.NotEmpty(t, expectStTrie)
+	for _, workers := range []int{2, 5, 32} {
+		root, accTrie, stTrie := regenerate(cfg.WithWorkers(workers))
+		require.Equal(t, expectRoot, root, workers)
+		require.Equal(t, expectAccTrie, accTrie, workers)
+		require.Equal(t, expectStTrie, stTrie, workers)
+	}
+}
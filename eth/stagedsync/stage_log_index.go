@@ -22,8 +22,10 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
 
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb/cbor"
 	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/turbo/services"
 )
 
 const (
@@ -38,22 +40,46 @@ type LogIndexCfg struct {
 	bufLimit   datasize.ByteSize
 	flushEvery time.Duration
 
-	// For not pruning the logs of this contract since deposit contract logs are needed by CL to validate/produce blocks.
-	// All logs should be available to a validating node through eth_getLogs
-	depositContract *libcommon.Address
+	// For not pruning the logs of these contracts: deposit contract logs are needed by CL to validate/produce blocks,
+	// logs of addresses of --prune.logs.keep are retained forever. All logs of a transaction are kept if one of them matches
+	noPruneContracts map[libcommon.Address]bool
 }
 
-func StageLogIndexCfg(db kv.RwDB, prune prune.Mode, tmpDir string, depositContract *libcommon.Address) LogIndexCfg {
+func StageLogIndexCfg(db kv.RwDB, prune prune.Mode, tmpDir string, depositContract *libcommon.Address, keepLogsOf []libcommon.Address) LogIndexCfg {
 	return LogIndexCfg{
-		db:              db,
-		prune:           prune,
-		bufLimit:        bitmapsBufLimit,
-		flushEvery:      bitmapsFlushEvery,
-		tmpdir:          tmpDir,
-		depositContract: depositContract,
+		db:               db,
+		prune:            prune,
+		bufLimit:         bitmapsBufLimit,
+		flushEvery:       bitmapsFlushEvery,
+		tmpdir:           tmpDir,
+		noPruneContracts: noPruneContracts(depositContract, keepLogsOf),
 	}
 }
 
+func noPruneContracts(depositContract *libcommon.Address, keepLogsOf []libcommon.Address) map[libcommon.Address]bool {
+	if depositContract == nil && len(keepLogsOf) == 0 {
+		return nil
+	}
+	contracts := make(map[libcommon.Address]bool, len(keepLogsOf)+1)
+	if depositContract != nil {
+		contracts[*depositContract] = true
+	}
+	for _, addr := range keepLogsOf {
+		contracts[addr] = true
+	}
+	return contracts
+}
+
+// hasNoPruneLog - if any of the log addresses is in noPrune, all logs of the txn are stored and indexed
+func hasNoPruneLog(logs types.Logs, noPrune map[libcommon.Address]bool) bool {
+	for _, l := range logs {
+		if noPrune[l.Address] {
+			return true
+		}
+	}
+	return false
+}
+
 func SpawnLogIndex(s *StageState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Context, prematureEndBlock uint64, logger log.Logger) error {
 	useExternalTx := tx != nil
 	if !useExternalTx {
@@ -86,7 +112,7 @@ func SpawnLogIndex(s *StageState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Conte
 	}
 
 	startBlock := s.BlockNumber
-	pruneTo := cfg.prune.Receipts.PruneTo(endBlock) //endBlock - prune.r.older
+	pruneTo := cfg.prune.LogIndex.PruneTo(endBlock) //endBlock - prune.logindex
 	// if startBlock < pruneTo {
 	// 	startBlock = pruneTo
 	// }
@@ -109,7 +135,7 @@ func SpawnLogIndex(s *StageState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Conte
 	return nil
 }
 
-// Add the topics and address index for logs, if not in prune range or addr is the deposit contract
+// Add the topics and address index for logs, if not in prune range or addr is one of no-prune contracts
 func promoteLogIndex(logPrefix string, tx kv.RwTx, start uint64, endBlock uint64, pruneBlock uint64, cfg LogIndexCfg, ctx context.Context, logger log.Logger) error {
 	quit := ctx.Done()
 	logEvery := time.NewTicker(30 * time.Second)
@@ -135,6 +161,10 @@ func promoteLogIndex(logPrefix string, tx kv.RwTx, start uint64, endBlock uint64
 	if endBlock != 0 && endBlock-start > 100 {
 		logger.Info(fmt.Sprintf("[%s] processing", logPrefix), "from", start, "to", endBlock, "pruneTo", pruneBlock)
 	}
+	progress := NewStageProgress(logPrefix, "block", start, 0)
+	if endBlock != 0 {
+		progress.SetTo(endBlock + 1)
+	}
 
 	for k, v, err := logs.Seek(dbutils.LogKey(start, 0)); k != nil; k, v, err = logs.Next() {
 		if err != nil {
@@ -158,7 +188,7 @@ func promoteLogIndex(logPrefix string, tx kv.RwTx, start uint64, endBlock uint64
 		case <-logEvery.C:
 			var m runtime.MemStats
 			dbg.ReadMemStats(&m)
-			logger.Info(fmt.Sprintf("[%s] Progress", logPrefix), "number", blockNum, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
+			progress.Log(logger, blockNum, "alloc", libcommon.ByteCount(m.Alloc), "sys", libcommon.ByteCount(m.Sys))
 		case <-checkFlushEvery.C:
 			if needFlush(topics, cfg.bufLimit) {
 				if err := flushBitmaps(collectorTopics, topics); err != nil {
@@ -181,23 +211,8 @@ func promoteLogIndex(logPrefix string, tx kv.RwTx, start uint64, endBlock uint64
 			return fmt.Errorf("receipt unmarshal failed: %w, blocl=%d", err, blockNum)
 		}
 
-		toStore := true
-		// if pruning is enabled, and depositContract isn't configured for the chain, don't index
-		if blockNum < pruneBlock {
-			toStore = false
-			if cfg.depositContract == nil {
-				continue
-			}
-			for _, l := range ll {
-				// if any of the log address is in noPrune, store and index all logs for this txId
-				if *cfg.depositContract == l.Address {
-					toStore = true
-					break
-				}
-			}
-		}
-
-		if !toStore {
+		// if pruning is enabled, index only logs of no-prune contracts
+		if blockNum < pruneBlock && !hasNoPruneLog(ll, cfg.noPruneContracts) {
 			continue
 		}
 		for _, l := range ll {
@@ -382,7 +397,9 @@ func truncateBitmaps(tx kv.RwTx, bucket string, inMem map[string]struct{}, to ui
 	return nil
 }
 
-func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo uint64, ctx context.Context) error {
+// pruneOldLogChunks - deletes chunks before pruneTo of collected keys. If some blocks of prune range have retained logs,
+// chunks keep bits of these blocks and of blocks before pruneFrom (processed by previous prunes) instead
+func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneFrom, pruneTo uint64, kept *roaring.Bitmap, ctx context.Context) error {
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 
@@ -392,8 +409,15 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 	}
 	defer c.Close()
 
+	var keep *roaring.Bitmap
+	if !kept.IsEmpty() {
+		keep = kept.Clone()
+		keep.AddRange(0, pruneFrom)
+	}
+	buf := bytes.NewBuffer(nil)
+
 	if err := inMem.Load(tx, bucket, func(key, v []byte, table etl.CurrentTableReader, next etl.LoadNextFunc) error {
-		for k, _, err := c.Seek(key); k != nil; k, _, err = c.Next() {
+		for k, v, err := c.Seek(key); k != nil; k, v, err = c.Next() {
 			if err != nil {
 				return err
 			}
@@ -404,6 +428,24 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 				break
 			}
 
+			if keep != nil {
+				chunk := roaring.New()
+				if err := chunk.UnmarshalBinary(v); err != nil {
+					return err
+				}
+				chunk.And(keep)
+				if !chunk.IsEmpty() {
+					buf.Reset()
+					if _, err := chunk.WriteTo(buf); err != nil {
+						return err
+					}
+					if err = c.Put(libcommon.Copy(k), libcommon.Copy(buf.Bytes())); err != nil {
+						return fmt.Errorf("failed put log/index, bucket=%v block=%d: %w", bucket, blockNum, err)
+					}
+					continue
+				}
+			}
+
 			if err = c.DeleteCurrent(); err != nil {
 				return fmt.Errorf("failed delete log/index, bucket=%v block=%d: %w", bucket, blockNum, err)
 			}
@@ -419,7 +461,7 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 
 // Call pruneLogIndex with the current sync progresses and commit the data to db
 func PruneLogIndex(s *PruneState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Context, logger log.Logger) (err error) {
-	if !cfg.prune.Receipts.Enabled() {
+	if !cfg.prune.LogIndex.Enabled() {
 		return nil
 	}
 	logPrefix := s.LogPrefix()
@@ -433,8 +475,8 @@ func PruneLogIndex(s *PruneState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Conte
 		defer tx.Rollback()
 	}
 
-	pruneTo := cfg.prune.Receipts.PruneTo(s.ForwardProgress)
-	if err = pruneLogIndex(logPrefix, tx, cfg.tmpdir, s.PruneProgress, pruneTo, ctx, logger, cfg.depositContract); err != nil {
+	pruneTo := cfg.prune.LogIndex.PruneTo(s.ForwardProgress)
+	if err = pruneLogIndex(logPrefix, tx, cfg.tmpdir, s.PruneProgress, pruneTo, ctx, logger, cfg.noPruneContracts); err != nil {
 		return err
 	}
 	if err = s.DoneAt(tx, pruneTo); err != nil {
@@ -450,7 +492,7 @@ func PruneLogIndex(s *PruneState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Conte
 }
 
 // Prune log indexes as well as logs within the prune range
-func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneFrom, pruneTo uint64, ctx context.Context, logger log.Logger, depositContract *libcommon.Address) error {
+func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneFrom, pruneTo uint64, ctx context.Context, logger log.Logger, noPrune map[libcommon.Address]bool) error {
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 
@@ -460,6 +502,8 @@ func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneFrom, prune
 	addrs := etl.NewCollector(logPrefix, tmpDir, etl.NewOldestEntryBuffer(bufferSize), logger)
 	defer addrs.Close()
 
+	// blocks with retained logs: their bits stay in pruned chunks of shared topics
+	kept := roaring.New()
 	reader := bytes.NewReader(nil)
 	{
 		c, err := tx.Cursor(kv.Log)
@@ -490,17 +534,11 @@ func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneFrom, prune
 				return fmt.Errorf("receipt unmarshal failed: %w, block=%d", err, binary.BigEndian.Uint64(k))
 			}
 
-			toPrune := true
-			for _, l := range logs {
-				// No logs (or sublogs) for this txId should be pruned
-				// if one of the logs belongs to the deposit contract
-				if depositContract != nil && *depositContract == l.Address {
-					toPrune = false
-					break
-				}
-			}
-
-			if toPrune {
+			// No logs (or sublogs) for this txId should be pruned
+			// if one of the logs belongs to no-prune contracts
+			if hasNoPruneLog(logs, noPrune) {
+				kept.Add(uint32(blockNum))
+			} else {
 				for _, l := range logs {
 					for _, topic := range l.Topics {
 						if err := topics.Collect(topic.Bytes(), nil); err != nil {
@@ -518,11 +556,132 @@ func pruneLogIndex(logPrefix string, tx kv.RwTx, tmpDir string, pruneFrom, prune
 		}
 	}
 
-	if err := pruneOldLogChunks(tx, kv.LogTopicIndex, topics, pruneTo, ctx); err != nil {
+	if err := pruneOldLogChunks(tx, kv.LogTopicIndex, topics, pruneFrom, pruneTo, kept, ctx); err != nil {
 		return err
 	}
-	if err := pruneOldLogChunks(tx, kv.LogAddressIndex, addrs, pruneTo, ctx); err != nil {
+	if err := pruneOldLogChunks(tx, kv.LogAddressIndex, addrs, pruneFrom, pruneTo, kept, ctx); err != nil {
 		return err
 	}
 	return nil
 }
+
+// ReindexLogsOf - restores pruned logs of addresses added to --prune.logs.keep: reads receipts of blocks [from, to)
+// from snapshots, writes logs of transactions with logs of these addresses and adds them to logs index.
+// Blocks from LogIndex prune progress are not pruned, `to` is capped by it
+func ReindexLogsOf(ctx context.Context, tx kv.RwTx, blockReader services.FullBlockReader, addresses []libcommon.Address, from, to uint64, logger log.Logger) (restored int, err error) {
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+
+	pruneProgress, err := stages.GetStagePruneProgress(tx, stages.LogIndex)
+	if err != nil {
+		return 0, err
+	}
+	if to == 0 || to > pruneProgress {
+		to = pruneProgress
+	}
+	noPrune := noPruneContracts(nil, addresses)
+	topics := map[string]*roaring.Bitmap{}
+	addrs := map[string]*roaring.Bitmap{}
+	buf := bytes.NewBuffer(nil)
+	for blockNum := from; blockNum < to; blockNum++ {
+		select {
+		case <-logEvery.C:
+			logger.Info("[reindex logs]", "block", blockNum, "to", to, "restored", restored)
+		case <-ctx.Done():
+			return restored, libcommon.ErrStopped
+		default:
+		}
+
+		hash, err := blockReader.CanonicalHash(ctx, tx, blockNum)
+		if err != nil {
+			return restored, err
+		}
+		block, senders, err := blockReader.BlockWithSenders(ctx, tx, hash, blockNum)
+		if err != nil {
+			return restored, err
+		}
+		if block == nil {
+			return restored, fmt.Errorf("block %d not found", blockNum)
+		}
+		receipts, err := blockReader.ReceiptsFromSnapshots(ctx, block, senders)
+		if err != nil {
+			return restored, err
+		}
+		if receipts == nil {
+			return restored, fmt.Errorf("receipts of block %d are not in snapshots (--snap.receipts)", blockNum)
+		}
+		for txIndex, r := range receipts {
+			if !hasNoPruneLog(r.Logs, noPrune) {
+				continue
+			}
+			k := dbutils.LogKey(blockNum, uint32(txIndex))
+			if has, err := tx.Has(kv.Log, k); err != nil {
+				return restored, err
+			} else if has {
+				continue
+			}
+			buf.Reset()
+			if err := cbor.Marshal(buf, r.Logs); err != nil {
+				return restored, fmt.Errorf("encode logs of block %d: %w", blockNum, err)
+			}
+			if err := tx.Put(kv.Log, k, buf.Bytes()); err != nil {
+				return restored, err
+			}
+			for _, l := range r.Logs {
+				for _, topic := range l.Topics {
+					addToBitmap(topics, topic.Bytes(), blockNum)
+				}
+				addToBitmap(addrs, l.Address.Bytes(), blockNum)
+			}
+			restored++
+		}
+	}
+
+	if err := mergeBitmaps(tx, kv.LogTopicIndex, topics); err != nil {
+		return restored, err
+	}
+	if err := mergeBitmaps(tx, kv.LogAddressIndex, addrs); err != nil {
+		return restored, err
+	}
+	return restored, nil
+}
+
+func addToBitmap(bitmaps map[string]*roaring.Bitmap, key []byte, blockNum uint64) {
+	m, ok := bitmaps[string(key)]
+	if !ok {
+		m = roaring.New()
+		bitmaps[string(key)] = m
+	}
+	m.Add(uint32(blockNum))
+}
+
+// mergeBitmaps - adds bits to any chunks of index (not only to last one, as stage does): chunks of a key are re-written
+func mergeBitmaps(tx kv.RwTx, bucket string, bitmaps map[string]*roaring.Bitmap) error {
+	keys := make([]string, 0, len(bitmaps))
+	for k := range bitmaps {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	buf := bytes.NewBuffer(nil)
+	for _, k := range keys {
+		key := []byte(k)
+		m, err := bitmapdb.Get(tx, bucket, key, 0, bitmapdb.MaxUint32)
+		if err != nil {
+			return err
+		}
+		m.Or(bitmaps[k])
+		if err := bitmapdb.TruncateRange(tx, bucket, key, 0); err != nil {
+			return err
+		}
+		if err := bitmapdb.WalkChunkWithKeys(key, m, bitmapdb.ChunkLimit, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+			buf.Reset()
+			if _, err := chunk.WriteTo(buf); err != nil {
+				return err
+			}
+			return tx.Put(bucket, chunkKey, buf.Bytes())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -99,7 +100,7 @@ func TestPromoteLogIndex(t *testing.T) {
 
 	expectAddrs, expectTopics := genReceipts(t, tx, 100)
 
-	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil)
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil, nil)
 	cfgCopy := cfg
 	cfgCopy.bufLimit = 10
 	cfgCopy.flushEvery = time.Nanosecond
@@ -127,7 +128,7 @@ func TestPruneLogIndex(t *testing.T) {
 
 	_, _ = genReceipts(t, tx, 90)
 
-	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil)
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil, nil)
 	cfgCopy := cfg
 	cfgCopy.bufLimit = 10
 	cfgCopy.flushEvery = time.Nanosecond
@@ -136,7 +137,7 @@ func TestPruneLogIndex(t *testing.T) {
 
 	// Mode test
 	depositContract := libcommon.Address{1} // using addr {1} from genReceipts
-	err = pruneLogIndex("", tx, tmpDir, 0, 45, ctx, logger, &depositContract)
+	err = pruneLogIndex("", tx, tmpDir, 0, 45, ctx, logger, noPruneContracts(&depositContract, nil))
 	require.NoError(err)
 
 	{
@@ -177,7 +178,7 @@ func TestUnwindLogIndex(t *testing.T) {
 
 	expectAddrs, expectTopics := genReceipts(t, tx, 100)
 
-	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil)
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil, nil)
 	cfgCopy := cfg
 	cfgCopy.bufLimit = 10
 	cfgCopy.flushEvery = time.Nanosecond
@@ -203,3 +204,61 @@ func TestUnwindLogIndex(t *testing.T) {
 		require.True(m.Maximum() <= 700)
 	}
 }
+
+func TestPruneLogIndexKeepLogsOf(t *testing.T) {
+	logger := log.New()
+	require, tmpDir, ctx := require.New(t), t.TempDir(), context.Background()
+	_, tx := memdb.NewTestTx(t)
+
+	_, _ = genReceipts(t, tx, 3000) // enough blocks for several chunks of each key
+
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, "", nil, []libcommon.Address{{2}})
+	err := promoteLogIndex("logPrefix", tx, 0, 0, 0, cfg, ctx, logger)
+	require.NoError(err)
+
+	err = pruneLogIndex("", tx, tmpDir, 0, 1000, ctx, logger, cfg.noPruneContracts)
+	require.NoError(err)
+	err = pruneLogIndex("", tx, tmpDir, 1000, 2000, ctx, logger, cfg.noPruneContracts)
+	require.NoError(err)
+
+	// logs of addr {2} (blocks i%3==1) are retained with shared topics {2}, {3} and addr {3} of their txs
+	for _, key := range []struct {
+		bucket string
+		key    []byte
+	}{
+		{kv.LogAddressIndex, libcommon.Address{2}.Bytes()},
+		{kv.LogAddressIndex, libcommon.Address{3}.Bytes()},
+		{kv.LogTopicIndex, libcommon.Hash{2}.Bytes()},
+		{kv.LogTopicIndex, libcommon.Hash{3}.Bytes()},
+	} {
+		m, err := bitmapdb.Get(tx, key.bucket, key.key, 0, 1999)
+		require.NoError(err)
+		for i := uint32(1); i < 2000; i += 3 {
+			require.True(m.Contains(i), "bucket=%s, key=%x, block=%d", key.bucket, key.key, i)
+		}
+	}
+	// topic {2} of addr {1} logs (blocks i%3==0) is pruned from chunks before block 2000
+	m, err := bitmapdb.Get(tx, kv.LogTopicIndex, libcommon.Hash{2}.Bytes(), 0, 1999)
+	require.NoError(err)
+	for i := uint32(1002); i < 1449; i += 3 {
+		require.False(m.Contains(i), "block=%d", i)
+	}
+
+	total := 0
+	err = tx.ForEach(kv.Log, nil, func(k, v []byte) error {
+		if binary.BigEndian.Uint64(k) < 2000 {
+			require.Equal(uint64(1), binary.BigEndian.Uint64(k)%3)
+		}
+		total++
+		return nil
+	})
+	require.NoError(err)
+	require.Equal(3000-667, total) // 1000 blocks with 1 txn and 1000 blocks with 2 txns, 667 txns of addr {1} pruned
+
+	// re-indexed logs are merged into existing chunks
+	require.NoError(mergeBitmaps(tx, kv.LogAddressIndex, map[string]*roaring.Bitmap{string(libcommon.Address{1}.Bytes()): roaring.BitmapOf(3)}))
+	m, err = bitmapdb.Get(tx, kv.LogAddressIndex, libcommon.Address{1}.Bytes(), 0, 10_000_000)
+	require.NoError(err)
+	require.True(m.Contains(3))
+	require.True(m.Contains(2997))
+}
@@ -32,6 +32,7 @@ type MiningBlock struct {
 	Receipts         types.Receipts
 	Withdrawals      []*types.Withdrawal
 	PreparedTxs      types.TransactionsStream
+	InclusionList    types.Transactions
 }
 
 type MiningState struct {
@@ -200,6 +201,7 @@ func SpawnMiningCreateBlockStage(s *StageState, tx kv.RwTx, cfg MiningCreateBloc
 		current.Header = header
 		current.Uncles = nil
 		current.Withdrawals = cfg.blockBuilderParameters.Withdrawals
+		current.InclusionList = cfg.blockBuilderParameters.InclusionList
 		return nil
 	}
 
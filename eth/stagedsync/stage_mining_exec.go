@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -47,6 +48,7 @@ type MiningExecCfg struct {
 	payloadId   uint64
 	txPool      TxPoolForMining
 	txPoolDB    kv.RoDB
+	txSelector  MiningTxSelector
 }
 
 type TxPoolForMining interface {
@@ -60,7 +62,11 @@ func StageMiningExecCfg(
 	tmpdir string, interrupt *int32, payloadId uint64,
 	txPool TxPoolForMining, txPoolDB kv.RoDB,
 	blockReader services.FullBlockReader,
+	txSelector MiningTxSelector, // nil - NewMiningTxSelector(miningState.MiningConfig)
 ) MiningExecCfg {
+	if txSelector == nil {
+		txSelector = NewMiningTxSelector(miningState.MiningConfig)
+	}
 	return MiningExecCfg{
 		db:          db,
 		miningState: miningState,
@@ -74,6 +80,7 @@ func StageMiningExecCfg(
 		payloadId:   payloadId,
 		txPool:      txPool,
 		txPoolDB:    txPoolDB,
+		txSelector:  txSelector,
 	}
 }
 
@@ -114,7 +121,7 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 	// empty block is necessary to keep the liveness of the network.
 	if noempty {
 		if txs != nil && !txs.Empty() {
-			logs, _, err := addTransactionsToMiningBlock(logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txs, cfg.miningState.MiningConfig.Etherbase, ibs, ctx, cfg.interrupt, cfg.payloadId, logger)
+			logs, _, err := addTransactionsToMiningBlock(logPrefix, current, cfg.chainConfig, cfg.chainConfig.GetMaxBlobGasPerBlock(), cfg.vmConfig, getHeader, cfg.engine, txs, cfg.miningState.MiningConfig.Etherbase, ibs, ctx, cfg.interrupt, cfg.payloadId, logger)
 			if err != nil {
 				return err
 			}
@@ -144,15 +151,39 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 			if err != nil {
 				return err
 			}
+			maxBlobGas := cfg.txSelector.MaxBlobGas(current.Header, cfg.chainConfig.GetMaxBlobGasPerBlock())
+
+			bundles, err := cfg.txSelector.Bundles(current.Header)
+			if err != nil {
+				return err
+			}
+			if len(bundles) > 0 {
+				logs := addBundlesToMiningBlock(logPrefix, current, cfg.chainConfig, maxBlobGas, cfg.vmConfig, getHeader, cfg.engine, bundles, cfg.miningState.MiningConfig.Etherbase, ibs, yielded, logger)
+				NotifyPendingLogs(logPrefix, cfg.notifier, logs, logger)
+			}
+			if len(current.InclusionList) > 0 {
+				// each transaction of inclusion list is a bundle on its own: invalid ones are skipped
+				inclusionList := make([]types.Transactions, len(current.InclusionList))
+				for i, txn := range current.InclusionList {
+					inclusionList[i] = types.Transactions{txn}
+				}
+				txCount := len(current.Txs)
+				logs := addBundlesToMiningBlock(logPrefix, current, cfg.chainConfig, maxBlobGas, cfg.vmConfig, getHeader, cfg.engine, inclusionList, cfg.miningState.MiningConfig.Etherbase, ibs, yielded, logger)
+				NotifyPendingLogs(logPrefix, cfg.notifier, logs, logger)
+				if missed := len(inclusionList) - (len(current.Txs) - txCount); missed > 0 {
+					metrics.BlockProducerInclusionListMissed.AddInt(missed)
+					logger.Debug(fmt.Sprintf("[%s] Inclusion list transactions not included", logPrefix), "missed", missed, "of", len(inclusionList))
+				}
+			}
 
 			for {
-				txs, y, err := getNextTransactions(cfg, chainID, current.Header, 50, executionAt, yielded, simStateReader, simStateWriter, logger)
+				txs, y, err := getNextTransactions(cfg, chainID, current.Header, 50, executionAt, maxBlobGas, yielded, simStateReader, simStateWriter, logger)
 				if err != nil {
 					return err
 				}
 
 				if !txs.Empty() {
-					logs, stop, err := addTransactionsToMiningBlock(logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txs, cfg.miningState.MiningConfig.Etherbase, ibs, ctx, cfg.interrupt, cfg.payloadId, logger)
+					logs, stop, err := addTransactionsToMiningBlock(logPrefix, current, cfg.chainConfig, maxBlobGas, cfg.vmConfig, getHeader, cfg.engine, txs, cfg.miningState.MiningConfig.Etherbase, ibs, ctx, cfg.interrupt, cfg.payloadId, logger)
 					if err != nil {
 						return err
 					}
@@ -171,6 +202,11 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 			}
 
 			metrics.UpdateBlockProducerProductionDelay(current.ParentHeaderTime, current.Header.Number.Uint64(), logger)
+			var blobGasUsed uint64
+			if current.Header.BlobGasUsed != nil {
+				blobGasUsed = *current.Header.BlobGasUsed
+			}
+			metrics.UpdateBlockProducerPayload(miningPayloadValue(current), current.Header.Number.Uint64(), current.Header.GasUsed, current.Header.GasLimit, blobGasUsed, maxBlobGas, logger)
 		}
 	}
 
@@ -247,6 +283,7 @@ func getNextTransactions(
 	header *types.Header,
 	amount uint16,
 	executionAt uint64,
+	maxBlobGas uint64,
 	alreadyYielded mapset.Set[[32]byte],
 	simStateReader state.StateReader,
 	simStateWriter state.StateWriter,
@@ -254,16 +291,21 @@ func getNextTransactions(
 ) (types.TransactionsStream, int, error) {
 	txSlots := types2.TxsRlp{}
 	count := 0
+	remainingBlobGas := uint64(0)
+	if header.BlobGasUsed != nil && maxBlobGas > *header.BlobGasUsed {
+		remainingBlobGas = maxBlobGas - *header.BlobGasUsed
+	}
 	if err := cfg.txPoolDB.View(context.Background(), func(poolTx kv.Tx) error {
 		var err error
 
 		remainingGas := header.GasLimit - header.GasUsed
-		remainingBlobGas := uint64(0)
-		if header.BlobGasUsed != nil {
-			remainingBlobGas = cfg.chainConfig.GetMaxBlobGasPerBlock() - *header.BlobGasUsed
+		// txpool fills blob gas first come first served, so take all blob transactions and pack them by PackBlobs
+		yieldBlobGas := remainingBlobGas
+		if yieldBlobGas > 0 {
+			yieldBlobGas = math.MaxUint64
 		}
 
-		if _, count, err = cfg.txPool.YieldBest(amount, &txSlots, poolTx, executionAt, remainingGas, remainingBlobGas, alreadyYielded); err != nil {
+		if _, count, err = cfg.txPool.YieldBest(amount, &txSlots, poolTx, executionAt, remainingGas, yieldBlobGas, alreadyYielded); err != nil {
 			return err
 		}
 
@@ -273,6 +315,7 @@ func getNextTransactions(
 	}
 
 	var txs []types.Transaction //nolint:prealloc
+	locals := mapset.NewThreadUnsafeSet[libcommon.Hash]()
 	for i := range txSlots.Txs {
 		transaction, err := types.DecodeWrappedTransaction(txSlots.Txs[i])
 		if err == io.EOF {
@@ -291,6 +334,9 @@ func getNextTransactions(
 		// Check if tx nonce is too low
 		txs = append(txs, transaction)
 		txs[len(txs)-1].SetSender(sender)
+		if txSlots.IsLocal[i] {
+			locals.Add(transaction.Hash())
+		}
 	}
 
 	blockNum := executionAt + 1
@@ -298,6 +344,8 @@ func getNextTransactions(
 	if err != nil {
 		return nil, 0, err
 	}
+	txs = cfg.txSelector.PackBlobs(header, txs, locals, remainingBlobGas)
+	txs = cfg.txSelector.Order(header, txs, locals)
 
 	return types.NewTransactionsFixedOrder(txs), count, nil
 }
@@ -420,15 +468,12 @@ func filterBadTransactions(transactions []types.Transaction, config chain.Config
 	return filtered, nil
 }
 
-func addTransactionsToMiningBlock(logPrefix string, current *MiningBlock, chainConfig chain.Config, vmConfig *vm.Config, getHeader func(hash libcommon.Hash, number uint64) *types.Header,
+func addTransactionsToMiningBlock(logPrefix string, current *MiningBlock, chainConfig chain.Config, maxBlobGas uint64, vmConfig *vm.Config, getHeader func(hash libcommon.Hash, number uint64) *types.Header,
 	engine consensus.Engine, txs types.TransactionsStream, coinbase libcommon.Address, ibs *state.IntraBlockState, ctx context.Context,
 	interrupt *int32, payloadId uint64, logger log.Logger) (types.Logs, bool, error) {
 	header := current.Header
 	tcount := 0
-	gasPool := new(core.GasPool).AddGas(header.GasLimit - header.GasUsed)
-	if header.BlobGasUsed != nil {
-		gasPool.AddBlobGas(chainConfig.GetMaxBlobGasPerBlock() - *header.BlobGasUsed)
-	}
+	gasPool := newMiningGasPool(header, maxBlobGas)
 	signer := types.MakeSigner(&chainConfig, header.Number.Uint64(), header.Time)
 
 	var coalescedLogs types.Logs
@@ -550,6 +595,80 @@ LOOP:
 
 }
 
+// addBundlesToMiningBlock - executes bundles in given order. Bundle is included all-or-nothing: on failure of
+// any its transaction state, gas and receipts are reverted to before the bundle
+func addBundlesToMiningBlock(logPrefix string, current *MiningBlock, chainConfig chain.Config, maxBlobGas uint64, vmConfig *vm.Config, getHeader func(hash libcommon.Hash, number uint64) *types.Header,
+	engine consensus.Engine, bundles []types.Transactions, coinbase libcommon.Address, ibs *state.IntraBlockState, yielded mapset.Set[[32]byte], logger log.Logger) types.Logs {
+	header := current.Header
+	signer := types.MakeSigner(&chainConfig, header.Number.Uint64(), header.Time)
+	noop := state.NewNoopWriter()
+
+	var coalescedLogs types.Logs
+	for i, bundle := range bundles {
+		snap := ibs.Snapshot()
+		txCount, gasUsed := len(current.Txs), header.GasUsed
+		var blobGasUsed uint64
+		if header.BlobGasUsed != nil {
+			blobGasUsed = *header.BlobGasUsed
+		}
+		gasPool := newMiningGasPool(header, maxBlobGas)
+
+		var logs types.Logs
+		var err error
+		for _, txn := range bundle {
+			if _, err = txn.Sender(*signer); err != nil {
+				break
+			}
+			ibs.SetTxContext(txn.Hash(), libcommon.Hash{}, len(current.Txs))
+			var receipt *types.Receipt
+			receipt, _, err = core.ApplyTransaction(&chainConfig, core.GetHashFn(header, getHeader), engine, &coinbase, gasPool, ibs, noop, header, txn, &header.GasUsed, header.BlobGasUsed, *vmConfig)
+			if err != nil {
+				break
+			}
+			current.Txs = append(current.Txs, txn)
+			current.Receipts = append(current.Receipts, receipt)
+			logs = append(logs, receipt.Logs...)
+		}
+		if err != nil {
+			ibs.RevertToSnapshot(snap)
+			current.Txs, current.Receipts, header.GasUsed = current.Txs[:txCount], current.Receipts[:txCount], gasUsed
+			if header.BlobGasUsed != nil {
+				*header.BlobGasUsed = blobGasUsed
+			}
+			logger.Debug(fmt.Sprintf("[%s] Skipping bundle", logPrefix), "bundle", i, "txs", len(bundle), "err", err)
+			continue
+		}
+		for _, txn := range bundle {
+			yielded.Add(txn.Hash())
+		}
+		coalescedLogs = append(coalescedLogs, logs...)
+		logger.Trace(fmt.Sprintf("[%s] Added bundle", logPrefix), "bundle", i, "txs", len(bundle))
+	}
+	return coalescedLogs
+}
+
+// miningPayloadValue - tips received by fee recipient for transactions added so far
+func miningPayloadValue(current *MiningBlock) *uint256.Int {
+	var baseFee *uint256.Int
+	if current.Header.BaseFee != nil {
+		baseFee, _ = uint256.FromBig(current.Header.BaseFee)
+	}
+	value := uint256.NewInt(0)
+	for i, txn := range current.Txs {
+		txValue := new(uint256.Int).Mul(txn.GetEffectiveGasTip(baseFee), uint256.NewInt(current.Receipts[i].GasUsed))
+		value.Add(value, txValue)
+	}
+	return value
+}
+
+func newMiningGasPool(header *types.Header, maxBlobGas uint64) *core.GasPool {
+	gasPool := new(core.GasPool).AddGas(header.GasLimit - header.GasUsed)
+	if header.BlobGasUsed != nil && maxBlobGas > *header.BlobGasUsed {
+		gasPool.AddBlobGas(maxBlobGas - *header.BlobGasUsed)
+	}
+	return gasPool
+}
+
 func NotifyPendingLogs(logPrefix string, notifier ChainEventNotifier, logs types.Logs, logger log.Logger) {
 	if len(logs) == 0 {
 		return
@@ -0,0 +1,79 @@
+package stagedsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/diagnostics"
+)
+
+// StageProgress - tracks structured progress of stage: processed/total items, rate and ETA.
+// Stages log it in same format instead of own ad-hoc lines, and each `Log` is also sent to diagnostics.
+type StageProgress struct {
+	logPrefix string
+	unit      string
+	from      uint64
+	total     uint64 // 0 - unknown
+
+	prevTime      time.Time
+	prevProcessed uint64
+}
+
+// NewProgress - progress of items in range `[from, to)`. `to=0` - amount of items is unknown
+func (s *StageState) NewProgress(unit string, from, to uint64) *StageProgress {
+	return NewStageProgress(s.LogPrefix(), unit, from, to)
+}
+
+func NewStageProgress(logPrefix, unit string, from, to uint64) *StageProgress {
+	p := &StageProgress{logPrefix: logPrefix, unit: unit, from: from, prevTime: time.Now()}
+	p.SetTo(to)
+	return p
+}
+
+// SetTo - for stages which learn amount of items during execution (for example: headers download)
+func (p *StageProgress) SetTo(to uint64) {
+	if to > p.from {
+		p.total = to - p.from
+	} else {
+		p.total = 0
+	}
+}
+
+// Report - progress as of item `current`. Rate is calculated since previous report
+func (p *StageProgress) Report(current uint64) diagnostics.StageProgress {
+	var processed uint64
+	if current > p.from {
+		processed = current - p.from
+	}
+	now := time.Now()
+	r := diagnostics.StageProgress{Stage: p.logPrefix, Unit: p.unit, Processed: processed, Total: p.total}
+	if interval := now.Sub(p.prevTime).Seconds(); interval > 0 && processed >= p.prevProcessed {
+		r.Rate = float64(processed-p.prevProcessed) / interval
+	}
+	if r.Rate > 0 && r.Total > r.Processed {
+		r.Eta = float64(r.Total-r.Processed) / r.Rate
+	}
+	p.prevTime, p.prevProcessed = now, processed
+	return r
+}
+
+// Log - reports progress to log and diagnostics. `ctx` - stage-specific details appended to log line
+func (p *StageProgress) Log(logger log.Logger, current uint64, ctx ...interface{}) {
+	r := p.Report(current)
+	if diagnostics.TypeOf(r).Enabled() {
+		diagnostics.Send(r)
+	}
+
+	args := make([]interface{}, 0, 8+len(ctx))
+	args = append(args, p.unit, current)
+	if r.Total > 0 {
+		args = append(args, "progress", fmt.Sprintf("%.2f%%", 100*float64(r.Processed)/float64(r.Total)))
+	}
+	args = append(args, "rate", fmt.Sprintf("%.1f/s", r.Rate))
+	if r.Eta > 0 {
+		args = append(args, "eta", (time.Duration(r.Eta) * time.Second).String())
+	}
+	logger.Info(fmt.Sprintf("[%s] Progress", p.logPrefix), append(args, ctx...)...)
+}
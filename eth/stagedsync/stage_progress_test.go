@@ -0,0 +1,37 @@
+package stagedsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageProgress(t *testing.T) {
+	p := NewStageProgress("test", "block", 100, 1100)
+	p.prevTime = time.Now().Add(-10 * time.Second)
+	r := p.Report(300)
+	require.Equal(t, "test", r.Stage)
+	require.Equal(t, uint64(200), r.Processed)
+	require.Equal(t, uint64(1000), r.Total)
+	require.InDelta(t, 20, r.Rate, 1)
+	require.InDelta(t, 40, r.Eta, 2) // 800 blocks left
+
+	// rate is calculated since previous report
+	p.prevTime = time.Now().Add(-10 * time.Second)
+	r = p.Report(400)
+	require.InDelta(t, 10, r.Rate, 1)
+	require.InDelta(t, 70, r.Eta, 7)
+
+	// unknown total: no ETA
+	p = NewStageProgress("test", "block", 100, 0)
+	p.prevTime = time.Now().Add(-10 * time.Second)
+	r = p.Report(300)
+	require.Zero(t, r.Total)
+	require.Zero(t, r.Eta)
+	require.InDelta(t, 20, r.Rate, 1)
+
+	p.SetTo(501)
+	r = p.Report(500)
+	require.Equal(t, uint64(401), r.Total)
+}
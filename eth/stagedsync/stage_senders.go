@@ -11,12 +11,15 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/cmp"
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
 	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/consensus"
@@ -26,6 +29,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	coresnaptype "github.com/ledgerwatch/erigon/core/snaptype"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb/prune"
@@ -53,7 +57,7 @@ func StageSendersCfg(db kv.RwDB, chainCfg *chain.Config, syncCfg ethconfig.Sync,
 	const sendersBatchSize = 10000
 	const sendersBlockSize = 4096
 
-	return SendersCfg{
+	cfg := SendersCfg{
 		db:              db,
 		batchSize:       sendersBatchSize,
 		blockSize:       sendersBlockSize,
@@ -69,6 +73,8 @@ func StageSendersCfg(db kv.RwDB, chainCfg *chain.Config, syncCfg ethconfig.Sync,
 		loopBreakCheck:  loopBreakCheck,
 		syncCfg:         syncCfg,
 	}
+	cfg.numOfGoroutines = syncCfg.StageThrottle[string(stages.Senders)].LimitWorkers(cfg.numOfGoroutines)
+	return cfg
 }
 
 func SpawnRecoverSendersStage(cfg SendersCfg, s *StageState, u Unwinder, tx kv.RwTx, toBlock uint64, ctx context.Context, logger log.Logger) error {
@@ -87,6 +93,12 @@ func SpawnRecoverSendersStage(cfg SendersCfg, s *StageState, u Unwinder, tx kv.R
 		defer tx.Rollback()
 	}
 
+	if cfg.blockReader.FreezingCfg().Enabled && cfg.syncCfg.SendersTrust == ethconfig.SendersTrustManifest {
+		if err := verifySnapshotsSenders(ctx, cfg, tx, s.LogPrefix(), logger); err != nil {
+			return err
+		}
+	}
+
 	prevStageProgress, errStart := stages.GetStageProgress(tx, stages.Bodies)
 	if errStart != nil {
 		return errStart
@@ -106,6 +118,7 @@ func SpawnRecoverSendersStage(cfg SendersCfg, s *StageState, u Unwinder, tx kv.R
 
 	logEvery := time.NewTicker(30 * time.Second)
 	defer logEvery.Stop()
+	progress := s.NewProgress("block", s.BlockNumber, to)
 
 	startFrom := s.BlockNumber + 1
 
@@ -143,7 +156,7 @@ func SpawnRecoverSendersStage(cfg SendersCfg, s *StageState, u Unwinder, tx kv.R
 				if j != nil {
 					n += uint64(j.index)
 				}
-				logger.Info(fmt.Sprintf("[%s] Recovery", logPrefix), "block_number", n, "ch", fmt.Sprintf("%d/%d", len(jobs), cap(jobs)))
+				progress.Log(logger, n, "ch", fmt.Sprintf("%d/%d", len(jobs), cap(jobs)))
 			case j, ok = <-out:
 				if !ok {
 					return
@@ -304,6 +317,105 @@ Loop:
 	return nil
 }
 
+// verifySnapshotsSenders - senders embedded into transactions snapshots which are not in chain's manifest are recovered
+// and compared with embedded ones. Each snapshot is verified once: verified snapshots are remembered in db.
+func verifySnapshotsSenders(ctx context.Context, cfg SendersCfg, tx kv.RwTx, logPrefix string, logger log.Logger) error {
+	preverified := snapcfg.KnownCfg(cfg.chainConfig.ChainName).Preverified
+	for _, fileName := range cfg.blockReader.Snapshots().Files() {
+		info, _, ok := snaptype.ParseFileName("", fileName)
+		if !ok || info.Type == nil || info.Type.Enum() != coresnaptype.Enums.Transactions {
+			continue
+		}
+		if preverified.Contains(fileName, true) {
+			continue
+		}
+		key := append(libcommon.Copy(kv.SnapshotSendersVerified), fileName...)
+		verified, err := tx.Has(kv.DatabaseInfo, key)
+		if err != nil {
+			return err
+		}
+		if verified {
+			continue
+		}
+		logger.Info(fmt.Sprintf("[%s] Verifying senders of snapshot not listed in manifest", logPrefix), "file", fileName)
+		if err := verifySendersOfBlocks(ctx, cfg, tx, info.From, info.To, logPrefix, logger); err != nil {
+			return fmt.Errorf("snapshot %s: %w", fileName, err)
+		}
+		if err := tx.Put(kv.DatabaseInfo, key, []byte{1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySendersOfBlocks - recovers senders of frozen blocks `[from, to)` and compares them with senders embedded into snapshots
+func verifySendersOfBlocks(ctx context.Context, cfg SendersCfg, tx kv.Tx, from, to uint64, logPrefix string, logger log.Logger) error {
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	progress := NewStageProgress(logPrefix, "block", from, to)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	jobs := make(chan *senderRecoveryJob, cfg.batchSize)
+	for i := 0; i < cfg.numOfGoroutines; i++ {
+		cryptoContext := secp256k1.ContextForThread(i)
+		g.Go(func() error {
+			for j := range jobs {
+				signer := types.MakeSigner(cfg.chainConfig, j.blockNumber, j.blockTime)
+				for _, txn := range j.body.Transactions {
+					embedded, ok := txn.GetSender()
+					if !ok {
+						return fmt.Errorf("no sender embedded for tx=%x, block=%d", txn.Hash(), j.blockNumber)
+					}
+					recovered, err := signer.SenderWithContext(cryptoContext, txn)
+					if err != nil {
+						return fmt.Errorf("recovering sender for tx=%x, block=%d: %w", txn.Hash(), j.blockNumber, err)
+					}
+					if recovered != embedded {
+						return fmt.Errorf("wrong sender embedded for tx=%x, block=%d: %x, recovered: %x", txn.Hash(), j.blockNumber, embedded, recovered)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	// RwTx is bound to current goroutine: blocks are read here, workers only recover senders
+	readErr := func() error {
+		defer close(jobs)
+		for blockNum := from; blockNum < to; blockNum++ {
+			select {
+			case <-logEvery.C:
+				progress.Log(logger, blockNum)
+			default:
+			}
+			header, err := cfg.blockReader.HeaderByNumber(gCtx, tx, blockNum)
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("header %d not found", blockNum)
+			}
+			body, err := cfg.blockReader.BodyWithTransactions(gCtx, tx, header.Hash(), blockNum)
+			if err != nil {
+				return err
+			}
+			if body == nil {
+				return fmt.Errorf("body %d not found", blockNum)
+			}
+			select {
+			case jobs <- &senderRecoveryJob{body: body, blockNumber: blockNum, blockTime: header.Time, blockHash: header.Hash()}:
+			case <-gCtx.Done():
+				return nil // error of worker is returned by Wait
+			}
+		}
+		return nil
+	}()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return readErr
+}
+
 type senderRecoveryError struct {
 	err         error
 	blockNumber uint64
@@ -0,0 +1,89 @@
+package stagedsync
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
+)
+
+// frozenTxsReader - serves bodies of frozen blocks as transactions snapshot with embedded senders would do
+type frozenTxsReader struct {
+	services.FullBlockReader
+	files  []string
+	bodies map[uint64]*types.Body
+	reads  atomic.Uint64
+}
+
+type frozenTxsSnapshots struct {
+	services.BlockSnapshots
+	files []string
+}
+
+func (s *frozenTxsSnapshots) Files() []string { return s.files }
+
+func (r *frozenTxsReader) Snapshots() services.BlockSnapshots {
+	return &frozenTxsSnapshots{files: r.files}
+}
+
+func (r *frozenTxsReader) HeaderByNumber(_ context.Context, _ kv.Getter, blockNum uint64) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(blockNum)}, nil
+}
+
+func (r *frozenTxsReader) BodyWithTransactions(_ context.Context, _ kv.Getter, _ libcommon.Hash, blockNum uint64) (*types.Body, error) {
+	r.reads.Add(1)
+	if body, ok := r.bodies[blockNum]; ok {
+		return body, nil
+	}
+	return &types.Body{}, nil
+}
+
+func TestVerifySnapshotsSenders(t *testing.T) {
+	logger := log.New()
+	_, tx := memdb.NewTestTx(t)
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.MakeSigner(params.TestChainConfig, 1, 0)
+	txn, err := types.SignTx(&types.LegacyTx{CommonTx: types.CommonTx{Nonce: 1, To: &addr, Value: u256.Num1, Gas: 21000}, GasPrice: u256.Num1}, *signer, key)
+	require.NoError(t, err)
+
+	// segment of blocks [0, 1000): not in manifest of test chain
+	reader := &frozenTxsReader{files: []string{"v1-000000-000001-transactions.seg", "v1-000000-000001-headers.seg"}, bodies: map[uint64]*types.Body{}}
+	reader.bodies[7] = &types.Body{Transactions: []types.Transaction{txn}}
+	cfg := StageSendersCfg(nil, params.TestChainConfig, ethconfig.Defaults.Sync, false, t.TempDir(), prune.DefaultMode, reader, nil, nil)
+	ctx := context.Background()
+
+	txn.SetSender(libcommon.HexToAddress("0x01"))
+	err = verifySnapshotsSenders(ctx, cfg, tx, "test", logger)
+	require.ErrorContains(t, err, "wrong sender embedded")
+	verified, err := tx.Has(kv.DatabaseInfo, append(libcommon.Copy(kv.SnapshotSendersVerified), reader.files[0]...))
+	require.NoError(t, err)
+	require.False(t, verified)
+
+	txn.SetSender(addr)
+	reads := reader.reads.Load()
+	require.NoError(t, verifySnapshotsSenders(ctx, cfg, tx, "test", logger))
+	require.Equal(t, reads+1000, reader.reads.Load()) // all blocks of segment
+	reads = reader.reads.Load()
+
+	// verified segment is remembered: not read again
+	txn.SetSender(libcommon.HexToAddress("0x01"))
+	require.NoError(t, verifySnapshotsSenders(ctx, cfg, tx, "test", logger))
+	require.Equal(t, reads, reader.reads.Load())
+}
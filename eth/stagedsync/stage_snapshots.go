@@ -110,7 +110,7 @@ func StageSnapshotsCfg(db kv.RwDB,
 			torrentFiles: downloader.NewAtomicTorrentFS(cfg.dirs.Snap),
 		}
 
-		cfg.blockRetire.SetWorkers(estimate.CompressSnapshot.Workers())
+		cfg.blockRetire.SetWorkers(cfg.syncConfig.StageThrottle[string(stages.Snapshots)].LimitWorkers(estimate.CompressSnapshot.Workers()))
 
 		freezingCfg := cfg.blockReader.FreezingCfg()
 
@@ -255,7 +255,7 @@ func DownloadAndIndexSnapshotsIfNeed(s *StageState, ctx context.Context, tx kv.R
 	}
 
 	if cfg.historyV3 {
-		indexWorkers := estimate.IndexSnapshot.Workers()
+		indexWorkers := cfg.syncConfig.StageThrottle[string(stages.Snapshots)].LimitWorkers(estimate.IndexSnapshot.Workers())
 		if err := cfg.agg.BuildOptionalMissedIndices(ctx, indexWorkers); err != nil {
 			return err
 		}
@@ -453,7 +453,7 @@ func SnapshotsPrune(s *PruneState, initialCycle bool, cfg SnapshotsCfg, ctx cont
 			}
 
 			if initialCycle {
-				cfg.blockRetire.SetWorkers(estimate.CompressSnapshot.Workers())
+				cfg.blockRetire.SetWorkers(cfg.syncConfig.StageThrottle[string(stages.Snapshots)].LimitWorkers(estimate.CompressSnapshot.Workers()))
 			} else {
 				cfg.blockRetire.SetWorkers(1)
 			}
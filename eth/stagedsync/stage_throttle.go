@@ -0,0 +1,30 @@
+package stagedsync
+
+import (
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/diagnostics"
+	"github.com/ledgerwatch/erigon-lib/seg"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+)
+
+// StageThrottleState - limits of resources of stage (see ethconfig.Sync.StageThrottle) and how much stage was slowed down by them.
+// IO limit is applied by compressors of snapshot files: it's reported for Snapshots stage only.
+func StageThrottleState(id stages.SyncStage, t ethconfig.StageThrottle) diagnostics.StageThrottle {
+	state := diagnostics.StageThrottle{Stage: string(id), Workers: t.Workers, UpdatedAt: time.Now().Unix()}
+	if id == stages.Snapshots {
+		state.IOLimit = uint64(seg.WriteLimit())
+		state.IOWaited = seg.WriteThrottled().Seconds()
+	}
+	return state
+}
+
+func (s *Sync) reportThrottle(id stages.SyncStage) {
+	t, ok := s.cfg.StageThrottle[string(id)]
+	if !ok || !diagnostics.TypeOf(diagnostics.StageThrottle{}).Enabled() {
+		return
+	}
+	diagnostics.Send(StageThrottleState(id, t))
+}
@@ -0,0 +1,40 @@
+package stagedsync
+
+import (
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/seg"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+)
+
+func TestStageThrottle(t *testing.T) {
+	throttle, err := ethconfig.ParseStageThrottle("Execution:cpu=8, Snapshots:io=100mb,Snapshots:cpu=2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]ethconfig.StageThrottle{
+		"Execution": {Workers: 8},
+		"Snapshots": {Workers: 2, IO: 100 * datasize.MB},
+	}, throttle)
+
+	for _, bad := range []string{"Execution", "Execution:cpu", "Execution:cpu=x", "Execution:cpu=-1", "Execution:mem=1gb", "Snapshots:io=fast"} {
+		_, err := ethconfig.ParseStageThrottle(bad)
+		require.Error(t, err, bad)
+	}
+
+	exec := throttle[string(stages.Execution)]
+	require.Equal(t, 8, exec.LimitWorkers(16))
+	require.Equal(t, 4, exec.LimitWorkers(4))
+	require.Equal(t, 16, throttle[string(stages.Senders)].LimitWorkers(16))
+
+	defer seg.SetWriteLimit(0)
+	seg.SetWriteLimit(throttle[string(stages.Snapshots)].IO)
+	state := StageThrottleState(stages.Snapshots, throttle[string(stages.Snapshots)])
+	require.Equal(t, "Snapshots", state.Stage)
+	require.Equal(t, 2, state.Workers)
+	require.Equal(t, uint64(100*datasize.MB), state.IOLimit)
+	require.Zero(t, StageThrottleState(stages.Execution, exec).IOLimit)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/ledgerwatch/erigon-lib/kv/temporal"
 	"github.com/ledgerwatch/log/v3"
@@ -89,6 +90,7 @@ func collectAndComputeCommitment(ctx context.Context, tx kv.RwTx, tmpDir string,
 	var (
 		batchSize = uint64(10_000_000)
 		processed atomic.Uint64
+		started   = time.Now()
 	)
 
 	sdCtx := state.NewSharedDomainsCommitmentContext(domains, state.CommitmentModeDirect, commitment.VariantHexPatriciaTrie)
@@ -99,8 +101,10 @@ func collectAndComputeCommitment(ctx context.Context, tx kv.RwTx, tmpDir string,
 			if err != nil {
 				return err
 			}
+			done := float64(processed.Load()) / float64(totalKeys.Load())
 			logger.Info("Committing batch",
-				"processed", fmt.Sprintf("%dM/%dM (%.2f%%)", processed.Load()/1_000_000, totalKeys.Load()/1_000_000, float64(processed.Load())/float64(totalKeys.Load())*100),
+				"processed", fmt.Sprintf("%dM/%dM (%.2f%%)", processed.Load()/1_000_000, totalKeys.Load()/1_000_000, done*100),
+				"eta", time.Duration(float64(time.Since(started))*(1-done)/done).Round(time.Second),
 				"intermediate root", fmt.Sprintf("%x", rh))
 		}
 		processed.Add(1)
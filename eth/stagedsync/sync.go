@@ -6,12 +6,16 @@ import (
 	"time"
 
 	"github.com/ledgerwatch/log/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/diagnostics"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 	"github.com/ledgerwatch/erigon-lib/wrap"
 
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
@@ -33,6 +37,10 @@ type Sync struct {
 	logPrefixes   []string
 	logger        log.Logger
 	stagesIdsList []string
+	background    bool // instance of BackgroundPruner
+
+	cycleCtx context.Context // span of current Run* call
+	stageCtx context.Context // span of running stage, see StageState.TraceContext
 }
 
 type Timing struct {
@@ -184,7 +192,7 @@ func (s *Sync) SetCurrentStage(id stages.SyncStage) error {
 		if stage.ID == id {
 			s.currentStage = uint(i)
 			isDiagEnabled := diagnostics.TypeOf(diagnostics.CurrentSyncStage{}).Enabled()
-			if isDiagEnabled {
+			if isDiagEnabled && !s.background {
 				diagnostics.Send(diagnostics.CurrentSyncStage{Stage: s.currentStage})
 			}
 
@@ -261,6 +269,9 @@ func (s *Sync) RunUnwind(db kv.RwDB, txc wrap.TxContainer) error {
 	if s.unwindPoint == nil {
 		return nil
 	}
+	var cycleSpan trace.Span
+	s.cycleCtx, cycleSpan = tracing.Start(context.Background(), "sync unwind")
+	defer cycleSpan.End()
 	for j := 0; j < len(s.unwindOrder); j++ {
 		if s.unwindOrder[j] == nil || s.unwindOrder[j].Disabled || s.unwindOrder[j].Unwind == nil {
 			continue
@@ -281,6 +292,9 @@ func (s *Sync) RunUnwind(db kv.RwDB, txc wrap.TxContainer) error {
 func (s *Sync) RunNoInterrupt(db kv.RwDB, txc wrap.TxContainer, firstCycle bool) error {
 	s.prevUnwindPoint = nil
 	s.timings = s.timings[:0]
+	var cycleSpan trace.Span
+	s.cycleCtx, cycleSpan = tracing.Start(context.Background(), "sync cycle")
+	defer cycleSpan.End()
 
 	for !s.IsDone() {
 		var badBlockUnwind bool
@@ -349,6 +363,9 @@ func (s *Sync) RunNoInterrupt(db kv.RwDB, txc wrap.TxContainer, firstCycle bool)
 func (s *Sync) Run(db kv.RwDB, txc wrap.TxContainer, firstCycle bool) (bool, error) {
 	s.prevUnwindPoint = nil
 	s.timings = s.timings[:0]
+	var cycleSpan trace.Span
+	s.cycleCtx, cycleSpan = tracing.Start(context.Background(), "sync cycle")
+	defer cycleSpan.End()
 
 	hasMore := false
 
@@ -439,9 +456,16 @@ func (s *Sync) Run(db kv.RwDB, txc wrap.TxContainer, firstCycle bool) (bool, err
 	return hasMore, nil
 }
 
-// Run pruning for stages as per the defined pruning order, if enabled for that stage
+// Run pruning for stages as per the defined pruning order, if enabled for that stage.
+// Does nothing if pruning is done by BackgroundPruner
 func (s *Sync) RunPrune(db kv.RwDB, tx kv.RwTx, firstCycle bool) error {
+	if s.cfg.PruneInterval > 0 && !s.background {
+		return nil
+	}
 	s.timings = s.timings[:0]
+	var cycleSpan trace.Span
+	s.cycleCtx, cycleSpan = tracing.Start(context.Background(), "sync prune")
+	defer cycleSpan.End()
 	for i := 0; i < len(s.pruningOrder); i++ {
 		if s.pruningOrder[i] == nil || s.pruningOrder[i].Disabled || s.pruningOrder[i].Prune == nil {
 			continue
@@ -519,12 +543,34 @@ func CollectTableSizes(db kv.RoDB, tx kv.Tx, buckets []string) []interface{} {
 	return bucketSizes
 }
 
+// startStageSpan - span of stage run, child of span of Run* call
+func (s *Sync) startStageSpan(name string, id stages.SyncStage, blockNum uint64) trace.Span {
+	parent := s.cycleCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	var span trace.Span
+	s.stageCtx, span = tracing.Start(parent, name, attribute.String("stage", string(id)), attribute.Int64("block", int64(blockNum)))
+	return span
+}
+
+func (s *Sync) endStageSpan(span trace.Span, err error) {
+	s.stageCtx = nil
+	tracing.End(span, err)
+}
+
 func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, firstCycle bool, badBlockUnwind bool) (err error) {
 	start := time.Now()
 	stageState, err := s.StageState(stage.ID, txc.Tx, db)
 	if err != nil {
 		return err
 	}
+	span := s.startStageSpan("stage "+string(stage.ID), stage.ID, stageState.BlockNumber)
+	defer func() { s.endStageSpan(span, err) }()
+
+	if err = disk.CheckFreeSpace(disk.FreeSpaceCritical); err != nil {
+		return fmt.Errorf("[%s] %w", s.LogPrefix(), err)
+	}
 
 	if err = stage.Forward(firstCycle, badBlockUnwind, stageState, s, txc, s.logger); err != nil {
 		wrappedError := fmt.Errorf("[%s] %w", s.LogPrefix(), err)
@@ -532,6 +578,8 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, firstCyc
 		return wrappedError
 	}
 
+	s.reportThrottle(stage.ID)
+
 	took := time.Since(start)
 	logPrefix := s.LogPrefix()
 	if took > 60*time.Second {
@@ -543,7 +591,7 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, firstCyc
 	return nil
 }
 
-func (s *Sync) unwindStage(firstCycle bool, stage *Stage, db kv.RwDB, txc wrap.TxContainer) error {
+func (s *Sync) unwindStage(firstCycle bool, stage *Stage, db kv.RwDB, txc wrap.TxContainer) (err error) {
 	start := time.Now()
 	s.logger.Trace("Unwind...", "stage", stage.ID)
 	stageState, err := s.StageState(stage.ID, txc.Tx, db)
@@ -562,6 +610,8 @@ func (s *Sync) unwindStage(firstCycle bool, stage *Stage, db kv.RwDB, txc wrap.T
 		return err
 	}
 
+	span := s.startStageSpan("unwind "+string(stage.ID), stage.ID, unwind.UnwindPoint)
+	defer func() { s.endStageSpan(span, err) }()
 	err = stage.Unwind(firstCycle, unwind, stageState, txc, s.logger)
 	if err != nil {
 		return fmt.Errorf("[%s] %w", s.LogPrefix(), err)
@@ -577,7 +627,7 @@ func (s *Sync) unwindStage(firstCycle bool, stage *Stage, db kv.RwDB, txc wrap.T
 }
 
 // Run the pruning function for the given stage
-func (s *Sync) pruneStage(firstCycle bool, stage *Stage, db kv.RwDB, tx kv.RwTx) error {
+func (s *Sync) pruneStage(firstCycle bool, stage *Stage, db kv.RwDB, tx kv.RwTx) (err error) {
 	start := time.Now()
 	s.logger.Debug("Prune...", "stage", stage.ID)
 
@@ -594,6 +644,8 @@ func (s *Sync) pruneStage(firstCycle bool, stage *Stage, db kv.RwDB, tx kv.RwTx)
 		return err
 	}
 
+	span := s.startStageSpan("prune "+string(stage.ID), stage.ID, pruneState.PruneProgress)
+	defer func() { s.endStageSpan(span, err) }()
 	err = stage.Prune(firstCycle, pruneState, tx, s.logger)
 	if err != nil {
 		return fmt.Errorf("[%s] %w", s.LogPrefix(), err)
@@ -0,0 +1,120 @@
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/evmtypes"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/tests"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+)
+
+// TestFourByteTracerNative checks the native 4byteTracer against the javascript one.
+func TestFourByteTracerNative(t *testing.T) {
+	forEachCallTracerTest(t, func(t *testing.T, test *callTracerTest) {
+		have := runTracer(t, "4byteTracer", test)
+		want := runTracer(t, "4byteTracerLegacy", test)
+		require.JSONEq(t, string(want), string(have))
+	})
+}
+
+// TestOpcodeHistogramTracer checks the opcodes counted by the native histogram
+// against the javascript opcountTracer.
+func TestOpcodeHistogramTracer(t *testing.T) {
+	forEachCallTracerTest(t, func(t *testing.T, test *callTracerTest) {
+		var histogram map[string]struct {
+			Count uint64 `json:"count"`
+			Gas   uint64 `json:"gas"`
+		}
+		require.NoError(t, json.Unmarshal(runTracer(t, "opcodeHistogramTracer", test), &histogram))
+		var opcount uint64
+		require.NoError(t, json.Unmarshal(runTracer(t, "opcountTracer", test), &opcount))
+
+		var count, gas uint64
+		for op, stats := range histogram {
+			require.NotZero(t, stats.Count, op)
+			count += stats.Count
+			gas += stats.Gas
+		}
+		require.Equal(t, opcount, count)
+		require.Equal(t, count > 0, gas > 0)
+	})
+}
+
+func forEachCallTracerTest(t *testing.T, f func(t *testing.T, test *callTracerTest)) {
+	files, err := dir.ReadDir(filepath.Join("testdata", "call_tracer"))
+	if err != nil {
+		t.Fatalf("failed to retrieve tracer test suite: %v", err)
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(file.Name(), ".json")), func(t *testing.T) {
+			t.Parallel()
+			test := new(callTracerTest)
+			blob, err := os.ReadFile(filepath.Join("testdata", "call_tracer", file.Name()))
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(blob, test))
+			f(t, test)
+		})
+	}
+}
+
+// runTracer executes the transaction of the test on top of its prestate and
+// returns the result of the tracer.
+func runTracer(t *testing.T, tracerName string, test *callTracerTest) json.RawMessage {
+	tx, err := types.UnmarshalTransactionFromBinary(common.FromHex(test.Input), false /* blobTxnsAreWrappedWithBlobs */)
+	require.NoError(t, err)
+	var (
+		signer    = types.MakeSigner(test.Genesis.Config, uint64(test.Context.Number), uint64(test.Context.Time))
+		origin, _ = signer.Sender(tx)
+		txContext = evmtypes.TxContext{
+			Origin:   origin,
+			GasPrice: tx.GetPrice(),
+		}
+		context = evmtypes.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			Coinbase:    test.Context.Miner,
+			BlockNumber: uint64(test.Context.Number),
+			Time:        uint64(test.Context.Time),
+			Difficulty:  (*big.Int)(test.Context.Difficulty),
+			GasLimit:    uint64(test.Context.GasLimit),
+		}
+		rules = test.Genesis.Config.Rules(context.BlockNumber, context.Time)
+	)
+	m := mock.Mock(t)
+	dbTx, err := m.DB.BeginRw(m.Ctx)
+	require.NoError(t, err)
+	defer dbTx.Rollback()
+	statedb, _ := tests.MakePreState(rules, dbTx, test.Genesis.Alloc, uint64(test.Context.Number), m.HistoryV3)
+	if test.Genesis.BaseFee != nil {
+		context.BaseFee, _ = uint256.FromBig(test.Genesis.BaseFee)
+	}
+	tracer, err := tracers.New(tracerName, new(tracers.Context), nil)
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, txContext, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(*signer, test.Genesis.BaseFee, rules)
+	require.NoError(t, err)
+	_, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(tx.GetGas()).AddBlobGas(tx.GetBlobGas()), true /* refunds */, false /* gasBailout */)
+	require.NoError(t, err)
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	return res
+}
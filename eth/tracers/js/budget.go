@@ -0,0 +1,140 @@
+package js
+
+import (
+	"errors"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+// budget accounts the resources used by the code of a tracer against the
+// budget of the request. Tracer code is interrupted cooperatively: a call
+// running past the cpu time budget is interrupted by a timer, the memory
+// budget is checked when a call returns. Allocations are sampled from the
+// process-wide heap statistics, so allocations of other goroutines made
+// while the tracer code runs are accounted to the tracer as well.
+type budget struct {
+	limits tracers.Budget
+	vm     *goja.Runtime
+
+	mu      sync.Mutex
+	used    time.Duration // cpu time of the finished calls
+	started time.Time     // start of the running call, zero if none
+	timer   *time.Timer   // armed while a call runs, with a cpu time budget
+	aborted *tracers.AbortedError
+
+	allocated uint64 // bytes allocated by the finished calls
+	sample    []metrics.Sample
+}
+
+func newBudget(vm *goja.Runtime, limits *tracers.Budget) *budget {
+	b := &budget{vm: vm}
+	if limits != nil {
+		b.limits = *limits
+	}
+	if b.limits.Memory > 0 {
+		b.sample = []metrics.Sample{{Name: heapAllocsMetric}}
+	}
+	return b
+}
+
+// run runs tracer code within the budget. Once the budget is exceeded the code
+// is interrupted and all further runs fail with the *tracers.AbortedError.
+func (b *budget) run(f func() (goja.Value, error)) (goja.Value, error) {
+	if err := b.start(); err != nil {
+		return nil, err
+	}
+	allocs := b.heapAllocs()
+	res, err := f()
+	if b.limits.Memory > 0 {
+		b.allocated += b.heapAllocs() - allocs
+		if b.allocated > b.limits.Memory {
+			b.abort(&tracers.AbortedError{Resource: tracers.BudgetMemory, Limit: b.limits.Memory})
+		}
+	}
+	if aborted := b.stop(); aborted != nil {
+		return nil, aborted
+	}
+	return res, err
+}
+
+// checkOutput checks the size of the encoded result against the budget
+func (b *budget) checkOutput(size int) error {
+	if b.limits.OutputSize > 0 && uint64(size) > b.limits.OutputSize {
+		return &tracers.AbortedError{Resource: tracers.BudgetOutputSize, Limit: b.limits.OutputSize}
+	}
+	return nil
+}
+
+func (b *budget) start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.aborted != nil {
+		return b.aborted
+	}
+	b.started = time.Now()
+	if b.limits.CPUTime > 0 {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.limits.CPUTime-b.used, b.expire)
+		} else {
+			b.timer.Reset(b.limits.CPUTime - b.used)
+		}
+	}
+	return nil
+}
+
+func (b *budget) stop() *tracers.AbortedError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.used += time.Since(b.started)
+	b.started = time.Time{}
+	if b.aborted == nil && b.limits.CPUTime > 0 && b.used > b.limits.CPUTime {
+		b.aborted = &tracers.AbortedError{Resource: tracers.BudgetCPUTime, Limit: uint64(b.limits.CPUTime)}
+	}
+	return b.aborted
+}
+
+// expire interrupts the running call once it used the rest of the cpu time budget
+func (b *budget) expire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started.IsZero() || b.aborted != nil {
+		return
+	}
+	b.aborted = &tracers.AbortedError{Resource: tracers.BudgetCPUTime, Limit: uint64(b.limits.CPUTime)}
+	b.vm.Interrupt(b.aborted)
+}
+
+func (b *budget) abort(err *tracers.AbortedError) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.aborted == nil {
+		b.aborted = err
+	}
+}
+
+func (b *budget) heapAllocs() uint64 {
+	if b.sample == nil {
+		return 0
+	}
+	metrics.Read(b.sample)
+	return b.sample[0].Value.Uint64()
+}
+
+// abortedError returns the *tracers.AbortedError the code was interrupted with, if any
+func abortedError(err error) (*tracers.AbortedError, bool) {
+	var aborted *tracers.AbortedError
+	if errors.As(err, &aborted) {
+		return aborted, true
+	}
+	return nil, false
+}
@@ -106,6 +106,7 @@ type jsTracer struct {
 	gasLimit          uint64                // Amount of gas bought for the whole tx
 	err               error                 // Any error that should stop tracing
 	obj               *goja.Object          // Trace object
+	budget            *budget               // Resources the tracer code may use
 
 	// Methods exposed by tracer
 	result goja.Callable
@@ -139,13 +140,14 @@ func newJsTracer(code string, ctx *tracers.Context, cfg json.RawMessage) (tracer
 	vm := goja.New()
 	// By default field names are exported to JS as is, i.e. capitalized.
 	vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
-	t := &jsTracer{
-		vm:  vm,
-		ctx: make(map[string]goja.Value),
-	}
 	if ctx == nil {
 		ctx = new(tracers.Context)
 	}
+	t := &jsTracer{
+		vm:     vm,
+		ctx:    make(map[string]goja.Value),
+		budget: newBudget(vm, ctx.Budget),
+	}
 	if ctx.BlockHash != (libcommon.Hash{}) {
 		t.ctx["blockHash"] = vm.ToValue(ctx.BlockHash.Bytes())
 		if ctx.TxHash != (libcommon.Hash{}) {
@@ -156,7 +158,7 @@ func newJsTracer(code string, ctx *tracers.Context, cfg json.RawMessage) (tracer
 
 	t.setTypeConverters()
 	t.setBuiltinFunctions()
-	ret, err := vm.RunString("(" + code + ")")
+	ret, err := t.budget.run(func() (goja.Value, error) { return vm.RunString("(" + code + ")") })
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +193,7 @@ func newJsTracer(code string, ctx *tracers.Context, cfg json.RawMessage) (tracer
 		if cfg != nil {
 			cfgStr = string(cfg)
 		}
-		if _, err := setup(obj, vm.ToValue(cfgStr)); err != nil {
+		if _, err := t.call(setup, vm.ToValue(cfgStr)); err != nil {
 			return nil, err
 		}
 	}
@@ -270,7 +272,7 @@ func (t *jsTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope
 	log.refund = t.env.IntraBlockState().GetRefund()
 	log.depth = depth
 	log.err = err
-	if _, err := t.step(t.obj, t.logValue, t.dbValue); err != nil {
+	if _, err := t.call(t.step, t.logValue, t.dbValue); err != nil {
 		t.onError("step", err)
 	}
 }
@@ -282,7 +284,7 @@ func (t *jsTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope
 	}
 	// Other log fields have been already set as part of the last CaptureState.
 	t.log.err = err
-	if _, err := t.fault(t.obj, t.logValue, t.dbValue); err != nil {
+	if _, err := t.call(t.fault, t.logValue, t.dbValue); err != nil {
 		t.onError("fault", err)
 	}
 }
@@ -314,7 +316,7 @@ func (t *jsTracer) CaptureEnter(typ vm.OpCode, from libcommon.Address, to libcom
 		t.frame.value = value.ToBig()
 	}
 
-	if _, err := t.enter(t.obj, t.frameValue); err != nil {
+	if _, err := t.call(t.enter, t.frameValue); err != nil {
 		t.onError("enter", err)
 	}
 }
@@ -330,7 +332,7 @@ func (t *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	t.frameResult.output = libcommon.CopyBytes(output)
 	t.frameResult.err = err
 
-	if _, err := t.exit(t.obj, t.frameResultValue); err != nil {
+	if _, err := t.call(t.exit, t.frameResultValue); err != nil {
 		t.onError("exit", err)
 	}
 }
@@ -338,17 +340,28 @@ func (t *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 // GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
 func (t *jsTracer) GetResult() (json.RawMessage, error) {
 	ctx := t.vm.ToValue(t.ctx)
-	res, err := t.result(t.obj, ctx, t.dbValue)
+	res, err := t.call(t.result, ctx, t.dbValue)
 	if err != nil {
+		if aborted, ok := abortedError(err); ok {
+			return nil, aborted
+		}
 		return nil, wrapError("result", err)
 	}
 	encoded, err := json.Marshal(res)
 	if err != nil {
 		return nil, err
 	}
+	if err := t.budget.checkOutput(len(encoded)); err != nil {
+		return nil, err
+	}
 	return json.RawMessage(encoded), t.err
 }
 
+// call calls a method of the tracer object within the budget of the tracer
+func (t *jsTracer) call(method goja.Callable, args ...goja.Value) (goja.Value, error) {
+	return t.budget.run(func() (goja.Value, error) { return method(t.obj, args...) })
+}
+
 // Stop terminates execution of the tracer at the first opportune moment.
 func (t *jsTracer) Stop(err error) {
 	t.vm.Interrupt(err)
@@ -358,7 +371,11 @@ func (t *jsTracer) Stop(err error) {
 // and returns an error. It in turn pings the EVM to cancel its
 // execution.
 func (t *jsTracer) onError(context string, err error) {
-	t.err = wrapError(context, err)
+	if aborted, ok := abortedError(err); ok {
+		t.err = aborted
+	} else {
+		t.err = wrapError(context, err)
+	}
 	// `env` is set on CaptureStart which comes before any JS execution.
 	// So it should be non-nil.
 	t.env.Cancel()
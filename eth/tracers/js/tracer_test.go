@@ -199,6 +199,62 @@ func TestHaltBetweenSteps(t *testing.T) {
 	}
 }
 
+func TestBudget(t *testing.T) {
+	for i, tt := range []struct {
+		code     string
+		budget   tracers.Budget
+		want     string
+		resource string
+	}{
+		{ // within the budget
+			code:   "{count: 0, step: function() { this.count += 1; }, fault: function() {}, result: function() { return this.count; }}",
+			budget: tracers.Budget{CPUTime: time.Minute, Memory: 1 << 30, OutputSize: 1},
+			want:   `3`,
+		}, { // endless step is interrupted
+			code:     "{step: function() { while(1); }, fault: function() {}, result: function() { return null; }}",
+			budget:   tracers.Budget{CPUTime: 100 * time.Millisecond},
+			resource: tracers.BudgetCPUTime,
+		}, { // endless result is interrupted
+			code:     "{step: function() {}, fault: function() {}, result: function() { while(1); }}",
+			budget:   tracers.Budget{CPUTime: 100 * time.Millisecond},
+			resource: tracers.BudgetCPUTime,
+		}, {
+			code:     "{data: [], step: function() { this.data.push(new Array(1 << 20).fill(1)); }, fault: function() {}, result: function() { return this.data.length; }}",
+			budget:   tracers.Budget{Memory: 1 << 20},
+			resource: tracers.BudgetMemory,
+		}, {
+			code:     "{step: function() {}, fault: function() {}, result: function() { return 'x'.repeat(1 << 10); }}",
+			budget:   tracers.Budget{OutputSize: 1 << 9},
+			resource: tracers.BudgetOutputSize,
+		},
+	} {
+		budget := tt.budget
+		tracer, err := newJsTracer(tt.code, &tracers.Context{Budget: &budget}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := runTrace(tracer, testCtx(), params.TestChainConfig, nil)
+		if tt.resource == "" {
+			if err != nil || string(have) != tt.want {
+				t.Errorf("testcase %d: expected return value to be '%s' got '%s', error %v", i, tt.want, string(have), err)
+			}
+			continue
+		}
+		var aborted *tracers.AbortedError
+		if !errors.As(err, &aborted) || aborted.Resource != tt.resource {
+			t.Errorf("testcase %d: expected %s budget to be exceeded, got error %v", i, tt.resource, err)
+		}
+	}
+
+	// tracer construction is within the budget as well
+	budget := tracers.Budget{CPUTime: 100 * time.Millisecond}
+	_, err := newJsTracer("{x: (function() { while(1); })(), step: function() {}, fault: function() {}, result: function() { return null; }}", &tracers.Context{Budget: &budget}, nil)
+	var aborted *tracers.AbortedError
+	if !errors.As(err, &aborted) || aborted.Resource != tracers.BudgetCPUTime {
+		t.Errorf("expected cpu time budget to be exceeded, got error %v", err)
+	}
+}
+
 // testNoStepExec tests a regular value transfer (no exec), and accessing the statedb
 // in 'result'
 func TestNoStepExec(t *testing.T) {
@@ -0,0 +1,78 @@
+package native
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	register("opcodeHistogramTracer", newOpcodeHistogramTracer)
+}
+
+// opcodeStats is the number of executions of an opcode and the gas they were charged.
+type opcodeStats struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// opcodeHistogramTracer counts the opcodes executed by a transaction, across all
+// call frames, along with the gas charged for them. The gas charged for calls and
+// creates includes the gas passed to the callee.
+//
+// Example:
+//
+//	> debug.traceTransaction( "0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "opcodeHistogramTracer"})
+//	{
+//	  "ADD": {"count": 12, "gas": 36},
+//	  "CALL": {"count": 1, "gas": 34820},
+//	  "PUSH1": {"count": 57, "gas": 171}
+//	}
+type opcodeHistogramTracer struct {
+	noopTracer
+	ops       map[vm.OpCode]*opcodeStats
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newOpcodeHistogramTracer returns a native go tracer which counts the executed
+// opcodes of a tx, and implements vm.EVMLogger.
+func newOpcodeHistogramTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &opcodeHistogramTracer{ops: make(map[vm.OpCode]*opcodeStats)}, nil
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *opcodeHistogramTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	stats, ok := t.ops[op]
+	if !ok {
+		stats = &opcodeStats{}
+		t.ops[op] = stats
+	}
+	stats.Count++
+	stats.Gas += cost
+}
+
+// GetResult returns the json-encoded histogram keyed by opcode name, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *opcodeHistogramTracer) GetResult() (json.RawMessage, error) {
+	res := make(map[string]*opcodeStats, len(t.ops))
+	for op, stats := range t.ops {
+		res[op.String()] = stats
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return b, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *opcodeHistogramTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
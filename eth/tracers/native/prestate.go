@@ -27,7 +27,9 @@ import (
 	"github.com/holiman/uint256"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/fixedgas"
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/tracers"
@@ -73,7 +75,9 @@ type prestateTracer struct {
 }
 
 type prestateTracerConfig struct {
-	DiffMode bool `json:"diffMode"` // If true, this tracer will return state modifications
+	DiffMode       bool `json:"diffMode"`       // If true, this tracer will return state modifications
+	DisableCode    bool `json:"disableCode"`    // If true, this tracer will not return the contract code
+	DisableStorage bool `json:"disableStorage"` // If true, this tracer will not return the contract storage
 }
 
 func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
@@ -102,20 +106,28 @@ func (t *prestateTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to li
 	t.lookupAccount(to)
 	t.lookupAccount(env.Context.Coinbase)
 
-	// The recipient balance includes the value transferred.
-	toBal := new(big.Int).Sub(t.pre[to].Balance, value.ToBig())
-	t.pre[to].Balance = toBal
-
-	// The sender balance is after reducing: value and gasLimit.
+	// The sender balance is after reducing gasLimit and blob gas.
 	// We need to re-add them to get the pre-tx balance.
 	fromBal := new(big.Int).Set(t.pre[from].Balance)
 	gasPrice := env.GasPrice
 	consumedGas := new(big.Int).Mul(gasPrice.ToBig(), new(big.Int).SetUint64(t.gasLimit))
-	fromBal.Add(fromBal, new(big.Int).Add(value.ToBig(), consumedGas))
-	t.pre[from].Balance = fromBal
-	if t.pre[from].Nonce > 0 {
-		t.pre[from].Nonce--
+	fromBal.Add(fromBal, consumedGas)
+	if blobGas := uint64(len(env.BlobHashes)) * fixedgas.BlobGasPerBlob; blobGas > 0 && env.Context.ExcessBlobGas != nil {
+		if blobGasPrice, err := misc.GetBlobGasPrice(env.ChainConfig(), *env.Context.ExcessBlobGas); err == nil {
+			fromBal.Add(fromBal, new(big.Int).Mul(blobGasPrice.ToBig(), new(big.Int).SetUint64(blobGas)))
+		}
+	}
+	// Contract creation is captured before the value transfer and the increment of the sender nonce,
+	// calls are captured after both.
+	if !create {
+		// The recipient balance includes the value transferred.
+		t.pre[to].Balance = new(big.Int).Sub(t.pre[to].Balance, value.ToBig())
+		fromBal.Add(fromBal, value.ToBig())
+		if t.pre[from].Nonce > 0 {
+			t.pre[from].Nonce--
+		}
 	}
+	t.pre[from].Balance = fromBal
 
 	if create && t.config.DiffMode {
 		t.created[to] = true
@@ -201,7 +213,7 @@ func (t *prestateTracer) CaptureTxEnd(restGas uint64) {
 			modified = true
 			postAccount.Nonce = newNonce
 		}
-		if !bytes.Equal(newCode, t.pre[addr].Code) {
+		if !t.config.DisableCode && !bytes.Equal(newCode, t.pre[addr].Code) {
 			modified = true
 			postAccount.Code = newCode
 		}
@@ -273,18 +285,26 @@ func (t *prestateTracer) lookupAccount(addr libcommon.Address) {
 		return
 	}
 
-	t.pre[addr] = &account{
+	acc := &account{
 		Balance: t.env.IntraBlockState().GetBalance(addr).ToBig(),
 		Nonce:   t.env.IntraBlockState().GetNonce(addr),
-		Code:    t.env.IntraBlockState().GetCode(addr),
-		Storage: make(map[libcommon.Hash]libcommon.Hash),
 	}
+	if !t.config.DisableCode {
+		acc.Code = t.env.IntraBlockState().GetCode(addr)
+	}
+	if !t.config.DisableStorage {
+		acc.Storage = make(map[libcommon.Hash]libcommon.Hash)
+	}
+	t.pre[addr] = acc
 }
 
 // lookupStorage fetches the requested storage slot and adds
 // it to the prestate of the given contract. It assumes `lookupAccount`
 // has been performed on the contract before.
 func (t *prestateTracer) lookupStorage(addr libcommon.Address, key libcommon.Hash) {
+	if t.config.DisableStorage {
+		return
+	}
 	if _, ok := t.pre[addr].Storage[key]; ok {
 		return
 	}
@@ -20,6 +20,8 @@ package tracers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 
@@ -32,6 +34,43 @@ type Context struct {
 	BlockHash libcommon.Hash // Hash of the block the tx is contained within (zero if dangling tx or call)
 	TxIndex   int            // Index of the transaction within a block (zero if dangling tx or call)
 	TxHash    libcommon.Hash // Hash of the transaction being traced (zero if dangling call)
+	Budget    *Budget        // Resources user-supplied tracers may use (nil if unlimited)
+}
+
+// Budget limits the resources a user-supplied tracer may use while tracing a
+// transaction, so that tracer code cannot wedge the node. Zero values are
+// unlimited. Tracers exceeding their budget are interrupted and return an
+// *AbortedError.
+type Budget struct {
+	CPUTime    time.Duration // Time spent running the tracer code
+	Memory     uint64        // Bytes allocated while the tracer code is running
+	OutputSize uint64        // Size of the encoded result of the tracer
+}
+
+// Resources of a tracer Budget
+const (
+	BudgetCPUTime    = "cpuTime"
+	BudgetMemory     = "memory"
+	BudgetOutputSize = "outputSize"
+)
+
+// AbortedError is returned by tracers stopped for exceeding their budget.
+type AbortedError struct {
+	Resource string // One of BudgetCPUTime, BudgetMemory, BudgetOutputSize
+	Limit    uint64 // Limit of the resource, in nanoseconds for BudgetCPUTime and bytes otherwise
+}
+
+func (e *AbortedError) Error() string {
+	if e.Resource == BudgetCPUTime {
+		return fmt.Sprintf("tracer aborted: %s budget of %v exceeded", e.Resource, time.Duration(e.Limit))
+	}
+	return fmt.Sprintf("tracer aborted: %s budget of %d bytes exceeded", e.Resource, e.Limit)
+}
+
+func (e *AbortedError) ErrorCode() int { return -32000 }
+
+func (e *AbortedError) ErrorData() interface{} {
+	return map[string]interface{}{"resource": e.Resource, "limit": e.Limit}
 }
 
 // Tracer interface extends vm.EVMLogger and additionally
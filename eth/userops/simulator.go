@@ -0,0 +1,287 @@
+// Package userops - node side of ERC-4337 user operations pool (erigon-lib/txpool/aa): simulation of user operations
+// validation by EntryPoint on latest state.
+package userops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/txpool/aa"
+
+	"github.com/ledgerwatch/erigon/accounts/abi"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/transactions"
+)
+
+var entryPointABI = `[
+{"type":"function","name":"simulateValidation","stateMutability":"nonpayable","outputs":[],"inputs":[{"name":"userOp","type":"tuple","components":[
+	{"name":"sender","type":"address"},{"name":"nonce","type":"uint256"},{"name":"initCode","type":"bytes"},{"name":"callData","type":"bytes"},
+	{"name":"callGasLimit","type":"uint256"},{"name":"verificationGasLimit","type":"uint256"},{"name":"preVerificationGas","type":"uint256"},
+	{"name":"maxFeePerGas","type":"uint256"},{"name":"maxPriorityFeePerGas","type":"uint256"},{"name":"paymasterAndData","type":"bytes"},
+	{"name":"signature","type":"bytes"}]}]},
+{"type":"function","name":"getNonce","stateMutability":"view","inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],
+	"outputs":[{"name":"nonce","type":"uint256"}]},
+{"type":"error","name":"FailedOp","inputs":[{"name":"opIndex","type":"uint256"},{"name":"reason","type":"string"}]},
+{"type":"error","name":"ValidationResult","inputs":[` + returnInfoABI + `,` + stakeInfoABI("senderInfo") + `,` + stakeInfoABI("factoryInfo") + `,` + stakeInfoABI("paymasterInfo") + `]},
+{"type":"error","name":"ValidationResultWithAggregation","inputs":[` + returnInfoABI + `,` + stakeInfoABI("senderInfo") + `,` + stakeInfoABI("factoryInfo") + `,` + stakeInfoABI("paymasterInfo") + `,
+	{"name":"aggregatorInfo","type":"tuple","components":[{"name":"aggregator","type":"address"},` + stakeInfoABI("stakeInfo") + `]}]}
+]`
+
+const returnInfoABI = `{"name":"returnInfo","type":"tuple","components":[{"name":"preOpGas","type":"uint256"},{"name":"prefund","type":"uint256"},
+	{"name":"sigFailed","type":"bool"},{"name":"validAfter","type":"uint48"},{"name":"validUntil","type":"uint48"},{"name":"paymasterContext","type":"bytes"}]}`
+
+func stakeInfoABI(name string) string {
+	return `{"name":"` + name + `","type":"tuple","components":[{"name":"stake","type":"uint256"},{"name":"unstakeDelaySec","type":"uint256"}]}`
+}
+
+var entryPoint = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(entryPointABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// userOperation - UserOperation in form of abi tuple
+type userOperation struct {
+	Sender               libcommon.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+type stakeInfo struct {
+	Stake           *big.Int
+	UnstakeDelaySec *big.Int
+}
+
+type validationResult struct {
+	ReturnInfo struct {
+		PreOpGas         *big.Int
+		Prefund          *big.Int
+		SigFailed        bool
+		ValidAfter       *big.Int
+		ValidUntil       *big.Int
+		PaymasterContext []byte
+	}
+	SenderInfo     stakeInfo
+	FactoryInfo    stakeInfo
+	PaymasterInfo  stakeInfo
+	AggregatorInfo struct { // only in ValidationResultWithAggregation
+		Aggregator libcommon.Address
+		StakeInfo  stakeInfo
+	}
+}
+
+// Simulator - aa.Simulator which executes EntryPoint on top of latest block
+type Simulator struct {
+	db          kv.RoDB
+	blockReader services.FullBlockReader
+	engine      consensus.EngineReader
+	chainConfig *chain.Config
+	historyV3   bool
+	gasCap      uint64
+	timeout     time.Duration
+}
+
+var _ aa.Simulator = &Simulator{}
+
+func NewSimulator(db kv.RoDB, blockReader services.FullBlockReader, engine consensus.EngineReader, chainConfig *chain.Config, historyV3 bool) *Simulator {
+	return &Simulator{db: db, blockReader: blockReader, engine: engine, chainConfig: chainConfig, historyV3: historyV3,
+		gasCap: 30_000_000, timeout: 5 * time.Second}
+}
+
+func (s *Simulator) SimulateValidation(ctx context.Context, entryPointAddr libcommon.Address, op *aa.UserOperation) (*aa.ValidationResult, error) {
+	data, err := entryPoint.Pack("simulateValidation", userOperation{
+		Sender:               op.Sender,
+		Nonce:                op.Nonce.ToBig(),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         op.CallGasLimit.ToBig(),
+		VerificationGasLimit: op.VerificationGasLimit.ToBig(),
+		PreVerificationGas:   op.PreVerificationGas.ToBig(),
+		MaxFeePerGas:         op.MaxFeePerGas.ToBig(),
+		MaxPriorityFeePerGas: op.MaxPriorityFeePerGas.ToBig(),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tracer := newValidationTracer()
+	res, err := s.call(ctx, entryPointAddr, data, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Failed() {
+		return nil, errors.New("simulateValidation didn't revert")
+	}
+	return decodeValidationResult(op, res.Revert(), tracer.traces)
+}
+
+func decodeValidationResult(op *aa.UserOperation, revert []byte, traces map[aa.Entity]*aa.EntityTrace) (*aa.ValidationResult, error) {
+	if len(revert) < 4 {
+		return nil, &aa.RejectError{Code: aa.CodeRejectedByAccount, Message: "simulateValidation reverted without reason"}
+	}
+	for _, name := range []string{"ValidationResult", "ValidationResultWithAggregation", "FailedOp"} {
+		e := entryPoint.Errors[name]
+		if libcommon.BytesToHash(e.ID[:4]) != libcommon.BytesToHash(revert[:4]) {
+			continue
+		}
+		values, err := e.Inputs.Unpack(revert[4:])
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+		if name == "FailedOp" {
+			reason, _ := values[1].(string)
+			code := aa.CodeRejectedByAccount
+			if strings.HasPrefix(reason, "AA3") { // AA3x - paymaster validation failures
+				code = aa.CodeRejectedByPaymaster
+			}
+			return nil, &aa.RejectError{Code: code, Message: reason}
+		}
+		var decoded validationResult
+		if err := e.Inputs.Copy(&decoded, values); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+		return validationResultFrom(op, &decoded, traces), nil
+	}
+	return nil, &aa.RejectError{Code: aa.CodeRejectedByAccount, Message: fmt.Sprintf("simulateValidation reverted: %x", revert)}
+}
+
+func validationResultFrom(op *aa.UserOperation, decoded *validationResult, traces map[aa.Entity]*aa.EntityTrace) *aa.ValidationResult {
+	info := &decoded.ReturnInfo
+	res := &aa.ValidationResult{
+		ReturnInfo: aa.ReturnInfo{
+			PreOpGas:         info.PreOpGas.Uint64(),
+			SigFailed:        info.SigFailed,
+			ValidAfter:       info.ValidAfter.Uint64(),
+			ValidUntil:       info.ValidUntil.Uint64(),
+			PaymasterContext: info.PaymasterContext,
+		},
+		Sender:    stakeInfoFrom(op.Sender, &decoded.SenderInfo),
+		Factory:   stakeInfoFrom(op.Factory(), &decoded.FactoryInfo),
+		Paymaster: stakeInfoFrom(op.Paymaster(), &decoded.PaymasterInfo),
+		Traces:    traces,
+	}
+	res.ReturnInfo.Prefund.SetFromBig(info.Prefund)
+	if res.ReturnInfo.ValidUntil == 1<<48-1 { // EntryPoint reports "no limit" as max uint48
+		res.ReturnInfo.ValidUntil = 0
+	}
+	if decoded.AggregatorInfo.Aggregator != (libcommon.Address{}) {
+		aggregator := stakeInfoFrom(decoded.AggregatorInfo.Aggregator, &decoded.AggregatorInfo.StakeInfo)
+		res.Aggregator = &aggregator
+	}
+	return res
+}
+
+func stakeInfoFrom(addr libcommon.Address, info *stakeInfo) aa.StakeInfo {
+	res := aa.StakeInfo{Addr: addr, UnstakeDelaySec: info.UnstakeDelaySec.Uint64()}
+	res.Stake.SetFromBig(info.Stake)
+	return res
+}
+
+func (s *Simulator) Nonce(ctx context.Context, entryPointAddr, sender libcommon.Address, key *uint256.Int) (*uint256.Int, error) {
+	data, err := entryPoint.Pack("getNonce", sender, key.ToBig())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.call(ctx, entryPointAddr, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed() {
+		return nil, fmt.Errorf("getNonce reverted: %w", res.Err)
+	}
+	values, err := entryPoint.Unpack("getNonce", res.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	nonce, _ := uint256.FromBig(values[0].(*big.Int))
+	return nonce, nil
+}
+
+// call - executes call of entry point on top of latest block
+func (s *Simulator) call(ctx context.Context, to libcommon.Address, data []byte, tracer vm.EVMLogger) (*core.ExecutionResult, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	blockNum, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return nil, err
+	}
+	header, err := s.blockReader.HeaderByNumber(ctx, tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header %d not found", blockNum)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	ibs := state.New(rpchelper.NewLatestStateReader(tx, s.historyV3))
+	msg := types.NewMessage(libcommon.Address{}, &to, 0, new(uint256.Int), s.gasCap, new(uint256.Int), nil, nil, data, nil,
+		false /* checkNonce */, true /* isFree */, nil)
+	blockCtx := transactions.NewEVMBlockContext(s.engine, header, true /* requireCanonical */, tx, s.blockReader)
+	vmConfig := vm.Config{NoBaseFee: true}
+	if tracer != nil {
+		vmConfig.Debug, vmConfig.Tracer = true, tracer
+	}
+	evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), ibs, s.chainConfig, vmConfig)
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	gp := new(core.GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	res, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
+	if err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", s.timeout)
+	}
+	return res, nil
+}
+
+// HeadLoop - removes included and expired user operations from pool on every new header
+func HeadLoop(ctx context.Context, pool *aa.Pool, headers <-chan [][]byte, logger log.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-headers:
+			if !ok {
+				return
+			}
+			if err := pool.OnNewHead(ctx); err != nil {
+				logger.Warn("[txpool.aa] OnNewHead", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package userops
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/txpool/aa"
+)
+
+func TestDecodeValidationResult(t *testing.T) {
+	paymaster := libcommon.Address{0xaa}
+	op := &aa.UserOperation{Sender: libcommon.Address{1}, PaymasterAndData: paymaster[:]}
+
+	var result validationResult
+	result.ReturnInfo.PreOpGas = big.NewInt(50_000)
+	result.ReturnInfo.Prefund = big.NewInt(1_000_000)
+	result.ReturnInfo.ValidAfter = big.NewInt(0)
+	result.ReturnInfo.ValidUntil = big.NewInt(1<<48 - 1)
+	result.ReturnInfo.PaymasterContext = []byte{1, 2}
+	result.SenderInfo = stakeInfo{Stake: big.NewInt(0), UnstakeDelaySec: big.NewInt(0)}
+	result.FactoryInfo = stakeInfo{Stake: big.NewInt(0), UnstakeDelaySec: big.NewInt(0)}
+	result.PaymasterInfo = stakeInfo{Stake: big.NewInt(1e18), UnstakeDelaySec: big.NewInt(86400)}
+
+	e := entryPoint.Errors["ValidationResult"]
+	data, err := e.Inputs.Pack(result.ReturnInfo, result.SenderInfo, result.FactoryInfo, result.PaymasterInfo)
+	require.NoError(t, err)
+	traces := map[aa.Entity]*aa.EntityTrace{}
+	res, err := decodeValidationResult(op, append(e.ID[:4:4], data...), traces)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50_000), res.ReturnInfo.PreOpGas)
+	require.Equal(t, uint64(1_000_000), res.ReturnInfo.Prefund.Uint64())
+	require.Equal(t, uint64(0), res.ReturnInfo.ValidUntil)
+	require.Equal(t, []byte{1, 2}, res.ReturnInfo.PaymasterContext)
+	require.Equal(t, paymaster, res.Paymaster.Addr)
+	require.Equal(t, uint64(86400), res.Paymaster.UnstakeDelaySec)
+	require.Equal(t, uint64(1e18), res.Paymaster.Stake.Uint64())
+	require.Nil(t, res.Aggregator)
+
+	e = entryPoint.Errors["FailedOp"]
+	data, err = e.Inputs.Pack(big.NewInt(0), "AA33 reverted (or OOG)")
+	require.NoError(t, err)
+	_, err = decodeValidationResult(op, append(e.ID[:4:4], data...), traces)
+	require.Equal(t, &aa.RejectError{Code: aa.CodeRejectedByPaymaster, Message: "AA33 reverted (or OOG)"}, err)
+}
@@ -0,0 +1,119 @@
+package userops
+
+import (
+	"errors"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/txpool/aa"
+
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// validationTracer - collects what validation code of each entity does during `EntryPoint.simulateValidation`.
+// EntryPoint v0.6 executes NUMBER opcode (at depth 1) as marker between validation phases:
+// factory (deployment of sender), account, paymaster.
+type validationTracer struct {
+	env    *vm.EVM
+	phase  int
+	traces map[aa.Entity]*aa.EntityTrace
+	gasOp  bool // previous opcode was GAS, it's allowed only if followed by *CALL
+}
+
+var phases = []aa.Entity{aa.EntityFactory, aa.EntitySender, aa.EntityPaymaster}
+
+func newValidationTracer() *validationTracer {
+	return &validationTracer{traces: map[aa.Entity]*aa.EntityTrace{}}
+}
+
+func (t *validationTracer) trace() *aa.EntityTrace {
+	if t.phase >= len(phases) {
+		return nil
+	}
+	entity := phases[t.phase]
+	trace, ok := t.traces[entity]
+	if !ok {
+		trace = &aa.EntityTrace{
+			Opcodes:      map[string]int{},
+			Storage:      map[libcommon.Address]aa.StorageAccess{},
+			ContractSize: map[libcommon.Address]int{},
+		}
+		t.traces[entity] = trace
+	}
+	return trace
+}
+
+func (t *validationTracer) CaptureTxStart(gasLimit uint64) {}
+func (t *validationTracer) CaptureTxEnd(restGas uint64)    {}
+
+func (t *validationTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	t.env = env
+}
+
+func (t *validationTracer) CaptureEnd(output []byte, usedGas uint64, err error) {}
+
+func (t *validationTracer) CaptureEnter(typ vm.OpCode, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	if precompile || create {
+		return
+	}
+	if trace := t.trace(); trace != nil {
+		trace.ContractSize[to] = len(code)
+	}
+}
+
+func (t *validationTracer) CaptureExit(output []byte, usedGas uint64, err error) {
+	if errors.Is(err, vm.ErrOutOfGas) {
+		if trace := t.trace(); trace != nil {
+			trace.OOG = true
+		}
+	}
+}
+
+func (t *validationTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if depth == 1 {
+		if op == vm.NUMBER {
+			t.phase++
+		}
+		return
+	}
+	trace := t.trace()
+	if trace == nil {
+		return
+	}
+	if t.gasOp && op != vm.CALL && op != vm.CALLCODE && op != vm.DELEGATECALL && op != vm.STATICCALL {
+		trace.Opcodes[vm.GAS.String()]++
+	}
+	t.gasOp = op == vm.GAS
+	if op != vm.GAS {
+		trace.Opcodes[op.String()]++
+	}
+
+	st := scope.Stack
+	switch op {
+	case vm.SLOAD, vm.SSTORE:
+		addr := scope.Contract.Address()
+		access, ok := trace.Storage[addr]
+		if !ok {
+			access = aa.StorageAccess{Reads: map[libcommon.Hash]struct{}{}, Writes: map[libcommon.Hash]struct{}{}}
+			trace.Storage[addr] = access
+		}
+		slot := libcommon.Hash(st.Peek().Bytes32())
+		if op == vm.SLOAD {
+			access.Reads[slot] = struct{}{}
+		} else {
+			access.Writes[slot] = struct{}{}
+		}
+	case vm.KECCAK256:
+		offset, size := st.Back(0), st.Back(1)
+		if size.IsUint64() && size.Uint64() <= 512 && offset.IsUint64() {
+			trace.Keccak = append(trace.Keccak, scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64())))
+		}
+	case vm.EXTCODESIZE, vm.EXTCODEHASH, vm.EXTCODECOPY:
+		addr := libcommon.Address(st.Peek().Bytes20())
+		trace.ContractSize[addr] = t.env.IntraBlockState().GetCodeSize(addr)
+	}
+}
+
+func (t *validationTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
@@ -9,6 +9,7 @@ import (
 
 	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
+	"github.com/ledgerwatch/erigon-lib/txpool"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -28,7 +29,7 @@ func StartGrpc(kv *remotedbserver.KvServer, ethBackendSrv *EthBackendServer, txP
 	grpcServer := grpcutil.NewServer(rateLimit, creds)
 	remote.RegisterETHBACKENDServer(grpcServer, ethBackendSrv)
 	if txPoolServer != nil {
-		txpool_proto.RegisterTxpoolServer(grpcServer, txPoolServer)
+		txpool.RegisterTxpoolServer(grpcServer, txPoolServer)
 	}
 	if miningServer != nil {
 		txpool_proto.RegisterMiningServer(grpcServer, miningServer)
@@ -54,6 +54,10 @@ type EthBackend interface {
 	NodesInfo(limit int) (*remote.NodesInfoReply, error)
 	Peers(ctx context.Context) (*remote.PeersReply, error)
 	AddPeer(ctx context.Context, url *remote.AddPeerRequest) (*remote.AddPeerReply, error)
+	RemovePeer(ctx context.Context, req *remote.RemovePeerRequest) (*remote.RemovePeerReply, error)
+	AddTrustedPeer(ctx context.Context, req *remote.AddTrustedPeerRequest) (*remote.AddTrustedPeerReply, error)
+	RemoveTrustedPeer(ctx context.Context, req *remote.RemoveTrustedPeerRequest) (*remote.RemoveTrustedPeerReply, error)
+	ReloadPeerFiles(ctx context.Context, req *remote.ReloadPeerFilesRequest) (*remote.ReloadPeerFilesReply, error)
 }
 
 func NewEthBackendServer(ctx context.Context, eth EthBackend, db kv.RwDB, events *shards.Events, blockReader services.FullBlockReader,
@@ -251,6 +255,22 @@ func (s *EthBackendServer) AddPeer(ctx context.Context, req *remote.AddPeerReque
 	return s.eth.AddPeer(ctx, req)
 }
 
+func (s *EthBackendServer) RemovePeer(ctx context.Context, req *remote.RemovePeerRequest) (*remote.RemovePeerReply, error) {
+	return s.eth.RemovePeer(ctx, req)
+}
+
+func (s *EthBackendServer) AddTrustedPeer(ctx context.Context, req *remote.AddTrustedPeerRequest) (*remote.AddTrustedPeerReply, error) {
+	return s.eth.AddTrustedPeer(ctx, req)
+}
+
+func (s *EthBackendServer) RemoveTrustedPeer(ctx context.Context, req *remote.RemoveTrustedPeerRequest) (*remote.RemoveTrustedPeerReply, error) {
+	return s.eth.RemoveTrustedPeer(ctx, req)
+}
+
+func (s *EthBackendServer) ReloadPeerFiles(ctx context.Context, req *remote.ReloadPeerFilesRequest) (*remote.ReloadPeerFilesReply, error) {
+	return s.eth.ReloadPeerFiles(ctx, req)
+}
+
 func (s *EthBackendServer) SubscribeLogs(server remote.ETHBACKEND_SubscribeLogsServer) (err error) {
 	if s.logsFilter != nil {
 		return s.logsFilter.subscribeLogs(server)
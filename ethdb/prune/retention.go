@@ -0,0 +1,113 @@
+package prune
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dataTypes - data types with independent retention: `--prune.<name>` flags and `[prune]` block of config file.
+// Methods - RPC methods which can't serve (or serve incomplete results for) blocks which data is pruned
+var dataTypes = []struct {
+	name    string
+	amount  func(m *Mode) *BlockAmount
+	methods []string
+}{
+	{"history", func(m *Mode) *BlockAmount { return &m.History }, []string{
+		"eth_getBalance", "eth_getCode", "eth_getStorageAt", "eth_getTransactionCount", "eth_call", "eth_estimateGas",
+		"eth_createAccessList", "debug_traceTransaction", "debug_traceBlockByNumber", "debug_traceCall", "trace_block",
+		"trace_transaction", "trace_replayBlockTransactions", "trace_call"}},
+	{"receipts", func(m *Mode) *BlockAmount { return &m.Receipts }, []string{
+		"eth_getTransactionReceipt", "eth_getBlockReceipts"}},
+	{"txindex", func(m *Mode) *BlockAmount { return &m.TxIndex }, []string{
+		"eth_getTransactionByHash", "eth_getTransactionReceipt", "debug_traceTransaction", "trace_transaction"}},
+	{"calltraces", func(m *Mode) *BlockAmount { return &m.CallTraces }, []string{
+		"trace_filter", "ots_searchTransactionsBefore", "ots_searchTransactionsAfter"}},
+	{"logindex", func(m *Mode) *BlockAmount { return &m.LogIndex }, []string{
+		"eth_getLogs", "eth_getFilterLogs", "erigon_getLogs"}},
+}
+
+// DataTypes - names of data types with independent retention
+func DataTypes() []string {
+	names := make([]string, len(dataTypes))
+	for i, t := range dataTypes {
+		names[i] = t.name
+	}
+	return names
+}
+
+// ParseRetention - `older=N`: keep last N blocks, `before=N`: keep blocks starting from N. Empty - not set (nil)
+func ParseRetention(s string) (BlockAmount, error) {
+	if s == "" {
+		return nil, nil
+	}
+	kind, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid retention %q, expected older=N or before=N", s)
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention %q: %w", s, err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("invalid retention %q: must be positive", s)
+	}
+	switch kind {
+	case "older":
+		return Distance(n), nil
+	case "before":
+		return Before(n), nil
+	default:
+		return nil, fmt.Errorf("invalid retention %q, expected older=N or before=N", s)
+	}
+}
+
+func retentionString(b BlockAmount) string {
+	return fmt.Sprintf("%s=%d", b.dbType(), b.toValue())
+}
+
+// ApplyRetention - overrides retention of data types, keys are DataTypes(). Takes precedence over --prune letters and
+// --prune.<x>.older/before flags. Log index follows receipts unless set explicitly.
+func (m Mode) ApplyRetention(retention map[string]string) (Mode, error) {
+	known := make(map[string]struct{}, len(dataTypes))
+	for _, t := range dataTypes {
+		known[t.name] = struct{}{}
+		amount, err := ParseRetention(retention[t.name])
+		if err != nil {
+			return m, fmt.Errorf("--prune.%s: %w", t.name, err)
+		}
+		if amount == nil {
+			continue
+		}
+		*t.amount(&m) = amount
+		if t.name == "receipts" && retention["logindex"] == "" {
+			m.LogIndex = amount
+		}
+	}
+	for name := range retention {
+		if _, ok := known[name]; !ok {
+			return m, fmt.Errorf("unknown prune data type %q, available: %s", name, strings.Join(DataTypes(), ", "))
+		}
+	}
+	return m, nil
+}
+
+// Degradation - RPC methods which can't serve data of blocks pruned by the mode
+type Degradation struct {
+	Data      string // data type, one of DataTypes()
+	Retention string // older=N or before=N
+	Methods   []string
+}
+
+// Degraded - data types pruned by the mode and RPC methods which degrade for old blocks because of it
+func (m Mode) Degraded() []Degradation {
+	var res []Degradation
+	for _, t := range dataTypes {
+		amount := *t.amount(&m)
+		if amount == nil || !amount.Enabled() {
+			continue
+		}
+		res = append(res, Degradation{Data: t.name, Retention: retentionString(amount), Methods: t.methods})
+	}
+	return res
+}
@@ -0,0 +1,69 @@
+package prune
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetention(t *testing.T) {
+	for s, expected := range map[string]BlockAmount{
+		"":              nil,
+		"older=90000":   Distance(90_000),
+		"before=100000": Before(100_000),
+	} {
+		amount, err := ParseRetention(s)
+		require.NoError(t, err)
+		require.Equal(t, expected, amount)
+	}
+	for _, s := range []string{"90000", "older=", "older=0", "newer=10", "before=-1"} {
+		_, err := ParseRetention(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	mode, err := FromCli(1, "hr", 0, 0, 0, 0, 0, 0, 0, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, mode.Receipts, mode.LogIndex)
+
+	// overrides --prune letters, log index follows receipts
+	mode, err = mode.ApplyRetention(map[string]string{"history": "older=1000", "receipts": "before=500"})
+	require.NoError(t, err)
+	require.Equal(t, Distance(1000), mode.History)
+	require.Equal(t, Before(500), mode.Receipts)
+	require.Equal(t, Before(500), mode.LogIndex)
+	require.Equal(t, "--prune.h.older=1000 --prune.r.before=500", mode.String())
+
+	mode, err = mode.ApplyRetention(map[string]string{"logindex": "older=10", "calltraces": ""})
+	require.NoError(t, err)
+	require.Equal(t, Distance(10), mode.LogIndex)
+	require.False(t, mode.CallTraces.Enabled())
+	require.Equal(t, "--prune.h.older=1000 --prune.r.before=500 --prune.logindex=older=10", mode.String())
+
+	_, err = mode.ApplyRetention(map[string]string{"logs": "older=10"})
+	require.Error(t, err)
+	_, err = mode.ApplyRetention(map[string]string{"txindex": "10"})
+	require.Error(t, err)
+
+	var degraded []string
+	for _, d := range mode.Degraded() {
+		degraded = append(degraded, d.Data+":"+d.Retention)
+		require.NotEmpty(t, d.Methods)
+	}
+	require.Equal(t, []string{"history:older=1000", "receipts:before=500", "logindex:older=10"}, degraded)
+	require.Empty(t, DefaultMode.Degraded())
+
+	// stored with the mode, dbs without log index retention prune it with receipts
+	_, tx := memdb.NewTestTx(t)
+	require.NoError(t, Override(tx, mode))
+	stored, err := Get(tx)
+	require.NoError(t, err)
+	require.Equal(t, mode, stored)
+	require.NoError(t, tx.Delete(kv.DatabaseInfo, kv.PruneLogIndex))
+	stored, err = Get(tx)
+	require.NoError(t, err)
+	require.Equal(t, Before(500), stored.LogIndex)
+}
@@ -20,9 +20,19 @@ var DefaultMode = Mode{
 	Receipts:    Distance(math.MaxUint64),
 	TxIndex:     Distance(math.MaxUint64),
 	CallTraces:  Distance(math.MaxUint64),
+	Blocks:      Distance(math.MaxUint64),
+	LogIndex:    Distance(math.MaxUint64),
 	Experiments: Experiments{}, // all off
 }
 
+// expiryBlocks - first post-merge block of networks which pre-merge history is distributed outside of p2p:
+// era1 archives and Portal network (EIP-4444)
+var expiryBlocks = map[uint64]uint64{
+	1:        15_537_394, // mainnet
+	5:        7_382_819,  // goerli
+	11155111: 1_450_409,  // sepolia
+}
+
 type Experiments struct {
 }
 
@@ -41,6 +51,12 @@ func FromCli(chainId uint64, flags string, exactHistory, exactReceipts, exactTxI
 				mode.TxIndex = Distance(params.FullImmutabilityThreshold)
 			case 'c':
 				mode.CallTraces = Distance(params.FullImmutabilityThreshold)
+			case 'e':
+				expiryBlock, ok := expiryBlocks[chainId]
+				if !ok {
+					return DefaultMode, fmt.Errorf("history expiry is not supported for chain id %d", chainId)
+				}
+				mode.Blocks = Before(expiryBlock)
 			default:
 				return DefaultMode, fmt.Errorf("unexpected flag found: %c", flag)
 			}
@@ -73,6 +89,14 @@ func FromCli(chainId uint64, flags string, exactHistory, exactReceipts, exactTxI
 		mode.CallTraces = Before(beforeC)
 	}
 
+	// expired blocks have no receipts
+	if mode.Blocks.Enabled() && !mode.Receipts.Enabled() {
+		mode.Receipts = mode.Blocks
+	}
+
+	// log index follows receipts unless set by --prune.logindex
+	mode.LogIndex = mode.Receipts
+
 	for _, ex := range experiments {
 		switch ex {
 		case "":
@@ -120,6 +144,24 @@ func Get(db kv.Getter) (Mode, error) {
 		prune.CallTraces = blockAmount
 	}
 
+	blockAmount, err = get(db, kv.PruneBlocks)
+	if err != nil {
+		return prune, err
+	}
+	if blockAmount != nil {
+		prune.Blocks = blockAmount
+	}
+
+	// dbs created before --prune.logindex prune log index with receipts
+	prune.LogIndex = prune.Receipts
+	blockAmount, err = get(db, kv.PruneLogIndex)
+	if err != nil {
+		return prune, err
+	}
+	if blockAmount != nil {
+		prune.LogIndex = blockAmount
+	}
+
 	return prune, nil
 }
 
@@ -129,9 +171,20 @@ type Mode struct {
 	Receipts    BlockAmount
 	TxIndex     BlockAmount
 	CallTraces  BlockAmount
+	Blocks      BlockAmount // EIP-4444 history expiry: bodies and receipts of blocks before it are served only from files
+	LogIndex    BlockAmount
 	Experiments Experiments
 }
 
+// ExpiryBlock - first block which bodies and receipts are kept by node, 0 if history expiry is off.
+// Pre-merge transactions and receipts files are not downloaded, but used if present.
+func (m Mode) ExpiryBlock() uint64 {
+	if b, ok := m.Blocks.(Before); ok {
+		return uint64(b)
+	}
+	return 0
+}
+
 type BlockAmount interface {
 	PruneTo(stageHead uint64) uint64
 	Enabled() bool
@@ -215,6 +268,12 @@ func (m Mode) String() string {
 			long += fmt.Sprintf(" --prune.c.%s=%d", m.CallTraces.dbType(), m.CallTraces.toValue())
 		}
 	}
+	if m.Blocks.Enabled() {
+		short += " --prune=e"
+	}
+	if m.LogIndex != m.Receipts {
+		long += " --prune.logindex=" + retentionString(m.LogIndex)
+	}
 
 	return strings.TrimLeft(short+long, " ")
 }
@@ -244,6 +303,16 @@ func Override(db kv.RwTx, sm Mode) error {
 		return err
 	}
 
+	err = set(db, kv.PruneBlocks, sm.Blocks)
+	if err != nil {
+		return err
+	}
+
+	err = set(db, kv.PruneLogIndex, sm.LogIndex)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -262,6 +331,9 @@ func EnsureNotChanged(tx kv.GetPut, pruneMode Mode) (Mode, error) {
 	if pruneMode.Initialised {
 		// Don't change from previous default as default for Receipts pruning has now changed
 		if pruneMode.Receipts.useDefaultValue() {
+			if pruneMode.LogIndex == pruneMode.Receipts {
+				pruneMode.LogIndex = pm.LogIndex
+			}
 			pruneMode.Receipts = pm.Receipts
 		}
 
@@ -290,6 +362,8 @@ func setIfNotExist(db kv.GetPut, pm Mode) error {
 		string(kv.PruneReceipts):   pm.Receipts,
 		string(kv.PruneTxIndex):    pm.TxIndex,
 		string(kv.PruneCallTraces): pm.CallTraces,
+		string(kv.PruneBlocks):     pm.Blocks,
+		string(kv.PruneLogIndex):   pm.LogIndex,
 	}
 
 	for key, value := range pruneDBData {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,16 +16,35 @@ func TestSetStorageModeIfNotExist(t *testing.T) {
 	prune, err := Get(tx)
 	assert.NoError(t, err)
 	assert.Equal(t, Mode{true, Distance(math.MaxUint64), Distance(math.MaxUint64),
-		Distance(math.MaxUint64), Distance(math.MaxUint64), Experiments{}}, prune)
+		Distance(math.MaxUint64), Distance(math.MaxUint64), Distance(math.MaxUint64), Distance(math.MaxUint64), Experiments{}}, prune)
 
 	err = setIfNotExist(tx, Mode{true, Distance(1), Distance(2),
-		Before(3), Before(4), Experiments{}})
+		Before(3), Before(4), Before(5), Distance(6), Experiments{}})
 	assert.NoError(t, err)
 
 	prune, err = Get(tx)
 	assert.NoError(t, err)
 	assert.Equal(t, Mode{true, Distance(1), Distance(2),
-		Before(3), Before(4), Experiments{}}, prune)
+		Before(3), Before(4), Before(5), Distance(6), Experiments{}}, prune)
+}
+
+func TestHistoryExpiryFromCli(t *testing.T) {
+	mode, err := FromCli(1, "e", 0, 0, 0, 0, 0, 0, 0, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15_537_394), mode.ExpiryBlock())
+	assert.Equal(t, Before(15_537_394), mode.Receipts)
+	assert.Equal(t, "--prune=e --prune.r.before=15537394", mode.String())
+
+	mode, err = FromCli(1, "re", 0, 0, 0, 0, 0, 0, 0, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Distance(params.FullImmutabilityThreshold), mode.Receipts)
+
+	mode, err = FromCli(1, "h", 0, 0, 0, 0, 0, 0, 0, 0, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, mode.ExpiryBlock())
+
+	_, err = FromCli(137, "e", 0, 0, 0, 0, 0, 0, 0, 0, nil)
+	assert.Error(t, err)
 }
 
 var distanceTests = []struct {
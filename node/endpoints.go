@@ -67,6 +67,7 @@ func StartHTTPEndpoint(urlEndpoint string, cfg *HttpEndpointConfig, handler http
 		WriteTimeout:      cfg.Timeouts.WriteTimeout,
 		IdleTimeout:       cfg.Timeouts.IdleTimeout,
 		ReadHeaderTimeout: cfg.Timeouts.ReadTimeout,
+		ConnState:         rpc.HTTPConnState,
 	}
 	// start the HTTP server
 	go func() {
@@ -121,6 +122,7 @@ func StartHTTPSEndpoint(urlEndpoint string,
 		WriteTimeout:      timeouts.WriteTimeout,
 		IdleTimeout:       timeouts.IdleTimeout,
 		ReadHeaderTimeout: timeouts.ReadTimeout,
+		ConnState:         rpc.HTTPConnState,
 	}
 	// start the HTTP server
 	go func() {
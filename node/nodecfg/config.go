@@ -23,9 +23,11 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/ledgerwatch/erigon/common"
@@ -158,10 +160,19 @@ type Config struct {
 
 	TLSKeyFile string
 	TLSCACert  string
+	// TLSAllowedCNs - Common Names of clients allowed to connect to private api (rpcdaemon, txpool, ...). Requires TLSCACert.
+	TLSAllowedCNs []string
+	// SentryTLSAllowedCNs, DownloaderTLSAllowedCNs - Common Names expected from external sentries and downloader
+	SentryTLSAllowedCNs     []string
+	DownloaderTLSAllowedCNs []string
+	// TLSReloadInterval - how often certificate files are checked for rotation
+	TLSReloadInterval time.Duration
 
 	MdbxPageSize    datasize.ByteSize
 	MdbxDBSizeLimit datasize.ByteSize
 	MdbxGrowthStep  datasize.ByteSize
+	// MdbxReadTxWatchdog - report chaindata read transactions living longer than this. 0 - disabled
+	MdbxReadTxWatchdog time.Duration
 	// HealthCheck enables standard grpc health check
 	HealthCheck bool
 
@@ -246,6 +257,21 @@ func (c *Config) ExtRPCEnabled() bool {
 	return c.HTTPHost != "" || c.WSHost != ""
 }
 
+// GrpcTLS - TLS settings of gRPC connections with other Erigon components (both server and client side).
+// allowedCNs - Common Names of other side. Empty result if --tls is not set.
+func (c *Config) GrpcTLS(allowedCNs []string) grpcutil.TLSConfig {
+	if !c.TLSConnection {
+		return grpcutil.TLSConfig{}
+	}
+	return grpcutil.TLSConfig{
+		CACert:         c.TLSCACert,
+		CertFile:       c.TLSCertFile,
+		KeyFile:        c.TLSKeyFile,
+		AllowedCNs:     allowedCNs,
+		ReloadInterval: c.TLSReloadInterval,
+	}
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
@@ -3,6 +3,7 @@ package sentry
 import (
 	"fmt"
 
+	"github.com/ledgerwatch/erigon-lib/direct"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
 	"github.com/ledgerwatch/erigon/core/forkid"
@@ -21,7 +22,7 @@ func readAndValidatePeerStatusMessage(
 		return nil, p2p.NewPeerError(p2p.PeerErrorStatusReceive, p2p.DiscNetworkError, err, "readAndValidatePeerStatusMessage rw.ReadMsg error")
 	}
 
-	reply, err := tryDecodeStatusMessage(&msg)
+	reply, err := tryDecodeStatusMessage(&msg, version)
 	msg.Discard()
 	if err != nil {
 		return nil, p2p.NewPeerError(p2p.PeerErrorStatusDecode, p2p.DiscProtocolError, err, "readAndValidatePeerStatusMessage tryDecodeStatusMessage error")
@@ -35,7 +36,7 @@ func readAndValidatePeerStatusMessage(
 	return reply, nil
 }
 
-func tryDecodeStatusMessage(msg *p2p.Msg) (*eth.StatusPacket, error) {
+func tryDecodeStatusMessage(msg *p2p.Msg, version uint) (*eth.StatusPacket, error) {
 	if msg.Code != eth.StatusMsg {
 		return nil, fmt.Errorf("first msg has code %x (!= %x)", msg.Code, eth.StatusMsg)
 	}
@@ -44,6 +45,23 @@ func tryDecodeStatusMessage(msg *p2p.Msg) (*eth.StatusPacket, error) {
 		return nil, fmt.Errorf("message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize)
 	}
 
+	if version >= direct.ETH69 {
+		var reply eth.StatusPacket69
+		if err := msg.Decode(&reply); err != nil {
+			return nil, fmt.Errorf("decode message %v: %w", msg, err)
+		}
+		if reply.EarliestBlock > reply.LatestBlock {
+			return nil, fmt.Errorf("invalid block range: earliest %d, latest %d", reply.EarliestBlock, reply.LatestBlock)
+		}
+		return &eth.StatusPacket{
+			ProtocolVersion: reply.ProtocolVersion,
+			NetworkID:       reply.NetworkID,
+			Head:            reply.LatestBlockHash,
+			Genesis:         reply.Genesis,
+			ForkID:          reply.ForkID,
+		}, nil
+	}
+
 	var reply eth.StatusPacket
 	if err := msg.Decode(&reply); err != nil {
 		return nil, fmt.Errorf("decode message %v: %w", msg, err)
@@ -1,6 +1,7 @@
 package sentry
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 
@@ -14,7 +15,9 @@ import (
 
 	"github.com/ledgerwatch/erigon/core/forkid"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/p2p"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 )
 
 func TestCheckPeerStatusCompatibility(t *testing.T) {
@@ -81,3 +84,37 @@ func TestCheckPeerStatusCompatibility(t *testing.T) {
 		assert.ErrorIs(t, err, forkid.ErrLocalIncompatibleOrStale)
 	})
 }
+
+func TestDecodeStatusMessage69(t *testing.T) {
+	encode := func(packet *eth.StatusPacket69) *p2p.Msg {
+		b, err := rlp.EncodeToBytes(packet)
+		assert.NoError(t, err)
+		return &p2p.Msg{Code: eth.StatusMsg, Size: uint32(len(b)), Payload: bytes.NewReader(b)}
+	}
+	packet := eth.StatusPacket69{
+		ProtocolVersion: direct.ETH69,
+		NetworkID:       1,
+		Genesis:         params.MainnetGenesisHash,
+		EarliestBlock:   10,
+		LatestBlock:     20,
+		LatestBlockHash: libcommon.HexToHash("0x01"),
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		reply, err := tryDecodeStatusMessage(encode(&packet), direct.ETH69)
+		assert.NoError(t, err)
+		assert.Equal(t, packet.LatestBlockHash, reply.Head)
+		assert.Equal(t, packet.Genesis, reply.Genesis)
+		assert.Nil(t, reply.TD)
+	})
+	t.Run("invalid range", func(t *testing.T) {
+		invalid := packet
+		invalid.EarliestBlock = 21
+		_, err := tryDecodeStatusMessage(encode(&invalid), direct.ETH69)
+		assert.ErrorContains(t, err, "invalid block range")
+	})
+	t.Run("eth/68 status", func(t *testing.T) {
+		_, err := tryDecodeStatusMessage(encode(&packet), direct.ETH68)
+		assert.Error(t, err)
+	})
+}
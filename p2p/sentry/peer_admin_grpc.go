@@ -0,0 +1,56 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+)
+
+// adminPeer parses the enode URL and calls f with the started p2p server
+func (ss *GrpcServer) adminPeer(url string, f func(srv *p2p.Server, node *enode.Node)) error {
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	srv := ss.getP2PServer()
+	if srv == nil {
+		return errors.New("p2p server was not started")
+	}
+	f(srv, node)
+	return nil
+}
+
+func (ss *GrpcServer) RemovePeer(_ context.Context, req *proto_sentry.RemovePeerRequest) (*proto_sentry.RemovePeerReply, error) {
+	if err := ss.adminPeer(req.Url, func(srv *p2p.Server, node *enode.Node) { srv.RemovePeer(node) }); err != nil {
+		return nil, err
+	}
+	return &proto_sentry.RemovePeerReply{Success: true}, nil
+}
+
+func (ss *GrpcServer) AddTrustedPeer(_ context.Context, req *proto_sentry.AddTrustedPeerRequest) (*proto_sentry.AddTrustedPeerReply, error) {
+	if err := ss.adminPeer(req.Url, func(srv *p2p.Server, node *enode.Node) { srv.AddTrustedPeer(node) }); err != nil {
+		return nil, err
+	}
+	return &proto_sentry.AddTrustedPeerReply{Success: true}, nil
+}
+
+func (ss *GrpcServer) RemoveTrustedPeer(_ context.Context, req *proto_sentry.RemoveTrustedPeerRequest) (*proto_sentry.RemoveTrustedPeerReply, error) {
+	if err := ss.adminPeer(req.Url, func(srv *p2p.Server, node *enode.Node) { srv.RemoveTrustedPeer(node) }); err != nil {
+		return nil, err
+	}
+	return &proto_sentry.RemoveTrustedPeerReply{Success: true}, nil
+}
+
+func (ss *GrpcServer) ReloadPeerFiles(_ context.Context, _ *proto_sentry.ReloadPeerFilesRequest) (*proto_sentry.ReloadPeerFilesReply, error) {
+	if err := ss.reloadPeerFiles(true); err != nil {
+		return nil, err
+	}
+	return &proto_sentry.ReloadPeerFilesReply{Success: true}, nil
+}
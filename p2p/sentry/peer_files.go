@@ -0,0 +1,174 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+)
+
+// peerFilesInterval - how often static and trusted peers files are checked for changes
+const peerFilesInterval = 5 * time.Second
+
+// peersFile - file with JSON list of enode URLs, see p2p.Config.StaticNodesFile. Remembers the nodes applied from
+// it, so the nodes removed from the file are removed from the p2p server. A missing file is an empty list.
+type peersFile struct {
+	path       string
+	configured map[enode.ID]struct{} // nodes of the config, never removed
+	modTime    time.Time
+	size       int64
+	loaded     bool
+	nodes      map[enode.ID]*enode.Node
+}
+
+func newPeersFile(path string, configured []*enode.Node) *peersFile {
+	f := &peersFile{path: path, configured: map[enode.ID]struct{}{}, nodes: map[enode.ID]*enode.Node{}}
+	for _, n := range configured {
+		f.configured[n.ID()] = struct{}{}
+	}
+	return f
+}
+
+func readPeersFile(path string) ([]*enode.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	nodes := make([]*enode.Node, 0, len(urls))
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		n, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid node URL %s: %w", path, url, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// changed - whether the file changed since it was loaded
+func (f *peersFile) changed() bool {
+	if !f.loaded {
+		return true
+	}
+	st, err := os.Stat(f.path)
+	if err != nil {
+		return len(f.nodes) > 0 || !errors.Is(err, os.ErrNotExist)
+	}
+	return !st.ModTime().Equal(f.modTime) || st.Size() != f.size
+}
+
+// reload applies the nodes of the file: adds the new ones and removes the ones which are not in the file anymore.
+// On error the previously applied nodes are kept, until the file changes again.
+func (f *peersFile) reload(add, remove func(*enode.Node)) (added, removed int, err error) {
+	f.modTime, f.size, f.loaded = time.Time{}, 0, true
+	if st, err := os.Stat(f.path); err == nil {
+		f.modTime, f.size = st.ModTime(), st.Size()
+	}
+	nodes, err := readPeersFile(f.path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current := make(map[enode.ID]*enode.Node, len(nodes))
+	for _, n := range nodes {
+		current[n.ID()] = n
+		if old, ok := f.nodes[n.ID()]; ok && old.URLv4() == n.URLv4() {
+			continue
+		}
+		add(n)
+		added++
+	}
+	for id, n := range f.nodes {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if _, ok := f.configured[id]; !ok {
+			remove(n)
+			removed++
+		}
+	}
+	f.nodes = current
+	return added, removed, nil
+}
+
+// peerFiles - static and trusted peers files of the sentry, nil fields if not configured
+type peerFiles struct {
+	lock    sync.Mutex
+	static  *peersFile
+	trusted *peersFile
+}
+
+func newPeerFiles(cfg *p2p.Config) *peerFiles {
+	pf := &peerFiles{}
+	if cfg.StaticNodesFile != "" {
+		pf.static = newPeersFile(cfg.StaticNodesFile, cfg.StaticNodes)
+	}
+	if cfg.TrustedNodesFile != "" {
+		pf.trusted = newPeersFile(cfg.TrustedNodesFile, cfg.TrustedNodes)
+	}
+	return pf
+}
+
+// reloadPeerFiles applies static and trusted peers files to the p2p server, if they changed or force is set
+func (ss *GrpcServer) reloadPeerFiles(force bool) error {
+	srv := ss.getP2PServer()
+	if srv == nil {
+		return errors.New("p2p server was not started")
+	}
+	pf := ss.peerFiles
+	pf.lock.Lock()
+	defer pf.lock.Unlock()
+	var errs []error
+	apply := func(f *peersFile, add, remove func(*enode.Node)) {
+		if f == nil || !(force || f.changed()) {
+			return
+		}
+		added, removed, err := f.reload(add, remove)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		ss.logger.Info("[sentry] peers file reloaded", "file", f.path, "nodes", len(f.nodes), "added", added, "removed", removed)
+	}
+	apply(pf.static, srv.AddPeer, srv.RemovePeer)
+	apply(pf.trusted, srv.AddTrustedPeer, srv.RemoveTrustedPeer)
+	return errors.Join(errs...)
+}
+
+// watchPeerFiles reloads changed static and trusted peers files until ctx is done
+func (ss *GrpcServer) watchPeerFiles(ctx context.Context) {
+	if ss.peerFiles.static == nil && ss.peerFiles.trusted == nil {
+		return
+	}
+	ticker := time.NewTicker(peerFilesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if ss.getP2PServer() == nil {
+				continue
+			}
+			if err := ss.reloadPeerFiles(false); err != nil {
+				ss.logger.Warn("[sentry] can't reload peers file", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package sentry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/p2p/enode"
+)
+
+const (
+	testNode1 = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+	testNode2 = "enode://3f1d12044546b76342d59d4a05532c14b85aa669704bfe1f864fe079415aa2c02d743e03218e57a33fb94523adb54032871a6c51b2cc5514cb7c7e35b3ed0a99@13.93.211.84:30303"
+	testNode3 = "enode://78de8a0916848093c73790ead81d1928bec737d565119932b98c6b100d944b7a95e94f847f689fc723399d2e31129d182f7ef3863f2b4c820abbf3ab2722344d@191.235.84.50:30303"
+)
+
+func TestPeersFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "static-nodes.json")
+	write := func(urls ...string) {
+		data, err := json.Marshal(urls)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		// make the change visible on file systems with coarse modification times
+		later := time.Now().Add(time.Duration(len(urls)) * time.Second)
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+	configured := enode.MustParse(testNode3)
+	f := newPeersFile(path, []*enode.Node{configured})
+
+	added, removed := map[enode.ID]bool{}, map[enode.ID]bool{}
+	reload := func() {
+		_, _, err := f.reload(func(n *enode.Node) { added[n.ID()] = true }, func(n *enode.Node) { removed[n.ID()] = true })
+		require.NoError(t, err)
+	}
+
+	// missing file is empty
+	require.True(t, f.changed())
+	reload()
+	require.False(t, f.changed())
+	require.Empty(t, added)
+
+	write(testNode1, testNode2, testNode3)
+	require.True(t, f.changed())
+	reload()
+	require.Len(t, added, 3)
+	require.False(t, f.changed())
+
+	// nodes of the config are not removed with the file
+	write(testNode2)
+	reload()
+	require.True(t, removed[enode.MustParse(testNode1).ID()])
+	require.False(t, removed[configured.ID()])
+	require.Len(t, removed, 1)
+
+	// invalid file keeps the nodes
+	require.NoError(t, os.WriteFile(path, []byte(`["enode://invalid"]`), 0644))
+	_, _, err := f.reload(func(*enode.Node) {}, func(*enode.Node) {})
+	require.Error(t, err)
+	require.Len(t, f.nodes, 1)
+
+	require.NoError(t, os.Remove(path))
+	require.True(t, f.changed())
+	reload()
+	require.True(t, removed[enode.MustParse(testNode2).ID()])
+	require.Empty(t, f.nodes)
+}
@@ -0,0 +1,294 @@
+package sentry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common/dir"
+)
+
+// ScoreEvent - kind of peer behaviour which changes its score
+type ScoreEvent int
+
+const (
+	ScoreUseful    ScoreEvent = iota // non-empty response to our request
+	ScoreUseless                     // empty or unsolicited response, or peer penalized by core
+	ScoreTimeout                     // request not answered before its deadline
+	ScoreViolation                   // protocol violation, the peer is disconnected
+)
+
+// scoreWeights - how much each event changes the score
+var scoreWeights = [...]int64{
+	ScoreUseful:    1,
+	ScoreUseless:   -5,
+	ScoreTimeout:   -2,
+	ScoreViolation: -50,
+}
+
+const (
+	maxScore = 1000
+	minScore = -1000
+	// banScore - peers at or below this score are disconnected and refused until their score decays above it
+	banScore = -200
+
+	// scoreDecayInterval - every this often scores move 1% towards zero and are persisted
+	scoreDecayInterval = time.Minute
+	// scoreRetention - scores of peers not seen for this long are forgotten, unless pinned or banned
+	scoreRetention = 7 * 24 * time.Hour
+
+	peerScoresFile = "peer_scores.json"
+)
+
+// PeerScore - reputation of a peer. Counters are lifetime totals, Score is their decaying weighted sum.
+type PeerScore struct {
+	Score      int64     `json:"score"`
+	Useful     uint64    `json:"useful"`
+	Useless    uint64    `json:"useless"`
+	Timeouts   uint64    `json:"timeouts"`
+	Violations uint64    `json:"violations"`
+	Pinned     bool      `json:"pinned,omitempty"` // always preferred, never disconnected for bad behaviour
+	Banned     bool      `json:"banned,omitempty"` // disconnected and refused regardless of score
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// PeerScores - scores of the peers of one sentry, persisted across restarts. Nil PeerScores is valid and
+// keeps no scores, all peers are then equal.
+type PeerScores struct {
+	lock   sync.RWMutex
+	scores map[[64]byte]*PeerScore
+	path   string // empty - not persisted
+	logger log.Logger
+}
+
+// NewPeerScores loads scores from the given directory, empty dir means scores are kept in memory only
+func NewPeerScores(dirPath string, logger log.Logger) *PeerScores {
+	ps := &PeerScores{scores: map[[64]byte]*PeerScore{}, logger: logger}
+	if dirPath == "" {
+		return ps
+	}
+	ps.path = filepath.Join(dirPath, peerScoresFile)
+	if err := ps.load(); err != nil {
+		logger.Warn("[sentry] can't load peer scores, starting from scratch", "file", ps.path, "err", err)
+	}
+	return ps
+}
+
+func (ps *PeerScores) load() error {
+	data, err := os.ReadFile(ps.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var stored map[string]*PeerScore
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	for k, score := range stored {
+		id, err := parsePeerID(k)
+		if err != nil {
+			return err
+		}
+		ps.scores[id] = score
+	}
+	return nil
+}
+
+// Save persists the scores, noop if they are kept in memory only
+func (ps *PeerScores) Save() error {
+	if ps == nil || ps.path == "" {
+		return nil
+	}
+	ps.lock.RLock()
+	stored := make(map[string]*PeerScore, len(ps.scores))
+	for id, score := range ps.scores {
+		stored[hex.EncodeToString(id[:])] = score
+	}
+	data, err := json.Marshal(stored)
+	ps.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ps.path), 0755); err != nil {
+		return err
+	}
+	tmp := ps.path + ".tmp"
+	if err := dir.WriteFileWithFsync(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ps.path)
+}
+
+// Decay moves all scores 1% (at least 1 point) towards zero and forgets peers not seen for scoreRetention
+func (ps *PeerScores) Decay(now time.Time) {
+	if ps == nil {
+		return
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	for id, score := range ps.scores {
+		if !score.Pinned && !score.Banned && now.Sub(score.LastSeen) > scoreRetention {
+			delete(ps.scores, id)
+			continue
+		}
+		step := score.Score / 100
+		switch {
+		case score.Score > 0:
+			score.Score -= max(step, 1)
+		case score.Score < 0:
+			score.Score -= min(step, -1)
+		}
+	}
+}
+
+func (ps *PeerScores) get(id [64]byte) *PeerScore {
+	score, ok := ps.scores[id]
+	if !ok {
+		score = &PeerScore{}
+		ps.scores[id] = score
+	}
+	return score
+}
+
+// Record applies the event to the peer score n times and reports whether the peer has to be dropped
+func (ps *PeerScores) Record(id [64]byte, event ScoreEvent, n int) (drop bool) {
+	if ps == nil || n <= 0 {
+		return false
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	score := ps.get(id)
+	switch event {
+	case ScoreUseful:
+		score.Useful += uint64(n)
+	case ScoreUseless:
+		score.Useless += uint64(n)
+	case ScoreTimeout:
+		score.Timeouts += uint64(n)
+	case ScoreViolation:
+		score.Violations += uint64(n)
+	}
+	score.Score = min(max(score.Score+scoreWeights[event]*int64(n), minScore), maxScore)
+	score.LastSeen = time.Now()
+	return !score.Pinned && (score.Banned || score.Score <= banScore)
+}
+
+// Seen marks the peer as connected now and reports whether it is refused
+func (ps *PeerScores) Seen(id [64]byte) (refused bool) {
+	if ps == nil {
+		return false
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	score := ps.get(id)
+	score.LastSeen = time.Now()
+	return !score.Pinned && (score.Banned || score.Score <= banScore)
+}
+
+// Score returns the score of the peer, pinned peers have the maximum one
+func (ps *PeerScores) Score(id [64]byte) int64 {
+	if ps == nil {
+		return 0
+	}
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	score, ok := ps.scores[id]
+	if !ok {
+		return 0
+	}
+	if score.Pinned {
+		return maxScore
+	}
+	return score.Score
+}
+
+// Pinned - whether the peer is exempt from disconnection for bad behaviour
+func (ps *PeerScores) Pinned(id [64]byte) bool {
+	if ps == nil {
+		return false
+	}
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	score, ok := ps.scores[id]
+	return ok && score.Pinned
+}
+
+// Pin pins or unpins the peer, pinning lifts the ban
+func (ps *PeerScores) Pin(id [64]byte, pinned bool) {
+	if ps == nil {
+		return
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	score := ps.get(id)
+	score.Pinned = pinned
+	if pinned {
+		score.Banned = false
+	}
+}
+
+// Ban bans or unbans the peer, banning unpins it. Unbanning also resets a score below banScore, otherwise the
+// peer would stay refused.
+func (ps *PeerScores) Ban(id [64]byte, banned bool) {
+	if ps == nil {
+		return
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	score := ps.get(id)
+	score.Banned = banned
+	if banned {
+		score.Pinned = false
+	} else if score.Score <= banScore {
+		score.Score = 0
+	}
+}
+
+// PeerScoreInfo - PeerScore of the peer with given ID, as returned by `sentry.Sentry/PeerScores`
+type PeerScoreInfo struct {
+	ID [64]byte
+	PeerScore
+}
+
+// List returns scores of all known peers, best first
+func (ps *PeerScores) List() []PeerScoreInfo {
+	if ps == nil {
+		return nil
+	}
+	ps.lock.RLock()
+	list := make([]PeerScoreInfo, 0, len(ps.scores))
+	for id, score := range ps.scores {
+		list = append(list, PeerScoreInfo{ID: id, PeerScore: *score})
+	}
+	ps.lock.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return bytes.Compare(list[i].ID[:], list[j].ID[:]) < 0
+	})
+	return list
+}
+
+func parsePeerID(s string) (id [64]byte, err error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(b) != len(id) {
+		return id, fmt.Errorf("invalid peer id length %d", len(b))
+	}
+	copy(id[:], b)
+	return id, nil
+}
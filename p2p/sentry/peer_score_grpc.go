@@ -0,0 +1,62 @@
+package sentry
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+
+	"github.com/ledgerwatch/erigon/p2p"
+)
+
+func (ss *GrpcServer) PeerScores(_ context.Context, _ *proto_sentry.PeerScoresRequest) (*proto_sentry.PeerScoresReply, error) {
+	list := ss.scores.List()
+	reply := &proto_sentry.PeerScoresReply{Scores: make([]*proto_sentry.PeerScore, len(list))}
+	for i, info := range list {
+		reply.Scores[i] = &proto_sentry.PeerScore{
+			PeerId:     gointerfaces.ConvertHashToH512(info.ID),
+			Score:      info.Score,
+			Useful:     info.Useful,
+			Useless:    info.Useless,
+			Timeouts:   info.Timeouts,
+			Violations: info.Violations,
+			Pinned:     info.Pinned,
+			Banned:     info.Banned,
+			LastSeen:   uint64(info.LastSeen.Unix()),
+		}
+	}
+	return reply, nil
+}
+
+func (ss *GrpcServer) PinPeer(_ context.Context, req *proto_sentry.PinPeerRequest) (*emptypb.Empty, error) {
+	peerID, err := peerIDFromProto(req.PeerId)
+	if err != nil {
+		return nil, err
+	}
+	ss.scores.Pin(peerID, req.Pinned)
+	return &emptypb.Empty{}, nil
+}
+
+func (ss *GrpcServer) BanPeer(_ context.Context, req *proto_sentry.BanPeerRequest) (*emptypb.Empty, error) {
+	peerID, err := peerIDFromProto(req.PeerId)
+	if err != nil {
+		return nil, err
+	}
+	ss.scores.Ban(peerID, req.Banned)
+	if req.Banned {
+		ss.removePeer(peerID, p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscRequested, nil, "banned peer"))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func peerIDFromProto(id *proto_types.H512) ([64]byte, error) {
+	if id == nil {
+		return [64]byte{}, status.Error(codes.InvalidArgument, "peer_id is required")
+	}
+	return gointerfaces.ConvertH512ToHash(id), nil
+}
@@ -0,0 +1,127 @@
+package sentry
+
+import (
+	"container/heap"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+
+	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+func TestPeerScores(t *testing.T) {
+	ps := NewPeerScores("", log.New())
+	good, bad, pinned := [64]byte{1}, [64]byte{2}, [64]byte{3}
+
+	require.False(t, ps.Record(good, ScoreUseful, 10))
+	require.Equal(t, int64(10), ps.Score(good))
+	require.False(t, ps.Record(bad, ScoreTimeout, 10))
+	require.Equal(t, int64(-20), ps.Score(bad))
+	require.True(t, ps.Record(bad, ScoreViolation, 4))
+	require.True(t, ps.Seen(bad))
+
+	ps.Pin(pinned, true)
+	require.False(t, ps.Record(pinned, ScoreViolation, 100))
+	require.Equal(t, int64(maxScore), ps.Score(pinned))
+	require.False(t, ps.Seen(pinned))
+
+	ps.Ban(good, true)
+	require.True(t, ps.Seen(good))
+	ps.Ban(good, false)
+	require.False(t, ps.Seen(good))
+	ps.Ban(bad, false)
+	require.False(t, ps.Seen(bad))
+	require.Equal(t, int64(0), ps.Score(bad))
+
+	list := ps.List()
+	require.Len(t, list, 3)
+	require.Equal(t, good, list[0].ID)
+	require.Equal(t, uint64(10), list[0].Useful)
+}
+
+func TestPeerScoresDecay(t *testing.T) {
+	ps := NewPeerScores("", log.New())
+	pos, neg, old := [64]byte{1}, [64]byte{2}, [64]byte{3}
+	ps.Record(pos, ScoreUseful, 500)
+	ps.Record(neg, ScoreTimeout, 1)
+	ps.Record(old, ScoreUseful, 1)
+
+	ps.Decay(time.Now())
+	require.Equal(t, int64(495), ps.Score(pos))
+	require.Equal(t, int64(-1), ps.Score(neg))
+
+	ps.Decay(time.Now().Add(scoreRetention + time.Hour))
+	require.Empty(t, ps.List())
+}
+
+func TestPeerScoresPersistence(t *testing.T) {
+	dir := t.TempDir()
+	ps := NewPeerScores(dir, log.New())
+	peer := [64]byte{1, 2, 3}
+	ps.Record(peer, ScoreUseful, 3)
+	ps.Ban([64]byte{4}, true)
+	require.NoError(t, ps.Save())
+
+	loaded := NewPeerScores(dir, log.New())
+	require.Equal(t, int64(3), loaded.Score(peer))
+	require.True(t, loaded.Seen([64]byte{4}))
+}
+
+func TestNilPeerScores(t *testing.T) {
+	var ps *PeerScores
+	require.False(t, ps.Record([64]byte{1}, ScoreViolation, 100))
+	require.False(t, ps.Seen([64]byte{1}))
+	require.Zero(t, ps.Score([64]byte{1}))
+	require.NoError(t, ps.Save())
+	require.Empty(t, ps.List())
+}
+
+func TestEmptyResponse(t *testing.T) {
+	empty, err := rlp.EncodeToBytes(&eth.BlockBodiesRLPPacket66{RequestId: 1})
+	require.NoError(t, err)
+	require.True(t, emptyResponse(empty))
+	full, err := rlp.EncodeToBytes(&eth.BlockBodiesRLPPacket66{RequestId: 1, BlockBodiesRLPPacket: eth.BlockBodiesRLPPacket{rlp.RawValue{0xc0}}})
+	require.NoError(t, err)
+	require.False(t, emptyResponse(full))
+	require.True(t, emptyResponse([]byte{0x01}))
+}
+
+func TestPeersByMinBlockScore(t *testing.T) {
+	a, b, c := &PeerInfo{}, &PeerInfo{}, &PeerInfo{}
+	bp := PeersByMinBlock{}
+	heap.Push(&bp, PeerRef{pi: a, height: 10, score: 5})
+	heap.Push(&bp, PeerRef{pi: b, height: 10, score: -5})
+	heap.Push(&bp, PeerRef{pi: c, height: 9, score: 100})
+	require.Equal(t, c, heap.Pop(&bp).(PeerRef).pi)
+	require.Equal(t, b, heap.Pop(&bp).(PeerRef).pi)
+}
+
+func TestPeerScoresService(t *testing.T) {
+	ctx := context.Background()
+	ss := &GrpcServer{scores: NewPeerScores("", log.New())}
+	peer := [64]byte{7}
+	id := gointerfaces.ConvertHashToH512(peer)
+
+	_, err := ss.PinPeer(ctx, &proto_sentry.PinPeerRequest{PeerId: id, Pinned: true})
+	require.NoError(t, err)
+
+	reply, err := ss.PeerScores(ctx, &proto_sentry.PeerScoresRequest{})
+	require.NoError(t, err)
+	require.Len(t, reply.Scores, 1)
+	require.Equal(t, peer, gointerfaces.ConvertH512ToHash(reply.Scores[0].PeerId))
+	require.True(t, reply.Scores[0].Pinned)
+
+	_, err = ss.BanPeer(ctx, &proto_sentry.BanPeerRequest{PeerId: id, Banned: true})
+	require.NoError(t, err)
+	require.True(t, ss.scores.Seen(peer))
+
+	_, err = ss.BanPeer(ctx, &proto_sentry.BanPeerRequest{Banned: true})
+	require.Error(t, err)
+}
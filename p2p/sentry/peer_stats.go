@@ -0,0 +1,247 @@
+package sentry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/metrics"
+
+	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+)
+
+const (
+	// statsRequestTimeout - requests not answered for this long are counted as failed
+	statsRequestTimeout = 30 * time.Second
+	// peerStatsInterval - how often per-peer Prometheus gauges are refreshed
+	peerStatsInterval = 10 * time.Second
+)
+
+var (
+	responseLatency = metrics.GetOrCreateSummary(`sentry_response_latency`)
+	requestsSent    = metrics.GetOrCreateCounter(`sentry_requests{result="sent"}`)
+	requestsAnswer  = metrics.GetOrCreateCounter(`sentry_requests{result="answered"}`)
+	requestsTimeout = metrics.GetOrCreateCounter(`sentry_requests{result="timeout"}`)
+)
+
+// messageBytes - traffic of all peers by direction and message type
+type messageBytes struct {
+	lock     sync.Mutex
+	counters map[string]metrics.Counter
+}
+
+var bytesIn, bytesOut = &messageBytes{counters: map[string]metrics.Counter{}}, &messageBytes{counters: map[string]metrics.Counter{}}
+
+func (mb *messageBytes) add(direction, msgType string, n int) {
+	mb.lock.Lock()
+	c, ok := mb.counters[msgType]
+	if !ok {
+		c = metrics.GetOrCreateCounter(fmt.Sprintf(`sentry_bytes_%s{msg="%s"}`, direction, msgType))
+		mb.counters[msgType] = c
+	}
+	mb.lock.Unlock()
+	c.Add(float64(n))
+}
+
+// isRequest - message which the peer is expected to answer
+func isRequest(msgcode uint64) bool {
+	switch msgcode {
+	case eth.GetBlockHeadersMsg, eth.GetBlockBodiesMsg, eth.GetReceiptsMsg, eth.GetPooledTransactionsMsg, eth.GetNodeDataMsg:
+		return true
+	}
+	return false
+}
+
+// isResponse - message answering a request
+func isResponse(msgcode uint64) bool {
+	switch msgcode {
+	case eth.BlockHeadersMsg, eth.BlockBodiesMsg, eth.ReceiptsMsg, eth.PooledTransactionsMsg, eth.NodeDataMsg:
+		return true
+	}
+	return false
+}
+
+func messageType(protocol uint, msgcode uint64) string {
+	if id, ok := eth.ToProto[protocol][msgcode]; ok {
+		return id.String()
+	}
+	return fmt.Sprintf("0x%02x", msgcode)
+}
+
+// PeerStats - traffic of a peer and how well it answers our requests. Responses are matched to requests in
+// order, as peers answer them in order in practice.
+type PeerStats struct {
+	lock       sync.Mutex
+	connected  time.Time
+	bytesIn    map[string]uint64 // by message type
+	bytesOut   map[string]uint64
+	pending    []time.Time // send times of requests awaiting response
+	requests   uint64
+	responses  uint64
+	timeouts   uint64
+	latencySum time.Duration
+
+	gauges *peerGauges // nil - not exported to Prometheus
+}
+
+func NewPeerStats(now time.Time) *PeerStats {
+	return &PeerStats{
+		connected: now,
+		bytesIn:   map[string]uint64{},
+		bytesOut:  map[string]uint64{},
+	}
+}
+
+// expire drops the pending requests which timed out, must be called with the lock held
+func (s *PeerStats) expire(now time.Time) {
+	i := sort.Search(len(s.pending), func(i int) bool { return now.Sub(s.pending[i]) < statsRequestTimeout })
+	if i > 0 {
+		s.timeouts += uint64(i)
+		requestsTimeout.Add(float64(i))
+		s.pending = s.pending[i:]
+	}
+}
+
+// In accounts a message received from the peer
+func (s *PeerStats) In(msgType string, msgcode uint64, size int, now time.Time) {
+	if s == nil {
+		return
+	}
+	bytesIn.add("in", msgType, size)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.bytesIn[msgType] += uint64(size)
+	if !isResponse(msgcode) {
+		return
+	}
+	s.expire(now)
+	if len(s.pending) == 0 {
+		return // answer to a timed out request, or unsolicited
+	}
+	latency := now.Sub(s.pending[0])
+	s.pending = s.pending[1:]
+	s.responses++
+	s.latencySum += latency
+	requestsAnswer.Inc()
+	responseLatency.Observe(latency.Seconds())
+}
+
+// Out accounts a message sent to the peer
+func (s *PeerStats) Out(msgType string, msgcode uint64, size int, now time.Time) {
+	if s == nil {
+		return
+	}
+	bytesOut.add("out", msgType, size)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.bytesOut[msgType] += uint64(size)
+	if !isRequest(msgcode) {
+		return
+	}
+	s.expire(now)
+	s.pending = append(s.pending, now)
+	s.requests++
+	requestsSent.Inc()
+}
+
+// PeerStatsInfo - PeerStats of the peer with given ID, as returned by `sentry.Sentry/PeerStats`
+type PeerStatsInfo struct {
+	ID            [64]byte
+	Name          string
+	ConnectedSecs uint64
+	BytesIn       map[string]uint64
+	BytesOut      map[string]uint64
+	TotalIn       uint64
+	TotalOut      uint64
+	Requests      uint64
+	Responses     uint64
+	Timeouts      uint64
+	Pending       int
+	SuccessRate   float64 // answered of the requests which are not pending anymore, 1 if none
+	AvgLatencyMs  float64 // of answered requests
+}
+
+func (s *PeerStats) Snapshot(now time.Time) PeerStatsInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.expire(now)
+	info := PeerStatsInfo{
+		ConnectedSecs: uint64(now.Sub(s.connected) / time.Second),
+		BytesIn:       make(map[string]uint64, len(s.bytesIn)),
+		BytesOut:      make(map[string]uint64, len(s.bytesOut)),
+		Requests:      s.requests,
+		Responses:     s.responses,
+		Timeouts:      s.timeouts,
+		Pending:       len(s.pending),
+		SuccessRate:   1,
+	}
+	for k, v := range s.bytesIn {
+		info.BytesIn[k] = v
+		info.TotalIn += v
+	}
+	for k, v := range s.bytesOut {
+		info.BytesOut[k] = v
+		info.TotalOut += v
+	}
+	if done := s.responses + s.timeouts; done > 0 {
+		info.SuccessRate = float64(s.responses) / float64(done)
+	}
+	if s.responses > 0 {
+		info.AvgLatencyMs = float64(s.latencySum.Milliseconds()) / float64(s.responses)
+	}
+	return info
+}
+
+// peerGauges - per-peer Prometheus gauges, registered while the peer is connected
+type peerGauges struct {
+	names                                      []string
+	bytesIn, bytesOut, successRate, avgLatency metrics.Gauge
+}
+
+func newPeerGauges(peer string) *peerGauges {
+	g := &peerGauges{names: []string{
+		fmt.Sprintf(`sentry_peer_bytes_in{peer="%s"}`, peer),
+		fmt.Sprintf(`sentry_peer_bytes_out{peer="%s"}`, peer),
+		fmt.Sprintf(`sentry_peer_success_rate{peer="%s"}`, peer),
+		fmt.Sprintf(`sentry_peer_latency_ms{peer="%s"}`, peer),
+	}}
+	g.bytesIn = metrics.GetOrCreateGauge(g.names[0])
+	g.bytesOut = metrics.GetOrCreateGauge(g.names[1])
+	g.successRate = metrics.GetOrCreateGauge(g.names[2])
+	g.avgLatency = metrics.GetOrCreateGauge(g.names[3])
+	return g
+}
+
+// ExportMetrics registers per-peer Prometheus gauges labelled with the given peer name, until UnexportMetrics
+func (s *PeerStats) ExportMetrics(peer string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.gauges = newPeerGauges(peer)
+}
+
+func (s *PeerStats) UnexportMetrics() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.gauges == nil {
+		return
+	}
+	for _, name := range s.gauges.names {
+		metrics.UnregisterMetric(name)
+	}
+	s.gauges = nil
+}
+
+// updateMetrics refreshes per-peer Prometheus gauges, if exported
+func (s *PeerStats) updateMetrics(now time.Time) {
+	info := s.Snapshot(now)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.gauges == nil {
+		return
+	}
+	s.gauges.bytesIn.SetUint64(info.TotalIn)
+	s.gauges.bytesOut.SetUint64(info.TotalOut)
+	s.gauges.successRate.Set(info.SuccessRate)
+	s.gauges.avgLatency.Set(info.AvgLatencyMs)
+}
@@ -0,0 +1,44 @@
+package sentry
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
+)
+
+func (ss *GrpcServer) PeerStats(_ context.Context, _ *proto_sentry.PeerStatsRequest) (*proto_sentry.PeerStatsReply, error) {
+	now := time.Now()
+	var list []PeerStatsInfo
+	ss.rangePeers(func(peerInfo *PeerInfo) bool {
+		if peerInfo.stats == nil {
+			return true
+		}
+		info := peerInfo.stats.Snapshot(now)
+		info.ID, info.Name = peerInfo.ID(), peerInfo.peer.Fullname()
+		list = append(list, info)
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].TotalOut > list[j].TotalOut })
+	reply := &proto_sentry.PeerStatsReply{Peers: make([]*proto_sentry.PeerStats, len(list))}
+	for i, info := range list {
+		reply.Peers[i] = &proto_sentry.PeerStats{
+			PeerId:        gointerfaces.ConvertHashToH512(info.ID),
+			Name:          info.Name,
+			ConnectedSecs: info.ConnectedSecs,
+			BytesIn:       info.BytesIn,
+			BytesOut:      info.BytesOut,
+			TotalIn:       info.TotalIn,
+			TotalOut:      info.TotalOut,
+			Requests:      info.Requests,
+			Responses:     info.Responses,
+			Timeouts:      info.Timeouts,
+			Pending:       uint32(info.Pending),
+			SuccessRate:   info.SuccessRate,
+			AvgLatencyMs:  info.AvgLatencyMs,
+		}
+	}
+	return reply, nil
+}
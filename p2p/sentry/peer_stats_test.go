@@ -0,0 +1,62 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/direct"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+
+	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+)
+
+func TestPeerStats(t *testing.T) {
+	start := time.Now()
+	s := NewPeerStats(start)
+	headers, bodies := messageType(direct.ETH68, eth.BlockHeadersMsg), messageType(direct.ETH68, eth.BlockBodiesMsg)
+	getHeaders := messageType(direct.ETH68, eth.GetBlockHeadersMsg)
+
+	s.Out(getHeaders, eth.GetBlockHeadersMsg, 10, start)
+	s.Out(getHeaders, eth.GetBlockHeadersMsg, 10, start.Add(time.Second))
+	s.In(headers, eth.BlockHeadersMsg, 1000, start.Add(100*time.Millisecond))
+	s.In(bodies, eth.BlockBodiesMsg, 500, start.Add(1300*time.Millisecond))
+	// unsolicited response
+	s.In(headers, eth.BlockHeadersMsg, 1000, start.Add(2*time.Second))
+	s.Out(messageType(direct.ETH68, eth.NewBlockHashesMsg), eth.NewBlockHashesMsg, 40, start.Add(2*time.Second))
+
+	info := s.Snapshot(start.Add(3 * time.Second))
+	require.Equal(t, uint64(3), info.ConnectedSecs)
+	require.Equal(t, uint64(2000), info.BytesIn[headers])
+	require.Equal(t, uint64(2500), info.TotalIn)
+	require.Equal(t, uint64(60), info.TotalOut)
+	require.Equal(t, uint64(2), info.Requests)
+	require.Equal(t, uint64(2), info.Responses)
+	require.Equal(t, 1.0, info.SuccessRate)
+	require.Equal(t, 200.0, info.AvgLatencyMs)
+
+	// unanswered requests time out
+	s.Out(getHeaders, eth.GetBlockHeadersMsg, 10, start.Add(4*time.Second))
+	s.Out(getHeaders, eth.GetBlockHeadersMsg, 10, start.Add(5*time.Second))
+	info = s.Snapshot(start.Add(4*time.Second + statsRequestTimeout))
+	require.Equal(t, uint64(1), info.Timeouts)
+	require.Equal(t, 1, info.Pending)
+	require.InDelta(t, 2.0/3, info.SuccessRate, 1e-9)
+
+	var nilStats *PeerStats
+	nilStats.In(headers, eth.BlockHeadersMsg, 1, start)
+	nilStats.Out(getHeaders, eth.GetBlockHeadersMsg, 1, start)
+}
+
+func TestPeerStatsMetrics(t *testing.T) {
+	s := NewPeerStats(time.Now())
+	s.ExportMetrics("0011223344")
+	s.Out("Test", eth.GetBlockHeadersMsg, 10, time.Now())
+	s.updateMetrics(time.Now())
+	require.Equal(t, 10.0, metrics.GetOrCreateGauge(`sentry_peer_bytes_out{peer="0011223344"}`).GetValue())
+
+	s.UnexportMetrics()
+	require.Equal(t, 0.0, metrics.GetOrCreateGauge(`sentry_peer_bytes_out{peer="0011223344"}`).GetValue())
+	s.UnexportMetrics()
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -33,6 +34,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
 	proto_types "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+	"github.com/ledgerwatch/erigon-lib/metrics"
 
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common/debug"
@@ -50,8 +52,62 @@ const (
 	// complete before dropping the connection.= as malicious.
 	handshakeTimeout  = 5 * time.Second
 	maxPermitsPerPeer = 4 // How many outstanding requests per peer we may have
+
+	// blockRangeUpdateInterval - eth/69 peers are notified about new head every this many blocks
+	blockRangeUpdateInterval = 32
 )
 
+// protocolMetrics - connected peers and messages of each version of eth protocol
+type protocolMetrics struct {
+	peers             metrics.Gauge
+	msgsIn            metrics.Counter
+	msgsOut           metrics.Counter
+	translated        metrics.Counter
+	handshakeFailures metrics.Counter
+}
+
+var protocolsMetrics = func() map[uint]*protocolMetrics {
+	m := make(map[uint]*protocolMetrics, len(eth.ProtocolToString))
+	for protocol, label := range eth.ProtocolToString {
+		m[protocol] = &protocolMetrics{
+			peers:             metrics.GetOrCreateGauge(fmt.Sprintf(`sentry_peers{protocol="%s"}`, label)),
+			msgsIn:            metrics.GetOrCreateCounter(fmt.Sprintf(`sentry_msgs_in{protocol="%s"}`, label)),
+			msgsOut:           metrics.GetOrCreateCounter(fmt.Sprintf(`sentry_msgs_out{protocol="%s"}`, label)),
+			translated:        metrics.GetOrCreateCounter(fmt.Sprintf(`sentry_msgs_translated{protocol="%s"}`, label)),
+			handshakeFailures: metrics.GetOrCreateCounter(fmt.Sprintf(`sentry_handshake_failures{protocol="%s"}`, label)),
+		}
+	}
+	return m
+}()
+
+// protocolToProto - version of eth protocol as it's known to gRPC clients of sentry
+func protocolToProto(protocol uint) proto_sentry.Protocol {
+	switch protocol {
+	case direct.ETH65:
+		return proto_sentry.Protocol_ETH65
+	case direct.ETH66:
+		return proto_sentry.Protocol_ETH66
+	case direct.ETH67:
+		return proto_sentry.Protocol_ETH67
+	case direct.ETH68:
+		return proto_sentry.Protocol_ETH68
+	case direct.ETH69:
+		return proto_sentry.Protocol_ETH69
+	}
+	return proto_sentry.Protocol(protocol)
+}
+
+// obsoleteMessage - whether message was removed from given version of eth protocol
+func obsoleteMessage(protocol uint, msgcode uint64) bool {
+	switch msgcode {
+	case eth.GetNodeDataMsg:
+		return protocol >= direct.ETH67
+	case eth.NewBlockHashesMsg, eth.NewBlockMsg:
+		return protocol >= direct.ETH69
+	}
+	return false
+}
+
 // PeerInfo collects various extra bits of information about the peer,
 // for example deadlines that is used for regulating requests sent to the peer
 type PeerInfo struct {
@@ -62,6 +118,8 @@ type PeerInfo struct {
 	height        uint64
 	rw            p2p.MsgReadWriter
 	protocol      uint
+	scores        *PeerScores // nil - peer is not scored
+	stats         *PeerStats  // nil - traffic of the peer is not tracked
 
 	ctx       context.Context
 	ctxCancel context.CancelFunc
@@ -80,6 +138,7 @@ type PeerInfo struct {
 type PeerRef struct {
 	pi     *PeerInfo
 	height uint64
+	score  int64
 }
 
 // PeersByMinBlock is the priority queue of peers. Used to select certain number of peers considered to be "best available"
@@ -90,9 +149,12 @@ func (bp PeersByMinBlock) Len() int {
 	return len(bp)
 }
 
-// Less (part of heap.Interface) compares two peers
+// Less (part of heap.Interface) compares two peers, of equal height the one with lower score is worse
 func (bp PeersByMinBlock) Less(i, j int) bool {
-	return bp[i].height < bp[j].height
+	if bp[i].height != bp[j].height {
+		return bp[i].height < bp[j].height
+	}
+	return bp[i].score < bp[j].score
 }
 
 // Swap (part of heap.Interface) moves two peers in the queue into each other's places.
@@ -183,6 +245,7 @@ func (pi *PeerInfo) SetIncreasedHeight(newHeight uint64) {
 // ClearDeadlines goes through the deadlines of
 // given peers and removes the ones that have passed
 // Optionally, it also clears one extra deadline - this is used when response is received
+// Passed deadlines are scored as timeouts
 // It returns the number of deadlines left
 func (pi *PeerInfo) ClearDeadlines(now time.Time, givePermit bool) int {
 	pi.lock.Lock()
@@ -191,6 +254,9 @@ func (pi *PeerInfo) ClearDeadlines(now time.Time, givePermit bool) int {
 	firstNotPassed := sort.Search(len(pi.deadlines), func(i int) bool {
 		return pi.deadlines[i].After(now)
 	})
+	if firstNotPassed > 0 && pi.scores.Record(pi.ID(), ScoreTimeout, firstNotPassed) {
+		pi.Remove(p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscUselessPeer, nil, "peer score too low: timeouts"))
+	}
 	cutOff := firstNotPassed
 	if cutOff < len(pi.deadlines) && givePermit {
 		cutOff++
@@ -296,15 +362,28 @@ func handShake(
 
 	go func() {
 		defer debug.LogPanic()
-		status := &eth.StatusPacket{
-			ProtocolVersion: uint32(version),
-			NetworkID:       status.NetworkId,
-			TD:              ourTD.ToBig(),
-			Head:            gointerfaces.ConvertH256ToHash(status.BestHash),
-			Genesis:         genesisHash,
-			ForkID:          forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime),
+		var packet interface{}
+		if version >= direct.ETH69 {
+			packet = &eth.StatusPacket69{
+				ProtocolVersion: uint32(version),
+				NetworkID:       status.NetworkId,
+				Genesis:         genesisHash,
+				ForkID:          forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime),
+				EarliestBlock:   status.EarliestBlock,
+				LatestBlock:     status.MaxBlockHeight,
+				LatestBlockHash: gointerfaces.ConvertH256ToHash(status.BestHash),
+			}
+		} else {
+			packet = &eth.StatusPacket{
+				ProtocolVersion: uint32(version),
+				NetworkID:       status.NetworkId,
+				TD:              ourTD.ToBig(),
+				Head:            gointerfaces.ConvertH256ToHash(status.BestHash),
+				Genesis:         genesisHash,
+				ForkID:          forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime),
+			}
 		}
-		err := p2p.Send(rw, eth.StatusMsg, status)
+		err := p2p.Send(rw, eth.StatusMsg, packet)
 
 		if err == nil {
 			errChan <- nil
@@ -352,6 +431,7 @@ func runPeer(
 	peerInfo *PeerInfo,
 	send func(msgId proto_sentry.MessageId, peerID [64]byte, b []byte),
 	hasSubscribers func(msgId proto_sentry.MessageId) bool,
+	protocolMetrics *protocolMetrics,
 	logger log.Logger,
 ) *p2p.PeerError {
 	protocol := cap.Version
@@ -392,6 +472,13 @@ func runPeer(
 			return p2p.NewPeerError(p2p.PeerErrorMessageSizeLimit, p2p.DiscSubprotocolError, nil, fmt.Sprintf("sentry.runPeer: message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize))
 		}
 
+		protocolMetrics.msgsIn.Inc()
+		if obsoleteMessage(protocol, msg.Code) {
+			msg.Discard()
+			return p2p.NewPeerError(p2p.PeerErrorMessageObsolete, p2p.DiscSubprotocolError, nil, fmt.Sprintf("unexpected message %d from %s in eth/%d", msg.Code, peerID, protocol))
+		}
+		peerInfo.stats.In(messageType(protocol, msg.Code), msg.Code, int(msg.Size), time.Now())
+
 		givePermit := false
 		switch msg.Code {
 		case eth.StatusMsg:
@@ -417,6 +504,10 @@ func runPeer(
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
+			if err := scoreResponse(peerInfo, b); err != nil {
+				msg.Discard()
+				return err
+			}
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
 		case eth.GetBlockBodiesMsg:
 			if !hasSubscribers(eth.ToProto[protocol][msg.Code]) {
@@ -436,12 +527,12 @@ func runPeer(
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
-			send(eth.ToProto[protocol][msg.Code], peerID, b)
-		case eth.GetNodeDataMsg:
-			if protocol >= direct.ETH67 {
+			if err := scoreResponse(peerInfo, b); err != nil {
 				msg.Discard()
-				return p2p.NewPeerError(p2p.PeerErrorMessageObsolete, p2p.DiscSubprotocolError, nil, fmt.Sprintf("unexpected GetNodeDataMsg from %s in eth/%d", peerID, protocol))
+				return err
 			}
+			send(eth.ToProto[protocol][msg.Code], peerID, b)
+		case eth.GetNodeDataMsg:
 			if !hasSubscribers(eth.ToProto[protocol][msg.Code]) {
 				continue
 			}
@@ -469,6 +560,13 @@ func runPeer(
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
+			if protocol >= direct.ETH69 {
+				if b, err = eth.ReceiptsPacket69To66(b); err != nil {
+					msg.Discard()
+					return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscProtocolError, err, "sentry.runPeer: invalid eth/69 receipts")
+				}
+				protocolMetrics.translated.Inc()
+			}
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
 			//log.Info(fmt.Sprintf("[%s] ReceiptsMsg", peerID))
 		case eth.NewBlockHashesMsg:
@@ -531,6 +629,15 @@ func runPeer(
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
+		case eth.BlockRangeUpdateMsg:
+			var update eth.BlockRangeUpdatePacket
+			if err := msg.Decode(&update); err != nil {
+				return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscProtocolError, err, "sentry.runPeer: invalid BlockRangeUpdate")
+			}
+			if update.EarliestBlock > update.LatestBlock {
+				return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscProtocolError, nil, fmt.Sprintf("sentry.runPeer: invalid block range %d-%d", update.EarliestBlock, update.LatestBlock))
+			}
+			peerInfo.SetIncreasedHeight(update.LatestBlock)
 		case 11:
 			// Ignore
 			// TODO: Investigate why BSC peers for eth/67 send these messages
@@ -548,6 +655,33 @@ func runPeer(
 	}
 }
 
+// scoreResponse scores the response to a header or body request, an empty one is useless.
+// Returns error if the peer score fell too low.
+func scoreResponse(peerInfo *PeerInfo, b []byte) *p2p.PeerError {
+	event := ScoreUseful
+	if emptyResponse(b) {
+		event = ScoreUseless
+	}
+	if peerInfo.scores.Record(peerInfo.ID(), event, 1) {
+		return p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscUselessPeer, nil, "sentry.runPeer: peer score too low")
+	}
+	return nil
+}
+
+// emptyResponse - whether eth/66+ response [requestId, [items...]] has no items
+func emptyResponse(b []byte) bool {
+	content, _, err := rlp.SplitList(b)
+	if err != nil {
+		return true
+	}
+	_, _, rest, err := rlp.Split(content) // requestId
+	if err != nil {
+		return true
+	}
+	items, _, err := rlp.SplitList(rest)
+	return err != nil || len(items) == 0
+}
+
 func trackPeerStatistics(peerID string, inbound bool, msgType string, msgCap string, bytes int) {
 	isDiagEnabled := diagnostics.TypeOf(diagnostics.PeerStatisticMsgUpdate{}).Enabled()
 	if isDiagEnabled {
@@ -564,7 +698,7 @@ func trackPeerStatistics(peerID string, inbound bool, msgType string, msgCap str
 	}
 }
 
-func grpcSentryServer(ctx context.Context, sentryAddr string, ss *GrpcServer, healthCheck bool) (*grpc.Server, error) {
+func grpcSentryServer(ctx context.Context, sentryAddr string, ss *GrpcServer, creds credentials.TransportCredentials, healthCheck bool) (*grpc.Server, error) {
 	// STARTING GRPC SERVER
 	ss.logger.Info("Starting Sentry gRPC server", "on", sentryAddr)
 	listenConfig := net.ListenConfig{
@@ -577,7 +711,7 @@ func grpcSentryServer(ctx context.Context, sentryAddr string, ss *GrpcServer, he
 	if err != nil {
 		return nil, fmt.Errorf("could not create Sentry P2P listener: %w, addr=%s", err, sentryAddr)
 	}
-	grpcServer := grpcutil.NewServer(100, nil)
+	grpcServer := grpcutil.NewServer(100, creds)
 	proto_sentry.RegisterSentryServer(grpcServer, ss)
 	var healthServer *health.Server
 	if healthCheck {
@@ -601,23 +735,32 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 		ctx:          ctx,
 		p2p:          cfg,
 		peersStreams: NewPeersStreams(),
+		scores:       NewPeerScores(cfg.NodeDatabase, logger),
+		peerFiles:    newPeerFiles(cfg),
 		logger:       logger,
 	}
+	go ss.maintainScores(ctx)
+	go ss.maintainStats(ctx)
+	go ss.watchPeerFiles(ctx)
 
 	var disc enode.Iterator
 	if dialCandidates != nil {
 		disc = dialCandidates()
 	}
 	protocols := []uint{protocol}
-	if protocol == direct.ETH67 {
+	switch protocol {
+	case direct.ETH67:
 		protocols = append(protocols, direct.ETH66)
+	case direct.ETH69:
+		protocols = append(protocols, direct.ETH68)
 	}
 	for _, p := range protocols {
 		protocol := p
+		protocolMetrics := protocolsMetrics[protocol]
 		ss.Protocols = append(ss.Protocols, p2p.Protocol{
 			Name:           eth.ProtocolName,
 			Version:        protocol,
-			Length:         17,
+			Length:         eth.ProtocolLengths[protocol],
 			DialCandidates: disc,
 			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) *p2p.PeerError {
 				peerID := peer.Pubkey()
@@ -625,10 +768,17 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 				if ss.getPeer(peerID) != nil {
 					return p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscAlreadyConnected, nil, "peer already has connection")
 				}
+				if ss.scores.Seen(peerID) && !peer.Info().Network.Static && !peer.Info().Network.Trusted {
+					return p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscUselessPeer, nil, "peer is banned or its score is too low")
+				}
 				logger.Trace("[p2p] start with peer", "peerId", printablePeerID)
 
 				peerInfo := NewPeerInfo(peer, rw)
 				peerInfo.protocol = protocol
+				peerInfo.scores = ss.scores
+				peerInfo.stats = NewPeerStats(time.Now())
+				peerInfo.stats.ExportMetrics(printablePeerID)
+				defer peerInfo.stats.UnexportMetrics()
 				defer peerInfo.Close()
 
 				defer ss.GoodPeers.Delete(peerID)
@@ -641,8 +791,11 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 
 				peerBestHash, err := handShake(ctx, status, rw, protocol, protocol)
 				if err != nil {
+					protocolMetrics.handshakeFailures.Inc()
 					return err
 				}
+				protocolMetrics.peers.Inc()
+				defer protocolMetrics.peers.Dec()
 
 				// handshake is successful
 				logger.Trace("[p2p] Received status message OK", "peerId", printablePeerID, "name", peer.Name())
@@ -657,7 +810,7 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 
 				cap := p2p.Cap{Name: eth.ProtocolName, Version: protocol}
 
-				return runPeer(
+				err = runPeer(
 					ctx,
 					peerID,
 					cap,
@@ -665,8 +818,13 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 					peerInfo,
 					ss.send,
 					ss.hasSubscribers,
+					protocolMetrics,
 					logger,
 				)
+				if err != nil && (err.Reason == p2p.DiscProtocolError || err.Reason == p2p.DiscSubprotocolError) {
+					ss.scores.Record(peerID, ScoreViolation, 1)
+				}
+				return err
 			},
 			NodeInfo: func() interface{} {
 				return readNodeInfo()
@@ -682,8 +840,8 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 	return ss
 }
 
-// Sentry creates and runs standalone sentry
-func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discoveryDNS []string, cfg *p2p.Config, protocolVersion uint, healthCheck bool, logger log.Logger) error {
+// Sentry creates and runs standalone sentry. creds=nil means plaintext gRPC
+func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discoveryDNS []string, cfg *p2p.Config, protocolVersion uint, creds credentials.TransportCredentials, healthCheck bool, logger log.Logger) error {
 	dir.MustExist(dirs.DataDir)
 
 	discovery := func() enode.Iterator {
@@ -696,7 +854,7 @@ func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discovery
 	sentryServer := NewGrpcServer(ctx, discovery, func() *eth.NodeInfo { return nil }, cfg, protocolVersion, logger)
 	sentryServer.discoveryDNS = discoveryDNS
 
-	grpcServer, err := grpcSentryServer(ctx, sentryAddr, sentryServer, healthCheck)
+	grpcServer, err := grpcSentryServer(ctx, sentryAddr, sentryServer, creds, healthCheck)
 	if err != nil {
 		return err
 	}
@@ -723,9 +881,48 @@ type GrpcServer struct {
 	messageStreamsLock   sync.RWMutex
 	peersStreams         *PeersStreams
 	p2p                  *p2p.Config
+	lastBlockRangeUpdate uint64 // head announced to eth/69 peers, guarded by statusDataLock
+	scores               *PeerScores
+	peerFiles            *peerFiles
 	logger               log.Logger
 }
 
+// maintainScores decays and persists peer scores until ctx is done
+func (ss *GrpcServer) maintainScores(ctx context.Context) {
+	ticker := time.NewTicker(scoreDecayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ss.scores.Decay(now)
+			if err := ss.scores.Save(); err != nil {
+				ss.logger.Warn("[sentry] can't save peer scores", "err", err)
+			}
+		}
+	}
+}
+
+// maintainStats refreshes per-peer Prometheus gauges until ctx is done
+func (ss *GrpcServer) maintainStats(ctx context.Context) {
+	ticker := time.NewTicker(peerStatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ss.rangePeers(func(peerInfo *PeerInfo) bool {
+				if peerInfo.stats != nil {
+					peerInfo.stats.updateMetrics(now)
+				}
+				return true
+			})
+		}
+	}
+}
+
 func (ss *GrpcServer) rangePeers(f func(peerInfo *PeerInfo) bool) {
 	ss.GoodPeers.Range(func(key, value interface{}) bool {
 		peerInfo, _ := value.(*PeerInfo)
@@ -757,7 +954,20 @@ func (ss *GrpcServer) removePeer(peerID [64]byte, reason *p2p.PeerError) {
 }
 
 func (ss *GrpcServer) writePeer(logPrefix string, peerInfo *PeerInfo, msgcode uint64, data []byte, ttl time.Duration) {
+	if obsoleteMessage(peerInfo.protocol, msgcode) {
+		return
+	}
 	peerInfo.Async(func() {
+		data := data
+		if msgcode == eth.ReceiptsMsg && peerInfo.protocol >= direct.ETH69 {
+			var err error
+			if data, err = eth.ReceiptsPacket66To69(data); err != nil {
+				ss.logger.Debug(logPrefix+" can't translate receipts to eth/69", "err", err)
+				return
+			}
+			protocolsMetrics[peerInfo.protocol].translated.Inc()
+		}
+		protocolsMetrics[peerInfo.protocol].msgsOut.Inc()
 
 		cap := p2p.Cap{Name: eth.ProtocolName, Version: peerInfo.protocol}
 		msgType := eth.ToProto[cap.Version][msgcode]
@@ -768,8 +978,10 @@ func (ss *GrpcServer) writePeer(logPrefix string, peerInfo *PeerInfo, msgcode ui
 			peerInfo.Remove(p2p.NewPeerError(p2p.PeerErrorMessageSend, p2p.DiscNetworkError, err, fmt.Sprintf("%s writePeer msgcode=%d", logPrefix, msgcode)))
 			ss.GoodPeers.Delete(peerInfo.ID())
 		} else {
+			now := time.Now()
+			peerInfo.stats.Out(messageType(peerInfo.protocol, msgcode), msgcode, len(data), now)
 			if ttl > 0 {
-				peerInfo.AddDeadline(time.Now().Add(ttl))
+				peerInfo.AddDeadline(now.Add(ttl))
 			}
 		}
 	}, ss.logger)
@@ -803,8 +1015,9 @@ func (ss *GrpcServer) getBlockHeaders(ctx context.Context, bestHash libcommon.Ha
 func (ss *GrpcServer) PenalizePeer(_ context.Context, req *proto_sentry.PenalizePeerRequest) (*emptypb.Empty, error) {
 	//log.Warn("Received penalty", "kind", req.GetPenalty().Descriptor().FullName, "from", fmt.Sprintf("%s", req.GetPeerId()))
 	peerID := ConvertH512ToPeerID(req.PeerId)
+	ss.scores.Record(peerID, ScoreUseless, 1)
 	peerInfo := ss.getPeer(peerID)
-	if ss.statusData != nil && peerInfo != nil && !peerInfo.peer.Info().Network.Static && !peerInfo.peer.Info().Network.Trusted {
+	if ss.statusData != nil && peerInfo != nil && !peerInfo.peer.Info().Network.Static && !peerInfo.peer.Info().Network.Trusted && !ss.scores.Pinned(peerID) {
 		ss.removePeer(peerID, p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscRequested, nil, "penalized peer"))
 	}
 	return &emptypb.Empty{}, nil
@@ -829,7 +1042,7 @@ func (ss *GrpcServer) findBestPeersWithPermit(peerCount int) []*PeerInfo {
 		height := peerInfo.Height()
 		//fmt.Printf("%d deadlines for peer %s\n", deadlines, peerID)
 		if deadlines < maxPermitsPerPeer {
-			heap.Push(&byMinBlock, PeerRef{pi: peerInfo, height: height})
+			heap.Push(&byMinBlock, PeerRef{pi: peerInfo, height: height, score: ss.scores.Score(peerInfo.ID())})
 			if byMinBlock.Len() > peerCount {
 				// Remove the worst peer
 				peerRef := heap.Pop(&byMinBlock).(PeerRef)
@@ -856,9 +1069,10 @@ func (ss *GrpcServer) findBestPeersWithPermit(peerCount int) []*PeerInfo {
 }
 
 func (ss *GrpcServer) findPeerByMinBlock(minBlock uint64) (*PeerInfo, bool) {
-	// Choose a peer that we can send this request to, with maximum number of permits
+	// Choose a peer that we can send this request to, with the best score and then maximum number of permits
 	var foundPeerInfo *PeerInfo
 	var maxPermits int
+	var bestScore int64
 	now := time.Now()
 	ss.rangePeers(func(peerInfo *PeerInfo) bool {
 		if peerInfo.Height() >= minBlock {
@@ -866,8 +1080,10 @@ func (ss *GrpcServer) findPeerByMinBlock(minBlock uint64) (*PeerInfo, bool) {
 			//fmt.Printf("%d deadlines for peer %s\n", deadlines, peerID)
 			if deadlines < maxPermitsPerPeer {
 				permits := maxPermitsPerPeer - deadlines
-				if permits > maxPermits {
+				score := ss.scores.Score(peerInfo.ID())
+				if foundPeerInfo == nil || score > bestScore || (score == bestScore && permits > maxPermits) {
 					maxPermits = permits
+					bestScore = score
 					foundPeerInfo = peerInfo
 				}
 			}
@@ -912,6 +1128,7 @@ func (ss *GrpcServer) SendMessageById(_ context.Context, inreq *proto_sentry.Sen
 		msgcode != eth.GetReceiptsMsg &&
 		msgcode != eth.ReceiptsMsg &&
 		msgcode != eth.NewPooledTransactionHashesMsg &&
+		msgcode != eth.TransactionsMsg &&
 		msgcode != eth.PooledTransactionsMsg &&
 		msgcode != eth.GetPooledTransactionsMsg {
 		return reply, fmt.Errorf("sendMessageById not implemented for message Id: %s", inreq.Data.Id)
@@ -943,7 +1160,9 @@ func (ss *GrpcServer) SendMessageToRandomPeers(ctx context.Context, req *proto_s
 
 	peerInfos := make([]*PeerInfo, 0, 100)
 	ss.rangePeers(func(peerInfo *PeerInfo) bool {
-		peerInfos = append(peerInfos, peerInfo)
+		if !obsoleteMessage(peerInfo.protocol, msgcode) {
+			peerInfos = append(peerInfos, peerInfo)
+		}
 		return true
 	})
 	rand.Shuffle(len(peerInfos), func(i int, j int) {
@@ -978,6 +1197,9 @@ func (ss *GrpcServer) SendMessageToAll(ctx context.Context, req *proto_sentry.Ou
 
 	var lastErr error
 	ss.rangePeers(func(peerInfo *PeerInfo) bool {
+		if obsoleteMessage(peerInfo.protocol, msgcode) {
+			return true
+		}
 		ss.writePeer("[sentry] SendMessageToAll", peerInfo, msgcode, req.Data, 0)
 		reply.Peers = append(reply.Peers, gointerfaces.ConvertHashToH512(peerInfo.ID()))
 		return true
@@ -986,16 +1208,7 @@ func (ss *GrpcServer) SendMessageToAll(ctx context.Context, req *proto_sentry.Ou
 }
 
 func (ss *GrpcServer) HandShake(context.Context, *emptypb.Empty) (*proto_sentry.HandShakeReply, error) {
-	reply := &proto_sentry.HandShakeReply{}
-	switch ss.Protocols[0].Version {
-	case direct.ETH66:
-		reply.Protocol = proto_sentry.Protocol_ETH66
-	case direct.ETH67:
-		reply.Protocol = proto_sentry.Protocol_ETH67
-	case direct.ETH68:
-		reply.Protocol = proto_sentry.Protocol_ETH68
-	}
-	return reply, nil
+	return &proto_sentry.HandShakeReply{Protocol: protocolToProto(ss.Protocols[0].Version)}, nil
 }
 
 func (ss *GrpcServer) startP2PServer(genesisHash libcommon.Hash) (*p2p.Server, error) {
@@ -1056,9 +1269,32 @@ func (ss *GrpcServer) SetStatus(ctx context.Context, statusData *proto_sentry.St
 		// Not overwrite statusData if the message contains zero MaxBlock (comes from standalone transaction pool)
 		ss.statusData = statusData
 	}
+	if statusData.MaxBlockHeight >= ss.lastBlockRangeUpdate+blockRangeUpdateInterval {
+		ss.lastBlockRangeUpdate = statusData.MaxBlockHeight
+		ss.sendBlockRangeUpdate(statusData)
+	}
 	return reply, nil
 }
 
+// sendBlockRangeUpdate - notifies eth/69 peers about new head
+func (ss *GrpcServer) sendBlockRangeUpdate(statusData *proto_sentry.StatusData) {
+	data, err := rlp.EncodeToBytes(&eth.BlockRangeUpdatePacket{
+		EarliestBlock:   statusData.EarliestBlock,
+		LatestBlock:     statusData.MaxBlockHeight,
+		LatestBlockHash: gointerfaces.ConvertH256ToHash(statusData.BestHash),
+	})
+	if err != nil {
+		ss.logger.Warn("[sentry] can't encode BlockRangeUpdate", "err", err)
+		return
+	}
+	ss.rangePeers(func(peerInfo *PeerInfo) bool {
+		if peerInfo.protocol >= direct.ETH69 {
+			ss.writePeer("[sentry] sendBlockRangeUpdate", peerInfo, eth.BlockRangeUpdateMsg, data, 0)
+		}
+		return true
+	})
+}
+
 func (ss *GrpcServer) Peers(_ context.Context, _ *emptypb.Empty) (*proto_sentry.PeersReply, error) {
 	p2pServer := ss.getP2PServer()
 	if p2pServer == nil {
@@ -1103,7 +1339,7 @@ func (ss *GrpcServer) PeerCount(_ context.Context, req *proto_sentry.PeerCountRe
 	reply := &proto_sentry.PeerCountReply{}
 	for protocol, count := range counts {
 		reply.Count += uint64(count)
-		reply.CountsPerProtocol = append(reply.CountsPerProtocol, &proto_sentry.PeerCountPerProtocol{Protocol: proto_sentry.Protocol(protocol), Count: uint64(count)})
+		reply.CountsPerProtocol = append(reply.CountsPerProtocol, &proto_sentry.PeerCountPerProtocol{Protocol: protocolToProto(protocol), Count: uint64(count)})
 	}
 	return reply, nil
 }
@@ -1236,6 +1472,9 @@ func (ss *GrpcServer) Close() {
 	if p2pServer != nil {
 		p2pServer.Stop()
 	}
+	if err := ss.scores.Save(); err != nil {
+		ss.logger.Warn("[sentry] can't save peer scores", "err", err)
+	}
 }
 
 func (ss *GrpcServer) sendNewPeerToClients(peerID *proto_types.H512) {
@@ -38,11 +38,8 @@ func (cs *MultiClient) SetStatus(ctx context.Context) {
 }
 
 func (cs *MultiClient) SendBodyRequest(ctx context.Context, req *bodydownload.BodyRequest) (peerID [64]byte, ok bool) {
-	// if sentry not found peers to send such message, try next one. stop if found.
-	for i, ok, next := cs.randSentryIndex(); ok; i, ok = next() {
-		if !cs.sentries[i].Ready() {
-			continue
-		}
+	// if sentry not found peers to send such message or failed, try next one. stop if found.
+	for _, i := range cs.balancer.Order(proto_sentry.MessageId_GET_BLOCK_BODIES_66) {
 
 		//log.Info(fmt.Sprintf("Sending body request for %v", req.BlockNums))
 		var bytes []byte
@@ -66,9 +63,10 @@ func (cs *MultiClient) SendBodyRequest(ctx context.Context, req *bodydownload.Bo
 
 		sentPeers, err1 := cs.sentries[i].SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
 		if err1 != nil {
-			cs.logger.Error("Could not send block bodies request", "err", err1)
-			return [64]byte{}, false
+			cs.sentryFailure(i, err1)
+			continue
 		}
+		cs.balancer.Success(i)
 		if sentPeers == nil || len(sentPeers.Peers) == 0 {
 			continue
 		}
@@ -78,11 +76,8 @@ func (cs *MultiClient) SendBodyRequest(ctx context.Context, req *bodydownload.Bo
 }
 
 func (cs *MultiClient) SendHeaderRequest(ctx context.Context, req *headerdownload.HeaderRequest) (peerID [64]byte, ok bool) {
-	// if sentry not found peers to send such message, try next one. stop if found.
-	for i, ok, next := cs.randSentryIndex(); ok; i, ok = next() {
-		if !cs.sentries[i].Ready() {
-			continue
-		}
+	// if sentry not found peers to send such message or failed, try next one. stop if found.
+	for _, i := range cs.balancer.Order(proto_sentry.MessageId_GET_BLOCK_HEADERS_66) {
 		//log.Info(fmt.Sprintf("Sending header request {hash: %x, height: %d, length: %d}", req.Hash, req.Number, req.Length))
 		reqData := &eth.GetBlockHeadersPacket66{
 			RequestId: rand.Uint64(), // nolint: gosec
@@ -113,9 +108,10 @@ func (cs *MultiClient) SendHeaderRequest(ctx context.Context, req *headerdownloa
 		}
 		sentPeers, err1 := cs.sentries[i].SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
 		if err1 != nil {
-			cs.logger.Error("Could not send header request", "err", err1)
-			return [64]byte{}, false
+			cs.sentryFailure(i, err1)
+			continue
 		}
+		cs.balancer.Success(i)
 		if sentPeers == nil || len(sentPeers.Peers) == 0 {
 			continue
 		}
@@ -136,6 +132,15 @@ func (cs *MultiClient) randSentryIndex() (int, bool, func() (int, bool)) {
 	}
 }
 
+// sentryFailure - request to sentry i failed, it's tried again after the other sentries
+func (cs *MultiClient) sentryFailure(i int, err error) {
+	if cs.balancer.Failure(i) {
+		cs.logger.Warn("[sentry] degraded, requests failover to other sentries", "sentry", i, "err", err)
+		return
+	}
+	cs.logger.Debug("[sentry] request failed", "sentry", i, "err", err)
+}
+
 // sending list of penalties to all sentries
 func (cs *MultiClient) Penalize(ctx context.Context, penalties []headerdownload.PenaltyItem) {
 	for i := range penalties {
@@ -15,6 +15,7 @@ import (
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -62,6 +63,7 @@ func (cs *MultiClient) StartStreamLoops(ctx context.Context) {
 		go cs.RecvUploadHeadersMessageLoop(ctx, sentry, nil)
 		go cs.PeerEventsLoop(ctx, sentry, nil)
 	}
+	go cs.balancer.Run(ctx)
 }
 
 func (cs *MultiClient) RecvUploadMessageLoop(
@@ -265,6 +267,7 @@ type MultiClient struct {
 	Bd                                *bodydownload.BodyDownload
 	IsMock                            bool
 	sentries                          []direct.SentryClient
+	balancer                          *direct.SentryBalancer
 	ChainConfig                       *chain.Config
 	db                                kv.RwDB
 	Engine                            consensus.Engine
@@ -334,6 +337,7 @@ func NewMultiClient(
 		Hd:                                hd,
 		Bd:                                bd,
 		sentries:                          sentries,
+		balancer:                          direct.NewSentryBalancer(sentries, direct.DefaultSentryBalancerCfg),
 		ChainConfig:                       chainConfig,
 		db:                                db,
 		Engine:                            engine,
@@ -352,6 +356,9 @@ func NewMultiClient(
 
 func (cs *MultiClient) Sentries() []direct.SentryClient { return cs.sentries }
 
+// SetSentryBalancer replaces the default (random) routing of header and body requests across sentries
+func (cs *MultiClient) SetSentryBalancer(b *direct.SentryBalancer) { cs.balancer = b }
+
 func (cs *MultiClient) newBlockHashes66(ctx context.Context, req *proto_sentry.InboundMessage, sentry direct.SentryClient) error {
 	if cs.disableBlockDownload {
 		return nil
@@ -815,7 +822,8 @@ func (cs *MultiClient) makeStatusData(ctx context.Context) (*proto_sentry.Status
 	return cs.statusDataProvider.GetStatusData(ctx)
 }
 
-func GrpcClient(ctx context.Context, sentryAddr string) (*direct.SentryClientRemote, error) {
+// GrpcClient - creds=nil means plaintext connection
+func GrpcClient(ctx context.Context, sentryAddr string, creds credentials.TransportCredentials) (*direct.SentryClientRemote, error) {
 	// creating grpc client connection
 	var dialOpts []grpc.DialOption
 
@@ -828,7 +836,10 @@ func GrpcClient(ctx context.Context, sentryAddr string) (*direct.SentryClientRem
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{}),
 	}
 
-	dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
 	conn, err := grpc.DialContext(ctx, sentryAddr, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
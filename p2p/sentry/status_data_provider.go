@@ -10,6 +10,7 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/chain"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/cmp"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentryproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
@@ -34,6 +35,8 @@ type StatusDataProvider struct {
 	genesisHash libcommon.Hash
 	heightForks []uint64
 	timeForks   []uint64
+
+	earliestBlock uint64 // first block with bodies and receipts, advertised to eth/69 peers
 }
 
 func NewStatusDataProvider(
@@ -41,11 +44,13 @@ func NewStatusDataProvider(
 	chainConfig *chain.Config,
 	genesis *types.Block,
 	networkId uint64,
+	earliestBlock uint64,
 ) *StatusDataProvider {
 	s := &StatusDataProvider{
-		db:          db,
-		networkId:   networkId,
-		genesisHash: genesis.Hash(),
+		db:            db,
+		networkId:     networkId,
+		genesisHash:   genesis.Hash(),
+		earliestBlock: earliestBlock,
 	}
 
 	s.heightForks, s.timeForks = forkid.GatherForks(chainConfig, genesis.Time())
@@ -60,6 +65,7 @@ func (s *StatusDataProvider) makeStatusData(head ChainHead) *proto_sentry.Status
 		BestHash:        gointerfaces.ConvertHashToH256(head.HeadHash),
 		MaxBlockHeight:  head.HeadHeight,
 		MaxBlockTime:    head.HeadTime,
+		EarliestBlock:   cmp.Min(s.earliestBlock, head.HeadHeight), // peers reject ranges starting after the head
 		ForkData: &proto_sentry.Forks{
 			Genesis:     gointerfaces.ConvertHashToH256(s.genesisHash),
 			HeightForks: s.heightForks,
@@ -122,6 +122,12 @@ type Config struct {
 	// allowed to connect, even above the peer limit.
 	TrustedNodes []*enode.Node
 
+	// StaticNodesFile and TrustedNodesFile are JSON lists of enode URLs of
+	// static and trusted nodes in addition to StaticNodes and TrustedNodes.
+	// Sentry reloads them on change, without restart.
+	StaticNodesFile  string `toml:",omitempty"`
+	TrustedNodesFile string `toml:",omitempty"`
+
 	// Connectivity can be restricted to certain IP networks.
 	// If this option is set to a non-nil value, only hosts which match one of the
 	// IP networks contained in the list are considered.
@@ -153,6 +159,12 @@ type Config struct {
 
 	SentryAddr []string
 
+	// SentryPolicy - routing of header, body and tx requests across sentries, see direct.SentryPolicy.
+	// SentryTags are tags of SentryAddr sentries, e.g. their regions, used by the "tag" policy with SentryPreferredTag.
+	SentryPolicy       string
+	SentryTags         []string
+	SentryPreferredTag string
+
 	// If set to a non-nil value, the given NAT port mapper
 	// is used to make the listening port available to the
 	// Internet.
@@ -21,4 +21,19 @@ type MiningConfig struct {
 	GasLimit   uint64            // Target gas limit for mined blocks.
 	GasPrice   *big.Int          // Minimum gas price for mining a transaction
 	Recommit   time.Duration     // The time interval for miner to re-create mining work.
+
+	TxOrdering       string // Order of txpool transactions in mined blocks: MiningTxOrderingPool or MiningTxOrderingTip
+	MaxBlobsPerBlock uint64 // Limit of blobs in mined blocks, 0 - chain's limit
+	LocalTxBoost     uint64 // Percent added to effective tip of local transactions when ordering and packing them
+
+	// External builders asked for payloads in engine_getPayload, the more valuable of built one and built locally is returned
+	Builders        []string            // JSON-RPC URLs serving builder_getPayload
+	BuilderMinBid   *big.Int            // Builder payload is taken only if its value (wei) is at least this, nil - any
+	BuilderDenyList []libcommon.Address // Builder payloads with transactions from or to these addresses are rejected
+	BuilderTimeout  time.Duration       // How long engine_getPayload waits for builders
 }
+
+const (
+	MiningTxOrderingPool = "pool" // as yielded by txpool (see --txpool.scoring)
+	MiningTxOrderingTip  = "tip"  // by effective priority fee, keeping nonce order of each sender
+)
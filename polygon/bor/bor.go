@@ -447,6 +447,16 @@ func (c *Bor) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Head
 	return c.verifyHeader(chain, header, nil)
 }
 
+// PreverifySeal implements consensus.SealPreverifier: recovers signer of the header,
+// the result is kept in signatures cache and reused by VerifyHeader
+func (c *Bor) PreverifySeal(header *types.Header) error {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	_, err := Ecrecover(header, c.Signatures, c.config)
+	return err
+}
+
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
 // method returns a quit channel to abort the operations and a results channel to
 // retrieve the async verifications (the order is that of the input slice).
@@ -4,53 +4,42 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/core/rawdb"
-	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/polygon/bor/finality/whitelist"
 )
 
-func GetFinalizedBlockNumber(tx kv.Tx) uint64 {
-	currentBlockNum := rawdb.ReadCurrentHeader(tx)
-
+// FinalizedBlock - latest finalized block of local canonical chain: the highest of whitelisted milestone and
+// checkpoint, which is known locally and matches canonical chain. Whitelisted milestone which isn't on local chain
+// yet (node is behind) or anymore (reorg pending), or is older than checkpoint (Heimdall stopped producing
+// milestones), falls back to checkpoint. Single source of bor finality for RPC: `finalized` and `safe` block tags,
+// eth_syncing and finalizedHeads subscription
+func FinalizedBlock(tx kv.Tx) (number uint64, hash common.Hash, ok bool) {
 	service := whitelist.GetWhitelistingService()
-
-	doExist, number, hash := service.GetWhitelistedMilestone()
-	if doExist && number <= currentBlockNum.Number.Uint64() {
-
-		blockHeader := rawdb.ReadHeaderByNumber(tx, number)
-
-		if blockHeader == nil {
-			return 0
-		}
-
-		if blockHeader.Hash() == hash {
-			return number
-		}
+	if service == nil {
+		return 0, common.Hash{}, false
 	}
+	return finalizedBlock(tx, service)
+}
 
-	doExist, number, hash = service.GetWhitelistedCheckpoint()
-	if doExist && number <= currentBlockNum.Number.Uint64() {
-
-		blockHeader := rawdb.ReadHeaderByNumber(tx, number)
-
-		if blockHeader == nil {
-			return 0
-		}
+func finalizedBlock(tx kv.Tx, service *whitelist.Service) (number uint64, hash common.Hash, ok bool) {
+	currentHeader := rawdb.ReadCurrentHeader(tx)
+	if currentHeader == nil {
+		return 0, common.Hash{}, false
+	}
+	head := currentHeader.Number.Uint64()
 
-		if blockHeader.Hash() == hash {
-			return number
+	onCanonicalChain := func(doExist bool, number uint64, hash common.Hash) bool {
+		if !doExist || number > head {
+			return false
 		}
+		canonicalHash, err := rawdb.ReadCanonicalHash(tx, number)
+		return err == nil && canonicalHash == hash
 	}
 
-	return 0
-}
-
-// CurrentFinalizedBlock retrieves the current finalized block of the canonical
-// chain. The block is retrieved from the blockchain's internal cache.
-func CurrentFinalizedBlock(tx kv.Tx, number uint64) *types.Block {
-	hash, err := rawdb.ReadCanonicalHash(tx, number)
-	if err != nil || hash == (common.Hash{}) {
-		return nil
+	if doExist, milestoneNumber, milestoneHash := service.GetWhitelistedMilestone(); onCanonicalChain(doExist, milestoneNumber, milestoneHash) {
+		number, hash, ok = milestoneNumber, milestoneHash, true
 	}
-
-	return rawdb.ReadBlock(tx, hash, number)
+	if doExist, checkpointNumber, checkpointHash := service.GetWhitelistedCheckpoint(); (!ok || checkpointNumber > number) && onCanonicalChain(doExist, checkpointNumber, checkpointHash) {
+		number, hash, ok = checkpointNumber, checkpointHash, true
+	}
+	return number, hash, ok
 }
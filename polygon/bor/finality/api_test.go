@@ -0,0 +1,65 @@
+package finality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/polygon/bor/finality/whitelist"
+)
+
+func TestFinalizedBlock(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	var hashes []common.Hash
+	parent := common.Hash{}
+	for i := int64(0); i <= 10; i++ {
+		header := &types.Header{Number: big.NewInt(i), ParentHash: parent, Difficulty: common.Big1}
+		rawdb.WriteHeader(tx, header)
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, header.Hash(), header.Number.Uint64()))
+		rawdb.WriteHeadHeaderHash(tx, header.Hash())
+		hashes = append(hashes, header.Hash())
+		parent = header.Hash()
+	}
+
+	service := whitelist.NewService(memdb.NewTestDB(t))
+	requireFinalized := func(expected uint64, expectedOk bool) {
+		number, hash, ok := finalizedBlock(tx, service)
+		require.Equal(t, expectedOk, ok)
+		require.Equal(t, expected, number)
+		if ok {
+			require.Equal(t, hashes[expected], hash)
+		}
+	}
+
+	requireFinalized(0, false)
+
+	service.ProcessCheckpoint(4, hashes[4])
+	requireFinalized(4, true)
+
+	service.ProcessMilestone(8, hashes[8])
+	requireFinalized(8, true)
+
+	// node is behind Heimdall
+	service.ProcessMilestone(12, common.Hash{12})
+	requireFinalized(4, true)
+
+	// milestone isn't on local chain
+	service.ProcessMilestone(6, common.Hash{6})
+	requireFinalized(4, true)
+
+	// milestones lag behind checkpoints
+	service.ProcessMilestone(8, hashes[8])
+	service.ProcessCheckpoint(9, hashes[9])
+	requireFinalized(9, true)
+}
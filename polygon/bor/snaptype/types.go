@@ -31,7 +31,7 @@ import (
 )
 
 func init() {
-	borTypes := append(coresnaptype.BlockSnapshotTypes, BorSnapshotTypes...)
+	borTypes := append(append(coresnaptype.BlockSnapshotTypes, coresnaptype.ReceiptSnapshotTypes...), BorSnapshotTypes...)
 
 	snapcfg.RegisterKnownTypes(networkname.MumbaiChainName, borTypes)
 	snapcfg.RegisterKnownTypes(networkname.AmoyChainName, borTypes)
@@ -11,9 +11,12 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/ledgerwatch/erigon-lib/metrics"
 )
@@ -35,6 +38,13 @@ const (
 	apiHeimdallTimeout = 10 * time.Second
 	retryBackOff       = time.Second
 	maxRetries         = 5
+
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+
+	spansCached       = 128
+	checkpointsCached = 256
+	milestonesCached  = 256
 )
 
 //go:generate mockgen -typed=true -destination=./client_mock.go -package=heimdall . HeimdallClient
@@ -65,13 +75,23 @@ type HeimdallClient interface {
 
 var _ HeimdallClient = &Client{}
 
+// Client - Heimdall REST client. Several endpoints may be given: requests go to the last endpoint which answered,
+// and fail over to the next one on error. Spans, checkpoints and milestones never change once Heimdall has them,
+// so they are cached by number, and identical concurrent requests share one round trip
 type Client struct {
-	urlString    string
+	urlString    string   // primary endpoint, used to build request URLs
+	urls         []string // all endpoints, primary first
+	active       atomic.Int32
 	client       HttpClient
 	retryBackOff time.Duration
 	maxRetries   int
 	closeCh      chan struct{}
 	logger       log.Logger
+
+	inflight    singleflight.Group
+	spans       *lru.Cache[uint64, *Span]
+	checkpoints *lru.Cache[int64, *Checkpoint]
+	milestones  *lru.Cache[int64, *Milestone]
 }
 
 type Request struct {
@@ -86,21 +106,44 @@ type HttpClient interface {
 	CloseIdleConnections()
 }
 
+// NewHeimdallClient - urlString is comma-separated list of Heimdall endpoints, in order of preference
 func NewHeimdallClient(urlString string, logger log.Logger) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
 	httpClient := &http.Client{
-		Timeout: apiHeimdallTimeout,
+		Timeout:   apiHeimdallTimeout,
+		Transport: transport,
 	}
 	return newHeimdallClient(urlString, httpClient, retryBackOff, maxRetries, logger)
 }
 
 func newHeimdallClient(urlString string, httpClient HttpClient, retryBackOff time.Duration, maxRetries int, logger log.Logger) *Client {
+	var urls []string
+	for _, u := range strings.Split(urlString, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		urls = []string{urlString}
+	}
+
+	spans, _ := lru.New[uint64, *Span](spansCached)
+	checkpoints, _ := lru.New[int64, *Checkpoint](checkpointsCached)
+	milestones, _ := lru.New[int64, *Milestone](milestonesCached)
+
 	return &Client{
-		urlString:    urlString,
+		urlString:    urls[0],
+		urls:         urls,
 		logger:       logger,
 		client:       httpClient,
 		retryBackOff: retryBackOff,
 		maxRetries:   maxRetries,
 		closeCh:      make(chan struct{}),
+		spans:        spans,
+		checkpoints:  checkpoints,
+		milestones:   milestones,
 	}
 }
 
@@ -218,6 +261,12 @@ func (c *Client) FetchLatestSpan(ctx context.Context) (*Span, error) {
 }
 
 func (c *Client) FetchSpan(ctx context.Context, spanID uint64) (*Span, error) {
+	if span, ok := c.spans.Get(spanID); ok {
+		cacheHits[spanRequest].Inc()
+		spanCopy := *span
+		return &spanCopy, nil
+	}
+
 	url, err := spanURL(c.urlString, spanID)
 	if err != nil {
 		return nil, fmt.Errorf("%w, spanID=%d", err, spanID)
@@ -230,11 +279,20 @@ func (c *Client) FetchSpan(ctx context.Context, spanID uint64) (*Span, error) {
 		return nil, fmt.Errorf("%w, spanID=%d", err, spanID)
 	}
 
+	span := response.Result
+	c.spans.Add(spanID, &span)
+
 	return &response.Result, nil
 }
 
 // FetchCheckpoint fetches the checkpoint from heimdall
 func (c *Client) FetchCheckpoint(ctx context.Context, number int64) (*Checkpoint, error) {
+	if checkpoint, ok := c.checkpoints.Get(number); ok {
+		cacheHits[checkpointRequest].Inc()
+		checkpointCopy := *checkpoint
+		return &checkpointCopy, nil
+	}
+
 	url, err := checkpointURL(c.urlString, number)
 	if err != nil {
 		return nil, err
@@ -247,6 +305,11 @@ func (c *Client) FetchCheckpoint(ctx context.Context, number int64) (*Checkpoint
 		return nil, err
 	}
 
+	if number >= 0 { // -1 is the latest checkpoint
+		checkpoint := response.Result
+		c.checkpoints.Add(number, &checkpoint)
+	}
+
 	return &response.Result, nil
 }
 
@@ -273,6 +336,12 @@ func isInvalidMilestoneIndexError(err error) bool {
 
 // FetchMilestone fetches a milestone from heimdall
 func (c *Client) FetchMilestone(ctx context.Context, number int64) (*Milestone, error) {
+	if milestone, ok := c.milestones.Get(number); ok {
+		cacheHits[milestoneRequest].Inc()
+		milestoneCopy := *milestone
+		return &milestoneCopy, nil
+	}
+
 	url, err := milestoneURL(c.urlString, number)
 	if err != nil {
 		return nil, err
@@ -294,6 +363,11 @@ func (c *Client) FetchMilestone(ctx context.Context, number int64) (*Milestone,
 
 	response.Result.Id = MilestoneId(number)
 
+	if number >= 0 { // -1 is the latest milestone
+		milestone := response.Result
+		c.milestones.Add(number, &milestone)
+	}
+
 	return &response.Result, nil
 }
 
@@ -397,13 +471,36 @@ func FetchWithRetry[T any](ctx context.Context, client *Client, url *url.URL, lo
 	return FetchWithRetryEx[T](ctx, client, url, nil, logger)
 }
 
-// FetchWithRetryEx returns data from heimdall with retry
+// FetchWithRetryEx returns data from heimdall with retry. Identical concurrent requests are coalesced into one
 func FetchWithRetryEx[T any](
 	ctx context.Context,
 	client *Client,
 	url *url.URL,
 	isRecoverableError func(error) bool,
 	logger log.Logger,
+) (*T, error) {
+	res, err, shared := client.inflight.Do(url.String(), func() (interface{}, error) {
+		return fetchWithRetryEx[T](ctx, client, url, isRecoverableError, logger)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := res.(*T)
+	if shared {
+		// callers may modify result
+		resultCopy := *result
+		result = &resultCopy
+	}
+	return result, nil
+}
+
+// fetchWithRetryEx - each attempt tries endpoints one by one starting from the active one, until one of them answers
+func fetchWithRetryEx[T any](
+	ctx context.Context,
+	client *Client,
+	url *url.URL,
+	isRecoverableError func(error) bool,
+	logger log.Logger,
 ) (result *T, err error) {
 	attempt := 0
 	// create a new ticker for retrying the request
@@ -413,25 +510,42 @@ func FetchWithRetryEx[T any](
 	for attempt < client.maxRetries {
 		attempt++
 
-		request := &Request{client: client.client, url: url, start: time.Now()}
-		result, err = Fetch[T](ctx, request, logger)
-		if err == nil {
-			return result, nil
-		}
+		active := int(client.active.Load())
+		for i := 0; i < len(client.urls); i++ {
+			endpoint := (active + i) % len(client.urls)
+			endpointURL, urlErr := client.endpointURL(url, endpoint)
+			if urlErr != nil {
+				return nil, urlErr
+			}
 
-		// 503 (Service Unavailable) is thrown when an endpoint isn't activated
-		// yet in heimdall. E.g. when the hard fork hasn't hit yet but heimdall
-		// is upgraded.
-		if errors.Is(err, ErrServiceUnavailable) {
-			client.logger.Debug(heimdallLogPrefix("service unavailable at the moment"), "path", url.Path, "queryParams", url.RawQuery, "attempt", attempt, "err", err)
-			return nil, err
-		}
+			request := &Request{client: client.client, url: endpointURL, start: time.Now()}
+			result, err = Fetch[T](ctx, request, logger)
+			if err == nil {
+				if endpoint != active && client.active.CompareAndSwap(int32(active), int32(endpoint)) {
+					failovers.Inc()
+					client.logger.Info(heimdallLogPrefix("switched endpoint"), "from", client.urls[active], "to", client.urls[endpoint])
+				}
+				return result, nil
+			}
 
-		if (isRecoverableError != nil) && !isRecoverableError(err) {
-			return nil, err
-		}
+			// 503 (Service Unavailable) is thrown when an endpoint isn't activated
+			// yet in heimdall. E.g. when the hard fork hasn't hit yet but heimdall
+			// is upgraded.
+			if errors.Is(err, ErrServiceUnavailable) {
+				client.logger.Debug(heimdallLogPrefix("service unavailable at the moment"), "path", url.Path, "queryParams", url.RawQuery, "attempt", attempt, "err", err)
+				return nil, err
+			}
+
+			if (isRecoverableError != nil) && !isRecoverableError(err) {
+				return nil, err
+			}
 
-		client.logger.Warn(heimdallLogPrefix("an error while fetching"), "path", url.Path, "queryParams", url.RawQuery, "attempt", attempt, "err", err)
+			if ctx.Err() != nil {
+				break
+			}
+
+			client.logger.Warn(heimdallLogPrefix("an error while fetching"), "endpoint", client.urls[endpoint], "path", url.Path, "queryParams", url.RawQuery, "attempt", attempt, "err", err)
+		}
 
 		select {
 		case <-ctx.Done():
@@ -448,6 +562,18 @@ func FetchWithRetryEx[T any](
 	return nil, err
 }
 
+// endpointURL - request URL built for the primary endpoint moved to endpoint with index `endpoint`
+func (c *Client) endpointURL(u *url.URL, endpoint int) (*url.URL, error) {
+	if endpoint == 0 {
+		return u, nil
+	}
+	primary, err := url.Parse(c.urlString)
+	if err != nil {
+		return nil, err
+	}
+	return makeURL(c.urls[endpoint], strings.TrimPrefix(u.Path, primary.Path), u.RawQuery)
+}
+
 // Fetch fetches response from heimdall
 func Fetch[T any](ctx context.Context, request *Request, logger log.Logger) (*T, error) {
 	isSuccessful := false
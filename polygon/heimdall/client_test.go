@@ -2,8 +2,11 @@ package heimdall
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -61,3 +64,44 @@ func TestHeimdallClientStateSyncEventsReturnsErrNoResponseWhenHttp200WithEmptyBo
 	require.Nil(t, spanRes)
 	require.ErrorIs(t, err, ErrNoResponse)
 }
+
+func TestHeimdallClientFailover(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	httpClient := NewMockHttpClient(ctrl)
+	var hosts []string
+	httpClient.EXPECT().
+		Do(gomock.Any()).
+		DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			hosts = append(hosts, req.URL.Host+req.URL.Path)
+			if req.URL.Host == "primary.com" {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader(`{"height":"0","result":{"span_id":` + path.Base(req.URL.Path) + `}}`)),
+			}, nil
+		}).
+		AnyTimes()
+	logger := testlog.Logger(t, log.LvlDebug)
+	heimdallClient := newHeimdallClient("https://primary.com/api, https://backup.com", httpClient, time.Millisecond, 2, logger)
+
+	span, err := heimdallClient.FetchSpan(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, SpanId(1), span.Id)
+	require.Equal(t, []string{"primary.com/api/bor/span/1", "backup.com/bor/span/1"}, hosts)
+
+	// backup stays active
+	hosts = nil
+	span, err = heimdallClient.FetchSpan(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, SpanId(2), span.Id)
+	require.Equal(t, []string{"backup.com/bor/span/2"}, hosts)
+
+	// spans are cached
+	hosts = nil
+	span, err = heimdallClient.FetchSpan(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, SpanId(1), span.Id)
+	require.Empty(t, hosts)
+}
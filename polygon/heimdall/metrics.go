@@ -41,37 +41,37 @@ func getRequestType(ctx context.Context) (requestType, bool) {
 
 var (
 	requestMeters = map[requestType]meter{
-		stateSyncRequest: {
-			request: map[bool]metrics.Gauge{
-				true:  metrics.GetOrCreateGauge("client_requests_statesync_valid"),
-				false: metrics.GetOrCreateGauge("client_requests_statesync_invalid"),
-			},
-			timer: metrics.GetOrCreateSummary("client_requests_statesync_duration"),
-		},
-		spanRequest: {
-			request: map[bool]metrics.Gauge{
-				true:  metrics.GetOrCreateGauge("client_requests_span_valid"),
-				false: metrics.GetOrCreateGauge("client_requests_span_invalid"),
-			},
-			timer: metrics.GetOrCreateSummary("client_requests_span_duration"),
-		},
-		checkpointRequest: {
-			request: map[bool]metrics.Gauge{
-				true:  metrics.GetOrCreateGauge("client_requests_checkpoint_valid"),
-				false: metrics.GetOrCreateGauge("client_requests_checkpoint_invalid"),
-			},
-			timer: metrics.GetOrCreateSummary("client_requests_checkpoint_duration"),
-		},
-		checkpointCountRequest: {
-			request: map[bool]metrics.Gauge{
-				true:  metrics.GetOrCreateGauge("client_requests_checkpointcount_valid"),
-				false: metrics.GetOrCreateGauge("client_requests_checkpointcount_invalid"),
-			},
-			timer: metrics.GetOrCreateSummary("client_requests_checkpointcount_duration"),
-		},
+		stateSyncRequest:          newMeter("statesync"),
+		spanRequest:               newMeter("span"),
+		checkpointRequest:         newMeter("checkpoint"),
+		checkpointCountRequest:    newMeter("checkpointcount"),
+		checkpointListRequest:     newMeter("checkpointlist"),
+		milestoneRequest:          newMeter("milestone"),
+		milestoneCountRequest:     newMeter("milestonecount"),
+		milestoneNoAckRequest:     newMeter("milestonenoack"),
+		milestoneLastNoAckRequest: newMeter("milestonelastnoack"),
+		milestoneIDRequest:        newMeter("milestoneid"),
+	}
+
+	cacheHits = map[requestType]metrics.Counter{
+		spanRequest:       metrics.GetOrCreateCounter("client_cache_span_hits"),
+		checkpointRequest: metrics.GetOrCreateCounter("client_cache_checkpoint_hits"),
+		milestoneRequest:  metrics.GetOrCreateCounter("client_cache_milestone_hits"),
 	}
+
+	failovers = metrics.GetOrCreateCounter("client_requests_failover")
 )
 
+func newMeter(name string) meter {
+	return meter{
+		request: map[bool]metrics.Gauge{
+			true:  metrics.GetOrCreateGauge("client_requests_" + name + "_valid"),
+			false: metrics.GetOrCreateGauge("client_requests_" + name + "_invalid"),
+		},
+		timer: metrics.GetOrCreateSummary("client_requests_" + name + "_duration"),
+	}
+}
+
 func sendMetrics(ctx context.Context, start time.Time, isSuccessful bool) {
 	reqType, ok := getRequestType(ctx)
 	if !ok {
@@ -37,6 +37,7 @@ func TraceBorStateSyncTxnDebugAPI(
 	blockCtx evmtypes.BlockContext,
 	stream *jsoniter.Stream,
 	callTimeout time.Duration,
+	budget *tracers.Budget,
 ) error {
 	stateSyncEvents, err := blockReader.EventsByBlock(ctx, dbTx, blockHash, blockNum)
 	if err != nil {
@@ -45,7 +46,7 @@ func TraceBorStateSyncTxnDebugAPI(
 	}
 
 	txCtx := initStateSyncTxContext(blockNum, blockHash)
-	tracer, streaming, cancel, err := transactions.AssembleTracer(ctx, traceConfig, txCtx.TxHash, stream, callTimeout)
+	tracer, streaming, cancel, err := transactions.AssembleTracer(ctx, traceConfig, txCtx.TxHash, stream, callTimeout, budget)
 	if err != nil {
 		stream.WriteNil()
 		return err
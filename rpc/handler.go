@@ -28,6 +28,10 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/log/v3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 
 	"github.com/ledgerwatch/erigon/rpc/rpccfg"
 )
@@ -200,7 +204,7 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 				}
 
 				buf := bytes.NewBuffer(nil)
-				stream := jsoniter.NewStream(jsoniter.ConfigDefault, buf, 4096)
+				stream := newCountingStream(buf)
 				if res := h.handleCallMsg(cp, calls[i], stream); res != nil {
 					answersWithNils[i] = res
 				}
@@ -472,23 +476,37 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream *jsoniter
 		callb = h.reg.callback(msg.Method)
 	}
 	if callb == nil {
-		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+		resp := msg.errorResponse(&methodNotFoundError{method: msg.Method})
+		observeError(unknownMethod, resp.Error)
+		return resp
 	}
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
-		return msg.errorResponse(&InvalidParamsError{err.Error()})
+		resp := msg.errorResponse(&InvalidParamsError{err.Error()})
+		if callb != h.unsubscribeCb {
+			observeError(msg.Method, resp.Error)
+		}
+		return resp
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args, stream)
+	written := streamedBytes(stream)
+	answer, callErr := h.runMethod(cp.ctx, msg, callb, args, stream)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
 	if callb != h.unsubscribeCb {
-		rpcRequestGauge.Inc()
+		var rpcErr *jsonError
 		if answer != nil && answer.Error != nil {
+			rpcErr = answer.Error
+		} else if callErr != nil { // streamed error
+			rpcErr = errorMessage(callErr).Error
+		}
+		rpcRequestGauge.Inc()
+		if rpcErr != nil {
 			failedReqeustGauge.Inc()
 		}
-		newRPCServingTimerMS(msg.Method, answer == nil || answer.Error == nil).ObserveDuration(start)
+		newRPCServingTimerMS(msg.Method, rpcErr == nil).ObserveDuration(start)
+		observeCall(msg.Method, start, len(msg.Params), streamedBytes(stream)-written+responseSize(answer), rpcErr)
 	}
 	return answer
 }
@@ -523,17 +541,21 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream *jso
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
-	return h.runMethod(ctx, msg, callb, args, stream)
+	answer, _ := h.runMethod(ctx, msg, callb, args, stream)
+	return answer
 }
 
-// runMethod runs the Go callback for an RPC method.
-func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) *jsonrpcMessage {
+// runMethod runs the Go callback for an RPC method. Error of method is returned also when it's written to stream.
+func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) (*jsonrpcMessage, error) {
+	ctx = kv.WithTxOwner(ctx, msg.Method) // attribute long read transactions to rpc method
+	ctx, span := tracing.Start(ctx, "rpc "+msg.Method, attribute.String("rpc.method", msg.Method))
 	if !callb.streamable {
 		result, err := callb.call(ctx, msg.Method, args, stream)
+		tracing.End(span, err)
 		if err != nil {
-			return msg.errorResponse(err)
+			return msg.errorResponse(err), err
 		}
-		return msg.response(result)
+		return msg.response(result), nil
 	}
 
 	stream.WriteObjectStart()
@@ -547,6 +569,7 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	}
 	stream.WriteObjectField("result")
 	_, err := callb.call(ctx, msg.Method, args, stream)
+	tracing.End(span, err)
 	if err != nil {
 		writeNilIfNotPresent(stream)
 		stream.WriteMore()
@@ -554,7 +577,7 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	}
 	stream.WriteObjectEnd()
 	stream.Flush()
-	return nil
+	return nil, err
 }
 
 var nullAsBytes = []byte{110, 117, 108, 108}
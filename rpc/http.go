@@ -37,6 +37,7 @@ import (
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 )
 
 const (
@@ -251,6 +252,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
 	ctx = context.WithValue(ctx, "scheme", r.Proto)
 	ctx = context.WithValue(ctx, "local", r.Host)
+	ctx = tracing.Extract(ctx, r.Header) // spans of request continue caller's trace (`traceparent` header)
 	if ua := r.Header.Get("User-Agent"); ua != "" {
 		ctx = context.WithValue(ctx, "User-Agent", ua)
 	}
@@ -269,7 +271,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer codec.Close()
 	var stream *jsoniter.Stream
 	if !s.disableStreaming {
-		stream = jsoniter.NewStream(jsoniter.ConfigDefault, w, 4096)
+		stream = newCountingStream(w)
 	}
 	s.serveSingleRequest(ctx, codec, stream)
 }
@@ -301,7 +303,9 @@ func validateRequest(r *http.Request) (int, error) {
 	return http.StatusUnsupportedMediaType, err
 }
 
-func CheckJwtSecret(w http.ResponseWriter, r *http.Request, jwtSecret []byte) bool {
+// CheckJwtSecret checks that the request carries a fresh token signed with
+// any of the secrets.
+func CheckJwtSecret(w http.ResponseWriter, r *http.Request, jwtSecrets *JwtSecrets) bool {
 	var tokenStr string
 	// Check if JWT signature is correct
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
@@ -313,16 +317,26 @@ func CheckJwtSecret(w http.ResponseWriter, r *http.Request, jwtSecret []byte) bo
 		return false
 	}
 
-	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+	var (
+		token  *jwt.Token
+		claims jwt.RegisteredClaims
+		err    = errors.New("no JWT secrets")
+	)
+	for _, jwtSecret := range jwtSecrets.Get() {
+		keyFunc := func(token *jwt.Token) (interface{}, error) {
+			return jwtSecret, nil
+		}
+		claims = jwt.RegisteredClaims{}
+		// We explicitly set only HS256 allowed, and also disables the
+		// claim-check: the RegisteredClaims internally requires 'iat' to
+		// be no later than 'now', but we allow for a bit of drift.
+		token, err = jwt.ParseWithClaims(tokenStr, &claims, keyFunc,
+			jwt.WithValidMethods([]string{"HS256"}),
+			jwt.WithoutClaimsValidation())
+		if err == nil {
+			break
+		}
 	}
-	claims := jwt.RegisteredClaims{}
-	// We explicitly set only HS256 allowed, and also disables the
-	// claim-check: the RegisteredClaims internally requires 'iat' to
-	// be no later than 'now', but we allow for a bit of drift.
-	token, err := jwt.ParseWithClaims(tokenStr, &claims, keyFunc,
-		jwt.WithValidMethods([]string{"HS256"}),
-		jwt.WithoutClaimsValidation())
 
 	switch {
 	case err != nil:
@@ -17,11 +17,14 @@
 package rpc
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -129,3 +132,32 @@ func TestHTTPRespBodyUnlimited(t *testing.T) {
 		t.Fatalf("response has wrong length %d, want %d", len(r), respLength)
 	}
 }
+
+func TestCheckJwtSecret(t *testing.T) {
+	oldSecret, newSecret := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	check := func(secrets *JwtSecrets, secret []byte) int {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now())}).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := httptest.NewRequest(http.MethodPost, "http://url.com", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		if CheckJwtSecret(recorder, request, secrets) {
+			return http.StatusOK
+		}
+		return recorder.Code
+	}
+
+	secrets := NewJwtSecrets([][]byte{oldSecret})
+	confirmStatusCode(t, check(secrets, oldSecret), http.StatusOK)
+	confirmStatusCode(t, check(secrets, newSecret), http.StatusForbidden)
+
+	// rotation: both are accepted, then only the new one
+	secrets.Set([][]byte{oldSecret, newSecret})
+	confirmStatusCode(t, check(secrets, oldSecret), http.StatusOK)
+	confirmStatusCode(t, check(secrets, newSecret), http.StatusOK)
+	secrets.Set([][]byte{newSecret})
+	confirmStatusCode(t, check(secrets, oldSecret), http.StatusForbidden)
+	confirmStatusCode(t, check(secrets, newSecret), http.StatusOK)
+}
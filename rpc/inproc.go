@@ -28,7 +28,7 @@ func DialInProc(handler *Server, logger log.Logger) *Client {
 	initctx := context.Background()
 	c, _ := newClient(initctx, func(context.Context) (ServerCodec, error) {
 		p1, p2 := net.Pipe()
-		go handler.ServeCodec(NewCodec(p1), 0)
+		go handler.serveCodec(NewCodec(p1), transportInproc)
 		return NewCodec(p2), nil
 	}, logger)
 	return c
@@ -0,0 +1,28 @@
+package rpc
+
+import "sync/atomic"
+
+// JwtSecrets is the set of secrets the authenticated endpoint accepts tokens
+// signed with. Accepting several secrets lets consensus clients rotate theirs
+// without a simultaneous restart: the new secret is added next to the old one,
+// which is removed once every client has switched. The set can be replaced
+// while the endpoint is serving.
+type JwtSecrets struct {
+	secrets atomic.Pointer[[][]byte]
+}
+
+func NewJwtSecrets(secrets [][]byte) *JwtSecrets {
+	s := &JwtSecrets{}
+	s.Set(secrets)
+	return s
+}
+
+// Get returns the secrets currently accepted.
+func (s *JwtSecrets) Get() [][]byte {
+	return *s.secrets.Load()
+}
+
+// Set replaces the secrets accepted.
+func (s *JwtSecrets) Set(secrets [][]byte) {
+	s.secrets.Store(&secrets)
+}
@@ -17,9 +17,17 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
 
 	"github.com/ledgerwatch/erigon-lib/metrics"
 )
@@ -93,3 +101,165 @@ func newRPCServingTimerMS(method string, valid bool) metrics.Summary {
 
 	return metrics.GetOrCreateSummary(label)
 }
+
+const unknownMethod = "unknown" // label of methods not registered: don't let clients create labels
+
+var (
+	// upper bounds of buckets: latency from 1ms to 1min, payloads from 64b to 16mb
+	rpcDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	rpcSizeBuckets     = exponentialBuckets(64, 4, 10)
+
+	rpcMethodMetrics sync.Map // method -> *methodMetrics
+)
+
+func exponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// methodMetrics - histograms of JSON-RPC method, for SLO dashboards
+type methodMetrics struct {
+	duration     metrics.Histogram
+	requestSize  metrics.Histogram // bytes of params
+	responseSize metrics.Histogram // bytes of result or error, streamed results included
+}
+
+func getMethodMetrics(method string) *methodMetrics {
+	if m, ok := rpcMethodMetrics.Load(method); ok {
+		return m.(*methodMetrics)
+	}
+	m, _ := rpcMethodMetrics.LoadOrStore(method, &methodMetrics{
+		duration:     metrics.GetOrCreateHistogramWithBuckets(fmt.Sprintf(`rpc_request_duration_seconds{method="%s"}`, method), rpcDurationBuckets),
+		requestSize:  metrics.GetOrCreateHistogramWithBuckets(fmt.Sprintf(`rpc_request_size_bytes{method="%s"}`, method), rpcSizeBuckets),
+		responseSize: metrics.GetOrCreateHistogramWithBuckets(fmt.Sprintf(`rpc_response_size_bytes{method="%s"}`, method), rpcSizeBuckets),
+	})
+	return m.(*methodMetrics)
+}
+
+func observeCall(method string, start time.Time, requestSize, responseSize int, rpcErr *jsonError) {
+	m := getMethodMetrics(method)
+	m.duration.ObserveDuration(start)
+	m.requestSize.Observe(float64(requestSize))
+	m.responseSize.Observe(float64(responseSize))
+	if rpcErr != nil {
+		observeError(method, rpcErr)
+	}
+}
+
+func observeError(method string, rpcErr *jsonError) {
+	metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_errors_total{method="%s",class="%s"}`, method, errorClass(rpcErr))).Inc()
+}
+
+// errorClass - low-cardinality class of error by JSON-RPC code, for server errors - by message
+func errorClass(rpcErr *jsonError) string {
+	switch code := rpcErr.Code; {
+	case code == -32700:
+		return "parse"
+	case code == -32600:
+		return "invalid_request"
+	case code == -32601:
+		return "method_not_found"
+	case code == -32602:
+		return "invalid_params"
+	case code == 3:
+		return "reverted"
+	case code <= -38000 && code > -39000:
+		return "engine"
+	case code == defaultErrorCode:
+		switch {
+		case strings.Contains(rpcErr.Message, context.Canceled.Error()):
+			return "canceled"
+		case strings.Contains(rpcErr.Message, context.DeadlineExceeded.Error()), strings.Contains(rpcErr.Message, "timeout"):
+			return "timeout"
+		default:
+			return "server"
+		}
+	default:
+		return "other"
+	}
+}
+
+// responseSize - bytes of non-streamed response
+func responseSize(answer *jsonrpcMessage) int {
+	if answer == nil {
+		return 0
+	}
+	size := len(answer.Result)
+	if answer.Error != nil {
+		size += len(answer.Error.Message)
+	}
+	return size
+}
+
+// countingWriter - counts bytes flushed by stream, to measure streamed responses
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+func newCountingStream(w io.Writer) *jsoniter.Stream {
+	cw := &countingWriter{w: w}
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, cw, 4096)
+	stream.Attachment = cw
+	return stream
+}
+
+// streamedBytes - bytes written to stream: flushed (if counted) and buffered
+func streamedBytes(stream *jsoniter.Stream) int {
+	if stream == nil {
+		return 0
+	}
+	n := stream.Buffered()
+	if cw, ok := stream.Attachment.(*countingWriter); ok {
+		n += cw.n
+	}
+	return n
+}
+
+// transports of connections
+const (
+	transportHTTP   = "http"
+	transportWS     = "ws"
+	transportIPC    = "ipc"
+	transportInproc = "inproc"
+	transportOther  = "other"
+)
+
+var rpcConnectionBuckets = []float64{0.01, 0.1, 1, 10, 60, 300, 1800, 3600}
+
+// connOpened - counts connection of transport, returned func must be called when it's closed
+func connOpened(transport string) (closed func()) {
+	start := time.Now()
+	metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_connections_total{transport="%s"}`, transport)).Inc()
+	active := metrics.GetOrCreateGauge(fmt.Sprintf(`rpc_connections_active{transport="%s"}`, transport))
+	active.Inc()
+	return func() {
+		active.Dec()
+		metrics.GetOrCreateHistogramWithBuckets(fmt.Sprintf(`rpc_connection_duration_seconds{transport="%s"}`, transport), rpcConnectionBuckets).ObserveDuration(start)
+	}
+}
+
+var httpConns sync.Map // net.Conn -> func(), closing of connection
+
+// HTTPConnState - hook of http.Server (ConnState), counts connections of HTTP transport. Connections upgraded to
+// websocket are closed for HTTP and counted by WS transport.
+func HTTPConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		httpConns.Store(conn, connOpened(transportHTTP))
+	case http.StateClosed, http.StateHijacked:
+		if closed, ok := httpConns.LoadAndDelete(conn); ok {
+			closed.(func())()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+func TestErrorClass(t *testing.T) {
+	cases := []struct {
+		err   error
+		class string
+	}{
+		{&methodNotFoundError{method: "x_y"}, "method_not_found"},
+		{&InvalidParamsError{"bad"}, "invalid_params"},
+		{&invalidRequestError{"bad"}, "invalid_request"},
+		{&parseError{"bad"}, "parse"},
+		{&CustomError{Code: 3, Message: "execution reverted"}, "reverted"},
+		{&UnsupportedForkError{"bad"}, "engine"},
+		{context.Canceled, "canceled"},
+		{context.DeadlineExceeded, "timeout"},
+		{errors.New("header not found"), "server"},
+		{testError{}, "other"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.class, errorClass(errorMessage(c.err).Error), c.err.Error())
+	}
+}
+
+func TestStreamedBytes(t *testing.T) {
+	var buf bytes.Buffer
+	stream := newCountingStream(&buf)
+	stream.WriteString("abc")
+	require.Equal(t, 5, streamedBytes(stream))
+	require.NoError(t, stream.Flush())
+	stream.WriteString("de")
+	require.Equal(t, 9, streamedBytes(stream))
+	require.Equal(t, 0, streamedBytes(nil))
+}
+
+func TestMethodMetrics(t *testing.T) {
+	logger := log.New()
+	server := newTestServer(logger)
+	defer server.Stop()
+	client := DialInProc(server, logger)
+	defer client.Close()
+
+	errCount := func(method, class string) uint64 {
+		return metrics.GetOrCreateCounter(`rpc_errors_total{method="` + method + `",class="` + class + `"}`).GetValueUint64()
+	}
+	returnErrors, notFound := errCount("test_returnError", "other"), errCount(unknownMethod, "method_not_found")
+	active := metrics.GetOrCreateGauge(`rpc_connections_active{transport="inproc"}`)
+
+	var res echoResult
+	require.NoError(t, client.Call(&res, "test_echo", "x", 1))
+	require.Error(t, client.Call(nil, "test_returnError"))
+	require.Error(t, client.Call(nil, "test_noSuchMethod"))
+
+	require.Equal(t, returnErrors+1, errCount("test_returnError", "other"))
+	require.Equal(t, notFound+1, errCount(unknownMethod, "method_not_found"))
+	require.Same(t, getMethodMetrics("test_echo"), getMethodMetrics("test_echo"))
+	require.GreaterOrEqual(t, active.GetValue(), float64(1))
+}
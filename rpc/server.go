@@ -53,17 +53,18 @@ type Server struct {
 
 	batchConcurrency    uint
 	disableStreaming    bool
-	traceRequests       bool // Whether to print requests at INFO level
-	debugSingleRequest  bool // Whether to print requests at INFO level
-	batchLimit          int  // Maximum number of requests in a batch
+	traceRequests       bool         // Whether to print requests at INFO level
+	debugSingleRequest  bool         // Whether to print requests at INFO level
+	batchLimit          atomic.Int64 // Maximum number of requests in a batch, changed by config reload
 	logger              log.Logger
-	rpcSlowLogThreshold time.Duration
+	rpcSlowLogThreshold atomic.Int64 // time.Duration, changed by config reload
 }
 
 // NewServer creates a new server instance with no registered handlers.
 func NewServer(batchConcurrency uint, traceRequests, debugSingleRequest, disableStreaming bool, logger log.Logger, rpcSlowLogThreshold time.Duration) *Server {
 	server := &Server{services: serviceRegistry{logger: logger}, idgen: randomIDGenerator(), codecs: mapset.NewSet(), run: 1, batchConcurrency: batchConcurrency,
-		disableStreaming: disableStreaming, traceRequests: traceRequests, debugSingleRequest: debugSingleRequest, logger: logger, rpcSlowLogThreshold: rpcSlowLogThreshold}
+		disableStreaming: disableStreaming, traceRequests: traceRequests, debugSingleRequest: debugSingleRequest, logger: logger}
+	server.rpcSlowLogThreshold.Store(int64(rpcSlowLogThreshold))
 	// Register the default service providing meta information about the RPC service such
 	// as the services and methods it offers.
 	rpcService := &RPCService{server: server}
@@ -78,7 +79,12 @@ func (s *Server) SetAllowList(allowList AllowList) {
 
 // SetBatchLimit sets limit of number of requests in a batch
 func (s *Server) SetBatchLimit(limit int) {
-	s.batchLimit = limit
+	s.batchLimit.Store(int64(limit))
+}
+
+// SetSlowLogThreshold sets duration of request after which it's logged as slow, 0 - disabled
+func (s *Server) SetSlowLogThreshold(threshold time.Duration) {
+	s.rpcSlowLogThreshold.Store(int64(threshold))
 }
 
 // RegisterName creates a service for the given receiver type under the given name. When no
@@ -95,6 +101,11 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(codec, transportOther)
+}
+
+// serveCodec - ServeCodec with connection counted by transport
+func (s *Server) serveCodec(codec ServerCodec, transport string) {
 	defer codec.Close()
 
 	// Don't serve if server is stopped.
@@ -106,6 +117,9 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
+	closed := connOpened(transport)
+	defer closed()
+
 	c := initClient(codec, s.idgen, &s.services, s.logger)
 	<-codec.closed()
 	c.Close()
@@ -120,7 +134,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stre
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests, s.logger, s.rpcSlowLogThreshold)
+	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests, s.logger, time.Duration(s.rpcSlowLogThreshold.Load()))
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -132,8 +146,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stre
 		return
 	}
 	if batch {
-		if s.batchLimit > 0 && len(reqs) > s.batchLimit {
-			codec.WriteJSON(ctx, errorMessage(fmt.Errorf("batch limit %d exceeded (can increase by --rpc.batch.limit). Requested batch of size: %d", s.batchLimit, len(reqs))))
+		if batchLimit := int(s.batchLimit.Load()); batchLimit > 0 && len(reqs) > batchLimit {
+			codec.WriteJSON(ctx, errorMessage(fmt.Errorf("batch limit %d exceeded (can increase by --rpc.batch.limit). Requested batch of size: %d", batchLimit, len(reqs))))
 		} else {
 			h.handleBatch(reqs)
 		}
@@ -46,7 +46,7 @@ var wsBufferPool = new(sync.Pool)
 //
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
-func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, compression bool, logger log.Logger) http.Handler {
+func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecrets *JwtSecrets, compression bool, logger log.Logger) http.Handler {
 	upgrader := websocket.Upgrader{
 		EnableCompression: compression,
 		ReadBufferSize:    wsReadBuffer,
@@ -55,7 +55,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, com
 		CheckOrigin:       wsHandshakeValidator(allowedOrigins, logger),
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if jwtSecret != nil && !CheckJwtSecret(w, r, jwtSecret) {
+		if jwtSecrets != nil && !CheckJwtSecret(w, r, jwtSecrets) {
 			return
 		}
 		conn, err := upgrader.Upgrade(w, r, nil)
@@ -64,7 +64,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, com
 			return
 		}
 		codec := NewWebsocketCodec(conn)
-		s.ServeCodec(codec, 0)
+		s.serveCodec(codec, transportWS)
 	})
 }
 
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// blocksImporter - imports blocks of other clients (era1, rlp) into block snapshots: continues after last block in snapshots.
+// Blocks are staged in temporary db, then frozen by same code as `snapshots retire`
+type blocksImporter struct {
+	ctx         context.Context
+	logPrefix   string
+	dirs        datadir.Dirs
+	chainConfig *chain.Config
+	blockSnaps  *freezeblocks.RoSnapshots
+	blockReader *freezeblocks.BlockReader
+	logger      log.Logger
+
+	next       uint64 // next block to import
+	parent     libcommon.Hash
+	dumpFrom   uint64 // first staged block
+	freezeStep uint64 // during import staged blocks are frozen by full steps, rest - by finish
+
+	stagingDir string
+	staging    kv.RwDB
+}
+
+func newBlocksImporter(ctx context.Context, logPrefix string, dirs datadir.Dirs, chainConfig *chain.Config, logger log.Logger) (*blocksImporter, error) {
+	blockSnaps := freezeblocks.NewRoSnapshots(ethconfig.NewSnapCfg(true, false, true), dirs.Snap, 0, logger)
+	if err := blockSnaps.ReopenFolder(); err != nil {
+		blockSnaps.Close()
+		return nil, err
+	}
+	imp := &blocksImporter{
+		ctx: ctx, logPrefix: logPrefix, dirs: dirs, chainConfig: chainConfig,
+		blockSnaps: blockSnaps, blockReader: freezeblocks.NewBlockReader(blockSnaps, nil), logger: logger,
+		freezeStep: snaptype.Erigon2MergeLimit,
+	}
+	if frozen := imp.blockReader.FrozenBlocks(); frozen > 0 {
+		imp.next = frozen + 1
+		h, err := imp.blockReader.HeaderByNumber(ctx, nil, frozen)
+		if err != nil {
+			imp.close()
+			return nil, err
+		}
+		if h == nil {
+			imp.close()
+			return nil, fmt.Errorf("header %d not found in snapshots", frozen)
+		}
+		imp.parent = h.Hash()
+	}
+	imp.dumpFrom = imp.next
+	return imp, nil
+}
+
+func (imp *blocksImporter) close() {
+	imp.closeStaging()
+	imp.blockSnaps.Close()
+}
+
+// stage - writes next block into staging db
+func (imp *blocksImporter) stage(tx kv.RwTx, block *types.Block) error {
+	if err := rawdb.WriteCanonicalHash(tx, block.Hash(), block.NumberU64()); err != nil {
+		return err
+	}
+	if err := rawdb.WriteBlock(tx, block); err != nil {
+		return err
+	}
+	imp.next, imp.parent = block.NumberU64()+1, block.Hash()
+	return nil
+}
+
+// freezeSteps - freezes staged blocks of full freeze steps: keeps staging db small
+func (imp *blocksImporter) freezeSteps() error {
+	return imp.freeze(imp.next - imp.next%imp.freezeStep)
+}
+
+// finish - freezes staged blocks which are enough for smallest segment. Rest is not imported: node will sync them
+func (imp *blocksImporter) finish() error {
+	if err := imp.freeze(imp.next - imp.next%snaptype.Erigon2MinSegmentSize); err != nil {
+		return err
+	}
+	if imp.next > imp.dumpFrom {
+		imp.logger.Warn(fmt.Sprintf("[%s] not enough blocks for snapshot segment, not imported", imp.logPrefix), "from", imp.dumpFrom, "to", imp.next)
+	}
+	imp.logger.Info(fmt.Sprintf("[%s] import done", imp.logPrefix), "snapshots_to_block", imp.blockReader.FrozenBlocks())
+	return nil
+}
+
+func (imp *blocksImporter) openStaging() (err error) {
+	if imp.staging != nil {
+		return nil
+	}
+	if err := os.MkdirAll(imp.dirs.Tmp, 0o755); err != nil {
+		return err
+	}
+	if imp.stagingDir, err = os.MkdirTemp(imp.dirs.Tmp, imp.logPrefix+"-import-"); err != nil {
+		return err
+	}
+	imp.staging, err = mdbx.NewMDBX(imp.logger).Label(kv.ChainDB).Path(imp.stagingDir).Open(imp.ctx)
+	return err
+}
+
+func (imp *blocksImporter) closeStaging() {
+	if imp.staging != nil {
+		imp.staging.Close()
+		imp.staging = nil
+	}
+	if imp.stagingDir != "" {
+		os.RemoveAll(imp.stagingDir)
+		imp.stagingDir = ""
+	}
+}
+
+// freeze - builds snapshots of staged blocks [dumpFrom, to). Frozen blocks are removed from staging db: keeps it small.
+func (imp *blocksImporter) freeze(to uint64) error {
+	if to <= imp.dumpFrom {
+		return nil
+	}
+	imp.logger.Info(fmt.Sprintf("[%s] building snapshots", imp.logPrefix), "from", imp.dumpFrom, "to", to)
+	if err := freezeblocks.DumpBlocks(imp.ctx, imp.dumpFrom, to, imp.chainConfig, imp.dirs.Tmp, imp.dirs.Snap, imp.staging,
+		estimate.CompressSnapshot.Workers(), log.LvlInfo, imp.logger, imp.blockReader); err != nil {
+		return err
+	}
+	if err := imp.blockSnaps.ReopenFolder(); err != nil {
+		return err
+	}
+	if frozen := imp.blockReader.FrozenBlocks(); frozen+1 != to {
+		return fmt.Errorf("snapshots end at block %d after freezing, expected %d", frozen, to-1)
+	}
+
+	imp.dumpFrom = to
+	return imp.staging.Update(imp.ctx, func(tx kv.RwTx) error {
+		return rawdb.PruneBlocks(tx, to, math.MaxInt32)
+	})
+}
+
+// verifyBlockRoots - verifies block's body by roots in header
+func verifyBlockRoots(block *types.Block) error {
+	num := block.NumberU64()
+	if hash := types.DeriveSha(block.Transactions()); hash != block.TxHash() {
+		return fmt.Errorf("block %d: transactions root %x, expected %x", num, hash, block.TxHash())
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != block.UncleHash() {
+		return fmt.Errorf("block %d: uncles hash %x, expected %x", num, hash, block.UncleHash())
+	}
+	if block.WithdrawalsHash() != nil {
+		if hash := types.DeriveSha(block.Withdrawals()); hash != *block.WithdrawalsHash() {
+			return fmt.Errorf("block %d: withdrawals root %x, expected %x", num, hash, *block.WithdrawalsHash())
+		}
+	}
+	return nil
+}
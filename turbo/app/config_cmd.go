@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	cli2 "github.com/ledgerwatch/erigon/turbo/cli"
+)
+
+var configCommand = cli.Command{
+	Name:  "config",
+	Usage: "Config file (--config) utilities",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "check",
+			Action:    checkConfig,
+			Usage:     "Validate config file without starting the node: unknown flags and invalid values are reported",
+			ArgsUsage: "<configPath>",
+			Flags: []cli.Flag{
+				&utils.ConfigFlag,
+			},
+			Description: `
+Config file is .toml or .yaml with flags as keys, blocks of it give prefix to names of flags:
+'[prune]' block with 'receipts = "older=90000"' is '--prune.receipts=older=90000'.
+Running node re-reads it on SIGHUP or admin_reloadConfig: changes of reloadable flags (log verbosity, rpc limits,
+prune schedule, torrent rates) are applied without restart.`,
+		},
+	},
+}
+
+func checkConfig(cliCtx *cli.Context) error {
+	configPath := cliCtx.String(utils.ConfigFlag.Name)
+	if configPath == "" {
+		configPath = cliCtx.Args().First()
+	}
+	if configPath == "" {
+		return fmt.Errorf("usage: erigon config check <configPath>")
+	}
+	if err := cli2.ValidateConfigFile(configPath, cliCtx.App.Flags); err != nil {
+		return cli.Exit(fmt.Sprintf("%s is invalid:\n%s", configPath, err), 1)
+	}
+	fmt.Printf("%s is valid\n", configPath)
+	return nil
+}
@@ -0,0 +1,337 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cmd/hack/tool/fromdb"
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/core/era1"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/debug"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var importEraCommand = cli.Command{
+	Action:    doImportEra,
+	Name:      "import-era",
+	Usage:     "Import pre-merge history from era1 files into block snapshots",
+	ArgsUsage: "<dir with era1 files>",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&utils.ChainFlag,
+	}),
+	Description: `
+Builds headers, bodies and transactions snapshots from era1 files (geth/nimbus history archives)
+of --chain network: <network>-<epoch>-<root>.era1. Import continues after last block in snapshots,
+epochs must be consecutive. Every block is verified: hashes chain, transactions/uncles/receipts roots,
+total difficulty and accumulator root of each file. Receipts are only verified: erigon re-executes blocks.
+Blocks after last full 1K-blocks segment are not imported - node will sync them.
+Built files are not in chain's preverified list: use --no-downloader for chains with public snapshots.`,
+}
+
+var exportEraCommand = cli.Command{
+	Action:    doExportEra,
+	Name:      "export-era",
+	Usage:     "Export pre-merge history (headers, bodies, receipts) into era1 files",
+	ArgsUsage: "<output dir>",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&SnapshotFromFlag,
+		&SnapshotToFlag,
+	}),
+	Description: `
+Writes era1 files of 8192 blocks starting from epoch of --from block. Stops at --to block (default: executed blocks)
+or at first post-merge block: last file may be shorter. Receipts are read from db: node must keep receipts (no --prune.r).`,
+}
+
+func doImportEra(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	if cliCtx.NArg() < 1 {
+		return fmt.Errorf("dir with era1 files is required")
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	chainName := cliCtx.String(utils.ChainFlag.Name)
+	chainConfig := params.ChainConfigByChainName(chainName)
+	if chainConfig == nil {
+		return fmt.Errorf("unknown chain: %s", chainName)
+	}
+	files, err := era1.ReadDir(cliCtx.Args().First(), chainName)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no era1 files of %s in %s", chainName, cliCtx.Args().First())
+	}
+
+	blocks, err := newBlocksImporter(ctx, "era1", dirs, chainConfig, logger)
+	if err != nil {
+		return err
+	}
+	defer blocks.close()
+	return importEra(&eraImporter{blocksImporter: blocks, chainName: chainName}, files)
+}
+
+func importEra(imp *eraImporter, files []string) error {
+	imp.logger.Info("[era1] import", "files", len(files), "from_block", imp.next)
+	for _, path := range files {
+		if err := imp.importFile(path); err != nil {
+			return err
+		}
+	}
+	return imp.finish()
+}
+
+// eraImporter - verifies blocks by era1 data: total difficulty, receipts and accumulator root
+type eraImporter struct {
+	*blocksImporter
+	chainName string
+	td        *big.Int
+}
+
+func (imp *eraImporter) importFile(path string) error {
+	e, err := era1.Open(path)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	if e.Start()+e.Count() <= imp.next {
+		return nil // already in snapshots
+	}
+	if e.Start() > imp.next {
+		return fmt.Errorf("%s: starts at block %d, expected file with block %d", filepath.Base(path), e.Start(), imp.next)
+	}
+
+	hashes := make([]libcommon.Hash, e.Count())
+	tds := make([]*big.Int, e.Count())
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+	if err := imp.openStaging(); err != nil {
+		return err
+	}
+	if err := imp.staging.Update(imp.ctx, func(tx kv.RwTx) error {
+		for num := e.Start(); num < e.Start()+e.Count(); num++ {
+			block, receipts, td, err := e.GetBlockByNumber(num)
+			if err != nil {
+				return err
+			}
+			hashes[num-e.Start()], tds[num-e.Start()] = block.Hash(), td
+			if num < imp.next {
+				imp.parent, imp.td = block.Hash(), td
+				continue
+			}
+			if err := imp.verify(block, receipts, td); err != nil {
+				return fmt.Errorf("%s: %w", filepath.Base(path), err)
+			}
+			if err := imp.stage(tx, block); err != nil {
+				return err
+			}
+			if err := rawdb.WriteTd(tx, block.Hash(), num, td); err != nil {
+				return err
+			}
+			imp.td = td
+
+			select {
+			case <-imp.ctx.Done():
+				return imp.ctx.Err()
+			case <-logEvery.C:
+				imp.logger.Info("[era1] importing", "file", filepath.Base(path), "block", num)
+			default:
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	root, err := e.Accumulator()
+	if err != nil {
+		return err
+	}
+	expected, err := era1.ComputeAccumulator(hashes, tds)
+	if err != nil {
+		return err
+	}
+	if root != expected {
+		return fmt.Errorf("%s: accumulator mismatch: %x, computed %x", filepath.Base(path), root, expected)
+	}
+	if _, epoch, _ := era1.ParseFilename(path); filepath.Base(path) != era1.Filename(imp.chainName, epoch, root) {
+		return fmt.Errorf("%s: file name doesn't match accumulator root %x", filepath.Base(path), root)
+	}
+
+	return imp.freezeSteps()
+}
+
+func (imp *eraImporter) verify(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	num := block.NumberU64()
+	if num == 0 {
+		if genesis := params.GenesisHashByChainName(imp.chainName); genesis != nil && *genesis != block.Hash() {
+			return fmt.Errorf("genesis mismatch: %x, expected %x", block.Hash(), *genesis)
+		}
+	} else if block.ParentHash() != imp.parent {
+		return fmt.Errorf("block %d: parent hash %x, expected %x", num, block.ParentHash(), imp.parent)
+	}
+	if imp.td != nil {
+		if expected := new(big.Int).Add(imp.td, block.Difficulty()); expected.Cmp(td) != 0 {
+			return fmt.Errorf("block %d: total difficulty %d, expected %d", num, td, expected)
+		}
+	}
+	if err := verifyBlockRoots(block); err != nil {
+		return err
+	}
+	if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+		return fmt.Errorf("block %d: receipts root %x, expected %x", num, hash, block.ReceiptHash())
+	}
+	return nil
+}
+
+func doExportEra(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	if cliCtx.NArg() < 1 {
+		return fmt.Errorf("output dir is required")
+	}
+	outDir := cliCtx.Args().First()
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	from, to := cliCtx.Uint64(SnapshotFromFlag.Name), cliCtx.Uint64(SnapshotToFlag.Name)
+
+	db := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer db.Close()
+	chainConfig := fromdb.ChainConfig(db)
+
+	blockSnaps := freezeblocks.NewRoSnapshots(ethconfig.NewSnapCfg(true, false, true), dirs.Snap, 0, logger)
+	if err := blockSnaps.ReopenFolder(); err != nil {
+		return err
+	}
+	defer blockSnaps.Close()
+	blockReader := freezeblocks.NewBlockReader(blockSnaps, nil)
+
+	if to == 0 {
+		if err := db.View(ctx, func(tx kv.Tx) error {
+			to, err = stages.GetStageProgress(tx, stages.Execution)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+	for epoch := from / era1.MaxEraSize; epoch*era1.MaxEraSize <= to; epoch++ {
+		var merged bool
+		var name string
+		if err := db.View(ctx, func(tx kv.Tx) (err error) {
+			name, merged, err = exportEraEpoch(ctx, tx, blockReader, chainConfig.ChainName, epoch, to, outDir, logEvery, logger)
+			return err
+		}); err != nil {
+			return err
+		}
+		if name != "" {
+			logger.Info("[era1] exported", "file", name)
+		}
+		if merged {
+			break
+		}
+	}
+	return nil
+}
+
+// exportEraEpoch - writes blocks of epoch until `to` block or first post-merge block. Returns file name, empty if nothing written.
+func exportEraEpoch(ctx context.Context, tx kv.Tx, blockReader *freezeblocks.BlockReader, network string, epoch, to uint64, outDir string,
+	logEvery *time.Ticker, logger log.Logger) (name string, merged bool, err error) {
+	tmpPath := filepath.Join(outDir, fmt.Sprintf("%s-%05d%s.tmp", network, epoch, era1.Ext))
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	defer os.Remove(tmpPath) // no-op after rename
+
+	b := era1.NewBuilder(f)
+	var added int
+	for num := epoch * era1.MaxEraSize; num < (epoch+1)*era1.MaxEraSize && num <= to; num++ {
+		hash, err := blockReader.CanonicalHash(ctx, tx, num)
+		if err != nil {
+			return "", false, err
+		}
+		block, senders, err := blockReader.BlockWithSenders(ctx, tx, hash, num)
+		if err != nil {
+			return "", false, err
+		}
+		if block == nil {
+			return "", false, fmt.Errorf("block %d not found", num)
+		}
+		if block.Difficulty().Sign() == 0 {
+			merged = true
+			break
+		}
+		td, err := rawdb.ReadTd(tx, hash, num)
+		if err != nil {
+			return "", false, err
+		}
+		if td == nil {
+			return "", false, fmt.Errorf("total difficulty of block %d not found", num)
+		}
+		receipts := rawdb.ReadReceipts(tx, block, senders)
+		if receipts == nil && block.Transactions().Len() > 0 {
+			return "", false, fmt.Errorf("receipts of block %d not found: node must keep receipts", num)
+		}
+		for _, r := range receipts {
+			r.Bloom = types.CreateBloom(types.Receipts{r})
+		}
+		if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+			return "", false, fmt.Errorf("block %d: receipts root %x, expected %x", num, hash, block.ReceiptHash())
+		}
+		if err := b.Add(block, receipts, td); err != nil {
+			return "", false, err
+		}
+		added++
+
+		select {
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		case <-logEvery.C:
+			logger.Info("[era1] exporting", "epoch", epoch, "block", num)
+		default:
+		}
+	}
+	if added == 0 {
+		return "", merged, nil
+	}
+	root, err := b.Finalize()
+	if err != nil {
+		return "", false, err
+	}
+	if err := f.Sync(); err != nil {
+		return "", false, err
+	}
+	name = era1.Filename(network, epoch, root)
+	if err := os.Rename(tmpPath, filepath.Join(outDir, name)); err != nil {
+		return "", false, err
+	}
+	return name, merged, nil
+}
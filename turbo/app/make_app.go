@@ -19,6 +19,7 @@ import (
 	"github.com/ledgerwatch/erigon/params"
 	cli2 "github.com/ledgerwatch/erigon/turbo/cli"
 	"github.com/ledgerwatch/erigon/turbo/debug"
+	"github.com/ledgerwatch/erigon/turbo/reload"
 )
 
 // MakeApp creates a cli application (based on `github.com/urlfave/cli` package).
@@ -44,10 +45,23 @@ func MakeApp(name string, action cli.ActionFunc, cliFlags []cli.Flag) *cli.App {
 		// handle case: config flag
 		configFilePath := context.String(utils.ConfigFlag.Name)
 		if configFilePath != "" {
+			if err := cli2.ValidateConfigFile(configFilePath, context.App.Flags); err != nil {
+				log.Error("invalid config file", "err", err)
+				return err
+			}
+			reloader := cli2.NewConfigReloader(context, configFilePath, log.Root())
 			if err := cli2.SetFlagsFromConfigFile(context, configFilePath); err != nil {
 				log.Error("failed setting config flags from yaml/toml file", "err", err)
 				return err
 			}
+			reload.SetReloader(reloader.Reload)
+			go reloader.ListenReload(context.Context)
+		}
+
+		// handle case: node profile flag, after config file - it may set the profile
+		if err := cli2.SetFlagsFromNodeProfile(context); err != nil {
+			log.Error("failed setting node profile flags", "err", err)
+			return err
 		}
 
 		// run default action
@@ -63,8 +77,13 @@ func MakeApp(name string, action cli.ActionFunc, cliFlags []cli.Flag) *cli.App {
 	app.Commands = []*cli.Command{
 		&initCommand,
 		&importCommand,
+		&importEraCommand,
+		&exportEraCommand,
+		&importRlpCommand,
+		&exportRlpCommand,
 		&snapshotCommand,
 		&supportCommand,
+		&configCommand,
 		//&backupCommand,
 	}
 	return app
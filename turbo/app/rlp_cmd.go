@@ -0,0 +1,282 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/debug"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+const rlpImportBatchSize = 10_000
+
+var importRlpCommand = cli.Command{
+	Action:    doImportRlp,
+	Name:      "import-rlp",
+	Usage:     "Import blocks from RLP files (geth/nethermind export) into block snapshots",
+	ArgsUsage: "<filename> (<filename 2> ... <filename N>)",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&utils.ChainFlag,
+	}),
+	Description: `
+Builds headers, bodies and transactions snapshots from files with RLP-encoded blocks of --chain network
+(for example produced by 'geth export'), *.gz files are decompressed. Import continues after last block in snapshots:
+files must be passed in order of blocks, blocks already in snapshots are skipped. Blocks are verified: hashes chain,
+transactions/uncles/withdrawals roots. Blocks are not executed: node executes them after start.
+Blocks after last full 1K-blocks segment are not imported - node will sync them (or use 'import' command).
+Built files are not in chain's preverified list: use --no-downloader for chains with public snapshots.`,
+}
+
+var exportRlpCommand = cli.Command{
+	Action:    doExportRlp,
+	Name:      "export-rlp",
+	Usage:     "Export blocks from snapshots into RLP file (importable by geth/nethermind)",
+	ArgsUsage: "<output file>",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&SnapshotFromFlag,
+		&SnapshotToFlag,
+	}),
+	Description: `
+Writes RLP-encoded blocks [--from, --to] (default --to: last block in snapshots) into file in format of
+'geth export' and 'erigon import'. File with *.gz suffix is compressed. Only blocks in snapshots are exported:
+works without chaindata, and alongside of running node.`,
+}
+
+func doExportRlp(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	if cliCtx.NArg() < 1 {
+		return fmt.Errorf("output file is required")
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	return exportRlp(ctx, dirs, cliCtx.Uint64(SnapshotFromFlag.Name), cliCtx.Uint64(SnapshotToFlag.Name), cliCtx.Args().First(), logger)
+}
+
+// exportRlp - writes blocks [from, to] of snapshots into `fileName`, to=0 - last block in snapshots
+func exportRlp(ctx context.Context, dirs datadir.Dirs, from, to uint64, fileName string, logger log.Logger) error {
+	blockSnaps := freezeblocks.NewRoSnapshots(ethconfig.NewSnapCfg(true, false, true), dirs.Snap, 0, logger)
+	if err := blockSnaps.ReopenFolder(); err != nil {
+		return err
+	}
+	defer blockSnaps.Close()
+	blockReader := freezeblocks.NewBlockReader(blockSnaps, nil)
+
+	frozen := blockReader.FrozenBlocks()
+	if frozen == 0 {
+		return fmt.Errorf("no blocks in snapshots: %s", dirs.Snap)
+	}
+	if to == 0 {
+		to = frozen
+	}
+	if to > frozen {
+		return fmt.Errorf("block %d is not in snapshots, last block in snapshots: %d", to, frozen)
+	}
+	if from > to {
+		return fmt.Errorf("--from %d is greater than --to %d", from, to)
+	}
+
+	tmpPath := fileName + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer os.Remove(tmpPath) // no-op after rename
+
+	bw := bufio.NewWriterSize(f, 4*1024*1024)
+	var w io.Writer = bw
+	var gw *gzip.Writer
+	if strings.HasSuffix(fileName, ".gz") {
+		gw = gzip.NewWriter(bw)
+		w = gw
+	}
+
+	logger.Info("[rlp] export", "from", from, "to", to, "file", fileName)
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+	for num := from; num <= to; num++ {
+		header, err := blockReader.HeaderByNumber(ctx, nil, num)
+		if err != nil {
+			return err
+		}
+		if header == nil {
+			return fmt.Errorf("header %d not found in snapshots", num)
+		}
+		block, _, err := blockReader.BlockWithSenders(ctx, nil, header.Hash(), num)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return fmt.Errorf("block %d not found in snapshots", num)
+		}
+		if err := block.EncodeRLP(w); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-logEvery.C:
+			logger.Info("[rlp] exporting", "block", num, "to", to)
+		default:
+		}
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, fileName); err != nil {
+		return err
+	}
+	logger.Info("[rlp] export done", "blocks", to-from+1, "file", fileName)
+	return nil
+}
+
+func doImportRlp(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	if cliCtx.NArg() < 1 {
+		return fmt.Errorf("file with RLP-encoded blocks is required")
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	chainName := cliCtx.String(utils.ChainFlag.Name)
+	chainConfig := params.ChainConfigByChainName(chainName)
+	if chainConfig == nil {
+		return fmt.Errorf("unknown chain: %s", chainName)
+	}
+
+	blocks, err := newBlocksImporter(ctx, "rlp", dirs, chainConfig, logger)
+	if err != nil {
+		return err
+	}
+	defer blocks.close()
+	return importRlp(&rlpImporter{blocksImporter: blocks, chainName: chainName}, cliCtx.Args().Slice())
+}
+
+func importRlp(imp *rlpImporter, files []string) error {
+	imp.logger.Info("[rlp] import", "files", len(files), "from_block", imp.next)
+	for _, path := range files {
+		if err := imp.importFile(path); err != nil {
+			return err
+		}
+	}
+	return imp.finish()
+}
+
+type rlpImporter struct {
+	*blocksImporter
+	chainName string
+}
+
+func (imp *rlpImporter) importFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReaderSize(f, 4*1024*1024)
+	if strings.HasSuffix(path, ".gz") {
+		if r, err = gzip.NewReader(r); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(r, 0)
+	if err := imp.openStaging(); err != nil {
+		return err
+	}
+
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+	for eof := false; !eof; {
+		if err := imp.staging.Update(imp.ctx, func(tx kv.RwTx) error {
+			for i := 0; i < rlpImportBatchSize; i++ {
+				var block types.Block
+				if err := stream.Decode(&block); errors.Is(err, io.EOF) {
+					eof = true
+					return nil
+				} else if err != nil {
+					return fmt.Errorf("%s: block after %d: %w", filepath.Base(path), imp.next, err)
+				}
+				if block.NumberU64() < imp.next {
+					// already in snapshots: only check that file is of same chain
+					if block.NumberU64()+1 == imp.next && block.Hash() != imp.parent {
+						return fmt.Errorf("%s: block %d hash %x, in snapshots %x", filepath.Base(path), block.NumberU64(), block.Hash(), imp.parent)
+					}
+					continue
+				}
+				if err := imp.verify(&block); err != nil {
+					return fmt.Errorf("%s: %w", filepath.Base(path), err)
+				}
+				if err := imp.stage(tx, &block); err != nil {
+					return err
+				}
+
+				select {
+				case <-imp.ctx.Done():
+					return imp.ctx.Err()
+				case <-logEvery.C:
+					imp.logger.Info("[rlp] importing", "file", filepath.Base(path), "block", block.NumberU64())
+				default:
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := imp.freezeSteps(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *rlpImporter) verify(block *types.Block) error {
+	num := block.NumberU64()
+	if num > imp.next {
+		return fmt.Errorf("block %d: expected block %d, files must be passed in order of blocks", num, imp.next)
+	}
+	if num == 0 {
+		if genesis := params.GenesisHashByChainName(imp.chainName); genesis != nil && *genesis != block.Hash() {
+			return fmt.Errorf("genesis mismatch: %x, expected %x", block.Hash(), *genesis)
+		}
+	} else if block.ParentHash() != imp.parent {
+		return fmt.Errorf("block %d: parent hash %x, expected %x", num, block.ParentHash(), imp.parent)
+	}
+	return verifyBlockRoots(block)
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/chain/networkname"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/era1"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+)
+
+// importTestFreezeStep - instead of 100K blocks of real chains
+const importTestFreezeStep = 2_000
+
+// createImportTestChain - pre-merge chain of blocks [0, 3500), with `txs` every 10th block has a transaction
+func createImportTestChain(t *testing.T, txs bool) (*mock.MockSentry, []*types.Block) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	gspec := &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	m := mock.MockWithGenesisPruneMode(t, gspec, key, 3500, prune.DefaultMode, false)
+	signer := types.LatestSigner(gspec.Config)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3499, func(i int, b *core.BlockGen) {
+		if !txs || i%10 != 0 {
+			return
+		}
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), libcommon.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	return m, append([]*types.Block{m.Genesis}, chain.Blocks...)
+}
+
+func encodeBlocks(t *testing.T, blocks []*types.Block) []byte {
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		require.NoError(t, block.EncodeRLP(&buf))
+	}
+	return buf.Bytes()
+}
+
+func TestRlpExportImport(t *testing.T) {
+	m, blocks := createImportTestChain(t, true)
+	ctx, logger := m.Ctx, m.Log
+	filesDir := t.TempDir()
+	// files of other client, like `geth export`
+	writeFile := func(name string, blocks []*types.Block) string {
+		path := filepath.Join(filesDir, name)
+		require.NoError(t, os.WriteFile(path, encodeBlocks(t, blocks), 0o644))
+		return path
+	}
+	file1, file2 := writeFile("1.rlp", blocks[:2500]), writeFile("2.rlp", blocks[2500:])
+
+	newImporter := func(dirs datadir.Dirs, chainName string) *rlpImporter {
+		blocks, err := newBlocksImporter(ctx, "rlp", dirs, m.ChainConfig, logger)
+		require.NoError(t, err)
+		t.Cleanup(blocks.close)
+		blocks.freezeStep = importTestFreezeStep
+		return &rlpImporter{blocksImporter: blocks, chainName: chainName}
+	}
+	exported := func(dirs datadir.Dirs, name string) []byte {
+		path := filepath.Join(filesDir, name)
+		require.NoError(t, exportRlp(ctx, dirs, 0, 0, path, logger))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return data
+	}
+
+	dirs := datadir.New(t.TempDir())
+	imp := newImporter(dirs, "")
+	require.NoError(t, imp.importFile(file1))
+	require.Equal(t, uint64(importTestFreezeStep-1), imp.blockReader.FrozenBlocks()) // full freeze step is frozen during import
+	require.NoError(t, imp.importFile(file2))
+	require.NoError(t, imp.finish())
+	require.Equal(t, uint64(2999), imp.blockReader.FrozenBlocks()) // blocks of not full 1K segment aren't imported
+	require.Equal(t, encodeBlocks(t, blocks[:3000]), exported(dirs, "exported.rlp"))
+
+	// import continues after last block in snapshots: already imported blocks are skipped
+	imp = newImporter(dirs, "")
+	require.NoError(t, importRlp(imp, []string{file1, file2}))
+	require.Equal(t, uint64(2999), imp.blockReader.FrozenBlocks())
+	imp.close()
+
+	// compressed export is importable
+	gzExported := filepath.Join(filesDir, "exported.rlp.gz")
+	require.NoError(t, exportRlp(ctx, dirs, 0, 0, gzExported, logger))
+	gzDirs := datadir.New(t.TempDir())
+	require.NoError(t, importRlp(newImporter(gzDirs, ""), []string{gzExported}))
+	require.Equal(t, encodeBlocks(t, blocks[:3000]), exported(gzDirs, "gz-exported.rlp"))
+
+	// file of other chain
+	err := importRlp(newImporter(datadir.New(t.TempDir()), networkname.MainnetChainName), []string{file1})
+	require.ErrorContains(t, err, "genesis mismatch")
+
+	// files not in order of blocks
+	err = importRlp(newImporter(datadir.New(t.TempDir()), ""), []string{file2, file1})
+	require.ErrorContains(t, err, "files must be passed in order")
+
+	// block of fork
+	fork, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 11, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(libcommon.Address{2})
+	})
+	require.NoError(t, err)
+	forked := writeFile("forked.rlp", append(blocks[:11:11], fork.Blocks[10]))
+	err = importRlp(newImporter(datadir.New(t.TempDir()), ""), []string{forked})
+	require.ErrorContains(t, err, "block 11: parent hash")
+}
+
+func TestEraExportImport(t *testing.T) {
+	m, blocks := createImportTestChain(t, false) // receipts aren't kept in db of HistoryV3
+	ctx, logger := m.Ctx, m.Log
+	eraDir := t.TempDir()
+
+	tx, err := m.DB.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	logEvery := time.NewTicker(time.Minute)
+	defer logEvery.Stop()
+	name, merged, err := exportEraEpoch(ctx, tx, m.BlockReader.(*freezeblocks.BlockReader), "era-test", 0, 3499, eraDir, logEvery, logger)
+	require.NoError(t, err)
+	require.False(t, merged)
+	require.NotEmpty(t, name)
+
+	files, err := era1.ReadDir(eraDir, "era-test")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	dirs := datadir.New(t.TempDir())
+	blocksImp, err := newBlocksImporter(ctx, "era1", dirs, m.ChainConfig, logger)
+	require.NoError(t, err)
+	defer blocksImp.close()
+	blocksImp.freezeStep = importTestFreezeStep
+	require.NoError(t, importEra(&eraImporter{blocksImporter: blocksImp, chainName: "era-test"}, files))
+	require.Equal(t, uint64(2999), blocksImp.blockReader.FrozenBlocks())
+
+	exported := filepath.Join(t.TempDir(), "exported.rlp")
+	require.NoError(t, exportRlp(ctx, dirs, 0, 0, exported, logger))
+	data, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	require.Equal(t, encodeBlocks(t, blocks[:3000]), data)
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -31,14 +34,18 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	"github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/downloader"
+	"github.com/ledgerwatch/erigon-lib/downloader/downloadercfg"
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcfg"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
 	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
 	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/recsplit"
 	"github.com/ledgerwatch/erigon-lib/seg"
 	libstate "github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/erigon-lib/tiering"
 	"github.com/ledgerwatch/erigon/cmd/hack/tool/fromdb"
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/core/rawdb"
@@ -48,6 +55,7 @@ import (
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
 	"github.com/ledgerwatch/erigon/eth/integrity"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/params"
 	erigoncli "github.com/ledgerwatch/erigon/turbo/cli"
@@ -86,6 +94,8 @@ var snapshotCommand = cli.Command{
 				&utils.DataDirFlag,
 				&SnapshotFromFlag,
 				&SnapshotRebuildFlag,
+				&SnapshotIndexOnlyFlag,
+				&erigoncli.IndexWorkersFlag,
 			}),
 		},
 		{
@@ -99,6 +109,21 @@ var snapshotCommand = cli.Command{
 				&SnapshotEveryFlag,
 			}),
 		},
+		{
+			Name:   "create",
+			Action: doCreateCommand,
+			Usage:  "Freeze blocks and state of (private) chain, build indices, .torrent files and manifests, optionally upload them to webseed",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&SnapshotFromFlag,
+				&SnapshotToFlag,
+				&SnapshotEveryFlag,
+				&SnapshotReleaseAllFlag,
+				&SnapshotReleaseKeyFlag,
+				&SnapshotReleaseV2RootsFlag,
+				&erigoncli.UploadLocationFlag,
+			}),
+		},
 		{
 			Name:   "uploader",
 			Action: doUploaderCommand,
@@ -139,7 +164,15 @@ var snapshotCommand = cli.Command{
 		{
 			Name:   "compress",
 			Action: doCompress,
-			Flags:  joinFlags([]cli.Flag{&utils.DataDirFlag}),
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&CompressMinPatternScoreFlag,
+				&CompressMaxPatternLenFlag,
+				&CompressSamplingFactorFlag,
+				&CompressDictSizeFlag,
+				&CompressDictInFlag,
+				&CompressDictOutFlag,
+			}),
 		},
 		{
 			Name:   "decompress-speed",
@@ -256,6 +289,37 @@ var snapshotCommand = cli.Command{
 				&utils.DataDirFlag,
 			}),
 		},
+		{
+			Name:      "pin",
+			Action:    doPin,
+			Usage:     "Keep frozen files on local disk regardless of cold storage tier policy. Files in cold tier are moved back: now if --snap.cold.dir is set, otherwise by running node",
+			ArgsUsage: "<file name>...",
+			Flags:     joinFlags([]cli.Flag{&utils.DataDirFlag, &utils.SnapColdDirFlag}),
+		},
+		{
+			Name:      "unpin",
+			Action:    doUnpin,
+			Usage:     "Frozen files are moved to cold storage tier by policy again",
+			ArgsUsage: "<file name>...",
+			Flags:     joinFlags([]cli.Flag{&utils.DataDirFlag}),
+		},
+		{
+			Name:   "tiers",
+			Action: doTiers,
+			Usage:  "List frozen files with their storage tier (local or cold) and pins",
+			Flags:  joinFlags([]cli.Flag{&utils.DataDirFlag}),
+		},
+		{
+			Name:   "reindex-logs",
+			Action: doReindexLogs,
+			Usage:  "Restore pruned logs of addresses added to --prune.logs.keep from receipts snapshots and add them to logs index",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&erigoncli.PruneLogsKeepFlag,
+				&SnapshotFromFlag,
+				&SnapshotToFlag,
+			}),
+		},
 		//{
 		//	Name:   "bodies_decrement_datafix",
 		//	Action: doBodiesDecrement,
@@ -286,6 +350,50 @@ var (
 		Name:  "rebuild",
 		Usage: "Force rebuild",
 	}
+	SnapshotIndexOnlyFlag = cli.StringSliceFlag{
+		Name:  "only",
+		Usage: "Re-build indices only of given files (and indices which depend on them): --only=v1-000000-000500-bodies.seg",
+	}
+	SnapshotReleaseAllFlag = cli.BoolFlag{
+		Name:  "all",
+		Usage: "Publish all frozen files. By default only files of merge-limit size (500K blocks) are published - short private chains may have no such files",
+	}
+	SnapshotReleaseKeyFlag = cli.PathFlag{
+		Name:  "release.key",
+		Usage: "Sign preverified.toml by ed25519 key from this file (created if not exists). Nodes trust webseed by --downloader.release.keys=<public key>",
+	}
+	SnapshotReleaseV2RootsFlag = cli.BoolFlag{
+		Name:  "v2",
+		Usage: "Add BitTorrent v2 pieces roots to preverified.toml",
+	}
+	CompressMinPatternScoreFlag = cli.Uint64Flag{
+		Name:  "compress.min.pattern.score",
+		Usage: "Minimum score (per superstring) of pattern to be included into dictionary",
+		Value: seg.DefaultCfg.MinPatternScore,
+	}
+	CompressMaxPatternLenFlag = cli.IntFlag{
+		Name:  "compress.max.pattern.len",
+		Usage: "Maximum length of dictionary pattern",
+		Value: seg.DefaultCfg.MaxPatternLen,
+	}
+	CompressSamplingFactorFlag = cli.Uint64Flag{
+		Name:  "compress.sampling",
+		Usage: "Only 1 of N superstrings is used to train dictionary. Lower - better ratio, slower build",
+		Value: seg.DefaultCfg.SamplingFactor,
+	}
+	CompressDictSizeFlag = cli.IntFlag{
+		Name:  "compress.dict.size",
+		Usage: "Maximum amount of patterns in dictionary. Larger - better ratio, more RAM to decompress",
+		Value: seg.DefaultCfg.MaxDictPatterns,
+	}
+	CompressDictInFlag = cli.StringFlag{
+		Name:  "compress.dict.in",
+		Usage: "Use pre-trained dictionary from file (see --compress.dict.out) instead of training: faster build of files of same snapshot type",
+	}
+	CompressDictOutFlag = cli.StringFlag{
+		Name:  "compress.dict.out",
+		Usage: "Save dictionary to file: to re-use it by --compress.dict.in",
+	}
 )
 
 func doBtSearch(cliCtx *cli.Context) error {
@@ -538,6 +646,7 @@ func doIndicesCommand(cliCtx *cli.Context) error {
 
 	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
 	rebuild := cliCtx.Bool(SnapshotRebuildFlag.Name)
+	recsplit.Workers = cliCtx.Int(erigoncli.IndexWorkersFlag.Name)
 	chainDB := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
 	defer chainDB.Close()
 
@@ -548,6 +657,13 @@ func doIndicesCommand(cliCtx *cli.Context) error {
 	if err := freezeblocks.RemoveIncompatibleIndices(dirs); err != nil {
 		return err
 	}
+	if only := cliCtx.StringSlice(SnapshotIndexOnlyFlag.Name); len(only) > 0 {
+		removed, err := freezeblocks.RemoveIndicesOf(dirs, only)
+		if err != nil {
+			return err
+		}
+		logger.Info("Indices to re-build", "files", removed)
+	}
 
 	cfg := ethconfig.NewSnapCfg(true, false, true)
 	chainConfig := fromdb.ChainConfig(chainDB)
@@ -692,7 +808,18 @@ func doCompress(cliCtx *cli.Context) error {
 	f := args.First()
 	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
 	logger.Info("file", "datadir", dirs.DataDir, "f", f)
-	c, err := seg.NewCompressor(ctx, "compress", f, dirs.Tmp, seg.MinPatternScore, estimate.CompressSnapshot.Workers(), log.LvlInfo, logger)
+	compressCfg := seg.DefaultCfg
+	compressCfg.Workers = estimate.CompressSnapshot.Workers()
+	compressCfg.MinPatternScore = cliCtx.Uint64(CompressMinPatternScoreFlag.Name)
+	compressCfg.MaxPatternLen = cliCtx.Int(CompressMaxPatternLenFlag.Name)
+	compressCfg.SamplingFactor = cliCtx.Uint64(CompressSamplingFactorFlag.Name)
+	compressCfg.MaxDictPatterns = cliCtx.Int(CompressDictSizeFlag.Name)
+	if dictIn := cliCtx.String(CompressDictInFlag.Name); dictIn != "" {
+		if compressCfg.Dictionary, err = seg.ReadDictionary(dictIn); err != nil {
+			return err
+		}
+	}
+	c, err := seg.NewCompressorWithCfg(ctx, "compress", f, dirs.Tmp, compressCfg, log.LvlInfo, logger)
 	if err != nil {
 		return err
 	}
@@ -724,6 +851,11 @@ func doCompress(cliCtx *cli.Context) error {
 	if err := c.Compress(); err != nil {
 		return err
 	}
+	if dictOut := cliCtx.String(CompressDictOutFlag.Name); dictOut != "" {
+		if err := seg.PersistDictionary(dictOut, c.Dictionary()); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -917,6 +1049,75 @@ func doRetireCommand(cliCtx *cli.Context) error {
 	return nil
 }
 
+// doCreateCommand - fast-bootstrap for custom/private chains: same files as mainnet releases.
+// Nodes of the chain use them by --webseed=<upload.location url> (and --downloader.release.keys if release is signed)
+func doCreateCommand(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+
+	// freeze blocks and state, build indices
+	if err := doRetireCommand(cliCtx); err != nil {
+		return err
+	}
+
+	db := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	chainConfig := fromdb.ChainConfig(db)
+	db.Close()
+
+	cfg := downloader.ReleaseCfg{
+		Chain:   chainConfig.ChainName,
+		All:     cliCtx.Bool(SnapshotReleaseAllFlag.Name),
+		V2Roots: cliCtx.Bool(SnapshotReleaseV2RootsFlag.Name),
+	}
+	if keyFile := cliCtx.String(SnapshotReleaseKeyFlag.Name); keyFile != "" {
+		key, created, err := downloadercfg.LoadReleaseKey(keyFile)
+		if err != nil {
+			return err
+		}
+		if created {
+			logger.Info("[snapshots.create] created release key", "file", keyFile)
+		}
+		cfg.Key = key
+		logger.Info("[snapshots.create] signing release", "release_key", hex.EncodeToString(key.Public().(ed25519.PublicKey)))
+	}
+	release, err := downloader.CreateRelease(ctx, dirs, cfg)
+	if err != nil {
+		return err
+	}
+	if len(release.Preverified) == 0 {
+		return fmt.Errorf("no seedable files in %s: chain is shorter than merge limit? use --%s", dirs.Snap, SnapshotReleaseAllFlag.Name)
+	}
+	logger.Info("[snapshots.create] release created", "chain", cfg.Chain, "files", len(release.Preverified), "manifests", release.Meta)
+
+	location := cliCtx.String(erigoncli.UploadLocationFlag.Name)
+	if location == "" {
+		return nil
+	}
+	rclone, err := downloader.NewRCloneClient(logger)
+	if err != nil {
+		return err
+	}
+	session, err := rclone.NewSession(ctx, dirs.Snap, location, nil)
+	if err != nil {
+		return err
+	}
+	defer session.Stop()
+	if err := session.Upload(ctx, release.Files...); err != nil {
+		return err
+	}
+	for _, name := range release.Meta { // one by one: in order
+		if err := session.Upload(ctx, name); err != nil {
+			return err
+		}
+	}
+	logger.Info("[snapshots.create] uploaded", "location", location)
+	return nil
+}
+
 func doUploaderCommand(cliCtx *cli.Context) error {
 	var logger log.Logger
 	var err error
@@ -1073,3 +1274,97 @@ func openAgg(ctx context.Context, dirs datadir.Dirs, chainDB kv.RwDB, logger log
 	agg.SetCompressWorkers(estimate.CompressSnapshot.Workers())
 	return agg
 }
+
+func doPin(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* root logger */)
+	if err != nil {
+		return err
+	}
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	names := cliCtx.Args().Slice()
+	if len(names) == 0 {
+		return fmt.Errorf("no files to pin")
+	}
+	if err := tiering.Pin(dirs, names...); err != nil {
+		return err
+	}
+	if coldDir := cliCtx.String(utils.SnapColdDirFlag.Name); coldDir != "" {
+		coldTier, err := tiering.New(dirs, coldDir, tiering.DefaultPolicy, logger)
+		if err != nil {
+			return err
+		}
+		promoted, err := coldTier.Promote(names...)
+		if err != nil {
+			return err
+		}
+		logger.Info("[tiering] moved pinned files to local disk", "files", promoted)
+	}
+	return nil
+}
+
+func doUnpin(cliCtx *cli.Context) error {
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	if cliCtx.NArg() == 0 {
+		return fmt.Errorf("no files to unpin")
+	}
+	return tiering.Unpin(dirs, cliCtx.Args().Slice()...)
+}
+
+func doTiers(cliCtx *cli.Context) error {
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	files, err := tiering.Files(dirs)
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Rel < files[j].Rel })
+	sizes := map[tiering.Tier]int64{}
+	for _, f := range files {
+		pinned := ""
+		if f.Pinned {
+			pinned = "pinned"
+		}
+		fmt.Printf("%-5s %10s %s %s\n", f.Tier, datasize.ByteSize(f.Size).HumanReadable(), f.Rel, pinned)
+		sizes[f.Tier] += f.Size
+	}
+	fmt.Printf("local: %s, cold: %s\n", datasize.ByteSize(sizes[tiering.Local]).HumanReadable(), datasize.ByteSize(sizes[tiering.Cold]).HumanReadable())
+	return nil
+}
+
+func doReindexLogs(cliCtx *cli.Context) error {
+	logger, _, _, err := debug.Setup(cliCtx, true /* root logger */)
+	if err != nil {
+		return err
+	}
+	addresses, err := erigoncli.ParseAddresses(cliCtx.String(erigoncli.PruneLogsKeepFlag.Name))
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("no addresses to reindex, set --%s", erigoncli.PruneLogsKeepFlag.Name)
+	}
+
+	ctx := cliCtx.Context
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	chainDB := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer chainDB.Close()
+
+	cfg := ethconfig.NewSnapCfg(true, false, true)
+	blockSnaps, borSnaps, caplinSnaps, br, agg, err := openSnaps(ctx, cfg, dirs, chainDB, logger)
+	if err != nil {
+		return err
+	}
+	defer blockSnaps.Close()
+	defer borSnaps.Close()
+	defer caplinSnaps.Close()
+	defer agg.Close()
+
+	blockReader, _ := br.IO()
+	return chainDB.Update(ctx, func(tx kv.RwTx) error {
+		restored, err := stagedsync.ReindexLogsOf(ctx, tx, blockReader, addresses, cliCtx.Uint64(SnapshotFromFlag.Name), cliCtx.Uint64(SnapshotToFlag.Name), logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("[reindex logs] done", "addresses", len(addresses), "restored_txs", restored)
+		return nil
+	})
+}
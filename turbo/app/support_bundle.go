@@ -0,0 +1,464 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/downloader"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/logging"
+)
+
+var (
+	bundleOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of archive, default: erigon-support-<time>.tar.gz in current dir",
+	}
+	bundleLogsLimitFlag = cli.StringFlag{
+		Name:  "logs.limit",
+		Usage: "Max size of recent logs in archive, older logs are skipped, oldest included log is cut from the head",
+		Value: "64mb",
+	}
+)
+
+var supportBundleCommand = cli.Command{
+	Action: MigrateFlags(supportBundle),
+	Name:   "bundle",
+	Usage:  "Collect logs, config, db stats, stage progress, profiles and snapshots state into sanitized archive for bug report",
+	Flags: []cli.Flag{
+		&utils.DataDirFlag,
+		&utils.ConfigFlag,
+		&logging.LogDirPathFlag,
+		&debugURLsFlag,
+		&bundleOutputFlag,
+		&bundleLogsLimitFlag,
+	},
+	Description: `Works with stopped node: reads datadir (chaindata is opened read-only). If node is running and its
+debug endpoint (--debug.addrs, node started with --pprof) is reachable - version, flags, goroutines and heap profiles
+are added. Passwords, tokens, secrets, credentials in URLs, enode ids and public IPs are redacted.
+Parts which failed to collect are listed in manifest.json, the archive is written anyway.`,
+}
+
+// bundleCfg - what to collect into support bundle
+type bundleCfg struct {
+	dirs       datadir.Dirs
+	logDir     string
+	configFile string
+	debugURL   string // empty - node is not asked
+	logsLimit  datasize.ByteSize
+}
+
+// bundleManifest - index of archive
+type bundleManifest struct {
+	Version  string            `json:"version"`
+	Created  time.Time         `json:"created"`
+	OS       string            `json:"os"`
+	Go       string            `json:"go"`
+	Files    []string          `json:"files"`
+	Failures map[string]string `json:"failures,omitempty"` // part -> error
+}
+
+func supportBundle(cliCtx *cli.Context) error {
+	logger := log.Root()
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+
+	var logsLimit datasize.ByteSize
+	if err := logsLimit.UnmarshalText([]byte(cliCtx.String(bundleLogsLimitFlag.Name))); err != nil {
+		return fmt.Errorf("--%s: %w", bundleLogsLimitFlag.Name, err)
+	}
+	cfg := bundleCfg{
+		dirs:       dirs,
+		logDir:     cliCtx.String(logging.LogDirPathFlag.Name),
+		configFile: cliCtx.String(utils.ConfigFlag.Name),
+		logsLimit:  logsLimit,
+	}
+	if cfg.logDir == "" {
+		cfg.logDir = filepath.Join(dirs.DataDir, "logs")
+	}
+	if debugURLs := cliCtx.StringSlice(debugURLsFlag.Name); len(debugURLs) > 0 {
+		cfg.debugURL = "http://" + debugURLs[0]
+	}
+
+	output := cliCtx.String(bundleOutputFlag.Name)
+	if output == "" {
+		output = fmt.Sprintf("erigon-support-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	manifest, err := writeSupportBundle(cliCtx.Context, f, cfg)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	for part, failure := range manifest.Failures {
+		logger.Warn("[support] not collected", "part", part, "err", failure)
+	}
+	logger.Info("[support] bundle is ready, attach it to bug report", "file", output, "files", len(manifest.Files))
+	return nil
+}
+
+// bundleWriter - tar.gz of sanitized files
+type bundleWriter struct {
+	tw       *tar.Writer
+	manifest *bundleManifest
+	now      time.Time
+}
+
+func (w *bundleWriter) add(name string, data []byte, sanitize bool) error {
+	if sanitize {
+		data = []byte(sanitizeText(string(data)))
+	}
+	if err := w.tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: w.now}); err != nil {
+		return err
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, name)
+	return nil
+}
+
+func (w *bundleWriter) addJson(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.add(name, data, true)
+}
+
+func (w *bundleWriter) failed(part string, err error) {
+	w.manifest.Failures[part] = sanitizeText(err.Error())
+}
+
+// writeSupportBundle - collects everything it can: failure of one part doesn't stop others, only failure to write
+// archive is returned
+func writeSupportBundle(ctx context.Context, out io.Writer, cfg bundleCfg) (*bundleManifest, error) {
+	gz := gzip.NewWriter(out)
+	w := &bundleWriter{
+		tw:  tar.NewWriter(gz),
+		now: time.Now().UTC(),
+		manifest: &bundleManifest{
+			Version:  params.VersionWithCommit(params.GitCommit),
+			OS:       runtime.GOOS + "/" + runtime.GOARCH,
+			Go:       runtime.Version(),
+			Failures: map[string]string{},
+		},
+	}
+	w.manifest.Created = w.now
+
+	parts := []struct {
+		name    string
+		collect func() error
+	}{
+		{"logs", func() error { return bundleLogs(w, cfg.logDir, cfg.logsLimit) }},
+		{"config", func() error { return bundleConfig(w, cfg.configFile) }},
+		{"db", func() error { return bundleDb(ctx, w, cfg.dirs.Chaindata) }},
+		{"snapshots", func() error { return bundleSnapshots(w, cfg.dirs.Snap) }},
+		{"node", func() error { return bundleNode(ctx, w, cfg.debugURL) }},
+	}
+	for _, part := range parts {
+		if err := part.collect(); err != nil {
+			var writeErr bundleWriteError
+			if errors.As(err, &writeErr) {
+				return nil, err
+			}
+			w.failed(part.name, err)
+		}
+	}
+
+	if err := w.addJson("manifest.json", w.manifest); err != nil {
+		return nil, err
+	}
+	if err := w.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return w.manifest, nil
+}
+
+// bundleWriteError - archive can't be written, collection stops
+type bundleWriteError struct{ error }
+
+func (w *bundleWriter) mustAdd(name string, data []byte, sanitize bool) error {
+	if err := w.add(name, data, sanitize); err != nil {
+		return bundleWriteError{err}
+	}
+	return nil
+}
+
+func (w *bundleWriter) mustAddJson(name string, v interface{}) error {
+	if err := w.addJson(name, v); err != nil {
+		return bundleWriteError{err}
+	}
+	return nil
+}
+
+// bundleLogs - newest log files until limit, the oldest included file is cut from the head
+func bundleLogs(w *bundleWriter, logDir string, limit datasize.ByteSize) error {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return err
+	}
+	type logFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []logFile
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, logFile{e.Name(), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	budget := int64(limit.Bytes())
+	for _, f := range files {
+		if budget <= 0 {
+			break
+		}
+		data, err := readTail(filepath.Join(logDir, f.name), budget)
+		if err != nil {
+			return err
+		}
+		budget -= int64(len(data))
+		if err := w.mustAdd("logs/"+f.name, data, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTail - last n bytes of file, starting from full line if file is cut
+func readTail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= n {
+		return io.ReadAll(f)
+	}
+	if _, err := f.Seek(info.Size()-n, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+func bundleConfig(w *bundleWriter, configFile string) error {
+	if configFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	return w.mustAdd("config/"+filepath.Base(configFile), data, true)
+}
+
+type stageState struct {
+	Stage         stages.SyncStage `json:"stage"`
+	Progress      uint64           `json:"progress"`
+	PruneProgress uint64           `json:"pruneProgress"`
+}
+
+// bundleDb - table stats and stage progress of chaindata, opened read-only: works while node is running
+func bundleDb(ctx context.Context, w *bundleWriter, chaindata string) error {
+	if _, err := os.Stat(filepath.Join(chaindata, "mdbx.dat")); err != nil {
+		return err
+	}
+	db, err := dbCfg(kv.ChainDB, chaindata).Readonly().Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var progress []stageState
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		for _, stage := range stages.AllStages {
+			p, err := stages.GetStageProgress(tx, stage)
+			if err != nil {
+				return err
+			}
+			pp, err := stages.GetStagePruneProgress(tx, stage)
+			if err != nil {
+				return err
+			}
+			progress = append(progress, stageState{Stage: stage, Progress: p, PruneProgress: pp})
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := w.mustAddJson("db/stages.json", progress); err != nil {
+		return err
+	}
+
+	s, ok := db.(kv.HasTableStats)
+	if !ok {
+		return nil
+	}
+	stats, err := s.TableStats(ctx)
+	if err != nil {
+		return err
+	}
+	return w.mustAddJson("db/chaindata_tables.json", stats)
+}
+
+type snapshotFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// bundleSnapshots - list of files of snapshots dir and state of downloader
+func bundleSnapshots(w *bundleWriter, snapDir string) error {
+	var files []snapshotFile
+	if err := filepath.WalkDir(snapDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(snapDir, path)
+		files = append(files, snapshotFile{Path: rel, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := w.mustAddJson("snapshots/files.json", files); err != nil {
+		return err
+	}
+	for _, name := range []string{downloader.SnapshotsLockFileName, downloader.ProhibitNewDownloadsFileName} {
+		data, err := os.ReadFile(filepath.Join(snapDir, name))
+		if err != nil {
+			continue
+		}
+		if err := w.mustAdd("snapshots/"+name, data, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bundleNode - state of running node from its debug endpoint
+func bundleNode(ctx context.Context, w *bundleWriter, debugURL string) error {
+	if debugURL == "" {
+		return nil
+	}
+	endpoints := []struct {
+		path     string
+		name     string
+		sanitize bool
+	}{
+		{"/debug/diag/version", "node/version.json", true},
+		{"/debug/diag/cmdline", "node/cmdline.json", true},
+		{"/debug/diag/flags", "node/flags.json", true},
+		{"/debug/diag/stages-progress", "node/stages_progress.json", true},
+		{"/debug/diag/mem", "node/mem.json", true},
+		{"/debug/pprof/goroutine?debug=2", "profiles/goroutine.txt", true},
+		{"/debug/pprof/heap", "profiles/heap.pb.gz", false},
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var failures []string
+	for _, e := range endpoints {
+		data, err := httpGet(ctx, client, debugURL+e.path)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if err := w.mustAdd(e.name, data, e.sanitize); err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+const redacted = "<redacted>"
+
+var (
+	urlCredentialsRe = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+	secretValueRe    = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|apikey|api[-_.]key|private[-_.]?key|nodekey(?:hex)?)["']?\s*[=:]\s*["']?)[^\s"',&]+`)
+	enodeIdRe        = regexp.MustCompile(`(enode://)[0-9a-fA-F]{128}`)
+	ipv4Re           = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// sanitizeText - redacts secrets and identity of node: credentials in URLs, values of secret-like keys, enode ids,
+// public IPs. Loopback and private IPs are kept: they help with triage of local setups
+func sanitizeText(s string) string {
+	s = urlCredentialsRe.ReplaceAllString(s, "${1}"+redacted+"@")
+	s = secretValueRe.ReplaceAllString(s, "${1}"+redacted)
+	s = enodeIdRe.ReplaceAllString(s, "${1}"+redacted)
+	return ipv4Re.ReplaceAllStringFunc(s, func(ip string) string {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() {
+			return ip
+		}
+		return redacted
+	})
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -79,8 +80,12 @@ var supportCommand = cli.Command{
 		&sessionsFlag,
 		&insecureFlag,
 	}, debug.Flags...),
+	Subcommands: []*cli.Command{
+		&supportBundleCommand,
+	},
 	//Category: "SUPPORT COMMANDS",
-	Description: `The support command connects a running Erigon instances to a diagnostics system specified by the URL.`,
+	Description: `The support command connects a running Erigon instances to a diagnostics system specified by the URL.
+'support bundle' collects state of the node into archive for bug report.`,
 }
 
 const Version = 1
@@ -321,13 +326,18 @@ func tunnel(ctx context.Context, cancel context.CancelFunc, sigs chan os.Signal,
 		if node, ok := nodes[nodeRequest.NodeId]; ok {
 			err := func() error {
 				var queryString string
+				var debugResponse *http.Response
 
 				if len(nodeRequest.QueryParams) > 0 {
 					queryString = "?" + nodeRequest.QueryParams.Encode()
 				}
 
 				debugURL := node.debugURL + "/debug/diag/" + requests[0].Method + queryString
-				debugResponse, err := metricsClient.Get(debugURL)
+				debugRequest, err := http.NewRequestWithContext(ctx1, http.MethodGet, debugURL, nil)
+
+				if err == nil {
+					debugResponse, err = metricsClient.Do(debugRequest)
+				}
 
 				if err != nil {
 					return codec.WriteJSON(ctx1, &nodeResponse{
@@ -340,6 +350,39 @@ func tunnel(ctx context.Context, cancel context.CancelFunc, sigs chan os.Signal,
 					})
 				}
 
+				// live diagnostics are streamed as one json message per line, forward each of them
+				// in background so the tunnel keeps serving other requests while the stream is open
+				if debugResponse.Header.Get("Content-Type") == "application/x-ndjson" {
+					go func() {
+						defer debugResponse.Body.Close()
+
+						scanner := bufio.NewScanner(debugResponse.Body)
+						scanner.Buffer(make([]byte, 0, 64*1024), wsMessageSizeLimit)
+
+						for scanner.Scan() {
+							if err := codec.WriteJSON(ctx1, &nodeResponse{
+								Id:     requestId,
+								Result: json.RawMessage(bytes.Clone(scanner.Bytes())),
+							}); err != nil {
+								logger.Debug("Live diagnostics stream stopped", "id", requestId, "err", err)
+								return
+							}
+						}
+
+						var streamErr *responseError
+						if err := scanner.Err(); err != nil && ctx1.Err() == nil {
+							streamErr = &responseError{
+								Code:    http.StatusInternalServerError,
+								Message: fmt.Sprintf("Stream for metrics method [%s] failed: %v", debugURL, err),
+							}
+						}
+
+						codec.WriteJSON(ctx1, &nodeResponse{Id: requestId, Error: streamErr, Last: true}) //nolint:errcheck
+					}()
+
+					return nil
+				}
+
 				defer debugResponse.Body.Close()
 
 				//Websocket ok message
@@ -2,10 +2,13 @@ package cli
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pelletier/go-toml"
@@ -14,6 +17,24 @@ import (
 )
 
 func SetFlagsFromConfigFile(ctx *cli.Context, filePath string) error {
+	fileConfig, err := readConfigFile(filePath)
+	if err != nil {
+		return err
+	}
+	// sets global flags to value in yaml/toml file
+	for key, value := range fileConfig {
+		if !ctx.IsSet(key) {
+			if err := ctx.Set(key, value); err != nil {
+				return fmt.Errorf("failed setting %s flag with value=%s error=%s", key, value, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readConfigFile - flattened flag values of .yaml or .toml config file, slices are joined by ","
+func readConfigFile(filePath string) (map[string]string, error) {
 	fileExtension := filepath.Ext(filePath)
 
 	fileConfig := make(map[string]interface{})
@@ -21,46 +42,112 @@ func SetFlagsFromConfigFile(ctx *cli.Context, filePath string) error {
 	if fileExtension == ".yml" || fileExtension == ".yaml" {
 		yamlFile, err := os.ReadFile(filePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		err = yaml.Unmarshal(yamlFile, fileConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else if fileExtension == ".toml" {
 		tomlFile, err := os.ReadFile(filePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		err = toml.Unmarshal(tomlFile, &fileConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
-		return errors.New("config files only accepted are .yaml and .toml")
+		return nil, errors.New("config files only accepted are .yaml and .toml")
 	}
-	// sets global flags to value in yaml/toml file
-	for key, value := range fileConfig {
-		if !ctx.IsSet(key) {
-			if reflect.ValueOf(value).Kind() == reflect.Slice {
-				sliceInterface := value.([]interface{})
-				s := make([]string, len(sliceInterface))
-				for i, v := range sliceInterface {
-					s[i] = fmt.Sprintf("%v", v)
-				}
-				err := ctx.Set(key, strings.Join(s, ","))
-				if err != nil {
-					return fmt.Errorf("failed setting %s flag with values=%s error=%s", key, s, err)
-				}
-			} else {
-				err := ctx.Set(key, fmt.Sprintf("%v", value))
-				if err != nil {
-					return fmt.Errorf("failed setting %s flag with value=%v error=%s", key, value, err)
 
-				}
+	values := make(map[string]string)
+	for key, value := range flattenConfig("", fileConfig, map[string]interface{}{}) {
+		if reflect.ValueOf(value).Kind() == reflect.Slice {
+			sliceInterface := value.([]interface{})
+			s := make([]string, len(sliceInterface))
+			for i, v := range sliceInterface {
+				s[i] = fmt.Sprintf("%v", v)
 			}
+			values[key] = strings.Join(s, ",")
+		} else {
+			values[key] = fmt.Sprintf("%v", value)
 		}
 	}
+	return values, nil
+}
 
-	return nil
+// ValidateConfigFile - checks that every key of config file is a known flag and its value is parsable by the flag,
+// all problems are reported at once
+func ValidateConfigFile(filePath string, flags []cli.Flag) error {
+	fileConfig, err := readConfigFile(filePath)
+	if err != nil {
+		return err
+	}
+	return validateConfig(fileConfig, flags)
+}
+
+func validateConfig(fileConfig map[string]string, flags []cli.Flag) error {
+	set, err := flagSet(flags)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(fileConfig))
+	for key := range fileConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if set.Lookup(key) == nil {
+			errs = append(errs, fmt.Errorf("%s: unknown flag", key))
+			continue
+		}
+		if err := set.Set(key, fileConfig[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", key, fileConfig[key], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flagSet - fresh set of flags with default values, to parse values without touching flags of running app:
+// some flags keep parsed value in themselves, so copies of them are applied
+func flagSet(flags []cli.Flag) (*flag.FlagSet, error) {
+	set := flag.NewFlagSet("config", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	for _, f := range flags {
+		if set.Lookup(f.Names()[0]) != nil { // same flag in several groups
+			continue
+		}
+		if v := reflect.ValueOf(f); v.Kind() == reflect.Pointer {
+			c := reflect.New(v.Elem().Type())
+			c.Elem().Set(v.Elem())
+			f = c.Interface().(cli.Flag)
+		}
+		if err := f.Apply(set); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// flattenConfig - blocks of config file set flags with dotted names: `[prune]` block with `receipts = "older=90000"`
+// is `--prune.receipts=older=90000`
+func flattenConfig(prefix string, in map[string]interface{}, out map[string]interface{}) map[string]interface{} {
+	for key, value := range in {
+		switch block := value.(type) {
+		case map[string]interface{}: // toml
+			flattenConfig(prefix+key+".", block, out)
+		case map[interface{}]interface{}: // yaml
+			m := make(map[string]interface{}, len(block))
+			for k, v := range block {
+				m[fmt.Sprintf("%v", k)] = v
+			}
+			flattenConfig(prefix+key+".", m, out)
+		default:
+			out[prefix+key] = value
+		}
+	}
+	return out
 }
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/turbo/reload"
+)
+
+// ConfigReloader - re-reads config file of running node (on SIGHUP or admin_reloadConfig) and applies changed values
+// of reloadable flags (see package reload). Other changes are reported as requiring restart. Flags set by command line
+// have priority over config file, so their changes in file are ignored - same as at start.
+type ConfigReloader struct {
+	path    string
+	flags   []cli.Flag
+	cmdline map[string]bool // names of flags set by command line
+	logger  log.Logger
+
+	lock    sync.Mutex
+	applied map[string]string // values of config file applied at start or by reload
+}
+
+// NewConfigReloader - must be created before config file is applied to ctx, to know flags set by command line
+func NewConfigReloader(ctx *cli.Context, path string, logger log.Logger) *ConfigReloader {
+	r := &ConfigReloader{path: path, flags: ctx.App.Flags, cmdline: map[string]bool{}, logger: logger, applied: map[string]string{}}
+	for _, name := range ctx.FlagNames() {
+		r.cmdline[name] = true
+	}
+	if values, err := readConfigFile(path); err == nil {
+		r.applied = values
+	}
+	return r
+}
+
+func (r *ConfigReloader) setByCmdline(key string) bool {
+	for _, f := range r.flags {
+		names := f.Names()
+		if !slices.Contains(names, key) {
+			continue
+		}
+		for _, name := range names {
+			if r.cmdline[name] {
+				return true
+			}
+		}
+		return false
+	}
+	return r.cmdline[key]
+}
+
+// Reload - applies changes of config file. Invalid file is rejected as a whole: nothing is applied
+func (r *ConfigReloader) Reload() (*reload.Result, error) {
+	values, err := readConfigFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(values, r.flags); err != nil {
+		return nil, err
+	}
+	set, err := flagSet(r.flags)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]string, 0, len(values)+len(r.applied))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	for key := range r.applied {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	res := &reload.Result{}
+	for _, key := range keys {
+		value, inFile := values[key]
+		prev, wasInFile := r.applied[key]
+		if inFile == wasInFile && value == prev {
+			continue
+		}
+		if !inFile { // removed from file: back to default
+			value = set.Lookup(key).DefValue
+		}
+
+		switch {
+		case r.setByCmdline(key):
+			res.Overridden = append(res.Overridden, key)
+		case !reload.Reloadable(key):
+			res.RequireRestart = append(res.RequireRestart, key)
+		default:
+			if err := reload.Apply(key, value); err != nil {
+				if res.Failed == nil {
+					res.Failed = map[string]string{}
+				}
+				res.Failed[key] = err.Error()
+				continue
+			}
+			res.Applied = append(res.Applied, key)
+			if inFile {
+				r.applied[key] = value
+			} else {
+				delete(r.applied, key)
+			}
+		}
+	}
+
+	r.logger.Info("[config] reloaded", "file", r.path, "applied", strings.Join(res.Applied, ","))
+	if len(res.RequireRestart) > 0 {
+		r.logger.Warn("[config] changes require restart", "flags", strings.Join(res.RequireRestart, ","))
+	}
+	if len(res.Overridden) > 0 {
+		r.logger.Warn("[config] changes ignored: flags are set by command line", "flags", strings.Join(res.Overridden, ","))
+	}
+	for key, err := range res.Failed {
+		r.logger.Warn("[config] failed to apply", "flag", key, "err", err)
+	}
+	return res, nil
+}
+
+// ListenReload - reloads config file on SIGHUP until ctx is cancelled
+func (r *ConfigReloader) ListenReload(ctx context.Context) {
+	sighup, stop := notifyReload()
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			if _, err := r.Reload(); err != nil {
+				r.logger.Warn("[config] reload failed, nothing applied", "file", r.path, "err", err)
+			}
+		}
+	}
+}
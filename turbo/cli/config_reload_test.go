@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/turbo/reload"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.IntFlag{Name: "test.validate.limit"},
+		&cli.DurationFlag{Name: "test.validate.interval"},
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	require.NoError(t, os.WriteFile(path, []byte("[test.validate]\nlimit = 10\ninterval = \"30s\"\n"), 0644))
+	require.NoError(t, ValidateConfigFile(path, flags))
+
+	require.NoError(t, os.WriteFile(path, []byte("[test.validate]\nlimit = \"many\"\nunknown = 1\n"), 0644))
+	err := ValidateConfigFile(path, flags)
+	require.ErrorContains(t, err, "test.validate.limit: invalid value")
+	require.ErrorContains(t, err, "test.validate.unknown: unknown flag")
+
+	require.ErrorContains(t, ValidateConfigFile(filepath.Join(t.TempDir(), "config.json"), flags), "only accepted")
+}
+
+func TestConfigReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  reload:\n    limit: 10\n    pinned: 1\n    fixed: a\n"), 0644))
+
+	var limit string
+	reload.Register("test.reload.limit", func(value string) error { limit = value; return nil })
+	reload.Register("test.reload.pinned", func(value string) error { t.Fatal("flag set by command line is applied"); return nil })
+
+	var reloader *ConfigReloader
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "test.reload.limit", Value: 5},
+			&cli.IntFlag{Name: "test.reload.pinned"},
+			&cli.StringFlag{Name: "test.reload.fixed"},
+		},
+		Action: func(ctx *cli.Context) error {
+			reloader = NewConfigReloader(ctx, path, log.New())
+			return SetFlagsFromConfigFile(ctx, path)
+		},
+	}
+	require.NoError(t, app.Run([]string{"erigon", "--test.reload.pinned=2"}))
+
+	res, err := reloader.Reload() // no changes
+	require.NoError(t, err)
+	require.Empty(t, res.Applied)
+	require.Empty(t, res.RequireRestart)
+
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  reload:\n    limit: 20\n    pinned: 3\n    fixed: b\n"), 0644))
+	res, err = reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.reload.limit"}, res.Applied)
+	require.Equal(t, []string{"test.reload.fixed"}, res.RequireRestart)
+	require.Equal(t, []string{"test.reload.pinned"}, res.Overridden)
+	require.Equal(t, "20", limit)
+
+	// invalid file is rejected as a whole
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  reload:\n    limit: 30\n    fixed: [\n"), 0644))
+	_, err = reloader.Reload()
+	require.Error(t, err)
+	require.Equal(t, "20", limit)
+
+	// removed from file: back to default
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  reload:\n    pinned: 3\n    fixed: b\n"), 0644))
+	res, err = reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{"test.reload.limit"}, res.Applied)
+	require.Equal(t, "5", limit)
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+func notifyReload() (<-chan os.Signal, func()) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, unix.SIGHUP)
+	return sighup, func() { signal.Stop(sighup) }
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cli
+
+import "os"
+
+// no SIGHUP on windows: config is reloaded only by admin_reloadConfig
+func notifyReload() (<-chan os.Signal, func()) {
+	return nil, func() {}
+}
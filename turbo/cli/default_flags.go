@@ -10,23 +10,42 @@ import (
 var DefaultFlags = []cli.Flag{
 	&utils.DataDirFlag,
 	&utils.EthashDatasetDirFlag,
+	&utils.EthashLightFlag,
 	&utils.ExternalConsensusFlag,
+	&utils.EngineAsyncValidationFlag,
+	&utils.EngineAsyncValidationWaitFlag,
+	&utils.EngineWitnessFlag,
+	&utils.EngineRecordFlag,
 	&utils.TxPoolDisableFlag,
 	&utils.TxPoolLocalsFlag,
 	&utils.TxPoolNoLocalsFlag,
 	&utils.TxPoolPriceLimitFlag,
+	&utils.TxPoolMinTipFlag,
+	&utils.TxPoolMaxNonceGapFlag,
 	&utils.TxPoolPriceBumpFlag,
 	&utils.TxPoolBlobPriceBumpFlag,
 	&utils.TxPoolAccountSlotsFlag,
 	&utils.TxPoolBlobSlotsFlag,
 	&utils.TxPoolTotalBlobPoolLimit,
+	&utils.TxPoolTotalBlobPoolBytesFlag,
 	&utils.TxPoolGlobalSlotsFlag,
 	&utils.TxPoolGlobalBaseFeeSlotsFlag,
 	&utils.TxPoolAccountQueueFlag,
 	&utils.TxPoolGlobalQueueFlag,
 	&utils.TxPoolLifetimeFlag,
+	&utils.TxPoolNoJournalFlag,
+	&utils.TxPoolJournalLifetimeFlag,
+	&utils.TxPoolRejournalFlag,
+	&utils.TxPoolScoringFlag,
+	&utils.TxPoolPrivateBlocksFlag,
+	&utils.TxPoolPropagationFractionFlag,
+	&utils.TxPoolPropagationPeerRateFlag,
+	&utils.TxPoolPropagationDenyFlag,
+	&utils.TxPoolUserOpsEntryPointsFlag,
+	&utils.TxPoolUserOpsMaxFlag,
 	&utils.TxPoolTraceSendersFlag,
 	&utils.TxPoolCommitEveryFlag,
+	&NodeProfileFlag,
 	&PruneFlag,
 	&PruneHistoryFlag,
 	&PruneReceiptFlag,
@@ -36,16 +55,33 @@ var DefaultFlags = []cli.Flag{
 	&PruneReceiptBeforeFlag,
 	&PruneTxIndexBeforeFlag,
 	&PruneCallTracesBeforeFlag,
+	&PruneHistoryRetentionFlag,
+	&PruneReceiptsRetentionFlag,
+	&PruneTxIndexRetentionFlag,
+	&PruneCallTracesRetentionFlag,
+	&PruneLogIndexRetentionFlag,
+	&PruneLogsKeepFlag,
 	&BatchSizeFlag,
+	&DiskFreePauseFlag,
+	&DiskFreeHaltFlag,
 	&BodyCacheLimitFlag,
+	&BodiesResponseLimitFlag,
 	&DatabaseVerbosityFlag,
 	&PrivateApiAddr,
 	&PrivateApiRateLimit,
 	&EtlBufferSizeFlag,
+	&EtlTmpQuotaFlag,
+	&IndexWorkersFlag,
+	&BtreePageCacheFlag,
+	&BtreeFanoutFlag,
 	&TLSFlag,
 	&TLSCertFlag,
 	&TLSKeyFlag,
 	&TLSCACertFlag,
+	&TLSAllowedCNFlag,
+	&SentryTLSAllowedCNFlag,
+	&DownloaderTLSAllowedCNFlag,
+	&TLSReloadIntervalFlag,
 	&StateStreamDisableFlag,
 	&SyncLoopThrottleFlag,
 	&BadBlockFlag,
@@ -58,6 +94,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.AuthRpcAddr,
 	&utils.AuthRpcPort,
 	&utils.JWTSecretPath,
+	&utils.JWTSecretEnv,
+	&utils.JWTSecretReload,
 	&utils.HttpCompressionFlag,
 	&utils.HTTPCORSDomainFlag,
 	&utils.HTTPVirtualHostsFlag,
@@ -69,11 +107,24 @@ var DefaultFlags = []cli.Flag{
 	&utils.HTTPTraceFlag,
 	&utils.HTTPDebugSingleFlag,
 	&utils.StateCacheFlag,
+	&utils.StateCachePinFlag,
+	&utils.StateCachePinSizeFlag,
 	&utils.RpcBatchConcurrencyFlag,
 	&utils.RpcStreamingDisableFlag,
 	&utils.DBReadConcurrencyFlag,
 	&utils.RpcAccessListFlag,
 	&utils.RpcTraceCompatFlag,
+	&utils.RpcTraceChainConcurrencyFlag,
+	&utils.RpcReexecWorkersFlag,
+	&utils.RpcReexecQueueFlag,
+	&utils.HealthReadyMaxLagFlag,
+	&utils.HealthReadyMinPeersFlag,
+	&utils.RpcReceiptsRegenFlag,
+	&utils.RpcReceiptsRegenMaxTxsFlag,
+	&utils.RpcReceiptsRegenTimeoutFlag,
+	&utils.RpcTracerCPUTimeFlag,
+	&utils.RpcTracerMemoryFlag,
+	&utils.RpcTracerOutputSizeFlag,
 	&utils.RpcGasCapFlag,
 	&utils.RpcBatchLimit,
 	&utils.RpcReturnDataLimit,
@@ -94,8 +145,16 @@ var DefaultFlags = []cli.Flag{
 
 	&utils.SnapKeepBlocksFlag,
 	&utils.SnapStopFlag,
+	&utils.SnapDownloadProfileFlag,
+	&utils.SnapReceiptsFlag,
+	&utils.SnapColdDirFlag,
+	&utils.SnapColdAgeFlag,
+	&utils.SnapColdReadsFlag,
+	&utils.SnapColdWindowFlag,
+	&utils.DiskIOProfileFlag,
 	&utils.DbPageSizeFlag,
 	&utils.DbSizeLimitFlag,
+	&utils.DbReadTxWatchdogFlag,
 	&utils.TorrentPortFlag,
 	&utils.TorrentMaxPeersFlag,
 	&utils.TorrentConnsPerFileFlag,
@@ -103,10 +162,14 @@ var DefaultFlags = []cli.Flag{
 	&utils.TorrentStaticPeersFlag,
 	&utils.TorrentUploadRateFlag,
 	&utils.TorrentDownloadRateFlag,
+	&utils.TorrentBandwidthScheduleFlag,
 	&utils.TorrentVerbosityFlag,
 	&utils.ListenPortFlag,
 	&utils.P2pProtocolVersionFlag,
 	&utils.P2pProtocolAllowedPorts,
+	&utils.P2pSnapServeFlag,
+	&utils.P2pWitServeFlag,
+	&utils.P2pWitFetchFlag,
 	&utils.NATFlag,
 	&utils.NoDiscoverFlag,
 	&utils.DiscoveryV5Flag,
@@ -117,6 +180,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.BootnodesFlag,
 	&utils.StaticPeersFlag,
 	&utils.TrustedPeersFlag,
+	&utils.StaticPeersFileFlag,
+	&utils.TrustedPeersFileFlag,
 	&utils.MaxPeersFlag,
 	&utils.ChainFlag,
 	&utils.DeveloperPeriodFlag,
@@ -138,9 +203,19 @@ var DefaultFlags = []cli.Flag{
 	&utils.MinerEtherbaseFlag,
 	&utils.MinerExtraDataFlag,
 	&utils.MinerNoVerfiyFlag,
+	&utils.MinerTxOrderingFlag,
+	&utils.MinerMaxBlobsFlag,
+	&utils.MinerLocalTxBoostFlag,
+	&utils.BuilderURLsFlag,
+	&utils.BuilderMinBidFlag,
+	&utils.BuilderDenyListFlag,
+	&utils.BuilderTimeoutFlag,
 	&utils.MinerSigningKeyFileFlag,
 	&utils.MinerRecommitIntervalFlag,
 	&utils.SentryAddrFlag,
+	&utils.SentryPolicyFlag,
+	&utils.SentryTagsFlag,
+	&utils.SentryPreferredTagFlag,
 	&utils.SentryLogPeerInfoFlag,
 	&utils.DownloaderAddrFlag,
 	&utils.DisableIPV4,
@@ -150,6 +225,10 @@ var DefaultFlags = []cli.Flag{
 	&HealthCheckFlag,
 	&utils.HeimdallURLFlag,
 	&utils.WebSeedsFlag,
+	&utils.WebSeedS3EndpointFlag,
+	&utils.WebSeedS3RegionFlag,
+	&utils.WebSeedS3RequesterPaysFlag,
+	&utils.DownloaderReleaseKeysFlag,
 	&utils.WithoutHeimdallFlag,
 	&utils.BorBlockPeriodFlag,
 	&utils.BorBlockSizeFlag,
@@ -194,6 +273,24 @@ var DefaultFlags = []cli.Flag{
 	&utils.CaplinBlobBackfillingFlag,
 	&utils.CaplinDisableBlobPruningFlag,
 	&utils.CaplinArchiveFlag,
+	&utils.CaplinStatesSnapshotsFlag,
+	&utils.CaplinBlobsArchiveFlag,
+	&utils.CaplinValidatorKeystoreDirFlag,
+	&utils.CaplinValidatorPasswordFileFlag,
+	&utils.CaplinValidatorFeeRecipientFlag,
+	&utils.CaplinValidatorDoppelgangerEpochsFlag,
+	&utils.CaplinLightClientServerFlag,
+	&utils.CaplinMevRelayUrlFlag,
+	&utils.CaplinCheckpointSyncUrlFlag,
+	&utils.CaplinCheckpointSyncQuorumFlag,
+	&utils.CaplinCheckpointSyncRootFlag,
+	&utils.CaplinGossipScoringFlag,
+	&utils.CaplinGossipThresholdFlag,
+	&utils.CaplinGossipPublishThresholdFlag,
+	&utils.CaplinGossipGraylistThresholdFlag,
+	&utils.CaplinGossipIPColocationThresholdFlag,
+	&utils.CaplinValidatorMonitorIndicesFlag,
+	&utils.CaplinValidatorMonitorAutoFlag,
 
 	&utils.TrustedSetupFile,
 	&utils.RPCSlowFlag,
@@ -202,4 +299,10 @@ var DefaultFlags = []cli.Flag{
 	&SyncLoopBlockLimitFlag,
 	&SyncLoopBreakAfterFlag,
 	&SyncLoopPruneLimitFlag,
+	&SyncPruneIntervalFlag,
+	&ExecParallelWorkersFlag,
+	&ExecCheckpointBlocksFlag,
+	&ExecCheckpointIntervalFlag,
+	&SyncSendersTrustFlag,
+	&SyncStageThrottleFlag,
 }
@@ -15,8 +15,11 @@ import (
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon-lib/etl"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/grpcutil"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon-lib/recsplit"
+	"github.com/ledgerwatch/erigon-lib/state"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/pflag"
 	"github.com/urfave/cli/v2"
@@ -39,17 +42,53 @@ var (
 		Usage: "Batch size for the execution stage",
 		Value: "256M",
 	}
+	DiskFreePauseFlag = cli.StringFlag{
+		Name:  "disk.free.pause",
+		Usage: "Pause snapshot retirement/merges and new torrents when free space on datadir or tmp volume is below (0 - disabled)",
+		Value: "10GB",
+	}
+	DiskFreeHaltFlag = cli.StringFlag{
+		Name:  "disk.free.halt",
+		Usage: "Halt sync loop before next commit when free space on datadir or tmp volume is below, resume when space is freed (0 - disabled)",
+		Value: "1GB",
+	}
 	EtlBufferSizeFlag = cli.StringFlag{
 		Name:  "etl.bufferSize",
 		Usage: "Buffer size for ETL operations.",
 		Value: etl.BufferOptimalSize.String(),
 	}
+	EtlTmpQuotaFlag = cli.StringFlag{
+		Name:  "etl.tmp.quota",
+		Usage: "Limit of total size of ETL temporary files in tmpdir (for example: 200GB). When reached - ETL waits for other collectors to free space or fails stage with clear error, instead of filling the disk. Empty - unlimited",
+		Value: "",
+	}
+	IndexWorkersFlag = cli.IntFlag{
+		Name:  "index.workers",
+		Usage: "Amount of goroutines building one snapshot index (recsplit bucket splitting and golomb-rice encoding). Files are indexed in parallel anyway - increase it when few large files are indexed",
+		Value: recsplit.Workers,
+	}
+	BtreePageCacheFlag = cli.StringFlag{
+		Name:  "bt.page.cache",
+		Usage: "Memory limit of cache of domain .bt index pages (for example: 1GB). Replaces log2(M) random reads of each historical lookup by 1 sequential read per page - useful for archive nodes on HDD. Empty - disabled",
+		Value: "",
+	}
+	BtreeFanoutFlag = cli.StringFlag{
+		Name:  "bt.fanout",
+		Usage: "Per-domain amount of keys on leaf of .bt index, for example: accounts=128,storage=512. Bigger - less RAM, but more reads per lookup. Empty - default for all domains",
+		Value: "",
+	}
 	BodyCacheLimitFlag = cli.StringFlag{
 		Name:  "bodies.cache",
 		Usage: "Limit on the cache for block bodies",
 		Value: fmt.Sprintf("%d", ethconfig.Defaults.Sync.BodyCacheLimit),
 	}
 
+	BodiesResponseLimitFlag = cli.StringFlag{
+		Name:  "engine.bodies.limit",
+		Usage: "Limit on size of engine_getPayloadBodiesByRange response: it's cut once reached, but requests of up to 32 blocks (minimum of execution-apis) are always served whole. 0 - no limit",
+		Value: fmt.Sprintf("%d", ethconfig.Defaults.Sync.BodiesResponseLimit),
+	}
+
 	PrivateApiAddr = cli.StringFlag{
 		Name:  "private.api.addr",
 		Usage: "Erigon's components (txpool, rpcdaemon, sentry, downloader, ...) can be deployed as independent Processes on same/another server. Then components will connect to erigon by this internal grpc API. example: 127.0.0.1:9090, empty string means not to start the listener. do not expose to public network. serves remote database interface",
@@ -69,10 +108,13 @@ var (
 	r - prune receipts (Receipts, Logs, LogTopicIndex, LogAddressIndex - used by eth_getLogs and similar RPC methods)
 	t - prune transaction by it's hash index
 	c - prune call traces (used by trace_filter method)
+	e - EIP-4444 history expiry: don't keep transactions and receipts of pre-merge blocks (served only if their snapshot files are present, otherwise available from era1 archives and Portal network)
 	Does delete data older than 90K blocks, --prune=h is shortcut for: --prune.h.older=90000.
 	Similarly, --prune=t is shortcut for: --prune.t.older=90000 and --prune=c is shortcut for: --prune.c.older=90000.
 	However, --prune=r means to prune receipts before the Beacon Chain genesis (Consensus Layer might need receipts after that).
 	If an item is NOT on the list - means NO pruning for this data.
+	Retention of each data type can be set independently: --prune.history, --prune.receipts, --prune.txindex, --prune.calltraces, --prune.logindex
+	with values older=N or before=N (or by [prune] block of --config file), they take precedence over this flag.
 	Example: --prune=htc`,
 		Value: "disabled",
 	}
@@ -110,6 +152,34 @@ var (
 		Usage: `Prune data before this block`,
 	}
 
+	// Retention of each data type: older=N - keep last N blocks, before=N - keep blocks starting from N.
+	// Set by flags or by `[prune]` block of config file, take precedence over --prune and --prune.<x>.older/before flags
+	PruneHistoryRetentionFlag = cli.StringFlag{
+		Name:  "prune.history",
+		Usage: `Retention of history (historical state access, traces): older=N or before=N`,
+	}
+	PruneReceiptsRetentionFlag = cli.StringFlag{
+		Name:  "prune.receipts",
+		Usage: `Retention of receipts: older=N or before=N. Log index follows it unless --prune.logindex is set`,
+	}
+	PruneTxIndexRetentionFlag = cli.StringFlag{
+		Name:  "prune.txindex",
+		Usage: `Retention of transaction by hash index: older=N or before=N`,
+	}
+	PruneCallTracesRetentionFlag = cli.StringFlag{
+		Name:  "prune.calltraces",
+		Usage: `Retention of call traces (trace_filter): older=N or before=N`,
+	}
+	PruneLogIndexRetentionFlag = cli.StringFlag{
+		Name:  "prune.logindex",
+		Usage: `Retention of logs index (eth_getLogs): older=N or before=N`,
+	}
+	PruneLogsKeepFlag = cli.StringFlag{
+		Name: "prune.logs.keep",
+		Usage: `Comma separated list of addresses which logs are indexed and retained forever, other logs follow logs index retention.
+	After adding addresses, their pruned logs can be restored by 'erigon snapshots reindex-logs'`,
+	}
+
 	ExperimentsFlag = cli.StringFlag{
 		Name: "experiments",
 		Usage: `Enable some experimental stages:
@@ -137,6 +207,26 @@ var (
 		Usage: "Specify certificate authority",
 		Value: "",
 	}
+	TLSAllowedCNFlag = cli.StringFlag{
+		Name:  "tls.allowed.cn",
+		Usage: "Comma-separated Common Names of clients allowed to connect to private api (requires --tls.cacert). Empty - any certificate signed by CA",
+		Value: "",
+	}
+	SentryTLSAllowedCNFlag = cli.StringFlag{
+		Name:  "sentry.tls.allowed.cn",
+		Usage: "Comma-separated Common Names expected from external sentries (--sentry.api.addr). Requires --tls.cacert",
+		Value: "",
+	}
+	DownloaderTLSAllowedCNFlag = cli.StringFlag{
+		Name:  "downloader.tls.allowed.cn",
+		Usage: "Comma-separated Common Names expected from external downloader (--downloader.api.addr). Requires --tls.cacert",
+		Value: "",
+	}
+	TLSReloadIntervalFlag = cli.DurationFlag{
+		Name:  "tls.reload.interval",
+		Usage: "How often to check certificate files for rotation",
+		Value: grpcutil.DefaultTLSReloadInterval,
+	}
 	StateStreamDisableFlag = cli.BoolFlag{
 		Name:  "state.stream.disable",
 		Usage: "Disable streaming of state changes from core to RPC daemon",
@@ -155,6 +245,12 @@ var (
 		Value: 100,
 	}
 
+	SyncPruneIntervalFlag = cli.DurationFlag{
+		Name:  "sync.prune.interval",
+		Usage: "Experimental: prune stages in background, independently of sync loop, with given interval between prune rounds (e.g. 30s). Each round is limited by --sync.loop.prune.limit. 0 - prune at end of each sync loop iteration",
+		Value: 0,
+	}
+
 	SyncLoopBreakAfterFlag = cli.StringFlag{
 		Name:  "sync.loop.break.after",
 		Usage: "Sets the last stage of the sync loop to run",
@@ -167,6 +263,34 @@ var (
 		Value: 2_000, // unlimited
 	}
 
+	ExecParallelWorkersFlag = cli.UintFlag{
+		Name:  "exec.parallel.workers",
+		Usage: "Experimental: amount of workers for optimistic parallel execution of transactions of a block (conflicting transactions are re-executed serially). 0 - serial execution",
+		Value: 0,
+	}
+
+	ExecCheckpointBlocksFlag = cli.Uint64Flag{
+		Name:  "exec.checkpoint.blocks",
+		Usage: "Execution stage commits progress at least every given amount of blocks, even if batch is not full: crash during long catch-up re-executes at most this amount of blocks. 0 - disabled",
+		Value: 0,
+	}
+	ExecCheckpointIntervalFlag = cli.DurationFlag{
+		Name:  "exec.checkpoint.interval",
+		Usage: "Execution stage commits progress at least with given interval (e.g. 10m), even if batch is not full. 0 - disabled",
+		Value: 0,
+	}
+
+	SyncSendersTrustFlag = cli.StringFlag{
+		Name:  "sync.senders.trust",
+		Usage: "Which snapshots are trusted to have correct senders embedded: 'snapshots' - all, 'manifest' - only snapshots listed in chain's manifest, senders of other snapshots are verified once. Senders of not frozen blocks are always recovered",
+		Value: string(ethconfig.SendersTrustSnapshots),
+	}
+	SyncStageThrottleFlag = cli.StringFlag{
+		Name:  "sync.stage.throttle",
+		Usage: "Limits of resources of stages, comma-separated: '<stage>:cpu=<workers>' caps worker pools of Senders, Execution, Snapshots stages; '<stage>:io=<size>' caps bandwidth of writing snapshot files by Snapshots stage (blocks retire, merge). Example: 'Execution:cpu=8,Snapshots:io=100mb'",
+		Value: "",
+	}
+
 	UploadLocationFlag = cli.StringFlag{
 		Name:  "upload.location",
 		Usage: "Location to upload snapshot segments to",
@@ -253,6 +377,18 @@ var (
 	}
 )
 
+// ParseAddresses - comma separated list of hex addresses
+func ParseAddresses(s string) ([]libcommon.Address, error) {
+	var addresses []libcommon.Address
+	for _, a := range libcommon.CliString2Array(s) {
+		if !libcommon.IsHexAddress(a) {
+			return nil, fmt.Errorf("invalid address %q", a)
+		}
+		addresses = append(addresses, libcommon.HexToAddress(a))
+	}
+	return addresses, nil
+}
+
 func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.Logger) {
 	chainId := cfg.NetworkID
 	if cfg.Genesis != nil {
@@ -275,7 +411,22 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 	if err != nil {
 		utils.Fatalf(fmt.Sprintf("error while parsing mode: %v", err))
 	}
+	mode, err = mode.ApplyRetention(map[string]string{
+		"history":    ctx.String(PruneHistoryRetentionFlag.Name),
+		"receipts":   ctx.String(PruneReceiptsRetentionFlag.Name),
+		"txindex":    ctx.String(PruneTxIndexRetentionFlag.Name),
+		"calltraces": ctx.String(PruneCallTracesRetentionFlag.Name),
+		"logindex":   ctx.String(PruneLogIndexRetentionFlag.Name),
+	})
+	if err != nil {
+		utils.Fatalf(fmt.Sprintf("error while parsing mode: %v", err))
+	}
 	cfg.Prune = mode
+	keepLogsOf, err := ParseAddresses(ctx.String(PruneLogsKeepFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid %s provided: %v", PruneLogsKeepFlag.Name, err)
+	}
+	cfg.PruneKeepLogsOf = keepLogsOf
 	if ctx.String(BatchSizeFlag.Name) != "" {
 		err := cfg.BatchSize.UnmarshalText([]byte(ctx.String(BatchSizeFlag.Name)))
 		if err != nil {
@@ -283,6 +434,13 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		}
 	}
 
+	if err := cfg.DiskFreePause.UnmarshalText([]byte(ctx.String(DiskFreePauseFlag.Name))); err != nil {
+		utils.Fatalf("Invalid %s provided: %v", DiskFreePauseFlag.Name, err)
+	}
+	if err := cfg.DiskFreeHalt.UnmarshalText([]byte(ctx.String(DiskFreeHaltFlag.Name))); err != nil {
+		utils.Fatalf("Invalid %s provided: %v", DiskFreeHaltFlag.Name, err)
+	}
+
 	if ctx.String(EtlBufferSizeFlag.Name) != "" {
 		sizeVal := datasize.ByteSize(0)
 		size := &sizeVal
@@ -292,6 +450,28 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		}
 		etl.BufferOptimalSize = *size
 	}
+	if ctx.String(EtlTmpQuotaFlag.Name) != "" {
+		var quota datasize.ByteSize
+		if err := quota.UnmarshalText([]byte(ctx.String(EtlTmpQuotaFlag.Name))); err != nil {
+			utils.Fatalf("Invalid etl.tmp.quota provided: %v", err)
+		}
+		etl.SetTmpDirQuota(quota)
+	}
+	if ctx.IsSet(IndexWorkersFlag.Name) {
+		recsplit.Workers = ctx.Int(IndexWorkersFlag.Name)
+	}
+	if ctx.String(BtreePageCacheFlag.Name) != "" {
+		var limit datasize.ByteSize
+		if err := limit.UnmarshalText([]byte(ctx.String(BtreePageCacheFlag.Name))); err != nil {
+			utils.Fatalf("Invalid bt.page.cache provided: %v", err)
+		}
+		state.SetBtreePageCache(limit)
+	}
+	if ctx.String(BtreeFanoutFlag.Name) != "" {
+		if err := state.SetBtreeFanouts(ctx.String(BtreeFanoutFlag.Name)); err != nil {
+			utils.Fatalf("Invalid bt.fanout provided: %v", err)
+		}
+	}
 
 	cfg.StateStream = !ctx.Bool(StateStreamDisableFlag.Name)
 	if ctx.String(BodyCacheLimitFlag.Name) != "" {
@@ -300,6 +480,11 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 			utils.Fatalf("Invalid bodyCacheLimit provided: %v", err)
 		}
 	}
+	if ctx.String(BodiesResponseLimitFlag.Name) != "" {
+		if err := cfg.Sync.BodiesResponseLimit.UnmarshalText([]byte(ctx.String(BodiesResponseLimitFlag.Name))); err != nil {
+			utils.Fatalf("Invalid %s provided: %v", BodiesResponseLimitFlag.Name, err)
+		}
+	}
 
 	if ctx.String(SyncLoopThrottleFlag.Name) != "" {
 		syncLoopThrottle, err := time.ParseDuration(ctx.String(SyncLoopThrottleFlag.Name))
@@ -313,6 +498,10 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		cfg.Sync.PruneLimit = int(limit)
 	}
 
+	if interval := ctx.Duration(SyncPruneIntervalFlag.Name); interval > 0 {
+		cfg.Sync.PruneInterval = interval
+	}
+
 	if stage := ctx.String(SyncLoopBreakAfterFlag.Name); len(stage) > 0 {
 		cfg.Sync.BreakAfterStage = stage
 	}
@@ -321,6 +510,33 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		cfg.Sync.LoopBlockLimit = limit
 	}
 
+	if workers := ctx.Uint(ExecParallelWorkersFlag.Name); workers > 0 {
+		cfg.Sync.ParallelExecWorkers = int(workers)
+	}
+
+	if blocks := ctx.Uint64(ExecCheckpointBlocksFlag.Name); blocks > 0 {
+		cfg.Sync.ExecCheckpointBlocks = blocks
+	}
+	if interval := ctx.Duration(ExecCheckpointIntervalFlag.Name); interval > 0 {
+		cfg.Sync.ExecCheckpointInterval = interval
+	}
+
+	if v := ctx.String(SyncSendersTrustFlag.Name); v != "" {
+		sendersTrust, err := ethconfig.SendersTrustFromString(v)
+		if err != nil {
+			utils.Fatalf("Invalid %s provided: %v", SyncSendersTrustFlag.Name, err)
+		}
+		cfg.Sync.SendersTrust = sendersTrust
+	}
+
+	if v := ctx.String(SyncStageThrottleFlag.Name); v != "" {
+		throttle, err := ethconfig.ParseStageThrottle(v)
+		if err != nil {
+			utils.Fatalf("Invalid %s provided: %v", SyncStageThrottleFlag.Name, err)
+		}
+		cfg.Sync.StageThrottle = throttle
+	}
+
 	if location := ctx.String(UploadLocationFlag.Name); len(location) > 0 {
 		cfg.Sync.UploadLocation = location
 	}
@@ -419,6 +635,28 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 		}
 		etl.BufferOptimalSize = *size
 	}
+	if v := f.String(EtlTmpQuotaFlag.Name, EtlTmpQuotaFlag.Value, EtlTmpQuotaFlag.Usage); v != nil && *v != "" {
+		var quota datasize.ByteSize
+		if err := quota.UnmarshalText([]byte(*v)); err != nil {
+			utils.Fatalf("Invalid etl.tmp.quota provided: %v", err)
+		}
+		etl.SetTmpDirQuota(quota)
+	}
+	if v := f.Int(IndexWorkersFlag.Name, IndexWorkersFlag.Value, IndexWorkersFlag.Usage); v != nil {
+		recsplit.Workers = *v
+	}
+	if v := f.String(BtreePageCacheFlag.Name, BtreePageCacheFlag.Value, BtreePageCacheFlag.Usage); v != nil && *v != "" {
+		var limit datasize.ByteSize
+		if err := limit.UnmarshalText([]byte(*v)); err != nil {
+			utils.Fatalf("Invalid bt.page.cache provided: %v", err)
+		}
+		state.SetBtreePageCache(limit)
+	}
+	if v := f.String(BtreeFanoutFlag.Name, BtreeFanoutFlag.Value, BtreeFanoutFlag.Usage); v != nil && *v != "" {
+		if err := state.SetBtreeFanouts(*v); err != nil {
+			utils.Fatalf("Invalid bt.fanout provided: %v", err)
+		}
+	}
 
 	cfg.StateStream = true
 	if v := f.Bool(StateStreamDisableFlag.Name, false, StateStreamDisableFlag.Usage); v != nil {
@@ -461,6 +699,8 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 		AuthRpcHTTPListenAddress: ctx.String(utils.AuthRpcAddr.Name),
 		AuthRpcPort:              ctx.Int(utils.AuthRpcPort.Name),
 		JWTSecretPath:            jwtSecretPath,
+		JWTSecretEnv:             ctx.String(utils.JWTSecretEnv.Name),
+		JWTSecretReload:          ctx.Duration(utils.JWTSecretReload.Name),
 		TraceRequests:            ctx.Bool(utils.HTTPTraceFlag.Name),
 		DebugSingleRequest:       ctx.Bool(utils.HTTPDebugSingleFlag.Name),
 		HttpCORSDomain:           libcommon.CliString2Array(ctx.String(utils.HTTPCORSDomainFlag.Name)),
@@ -490,6 +730,15 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 		Gascap:                            ctx.Uint64(utils.RpcGasCapFlag.Name),
 		MaxTraces:                         ctx.Uint64(utils.TraceMaxtracesFlag.Name),
 		TraceCompatibility:                ctx.Bool(utils.RpcTraceCompatFlag.Name),
+		TraceChainConcurrency:             ctx.Int(utils.RpcTraceChainConcurrencyFlag.Name),
+		ReexecWorkers:                     ctx.Int(utils.RpcReexecWorkersFlag.Name),
+		ReexecQueueSize:                   ctx.Int(utils.RpcReexecQueueFlag.Name),
+		ReceiptsRegen:                     ctx.Bool(utils.RpcReceiptsRegenFlag.Name),
+		ReceiptsRegenMaxTxs:               ctx.Int(utils.RpcReceiptsRegenMaxTxsFlag.Name),
+		ReceiptsRegenTimeout:              ctx.Duration(utils.RpcReceiptsRegenTimeoutFlag.Name),
+		TracerCPUTime:                     ctx.Duration(utils.RpcTracerCPUTimeFlag.Name),
+		TracerMemory:                      ctx.Uint64(utils.RpcTracerMemoryFlag.Name),
+		TracerOutputSize:                  ctx.Uint64(utils.RpcTracerOutputSizeFlag.Name),
 		BatchLimit:                        ctx.Int(utils.RpcBatchLimit.Name),
 		ReturnDataLimit:                   ctx.Int(utils.RpcReturnDataLimit.Name),
 		AllowUnprotectedTxs:               ctx.Bool(utils.AllowUnprotectedTxs.Name),
@@ -501,6 +750,8 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 
 		StateCache:          kvcache.DefaultCoherentConfig,
 		RPCSlowLogThreshold: ctx.Duration(utils.RPCSlowFlag.Name),
+		HealthReadyMaxLag:   ctx.Uint64(utils.HealthReadyMaxLagFlag.Name),
+		HealthReadyMinPeers: ctx.Uint(utils.HealthReadyMinPeersFlag.Name),
 	}
 
 	if c.Enabled {
@@ -527,6 +778,15 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 	if err != nil {
 		utils.Fatalf("Invalid state.cache value provided")
 	}
+	for _, addr := range libcommon.CliString2Array(ctx.String(utils.StateCachePinFlag.Name)) {
+		if !libcommon.IsHexAddress(addr) {
+			utils.Fatalf("Invalid state.cache.pin address provided: %s", addr)
+		}
+		c.StateCache.PinnedAddresses = append(c.StateCache.PinnedAddresses, libcommon.HexToAddress(addr))
+	}
+	if err := c.StateCache.PinnedCacheSize.UnmarshalText([]byte(ctx.String(utils.StateCachePinSizeFlag.Name))); err != nil {
+		utils.Fatalf("Invalid state.cache.pin.size value provided")
+	}
 
 	/*
 		rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
@@ -561,6 +821,10 @@ func setPrivateApi(ctx *cli.Context, cfg *nodecfg.Config) {
 		cfg.TLSCertFile = certFile
 		cfg.TLSKeyFile = keyFile
 		cfg.TLSCACert = ctx.String(TLSCACertFlag.Name)
+		cfg.TLSAllowedCNs = grpcutil.ParseAllowedCNs(ctx.String(TLSAllowedCNFlag.Name))
+		cfg.SentryTLSAllowedCNs = grpcutil.ParseAllowedCNs(ctx.String(SentryTLSAllowedCNFlag.Name))
+		cfg.DownloaderTLSAllowedCNs = grpcutil.ParseAllowedCNs(ctx.String(DownloaderTLSAllowedCNFlag.Name))
+		cfg.TLSReloadInterval = ctx.Duration(TLSReloadIntervalFlag.Name)
 	}
 	cfg.HealthCheck = ctx.Bool(HealthCheckFlag.Name)
 }
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+
+	"github.com/ledgerwatch/erigon/cmd/utils"
+)
+
+var NodeProfileFlag = cli.StringFlag{
+	Name: "profile",
+	Usage: `Set of flags for a kind of node, flags set in command line or config file take precedence:
+	minimal - <1 TB mainnet node: keeps last 90K blocks of history, receipts, transactions index and call traces,
+	downloads block snapshots of recent blocks only (--snap.download.profile=minimal), blob sidecars are pruned after retention window.
+	RPC methods return "data not available" errors for blocks out of this window`,
+}
+
+// nodeProfiles - values of flags set by --profile
+var nodeProfiles = map[string]map[string]string{
+	"minimal": {
+		PruneFlag.Name:                          "hrtc",
+		PruneHistoryRetentionFlag.Name:          "older=90000",
+		PruneReceiptsRetentionFlag.Name:         "older=90000",
+		PruneTxIndexRetentionFlag.Name:          "older=90000",
+		PruneCallTracesRetentionFlag.Name:       "older=90000",
+		utils.SnapDownloadProfileFlag.Name:      snapcfg.MinimalProfile.Name,
+		utils.SnapReceiptsFlag.Name:             "false",
+		utils.CaplinArchiveFlag.Name:            "false",
+		utils.CaplinBlobBackfillingFlag.Name:    "false",
+		utils.CaplinDisableBlobPruningFlag.Name: "false",
+		utils.CaplinBlobsArchiveFlag.Name:       "false",
+	},
+}
+
+// SetFlagsFromNodeProfile - sets flags of --profile which are not set by command line or config file
+func SetFlagsFromNodeProfile(ctx *cli.Context) error {
+	name := ctx.String(NodeProfileFlag.Name)
+	if name == "" {
+		return nil
+	}
+	profile, ok := nodeProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(nodeProfiles))
+		for n := range nodeProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown node profile %q, available: %s", name, strings.Join(names, ", "))
+	}
+	for flag, value := range profile {
+		if ctx.IsSet(flag) {
+			if ctx.String(flag) != value {
+				log.Warn("[profile] flag overrides value of node profile", "profile", name, "flag", flag, "value", ctx.String(flag), "profile_value", value)
+			}
+			continue
+		}
+		if err := ctx.Set(flag, value); err != nil {
+			return fmt.Errorf("failed setting %s flag of node profile %s: %w", flag, name, err)
+		}
+	}
+	return nil
+}
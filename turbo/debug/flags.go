@@ -17,16 +17,19 @@
 package debug
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof" //nolint:gosec
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/common/mem"
 	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 
 	"github.com/ledgerwatch/log/v3"
 	"github.com/pelletier/go-toml"
@@ -82,12 +85,40 @@ var (
 		Name:  "trace",
 		Usage: "Write execution trace to the given file",
 	}
+	tracingEndpointFlag = cli.StringFlag{
+		Name:  "tracing.otlp.endpoint",
+		Usage: "Export OpenTelemetry spans of stage loop, domains, commitment and RPC handlers to OTLP/HTTP collector (host:port, e.g. localhost:4318)",
+	}
+	tracingInsecureFlag = cli.BoolFlag{
+		Name:  "tracing.otlp.insecure",
+		Usage: "Connect to OTLP collector over HTTP instead of HTTPS",
+	}
+	tracingSamplingFlag = cli.Float64Flag{
+		Name:  "tracing.sampling",
+		Usage: "Share of sync cycles and RPC requests which are traced, from 0 to 1",
+		Value: 1,
+	}
 )
 
 // Flags holds all command-line flags required for debugging.
 var Flags = []cli.Flag{
 	&pprofFlag, &pprofAddrFlag, &pprofPortFlag,
 	&cpuprofileFlag, &traceFlag,
+	&tracingEndpointFlag, &tracingInsecureFlag, &tracingSamplingFlag,
+}
+
+var tracingShutdown func(context.Context) error
+
+func setupTracing(endpoint string, insecure bool, sampling float64, logger log.Logger) error {
+	if sampling < 0 || sampling > 1 {
+		return fmt.Errorf("invalid --%s=%v, expected value from 0 to 1", tracingSamplingFlag.Name, sampling)
+	}
+	shutdown, err := tracing.Setup(endpoint, insecure, sampling, logger)
+	if err != nil {
+		return err
+	}
+	tracingShutdown = shutdown
+	return nil
 }
 
 // SetupCobra sets up logging, profiling and tracing for cobra commands
@@ -124,6 +155,18 @@ func SetupCobra(cmd *cobra.Command, filePrefix string) log.Logger {
 			return logger
 		}
 	}
+	if endpoint, _ := flags.GetString(tracingEndpointFlag.Name); endpoint != "" {
+		insecure, _ := flags.GetBool(tracingInsecureFlag.Name)
+		sampling, err := flags.GetFloat64(tracingSamplingFlag.Name)
+		if err != nil {
+			log.Error("failed setting config flags from yaml/toml file", "err", err)
+			panic(err)
+		}
+		if err := setupTracing(endpoint, insecure, sampling, logger); err != nil {
+			log.Error("failed setting up tracing", "err", err)
+			panic(err)
+		}
+	}
 
 	go ListenSignals(nil, logger)
 	pprof, err := flags.GetBool(pprofFlag.Name)
@@ -205,6 +248,11 @@ func Setup(ctx *cli.Context, rootLogger bool) (log.Logger, *http.ServeMux, *http
 			return logger, nil, nil, err
 		}
 	}
+	if endpoint := ctx.String(tracingEndpointFlag.Name); endpoint != "" {
+		if err := setupTracing(endpoint, ctx.Bool(tracingInsecureFlag.Name), ctx.Float64(tracingSamplingFlag.Name), logger); err != nil {
+			return logger, nil, nil, err
+		}
+	}
 	pprofEnabled := ctx.Bool(pprofFlag.Name)
 	metricsEnabled := ctx.Bool(metricsEnabledFlag.Name)
 	metricsAddr := ctx.String(metricsAddrFlag.Name)
@@ -275,6 +323,11 @@ func StartPProf(address string, metricsMux *http.ServeMux) *http.ServeMux {
 func Exit() {
 	_ = Handler.StopCPUProfile()
 	_ = Handler.StopGoTrace()
+	if tracingShutdown != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tracingShutdown(ctx)
+	}
 }
 
 // RaiseFdLimit raises out the number of allowed file handles per process
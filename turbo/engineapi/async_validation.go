@@ -0,0 +1,120 @@
+package engineapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/ledgerwatch/log/v3"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+const asyncValidationResults = 128
+
+// forkchoiceValidationWait - how long engine_forkchoiceUpdated waits for validation of its head: half of CL's timeout
+// of the call (8s), then it answers SYNCING as execution module is busy
+const forkchoiceValidationWait = 4 * time.Second
+
+// asyncValidator - lets engine_newPayload answer SYNCING when validation of inserted payload doesn't finish in
+// `wait` (see `--engine.asyncvalidation`). Validation goes on in background and its result is returned by following
+// engine_newPayload for the same block, engine_forkchoiceUpdated waits for validation of its head
+type asyncValidator struct {
+	ctx        context.Context // validations are cancelled by stop, not by requests which started them
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	wait       time.Duration
+	validating sync.Mutex // validations are serialized: execution module validates one chain at a time
+
+	lock    sync.Mutex
+	pending map[libcommon.Hash]chan struct{}
+	results *lru.Cache[libcommon.Hash, *engine_types.PayloadStatus]
+	logger  log.Logger
+}
+
+func newAsyncValidator(ctx context.Context, wait time.Duration, logger log.Logger) *asyncValidator {
+	results, _ := lru.New[libcommon.Hash, *engine_types.PayloadStatus](asyncValidationResults)
+	ctx, cancel := context.WithCancel(ctx)
+	return &asyncValidator{ctx: ctx, cancel: cancel, wait: wait, pending: map[libcommon.Hash]chan struct{}{}, results: results, logger: logger}
+}
+
+// stop - cancels validations in progress and waits for them
+func (v *asyncValidator) stop() {
+	v.lock.Lock() // run doesn't start validation after cancel
+	v.cancel()
+	v.lock.Unlock()
+	v.wg.Wait()
+}
+
+// run - result of `validate` of block `hash` if it's known or ready in `wait`, SYNCING otherwise. `validate` runs
+// once per block at a time, with validator's context instead of request's one
+func (v *asyncValidator) run(hash libcommon.Hash, validate func(ctx context.Context) (*engine_types.PayloadStatus, error)) (*engine_types.PayloadStatus, error) {
+	v.lock.Lock()
+	if status, ok := v.results.Get(hash); ok {
+		v.lock.Unlock()
+		return status, nil
+	}
+	if v.ctx.Err() != nil {
+		v.lock.Unlock()
+		return nil, v.ctx.Err()
+	}
+	done, ok := v.pending[hash]
+	if !ok {
+		done = make(chan struct{})
+		v.pending[hash] = done
+		v.wg.Add(1)
+		go func() {
+			defer v.wg.Done()
+			v.validating.Lock()
+			status, err := validate(v.ctx)
+			v.validating.Unlock()
+
+			v.lock.Lock()
+			defer v.lock.Unlock()
+			switch {
+			case v.ctx.Err() != nil:
+				v.logger.Debug("[NewPayload] async validation cancelled", "hash", hash)
+			case err != nil:
+				v.logger.Warn("[NewPayload] async validation failed", "hash", hash, "err", err)
+			case status.Status == engine_types.ValidStatus || status.Status == engine_types.InvalidStatus:
+				v.results.Add(hash, status)
+			default: // busy execution or missing parent, following newPayload validates again
+				v.logger.Debug("[NewPayload] async validation not finished", "hash", hash, "status", status.Status)
+			}
+			delete(v.pending, hash)
+			close(done)
+		}()
+	}
+	v.lock.Unlock()
+
+	select {
+	case <-done:
+		if status, ok := v.results.Get(hash); ok {
+			return status, nil
+		}
+	case <-time.After(v.wait):
+		v.logger.Debug("[NewPayload] validation continues in background", "hash", hash)
+	}
+	return &engine_types.PayloadStatus{Status: engine_types.SyncingStatus}, nil
+}
+
+// waitFor - waits at most `timeout` for validation of block `hash` if it's in progress. false - validation isn't finished
+func (v *asyncValidator) waitFor(ctx context.Context, hash libcommon.Hash, timeout time.Duration) bool {
+	v.lock.Lock()
+	done, ok := v.pending[hash]
+	v.lock.Unlock()
+	if !ok {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+	case <-v.ctx.Done():
+	case <-time.After(timeout):
+	}
+	return false
+}
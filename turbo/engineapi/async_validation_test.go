@@ -0,0 +1,79 @@
+package engineapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+func TestAsyncValidator(t *testing.T) {
+	v := newAsyncValidator(context.Background(), 50*time.Millisecond, log.New())
+	valid := &engine_types.PayloadStatus{Status: engine_types.ValidStatus}
+
+	// fast validation is answered right away
+	status, err := v.run(libcommon.Hash{1}, func(context.Context) (*engine_types.PayloadStatus, error) { return valid, nil })
+	require.NoError(t, err)
+	require.Equal(t, valid, status)
+
+	// slow one is answered with SYNCING and its result by the following call
+	release := make(chan struct{})
+	calls := 0
+	slow := func(_ context.Context) (*engine_types.PayloadStatus, error) {
+		calls++
+		<-release
+		return valid, nil
+	}
+	status, err = v.run(libcommon.Hash{2}, slow)
+	require.NoError(t, err)
+	require.Equal(t, engine_types.SyncingStatus, status.Status)
+	status, err = v.run(libcommon.Hash{2}, slow)
+	require.NoError(t, err)
+	require.Equal(t, engine_types.SyncingStatus, status.Status)
+
+	// forkchoiceUpdated doesn't wait for validation longer than given timeout
+	require.False(t, v.waitFor(context.Background(), libcommon.Hash{2}, 10*time.Millisecond))
+	close(release)
+	require.True(t, v.waitFor(context.Background(), libcommon.Hash{2}, time.Minute))
+	status, err = v.run(libcommon.Hash{2}, slow)
+	require.NoError(t, err)
+	require.Equal(t, valid, status)
+	require.Equal(t, 1, calls)
+
+	// failed validation isn't remembered
+	status, err = v.run(libcommon.Hash{3}, func(context.Context) (*engine_types.PayloadStatus, error) { return nil, errors.New("db") })
+	require.NoError(t, err)
+	require.Equal(t, engine_types.SyncingStatus, status.Status)
+	status, err = v.run(libcommon.Hash{3}, func(context.Context) (*engine_types.PayloadStatus, error) { return valid, nil })
+	require.NoError(t, err)
+	require.Equal(t, valid, status)
+}
+
+func TestAsyncValidatorStop(t *testing.T) {
+	v := newAsyncValidator(context.Background(), 10*time.Millisecond, log.New())
+
+	// validation outlives request, but not the server
+	started := make(chan struct{})
+	status, err := v.run(libcommon.Hash{1}, func(ctx context.Context) (*engine_types.PayloadStatus, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+	require.Equal(t, engine_types.SyncingStatus, status.Status)
+	<-started
+	v.stop() // returns only when validation is cancelled
+
+	_, err = v.run(libcommon.Hash{2}, func(context.Context) (*engine_types.PayloadStatus, error) {
+		t.Fatal("validation after stop")
+		return nil, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
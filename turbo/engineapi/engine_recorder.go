@@ -0,0 +1,74 @@
+package engineapi
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+)
+
+// RecordedCall - engine API call persisted by `--engine.record`, one JSON object per line of recording. Recording is
+// replayed by `rpctest replayEngine`
+type RecordedCall struct {
+	Time     time.Time         `json:"time"`
+	Method   string            `json:"method"`
+	Params   []json.RawMessage `json:"params"`
+	Response json.RawMessage   `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// engineRecorder - appends engine_newPayload and engine_forkchoiceUpdated calls of CL with EL responses to file,
+// to reproduce consensus issues without the original CL. Every call is written right away: recording is useful
+// exactly when the node crashes or stalls
+type engineRecorder struct {
+	lock   sync.Mutex
+	file   *os.File
+	logger log.Logger
+}
+
+func newEngineRecorder(path string, logger log.Logger) (*engineRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &engineRecorder{file: file, logger: logger}, nil
+}
+
+// record - no-op on nil recorder, failures are logged: recording mustn't affect CL
+func (r *engineRecorder) record(method string, params []any, response any, callErr error) {
+	if r == nil {
+		return
+	}
+	call := RecordedCall{Time: time.Now().UTC(), Method: method, Params: make([]json.RawMessage, len(params))}
+	for i, param := range params {
+		encoded, err := json.Marshal(param)
+		if err != nil {
+			r.logger.Warn("[EngineRecorder] can't encode call", "method", method, "err", err)
+			return
+		}
+		call.Params[i] = encoded
+	}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	} else {
+		encoded, err := json.Marshal(response)
+		if err != nil {
+			r.logger.Warn("[EngineRecorder] can't encode response", "method", method, "err", err)
+			return
+		}
+		call.Response = encoded
+	}
+	line, err := json.Marshal(call)
+	if err != nil {
+		r.logger.Warn("[EngineRecorder] can't encode call", "method", method, "err", err)
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, err = r.file.Write(append(line, '\n')); err != nil {
+		r.logger.Warn("[EngineRecorder] can't write call", "file", r.file.Name(), "err", err)
+	}
+}
@@ -0,0 +1,53 @@
+package engineapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+func TestEngineRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.jsonl")
+	recorder, err := newEngineRecorder(path, log.New())
+	require.NoError(t, err)
+
+	forkChoice := &engine_types.ForkChoiceState{HeadHash: libcommon.Hash{1}}
+	status := &engine_types.PayloadStatus{Status: engine_types.ValidStatus}
+	recorder.record("engine_forkchoiceUpdatedV3", []any{forkChoice, nil}, &engine_types.ForkChoiceUpdatedResponse{PayloadStatus: status}, nil)
+	recorder.record("engine_newPayloadV3", []any{&engine_types.ExecutionPayload{}}, nil, errors.New("unsupported fork"))
+	(*engineRecorder)(nil).record("engine_newPayloadV1", nil, status, nil)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var calls []RecordedCall
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var call RecordedCall
+		require.NoError(t, json.Unmarshal(s.Bytes(), &call))
+		calls = append(calls, call)
+	}
+	require.Len(t, calls, 2)
+
+	require.Equal(t, "engine_forkchoiceUpdatedV3", calls[0].Method)
+	require.Len(t, calls[0].Params, 2)
+	var recordedForkChoice engine_types.ForkChoiceState
+	require.NoError(t, json.Unmarshal(calls[0].Params[0], &recordedForkChoice))
+	require.Equal(t, *forkChoice, recordedForkChoice)
+	require.Equal(t, "null", string(calls[0].Params[1]))
+	var response engine_types.ForkChoiceUpdatedResponse
+	require.NoError(t, json.Unmarshal(calls[0].Response, &response))
+	require.Equal(t, engine_types.ValidStatus, response.PayloadStatus.Status)
+
+	require.Equal(t, "unsupported fork", calls[1].Error)
+	require.Empty(t, calls[1].Response)
+}
@@ -54,6 +54,12 @@ type EngineServer struct {
 	test             bool
 	caplin           bool // we need to send errors for caplin.
 	executionService execution.ExecutionClient
+	blobsReader      jsonrpc.BlobsReader // nil - if embedded Caplin is not enabled
+	builders         *ExternalBuilders   // nil - if no external builders are configured
+	builderRequests  map[uint64]builderRequest
+	asyncValidation  *asyncValidator // nil - newPayload waits for validation
+	witnesses        *WitnessAPI     // nil - execution witnesses aren't served
+	recorder         *engineRecorder // nil - calls aren't recorded
 
 	chainRW eth1_chain_reader.ChainReaderWriterEth1
 	lock    sync.Mutex
@@ -64,13 +70,15 @@ const fcuTimeout = 1000 // according to mathematics: 1000 millisecods = 1 second
 
 func NewEngineServer(logger log.Logger, config *chain.Config, executionService execution.ExecutionClient,
 	hd *headerdownload.HeaderDownload,
-	blockDownloader *engine_block_downloader.EngineBlockDownloader, caplin, test, proposing bool) *EngineServer {
+	blockDownloader *engine_block_downloader.EngineBlockDownloader, builders *ExternalBuilders, caplin, test, proposing bool) *EngineServer {
 	chainRW := eth1_chain_reader.NewChainReaderEth1(config, executionService, fcuTimeout)
 	return &EngineServer{
 		logger:           logger,
 		config:           config,
 		executionService: executionService,
 		blockDownloader:  blockDownloader,
+		builders:         builders,
+		builderRequests:  map[uint64]builderRequest{},
 		chainRW:          chainRW,
 		proposing:        proposing,
 		hd:               hd,
@@ -78,6 +86,34 @@ func NewEngineServer(logger log.Logger, config *chain.Config, executionService e
 	}
 }
 
+// EnableAsyncValidation - newPayload answers SYNCING if validation of payload takes longer than `wait`. Background
+// validations are cancelled with `ctx` or by Stop
+func (e *EngineServer) EnableAsyncValidation(ctx context.Context, wait time.Duration) {
+	e.asyncValidation = newAsyncValidator(ctx, wait, e.logger)
+}
+
+// Stop - cancels background work of the server and waits for it
+func (e *EngineServer) Stop() {
+	if e.asyncValidation != nil {
+		e.asyncValidation.stop()
+	}
+}
+
+// EnableRecording - engine_newPayload and engine_forkchoiceUpdated calls are appended to file `path`
+func (e *EngineServer) EnableRecording(path string) error {
+	recorder, err := newEngineRecorder(path, e.logger)
+	if err != nil {
+		return err
+	}
+	e.recorder = recorder
+	return nil
+}
+
+// EnableWitnesses - execution witnesses of new heads are generated and served by `debug_executionWitness`
+func (e *EngineServer) EnableWitnesses(generate WitnessGenerateFunc) {
+	e.witnesses = newWitnessAPI(generate, e.logger)
+}
+
 func (e *EngineServer) Start(
 	ctx context.Context,
 	httpConfig *httpcfg.HttpCfg,
@@ -90,7 +126,9 @@ func (e *EngineServer) Start(
 	eth rpchelper.ApiBackend,
 	txPool txpool.TxpoolClient,
 	mining txpool.MiningClient,
+	blobsReader jsonrpc.BlobsReader,
 ) {
+	e.blobsReader = blobsReader
 	base := jsonrpc.NewBaseApi(filters, stateCache, blockReader, agg, httpConfig.WithDatadir, httpConfig.EvmCallTimeout, engineReader, httpConfig.Dirs)
 
 	ethImpl := jsonrpc.NewEthAPI(base, db, eth, txPool, mining, httpConfig.Gascap, httpConfig.ReturnDataLimit, httpConfig.AllowUnprotectedTxs, httpConfig.MaxGetProofRewindBlockCount, httpConfig.WebsocketSubscribeLogsChannelSize, e.logger)
@@ -109,6 +147,16 @@ func (e *EngineServer) Start(
 			Service:   EngineAPI(e),
 			Version:   "1.0",
 		}}
+	if e.witnesses != nil {
+		e.witnesses.db = db
+		go e.witnesses.loop(ctx)
+		apiList = append(apiList, rpc.API{
+			Namespace: "debug",
+			Public:    true,
+			Service:   e.witnesses,
+			Version:   "1.0",
+		})
+	}
 
 	if err := cli.StartRpcServerWithJwtAuthentication(ctx, httpConfig, apiList, e.logger); err != nil {
 		e.logger.Error(err.Error())
@@ -133,32 +181,12 @@ func (s *EngineServer) newPayload(ctx context.Context, req *engine_types.Executi
 		s.logger.Crit(caplinEnabledLog)
 		return nil, errCaplinEnabled
 	}
-	var bloom types.Bloom
-	copy(bloom[:], req.LogsBloom)
-
 	txs := [][]byte{}
 	for _, transaction := range req.Transactions {
 		txs = append(txs, transaction)
 	}
 
-	header := types.Header{
-		ParentHash:  req.ParentHash,
-		Coinbase:    req.FeeRecipient,
-		Root:        req.StateRoot,
-		Bloom:       bloom,
-		BaseFee:     (*big.Int)(req.BaseFeePerGas),
-		Extra:       req.ExtraData,
-		Number:      big.NewInt(int64(req.BlockNumber)),
-		GasUsed:     uint64(req.GasUsed),
-		GasLimit:    uint64(req.GasLimit),
-		Time:        uint64(req.Timestamp),
-		MixDigest:   req.PrevRandao,
-		UncleHash:   types.EmptyUncleHash,
-		Difficulty:  merge.ProofOfStakeDifficulty,
-		Nonce:       merge.ProofOfStakeNonce,
-		ReceiptHash: req.ReceiptsRoot,
-		TxHash:      types.DeriveSha(types.BinaryTransactions(txs)),
-	}
+	header := payloadHeader(req, txs)
 	var withdrawals []*types.Withdrawal
 	if version >= clparams.CapellaVersion {
 		withdrawals = req.Withdrawals
@@ -281,6 +309,31 @@ func (s *EngineServer) newPayload(ctx context.Context, req *engine_types.Executi
 	return payloadStatus, nil
 }
 
+// payloadHeader - header of execution payload, without withdrawals hash and fields added in Dencun
+func payloadHeader(req *engine_types.ExecutionPayload, txs [][]byte) types.Header {
+	var bloom types.Bloom
+	copy(bloom[:], req.LogsBloom)
+
+	return types.Header{
+		ParentHash:  req.ParentHash,
+		Coinbase:    req.FeeRecipient,
+		Root:        req.StateRoot,
+		Bloom:       bloom,
+		BaseFee:     (*big.Int)(req.BaseFeePerGas),
+		Extra:       req.ExtraData,
+		Number:      big.NewInt(int64(req.BlockNumber)),
+		GasUsed:     uint64(req.GasUsed),
+		GasLimit:    uint64(req.GasLimit),
+		Time:        uint64(req.Timestamp),
+		MixDigest:   req.PrevRandao,
+		UncleHash:   types.EmptyUncleHash,
+		Difficulty:  merge.ProofOfStakeDifficulty,
+		Nonce:       merge.ProofOfStakeNonce,
+		ReceiptHash: req.ReceiptsRoot,
+		TxHash:      types.DeriveSha(types.BinaryTransactions(txs)),
+	}
+}
+
 // Check if we can quickly determine the status of a newPayload or forkchoiceUpdated.
 func (s *EngineServer) getQuickPayloadStatusIfPossible(ctx context.Context, blockHash libcommon.Hash, blockNumber uint64, parentHash libcommon.Hash, forkchoiceMessage *engine_types.ForkChoiceState, newPayload bool) (*engine_types.PayloadStatus, error) {
 	// Determine which prefix to use for logs
@@ -416,6 +469,13 @@ func (s *EngineServer) getPayload(ctx context.Context, payloadId uint64, version
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.logger.Debug("[GetPayload] lock acquired")
+
+	var bids chan []*engine_types.GetPayloadResponse
+	if req, ok := s.builderRequests[payloadId]; ok && s.builders != nil {
+		bids = make(chan []*engine_types.GetPayloadResponse, 1)
+		go func() { bids <- s.builders.fetch(ctx, req) }()
+	}
+
 	resp, err := s.executionService.GetAssembledBlock(ctx, &execution.GetAssembledBlockRequest{
 		Id: payloadId,
 	})
@@ -440,11 +500,15 @@ func (s *EngineServer) getPayload(ctx context.Context, payloadId uint64, version
 		return nil, &rpc.UnsupportedForkError{Message: "Unsupported fork"}
 	}
 
-	return &engine_types.GetPayloadResponse{
+	local := &engine_types.GetPayloadResponse{
 		ExecutionPayload: engine_types.ConvertPayloadFromRpc(data.ExecutionPayload),
 		BlockValue:       (*hexutil.Big)(gointerfaces.ConvertH256ToUint256Int(data.BlockValue).ToBig()),
 		BlobsBundle:      engine_types.ConvertBlobsFromRpc(data.BlobsBundle),
-	}, nil
+	}
+	if bids == nil {
+		return local, nil
+	}
+	return s.builders.choose(local, <-bids), nil
 }
 
 // engineForkChoiceUpdated either states new block head or request the assembling of a new block
@@ -454,6 +518,11 @@ func (s *EngineServer) forkchoiceUpdated(ctx context.Context, forkchoiceState *e
 		s.logger.Crit("[NewPayload] caplin is enabled")
 		return nil, errCaplinEnabled
 	}
+	if s.asyncValidation != nil {
+		if !s.asyncValidation.waitFor(ctx, forkchoiceState.HeadHash, forkchoiceValidationWait) {
+			s.logger.Debug("[ForkChoiceUpdated] head is still being validated", "head", forkchoiceState.HeadHash)
+		}
+	}
 	status, err := s.getQuickPayloadStatusIfPossible(ctx, forkchoiceState.HeadHash, 0, libcommon.Hash{}, forkchoiceState, false)
 	if err != nil {
 		return nil, err
@@ -483,6 +552,10 @@ func (s *EngineServer) forkchoiceUpdated(ctx context.Context, forkchoiceState *e
 		}
 	}
 
+	if s.witnesses != nil && status.Status == engine_types.ValidStatus {
+		s.witnesses.notify(forkchoiceState.HeadHash)
+	}
+
 	// No need for payload building
 	if payloadAttributes == nil || status.Status != engine_types.ValidStatus {
 		return &engine_types.ForkChoiceUpdatedResponse{PayloadStatus: status}, nil
@@ -535,6 +608,9 @@ func (s *EngineServer) forkchoiceUpdated(ctx context.Context, forkchoiceState *e
 	if resp.Busy {
 		return nil, errors.New("[ForkChoiceUpdated]: execution service is busy, cannot assemble blocks")
 	}
+	if s.builders != nil {
+		s.addBuilderRequest(resp.Id, builderRequest{parentHash: forkchoiceState.HeadHash, attributes: payloadAttributes, version: version})
+	}
 	return &engine_types.ForkChoiceUpdatedResponse{
 		PayloadStatus: &engine_types.PayloadStatus{
 			Status:          engine_types.ValidStatus,
@@ -544,6 +620,16 @@ func (s *EngineServer) forkchoiceUpdated(ctx context.Context, forkchoiceState *e
 	}, nil
 }
 
+// addBuilderRequest - remembers what payload is built from to ask external builders for the same one in getPayload.
+// Like local builders, at most MaxBuilders are kept
+func (s *EngineServer) addBuilderRequest(payloadId uint64, req builderRequest) {
+	s.builderRequests[payloadId] = req
+	ids := libcommon.SortedKeys(s.builderRequests)
+	for i := 0; i < len(ids)-engine_helpers.MaxBuilders; i++ {
+		delete(s.builderRequests, ids[i])
+	}
+}
+
 func (s *EngineServer) getPayloadBodiesByHash(ctx context.Context, request []libcommon.Hash, _ clparams.StateVersion) ([]*engine_types.ExecutionPayloadBodyV1, error) {
 	bodies, err := s.chainRW.GetBodiesByHashes(ctx, request)
 	if err != nil {
@@ -622,38 +708,50 @@ func (e *EngineServer) GetPayloadV3(ctx context.Context, payloadID hexutility.By
 // (asynchronously updated with transactions), if payloadAttributes is not nil and passes validation
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/paris.md#engine_forkchoiceupdatedv1
 func (e *EngineServer) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.BellatrixVersion)
+	resp, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.BellatrixVersion)
+	e.recorder.record("engine_forkchoiceUpdatedV1", []any{forkChoiceState, payloadAttributes}, resp, err)
+	return resp, err
 }
 
 // Same as, and a replacement for, [ForkchoiceUpdatedV1], post Shanghai
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_forkchoiceupdatedv2
 func (e *EngineServer) ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.CapellaVersion)
+	resp, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.CapellaVersion)
+	e.recorder.record("engine_forkchoiceUpdatedV2", []any{forkChoiceState, payloadAttributes}, resp, err)
+	return resp, err
 }
 
 // Successor of [ForkchoiceUpdatedV2] post Cancun, with stricter check on params
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_forkchoiceupdatedv3
 func (e *EngineServer) ForkchoiceUpdatedV3(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.DenebVersion)
+	resp, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.DenebVersion)
+	e.recorder.record("engine_forkchoiceUpdatedV3", []any{forkChoiceState, payloadAttributes}, resp, err)
+	return resp, err
 }
 
 // NewPayloadV1 processes new payloads (blocks) from the beacon chain without withdrawals.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/paris.md#engine_newpayloadv1
 func (e *EngineServer) NewPayloadV1(ctx context.Context, payload *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, nil, nil, clparams.BellatrixVersion)
+	status, err := e.newPayload(ctx, payload, nil, nil, clparams.BellatrixVersion)
+	e.recorder.record("engine_newPayloadV1", []any{payload}, status, err)
+	return status, err
 }
 
 // NewPayloadV2 processes new payloads (blocks) from the beacon chain with withdrawals.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_newpayloadv2
 func (e *EngineServer) NewPayloadV2(ctx context.Context, payload *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, nil, nil, clparams.CapellaVersion)
+	status, err := e.newPayload(ctx, payload, nil, nil, clparams.CapellaVersion)
+	e.recorder.record("engine_newPayloadV2", []any{payload}, status, err)
+	return status, err
 }
 
 // NewPayloadV3 processes new payloads (blocks) from the beacon chain with withdrawals & blob gas.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_newpayloadv3
 func (e *EngineServer) NewPayloadV3(ctx context.Context, payload *engine_types.ExecutionPayload,
 	expectedBlobHashes []libcommon.Hash, parentBeaconBlockRoot *libcommon.Hash) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, clparams.DenebVersion)
+	status, err := e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, clparams.DenebVersion)
+	e.recorder.record("engine_newPayloadV3", []any{payload, expectedBlobHashes, parentBeaconBlockRoot}, status, err)
+	return status, err
 }
 
 // Receives consensus layer's transition configuration and checks if the execution layer has the correct configuration.
@@ -703,6 +801,30 @@ func (e *EngineServer) GetPayloadBodiesByRangeV1(ctx context.Context, start, cou
 	return e.getPayloadBodiesByRange(ctx, uint64(start), uint64(count), clparams.CapellaVersion)
 }
 
+// Returns blobs and proofs by versioned hashes, null for blob which is not available.
+// Blobs are read from embedded Caplin's blob storage: after retention window only with `--caplin.blobs-archive`
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_getblobsv1
+func (e *EngineServer) GetBlobsV1(ctx context.Context, blobHashes []libcommon.Hash) ([]*engine_types.BlobAndProofV1, error) {
+	if len(blobHashes) > 128 {
+		return nil, &engine_helpers.TooLargeRequestErr
+	}
+	res := make([]*engine_types.BlobAndProofV1, len(blobHashes))
+	if e.blobsReader == nil {
+		return res, nil
+	}
+	for i, blobHash := range blobHashes {
+		blob, proof, err := e.blobsReader.BlobAndProof(ctx, blobHash)
+		if err != nil {
+			return nil, err
+		}
+		if blob == nil {
+			continue
+		}
+		res[i] = &engine_types.BlobAndProofV1{Blob: blob, Proof: proof}
+	}
+	return res, nil
+}
+
 var ourCapabilities = []string{
 	"engine_forkchoiceUpdatedV1",
 	"engine_forkchoiceUpdatedV2",
@@ -716,6 +838,7 @@ var ourCapabilities = []string{
 	"engine_exchangeTransitionConfigurationV1",
 	"engine_getPayloadBodiesByHashV1",
 	"engine_getPayloadBodiesByRangeV1",
+	"engine_getBlobsV1",
 }
 
 func (e *EngineServer) ExchangeCapabilities(fromCl []string) []string {
@@ -804,6 +927,17 @@ func (e *EngineServer) HandleNewPayload(
 		return &engine_types.PayloadStatus{Status: engine_types.AcceptedStatus}, nil
 	}
 
+	if e.asyncValidation != nil {
+		return e.asyncValidation.run(headerHash, func(ctx context.Context) (*engine_types.PayloadStatus, error) {
+			return e.validatePayload(ctx, logPrefix, block)
+		})
+	}
+	return e.validatePayload(ctx, logPrefix, block)
+}
+
+// validatePayload - executes inserted payload on top of its parent
+func (e *EngineServer) validatePayload(ctx context.Context, logPrefix string, block *types.Block) (*engine_types.PayloadStatus, error) {
+	headerHash, headerNumber := block.Hash(), block.NumberU64()
 	e.logger.Debug(fmt.Sprintf("[%s] New payload begin verification", logPrefix))
 	status, validationErr, latestValidHash, err := e.chainRW.ValidateChain(ctx, headerHash, headerNumber)
 	e.logger.Debug(fmt.Sprintf("[%s] New payload verification ended", logPrefix), "status", status.String(), "err", err)
@@ -12,6 +12,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	execution "github.com/ledgerwatch/erigon-lib/gointerfaces/executionproto"
 	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
+	"github.com/ledgerwatch/erigon/core/stateless"
 	"github.com/ledgerwatch/erigon/core/types"
 )
 
@@ -71,6 +72,19 @@ type ExecutionPayloadBodyV1 struct {
 	Withdrawals  []*types.Withdrawal `json:"withdrawals"  gencodec:"required"`
 }
 
+type BlobAndProofV1 struct {
+	Blob  hexutility.Bytes `json:"blob" gencodec:"required"`
+	Proof hexutility.Bytes `json:"proof" gencodec:"required"`
+}
+
+// ExecutionWitness - state needed to execute a block statelessly: ancestor headers (parent first), bytecodes and
+// state trie nodes proving accessed accounts and storage against parent's state root
+type ExecutionWitness struct {
+	Headers []*types.Header    `json:"headers" gencodec:"required"`
+	Codes   []hexutility.Bytes `json:"codes"   gencodec:"required"`
+	State   []hexutility.Bytes `json:"state"   gencodec:"required"`
+}
+
 type PayloadStatus struct {
 	Status          EngineStatus      `json:"status" gencodec:"required"`
 	ValidationError *StringifiedError `json:"validationError"`
@@ -249,3 +263,18 @@ func ConvertPayloadId(payloadId uint64) *hexutility.Bytes {
 	ret := hexutility.Bytes(encodedPayloadId)
 	return &ret
 }
+
+func ConvertWitnessToRpc(witness *stateless.Witness) *ExecutionWitness {
+	res := &ExecutionWitness{
+		Headers: witness.Headers,
+		Codes:   make([]hexutility.Bytes, len(witness.Codes)),
+		State:   make([]hexutility.Bytes, len(witness.State)),
+	}
+	for i, code := range witness.Codes {
+		res.Codes[i] = code
+	}
+	for i, node := range witness.State {
+		res.State[i] = node
+	}
+	return res
+}
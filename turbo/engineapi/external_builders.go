@@ -0,0 +1,190 @@
+package engineapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+// builderGetPayload - method served by external builders: (parentHash, payloadAttributes) -> GetPayloadResponse
+const builderGetPayload = "builder_getPayload"
+
+// ExternalBuilders - asks external builders (`--builder.urls`) for payloads on top of the same parent and with the
+// same attributes as locally built one, in parallel with local building. engine_getPayload returns the most
+// valuable builder payload which passes policies (`--builder.minbid`, `--builder.denylist`) if it's worth more
+// than the local one - local payload is the fallback for slow, failing or cheating builders
+type ExternalBuilders struct {
+	urls     []string
+	clients  []*rpc.Client
+	minBid   *big.Int
+	denyList map[libcommon.Address]struct{}
+	timeout  time.Duration
+	signer   *types.Signer
+	logger   log.Logger
+}
+
+// builderRequest - what payload with given id is built from
+type builderRequest struct {
+	parentHash libcommon.Hash
+	attributes *engine_types.PayloadAttributes
+	version    clparams.StateVersion
+}
+
+// NewExternalBuilders - nil if no builders are configured
+func NewExternalBuilders(cfg *params.MiningConfig, chainConfig *chain.Config, logger log.Logger) (*ExternalBuilders, error) {
+	if cfg == nil || len(cfg.Builders) == 0 {
+		return nil, nil
+	}
+	b := &ExternalBuilders{
+		urls:     cfg.Builders,
+		minBid:   cfg.BuilderMinBid,
+		denyList: make(map[libcommon.Address]struct{}, len(cfg.BuilderDenyList)),
+		timeout:  cfg.BuilderTimeout,
+		signer:   types.LatestSigner(chainConfig),
+		logger:   logger,
+	}
+	for _, url := range cfg.Builders {
+		client, err := rpc.DialHTTP(url, logger)
+		if err != nil {
+			return nil, fmt.Errorf("builder %s: %w", url, err)
+		}
+		b.clients = append(b.clients, client)
+	}
+	for _, addr := range cfg.BuilderDenyList {
+		b.denyList[addr] = struct{}{}
+	}
+	return b, nil
+}
+
+// fetch - asks all builders in parallel, waits for them not longer than configured timeout. Only valid payloads
+// are returned, index of payload is index of builder
+func (b *ExternalBuilders) fetch(ctx context.Context, req builderRequest) []*engine_types.GetPayloadResponse {
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	bids := make([]*engine_types.GetPayloadResponse, len(b.clients))
+	var wg sync.WaitGroup
+	for i, client := range b.clients {
+		wg.Add(1)
+		go func(i int, client *rpc.Client) {
+			defer wg.Done()
+			var bid engine_types.GetPayloadResponse
+			if err := client.CallContext(ctx, &bid, builderGetPayload, req.parentHash, req.attributes); err != nil {
+				b.logger.Debug("[GetPayload] builder failed", "builder", b.urls[i], "err", err)
+				return
+			}
+			if err := b.validate(&bid, req); err != nil {
+				b.logger.Warn("[GetPayload] builder payload rejected", "builder", b.urls[i], "err", err)
+				return
+			}
+			bids[i] = &bid
+		}(i, client)
+	}
+	wg.Wait()
+	return bids
+}
+
+// choose - the most valuable of bids if it's worth more than `local` and not less than min bid, `local` otherwise
+func (b *ExternalBuilders) choose(local *engine_types.GetPayloadResponse, bids []*engine_types.GetPayloadResponse) *engine_types.GetPayloadResponse {
+	best, builder := local, -1
+	for i, bid := range bids {
+		if bid == nil {
+			continue
+		}
+		if b.minBid != nil && bid.BlockValue.ToInt().Cmp(b.minBid) < 0 {
+			b.logger.Debug("[GetPayload] builder bid below minimum", "builder", b.urls[i], "value", bid.BlockValue, "min", b.minBid)
+			continue
+		}
+		if bid.BlockValue.ToInt().Cmp(best.BlockValue.ToInt()) > 0 {
+			best, builder = bid, i
+		}
+	}
+	if builder < 0 {
+		b.logger.Info("[GetPayload] local payload chosen", "value", local.BlockValue)
+		return local
+	}
+	b.logger.Info("[GetPayload] builder payload chosen", "builder", b.urls[builder], "value", best.BlockValue, "local", local.BlockValue)
+	return best
+}
+
+// validate - builder payload must be built on requested parent with requested attributes and must pay to requested
+// fee recipient: claimed block value can't be checked without execution, so it's up to builder's reputation
+func (b *ExternalBuilders) validate(bid *engine_types.GetPayloadResponse, req builderRequest) error {
+	payload, attributes := bid.ExecutionPayload, req.attributes
+	if payload == nil || bid.BlockValue == nil {
+		return errors.New("missing payload or block value")
+	}
+	if payload.ParentHash != req.parentHash {
+		return fmt.Errorf("parent hash %x, expected %x", payload.ParentHash, req.parentHash)
+	}
+	if uint64(payload.Timestamp) != uint64(attributes.Timestamp) || payload.PrevRandao != attributes.PrevRandao {
+		return errors.New("timestamp or prevRandao don't match attributes")
+	}
+	if payload.FeeRecipient != attributes.SuggestedFeeRecipient {
+		return fmt.Errorf("fee recipient %x, expected %x", payload.FeeRecipient, attributes.SuggestedFeeRecipient)
+	}
+
+	txs := make([][]byte, len(payload.Transactions))
+	for i := range payload.Transactions {
+		txs[i] = payload.Transactions[i]
+	}
+	header := payloadHeader(payload, txs)
+	if req.version >= clparams.CapellaVersion {
+		wh := types.DeriveSha(types.Withdrawals(payload.Withdrawals))
+		if wh != types.DeriveSha(types.Withdrawals(attributes.Withdrawals)) {
+			return errors.New("withdrawals don't match attributes")
+		}
+		header.WithdrawalsHash = &wh
+	}
+	if req.version >= clparams.DenebVersion {
+		if payload.BlobGasUsed == nil || payload.ExcessBlobGas == nil || bid.BlobsBundle == nil {
+			return errors.New("blobGasUsed/excessBlobGas/blobsBundle missing")
+		}
+		header.BlobGasUsed = (*uint64)(payload.BlobGasUsed)
+		header.ExcessBlobGas = (*uint64)(payload.ExcessBlobGas)
+		header.ParentBeaconBlockRoot = attributes.ParentBeaconBlockRoot
+	}
+	if header.Hash() != payload.BlockHash {
+		return fmt.Errorf("block hash %x, actual %x", payload.BlockHash, header.Hash())
+	}
+
+	if len(b.denyList) == 0 {
+		return nil
+	}
+	transactions, err := types.DecodeTransactions(txs)
+	if err != nil {
+		return err
+	}
+	for _, txn := range transactions {
+		sender, err := b.signer.Sender(txn)
+		if err != nil {
+			return err
+		}
+		if _, ok := b.denyList[sender]; ok {
+			return fmt.Errorf("transaction %x from denied %x", txn.Hash(), sender)
+		}
+		if to := txn.GetTo(); to != nil {
+			if _, ok := b.denyList[*to]; ok {
+				return fmt.Errorf("transaction %x to denied %x", txn.Hash(), *to)
+			}
+		}
+	}
+	return nil
+}
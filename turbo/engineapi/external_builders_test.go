@@ -0,0 +1,114 @@
+package engineapi
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+type testBuilder struct {
+	value        int64
+	feeRecipient *libcommon.Address // nil - requested one
+}
+
+func (b *testBuilder) GetPayload(_ context.Context, parentHash libcommon.Hash, attributes *engine_types.PayloadAttributes) (*engine_types.GetPayloadResponse, error) {
+	payload := &engine_types.ExecutionPayload{
+		ParentHash:    parentHash,
+		FeeRecipient:  attributes.SuggestedFeeRecipient,
+		PrevRandao:    attributes.PrevRandao,
+		Timestamp:     attributes.Timestamp,
+		BlockNumber:   1,
+		GasLimit:      30_000_000,
+		BaseFeePerGas: (*hexutil.Big)(big.NewInt(7)),
+	}
+	if b.feeRecipient != nil {
+		payload.FeeRecipient = *b.feeRecipient
+	}
+	header := payloadHeader(payload, [][]byte{})
+	payload.BlockHash = header.Hash()
+	return &engine_types.GetPayloadResponse{ExecutionPayload: payload, BlockValue: (*hexutil.Big)(big.NewInt(b.value))}, nil
+}
+
+func startTestBuilder(t *testing.T, b *testBuilder) string {
+	logger := log.New()
+	srv := rpc.NewServer(50, false, false, true, logger, 0)
+	require.NoError(t, srv.RegisterName("builder", b))
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return httpSrv.URL
+}
+
+func TestExternalBuilders(t *testing.T) {
+	other := libcommon.Address{0xee}
+	urls := []string{
+		startTestBuilder(t, &testBuilder{value: 50}),
+		startTestBuilder(t, &testBuilder{value: 200}),
+		startTestBuilder(t, &testBuilder{value: 1000, feeRecipient: &other}), // pays to someone else
+	}
+	req := builderRequest{
+		parentHash: libcommon.Hash{1},
+		attributes: &engine_types.PayloadAttributes{Timestamp: 12, PrevRandao: libcommon.Hash{2}, SuggestedFeeRecipient: libcommon.Address{3}},
+		version:    clparams.BellatrixVersion,
+	}
+	local := &engine_types.GetPayloadResponse{ExecutionPayload: &engine_types.ExecutionPayload{}, BlockValue: (*hexutil.Big)(big.NewInt(100))}
+
+	builders, err := NewExternalBuilders(&params.MiningConfig{Builders: urls, BuilderTimeout: 5 * time.Second}, params.TestChainConfig, log.New())
+	require.NoError(t, err)
+	bids := builders.fetch(context.Background(), req)
+	require.Len(t, bids, 3)
+	require.NotNil(t, bids[0])
+	require.NotNil(t, bids[1])
+	require.Nil(t, bids[2])
+	require.Equal(t, bids[1], builders.choose(local, bids))
+
+	builders.minBid = big.NewInt(500)
+	require.Equal(t, local, builders.choose(local, bids))
+
+	none, err := NewExternalBuilders(&params.MiningConfig{}, params.TestChainConfig, log.New())
+	require.NoError(t, err)
+	require.Nil(t, none)
+}
+
+func TestExternalBuildersDenyList(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	builders, err := NewExternalBuilders(&params.MiningConfig{Builders: []string{"http://localhost:0"}, BuilderDenyList: []libcommon.Address{sender}}, params.TestChainConfig, log.New())
+	require.NoError(t, err)
+
+	txn, err := types.SignTx(types.NewTransaction(0, libcommon.Address{}, uint256.NewInt(0), 21_000, uint256.NewInt(1), nil), *types.LatestSigner(params.TestChainConfig), key)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, txn.MarshalBinary(&buf))
+
+	attributes := &engine_types.PayloadAttributes{Timestamp: 12}
+	payload := &engine_types.ExecutionPayload{Timestamp: 12, BaseFeePerGas: (*hexutil.Big)(big.NewInt(7)), Transactions: []hexutility.Bytes{buf.Bytes()}}
+	header := payloadHeader(payload, [][]byte{buf.Bytes()})
+	payload.BlockHash = header.Hash()
+	bid := &engine_types.GetPayloadResponse{ExecutionPayload: payload, BlockValue: (*hexutil.Big)(big.NewInt(1))}
+
+	req := builderRequest{attributes: attributes, version: clparams.BellatrixVersion}
+	require.ErrorContains(t, builders.validate(bid, req), "denied")
+	builders.denyList = nil
+	require.NoError(t, builders.validate(bid, req))
+}
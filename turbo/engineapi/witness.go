@@ -0,0 +1,98 @@
+package engineapi
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/ledgerwatch/log/v3"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/turbo/engineapi/engine_types"
+)
+
+const (
+	witnessesKept  = 64
+	witnessesQueue = 16
+)
+
+// WitnessGenerateFunc - execution witness of canonical block, nil if block isn't known or isn't canonical
+type WitnessGenerateFunc func(ctx context.Context, tx kv.Tx, hash libcommon.Hash) (*stateless.Witness, error)
+
+// WitnessAPI - execution witnesses of validated payloads (see `--engine.witness`) for stateless verifiers and
+// provers, served under `debug` namespace of engine API endpoint. Witness of each new head accepted by
+// engine_forkchoiceUpdated is generated in background and kept for last witnessesKept heads, witnesses of other
+// recent canonical blocks are generated on request. Payloads of side chains have no witnesses: state is proven
+// against canonical parent state, by commitment trie on HistoryV3 databases
+type WitnessAPI struct {
+	generate  WitnessGenerateFunc
+	db        kv.RoDB // set by EngineServer.Start
+	queue     chan libcommon.Hash
+	witnesses *lru.Cache[libcommon.Hash, *stateless.Witness]
+	logger    log.Logger
+}
+
+func newWitnessAPI(generate WitnessGenerateFunc, logger log.Logger) *WitnessAPI {
+	witnesses, _ := lru.New[libcommon.Hash, *stateless.Witness](witnessesKept)
+	return &WitnessAPI{generate: generate, queue: make(chan libcommon.Hash, witnessesQueue), witnesses: witnesses, logger: logger}
+}
+
+// ExecutionWitness - witness of canonical block `blockHash`, null if block isn't known or isn't canonical
+func (w *WitnessAPI) ExecutionWitness(ctx context.Context, blockHash libcommon.Hash) (*engine_types.ExecutionWitness, error) {
+	witness, ok := w.witnesses.Get(blockHash)
+	if !ok {
+		var err error
+		if witness, err = w.generateWitness(ctx, blockHash); err != nil {
+			return nil, err
+		}
+	}
+	if witness == nil {
+		return nil, nil
+	}
+	return engine_types.ConvertWitnessToRpc(witness), nil
+}
+
+// notify - queues generation of witness of new head, skipped if generation lags behind
+func (w *WitnessAPI) notify(hash libcommon.Hash) {
+	select {
+	case w.queue <- hash:
+	default:
+		w.logger.Debug("[Witness] generation lags behind, skipping", "hash", hash)
+	}
+}
+
+func (w *WitnessAPI) loop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case hash := <-w.queue:
+			if w.witnesses.Contains(hash) {
+				continue
+			}
+			witness, err := w.generateWitness(ctx, hash)
+			if err != nil {
+				w.logger.Warn("[Witness] generation failed", "hash", hash, "err", err)
+				continue
+			}
+			if witness != nil {
+				w.logger.Debug("[Witness] generated", "hash", hash, "nodes", len(witness.State), "codes", len(witness.Codes))
+			}
+		}
+	}
+}
+
+func (w *WitnessAPI) generateWitness(ctx context.Context, hash libcommon.Hash) (witness *stateless.Witness, err error) {
+	if err = w.db.View(ctx, func(tx kv.Tx) error {
+		witness, err = w.generate(ctx, tx, hash)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if witness != nil {
+		w.witnesses.Add(hash, witness)
+	}
+	return witness, nil
+}
@@ -0,0 +1,102 @@
+package engineapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/stateless"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/protocols/wit"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+)
+
+func TestWitnessAPI(t *testing.T) {
+	canonical := libcommon.Hash{1}
+	calls := 0
+	generate := func(_ context.Context, _ kv.Tx, hash libcommon.Hash) (*stateless.Witness, error) {
+		calls++
+		if hash != canonical {
+			return nil, nil
+		}
+		return &stateless.Witness{Headers: []*types.Header{{Number: big.NewInt(1)}}, Codes: [][]byte{{0x60}}, State: [][]byte{{0xc0}}}, nil
+	}
+	w := newWitnessAPI(generate, log.New())
+	w.db = memdb.NewTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.loop(ctx)
+
+	// head's witness is generated in background
+	w.notify(canonical)
+	require.Eventually(t, func() bool { return w.witnesses.Contains(canonical) }, 5*time.Second, 10*time.Millisecond)
+	witness, err := w.ExecutionWitness(ctx, canonical)
+	require.NoError(t, err)
+	require.Equal(t, []hexutility.Bytes{{0x60}}, witness.Codes)
+	require.Equal(t, []hexutility.Bytes{{0xc0}}, witness.State)
+	require.Equal(t, 1, calls)
+
+	// unknown blocks have no witness
+	witness, err = w.ExecutionWitness(ctx, libcommon.Hash{2})
+	require.NoError(t, err)
+	require.Nil(t, witness)
+	require.Equal(t, 2, calls)
+}
+
+func TestWitnessAPIHistoryV3(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	// stores call value at slot of block number
+	contract, code := libcommon.HexToAddress("0xc0de"), []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE), byte(vm.STOP)}
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			addr:     {Balance: big.NewInt(params.Ether)},
+			contract: {Balance: big.NewInt(1), Code: code},
+		},
+	}, key, false)
+	require.True(t, m.HistoryV3)
+
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), contract, uint256.NewInt(uint64(i+1)), 50000, nil, nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	require.NoError(t, err)
+	// commitment is computed for every block
+	for i := 0; i < chain.Length(); i++ {
+		require.NoError(t, m.InsertChain(chain.Slice(i, i+1)))
+	}
+
+	w := newWitnessAPI(wit.NewGenerator(m.ChainConfig, m.Engine, m.BlockReader, m.Dirs, m.HistoryV3, m.Log).Generate, m.Log)
+	w.db = m.DB
+	ctx := context.Background()
+	for _, block := range chain.Blocks {
+		witness, err := w.ExecutionWitness(ctx, block.Hash())
+		require.NoError(t, err)
+		require.NotNil(t, witness)
+		require.Equal(t, block.ParentHash(), witness.Headers[0].Hash())
+		require.Contains(t, witness.Codes, hexutility.Bytes(code))
+		// state is proven from parent state root
+		var hasRoot bool
+		for _, node := range witness.State {
+			hasRoot = hasRoot || crypto.Keccak256Hash(node) == witness.Headers[0].Root
+		}
+		require.True(t, hasRoot)
+	}
+}
@@ -20,6 +20,15 @@ import (
 )
 
 var errNotFound = errors.New("notfound")
+var errBodiesLimit = errors.New("bodies response limit reached")
+
+// minBodiesByRange - engine_getPayloadBodiesByRange response isn't cut by size limit before it has this amount of
+// bodies: execution-apis requires support of requests for at least 32 blocks, such requests are always served whole.
+// Cut response looks like the end of the chain for CL, so only longer requests may get less than they asked for
+const minBodiesByRange = 32
+
+// withdrawalSize - index, validator index, address and amount
+const withdrawalSize = 8 + 8 + 20 + 8
 
 func (e *EthereumExecutionModule) parseSegmentRequest(ctx context.Context, tx kv.Tx, req *execution.GetSegmentRequest) (blockHash libcommon.Hash, blockNumber uint64, err error) {
 	switch {
@@ -161,9 +170,38 @@ func (e *EthereumExecutionModule) GetBodiesByRange(ctx context.Context, req *exe
 	defer tx.Rollback()
 
 	bodies := make([]*execution.BlockBody, 0, req.Count)
+	// response is cut when it reaches the limit, but not before minBodiesByRange bodies
+	var size uint64
+	limit := uint64(e.syncCfg.BodiesResponseLimit)
+	add := func(body *execution.BlockBody) (full bool) {
+		bodies = append(bodies, body)
+		for _, txn := range body.Transactions {
+			size += uint64(len(txn))
+		}
+		size += uint64(len(body.Withdrawals)) * withdrawalSize
+		return limit > 0 && size >= limit && len(bodies) >= minBodiesByRange
+	}
+
+	from, to := req.Start, req.Start+req.Count
+	if e.blockReader != nil {
+		// frozen blocks are canonical, their transactions are taken from files as is
+		err = e.blockReader.IterateFrozenRawBodies(from, to, func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error {
+			from = blockNum + 1
+			if add(&execution.BlockBody{Transactions: txs, Withdrawals: eth1_utils.ConvertWithdrawalsToRpc(withdrawals)}) {
+				return errBodiesLimit
+			}
+			return nil
+		})
+		if errors.Is(err, errBodiesLimit) {
+			return &execution.GetBodiesBatchResponse{Bodies: bodies}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ethereumExecutionModule.GetBodiesByRange: IterateFrozenRawBodies error %w", err)
+		}
+	}
 
-	for i := uint64(0); i < req.Count; i++ {
-		hash, err := rawdb.ReadCanonicalHash(tx, req.Start+i)
+	for blockNum := from; blockNum < to; blockNum++ {
+		hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
 		if err != nil {
 			return nil, fmt.Errorf("ethereumExecutionModule.GetBodiesByRange: ReadCanonicalHash error %w", err)
 		}
@@ -172,7 +210,7 @@ func (e *EthereumExecutionModule) GetBodiesByRange(ctx context.Context, req *exe
 			break
 		}
 
-		body, err := e.getBody(ctx, tx, hash, req.Start+i)
+		body, err := e.getBody(ctx, tx, hash, blockNum)
 		if err != nil {
 			return nil, fmt.Errorf("ethereumExecutionModule.GetBodiesByRange: getBody error %w", err)
 		}
@@ -186,10 +224,12 @@ func (e *EthereumExecutionModule) GetBodiesByRange(ctx context.Context, req *exe
 		if err != nil {
 			return nil, fmt.Errorf("ethereumExecutionModule.GetBodiesByRange: MarshalTransactionsBinary error %w", err)
 		}
-		bodies = append(bodies, &execution.BlockBody{
+		if add(&execution.BlockBody{
 			Transactions: txs,
 			Withdrawals:  eth1_utils.ConvertWithdrawalsToRpc(body.Withdrawals),
-		})
+		}) {
+			break
+		}
 	}
 	// Remove trailing nil values as per spec
 	// See point 4 in https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#specification-4
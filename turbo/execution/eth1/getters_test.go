@@ -0,0 +1,59 @@
+package eth1_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	execution "github.com/ledgerwatch/erigon-lib/gointerfaces/executionproto"
+
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/execution/eth1"
+	"github.com/ledgerwatch/erigon/turbo/stages/mock"
+)
+
+func TestGetBodiesByRangeLimit(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	gspec := &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	m := mock.MockWithGenesis(t, gspec, key, false)
+	signer := types.LatestSigner(gspec.Config)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 40, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), libcommon.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	bodiesByRange := func(limit datasize.ByteSize, start, count uint64) []*execution.BlockBody {
+		syncCfg := ethconfig.Defaults.Sync
+		syncCfg.BodiesResponseLimit = limit
+		module := eth1.NewEthereumExecutionModule(m.BlockReader, m.DB, nil, nil, m.ChainConfig, nil, nil, nil, nil, m.Log, m.Engine, m.HistoryV3, syncCfg, m.Ctx)
+		resp, err := module.GetBodiesByRange(m.Ctx, &execution.GetBodiesByRangeRequest{Start: start, Count: count})
+		require.NoError(t, err)
+		return resp.Bodies
+	}
+
+	require.Len(t, bodiesByRange(0, 1, 40), 40)
+	require.Len(t, bodiesByRange(0, 1, 100), 40) // end of chain
+
+	// every body exceeds the limit, but requests of minimal size are served whole
+	require.Len(t, bodiesByRange(1, 1, 40), 32)
+	require.Len(t, bodiesByRange(1, 5, 32), 32)
+	require.Len(t, bodiesByRange(1, 30, 20), 11) // end of chain
+	bodies := bodiesByRange(1, 9, 32)
+	require.Equal(t, chain.Blocks[8].Transactions()[0].Hash(), crypto.Keccak256Hash(bodies[0].Transactions[0]))
+	require.Equal(t, chain.Blocks[39].Transactions()[0].Hash(), crypto.Keccak256Hash(bodies[31].Transactions[0]))
+}
@@ -5,9 +5,18 @@ import (
 	"errors"
 	"fmt"
 
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
+	proto_txpool "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon-lib/txpool"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
 	"github.com/ledgerwatch/erigon/p2p"
 
+	"github.com/ledgerwatch/erigon/turbo/reload"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 )
 
@@ -22,17 +31,61 @@ type AdminAPI interface {
 
 	// AddPeer requests connecting to a remote node.
 	AddPeer(ctx context.Context, url string) (bool, error)
+
+	// RemovePeer disconnects from a remote node, and stops reconnecting to it if it was added as static.
+	RemovePeer(ctx context.Context, url string) (bool, error)
+
+	// AddTrustedPeer allows a remote node to always connect, even above the peer limit.
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+
+	// RemoveTrustedPeer removes a remote node from the trusted peers, without disconnecting it.
+	RemoveTrustedPeer(ctx context.Context, url string) (bool, error)
+
+	// ReloadPeerFiles makes all sentries reload their static and trusted peers files (see --staticpeers.file).
+	ReloadPeerFiles(ctx context.Context) (bool, error)
+
+	// LongReadTransactions returns db read transactions living longer than watchdog threshold (see --db.read.tx.watchdog).
+	LongReadTransactions(ctx context.Context) ([]kv.LongReadTx, error)
+
+	// DbStats returns per-table stats of chaindata: entries, sizes, pages and estimated fill factor. Sorted by size desc.
+	DbStats(ctx context.Context) ([]kv.TableStat, error)
+
+	// TxPoolPolicy returns current replacement and pricing policy of txpool.
+	TxPoolPolicy(ctx context.Context) (*txpoolcfg.Policy, error)
+
+	// SetTxPoolPolicy changes policy of txpool without restart. Omitted fields keep current values.
+	SetTxPoolPolicy(ctx context.Context, policy TxPoolPolicyUpdate) (*txpoolcfg.Policy, error)
+
+	// StateCachePin keeps state (account and storage) of given addresses in StateCache, same as --state.cache.pin.
+	// Returns all pinned addresses.
+	StateCachePin(ctx context.Context, addrs []libcommon.Address) ([]libcommon.Address, error)
+
+	// StateCacheUnpin returns state of given addresses under usual eviction of StateCache. Returns all pinned addresses.
+	StateCacheUnpin(ctx context.Context, addrs []libcommon.Address) ([]libcommon.Address, error)
+
+	// StateCachePinned returns addresses which state is pinned in StateCache.
+	StateCachePinned(ctx context.Context) ([]libcommon.Address, error)
+
+	// ReloadConfig re-reads config file of the node (see --config) and applies changed settings which don't require
+	// restart, same as SIGHUP. Available only in rpcdaemon embedded into erigon.
+	ReloadConfig(ctx context.Context) (*reload.Result, error)
 }
 
 // AdminAPIImpl data structure to store things needed for admin_* commands.
 type AdminAPIImpl struct {
 	ethBackend rpchelper.ApiBackend
+	db         kv.RoDB
+	txPool     proto_txpool.TxpoolClient
+	stateCache kvcache.Cache
 }
 
 // NewAdminAPI returns AdminAPIImpl instance.
-func NewAdminAPI(eth rpchelper.ApiBackend) *AdminAPIImpl {
+func NewAdminAPI(db kv.RoDB, eth rpchelper.ApiBackend, txPool proto_txpool.TxpoolClient, stateCache kvcache.Cache) *AdminAPIImpl {
 	return &AdminAPIImpl{
 		ethBackend: eth,
+		db:         db,
+		txPool:     txPool,
+		stateCache: stateCache,
 	}
 }
 
@@ -63,3 +116,108 @@ func (api *AdminAPIImpl) AddPeer(ctx context.Context, url string) (bool, error)
 	}
 	return result.Success, nil
 }
+
+func (api *AdminAPIImpl) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.RemovePeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.AddTrustedPeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) RemoveTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.RemoveTrustedPeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) ReloadPeerFiles(ctx context.Context) (bool, error) {
+	return api.ethBackend.ReloadPeerFiles(ctx)
+}
+
+func (api *AdminAPIImpl) LongReadTransactions(ctx context.Context) ([]kv.LongReadTx, error) {
+	w, ok := api.db.(kv.HasLongReadTxs)
+	if !ok {
+		return nil, errors.New("long read transactions are tracked only by local db")
+	}
+	return w.LongReadTxs(), nil
+}
+
+func (api *AdminAPIImpl) DbStats(ctx context.Context) ([]kv.TableStat, error) {
+	s, ok := api.db.(kv.HasTableStats)
+	if !ok {
+		return nil, errors.New("table stats are available only for local db")
+	}
+	return s.TableStats(ctx)
+}
+
+func (api *AdminAPIImpl) TxPoolPolicy(ctx context.Context) (*txpoolcfg.Policy, error) {
+	reply, err := api.txPool.GetPolicy(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return txpool.PolicyFromReply(reply), nil
+}
+
+// TxPoolPolicyUpdate - fields of txpoolcfg.Policy to change by admin_setTxPoolPolicy
+type TxPoolPolicyUpdate struct {
+	PriceBump     *uint64 `json:"priceBump"`
+	BlobPriceBump *uint64 `json:"blobPriceBump"`
+	MinFeeCap     *uint64 `json:"minFeeCap"`
+	MinTipCap     *uint64 `json:"minTipCap"`
+	AccountSlots  *uint64 `json:"accountSlots"`
+	BlobSlots     *uint64 `json:"blobSlots"`
+	MaxNonceGap   *uint64 `json:"maxNonceGap"`
+}
+
+func (api *AdminAPIImpl) SetTxPoolPolicy(ctx context.Context, policy TxPoolPolicyUpdate) (*txpoolcfg.Policy, error) {
+	reply, err := api.txPool.SetPolicy(ctx, &proto_txpool.SetPolicyRequest{
+		PriceBump:     policy.PriceBump,
+		BlobPriceBump: policy.BlobPriceBump,
+		MinFeeCap:     policy.MinFeeCap,
+		MinTipCap:     policy.MinTipCap,
+		AccountSlots:  policy.AccountSlots,
+		BlobSlots:     policy.BlobSlots,
+		MaxNonceGap:   policy.MaxNonceGap,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return txpool.PolicyFromReply(reply), nil
+}
+
+func (api *AdminAPIImpl) StateCachePin(ctx context.Context, addrs []libcommon.Address) ([]libcommon.Address, error) {
+	c, err := api.stateCachePins()
+	if err != nil {
+		return nil, err
+	}
+	c.Pin(addrs...)
+	return c.Pinned(), nil
+}
+
+func (api *AdminAPIImpl) StateCacheUnpin(ctx context.Context, addrs []libcommon.Address) ([]libcommon.Address, error) {
+	c, err := api.stateCachePins()
+	if err != nil {
+		return nil, err
+	}
+	c.Unpin(addrs...)
+	return c.Pinned(), nil
+}
+
+func (api *AdminAPIImpl) StateCachePinned(ctx context.Context) ([]libcommon.Address, error) {
+	c, err := api.stateCachePins()
+	if err != nil {
+		return nil, err
+	}
+	return c.Pinned(), nil
+}
+
+func (api *AdminAPIImpl) stateCachePins() (kvcache.HasPins, error) {
+	c, ok := api.stateCache.(kvcache.HasPins)
+	if !ok {
+		return nil, errors.New("state cache is disabled (see --state.cache)")
+	}
+	return c, nil
+}
+
+func (api *AdminAPIImpl) ReloadConfig(ctx context.Context) (*reload.Result, error) {
+	return reload.ReloadConfig()
+}
@@ -1,14 +1,17 @@
 package jsonrpc
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
 	"github.com/ledgerwatch/erigon-lib/kv"
 
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/polygon/bor"
 	"github.com/ledgerwatch/erigon/polygon/bor/valset"
+	"github.com/ledgerwatch/erigon/polygon/heimdall"
 	"github.com/ledgerwatch/erigon/rpc"
 )
 
@@ -25,6 +28,13 @@ type BorAPI interface {
 	GetSnapshotProposer(blockNrOrHash *rpc.BlockNumberOrHash) (common.Address, error)
 	GetSnapshotProposerSequence(blockNrOrHash *rpc.BlockNumberOrHash) (BlockSigners, error)
 	GetRootHash(start uint64, end uint64) (string, error)
+
+	// Bor events and spans related (see ./bor_events.go)
+	GetEventsByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*heimdall.EventRecordWithTime, error)
+	GetSpan(ctx context.Context, spanId hexutil.Uint64) (*heimdall.Span, error)
+	GetSpanByBlock(ctx context.Context, number rpc.BlockNumber) (*heimdall.Span, error)
+	GetLatestSpan(ctx context.Context) (*heimdall.Span, error)
+	GetProducersByBlock(ctx context.Context, number rpc.BlockNumber) ([]valset.Validator, error)
 }
 
 // BorImpl is implementation of the BorAPI interface
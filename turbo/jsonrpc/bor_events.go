@@ -0,0 +1,113 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/polygon/bor"
+	"github.com/ledgerwatch/erigon/polygon/bor/valset"
+	"github.com/ledgerwatch/erigon/polygon/heimdall"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// GetEventsByBlock returns state sync events committed in the block, empty list for blocks without events.
+// Events of frozen blocks are served from bor snapshot files
+func (api *BorImpl) GetEventsByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*heimdall.EventRecordWithTime, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, hash, _, err := rpchelper.GetCanonicalBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	rawEvents, err := api._blockReader.EventsByBlock(ctx, tx, hash, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	stateReceiverABI := bor.GenesisContractStateReceiverABI()
+	events := make([]*heimdall.EventRecordWithTime, 0, len(rawEvents))
+	for _, rawEvent := range rawEvents {
+		event, err := heimdall.UnpackEventRecordWithTime(stateReceiverABI, rawEvent)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// GetSpan returns span by its id, null if the span isn't known yet
+func (api *BorImpl) GetSpan(ctx context.Context, spanId hexutil.Uint64) (*heimdall.Span, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return api.span(ctx, tx, uint64(spanId))
+}
+
+// GetSpanByBlock returns span which the block belongs to
+func (api *BorImpl) GetSpanByBlock(ctx context.Context, number rpc.BlockNumber) (*heimdall.Span, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(number), tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	return api.span(ctx, tx, uint64(heimdall.SpanIdAt(blockNum)))
+}
+
+// GetLatestSpan returns the last span known to the node, it may start in future
+func (api *BorImpl) GetLatestSpan(ctx context.Context) (*heimdall.Span, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	spanId, ok, err := api._blockReader.LastSpanId(ctx, tx)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return api.span(ctx, tx, spanId)
+}
+
+// GetProducersByBlock returns producers selected for the span which the block belongs to, proposer of the block
+// is one of them (see GetSnapshotProposer)
+func (api *BorImpl) GetProducersByBlock(ctx context.Context, number rpc.BlockNumber) ([]valset.Validator, error) {
+	span, err := api.GetSpanByBlock(ctx, number)
+	if err != nil || span == nil {
+		return nil, err
+	}
+	return span.SelectedProducers, nil
+}
+
+func (api *BorImpl) span(ctx context.Context, tx kv.Tx, spanId uint64) (*heimdall.Span, error) {
+	spanBytes, err := api._blockReader.Span(ctx, tx, spanId)
+	if err != nil {
+		if errors.Is(err, freezeblocks.ErrSpanNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var span heimdall.Span
+	if err = json.Unmarshal(spanBytes, &span); err != nil {
+		return nil, err
+	}
+	return &span, nil
+}
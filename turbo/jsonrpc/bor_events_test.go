@@ -0,0 +1,89 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/polygon/bor"
+	"github.com/ledgerwatch/erigon/polygon/bor/valset"
+	"github.com/ledgerwatch/erigon/polygon/heimdall"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestBorEventsAndSpans(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	ctx := context.Background()
+	producer := valset.Validator{ID: 1, Address: libcommon.Address{1}, VotingPower: 10}
+	eventTime := time.Unix(1700000000, 0).UTC()
+	err := m.DB.Update(ctx, func(tx kv.RwTx) error {
+		for _, span := range []heimdall.Span{
+			{Id: 0, StartBlock: 0, EndBlock: 255, SelectedProducers: []valset.Validator{producer}},
+			{Id: 1, StartBlock: 256, EndBlock: 6655},
+		} {
+			spanBytes, err := json.Marshal(span)
+			if err != nil {
+				return err
+			}
+			if err = tx.Put(kv.BorSpans, hexutility.EncodeTs(uint64(span.Id)), spanBytes); err != nil {
+				return err
+			}
+		}
+
+		record, err := rlp.EncodeToBytes(&heimdall.EventRecord{ID: 7, Contract: libcommon.Address{2}, Data: []byte{3}, ChainID: "1337"})
+		if err != nil {
+			return err
+		}
+		event, err := bor.GenesisContractStateReceiverABI().Pack("commitState", big.NewInt(eventTime.Unix()), record)
+		if err != nil {
+			return err
+		}
+		var eventId [8]byte
+		binary.BigEndian.PutUint64(eventId[:], 7)
+		if err = tx.Put(kv.BorEventNums, hexutility.EncodeTs(1), eventId[:]); err != nil {
+			return err
+		}
+		return tx.Put(kv.BorEvents, eventId[:], event)
+	})
+	require.NoError(t, err)
+
+	api := NewBorAPI(newBaseApiForTest(m), m.DB)
+
+	events, err := api.GetEventsByBlock(ctx, rpc.BlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(7), events[0].ID)
+	require.Equal(t, libcommon.Address{2}, events[0].Contract)
+	require.Equal(t, eventTime, events[0].Time.UTC())
+
+	events, err = api.GetEventsByBlock(ctx, rpc.BlockNumberOrHashWithNumber(2))
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	span, err := api.GetSpanByBlock(ctx, rpc.BlockNumber(1))
+	require.NoError(t, err)
+	require.Equal(t, heimdall.SpanId(0), span.Id)
+
+	producers, err := api.GetProducersByBlock(ctx, rpc.BlockNumber(1))
+	require.NoError(t, err)
+	require.Equal(t, []valset.Validator{producer}, producers)
+
+	span, err = api.GetLatestSpan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, heimdall.SpanId(1), span.Id)
+
+	span, err = api.GetSpan(ctx, 2)
+	require.NoError(t, err)
+	require.Nil(t, span)
+}
@@ -8,29 +8,40 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/polygon/bor"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/semaphore"
 )
 
 // APIList describes the list of available RPC apis
 func APIList(db kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient,
 	filters *rpchelper.Filters, stateCache kvcache.Cache,
 	blockReader services.FullBlockReader, agg *libstate.Aggregator, cfg *httpcfg.HttpCfg, engine consensus.EngineReader,
-	logger log.Logger,
+	blobsReader BlobsReader, logger log.Logger,
 ) (list []rpc.API) {
 	base := NewBaseApi(filters, stateCache, blockReader, agg, cfg.WithDatadir, cfg.EvmCallTimeout, engine, cfg.Dirs)
+	if cfg.ReexecWorkers > 0 {
+		base.reexec = newReexecPool(cfg.ReexecWorkers, cfg.ReexecQueueSize)
+	}
+	base.receiptsRegen = ReceiptsRegenConfig{Enabled: cfg.ReceiptsRegen, MaxTxs: cfg.ReceiptsRegenMaxTxs, Timeout: cfg.ReceiptsRegenTimeout}
 	ethImpl := NewEthAPI(base, db, eth, txPool, mining, cfg.Gascap, cfg.ReturnDataLimit, cfg.AllowUnprotectedTxs, cfg.MaxGetProofRewindBlockCount, cfg.WebsocketSubscribeLogsChannelSize, logger)
+	ethImpl.blobsReader = blobsReader
 	erigonImpl := NewErigonAPI(base, db, eth)
 	txpoolImpl := NewTxPoolAPI(base, db, txPool)
 	netImpl := NewNetAPIImpl(eth)
 	debugImpl := NewPrivateDebugAPI(base, db, cfg.Gascap)
+	if cfg.TraceChainConcurrency > 0 {
+		debugImpl.traceChainSem = semaphore.NewWeighted(int64(cfg.TraceChainConcurrency))
+	}
+	debugImpl.tracerBudget = &tracers.Budget{CPUTime: cfg.TracerCPUTime, Memory: cfg.TracerMemory, OutputSize: cfg.TracerOutputSize}
 	traceImpl := NewTraceAPI(base, db, cfg)
 	web3Impl := NewWeb3APIImpl(eth)
 	dbImpl := NewDBAPIImpl() /* deprecated */
-	adminImpl := NewAdminAPI(eth)
+	adminImpl := NewAdminAPI(db, eth, txPool, stateCache)
 	parityImpl := NewParityAPIImpl(base, db)
 
 	var borImpl *BorImpl
@@ -0,0 +1,38 @@
+package jsonrpc
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+)
+
+// DataUnavailableError - requested block is out of window of data kept by the node: pruned (--prune, --prune.<type>)
+// or older than the first block of block snapshots (--snap.download.profile=minimal, --profile=minimal)
+type DataUnavailableError struct {
+	Data           string // history, blocks
+	BlockNum       uint64
+	FirstAvailable uint64
+}
+
+func (e *DataUnavailableError) Error() string {
+	return fmt.Sprintf("data not available: %s of block %d is not kept by the node, first available block %d", e.Data, e.BlockNum, e.FirstAvailable)
+}
+
+func (e *DataUnavailableError) ErrorCode() int { return -32001 } // resource not found
+
+func (e *DataUnavailableError) ErrorData() interface{} {
+	return map[string]interface{}{
+		"data":                e.Data,
+		"blockNumber":         hexutil.Uint64(e.BlockNum),
+		"firstAvailableBlock": hexutil.Uint64(e.FirstAvailable),
+	}
+}
+
+// checkBlockAvailable - called when block is not found: *DataUnavailableError if the block is older than the first
+// block of block snapshots. Such blocks are not downloaded, node has neither headers nor bodies of them
+func (api *BaseAPI) checkBlockAvailable(number uint64) error {
+	if firstAvailable := api._blockReader.Snapshots().SegmentsMin(); number < firstAvailable {
+		return &DataUnavailableError{Data: "blocks", BlockNum: number, FirstAvailable: firstAvailable}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+func TestDataUnavailableError(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	ctx := context.Background()
+
+	// history of blocks before 4 is pruned, block 2 is older than block snapshots
+	tx, err := m.DB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	mode := prune.DefaultMode
+	mode.History = prune.Before(5)
+	require.NoError(t, prune.Override(tx, mode))
+	hash, err := rawdb.ReadCanonicalHash(tx, 2)
+	require.NoError(t, err)
+	rawdb.DeleteBody(tx, hash, 2)
+	require.NoError(t, tx.Commit())
+	m.BlockReader.Snapshots().(*freezeblocks.RoSnapshots).SetSegmentsMin(3)
+	defer m.BlockReader.Snapshots().(*freezeblocks.RoSnapshots).SetSegmentsMin(0)
+
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 100_000, false, 100_000, 128, log.New())
+	_, err = api.GetBlockByNumber(ctx, 2, false)
+	var unavailable *DataUnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	require.Equal(t, DataUnavailableError{Data: "blocks", BlockNum: 2, FirstAvailable: 3}, *unavailable)
+	require.Equal(t, -32001, unavailable.ErrorCode())
+
+	roTx, err := m.DB.BeginRo(ctx)
+	require.NoError(t, err)
+	defer roTx.Rollback()
+	err = api.checkPruneHistory(roTx, 3)
+	require.ErrorAs(t, err, &unavailable)
+	require.Equal(t, DataUnavailableError{Data: "history", BlockNum: 3, FirstAvailable: 4}, *unavailable)
+	require.NoError(t, api.checkPruneHistory(roTx, 4))
+}
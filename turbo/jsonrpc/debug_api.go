@@ -3,6 +3,10 @@ package jsonrpc
 import (
 	"context"
 	"fmt"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/ledgerwatch/erigon-lib/common/hexutil"
 
@@ -42,6 +46,7 @@ type PrivateDebugAPI interface {
 	AccountAt(ctx context.Context, blockHash common.Hash, txIndex uint64, account common.Address) (*AccountResult, error)
 	GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutility.Bytes, error)
 	GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutility.Bytes, error)
+	TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceChainConfig) (*rpc.Subscription, error)
 }
 
 // PrivateDebugAPIImpl is implementation of the PrivateDebugAPI interface based on remote Db access
@@ -49,14 +54,22 @@ type PrivateDebugAPIImpl struct {
 	*BaseAPI
 	db     kv.RoDB
 	GasCap uint64
+
+	traceChainSem *semaphore.Weighted // bounds blocks re-executed at the same time by debug_traceChain
+	traceChains   *expirable.LRU[string, *traceChainProgress]
+	traceChainsMu sync.Mutex
+
+	tracerBudget *tracers.Budget // resources javascript tracers may use per transaction, nil if unlimited
 }
 
 // NewPrivateDebugAPI returns PrivateDebugAPIImpl instance
 func NewPrivateDebugAPI(base *BaseAPI, db kv.RoDB, gascap uint64) *PrivateDebugAPIImpl {
 	return &PrivateDebugAPIImpl{
-		BaseAPI: base,
-		db:      db,
-		GasCap:  gascap,
+		BaseAPI:       base,
+		db:            db,
+		GasCap:        gascap,
+		traceChainSem: semaphore.NewWeighted(defaultTraceChainConcurrency),
+		traceChains:   expirable.NewLRU[string, *traceChainProgress](traceChainResumeLimit, nil, traceChainResumeTTL),
 	}
 }
 
@@ -94,7 +107,7 @@ func (api *PrivateDebugAPIImpl) StorageRangeAt(ctx context.Context, blockHash co
 		return StorageRangeResult{}, nil
 	}
 
-	_, _, _, _, stateReader, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, int(txIndex), api.historyV3(tx))
+	_, _, _, _, stateReader, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, int(txIndex), api.historyV3(tx), api.executionCache)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
@@ -349,7 +362,7 @@ func (api *PrivateDebugAPIImpl) AccountAt(ctx context.Context, blockHash common.
 	if block == nil {
 		return nil, nil
 	}
-	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, int(txIndex), api.historyV3(tx))
+	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, int(txIndex), api.historyV3(tx), api.executionCache)
 	if err != nil {
 		return nil, err
 	}
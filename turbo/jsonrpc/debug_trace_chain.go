@@ -0,0 +1,214 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+const (
+	defaultTraceChainConcurrency = 2
+	// progress of interrupted debug_traceChain subscriptions is kept for resumption
+	traceChainResumeLimit = 1024
+	traceChainResumeTTL   = 10 * time.Minute
+)
+
+// TraceChainConfig - config of debug_traceChain: tracer config applied to every block, plus the token of an interrupted
+// subscription to resume
+type TraceChainConfig struct {
+	tracers.TraceConfig
+	ResumeToken *string `json:"resumeToken"`
+}
+
+// TraceChainResult - notification of debug_traceChain, traces of a block in the format of debug_traceBlockByNumber
+type TraceChainResult struct {
+	Block       hexutil.Uint64  `json:"block"`
+	Hash        common.Hash     `json:"hash"`
+	Traces      json.RawMessage `json:"traces"`
+	ResumeToken string          `json:"resumeToken"`
+}
+
+// traceChainProgress - blocks left to stream by a debug_traceChain subscription
+type traceChainProgress struct {
+	next, end uint64
+	config    *tracers.TraceConfig
+	active    bool // a subscription is streaming the blocks
+}
+
+// TraceChain implements debug_traceChain. Sends a notification with the traces of each block in [start, end], in block
+// order: `debug_subscribe("traceChain", start, end, config)`. Every notification carries a resume token, if the
+// subscription is interrupted (disconnect, unsubscribe, tracing error) a new subscription with the token in config
+// resumes after the last block that was sent, with the range and tracer config of the interrupted one. Tokens expire
+// after 10 minutes. Re-execution of blocks is bounded by --rpc.tracechain.concurrency across all subscriptions.
+func (api *PrivateDebugAPIImpl) TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceChainConfig) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if config == nil {
+		config = &TraceChainConfig{}
+	}
+
+	var (
+		token    string
+		progress *traceChainProgress
+		err      error
+	)
+	if config.ResumeToken != nil {
+		token = *config.ResumeToken
+		progress, err = api.resumeTraceChain(token)
+	} else {
+		token, progress, err = api.newTraceChain(ctx, start, end, &config.TraceConfig)
+	}
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	// tracing outlives the request, it stops on unsubscribe
+	traceCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer debug.LogPanic()
+		select {
+		case <-rpcSub.Err():
+		case <-traceCtx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer debug.LogPanic()
+		defer cancel()
+		api.traceChain(traceCtx, notifier, rpcSub.ID, token, progress)
+	}()
+
+	return rpcSub, nil
+}
+
+func (api *PrivateDebugAPIImpl) newTraceChain(ctx context.Context, start, end rpc.BlockNumber, config *tracers.TraceConfig) (string, *traceChainProgress, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	startNum, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(start), tx, api.filters)
+	if err != nil {
+		return "", nil, err
+	}
+	endNum, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(end), tx, api.filters)
+	if err != nil {
+		return "", nil, err
+	}
+	latest, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return "", nil, err
+	}
+	if endNum > latest {
+		return "", nil, fmt.Errorf("end block (%d) is later than the latest block (%d)", endNum, latest)
+	}
+	if startNum > endNum {
+		return "", nil, fmt.Errorf("start block (%d) must be less than or equal to end block (%d)", startNum, endNum)
+	}
+	if err := api.BaseAPI.checkPruneHistory(tx, startNum); err != nil {
+		return "", nil, err
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", nil, err
+	}
+	token := hex.EncodeToString(b[:])
+	progress := &traceChainProgress{next: startNum, end: endNum, config: config, active: true}
+	api.traceChainsMu.Lock()
+	defer api.traceChainsMu.Unlock()
+	api.traceChains.Add(token, progress)
+	return token, progress, nil
+}
+
+func (api *PrivateDebugAPIImpl) resumeTraceChain(token string) (*traceChainProgress, error) {
+	api.traceChainsMu.Lock()
+	defer api.traceChainsMu.Unlock()
+	progress, ok := api.traceChains.Get(token)
+	if !ok {
+		return nil, errors.New("unknown or expired resume token")
+	}
+	if progress.active {
+		return nil, errors.New("subscription of the resume token is still active")
+	}
+	progress.active = true
+	return progress, nil
+}
+
+// traceChain - streams traces of the remaining blocks of the subscription until they are all sent or the subscription
+// is interrupted
+func (api *PrivateDebugAPIImpl) traceChain(ctx context.Context, notifier *rpc.Notifier, id rpc.ID, token string, progress *traceChainProgress) {
+	defer func() {
+		api.traceChainsMu.Lock()
+		defer api.traceChainsMu.Unlock()
+		progress.active = false
+		if progress.next > progress.end {
+			api.traceChains.Remove(token)
+		} else {
+			// re-added to be kept for resumption even if tracing took longer than the ttl
+			api.traceChains.Add(token, progress)
+		}
+	}()
+
+	for progress.next <= progress.end {
+		if err := api.traceChainSem.Acquire(ctx, 1); err != nil {
+			return
+		}
+		result, err := api.traceChainBlock(ctx, progress.next, progress.config)
+		api.traceChainSem.Release(1)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Warn("[rpc] debug_traceChain failed to trace block", "block", progress.next, "err", err)
+			}
+			return
+		}
+		result.ResumeToken = token
+		if err := notifier.Notify(id, result); err != nil {
+			log.Warn("[rpc] error while notifying subscription", "err", err)
+			return
+		}
+		progress.next++
+	}
+}
+
+func (api *PrivateDebugAPIImpl) traceChainBlock(ctx context.Context, blockNum uint64, config *tracers.TraceConfig) (*TraceChainResult, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := api._blockReader.CanonicalHash(ctx, tx, blockNum)
+	tx.Rollback()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+	if err := api.traceBlock(ctx, rpc.BlockNumberOrHashWithHash(hash, true), config, reexecPriorityLow, stream); err != nil {
+		return nil, err
+	}
+	if err := stream.Flush(); err != nil {
+		return nil, err
+	}
+	return &TraceChainResult{Block: hexutil.Uint64(blockNum), Hash: hash, Traces: buf.Bytes()}, nil
+}
@@ -0,0 +1,76 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestTraceChain(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+	srv := rpc.NewServer(50, false, false, true, log.New(), 0)
+	defer srv.Stop()
+	require.NoError(t, srv.RegisterName("debug", api))
+	client := rpc.DialInProc(srv, log.New())
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// the subscription is interrupted while it waits for its turn to re-execute blocks
+	api.traceChainSem = semaphore.NewWeighted(1)
+	require.NoError(t, api.traceChainSem.Acquire(ctx, 1))
+	tracer := "callTracer"
+	config := &TraceChainConfig{TraceConfig: tracers.TraceConfig{Tracer: &tracer}}
+	results := make(chan *TraceChainResult)
+	sub, err := client.Subscribe(ctx, "debug", results, "traceChain", rpc.BlockNumber(2), rpc.BlockNumber(8), config)
+	require.NoError(t, err)
+	sub.Unsubscribe()
+	var token string
+	require.Eventually(t, func() bool {
+		api.traceChainsMu.Lock()
+		defer api.traceChainsMu.Unlock()
+		for _, k := range api.traceChains.Keys() {
+			if p, _ := api.traceChains.Peek(k); !p.active {
+				token = k
+			}
+		}
+		return token != ""
+	}, 10*time.Second, 10*time.Millisecond)
+	api.traceChainSem.Release(1)
+
+	// the resumed subscription streams the whole range, ignoring its own range and config
+	config = &TraceChainConfig{ResumeToken: &token}
+	sub, err = client.Subscribe(ctx, "debug", results, "traceChain", rpc.BlockNumber(0), rpc.BlockNumber(0), config)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	for block := uint64(2); block <= 8; block++ {
+		select {
+		case res := <-results:
+			require.Equal(t, block, uint64(res.Block))
+			require.Equal(t, token, res.ResumeToken)
+			require.NotEqual(t, "null", string(res.Traces))
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+	}
+
+	// token is dropped once all blocks are sent
+	require.Eventually(t, func() bool {
+		_, err := api.resumeTraceChain(token)
+		return err != nil && err.Error() == "unknown or expired resume token"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	_, err = client.Subscribe(ctx, "debug", make(chan *TraceChainResult), "traceChain", rpc.BlockNumber(8), rpc.BlockNumber(2), nil)
+	require.Error(t, err)
+}
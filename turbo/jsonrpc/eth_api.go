@@ -26,6 +26,7 @@ import (
 	libstate "github.com/ledgerwatch/erigon-lib/state"
 	types2 "github.com/ledgerwatch/erigon-lib/types"
 
+	"github.com/ledgerwatch/erigon/cl/cltypes"
 	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/misc"
@@ -38,6 +39,7 @@ import (
 	ethapi2 "github.com/ledgerwatch/erigon/turbo/adapter/ethapi"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/transactions"
 )
 
 // EthAPI is a collection of functions that are exposed in the
@@ -61,6 +63,9 @@ type EthAPI interface {
 	GetLogs(ctx context.Context, crit ethFilters.FilterCriteria) (types.Logs, error)
 	GetBlockReceipts(ctx context.Context, numberOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error)
 
+	// Blobs related (see ./eth_blobs.go)
+	GetBlobSidecars(ctx context.Context, numberOrHash rpc.BlockNumberOrHash) ([]*cltypes.BlobSidecar, error)
+
 	// Uncle related (see ./eth_uncles.go)
 	GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error)
 	GetUncleByBlockHashAndIndex(ctx context.Context, hash common.Hash, index hexutil.Uint) (map[string]interface{}, error)
@@ -93,6 +98,8 @@ type EthAPI interface {
 	Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutility.Bytes, error)
 	EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutility.Bytes) (common.Hash, error)
+	SendPrivateRawTransaction(ctx context.Context, encodedTx hexutility.Bytes, maxBlockNumber *hexutil.Uint64) (common.Hash, error)
+	SendRawTransactionConditional(ctx context.Context, encodedTx hexutility.Bytes, options TransactionConditions) (common.Hash, error)
 	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
 	Sign(ctx context.Context, _ common.Address, _ hexutility.Bytes) (hexutility.Bytes, error)
 	SignTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
@@ -113,6 +120,9 @@ type BaseAPI struct {
 	stateCache    kvcache.Cache
 	blocksLRU     *lru.Cache[common.Hash, *types.Block]
 	receiptsCache *lru.Cache[common.Hash, []*types.Receipt]
+	tracesCache   *lru.Cache[blockTracesKey, *blockTraces]
+	// state writes of re-executed blocks
+	executionCache *transactions.ExecutionCache
 
 	filters      *rpchelper.Filters
 	_chainConfig atomic.Pointer[chain.Config]
@@ -127,17 +137,24 @@ type BaseAPI struct {
 
 	evmCallTimeout time.Duration
 	dirs           datadir.Dirs
+
+	reexec        *reexecPool         // workers of the handlers re-executing transactions of historical blocks
+	receiptsRegen ReceiptsRegenConfig // re-generation of pruned receipts
 }
 
 func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader services.FullBlockReader, agg *libstate.Aggregator, singleNodeMode bool, evmCallTimeout time.Duration, engine consensus.EngineReader, dirs datadir.Dirs) *BaseAPI {
 	var (
 		blocksLRUSize      = 128 // ~32Mb
 		receiptsCacheLimit = 32
+		tracesCacheLimit   = 16 // blocks with tens of thousands of internal calls take tens of Mb
+		executionCacheSize = 32
 	)
 	// if RPCDaemon deployed as independent process: increase cache sizes
 	if !singleNodeMode {
 		blocksLRUSize *= 5
 		receiptsCacheLimit *= 5
+		tracesCacheLimit *= 5
+		executionCacheSize *= 5
 	}
 	blocksLRU, err := lru.New[common.Hash, *types.Block](blocksLRUSize)
 	if err != nil {
@@ -147,18 +164,25 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 	if err != nil {
 		panic(err)
 	}
+	tracesCache, err := lru.New[blockTracesKey, *blockTraces](tracesCacheLimit)
+	if err != nil {
+		panic(err)
+	}
 
 	return &BaseAPI{
 		filters:        f,
 		stateCache:     stateCache,
 		blocksLRU:      blocksLRU,
 		receiptsCache:  receiptsCache,
+		tracesCache:    tracesCache,
+		executionCache: transactions.NewExecutionCache(executionCacheSize),
 		_blockReader:   blockReader,
 		_txnReader:     blockReader,
 		_agg:           agg,
 		evmCallTimeout: evmCallTimeout,
 		_engine:        engine,
 		dirs:           dirs,
+		reexec:         newReexecPool(defaultReexecWorkers, defaultReexecQueueSize),
 	}
 }
 
@@ -214,7 +238,10 @@ func (api *BaseAPI) blockWithSenders(ctx context.Context, tx kv.Tx, hash common.
 		return nil, err
 	}
 	if block == nil { // don't save nil's to cache
-		return nil, nil
+		if err := api.checkBlockAvailable(number); err != nil {
+			return nil, err
+		}
+		return nil, api.checkHistoryExpiry(ctx, tx, hash, number)
 	}
 	// don't save empty blocks to cache, because in Erigon
 	// if block become non-canonical - we remove it's transactions, but block can become canonical in future
@@ -316,7 +343,7 @@ func (api *BaseAPI) checkPruneHistory(tx kv.Tx, block uint64) error {
 		}
 		prunedTo := p.History.PruneTo(latest)
 		if block < prunedTo {
-			return fmt.Errorf("history has been pruned for this block")
+			return &DataUnavailableError{Data: "history", BlockNum: block, FirstAvailable: prunedTo}
 		}
 	}
 
@@ -336,7 +363,7 @@ func (api *BaseAPI) pruneMode(tx kv.Tx) (*prune.Mode, error) {
 
 	api._pruneMode.Store(&mode)
 
-	return p, nil
+	return &mode, nil
 }
 
 // APIImpl is implementation of the EthAPI interface based on remote Db access
@@ -352,6 +379,7 @@ type APIImpl struct {
 	AllowUnprotectedTxs         bool
 	MaxGetProofRewindBlockCount int
 	SubscribeLogsChannelSize    int
+	blobsReader                 BlobsReader
 	logger                      log.Logger
 }
 
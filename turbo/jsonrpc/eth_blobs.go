@@ -0,0 +1,51 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// BlobsReader - blob sidecars kept by embedded Caplin: after retention window only with `--caplin.blobs-archive`
+type BlobsReader interface {
+	// BlobSidecars - sidecars of canonical execution block. nil - if block has no blobs or they are not available
+	BlobSidecars(ctx context.Context, blockHash common.Hash, blockTime uint64) ([]*cltypes.BlobSidecar, error)
+	// BlobAndProof - blob and its kzg proof by versioned hash. nil - if blob is not available
+	BlobAndProof(ctx context.Context, versionedHash common.Hash) (blob []byte, proof []byte, err error)
+}
+
+var errBlobsNotSupported = errors.New("blob sidecars are available only with embedded Caplin (--internalcl)")
+
+// GetBlobSidecars implements eth_getBlobSidecars. Returns blob sidecars of block, nil - if they are not available (pruned)
+func (api *APIImpl) GetBlobSidecars(ctx context.Context, numberOrHash rpc.BlockNumberOrHash) ([]*cltypes.BlobSidecar, error) {
+	if api.blobsReader == nil {
+		return nil, errBlobsNotSupported
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, blockHash, _, err := rpchelper.GetBlockNumber(numberOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api._blockReader.Header(ctx, tx, blockHash, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, nil
+	}
+	tx.Rollback()
+	if header.BlobGasUsed == nil || *header.BlobGasUsed == 0 {
+		return []*cltypes.BlobSidecar{}, nil
+	}
+	return api.blobsReader.BlobSidecars(ctx, blockHash, header.Time)
+}
@@ -161,6 +161,67 @@ func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// FinalizedHeads send a notification each time finalized block advances: on PoS chains with forkchoice of CL,
+// on bor with milestones and checkpoints of Heimdall. Finality is checked on each new head, several blocks
+// finalized at once produce one notification
+func (api *APIImpl) FinalizedHeads(ctx context.Context) (*rpc.Subscription, error) {
+	if api.filters == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		headers, id := api.filters.SubscribeNewHeads(32)
+		defer api.filters.UnsubscribeHeads(id)
+		var lastFinalized uint64
+		for {
+			select {
+			case h, ok := <-headers:
+				if h != nil {
+					finalized, err := api.finalizedHeader(context.Background(), lastFinalized)
+					if err != nil {
+						log.Warn("[rpc] error while reading finalized header", "err", err)
+					} else if finalized != nil {
+						lastFinalized = finalized.Number.Uint64()
+						if err = notifier.Notify(rpcSub.ID, finalized); err != nil {
+							log.Warn("[rpc] error while notifying subscription", "err", err)
+						}
+					}
+				}
+				if !ok {
+					log.Warn("[rpc] new heads channel was closed")
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// finalizedHeader - header of finalized block if it's above `after`, nil otherwise
+func (api *APIImpl) finalizedHeader(ctx context.Context, after uint64) (*types.Header, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	number, err := rpchelper.GetFinalizedBlockNumber(tx)
+	if err != nil || number <= after {
+		return nil, nil //nolint:nilerr // no finalized block yet
+	}
+	return api._blockReader.HeaderByNumber(ctx, tx, number)
+}
+
 // NewPendingTransactions send a notification each time when a transaction had added into mempool.
 func (api *APIImpl) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
 	if api.filters == nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -38,7 +39,8 @@ import (
 	"github.com/ledgerwatch/erigon/turbo/transactions"
 )
 
-// getReceipts - checking in-mem cache, or else fallback to db, or else fallback to re-exec of block to re-gen receipts
+// getReceipts - checking in-mem cache, or else fallback to db, or else fallback to receipts snapshots, or else fallback to re-exec of block to re-gen receipts.
+// Pruned receipts are re-generated only if enabled by ReceiptsRegenConfig, errReceiptsPruned otherwise
 func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, block *types.Block, senders []common.Address) (types.Receipts, error) {
 	if receipts, ok := api.receiptsCache.Get(block.Hash()); ok {
 		return receipts, nil
@@ -49,13 +51,40 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, block *types.Bloc
 		return receipts, nil
 	}
 
+	receipts, err := api._blockReader.ReceiptsFromSnapshots(ctx, block, senders)
+	if err != nil {
+		return nil, err
+	}
+	if receipts != nil {
+		api.receiptsCache.Add(block.Hash(), receipts)
+		return receipts, nil
+	}
+
+	pruned, err := api.receiptsPruned(tx, block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if pruned {
+		regenCtx, cancel, err := api.regenerationContext(ctx, tx, block.NumberU64(), len(block.Transactions()))
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+		ctx = regenCtx
+	}
+
 	engine := api.engine()
 	chainConfig, err := api.chainConfig(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, 0, api.historyV3(tx))
+	release, err := api.reexec.acquire(ctx, reexecPriorityHigh)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, 0, api.historyV3(tx), api.executionCache)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +95,7 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, block *types.Bloc
 
 	noopWriter := state.NewNoopWriter()
 
-	receipts := make(types.Receipts, len(block.Transactions()))
+	receipts = make(types.Receipts, len(block.Transactions()))
 
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
 		h, e := api._blockReader.Header(ctx, tx, hash, number)
@@ -77,6 +106,9 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, block *types.Bloc
 	}
 	header := block.Header()
 	for i, txn := range block.Transactions() {
+		if pruned && ctx.Err() != nil {
+			return nil, &ReceiptsRegenLimitError{BlockNum: block.NumberU64(), Reason: fmt.Sprintf("re-execution timeout %s", api.receiptsRegen.Timeout)}
+		}
 		ibs.SetTxContext(txn.Hash(), block.Hash(), i)
 		receipt, _, err := core.ApplyTransaction(chainConfig, core.GetHashFn(header, getHeader), engine, nil, gp, ibs, noopWriter, header, txn, usedGas, usedBlobGas, vm.Config{})
 		if err != nil {
@@ -183,6 +215,10 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
+		// logs of one response must come from one db snapshot: tx can't be re-opened here
+		if err := kv.CheckLongRead(tx); err != nil {
+			return nil, fmt.Errorf("blocks %d-%d: %w, use narrower block range", begin, end, err)
+		}
 
 		blockNumber := uint64(iter.Next())
 		var logIndex uint
@@ -420,6 +456,7 @@ func (api *APIImpl) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end
 
 	var blockHash common.Hash
 	var header *types.Header
+	var release func()
 
 	txNumbers, err := applyFiltersV3(tx, begin, end, crit)
 	if err != nil {
@@ -431,6 +468,9 @@ func (api *APIImpl) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end
 		if err = ctx.Err(); err != nil {
 			return nil, err
 		}
+		if err = kv.CheckLongRead(tx); err != nil {
+			return nil, fmt.Errorf("blocks %d-%d: %w, use narrower block range", begin, end, err)
+		}
 		txNum, blockNum, txIndex, isFinalTxn, blockNumChanged, err := iter.Next()
 		if err != nil {
 			return nil, err
@@ -461,6 +501,13 @@ func (api *APIImpl) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end
 			continue
 		}
 
+		// logs are re-executed, a worker is taken once the first matching transaction is found
+		if release == nil {
+			if release, err = api.reexec.acquire(ctx, reexecPriorityLow); err != nil {
+				return nil, err
+			}
+			defer release()
+		}
 		_, err = exec.ExecTxn(txNum, txIndex, txn)
 		if err != nil {
 			return nil, err
@@ -587,6 +634,13 @@ func (api *APIImpl) GetTransactionReceipt(ctx context.Context, txnHash common.Ha
 		}
 	}
 	receipts, err := api.getReceipts(ctx, tx, block, block.Body().SendersFromTxs())
+	if errors.Is(err, errReceiptsPruned) {
+		return nil, nil
+	}
+	var limitErr *ReceiptsRegenLimitError
+	if errors.As(err, &limitErr) {
+		return nil, limitErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getReceipts error: %w", err)
 	}
@@ -633,6 +687,13 @@ func (api *APIImpl) GetBlockReceipts(ctx context.Context, numberOrHash rpc.Block
 		return nil, err
 	}
 	receipts, err := api.getReceipts(ctx, tx, block, block.Body().SendersFromTxs())
+	if errors.Is(err, errReceiptsPruned) {
+		return nil, nil
+	}
+	var limitErr *ReceiptsRegenLimitError
+	if errors.As(err, &limitErr) {
+		return nil, limitErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getReceipts error: %w", err)
 	}
@@ -0,0 +1,135 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/log/v3"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
+	"github.com/ledgerwatch/erigon-lib/kv"
+
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// StateChangesBlock - per-block state diff for external indexers and caches
+type StateChangesBlock struct {
+	BlockNumber  hexutil.Uint64          `json:"blockNumber"`
+	BlockHash    libcommon.Hash          `json:"blockHash"`
+	ReceiptsRoot *libcommon.Hash         `json:"receiptsRoot"` // nil if block is not available in db anymore (unwound)
+	Unwind       bool                    `json:"unwind"`       // changes revert state of this block
+	Accounts     []*StateChangesAccount  `json:"accounts"`
+	Storage      []*StateChangesStorage  `json:"storage,omitempty"`
+	Code         []*StateChangesCodeItem `json:"code,omitempty"`
+}
+
+type StateChangesAccount struct {
+	Address     libcommon.Address `json:"address"`
+	Incarnation hexutil.Uint64    `json:"incarnation"`
+	Deleted     bool              `json:"deleted,omitempty"`
+	Data        hexutility.Bytes  `json:"data,omitempty"` // encoded account
+}
+
+type StateChangesStorage struct {
+	Address  libcommon.Address `json:"address"`
+	Location libcommon.Hash    `json:"location"`
+	Value    hexutility.Bytes  `json:"value"`
+}
+
+type StateChangesCodeItem struct {
+	Address libcommon.Address `json:"address"`
+	Code    hexutility.Bytes  `json:"code"`
+}
+
+// StateChanges send a notification with state diff of each new block (accounts, storage, code).
+// If fromBlock is set - recent blocks starting from fromBlock are replayed first.
+func (api *APIImpl) StateChanges(ctx context.Context, fromBlock *hexutil.Uint64) (*rpc.Subscription, error) {
+	if api.filters == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	var from *uint64
+	if fromBlock != nil {
+		from = (*uint64)(fromBlock)
+	}
+	batches, err := api.filters.SubscribeStateChanges(streamCtx, from, 16)
+	if err != nil {
+		cancel()
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		defer cancel()
+		for {
+			select {
+			case batch, ok := <-batches:
+				if !ok {
+					log.Warn("[rpc] state changes channel was closed")
+					return
+				}
+				for _, sc := range batch.ChangeBatch {
+					if from != nil && sc.BlockHeight < *from {
+						continue
+					}
+					if err := notifier.Notify(rpcSub.ID, api.stateChangesBlock(streamCtx, sc)); err != nil {
+						log.Warn("[rpc] error while notifying subscription", "err", err)
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+func (api *APIImpl) stateChangesBlock(ctx context.Context, sc *remote.StateChange) *StateChangesBlock {
+	res := &StateChangesBlock{
+		BlockNumber: hexutil.Uint64(sc.BlockHeight),
+		BlockHash:   gointerfaces.ConvertH256ToHash(sc.BlockHash),
+		Unwind:      sc.Direction == remote.Direction_UNWIND,
+		Accounts:    []*StateChangesAccount{},
+	}
+	for _, change := range sc.Changes {
+		addr := gointerfaces.ConvertH160toAddress(change.Address)
+		switch change.Action {
+		case remote.Action_UPSERT, remote.Action_UPSERT_CODE:
+			res.Accounts = append(res.Accounts, &StateChangesAccount{Address: addr, Incarnation: hexutil.Uint64(change.Incarnation), Data: change.Data})
+		case remote.Action_REMOVE:
+			res.Accounts = append(res.Accounts, &StateChangesAccount{Address: addr, Incarnation: hexutil.Uint64(change.Incarnation), Deleted: true})
+		}
+		if change.Action == remote.Action_UPSERT_CODE || change.Action == remote.Action_CODE {
+			res.Code = append(res.Code, &StateChangesCodeItem{Address: addr, Code: change.Code})
+		}
+		for _, storageChange := range change.StorageChanges {
+			res.Storage = append(res.Storage, &StateChangesStorage{Address: addr, Location: gointerfaces.ConvertH256ToHash(storageChange.Location), Value: storageChange.Data})
+		}
+	}
+
+	if err := api.db.View(ctx, func(tx kv.Tx) error {
+		header, err := api._blockReader.Header(ctx, tx, res.BlockHash, sc.BlockHeight)
+		if err != nil {
+			return err
+		}
+		if header != nil {
+			res.ReceiptsRoot = &header.ReceiptHash
+		}
+		return nil
+	}); err != nil {
+		log.Debug("[rpc] state changes: can't read header", "block", sc.BlockHeight, "err", err)
+	}
+	return res
+}
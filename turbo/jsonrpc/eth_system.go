@@ -68,11 +68,15 @@ func (api *APIImpl) Syncing(ctx context.Context) (interface{}, error) {
 		stagesMap[i].BlockNumber = hexutil.Uint64(progress)
 	}
 
-	return map[string]interface{}{
+	syncing := map[string]interface{}{
 		"currentBlock": hexutil.Uint64(currentBlock),
 		"highestBlock": hexutil.Uint64(highestBlock),
 		"stages":       stagesMap,
-	}, nil
+	}
+	if finalizedBlock, err := rpchelper.GetFinalizedBlockNumber(tx); err == nil {
+		syncing["finalizedBlock"] = hexutil.Uint64(finalizedBlock)
+	}
+	return syncing, nil
 }
 
 // ChainId implements eth_chainId. Returns the current ethereum chainId.
@@ -0,0 +1,52 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// historySources - where bodies and receipts of expired blocks are available
+var historySources = []string{"era1", "portal"}
+
+// HistoryExpiredError - body or receipts of the block are dropped by EIP-4444 history expiry (--prune=e) and their
+// snapshot files are not present
+type HistoryExpiredError struct {
+	BlockNum    uint64
+	ExpiryBlock uint64
+}
+
+func (e *HistoryExpiredError) Error() string {
+	return fmt.Sprintf("pruned history unavailable: block %d is before history expiry block %d, "+
+		"its body and receipts are available from era1 archives and Portal network", e.BlockNum, e.ExpiryBlock)
+}
+
+func (e *HistoryExpiredError) ErrorCode() int { return 4444 }
+
+func (e *HistoryExpiredError) ErrorData() interface{} {
+	return map[string]interface{}{
+		"blockNumber": hexutil.Uint64(e.BlockNum),
+		"expiryBlock": hexutil.Uint64(e.ExpiryBlock),
+		"sources":     historySources,
+	}
+}
+
+// checkHistoryExpiry - called when block body is not found: *HistoryExpiredError if the block exists but its body is expired
+func (api *BaseAPI) checkHistoryExpiry(ctx context.Context, tx kv.Tx, hash common.Hash, number uint64) error {
+	p, err := api.pruneMode(tx)
+	if err != nil || p == nil {
+		return err
+	}
+	expiryBlock := p.ExpiryBlock()
+	if number >= expiryBlock {
+		return nil
+	}
+	header, err := api._blockReader.Header(ctx, tx, hash, number)
+	if err != nil || header == nil {
+		return err
+	}
+	return &HistoryExpiredError{BlockNum: number, ExpiryBlock: expiryBlock}
+}
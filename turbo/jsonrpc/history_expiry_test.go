@@ -0,0 +1,46 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestHistoryExpiredError(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	ctx := context.Background()
+
+	// body of block 1 is dropped, it's before expiry block
+	tx, err := m.DB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	mode := prune.DefaultMode
+	mode.Blocks = prune.Before(5)
+	require.NoError(t, prune.Override(tx, mode))
+	hash, err := rawdb.ReadCanonicalHash(tx, 1)
+	require.NoError(t, err)
+	rawdb.DeleteBody(tx, hash, 1)
+	require.NoError(t, tx.Commit())
+
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 100_000, false, 100_000, 128, log.New())
+	_, err = api.GetBlockByNumber(ctx, 1, true)
+	var expired *HistoryExpiredError
+	require.ErrorAs(t, err, &expired)
+	require.Equal(t, HistoryExpiredError{BlockNum: 1, ExpiryBlock: 5}, *expired)
+	require.Equal(t, 4444, expired.ErrorCode())
+
+	// blocks with body are served, missing blocks are not an error
+	b, err := api.GetBlockByNumber(ctx, 2, true)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	b, err = api.GetBlockByNumber(ctx, rpc.BlockNumber(1_000), true)
+	require.NoError(t, err)
+	require.Nil(t, b)
+}
@@ -0,0 +1,77 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+var (
+	receiptsRegenerated      = metrics.GetOrCreateCounter("rpc_receipts_regenerated")
+	receiptsRegenRejected    = metrics.GetOrCreateCounter("rpc_receipts_regenerate_rejected")
+	receiptsRegenUnavailable = metrics.GetOrCreateCounter("rpc_receipts_regenerate_unavailable")
+)
+
+// ReceiptsRegenConfig - re-execution of blocks which receipts are pruned (--prune=r, --prune.receipts).
+// Receipts of not pruned blocks are re-generated regardless of it.
+type ReceiptsRegenConfig struct {
+	Enabled bool
+	MaxTxs  int           // maximum transactions of a block to re-execute per request (0 = unlimited)
+	Timeout time.Duration // maximum time of re-execution per request (0 = unlimited)
+}
+
+// errReceiptsPruned - receipts of the block are pruned and can't be re-generated: regeneration is disabled or state
+// history of the block is pruned too. Handlers of receipts return null for it.
+var errReceiptsPruned = fmt.Errorf("receipts have been pruned for this block")
+
+// ReceiptsRegenLimitError - re-generation of pruned receipts of the block exceeds per request limits
+type ReceiptsRegenLimitError struct {
+	BlockNum uint64
+	Reason   string
+}
+
+func (e *ReceiptsRegenLimitError) Error() string {
+	return fmt.Sprintf("receipts of block %d are pruned and re-generating them exceeds limits: %s", e.BlockNum, e.Reason)
+}
+
+func (e *ReceiptsRegenLimitError) ErrorCode() int { return -32005 } // limit exceeded
+
+// receiptsPruned - receipts of the block are dropped by prune mode
+func (api *BaseAPI) receiptsPruned(tx kv.Tx, blockNum uint64) (bool, error) {
+	p, err := api.pruneMode(tx)
+	if err != nil || p == nil || !p.Receipts.Enabled() {
+		return false, err
+	}
+	latest, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), tx, api.filters)
+	if err != nil {
+		return false, err
+	}
+	return blockNum < p.Receipts.PruneTo(latest), nil
+}
+
+// regenerationContext - checks if pruned receipts of the block can be re-generated, ctx bounded by the timeout limit
+func (api *BaseAPI) regenerationContext(ctx context.Context, tx kv.Tx, blockNum uint64, txs int) (context.Context, context.CancelFunc, error) {
+	if !api.receiptsRegen.Enabled {
+		return nil, nil, errReceiptsPruned
+	}
+	if err := api.checkPruneHistory(tx, blockNum); err != nil {
+		receiptsRegenUnavailable.Inc()
+		return nil, nil, errReceiptsPruned
+	}
+	if api.receiptsRegen.MaxTxs > 0 && txs > api.receiptsRegen.MaxTxs {
+		receiptsRegenRejected.Inc()
+		return nil, nil, &ReceiptsRegenLimitError{BlockNum: blockNum, Reason: fmt.Sprintf("%d transactions, limit %d", txs, api.receiptsRegen.MaxTxs)}
+	}
+	receiptsRegenerated.Inc()
+	if api.receiptsRegen.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, api.receiptsRegen.Timeout)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
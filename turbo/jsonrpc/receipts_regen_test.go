@@ -0,0 +1,72 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestRegeneratePrunedReceipts(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	ctx := context.Background()
+
+	// receipts of blocks before 7 are pruned (--prune.r.before=8), state history is available
+	tx, err := m.DB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	mode := prune.DefaultMode
+	mode.Receipts = prune.Before(8)
+	require.NoError(t, prune.Override(tx, mode))
+	block, err := m.BlockReader.BlockByNumber(ctx, tx, 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	txnHash := block.Transactions()[0].Hash()
+
+	newAPI := func(regen ReceiptsRegenConfig) *APIImpl {
+		base := newBaseApiForTest(m)
+		base.receiptsRegen = regen
+		return NewEthAPI(base, m.DB, nil, nil, nil, 5000000, 100_000, false, 100_000, 128, log.New())
+	}
+
+	// disabled: null for pruned, receipts of not pruned blocks are served
+	api := newAPI(ReceiptsRegenConfig{})
+	receipt, err := api.GetTransactionReceipt(ctx, txnHash)
+	require.NoError(t, err)
+	require.Nil(t, receipt)
+	receipts, err := api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	require.Nil(t, receipts)
+	receipts, err = api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(7))
+	require.NoError(t, err)
+	require.NotEmpty(t, receipts)
+
+	api = newAPI(ReceiptsRegenConfig{Enabled: true, MaxTxs: 10})
+	receipt, err = api.GetTransactionReceipt(ctx, txnHash)
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+	require.Equal(t, txnHash, receipt["transactionHash"])
+
+	// per request limits, block 6 has 32 transactions
+	_, err = api.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(6))
+	var limitErr *ReceiptsRegenLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, uint64(6), limitErr.BlockNum)
+
+	// state history is pruned too
+	tx, err = m.DB.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	mode.History = prune.Before(8)
+	require.NoError(t, prune.Override(tx, mode))
+	require.NoError(t, tx.Commit())
+	api = newAPI(ReceiptsRegenConfig{Enabled: true})
+	receipt, err = api.GetTransactionReceipt(ctx, txnHash)
+	require.NoError(t, err)
+	require.Nil(t, receipt)
+}
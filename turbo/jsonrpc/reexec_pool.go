@@ -0,0 +1,124 @@
+package jsonrpc
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/cmp"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+)
+
+var (
+	reexecQueueDepth  = metrics.GetOrCreateGauge("rpc_reexec_queue_depth")
+	reexecBusyWorkers = metrics.GetOrCreateGauge("rpc_reexec_busy_workers")
+	reexecRejected    = metrics.GetOrCreateCounter("rpc_reexec_rejected")
+	reexecWait        = [reexecPriorities]metrics.Summary{
+		reexecPriorityLow:    metrics.GetOrCreateSummary(`rpc_reexec_wait_seconds{priority="low"}`),
+		reexecPriorityNormal: metrics.GetOrCreateSummary(`rpc_reexec_wait_seconds{priority="normal"}`),
+		reexecPriorityHigh:   metrics.GetOrCreateSummary(`rpc_reexec_wait_seconds{priority="high"}`),
+	}
+)
+
+var errReexecQueueFull = errors.New("too many requests re-executing blocks, try again later")
+
+// reexecPriority - order in which queued re-executions get a worker
+type reexecPriority int
+
+const (
+	reexecPriorityLow    reexecPriority = iota // ranges of blocks: debug_traceChain, eth_getLogs
+	reexecPriorityNormal                       // whole blocks: trace_block, debug_traceBlock*
+	reexecPriorityHigh                         // single transactions and receipts of a block
+
+	reexecPriorities = 3
+)
+
+var defaultReexecWorkers = cmp.Max(1, runtime.GOMAXPROCS(-1)/2)
+
+const defaultReexecQueueSize = 1024
+
+// reexecPool - bounded pool of workers for the RPC handlers re-executing transactions of historical blocks (traces,
+// receipts missing from the db, logs of eth_getLogs), isolating them from the latency-sensitive calls like eth_call.
+// A handler holds one of the workers while it re-executes, handlers waiting for a worker are queued by priority and in
+// arrival order within a priority. The queue is bounded, handlers are rejected when it is full.
+type reexecPool struct {
+	mu        sync.Mutex
+	free      int
+	queued    int
+	queueSize int
+	queues    [reexecPriorities]list.List // of *reexecWaiter
+}
+
+type reexecWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newReexecPool(workers, queueSize int) *reexecPool {
+	return &reexecPool{free: workers, queueSize: queueSize}
+}
+
+// acquire - waits for a worker, the returned func releases it
+func (p *reexecPool) acquire(ctx context.Context, priority reexecPriority) (release func(), err error) {
+	start := time.Now()
+	p.mu.Lock()
+	if p.free > 0 && p.queued == 0 {
+		p.free--
+		p.mu.Unlock()
+		reexecBusyWorkers.Inc()
+		reexecWait[priority].ObserveDuration(start)
+		return p.release, nil
+	}
+	if p.queued >= p.queueSize {
+		p.mu.Unlock()
+		reexecRejected.Inc()
+		return nil, errReexecQueueFull
+	}
+	w := &reexecWaiter{ready: make(chan struct{})}
+	elem := p.queues[priority].PushBack(w)
+	p.queued++
+	p.mu.Unlock()
+	reexecQueueDepth.Inc()
+
+	select {
+	case <-w.ready:
+		reexecWait[priority].ObserveDuration(start)
+		return p.release, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		granted := w.granted
+		if !granted {
+			p.queues[priority].Remove(elem)
+			p.queued--
+		}
+		p.mu.Unlock()
+		if granted {
+			// the worker was handed over concurrently with the cancellation
+			p.release()
+		} else {
+			reexecQueueDepth.Dec()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release - hands the worker over to the first waiter of the highest priority
+func (p *reexecPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for priority := reexecPriorities - 1; priority >= 0; priority-- {
+		if front := p.queues[priority].Front(); front != nil {
+			w := p.queues[priority].Remove(front).(*reexecWaiter)
+			p.queued--
+			w.granted = true
+			close(w.ready)
+			reexecQueueDepth.Dec()
+			return
+		}
+	}
+	p.free++
+	reexecBusyWorkers.Dec()
+}
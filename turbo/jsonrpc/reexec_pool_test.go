@@ -0,0 +1,55 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReexecPool(t *testing.T) {
+	ctx := context.Background()
+	p := newReexecPool(1, 2)
+	release, err := p.acquire(ctx, reexecPriorityNormal)
+	require.NoError(t, err)
+
+	// waiters get the worker by priority
+	granted := make(chan reexecPriority, 2)
+	for _, priority := range []reexecPriority{reexecPriorityLow, reexecPriorityHigh} {
+		priority := priority
+		go func() {
+			release, err := p.acquire(ctx, priority)
+			if err == nil {
+				granted <- priority
+				release()
+			}
+		}()
+		require.Eventually(t, func() bool {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return p.queues[priority].Len() == 1
+		}, 10*time.Second, time.Millisecond)
+	}
+
+	// the queue is full
+	_, err = p.acquire(ctx, reexecPriorityHigh)
+	require.ErrorIs(t, err, errReexecQueueFull)
+
+	release()
+	require.Equal(t, reexecPriorityHigh, <-granted)
+	require.Equal(t, reexecPriorityLow, <-granted)
+
+	// cancelled waiters leave the queue
+	release, err = p.acquire(ctx, reexecPriorityNormal)
+	require.NoError(t, err)
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = p.acquire(cancelCtx, reexecPriorityHigh)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	release()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	require.Equal(t, 1, p.free)
+	require.Zero(t, p.queued)
+}
@@ -2,13 +2,16 @@ package jsonrpc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
 	txPoolProto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/txpool"
 
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
@@ -17,53 +20,160 @@ import (
 
 // SendRawTransaction implements eth_sendRawTransaction. Creates new message call transaction or a contract creation for previously-signed transactions.
 func (api *APIImpl) SendRawTransaction(ctx context.Context, encodedTx hexutility.Bytes) (common.Hash, error) {
-	txn, err := types.DecodeWrappedTransaction(encodedTx)
+	txn, err := api.checkRawTransaction(ctx, encodedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hash := txn.Hash()
+	res, err := api.txPool.Add(ctx, &txPoolProto.AddRequest{RlpTxs: [][]byte{encodedTx}})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if res.Imported[0] != txPoolProto.ImportResult_SUCCESS {
+		return hash, fmt.Errorf("%s: %s", txPoolProto.ImportResult_name[int32(res.Imported[0])], res.Errors[0])
+	}
+
+	return txn.Hash(), nil
+}
+
+// SendPrivateRawTransaction implements eth_sendPrivateRawTransaction. Like eth_sendRawTransaction, but transaction is
+// only included into blocks produced by this node: it's never gossiped to peers. Transaction is dropped if it's not
+// mined until maxBlockNumber (by default - `--txpool.private.blocks` after current block).
+func (api *APIImpl) SendPrivateRawTransaction(ctx context.Context, encodedTx hexutility.Bytes, maxBlockNumber *hexutil.Uint64) (common.Hash, error) {
+	txn, err := api.checkRawTransaction(ctx, encodedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	req := &txPoolProto.AddPrivateRequest{RlpTxs: [][]byte{encodedTx}}
+	if maxBlockNumber != nil {
+		req.MaxBlockNumber = uint64(*maxBlockNumber)
+	}
+	hash := txn.Hash()
+	res, err := api.txPool.AddPrivate(ctx, req)
 	if err != nil {
 		return common.Hash{}, err
 	}
 
+	if res.Imported[0] != txPoolProto.ImportResult_SUCCESS {
+		return hash, fmt.Errorf("%s: %s", txPoolProto.ImportResult_name[int32(res.Imported[0])], res.Errors[0])
+	}
+
+	return hash, nil
+}
+
+// TransactionConditions - options of eth_sendRawTransactionConditional
+type TransactionConditions struct {
+	KnownAccounts  map[common.Address]KnownAccountStorage `json:"knownAccounts"`
+	BlockNumberMin *hexutil.Uint64                        `json:"blockNumberMin"`
+	BlockNumberMax *hexutil.Uint64                        `json:"blockNumberMax"`
+	TimestampMin   *hexutil.Uint64                        `json:"timestampMin"`
+	TimestampMax   *hexutil.Uint64                        `json:"timestampMax"`
+}
+
+// KnownAccountStorage - expected storage root of account, or expected values of its storage slots
+type KnownAccountStorage struct {
+	StorageRoot *common.Hash
+	Slots       map[common.Hash]common.Hash
+}
+
+func (s *KnownAccountStorage) UnmarshalJSON(data []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(data, &root); err == nil {
+		s.StorageRoot = &root
+		return nil
+	}
+	return json.Unmarshal(data, &s.Slots)
+}
+
+func (c *TransactionConditions) toTxPool() (*txpool.Conditions, error) {
+	res := &txpool.Conditions{KnownAccounts: make(map[common.Address]map[common.Hash]common.Hash, len(c.KnownAccounts))}
+	for addr, storage := range c.KnownAccounts {
+		if storage.StorageRoot != nil {
+			return nil, fmt.Errorf("storage root condition of %x is not supported, expected values of storage slots", addr)
+		}
+		res.KnownAccounts[addr] = storage.Slots
+	}
+	res.BlockNumberMin, res.BlockNumberMax = optionalUint64(c.BlockNumberMin), optionalUint64(c.BlockNumberMax)
+	res.TimestampMin, res.TimestampMax = optionalUint64(c.TimestampMin), optionalUint64(c.TimestampMax)
+	return res, res.Validate()
+}
+
+func optionalUint64(v *hexutil.Uint64) *uint64 {
+	if v == nil {
+		return nil
+	}
+	res := uint64(*v)
+	return &res
+}
+
+// SendRawTransactionConditional implements eth_sendRawTransactionConditional. Like eth_sendPrivateRawTransaction,
+// but transaction is kept in txpool only while its conditions are met: values of storage slots of known accounts,
+// range of block numbers and timestamps (checked against wall clock). Transaction is dropped as soon as new block
+// invalidates them.
+func (api *APIImpl) SendRawTransactionConditional(ctx context.Context, encodedTx hexutility.Bytes, options TransactionConditions) (common.Hash, error) {
+	conditions, err := options.toTxPool()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	txn, err := api.checkRawTransaction(ctx, encodedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hash := txn.Hash()
+	res, err := api.txPool.AddConditional(ctx, txpool.AddConditionalRequest([][]byte{encodedTx}, conditions))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if res.Imported[0] != txPoolProto.ImportResult_SUCCESS {
+		return hash, fmt.Errorf("%s: %s", txPoolProto.ImportResult_name[int32(res.Imported[0])], res.Errors[0])
+	}
+
+	return hash, nil
+}
+
+// checkRawTransaction - decodes transaction submitted over RPC and checks its fee and chain id
+func (api *APIImpl) checkRawTransaction(ctx context.Context, encodedTx hexutility.Bytes) (types.Transaction, error) {
+	txn, err := types.DecodeWrappedTransaction(encodedTx)
+	if err != nil {
+		return nil, err
+	}
+
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(txn.GetPrice().ToBig(), txn.GetGas(), ethconfig.Defaults.RPCTxFeeCap); err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
 	if !txn.Protected() && !api.AllowUnprotectedTxs {
-		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+		return nil, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
 
 	// this has been moved to prior to adding of transactions to capture the
 	// pre state of the db - which is used for logging in the messages below
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
 
 	defer tx.Rollback()
 
 	cc, err := api.chainConfig(ctx, tx)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
 
 	if txn.Protected() {
 		txnChainId := txn.GetChainID()
 		chainId := cc.ChainID
 		if chainId.Cmp(txnChainId.ToBig()) != 0 {
-			return common.Hash{}, fmt.Errorf("invalid chain id, expected: %d got: %d", chainId, *txnChainId)
+			return nil, fmt.Errorf("invalid chain id, expected: %d got: %d", chainId, *txnChainId)
 		}
 	}
-
-	hash := txn.Hash()
-	res, err := api.txPool.Add(ctx, &txPoolProto.AddRequest{RlpTxs: [][]byte{encodedTx}})
-	if err != nil {
-		return common.Hash{}, err
-	}
-
-	if res.Imported[0] != txPoolProto.ImportResult_SUCCESS {
-		return hash, fmt.Errorf("%s: %s", txPoolProto.ImportResult_name[int32(res.Imported[0])], res.Errors[0])
-	}
-
-	return txn.Hash(), nil
+	return txn, nil
 }
 
 // SendTransaction implements eth_sendTransaction. Creates new message call transaction or a contract creation if the data field contains code.
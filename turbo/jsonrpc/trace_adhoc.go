@@ -227,10 +227,8 @@ func (args *TraceCallParam) ToMessage(globalGasCap uint64, baseFee *uint256.Int)
 // OpenEthereum-style tracer
 type OeTracer struct {
 	r            *TraceCallResult
-	traceAddr    []int
-	traceStack   []*ParityTrace
-	precompile   bool // Whether the last CaptureStart was called with `precompile = true`
-	compat       bool // Bug for bug compatibility mode
+	flat         flatTracer // Builds the "trace" part of the result
+	compat       bool       // Bug for bug compatibility mode
 	lastVmOp     *VmTraceOp
 	lastOp       vm.OpCode
 	lastMemOff   uint64
@@ -277,79 +275,10 @@ func (ot *OeTracer) captureStartOrEnter(deep bool, typ vm.OpCode, from libcommon
 			vmTrace.Code = code
 		}
 	}
-	if precompile && deep && (value == nil || value.IsZero()) {
-		ot.precompile = true
-		return
-	}
-	if gas > 500000000 {
-		gas = 500000001 - (0x8000000000000000 - gas)
-	}
-	trace := &ParityTrace{}
-	if create {
-		trResult := &CreateTraceResult{}
-		trace.Type = CREATE
-		trResult.Address = new(libcommon.Address)
-		copy(trResult.Address[:], to.Bytes())
-		trace.Result = trResult
-	} else {
-		trace.Result = &TraceResult{}
-		trace.Type = CALL
-	}
-	if deep {
-		topTrace := ot.traceStack[len(ot.traceStack)-1]
-		traceIdx := topTrace.Subtraces
-		ot.traceAddr = append(ot.traceAddr, traceIdx)
-		topTrace.Subtraces++
-		if typ == vm.DELEGATECALL {
-			switch action := topTrace.Action.(type) {
-			case *CreateTraceAction:
-				value, _ = uint256.FromBig(action.Value.ToInt())
-			case *CallTraceAction:
-				value, _ = uint256.FromBig(action.Value.ToInt())
-			}
-		}
-		if typ == vm.STATICCALL {
-			value = uint256.NewInt(0)
-		}
+	if !deep {
+		ot.flat.compat = ot.compat
 	}
-	trace.TraceAddress = make([]int, len(ot.traceAddr))
-	copy(trace.TraceAddress, ot.traceAddr)
-	if create {
-		action := CreateTraceAction{}
-		action.From = from
-		action.Gas.ToInt().SetUint64(gas)
-		action.Init = libcommon.CopyBytes(input)
-		action.Value.ToInt().Set(value.ToBig())
-		trace.Action = &action
-	} else if typ == vm.SELFDESTRUCT {
-		trace.Type = SUICIDE
-		trace.Result = nil
-		action := &SuicideTraceAction{}
-		action.Address = from
-		action.RefundAddress = to
-		action.Balance.ToInt().Set(value.ToBig())
-		trace.Action = action
-	} else {
-		action := CallTraceAction{}
-		switch typ {
-		case vm.CALL:
-			action.CallType = CALL
-		case vm.CALLCODE:
-			action.CallType = CALLCODE
-		case vm.DELEGATECALL:
-			action.CallType = DELEGATECALL
-		case vm.STATICCALL:
-			action.CallType = STATICCALL
-		}
-		action.From = from
-		action.To = to
-		action.Gas.ToInt().SetUint64(gas)
-		action.Input = libcommon.CopyBytes(input)
-		action.Value.ToInt().Set(value.ToBig())
-		trace.Action = &action
-	}
-	ot.r.Trace = append(ot.r.Trace, trace)
-	ot.traceStack = append(ot.traceStack, trace)
+	ot.flat.enter(deep, typ, from, to, precompile, create, input, gas, value)
 }
 
 func (ot *OeTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
@@ -376,56 +305,13 @@ func (ot *OeTracer) captureEndOrExit(deep bool, output []byte, usedGas uint64, e
 			ot.memLenStack = ot.memLenStack[:len(ot.memLenStack)-1]
 		}
 	}
-	if ot.precompile {
-		ot.precompile = false
-		return
-	}
-	if !deep {
+	if !deep && !ot.flat.precompile {
 		ot.r.Output = libcommon.CopyBytes(output)
 	}
-	ignoreError := false
-	topTrace := ot.traceStack[len(ot.traceStack)-1]
-	if ot.compat {
-		ignoreError = !deep && topTrace.Type == CREATE
-	}
-	if err != nil && !ignoreError {
-		if err == vm.ErrExecutionReverted {
-			topTrace.Error = "Reverted"
-			switch topTrace.Type {
-			case CALL:
-				topTrace.Result.(*TraceResult).GasUsed = new(hexutil.Big)
-				topTrace.Result.(*TraceResult).GasUsed.ToInt().SetUint64(usedGas)
-				topTrace.Result.(*TraceResult).Output = libcommon.CopyBytes(output)
-			case CREATE:
-				topTrace.Result.(*CreateTraceResult).GasUsed = new(hexutil.Big)
-				topTrace.Result.(*CreateTraceResult).GasUsed.ToInt().SetUint64(usedGas)
-				topTrace.Result.(*CreateTraceResult).Code = libcommon.CopyBytes(output)
-			}
-		} else {
-			topTrace.Result = nil
-			topTrace.Error = err.Error()
-		}
-	} else {
-		if len(output) > 0 {
-			switch topTrace.Type {
-			case CALL:
-				topTrace.Result.(*TraceResult).Output = libcommon.CopyBytes(output)
-			case CREATE:
-				topTrace.Result.(*CreateTraceResult).Code = libcommon.CopyBytes(output)
-			}
-		}
-		switch topTrace.Type {
-		case CALL:
-			topTrace.Result.(*TraceResult).GasUsed = new(hexutil.Big)
-			topTrace.Result.(*TraceResult).GasUsed.ToInt().SetUint64(usedGas)
-		case CREATE:
-			topTrace.Result.(*CreateTraceResult).GasUsed = new(hexutil.Big)
-			topTrace.Result.(*CreateTraceResult).GasUsed.ToInt().SetUint64(usedGas)
-		}
-	}
-	ot.traceStack = ot.traceStack[:len(ot.traceStack)-1]
-	if deep {
-		ot.traceAddr = ot.traceAddr[:len(ot.traceAddr)-1]
+	ot.flat.exit(deep, output, usedGas, err)
+	if !deep {
+		ot.r.Trace = append(ot.r.Trace, ot.flat.traces...)
+		ot.flat.traces = nil
 	}
 }
 
@@ -770,7 +656,7 @@ func (api *TraceAPIImpl) ReplayTransaction(ctx context.Context, txHash libcommon
 
 	signer := types.MakeSigner(chainConfig, blockNum, block.Time())
 	// Returns an array of trace arrays, one trace array for each transaction
-	traces, _, err := api.callManyTransactions(ctx, tx, block, traceTypes, txnIndex, *gasBailOut, signer, chainConfig)
+	traces, _, err := api.callManyTransactions(ctx, tx, block, traceTypes, txnIndex, *gasBailOut, signer, chainConfig, api.compatibility)
 	if err != nil {
 		return nil, err
 	}
@@ -852,11 +738,39 @@ func (api *TraceAPIImpl) ReplayBlockTransactions(ctx context.Context, blockNrOrH
 		}
 	}
 
-	signer := types.MakeSigner(chainConfig, blockNumber, block.Time())
-	// Returns an array of trace arrays, one trace array for each transaction
-	traces, _, err := api.callManyTransactions(ctx, tx, block, traceTypes, -1 /* all tx indices */, *gasBailOut, signer, chainConfig)
-	if err != nil {
-		return nil, err
+	// Within the history window the stateDiff is read from the history of the block instead of re-executing it. State
+	// sync transactions of bor are not executed at their own txNum, blocks of bor are re-executed.
+	historyStateDiff := traceTypeStateDiff && api.historyV3(tx) && chainConfig.Bor == nil && api.checkPruneHistory(tx, blockNumber) == nil
+
+	var traces []*TraceCallResult
+	if historyStateDiff && !traceTypeVmTrace {
+		bt, err := api.flatTraceBlock(ctx, tx, block, chainConfig, api.compatibility, *gasBailOut)
+		if err != nil {
+			return nil, err
+		}
+		traces = replayTraces(bt)
+	} else {
+		if historyStateDiff {
+			traceTypes = []string{TraceTypeVmTrace}
+			if traceTypeTrace {
+				traceTypes = append(traceTypes, TraceTypeTrace)
+			}
+		}
+		signer := types.MakeSigner(chainConfig, blockNumber, block.Time())
+		// Returns an array of trace arrays, one trace array for each transaction
+		traces, _, err = api.callManyTransactions(ctx, tx, block, traceTypes, -1 /* all tx indices */, *gasBailOut, signer, chainConfig, api.compatibility)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if historyStateDiff {
+		diffs, err := historyStateDiffs(tx.(kv.TemporalTx), blockNumber, len(traces))
+		if err != nil {
+			return nil, err
+		}
+		for i, diff := range diffs {
+			traces[i].StateDiff = diff
+		}
 	}
 
 	result := make([]*TraceCallResult, len(traces))
@@ -955,7 +869,6 @@ func (api *TraceAPIImpl) Call(ctx context.Context, args TraceCallParam, traceTyp
 	ot.compat = api.compatibility
 	if traceTypeTrace || traceTypeVmTrace {
 		ot.r = traceResult
-		ot.traceAddr = []int{}
 	}
 
 	// Get a new instance of the EVM.
@@ -1096,12 +1009,12 @@ func (api *TraceAPIImpl) CallMany(ctx context.Context, calls json.RawMessage, pa
 			return nil, fmt.Errorf("convert callParam to msg: %w", err)
 		}
 	}
-	results, _, err := api.doCallMany(ctx, dbtx, msgs, callParams, parentNrOrHash, nil, true /* gasBailout */, -1 /* all tx indices */)
+	results, _, err := api.doCallMany(ctx, dbtx, msgs, callParams, parentNrOrHash, nil, true /* gasBailout */, -1 /* all tx indices */, api.compatibility)
 	return results, err
 }
 
-func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []types.Message, callParams []TraceCallParam,
-	parentNrOrHash *rpc.BlockNumberOrHash, header *types.Header, gasBailout bool, txIndexNeeded int,
+func (api *BaseAPI) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []types.Message, callParams []TraceCallParam,
+	parentNrOrHash *rpc.BlockNumberOrHash, header *types.Header, gasBailout bool, txIndexNeeded int, compat bool,
 ) ([]*TraceCallResult, *state.IntraBlockState, error) {
 	chainConfig, err := api.chainConfig(ctx, dbtx)
 	if err != nil {
@@ -1182,19 +1095,20 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []type
 
 		traceResult := &TraceCallResult{Trace: []*ParityTrace{}, TransactionHash: args.txHash}
 		vmConfig := vm.Config{}
-		if (traceTypeTrace && (txIndexNeeded == -1 || txIndex == txIndexNeeded)) || traceTypeVmTrace {
+		// the flat tracer follows call frames only, vmTrace needs every opcode
+		var ft *flatTracer
+		if traceTypeVmTrace {
 			var ot OeTracer
-			ot.compat = api.compatibility
+			ot.compat = compat
 			ot.r = traceResult
 			ot.idx = []string{fmt.Sprintf("%d-", txIndex)}
-			if traceTypeTrace && (txIndexNeeded == -1 || txIndex == txIndexNeeded) {
-				ot.traceAddr = []int{}
-			}
-			if traceTypeVmTrace {
-				traceResult.VmTrace = &VmTrace{Ops: []*VmTraceOp{}}
-			}
+			traceResult.VmTrace = &VmTrace{Ops: []*VmTraceOp{}}
 			vmConfig.Debug = true
 			vmConfig.Tracer = &ot
+		} else if traceTypeTrace && (txIndexNeeded == -1 || txIndex == txIndexNeeded) {
+			ft = &flatTracer{compat: compat}
+			vmConfig.Debug = true
+			vmConfig.Tracer = ft
 		}
 
 		blockCtx := transactions.NewEVMBlockContext(engine, header, parentNrOrHash.RequireCanonical, dbtx, api._blockReader)
@@ -1260,6 +1174,9 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx kv.Tx, msgs []type
 
 		chainRules := chainConfig.Rules(blockCtx.BlockNumber, blockCtx.Time)
 		traceResult.Output = libcommon.CopyBytes(execResult.ReturnData)
+		if ft != nil {
+			traceResult.Trace = append(traceResult.Trace, ft.traces...)
+		}
 		if traceTypeStateDiff {
 			initialIbs := state.New(cloneReader)
 			if !txFinalized {
@@ -13,7 +13,9 @@ import (
 
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 )
 
 func TestEmptyQuery(t *testing.T) {
@@ -101,3 +103,37 @@ func TestReplayBlockTransactions(t *testing.T) {
 	v := addrDiff.Balance.(map[string]*hexutil.Big)["+"].ToInt().Uint64()
 	require.Equal(t, uint64(1_000_000_000_000_000), v)
 }
+
+func TestReplayBlockTransactionsStateDiffFromHistory(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewTraceAPI(newBaseApiForTest(m), m.DB, &httpcfg.HttpCfg{})
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.True(t, api.historyV3(tx))
+	chainConfig, err := api.chainConfig(m.Ctx, tx)
+	require.NoError(t, err)
+	latest, err := rpchelper.GetLatestBlockNumber(tx)
+	require.NoError(t, err)
+
+	for _, traceTypes := range [][]string{{"stateDiff"}, {"trace", "stateDiff"}, {"trace", "vmTrace", "stateDiff"}} {
+		for blockNum := uint64(1); blockNum <= latest; blockNum++ {
+			block, err := m.BlockReader.BlockByNumber(m.Ctx, tx, blockNum)
+			require.NoError(t, err)
+			// re-executed with the state diff of the execution
+			signer := types.MakeSigner(chainConfig, blockNum, block.Time())
+			want, _, err := api.callManyTransactions(m.Ctx, tx, block, traceTypes, -1, false, signer, chainConfig, api.compatibility)
+			require.NoError(t, err)
+			n := rpc.BlockNumber(blockNum)
+			have, err := api.ReplayBlockTransactions(m.Ctx, rpc.BlockNumberOrHash{BlockNumber: &n}, traceTypes, new(bool))
+			require.NoError(t, err)
+
+			wantJson, err := json.Marshal(want)
+			require.NoError(t, err)
+			haveJson, err := json.Marshal(have)
+			require.NoError(t, err)
+			require.JSONEq(t, string(wantJson), string(haveJson), "block %d %v", blockNum, traceTypes)
+		}
+	}
+}
@@ -27,7 +27,7 @@ type TraceAPI interface {
 
 	Transaction(ctx context.Context, txHash libcommon.Hash, gasBailOut *bool) (ParityTraces, error)
 	Get(ctx context.Context, txHash libcommon.Hash, txIndicies []hexutil.Uint64, gasBailOut *bool) (*ParityTrace, error)
-	Block(ctx context.Context, blockNr rpc.BlockNumber, gasBailOut *bool) (ParityTraces, error)
+	Block(ctx context.Context, blockNr rpc.BlockNumber, gasBailOut *bool, page *TraceBlockPage) (ParityTraces, error)
 	Filter(ctx context.Context, req TraceFilterRequest, gasBailOut *bool, stream *jsoniter.Stream) error
 }
 
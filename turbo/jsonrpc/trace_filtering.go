@@ -97,32 +97,14 @@ func (api *TraceAPIImpl) Transaction(ctx context.Context, txHash common.Hash, ga
 		}
 	}
 
-	bn := hexutil.Uint64(blockNumber)
-	hash := block.Hash()
-	signer := types.MakeSigner(chainConfig, blockNumber, block.Time())
-	// Returns an array of trace arrays, one trace array for each transaction
-	traces, _, err := api.callManyTransactions(ctx, tx, block, []string{TraceTypeTrace}, txIndex, *gasBailOut, signer, chainConfig)
+	traces, err := api.flatTraceBlock(ctx, tx, block, chainConfig, api.compatibility, *gasBailOut)
 	if err != nil {
 		return nil, err
 	}
-
-	out := make([]ParityTrace, 0, len(traces))
-	blockno := uint64(bn)
-	for txno, trace := range traces {
-		// We're only looking for a specific transaction
-		if txno == txIndex {
-			for _, pt := range trace.Trace {
-				pt.BlockHash = &hash
-				pt.BlockNumber = &blockno
-				pt.TransactionHash = trace.TransactionHash
-				txpos := uint64(txno)
-				pt.TransactionPosition = &txpos
-				out = append(out, *pt)
-			}
-		}
+	if txIndex >= len(traces.txStart) {
+		return ParityTraces{}, nil
 	}
-
-	return out, err
+	return traces.txTraces(txIndex), nil
 }
 
 // Get implements trace_get
@@ -161,8 +143,8 @@ func rewardKindToString(kind consensus.RewardKind) string {
 	}
 }
 
-// Block implements trace_block
-func (api *TraceAPIImpl) Block(ctx context.Context, blockNr rpc.BlockNumber, gasBailOut *bool) (ParityTraces, error) {
+// Block implements trace_block, traces of blocks with many internal calls can be fetched page by page
+func (api *TraceAPIImpl) Block(ctx context.Context, blockNr rpc.BlockNumber, gasBailOut *bool, page *TraceBlockPage) (ParityTraces, error) {
 	if gasBailOut == nil {
 		gasBailOut = new(bool) // false by default
 	}
@@ -193,46 +175,11 @@ func (api *TraceAPIImpl) Block(ctx context.Context, blockNr rpc.BlockNumber, gas
 	if err != nil {
 		return nil, err
 	}
-	signer := types.MakeSigner(cfg, blockNum, block.Time())
-	traces, syscall, err := api.callManyTransactions(ctx, tx, block, []string{TraceTypeTrace}, -1 /* all tx indices */, *gasBailOut /* gasBailOut */, signer, cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	out := make([]ParityTrace, 0, len(traces))
-	for txno, trace := range traces {
-		txpos := uint64(txno)
-		for _, pt := range trace.Trace {
-			pt.BlockHash = &hash
-			pt.BlockNumber = &blockNum
-			pt.TransactionHash = trace.TransactionHash
-			pt.TransactionPosition = &txpos
-			out = append(out, *pt)
-		}
-	}
-
-	rewards, err := api.engine().CalculateRewards(cfg, block.Header(), block.Uncles(), syscall)
+	traces, err := api.flatTraceBlock(ctx, tx, block, cfg, api.compatibility, *gasBailOut)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, r := range rewards {
-		var tr ParityTrace
-		rewardAction := &RewardTraceAction{}
-		rewardAction.Author = r.Beneficiary
-		rewardAction.RewardType = rewardKindToString(r.Kind)
-		rewardAction.Value.ToInt().Set(r.Amount.ToBig())
-		tr.Action = rewardAction
-		tr.BlockHash = &common.Hash{}
-		copy(tr.BlockHash[:], block.Hash().Bytes())
-		tr.BlockNumber = new(uint64)
-		*tr.BlockNumber = block.NumberU64()
-		tr.Type = "reward" // nolint: goconst
-		tr.TraceAddress = []int{}
-		out = append(out, tr)
-	}
-
-	return out, err
+	return page.slice(traces.traces), nil
 }
 
 func traceFilterBitmaps(tx kv.Tx, req TraceFilterRequest, from, to uint64) (fromAddresses, toAddresses map[common.Address]struct{}, allBlocks *roaring64.Bitmap, err error) {
@@ -424,10 +371,7 @@ func (api *TraceAPIImpl) Filter(ctx context.Context, req TraceFilterRequest, gas
 			continue
 		}
 
-		blockHash := block.Hash()
-		blockNumber := block.NumberU64()
-		signer := types.MakeSigner(chainConfig, b, block.Time())
-		t, syscall, tErr := api.callManyTransactions(ctx, dbtx, block, []string{TraceTypeTrace}, -1 /* all tx indices */, *gasBailOut, signer, chainConfig)
+		traces, tErr := api.flatTraceBlock(ctx, dbtx, block, chainConfig, api.compatibility, *gasBailOut)
 		if tErr != nil {
 			if first {
 				first = false
@@ -441,86 +385,39 @@ func (api *TraceAPIImpl) Filter(ctx context.Context, req TraceFilterRequest, gas
 		}
 		isIntersectionMode := req.Mode == TraceFilterModeIntersection
 		includeAll := len(fromAddresses) == 0 && len(toAddresses) == 0
-		for i, trace := range t {
-			txPosition := uint64(i)
-			// Check if transaction concerns any of the addresses we wanted
-			for _, pt := range trace.Trace {
-				if includeAll || filterTrace(pt, fromAddresses, toAddresses, isIntersectionMode) {
-					nSeen++
-					pt.BlockHash = &blockHash
-					pt.BlockNumber = &blockNumber
-					pt.TransactionHash = trace.TransactionHash
-					pt.TransactionPosition = &txPosition
-					b, err := json.Marshal(pt)
-					if err != nil {
-						if first {
-							first = false
-						} else {
-							stream.WriteMore()
-						}
-						stream.WriteObjectStart()
-						rpc.HandleError(err, stream)
-						stream.WriteObjectEnd()
-						continue
-					}
-					if nSeen > after && nExported < count {
-						if first {
-							first = false
-						} else {
-							stream.WriteMore()
-						}
-						if _, err := stream.Write(b); err != nil {
-							return err
-						}
-						nExported++
-					}
+		for i := range traces.traces {
+			pt := &traces.traces[i]
+			if i < traces.rewards {
+				// Check if transaction concerns any of the addresses we wanted
+				if !includeAll && !filterTrace(pt, fromAddresses, toAddresses, isIntersectionMode) {
+					continue
 				}
+			} else if _, ok := toAddresses[pt.Action.(*RewardTraceAction).Author]; !ok && !includeAll {
+				continue
 			}
-		}
-
-		rewards, err := api.engine().CalculateRewards(chainConfig, block.Header(), block.Uncles(), syscall)
-		if err != nil {
-			return err
-		}
-
-		for _, r := range rewards {
-			if _, ok := toAddresses[r.Beneficiary]; ok || includeAll {
-				nSeen++
-				var tr ParityTrace
-				rewardAction := &RewardTraceAction{}
-				rewardAction.Author = r.Beneficiary
-				rewardAction.RewardType = rewardKindToString(r.Kind)
-				rewardAction.Value.ToInt().Set(r.Amount.ToBig())
-				tr.Action = rewardAction
-				tr.BlockHash = &common.Hash{}
-				copy(tr.BlockHash[:], block.Hash().Bytes())
-				tr.BlockNumber = new(uint64)
-				*tr.BlockNumber = block.NumberU64()
-				tr.Type = "reward" // nolint: goconst
-				tr.TraceAddress = []int{}
-				b, err := json.Marshal(tr)
-				if err != nil {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					stream.WriteObjectStart()
-					rpc.HandleError(err, stream)
-					stream.WriteObjectEnd()
-					continue
+			nSeen++
+			b, err := json.Marshal(pt)
+			if err != nil {
+				if first {
+					first = false
+				} else {
+					stream.WriteMore()
 				}
-				if nSeen > after && nExported < count {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					if _, err := stream.Write(b); err != nil {
-						return err
-					}
-					nExported++
+				stream.WriteObjectStart()
+				rpc.HandleError(err, stream)
+				stream.WriteObjectEnd()
+				continue
+			}
+			if nSeen > after && nExported < count {
+				if first {
+					first = false
+				} else {
+					stream.WriteMore()
+				}
+				if _, err := stream.Write(b); err != nil {
+					return err
 				}
+				nExported++
 			}
 		}
 	}
@@ -778,7 +675,6 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 		ot.compat = api.compatibility
 		ot.r = traceResult
 		ot.idx = []string{fmt.Sprintf("%d-", txIndex)}
-		ot.traceAddr = []int{}
 		vmConfig.Debug = true
 		vmConfig.Tracer = &ot
 		ibs := state.New(cachedReader)
@@ -889,7 +785,7 @@ func filterTrace(pt *ParityTrace, fromAddresses map[common.Address]struct{}, toA
 	}
 }
 
-func (api *TraceAPIImpl) callManyTransactions(
+func (api *BaseAPI) callManyTransactions(
 	ctx context.Context,
 	dbtx kv.Tx,
 	block *types.Block,
@@ -898,6 +794,7 @@ func (api *TraceAPIImpl) callManyTransactions(
 	gasBailOut bool,
 	signer *types.Signer,
 	cfg *chain.Config,
+	compat bool,
 ) ([]*TraceCallResult, consensus.SystemCall, error) {
 	blockNumber := block.NumberU64()
 	pNo := blockNumber
@@ -925,6 +822,16 @@ func (api *TraceAPIImpl) callManyTransactions(
 		}
 	}
 
+	priority := reexecPriorityNormal
+	if txIndex != -1 {
+		priority = reexecPriorityHigh
+	}
+	release, err := api.reexec.acquire(ctx, priority)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
 	callParams := make([]TraceCallParam, 0, len(txs))
 	reader, err := rpchelper.CreateHistoryStateReader(dbtx, blockNumber, txIndex, api.historyV3(dbtx), cfg.ChainName)
 	if err != nil {
@@ -984,7 +891,7 @@ func (api *TraceAPIImpl) callManyTransactions(
 		BlockNumber:      &parentNo,
 		BlockHash:        &parentHash,
 		RequireCanonical: true,
-	}, header, gasBailOut /* gasBailout */, txIndex)
+	}, header, gasBailOut /* gasBailout */, txIndex, compat)
 
 	if cmErr != nil {
 		return nil, nil, cmErr
@@ -997,6 +904,27 @@ func (api *TraceAPIImpl) callManyTransactions(
 	return traces, syscall, nil
 }
 
+// TraceBlockPage represents the optional pagination of trace_block: the first After traces of the block are skipped and
+// at most Count traces are returned
+type TraceBlockPage struct {
+	After *uint64 `json:"after"`
+	Count *uint64 `json:"count"`
+}
+
+func (p *TraceBlockPage) slice(traces ParityTraces) ParityTraces {
+	if p == nil {
+		return traces
+	}
+	from, to := uint64(0), uint64(len(traces))
+	if p.After != nil {
+		from = min(*p.After, to)
+	}
+	if p.Count != nil && *p.Count < to-from {
+		to = from + *p.Count
+	}
+	return traces[from:to]
+}
+
 // TraceFilterRequest represents the arguments for trace_filter
 type TraceFilterRequest struct {
 	FromBlock   *hexutil.Uint64   `json:"fromBlock"`
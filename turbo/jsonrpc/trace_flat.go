@@ -0,0 +1,313 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// flatCallTracerName - tracer of debug_ handlers returning OpenEthereum-style traces, served from the block traces cache
+const flatCallTracerName = "flatCallTracer"
+
+var (
+	tracesCacheHit  = metrics.GetOrCreateCounter("rpc_traces_cache_hit")
+	tracesCacheMiss = metrics.GetOrCreateCounter("rpc_traces_cache_miss")
+)
+
+// flatTracer - native tracer building the flat list of OpenEthereum-style traces of a transaction in a single pass, it
+// only follows call frames and ignores opcodes
+type flatTracer struct {
+	compat     bool // Bug for bug compatibility mode
+	traces     []*ParityTrace
+	traceAddr  []int
+	traceStack []*ParityTrace
+	precompile bool // Whether the last CaptureEnter was called with `precompile = true`
+}
+
+func (ft *flatTracer) enter(deep bool, typ vm.OpCode, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int) {
+	if precompile && deep && (value == nil || value.IsZero()) {
+		ft.precompile = true
+		return
+	}
+	if gas > 500000000 {
+		gas = 500000001 - (0x8000000000000000 - gas)
+	}
+	trace := &ParityTrace{}
+	if create {
+		trResult := &CreateTraceResult{}
+		trace.Type = CREATE
+		trResult.Address = new(libcommon.Address)
+		copy(trResult.Address[:], to.Bytes())
+		trace.Result = trResult
+	} else {
+		trace.Result = &TraceResult{}
+		trace.Type = CALL
+	}
+	if deep {
+		topTrace := ft.traceStack[len(ft.traceStack)-1]
+		traceIdx := topTrace.Subtraces
+		ft.traceAddr = append(ft.traceAddr, traceIdx)
+		topTrace.Subtraces++
+		if typ == vm.DELEGATECALL {
+			switch action := topTrace.Action.(type) {
+			case *CreateTraceAction:
+				value, _ = uint256.FromBig(action.Value.ToInt())
+			case *CallTraceAction:
+				value, _ = uint256.FromBig(action.Value.ToInt())
+			}
+		}
+		if typ == vm.STATICCALL {
+			value = uint256.NewInt(0)
+		}
+	}
+	trace.TraceAddress = make([]int, len(ft.traceAddr))
+	copy(trace.TraceAddress, ft.traceAddr)
+	if create {
+		action := CreateTraceAction{}
+		action.From = from
+		action.Gas.ToInt().SetUint64(gas)
+		action.Init = libcommon.CopyBytes(input)
+		action.Value.ToInt().Set(value.ToBig())
+		trace.Action = &action
+	} else if typ == vm.SELFDESTRUCT {
+		trace.Type = SUICIDE
+		trace.Result = nil
+		action := &SuicideTraceAction{}
+		action.Address = from
+		action.RefundAddress = to
+		action.Balance.ToInt().Set(value.ToBig())
+		trace.Action = action
+	} else {
+		action := CallTraceAction{}
+		switch typ {
+		case vm.CALL:
+			action.CallType = CALL
+		case vm.CALLCODE:
+			action.CallType = CALLCODE
+		case vm.DELEGATECALL:
+			action.CallType = DELEGATECALL
+		case vm.STATICCALL:
+			action.CallType = STATICCALL
+		}
+		action.From = from
+		action.To = to
+		action.Gas.ToInt().SetUint64(gas)
+		action.Input = libcommon.CopyBytes(input)
+		action.Value.ToInt().Set(value.ToBig())
+		trace.Action = &action
+	}
+	ft.traces = append(ft.traces, trace)
+	ft.traceStack = append(ft.traceStack, trace)
+}
+
+func (ft *flatTracer) exit(deep bool, output []byte, usedGas uint64, err error) {
+	if ft.precompile {
+		ft.precompile = false
+		return
+	}
+	ignoreError := false
+	topTrace := ft.traceStack[len(ft.traceStack)-1]
+	if ft.compat {
+		ignoreError = !deep && topTrace.Type == CREATE
+	}
+	if err != nil && !ignoreError {
+		if err == vm.ErrExecutionReverted {
+			topTrace.Error = "Reverted"
+			switch topTrace.Type {
+			case CALL:
+				topTrace.Result.(*TraceResult).GasUsed = new(hexutil.Big)
+				topTrace.Result.(*TraceResult).GasUsed.ToInt().SetUint64(usedGas)
+				topTrace.Result.(*TraceResult).Output = libcommon.CopyBytes(output)
+			case CREATE:
+				topTrace.Result.(*CreateTraceResult).GasUsed = new(hexutil.Big)
+				topTrace.Result.(*CreateTraceResult).GasUsed.ToInt().SetUint64(usedGas)
+				topTrace.Result.(*CreateTraceResult).Code = libcommon.CopyBytes(output)
+			}
+		} else {
+			topTrace.Result = nil
+			topTrace.Error = err.Error()
+		}
+	} else {
+		if len(output) > 0 {
+			switch topTrace.Type {
+			case CALL:
+				topTrace.Result.(*TraceResult).Output = libcommon.CopyBytes(output)
+			case CREATE:
+				topTrace.Result.(*CreateTraceResult).Code = libcommon.CopyBytes(output)
+			}
+		}
+		switch topTrace.Type {
+		case CALL:
+			topTrace.Result.(*TraceResult).GasUsed = new(hexutil.Big)
+			topTrace.Result.(*TraceResult).GasUsed.ToInt().SetUint64(usedGas)
+		case CREATE:
+			topTrace.Result.(*CreateTraceResult).GasUsed = new(hexutil.Big)
+			topTrace.Result.(*CreateTraceResult).GasUsed.ToInt().SetUint64(usedGas)
+		}
+	}
+	ft.traceStack = ft.traceStack[:len(ft.traceStack)-1]
+	if deep {
+		ft.traceAddr = ft.traceAddr[:len(ft.traceAddr)-1]
+	}
+}
+
+func (ft *flatTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (ft *flatTracer) CaptureTxEnd(restGas uint64) {}
+
+func (ft *flatTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	ft.enter(false /* deep */, vm.CALL, from, to, precompile, create, input, gas, value)
+}
+
+func (ft *flatTracer) CaptureEnter(typ vm.OpCode, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	ft.enter(true /* deep */, typ, from, to, precompile, create, input, gas, value)
+}
+
+func (ft *flatTracer) CaptureEnd(output []byte, usedGas uint64, err error) {
+	ft.exit(false /* deep */, output, usedGas, err)
+}
+
+func (ft *flatTracer) CaptureExit(output []byte, usedGas uint64, err error) {
+	ft.exit(true /* deep */, output, usedGas, err)
+}
+
+func (ft *flatTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, opDepth int, err error) {
+}
+
+func (ft *flatTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, opDepth int, err error) {
+}
+
+// blockTraces - flat traces of a block: traces of every transaction in block order, followed by the block rewards
+type blockTraces struct {
+	traces   ParityTraces
+	txHashes []libcommon.Hash
+	txStart  []int // index in traces of the first trace of each transaction
+	rewards  int   // index in traces of the first reward
+}
+
+// txTraces - traces of the transaction at position txIndex in the block
+func (bt *blockTraces) txTraces(txIndex int) ParityTraces {
+	end := bt.rewards
+	if txIndex+1 < len(bt.txStart) {
+		end = bt.txStart[txIndex+1]
+	}
+	return bt.traces[bt.txStart[txIndex]:end]
+}
+
+// replayTraces - traces and outputs of the transactions of the block in the format of trace_replayBlockTransactions.
+// The output of a transaction is the output of its top level call.
+func replayTraces(bt *blockTraces) []*TraceCallResult {
+	results := make([]*TraceCallResult, len(bt.txHashes))
+	for i := range bt.txHashes {
+		txTraces := bt.txTraces(i)
+		txHash := bt.txHashes[i]
+		result := &TraceCallResult{Trace: make([]*ParityTrace, len(txTraces)), TransactionHash: &txHash}
+		for j := range txTraces {
+			// cached traces are shared, block and transaction fields are dropped from a copy
+			pt := txTraces[j]
+			pt.BlockHash, pt.BlockNumber, pt.TransactionHash, pt.TransactionPosition = nil, nil, nil, nil
+			result.Trace[j] = &pt
+		}
+		if len(txTraces) > 0 {
+			switch r := txTraces[0].Result.(type) {
+			case *TraceResult:
+				result.Output = r.Output
+			case *CreateTraceResult:
+				result.Output = r.Code
+			}
+		}
+		results[i] = result
+	}
+	return results
+}
+
+type blockTracesKey struct {
+	hash   libcommon.Hash
+	compat bool
+}
+
+// flatTraceBlock - flat traces of the block, the block is re-executed once and its traces are cached for trace_ and
+// debug_ handlers. Traces with gas bail out are not cached.
+func (api *BaseAPI) flatTraceBlock(ctx context.Context, dbtx kv.Tx, block *types.Block, cfg *chain.Config, compat bool, gasBailOut bool) (*blockTraces, error) {
+	key := blockTracesKey{hash: block.Hash(), compat: compat}
+	if !gasBailOut {
+		if bt, ok := api.tracesCache.Get(key); ok {
+			tracesCacheHit.Inc()
+			return bt, nil
+		}
+		tracesCacheMiss.Inc()
+	}
+
+	signer := types.MakeSigner(cfg, block.NumberU64(), block.Time())
+	traces, syscall, err := api.callManyTransactions(ctx, dbtx, block, []string{TraceTypeTrace}, -1 /* all tx indices */, gasBailOut, signer, cfg, compat)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash, blockNumber := block.Hash(), block.NumberU64()
+	bt := &blockTraces{
+		txHashes: make([]libcommon.Hash, 0, len(traces)),
+		txStart:  make([]int, 0, len(traces)),
+	}
+	for txno, trace := range traces {
+		txpos := uint64(txno)
+		bt.txHashes = append(bt.txHashes, *trace.TransactionHash)
+		bt.txStart = append(bt.txStart, len(bt.traces))
+		for _, pt := range trace.Trace {
+			pt.BlockHash = &blockHash
+			pt.BlockNumber = &blockNumber
+			pt.TransactionHash = trace.TransactionHash
+			pt.TransactionPosition = &txpos
+			bt.traces = append(bt.traces, *pt)
+		}
+	}
+	bt.rewards = len(bt.traces)
+
+	rewards, err := api.engine().CalculateRewards(cfg, block.Header(), block.Uncles(), syscall)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rewards {
+		var tr ParityTrace
+		rewardAction := &RewardTraceAction{}
+		rewardAction.Author = r.Beneficiary
+		rewardAction.RewardType = rewardKindToString(r.Kind)
+		rewardAction.Value.ToInt().Set(r.Amount.ToBig())
+		tr.Action = rewardAction
+		tr.BlockHash = &blockHash
+		tr.BlockNumber = &blockNumber
+		tr.Type = "reward" // nolint: goconst
+		tr.TraceAddress = []int{}
+		bt.traces = append(bt.traces, tr)
+	}
+
+	if !gasBailOut {
+		api.tracesCache.Add(key, bt)
+	}
+	return bt, nil
+}
+
+// writeFlatCallTraces - writes the traces of a transaction as the result of the flatCallTracer
+func writeFlatCallTraces(traces ParityTraces, stream *jsoniter.Stream) error {
+	if len(traces) == 0 {
+		stream.WriteEmptyArray()
+		return nil
+	}
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	b, err := json.Marshal(traces)
+	if err != nil {
+		return fmt.Errorf("marshal flat call traces: %w", err)
+	}
+	_, err = stream.Write(b)
+	return err
+}
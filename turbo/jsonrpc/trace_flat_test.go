@@ -0,0 +1,85 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+func TestTraceBlockPagination(t *testing.T) {
+	m := rpcdaemontest.CreateTestSentryForTraces(t)
+	baseApi := newBaseApiForTest(m)
+	api := NewTraceAPI(baseApi, m.DB, &httpcfg.HttpCfg{})
+	ctx := context.Background()
+
+	all, err := api.Block(ctx, rpc.BlockNumber(1), new(bool), nil)
+	require.NoError(t, err)
+	require.Greater(t, len(all), 3)
+	require.Equal(t, 1, baseApi.tracesCache.Len())
+
+	var paged ParityTraces
+	for after := uint64(0); ; after += 2 {
+		count := uint64(2)
+		page, err := api.Block(ctx, rpc.BlockNumber(1), new(bool), &TraceBlockPage{After: &after, Count: &count})
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		require.LessOrEqual(t, len(page), 2)
+		paged = append(paged, page...)
+	}
+	require.Equal(t, all, paged)
+	// pages are served from the traces of the first call
+	require.Equal(t, 1, baseApi.tracesCache.Len())
+
+	after := uint64(len(all) + 10)
+	page, err := api.Block(ctx, rpc.BlockNumber(1), new(bool), &TraceBlockPage{After: &after})
+	require.NoError(t, err)
+	require.Empty(t, page)
+}
+
+func TestFlatCallTracerMatchesTraceBlock(t *testing.T) {
+	m := rpcdaemontest.CreateTestSentryForTraces(t)
+	baseApi := newBaseApiForTest(m)
+	traceApi := NewTraceAPI(baseApi, m.DB, &httpcfg.HttpCfg{})
+	debugApi := NewPrivateDebugAPI(baseApi, m.DB, 0)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+	tracer := flatCallTracerName
+	require.NoError(t, debugApi.TraceBlockByNumber(ctx, rpc.BlockNumber(1), &tracers.TraceConfig{Tracer: &tracer}, stream))
+	require.NoError(t, stream.Flush())
+	var debugResult []struct {
+		TxHash common.Hash     `json:"txHash"`
+		Result json.RawMessage `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &debugResult))
+	require.NotEmpty(t, debugResult)
+
+	for _, tx := range debugResult {
+		traces, err := traceApi.Transaction(ctx, tx.TxHash, new(bool))
+		require.NoError(t, err)
+		expected, err := json.Marshal(traces)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(tx.Result))
+
+		buf.Reset()
+		stream.Reset(&buf)
+		require.NoError(t, debugApi.TraceTransaction(ctx, tx.TxHash, &tracers.TraceConfig{Tracer: &tracer}, stream))
+		require.NoError(t, stream.Flush())
+		require.JSONEq(t, string(expected), buf.String())
+	}
+	// debug_ and trace_ handlers shared the re-execution of the block
+	require.Equal(t, 1, baseApi.tracesCache.Len())
+}
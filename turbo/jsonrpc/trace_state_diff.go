@@ -0,0 +1,164 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/order"
+	"github.com/ledgerwatch/erigon-lib/kv/rawdbv3"
+
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+)
+
+// historyStateDiffs - state diffs of the first txCount transactions of the block, read from the accounts, storage and
+// code history instead of re-executing the block. The transaction at position i is executed at txNum minTxNum+1+i,
+// its diff is the change of the state between txNum and txNum+1.
+func historyStateDiffs(tx kv.TemporalTx, blockNum uint64, txCount int) ([]map[libcommon.Address]*StateDiffAccount, error) {
+	minTxNum, err := rawdbv3.TxNums.Min(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]map[libcommon.Address]*StateDiffAccount, txCount)
+	for i := range diffs {
+		if diffs[i], err = historyStateDiff(tx, minTxNum+1+uint64(i)); err != nil {
+			return nil, err
+		}
+	}
+	return diffs, nil
+}
+
+// historyStateDiff - diff of the state before and after the transaction at txNum, in the format of CompareStates
+func historyStateDiff(tx kv.TemporalTx, txNum uint64) (map[libcommon.Address]*StateDiffAccount, error) {
+	changed := make(map[libcommon.Address][]libcommon.Hash)
+	for _, h := range []kv.History{kv.AccountsHistory, kv.CodeHistory, kv.StorageHistory} {
+		it, err := tx.HistoryRange(h, int(txNum), int(txNum+1), order.Asc, kv.Unlim)
+		if err != nil {
+			return nil, err
+		}
+		for it.HasNext() {
+			k, _, err := it.Next()
+			if err != nil {
+				it.Close()
+				return nil, err
+			}
+			addr := libcommon.BytesToAddress(k[:length.Addr])
+			if h == kv.StorageHistory {
+				changed[addr] = append(changed[addr], libcommon.BytesToHash(k[length.Addr:]))
+			} else if _, ok := changed[addr]; !ok {
+				changed[addr] = nil
+			}
+		}
+		it.Close()
+	}
+
+	sdMap := make(map[libcommon.Address]*StateDiffAccount, len(changed))
+	for addr, keys := range changed {
+		from, err := accountAsOf(tx, addr, txNum)
+		if err != nil {
+			return nil, err
+		}
+		to, err := accountAsOf(tx, addr, txNum+1)
+		if err != nil {
+			return nil, err
+		}
+		if from == nil && to == nil {
+			continue
+		}
+		accountDiff := &StateDiffAccount{Storage: make(map[libcommon.Hash]map[string]interface{})}
+		// storage of destructed accounts is not part of their diff
+		if to != nil {
+			for _, key := range keys {
+				k := append(addr.Bytes(), key.Bytes()...)
+				fromValue, _, err := tx.DomainGetAsOf(kv.StorageDomain, k, nil, txNum)
+				if err != nil {
+					return nil, err
+				}
+				toValue, _, err := tx.DomainGetAsOf(kv.StorageDomain, k, nil, txNum+1)
+				if err != nil {
+					return nil, err
+				}
+				fromHash, toHash := libcommon.BytesToHash(fromValue), libcommon.BytesToHash(toValue)
+				if fromHash == toHash {
+					continue
+				}
+				m := make(map[string]interface{})
+				if from == nil {
+					m["+"] = &toHash
+				} else {
+					m["*"] = &StateDiffStorage{From: fromHash, To: toHash}
+				}
+				accountDiff.Storage[key] = m
+			}
+		}
+		fromCode, err := codeAsOf(tx, addr, from, txNum)
+		if err != nil {
+			return nil, err
+		}
+		toCode, err := codeAsOf(tx, addr, to, txNum+1)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case from == nil:
+			accountDiff.Balance = map[string]*hexutil.Big{"+": (*hexutil.Big)(to.Balance.ToBig())}
+			accountDiff.Code = map[string]hexutility.Bytes{"+": toCode}
+			accountDiff.Nonce = map[string]hexutil.Uint64{"+": hexutil.Uint64(to.Nonce)}
+		case to == nil:
+			accountDiff.Balance = map[string]*hexutil.Big{"-": (*hexutil.Big)(from.Balance.ToBig())}
+			accountDiff.Code = map[string]hexutility.Bytes{"-": fromCode}
+			accountDiff.Nonce = map[string]hexutil.Uint64{"-": hexutil.Uint64(from.Nonce)}
+		default:
+			allEqual := len(accountDiff.Storage) == 0
+			if from.Balance.Eq(&to.Balance) {
+				accountDiff.Balance = "="
+			} else {
+				accountDiff.Balance = map[string]*StateDiffBalance{"*": {From: (*hexutil.Big)(from.Balance.ToBig()), To: (*hexutil.Big)(to.Balance.ToBig())}}
+				allEqual = false
+			}
+			if bytes.Equal(fromCode, toCode) {
+				accountDiff.Code = "="
+			} else {
+				accountDiff.Code = map[string]*StateDiffCode{"*": {From: fromCode, To: toCode}}
+				allEqual = false
+			}
+			if from.Nonce == to.Nonce {
+				accountDiff.Nonce = "="
+			} else {
+				accountDiff.Nonce = map[string]*StateDiffNonce{"*": {From: hexutil.Uint64(from.Nonce), To: hexutil.Uint64(to.Nonce)}}
+				allEqual = false
+			}
+			if allEqual {
+				continue
+			}
+		}
+		sdMap[addr] = accountDiff
+	}
+	return sdMap, nil
+}
+
+// accountAsOf - account at the start of txNum, nil if it does not exist
+func accountAsOf(tx kv.TemporalTx, addr libcommon.Address, txNum uint64) (*accounts.Account, error) {
+	enc, ok, err := tx.DomainGetAsOf(kv.AccountsDomain, addr[:], nil, txNum)
+	if err != nil || !ok || len(enc) == 0 {
+		return nil, err
+	}
+	var a accounts.Account
+	if err := accounts.DeserialiseV3(&a, enc); err != nil {
+		return nil, fmt.Errorf("account %x: %w", addr, err)
+	}
+	return &a, nil
+}
+
+func codeAsOf(tx kv.TemporalTx, addr libcommon.Address, account *accounts.Account, txNum uint64) ([]byte, error) {
+	if account == nil || account.IsEmptyCodeHash() {
+		return nil, nil
+	}
+	code, _, err := tx.DomainGetAsOf(kv.CodeDomain, addr[:], nil, txNum)
+	return code, err
+}
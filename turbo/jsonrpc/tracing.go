@@ -9,8 +9,10 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/log/v3"
 
+	"github.com/ledgerwatch/erigon-lib/chain"
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/kv"
 
 	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/core"
@@ -29,15 +31,15 @@ import (
 
 // TraceBlockByNumber implements debug_traceBlockByNumber. Returns Geth style block traces.
 func (api *PrivateDebugAPIImpl) TraceBlockByNumber(ctx context.Context, blockNum rpc.BlockNumber, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
-	return api.traceBlock(ctx, rpc.BlockNumberOrHashWithNumber(blockNum), config, stream)
+	return api.traceBlock(ctx, rpc.BlockNumberOrHashWithNumber(blockNum), config, reexecPriorityNormal, stream)
 }
 
 // TraceBlockByHash implements debug_traceBlockByHash. Returns Geth style block traces.
 func (api *PrivateDebugAPIImpl) TraceBlockByHash(ctx context.Context, hash common.Hash, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
-	return api.traceBlock(ctx, rpc.BlockNumberOrHashWithHash(hash, true), config, stream)
+	return api.traceBlock(ctx, rpc.BlockNumberOrHashWithHash(hash, true), config, reexecPriorityNormal, stream)
 }
 
-func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
+func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *tracers.TraceConfig, priority reexecPriority, stream *jsoniter.Stream) error {
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		stream.WriteNil()
@@ -82,9 +84,18 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 		stream.WriteNil()
 		return err
 	}
+	if config.Tracer != nil && *config.Tracer == flatCallTracerName {
+		return api.flatTraceBlockToStream(ctx, tx, block, chainConfig, *config.BorTraceEnabled, stream)
+	}
+	release, err := api.reexec.acquire(ctx, priority)
+	if err != nil {
+		stream.WriteNil()
+		return err
+	}
+	defer release()
 	engine := api.engine()
 
-	_, blockCtx, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, 0, api.historyV3(tx))
+	_, blockCtx, _, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, 0, api.historyV3(tx), api.executionCache)
 	if err != nil {
 		stream.WriteNil()
 		return err
@@ -162,9 +173,10 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 				blockCtx,
 				stream,
 				api.evmCallTimeout,
+				api.tracerBudget,
 			)
 		} else {
-			err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout)
+			err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout, api.tracerBudget)
 		}
 		if err == nil {
 			err = ibs.FinalizeTx(rules, state.NewNoopWriter())
@@ -194,6 +206,39 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 	return nil
 }
 
+// flatTraceBlockToStream - debug_traceBlock* result of the flatCallTracer, the block traces are shared with trace_
+// handlers
+func (api *PrivateDebugAPIImpl) flatTraceBlockToStream(ctx context.Context, tx kv.Tx, block *types.Block, chainConfig *chain.Config, borTraceEnabled bool, stream *jsoniter.Stream) error {
+	traces, err := api.flatTraceBlock(ctx, tx, block, chainConfig, false /* compat */, false /* gasBailOut */)
+	if err != nil {
+		stream.WriteNil()
+		return err
+	}
+	txCount := len(traces.txHashes)
+	if !borTraceEnabled {
+		// bor state sync transaction is the last one of the block
+		txCount = min(txCount, block.Transactions().Len())
+	}
+
+	stream.WriteArrayStart()
+	for idx := 0; idx < txCount; idx++ {
+		stream.WriteObjectStart()
+		stream.WriteObjectField("txHash")
+		stream.WriteString(traces.txHashes[idx].Hex())
+		stream.WriteMore()
+		stream.WriteObjectField("result")
+		if err := writeFlatCallTraces(traces.txTraces(idx), stream); err != nil {
+			return err
+		}
+		stream.WriteObjectEnd()
+		if idx != txCount-1 {
+			stream.WriteMore()
+		}
+	}
+	stream.WriteArrayEnd()
+	return stream.Flush()
+}
+
 // TraceTransaction implements debug_traceTransaction. Returns Geth style transaction traces.
 func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash common.Hash, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
 	tx, err := api.db.BeginRo(ctx)
@@ -273,9 +318,27 @@ func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash commo
 			return fmt.Errorf("transaction %#x not found", hash)
 		}
 	}
+	if config != nil && config.Tracer != nil && *config.Tracer == flatCallTracerName {
+		traces, err := api.flatTraceBlock(ctx, tx, block, chainConfig, false /* compat */, false /* gasBailOut */)
+		if err != nil {
+			stream.WriteNil()
+			return err
+		}
+		if txnIndex >= len(traces.txStart) {
+			stream.WriteEmptyArray()
+			return nil
+		}
+		return writeFlatCallTraces(traces.txTraces(txnIndex), stream)
+	}
+	release, err := api.reexec.acquire(ctx, reexecPriorityHigh)
+	if err != nil {
+		stream.WriteNil()
+		return err
+	}
+	defer release()
 	engine := api.engine()
 
-	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, txnIndex, api.historyV3(tx))
+	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, engine, block, chainConfig, api._blockReader, tx, txnIndex, api.historyV3(tx), api.executionCache)
 	if err != nil {
 		stream.WriteNil()
 		return err
@@ -294,10 +357,11 @@ func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash commo
 			blockCtx,
 			stream,
 			api.evmCallTimeout,
+			api.tracerBudget,
 		)
 	}
 	// Trace the transaction and return
-	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout)
+	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout, api.tracerBudget)
 }
 
 // TraceCall implements debug_traceCall. Returns Geth style call traces.
@@ -364,7 +428,7 @@ func (api *PrivateDebugAPIImpl) TraceCall(ctx context.Context, args ethapi.CallA
 	blockCtx := transactions.NewEVMBlockContext(engine, header, blockNrOrHash.RequireCanonical, dbtx, api._blockReader)
 	txCtx := core.NewEVMTxContext(msg)
 	// Trace the transaction and return
-	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout)
+	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout, api.tracerBudget)
 }
 
 func (api *PrivateDebugAPIImpl) TraceCallMany(ctx context.Context, bundles []Bundle, simulateContext StateContext, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
@@ -527,7 +591,7 @@ func (api *PrivateDebugAPIImpl) TraceCallMany(ctx context.Context, bundles []Bun
 			txCtx = core.NewEVMTxContext(msg)
 			ibs := evm.IntraBlockState().(*state.IntraBlockState)
 			ibs.SetTxContext(common.Hash{}, header.Hash(), txnIndex)
-			err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, evm.IntraBlockState(), config, chainConfig, stream, api.evmCallTimeout)
+			err = transactions.TraceTx(ctx, msg, blockCtx, txCtx, evm.IntraBlockState(), config, chainConfig, stream, api.evmCallTimeout, api.tracerBudget)
 			if err != nil {
 				stream.WriteArrayEnd()
 				stream.WriteArrayEnd()
@@ -4,21 +4,32 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ledgerwatch/log/v3"
+	"google.golang.org/protobuf/types/known/emptypb"
+
 	"github.com/ledgerwatch/erigon-lib/common/hexutil"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	proto_txpool "github.com/ledgerwatch/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/typesproto"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/txpool"
 
+	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
 )
 
 // TxPoolAPI the interface for the txpool_ RPC commands
 type TxPoolAPI interface {
 	Content(ctx context.Context) (map[string]map[string]map[string]*RPCTransaction, error)
 	ContentFrom(ctx context.Context, addr libcommon.Address) (map[string]map[string]*RPCTransaction, error)
+	Journal(ctx context.Context) ([]*RPCTransaction, error)
+	EvictJournal(ctx context.Context, hashes []libcommon.Hash) ([]libcommon.Hash, error)
+	Outcomes(ctx context.Context, hashes *[]libcommon.Hash) (*rpc.Subscription, error)
+	Analytics(ctx context.Context, sender *libcommon.Address) (*txpool.Analytics, error)
 }
 
 // TxPoolAPIImpl data structure to store things needed for net_ commands
@@ -238,3 +249,119 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 */
+
+// Journal returns journaled local transactions, oldest first. They are replayed to the pool after restart
+// and re-announced to peers until they are mined or expired.
+func (api *TxPoolAPIImpl) Journal(ctx context.Context) ([]*RPCTransaction, error) {
+	reply, err := api.pool.ListJournal(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	curHeader := rawdb.ReadCurrentHeader(tx)
+
+	res := make([]*RPCTransaction, 0, len(reply.RlpTxs))
+	for _, rlpTx := range reply.RlpTxs {
+		txn, err := types.DecodeWrappedTransaction(rlpTx)
+		if err != nil {
+			return nil, fmt.Errorf("decoding transaction from: %x: %w", rlpTx, err)
+		}
+		res = append(res, newRPCPendingTransaction(txn, curHeader, cc))
+	}
+	return res, nil
+}
+
+// EvictJournal removes transactions from the journal and returns hashes of removed ones. Transactions stay in the pool,
+// but are not replayed after restart and not re-announced anymore.
+func (api *TxPoolAPIImpl) EvictJournal(ctx context.Context, hashes []libcommon.Hash) ([]libcommon.Hash, error) {
+	req := &proto_txpool.TxHashes{Hashes: make([]*typesproto.H256, len(hashes))}
+	for i := range hashes {
+		req.Hashes[i] = gointerfaces.ConvertHashToH256(hashes[i])
+	}
+	reply, err := api.pool.EvictJournal(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	evicted := make([]libcommon.Hash, len(reply.Hashes))
+	for i := range reply.Hashes {
+		evicted[i] = gointerfaces.ConvertH256ToHash(reply.Hashes[i])
+	}
+	return evicted, nil
+}
+
+// Outcomes send a notification (txpool.TxOutcome) each time txpool accepts, rejects, replaces, drops transaction
+// or removes mined one: `txpool_subscribe("outcomes", [hashes])`. Rejections and drops carry reason, replacements
+// carry hash of the new transaction. Optional hashes limit notifications to given transactions.
+func (api *TxPoolAPIImpl) Outcomes(ctx context.Context, hashes *[]libcommon.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	req := &proto_txpool.OutcomesRequest{}
+	if hashes != nil {
+		req.Hashes = make([]*typesproto.H256, len(*hashes))
+		for i, hash := range *hashes {
+			req.Hashes[i] = gointerfaces.ConvertHashToH256(hash)
+		}
+	}
+	// stream outlives the request, it's closed on unsubscribe
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream, err := api.pool.SubscribeOutcomes(streamCtx, req)
+	if err != nil {
+		cancel()
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		select {
+		case <-rpcSub.Err():
+		case <-streamCtx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer debug.LogPanic()
+		defer cancel()
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				if streamCtx.Err() == nil {
+					log.Warn("[rpc] txpool outcomes stream was closed", "err", err)
+				}
+				return
+			}
+			if err := notifier.Notify(rpcSub.ID, txpool.TxOutcomeFromProto(reply)); err != nil {
+				log.Warn("[rpc] error while notifying subscription", "err", err)
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Analytics returns diagnostics of pool content: age distribution of transactions (in blocks), count of transactions
+// with feeCap below pending base fee and senders whose queued transactions wait for missing nonce. With sender
+// given - only transactions of this sender, to see why they are not mined.
+func (api *TxPoolAPIImpl) Analytics(ctx context.Context, sender *libcommon.Address) (*txpool.Analytics, error) {
+	req := &proto_txpool.AnalyticsRequest{}
+	if sender != nil {
+		req.Sender = gointerfaces.ConvertAddressToH160(*sender)
+	}
+	reply, err := api.pool.Analytics(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return txpool.AnalyticsFromReply(reply), nil
+}
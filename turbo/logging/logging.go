@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/cobra"
@@ -12,6 +14,8 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/ledgerwatch/erigon-lib/common/metrics"
+
+	"github.com/ledgerwatch/erigon/turbo/reload"
 )
 
 // Determine the log dir path based on the given urfave context
@@ -79,10 +83,46 @@ func SetupLoggerCtx(filePrefix string, ctx *cli.Context,
 		logger = log.New()
 	}
 
-	initSeparatedLogging(logger, filePrefix, dirPath, consoleLevel, dirLevel, consoleJson, dirJson)
+	levels := initSeparatedLogging(logger, filePrefix, dirPath, consoleLevel, dirLevel, consoleJson, dirJson)
+	if rootHandler {
+		rootLevels.Store(levels)
+		registerReloadOnce.Do(registerReload)
+	}
 	return logger
 }
 
+// logLevels - levels of console and dir handlers, changed at runtime by config reload
+type logLevels struct {
+	console, dir atomic.Int32
+}
+
+func lvlFilterHandler(lvl *atomic.Int32, h log.Handler) log.Handler {
+	return log.FilterHandler(func(r *log.Record) bool { return r.Lvl <= log.Lvl(lvl.Load()) }, h)
+}
+
+var (
+	rootLevels         atomic.Pointer[logLevels]
+	registerReloadOnce sync.Once
+)
+
+func registerReload() {
+	setLevel := func(lvl func(*logLevels) *atomic.Int32) reload.Func {
+		return func(value string) error {
+			l, err := tryGetLogLevel(value)
+			if err != nil {
+				return err
+			}
+			lvl(rootLevels.Load()).Store(int32(l))
+			log.Info("[logging] level changed", "level", l)
+			return nil
+		}
+	}
+	console := func(l *logLevels) *atomic.Int32 { return &l.console }
+	reload.Register(LogVerbosityFlag.Name, setLevel(console))
+	reload.Register(LogConsoleVerbosityFlag.Name, setLevel(console))
+	reload.Register(LogDirVerbosityFlag.Name, setLevel(func(l *logLevels) *atomic.Int32 { return &l.dir }))
+}
+
 // SetupLoggerCmd perform the logging for a cobra command, and sets it to the root logger
 // This is the function which is NOT used by Erigon itself, but instead by some cobra-based commands,
 // for example, rpcdaemon or integration.
@@ -193,26 +233,30 @@ func initSeparatedLogging(
 	consoleLevel log.Lvl,
 	dirLevel log.Lvl,
 	consoleJson bool,
-	dirJson bool) {
+	dirJson bool) *logLevels {
+
+	levels := &logLevels{}
+	levels.console.Store(int32(consoleLevel))
+	levels.dir.Store(int32(dirLevel))
 
 	var consoleHandler log.Handler
 
 	if consoleJson {
-		consoleHandler = log.LvlFilterHandler(consoleLevel, log.StreamHandler(os.Stderr, log.JsonFormat()))
+		consoleHandler = lvlFilterHandler(&levels.console, log.StreamHandler(os.Stderr, log.JsonFormat()))
 	} else {
-		consoleHandler = log.LvlFilterHandler(consoleLevel, log.StderrHandler)
+		consoleHandler = lvlFilterHandler(&levels.console, log.StderrHandler)
 	}
 	logger.SetHandler(consoleHandler)
 
 	if len(dirPath) == 0 {
 		logger.Info("console logging only")
-		return
+		return levels
 	}
 
 	err := os.MkdirAll(dirPath, 0764)
 	if err != nil {
 		logger.Warn("failed to create log dir, console logging only")
-		return
+		return levels
 	}
 
 	dirFormat := log.TerminalFormatNoColor()
@@ -228,9 +272,10 @@ func initSeparatedLogging(
 	}
 	userLog := log.StreamHandler(lumberjack, dirFormat)
 
-	mux := log.MultiHandler(consoleHandler, log.LvlFilterHandler(dirLevel, userLog))
+	mux := log.MultiHandler(consoleHandler, lvlFilterHandler(&levels.dir, userLog))
 	logger.SetHandler(mux)
 	logger.Info("logging to file system", "log dir", dirPath, "file prefix", filePrefix, "log level", dirLevel, "json", dirJson)
+	return levels
 }
 
 func tryGetLogLevel(s string) (log.Lvl, error) {
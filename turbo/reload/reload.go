@@ -0,0 +1,90 @@
+// Package reload - settings which can be changed without restart of the node: components register appliers of
+// flags they own, config file reload (SIGHUP or admin_reloadConfig) applies changed values of registered flags.
+package reload
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Func - applies new value of flag to running component. Value is already validated by flag parsing.
+type Func func(value string) error
+
+var (
+	lock     sync.RWMutex
+	appliers = map[string][]Func{}
+	reloader func() (*Result, error)
+)
+
+// Register - makes flag reloadable. Several components may register same flag (e.g. rpc servers)
+func Register(flag string, f Func) {
+	lock.Lock()
+	defer lock.Unlock()
+	appliers[flag] = append(appliers[flag], f)
+}
+
+// Reloadable - flag has appliers in this process
+func Reloadable(flag string) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return len(appliers[flag]) > 0
+}
+
+// Flags - sorted names of reloadable flags
+func Flags() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	res := make([]string, 0, len(appliers))
+	for flag := range appliers {
+		res = append(res, flag)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Apply - applies value of flag by all its appliers, errors are joined
+func Apply(flag, value string) error {
+	lock.RLock()
+	fs := appliers[flag]
+	lock.RUnlock()
+	if len(fs) == 0 {
+		return fmt.Errorf("flag %s is not reloadable", flag)
+	}
+	var errs []error
+	for _, f := range fs {
+		if err := f(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Result - changes found by config reload
+type Result struct {
+	Applied        []string          `json:"applied"`        // changed and applied to running node
+	RequireRestart []string          `json:"requireRestart"` // changed, but not reloadable
+	Overridden     []string          `json:"overridden"`     // changed, but set by command line, which has priority
+	Failed         map[string]string `json:"failed,omitempty"`
+}
+
+var ErrNoConfigFile = errors.New("node is not started with --config file, or reload is not available in this process (use SIGHUP)")
+
+// SetReloader - installs reload of config file, node started with --config does it
+func SetReloader(f func() (*Result, error)) {
+	lock.Lock()
+	defer lock.Unlock()
+	reloader = f
+}
+
+// ReloadConfig - re-reads config file of the node and applies changed reloadable settings
+func ReloadConfig() (*Result, error) {
+	lock.RLock()
+	f := reloader
+	lock.RUnlock()
+	if f == nil {
+		return nil, ErrNoConfigFile
+	}
+	return f()
+}
@@ -38,6 +38,7 @@ type Filters struct {
 	logsSubs         *LogsFilterAggregator
 	logsRequestor    atomic.Value
 	onNewSnapshot    func()
+	stateChanges     StateChangesClient
 
 	storeMu            sync.Mutex
 	logsStores         *SyncMap[LogsSubID, []*types.Log]
@@ -28,5 +28,9 @@ type ApiBackend interface {
 	NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error)
 	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
 	AddPeer(ctx context.Context, url *remote.AddPeerRequest) (*remote.AddPeerReply, error)
+	RemovePeer(ctx context.Context, url string) (bool, error)
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+	RemoveTrustedPeer(ctx context.Context, url string) (bool, error)
+	ReloadPeerFiles(ctx context.Context) (bool, error)
 	PendingBlock(ctx context.Context) (*types.Block, error)
 }
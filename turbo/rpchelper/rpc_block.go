@@ -8,6 +8,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	borfinality "github.com/ledgerwatch/erigon/polygon/bor/finality"
 	"github.com/ledgerwatch/erigon/rpc"
 )
 
@@ -33,7 +34,12 @@ func GetLatestBlockNumber(tx kv.Tx) (uint64, error) {
 	return blockNum, nil
 }
 
+// GetFinalizedBlockNumber - bor: block finalized by milestones and checkpoints, otherwise: finalized block of last forkchoice
 func GetFinalizedBlockNumber(tx kv.Tx) (uint64, error) {
+	if number, _, ok := borfinality.FinalizedBlock(tx); ok {
+		return number, nil
+	}
+
 	forkchoiceFinalizedHash := rawdb.ReadForkchoiceFinalized(tx)
 	if forkchoiceFinalizedHash != (libcommon.Hash{}) {
 		forkchoiceFinalizedNum := rawdb.ReadHeaderNumber(tx, forkchoiceFinalizedHash)
@@ -53,6 +59,10 @@ func GetSafeBlockNumber(tx kv.Tx) (uint64, error) {
 			return *forkchoiceSafeNum, nil
 		}
 	}
+	// bor has no safe block distinct from finalized one
+	if number, _, ok := borfinality.FinalizedBlock(tx); ok {
+		return number, nil
+	}
 	return 0, UnknownBlockError
 }
 
@@ -0,0 +1,75 @@
+package rpchelper
+
+import (
+	"context"
+	"errors"
+
+	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
+	"google.golang.org/grpc"
+)
+
+var ErrStateChangesUnsupported = errors.New("state changes stream is not available")
+
+type StateChangesClient interface {
+	StateChanges(ctx context.Context, in *remote.StateChangeRequest, opts ...grpc.CallOption) (remote.KV_StateChangesClient, error)
+}
+
+// SetStateChangesClient - enables SubscribeStateChanges. Separated from New because most of Filters users don't need it.
+func (ff *Filters) SetStateChangesClient(client StateChangesClient) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	ff.stateChanges = client
+}
+
+// SubscribeStateChanges - every subscriber has own stream: it allows replay of recent blocks from `fromBlock`
+// (see remotedbserver.StateChangesReplayLimit). Returns error if `fromBlock` can't be replayed.
+// Channel closed when ctx is done or stream is broken.
+func (ff *Filters) SubscribeStateChanges(ctx context.Context, fromBlock *uint64, size int) (<-chan *remote.StateChangeBatch, error) {
+	ff.mu.RLock()
+	client := ff.stateChanges
+	ff.mu.RUnlock()
+	if client == nil {
+		return nil, ErrStateChangesUnsupported
+	}
+
+	stream, err := client.StateChanges(ctx, &remote.StateChangeRequest{WithStorage: true, WithTransactions: false, FromBlock: fromBlock}, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	var first *remote.StateChangeBatch
+	if fromBlock != nil {
+		// server starts replay by first message (or empty batch if nothing to replay) - or fails the stream
+		if first, err = stream.Recv(); err != nil {
+			return nil, err
+		}
+	}
+	ch := make(chan *remote.StateChangeBatch, size)
+	go func() {
+		defer close(ch)
+		if first != nil && len(first.ChangeBatch) > 0 {
+			select {
+			case ch <- first:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for {
+			batch, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					ff.logger.Debug("rpc filters: state changes stream closed", "err", err)
+				}
+				return
+			}
+			if batch == nil {
+				return
+			}
+			select {
+			case ch <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,48 @@
+package rpchelper_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/direct"
+	remote "github.com/ledgerwatch/erigon-lib/gointerfaces/remoteproto"
+	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
+
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+func TestSubscribeStateChangesReplay(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.New()
+	kvServer := remotedbserver.NewKvServer(ctx, nil, nil, nil, nil, logger)
+	f := rpchelper.New(ctx, nil, nil, nil, func() {}, logger)
+	f.SetStateChangesClient(direct.NewStateDiffClientDirect(kvServer))
+
+	// nothing to replay from: error must reach subscriber, not only close the channel
+	from := uint64(1)
+	_, err := f.SubscribeStateChanges(ctx, &from, 16)
+	require.ErrorContains(t, err, "not available for replay")
+
+	for i := uint64(1); i <= remotedbserver.StateChangesReplayLimit+2; i++ {
+		kvServer.SendStateChanges(ctx, &remote.StateChangeBatch{StateVersionId: i, ChangeBatch: []*remote.StateChange{{BlockHeight: i}}})
+	}
+	_, err = f.SubscribeStateChanges(ctx, &from, 16)
+	require.ErrorContains(t, err, "out of replay window")
+
+	from = remotedbserver.StateChangesReplayLimit + 2
+	ch, err := f.SubscribeStateChanges(ctx, &from, 16)
+	require.NoError(t, err)
+	require.Equal(t, from, (<-ch).StateVersionId)
+
+	// future block: nothing to replay yet, but it's served by live stream
+	from = remotedbserver.StateChangesReplayLimit + 3
+	ch, err = f.SubscribeStateChanges(ctx, &from, 16)
+	require.NoError(t, err)
+	kvServer.SendStateChanges(ctx, &remote.StateChangeBatch{StateVersionId: from, ChangeBatch: []*remote.StateChange{{BlockHeight: from}}})
+	require.Equal(t, from, (<-ch).StateVersionId)
+}
@@ -24,6 +24,9 @@ type BlockReader interface {
 	CurrentBlock(db kv.Tx) (*types.Block, error)
 	BlockWithSenders(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (block *types.Block, senders []common.Address, err error)
 	IterateFrozenBodies(f func(blockNum, baseTxNum, txAmount uint64) error) error
+	// IterateFrozenRawBodies - bodies of frozen blocks of [from, to) in block order, transactions in binary encoding.
+	// Stops at first block not in files. Files are locked during iteration: `f` must not use the reader
+	IterateFrozenRawBodies(from, to uint64, f func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error) error
 }
 
 type HeaderReader interface {
@@ -80,6 +83,11 @@ type TxnReader interface {
 	FirstTxnNumNotInSnapshots() uint64
 }
 
+type ReceiptsReader interface {
+	// ReceiptsFromSnapshots - returns nil if receipts of block are not in snapshots
+	ReceiptsFromSnapshots(ctx context.Context, block *types.Block, senders []common.Address) (types.Receipts, error)
+}
+
 type HeaderAndCanonicalReader interface {
 	HeaderReader
 	CanonicalReader
@@ -100,6 +108,7 @@ type FullBlockReader interface {
 	BorCheckpointReader
 	TxnReader
 	CanonicalReader
+	ReceiptsReader
 
 	FrozenBlocks() uint64
 	FrozenBorBlocks() uint64
@@ -115,6 +124,7 @@ type FullBlockReader interface {
 
 type BlockSnapshots interface {
 	LogStat(label string)
+	Files() []string
 	ReopenFolder() error
 	SegmentsMax() uint64
 	SegmentsMin() uint64
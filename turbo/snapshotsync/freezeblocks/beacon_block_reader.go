@@ -37,6 +37,9 @@ type BeaconSnapshotReader interface {
 	ReadBlockByRoot(ctx context.Context, tx kv.Tx, blockRoot libcommon.Hash) (*cltypes.SignedBeaconBlock, error)
 	ReadHeaderByRoot(ctx context.Context, tx kv.Tx, blockRoot libcommon.Hash) (*cltypes.SignedBeaconBlockHeader, error)
 	ReadBlindedBlockBySlot(ctx context.Context, tx kv.Tx, slot uint64) (*cltypes.SignedBlindedBeaconBlock, error)
+	// ReadBeaconStateSSZ reads the state at the given slot from beacon states snapshots.
+	// If the state is not frozen, it returns nil.
+	ReadBeaconStateSSZ(slot uint64) ([]byte, clparams.StateVersion, error)
 
 	FrozenSlots() uint64
 }
@@ -56,6 +59,10 @@ func (r *beaconSnapshotReader) FrozenSlots() uint64 {
 	return r.sn.BlocksAvailable()
 }
 
+func (r *beaconSnapshotReader) ReadBeaconStateSSZ(slot uint64) ([]byte, clparams.StateVersion, error) {
+	return r.sn.ReadBeaconStateSSZ(slot)
+}
+
 func (r *beaconSnapshotReader) ReadBlockBySlot(ctx context.Context, tx kv.Tx, slot uint64) (*cltypes.SignedBeaconBlock, error) {
 	if r.eth1Getter == nil {
 		return nil, nil
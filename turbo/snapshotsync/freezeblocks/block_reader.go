@@ -111,6 +111,9 @@ func (r *RemoteBlockReader) FrozenBlocks() uint64                  { panic("not
 func (r *RemoteBlockReader) FrozenBorBlocks() uint64               { panic("not supported") }
 func (r *RemoteBlockReader) FrozenFiles() (list []string)          { panic("not supported") }
 func (r *RemoteBlockReader) FreezingCfg() ethconfig.BlocksFreezing { panic("not supported") }
+func (r *RemoteBlockReader) ReceiptsFromSnapshots(ctx context.Context, block *types.Block, senders []common.Address) (types.Receipts, error) {
+	return nil, nil
+}
 
 func (r *RemoteBlockReader) HeaderByHash(ctx context.Context, tx kv.Getter, hash common.Hash) (*types.Header, error) {
 	blockNum := rawdb.ReadHeaderNumber(tx, hash)
@@ -195,6 +198,10 @@ func (r *RemoteBlockReader) IterateFrozenBodies(_ func(blockNum uint64, baseTxNu
 	panic("not implemented")
 }
 
+func (r *RemoteBlockReader) IterateFrozenRawBodies(_, _ uint64, _ func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error) error {
+	panic("not implemented")
+}
+
 func (r *RemoteBlockReader) Header(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (*types.Header, error) {
 	block, _, err := r.BlockWithSenders(ctx, tx, hash, blockHeight)
 	if err != nil {
@@ -328,6 +335,9 @@ func (r *BlockReader) BorSnapshots() services.BlockSnapshots {
 func (r *BlockReader) AllTypes() []snaptype.Type {
 	var types []snaptype.Type
 	types = append(types, r.sn.Types()...)
+	if r.sn.receipts != nil {
+		types = append(types, r.sn.receipts.Types()...)
+	}
 	if r.borSn != nil {
 		types = append(types, r.borSn.Types()...)
 	}
@@ -351,6 +361,13 @@ func (r *BlockReader) FrozenFiles() []string {
 }
 func (r *BlockReader) FreezingCfg() ethconfig.BlocksFreezing { return r.sn.Cfg() }
 
+func (r *BlockReader) ReceiptsFromSnapshots(ctx context.Context, block *types.Block, senders []common.Address) (types.Receipts, error) {
+	if r.sn == nil {
+		return nil, nil
+	}
+	return r.sn.receipts.ReadReceipts(block, senders)
+}
+
 func (r *BlockReader) HeadersRange(ctx context.Context, walker func(header *types.Header) error) error {
 	return ForEachHeader(ctx, r.sn, walker)
 }
@@ -1070,6 +1087,78 @@ func (r *BlockReader) IterateFrozenBodies(f func(blockNum, baseTxNum, txAmount u
 	return nil
 }
 
+// IterateFrozenRawBodies - reads bodies and transactions files sequentially from `from`, without decoding transactions
+func (r *BlockReader) IterateFrozenRawBodies(from, to uint64, f func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error) error {
+	to = min(to, r.sn.BlocksAvailable()+1)
+	view := r.sn.View()
+	defer view.Close()
+
+	var buf []byte
+	var b types.BodyForStorage
+	for blockNum := from; blockNum < to; {
+		bodiesSeg, ok := view.BodiesSegment(blockNum)
+		if !ok {
+			return nil
+		}
+		txsSeg, ok := view.TxsSegment(blockNum)
+		if !ok {
+			return nil
+		}
+		bodiesIdx, txsIdx := bodiesSeg.Index(), txsSeg.Index(coresnaptype.Indexes.TxnHash)
+		if bodiesIdx == nil || txsIdx == nil {
+			return nil
+		}
+
+		bodies, txs := bodiesSeg.MakeGetter(), txsSeg.MakeGetter()
+		bodies.Reset(bodiesIdx.OrdinalLookup(blockNum - bodiesIdx.BaseDataID()))
+		for segTo, first := min(to, bodiesSeg.to), true; blockNum < segTo; blockNum, first = blockNum+1, false {
+			if !bodies.HasNext() {
+				return nil
+			}
+			buf, _ = bodies.Next(buf[:0])
+			if err := rlp.DecodeBytes(buf, &b); err != nil {
+				return err
+			}
+			if first {
+				txs.Reset(txsIdx.OrdinalLookup(b.BaseTxId - txsIdx.BaseDataID()))
+			}
+
+			blockTxs := make([][]byte, 0, max(b.TxAmount, 2)-2)
+			for i := uint32(0); i < b.TxAmount; i++ {
+				if !txs.HasNext() {
+					return fmt.Errorf("IterateFrozenRawBodies: block %d has %d txs, file %s ended", blockNum, b.TxAmount, txsSeg.FilePath())
+				}
+				if i == 0 || i == b.TxAmount-1 { // empty txs in the beginning and end of block
+					txs.Skip()
+					continue
+				}
+				word, _ := txs.Next(nil)
+				if len(word) < 1+20 {
+					return fmt.Errorf("segment %s has too short record: len(buf)=%d < 21", txsSeg.FilePath(), len(word))
+				}
+				txn, err := binaryTxn(word[1+20:])
+				if err != nil {
+					return err
+				}
+				blockTxs = append(blockTxs, txn)
+			}
+			if err := f(blockNum, blockTxs, b.Withdrawals); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// binaryTxn - transaction in binary encoding: typed transactions may be stored as RLP string of it
+func binaryTxn(txn []byte) ([]byte, error) {
+	if !types.TypedTransactionMarshalledAsRlpString(txn) {
+		return txn, nil
+	}
+	_, content, _, err := rlp.Split(txn)
+	return content, err
+}
+
 func (r *BlockReader) IntegrityTxnID(failFast bool) error {
 	defer log.Info("[integrity] IntegrityTxnID done")
 	view := r.sn.View()
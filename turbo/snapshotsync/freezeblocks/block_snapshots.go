@@ -31,6 +31,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
 	dir2 "github.com/ledgerwatch/erigon-lib/common/dir"
+	"github.com/ledgerwatch/erigon-lib/common/disk"
 	"github.com/ledgerwatch/erigon-lib/common/hexutility"
 	"github.com/ledgerwatch/erigon-lib/diagnostics"
 	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
@@ -271,6 +272,11 @@ type RoSnapshots struct {
 
 	// allows for pruning segments - this is the min availible segment
 	segmentsMin atomic.Uint64
+	// allowGaps - files may start not from genesis and may have gaps (optional types)
+	allowGaps bool
+
+	// receipts - optional snapshots, opened together with blocks if exist. nil for non-block snapshots
+	receipts *ReceiptsRoSnapshots
 }
 
 // NewRoSnapshots - opens all snapshots. But to simplify everything:
@@ -279,7 +285,9 @@ type RoSnapshots struct {
 //   - gaps are not allowed
 //   - segment have [from:to) semantic
 func NewRoSnapshots(cfg ethconfig.BlocksFreezing, snapDir string, segmentsMin uint64, logger log.Logger) *RoSnapshots {
-	return newRoSnapshots(cfg, snapDir, coresnaptype.BlockSnapshotTypes, segmentsMin, logger)
+	s := newRoSnapshots(cfg, snapDir, coresnaptype.BlockSnapshotTypes, segmentsMin, logger)
+	s.receipts = NewReceiptsRoSnapshots(cfg, snapDir, logger)
+	return s
 }
 
 func newRoSnapshots(cfg ethconfig.BlocksFreezing, snapDir string, types []snaptype.Type, segmentsMin uint64, logger log.Logger) *RoSnapshots {
@@ -290,6 +298,7 @@ func newRoSnapshots(cfg ethconfig.BlocksFreezing, snapDir string, types []snapty
 
 	s := &RoSnapshots{dir: snapDir, cfg: cfg, segments: segs, logger: logger, types: types}
 	s.segmentsMin.Store(segmentsMin)
+	disk.Tag(snapDir, disk.Snapshots)
 
 	return s
 }
@@ -447,11 +456,17 @@ func (s *RoSnapshots) Files() (list []string) {
 		}
 		return true
 	})
+	if s.receipts != nil {
+		list = append(list, s.receipts.Files()...)
+	}
 
 	slices.Sort(list)
 	return list
 }
 
+// Receipts - optional receipts snapshots. nil if `s` is not blocks snapshots
+func (s *RoSnapshots) Receipts() *ReceiptsRoSnapshots { return s.receipts }
+
 func (s *RoSnapshots) OpenFiles() (list []string) {
 	s.segments.Scan(func(segtype snaptype.Enum, value *segments) bool {
 		value.lock.RLock()
@@ -471,6 +486,12 @@ func (s *RoSnapshots) ReopenList(fileNames []string, optimistic bool) error {
 	if err := s.rebuildSegments(fileNames, true, optimistic); err != nil {
 		return err
 	}
+	if s.receipts != nil {
+		// receipts are optional: can't make blocks unavailable
+		if err := s.receipts.ReopenList(fileNames, true); err != nil {
+			s.logger.Warn("[snapshots] open receipts", "err", err)
+		}
+	}
 	return nil
 }
 
@@ -594,24 +615,38 @@ func (s *RoSnapshots) Ranges() []Range {
 func (s *RoSnapshots) OptimisticalyReopenFolder()           { _ = s.ReopenFolder() }
 func (s *RoSnapshots) OptimisticalyReopenWithDB(db kv.RoDB) { _ = s.ReopenWithDB(db) }
 func (s *RoSnapshots) ReopenFolder() error {
-	if err := s.ReopenSegments(s.Types(), false); err != nil {
+	if err := s.ReopenSegments(s.Types(), s.allowGaps); err != nil {
 		return fmt.Errorf("ReopenSegments: %w", err)
 	}
 	return nil
 }
 
 func (s *RoSnapshots) ReopenSegments(types []snaptype.Type, allowGaps bool) error {
-	files, _, err := typedSegments(s.dir, s.segmentsMin.Load(), types, allowGaps)
-
+	list, err := s.segmentsList(types, allowGaps)
 	if err != nil {
 		return err
 	}
+	if s.receipts != nil {
+		receiptsList, err := s.receipts.segmentsList(s.receipts.Types(), true)
+		if err != nil {
+			return err
+		}
+		list = append(list, receiptsList...)
+	}
+	return s.ReopenList(list, false)
+}
+
+func (s *RoSnapshots) segmentsList(types []snaptype.Type, allowGaps bool) ([]string, error) {
+	files, _, err := typedSegments(s.dir, s.segmentsMin.Load(), types, allowGaps, s.cfg.ExpiryBlock)
+	if err != nil {
+		return nil, err
+	}
 	list := make([]string, 0, len(files))
 	for _, f := range files {
 		_, fName := filepath.Split(f.Path)
 		list = append(list, fName)
 	}
-	return s.ReopenList(list, false)
+	return list, nil
 }
 
 func (s *RoSnapshots) ReopenWithDB(db kv.RoDB) error {
@@ -631,6 +666,9 @@ func (s *RoSnapshots) Close() {
 	if s == nil {
 		return
 	}
+	if s.receipts != nil {
+		s.receipts.Close()
+	}
 	s.lockSegments()
 	defer s.unlockSegments()
 	s.closeWhatNotInList(nil)
@@ -924,13 +962,29 @@ func noGaps(in []snaptype.FileInfo, from uint64) (out []snaptype.FileInfo, missi
 	return out, missingSnapshots
 }
 
-func typeOfSegmentsMustExist(dir string, in []snaptype.FileInfo, types []snaptype.Type) (res []snaptype.FileInfo) {
+// noGapsAfterExpiry - files before expiryBlock may have gaps, files starting from the one which covers it must have no gaps
+func noGapsAfterExpiry(in []snaptype.FileInfo, expiryBlock uint64) (out []snaptype.FileInfo, missingSnapshots []Range) {
+	for i, f := range in {
+		if f.To <= expiryBlock {
+			out = append(out, f)
+			continue
+		}
+		rest, missingSnapshots := noGaps(in[i:], cmp.Min(f.From, expiryBlock))
+		return append(out, rest...), missingSnapshots
+	}
+	return out, nil
+}
+
+func typeOfSegmentsMustExist(dir string, in []snaptype.FileInfo, types []snaptype.Type, expiryBlock uint64) (res []snaptype.FileInfo) {
 MainLoop:
 	for _, f := range in {
 		if f.From == f.To {
 			continue
 		}
 		for _, t := range types {
+			if f.To <= expiryBlock && isExpirable(t) {
+				continue
+			}
 			p := filepath.Join(dir, snaptype.SegmentFileName(f.Version, f.From, f.To, t.Enum()))
 			if !dir2.FileExist(p) {
 				continue MainLoop
@@ -941,6 +995,12 @@ MainLoop:
 	return res
 }
 
+// isExpirable - files of type are optional for blocks before history expiry block (EIP-4444): bodies without transactions
+// are enough to read headers and number transactions of all blocks
+func isExpirable(t snaptype.Type) bool {
+	return t.Enum() == coresnaptype.Enums.Transactions
+}
+
 // noOverlaps - keep largest ranges and avoid overlap
 func noOverlaps(in []snaptype.FileInfo) (res []snaptype.FileInfo) {
 	for i := range in {
@@ -1020,11 +1080,11 @@ func SegmentsCaplin(dir string, minBlock uint64) (res []snaptype.FileInfo, missi
 		var l, lSidecars []snaptype.FileInfo
 		var m []Range
 		for _, f := range list {
-			if f.Type.Enum() != snaptype.CaplinEnums.BeaconBlocks && f.Type.Enum() != snaptype.CaplinEnums.BlobSidecars {
+			if !snaptype.IsCaplinType(f.Type.Enum()) {
 				continue
 			}
-			if f.Type.Enum() == snaptype.CaplinEnums.BlobSidecars {
-				lSidecars = append(lSidecars, f) // blobs are an exception
+			if f.Type.Enum() == snaptype.CaplinEnums.BlobSidecars || f.Type.Enum() == snaptype.CaplinEnums.BeaconStates {
+				lSidecars = append(lSidecars, f) // blobs and states are an exception: they don't start from genesis
 				continue
 			}
 			l = append(l, f)
@@ -1042,12 +1102,13 @@ func SegmentsCaplin(dir string, minBlock uint64) (res []snaptype.FileInfo, missi
 }
 
 func Segments(dir string, minBlock uint64) (res []snaptype.FileInfo, missingSnapshots []Range, err error) {
-	return typedSegments(dir, minBlock, coresnaptype.BlockSnapshotTypes, false)
+	return typedSegments(dir, minBlock, coresnaptype.BlockSnapshotTypes, false, 0)
 }
 
-func typedSegments(dir string, minBlock uint64, types []snaptype.Type, allowGaps bool) (res []snaptype.FileInfo, missingSnapshots []Range, err error) {
+// typedSegments - with expiryBlock > 0, files of expirable types may be missing before it: they are listed with gaps
+func typedSegments(dir string, minBlock uint64, types []snaptype.Type, allowGaps bool, expiryBlock uint64) (res []snaptype.FileInfo, missingSnapshots []Range, err error) {
 	segmentsTypeCheck := func(dir string, in []snaptype.FileInfo) (res []snaptype.FileInfo) {
-		return typeOfSegmentsMustExist(dir, in, types)
+		return typeOfSegmentsMustExist(dir, in, types, expiryBlock)
 	}
 
 	list, err := snaptype.Segments(dir)
@@ -1069,6 +1130,8 @@ func typedSegments(dir string, minBlock uint64, types []snaptype.Type, allowGaps
 
 			if allowGaps {
 				l = noOverlaps(segmentsTypeCheck(dir, l))
+			} else if expiryBlock > 0 && isExpirable(segType) {
+				l, m = noGapsAfterExpiry(noOverlaps(segmentsTypeCheck(dir, l)), expiryBlock)
 			} else {
 				l, m = noGaps(noOverlaps(segmentsTypeCheck(dir, l)), minBlock)
 			}
@@ -1354,6 +1417,11 @@ func (br *BlockRetire) RetireBlocksInBackground(ctx context.Context, minBlockNum
 		br.maxScheduledBlock.Store(maxBlockNum)
 	}
 
+	if err := disk.CheckFreeSpace(disk.FreeSpaceLow); err != nil {
+		br.logger.Debug("[snapshots] retire blocks paused", "err", err)
+		return
+	}
+
 	if !br.working.CompareAndSwap(false, true) {
 		return
 	}
@@ -1390,7 +1458,7 @@ func (br *BlockRetire) RetireBlocks(ctx context.Context, minBlockNum uint64, max
 
 	var err error
 	for {
-		var ok, okBor bool
+		var ok, okBor, okReceipts bool
 
 		minBlockNum = cmp.Max(br.blockReader.FrozenBlocks(), minBlockNum)
 		maxBlockNum = br.maxScheduledBlock.Load()
@@ -1408,6 +1476,12 @@ func (br *BlockRetire) RetireBlocks(ctx context.Context, minBlockNum uint64, max
 			return err
 		}
 
+		// "receipts snaps" are behind "block snaps": produced from frozen and executed blocks
+		okReceipts, err = br.retireReceipts(ctx, lvl, seedNewSnapshots, onDeleteSnapshots)
+		if err != nil {
+			return err
+		}
+
 		if includeBor {
 			minBorBlockNum := cmp.Max(br.blockReader.FrozenBorBlocks(), minBlockNum)
 			okBor, err = br.retireBorBlocks(ctx, minBorBlockNum, maxBlockNum, lvl, seedNewSnapshots, onDeleteSnapshots)
@@ -1416,7 +1490,7 @@ func (br *BlockRetire) RetireBlocks(ctx context.Context, minBlockNum uint64, max
 			}
 		}
 
-		if !(ok || okBor) {
+		if !(ok || okBor || okReceipts) {
 			break
 		}
 	}
@@ -1434,6 +1508,12 @@ func (br *BlockRetire) BuildMissedIndicesIfNeed(ctx context.Context, logPrefix s
 		}
 	}
 
+	if rs := br.snapshots().Receipts(); rs != nil && br.blockReader.FreezingCfg().Receipts {
+		if err := rs.buildMissedIndicesIfNeed(ctx, logPrefix, notifier, br); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -2121,6 +2201,8 @@ func (v *View) TxsSegment(blockNum uint64) (*Segment, bool) {
 	return v.Segment(coresnaptype.Transactions, blockNum)
 }
 
+// RemoveIncompatibleIndices - removes indices of old format and corrupted indices (can't be opened).
+// Only removed files are re-built by BuildMissedIndices - not all indices of the same type.
 func RemoveIncompatibleIndices(dirs datadir.Dirs) error {
 	l, err := dir2.ListFiles(dirs.Snap, ".idx")
 	if err != nil {
@@ -2137,20 +2219,61 @@ func RemoveIncompatibleIndices(dirs datadir.Dirs) error {
 	l = append(append(l, l1...), l2...)
 
 	for _, fPath := range l {
-		index, err := recsplit.OpenIndex(fPath)
-		if err != nil {
-			if errors.Is(err, recsplit.IncompatibleErr) {
-				_, fName := filepath.Split(fPath)
-				if err = os.Remove(fPath); err != nil {
-					log.Warn("Removing incompatible index", "file", fName, "err", err)
-				} else {
-					log.Info("Removing incompatible index", "file", fName)
+		err := checkIndex(fPath)
+		if err == nil {
+			continue
+		}
+		_, fName := filepath.Split(fPath)
+		reason := "incompatible"
+		if !errors.Is(err, recsplit.IncompatibleErr) {
+			reason = "corrupted"
+		}
+		if err = os.Remove(fPath); err != nil {
+			log.Warn("Removing "+reason+" index", "file", fName, "err", err)
+		} else {
+			log.Info("Removing "+reason+" index", "file", fName)
+		}
+	}
+	return nil
+}
+
+// checkIndex - recsplit.OpenIndex may panic on truncated file
+func checkIndex(fPath string) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("open index: %v", rec)
+		}
+	}()
+	index, err := recsplit.OpenIndex(fPath)
+	if err != nil {
+		return err
+	}
+	index.Close()
+	return nil
+}
+
+// RemoveIndicesOf - removes indices of given segments (`v1-000000-000500-bodies.seg` or it's `.idx` file name)
+// and indices which depend on them (see snaptype.RegisterIndexDeps). BuildMissedIndices re-builds only removed files.
+// It's resumable: if process is interrupted, files which are not built yet are still missing - and will be built by next start.
+func RemoveIndicesOf(dirs datadir.Dirs, names []string) (removed []string, err error) {
+	for _, name := range names {
+		_, name = filepath.Split(name)
+		info, isStateFile, ok := snaptype.ParseFileName(dirs.Snap, name)
+		if !ok || isStateFile {
+			return removed, fmt.Errorf("%s: not a block snapshot file", name)
+		}
+		for _, t := range snaptype.IndexDependents(info.Type.Enum()) {
+			for _, idxName := range t.Type().IdxFileNames(info.Version, info.From, info.To) {
+				err := os.Remove(filepath.Join(dirs.Snap, idxName))
+				if errors.Is(err, os.ErrNotExist) {
+					continue
 				}
-				continue
+				if err != nil {
+					return removed, err
+				}
+				removed = append(removed, idxName)
 			}
-			return fmt.Errorf("%w, %s", err, fPath)
 		}
-		index.Close()
 	}
-	return nil
+	return removed, nil
 }
@@ -2,6 +2,7 @@ package freezeblocks
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"testing"
 	"testing/fstest"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/ledgerwatch/erigon-lib/chain/networkname"
 	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
 	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
 	"github.com/ledgerwatch/erigon-lib/recsplit"
 	"github.com/ledgerwatch/erigon-lib/seg"
@@ -361,6 +363,33 @@ func TestOpenAllSnapshot(t *testing.T) {
 	}
 }
 
+func TestOpenSnapshotsWithHistoryExpiry(t *testing.T) {
+	logger := log.New()
+	dir, require := t.TempDir(), require.New(t)
+	for _, r := range []Range{{0, 500_000}, {500_000, 1_000_000}} {
+		createTestSegmentFile(t, r.from, r.to, coresnaptype.Enums.Headers, dir, 1, logger)
+		createTestSegmentFile(t, r.from, r.to, coresnaptype.Enums.Bodies, dir, 1, logger)
+	}
+	createTestSegmentFile(t, 500_000, 1_000_000, coresnaptype.Enums.Transactions, dir, 1, logger)
+
+	txsAvailable := func(expiryBlock uint64) bool {
+		s := NewRoSnapshots(ethconfig.BlocksFreezing{Enabled: true, ExpiryBlock: expiryBlock}, dir, 0, logger)
+		defer s.Close()
+		require.NoError(s.ReopenFolder())
+		view := s.View()
+		defer view.Close()
+		_, ok := view.TxsSegment(10)
+		require.False(ok)
+		_, ok = view.TxsSegment(500_000)
+		return ok
+	}
+	// transactions files of expired blocks are optional, others must have no gaps
+	require.False(txsAvailable(0))
+	require.True(txsAvailable(500_000))
+	require.True(txsAvailable(600_000))
+	require.False(txsAvailable(400_000))
+}
+
 func TestParseCompressedFileName(t *testing.T) {
 	require := require.New(t)
 	fs := fstest.MapFS{
@@ -399,3 +428,38 @@ func TestParseCompressedFileName(t *testing.T) {
 	require.Equal(1_000, int(f.From))
 	require.Equal(2_000, int(f.To))
 }
+
+func TestRemoveIndicesOf(t *testing.T) {
+	logger := log.New()
+	dirs := datadir.New(t.TempDir())
+	for _, e := range []snaptype.Enum{coresnaptype.Enums.Headers, coresnaptype.Enums.Bodies, coresnaptype.Enums.Transactions} {
+		createTestSegmentFile(t, 0, 500_000, e, dirs.Snap, 1, logger)
+		createTestSegmentFile(t, 500_000, 1_000_000, e, dirs.Snap, 1, logger)
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dirs.Snap, name))
+		return err == nil
+	}
+
+	// bodies: transactions.idx depends on bodies.seg
+	removed, err := RemoveIndicesOf(dirs, []string{"v1-000000-000500-bodies.seg"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1-000000-000500-bodies.idx", "v1-000000-000500-transactions.idx", "v1-000000-000500-transactions-to-block.idx"}, removed)
+	require.True(t, exists("v1-000000-000500-headers.idx"))
+	require.True(t, exists("v1-000500-001000-bodies.idx"))
+	require.True(t, exists("v1-000500-001000-transactions.idx"))
+
+	// by index name, nothing depends on headers
+	removed, err = RemoveIndicesOf(dirs, []string{"v1-000500-001000-headers.idx"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1-000500-001000-headers.idx"}, removed)
+
+	_, err = RemoveIndicesOf(dirs, []string{"v1-accounts.0-32.kv"})
+	require.Error(t, err)
+
+	// corrupted index is removed, others are kept
+	require.NoError(t, os.WriteFile(filepath.Join(dirs.Snap, "v1-000000-000500-headers.idx"), []byte{1, 2, 3}, 0644))
+	require.NoError(t, RemoveIncompatibleIndices(dirs))
+	require.False(t, exists("v1-000000-000500-headers.idx"))
+	require.True(t, exists("v1-000500-001000-bodies.idx"))
+}
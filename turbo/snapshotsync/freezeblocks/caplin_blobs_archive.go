@@ -0,0 +1,103 @@
+package freezeblocks
+
+import (
+	"context"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/persistence/beacon_indicies"
+	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
+	"github.com/ledgerwatch/erigon/cl/utils"
+	"github.com/ledgerwatch/erigon/cl/utils/eth_clock"
+)
+
+// BlobsArchiveReader - reads blob sidecars of execution blocks: from blob sidecars snapshots or from Caplin's blob storage.
+// Blobs after retention window are available only with `--caplin.blobs-archive`
+type BlobsArchiveReader struct {
+	indexDB     kv.RoDB
+	blobStorage blob_storage.BlobStorage
+	sn          *CaplinSnapshots
+	ethClock    eth_clock.EthereumClock
+}
+
+func NewBlobsArchiveReader(indexDB kv.RoDB, blobStorage blob_storage.BlobStorage, sn *CaplinSnapshots, ethClock eth_clock.EthereumClock) *BlobsArchiveReader {
+	return &BlobsArchiveReader{indexDB: indexDB, blobStorage: blobStorage, sn: sn, ethClock: ethClock}
+}
+
+func (r *BlobsArchiveReader) readBlobSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) ([]*cltypes.BlobSidecar, error) {
+	if slot < r.sn.FrozenBlobs() {
+		return r.sn.ReadBlobSidecars(slot)
+	}
+	sidecars, _, err := r.blobStorage.ReadBlobSidecars(ctx, slot, blockRoot)
+	return sidecars, err
+}
+
+// BlobSidecars - sidecars of canonical execution block. Slot of block is found by block's time. nil - if block has no blobs or they are not available
+func (r *BlobsArchiveReader) BlobSidecars(ctx context.Context, blockHash libcommon.Hash, blockTime uint64) ([]*cltypes.BlobSidecar, error) {
+	if blockTime < r.ethClock.GenesisTime() {
+		return nil, nil
+	}
+	slot := r.ethClock.GetSlotByTime(time.Unix(int64(blockTime), 0))
+
+	tx, err := r.indexDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	blockRoot, err := beacon_indicies.ReadCanonicalBlockRoot(tx, slot)
+	if err != nil {
+		return nil, err
+	}
+	if blockRoot == (libcommon.Hash{}) {
+		return nil, nil
+	}
+	executionBlockHash, err := beacon_indicies.ReadExecutionBlockHash(tx, blockRoot)
+	if err != nil {
+		return nil, err
+	}
+	if executionBlockHash != (libcommon.Hash{}) && executionBlockHash != blockHash {
+		return nil, nil
+	}
+	tx.Rollback()
+	return r.readBlobSidecars(ctx, slot, blockRoot)
+}
+
+// BlobAndProof - blob and its kzg proof by versioned hash of kzg commitment. nil - if blob is not available
+func (r *BlobsArchiveReader) BlobAndProof(ctx context.Context, versionedHash libcommon.Hash) (blob []byte, proof []byte, err error) {
+	slot, index, found, err := r.blobStorage.ReadVersionedHashIndex(ctx, versionedHash)
+	if err != nil || !found {
+		return nil, nil, err
+	}
+
+	tx, err := r.indexDB.BeginRo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	blockRoot, err := beacon_indicies.ReadCanonicalBlockRoot(tx, slot)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blockRoot == (libcommon.Hash{}) {
+		return nil, nil, nil
+	}
+	tx.Rollback()
+
+	sidecars, err := r.readBlobSidecars(ctx, slot, blockRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, sidecar := range sidecars {
+		if sidecar.Index != index {
+			continue
+		}
+		// index may point to sidecar of non-canonical block
+		if h, err := utils.KzgCommitmentToVersionedHash(sidecar.KzgCommitment); err != nil || h != versionedHash {
+			return nil, nil, err
+		}
+		return sidecar.Blob[:], sidecar.KzgProof[:], nil
+	}
+	return nil, nil, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/seg"
 	"github.com/ledgerwatch/erigon/cl/clparams"
 	"github.com/ledgerwatch/erigon/cl/cltypes"
+	"github.com/ledgerwatch/erigon/cl/persistence/base_encoding"
 	"github.com/ledgerwatch/erigon/cl/persistence/beacon_indicies"
 	"github.com/ledgerwatch/erigon/cl/persistence/blob_storage"
 	"github.com/ledgerwatch/erigon/cl/persistence/format/snapshot_format"
@@ -66,6 +67,7 @@ type CaplinSnapshots struct {
 
 	BeaconBlocks *segments
 	BlobSidecars *segments
+	BeaconStates *segments
 
 	dir         string
 	tmpdir      string
@@ -85,12 +87,18 @@ type CaplinSnapshots struct {
 //   - gaps are not allowed
 //   - segment have [from:to) semantic
 func NewCaplinSnapshots(cfg ethconfig.BlocksFreezing, beaconCfg *clparams.BeaconChainConfig, dirs datadir.Dirs, logger log.Logger) *CaplinSnapshots {
-	return &CaplinSnapshots{dir: dirs.Snap, tmpdir: dirs.Tmp, cfg: cfg, BeaconBlocks: &segments{}, BlobSidecars: &segments{}, logger: logger, beaconCfg: beaconCfg}
+	return &CaplinSnapshots{dir: dirs.Snap, tmpdir: dirs.Tmp, cfg: cfg, BeaconBlocks: &segments{}, BlobSidecars: &segments{}, BeaconStates: &segments{}, logger: logger, beaconCfg: beaconCfg}
 }
 
 func (s *CaplinSnapshots) IndicesMax() uint64  { return s.idxMax.Load() }
 func (s *CaplinSnapshots) SegmentsMax() uint64 { return s.segmentsMax.Load() }
 
+// BeaconStatesEnabled - produce snapshots of beacon states (--caplin.states-snapshots)
+func (s *CaplinSnapshots) BeaconStatesEnabled() bool { return s.cfg.BeaconStates }
+
+// BlobsArchiveEnabled - keep blob sidecars snapshots after retention window and index them (--caplin.blobs-archive)
+func (s *CaplinSnapshots) BlobsArchiveEnabled() bool { return s.cfg.BlobsArchive }
+
 func (s *CaplinSnapshots) SegFilePaths(from, to uint64) []string {
 	var res []string
 	for _, seg := range s.BeaconBlocks.segments {
@@ -103,6 +111,11 @@ func (s *CaplinSnapshots) SegFilePaths(from, to uint64) []string {
 			res = append(res, seg.FilePath())
 		}
 	}
+	for _, seg := range s.BeaconStates.segments {
+		if seg.from >= from && seg.to <= to {
+			res = append(res, seg.FilePath())
+		}
+	}
 	return res
 }
 
@@ -118,6 +131,8 @@ func (s *CaplinSnapshots) Close() {
 	defer s.BeaconBlocks.lock.Unlock()
 	s.BlobSidecars.lock.Lock()
 	defer s.BlobSidecars.lock.Unlock()
+	s.BeaconStates.lock.Lock()
+	defer s.BeaconStates.lock.Unlock()
 	s.closeWhatNotInList(nil)
 }
 
@@ -127,6 +142,8 @@ func (s *CaplinSnapshots) ReopenList(fileNames []string, optimistic bool) error
 	defer s.BeaconBlocks.lock.Unlock()
 	s.BlobSidecars.lock.Lock()
 	defer s.BlobSidecars.lock.Unlock()
+	s.BeaconStates.lock.Lock()
+	defer s.BeaconStates.lock.Unlock()
 
 	s.closeWhatNotInList(fileNames)
 	var segmentsMax uint64
@@ -229,6 +246,44 @@ Loop:
 			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
 				return err
 			}
+		case snaptype.CaplinEnums.BeaconStates:
+			var sn *Segment
+			var exists bool
+			for _, sn2 := range s.BeaconStates.segments {
+				if sn2.Decompressor == nil { // it's ok if some segment was not able to open
+					continue
+				}
+				if fName == sn2.FileName() {
+					sn = sn2
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				sn = &Segment{segType: snaptype.BeaconStates, version: f.Version, Range: Range{f.From, f.To}}
+			}
+			if err := sn.reopenSeg(s.dir); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					if optimistic {
+						continue Loop
+					} else {
+						break Loop
+					}
+				}
+				if optimistic {
+					s.logger.Warn("[snapshots] open segment", "err", err)
+					continue Loop
+				} else {
+					return err
+				}
+			}
+
+			if !exists {
+				s.BeaconStates.segments = append(s.BeaconStates.segments, sn)
+			}
+			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
+				return err
+			}
 		}
 
 	}
@@ -317,6 +372,31 @@ Loop2:
 			tail[i] = nil
 		}
 	}
+Loop3:
+	for i, sn := range s.BeaconStates.segments {
+		if sn.Decompressor == nil {
+			continue Loop3
+		}
+		_, name := filepath.Split(sn.FilePath())
+		for _, fName := range l {
+			if fName == name {
+				continue Loop3
+			}
+		}
+		sn.close()
+		s.BeaconStates.segments[i] = nil
+	}
+
+	for i = 0; i < len(s.BeaconStates.segments) && s.BeaconStates.segments[i] != nil && s.BeaconStates.segments[i].Decompressor != nil; i++ {
+	}
+	tail = s.BeaconStates.segments[i:]
+	s.BeaconStates.segments = s.BeaconStates.segments[:i]
+	for i = 0; i < len(tail); i++ {
+		if tail[i] != nil {
+			tail[i].close()
+			tail[i] = nil
+		}
+	}
 }
 
 type CaplinView struct {
@@ -328,6 +408,7 @@ func (s *CaplinSnapshots) View() *CaplinView {
 	v := &CaplinView{s: s}
 	v.s.BeaconBlocks.lock.RLock()
 	v.s.BlobSidecars.lock.RLock()
+	v.s.BeaconStates.lock.RLock()
 	return v
 }
 
@@ -338,11 +419,13 @@ func (v *CaplinView) Close() {
 	v.closed = true
 	v.s.BeaconBlocks.lock.RUnlock()
 	v.s.BlobSidecars.lock.RUnlock()
+	v.s.BeaconStates.lock.RUnlock()
 
 }
 
 func (v *CaplinView) BeaconBlocks() []*Segment { return v.s.BeaconBlocks.segments }
 func (v *CaplinView) BlobSidecars() []*Segment { return v.s.BlobSidecars.segments }
+func (v *CaplinView) BeaconStates() []*Segment { return v.s.BeaconStates.segments }
 
 func (v *CaplinView) BeaconBlocksSegment(slot uint64) (*Segment, bool) {
 	for _, seg := range v.BeaconBlocks() {
@@ -364,6 +447,16 @@ func (v *CaplinView) BlobSidecarsSegment(slot uint64) (*Segment, bool) {
 	return nil, false
 }
 
+func (v *CaplinView) BeaconStatesSegment(slot uint64) (*Segment, bool) {
+	for _, seg := range v.BeaconStates() {
+		if !(slot >= seg.from && slot < seg.to) {
+			continue
+		}
+		return seg, true
+	}
+	return nil, false
+}
+
 func dumpBeaconBlocksRange(ctx context.Context, db kv.RoDB, fromSlot uint64, toSlot uint64, salt uint32, dirs datadir.Dirs, workers int, lvl log.Lvl, logger log.Logger) error {
 	tmpDir, snapDir := dirs.Tmp, dirs.Snap
 
@@ -512,6 +605,103 @@ func DumpBlobsSidecar(ctx context.Context, blobStorage blob_storage.BlobStorage,
 	return nil
 }
 
+// SlotsPerStateSnapshot - beacon states snapshots have full state of every 8192th slot (256 epochs).
+// States of other slots are stored as diffs against the preceding full state.
+const SlotsPerStateSnapshot = 8192
+
+// stateDiffFlag - set in version byte of words which are diffs against a full state
+const stateDiffFlag = 0x80
+
+// BeaconStateSSZ - reads ssz of beacon state at given slot and segments of the ssz (see raw.BeaconState.SegmentsSSZ).
+// nil - state is not available (for example: missed slot)
+type BeaconStateSSZ func(ctx context.Context, tx kv.Tx, slot uint64) (ssz []byte, segments []int, version clparams.StateVersion, err error)
+
+// value: version(1 byte) + zstd(ssz(BeaconState)) at slots divisible by SlotsPerStateSnapshot, also for the first state of the
+// file and when fork changes. Other slots: (version|stateDiffFlag)(1 byte) + slot of full state(8 bytes) + segments xor diff
+// against the full state. Empty word for missed slots.
+func dumpBeaconStatesRange(ctx context.Context, db kv.RoDB, readState BeaconStateSSZ, fromSlot uint64, toSlot uint64, salt uint32, dirs datadir.Dirs, workers int, lvl log.Lvl, logger log.Logger) error {
+	tmpDir, snapDir := dirs.Tmp, dirs.Snap
+
+	segName := snaptype.BeaconStates.FileName(0, fromSlot, toSlot)
+	f, _, _ := snaptype.ParseFileName(snapDir, segName)
+
+	sn, err := seg.NewCompressor(ctx, "Snapshot BeaconStates", f.Path, tmpDir, seg.MinPatternScore, workers, lvl, logger)
+	if err != nil {
+		return err
+	}
+	defer sn.Close()
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return err
+	}
+	defer encoder.Close()
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		word         []byte
+		base         []byte
+		baseSegments []int
+		baseSlot     uint64
+		baseVersion  clparams.StateVersion
+		diff         bytes.Buffer
+	)
+	for i := fromSlot; i < toSlot; i++ {
+		ssz, segments, version, err := readState(ctx, tx, i)
+		if err != nil {
+			return fmt.Errorf("read state at slot %d: %w", i, err)
+		}
+		word = word[:0]
+		switch {
+		case ssz == nil:
+		case base == nil || i%SlotsPerStateSnapshot == 0 || version != baseVersion:
+			word = encoder.EncodeAll(ssz, append(word, byte(version)))
+			base, baseSegments, baseSlot, baseVersion = ssz, segments, i, version
+			logger.Log(lvl, "Dumping beacon states", "progress", i)
+		default:
+			diff.Reset()
+			if err := base_encoding.ComputeCompressedSegmentsXorDiff(&diff, base, ssz, baseSegments, segments); err != nil {
+				return fmt.Errorf("diff of state at slot %d: %w", i, err)
+			}
+			word = append(word, byte(version)|stateDiffFlag)
+			word = binary.BigEndian.AppendUint64(word, baseSlot)
+			word = append(word, diff.Bytes()...)
+		}
+		if err := sn.AddWord(word); err != nil {
+			return err
+		}
+	}
+	if err := sn.Compress(); err != nil {
+		return fmt.Errorf("compress: %w", err)
+	}
+	// Generate .idx file, which is the slot => offset mapping.
+	p := &background.Progress{}
+
+	return BeaconSimpleIdx(ctx, f, salt, tmpDir, p, lvl, logger)
+}
+
+// DumpBeaconStates - same ranges as beacon blocks. `readState` is provided by caller: states are reconstructed from historical data of Caplin
+func DumpBeaconStates(ctx context.Context, db kv.RoDB, readState BeaconStateSSZ, fromSlot, toSlot uint64, salt uint32, dirs datadir.Dirs, workers int, lvl log.Lvl, logger log.Logger) error {
+	for i := fromSlot; i < toSlot; i = chooseSegmentEnd(i, toSlot, snaptype.CaplinEnums.BeaconStates, nil) {
+		blocksPerFile := snapcfg.MergeLimit("", snaptype.CaplinEnums.BeaconStates, i)
+
+		if toSlot-i < blocksPerFile {
+			break
+		}
+		to := chooseSegmentEnd(i, toSlot, snaptype.CaplinEnums.BeaconStates, nil)
+		logger.Log(lvl, "Dumping beacon states", "from", i, "to", to)
+		if err := dumpBeaconStatesRange(ctx, db, readState, i, to, salt, dirs, workers, lvl, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *CaplinSnapshots) BuildMissingIndices(ctx context.Context, logger log.Logger) error {
 	if s == nil {
 		return nil
@@ -527,8 +717,8 @@ func (s *CaplinSnapshots) BuildMissingIndices(ctx context.Context, logger log.Lo
 	}
 	for index := range segments {
 		segment := segments[index]
-		// The same slot=>offset mapping is used for both beacon blocks and blob sidecars.
-		if segment.Type.Enum() != snaptype.CaplinEnums.BeaconBlocks && segment.Type.Enum() != snaptype.CaplinEnums.BlobSidecars {
+		// The same slot=>offset mapping is used for beacon blocks, blob sidecars and beacon states.
+		if !snaptype.IsCaplinType(segment.Type.Enum()) {
 			continue
 		}
 		if segment.Type.HasIndexFiles(segment, logger) {
@@ -633,6 +823,82 @@ func (s *CaplinSnapshots) ReadBlobSidecars(slot uint64) ([]*cltypes.BlobSidecar,
 	return sidecars, nil
 }
 
+// ReadBeaconStateSSZ - ssz of beacon state at slot from beacon states snapshots. nil - if slot is not frozen or missed
+func (s *CaplinSnapshots) ReadBeaconStateSSZ(slot uint64) ([]byte, clparams.StateVersion, error) {
+	buf, err := s.readBeaconStateWord(slot)
+	if err != nil || len(buf) == 0 {
+		return nil, 0, err
+	}
+	if buf[0]&stateDiffFlag == 0 {
+		ssz, err := decompressBeaconState(buf)
+		if err != nil {
+			return nil, 0, fmt.Errorf("beacon state at slot %d: %w", slot, err)
+		}
+		return ssz, clparams.StateVersion(buf[0]), nil
+	}
+	if len(buf) < 9 {
+		return nil, 0, fmt.Errorf("beacon state at slot %d: short diff", slot)
+	}
+	baseSlot := binary.BigEndian.Uint64(buf[1:9])
+	baseBuf, err := s.readBeaconStateWord(baseSlot)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(baseBuf) == 0 || baseBuf[0]&stateDiffFlag != 0 {
+		return nil, 0, fmt.Errorf("beacon state at slot %d: no full state at slot %d", slot, baseSlot)
+	}
+	base, err := decompressBeaconState(baseBuf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("beacon state at slot %d: %w", baseSlot, err)
+	}
+	ssz, err := base_encoding.ApplyCompressedSegmentsXorDiff(base, nil, buf[9:])
+	if err != nil {
+		return nil, 0, fmt.Errorf("beacon state at slot %d: %w", slot, err)
+	}
+	return ssz, clparams.StateVersion(buf[0] &^ stateDiffFlag), nil
+}
+
+func decompressBeaconState(buf []byte) ([]byte, error) {
+	reader := decompressorPool.Get().(*zstd.Decoder)
+	defer decompressorPool.Put(reader)
+	return reader.DecodeAll(buf[1:], nil)
+}
+
+// readBeaconStateWord - raw word of beacon states snapshots at slot, nil if slot is not frozen
+func (s *CaplinSnapshots) readBeaconStateWord(slot uint64) ([]byte, error) {
+	view := s.View()
+	defer view.Close()
+
+	seg, ok := view.BeaconStatesSegment(slot)
+	if !ok {
+		return nil, nil
+	}
+	idxSlot := seg.Index()
+	if idxSlot == nil {
+		return nil, nil
+	}
+	offset := idxSlot.OrdinalLookup(slot - idxSlot.BaseDataID())
+
+	gg := seg.MakeGetter()
+	gg.Reset(offset)
+	if !gg.HasNext() {
+		return nil, nil
+	}
+	buf, _ := gg.Next(nil)
+	return buf, nil
+}
+
+// FrozenStates - beacon states snapshots are available up to this slot (exclusive). 0 - no states snapshots
+func (s *CaplinSnapshots) FrozenStates() uint64 {
+	view := s.View()
+	defer view.Close()
+	var ret uint64
+	for _, seg := range view.BeaconStates() {
+		ret = utils.Max64(ret, seg.to)
+	}
+	return ret
+}
+
 func (s *CaplinSnapshots) FrozenBlobs() uint64 {
 	if s.beaconCfg.DenebForkEpoch == math.MaxUint64 {
 		return 0
@@ -0,0 +1,82 @@
+package freezeblocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/downloader/snaptype"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/cl/clparams"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+)
+
+func TestBeaconStatesSnapshots(t *testing.T) {
+	logger := log.New()
+	dirs := datadir.New(t.TempDir())
+	db := memdb.NewTestDB(t)
+	ctx := context.Background()
+
+	// two segments, the first one grows with slots
+	fakeState := func(slot uint64) ([]byte, []int) {
+		first := bytes.Repeat([]byte{byte(slot / SlotsPerStateSnapshot)}, 1000+int(slot%SlotsPerStateSnapshot))
+		binary.BigEndian.PutUint64(first, slot)
+		return append(first, bytes.Repeat([]byte{7}, 100)...), []int{0, len(first), len(first) + 100}
+	}
+	readState := func(ctx context.Context, tx kv.Tx, slot uint64) ([]byte, []int, clparams.StateVersion, error) {
+		if slot == 2*SlotsPerStateSnapshot || slot == 2*SlotsPerStateSnapshot+3 { // missed slots
+			return nil, nil, 0, nil
+		}
+		version := clparams.DenebVersion
+		if slot < SlotsPerStateSnapshot+10 {
+			version = clparams.CapellaVersion
+		}
+		buf, segments := fakeState(slot)
+		return buf, segments, version, nil
+	}
+	expectedState := func(slot uint64) []byte {
+		buf, _ := fakeState(slot)
+		return buf
+	}
+	require.NoError(t, DumpBeaconStates(ctx, db, readState, 0, snaptype.Erigon2MergeLimit, 0, dirs, 1, log.LvlDebug, logger))
+
+	sn := NewCaplinSnapshots(ethconfig.BlocksFreezing{BeaconStates: true}, &clparams.MainnetBeaconConfig, dirs, logger)
+	defer sn.Close()
+	require.NoError(t, sn.ReopenFolder())
+	require.Equal(t, uint64(snaptype.Erigon2MergeLimit), sn.FrozenStates())
+	require.Equal(t, uint64(0), sn.BlocksAvailable()) // states don't make blocks available
+
+	buf, version, err := sn.ReadBeaconStateSSZ(SlotsPerStateSnapshot)
+	require.NoError(t, err)
+	require.Equal(t, clparams.CapellaVersion, version)
+	require.Equal(t, expectedState(SlotsPerStateSnapshot), buf)
+
+	// diffs against full states
+	for _, slot := range []uint64{1, SlotsPerStateSnapshot + 1, SlotsPerStateSnapshot + 10, SlotsPerStateSnapshot + 11, 2*SlotsPerStateSnapshot + 4, 3*SlotsPerStateSnapshot - 1} {
+		buf, version, err = sn.ReadBeaconStateSSZ(slot)
+		require.NoError(t, err)
+		require.Equal(t, expectedState(slot), buf, slot)
+		if slot < SlotsPerStateSnapshot+10 {
+			require.Equal(t, clparams.CapellaVersion, version)
+		} else {
+			require.Equal(t, clparams.DenebVersion, version)
+		}
+	}
+
+	buf, _, err = sn.ReadBeaconStateSSZ(2 * SlotsPerStateSnapshot)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+	buf, _, err = sn.ReadBeaconStateSSZ(2*SlotsPerStateSnapshot + 3)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+	buf, _, err = sn.ReadBeaconStateSSZ(snaptype.Erigon2MergeLimit + SlotsPerStateSnapshot*4)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+}
@@ -288,3 +288,47 @@ func createDumpTestKV(t *testing.T, chainConfig *chain.Config, chainSize int) *m
 
 	return m
 }
+
+func TestIterateFrozenRawBodies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win")
+	}
+	require := require.New(t)
+	m := createDumpTestKV(t, params.TestChainConfig, 2000)
+
+	// two segments: [0, 1000) and [1000, 2000)
+	for _, r := range [][2]uint64{{0, 1000}, {1000, 2000}} {
+		require.NoError(freezeblocks.DumpBlocks(m.Ctx, r[0], r[1], m.ChainConfig, m.Dirs.Tmp, m.Dirs.Snap, m.DB, 1, log.LvlInfo, m.Log, m.BlockReader))
+		require.NoError(m.BlockSnapshots.ReopenFolder())
+	}
+	require.Equal(uint64(1999), m.BlockSnapshots.BlocksAvailable())
+	require.Len(m.BlockSnapshots.Files(), 6)
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	for _, r := range [][2]uint64{{0, 2000}, {990, 1010}, {1000, 1001}, {1995, 2100}} {
+		var blockNums []uint64
+		var bodies []*types.RawBody
+		err = m.BlockReader.IterateFrozenRawBodies(r[0], r[1], func(blockNum uint64, txs [][]byte, withdrawals []*types.Withdrawal) error {
+			blockNums = append(blockNums, blockNum)
+			bodies = append(bodies, &types.RawBody{Transactions: txs, Withdrawals: withdrawals})
+			return nil
+		})
+		require.NoError(err)
+		require.Equal(nonceRange(int(r[0]), int(min(r[1], 2000))-1), blockNums, r)
+
+		for i, blockNum := range blockNums {
+			hash, err := m.BlockReader.CanonicalHash(m.Ctx, tx, blockNum)
+			require.NoError(err)
+			body, err := m.BlockReader.BodyWithTransactions(m.Ctx, tx, hash, blockNum)
+			require.NoError(err)
+			expected, err := types.MarshalTransactionsBinary(body.Transactions)
+			require.NoError(err)
+			require.Equal(expected, bodies[i].Transactions, blockNum)
+			require.Equal(min(blockNum, 1), uint64(len(bodies[i].Transactions)), blockNum) // a transaction in every block
+			require.Equal(body.Withdrawals, bodies[i].Withdrawals, blockNum)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package freezeblocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/chain/snapcfg"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/cmp"
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	coresnaptype "github.com/ledgerwatch/erigon/core/snaptype"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/services"
+)
+
+// Receipts
+// value: rlp(types.ReceiptsForStorage) - one word per block
+// block_num - base_block_num -> offset
+
+type ReceiptsRoSnapshots struct {
+	RoSnapshots
+}
+
+// NewReceiptsRoSnapshots - opens receipts snapshots. Unlike blocks:
+//   - files are optional: produced only by `--snap.receipts` nodes, blocks are available without them
+//   - files may start not from genesis: receipts of old blocks may be pruned from db before files creation
+//   - segment have [from:to) semantic
+func NewReceiptsRoSnapshots(cfg ethconfig.BlocksFreezing, snapDir string, logger log.Logger) *ReceiptsRoSnapshots {
+	s := &ReceiptsRoSnapshots{*newRoSnapshots(cfg, snapDir, coresnaptype.ReceiptSnapshotTypes, 0, logger)}
+	s.allowGaps = true
+	return s
+}
+
+func (s *ReceiptsRoSnapshots) Ranges() []Range {
+	view := s.View()
+	defer view.Close()
+	view.baseSegType = coresnaptype.Receipts
+	return view.Ranges()
+}
+
+// ReadReceipts - receipts of given block from files. Returns nil if block's receipts are not in files
+func (s *ReceiptsRoSnapshots) ReadReceipts(block *types.Block, senders []common.Address) (types.Receipts, error) {
+	if s == nil || block == nil || block.NumberU64() > s.BlocksAvailable() {
+		return nil, nil
+	}
+
+	view := s.View()
+	defer view.Close()
+
+	sn, ok := view.Segment(coresnaptype.Receipts, block.NumberU64())
+	if !ok {
+		return nil, nil
+	}
+	stored, err := s.receiptsFromSnapshot(block.NumberU64(), sn)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	if len(stored) != block.Transactions().Len() {
+		return nil, fmt.Errorf("receipts snapshot %s: block %d has %d txs, but %d receipts", sn.FileName(), block.NumberU64(), block.Transactions().Len(), len(stored))
+	}
+
+	receipts := make(types.Receipts, len(stored))
+	for i, r := range stored {
+		receipts[i] = (*types.Receipt)(r)
+		receipts[i].Bloom = types.CreateBloom(types.Receipts{receipts[i]})
+	}
+	if len(senders) > 0 {
+		block.SendersToTxs(senders)
+	} else {
+		senders = block.Body().SendersFromTxs()
+	}
+	if err := receipts.DeriveFields(block.Hash(), block.NumberU64(), block.Transactions(), senders); err != nil {
+		return nil, fmt.Errorf("receipts snapshot %s: block %d: %w", sn.FileName(), block.NumberU64(), err)
+	}
+	return receipts, nil
+}
+
+func (s *ReceiptsRoSnapshots) receiptsFromSnapshot(blockNum uint64, sn *Segment) (types.ReceiptsForStorage, error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(fmt.Errorf("%+v, snapshot: %d-%d, trace: %s", rec, sn.from, sn.to, dbg.Stack()))
+		}
+	}() // avoid crash because Erigon's core does many things
+
+	index := sn.Index()
+	if index == nil {
+		return nil, nil
+	}
+	gg := sn.MakeGetter()
+	gg.Reset(index.OrdinalLookup(blockNum - index.BaseDataID()))
+	if !gg.HasNext() {
+		return nil, nil
+	}
+	buf, _ := gg.Next(nil)
+	stored := types.ReceiptsForStorage{}
+	if err := rlp.DecodeBytes(buf, &stored); err != nil {
+		return nil, fmt.Errorf("receipts snapshot %s: block %d: %w", sn.FileName(), blockNum, err)
+	}
+	return stored, nil
+}
+
+func (s *ReceiptsRoSnapshots) buildMissedIndicesIfNeed(ctx context.Context, logPrefix string, notifier services.DBEventNotifier, br *BlockRetire) error {
+	if s.IndicesMax() >= s.SegmentsMax() || !s.Cfg().Produce {
+		return nil
+	}
+	if err := s.buildMissedIndices(logPrefix, ctx, br.dirs, br.chainConfig, estimate.IndexSnapshot.Workers(), br.logger); err != nil {
+		return fmt.Errorf("can't build missed receipts indices: %w", err)
+	}
+	if err := s.ReopenFolder(); err != nil {
+		return err
+	}
+	if notifier != nil {
+		notifier.OnNewSnapshot()
+	}
+	return nil
+}
+
+// errReceiptsNotAvailable - db has no full receipts of block: for example they were pruned or written partially by `--prune.r`
+var errReceiptsNotAvailable = errors.New("receipts not available")
+
+// receiptsDumper - [from, to). Receipts are verified by header's ReceiptHash before writing
+func receiptsDumper(blockReader services.FullBlockReader) dumpFunc {
+	return func(ctx context.Context, db kv.RoDB, _ *chain.Config, blockFrom, blockTo uint64, _ firstKeyGetter, collect func([]byte) error, workers int, lvl log.Lvl, logger log.Logger) (uint64, error) {
+		logEvery := time.NewTicker(20 * time.Second)
+		defer logEvery.Stop()
+
+		const batch = 1_000 // don't keep read transaction open for whole file
+		for from := blockFrom; from < blockTo; from += batch {
+			if err := db.View(ctx, func(tx kv.Tx) error {
+				for blockNum := from; blockNum < cmp.Min(from+batch, blockTo); blockNum++ {
+					hash, err := blockReader.CanonicalHash(ctx, tx, blockNum)
+					if err != nil {
+						return err
+					}
+					block, senders, err := blockReader.BlockWithSenders(ctx, tx, hash, blockNum)
+					if err != nil {
+						return err
+					}
+					if block == nil {
+						return fmt.Errorf("%w: block %d not found", errReceiptsNotAvailable, blockNum)
+					}
+					receipts := rawdb.ReadReceipts(tx, block, senders)
+					stored := make(types.ReceiptsForStorage, len(receipts))
+					for i, r := range receipts {
+						r.Bloom = types.CreateBloom(types.Receipts{r})
+						stored[i] = (*types.ReceiptForStorage)(r)
+					}
+					if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+						return fmt.Errorf("%w: block %d receipts root mismatch: %x != %x", errReceiptsNotAvailable, blockNum, hash, block.ReceiptHash())
+					}
+					v, err := rlp.EncodeToBytes(stored)
+					if err != nil {
+						return err
+					}
+					if err := collect(v); err != nil {
+						return err
+					}
+
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-logEvery.C:
+						logger.Log(lvl, "[snapshots] Wrote into file", "type", coresnaptype.Receipts, "block num", blockNum)
+					default:
+					}
+				}
+				return nil
+			}); err != nil {
+				return 0, err
+			}
+		}
+		return 0, nil
+	}
+}
+
+// receiptsRetireFrom - first block of next receipts file. New files continue last existing file,
+// first file starts from first block which receipts are in db (aligned to merge limit: to allow merge of next files)
+func (br *BlockRetire) receiptsRetireFrom(tx kv.Tx, rs *ReceiptsRoSnapshots) (uint64, bool, error) {
+	if ranges := rs.Ranges(); len(ranges) > 0 {
+		return ranges[len(ranges)-1].to, true, nil
+	}
+	availableFrom, err := rawdb.ReceiptsAvailableFrom(tx)
+	if err != nil {
+		return 0, false, err
+	}
+	if availableFrom == math.MaxUint64 {
+		return 0, false, nil
+	}
+	if availableFrom <= 1 { // genesis receipts are not stored
+		return 0, true, nil
+	}
+	mergeLimit := snapcfg.MergeLimit(br.chainConfig.ChainName, coresnaptype.Enums.Receipts, availableFrom)
+	return (availableFrom + mergeLimit - 1) / mergeLimit * mergeLimit, true, nil
+}
+
+func (br *BlockRetire) retireReceipts(ctx context.Context, lvl log.Lvl, seedNewSnapshots func(downloadRequest []services.DownloadRequest) error, onDelete func(l []string) error) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	rs := br.snapshots().Receipts()
+	if rs == nil || !br.blockReader.FreezingCfg().Receipts {
+		return false, nil
+	}
+	notifier, logger, blockReader, tmpDir, db, workers := br.notifier, br.logger, br.blockReader, br.tmpDir, br.db, br.workers
+
+	var from, to uint64
+	var has bool
+	if err := db.View(ctx, func(tx kv.Tx) (err error) {
+		if from, has, err = br.receiptsRetireFrom(tx, rs); err != nil {
+			return err
+		}
+		executed, err := stages.GetStageProgress(tx, stages.Execution)
+		if err != nil {
+			return err
+		}
+		to = cmp.Min(blockReader.FrozenBlocks(), executed) + 1
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	blockFrom, blockTo, ok := canRetire(from, to, coresnaptype.Enums.Receipts, br.chainConfig)
+	ok = ok && has
+	if ok {
+		logger.Log(lvl, "[snapshots] Retire Receipts", "range", fmt.Sprintf("%dk-%dk", blockFrom/1000, blockTo/1000))
+		for i := blockFrom; i < blockTo; i = chooseSegmentEnd(i, blockTo, coresnaptype.Enums.Receipts, br.chainConfig) {
+			end := chooseSegmentEnd(i, blockTo, coresnaptype.Enums.Receipts, br.chainConfig)
+			if _, err := dumpRange(ctx, coresnaptype.Receipts.FileInfo(rs.Dir(), i, end), receiptsDumper(blockReader), nil, db, br.chainConfig, tmpDir, workers, lvl, logger); err != nil {
+				if !errors.Is(err, errReceiptsNotAvailable) {
+					return false, fmt.Errorf("DumpReceipts: %d-%d: %w", i, end, err)
+				}
+				logger.Debug("[snapshots] Retire Receipts: stop", "reason", err, "recommendations", "receipts files can be produced only from full receipts in db: don't use --prune.r or download receipts files")
+				ok = i > blockFrom
+				break
+			}
+		}
+	}
+	if ok {
+		if err := rs.ReopenFolder(); err != nil {
+			return ok, fmt.Errorf("reopen: %w", err)
+		}
+		rs.LogStat("receipts:retire")
+		if notifier != nil && !reflect.ValueOf(notifier).IsNil() { // notify about new snapshots of any size
+			notifier.OnNewSnapshot()
+		}
+	}
+
+	merger := NewMerger(tmpDir, workers, lvl, db, br.chainConfig, logger)
+	rangesToMerge := merger.FindMergeRanges(rs.Ranges(), rs.BlocksAvailable())
+	if len(rangesToMerge) == 0 {
+		return ok, nil
+	}
+	ok = true // have something to merge
+	onMerge := func(r Range) error {
+		if notifier != nil && !reflect.ValueOf(notifier).IsNil() { // notify about new snapshots of any size
+			notifier.OnNewSnapshot()
+		}
+
+		if seedNewSnapshots != nil {
+			downloadRequest := []services.DownloadRequest{
+				services.NewDownloadRequest("", ""),
+			}
+			if err := seedNewSnapshots(downloadRequest); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := merger.Merge(ctx, &rs.RoSnapshots, coresnaptype.ReceiptSnapshotTypes, rangesToMerge, rs.Dir(), true /* doIndex */, onMerge, onDelete); err != nil {
+		return ok, err
+	}
+	return ok, nil
+}
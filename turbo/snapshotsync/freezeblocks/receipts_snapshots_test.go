@@ -0,0 +1,88 @@
+package freezeblocks
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/background"
+	"github.com/ledgerwatch/erigon-lib/seg"
+
+	coresnaptype "github.com/ledgerwatch/erigon/core/snaptype"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+func TestReceiptsSnapshots(t *testing.T) {
+	logger := log.New()
+	dir, require := t.TempDir(), require.New(t)
+	from, to, withTxs := uint64(500_000), uint64(501_000), uint64(500_010)
+
+	to1 := libcommon.Address{1}
+	txs := []types.Transaction{
+		types.NewTransaction(0, to1, uint256.NewInt(1), 21000, uint256.NewInt(1), nil),
+		types.NewTransaction(1, to1, uint256.NewInt(1), 21000, uint256.NewInt(1), nil),
+	}
+	r1 := types.NewReceipt(false, 21000)
+	r1.Logs = []*types.Log{{Address: to1, Topics: []libcommon.Hash{{2}}, Data: []byte{3}}}
+	r1.Bloom = types.CreateBloom(types.Receipts{r1})
+	r2 := types.NewReceipt(true, 42000)
+	r2.Logs = []*types.Log{}
+
+	// files of optional types may start not from genesis
+	info := coresnaptype.Receipts.FileInfo(dir, from, to)
+	c, err := seg.NewCompressor(context.Background(), "test", info.Path, dir, seg.MinPatternScore, 1, log.LvlDebug, logger)
+	require.NoError(err)
+	defer c.Close()
+	c.DisableFsync()
+	for blockNum := from; blockNum < to; blockNum++ {
+		stored := types.ReceiptsForStorage{}
+		if blockNum == withTxs {
+			stored = types.ReceiptsForStorage{(*types.ReceiptForStorage)(r1), (*types.ReceiptForStorage)(r2)}
+		}
+		v, err := rlp.EncodeToBytes(stored)
+		require.NoError(err)
+		require.NoError(c.AddWord(v))
+	}
+	require.NoError(c.Compress())
+	require.NoError(coresnaptype.Receipts.BuildIndexes(context.Background(), info, params.MainnetChainConfig, dir, &background.Progress{}, log.LvlDebug, logger))
+
+	// opened together with blocks snapshots
+	s := NewRoSnapshots(ethconfig.BlocksFreezing{Enabled: true}, dir, 0, logger)
+	defer s.Close()
+	require.NoError(s.ReopenFolder())
+	require.Equal(to-1, s.Receipts().BlocksAvailable())
+	require.Equal([]Range{{from, to}}, s.Receipts().Ranges())
+	require.Contains(s.Files(), filepath.Base(info.Path))
+
+	header := &types.Header{Number: new(big.Int).SetUint64(withTxs)}
+	block := types.NewBlock(header, txs, nil, types.Receipts{(*types.Receipt)(r1), (*types.Receipt)(r2)}, nil)
+	senders := []libcommon.Address{{5}, {6}}
+	receipts, err := s.Receipts().ReadReceipts(block, senders)
+	require.NoError(err)
+	require.Equal(2, len(receipts))
+	require.Equal(block.ReceiptHash(), types.DeriveSha(receipts))
+	require.Equal(txs[1].Hash(), receipts[1].TxHash)
+	require.Equal(uint64(21000), receipts[1].GasUsed)
+	require.Equal(withTxs, receipts[0].BlockNumber.Uint64())
+	require.Equal(1, len(receipts[0].Logs))
+
+	// tx amount doesn't match block
+	_, err = s.Receipts().ReadReceipts(types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(withTxs + 1)}, txs, nil, nil, nil), senders)
+	require.Error(err)
+
+	// not in files
+	for _, blockNum := range []uint64{from - 1, to} {
+		receipts, err = s.Receipts().ReadReceipts(types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(blockNum)}, nil, nil, nil, nil), nil)
+		require.NoError(err)
+		require.Nil(receipts)
+	}
+}
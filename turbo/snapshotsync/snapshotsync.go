@@ -95,7 +95,21 @@ func WaitForDownloader(ctx context.Context, logPrefix string, histV3, blobs bool
 
 	// send all hashes to the Downloader service
 	snapCfg := snapcfg.KnownCfg(cc.ChainName)
-	preverifiedBlockSnapshots := snapCfg.Preverified
+	profile, err := snapcfg.ParseDownloadProfile(blockReader.FreezingCfg().DownloadProfile)
+	if err != nil {
+		return err
+	}
+	if !histV3 && profile.RecentBlocks > 0 {
+		return fmt.Errorf("[%s] download profile %s requires state files (history v3): without them all blocks must be executed", logPrefix, profile)
+	}
+	preverifiedBlockSnapshots := snapCfg.Preverified.Profiled(profile)
+	if profile.Name != snapcfg.ArchiveProfile.Name {
+		log.Info(fmt.Sprintf("[%s] Download profile", logPrefix), "profile", profile, "files", len(preverifiedBlockSnapshots), "of", len(snapCfg.Preverified), "min_block", snapCfg.Preverified.ProfileMinBlock(profile))
+	}
+	if expiryBlock := blockReader.FreezingCfg().ExpiryBlock; expiryBlock > 0 {
+		preverifiedBlockSnapshots = preverifiedBlockSnapshots.WithoutExpired(expiryBlock)
+		log.Info(fmt.Sprintf("[%s] History expiry: transactions and receipts of older blocks are not downloaded", logPrefix), "expiry_block", expiryBlock, "files", len(preverifiedBlockSnapshots))
+	}
 	downloadRequest := make([]services.DownloadRequest, 0, len(preverifiedBlockSnapshots))
 
 	// build all download requests
@@ -105,15 +119,22 @@ func WaitForDownloader(ctx context.Context, logPrefix string, histV3, blobs bool
 				continue
 			}
 		}
-		if caplin == NoCaplin && (strings.Contains(p.Name, "beaconblocks") || strings.Contains(p.Name, "blobsidecars")) {
+		isCaplin := strings.Contains(p.Name, "beaconblocks") || strings.Contains(p.Name, "blobsidecars") || strings.Contains(p.Name, "beaconstates")
+		if caplin == NoCaplin && isCaplin {
 			continue
 		}
-		if caplin == OnlyCaplin && !strings.Contains(p.Name, "beaconblocks") && !strings.Contains(p.Name, "blobsidecars") {
+		if caplin == OnlyCaplin && !isCaplin {
 			continue
 		}
 		if !blobs && strings.Contains(p.Name, "blobsidecars") {
 			continue
 		}
+		if !blockReader.FreezingCfg().BeaconStates && strings.Contains(p.Name, "beaconstates") {
+			continue
+		}
+		if !blockReader.FreezingCfg().Receipts && strings.Contains(p.Name, "receipts") {
+			continue
+		}
 		downloadRequest = append(downloadRequest, services.NewDownloadRequest(p.Name, p.Hash))
 	}
 
@@ -225,7 +246,8 @@ func WaitForDownloader(ctx context.Context, logPrefix string, histV3, blobs bool
 
 	if caplin != NoCaplin {
 		for _, p := range snaptype.CaplinSnapshotTypes {
-			if p.Enum() == snaptype.BlobSidecars.Enum() && !blobs {
+			// blobs archive keeps downloading new blob sidecars snapshots from webseeds
+			if p.Enum() == snaptype.BlobSidecars.Enum() && (!blobs || blockReader.FreezingCfg().BlobsArchive) {
 				continue
 			}
 
@@ -257,6 +279,10 @@ func WaitForDownloader(ctx context.Context, logPrefix string, histV3, blobs bool
 func logStats(ctx context.Context, stats *proto_downloader.StatsReply, startTime time.Time, stagesIdsList []string, logPrefix string, logReason string) {
 	var m runtime.MemStats
 
+	var eta float64
+	if stats.DownloadRate > 0 && stats.BytesTotal > stats.BytesCompleted {
+		eta = float64((stats.BytesTotal - stats.BytesCompleted) / stats.DownloadRate)
+	}
 	diagnostics.Send(diagnostics.SyncStagesList{Stages: stagesIdsList})
 	diagnostics.Send(diagnostics.SnapshotDownloadStatistics{
 		Downloaded:           stats.BytesCompleted,
@@ -271,6 +297,7 @@ func logStats(ctx context.Context, stats *proto_downloader.StatsReply, startTime
 		Sys:                  m.Sys,
 		DownloadFinished:     stats.Completed,
 		TorrentMetadataReady: stats.MetadataReady,
+		Eta:                  eta,
 	})
 
 	if stats.Completed {
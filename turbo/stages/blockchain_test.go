@@ -18,11 +18,14 @@ package stages_test
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/ledgerwatch/erigon-lib/common/hexutil"
@@ -38,9 +41,12 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/bitmapdb"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	state2 "github.com/ledgerwatch/erigon-lib/state"
 	types2 "github.com/ledgerwatch/erigon-lib/types"
 
 	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
@@ -48,6 +54,7 @@ import (
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/ethdb/prune"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/turbo/services"
@@ -730,6 +737,7 @@ func TestBeforeModes(t *testing.T) {
 	mode := prune.DefaultMode
 	mode.History = prune.Before(0)
 	mode.Receipts = prune.Before(1)
+	mode.LogIndex = prune.Before(1)
 	mode.TxIndex = prune.Before(2)
 	mode.CallTraces = prune.Before(3)
 	doModesTest(t, mode)
@@ -2271,3 +2279,191 @@ func current(m *mock.MockSentry, tx kv.Tx) *types.Block {
 	}
 	return b
 }
+
+// TestParallelExecution - blocks executed by optimistic parallel executor must have same state as serially generated ones:
+// txs of block conflict by senders, by storage slot of same contract, some use BLOCKHASH (always re-executed)
+func TestParallelExecution(t *testing.T) {
+	t.Parallel()
+	var (
+		counter   = libcommon.HexToAddress("0x000000000000000000000000000000000000cccc")
+		blockHash = libcommon.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		keys      = make([]*ecdsa.PrivateKey, 8)
+		funds     = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+		gspec     = &types.Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 30_000_000,
+			Alloc: types.GenesisAlloc{
+				// slot0 += 1
+				counter: {Balance: big.NewInt(0), Code: []byte{
+					byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+				}},
+				// slot[NUMBER] = BLOCKHASH(NUMBER-1)
+				blockHash: {Balance: big.NewInt(0), Code: []byte{
+					byte(vm.PUSH1), 1, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH), byte(vm.NUMBER), byte(vm.SSTORE), byte(vm.STOP),
+				}},
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		gspec.Alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.GenesisAccount{Balance: funds}
+	}
+	syncCfg := ethconfig.Defaults.Sync
+	syncCfg.ParallelExecWorkers = 4
+	m := mock.MockWithSyncConfig(t, gspec, keys[0], ethash.NewFaker(), syncCfg)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+
+	const blocks, txsPerSender = 5, 4
+	counterCalls := 0
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, blocks, func(i int, b *core.BlockGen) {
+		for j := 0; j < txsPerSender; j++ {
+			for k, key := range keys {
+				var to libcommon.Address
+				var gas uint64 = params.TxGas
+				switch {
+				case k%4 == 1:
+					to, gas = counter, 100_000
+					counterCalls++
+				case k == 2 && j == 0:
+					to, gas = blockHash, 100_000
+				default:
+					to = libcommon.BytesToAddress([]byte{byte(i), byte(j), byte(k), 1})
+				}
+				nonce := b.TxNonce(crypto.PubkeyToAddress(key.PublicKey))
+				txn, err := types.SignTx(types.NewTransaction(nonce, to, uint256.NewInt(1000), gas, uint256.NewInt(params.GWei), nil), *signer, key)
+				require.NoError(t, err)
+				b.AddTx(txn)
+			}
+		}
+	})
+	require.NoError(t, err)
+
+	applied := metrics.GetOrCreateCounter(`exec_parallel_applied`).GetValueUint64()
+	require.NoError(t, m.InsertChain(chain))
+	require.Greater(t, metrics.GetOrCreateCounter(`exec_parallel_applied`).GetValueUint64(), applied)
+
+	err = m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		st := state.New(m.NewStateReader(tx))
+		var value uint256.Int
+		st.GetState(counter, &libcommon.Hash{}, &value)
+		require.Equal(t, uint64(counterCalls), value.Uint64())
+		for i := 1; i <= blocks; i++ {
+			slot := libcommon.Hash(uint256.NewInt(uint64(i)).Bytes32())
+			st.GetState(blockHash, &slot, &value)
+			require.Equal(t, chain.Blocks[i-1].ParentHash(), libcommon.Hash(value.Bytes32()))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// receiptsRecorder - keeps receipts of executed blocks, as they are passed to Finalize
+type receiptsRecorder struct {
+	consensus.Engine
+	mu       sync.Mutex
+	receipts map[uint64]types.Receipts
+}
+
+func (r *receiptsRecorder) Finalize(config *libchain.Config, header *types.Header, ibs *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, withdrawals []*types.Withdrawal,
+	chain consensus.ChainReader, syscall consensus.SystemCall, logger log.Logger,
+) (types.Transactions, types.Receipts, error) {
+	r.mu.Lock()
+	r.receipts[header.Number.Uint64()] = slices.Clone(receipts) // slice is reused by next block
+	r.mu.Unlock()
+	return r.Engine.Finalize(config, header, ibs, txs, uncles, receipts, withdrawals, chain, syscall, logger)
+}
+
+func TestParallelExecutionMatchesSerial(t *testing.T) {
+	t.Parallel()
+	var (
+		// slot0 += 1, log of new value
+		counter = libcommon.HexToAddress("0x000000000000000000000000000000000000cccc")
+		// always reverts
+		reverter = libcommon.HexToAddress("0x000000000000000000000000000000000000dddd")
+		keys     = make([]*ecdsa.PrivateKey, 6)
+		funds    = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+		gspec    = &types.Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 30_000_000,
+			Alloc: types.GenesisAlloc{
+				counter: {Balance: big.NewInt(0), Code: []byte{
+					byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+					byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.LOG0), byte(vm.STOP),
+				}},
+				reverter: {Balance: big.NewInt(0), Code: []byte{byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT)}},
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		gspec.Alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.GenesisAccount{Balance: funds}
+	}
+
+	newMock := func(workers int) (*mock.MockSentry, *receiptsRecorder) {
+		engine := &receiptsRecorder{Engine: ethash.NewFaker(), receipts: map[uint64]types.Receipts{}}
+		syncCfg := ethconfig.Defaults.Sync
+		syncCfg.ParallelExecWorkers = workers
+		return mock.MockWithSyncConfig(t, gspec, keys[0], engine, syncCfg), engine
+	}
+	serial, serialReceipts := newMock(0)
+	parallel, parallelReceipts := newMock(4)
+
+	// Every sender calls the counter, so transactions of a block conflict with each other
+	signer := types.LatestSignerForChainID(serial.ChainConfig.ChainID)
+	const blocks, txsPerSender = 4, 3
+	chain, err := core.GenerateChain(serial.ChainConfig, serial.Genesis, ethash.NewFaker(), serial.DB, blocks, func(i int, b *core.BlockGen) {
+		for j := 0; j < txsPerSender; j++ {
+			for k, key := range keys {
+				to := counter
+				switch {
+				case k == 1 && j == 1:
+					to = reverter
+				case k == 2:
+					to = libcommon.BytesToAddress([]byte{byte(i), byte(j), 1})
+				}
+				nonce := b.TxNonce(crypto.PubkeyToAddress(key.PublicKey))
+				txn, err := types.SignTx(types.NewTransaction(nonce, to, uint256.NewInt(1000), 100_000, uint256.NewInt(params.GWei), nil), *signer, key)
+				require.NoError(t, err)
+				b.AddTx(txn)
+			}
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, serial.InsertChain(chain))
+	applied := metrics.GetOrCreateCounter(`exec_parallel_applied`).GetValueUint64()
+	require.NoError(t, parallel.InsertChain(chain))
+	require.Greater(t, metrics.GetOrCreateCounter(`exec_parallel_applied`).GetValueUint64(), applied)
+
+	stateRoot := func(m *mock.MockSentry) (root libcommon.Hash) {
+		require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+			domains, err := state2.NewSharedDomains(tx, m.Log)
+			if err != nil {
+				return err
+			}
+			defer domains.Close()
+			rootHash, err := domains.ComputeCommitment(m.Ctx, false, domains.BlockNum(), "")
+			root = libcommon.BytesToHash(rootHash)
+			return err
+		}))
+		return root
+	}
+	require.Equal(t, chain.TopBlock.Root(), stateRoot(serial))
+	require.Equal(t, chain.TopBlock.Root(), stateRoot(parallel))
+
+	for _, block := range chain.Blocks {
+		n := block.NumberU64()
+		require.Len(t, serialReceipts.receipts[n], block.Transactions().Len())
+		require.Equal(t, serialReceipts.receipts[n], parallelReceipts.receipts[n], "receipts of block %d", n)
+		var failed, logs int
+		for _, receipt := range parallelReceipts.receipts[n] {
+			if receipt.Status == types.ReceiptStatusFailed {
+				failed++
+			}
+			logs += len(receipt.Logs)
+		}
+		require.Equal(t, 1, failed)
+		require.Equal(t, (len(keys)-1)*txsPerSender-1, logs)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ledgerwatch/erigon-lib/common/dbg"
@@ -30,6 +32,7 @@ import (
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig/estimate"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
@@ -497,6 +500,60 @@ func (hd *HeaderDownload) VerifyHeader(header *types.Header) error {
 	return hd.engine.VerifyHeader(hd.consensusHeaderReader, header, true /* seal */)
 }
 
+// verifySealWorkers - amount of workers verifying seals of queued headers ahead of insertion, <= 1 - disabled
+var verifySealWorkers = dbg.EnvInt("HEADERS_VERIFY_WORKERS", estimate.AlmostAllCPUs())
+
+const (
+	preverifySealsBatch = 1024                    // headers per round of preverification
+	preverifySealsVisit = 4 * preverifySealsBatch // limits walk over links which don't need preverification
+)
+
+// preverifySeals - seals of not verified links of insert queue and of their descendants are verified by pool of workers.
+// Outcome is remembered by consensus engine: links are still verified and inserted in order by InsertHeader, which
+// makes the decision about bad headers
+func (hd *HeaderDownload) preverifySeals() {
+	preverifier, ok := hd.engine.(consensus.SealPreverifier)
+	if !ok || verifySealWorkers <= 1 {
+		return
+	}
+
+	hd.lock.Lock()
+	headers := make([]*types.Header, 0, preverifySealsBatch)
+	queue := make([]*Link, 0, hd.insertQueue.Len())
+	queue = append(queue, hd.insertQueue...)
+	for visited := 0; visited < len(queue) && visited < preverifySealsVisit && len(headers) < preverifySealsBatch; visited++ {
+		link := queue[visited]
+		if link.header == nil {
+			continue
+		}
+		if !link.verified && !link.sealChecked {
+			link.sealChecked = true
+			headers = append(headers, link.header)
+		}
+		for child := link.fChild; child != nil; child = child.next {
+			queue = append(queue, child)
+		}
+	}
+	hd.lock.Unlock()
+
+	workers := min(verifySealWorkers, len(headers))
+	if workers <= 1 {
+		return
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := int(next.Add(1)) - 1; j < len(headers); j = int(next.Add(1)) - 1 {
+				_ = preverifier.PreverifySeal(headers[j]) // invalid seal is detected by VerifyHeader
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 type FeedHeaderFunc = func(header *types.Header, headerRaw []byte, hash libcommon.Hash, blockHeight uint64) (td *big.Int, err error)
 
 func (hd *HeaderDownload) InsertHeader(hf FeedHeaderFunc, terminalTotalDifficulty *big.Int, logPrefix string, logChannel <-chan time.Time) (bool, bool, uint64, uint64, error) {
@@ -627,7 +684,10 @@ func (hd *HeaderDownload) InsertHeaders(hf FeedHeaderFunc, headerLimit uint, ter
 
 	startHeight := hd.highestInDb
 
-	for more {
+	for i := 0; more; i++ {
+		if i%preverifySealsBatch == 0 {
+			hd.preverifySeals()
+		}
 		if more, force, blocksToTTD, blockTime, err = hd.InsertHeader(hf, terminalTotalDifficulty, logPrefix, logChannel); err != nil {
 			return false, err
 		}
@@ -7,10 +7,12 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/log/v3"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/ledgerwatch/erigon-lib/chain"
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/tracing"
 
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core"
@@ -93,7 +95,12 @@ func DoCall(
 	}()
 
 	gp := new(core.GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	_, span := tracing.Start(ctx, "evm call", attribute.Int64("gas", int64(msg.Gas())))
 	result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
+	if result != nil {
+		span.SetAttributes(attribute.Int64("gas.used", int64(result.UsedGas)))
+	}
+	tracing.End(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,215 @@
+package transactions
+
+import (
+	"github.com/hashicorp/golang-lru/v2"
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+)
+
+var (
+	executionCacheHit  = metrics.GetOrCreateCounter("rpc_execution_cache_hit")
+	executionCacheMiss = metrics.GetOrCreateCounter("rpc_execution_cache_miss")
+)
+
+// ExecutionCache - bounded cache of the state writes of recently re-executed blocks, shared by the RPC handlers computing
+// the environment of a transaction: the state before a transaction is restored from the writes of the previous
+// transactions of the block instead of re-executing them. Records don't depend on tracers.
+type ExecutionCache struct {
+	records *lru.Cache[libcommon.Hash, *executionRecord]
+}
+
+func NewExecutionCache(size int) *ExecutionCache {
+	records, err := lru.New[libcommon.Hash, *executionRecord](size)
+	if err != nil {
+		panic(err)
+	}
+	return &ExecutionCache{records: records}
+}
+
+// Len - number of cached blocks
+func (c *ExecutionCache) Len() int {
+	return c.records.Len()
+}
+
+func (c *ExecutionCache) get(blockHash libcommon.Hash) (*executionRecord, bool) {
+	if c == nil {
+		return nil, false
+	}
+	record, ok := c.records.Get(blockHash)
+	if ok {
+		executionCacheHit.Inc()
+	} else {
+		executionCacheMiss.Inc()
+	}
+	return record, ok
+}
+
+func (c *ExecutionCache) add(blockHash libcommon.Hash, record *executionRecord) {
+	if c == nil {
+		return
+	}
+	c.records.Add(blockHash, record)
+}
+
+// executionRecord - state writes of the initialization of a block and of its first transactions. Records are immutable
+// once cached, a longer record of the block replaces them.
+type executionRecord struct {
+	init stateWrites
+	txs  []stateWrites
+}
+
+// extend - copy of the record to append writes of more transactions to
+func (r *executionRecord) extend() *executionRecord {
+	return &executionRecord{init: r.init, txs: r.txs[:len(r.txs):len(r.txs)]}
+}
+
+// stateWrites - state writes in the order they happened
+type stateWrites []func(w state.StateWriter) error
+
+func (ws stateWrites) apply(w state.StateWriter) error {
+	for _, write := range ws {
+		if err := write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordingWriter - forwards state writes to the writer and records them
+type recordingWriter struct {
+	w      state.StateWriter
+	writes stateWrites
+}
+
+func (rw *recordingWriter) UpdateAccountData(address libcommon.Address, original, account *accounts.Account) error {
+	original, account = original.SelfCopy(), account.SelfCopy()
+	rw.writes = append(rw.writes, func(w state.StateWriter) error {
+		return w.UpdateAccountData(address, original, account)
+	})
+	return rw.w.UpdateAccountData(address, original, account)
+}
+
+func (rw *recordingWriter) UpdateAccountCode(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash, code []byte) error {
+	code = libcommon.CopyBytes(code)
+	rw.writes = append(rw.writes, func(w state.StateWriter) error {
+		return w.UpdateAccountCode(address, incarnation, codeHash, code)
+	})
+	return rw.w.UpdateAccountCode(address, incarnation, codeHash, code)
+}
+
+func (rw *recordingWriter) DeleteAccount(address libcommon.Address, original *accounts.Account) error {
+	original = original.SelfCopy()
+	rw.writes = append(rw.writes, func(w state.StateWriter) error {
+		return w.DeleteAccount(address, original)
+	})
+	return rw.w.DeleteAccount(address, original)
+}
+
+func (rw *recordingWriter) WriteAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash, original, value *uint256.Int) error {
+	k, o, v := *key, *original, *value
+	rw.writes = append(rw.writes, func(w state.StateWriter) error {
+		return w.WriteAccountStorage(address, incarnation, &k, &o, &v)
+	})
+	return rw.w.WriteAccountStorage(address, incarnation, &k, &o, &v)
+}
+
+func (rw *recordingWriter) CreateContract(address libcommon.Address) error {
+	rw.writes = append(rw.writes, func(w state.StateWriter) error {
+		return w.CreateContract(address)
+	})
+	return rw.w.CreateContract(address)
+}
+
+type recordedStorageKey struct {
+	address     libcommon.Address
+	incarnation uint64
+	key         libcommon.Hash
+}
+
+// recordedState - state of the parent block with the writes of the block applied on top: written accounts, storage
+// and code are read from the writes, the rest from the parent state. Storage writes are also forwarded to the parent
+// state, for its ForEachStorage.
+type recordedState struct {
+	*state.PlainState
+	accounts map[libcommon.Address]*accounts.Account // nil for deleted accounts
+	storage  map[recordedStorageKey]uint256.Int
+	code     map[libcommon.Hash][]byte
+	// accounts deleted or re-created, storage of the parent state is gone
+	wiped map[libcommon.Address]struct{}
+}
+
+func newRecordedState(parent *state.PlainState) *recordedState {
+	return &recordedState{
+		PlainState: parent,
+		accounts:   make(map[libcommon.Address]*accounts.Account),
+		storage:    make(map[recordedStorageKey]uint256.Int),
+		code:       make(map[libcommon.Hash][]byte),
+		wiped:      make(map[libcommon.Address]struct{}),
+	}
+}
+
+func (s *recordedState) ReadAccountData(address libcommon.Address) (*accounts.Account, error) {
+	if account, ok := s.accounts[address]; ok {
+		if account == nil {
+			return nil, nil
+		}
+		return account.SelfCopy(), nil
+	}
+	return s.PlainState.ReadAccountData(address)
+}
+
+func (s *recordedState) ReadAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash) ([]byte, error) {
+	if value, ok := s.storage[recordedStorageKey{address: address, incarnation: incarnation, key: *key}]; ok {
+		if value.IsZero() {
+			return nil, nil
+		}
+		return value.Bytes(), nil
+	}
+	if _, ok := s.wiped[address]; ok {
+		return nil, nil
+	}
+	return s.PlainState.ReadAccountStorage(address, incarnation, key)
+}
+
+func (s *recordedState) ReadAccountCode(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash) ([]byte, error) {
+	if code, ok := s.code[codeHash]; ok {
+		return code, nil
+	}
+	return s.PlainState.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (s *recordedState) ReadAccountCodeSize(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash) (int, error) {
+	code, err := s.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+func (s *recordedState) UpdateAccountData(address libcommon.Address, original, account *accounts.Account) error {
+	s.accounts[address] = account.SelfCopy()
+	return nil
+}
+
+func (s *recordedState) UpdateAccountCode(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash, code []byte) error {
+	s.code[codeHash] = code
+	return nil
+}
+
+func (s *recordedState) DeleteAccount(address libcommon.Address, original *accounts.Account) error {
+	s.accounts[address] = nil
+	s.wiped[address] = struct{}{}
+	return nil
+}
+
+func (s *recordedState) WriteAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash, original, value *uint256.Int) error {
+	s.storage[recordedStorageKey{address: address, incarnation: incarnation, key: *key}] = *value
+	return s.PlainState.WriteAccountStorage(address, incarnation, key, original, value)
+}
+
+func (s *recordedState) CreateContract(address libcommon.Address) error {
+	s.wiped[address] = struct{}{}
+	return s.PlainState.CreateContract(address)
+}
@@ -0,0 +1,71 @@
+package transactions
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+func TestExecutionRecordRestoresState(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	var (
+		contract = libcommon.HexToAddress("0x01")
+		deleted  = libcommon.HexToAddress("0x02")
+		key      = libcommon.HexToHash("0x03")
+		code     = []byte{0x60, 0x00}
+		codeHash = crypto.Keccak256Hash(code)
+		value    = uint256.NewInt(7)
+	)
+
+	executed := newRecordedState(state.NewPlainState(tx, 1, nil))
+	w := &recordingWriter{w: executed}
+	account := &accounts.Account{Nonce: 1, Balance: *uint256.NewInt(100), Incarnation: 1, CodeHash: codeHash, Initialised: true}
+	require.NoError(t, w.CreateContract(contract))
+	require.NoError(t, w.UpdateAccountCode(contract, 1, codeHash, code))
+	require.NoError(t, w.WriteAccountStorage(contract, 1, &key, new(uint256.Int), value))
+	require.NoError(t, w.UpdateAccountData(contract, &accounts.Account{}, account))
+	require.NoError(t, w.UpdateAccountData(deleted, &accounts.Account{}, &accounts.Account{Nonce: 3, Initialised: true}))
+	require.NoError(t, w.DeleteAccount(deleted, &accounts.Account{Nonce: 3, Initialised: true}))
+	// the record does not alias the writes
+	account.Nonce = 2
+
+	cache := NewExecutionCache(1)
+	cache.add(libcommon.Hash{1}, &executionRecord{txs: []stateWrites{w.writes}})
+	record, ok := cache.get(libcommon.Hash{1})
+	require.True(t, ok)
+	_, ok = cache.get(libcommon.Hash{2})
+	require.False(t, ok)
+
+	restored := newRecordedState(state.NewPlainState(tx, 1, nil))
+	require.NoError(t, record.txs[0].apply(restored))
+	for _, s := range []*recordedState{executed, restored} {
+		a, err := s.ReadAccountData(contract)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), a.Nonce)
+		require.Equal(t, uint64(100), a.Balance.Uint64())
+
+		c, err := s.ReadAccountCode(contract, 1, codeHash)
+		require.NoError(t, err)
+		require.Equal(t, code, c)
+
+		v, err := s.ReadAccountStorage(contract, 1, &key)
+		require.NoError(t, err)
+		require.Equal(t, value.Bytes(), v)
+		// storage of re-created contracts is not read from the parent state
+		v, err = s.ReadAccountStorage(contract, 1, &libcommon.Hash{})
+		require.NoError(t, err)
+		require.Nil(t, v)
+
+		a, err = s.ReadAccountData(deleted)
+		require.NoError(t, err)
+		require.Nil(t, a)
+	}
+}
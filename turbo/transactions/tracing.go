@@ -35,8 +35,9 @@ type BlockGetter interface {
 	GetBlock(hash libcommon.Hash, number uint64) *types.Block
 }
 
-// ComputeTxEnv returns the execution environment of a certain transaction.
-func ComputeTxEnv(ctx context.Context, engine consensus.EngineReader, block *types.Block, cfg *chain.Config, headerReader services.HeaderReader, dbtx kv.Tx, txIndex int, historyV3 bool) (core.Message, evmtypes.BlockContext, evmtypes.TxContext, *state.IntraBlockState, state.StateReader, error) {
+// ComputeTxEnv returns the execution environment of a certain transaction. Without history v3 the previous
+// transactions of the block are re-executed, or their state writes are restored from the cache when it's not nil.
+func ComputeTxEnv(ctx context.Context, engine consensus.EngineReader, block *types.Block, cfg *chain.Config, headerReader services.HeaderReader, dbtx kv.Tx, txIndex int, historyV3 bool, cache *ExecutionCache) (core.Message, evmtypes.BlockContext, evmtypes.TxContext, *state.IntraBlockState, state.StateReader, error) {
 	reader, err := rpchelper.CreateHistoryStateReader(dbtx, block.NumberU64(), txIndex, historyV3, cfg.ChainName)
 	if err != nil {
 		return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, err
@@ -73,18 +74,41 @@ func ComputeTxEnv(ctx context.Context, engine consensus.EngineReader, block *typ
 		TxContext := core.NewEVMTxContext(msg)
 		return msg, blockContext, TxContext, statedb, reader, nil
 	}
+	// writes of the block are restored from the record and recorded on top of the parent state
+	recorded := newRecordedState(reader.(*state.PlainState))
+	statedb = state.New(recorded)
 	vmenv := vm.NewEVM(blockContext, evmtypes.TxContext{}, statedb, cfg, vm.Config{})
 	rules := vmenv.ChainRules()
 
-	consensusHeaderReader := consensuschain.NewReader(cfg, dbtx, nil, nil)
+	record, ok := cache.get(block.Hash())
+	if ok {
+		if err := record.init.apply(recorded); err != nil {
+			return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, err
+		}
+	} else {
+		consensusHeaderReader := consensuschain.NewReader(cfg, dbtx, nil, nil)
 
-	logger := log.New("tracing")
-	err = core.InitializeBlockExecution(engine.(consensus.Engine), consensusHeaderReader, header, cfg, statedb, logger)
-	if err != nil {
-		return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, err
+		logger := log.New("tracing")
+		initWriter := &recordingWriter{w: recorded}
+		err = core.InitializeBlockExecutionWithWriter(engine.(consensus.Engine), consensusHeaderReader, header, cfg, statedb, initWriter, logger)
+		if err != nil {
+			return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, err
+		}
+		record = &executionRecord{init: initWriter.writes}
+		cache.add(block.Hash(), record)
+	}
+	restored := min(txIndex, len(record.txs))
+	for _, writes := range record.txs[:restored] {
+		if err := writes.apply(recorded); err != nil {
+			return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, err
+		}
+	}
+	if restored == len(record.txs) {
+		record = record.extend()
 	}
 
-	for idx, txn := range block.Transactions() {
+	for idx, txn := range block.Transactions()[restored:] {
+		idx += restored
 		select {
 		default:
 		case <-ctx.Done():
@@ -112,13 +136,20 @@ func ComputeTxEnv(ctx context.Context, engine consensus.EngineReader, block *typ
 		}
 		// Ensure any modifications are committed to the state
 		// Only delete empty objects if EIP161 (part of Spurious Dragon) is in effect
-		_ = statedb.FinalizeTx(rules, reader.(*state.PlainState))
+		txWriter := &recordingWriter{w: recorded}
+		_ = statedb.FinalizeTx(rules, txWriter)
+		record.txs = append(record.txs, txWriter.writes)
+		cache.add(block.Hash(), &executionRecord{init: record.init, txs: record.txs})
 
 		if idx+1 == len(block.Transactions()) {
 			// Return the state from evaluating all txs in the block, note no msg or TxContext in this case
 			return nil, blockContext, evmtypes.TxContext{}, statedb, reader, nil
 		}
 	}
+	if restored > 0 && restored == len(block.Transactions()) {
+		// Return the state from evaluating all txs in the block, restored from the cache
+		return nil, blockContext, evmtypes.TxContext{}, statedb, reader, nil
+	}
 	return nil, evmtypes.BlockContext{}, evmtypes.TxContext{}, nil, nil, fmt.Errorf("transaction index %d out of range for block %x", txIndex, block.Hash())
 }
 
@@ -135,8 +166,9 @@ func TraceTx(
 	chainConfig *chain.Config,
 	stream *jsoniter.Stream,
 	callTimeout time.Duration,
+	budget *tracers.Budget,
 ) error {
-	tracer, streaming, cancel, err := AssembleTracer(ctx, config, txCtx.TxHash, stream, callTimeout)
+	tracer, streaming, cancel, err := AssembleTracer(ctx, config, txCtx.TxHash, stream, callTimeout, budget)
 	if err != nil {
 		stream.WriteNil()
 		return err
@@ -158,6 +190,7 @@ func AssembleTracer(
 	txHash libcommon.Hash,
 	stream *jsoniter.Stream,
 	callTimeout time.Duration,
+	budget *tracers.Budget,
 ) (vm.EVMLogger, bool, context.CancelFunc, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	switch {
@@ -177,7 +210,7 @@ func AssembleTracer(
 		if config != nil && config.TracerConfig != nil {
 			cfg = *config.TracerConfig
 		}
-		tracer, err := tracers.New(*config.Tracer, &tracers.Context{TxHash: txHash}, cfg)
+		tracer, err := tracers.New(*config.Tracer, &tracers.Context{TxHash: txHash, Budget: budget}, cfg)
 		if err != nil {
 			return nil, false, func() {}, err
 		}
@@ -197,6 +197,75 @@ type rawProofElement struct {
 	value []byte
 }
 
+// ProofPath follows the nibble encoded path (without terminator) from the root through the RLP encoded nodes given by
+// their hashes, such as the ones collected by HexPatriciaHashed.ProveHashedKeys. It returns the hashed nodes met on the
+// way, ordered from the root downwards, and the encoding of the node located exactly at the path, or nil if there is no
+// such node. Nodes embedded into their parents are not part of the proof. The walk ends at a leaf, at an empty child
+// or where the path diverges from the key of a short node, which is enough to prove the absence of the path. The
+// empty trie has no nodes.
+func ProofPath(root libcommon.Hash, nodes map[libcommon.Hash][]byte, hexPath []byte) (proof [][]byte, node []byte, err error) {
+	if root == EmptyRoot {
+		return nil, nil, nil
+	}
+	enc, ok := nodes[root]
+	if !ok {
+		return nil, nil, fmt.Errorf("missing root node %x", root)
+	}
+	proof = append(proof, enc)
+	for len(hexPath) > 0 {
+		elems, _, err := rlp.SplitList(enc)
+		if err != nil {
+			return nil, nil, err
+		}
+		var ref []byte
+		switch c, _ := rlp.CountValues(elems); c {
+		case 17:
+			ref = elems
+			for i := byte(0); i < hexPath[0]; i++ {
+				if _, _, ref, err = rlp.Split(ref); err != nil {
+					return nil, nil, err
+				}
+			}
+			hexPath = hexPath[1:]
+		case 2:
+			kbuf, rest, err := rlp.SplitString(elems)
+			if err != nil {
+				return nil, nil, err
+			}
+			key := compactToHex(kbuf)
+			if len(key) > 0 && key[len(key)-1] == 16 {
+				// leaf, nothing below it
+				return proof, nil, nil
+			}
+			if len(key) > len(hexPath) || !bytes.Equal(key, hexPath[:len(key)]) {
+				return proof, nil, nil
+			}
+			ref, hexPath = rest, hexPath[len(key):]
+		default:
+			return nil, nil, fmt.Errorf("invalid number of list elements: %v", c)
+		}
+
+		kind, val, rest, err := rlp.Split(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case kind == rlp.List:
+			enc = ref[:len(ref)-len(rest)]
+		case kind == rlp.String && len(val) == 0:
+			return proof, nil, nil
+		case kind == rlp.String && len(val) == length.Hash:
+			if enc, ok = nodes[libcommon.BytesToHash(val)]; !ok {
+				return nil, nil, fmt.Errorf("missing node %x", val)
+			}
+			proof = append(proof, enc)
+		default:
+			return nil, nil, fmt.Errorf("invalid RLP string size %d (want 0 through 32)", len(val))
+		}
+	}
+	return proof, enc, nil
+}
+
 // proofMap creates a map from hash to proof node
 func proofMap(proof []hexutility.Bytes) (map[libcommon.Hash]node, map[libcommon.Hash]rawProofElement, error) {
 	res := map[libcommon.Hash]node{}
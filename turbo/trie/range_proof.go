@@ -0,0 +1,141 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+)
+
+// proofElementRetainer is implemented by the retainers which collect proof
+// elements while the FlatDBTrieLoader calculates the trie root.
+type proofElementRetainer interface {
+	ProofElement(prefix []byte) *proofElement
+}
+
+// StorageTrieDepth is the length (in nibbles) of the prefix under which the
+// nodes of a storage trie are reported: account hash followed by incarnation.
+const StorageTrieDepth = 2 * (length.Hash + length.Incarnation)
+
+// RangeProofRetainer collects the nodes along an arbitrary set of trie paths,
+// together with the storage roots of the accounts on those paths, during the
+// FlatDBTrieLoader root calculation. Unlike ProofRetainer it is not tied to a
+// single account, which makes it suitable for range proofs (the nodes on the
+// paths to the first and the last key of a range) and for serving trie nodes
+// by path.
+type RangeProofRetainer struct {
+	rl     *RetainList
+	paths  [][]byte
+	sorted bool
+	proofs []*proofElement
+	roots  map[string]libcommon.Hash
+}
+
+// NewRangeProofRetainer creates a RangeProofRetainer which adds the paths it
+// is asked to track to the given RetainList. The RetainList must not be
+// populated through any other means, and the retainer must be set onto the
+// FlatDBTrieLoader via SetRangeProofRetainer before its root calculation.
+func NewRangeProofRetainer(rl *RetainList) *RangeProofRetainer {
+	return &RangeProofRetainer{rl: rl}
+}
+
+// AddKey requests the nodes on the path to the given key (in KEY encoding:
+// either an account hash, or an account hash followed by incarnation and a
+// storage hash) and returns the nibble encoded key.
+func (pr *RangeProofRetainer) AddKey(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2] = b / 16
+		hex[i*2+1] = b % 16
+	}
+	pr.AddHex(hex)
+	return hex
+}
+
+// AddHex requests the nodes on the given nibble encoded path.
+func (pr *RangeProofRetainer) AddHex(hex []byte) {
+	pr.rl.AddHex(hex)
+	pr.paths = append(pr.paths, hex)
+	pr.sorted = false
+}
+
+// ProofElement implements the proof collection hook of the FlatDBTrieLoader.
+func (pr *RangeProofRetainer) ProofElement(prefix []byte) *proofElement {
+	if !pr.rl.Retain(prefix) {
+		return nil
+	}
+	if !pr.sorted {
+		sort.Slice(pr.paths, func(i, j int) bool { return bytes.Compare(pr.paths[i], pr.paths[j]) < 0 })
+		pr.sorted = true
+	}
+	// Paths sharing the prefix are contiguous and the smallest of them is the
+	// first one not less than the prefix itself
+	i := sort.Search(len(pr.paths), func(i int) bool { return bytes.Compare(pr.paths[i], prefix) >= 0 })
+	if i == len(pr.paths) || !bytes.HasPrefix(pr.paths[i], prefix) {
+		return nil
+	}
+	pe := &proofElement{
+		hexKey: append([]byte{}, prefix...),
+	}
+	pr.proofs = append(pr.proofs, pe)
+	return pe
+}
+
+// Proof returns the RLP encoded nodes on the paths to the given nibble encoded
+// keys, ordered from the root downwards and without duplicates. Nodes above
+// depth are skipped (use 0 for the account trie, StorageTrieDepth for a
+// storage trie), and so are the nodes embedded into their parents.
+func (pr *RangeProofRetainer) Proof(depth int, hexKeys ...[]byte) [][]byte {
+	elements := make([]*proofElement, 0, len(pr.proofs))
+	for _, pe := range pr.proofs {
+		if len(pe.hexKey) < depth || pe.proof.Len() == 0 {
+			continue
+		}
+		if len(pe.hexKey) > depth && pe.proof.Len() < length.Hash {
+			continue
+		}
+		for _, hexKey := range hexKeys {
+			if bytes.HasPrefix(hexKey, pe.hexKey) {
+				elements = append(elements, pe)
+				break
+			}
+		}
+	}
+	sort.SliceStable(elements, func(i, j int) bool { return bytes.Compare(elements[i].hexKey, elements[j].hexKey) < 0 })
+	proof := make([][]byte, 0, len(elements))
+	for i, pe := range elements {
+		if i > 0 && bytes.Equal(elements[i-1].hexKey, pe.hexKey) {
+			continue
+		}
+		proof = append(proof, libcommon.CopyBytes(pe.proof.Bytes()))
+	}
+	return proof
+}
+
+// Node returns the RLP encoding of the node located at the given nibble
+// encoded path, or nil if no such node was collected.
+func (pr *RangeProofRetainer) Node(hex []byte) []byte {
+	for _, pe := range pr.proofs {
+		if pe.proof.Len() > 0 && bytes.Equal(pe.hexKey, hex) {
+			return libcommon.CopyBytes(pe.proof.Bytes())
+		}
+	}
+	return nil
+}
+
+// StorageRoot returns the storage root of the account with the given nibble
+// encoded key, provided the key was added to the retainer and the account
+// exists.
+func (pr *RangeProofRetainer) StorageRoot(hexKey []byte) (libcommon.Hash, bool) {
+	if pr.roots == nil {
+		pr.roots = make(map[string]libcommon.Hash)
+		for _, pe := range pr.proofs {
+			if pe.storageRootKey != nil {
+				pr.roots[string(pe.storageRootKey)] = pe.storageRoot
+			}
+		}
+	}
+	root, ok := pr.roots[string(hexKey)]
+	return root, ok
+}
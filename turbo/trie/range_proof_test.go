@@ -0,0 +1,137 @@
+package trie_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/dbutils"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+func TestRangeProofRetainer(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	hashes := make([]libcommon.Hash, 32)
+	accs := make([]accounts.Account, len(hashes))
+	for i := range hashes {
+		hashes[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		accs[i] = accounts.Account{Nonce: uint64(i), Initialised: true, CodeHash: trie.EmptyCodeHash}
+		if i%4 == 0 {
+			accs[i].Incarnation = 1
+			accs[i].CodeHash = libcommon.Hash{9}
+			for j := 0; j < 3; j++ {
+				slot := crypto.Keccak256Hash([]byte{byte(i), byte(j)})
+				require.NoError(t, tx.Put(kv.HashedStorage, dbutils.GenerateCompositeStorageKey(hashes[i], 1, slot), []byte{byte(j + 1)}))
+			}
+		}
+		enc := make([]byte, accs[i].EncodingLengthForStorage())
+		accs[i].EncodeForStorage(enc)
+		require.NoError(t, tx.Put(kv.HashedAccounts, hashes[i][:], enc))
+	}
+	root, err := trie.CalcRoot("test", tx)
+	require.NoError(t, err)
+
+	rl := trie.NewRetainList(0)
+	pr := trie.NewRangeProofRetainer(rl)
+	first, second := pr.AddKey(hashes[0][:]), pr.AddKey(hashes[1][:])
+	missing := pr.AddKey(libcommon.Hash{0xaa}.Bytes())
+	loader := trie.NewFlatDBTrieLoader("test", rl, nil, nil, false)
+	loader.SetRangeProofRetainer(pr)
+	calculated, err := loader.CalcTrieRoot(tx, nil)
+	require.NoError(t, err)
+	require.Equal(t, root, calculated)
+
+	for i, hexKey := range [][]byte{first, second} {
+		storageRoot, ok := pr.StorageRoot(hexKey)
+		require.True(t, ok)
+		if accs[i].Incarnation == 0 {
+			require.Equal(t, trie.EmptyRoot, storageRoot)
+		} else {
+			require.NotEqual(t, trie.EmptyRoot, storageRoot)
+		}
+		proof := &accounts.AccProofResult{
+			Balance:     (*hexutil.Big)(accs[i].Balance.ToBig()),
+			Nonce:       hexutil.Uint64(accs[i].Nonce),
+			CodeHash:    accs[i].CodeHash,
+			StorageHash: storageRoot,
+		}
+		for _, node := range pr.Proof(0, hexKey) {
+			proof.AccountProof = append(proof.AccountProof, hexutility.Bytes(node))
+		}
+		require.NoError(t, trie.VerifyAccountProofByHash(root, hashes[i], proof))
+	}
+
+	proof := &accounts.AccProofResult{Balance: (*hexutil.Big)(new(uint256.Int).ToBig())}
+	for _, node := range pr.Proof(0, missing) {
+		proof.AccountProof = append(proof.AccountProof, hexutility.Bytes(node))
+	}
+	require.NoError(t, trie.VerifyAccountProofByHash(root, libcommon.Hash{0xaa}, proof))
+
+	// The proof of a range shares the nodes close to the root
+	both := pr.Proof(0, first, second)
+	require.Less(t, len(both), len(pr.Proof(0, first))+len(pr.Proof(0, second)))
+	require.Equal(t, root, crypto.Keccak256Hash(both[0]))
+	require.Equal(t, both[0], pr.Node(nil))
+}
+
+func TestProofPath(t *testing.T) {
+	tr := trie.New(trie.EmptyRoot)
+	keys := []libcommon.Hash{{0xaa}, {0xaa, 0xbb}}
+	for i := 0; i < 32; i++ {
+		key := crypto.Keccak256Hash([]byte{byte(i)})
+		acc := accounts.Account{Nonce: uint64(i), Initialised: true, Root: trie.EmptyRoot, CodeHash: trie.EmptyCodeHash}
+		tr.UpdateAccount(key[:], &acc)
+		keys = append(keys, key)
+	}
+	root := tr.Hash()
+
+	nodes := make(map[libcommon.Hash][]byte)
+	proofs := make([][][]byte, len(keys))
+	for i, key := range keys {
+		proof, err := tr.Prove(key[:], 0, false)
+		require.NoError(t, err)
+		for _, node := range proof {
+			nodes[crypto.Keccak256Hash(node)] = node
+		}
+		proofs[i] = proof
+	}
+	for i, key := range keys {
+		proof, _, err := trie.ProofPath(root, nodes, keyToHex(key[:]))
+		require.NoError(t, err)
+		require.Equal(t, proofs[i], proof)
+	}
+
+	proof, node, err := trie.ProofPath(root, nodes, nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{nodes[root]}, proof)
+	require.Equal(t, nodes[root], node)
+	_, node, err = trie.ProofPath(root, nodes, keyToHex(keys[2][:1]))
+	require.NoError(t, err)
+	require.NotNil(t, node)
+
+	delete(nodes, crypto.Keccak256Hash(proofs[2][1]))
+	_, _, err = trie.ProofPath(root, nodes, keyToHex(keys[2][:]))
+	require.Error(t, err)
+}
+
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2], hex[i*2+1] = b/16, b%16
+	}
+	return hex
+}
@@ -126,7 +126,7 @@ type RootHashAggregator struct {
 	accData        GenStructStepAccountData
 
 	// Used to construct an Account proof while calculating the tree root.
-	proofRetainer *ProofRetainer
+	proofRetainer proofElementRetainer
 	cutoff        bool
 }
 
@@ -165,6 +165,10 @@ func (l *FlatDBTrieLoader) SetProofRetainer(pr *ProofRetainer) {
 	l.receiver.proofRetainer = pr
 }
 
+func (l *FlatDBTrieLoader) SetRangeProofRetainer(pr *RangeProofRetainer) {
+	l.receiver.proofRetainer = pr
+}
+
 // CalcTrieRoot algo:
 //
 //		for iterateIHOfAccounts {
@@ -328,6 +332,63 @@ func (l *FlatDBTrieLoader) CalcTrieRoot(tx kv.Tx, quit <-chan struct{}) (libcomm
 	return l.receiver.Root(), nil
 }
 
+// CalcSubTrie - builds part of trie: accounts which hashed key starts with given nibble (and their storage).
+// Reads only state (HashedAccounts, HashedStorage) - existing intermediate hashes are not used and not deleted.
+// Sends to hash collectors same records as CalcTrieRoot does for keys under this nibble, because record of branch
+// node depends only on sub-trie below it. It means 16 sub-tries can be built independently (in parallel) and
+// their records together form full TrieOfAccounts/TrieOfStorage - only root is not stored.
+// onAccount (optional) is called with key of each visited account - for progress reporting.
+func (l *FlatDBTrieLoader) CalcSubTrie(tx kv.Tx, nibble byte, onAccount func(k []byte), quit <-chan struct{}) error {
+	if nibble > 0x0f {
+		return fmt.Errorf("CalcSubTrie: nibble out of range: %x", nibble)
+	}
+	accC, err := tx.Cursor(kv.HashedAccounts)
+	if err != nil {
+		return err
+	}
+	defer accC.Close()
+	accs := NewStateCursor(accC, quit)
+	ss, err := tx.CursorDupSort(kv.HashedStorage)
+	if err != nil {
+		return err
+	}
+	defer ss.Close()
+
+	for k, kHex, v, err := accs.Seek([]byte{nibble << 4}); k != nil; k, kHex, v, err = accs.Next() {
+		if err != nil {
+			return err
+		}
+		if k[0]>>4 != nibble {
+			break
+		}
+		if onAccount != nil {
+			onAccount(k)
+		}
+		if err = l.accountValue.DecodeForStorage(v); err != nil {
+			return fmt.Errorf("fail DecodeForStorage: %w", err)
+		}
+		if err = l.receiver.Receive(AccountStreamItem, kHex, nil, &l.accountValue, nil, nil, false, 0); err != nil {
+			return err
+		}
+		if l.accountValue.Incarnation == 0 {
+			continue
+		}
+		copy(l.accAddrHashWithInc[:], k)
+		binary.BigEndian.PutUint64(l.accAddrHashWithInc[32:], l.accountValue.Incarnation)
+		accWithInc := l.accAddrHashWithInc[:]
+		for vS, err := ss.SeekBothRange(accWithInc, nil); vS != nil; _, vS, err = ss.NextDup() {
+			if err != nil {
+				return err
+			}
+			hexutil.DecompressNibbles(vS[:32], &l.kHexS)
+			if err = l.receiver.Receive(StorageStreamItem, accWithInc, l.kHexS, nil, vS[32:], nil, false, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return l.receiver.Receive(CutoffStreamItem, nil, nil, nil, nil, nil, false, 0)
+}
+
 func (l *FlatDBTrieLoader) logProgress(accountKey, ihK []byte) {
 	var k string
 	if accountKey != nil {